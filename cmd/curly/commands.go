@@ -0,0 +1,2802 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/textproto"
+	"net/url"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+	"time"
+	"unicode/utf8"
+
+	"github.com/williajm/curly/internal/app"
+	"github.com/williajm/curly/internal/domain"
+	"github.com/williajm/curly/internal/infrastructure/config"
+	"github.com/williajm/curly/internal/infrastructure/faker"
+	"github.com/williajm/curly/internal/infrastructure/http"
+	"github.com/williajm/curly/internal/infrastructure/imagepreview"
+	"github.com/williajm/curly/internal/infrastructure/openapi"
+	"github.com/williajm/curly/internal/infrastructure/release"
+	"github.com/williajm/curly/internal/infrastructure/repository"
+	"github.com/williajm/curly/internal/infrastructure/repository/sqlite"
+	"github.com/williajm/curly/internal/infrastructure/script"
+	"github.com/williajm/curly/internal/infrastructure/sharelink"
+	"github.com/williajm/curly/internal/infrastructure/storage"
+	"github.com/williajm/curly/internal/presentation/models"
+)
+
+// globalOptions holds the flags that apply to every command.
+type globalOptions struct {
+	configPath string
+	dbPath     string
+	workspace  string
+}
+
+// command is a non-interactive subcommand invoked as `curly <name> [args]`.
+type command struct {
+	name    string
+	usage   string
+	summary string
+	run     func(opts globalOptions, args []string) error
+}
+
+// commands returns all registered subcommands in the order they are listed in usage.
+func commands() []command {
+	return []command{
+		{
+			name:    "backup",
+			usage:   "backup <file>",
+			summary: "Write an online backup of the database to <file>",
+			run:     runBackup,
+		},
+		{
+			name:    "codegen",
+			usage:   "codegen [-lang <lang>] [-mask] <request>",
+			summary: "Print a saved request as a curl, Go, Python or JavaScript snippet",
+			run:     runCodegen,
+		},
+		{
+			name:    "contract",
+			usage:   "contract [-folder <name>] <file>",
+			summary: "Check a folder's responses against an OpenAPI document and report mismatches",
+			run:     runContract,
+		},
+		{
+			name:    "config",
+			usage:   "config init|show|validate [-force]",
+			summary: "Write a commented default config file, print the effective configuration, or check the config file",
+			run:     runConfig,
+		},
+		{
+			name:    "diff",
+			usage:   "diff <history-id> <history-id>",
+			summary: "Compare the responses of two history entries",
+			run:     runDiff,
+		},
+		{
+			name:    "env",
+			usage:   "env list|show|create|set|unset|use [flags] [args]",
+			summary: "Manage environments and choose the active one",
+			run:     runEnv,
+		},
+		{
+			name:    "export",
+			usage:   "export [-format postman|curl] [-folder <name>] [-o <file>]",
+			summary: "Write saved requests as a Postman collection or a script of curl commands",
+			run:     runExport,
+		},
+		{
+			name:    "fake",
+			usage:   "fake [-list] [-seed <n>] [file]",
+			summary: "Print a random example body for a JSON Schema (from a file or -), or list the {{fake.*}} placeholders",
+			run:     runFake,
+		},
+		{
+			name:    "history",
+			usage:   "history list|show|replay [flags] [history-id]",
+			summary: "List history entries matching filters, show one in full, or replay one",
+			run:     runHistory,
+		},
+		{
+			name:    "import",
+			usage:   "import openapi|postman|curl|link <file>",
+			summary: "Create saved requests from an OpenAPI document, a Postman collection, a curl command or a share link",
+			run:     runImport,
+		},
+		{
+			name:    "load",
+			usage:   "load [flags] <request>",
+			summary: "Load test a saved request and report latency percentiles and throughput",
+			run:     runLoad,
+		},
+		{
+			name:    "mock",
+			usage:   "mock [-collection <folder>] [-host <host>] [-port <port>]",
+			summary: "Serve the last recorded response of each saved request in a folder as a mock API",
+			run:     runMock,
+		},
+		{
+			name:    "replay",
+			usage:   "replay [-extract <path>] [-fail] <history-id>",
+			summary: "Re-send the request recorded by a history entry, exactly as it was sent",
+			run:     runReplay,
+		},
+		{
+			name:    "restore",
+			usage:   "restore <file>",
+			summary: "Replace the database contents with the backup in <file>",
+			run:     runRestore,
+		},
+		{
+			name:    "run",
+			usage:   "run [-extract <path>] [-report <file>] [-json] [folder | request | -id <request-id>]",
+			summary: "Run the saved requests in a folder in order, or a single saved request, and report the results",
+			run:     runRun,
+		},
+		{
+			name:    "schema",
+			usage:   "schema [-clear] <request> [file]",
+			summary: "Show, attach (from a file or -) or clear the JSON Schema a request's responses must match",
+			run:     runSchema,
+		},
+		{
+			name:    "script",
+			usage:   "script [-post] [-clear] <request> [file]",
+			summary: "Show, attach (from a file or -) or clear the JavaScript a request runs before it is sent, or with -post once its response is received",
+			run:     runScript,
+		},
+		{
+			name:    "send",
+			usage:   "send [-X <method>] [-H <header>]... [-d <body>] <url>",
+			summary: "Send a request without the TUI, print the response and record it to history",
+			run:     runSend,
+		},
+		{
+			name:    "share",
+			usage:   "share [-secrets] <request>",
+			summary: "Print a share link for a saved request, with credentials stripped unless -secrets is given",
+			run:     runShare,
+		},
+		{
+			name:    "stats",
+			usage:   "stats [-hosts] [-format table|json|prometheus]",
+			summary: "Print per-request or per-host execution statistics from history, or export them as JSON or Prometheus metrics",
+			run:     runStats,
+		},
+		{
+			name:    "sync",
+			usage:   "sync export|import [flags] <dir>",
+			summary: "Mirror saved requests and environments to YAML files in <dir>, or load them back",
+			run:     runSync,
+		},
+		{
+			name:    "upgrade",
+			usage:   "upgrade -check",
+			summary: "Check whether a newer release of curly is out",
+			run:     runUpgrade,
+		},
+		{
+			name:    "workspaces",
+			usage:   "workspaces",
+			summary: "List workspaces, marking the active one",
+			run:     runWorkspaces,
+		},
+	}
+}
+
+// runCommand dispatches to the named subcommand.
+func runCommand(opts globalOptions, name string, args []string) error {
+	for _, cmd := range commands() {
+		if cmd.name == name {
+			return cmd.run(opts, args)
+		}
+	}
+	usage()
+	return fmt.Errorf("unknown command %q", name)
+}
+
+// usage prints the top-level help text.
+func usage() {
+	out := flag.CommandLine.Output()
+	_, _ = fmt.Fprintf(out, "Usage:\n  curly [flags]              Start the terminal UI\n  curly [flags] <command>    Run a command\n\nCommands:\n")
+	for _, cmd := range commands() {
+		_, _ = fmt.Fprintf(out, "  %-26s %s\n", cmd.usage, cmd.summary)
+	}
+	_, _ = fmt.Fprintf(out, "\nFlags:\n")
+	flag.PrintDefaults()
+}
+
+// newFlagSet creates a flag set for a subcommand with a consistent usage line.
+func newFlagSet(cmdUsage string) *flag.FlagSet {
+	fs := flag.NewFlagSet(cmdUsage, flag.ContinueOnError)
+	fs.Usage = func() {
+		_, _ = fmt.Fprintf(fs.Output(), "Usage: curly %s\n", cmdUsage)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// runBackup implements `curly backup <file>`.
+func runBackup(opts globalOptions, args []string) error {
+	fs := newFlagSet("backup <file>")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return fmt.Errorf("backup requires exactly one destination file")
+	}
+
+	cfg, err := loadConfig(opts)
+	if err != nil {
+		return err
+	}
+	db, err := openSQLite(cfg)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = db.Close() }()
+
+	dest, err := filepath.Abs(fs.Arg(0))
+	if err != nil {
+		return fmt.Errorf("invalid destination: %w", err)
+	}
+	if err := sqlite.Backup(context.Background(), db, dest); err != nil {
+		return err
+	}
+
+	fmt.Printf("Backed up %s to %s\n", cfg.Database.Path, dest)
+	return nil
+}
+
+// runCodegen implements `curly codegen [-lang <lang>] [-mask] <request>`.
+// The request is given by ID, unique ID prefix, or name, and flags may also
+// follow it. -mask replaces secrets so the snippet can be published.
+func runCodegen(opts globalOptions, args []string) error {
+	codegenService := app.NewCodegenService(slog.Default())
+
+	fs := newFlagSet("codegen [-lang <lang>] [-mask] <request>")
+	language := fs.String("lang", "curl", "Snippet language: "+strings.Join(codegenService.Languages(), ", "))
+	mask := fs.Bool("mask", false, "Replace auth credentials and secret headers and query parameters with "+domain.SecretMask)
+	positional, err := parseInterspersed(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 {
+		fs.Usage()
+		return fmt.Errorf("codegen requires a request ID or name")
+	}
+
+	cfg, err := loadConfig(opts)
+	if err != nil {
+		return err
+	}
+	store, err := openStore(cfg)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = store.Close() }()
+
+	ctx := context.Background()
+	requestService := app.NewRequestService(store.Requests, http.NewClient(nil), store.History, slog.Default())
+	req, err := requestService.FindRequest(ctx, positional[0])
+	if err != nil {
+		return err
+	}
+
+	generate := codegenService.Generate
+	if *mask {
+		generate = codegenService.GenerateMasked
+	}
+	snippet, err := generate(req, *language)
+	if err != nil {
+		return err
+	}
+	fmt.Print(snippet)
+	return nil
+}
+
+// runContract implements `curly contract [-folder <name>] <file>`. It attaches
+// the document's response contracts to the folder's requests, then runs the
+// folder and reports every response that breaks its contract. The contracts
+// stay attached, so later executions are checked too.
+func runContract(opts globalOptions, args []string) error {
+	fs := newFlagSet("contract [-folder <name>] <file>")
+	folder := fs.String("folder", "", "Folder to check (defaults to the document's title)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return fmt.Errorf("contract requires an OpenAPI document, or - for standard input")
+	}
+
+	data, err := readInput(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	cfg, err := loadConfig(opts)
+	if err != nil {
+		return err
+	}
+	store, err := openStore(cfg)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = store.Close() }()
+
+	ctx := context.Background()
+	result, err := app.NewImportService(store.Requests, slog.Default()).AttachContracts(ctx, data, *folder)
+	if err != nil {
+		return err
+	}
+	for _, req := range result.Unmatched {
+		fmt.Fprintf(os.Stderr, "warning: no operation matches %s %s (%s)\n", req.Method, req.URL, req.Name)
+	}
+	if len(result.Attached) == 0 {
+		return fmt.Errorf("no request in folder %q matches an operation in the document", result.Folder)
+	}
+
+	requestService := app.NewRequestService(store.Requests, newHTTPClient(cfg), store.History, slog.Default())
+	report, err := app.NewRunnerService(requestService, slog.Default()).Run(ctx, result.Folder)
+	if err != nil {
+		return err
+	}
+	return printReport(report)
+}
+
+// configUsages gives the usage of each `curly config` subcommand.
+var configUsages = map[string]string{
+	"init":     "config init [-force]",
+	"show":     "config show",
+	"validate": "config validate",
+}
+
+// runConfig implements `curly config`: init writes a config file with every
+// setting at its default, show prints the configuration curly runs with, and
+// validate reports syntax errors, unknown settings and invalid values.
+func runConfig(opts globalOptions, args []string) error {
+	if len(args) == 0 || configUsages[args[0]] == "" {
+		newFlagSet("config init|show|validate [-force]").Usage()
+		return fmt.Errorf("config requires init, show or validate")
+	}
+	sub := args[0]
+
+	fs := newFlagSet(configUsages[sub])
+	force := false
+	if sub == "init" {
+		fs.BoolVar(&force, "force", false, "Replace an existing config file")
+	}
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+	if fs.NArg() != 0 {
+		fs.Usage()
+		return fmt.Errorf("usage: curly %s", configUsages[sub])
+	}
+
+	path, exists, err := config.Path(opts.configPath)
+	if err != nil {
+		return err
+	}
+
+	switch sub {
+	case "init":
+		if err := config.WriteDefault(path, force); err != nil {
+			if exists && !force {
+				return fmt.Errorf("%w (use -force to replace it)", err)
+			}
+			return err
+		}
+		fmt.Printf("Wrote %s\n", path)
+		return nil
+	case "show":
+		return showConfig(opts, path, exists)
+	default:
+		return validateConfig(opts, path, exists)
+	}
+}
+
+// showConfig prints the configuration curly runs with, naming the config file
+// it came from. The database password is hidden.
+func showConfig(opts globalOptions, path string, exists bool) error {
+	cfg, err := loadConfig(opts)
+	if err != nil {
+		return err
+	}
+	cfg.Database.DSN = redactDSN(cfg.Database.DSN)
+	data, err := config.Marshal(cfg)
+	if err != nil {
+		return err
+	}
+	if exists {
+		fmt.Printf("# %s\n", path)
+	} else {
+		fmt.Printf("# defaults (no config file at %s)\n", path)
+	}
+	fmt.Print(string(data))
+	return nil
+}
+
+// validateConfig prints the problems in the config file at path, and fails
+// if there are any.
+func validateConfig(opts globalOptions, path string, exists bool) error {
+	if !exists {
+		fmt.Printf("No config file at %s; curly uses the defaults\n", path)
+		return nil
+	}
+	problems, err := configProblems(opts)
+	if err != nil {
+		return err
+	}
+	for _, problem := range problems {
+		fmt.Printf("%s: %s\n", path, problem)
+	}
+	if len(problems) == 1 {
+		return fmt.Errorf("%s has 1 problem", path)
+	}
+	if len(problems) > 1 {
+		return fmt.Errorf("%s has %d problems", path, len(problems))
+	}
+	fmt.Printf("%s is valid\n", path)
+	return nil
+}
+
+// configProblems returns the problems in the configuration: settings curly
+// does not know, which are usually typos, and invalid values. A config file
+// that does not parse, or a value of the wrong type, is returned as an error.
+func configProblems(opts globalOptions) ([]string, error) {
+	unknown, err := config.UnknownKeys(opts.configPath)
+	if err != nil {
+		return nil, err
+	}
+	cfg, err := loadConfig(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var problems []string
+	for _, key := range unknown {
+		problems = append(problems, fmt.Sprintf("%s: unknown setting", key))
+	}
+	problems = append(problems, cfg.Validate()...)
+	if _, err := theme(cfg); err != nil {
+		problems = append(problems, fmt.Sprintf("ui.theme: %v", err))
+	}
+	if _, err := imagepreview.ParseProtocol(cfg.UI.ImagePreview); err != nil {
+		problems = append(problems, fmt.Sprintf("ui.image_preview: %v", err))
+	}
+	if _, err := models.DefaultKeymap().Bind(cfg.UI.Keybindings); err != nil {
+		problems = append(problems, fmt.Sprintf("ui.keybindings: %v", err))
+	}
+	if cfg.UI.DefaultTab != "" {
+		if _, err := models.TabNamed(cfg.UI.DefaultTab); err != nil {
+			problems = append(problems, fmt.Sprintf("ui.default_tab: %v", err))
+		}
+	}
+	return problems, nil
+}
+
+// redactDSN hides the password in a PostgreSQL connection string, given as a
+// URL or as key=value pairs.
+func redactDSN(dsn string) string {
+	if u, err := url.Parse(dsn); err == nil && u.Scheme != "" {
+		return u.Redacted()
+	}
+	fields := strings.Fields(dsn)
+	for i, field := range fields {
+		if strings.HasPrefix(field, "password=") {
+			fields[i] = "password=xxxxx"
+		}
+	}
+	return strings.Join(fields, " ")
+}
+
+// runDiff implements `curly diff <history-id> <history-id>`.
+func runDiff(opts globalOptions, args []string) error {
+	fs := newFlagSet("diff <history-id> <history-id>")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 2 {
+		fs.Usage()
+		return fmt.Errorf("diff requires two history entry IDs")
+	}
+
+	cfg, err := loadConfig(opts)
+	if err != nil {
+		return err
+	}
+	store, err := openStore(cfg)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = store.Close() }()
+
+	d, err := app.NewDiffService(store.History, slog.Default()).CompareIDs(context.Background(), fs.Arg(0), fs.Arg(1))
+	if err != nil {
+		return err
+	}
+	fmt.Print(d.Format())
+	return nil
+}
+
+// envUsages gives the usage of each `curly env` subcommand.
+var envUsages = map[string]string{
+	"list":   "env list",
+	"show":   "env show [name]",
+	"create": "env create <name>",
+	"set":    "env set [-env <name>] [-secret] NAME=value|NAME...",
+	"unset":  "env unset [-env <name>] NAME...",
+	"use":    "env use <name> | env use -none",
+}
+
+// runEnv implements `curly env`, which manages environments from scripts:
+// list them, show one, create one, set or unset its variables, and choose the
+// active one, whose variables fill {{name}} references in `curly run` and
+// `curly send`.
+func runEnv(opts globalOptions, args []string) error {
+	if len(args) == 0 || envUsages[args[0]] == "" {
+		newFlagSet("env list|show|create|set|unset|use [flags] [args]").Usage()
+		return fmt.Errorf("env requires list, show, create, set, unset or use")
+	}
+	sub := args[0]
+
+	fs := newFlagSet(envUsages[sub])
+	var (
+		envName string
+		secret  bool
+		none    bool
+	)
+	switch sub {
+	case "set", "unset":
+		fs.StringVar(&envName, "env", "", "Environment to change (default the active one)")
+		if sub == "set" {
+			fs.BoolVar(&secret, "secret", false, "Mask the values wherever they are shown")
+		}
+	case "use":
+		fs.BoolVar(&none, "none", false, "Deactivate the active environment")
+	}
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+	if !envArgsValid(sub, fs.NArg(), none) {
+		fs.Usage()
+		return fmt.Errorf("usage: curly %s", envUsages[sub])
+	}
+
+	cfg, err := loadConfig(opts)
+	if err != nil {
+		return err
+	}
+	store, err := openStore(cfg)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = store.Close() }()
+
+	ctx := context.Background()
+	service := app.NewEnvironmentService(store.Environments, slog.Default())
+
+	switch sub {
+	case "list":
+		return listEnvironments(ctx, service)
+	case "show":
+		return showEnvironment(ctx, service, fs.Arg(0))
+	case "create":
+		env, err := service.Create(ctx, fs.Arg(0))
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Created environment %s\n", env.Name)
+		return nil
+	case "set", "unset":
+		return changeVariables(ctx, service, envName, sub == "set", fs.Args(), secret)
+	default:
+		return activateEnvironment(ctx, service, fs.Arg(0))
+	}
+}
+
+// envArgsValid reports whether an env subcommand was given the right number
+// of arguments. use takes a name, or -none instead.
+func envArgsValid(sub string, n int, none bool) bool {
+	switch sub {
+	case "list":
+		return n == 0
+	case "show":
+		return n <= 1
+	case "create":
+		return n == 1
+	case "set", "unset":
+		return n > 0
+	default:
+		return none == (n == 0) && n <= 1
+	}
+}
+
+// changeVariables sets or unsets variables of the named environment, or the
+// active one if name is empty.
+func changeVariables(ctx context.Context, service *app.EnvironmentService, name string, set bool, args []string, secret bool) error {
+	env, err := findEnvironment(ctx, service, name)
+	if err != nil {
+		return err
+	}
+	if set {
+		err = setVariables(env, args, secret)
+	} else {
+		err = unsetVariables(env, args)
+	}
+	if err != nil {
+		return err
+	}
+	if err := service.Save(ctx, env); err != nil {
+		return err
+	}
+	fmt.Printf("Updated environment %s\n", env.Name)
+	return nil
+}
+
+// activateEnvironment makes the named environment the active one, or
+// deactivates the active one if name is empty.
+func activateEnvironment(ctx context.Context, service *app.EnvironmentService, name string) error {
+	env, err := service.Use(ctx, name)
+	if err != nil {
+		return err
+	}
+	if env == nil {
+		fmt.Println("No environment is active")
+	} else {
+		fmt.Printf("Using environment %s\n", env.Name)
+	}
+	return nil
+}
+
+// listEnvironments prints the environments, marking the active one.
+func listEnvironments(ctx context.Context, service *app.EnvironmentService) error {
+	envs, err := service.List(ctx)
+	if err != nil {
+		return err
+	}
+	if len(envs) == 0 {
+		fmt.Println("No environments yet.")
+		return nil
+	}
+	active, err := service.Active(ctx)
+	if err != nil {
+		return err
+	}
+	for _, env := range envs {
+		marker := " "
+		if active != nil && env.ID == active.ID {
+			marker = "*"
+		}
+		fmt.Printf("%s %s (%d variables)\n", marker, env.Name, len(env.Variables))
+	}
+	return nil
+}
+
+// showEnvironment prints the named environment, or the active one if name
+// is empty.
+func showEnvironment(ctx context.Context, service *app.EnvironmentService, name string) error {
+	env, err := findEnvironment(ctx, service, name)
+	if err != nil {
+		return err
+	}
+	fmt.Print(environmentText(env))
+	return nil
+}
+
+// findEnvironment returns the named environment, or the active one if name
+// is empty.
+func findEnvironment(ctx context.Context, service *app.EnvironmentService, name string) (*domain.Environment, error) {
+	if name != "" {
+		return service.Find(ctx, name)
+	}
+	env, err := service.Active(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if env == nil {
+		return nil, fmt.Errorf("no environment is active: name one with -env, or activate one with `curly env use <name>`")
+	}
+	return env, nil
+}
+
+// environmentText formats an environment's variables as NAME=value
+// lines under its name, with secrets masked.
+func environmentText(env *domain.Environment) string {
+	masked := env.MaskedVariables()
+	names := make([]string, 0, len(masked))
+	for name := range masked {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var text strings.Builder
+	fmt.Fprintf(&text, "# %s\n", env.Name)
+	for _, name := range names {
+		fmt.Fprintf(&text, "%s=%s\n", name, masked[name])
+	}
+	return text.String()
+}
+
+// setVariables applies NAME=value assignments to env, as secrets if secret
+// is set. A bare NAME takes its value from the process environment variable
+// of the same name, so CI can pass a secret without putting it on the
+// command line.
+func setVariables(env *domain.Environment, assignments []string, secret bool) error {
+	for _, assignment := range assignments {
+		name, value, ok := strings.Cut(assignment, "=")
+		if !ok {
+			value, ok = os.LookupEnv(name)
+			if !ok {
+				return fmt.Errorf("%s is not set in the process environment: use %s=value", name, name)
+			}
+		}
+		if err := domain.ValidateVariableName(name); err != nil {
+			return err
+		}
+		// A secret stays one when its value changes.
+		env.Set(name, value, secret || env.IsSecret(name))
+	}
+	return nil
+}
+
+// unsetVariables removes the named variables from env.
+func unsetVariables(env *domain.Environment, names []string) error {
+	for _, name := range names {
+		if _, ok := env.Variables[name]; !ok {
+			return fmt.Errorf("environment %s has no variable %s", env.Name, name)
+		}
+		env.Unset(name)
+	}
+	return nil
+}
+
+// runExport implements `curly export`. The export goes to standard output
+// unless -o is given; it includes credentials, so a file is only readable by
+// its owner.
+func runExport(opts globalOptions, args []string) error {
+	fs := newFlagSet("export [-format postman|curl] [-folder <name>] [-o <file>]")
+	format := fs.String("format", app.ExportFormatPostman, "Export format: "+strings.Join(app.ExportFormats, " or "))
+	folder := fs.String("folder", "", "Export only this folder and the folders nested in it")
+	output := fs.String("o", "", "Write the export to this file instead of standard output")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 0 {
+		fs.Usage()
+		return fmt.Errorf("export takes no arguments")
+	}
+
+	cfg, err := loadConfig(opts)
+	if err != nil {
+		return err
+	}
+	store, err := openStore(cfg)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = store.Close() }()
+
+	service := app.NewExportService(store.Requests, slog.Default())
+	out, count, err := service.Export(context.Background(), *format, *folder)
+	if err != nil {
+		return err
+	}
+
+	if *output == "" {
+		fmt.Print(out)
+		return nil
+	}
+	if err := os.WriteFile(*output, []byte(out), 0600); err != nil {
+		return err
+	}
+	fmt.Printf("Exported %d requests to %s\n", count, *output)
+	return nil
+}
+
+// runFake implements `curly fake [-list] [-seed <n>] [file]`.
+func runFake(_ globalOptions, args []string) error {
+	fs := newFlagSet("fake [-list] [-seed <n>] [file]")
+	list := fs.Bool("list", false, "List the generators usable as {{fake.NAME}} placeholders")
+	seed := fs.Uint64("seed", 0, "Seed for reproducible values (random if 0)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *list {
+		for _, name := range faker.Generators() {
+			fmt.Printf("{{fake.%s}}\n", name)
+		}
+		return nil
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return fmt.Errorf("fake requires a JSON Schema file, or - for standard input")
+	}
+
+	data, err := readInput(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	var schema any
+	if err := json.Unmarshal(data, &schema); err != nil {
+		return fmt.Errorf("failed to parse schema: %w", err)
+	}
+
+	f := faker.NewRandom()
+	if *seed != 0 {
+		f = faker.New(*seed)
+	}
+	body, err := json.MarshalIndent(f.FromSchema(schema, schema), "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode body: %w", err)
+	}
+	fmt.Println(string(body))
+	return nil
+}
+
+// runHistory implements `curly history list|show|replay`.
+func runHistory(opts globalOptions, args []string) error {
+	if len(args) == 0 || (args[0] != "list" && args[0] != "show" && args[0] != "replay") {
+		newFlagSet("history list|show|replay [flags] [history-id]").Usage()
+		return fmt.Errorf("history requires list, show or replay")
+	}
+	switch args[0] {
+	case "list":
+		return runHistoryList(opts, args[1:])
+	case "show":
+		return runHistoryShow(opts, args[1:])
+	default:
+		return runReplay(opts, args[1:])
+	}
+}
+
+// runHistoryList implements `curly history list`, printing the most recent
+// entries that match the filters, newest first.
+func runHistoryList(opts globalOptions, args []string) error {
+	fs := newFlagSet("history list [-status <2xx|3xx|4xx|5xx|failed>] [-since <age>] [flags]")
+	status := fs.String("status", "", "Only entries with a status in this class (2xx to 5xx), or failed ones")
+	since := fs.String("since", "", "Only entries executed in this long, such as 30m, 24h or 7d, or since an RFC3339 time")
+	method := fs.String("method", "", "Only entries sent with this method")
+	urlContains := fs.String("url", "", "Only entries whose URL contains this text")
+	request := fs.String("request", "", "Only executions of this saved request, by name or ID")
+	limit := fs.Int("limit", 50, "Maximum number of entries to list")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 0 {
+		fs.Usage()
+		return fmt.Errorf("history list takes no arguments")
+	}
+	if *limit <= 0 {
+		return fmt.Errorf("-limit must be positive")
+	}
+
+	filter, err := historyFilter(*status, *since, *method, *urlContains)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := loadConfig(opts)
+	if err != nil {
+		return err
+	}
+	store, err := openStore(cfg)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = store.Close() }()
+
+	ctx := context.Background()
+	requestService := app.NewRequestService(store.Requests, http.NewClient(nil), store.History, slog.Default())
+	if *request != "" {
+		req, err := requestService.FindRequest(ctx, *request)
+		if err != nil {
+			return err
+		}
+		filter.RequestIDs = []string{req.ID}
+	}
+
+	entries, err := app.NewHistoryService(store.History, slog.Default()).SearchHistory(ctx, filter, *limit)
+	if err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		fmt.Println("No matching history entries.")
+		return nil
+	}
+
+	return printHistoryEntries(entries)
+}
+
+// historyFilter builds the filter for the history list flags.
+func historyFilter(status, since, method, urlContains string) (repository.HistoryFilter, error) {
+	filter := repository.HistoryFilter{
+		Method:      strings.ToUpper(method),
+		URLContains: urlContains,
+	}
+	if status != "" {
+		class, failed, err := parseStatusFilter(status)
+		if err != nil {
+			return filter, err
+		}
+		filter.StatusClass = class
+		filter.FailedOnly = failed
+	}
+	if since != "" {
+		after, err := parseSince(since, time.Now())
+		if err != nil {
+			return filter, err
+		}
+		filter.After = after
+	}
+	return filter, nil
+}
+
+// printHistoryEntries prints entries as a table.
+func printHistoryEntries(entries []*repository.HistoryEntry) error {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "ID\tEXECUTED\tSTATUS\tTIME\tMETHOD\tURL")
+	for _, entry := range entries {
+		method, url := "-", "-"
+		if req, err := entry.Request(); err == nil {
+			method, url = req.Method, req.URL
+		}
+		status := entry.Status
+		if entry.Error != "" {
+			status = "error"
+		}
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%dms\t%s\t%s\n",
+			entry.ID, entry.ExecutedAt, status, entry.ResponseTimeMs, method, url)
+	}
+	return w.Flush()
+}
+
+// parseStatusFilter reads a -status value: a class such as 5xx, or failed
+// for executions that did not succeed.
+func parseStatusFilter(value string) (class int, failed bool, err error) {
+	value = strings.ToLower(value)
+	if value == "failed" {
+		return 0, true, nil
+	}
+	if len(value) == 3 && strings.HasSuffix(value, "xx") && value[0] >= '1' && value[0] <= '5' {
+		return int(value[0] - '0'), false, nil
+	}
+	return 0, false, fmt.Errorf("invalid status %q: expected 1xx to 5xx, or failed", value)
+}
+
+// parseSince reads a -since value, an age such as 24h or 7d or an RFC3339
+// time, and returns the time it stands for in the format history is stored in.
+func parseSince(value string, now time.Time) (string, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t.Format(time.RFC3339), nil
+	}
+	var age time.Duration
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return "", fmt.Errorf("invalid -since %q: expected an age such as 24h or 7d, or an RFC3339 time", value)
+		}
+		age = time.Duration(n) * 24 * time.Hour
+	} else {
+		var err error
+		if age, err = time.ParseDuration(value); err != nil {
+			return "", fmt.Errorf("invalid -since %q: expected an age such as 24h or 7d, or an RFC3339 time", value)
+		}
+	}
+	if age <= 0 {
+		return "", fmt.Errorf("invalid -since %q: the age must be positive", value)
+	}
+	return now.Add(-age).Format(time.RFC3339), nil
+}
+
+// runHistoryShow implements `curly history show <history-id>`, printing an
+// entry in full: the request as it was sent and the response recorded.
+func runHistoryShow(opts globalOptions, args []string) error {
+	fs := newFlagSet("history show <history-id>")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return fmt.Errorf("history show requires a history entry ID")
+	}
+
+	cfg, err := loadConfig(opts)
+	if err != nil {
+		return err
+	}
+	store, err := openStore(cfg)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = store.Close() }()
+
+	ctx := context.Background()
+	entry, err := app.NewHistoryService(store.History, slog.Default()).GetEntry(ctx, fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	name := "(ad-hoc)"
+	if entry.RequestID != "" {
+		name = entry.RequestID
+		if req, err := store.Requests.FindByID(ctx, entry.RequestID); err == nil {
+			name = fmt.Sprintf("%s (%s)", req.Name, req.ID)
+		}
+	}
+
+	fmt.Printf("ID:        %s\n", entry.ID)
+	fmt.Printf("Executed:  %s\n", entry.ExecutedAt)
+	fmt.Printf("Request:   %s\n", name)
+	if entry.RunID != "" {
+		fmt.Printf("Run:       %s\n", entry.RunID)
+	}
+	if entry.ReplayOf != "" {
+		fmt.Printf("Replay of: %s\n", entry.ReplayOf)
+	}
+
+	fmt.Println()
+	printSentRequest(entry)
+	fmt.Println()
+	printRecordedResponse(entry)
+	return nil
+}
+
+// printSentRequest prints the request of a history entry as it was sent.
+func printSentRequest(entry *repository.HistoryEntry) {
+	sent, err := entry.Request()
+	if err != nil {
+		fmt.Println("(the request was recorded before requests were kept with history)")
+		return
+	}
+	fullURL, err := sent.FullURL()
+	if err != nil {
+		fullURL = sent.URL
+	}
+	fmt.Printf("%s %s\n", sent.Method, fullURL)
+	printHeaders(sent.Headers)
+	if sent.Body != "" {
+		fmt.Println()
+		fmt.Println(sent.Body)
+	}
+}
+
+// printRecordedResponse prints the response of a history entry, or the
+// error that stopped the request.
+func printRecordedResponse(entry *repository.HistoryEntry) {
+	if entry.Error != "" {
+		fmt.Printf("Error: %s\n", entry.Error)
+		return
+	}
+	resp := entry.Response()
+	fmt.Printf("%s (%dms)\n", resp.Status, resp.DurationMillis())
+	printHeaders(resp.Headers)
+	for _, failure := range resp.AssertionFailures {
+		fmt.Printf("assertion failed: %s\n", failure)
+	}
+	if resp.Body != "" {
+		fmt.Println()
+		fmt.Println(resp.Body)
+	}
+}
+
+// printHeaders prints headers sorted by name, one "Name: value" per line.
+func printHeaders(headers map[string]string) {
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Printf("%s: %s\n", name, headers[name])
+	}
+}
+
+// runImport implements `curly import openapi <file>`, `curly import postman <file>`,
+// `curly import curl <file>` and `curly import link <link>`. A file or link of
+// "-" reads from standard input.
+func runImport(opts globalOptions, args []string) error {
+	if len(args) == 0 || !slices.Contains([]string{"openapi", "postman", "curl", "link"}, args[0]) {
+		newFlagSet("import openapi|postman|curl|link [flags] <file>").Usage()
+		return fmt.Errorf("import requires a format: openapi, postman, curl or link")
+	}
+	format := args[0]
+
+	fs := newFlagSet("import " + format + " [flags] <file>")
+	folder := fs.String("folder", "", "Folder for the imported requests")
+	var (
+		baseURL   *string
+		contracts *bool
+		fake      *bool
+		scripts   *bool
+	)
+	if format == "openapi" {
+		baseURL = fs.String("base-url", "", "Base URL to use instead of the document's servers")
+		contracts = fs.Bool("contract", false, "Check every execution against the document's responses")
+		fake = fs.Bool("fake", false, "Fill JSON bodies with random data generated from their schemas")
+	}
+	if format == "link" {
+		scripts = fs.Bool("scripts", false, "Keep the scripts the link carries, which run whenever the request is sent")
+	}
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return fmt.Errorf("import %s requires a file, or - for standard input", format)
+	}
+
+	data, err := importInput(format, fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	cfg, err := loadConfig(opts)
+	if err != nil {
+		return err
+	}
+	store, err := openStore(cfg)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = store.Close() }()
+
+	ctx := context.Background()
+	service := app.NewImportService(store.Requests, slog.Default())
+
+	switch format {
+	case "curl":
+		return importCurl(ctx, service, string(data), *folder)
+	case "postman":
+		return importPostman(ctx, service, data, *folder)
+	case "link":
+		return importLink(ctx, service, string(data), *folder, *scripts, fs.Arg(0) != "-")
+	default:
+		importOpts := openapi.Options{
+			Folder:    *folder,
+			BaseURL:   *baseURL,
+			Contracts: *contracts,
+		}
+		if *fake {
+			importOpts.Fake = faker.NewRandom()
+		}
+		return importOpenAPI(ctx, service, data, importOpts)
+	}
+}
+
+// importInput reads the document to import from a file, or standard input
+// for "-". A share link is short enough to pass as the argument itself.
+func importInput(format, arg string) ([]byte, error) {
+	if format == "link" && arg != "-" {
+		return []byte(arg), nil
+	}
+	return readInput(arg)
+}
+
+// importCurl imports a curl command and prints its warnings.
+func importCurl(ctx context.Context, service *app.ImportService, command, folder string) error {
+	result, err := service.ImportCurl(ctx, command, folder)
+	if err != nil {
+		return err
+	}
+	for _, warning := range result.Warnings {
+		fmt.Fprintf(os.Stderr, "warning: %s\n", warning)
+	}
+	fmt.Printf("Imported %s\n", result.Request.Name)
+	return nil
+}
+
+// importPostman imports a Postman collection and lists its requests.
+func importPostman(ctx context.Context, service *app.ImportService, data []byte, folder string) error {
+	result, err := service.ImportPostman(ctx, data, folder)
+	if err != nil {
+		return err
+	}
+	for _, warning := range result.Warnings {
+		fmt.Fprintf(os.Stderr, "warning: %s\n", warning)
+	}
+	for _, req := range result.Requests {
+		fmt.Printf("%-7s %s\n", req.Method, req.URL)
+	}
+	fmt.Printf("Imported %d requests\n", len(result.Requests))
+	return nil
+}
+
+// importLink imports a share link, keeping its scripts only if confirmed,
+// and warns about the credentials the sender left out. ask is false when
+// the link was read from standard input.
+func importLink(ctx context.Context, service *app.ImportService, link, folder string, scripts, ask bool) error {
+	keepScripts, err := confirmLinkScripts(service, link, scripts, ask)
+	if err != nil {
+		return err
+	}
+	result, err := service.ImportLink(ctx, link, folder, keepScripts)
+	if err != nil {
+		return err
+	}
+	for _, stripped := range result.Stripped {
+		fmt.Fprintf(os.Stderr, "warning: the sender left out the %s; add it before sending the request\n", stripped)
+	}
+	fmt.Printf("Imported %s\n", result.Request.Name)
+	return nil
+}
+
+// importOpenAPI imports an OpenAPI document and lists its requests.
+func importOpenAPI(ctx context.Context, service *app.ImportService, data []byte, opts openapi.Options) error {
+	requests, err := service.ImportOpenAPI(ctx, data, opts)
+	if err != nil {
+		return err
+	}
+	for _, req := range requests {
+		fmt.Printf("%-7s %s\n", req.Method, req.URL)
+	}
+	fmt.Printf("Imported %d requests\n", len(requests))
+	return nil
+}
+
+// confirmLinkScripts decides whether the scripts a share link carries are
+// imported. They run whenever the request is sent and can read every
+// variable, so they are shown first and kept only with -scripts or once the
+// user agrees at the terminal. ask is false when the link itself was read
+// from standard input.
+func confirmLinkScripts(service *app.ImportService, link string, keep, ask bool) (bool, error) {
+	result, err := service.ParseLink(link)
+	if err != nil {
+		return false, err
+	}
+	scripts := result.Scripts()
+	if keep || len(scripts) == 0 {
+		return keep, nil
+	}
+
+	req := result.Request
+	for _, script := range []struct{ name, source string }{
+		{"pre-request script", req.PreRequestScript},
+		{"post-response script", req.PostResponseScript},
+	} {
+		if script.source != "" {
+			fmt.Fprintf(os.Stderr, "The link carries a %s:\n%s\n\n", script.name, indent(script.source))
+		}
+	}
+	if ask && isTerminal(os.Stdin) {
+		fmt.Fprint(os.Stderr, "Scripts run whenever the request is sent and can read your variables. Keep them? [y/N] ")
+		answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil {
+			fmt.Fprintln(os.Stderr)
+		}
+		if strings.EqualFold(strings.TrimSpace(answer), "y") {
+			return true, nil
+		}
+	}
+	fmt.Fprintf(os.Stderr, "warning: left out the %s; pass -scripts to keep them\n", strings.Join(scripts, " and "))
+	return false, nil
+}
+
+// indent indents each line of text by four spaces.
+func indent(text string) string {
+	return "    " + strings.ReplaceAll(strings.TrimRight(text, "\n"), "\n", "\n    ")
+}
+
+// stdinRead records that readInput consumed standard input, so a second "-"
+// is reported rather than read as empty.
+var stdinRead bool
+
+// readInput reads a file, or standard input when path is "-".
+func readInput(path string) ([]byte, error) {
+	var (
+		data []byte
+		err  error
+	)
+	if path == "-" {
+		if stdinRead {
+			return nil, fmt.Errorf("standard input can only be read once")
+		}
+		stdinRead = true
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(path) // #nosec G304 -- path is given by the user
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read input: %w", err)
+	}
+	return data, nil
+}
+
+// runRestore implements `curly restore <file>`.
+// The current database is backed up to the backup directory first so a bad restore can be undone.
+func runRestore(opts globalOptions, args []string) error {
+	fs := newFlagSet("restore <file>")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return fmt.Errorf("restore requires exactly one backup file")
+	}
+
+	src, err := filepath.Abs(fs.Arg(0))
+	if err != nil {
+		return fmt.Errorf("invalid backup file: %w", err)
+	}
+	if _, err := os.Stat(src); err != nil {
+		return fmt.Errorf("cannot read backup: %w", err)
+	}
+
+	cfg, err := loadConfig(opts)
+	if err != nil {
+		return err
+	}
+	db, err := openSQLite(cfg)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = db.Close() }()
+
+	ctx := context.Background()
+	safety, err := sqlite.RotateBackup(ctx, db, cfg.Database.BackupDir, cfg.Database.MaxBackups)
+	if err != nil {
+		return fmt.Errorf("failed to back up current database: %w", err)
+	}
+
+	if err := sqlite.Restore(ctx, db, src); err != nil {
+		return err
+	}
+
+	// Bring an older backup up to the current schema.
+	if err := sqlite.MigrateDB(db); err != nil {
+		return fmt.Errorf("failed to migrate restored database: %w", err)
+	}
+
+	fmt.Printf("Restored %s from %s (previous contents saved to %s)\n", cfg.Database.Path, src, safety)
+	return nil
+}
+
+// openSQLite opens the configured database for commands that only support the SQLite driver.
+func openSQLite(cfg *config.Config) (*sql.DB, error) {
+	if cfg.Database.Driver != "" && cfg.Database.Driver != storage.DriverSQLite {
+		return nil, fmt.Errorf("this command requires the %s driver, but database.driver is %q", storage.DriverSQLite, cfg.Database.Driver)
+	}
+
+	store, err := openStore(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return store.DB(), nil
+}
+
+// runSchema implements `curly schema [-clear] <request> [file]`.
+// With a file (or - for standard input) the schema is attached to the request;
+// with -clear it is removed; otherwise the current schema is printed.
+func runSchema(opts globalOptions, args []string) error {
+	fs := newFlagSet("schema [-clear] <request> [file]")
+	clearSchema := fs.Bool("clear", false, "Remove the request's response schema")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() < 1 || fs.NArg() > 2 || *clearSchema && fs.NArg() != 1 {
+		fs.Usage()
+		return fmt.Errorf("schema requires a request ID or name, and a schema file unless -clear is given")
+	}
+
+	cfg, err := loadConfig(opts)
+	if err != nil {
+		return err
+	}
+	store, err := openStore(cfg)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = store.Close() }()
+
+	ctx := context.Background()
+	requestService := app.NewRequestService(store.Requests, http.NewClient(nil), store.History, slog.Default())
+	setSaveHook(requestService, cfg)
+	req, err := requestService.FindRequest(ctx, fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case *clearSchema:
+		req.ResponseSchema = ""
+	case fs.NArg() == 2:
+		data, err := readInput(fs.Arg(1))
+		if err != nil {
+			return err
+		}
+		req.ResponseSchema = string(data)
+	default:
+		if req.ResponseSchema == "" {
+			fmt.Printf("%s has no response schema\n", req.Name)
+		} else {
+			fmt.Println(strings.TrimSpace(req.ResponseSchema))
+		}
+		return nil
+	}
+
+	if err := requestService.SaveRequest(ctx, req); err != nil {
+		return err
+	}
+	if *clearSchema {
+		fmt.Printf("Cleared the response schema of %s\n", req.Name)
+	} else {
+		fmt.Printf("Attached a response schema to %s\n", req.Name)
+	}
+	return nil
+}
+
+// runScript implements `curly script [-post] [-clear] <request> [file]`.
+// With a file (or - for standard input) the script is attached to the
+// request; with -clear it is removed; otherwise the current script is
+// printed. -post selects the post-response script.
+func runScript(opts globalOptions, args []string) error {
+	fs := newFlagSet("script [-post] [-clear] <request> [file]")
+	post := fs.Bool("post", false, "Use the post-response script rather than the pre-request script")
+	clearScript := fs.Bool("clear", false, "Remove the request's script")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() < 1 || fs.NArg() > 2 || *clearScript && fs.NArg() != 1 {
+		fs.Usage()
+		return fmt.Errorf("script requires a request ID or name, and a script file unless -clear is given")
+	}
+
+	cfg, err := loadConfig(opts)
+	if err != nil {
+		return err
+	}
+	store, err := openStore(cfg)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = store.Close() }()
+
+	ctx := context.Background()
+	requestService := app.NewRequestService(store.Requests, http.NewClient(nil), store.History, slog.Default())
+	setSaveHook(requestService, cfg)
+	req, err := requestService.FindRequest(ctx, fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	source, name := &req.PreRequestScript, script.PreRequest
+	if *post {
+		source, name = &req.PostResponseScript, script.PostResponse
+	}
+	switch {
+	case *clearScript:
+		*source = ""
+	case fs.NArg() == 2:
+		data, err := readInput(fs.Arg(1))
+		if err != nil {
+			return err
+		}
+		*source = string(data)
+	default:
+		printScript(req, *source, name)
+		return nil
+	}
+	return saveScript(ctx, requestService, req, name, *clearScript)
+}
+
+// printScript prints a request's script, named name, or says it has none.
+func printScript(req *domain.Request, source, name string) {
+	if source == "" {
+		fmt.Printf("%s has no %s\n", req.Name, name)
+		return
+	}
+	fmt.Println(strings.TrimSpace(source))
+}
+
+// saveScript saves a request whose script, named name, was attached or
+// cleared.
+func saveScript(ctx context.Context, requestService *app.RequestService, req *domain.Request, name string, cleared bool) error {
+	if err := requestService.SaveRequest(ctx, req); err != nil {
+		return err
+	}
+	if cleared {
+		fmt.Printf("Cleared the %s of %s\n", name, req.Name)
+	} else {
+		fmt.Printf("Attached a %s to %s\n", name, req.Name)
+	}
+	return nil
+}
+
+// runShare implements `curly share [-secrets] [-scripts] <request>`.
+// The request is given by ID, unique ID prefix, or name. The link goes to
+// standard output and the stripped credentials are listed on standard error,
+// so the link can be piped.
+func runShare(opts globalOptions, args []string) error {
+	fs := newFlagSet("share [-secrets] [-scripts] <request>")
+	secrets := fs.Bool("secrets", false, "Keep auth credentials, secret headers and query parameters in the link")
+	scripts := fs.Bool("scripts", false, "Keep the pre-request and post-response scripts in the link")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return fmt.Errorf("share requires a request ID or name")
+	}
+
+	cfg, err := loadConfig(opts)
+	if err != nil {
+		return err
+	}
+	store, err := openStore(cfg)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = store.Close() }()
+
+	requestService := app.NewRequestService(store.Requests, http.NewClient(nil), store.History, slog.Default())
+	req, err := requestService.FindRequest(context.Background(), fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	link, stripped, err := app.NewCodegenService(slog.Default()).ShareLink(req, sharelink.Options{IncludeSecrets: *secrets, IncludeScripts: *scripts})
+	if err != nil {
+		return err
+	}
+	for _, item := range stripped {
+		fmt.Fprintf(os.Stderr, "stripped %s\n", item)
+	}
+	fmt.Println(link)
+	return nil
+}
+
+// runStats implements `curly stats [-hosts] [-format table|json|prometheus]`.
+// The table shows requests, or hosts with -hosts; JSON and Prometheus
+// metrics cover both.
+func runStats(opts globalOptions, args []string) error {
+	fs := newFlagSet("stats [-hosts] [-format table|json|prometheus]")
+	byHost := fs.Bool("hosts", false, "show statistics per host instead of per request")
+	format := fs.String("format", "table", "output format: table, json or prometheus")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 0 {
+		fs.Usage()
+		return fmt.Errorf("stats takes no arguments")
+	}
+	switch *format {
+	case "table", "json", "prometheus":
+	default:
+		return fmt.Errorf("unknown format %q (available: table, json, prometheus)", *format)
+	}
+
+	cfg, err := loadConfig(opts)
+	if err != nil {
+		return err
+	}
+	store, err := openStore(cfg)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = store.Close() }()
+
+	// A table shows either requests or hosts; the other formats show both.
+	table := *format == "table"
+	report, err := statsReport(context.Background(), store, !table || !*byHost, !table || *byHost)
+	if err != nil {
+		return err
+	}
+
+	switch *format {
+	case "json":
+		return report.WriteJSON(os.Stdout)
+	case "prometheus":
+		return report.WritePrometheus(os.Stdout)
+	}
+	if len(report.Requests) == 0 && len(report.Hosts) == 0 {
+		fmt.Println("No history yet.")
+		return nil
+	}
+	return printStats(report, *byHost)
+}
+
+// statsReport gathers the statistics per request, per host, or both, with
+// the names of the requests that are still saved.
+func statsReport(ctx context.Context, store *storage.Store, requests, hosts bool) (*app.StatsReport, error) {
+	historyService := app.NewHistoryService(store.History, slog.Default())
+	report := &app.StatsReport{}
+	var err error
+	if requests {
+		if report.Requests, err = historyService.GetStats(ctx); err != nil {
+			return nil, err
+		}
+	}
+	if hosts {
+		if report.Hosts, err = historyService.GetHostStats(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	// Show request names where the request is still saved.
+	report.Names = map[string]string{"": "(ad-hoc)"}
+	saved, err := store.Requests.FindAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load requests: %w", err)
+	}
+	for _, req := range saved {
+		report.Names[req.ID] = req.Name
+	}
+	return report, nil
+}
+
+// printStats prints the statistics per host or per request as a table.
+func printStats(report *app.StatsReport, byHost bool) error {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	if byHost {
+		_, _ = fmt.Fprintln(w, "HOST\tRUNS\tSUCCESS\tP50\tP95\tP99\tLAST FAILURE")
+		for _, s := range report.Hosts {
+			host := s.Host
+			if host == "" {
+				host = "(unknown)"
+			}
+			_, _ = fmt.Fprintf(w, "%s\t%d\t%.1f%%\t%dms\t%dms\t%dms\t%s\n",
+				host, s.Count, s.SuccessRate()*100, s.P50Ms, s.P95Ms, s.P99Ms, lastFailure(s.LastFailureAt, s.LastFailure))
+		}
+		return w.Flush()
+	}
+
+	_, _ = fmt.Fprintln(w, "REQUEST\tRUNS\tSUCCESS\tP50\tP95\tP99\tLAST FAILURE")
+	for _, s := range report.Requests {
+		name, ok := report.Names[s.RequestID]
+		if !ok {
+			name = s.RequestID
+		}
+		_, _ = fmt.Fprintf(w, "%s\t%d\t%.1f%%\t%dms\t%dms\t%dms\t%s\n",
+			name, s.Count, s.SuccessRate()*100, s.P50Ms, s.P95Ms, s.P99Ms, lastFailure(s.LastFailureAt, s.LastFailure))
+	}
+	return w.Flush()
+}
+
+// lastFailure describes the most recent failure in a stats table.
+func lastFailure(at, failure string) string {
+	if at == "" {
+		return "-"
+	}
+	return fmt.Sprintf("%s (%s)", at, failure)
+}
+
+// runLoad implements `curly load [-c <workers>] [-n <requests>] [-d <duration>] [-env <name>] <request>`.
+// An interrupt stops the test early and reports what completed.
+func runLoad(opts globalOptions, args []string) error {
+	fs := newFlagSet("load [-c <workers>] [-n <requests>] [-d <duration>] [-env <name>] <request>")
+	workers := fs.Int("c", 10, "Number of concurrent workers")
+	iterations := fs.Int("n", 0, "Total number of requests to send (default 100 without -d)")
+	duration := fs.Duration("d", 0, "How long to send requests for, e.g. 30s")
+	env := fs.String("env", "", "Environment to resolve {{name}} references from (default the active one)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return fmt.Errorf("load requires a request ID or name")
+	}
+	if *iterations == 0 && *duration == 0 {
+		*iterations = 100
+	}
+
+	cfg, err := loadConfig(opts)
+	if err != nil {
+		return err
+	}
+	store, err := openStore(cfg)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = store.Close() }()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	httpClient := newHTTPClient(cfg)
+	requestService := app.NewRequestService(store.Requests, httpClient, store.History, slog.Default())
+	if err := useEnvironment(ctx, requestService, store.Environments, *env); err != nil {
+		return err
+	}
+	req, err := requestService.FindRequest(ctx, fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	loadService := app.NewLoadService(requestService, httpClient, store.History, slog.Default())
+	report, err := loadService.Run(ctx, req, app.LoadOptions{
+		Workers:    *workers,
+		Iterations: *iterations,
+		Duration:   *duration,
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s\n\n", req.Name)
+	fmt.Print(report.Format())
+	return nil
+}
+
+// runMock implements `curly mock [-collection <folder>] [-port <port>]`. It
+// serves each saved request in the folder, or every saved request, with its
+// last successful response until interrupted, logging each request it answers
+// to standard output and to history.
+func runMock(opts globalOptions, args []string) error {
+	fs := newFlagSet("mock [-collection <folder>] [-host <host>] [-port <port>]")
+	collection := fs.String("collection", "", "Serve the saved requests in this folder and the folders nested in it")
+	host := fs.String("host", "127.0.0.1", "Address to listen on")
+	port := fs.Int("port", 8081, "Port to listen on (0 picks a free one)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 0 || *port < 0 || *port > 65535 {
+		fs.Usage()
+		return fmt.Errorf("usage: curly mock [-collection <folder>] [-host <host>] [-port <port>]")
+	}
+
+	cfg, err := loadConfig(opts)
+	if err != nil {
+		return err
+	}
+	store, err := openStore(cfg)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = store.Close() }()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	mockService := app.NewMockService(store.Requests, store.History, slog.Default())
+	routes, missing, err := mockService.Routes(ctx, *collection)
+	if err != nil {
+		return err
+	}
+	for _, req := range missing {
+		fmt.Fprintf(os.Stderr, "skipping %s: no response recorded\n", req.Name)
+	}
+	if len(routes) == 0 {
+		return fmt.Errorf("no saved request has a recorded response; send them first")
+	}
+
+	listener, err := net.Listen("tcp", net.JoinHostPort(*host, strconv.Itoa(*port)))
+	if err != nil {
+		return fmt.Errorf("failed to listen: %w", err)
+	}
+
+	fmt.Printf("Mocking %d requests on http://%s (Ctrl+C to stop)\n", len(routes), listener.Addr())
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, route := range routes {
+		fmt.Fprintf(w, "  %s\t%s\t%d\t%s\n", route.Method, route.Path, route.Example.StatusCode, route.Request.Name)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	fmt.Println()
+
+	return mockService.Serve(ctx, listener, mockService.Handler(routes, os.Stdout))
+}
+
+// runReplay implements `curly replay <history-id>`. It prints the response
+// status and body, or only the values selected by -extract, and fails if the
+// request fails.
+func runReplay(opts globalOptions, args []string) error {
+	fs := newFlagSet("replay [-extract <path>] [-fail] <history-id>")
+	extract := fs.String("extract", "", "Print only the values at this JSONPath or jq path of the response body")
+	failOnError := fs.Bool("fail", false, "Fail on a 4xx or 5xx status")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return fmt.Errorf("replay requires a history entry ID")
+	}
+	if err := domain.ValidateExtractPath(*extract); err != nil {
+		return err
+	}
+
+	cfg, err := loadConfig(opts)
+	if err != nil {
+		return err
+	}
+	store, err := openStore(cfg)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = store.Close() }()
+
+	historyService := app.NewHistoryService(store.History, slog.Default())
+	historyService.SetReplayer(app.NewRequestService(store.Requests, newHTTPClient(cfg), store.History, slog.Default()))
+
+	resp, err := historyService.Replay(context.Background(), fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	if *extract != "" {
+		text, err := resp.ExtractText(*extract)
+		if err != nil {
+			return err
+		}
+		if text != "" {
+			fmt.Println(text)
+		}
+		return responseFailure(resp, *failOnError)
+	}
+
+	fmt.Printf("%s (%dms)\n", resp.Status, resp.DurationMillis())
+	for _, failure := range resp.AssertionFailures {
+		fmt.Printf("assertion failed: %s\n", failure)
+	}
+	if resp.Body != "" {
+		fmt.Println()
+		fmt.Println(resp.Body)
+	}
+	return responseFailure(resp, *failOnError)
+}
+
+// repeatedFlag collects the values of a flag that can be given more than
+// once, such as -H.
+type repeatedFlag []string
+
+func (r *repeatedFlag) String() string {
+	return strings.Join(*r, ", ")
+}
+
+func (r *repeatedFlag) Set(value string) error {
+	*r = append(*r, value)
+	return nil
+}
+
+// dataFlag collects request body parts from -d and --data-raw, in the order
+// given, like curl. Parts of -d starting with @ are read from a file, or from
+// standard input for @-; --data-raw parts are taken as they are.
+type dataFlag struct {
+	parts *[]string
+	raw   bool
+}
+
+func (d dataFlag) String() string {
+	if d.parts == nil {
+		return ""
+	}
+	return strings.Join(*d.parts, "&")
+}
+
+func (d dataFlag) Set(value string) error {
+	if path, ok := strings.CutPrefix(value, "@"); ok && !d.raw {
+		body, err := readInput(path)
+		if err != nil {
+			return err
+		}
+		value = string(body)
+	}
+	*d.parts = append(*d.parts, value)
+	return nil
+}
+
+// environmentVariables resolves variable references from a chosen
+// environment rather than the active one.
+type environmentVariables struct {
+	env *domain.Environment
+}
+
+func (e environmentVariables) ActiveVariables(context.Context) (map[string]string, error) {
+	return e.env.Variables, nil
+}
+
+// Formats of commands that print an execution result.
+const (
+	formatText = "text"
+	formatJSON = "json"
+)
+
+// parseFormat checks a -format flag and reports whether it asks for JSON.
+func parseFormat(format string) (bool, error) {
+	switch format {
+	case formatText:
+		return false, nil
+	case formatJSON:
+		return true, nil
+	default:
+		return false, fmt.Errorf("unsupported format %q (supported: %s, %s)", format, formatText, formatJSON)
+	}
+}
+
+// parseInterspersed parses args like fs.Parse, but also accepts flags after
+// the positional arguments, as curl does. It returns the positional arguments.
+func parseInterspersed(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+// sendOptions are the flags of `curly send` that shape the request.
+type sendOptions struct {
+	method  string
+	headers []string
+	data    []string
+	form    []string
+	user    string
+	bearer  string
+}
+
+// runSend implements `curly send [flags] <url>`. It accepts the common curl
+// flags, before or after the URL, so curl can be replaced by curly in a
+// command line. It prints the response body, with the status on standard
+// error so the body can be piped, and records the execution to history
+// without saving a request. {{name}} references are resolved from the active
+// environment or the one given with -env. An interrupt cancels the request.
+func runSend(opts globalOptions, args []string) error {
+	fs := newFlagSet("send [-X <method>] [-H <header>]... [-d <body>] [-u <user:password>] [flags] <url>")
+	var send sendOptions
+	fs.StringVar(&send.method, "X", "", "HTTP method (default GET, or POST with a body)")
+	fs.Var((*repeatedFlag)(&send.headers), "H", "Header to send as \"Name: value\" (repeatable)")
+	fs.Var(dataFlag{parts: &send.data}, "d", "Request body; @file reads it from a file and @- from standard input (repeatable, joined with &)")
+	fs.Var(dataFlag{parts: &send.data}, "data", "Same as -d")
+	fs.Var(dataFlag{parts: &send.data, raw: true}, "data-raw", "Request body, taken as it is even if it starts with @ (repeatable)")
+	fs.Var((*repeatedFlag)(&send.form), "F", "Multipart form field as name=value, name=@file to upload a file or name=<file for a file's contents (repeatable)")
+	fs.StringVar(&send.user, "u", "", "Basic auth credentials as user:password")
+	fs.StringVar(&send.bearer, "bearer", "", "Bearer token to authenticate with")
+	var transport transportOptions
+	fs.BoolVar(&transport.insecure, "k", false, "Skip TLS certificate verification")
+	fs.BoolVar(&transport.followRedirects, "L", false, "Follow redirects, even if the configuration turns them off")
+	fs.Float64Var(&transport.maxTime, "max-time", 0, "Give up on the request after this many seconds (default the configured timeout)")
+	env := fs.String("env", "", "Environment to resolve {{name}} references from (default the active one)")
+	var out sendOutput
+	fs.BoolVar(&out.include, "i", false, "Print the status line and response headers before the body")
+	fs.StringVar(&out.file, "o", "", "Write the response body to this file instead of standard output")
+	fs.StringVar(&out.extract, "extract", "", "Print only the values at this JSONPath or jq path of the response body")
+	failOnError := fs.Bool("f", false, "Fail on a 4xx or 5xx status")
+	fs.BoolVar(failOnError, "fail", false, "Same as -f")
+	format := fs.String("format", formatText, "Output format: text, or json for the whole result as one JSON document")
+	positional, err := parseInterspersed(fs, args)
+	if err != nil {
+		return err
+	}
+	if out.structured, err = parseFormat(*format); err != nil {
+		return err
+	}
+	if len(positional) != 1 {
+		fs.Usage()
+		return fmt.Errorf("send requires a URL")
+	}
+	if err := out.check(); err != nil {
+		return err
+	}
+	if transport.maxTime < 0 {
+		return fmt.Errorf("-max-time must be positive")
+	}
+
+	req, err := sendRequest(positional[0], send)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := loadConfig(opts)
+	if err != nil {
+		return err
+	}
+	transport.apply(cfg)
+	store, err := openStore(cfg)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = store.Close() }()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	requestService := app.NewRequestService(store.Requests, newHTTPClient(cfg), store.History, slog.Default())
+	if err := useEnvironment(ctx, requestService, store.Environments, *env); err != nil {
+		return err
+	}
+
+	resp, err := requestService.ExecuteAdHoc(ctx, req)
+	if out.structured {
+		result := app.ExecutionResult{Request: req, Response: resp, Err: err}
+		if err := result.WriteJSON(os.Stdout); err != nil {
+			return err
+		}
+	}
+	if err != nil {
+		return err
+	}
+	if err := out.print(resp); err != nil {
+		return err
+	}
+
+	return responseFailure(resp, *failOnError)
+}
+
+// transportOptions are the flags of `curly send` that override the HTTP
+// configuration.
+type transportOptions struct {
+	insecure        bool
+	followRedirects bool
+	maxTime         float64
+}
+
+// apply overrides the HTTP configuration of cfg with the flags given.
+func (t transportOptions) apply(cfg *config.Config) {
+	if t.insecure {
+		cfg.HTTP.InsecureSkipTLS = true
+	}
+	if t.followRedirects {
+		cfg.HTTP.FollowRedirects = true
+	}
+	if t.maxTime > 0 {
+		cfg.HTTP.Timeout = time.Duration(t.maxTime * float64(time.Second))
+	}
+}
+
+// useEnvironment resolves the {{name}} references of requestService's
+// requests from the environment with the given name, or from the active
+// environment if name is empty.
+func useEnvironment(ctx context.Context, requestService *app.RequestService, environments repository.EnvironmentRepository, name string) error {
+	environmentService := app.NewEnvironmentService(environments, slog.Default())
+	if name == "" {
+		requestService.SetVariables(environmentService)
+		return nil
+	}
+	environment, err := environmentService.Find(ctx, name)
+	if err != nil {
+		return err
+	}
+	requestService.SetVariables(environmentVariables{env: environment})
+	return nil
+}
+
+// sendOutput are the flags of `curly send` that shape what it prints.
+type sendOutput struct {
+	include    bool
+	file       string
+	extract    string
+	structured bool
+}
+
+// check reports flags that cannot be used together.
+func (o sendOutput) check() error {
+	if o.structured && (o.extract != "" || o.include) {
+		return fmt.Errorf("-format json cannot be combined with -extract or -i")
+	}
+	if err := domain.ValidateExtractPath(o.extract); err != nil {
+		return err
+	}
+	if o.extract != "" && o.file != "" {
+		return fmt.Errorf("-extract and -o cannot be used together")
+	}
+	return nil
+}
+
+// print prints resp as the flags ask, writing its body to a file with -o.
+// A structured result is printed already, so only its body is written.
+func (o sendOutput) print(resp *domain.Response) error {
+	switch {
+	case o.structured:
+		if o.file != "" {
+			return o.writeBody(resp)
+		}
+	case o.extract != "":
+		text, err := resp.ExtractText(o.extract)
+		if err != nil {
+			return err
+		}
+		if text != "" {
+			fmt.Println(text)
+		}
+	case o.file != "":
+		printStatus(resp, o.include)
+		return o.writeBody(resp)
+	default:
+		printResponse(resp, o.include)
+	}
+	return nil
+}
+
+// writeBody writes the body of resp to the -o file.
+func (o sendOutput) writeBody(resp *domain.Response) error {
+	// #nosec G306 -- like curl -o, the file is readable by others
+	return os.WriteFile(o.file, []byte(resp.Body), 0644)
+}
+
+// sendRequest builds the request of `curly send` from its flags. A body from
+// -d or --data-raw is sent as a URL-encoded form unless a Content-Type header
+// is given, and -F fields as a multipart form, as curl sends them.
+func sendRequest(rawURL string, send sendOptions) (*domain.Request, error) {
+	if len(send.data) > 0 && len(send.form) > 0 {
+		return nil, fmt.Errorf("-d and -F cannot be used together")
+	}
+	method := send.method
+	if method == "" {
+		method = domain.MethodGet
+		if len(send.data) > 0 || len(send.form) > 0 {
+			method = domain.MethodPost
+		}
+	}
+	req := domain.NewRequestWithMethodAndURL(strings.ToUpper(method), rawURL)
+	req.Name = req.Method + " " + rawURL
+
+	for _, header := range send.headers {
+		name, value, ok := strings.Cut(header, ":")
+		if !ok {
+			return nil, fmt.Errorf("invalid header %q: expected \"Name: value\"", header)
+		}
+		// Assigned directly because SetHeader drops empty values.
+		req.Headers[strings.TrimSpace(name)] = strings.TrimSpace(value)
+	}
+
+	if err := send.setBody(req); err != nil {
+		return nil, err
+	}
+	if err := send.setAuth(req); err != nil {
+		return nil, err
+	}
+
+	if err := req.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid request: %w", err)
+	}
+	return req, nil
+}
+
+// setBody sets the -d data, or the -F fields, as the request body with a
+// Content-Type unless a -H header gave one.
+func (o sendOptions) setBody(req *domain.Request) error {
+	switch {
+	case len(o.data) > 0:
+		req.Body = strings.Join(o.data, "&")
+		defaultHeader(req, "Content-Type", "application/x-www-form-urlencoded")
+	case len(o.form) > 0:
+		body, contentType, err := multipartForm(o.form)
+		if err != nil {
+			return err
+		}
+		req.Body = body
+		defaultHeader(req, "Content-Type", contentType)
+	}
+	return nil
+}
+
+// setAuth sets the -u or -bearer credentials as the request's auth.
+func (o sendOptions) setAuth(req *domain.Request) error {
+	switch {
+	case o.user != "" && o.bearer != "":
+		return fmt.Errorf("-u and -bearer cannot be used together")
+	case o.user != "":
+		username, password, _ := strings.Cut(o.user, ":")
+		req.SetAuth(domain.NewBasicAuth(username, password))
+	case o.bearer != "":
+		req.SetAuth(domain.NewBearerAuth(o.bearer))
+	}
+	return nil
+}
+
+// multipartForm encodes -F fields as a multipart form body and returns it
+// with its Content-Type. A value of @file uploads the file and <file sends
+// the file's contents as a plain field.
+func multipartForm(fields []string) (string, string, error) {
+	var body strings.Builder
+	writer := multipart.NewWriter(&body)
+	for _, field := range fields {
+		name, value, ok := strings.Cut(field, "=")
+		if !ok || name == "" {
+			return "", "", fmt.Errorf("invalid form field %q: expected name=value", field)
+		}
+
+		switch {
+		case strings.HasPrefix(value, "@"):
+			path := value[1:]
+			data, err := readInput(path)
+			if err != nil {
+				return "", "", err
+			}
+			contentType := mime.TypeByExtension(filepath.Ext(path))
+			if contentType == "" {
+				contentType = "application/octet-stream"
+			}
+			header := textproto.MIMEHeader{}
+			header.Set("Content-Disposition", fmt.Sprintf("form-data; name=%q; filename=%q", name, filepath.Base(path)))
+			header.Set("Content-Type", contentType)
+			part, err := writer.CreatePart(header)
+			if err != nil {
+				return "", "", err
+			}
+			if _, err := part.Write(data); err != nil {
+				return "", "", err
+			}
+		case strings.HasPrefix(value, "<"):
+			data, err := readInput(value[1:])
+			if err != nil {
+				return "", "", err
+			}
+			if err := writer.WriteField(name, string(data)); err != nil {
+				return "", "", err
+			}
+		default:
+			if err := writer.WriteField(name, value); err != nil {
+				return "", "", err
+			}
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return "", "", err
+	}
+	return body.String(), writer.FormDataContentType(), nil
+}
+
+// defaultHeader sets a header unless the request already has it, in any case.
+func defaultHeader(req *domain.Request, name, value string) {
+	for existing := range req.Headers {
+		if strings.EqualFold(existing, name) {
+			return
+		}
+	}
+	req.Headers[name] = value
+}
+
+// printResponse prints the response body, preceded by the status line and
+// headers when include is set. Otherwise the status goes to standard error.
+func printResponse(resp *domain.Response, include bool) {
+	printStatus(resp, include)
+
+	fmt.Print(resp.Body)
+	// Text gets a final newline on a terminal, so the prompt starts on a line
+	// of its own. Redirected or piped, the body is written untouched.
+	if isTerminal(os.Stdout) && resp.Body != "" && !strings.HasSuffix(resp.Body, "\n") && utf8.ValidString(resp.Body) {
+		fmt.Println()
+	}
+}
+
+// isTerminal reports whether f is a terminal rather than a file or pipe.
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// printStatus prints the status line and headers when include is set, and
+// otherwise the status and duration to standard error; then any assertion
+// failures, to standard error.
+func printStatus(resp *domain.Response, include bool) {
+	if include {
+		fmt.Println(resp.Status)
+		printHeaders(resp.Headers)
+		fmt.Println()
+	} else {
+		fmt.Fprintf(os.Stderr, "%s (%dms)\n", resp.Status, resp.DurationMillis())
+	}
+	for _, failure := range resp.AssertionFailures {
+		fmt.Fprintf(os.Stderr, "assertion failed: %s\n", failure)
+	}
+}
+
+// runRun implements `curly run [folder]`, `curly run <request>` and
+// `curly run -id <request-id>`. An argument naming a folder runs the folder;
+// otherwise it names a saved request, by name or ID. -collection names a
+// folder explicitly. {{name}} references are resolved from the active
+// environment. It fails if any request fails.
+//
+// A folder run can also write a JUnit XML report to a file (-report) and print
+// a JSON summary in place of the table (-json), for CI pipelines.
+func runRun(opts globalOptions, args []string) error {
+	fs := newFlagSet("run [flags] [folder | request] | run [flags] -id <request-id>")
+	extract := fs.String("extract", "", "Also print the values at this JSONPath or jq path of each response body")
+	id := fs.String("id", "", "Run the saved request with this ID (or a unique prefix of it)")
+	collection := fs.String("collection", "", "Run the saved requests in this folder")
+	reportPath := fs.String("report", "", "Write a JUnit XML report of the folder run to this file")
+	jsonOutput := fs.Bool("json", false, "Print a JSON summary of the folder run in place of the table")
+	format := fs.String("format", formatText, "Output format: text, or json for the whole result as one JSON document")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	structured, err := parseFormat(*format)
+	if err != nil {
+		return err
+	}
+	if fs.NArg()+boolCount(*id != "", *collection != "") > 1 {
+		fs.Usage()
+		return fmt.Errorf("run takes one folder or request")
+	}
+	if err := checkRunExtract(*extract, *jsonOutput || structured); err != nil {
+		return err
+	}
+	reporting := *reportPath != "" || *jsonOutput
+
+	cfg, err := loadConfig(opts)
+	if err != nil {
+		return err
+	}
+	store, err := openStore(cfg)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = store.Close() }()
+
+	ctx := context.Background()
+	requestService := app.NewRequestService(store.Requests, newHTTPClient(cfg), store.History, slog.Default())
+	requestService.SetVariables(app.NewEnvironmentService(store.Environments, slog.Default()))
+
+	folder := *collection
+	if fs.NArg() == 1 {
+		folder = fs.Arg(0)
+	}
+	req, err := findRunTarget(ctx, requestService, *id, folder, fs.NArg() == 1, reporting)
+	if err != nil {
+		return err
+	}
+	if req != nil {
+		return runRequest(ctx, requestService, req, *extract, structured)
+	}
+
+	runner := app.NewRunnerService(requestService, slog.Default())
+	runner.SetLatencyService(app.NewLatencyService(store.History, slog.Default()))
+	report, err := runner.Run(ctx, folder)
+	if err != nil {
+		return err
+	}
+	return printRunReport(report, *reportPath, *jsonOutput || structured, *extract)
+}
+
+// checkRunExtract checks the -extract path of `curly run`, which cannot be
+// combined with JSON output.
+func checkRunExtract(extract string, jsonOutput bool) error {
+	if jsonOutput && extract != "" {
+		return fmt.Errorf("-json and -format json cannot be combined with -extract")
+	}
+	return domain.ValidateExtractPath(extract)
+}
+
+// boolCount returns how many of values are true.
+func boolCount(values ...bool) int {
+	count := 0
+	for _, value := range values {
+		if value {
+			count++
+		}
+	}
+	return count
+}
+
+// findRunTarget returns the saved request `curly run` runs: the one with the
+// given ID, or the one named by the argument if no folder has that name. It
+// returns nil when a folder is run. reporting is set when a report of a folder
+// run is asked for, which a single request cannot give.
+func findRunTarget(ctx context.Context, requestService *app.RequestService, id, folder string, named, reporting bool) (*domain.Request, error) {
+	if id != "" {
+		if reporting {
+			return nil, fmt.Errorf("-report and -json apply to folder runs")
+		}
+		req, err := requestService.FindRequest(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if !strings.HasPrefix(req.ID, id) {
+			// Matched by name rather than ID.
+			return nil, fmt.Errorf("request %q: %w", id, repository.ErrNotFound)
+		}
+		return req, nil
+	}
+
+	folders, err := requestService.ListFolders(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !named || slices.Contains(folders, folder) {
+		return nil, nil
+	}
+	if reporting {
+		return nil, fmt.Errorf("no folder named %q: -report and -json apply to folder runs", folder)
+	}
+	req, err := requestService.FindRequest(ctx, folder)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, fmt.Errorf("no folder or saved request named %q", folder)
+	}
+	return req, err
+}
+
+// printRunReport writes the JUnit report of a folder run to reportPath, if
+// set, and prints the report as JSON or as a table followed by the values at
+// extract.
+func printRunReport(report *app.RunReport, reportPath string, jsonOutput bool, extract string) error {
+	if reportPath != "" {
+		if err := writeJUnitReport(report, reportPath); err != nil {
+			return err
+		}
+	}
+	if jsonOutput {
+		if err := report.WriteJSON(os.Stdout); err != nil {
+			return err
+		}
+		return runFailed(report)
+	}
+	if extract != "" {
+		printExtracts(report, extract)
+	}
+	return printReport(report)
+}
+
+// writeJUnitReport writes the JUnit XML report of a run to path.
+func writeJUnitReport(report *app.RunReport, path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := report.WriteJUnit(f); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// runRequest executes a saved request and prints its response like
+// `curly send`, only the values at extract if it is set, or the whole result
+// as JSON if jsonOutput is set. It fails if the request fails: an error, a
+// status other than 2xx or 3xx, or a failed assertion.
+func runRequest(ctx context.Context, requestService *app.RequestService, req *domain.Request, extract string, jsonOutput bool) error {
+	resp, err := requestService.ExecuteAndSave(ctx, req)
+	result := app.ExecutionResult{Request: req, Response: resp, Err: err}
+	if jsonOutput {
+		if err := result.WriteJSON(os.Stdout); err != nil {
+			return err
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case jsonOutput:
+	case extract != "":
+		text, err := resp.ExtractText(extract)
+		if err != nil {
+			return err
+		}
+		if text != "" {
+			fmt.Println(text)
+		}
+	default:
+		printResponse(resp, false)
+	}
+
+	switch code := resultExitCode(result); {
+	case code == 0:
+		return nil
+	case code == exitAssertion:
+		return &exitCodeError{code: code, err: fmt.Errorf("%s failed %d assertions", req.Name, len(resp.AssertionFailures))}
+	default:
+		return &exitCodeError{code: code, err: fmt.Errorf("%s failed: %s", req.Name, resp.Status)}
+	}
+}
+
+// printExtracts prints the values at path in each response of a run, under
+// the request's name.
+func printExtracts(report *app.RunReport, path string) {
+	for _, result := range report.Results {
+		if result.Response == nil {
+			continue
+		}
+		text, err := result.Response.ExtractText(path)
+		if err != nil {
+			text = err.Error()
+		}
+		fmt.Printf("%s:\n", result.Request.Name)
+		for _, line := range strings.Split(text, "\n") {
+			fmt.Printf("  %s\n", line)
+		}
+	}
+	fmt.Println()
+}
+
+// printReport prints one line per executed request, the assertion failures
+// of each request that had any, any latency regressions, then a summary. It
+// fails if any request failed; latency regressions are only warnings.
+func printReport(report *app.RunReport) error {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, result := range report.Results {
+		outcome := "PASS"
+		if !result.Passed() {
+			outcome = "FAIL"
+		}
+		detail := "-"
+		switch {
+		case result.Err != nil:
+			detail = result.Err.Error()
+		case result.Response != nil:
+			detail = fmt.Sprintf("%d (%dms)", result.Response.StatusCode, result.Response.DurationMillis())
+			if n := len(result.Response.AssertionFailures); n > 0 {
+				detail += fmt.Sprintf(", %d assertion failures", n)
+			}
+		}
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", outcome, result.Request.Method, result.Request.Name, detail)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	for _, result := range report.Results {
+		if result.Response == nil || len(result.Response.AssertionFailures) == 0 {
+			continue
+		}
+		fmt.Printf("\n%s:\n", result.Request.Name)
+		for _, failure := range result.Response.AssertionFailures {
+			fmt.Printf("  %s\n", failure)
+		}
+	}
+
+	if len(report.Regressions) > 0 {
+		names := make(map[string]string, len(report.Results))
+		for _, result := range report.Results {
+			names[result.Request.ID] = result.Request.Name
+		}
+		fmt.Println("\nLatency regressions:")
+		for _, regression := range report.Regressions {
+			fmt.Printf("  %s: %s\n", names[regression.RequestID], regression)
+		}
+	}
+
+	fmt.Printf("\n%d passed, %d failed in %s (run %s)\n",
+		report.Passed(), report.Failed(), report.Duration.Round(time.Millisecond), report.RunID)
+	return runFailed(report)
+}
+
+// runFailed returns an error if any request of a run failed. Its exit code is
+// that of the most serious failure: a transport error, then a failed
+// assertion, then an error status.
+func runFailed(report *app.RunReport) error {
+	if report.Failed() == 0 {
+		return nil
+	}
+	code := 0
+	for _, result := range report.Results {
+		if c := resultExitCode(result); c != 0 && (code == 0 || exitCodeRank(c) < exitCodeRank(code)) {
+			code = c
+		}
+	}
+	return &exitCodeError{code: code, err: fmt.Errorf("%d of %d requests failed", report.Failed(), len(report.Results))}
+}
+
+// exitCodeError is an error that sets the status curly exits with.
+type exitCodeError struct {
+	code int
+	err  error
+}
+
+func (e *exitCodeError) Error() string { return e.err.Error() }
+
+func (e *exitCodeError) Unwrap() error { return e.err }
+
+// exitCode returns the status curly exits with after a command fails with
+// err: 0 if only the usage was asked for with -h, the code of an
+// exitCodeError, exitTransport if a request could not be sent or got no
+// response, and exitError otherwise.
+func exitCode(err error) int {
+	var coded *exitCodeError
+	switch {
+	case errors.Is(err, flag.ErrHelp):
+		return 0
+	case errors.As(err, &coded):
+		return coded.code
+	case errors.Is(err, app.ErrExecutionFailed):
+		return exitTransport
+	default:
+		return exitError
+	}
+}
+
+// exitCodeRank orders exit codes from the most serious failure.
+func exitCodeRank(code int) int {
+	return slices.Index([]int{exitTransport, exitAssertion, exitHTTPStatus, exitError}, code)
+}
+
+// resultExitCode returns the exit code for an execution of a saved request,
+// which fails on an error, a failed assertion, or a status other than 2xx or
+// 3xx; it returns 0 if the execution passed.
+func resultExitCode(result app.ExecutionResult) int {
+	switch {
+	case result.Passed():
+		return 0
+	case result.Err != nil:
+		return exitCode(result.Err)
+	case result.Response == nil:
+		return exitError
+	case len(result.Response.AssertionFailures) > 0:
+		return exitAssertion
+	default:
+		return exitHTTPStatus
+	}
+}
+
+// responseFailure returns an error if resp failed its assertions or, when
+// failOnStatus is set, has a 4xx or 5xx status, with the exit code for it.
+func responseFailure(resp *domain.Response, failOnStatus bool) error {
+	switch {
+	case len(resp.AssertionFailures) > 0:
+		return &exitCodeError{code: exitAssertion, err: fmt.Errorf("%d assertions failed", len(resp.AssertionFailures))}
+	case failOnStatus && resp.StatusCode >= 400:
+		return &exitCodeError{code: exitHTTPStatus, err: fmt.Errorf("request failed: %s", resp.Status)}
+	default:
+		return nil
+	}
+}
+
+// runSync implements `curly sync export [-include-secrets] <dir>` and
+// `curly sync import [-prune] <dir>`.
+func runSync(opts globalOptions, args []string) error {
+	if len(args) == 0 || (args[0] != "export" && args[0] != "import") {
+		newFlagSet("sync export|import [flags] <dir>").Usage()
+		return fmt.Errorf("sync requires export or import and a directory")
+	}
+	mode := args[0]
+
+	fs := newFlagSet("sync " + mode + " [flags] <dir>")
+	var includeSecrets, prune *bool
+	if mode == "export" {
+		includeSecrets = fs.Bool("include-secrets", false, "Write auth credentials, secret headers and query parameters, and secret variables instead of "+domain.SecretMask)
+	} else {
+		prune = fs.Bool("prune", false, "Delete saved requests and environments whose files are not in the directory")
+	}
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return fmt.Errorf("sync %s requires a directory", mode)
+	}
+	dir := fs.Arg(0)
+
+	cfg, err := loadConfig(opts)
+	if err != nil {
+		return err
+	}
+	store, err := openStore(cfg)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = store.Close() }()
+
+	ctx := context.Background()
+	service := app.NewSyncService(store.Requests, store.Environments, slog.Default())
+
+	if mode == "export" {
+		changed, err := service.Export(ctx, dir, *includeSecrets)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Exported requests and environments to %s (%d files changed)\n", dir, changed)
+		return nil
+	}
+
+	result, err := service.Import(ctx, dir, *prune)
+	if err != nil {
+		return err
+	}
+	printImportResult(dir, result, *prune)
+	return nil
+}
+
+// printImportResult prints what `curly sync import` changed, and warns about
+// the secrets left to fill in.
+func printImportResult(dir string, result *app.ImportResult, prune bool) {
+	fmt.Printf("Imported requests from %s: %d created, %d updated, %d unchanged",
+		dir, result.Created, result.Updated, result.Unchanged)
+	if prune {
+		fmt.Printf(", %d deleted", result.Deleted)
+	}
+	fmt.Println()
+	if envs := result.EnvironmentsCreated + result.EnvironmentsUpdated + result.EnvironmentsUnchanged + result.EnvironmentsDeleted; envs > 0 {
+		fmt.Printf("Imported environments: %d created, %d updated, %d unchanged",
+			result.EnvironmentsCreated, result.EnvironmentsUpdated, result.EnvironmentsUnchanged)
+		if prune {
+			fmt.Printf(", %d deleted", result.EnvironmentsDeleted)
+		}
+		fmt.Println()
+	}
+	for _, name := range result.Redacted {
+		fmt.Fprintf(os.Stderr, "warning: %q has redacted secrets (%s) to fill in\n", name, domain.SecretMask)
+	}
+	for _, name := range result.RedactedEnvironments {
+		fmt.Fprintf(os.Stderr, "warning: environment %q has redacted secret variables (%s) to fill in\n", name, domain.SecretMask)
+	}
+}
+
+// runUpgrade implements `curly upgrade -check`.
+func runUpgrade(_ globalOptions, args []string) error {
+	fs := newFlagSet("upgrade -check")
+	check := fs.Bool("check", false, "Check for a newer release and say how to get it")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 0 {
+		fs.Usage()
+		return fmt.Errorf("upgrade takes no arguments")
+	}
+	if !*check {
+		fs.Usage()
+		return fmt.Errorf("curly cannot replace itself; use -check to find out whether a newer release is out")
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	status, err := newUpdateService().Check(ctx)
+	if err != nil {
+		return err
+	}
+
+	latest := status.Latest
+	fmt.Printf("Current version: %s\n", status.Current)
+	fmt.Printf("Latest release:  %s", latest.Version)
+	if !latest.PublishedAt.IsZero() {
+		fmt.Printf(" (published %s)", latest.PublishedAt.Format(time.DateOnly))
+	}
+	fmt.Println()
+	switch {
+	case status.Available:
+		fmt.Printf("\nA newer release is out: %s\n", latest.URL)
+		fmt.Println("Download it from there, or run: go install github.com/williajm/curly/cmd/curly@latest")
+	case !release.IsRelease(status.Current):
+		fmt.Println("\nThis is a development build, so it cannot be compared with releases.")
+	default:
+		fmt.Println("\ncurly is up to date.")
+	}
+	return nil
+}
+
+// runWorkspaces implements `curly workspaces`.
+func runWorkspaces(opts globalOptions, args []string) error {
+	fs := newFlagSet("workspaces")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 0 {
+		fs.Usage()
+		return fmt.Errorf("workspaces takes no arguments")
+	}
+
+	cfg, workspaces, err := loadWorkspaceConfig(opts)
+	if err != nil {
+		return err
+	}
+
+	service := app.NewWorkspaceService(workspaces, cfg.Workspace, slog.Default())
+	names, err := service.List()
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		marker := " "
+		if name == service.Current() {
+			marker = "*"
+		}
+		fmt.Printf("%s %s\n", marker, name)
+	}
+	return nil
+}
@@ -0,0 +1,418 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/williajm/curly/internal/app"
+	"github.com/williajm/curly/internal/domain"
+)
+
+// transportErr is an error as returned for a request that got no response.
+var transportErr = fmt.Errorf("%w: dial tcp: connection refused", app.ErrExecutionFailed)
+
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"usage asked for", flag.ErrHelp, 0},
+		{"usage asked for, wrapped", fmt.Errorf("env list: %w", flag.ErrHelp), 0},
+		{"transport", transportErr, exitTransport},
+		{"status", &exitCodeError{code: exitHTTPStatus, err: errors.New("request failed: 500")}, exitHTTPStatus},
+		{"assertion", &exitCodeError{code: exitAssertion, err: errors.New("1 assertions failed")}, exitAssertion},
+		{"coded, wrapped", fmt.Errorf("replay: %w", &exitCodeError{code: exitAssertion, err: errors.New("failed")}), exitAssertion},
+		{"other", errors.New("unknown command"), exitError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, exitCode(tt.err))
+		})
+	}
+}
+
+// response returns a response of status code with the assertion failures given.
+func response(code int, failures ...string) *domain.Response {
+	resp := domain.NewResponse()
+	resp.StatusCode = code
+	resp.Status = fmt.Sprint(code)
+	resp.AssertionFailures = failures
+	return resp
+}
+
+// result returns the result of an execution that got response(code, failures...).
+func result(code int, failures ...string) app.ExecutionResult {
+	return app.ExecutionResult{Response: response(code, failures...)}
+}
+
+func TestRunFailed(t *testing.T) {
+	tests := []struct {
+		name    string
+		results []app.ExecutionResult
+		want    int
+		wantErr string
+	}{
+		{"all passed", []app.ExecutionResult{result(200), result(302)}, 0, ""},
+		{"status", []app.ExecutionResult{result(200), result(404)}, exitHTTPStatus, "1 of 2 requests failed"},
+		{"assertion", []app.ExecutionResult{result(200, "status is 201")}, exitAssertion, "1 of 1 requests failed"},
+		{"transport", []app.ExecutionResult{{Err: transportErr}}, exitTransport, "1 of 1 requests failed"},
+		{"invalid request", []app.ExecutionResult{{Err: errors.New("URL is required")}}, exitError, "1 of 1 requests failed"},
+		{"assertion over status", []app.ExecutionResult{result(500), result(200, "body has id")}, exitAssertion, "2 of 2 requests failed"},
+		{"transport over all", []app.ExecutionResult{result(500), result(200, "body has id"), {Err: transportErr}}, exitTransport, "3 of 3 requests failed"},
+		{"status over other errors", []app.ExecutionResult{{Err: errors.New("URL is required")}, result(503)}, exitHTTPStatus, "2 of 2 requests failed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := runFailed(&app.RunReport{Results: tt.results})
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, tt.wantErr)
+			assert.Equal(t, tt.want, exitCode(err))
+		})
+	}
+}
+
+func TestResponseFailure(t *testing.T) {
+	tests := []struct {
+		name         string
+		resp         *domain.Response
+		failOnStatus bool
+		want         int
+	}{
+		{"success", response(200), true, 0},
+		{"error status without -fail", response(500), false, 0},
+		{"client error with -fail", response(404), true, exitHTTPStatus},
+		{"server error with -fail", response(503), true, exitHTTPStatus},
+		{"redirect with -fail", response(301), true, 0},
+		{"assertion", response(200, "status is 201"), false, exitAssertion},
+		{"assertion over status", response(500, "status is 200"), true, exitAssertion},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := responseFailure(tt.resp, tt.failOnStatus)
+			if tt.want == 0 {
+				assert.NoError(t, err)
+				return
+			}
+			assert.Equal(t, tt.want, exitCode(err))
+		})
+	}
+}
+
+func TestParseStatusFilter(t *testing.T) {
+	tests := []struct {
+		value      string
+		wantClass  int
+		wantFailed bool
+		wantErr    bool
+	}{
+		{"1xx", 1, false, false},
+		{"2xx", 2, false, false},
+		{"5xx", 5, false, false},
+		{"4XX", 4, false, false},
+		{"failed", 0, true, false},
+		{"FAILED", 0, true, false},
+		{"6xx", 0, false, true},
+		{"0xx", 0, false, true},
+		{"404", 0, false, true},
+		{"4x", 0, false, true},
+		{"abc", 0, false, true},
+		{"", 0, false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			class, failed, err := parseStatusFilter(tt.value)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantClass, class)
+			assert.Equal(t, tt.wantFailed, failed)
+		})
+	}
+}
+
+func TestParseSince(t *testing.T) {
+	now := time.Date(2026, 3, 15, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		value   string
+		want    string
+		wantErr string
+	}{
+		{"24h", "2026-03-14T12:00:00Z", ""},
+		{"90m", "2026-03-15T10:30:00Z", ""},
+		{"7d", "2026-03-08T12:00:00Z", ""},
+		{"2026-03-01T08:00:00Z", "2026-03-01T08:00:00Z", ""},
+		{"2026-03-01T08:00:00+02:00", "2026-03-01T08:00:00+02:00", ""},
+		{"-24h", "", "the age must be positive"},
+		{"-7d", "", "the age must be positive"},
+		{"0s", "", "the age must be positive"},
+		{"0d", "", "the age must be positive"},
+		{"abc", "", "expected an age"},
+		{"xd", "", "expected an age"},
+		{"1.5d", "", "expected an age"},
+		{"2026-03-01", "", "expected an age"},
+		{"", "", "expected an age"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := parseSince(tt.value, now)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+// withStdin makes content standard input for the rest of the test.
+func withStdin(t *testing.T, content string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "stdin")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	file, err := os.Open(path)
+	require.NoError(t, err)
+
+	stdin := os.Stdin
+	os.Stdin = file
+	t.Cleanup(func() {
+		os.Stdin = stdin
+		stdinRead = false
+		_ = file.Close()
+	})
+}
+
+func TestParseInterspersed(t *testing.T) {
+	tests := []struct {
+		name           string
+		args           []string
+		stdin          string
+		wantPositional []string
+		wantMethod     string
+		wantHeaders    []string
+		wantData       []string
+		wantInsecure   bool
+		wantErr        string
+	}{
+		{
+			name:           "flags before the URL",
+			args:           []string{"-X", "PUT", "-H", "Accept: text/plain", "https://example.com"},
+			wantPositional: []string{"https://example.com"},
+			wantMethod:     "PUT",
+			wantHeaders:    []string{"Accept: text/plain"},
+		},
+		{
+			name:           "flags after the URL",
+			args:           []string{"https://example.com", "-X", "DELETE", "-k"},
+			wantPositional: []string{"https://example.com"},
+			wantMethod:     "DELETE",
+			wantInsecure:   true,
+		},
+		{
+			name:           "flags between positional arguments",
+			args:           []string{"-H", "A: 1", "https://example.com", "-H", "B: 2", "extra", "-d", "x=1"},
+			wantPositional: []string{"https://example.com", "extra"},
+			wantHeaders:    []string{"A: 1", "B: 2"},
+			wantData:       []string{"x=1"},
+		},
+		{
+			name:           "data from standard input",
+			args:           []string{"https://example.com", "--data", "@-"},
+			stdin:          `{"name":"curly"}`,
+			wantPositional: []string{"https://example.com"},
+			wantData:       []string{`{"name":"curly"}`},
+		},
+		{
+			name:           "raw data is not read from a file",
+			args:           []string{"--data-raw", "@-", "https://example.com"},
+			wantPositional: []string{"https://example.com"},
+			wantData:       []string{"@-"},
+		},
+		{
+			name:           "arguments after -- are positional",
+			args:           []string{"https://example.com", "--", "-k"},
+			wantPositional: []string{"https://example.com", "-k"},
+		},
+		{
+			name:    "standard input read twice",
+			args:    []string{"-d", "@-", "https://example.com", "--data", "@-"},
+			wantErr: "standard input can only be read once",
+		},
+		{
+			name:    "unknown flag after the URL",
+			args:    []string{"https://example.com", "-z"},
+			wantErr: "flag provided but not defined: -z",
+		},
+		{
+			name:    "missing flag value",
+			args:    []string{"https://example.com", "-X"},
+			wantErr: "flag needs an argument: -X",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withStdin(t, tt.stdin)
+			var send sendOptions
+			var insecure bool
+			fs := newFlagSet("send")
+			fs.SetOutput(io.Discard)
+			fs.StringVar(&send.method, "X", "", "")
+			fs.Var((*repeatedFlag)(&send.headers), "H", "")
+			fs.Var(dataFlag{parts: &send.data}, "d", "")
+			fs.Var(dataFlag{parts: &send.data}, "data", "")
+			fs.Var(dataFlag{parts: &send.data, raw: true}, "data-raw", "")
+			fs.BoolVar(&insecure, "k", false, "")
+
+			positional, err := parseInterspersed(fs, tt.args)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantPositional, positional)
+			assert.Equal(t, tt.wantMethod, send.method)
+			assert.Equal(t, tt.wantHeaders, send.headers)
+			assert.Equal(t, tt.wantData, send.data)
+			assert.Equal(t, tt.wantInsecure, insecure)
+		})
+	}
+}
+
+// formPart is a part of a multipart form as the tests compare it.
+type formPart struct {
+	name        string
+	filename    string
+	contentType string
+	data        string
+}
+
+// readForm decodes a multipart form body of the given Content-Type.
+func readForm(t *testing.T, body, contentType string) []formPart {
+	t.Helper()
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	require.NoError(t, err)
+	require.Equal(t, "multipart/form-data", mediaType)
+
+	var parts []formPart
+	reader := multipart.NewReader(strings.NewReader(body), params["boundary"])
+	for {
+		part, err := reader.NextPart()
+		if errors.Is(err, io.EOF) {
+			return parts
+		}
+		require.NoError(t, err)
+		data, err := io.ReadAll(part)
+		require.NoError(t, err)
+		parts = append(parts, formPart{
+			name:        part.FormName(),
+			filename:    part.FileName(),
+			contentType: part.Header.Get("Content-Type"),
+			data:        string(data),
+		})
+	}
+}
+
+func TestMultipartForm(t *testing.T) {
+	dir := t.TempDir()
+	image := filepath.Join(dir, "photo.png")
+	require.NoError(t, os.WriteFile(image, []byte("\x89PNG"), 0o600))
+	blob := filepath.Join(dir, "blob")
+	require.NoError(t, os.WriteFile(blob, []byte{0, 1, 2}, 0o600))
+	note := filepath.Join(dir, "note.txt")
+	require.NoError(t, os.WriteFile(note, []byte("hello"), 0o600))
+
+	tests := []struct {
+		name    string
+		fields  []string
+		stdin   string
+		want    []formPart
+		wantErr string
+	}{
+		{
+			name:   "plain fields",
+			fields: []string{"user=ann", "empty=", "expr=a=b"},
+			want: []formPart{
+				{name: "user", data: "ann"},
+				{name: "empty"},
+				{name: "expr", data: "a=b"},
+			},
+		},
+		{
+			name:   "file upload",
+			fields: []string{"avatar=@" + image},
+			want:   []formPart{{name: "avatar", filename: "photo.png", contentType: "image/png", data: "\x89PNG"}},
+		},
+		{
+			name:   "file upload of unknown type",
+			fields: []string{"file=@" + blob},
+			want:   []formPart{{name: "file", filename: "blob", contentType: "application/octet-stream", data: "\x00\x01\x02"}},
+		},
+		{
+			name:   "file contents as a field",
+			fields: []string{"message=<" + note, "to=bob"},
+			want: []formPart{
+				{name: "message", data: "hello"},
+				{name: "to", data: "bob"},
+			},
+		},
+		{
+			name:   "file contents from standard input",
+			fields: []string{"message=<-"},
+			stdin:  "from stdin",
+			want:   []formPart{{name: "message", data: "from stdin"}},
+		},
+		{
+			name:    "missing file",
+			fields:  []string{"avatar=@" + filepath.Join(dir, "missing.png")},
+			wantErr: "failed to read input",
+		},
+		{
+			name:    "no value",
+			fields:  []string{"user"},
+			wantErr: `invalid form field "user": expected name=value`,
+		},
+		{
+			name:    "no name",
+			fields:  []string{"=ann"},
+			wantErr: `invalid form field "=ann": expected name=value`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withStdin(t, tt.stdin)
+			body, contentType, err := multipartForm(tt.fields)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, readForm(t, body, contentType))
+		})
+	}
+}
@@ -2,6 +2,7 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"io"
@@ -9,22 +10,57 @@ import (
 	"log/slog"
 	"os"
 	"os/signal"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
 	"syscall"
 	"time"
 
+	"github.com/charmbracelet/lipgloss"
+
 	"github.com/williajm/curly/internal/app"
+	"github.com/williajm/curly/internal/domain"
 	"github.com/williajm/curly/internal/infrastructure/config"
+	"github.com/williajm/curly/internal/infrastructure/graphql"
+	"github.com/williajm/curly/internal/infrastructure/hooks"
 	"github.com/williajm/curly/internal/infrastructure/http"
+	"github.com/williajm/curly/internal/infrastructure/imagepreview"
+	"github.com/williajm/curly/internal/infrastructure/repository/archive"
 	"github.com/williajm/curly/internal/infrastructure/repository/sqlite"
+	"github.com/williajm/curly/internal/infrastructure/storage"
+	"github.com/williajm/curly/internal/infrastructure/workspace"
 	"github.com/williajm/curly/internal/presentation"
+	"github.com/williajm/curly/internal/presentation/models"
+	"github.com/williajm/curly/internal/presentation/styles"
 	"github.com/williajm/curly/pkg/version"
 )
 
+// Exit codes of commands, so scripts and CI can tell failures apart.
+const (
+	// exitError is any other failure, such as bad usage or configuration.
+	exitError = 1
+
+	// exitTransport means a request could not be sent or got no response,
+	// such as on a connection failure or a timeout.
+	exitTransport = 3
+
+	// exitHTTPStatus means a response had an error status: 4xx or 5xx with
+	// -fail for send and replay, anything but 2xx or 3xx for run.
+	exitHTTPStatus = 4
+
+	// exitAssertion means a response failed its assertions.
+	exitAssertion = 5
+)
+
 func main() {
 	// Command-line flags.
 	versionFlag := flag.Bool("version", false, "Print version information and exit")
 	configFlag := flag.String("config", "", "Path to configuration file")
 	dbPathFlag := flag.String("db", "", "Path to SQLite database (overrides config)")
+	workspaceFlag := flag.String("workspace", "", "Workspace to open (overrides config)")
+	flag.Usage = usage
 	flag.Parse()
 
 	// Handle version flag.
@@ -34,31 +70,34 @@ func main() {
 		os.Exit(0)
 	}
 
+	opts := globalOptions{configPath: *configFlag, dbPath: *dbPathFlag, workspace: *workspaceFlag}
+
+	// Run a non-interactive subcommand if one was given.
+	if flag.NArg() > 0 {
+		closeLog := setupCommandLogging(opts)
+		err := runCommand(opts, flag.Arg(0), flag.Args()[1:])
+		closeLog()
+		if err != nil && exitCode(err) != 0 {
+			fmt.Fprintf(os.Stderr, "curly: %v\n", err)
+			os.Exit(exitCode(err))
+		}
+		return
+	}
+
 	// Initialize and run the application.
-	if err := run(*configFlag, *dbPathFlag); err != nil {
+	if err := run(opts); err != nil {
 		log.Fatalf("Application error: %v", err)
 	}
 }
 
-func run(configPath, dbPath string) error {
-	// Load configuration.
-	cfg, err := config.Load(configPath)
+func run(opts globalOptions) error {
+	cfg, _, err := loadWorkspaceConfig(opts)
 	if err != nil {
-		return fmt.Errorf("failed to load configuration: %w", err)
-	}
-
-	// Override database path from command line if provided.
-	if dbPath != "" {
-		cfg.Database.Path = dbPath
-	}
-
-	// Ensure necessary directories exist.
-	if err := config.EnsureDirectories(cfg); err != nil {
-		return fmt.Errorf("failed to create directories: %w", err)
+		return err
 	}
 
 	// Set up logging.
-	logger, logFile, err := setupLogging(cfg)
+	logger, logFile, err := setupLogging(cfg, os.Stderr)
 	if err != nil {
 		return fmt.Errorf("failed to setup logging: %w", err)
 	}
@@ -73,91 +112,590 @@ func run(configPath, dbPath string) error {
 
 	slog.Info("Starting curly",
 		"version", version.Get().Version,
-		"config_loaded", configPath != "",
-		"database_path", cfg.Database.Path,
+		"config_loaded", opts.configPath != "",
+		"database_driver", cfg.Database.Driver,
 	)
 
 	// Set up signal handling for graceful shutdown.
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
 
-	// Initialize database with config.
-	dbConfig := &sqlite.Config{
-		Path:           cfg.Database.Path,
-		MigrationsPath: "", // Migrations are embedded in the code
+	// Run the TUI, reopening storage each time the user switches workspace.
+	for {
+		next, err := runWorkspace(opts, sigChan)
+		if err != nil || next == "" {
+			slog.Info("curly exited", "error", err)
+			return err
+		}
+		slog.Info("Switching workspace", "workspace", next)
+		opts.workspace = next
+	}
+}
+
+// runWorkspace runs the TUI against the selected workspace until it exits.
+// It returns the workspace to switch to, or "" when the application should exit.
+func runWorkspace(opts globalOptions, sigChan <-chan os.Signal) (string, error) {
+	cfg, workspaces, err := loadWorkspaceConfig(opts)
+	if err != nil {
+		return "", err
 	}
 
-	db, err := sqlite.Open(dbConfig)
+	imagePreview, keys, prefs, err := tuiSettings(cfg)
 	if err != nil {
-		return fmt.Errorf("failed to initialize database: %w", err)
+		return "", err
+	}
+
+	slog.Info("Opening workspace",
+		"workspace", cfg.Workspace,
+		"database_path", cfg.Database.Path,
+	)
+
+	store, err := openStore(cfg)
+	if err != nil {
+		return "", err
 	}
 	defer func() {
 		slog.Debug("Closing database connection")
-		if err := db.Close(); err != nil {
+		if err := store.Close(); err != nil {
 			slog.Error("Failed to close database", "error", err)
 		}
 	}()
 
-	// Run embedded migrations.
-	if err := sqlite.MigrateDB(db); err != nil {
-		return fmt.Errorf("failed to run database migrations: %w", err)
-	}
-
 	// Initialize repositories.
-	requestRepo := sqlite.NewRequestRepository(db)
-	historyRepo := sqlite.NewHistoryRepository(db)
+	requestRepo := store.Requests
+	historyRepo := store.History
 
 	// Initialize HTTP client with config.
-	httpConfig := &http.Config{
-		Timeout:         cfg.HTTP.Timeout,
-		MaxRedirects:    cfg.HTTP.MaxRedirects,
-		FollowRedirects: cfg.HTTP.FollowRedirects,
-		InsecureSkipTLS: cfg.HTTP.InsecureSkipTLS,
-	}
-	httpClient := http.NewClient(httpConfig)
+	httpClient := newHTTPClient(cfg)
 
 	// Initialize services.
 	requestService := app.NewRequestService(requestRepo, httpClient, historyRepo, slog.Default())
+	setSaveHook(requestService, cfg)
 	historyService := app.NewHistoryService(historyRepo, slog.Default())
+	historyService.SetReplayer(requestService)
+	if cfg.History.ArchiveBeforeCleanup {
+		historyService.SetArchiver(archive.NewArchiver(cfg.History.ArchiveDir))
+	}
 	authService := app.NewAuthService(slog.Default())
+	authService.SetHTTPClient(httpClient)
+	workspaceService := app.NewWorkspaceService(workspaces, cfg.Workspace, slog.Default())
+	importService := app.NewImportService(requestRepo, slog.Default())
+	codegenService := app.NewCodegenService(slog.Default())
+	latencyService := app.NewLatencyService(historyRepo, slog.Default())
+	runnerService := app.NewRunnerService(requestService, slog.Default())
+	runnerService.SetLatencyService(latencyService)
+	diffService := app.NewDiffService(historyRepo, slog.Default())
+	loadService := app.NewLoadService(requestService, httpClient, historyRepo, slog.Default())
+	graphqlService := app.NewGraphQLService(requestService, httpClient, slog.Default())
+	graphqlService.SetCache(graphql.NewCache(cfg.GraphQL.SchemaCacheDir))
+	collectionService := app.NewCollectionService(requestRepo, slog.Default())
+	environmentService := app.NewEnvironmentService(store.Environments, slog.Default())
+	requestService.SetVariables(environmentService)
+	bulkService := app.NewBulkService(requestRepo, historyRepo, slog.Default())
+	bulkService.SetArchiver(archive.NewArchiver(cfg.History.ArchiveDir))
+	var updateService *app.UpdateService
+	if cfg.UI.CheckForUpdates {
+		updateService = newUpdateService()
+	}
+	var draftService *app.DraftService
+	if cfg.UI.AutosaveInterval > 0 {
+		draftService = app.NewDraftService(store.Drafts, requestRepo, slog.Default())
+	}
+	var sessionService *app.SessionService
+	if cfg.UI.RestoreSession {
+		sessionService = app.NewSessionService(store.Session, requestRepo, historyRepo, slog.Default())
+	}
+
+	// Enforce history retention on startup and periodically while running.
+	backgroundCtx, stopBackground := context.WithCancel(context.Background())
+	defer stopBackground()
+	go historyService.RunRetention(backgroundCtx, retentionPolicy(cfg), app.DefaultRetentionInterval)
 
-	// Channel to receive TUI errors.
-	errChan := make(chan error, 1)
+	// Start scheduled executions.
+	var schedulerService *app.SchedulerService
+	if len(cfg.Schedules) > 0 {
+		schedulerService = app.NewSchedulerService(requestService, runnerService, slog.Default())
+		if err := schedulerService.Start(backgroundCtx, schedules(cfg)); err != nil {
+			return "", fmt.Errorf("invalid schedule: %w", err)
+		}
+	}
+
+	services := models.Services{
+		Request:     requestService,
+		History:     historyService,
+		Auth:        authService,
+		Workspace:   workspaceService,
+		Import:      importService,
+		Codegen:     codegenService,
+		Runner:      runnerService,
+		Scheduler:   schedulerService,
+		Diff:        diffService,
+		Load:        loadService,
+		GraphQL:     graphqlService,
+		Latency:     latencyService,
+		Collection:  collectionService,
+		Environment: environmentService,
+		Bulk:        bulkService,
+		Update:      updateService,
+		Draft:       draftService,
+		Session:     sessionService,
+	}
+
+	// Channel to receive the TUI result.
+	type tuiResult struct {
+		next string
+		err  error
+	}
+	resultChan := make(chan tuiResult, 1)
 
 	// Start the TUI in a goroutine.
 	go func() {
 		slog.Info("Starting curly TUI...")
-		if err := presentation.RunApp(requestService, historyService, authService); err != nil {
-			errChan <- fmt.Errorf("TUI error: %w", err)
-		} else {
-			errChan <- nil
+		next, err := presentation.RunApp(services, layout(cfg), saveLayout(opts.configPath), imagePreview, keys, prefs)
+		if err != nil {
+			err = fmt.Errorf("TUI error: %w", err)
 		}
+		resultChan <- tuiResult{next: next, err: err}
 	}()
 
 	// Wait for either a signal or TUI to exit.
-	var appErr error
 	select {
 	case sig := <-sigChan:
 		slog.Info("Received signal, shutting down gracefully", "signal", sig)
 		// Give the TUI a moment to clean up.
 		time.Sleep(100 * time.Millisecond)
-	case appErr = <-errChan:
-		// TUI exited normally or with error.
+		return "", nil
+	case result := <-resultChan:
+		// TUI exited normally, with an error, or to switch workspace.
+		return result.next, result.err
+	}
+}
+
+// tuiSettings applies the theme configured in cfg and returns the TUI's
+// image preview protocol, key bindings and preferences.
+func tuiSettings(cfg *config.Config) (imagepreview.Protocol, models.Keymap, models.Preferences, error) {
+	palette, err := theme(cfg)
+	if err != nil {
+		return "", nil, models.Preferences{}, err
+	}
+	styles.Apply(palette)
+
+	imagePreview, err := imagepreview.ParseProtocol(cfg.UI.ImagePreview)
+	if err != nil {
+		return "", nil, models.Preferences{}, err
+	}
+	keys, err := keymap(cfg)
+	if err != nil {
+		return "", nil, models.Preferences{}, err
+	}
+	prefs, err := preferences(cfg)
+	if err != nil {
+		return "", nil, models.Preferences{}, err
+	}
+	return imagePreview, keys, prefs, nil
+}
+
+// newHTTPClient creates the HTTP client described by cfg.
+func newHTTPClient(cfg *config.Config) http.Client {
+	var opts []http.Option
+	if cfg.Logging.Wire {
+		if w := wireLog(cfg); w != nil {
+			opts = append(opts, http.WithMiddleware(http.WireLog(w, cfg.Logging.WireBodyLimit)))
+		}
+	}
+	if runner := hookRunner(cfg); runner.Has(hooks.EventPreRequest) || runner.Has(hooks.EventPostResponse) {
+		opts = append(opts, http.WithHook(runner))
+	}
+	return http.NewClient(&http.Config{
+		Timeout:         cfg.HTTP.Timeout,
+		MaxRedirects:    cfg.HTTP.MaxRedirects,
+		FollowRedirects: cfg.HTTP.FollowRedirects,
+		InsecureSkipTLS: cfg.HTTP.InsecureSkipTLS,
+		Hosts:           hosts(cfg),
+	}, opts...)
+}
+
+// updateCheckTimeout is how long checking for a newer release may take.
+const updateCheckTimeout = 10 * time.Second
+
+// newUpdateService returns the service that checks for a newer release. It
+// has an HTTP client of its own, so that the check goes through none of the
+// hooks, wire logging or host settings meant for the user's requests.
+func newUpdateService() *app.UpdateService {
+	httpConfig := http.DefaultConfig()
+	httpConfig.Timeout = updateCheckTimeout
+	return app.NewUpdateService(http.NewClient(httpConfig), version.Version, slog.Default())
+}
+
+// hookRunner returns the runner of the hooks configured in cfg.
+func hookRunner(cfg *config.Config) *hooks.Runner {
+	list := make([]hooks.Hook, len(cfg.Hooks))
+	for i, hook := range cfg.Hooks {
+		list[i] = hooks.Hook{
+			Event:   hook.Event,
+			Command: hook.Command,
+			Args:    hook.Args,
+			Pattern: hook.Pattern,
+			Timeout: hook.Timeout,
+		}
 	}
+	return hooks.NewRunner(list)
+}
 
-	slog.Info("curly exited", "error", appErr)
-	return appErr
+// setSaveHook makes service run the on_save hooks configured in cfg, if any.
+func setSaveHook(service *app.RequestService, cfg *config.Config) {
+	if runner := hookRunner(cfg); runner.Has(hooks.EventOnSave) {
+		service.SetSaveHook(runner)
+	}
+}
+
+// The wire log is opened once, so that every client of a session writes to
+// the same file.
+var (
+	wireLogOnce sync.Once
+	wireLogFile *os.File
+)
+
+// wireLog returns the file the wire log of cfg goes to: a capture file for
+// this session in logging.wire_dir, or the log file. It returns nil, after
+// warning, if the file cannot be opened.
+func wireLog(cfg *config.Config) io.Writer {
+	wireLogOnce.Do(func() {
+		path := cfg.Logging.Path
+		if cfg.Logging.WireDir != "" {
+			name := fmt.Sprintf("wire-%s-%d.log", time.Now().Format("20060102-150405"), os.Getpid())
+			path = filepath.Join(cfg.Logging.WireDir, name)
+		}
+		file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+		if err != nil {
+			slog.Warn("Wire logging disabled", "error", err)
+			return
+		}
+		wireLogFile = file
+	})
+	if wireLogFile == nil {
+		return nil
+	}
+	return wireLogFile
+}
+
+// hosts converts the configured per-host settings to the HTTP client's.
+func hosts(cfg *config.Config) []http.Host {
+	result := make([]http.Host, len(cfg.Hosts))
+	for i, h := range cfg.Hosts {
+		result[i] = http.Host{
+			Pattern:         h.Pattern,
+			Headers:         h.Headers,
+			Auth:            hostAuth(h.Auth),
+			Timeout:         h.Timeout,
+			Proxy:           h.Proxy,
+			InsecureSkipTLS: h.InsecureSkipTLS,
+			CACert:          h.CACert,
+			ClientCert:      h.ClientCert,
+			ClientKey:       h.ClientKey,
+		}
+	}
+	return result
+}
+
+// hostAuth converts a host's authentication settings, returning nil if it
+// has none.
+func hostAuth(auth config.HostAuthConfig) domain.AuthConfig {
+	switch auth.Type {
+	case domain.AuthTypeBasic:
+		return domain.NewBasicAuth(auth.Username, auth.Password)
+	case domain.AuthTypeBearer:
+		return domain.NewBearerAuth(auth.Token)
+	case domain.AuthTypeAPIKey:
+		location := domain.APIKeyLocation(auth.Location)
+		if location == "" {
+			location = domain.APIKeyLocationHeader
+		}
+		return domain.NewAPIKeyAuth(auth.Key, auth.Value, location)
+	default:
+		return nil
+	}
+}
+
+// loadConfig loads the configuration, applies command-line overrides,
+// and ensures the application directories exist.
+func loadConfig(opts globalOptions) (*config.Config, error) {
+	cfg, _, err := loadWorkspaceConfig(opts)
+	return cfg, err
+}
+
+// loadWorkspaceConfig is loadConfig that also returns the workspace manager.
+// database.path is replaced by the selected workspace's database, and the
+// -db flag overrides both.
+func loadWorkspaceConfig(opts globalOptions) (*config.Config, *workspace.Manager, error) {
+	cfg, err := config.Load(opts.configPath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	// Override workspace from command line if provided.
+	if opts.workspace != "" {
+		cfg.Workspace = opts.workspace
+	}
+	if cfg.Workspace == "" {
+		cfg.Workspace = workspace.Default
+	}
+
+	workspaces := workspace.NewManager(cfg.Database.WorkspacesDir, cfg.Database.Path)
+	if cfg.Workspace != workspace.Default && cfg.Database.Driver != "" && cfg.Database.Driver != storage.DriverSQLite {
+		return nil, nil, fmt.Errorf("workspaces require the %s driver, but database.driver is %q", storage.DriverSQLite, cfg.Database.Driver)
+	}
+	cfg.Database.Path, err = workspaces.Path(cfg.Workspace)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := useWorkspaceDirs(cfg, workspaces); err != nil {
+		return nil, nil, err
+	}
+
+	// Override database path from command line if provided.
+	if opts.dbPath != "" {
+		cfg.Database.Path = opts.dbPath
+	}
+
+	// Ensure necessary directories exist.
+	if err := config.EnsureDirectories(cfg); err != nil {
+		return nil, nil, fmt.Errorf("failed to create directories: %w", err)
+	}
+
+	return cfg, workspaces, nil
+}
+
+// useWorkspaceDirs moves the backups, archived history and offloaded response
+// bodies of a workspace other than the default one into its own directory,
+// so that pruning and cleaning up one workspace never touches another's
+// files. Directories configured empty stay disabled.
+func useWorkspaceDirs(cfg *config.Config, workspaces *workspace.Manager) error {
+	dir, err := workspaces.DataDir(cfg.Workspace)
+	if err != nil || dir == "" {
+		return err
+	}
+	dirs := map[*string]string{
+		&cfg.Database.BackupDir: "backups",
+		&cfg.History.ArchiveDir: "archive",
+		&cfg.History.BodiesDir:  "bodies",
+	}
+	for path, name := range dirs {
+		if *path != "" {
+			*path = filepath.Join(dir, name)
+		}
+	}
+	return nil
+}
+
+// openStore opens the configured storage backend, which applies its migrations.
+func openStore(cfg *config.Config) (*storage.Store, error) {
+	storeConfig := &storage.Config{
+		Driver: cfg.Database.Driver,
+		SQLite: sqlite.Config{
+			Path:            cfg.Database.Path,
+			JournalMode:     cfg.Database.JournalMode,
+			Synchronous:     cfg.Database.Synchronous,
+			BusyTimeout:     cfg.Database.BusyTimeout,
+			MaxOpenConns:    cfg.Database.MaxOpenConns,
+			MaxIdleConns:    cfg.Database.MaxIdleConns,
+			ConnMaxLifetime: cfg.Database.ConnMaxLifetime,
+		},
+		DSN: cfg.Database.DSN,
+
+		BodiesDir:        cfg.History.BodiesDir,
+		OffloadThreshold: cfg.History.OffloadThreshold,
+	}
+	if cfg.Database.BackupBeforeMigrate {
+		storeConfig.SQLite.BackupDir = cfg.Database.BackupDir
+		storeConfig.SQLite.MaxBackups = cfg.Database.MaxBackups
+	}
+
+	store, err := storage.Open(storeConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize database: %w", err)
+	}
+
+	return store, nil
+}
+
+// retentionPolicy builds the history retention policy from configuration.
+// Age-based cleanup only applies when history.auto_cleanup is enabled.
+func retentionPolicy(cfg *config.Config) app.RetentionPolicy {
+	policy := app.RetentionPolicy{MaxEntries: cfg.History.MaxEntries}
+	if cfg.History.AutoCleanup {
+		policy.MaxAgeDays = cfg.History.CleanupAfterDays
+	}
+	return policy
+}
+
+// schedules converts the configured schedules for the scheduler service.
+func schedules(cfg *config.Config) []app.Schedule {
+	result := make([]app.Schedule, len(cfg.Schedules))
+	for i, s := range cfg.Schedules {
+		result[i] = app.Schedule{Name: s.Name, Spec: s.Cron, Request: s.Request, Folder: s.Folder}
+	}
+	return result
+}
+
+// layout returns the configured pane layout of the TUI.
+func layout(cfg *config.Config) models.Layout {
+	return models.Layout{Split: cfg.UI.Layout.Split, SplitRatio: cfg.UI.Layout.SplitRatio}
+}
+
+// keymap returns the TUI's shortcut keys with the configured keybindings.
+func keymap(cfg *config.Config) (models.Keymap, error) {
+	keys, err := models.DefaultKeymap().Bind(cfg.UI.Keybindings)
+	if err != nil {
+		return nil, fmt.Errorf("invalid keybinding: %w", err)
+	}
+	return keys, nil
+}
+
+// preferences returns the TUI's display settings and autosave interval
+// from cfg.
+func preferences(cfg *config.Config) (models.Preferences, error) {
+	prefs := models.Preferences{
+		ShowResponseTime:   cfg.UI.ShowResponseTime,
+		SyntaxHighlighting: cfg.UI.SyntaxHighlighting,
+		AutosaveInterval:   cfg.UI.AutosaveInterval,
+		KeepDefaultTab:     cfg.UI.DefaultTabSet,
+	}
+	if cfg.UI.DefaultTab != "" {
+		tab, err := models.TabNamed(cfg.UI.DefaultTab)
+		if err != nil {
+			return models.Preferences{}, fmt.Errorf("invalid default tab: %w", err)
+		}
+		prefs.DefaultTab = tab
+	}
+	return prefs, nil
+}
+
+// saveLayout returns a function that saves the TUI's pane layout to the
+// layout file next to the config file at configPath.
+func saveLayout(configPath string) func(models.Layout) error {
+	return func(l models.Layout) error {
+		return config.SaveLayout(configPath, config.LayoutConfig{Split: l.Split, SplitRatio: l.SplitRatio})
+	}
+}
+
+// theme returns the palette named by ui.theme: a built-in theme or one of
+// ui.themes, which starts from its base theme and replaces the colors it sets.
+func theme(cfg *config.Config) (styles.Palette, error) {
+	name := cfg.UI.Theme
+	if name == "" {
+		name = "dark"
+	}
+	if palette, ok := styles.Builtin(name); ok {
+		return palette, nil
+	}
+
+	custom, ok := cfg.UI.Themes[strings.ToLower(name)]
+	if !ok {
+		names := styles.BuiltinNames()
+		for themeName := range cfg.UI.Themes {
+			names = append(names, themeName)
+		}
+		sort.Strings(names)
+		return styles.Palette{}, fmt.Errorf("unknown theme %q (available: %s)", name, strings.Join(names, ", "))
+	}
+
+	baseName := custom.Base
+	if baseName == "" {
+		baseName = "dark"
+	}
+	base, ok := styles.Builtin(baseName)
+	if !ok {
+		return styles.Palette{}, fmt.Errorf("theme %q: unknown base theme %q (available: %s)", name, baseName, strings.Join(styles.BuiltinNames(), ", "))
+	}
+
+	colors := map[string]string{
+		"primary":       custom.Primary,
+		"secondary":     custom.Secondary,
+		"text":          custom.Text,
+		"text_dimmed":   custom.TextDimmed,
+		"background":    custom.Background,
+		"border":        custom.Border,
+		"border_active": custom.BorderActive,
+		"success":       custom.Success,
+		"error":         custom.Error,
+		"warning":       custom.Warning,
+		"info":          custom.Info,
+	}
+	for class, color := range custom.Status {
+		if !slices.Contains([]string{"2xx", "3xx", "4xx", "5xx"}, strings.ToLower(class)) {
+			return styles.Palette{}, fmt.Errorf("theme %q: unknown status class %q (use 2xx, 3xx, 4xx or 5xx)", name, class)
+		}
+		colors["status."+strings.ToLower(class)] = color
+	}
+	for method, color := range custom.Methods {
+		colors["methods."+method] = color
+	}
+	for field, color := range colors {
+		if color != "" && !styles.ValidColor(color) {
+			return styles.Palette{}, fmt.Errorf("theme %q: invalid color %q for %s", name, color, field)
+		}
+	}
+
+	override := styles.Palette{
+		Primary:      lipgloss.Color(custom.Primary),
+		Secondary:    lipgloss.Color(custom.Secondary),
+		Text:         lipgloss.Color(custom.Text),
+		TextDimmed:   lipgloss.Color(custom.TextDimmed),
+		Background:   lipgloss.Color(custom.Background),
+		Border:       lipgloss.Color(custom.Border),
+		BorderActive: lipgloss.Color(custom.BorderActive),
+		Success:      lipgloss.Color(custom.Success),
+		Error:        lipgloss.Color(custom.Error),
+		Warning:      lipgloss.Color(custom.Warning),
+		Info:         lipgloss.Color(custom.Info),
+		Status2xx:    lipgloss.Color(colors["status.2xx"]),
+		Status3xx:    lipgloss.Color(colors["status.3xx"]),
+		Status4xx:    lipgloss.Color(colors["status.4xx"]),
+		Status5xx:    lipgloss.Color(colors["status.5xx"]),
+		Methods:      make(map[string]lipgloss.Color, len(custom.Methods)),
+	}
+	for method, color := range custom.Methods {
+		override.Methods[method] = lipgloss.Color(color)
+	}
+	return base.Override(override), nil
+}
+
+// setupCommandLogging sets up logging for a subcommand as configured, to the
+// log file only, so that a command writes nothing but its own output. If the
+// configuration cannot be loaded the default logger is kept, and the command
+// reports the error itself. It returns a function that closes the log file.
+func setupCommandLogging(opts globalOptions) func() {
+	cfg, err := loadConfig(opts)
+	if err != nil {
+		return func() {}
+	}
+
+	logger, logFile, err := setupLogging(cfg, nil)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "curly: failed to setup logging: %v\n", err)
+		return func() {}
+	}
+	slog.SetDefault(logger)
+
+	return func() {
+		if logFile != nil {
+			_ = logFile.Close()
+		}
+	}
 }
 
 // setupLogging configures the application logger based on configuration.
-func setupLogging(cfg *config.Config) (*slog.Logger, *os.File, error) {
+// Logs go to the log file, if enabled, and to console unless it is nil.
+func setupLogging(cfg *config.Config, console io.Writer) (*slog.Logger, *os.File, error) {
 	var handler slog.Handler
 	var logFile *os.File
 
 	// Parse log level.
 	level := parseLogLevel(cfg.Logging.Level)
 
-	if cfg.Logging.Enabled && cfg.Logging.Path != "" {
+	switch {
+	case cfg.Logging.Enabled && cfg.Logging.Path != "":
 		// Create log file.
 		var err error
 		logFile, err = os.OpenFile(cfg.Logging.Path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
@@ -165,16 +703,21 @@ func setupLogging(cfg *config.Config) (*slog.Logger, *os.File, error) {
 			return nil, nil, fmt.Errorf("failed to open log file: %w", err)
 		}
 
-		// Log to both file and stderr.
-		multiWriter := io.MultiWriter(logFile, os.Stderr)
-		handler = slog.NewJSONHandler(multiWriter, &slog.HandlerOptions{
+		// Log to both file and console.
+		var out io.Writer = logFile
+		if console != nil {
+			out = io.MultiWriter(logFile, console)
+		}
+		handler = slog.NewJSONHandler(out, &slog.HandlerOptions{
 			Level: level,
 		})
-	} else {
-		// Log only to stderr.
-		handler = slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{
+	case console != nil:
+		// Log only to console.
+		handler = slog.NewTextHandler(console, &slog.HandlerOptions{
 			Level: level,
 		})
+	default:
+		handler = slog.DiscardHandler
 	}
 
 	return slog.New(handler), logFile, nil
@@ -1,18 +1,30 @@
 package app
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/williajm/curly/internal/domain"
+	httpinfra "github.com/williajm/curly/internal/infrastructure/http"
+	"github.com/williajm/curly/internal/infrastructure/oauth"
 )
 
 // AuthService handles authentication configuration and application to requests.
 // It provides factory methods to create auth configurations and validation.
 type AuthService struct {
 	logger *slog.Logger
+
+	// httpClient signs in with OAuth; nil disables it.
+	httpClient httpinfra.Client
+
+	// pollUnit is the length of a second of the device flow's polling
+	// interval, shortened by tests.
+	pollUnit time.Duration
 }
 
 // NewAuthService creates a new AuthService.
@@ -22,10 +34,16 @@ func NewAuthService(logger *slog.Logger) *AuthService {
 	}
 
 	return &AuthService{
-		logger: logger,
+		logger:   logger,
+		pollUnit: time.Second,
 	}
 }
 
+// SetHTTPClient sets the client that talks to OAuth authorization servers.
+func (s *AuthService) SetHTTPClient(client httpinfra.Client) {
+	s.httpClient = client
+}
+
 // CreateAuth creates an authentication configuration from the provided type and credentials.
 // Supported types: "none", "basic", "bearer", "apikey".
 // Returns an error if the auth type is not supported or credentials are invalid.
@@ -202,3 +220,86 @@ func (s *AuthService) SupportedTypes() []string {
 		"apikey",
 	}
 }
+
+// StartDeviceLogin starts signing in with the OAuth 2.0 device flow described
+// by cfg, returning the code for the user to enter at its verification URL.
+// Pass the code to AwaitDeviceToken to wait for them to do so.
+func (s *AuthService) StartDeviceLogin(ctx context.Context, cfg oauth.DeviceConfig) (*oauth.DeviceCode, error) {
+	if s.httpClient == nil {
+		return nil, fmt.Errorf("OAuth sign-in is not available")
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("requesting OAuth device code", "url", cfg.DeviceAuthURL)
+	resp, err := s.httpClient.Execute(ctx, oauth.DeviceCodeRequest(cfg))
+	if err != nil {
+		s.logger.Error("OAuth device code request failed", "error", err)
+		return nil, fmt.Errorf("failed to request device code: %w", err)
+	}
+
+	code, err := oauth.ParseDeviceCode(resp.Body)
+	if err != nil {
+		if !resp.IsSuccess() {
+			err = fmt.Errorf("server returned %s: %w", resp.Status, err)
+		}
+		s.logger.Error("OAuth device code request failed", "error", err)
+		return nil, fmt.Errorf("failed to request device code: %w", err)
+	}
+	return code, nil
+}
+
+// AwaitDeviceToken polls the token endpoint at the interval the server asks
+// for until the user has signed in with code, returning the access token as
+// bearer auth. It gives up when the code expires, the user declines, or ctx
+// is canceled.
+func (s *AuthService) AwaitDeviceToken(ctx context.Context, cfg oauth.DeviceConfig, code *oauth.DeviceCode) (domain.AuthConfig, error) {
+	if s.httpClient == nil {
+		return nil, fmt.Errorf("OAuth sign-in is not available")
+	}
+
+	interval := max(code.Interval, 1)
+	var expired <-chan time.Time
+	if code.ExpiresIn > 0 {
+		timer := time.NewTimer(time.Duration(code.ExpiresIn) * s.pollUnit)
+		defer timer.Stop()
+		expired = timer.C
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-expired:
+			return nil, oauth.ErrExpiredToken
+		case <-time.After(time.Duration(interval) * s.pollUnit):
+		}
+
+		resp, err := s.httpClient.Execute(ctx, oauth.TokenRequest(cfg, code.DeviceCode))
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			// Polling continues through network errors, as it would
+			// through a timeout.
+			s.logger.Warn("OAuth token request failed", "error", err)
+			continue
+		}
+
+		token, err := oauth.ParseToken(resp.Body)
+		switch {
+		case errors.Is(err, oauth.ErrAuthorizationPending):
+			continue
+		case errors.Is(err, oauth.ErrSlowDown):
+			interval += oauth.SlowDownStep
+			continue
+		case err != nil:
+			s.logger.Warn("OAuth device sign-in failed", "error", err)
+			return nil, err
+		}
+
+		s.logger.Info("OAuth device sign-in succeeded", "token_type", token.TokenType)
+		return domain.NewBearerAuth(token.AccessToken), nil
+	}
+}
@@ -1,12 +1,18 @@
 package app
 
 import (
+	"context"
 	"log/slog"
 	"net/http"
+	"net/url"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 	"github.com/williajm/curly/internal/domain"
+	"github.com/williajm/curly/internal/infrastructure/oauth"
 )
 
 func TestNewAuthService(t *testing.T) {
@@ -453,3 +459,110 @@ func TestSupportedTypes(t *testing.T) {
 	assert.Contains(t, types, "bearer")
 	assert.Contains(t, types, "apikey")
 }
+
+var deviceTestConfig = oauth.DeviceConfig{
+	DeviceAuthURL: "https://auth.example.com/device/code",
+	TokenURL:      "https://auth.example.com/token",
+	ClientID:      "curly",
+}
+
+// newDeviceTestService returns an AuthService whose device flow polls every
+// millisecond rather than every second.
+func newDeviceTestService(client *MockHTTPClient) *AuthService {
+	service := NewAuthService(slog.Default())
+	service.SetHTTPClient(client)
+	service.pollUnit = time.Millisecond
+	return service
+}
+
+// tokenRequestTo matches token requests to the test token endpoint.
+func tokenRequestTo(r *domain.Request) bool {
+	return r.URL == deviceTestConfig.TokenURL
+}
+
+func TestStartDeviceLogin(t *testing.T) {
+	client := new(MockHTTPClient)
+	service := newDeviceTestService(client)
+
+	client.On("Execute", mock.Anything, mock.MatchedBy(func(r *domain.Request) bool {
+		form, err := url.ParseQuery(r.Body)
+		return err == nil && r.URL == deviceTestConfig.DeviceAuthURL && form.Get("client_id") == "curly"
+	})).Return(&domain.Response{StatusCode: 200, Status: "200 OK", Body: `{
+		"device_code": "dev", "user_code": "ABCD-EFGH",
+		"verification_uri": "https://example.com/device", "expires_in": 900, "interval": 5
+	}`}, nil).Once()
+
+	code, err := service.StartDeviceLogin(context.Background(), deviceTestConfig)
+	require.NoError(t, err)
+	assert.Equal(t, "ABCD-EFGH", code.UserCode)
+	assert.Equal(t, "https://example.com/device", code.VerificationURI)
+	client.AssertExpectations(t)
+}
+
+func TestStartDeviceLogin_Errors(t *testing.T) {
+	_, err := NewAuthService(slog.Default()).StartDeviceLogin(context.Background(), deviceTestConfig)
+	assert.ErrorContains(t, err, "not available")
+
+	client := new(MockHTTPClient)
+	service := newDeviceTestService(client)
+	_, err = service.StartDeviceLogin(context.Background(), oauth.DeviceConfig{})
+	assert.ErrorContains(t, err, "device authorization URL is required")
+
+	client.On("Execute", mock.Anything, mock.Anything).
+		Return(&domain.Response{StatusCode: 401, Status: "401 Unauthorized", Body: `{"error": "invalid_client"}`}, nil).Once()
+	_, err = service.StartDeviceLogin(context.Background(), deviceTestConfig)
+	assert.ErrorContains(t, err, "server returned 401 Unauthorized: invalid_client")
+}
+
+func TestAwaitDeviceToken(t *testing.T) {
+	client := new(MockHTTPClient)
+	service := newDeviceTestService(client)
+
+	pending := &domain.Response{StatusCode: 400, Status: "400 Bad Request", Body: `{"error": "authorization_pending"}`}
+	slowDown := &domain.Response{StatusCode: 400, Status: "400 Bad Request", Body: `{"error": "slow_down"}`}
+	granted := &domain.Response{StatusCode: 200, Status: "200 OK", Body: `{"access_token": "secret", "token_type": "Bearer"}`}
+	client.On("Execute", mock.Anything, mock.MatchedBy(tokenRequestTo)).Return(pending, nil).Once()
+	client.On("Execute", mock.Anything, mock.MatchedBy(tokenRequestTo)).Return(slowDown, nil).Once()
+	client.On("Execute", mock.Anything, mock.MatchedBy(tokenRequestTo)).Return(granted, nil).Once()
+
+	auth, err := service.AwaitDeviceToken(context.Background(), deviceTestConfig, &oauth.DeviceCode{DeviceCode: "dev", Interval: 1})
+	require.NoError(t, err)
+	assert.Equal(t, domain.NewBearerAuth("secret"), auth)
+	client.AssertExpectations(t)
+}
+
+func TestAwaitDeviceToken_Fails(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want error
+	}{
+		{name: "denied", body: `{"error": "access_denied"}`, want: oauth.ErrAccessDenied},
+		{name: "expired", body: `{"error": "expired_token"}`, want: oauth.ErrExpiredToken},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := new(MockHTTPClient)
+			client.On("Execute", mock.Anything, mock.Anything).
+				Return(&domain.Response{StatusCode: 400, Status: "400 Bad Request", Body: tt.body}, nil).Once()
+
+			_, err := newDeviceTestService(client).AwaitDeviceToken(context.Background(), deviceTestConfig, &oauth.DeviceCode{DeviceCode: "dev", Interval: 1})
+			assert.ErrorIs(t, err, tt.want)
+		})
+	}
+}
+
+func TestAwaitDeviceToken_StopsWhenCodeExpiresOrCanceled(t *testing.T) {
+	client := new(MockHTTPClient)
+	client.On("Execute", mock.Anything, mock.Anything).
+		Return(&domain.Response{StatusCode: 400, Status: "400 Bad Request", Body: `{"error": "authorization_pending"}`}, nil)
+	service := newDeviceTestService(client)
+
+	_, err := service.AwaitDeviceToken(context.Background(), deviceTestConfig, &oauth.DeviceCode{DeviceCode: "dev", Interval: 1, ExpiresIn: 20})
+	assert.ErrorIs(t, err, oauth.ErrExpiredToken)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = service.AwaitDeviceToken(ctx, deviceTestConfig, &oauth.DeviceCode{DeviceCode: "dev", Interval: 1})
+	assert.ErrorIs(t, err, context.Canceled)
+}
@@ -0,0 +1,180 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/williajm/curly/internal/infrastructure/repository"
+)
+
+// ErrEmptyHistoryFilter is returned when a bulk history delete is given a
+// filter that would match every entry.
+var ErrEmptyHistoryFilter = errors.New("history filter must set at least one criterion")
+
+// ErrNoHistoryArchiver is returned when history is exported without an
+// archiver to write it.
+var ErrNoHistoryArchiver = errors.New("history export is not configured")
+
+// BulkService applies actions to many saved requests or history entries at
+// once, such as those selected in the TUI. Each action runs in a single
+// transaction, so it either applies to every item or to none.
+type BulkService struct {
+	requestRepo repository.RequestRepository
+	historyRepo repository.HistoryRepository
+	archiver    HistoryArchiver
+	logger      *slog.Logger
+}
+
+// NewBulkService creates a new BulkService with the provided dependencies.
+// Both repositories are required and must not be nil.
+func NewBulkService(requestRepo repository.RequestRepository, historyRepo repository.HistoryRepository, logger *slog.Logger) *BulkService {
+	if requestRepo == nil {
+		panic("request repository cannot be nil")
+	}
+	if historyRepo == nil {
+		panic("history repository cannot be nil")
+	}
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	return &BulkService{
+		requestRepo: requestRepo,
+		historyRepo: historyRepo,
+		logger:      logger,
+	}
+}
+
+// SetArchiver enables ExportHistory, which writes history entries through
+// archiver. Passing nil disables exporting.
+func (s *BulkService) SetArchiver(archiver HistoryArchiver) {
+	s.archiver = archiver
+}
+
+// DeleteRequests deletes saved requests, and with them their history.
+// If any request does not exist, none are deleted.
+func (s *BulkService) DeleteRequests(ctx context.Context, ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	s.logger.Info("deleting requests", "count", len(ids))
+
+	if err := s.requestRepo.DeleteMany(ctx, ids); err != nil {
+		s.logger.Error("failed to delete requests", "count", len(ids), "error", err)
+		return fmt.Errorf("failed to delete requests: %w", err)
+	}
+
+	return nil
+}
+
+// MoveRequests moves saved requests to the end of folder, in the order given.
+// The empty folder is the top level. If any request does not exist, none are moved.
+func (s *BulkService) MoveRequests(ctx context.Context, ids []string, folder string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	s.logger.Info("moving requests", "count", len(ids), "folder", folder)
+
+	if err := s.requestRepo.MoveMany(ctx, ids, folder); err != nil {
+		s.logger.Error("failed to move requests", "count", len(ids), "folder", folder, "error", err)
+		return fmt.Errorf("failed to move requests: %w", err)
+	}
+
+	return nil
+}
+
+// RetagRequests adds the tags in add to, and removes those in remove from,
+// saved requests. A tag in both is removed. If any request does not exist,
+// none are changed.
+func (s *BulkService) RetagRequests(ctx context.Context, ids []string, add, remove []string) error {
+	if len(ids) == 0 || (len(add) == 0 && len(remove) == 0) {
+		return nil
+	}
+	s.logger.Info("retagging requests", "count", len(ids), "add", add, "remove", remove)
+
+	if err := s.requestRepo.Retag(ctx, ids, add, remove); err != nil {
+		s.logger.Error("failed to retag requests", "count", len(ids), "error", err)
+		return fmt.Errorf("failed to retag requests: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteHistory deletes the history entries filter matches and returns how
+// many were deleted. The filter must set at least one criterion, so history
+// is never cleared by accident; it returns ErrEmptyHistoryFilter otherwise.
+func (s *BulkService) DeleteHistory(ctx context.Context, filter repository.HistoryFilter) (int64, error) {
+	if filter.IsEmpty() {
+		return 0, ErrEmptyHistoryFilter
+	}
+	if err := checkHistoryFilterTimes(filter); err != nil {
+		return 0, err
+	}
+
+	s.logger.Info("deleting history",
+		"requests", len(filter.RequestIDs),
+		"before", filter.Before,
+		"after", filter.After,
+		"failed_only", filter.FailedOnly,
+	)
+
+	deleted, err := s.historyRepo.DeleteMatching(ctx, filter)
+	if err != nil {
+		s.logger.Error("failed to delete history", "error", err)
+		return 0, fmt.Errorf("failed to delete history: %w", err)
+	}
+
+	s.logger.Info("history deleted", "count", deleted)
+	return deleted, nil
+}
+
+// ExportHistory writes the history entries filter matches to an archive and
+// returns its location and how many entries it holds. Nothing is written, and
+// the location is "", if no entries match. It returns ErrNoHistoryArchiver if
+// no archiver is set.
+func (s *BulkService) ExportHistory(ctx context.Context, filter repository.HistoryFilter) (string, int, error) {
+	if s.archiver == nil {
+		return "", 0, ErrNoHistoryArchiver
+	}
+	if err := checkHistoryFilterTimes(filter); err != nil {
+		return "", 0, err
+	}
+
+	entries, err := s.historyRepo.FindMatching(ctx, filter, 0)
+	if err != nil {
+		s.logger.Error("failed to load history for export", "error", err)
+		return "", 0, fmt.Errorf("failed to load history: %w", err)
+	}
+	if len(entries) == 0 {
+		return "", 0, nil
+	}
+	if err := readFullBodies(ctx, s.historyRepo, entries); err != nil {
+		s.logger.Error("failed to load history for export", "error", err)
+		return "", 0, fmt.Errorf("failed to load history: %w", err)
+	}
+
+	path, err := s.archiver.Archive(entries)
+	if err != nil {
+		s.logger.Error("failed to export history", "count", len(entries), "error", err)
+		return "", 0, fmt.Errorf("failed to export history: %w", err)
+	}
+
+	s.logger.Info("history exported", "count", len(entries), "path", path)
+	return path, len(entries), nil
+}
+
+// checkHistoryFilterTimes checks that the time bounds of filter are RFC3339.
+func checkHistoryFilterTimes(filter repository.HistoryFilter) error {
+	for _, bound := range []string{filter.Before, filter.After} {
+		if bound == "" {
+			continue
+		}
+		if _, err := time.Parse(time.RFC3339, bound); err != nil {
+			return fmt.Errorf("invalid history filter time %q: %w", bound, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,119 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/williajm/curly/internal/infrastructure/repository"
+)
+
+func TestNewBulkService_NilRepositories(t *testing.T) {
+	assert.Panics(t, func() { NewBulkService(nil, new(MockHistoryRepository), slog.Default()) })
+	assert.Panics(t, func() { NewBulkService(new(MockRequestRepository), nil, slog.Default()) })
+}
+
+func TestBulkService_Requests(t *testing.T) {
+	requestRepo := new(MockRequestRepository)
+	service := NewBulkService(requestRepo, new(MockHistoryRepository), slog.Default())
+	ctx := context.Background()
+	ids := []string{"a", "b"}
+
+	requestRepo.On("DeleteMany", ctx, ids).Return(nil).Once()
+	requestRepo.On("MoveMany", ctx, ids, "auth").Return(nil).Once()
+	requestRepo.On("Retag", ctx, ids, []string{"smoke"}, []string(nil)).Return(nil).Once()
+
+	require.NoError(t, service.DeleteRequests(ctx, ids))
+	require.NoError(t, service.MoveRequests(ctx, ids, "auth"))
+	require.NoError(t, service.RetagRequests(ctx, ids, []string{"smoke"}, nil))
+
+	// Empty selections and retags without tags do nothing.
+	require.NoError(t, service.DeleteRequests(ctx, nil))
+	require.NoError(t, service.MoveRequests(ctx, nil, "auth"))
+	require.NoError(t, service.RetagRequests(ctx, ids, nil, nil))
+
+	requestRepo.AssertExpectations(t)
+}
+
+func TestBulkService_RequestsNotFound(t *testing.T) {
+	requestRepo := new(MockRequestRepository)
+	service := NewBulkService(requestRepo, new(MockHistoryRepository), slog.Default())
+	ctx := context.Background()
+	ids := []string{"a", "missing"}
+
+	requestRepo.On("DeleteMany", ctx, ids).Return(repository.ErrNotFound)
+	requestRepo.On("MoveMany", ctx, ids, "").Return(repository.ErrNotFound)
+	requestRepo.On("Retag", ctx, ids, mock.Anything, mock.Anything).Return(repository.ErrNotFound)
+
+	assert.ErrorIs(t, service.DeleteRequests(ctx, ids), repository.ErrNotFound)
+	assert.ErrorIs(t, service.MoveRequests(ctx, ids, ""), repository.ErrNotFound)
+	assert.ErrorIs(t, service.RetagRequests(ctx, ids, nil, []string{"smoke"}), repository.ErrNotFound)
+}
+
+func TestBulkService_DeleteHistory(t *testing.T) {
+	historyRepo := new(MockHistoryRepository)
+	service := NewBulkService(new(MockRequestRepository), historyRepo, slog.Default())
+	ctx := context.Background()
+
+	filter := repository.HistoryFilter{RequestIDs: []string{"a"}, Before: "2026-01-01T00:00:00Z", FailedOnly: true}
+	historyRepo.On("DeleteMatching", ctx, filter).Return(int64(3), nil).Once()
+
+	deleted, err := service.DeleteHistory(ctx, filter)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), deleted)
+
+	_, err = service.DeleteHistory(ctx, repository.HistoryFilter{})
+	assert.ErrorIs(t, err, ErrEmptyHistoryFilter)
+
+	_, err = service.DeleteHistory(ctx, repository.HistoryFilter{After: "yesterday"})
+	assert.ErrorContains(t, err, `invalid history filter time "yesterday"`)
+
+	historyRepo.On("DeleteMatching", ctx, repository.HistoryFilter{FailedOnly: true}).Return(int64(0), errors.New("disk full")).Once()
+	_, err = service.DeleteHistory(ctx, repository.HistoryFilter{FailedOnly: true})
+	assert.ErrorContains(t, err, "failed to delete history: disk full")
+
+	historyRepo.AssertExpectations(t)
+}
+
+func TestBulkService_ExportHistory(t *testing.T) {
+	historyRepo := new(MockHistoryRepository)
+	service := NewBulkService(new(MockRequestRepository), historyRepo, slog.Default())
+	ctx := context.Background()
+	filter := repository.HistoryFilter{IDs: []string{"h1", "h2"}}
+
+	_, _, err := service.ExportHistory(ctx, filter)
+	assert.ErrorIs(t, err, ErrNoHistoryArchiver)
+
+	archiver := new(MockHistoryArchiver)
+	service.SetArchiver(archiver)
+
+	entries := []*repository.HistoryEntry{{ID: "h1"}, {ID: "h2"}}
+	historyRepo.On("FindMatching", ctx, filter, 0).Return(entries, nil).Once()
+	archiver.On("Archive", entries).Return("/archive/history.ndjson.gz", nil).Once()
+
+	path, count, err := service.ExportHistory(ctx, filter)
+	require.NoError(t, err)
+	assert.Equal(t, "/archive/history.ndjson.gz", path)
+	assert.Equal(t, 2, count)
+
+	// Nothing is written when no entries match.
+	historyRepo.On("FindMatching", ctx, repository.HistoryFilter{IDs: []string{"gone"}}, 0).
+		Return([]*repository.HistoryEntry{}, nil).Once()
+	path, count, err = service.ExportHistory(ctx, repository.HistoryFilter{IDs: []string{"gone"}})
+	require.NoError(t, err)
+	assert.Empty(t, path)
+	assert.Zero(t, count)
+
+	historyRepo.On("FindMatching", ctx, repository.HistoryFilter{IDs: []string{"h3"}}, 0).
+		Return([]*repository.HistoryEntry{{ID: "h3"}}, nil).Once()
+	archiver.On("Archive", mock.Anything).Return("", errors.New("disk full")).Once()
+	_, _, err = service.ExportHistory(ctx, repository.HistoryFilter{IDs: []string{"h3"}})
+	assert.ErrorContains(t, err, "failed to export history: disk full")
+
+	historyRepo.AssertExpectations(t)
+	archiver.AssertExpectations(t)
+}
@@ -0,0 +1,164 @@
+package app
+
+import (
+	"fmt"
+	"log/slog"
+	"net/url"
+	"strings"
+
+	"github.com/williajm/curly/internal/domain"
+	"github.com/williajm/curly/internal/infrastructure/codegen"
+	"github.com/williajm/curly/internal/infrastructure/sharelink"
+)
+
+// CodegenService renders requests as code snippets for sharing outside curly.
+type CodegenService struct {
+	logger *slog.Logger
+}
+
+// NewCodegenService creates a new CodegenService.
+func NewCodegenService(logger *slog.Logger) *CodegenService {
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	return &CodegenService{
+		logger: logger,
+	}
+}
+
+// Languages returns the supported snippet languages.
+func (s *CodegenService) Languages() []string {
+	return codegen.Languages
+}
+
+// Generate renders req as a snippet in language.
+// The snippet includes the request's auth, so it may contain credentials.
+func (s *CodegenService) Generate(req *domain.Request, language string) (string, error) {
+	snippet, err := codegen.Generate(req, language)
+	if err != nil {
+		s.logger.Warn("failed to generate snippet",
+			"request_id", req.ID,
+			"language", language,
+			"error", err,
+		)
+		return "", fmt.Errorf("failed to generate %s snippet: %w", language, err)
+	}
+
+	s.logger.Debug("snippet generated", "request_id", req.ID, "language", language)
+	return snippet, nil
+}
+
+// GenerateMasked is Generate with the request's secrets replaced by
+// domain.SecretMask: auth credentials, a password in the URL, and headers
+// and query parameters whose names look secret, such as "Authorization".
+// The snippet can be shared, but not run as it is.
+func (s *CodegenService) GenerateMasked(req *domain.Request, language string) (string, error) {
+	return s.Generate(maskSecrets(req), language)
+}
+
+// maskSecrets returns a copy of req with its secrets masked, leaving req
+// unchanged. Values holding a {{name}} reference are kept, as the secret they
+// refer to lives in an environment rather than in the request.
+func maskSecrets(req *domain.Request) *domain.Request {
+	masked := req.Clone()
+
+	switch auth := req.AuthConfig.(type) {
+	case *domain.BasicAuth:
+		masked.AuthConfig = domain.NewBasicAuth(auth.Username, maskValue(auth.Password))
+	case *domain.BearerAuth:
+		masked.AuthConfig = domain.NewBearerAuth(maskValue(auth.Token))
+	case *domain.APIKeyAuth:
+		masked.AuthConfig = domain.NewAPIKeyAuth(auth.Key, maskValue(auth.Value), auth.Location)
+	}
+
+	if parsed, err := url.Parse(masked.URL); err == nil && parsed.User != nil {
+		if password, ok := parsed.User.Password(); ok && !domain.HasVariables(password) {
+			parsed.User = url.UserPassword(parsed.User.Username(), domain.SecretMask)
+			masked.URL = parsed.String()
+		}
+	}
+	masked.URL = maskURLQuery(masked.URL)
+
+	for name, value := range masked.Headers {
+		if domain.IsSecretName(name) {
+			masked.Headers[name] = maskValue(value)
+		}
+	}
+	for _, params := range []map[string]string{masked.QueryParams, masked.DisabledQueryParams} {
+		for name, value := range params {
+			if domain.IsSecretName(name) {
+				params[name] = maskValue(value)
+			}
+		}
+	}
+	return masked
+}
+
+// maskURLQuery returns rawURL with the values of the parameters in its query
+// string whose names look secret masked, keeping the order and encoding of
+// the rest.
+func maskURLQuery(rawURL string) string {
+	base, params, fragment := splitURLQuery(rawURL)
+	for i, param := range params {
+		raw, value, found := strings.Cut(param, "=")
+		if found && domain.IsSecretName(unescapeQuery(raw)) && maskValue(unescapeQuery(value)) == domain.SecretMask {
+			params[i] = raw + "=" + domain.SecretMask
+		}
+	}
+	return joinURLQuery(base, params, fragment)
+}
+
+// splitURLQuery splits rawURL into the part before its query string, the
+// parameters of the query string, and the fragment with its '#', if any.
+func splitURLQuery(rawURL string) (string, []string, string) {
+	rest, fragment := rawURL, ""
+	if i := strings.IndexByte(rawURL, '#'); i >= 0 {
+		rest, fragment = rawURL[:i], rawURL[i:]
+	}
+	base, query, found := strings.Cut(rest, "?")
+	if !found {
+		return rawURL, nil, ""
+	}
+	return base, strings.Split(query, "&"), fragment
+}
+
+// joinURLQuery puts together a URL split by splitURLQuery.
+func joinURLQuery(base string, params []string, fragment string) string {
+	if params == nil {
+		return base + fragment
+	}
+	return base + "?" + strings.Join(params, "&") + fragment
+}
+
+// unescapeQuery decodes a query parameter name or value, or returns it as it
+// is if it is not valid.
+func unescapeQuery(s string) string {
+	if unescaped, err := url.QueryUnescape(s); err == nil {
+		return unescaped
+	}
+	return s
+}
+
+// maskValue returns domain.SecretMask in place of a secret value, keeping
+// empty values and {{name}} references.
+func maskValue(value string) string {
+	if value == "" || domain.HasVariables(value) {
+		return value
+	}
+	return domain.SecretMask
+}
+
+// ShareLink encodes req as a link that `curly import link` and the TUI's
+// import dialog accept. Credentials and scripts are left out unless opts
+// includes them, and described in the returned list.
+func (s *CodegenService) ShareLink(req *domain.Request, opts sharelink.Options) (string, []string, error) {
+	link, stripped, err := sharelink.Encode(req, opts)
+	if err != nil {
+		s.logger.Warn("failed to encode share link", "request_id", req.ID, "error", err)
+		return "", nil, fmt.Errorf("failed to create share link: %w", err)
+	}
+
+	s.logger.Debug("share link created", "request_id", req.ID, "stripped", len(stripped))
+	return link, stripped, nil
+}
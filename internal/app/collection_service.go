@@ -0,0 +1,121 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+
+	"github.com/williajm/curly/internal/domain"
+	"github.com/williajm/curly/internal/infrastructure/repository"
+)
+
+// FolderSeparator separates the levels of a nested folder name, so that the
+// folder "users/admin" sits inside "users" in the collection tree.
+const FolderSeparator = "/"
+
+// CollectionNode is a folder in the collection tree with the folders nested
+// in it and the saved requests it holds.
+type CollectionNode struct {
+	// Name is the last level of the folder name ("" for the top level).
+	Name string
+
+	// Path is the full folder name, as stored on its requests.
+	Path string
+
+	// Folders are the nested folders, sorted by name.
+	Folders []*CollectionNode
+
+	// Requests are the requests in the folder in their curated order.
+	Requests []*domain.Request
+}
+
+// Count returns the number of requests in the folder and the folders nested
+// in it.
+func (n *CollectionNode) Count() int {
+	count := len(n.Requests)
+	for _, folder := range n.Folders {
+		count += folder.Count()
+	}
+	return count
+}
+
+// CollectionService organises saved requests into a tree of folders.
+type CollectionService struct {
+	repo   repository.RequestRepository
+	logger *slog.Logger
+}
+
+// NewCollectionService creates a new CollectionService with the provided dependencies.
+// The repository is required and must not be nil.
+func NewCollectionService(repo repository.RequestRepository, logger *slog.Logger) *CollectionService {
+	if repo == nil {
+		panic("request repository cannot be nil")
+	}
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	return &CollectionService{
+		repo:   repo,
+		logger: logger,
+	}
+}
+
+// Tree returns the saved requests as a tree of folders rooted at the top
+// level. Folders that only hold nested folders are included so that every
+// folder can be reached.
+func (s *CollectionService) Tree(ctx context.Context) (*CollectionNode, error) {
+	requests, err := s.repo.FindAll(ctx)
+	if err != nil {
+		s.logger.Error("failed to load collections", "error", err)
+		return nil, fmt.Errorf("failed to load collections: %w", err)
+	}
+
+	root := &CollectionNode{}
+	nodes := map[string]*CollectionNode{"": root}
+	var folder func(path string) *CollectionNode
+	folder = func(path string) *CollectionNode {
+		if node, ok := nodes[path]; ok {
+			return node
+		}
+		parent, name := "", path
+		if i := strings.LastIndex(path, FolderSeparator); i >= 0 {
+			parent, name = path[:i], path[i+len(FolderSeparator):]
+		}
+		node := &CollectionNode{Name: name, Path: path}
+		nodes[path] = node
+		p := folder(parent)
+		p.Folders = append(p.Folders, node)
+		return node
+	}
+
+	for _, req := range requests {
+		node := folder(req.Folder)
+		node.Requests = append(node.Requests, req)
+	}
+	for _, node := range nodes {
+		sort.Slice(node.Folders, func(i, j int) bool { return node.Folders[i].Name < node.Folders[j].Name })
+		sort.SliceStable(node.Requests, func(i, j int) bool { return node.Requests[i].Position < node.Requests[j].Position })
+	}
+
+	s.logger.Debug("collections loaded", "requests", len(requests), "folders", len(nodes)-1)
+	return root, nil
+}
+
+// MoveToFolder moves saved requests to the end of folder, in the order given.
+// The empty folder is the top level. If any request does not exist, none are moved.
+func (s *CollectionService) MoveToFolder(ctx context.Context, ids []string, folder string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	s.logger.Info("moving requests to folder", "count", len(ids), "folder", folder)
+
+	if err := s.repo.MoveMany(ctx, ids, folder); err != nil {
+		s.logger.Error("failed to move requests", "count", len(ids), "folder", folder, "error", err)
+		return fmt.Errorf("failed to move requests: %w", err)
+	}
+
+	return nil
+}
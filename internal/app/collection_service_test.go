@@ -0,0 +1,95 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/williajm/curly/internal/domain"
+	"github.com/williajm/curly/internal/infrastructure/repository"
+)
+
+func TestNewCollectionService_PanicsOnNilRepo(t *testing.T) {
+	assert.Panics(t, func() { NewCollectionService(nil, slog.Default()) })
+}
+
+func TestCollectionService_Tree(t *testing.T) {
+	repo := new(MockRequestRepository)
+	service := NewCollectionService(repo, slog.Default())
+	ctx := context.Background()
+
+	request := func(name, folder string, position int) *domain.Request {
+		req := domain.NewRequest()
+		req.Name, req.Folder, req.Position = name, folder, position
+		return req
+	}
+	repo.On("FindAll", ctx).Return([]*domain.Request{
+		request("health", "", 0),
+		request("list users", "users", 1),
+		request("create user", "users", 0),
+		request("promote", "users/admin", 0),
+		request("login", "auth", 0),
+		request("ping", "deep/er/est", 0),
+	}, nil).Once()
+
+	root, err := service.Tree(ctx)
+	require.NoError(t, err)
+
+	assert.Equal(t, 6, root.Count())
+	require.Len(t, root.Requests, 1)
+	assert.Equal(t, "health", root.Requests[0].Name)
+
+	var names []string
+	for _, folder := range root.Folders {
+		names = append(names, folder.Name)
+	}
+	assert.Equal(t, []string{"auth", "deep", "users"}, names)
+
+	users := root.Folders[2]
+	assert.Equal(t, "users", users.Path)
+	assert.Equal(t, 3, users.Count())
+	require.Len(t, users.Requests, 2)
+	assert.Equal(t, "create user", users.Requests[0].Name)
+	assert.Equal(t, "list users", users.Requests[1].Name)
+	require.Len(t, users.Folders, 1)
+	assert.Equal(t, "admin", users.Folders[0].Name)
+	assert.Equal(t, "users/admin", users.Folders[0].Path)
+
+	// Intermediate folders without requests are still in the tree.
+	deep := root.Folders[1]
+	assert.Empty(t, deep.Requests)
+	require.Len(t, deep.Folders, 1)
+	require.Len(t, deep.Folders[0].Folders, 1)
+	assert.Equal(t, "deep/er/est", deep.Folders[0].Folders[0].Path)
+
+	repo.AssertExpectations(t)
+}
+
+func TestCollectionService_TreeError(t *testing.T) {
+	repo := new(MockRequestRepository)
+	service := NewCollectionService(repo, slog.Default())
+	ctx := context.Background()
+
+	repo.On("FindAll", ctx).Return(nil, errors.New("database error")).Once()
+
+	_, err := service.Tree(ctx)
+	assert.ErrorContains(t, err, "failed to load collections")
+}
+
+func TestCollectionService_MoveToFolder(t *testing.T) {
+	repo := new(MockRequestRepository)
+	service := NewCollectionService(repo, slog.Default())
+	ctx := context.Background()
+
+	repo.On("MoveMany", ctx, []string{"a"}, "users/admin").Return(nil).Once()
+	repo.On("MoveMany", ctx, []string{"missing"}, "").Return(repository.ErrNotFound).Once()
+
+	require.NoError(t, service.MoveToFolder(ctx, []string{"a"}, "users/admin"))
+	assert.ErrorIs(t, service.MoveToFolder(ctx, []string{"missing"}, ""), repository.ErrNotFound)
+	require.NoError(t, service.MoveToFolder(ctx, nil, "users"))
+
+	repo.AssertExpectations(t)
+}
@@ -0,0 +1,174 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	"github.com/williajm/curly/internal/infrastructure/diff"
+	"github.com/williajm/curly/internal/infrastructure/repository"
+)
+
+// ResponseDiff compares the responses recorded by two history entries.
+type ResponseDiff struct {
+	// A and B are the compared entries.
+	A, B *repository.HistoryEntry
+
+	// StatusChanged reports whether the status (or error) differs.
+	StatusChanged bool
+
+	// Headers lists header differences, ordered by name.
+	Headers []diff.Change
+
+	// JSON reports whether both bodies are JSON, in which case Body lists
+	// their structural differences.
+	JSON bool
+	Body []diff.Change
+
+	// BodyLines is a line diff of the bodies, pretty-printed when JSON.
+	BodyLines []diff.Line
+}
+
+// Identical reports whether the responses have the same status, headers and body.
+func (d *ResponseDiff) Identical() bool {
+	if d.StatusChanged || len(d.Headers) > 0 {
+		return false
+	}
+	if d.JSON {
+		return len(d.Body) == 0
+	}
+	for _, line := range d.BodyLines {
+		if line.Op != diff.Equal {
+			return false
+		}
+	}
+	return true
+}
+
+// Format renders the comparison as text: the status, the header changes,
+// and either the JSON changes or a unified line diff of the body.
+func (d *ResponseDiff) Format() string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "--- %s (%s)\n", d.A.ID, d.A.ExecutedAt)
+	fmt.Fprintf(&b, "+++ %s (%s)\n", d.B.ID, d.B.ExecutedAt)
+
+	if d.StatusChanged {
+		fmt.Fprintf(&b, "\nStatus: %s → %s\n", statusText(d.A), statusText(d.B))
+	} else {
+		fmt.Fprintf(&b, "\nStatus: %s (unchanged)\n", statusText(d.A))
+	}
+
+	b.WriteString("\nHeaders:\n")
+	writeChanges(&b, d.Headers)
+
+	b.WriteString("\nBody:\n")
+	switch {
+	case d.JSON:
+		writeChanges(&b, d.Body)
+	case d.Identical() || len(d.BodyLines) == 0:
+		b.WriteString("  (no changes)\n")
+	default:
+		for _, line := range d.BodyLines {
+			b.WriteString(line.String())
+			b.WriteString("\n")
+		}
+	}
+
+	return b.String()
+}
+
+// writeChanges writes one indented line per change.
+func writeChanges(b *strings.Builder, changes []diff.Change) {
+	if len(changes) == 0 {
+		b.WriteString("  (no changes)\n")
+		return
+	}
+	for _, c := range changes {
+		b.WriteString("  ")
+		b.WriteString(c.String())
+		b.WriteString("\n")
+	}
+}
+
+// statusText describes an entry's outcome: its status, or its error.
+func statusText(e *repository.HistoryEntry) string {
+	if e.Error != "" {
+		return "error: " + e.Error
+	}
+	if e.Status != "" {
+		return e.Status
+	}
+	return strconv.Itoa(e.StatusCode)
+}
+
+// DiffService compares responses recorded in history.
+type DiffService struct {
+	repo   repository.HistoryRepository
+	logger *slog.Logger
+}
+
+// NewDiffService creates a new DiffService with the provided dependencies.
+// The repository is required and must not be nil.
+func NewDiffService(repo repository.HistoryRepository, logger *slog.Logger) *DiffService {
+	if repo == nil {
+		panic("history repository cannot be nil")
+	}
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	return &DiffService{
+		repo:   repo,
+		logger: logger,
+	}
+}
+
+// Compare compares the responses of two history entries. Bodies that are
+// both JSON are compared structurally; others are compared line by line.
+func (s *DiffService) Compare(a, b *repository.HistoryEntry) *ResponseDiff {
+	d := &ResponseDiff{
+		A:             a,
+		B:             b,
+		StatusChanged: statusText(a) != statusText(b),
+		Headers:       diff.Map(s.headers(a), s.headers(b)),
+	}
+
+	bodyA, bodyB := []byte(a.ResponseBody), []byte(b.ResponseBody)
+	if changes, err := diff.JSON(bodyA, bodyB); err == nil {
+		d.JSON = true
+		d.Body = changes
+		d.BodyLines = diff.Lines(diff.Indent(bodyA), diff.Indent(bodyB))
+	} else {
+		d.BodyLines = diff.Lines(a.ResponseBody, b.ResponseBody)
+	}
+
+	return d
+}
+
+// CompareIDs loads two history entries, with their full bodies, and compares them.
+func (s *DiffService) CompareIDs(ctx context.Context, idA, idB string) (*ResponseDiff, error) {
+	a, err := s.repo.FindByID(ctx, idA)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load history entry %s: %w", idA, err)
+	}
+	b, err := s.repo.FindByID(ctx, idB)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load history entry %s: %w", idB, err)
+	}
+
+	s.logger.Debug("comparing history entries", "a", idA, "b", idB)
+	return s.Compare(a, b), nil
+}
+
+// headers decodes an entry's response headers, logging those that cannot be
+// decoded.
+func (s *DiffService) headers(e *repository.HistoryEntry) map[string]string {
+	headers, err := e.Headers()
+	if err != nil {
+		s.logger.Warn("failed to decode response headers", "history_id", e.ID, "error", err)
+	}
+	return headers
+}
@@ -0,0 +1,98 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/williajm/curly/internal/infrastructure/diff"
+	"github.com/williajm/curly/internal/infrastructure/repository"
+)
+
+func TestNewDiffService_PanicsOnNilRepo(t *testing.T) {
+	assert.Panics(t, func() { NewDiffService(nil, slog.Default()) })
+}
+
+func TestDiffService_Compare_JSON(t *testing.T) {
+	service := NewDiffService(new(MockHistoryRepository), slog.Default())
+
+	a := &repository.HistoryEntry{
+		ID:              "a",
+		StatusCode:      200,
+		Status:          "200 OK",
+		ResponseHeaders: `{"Content-Type":"application/json","X-Trace":"1"}`,
+		ResponseBody:    `{"name":"Ada","roles":["admin"]}`,
+	}
+	b := &repository.HistoryEntry{
+		ID:              "b",
+		StatusCode:      200,
+		Status:          "200 OK",
+		ResponseHeaders: `{"Content-Type":["application/json"],"X-Trace":["2"]}`,
+		ResponseBody:    `{"name":"Ada","roles":["admin","ops"]}`,
+	}
+
+	d := service.Compare(a, b)
+
+	assert.False(t, d.StatusChanged)
+	assert.Equal(t, []diff.Change{{Path: "X-Trace", Kind: diff.Changed, Old: "1", New: "2"}}, d.Headers)
+	assert.True(t, d.JSON)
+	assert.Equal(t, []diff.Change{{Path: "$.roles[1]", Kind: diff.Added, New: `"ops"`}}, d.Body)
+	assert.False(t, d.Identical())
+
+	out := d.Format()
+	assert.Contains(t, out, "Status: 200 OK (unchanged)")
+	assert.Contains(t, out, "~ X-Trace: 1 → 2")
+	assert.Contains(t, out, `+ $.roles[1]: "ops"`)
+}
+
+func TestDiffService_Compare_Text(t *testing.T) {
+	service := NewDiffService(new(MockHistoryRepository), slog.Default())
+
+	a := &repository.HistoryEntry{ID: "a", StatusCode: 200, Status: "200 OK", ResponseBody: "line one\nline two"}
+	b := &repository.HistoryEntry{ID: "b", Error: "connection refused"}
+
+	d := service.Compare(a, b)
+
+	assert.True(t, d.StatusChanged)
+	assert.False(t, d.JSON)
+	assert.Equal(t, []diff.Line{
+		{Op: diff.Delete, Text: "line one"},
+		{Op: diff.Delete, Text: "line two"},
+	}, d.BodyLines)
+
+	out := d.Format()
+	assert.Contains(t, out, "Status: 200 OK → error: connection refused")
+	assert.Contains(t, out, "-line one\n-line two\n")
+}
+
+func TestDiffService_Compare_Identical(t *testing.T) {
+	service := NewDiffService(new(MockHistoryRepository), slog.Default())
+
+	entry := &repository.HistoryEntry{ID: "a", StatusCode: 200, ResponseBody: "same"}
+	d := service.Compare(entry, entry)
+
+	assert.True(t, d.Identical())
+	assert.Contains(t, d.Format(), "Body:\n  (no changes)")
+}
+
+func TestDiffService_CompareIDs(t *testing.T) {
+	repo := new(MockHistoryRepository)
+	service := NewDiffService(repo, slog.Default())
+
+	a := &repository.HistoryEntry{ID: "a", StatusCode: 200, ResponseBody: "{}"}
+	b := &repository.HistoryEntry{ID: "b", StatusCode: 500, ResponseBody: "{}"}
+	repo.On("FindByID", mock.Anything, "a").Return(a, nil)
+	repo.On("FindByID", mock.Anything, "b").Return(b, nil)
+	repo.On("FindByID", mock.Anything, "missing").Return(nil, errors.New("not found"))
+
+	d, err := service.CompareIDs(context.Background(), "a", "b")
+	require.NoError(t, err)
+	assert.True(t, d.StatusChanged)
+
+	_, err = service.CompareIDs(context.Background(), "a", "missing")
+	assert.ErrorContains(t, err, "failed to load history entry missing")
+}
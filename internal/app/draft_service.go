@@ -0,0 +1,159 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/williajm/curly/internal/domain"
+	"github.com/williajm/curly/internal/infrastructure/repository"
+)
+
+// RecoveredDraft is a draft left by an earlier session, ready to restore.
+type RecoveredDraft struct {
+	// Draft is the draft as it was last saved. If it changes a saved
+	// request, its request is that request with the draft's changes, so
+	// what the request builder does not edit, such as its folder, is kept.
+	Draft *domain.Draft
+
+	// Saved is the saved request the draft changes, or nil if the draft is
+	// of a request that was never saved.
+	Saved *domain.Request
+}
+
+// DraftService keeps the request builder's unsaved changes as a draft, so
+// they can be restored if curly exits before they are saved. Each service
+// keeps the draft of one TUI session.
+type DraftService struct {
+	drafts   repository.DraftRepository
+	requests repository.RequestRepository
+	logger   *slog.Logger
+
+	// id is the ID of this session's draft.
+	id string
+
+	// mu guards saved, the snapshot of the draft last saved ("" if this
+	// session has none), and serializes writes of the draft.
+	mu    sync.Mutex
+	saved string
+}
+
+// NewDraftService creates a new DraftService with the provided dependencies.
+// The repositories are required and must not be nil.
+func NewDraftService(drafts repository.DraftRepository, requests repository.RequestRepository, logger *slog.Logger) *DraftService {
+	if drafts == nil {
+		panic("draft repository cannot be nil")
+	}
+	if requests == nil {
+		panic("request repository cannot be nil")
+	}
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	return &DraftService{
+		drafts:   drafts,
+		requests: requests,
+		logger:   logger,
+		id:       uuid.New().String(),
+	}
+}
+
+// Save keeps req as this session's draft. Nothing is written if the draft
+// is unchanged.
+func (s *DraftService) Save(ctx context.Context, req *domain.Request) error {
+	snapshot, err := repository.MarshalRequestSnapshot(req)
+	if err != nil {
+		return fmt.Errorf("failed to save draft: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if snapshot == s.saved {
+		return nil
+	}
+
+	draft := &domain.Draft{ID: s.id, Request: req, UpdatedAt: time.Now()}
+	if err := s.drafts.Save(ctx, draft); err != nil {
+		s.logger.Error("failed to save draft", "request_id", req.ID, "error", err)
+		return fmt.Errorf("failed to save draft: %w", err)
+	}
+	s.logger.Debug("draft saved", "draft_id", s.id, "request_id", req.ID)
+	s.saved = snapshot
+	return nil
+}
+
+// Discard deletes this session's draft, if it has one, once the request
+// builder has no unsaved changes left.
+func (s *DraftService) Discard(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.saved == "" {
+		return nil
+	}
+
+	if err := s.drafts.Delete(ctx, s.id); err != nil && !errors.Is(err, repository.ErrNotFound) {
+		s.logger.Error("failed to discard draft", "draft_id", s.id, "error", err)
+		return fmt.Errorf("failed to discard draft: %w", err)
+	}
+	s.logger.Debug("draft discarded", "draft_id", s.id)
+	s.saved = ""
+	return nil
+}
+
+// Recover returns the most recent draft left by an earlier session, or nil
+// if there is none.
+func (s *DraftService) Recover(ctx context.Context) (*RecoveredDraft, error) {
+	drafts, err := s.drafts.FindAll(ctx)
+	if err != nil {
+		s.logger.Error("failed to load drafts", "error", err)
+		return nil, fmt.Errorf("failed to load drafts: %w", err)
+	}
+
+	for _, draft := range drafts {
+		if draft.ID == s.id {
+			continue
+		}
+		recovered := &RecoveredDraft{Draft: draft}
+		saved, err := s.requests.FindByID(ctx, draft.Request.ID)
+		switch {
+		case err == nil:
+			recovered.Saved = saved
+			draft.Request = withDraftChanges(saved, draft.Request)
+		case !errors.Is(err, repository.ErrNotFound):
+			return nil, fmt.Errorf("failed to load the request of the draft: %w", err)
+		}
+		s.logger.Info("found draft to recover", "draft_id", draft.ID, "request_id", draft.Request.ID, "updated_at", draft.UpdatedAt)
+		return recovered, nil
+	}
+	return nil, nil
+}
+
+// Delete deletes the draft with the given ID, once a recovered draft is
+// restored or the user chooses not to.
+func (s *DraftService) Delete(ctx context.Context, id string) error {
+	if err := s.drafts.Delete(ctx, id); err != nil && !errors.Is(err, repository.ErrNotFound) {
+		s.logger.Error("failed to delete draft", "draft_id", id, "error", err)
+		return fmt.Errorf("failed to delete draft: %w", err)
+	}
+	return nil
+}
+
+// withDraftChanges returns a copy of saved with the fields the request
+// builder edits taken from draft.
+func withDraftChanges(saved, draft *domain.Request) *domain.Request {
+	req := saved.Clone()
+	req.Name = draft.Name
+	req.Method = draft.Method
+	req.URL = draft.URL
+	req.Headers = draft.Headers
+	req.QueryParams = draft.QueryParams
+	req.Body = draft.Body
+	req.AuthConfig = draft.AuthConfig
+	return req
+}
@@ -0,0 +1,176 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/williajm/curly/internal/domain"
+	"github.com/williajm/curly/internal/infrastructure/repository"
+)
+
+// MockDraftRepository is a mock implementation of repository.DraftRepository.
+type MockDraftRepository struct {
+	mock.Mock
+}
+
+func (m *MockDraftRepository) Save(ctx context.Context, draft *domain.Draft) error {
+	args := m.Called(ctx, draft)
+	return args.Error(0)
+}
+
+func (m *MockDraftRepository) FindAll(ctx context.Context) ([]*domain.Draft, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Draft), args.Error(1)
+}
+
+func (m *MockDraftRepository) Delete(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func TestNewDraftService_NilRepositories(t *testing.T) {
+	assert.Panics(t, func() {
+		NewDraftService(nil, new(MockRequestRepository), slog.Default())
+	})
+	assert.Panics(t, func() {
+		NewDraftService(new(MockDraftRepository), nil, slog.Default())
+	})
+}
+
+func TestDraftService_SaveAndDiscard(t *testing.T) {
+	drafts := new(MockDraftRepository)
+	service := NewDraftService(drafts, new(MockRequestRepository), slog.Default())
+	ctx := context.Background()
+
+	// Nothing is deleted while the session has no draft.
+	require.NoError(t, service.Discard(ctx))
+
+	var saved []*domain.Draft
+	drafts.On("Save", ctx, mock.Anything).Run(func(args mock.Arguments) {
+		saved = append(saved, args.Get(1).(*domain.Draft))
+	}).Return(nil)
+
+	req := domain.NewRequestWithMethodAndURL("POST", "https://api.example.com/users")
+	require.NoError(t, service.Save(ctx, req))
+	require.NoError(t, service.Save(ctx, req.Clone()), "an unchanged draft is not written again")
+	changed := req.Clone()
+	changed.Body = `{"name":"ada"}`
+	require.NoError(t, service.Save(ctx, changed))
+
+	require.Len(t, saved, 2)
+	assert.Equal(t, saved[0].ID, saved[1].ID, "a session keeps one draft")
+	assert.Same(t, changed, saved[1].Request)
+	assert.WithinDuration(t, time.Now(), saved[1].UpdatedAt, time.Minute)
+
+	drafts.On("Delete", ctx, saved[0].ID).Return(nil).Once()
+	require.NoError(t, service.Discard(ctx))
+	require.NoError(t, service.Discard(ctx))
+	drafts.AssertExpectations(t)
+
+	// Once discarded, the same request is saved again.
+	require.NoError(t, service.Save(ctx, changed))
+	assert.Len(t, saved, 3)
+}
+
+func TestDraftService_SaveFails(t *testing.T) {
+	drafts := new(MockDraftRepository)
+	service := NewDraftService(drafts, new(MockRequestRepository), slog.Default())
+	ctx := context.Background()
+
+	req := domain.NewRequestWithMethodAndURL("GET", "https://api.example.com/users")
+	drafts.On("Save", ctx, mock.Anything).Return(errors.New("disk I/O error")).Once()
+	assert.ErrorContains(t, service.Save(ctx, req), "failed to save draft: disk I/O error")
+
+	// The failed draft is written on the next try.
+	drafts.On("Save", ctx, mock.Anything).Return(nil).Once()
+	require.NoError(t, service.Save(ctx, req))
+	drafts.AssertExpectations(t)
+}
+
+func TestDraftService_Recover(t *testing.T) {
+	drafts := new(MockDraftRepository)
+	requests := new(MockRequestRepository)
+	service := NewDraftService(drafts, requests, slog.Default())
+	ctx := context.Background()
+
+	saved := domain.NewRequestWithMethodAndURL("GET", "https://api.example.com/users")
+	saved.Name = "List Users"
+	saved.Folder = "users"
+	saved.Tags = []string{"smoke"}
+	saved.PreRequestScript = `request.headers["X-Id"] = crypto.randomUUID();`
+
+	edited := saved.Clone()
+	edited.Name = "List Users"
+	edited.URL = "https://api.example.com/users?active=true"
+	edited.Headers["Accept"] = "application/json"
+	edited.Folder = ""
+	edited.PreRequestScript = ""
+	updatedAt := time.Now().Add(-time.Minute)
+
+	drafts.On("FindAll", ctx).Return([]*domain.Draft{{ID: "earlier-session", Request: edited, UpdatedAt: updatedAt}}, nil)
+	requests.On("FindByID", ctx, saved.ID).Return(saved, nil)
+
+	recovered, err := service.Recover(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, recovered)
+	assert.Equal(t, "earlier-session", recovered.Draft.ID)
+	assert.Equal(t, updatedAt, recovered.Draft.UpdatedAt)
+	assert.Same(t, saved, recovered.Saved)
+
+	req := recovered.Draft.Request
+	assert.Equal(t, saved.ID, req.ID)
+	assert.Equal(t, "https://api.example.com/users?active=true", req.URL)
+	assert.Equal(t, "application/json", req.Headers["Accept"])
+	assert.Equal(t, "users", req.Folder, "what the builder does not edit comes from the saved request")
+	assert.Equal(t, []string{"smoke"}, req.Tags)
+	assert.Equal(t, saved.PreRequestScript, req.PreRequestScript)
+	assert.Equal(t, "https://api.example.com/users", saved.URL, "the saved request is left as it was")
+}
+
+func TestDraftService_Recover_NewRequest(t *testing.T) {
+	drafts := new(MockDraftRepository)
+	requests := new(MockRequestRepository)
+	service := NewDraftService(drafts, requests, slog.Default())
+	ctx := context.Background()
+
+	req := domain.NewRequestWithMethodAndURL("POST", "https://api.example.com/orders")
+	drafts.On("FindAll", ctx).Return([]*domain.Draft{{ID: "earlier-session", Request: req}}, nil)
+	requests.On("FindByID", ctx, req.ID).Return(nil, repository.ErrNotFound)
+
+	recovered, err := service.Recover(ctx)
+	require.NoError(t, err)
+	assert.Nil(t, recovered.Saved)
+	assert.Same(t, req, recovered.Draft.Request)
+}
+
+func TestDraftService_Recover_SkipsOwnDraft(t *testing.T) {
+	drafts := new(MockDraftRepository)
+	service := NewDraftService(drafts, new(MockRequestRepository), slog.Default())
+	ctx := context.Background()
+
+	drafts.On("FindAll", ctx).Return([]*domain.Draft{{ID: service.id, Request: domain.NewRequest()}}, nil)
+	recovered, err := service.Recover(ctx)
+	require.NoError(t, err)
+	assert.Nil(t, recovered)
+}
+
+func TestDraftService_Delete(t *testing.T) {
+	drafts := new(MockDraftRepository)
+	service := NewDraftService(drafts, new(MockRequestRepository), slog.Default())
+	ctx := context.Background()
+
+	drafts.On("Delete", ctx, "earlier-session").Return(repository.ErrNotFound).Once()
+	require.NoError(t, service.Delete(ctx, "earlier-session"), "a draft already deleted is not an error")
+
+	drafts.On("Delete", ctx, "earlier-session").Return(errors.New("database is locked")).Once()
+	assert.ErrorContains(t, service.Delete(ctx, "earlier-session"), "database is locked")
+}
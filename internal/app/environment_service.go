@@ -0,0 +1,153 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/williajm/curly/internal/domain"
+	"github.com/williajm/curly/internal/infrastructure/repository"
+)
+
+// VariableSource supplies the variables that fill {{name}} references in
+// requests before they are sent. EnvironmentService implements it.
+type VariableSource interface {
+	// ActiveVariables returns the variables in effect, or nil if there are none.
+	ActiveVariables(ctx context.Context) (map[string]string, error)
+}
+
+// EnvironmentService manages environments and which one is active.
+type EnvironmentService struct {
+	repo   repository.EnvironmentRepository
+	logger *slog.Logger
+}
+
+// NewEnvironmentService creates a new EnvironmentService with the provided dependencies.
+// The repository is required and must not be nil.
+func NewEnvironmentService(repo repository.EnvironmentRepository, logger *slog.Logger) *EnvironmentService {
+	if repo == nil {
+		panic("environment repository cannot be nil")
+	}
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	return &EnvironmentService{
+		repo:   repo,
+		logger: logger,
+	}
+}
+
+// List returns every environment, ordered by name.
+func (s *EnvironmentService) List(ctx context.Context) ([]*domain.Environment, error) {
+	envs, err := s.repo.FindAll(ctx)
+	if err != nil {
+		s.logger.Error("failed to list environments", "error", err)
+		return nil, fmt.Errorf("failed to list environments: %w", err)
+	}
+	return envs, nil
+}
+
+// Find returns the environment with the given name.
+func (s *EnvironmentService) Find(ctx context.Context, name string) (*domain.Environment, error) {
+	env, err := s.repo.FindByName(ctx, strings.TrimSpace(name))
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, fmt.Errorf("environment %q not found: %w", name, err)
+		}
+		return nil, fmt.Errorf("failed to load environment: %w", err)
+	}
+	return env, nil
+}
+
+// Active returns the active environment, or nil if none is active.
+func (s *EnvironmentService) Active(ctx context.Context) (*domain.Environment, error) {
+	env, err := s.repo.FindActive(ctx)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, nil
+		}
+		s.logger.Error("failed to load active environment", "error", err)
+		return nil, fmt.Errorf("failed to load active environment: %w", err)
+	}
+	return env, nil
+}
+
+// ActiveVariables returns the variables of the active environment, or nil
+// if none is active.
+func (s *EnvironmentService) ActiveVariables(ctx context.Context) (map[string]string, error) {
+	env, err := s.Active(ctx)
+	if err != nil || env == nil {
+		return nil, err
+	}
+	return env.Variables, nil
+}
+
+// Use makes the named environment the active one and returns it. An empty
+// name deactivates every environment and returns nil.
+func (s *EnvironmentService) Use(ctx context.Context, name string) (*domain.Environment, error) {
+	if strings.TrimSpace(name) == "" {
+		s.logger.Info("deactivating environments")
+		if err := s.repo.SetActive(ctx, ""); err != nil {
+			return nil, fmt.Errorf("failed to deactivate environments: %w", err)
+		}
+		return nil, nil
+	}
+
+	env, err := s.Find(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("activating environment", "name", env.Name)
+	if err := s.repo.SetActive(ctx, env.ID); err != nil {
+		s.logger.Error("failed to activate environment", "name", env.Name, "error", err)
+		return nil, fmt.Errorf("failed to activate environment: %w", err)
+	}
+	return env, nil
+}
+
+// Create creates an empty environment with the given name.
+func (s *EnvironmentService) Create(ctx context.Context, name string) (*domain.Environment, error) {
+	env := domain.NewEnvironment(strings.TrimSpace(name))
+	if err := env.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid environment: %w", err)
+	}
+
+	s.logger.Info("creating environment", "name", env.Name)
+	if err := s.repo.Create(ctx, env); err != nil {
+		s.logger.Error("failed to create environment", "name", env.Name, "error", err)
+		return nil, fmt.Errorf("failed to create environment: %w", err)
+	}
+	return env, nil
+}
+
+// Save persists changes to an existing environment's name and variables.
+func (s *EnvironmentService) Save(ctx context.Context, env *domain.Environment) error {
+	if err := env.Validate(); err != nil {
+		return fmt.Errorf("invalid environment: %w", err)
+	}
+
+	s.logger.Info("saving environment", "name", env.Name, "variables", len(env.Variables))
+	if err := s.repo.Update(ctx, env); err != nil {
+		s.logger.Error("failed to save environment", "name", env.Name, "error", err)
+		return fmt.Errorf("failed to save environment: %w", err)
+	}
+	return nil
+}
+
+// Delete removes the named environment.
+func (s *EnvironmentService) Delete(ctx context.Context, name string) error {
+	env, err := s.Find(ctx, name)
+	if err != nil {
+		return err
+	}
+
+	s.logger.Info("deleting environment", "name", env.Name)
+	if err := s.repo.Delete(ctx, env.ID); err != nil {
+		return fmt.Errorf("failed to delete environment: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/williajm/curly/internal/domain"
+	"github.com/williajm/curly/internal/infrastructure/repository"
+)
+
+func TestNewEnvironmentService_PanicsOnNilRepo(t *testing.T) {
+	assert.Panics(t, func() { NewEnvironmentService(nil, slog.Default()) })
+}
+
+func TestEnvironmentService_Active(t *testing.T) {
+	repo := new(MockEnvironmentRepository)
+	service := NewEnvironmentService(repo, slog.Default())
+	ctx := context.Background()
+
+	repo.On("FindActive", ctx).Return(nil, repository.ErrNotFound).Twice()
+	env, err := service.Active(ctx)
+	require.NoError(t, err)
+	assert.Nil(t, env, "no active environment is not an error")
+
+	vars, err := service.ActiveVariables(ctx)
+	require.NoError(t, err)
+	assert.Nil(t, vars)
+
+	staging := domain.NewEnvironment("staging")
+	staging.Set("baseUrl", "https://staging.example.com", false)
+	repo.On("FindActive", ctx).Return(staging, nil).Once()
+	vars, err = service.ActiveVariables(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "https://staging.example.com", vars["baseUrl"])
+
+	repo.On("FindActive", ctx).Return(nil, errors.New("db down")).Once()
+	_, err = service.Active(ctx)
+	assert.ErrorContains(t, err, "failed to load active environment")
+	repo.AssertExpectations(t)
+}
+
+func TestEnvironmentService_Use(t *testing.T) {
+	repo := new(MockEnvironmentRepository)
+	service := NewEnvironmentService(repo, slog.Default())
+	ctx := context.Background()
+
+	staging := domain.NewEnvironment("staging")
+	repo.On("FindByName", ctx, "staging").Return(staging, nil)
+	repo.On("SetActive", ctx, staging.ID).Return(nil)
+	env, err := service.Use(ctx, "staging")
+	require.NoError(t, err)
+	assert.Equal(t, staging, env)
+
+	repo.On("SetActive", ctx, "").Return(nil)
+	env, err = service.Use(ctx, " ")
+	require.NoError(t, err)
+	assert.Nil(t, env)
+
+	repo.On("FindByName", ctx, "prod").Return(nil, repository.ErrNotFound)
+	_, err = service.Use(ctx, "prod")
+	assert.ErrorIs(t, err, repository.ErrNotFound)
+	assert.ErrorContains(t, err, `environment "prod" not found`)
+	repo.AssertExpectations(t)
+}
+
+func TestEnvironmentService_CreateAndSave(t *testing.T) {
+	repo := new(MockEnvironmentRepository)
+	service := NewEnvironmentService(repo, slog.Default())
+	ctx := context.Background()
+
+	repo.On("Create", ctx, mock.MatchedBy(func(env *domain.Environment) bool { return env.Name == "dev" })).Return(nil)
+	env, err := service.Create(ctx, " dev ")
+	require.NoError(t, err)
+	assert.Equal(t, "dev", env.Name)
+
+	_, err = service.Create(ctx, "")
+	assert.ErrorIs(t, err, domain.ErrEmptyEnvironmentName)
+
+	env.Set("token", "abc", true)
+	repo.On("Update", ctx, env).Return(nil)
+	require.NoError(t, service.Save(ctx, env))
+
+	env.Variables["bad name"] = "x"
+	assert.ErrorIs(t, service.Save(ctx, env), domain.ErrInvalidVariableName)
+	repo.AssertNumberOfCalls(t, "Update", 1)
+}
+
+func TestEnvironmentService_Delete(t *testing.T) {
+	repo := new(MockEnvironmentRepository)
+	service := NewEnvironmentService(repo, slog.Default())
+	ctx := context.Background()
+
+	dev := domain.NewEnvironment("dev")
+	repo.On("FindByName", ctx, "dev").Return(dev, nil)
+	repo.On("Delete", ctx, dev.ID).Return(nil)
+	require.NoError(t, service.Delete(ctx, "dev"))
+	repo.AssertExpectations(t)
+}
@@ -0,0 +1,161 @@
+package app
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"time"
+	"unicode/utf8"
+
+	"github.com/williajm/curly/internal/domain"
+)
+
+// executionDocument is the JSON form of an ExecutionResult.
+type executionDocument struct {
+	Request    sentSummary       `json:"request"`
+	Passed     bool              `json:"passed"`
+	Error      string            `json:"error,omitempty"`
+	Response   *responseDocument `json:"response"`
+	Assertions assertionSummary  `json:"assertions"`
+}
+
+type sentSummary struct {
+	ID      string            `json:"id"`
+	Name    string            `json:"name"`
+	Method  string            `json:"method"`
+	URL     string            `json:"url"`
+	Headers map[string]string `json:"headers"`
+}
+
+type responseDocument struct {
+	StatusCode   int               `json:"status_code"`
+	Status       string            `json:"status"`
+	Headers      map[string]string `json:"headers"`
+	SetCookies   []string          `json:"set_cookies"`
+	DurationMs   int64             `json:"duration_ms"`
+	Timestamp    time.Time         `json:"timestamp"`
+	Timing       *timingSummary    `json:"timing"`
+	TLS          *tlsSummary       `json:"tls,omitempty"`
+	Size         int               `json:"size"`
+	Body         string            `json:"body"`
+	BodyEncoding string            `json:"body_encoding,omitempty"`
+}
+
+// timingSummary holds the phases of a request in milliseconds, with
+// fractions, since local phases often take well under one.
+type timingSummary struct {
+	DNSMs            float64 `json:"dns_ms"`
+	ConnectMs        float64 `json:"connect_ms"`
+	TLSMs            float64 `json:"tls_ms"`
+	WaitMs           float64 `json:"wait_ms"`
+	DownloadMs       float64 `json:"download_ms"`
+	TotalMs          float64 `json:"total_ms"`
+	ReusedConnection bool    `json:"reused_connection"`
+}
+
+type tlsSummary struct {
+	Version            string `json:"version"`
+	CipherSuite        string `json:"cipher_suite"`
+	NegotiatedProtocol string `json:"negotiated_protocol,omitempty"`
+	ServerName         string `json:"server_name"`
+}
+
+type assertionSummary struct {
+	Passed   bool     `json:"passed"`
+	Failures []string `json:"failures"`
+}
+
+// WriteJSON writes the result as one indented JSON document: the request as
+// it was sent, whether it passed, and the response's status, headers, timing
+// breakdown, body and assertion results. A body that is not valid UTF-8 is
+// base64-encoded, with body_encoding set to "base64". The response is null if
+// the request got none, with error saying why.
+func (r ExecutionResult) WriteJSON(w io.Writer) error {
+	sent := r.Sent
+	if sent == nil && r.Response != nil {
+		sent = r.Response.Sent
+	}
+	if sent == nil {
+		sent = r.Request
+	}
+	fullURL, err := sent.FullURL()
+	if err != nil {
+		fullURL = sent.URL
+	}
+
+	doc := executionDocument{
+		Request: sentSummary{
+			ID:      r.Request.ID,
+			Name:    r.Request.Name,
+			Method:  sent.Method,
+			URL:     fullURL,
+			Headers: nonNilMap(sent.Headers),
+		},
+		Passed:     r.Passed(),
+		Assertions: assertionSummary{Passed: true, Failures: []string{}},
+	}
+	if r.Err != nil {
+		doc.Error = r.Err.Error()
+	}
+
+	if resp := r.Response; resp != nil {
+		doc.Assertions.Failures = append(doc.Assertions.Failures, resp.AssertionFailures...)
+		doc.Assertions.Passed = len(resp.AssertionFailures) == 0
+
+		response := &responseDocument{
+			StatusCode: resp.StatusCode,
+			Status:     resp.Status,
+			Headers:    nonNilMap(resp.Headers),
+			SetCookies: append([]string{}, resp.SetCookies...),
+			DurationMs: resp.DurationMillis(),
+			Timestamp:  resp.Timestamp,
+			Timing:     timingSummaryOf(resp.Timing),
+			Size:       len(resp.Body),
+			Body:       resp.Body,
+		}
+		if !utf8.ValidString(resp.Body) {
+			response.Body = base64.StdEncoding.EncodeToString([]byte(resp.Body))
+			response.BodyEncoding = "base64"
+		}
+		if resp.TLS != nil {
+			response.TLS = &tlsSummary{
+				Version:            resp.TLS.Version,
+				CipherSuite:        resp.TLS.CipherSuite,
+				NegotiatedProtocol: resp.TLS.NegotiatedProtocol,
+				ServerName:         resp.TLS.ServerName,
+			}
+		}
+		doc.Response = response
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(doc)
+}
+
+// timingSummaryOf converts timing, or returns nil if it was not measured.
+func timingSummaryOf(timing *domain.Timing) *timingSummary {
+	if timing == nil {
+		return nil
+	}
+	ms := func(d time.Duration) float64 {
+		return float64(d.Microseconds()) / 1000
+	}
+	return &timingSummary{
+		DNSMs:            ms(timing.DNS),
+		ConnectMs:        ms(timing.Connect),
+		TLSMs:            ms(timing.TLS),
+		WaitMs:           ms(timing.Wait),
+		DownloadMs:       ms(timing.Download),
+		TotalMs:          ms(timing.Total()),
+		ReusedConnection: timing.ReusedConnection,
+	}
+}
+
+// nonNilMap returns m, or an empty map if it is nil, so it encodes as {}.
+func nonNilMap(m map[string]string) map[string]string {
+	if m == nil {
+		return map[string]string{}
+	}
+	return m
+}
@@ -0,0 +1,97 @@
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/williajm/curly/internal/domain"
+)
+
+func TestExecutionResult_WriteJSON(t *testing.T) {
+	req := domain.NewRequestWithMethodAndURL("GET", "https://api.example.com/users/{{id}}")
+	req.Name = "Get user"
+	sent := req.Clone()
+	sent.URL = "https://api.example.com/users/7"
+	sent.SetQueryParam("expand", "teams")
+	sent.Headers["Accept"] = "application/json"
+
+	result := ExecutionResult{
+		Request: req,
+		Sent:    sent,
+		Response: &domain.Response{
+			StatusCode:        200,
+			Status:            "200 OK",
+			Headers:           map[string]string{"Content-Type": "application/json"},
+			Body:              `{"id": 7}`,
+			Duration:          42 * time.Millisecond,
+			AssertionFailures: []string{"name: required"},
+			Timing:            &domain.Timing{DNS: 1500 * time.Microsecond, Wait: 40 * time.Millisecond},
+			TLS:               &domain.TLSInfo{Version: "TLS 1.3", ServerName: "api.example.com"},
+		},
+	}
+
+	var out bytes.Buffer
+	require.NoError(t, result.WriteJSON(&out))
+
+	var doc executionDocument
+	require.NoError(t, json.Unmarshal(out.Bytes(), &doc))
+	assert.Equal(t, "Get user", doc.Request.Name)
+	assert.Equal(t, "https://api.example.com/users/7?expand=teams", doc.Request.URL)
+	assert.Equal(t, "application/json", doc.Request.Headers["Accept"])
+	assert.False(t, doc.Passed)
+	assert.Empty(t, doc.Error)
+
+	require.NotNil(t, doc.Response)
+	assert.Equal(t, 200, doc.Response.StatusCode)
+	assert.Equal(t, `{"id": 7}`, doc.Response.Body)
+	assert.Empty(t, doc.Response.BodyEncoding)
+	assert.Equal(t, int64(42), doc.Response.DurationMs)
+	require.NotNil(t, doc.Response.Timing)
+	assert.Equal(t, 1.5, doc.Response.Timing.DNSMs)
+	assert.Equal(t, 41.5, doc.Response.Timing.TotalMs)
+	require.NotNil(t, doc.Response.TLS)
+	assert.Equal(t, "TLS 1.3", doc.Response.TLS.Version)
+
+	assert.False(t, doc.Assertions.Passed)
+	assert.Equal(t, []string{"name: required"}, doc.Assertions.Failures)
+}
+
+func TestExecutionResult_WriteJSON_BinaryBody(t *testing.T) {
+	req := domain.NewRequestWithMethodAndURL("GET", "https://api.example.com/logo.png")
+	result := ExecutionResult{
+		Request:  req,
+		Response: &domain.Response{StatusCode: 200, Status: "200 OK", Body: "\x89PNG\xff"},
+	}
+
+	var out bytes.Buffer
+	require.NoError(t, result.WriteJSON(&out))
+
+	var doc executionDocument
+	require.NoError(t, json.Unmarshal(out.Bytes(), &doc))
+	assert.True(t, doc.Passed)
+	assert.Equal(t, "base64", doc.Response.BodyEncoding)
+	assert.Equal(t, "iVBOR/8=", doc.Response.Body)
+	assert.Equal(t, 5, doc.Response.Size)
+	assert.Nil(t, doc.Response.Timing)
+	assert.Contains(t, out.String(), `"headers": {}`)
+}
+
+func TestExecutionResult_WriteJSON_Error(t *testing.T) {
+	req := domain.NewRequestWithMethodAndURL("GET", "https://down.example.com/")
+	result := ExecutionResult{Request: req, Err: errors.New("connection refused")}
+
+	var out bytes.Buffer
+	require.NoError(t, result.WriteJSON(&out))
+
+	assert.Contains(t, out.String(), `"response": null`)
+	var doc executionDocument
+	require.NoError(t, json.Unmarshal(out.Bytes(), &doc))
+	assert.False(t, doc.Passed)
+	assert.Equal(t, "connection refused", doc.Error)
+	assert.Equal(t, "https://down.example.com/", doc.Request.URL)
+}
@@ -0,0 +1,136 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+
+	"github.com/williajm/curly/internal/domain"
+	"github.com/williajm/curly/internal/infrastructure/codegen"
+	"github.com/williajm/curly/internal/infrastructure/postman"
+	"github.com/williajm/curly/internal/infrastructure/repository"
+)
+
+// Export formats.
+const (
+	// ExportFormatPostman is a Postman v2.1 collection.
+	ExportFormatPostman = "postman"
+
+	// ExportFormatCurl is a shell script with a curl command per request.
+	ExportFormatCurl = "curl"
+)
+
+// ExportFormats lists the supported export formats.
+var ExportFormats = []string{ExportFormatPostman, ExportFormatCurl}
+
+// ExportService writes saved requests in formats other tools read, the
+// counterpart of ImportService.
+type ExportService struct {
+	repo   repository.RequestRepository
+	logger *slog.Logger
+}
+
+// NewExportService creates a new ExportService with the provided dependencies.
+// The repository is required and must not be nil.
+func NewExportService(repo repository.RequestRepository, logger *slog.Logger) *ExportService {
+	if repo == nil {
+		panic("request repository cannot be nil")
+	}
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	return &ExportService{
+		repo:   repo,
+		logger: logger,
+	}
+}
+
+// Export renders the saved requests in folder and the folders nested in it,
+// or every saved request if folder is empty, in format. It returns the
+// rendered export and the number of requests in it. The export includes the
+// requests' auth, so it may contain credentials.
+func (s *ExportService) Export(ctx context.Context, format, folder string) (string, int, error) {
+	if format != ExportFormatPostman && format != ExportFormatCurl {
+		return "", 0, fmt.Errorf("unsupported export format %q (supported: %s)", format, strings.Join(ExportFormats, ", "))
+	}
+
+	requests, err := requestsInFolder(ctx, s.repo, folder)
+	if err != nil {
+		s.logger.Error("failed to load requests for export", "error", err)
+		return "", 0, err
+	}
+	if len(requests) == 0 {
+		if folder == "" {
+			return "", 0, fmt.Errorf("there are no saved requests to export")
+		}
+		return "", 0, fmt.Errorf("folder %q has no requests", folder)
+	}
+
+	var out string
+	if format == ExportFormatPostman {
+		name := folder
+		if name == "" {
+			name = "curly"
+		}
+		data, err := postman.Export(name, folder, requests)
+		if err != nil {
+			s.logger.Error("failed to export Postman collection", "folder", folder, "error", err)
+			return "", 0, fmt.Errorf("failed to export Postman collection: %w", err)
+		}
+		out = string(data) + "\n"
+	} else {
+		out, err = curlScript(requests)
+		if err != nil {
+			return "", 0, err
+		}
+	}
+
+	s.logger.Info("requests exported", "format", format, "folder", folder, "count", len(requests))
+	return out, len(requests), nil
+}
+
+// requestsInFolder returns the saved requests in folder and its nested
+// folders, or every saved request if folder is empty, ordered by folder and
+// then position.
+func requestsInFolder(ctx context.Context, repo repository.RequestRepository, folder string) ([]*domain.Request, error) {
+	all, err := repo.FindAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load requests: %w", err)
+	}
+
+	var requests []*domain.Request
+	for _, req := range all {
+		if folder == "" || req.Folder == folder || strings.HasPrefix(req.Folder, folder+"/") {
+			requests = append(requests, req)
+		}
+	}
+	sort.SliceStable(requests, func(i, j int) bool {
+		if requests[i].Folder != requests[j].Folder {
+			return requests[i].Folder < requests[j].Folder
+		}
+		return requests[i].Position < requests[j].Position
+	})
+	return requests, nil
+}
+
+// curlScript renders requests as a shell script, each curl command preceded
+// by a comment naming the request and its folder.
+func curlScript(requests []*domain.Request) (string, error) {
+	var script strings.Builder
+	script.WriteString("#!/bin/sh\n")
+	for _, req := range requests {
+		command, err := codegen.Generate(req, codegen.LanguageCurl)
+		if err != nil {
+			return "", fmt.Errorf("failed to export %q: %w", req.Name, err)
+		}
+		name := req.Name
+		if req.Folder != "" {
+			name = req.Folder + "/" + req.Name
+		}
+		fmt.Fprintf(&script, "\n# %s\n%s\n", name, strings.TrimRight(command, "\n"))
+	}
+	return script.String(), nil
+}
@@ -0,0 +1,84 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/williajm/curly/internal/domain"
+	"github.com/williajm/curly/internal/infrastructure/postman"
+)
+
+func exportTestRequests() []*domain.Request {
+	users := domain.NewRequestWithMethodAndURL("GET", "https://shop.example.com/users")
+	users.Name = "List users"
+	users.Folder = "Shop/Users"
+
+	health := domain.NewRequestWithMethodAndURL("GET", "https://shop.example.com/health")
+	health.Name = "Health"
+	health.Folder = "Shop"
+
+	other := domain.NewRequestWithMethodAndURL("GET", "https://other.example.com/")
+	other.Name = "Other"
+	other.Folder = "Shopping"
+
+	return []*domain.Request{users, health, other}
+}
+
+func TestNewExportService_NilRepo(t *testing.T) {
+	assert.Panics(t, func() {
+		NewExportService(nil, slog.Default())
+	})
+}
+
+func TestExportService_Export_Postman(t *testing.T) {
+	repo := new(MockRequestRepository)
+	repo.On("FindAll", mock.Anything).Return(exportTestRequests(), nil)
+	service := NewExportService(repo, slog.Default())
+
+	out, count, err := service.Export(context.Background(), ExportFormatPostman, "Shop")
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+
+	imported, err := postman.Parse([]byte(out), "Shop")
+	require.NoError(t, err)
+	require.Len(t, imported.Requests, 2)
+	assert.Equal(t, "Shop", imported.Requests[0].Folder)
+	assert.Equal(t, "Shop/Users", imported.Requests[1].Folder)
+}
+
+func TestExportService_Export_Curl(t *testing.T) {
+	repo := new(MockRequestRepository)
+	repo.On("FindAll", mock.Anything).Return(exportTestRequests(), nil)
+	service := NewExportService(repo, slog.Default())
+
+	out, count, err := service.Export(context.Background(), ExportFormatCurl, "")
+	require.NoError(t, err)
+	assert.Equal(t, 3, count)
+	assert.Contains(t, out, "#!/bin/sh\n")
+	assert.Contains(t, out, "# Shop/Health\ncurl")
+	assert.Less(t, strings.Index(out, "# Shop/Health"), strings.Index(out, "# Shop/Users/List users"))
+	assert.Contains(t, out, "https://other.example.com/")
+}
+
+func TestExportService_Export_Errors(t *testing.T) {
+	repo := new(MockRequestRepository)
+	repo.On("FindAll", mock.Anything).Return(exportTestRequests(), nil)
+	service := NewExportService(repo, slog.Default())
+
+	_, _, err := service.Export(context.Background(), "har", "")
+	assert.ErrorContains(t, err, `unsupported export format "har"`)
+
+	_, _, err = service.Export(context.Background(), ExportFormatPostman, "Missing")
+	assert.ErrorContains(t, err, `folder "Missing" has no requests`)
+
+	failing := new(MockRequestRepository)
+	failing.On("FindAll", mock.Anything).Return(nil, errors.New("db error"))
+	_, _, err = NewExportService(failing, slog.Default()).Export(context.Background(), ExportFormatCurl, "")
+	assert.ErrorContains(t, err, "failed to load requests")
+}
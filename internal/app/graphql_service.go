@@ -0,0 +1,181 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+
+	"github.com/williajm/curly/internal/domain"
+	"github.com/williajm/curly/internal/infrastructure/graphql"
+	"github.com/williajm/curly/internal/infrastructure/http"
+)
+
+// ErrNoGraphQLSchema is returned when a query is checked against an endpoint
+// whose schema has not been introspected.
+var ErrNoGraphQLSchema = errors.New("no GraphQL schema loaded for this endpoint; introspect it first")
+
+// SchemaCache persists introspected GraphQL schemas by endpoint.
+// graphql.Cache implements it.
+type SchemaCache interface {
+	// Load returns the schema cached for endpoint, or graphql.ErrNotCached.
+	Load(endpoint string) (*graphql.Schema, error)
+
+	// Save caches the schema of endpoint.
+	Save(endpoint string, schema *graphql.Schema) error
+}
+
+// GraphQLService fetches GraphQL schemas by introspection and uses them to
+// validate and complete queries. Schemas are kept in memory per endpoint and,
+// if a cache is set, on disk.
+type GraphQLService struct {
+	requests   *RequestService
+	httpClient http.Client
+	cache      SchemaCache
+	logger     *slog.Logger
+
+	mu      sync.Mutex
+	schemas map[string]*graphql.Schema
+}
+
+// NewGraphQLService creates a new GraphQLService with the provided dependencies.
+// The request service resolves the {{name}} references of the requests
+// introspected. The request service and HTTP client are required and must
+// not be nil.
+func NewGraphQLService(requests *RequestService, httpClient http.Client, logger *slog.Logger) *GraphQLService {
+	if requests == nil {
+		panic("request service cannot be nil")
+	}
+	if httpClient == nil {
+		panic("http client cannot be nil")
+	}
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	return &GraphQLService{
+		requests:   requests,
+		httpClient: httpClient,
+		logger:     logger,
+		schemas:    make(map[string]*graphql.Schema),
+	}
+}
+
+// SetCache makes introspected schemas persist in cache.
+// Passing nil keeps them in memory only.
+func (s *GraphQLService) SetCache(cache SchemaCache) {
+	s.cache = cache
+}
+
+// Introspect sends the introspection query to req's URL, with its headers and
+// auth and its {{name}} references resolved as for a normal send, and caches
+// the schema for the endpoint as written in req. The cached schema is
+// returned without a request unless refresh is set.
+func (s *GraphQLService) Introspect(ctx context.Context, req *domain.Request, refresh bool) (*graphql.Schema, error) {
+	endpoint := req.URL
+	if !refresh {
+		if schema, ok := s.Schema(endpoint); ok {
+			return schema, nil
+		}
+	}
+
+	body, err := json.Marshal(map[string]string{"query": graphql.IntrospectionQuery})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode introspection query: %w", err)
+	}
+	introspect := req.Clone()
+	introspect.Method = domain.MethodPost
+	introspect.Body = string(body)
+	for name := range introspect.Headers {
+		if strings.EqualFold(name, "Content-Type") {
+			delete(introspect.Headers, name)
+		}
+	}
+	introspect.Headers["Content-Type"] = "application/json"
+	introspect.ResponseSchema = ""
+	introspect.ResponseContract = ""
+	if introspect, err = s.requests.resolve(ctx, introspect); err != nil {
+		return nil, fmt.Errorf("failed to introspect schema: %w", err)
+	}
+
+	s.logger.Info("introspecting GraphQL schema", "endpoint", endpoint)
+	resp, err := s.httpClient.Execute(ctx, introspect)
+	if err != nil {
+		s.logger.Error("GraphQL introspection failed", "endpoint", endpoint, "error", err)
+		return nil, fmt.Errorf("failed to introspect schema: %w", err)
+	}
+
+	schema, err := graphql.ParseIntrospection([]byte(resp.Body))
+	if err != nil {
+		if !resp.IsSuccess() {
+			err = fmt.Errorf("server returned %s", resp.Status)
+		}
+		s.logger.Error("GraphQL introspection failed", "endpoint", endpoint, "error", err)
+		return nil, fmt.Errorf("failed to introspect schema: %w", err)
+	}
+
+	s.mu.Lock()
+	s.schemas[endpoint] = schema
+	s.mu.Unlock()
+
+	if s.cache != nil {
+		if err := s.cache.Save(endpoint, schema); err != nil {
+			// The schema is still usable from memory.
+			s.logger.Warn("failed to cache GraphQL schema", "endpoint", endpoint, "error", err)
+		}
+	}
+
+	s.logger.Info("GraphQL schema introspected", "endpoint", endpoint, "types", len(schema.Types))
+	return schema, nil
+}
+
+// Schema returns the schema cached for endpoint, loading it from the disk
+// cache if needed. It reports false if the endpoint has not been introspected.
+// Misses are remembered, so repeated lookups such as one per redraw do not
+// read the disk again.
+func (s *GraphQLService) Schema(endpoint string) (*graphql.Schema, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if schema, ok := s.schemas[endpoint]; ok {
+		return schema, schema != nil
+	}
+	if s.cache == nil {
+		return nil, false
+	}
+
+	schema, err := s.cache.Load(endpoint)
+	if err != nil {
+		if !errors.Is(err, graphql.ErrNotCached) {
+			s.logger.Warn("failed to load cached GraphQL schema", "endpoint", endpoint, "error", err)
+		}
+		s.schemas[endpoint] = nil
+		return nil, false
+	}
+	s.schemas[endpoint] = schema
+	return schema, true
+}
+
+// Validate checks a query against the cached schema of endpoint.
+// It returns ErrNoGraphQLSchema if the endpoint has not been introspected.
+func (s *GraphQLService) Validate(endpoint, query string) ([]graphql.Error, error) {
+	schema, ok := s.Schema(endpoint)
+	if !ok {
+		return nil, ErrNoGraphQLSchema
+	}
+	return schema.Validate(query), nil
+}
+
+// Complete suggests what can be typed at a byte offset in query, using the
+// cached schema of endpoint.
+// It returns ErrNoGraphQLSchema if the endpoint has not been introspected.
+func (s *GraphQLService) Complete(endpoint, query string, offset int) (graphql.Completion, error) {
+	schema, ok := s.Schema(endpoint)
+	if !ok {
+		return graphql.Completion{}, ErrNoGraphQLSchema
+	}
+	return schema.Complete(query, offset), nil
+}
@@ -0,0 +1,195 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/williajm/curly/internal/domain"
+	"github.com/williajm/curly/internal/infrastructure/graphql"
+)
+
+const graphQLTestEndpoint = "https://api.example.com/graphql"
+
+const graphQLTestIntrospection = `{"data": {"__schema": {
+  "queryType": {"name": "Query"},
+  "types": [
+    {"kind": "OBJECT", "name": "Query", "fields": [
+      {"name": "user", "type": {"kind": "OBJECT", "name": "User"}}
+    ]},
+    {"kind": "OBJECT", "name": "User", "fields": [
+      {"name": "id", "type": {"kind": "SCALAR", "name": "ID"}},
+      {"name": "name", "type": {"kind": "SCALAR", "name": "String"}}
+    ]},
+    {"kind": "SCALAR", "name": "ID"},
+    {"kind": "SCALAR", "name": "String"}
+  ]
+}}}`
+
+// newTestGraphQLService returns a GraphQLService sending through httpClient,
+// with a request service that resolves from no variables.
+func newTestGraphQLService(httpClient *MockHTTPClient) *GraphQLService {
+	requests := NewRequestService(new(MockRequestRepository), httpClient, new(MockHistoryRepository), slog.Default())
+	return NewGraphQLService(requests, httpClient, slog.Default())
+}
+
+func TestNewGraphQLService_NilDependencies(t *testing.T) {
+	requests := NewRequestService(new(MockRequestRepository), new(MockHTTPClient), new(MockHistoryRepository), slog.Default())
+	assert.Panics(t, func() {
+		NewGraphQLService(nil, new(MockHTTPClient), slog.Default())
+	})
+	assert.Panics(t, func() {
+		NewGraphQLService(requests, nil, slog.Default())
+	})
+}
+
+func TestGraphQLService_Introspect(t *testing.T) {
+	httpClient := new(MockHTTPClient)
+	service := newTestGraphQLService(httpClient)
+
+	req := domain.NewRequestWithMethodAndURL(domain.MethodGet, graphQLTestEndpoint)
+	req.Headers["Authorization"] = "Bearer token"
+	req.Body = `{"query": "{ user { id } }"}`
+
+	httpClient.On("Execute", mock.Anything, mock.MatchedBy(func(r *domain.Request) bool {
+		return r.Method == domain.MethodPost &&
+			r.URL == graphQLTestEndpoint &&
+			r.Headers["Authorization"] == "Bearer token" &&
+			r.Headers["Content-Type"] == "application/json" &&
+			strings.Contains(r.Body, "IntrospectionQuery")
+	})).Return(&domain.Response{StatusCode: 200, Status: "200 OK", Body: graphQLTestIntrospection}, nil).Once()
+
+	schema, err := service.Introspect(context.Background(), req, false)
+	require.NoError(t, err)
+	assert.NotNil(t, schema.Type("User"))
+
+	// The request being edited is left alone.
+	assert.Equal(t, domain.MethodGet, req.Method)
+	assert.Equal(t, `{"query": "{ user { id } }"}`, req.Body)
+
+	// The schema is cached, so introspecting again does not send a request.
+	again, err := service.Introspect(context.Background(), req, false)
+	require.NoError(t, err)
+	assert.Same(t, schema, again)
+	httpClient.AssertExpectations(t)
+
+	errs, err := service.Validate(graphQLTestEndpoint, "{ user { email } }")
+	require.NoError(t, err)
+	require.Len(t, errs, 1)
+	assert.Equal(t, `1:10: cannot query field "email" on type "User"`, errs[0].String())
+
+	completion, err := service.Complete(graphQLTestEndpoint, "{ user { na", 11)
+	require.NoError(t, err)
+	assert.Equal(t, []graphql.Suggestion{{Label: "name", Detail: "String"}}, completion.Suggestions)
+}
+
+func TestGraphQLService_IntrospectResolvesVariables(t *testing.T) {
+	httpClient := new(MockHTTPClient)
+	requests := NewRequestService(new(MockRequestRepository), httpClient, new(MockHistoryRepository), slog.Default())
+	requests.SetVariables(staticVariables{"base_url": "https://api.example.com", "token": "s3cret"})
+	service := NewGraphQLService(requests, httpClient, slog.Default())
+
+	req := domain.NewRequestWithMethodAndURL(domain.MethodPost, "{{base_url}}/graphql")
+	req.Headers["Authorization"] = "Bearer {{token}}"
+	req.Headers["content-type"] = "application/graphql"
+
+	httpClient.On("Execute", mock.Anything, mock.MatchedBy(func(r *domain.Request) bool {
+		return r.URL == graphQLTestEndpoint &&
+			r.Headers["Authorization"] == "Bearer s3cret" &&
+			r.Headers["Content-Type"] == "application/json" &&
+			len(r.Headers) == 2
+	})).Return(&domain.Response{StatusCode: 200, Status: "200 OK", Body: graphQLTestIntrospection}, nil).Once()
+
+	_, err := service.Introspect(context.Background(), req, false)
+	require.NoError(t, err)
+	httpClient.AssertExpectations(t)
+
+	// Completion looks the schema up by the URL as written in the builder.
+	_, ok := service.Schema("{{base_url}}/graphql")
+	assert.True(t, ok)
+
+	// A reference without a value fails before anything is sent.
+	req.URL = "{{missing}}/graphql"
+	_, err = service.Introspect(context.Background(), req, true)
+	assert.ErrorIs(t, err, domain.ErrUndefinedVariable)
+	httpClient.AssertNumberOfCalls(t, "Execute", 1)
+}
+
+func TestGraphQLService_IntrospectErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		resp *domain.Response
+		err  error
+		want string
+	}{
+		{name: "transport", err: errors.New("connection refused"), want: "failed to introspect schema: connection refused"},
+		{name: "status", resp: &domain.Response{StatusCode: 404, Status: "404 Not Found", Body: "not found"}, want: "server returned 404 Not Found"},
+		{name: "disabled", resp: &domain.Response{StatusCode: 200, Body: `{"errors": [{"message": "introspection is disabled"}]}`}, want: "introspection is disabled"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			httpClient := new(MockHTTPClient)
+			if tt.err != nil {
+				httpClient.On("Execute", mock.Anything, mock.Anything).Return(nil, tt.err)
+			} else {
+				httpClient.On("Execute", mock.Anything, mock.Anything).Return(tt.resp, nil)
+			}
+			service := newTestGraphQLService(httpClient)
+
+			_, err := service.Introspect(context.Background(), domain.NewRequestWithMethodAndURL(domain.MethodPost, graphQLTestEndpoint), false)
+			assert.ErrorContains(t, err, tt.want)
+			_, ok := service.Schema(graphQLTestEndpoint)
+			assert.False(t, ok)
+		})
+	}
+}
+
+func TestGraphQLService_NoSchema(t *testing.T) {
+	service := newTestGraphQLService(new(MockHTTPClient))
+
+	_, err := service.Validate(graphQLTestEndpoint, "{ user { id } }")
+	assert.ErrorIs(t, err, ErrNoGraphQLSchema)
+	_, err = service.Complete(graphQLTestEndpoint, "{ ", 2)
+	assert.ErrorIs(t, err, ErrNoGraphQLSchema)
+}
+
+func TestGraphQLService_Cache(t *testing.T) {
+	cache := graphql.NewCache(t.TempDir())
+
+	httpClient := new(MockHTTPClient)
+	httpClient.On("Execute", mock.Anything, mock.Anything).
+		Return(&domain.Response{StatusCode: 200, Body: graphQLTestIntrospection}, nil).Once()
+	first := newTestGraphQLService(httpClient)
+	first.SetCache(cache)
+	_, err := first.Introspect(context.Background(), domain.NewRequestWithMethodAndURL(domain.MethodPost, graphQLTestEndpoint), false)
+	require.NoError(t, err)
+
+	// A new service, as after a restart, finds the schema on disk.
+	second := newTestGraphQLService(new(MockHTTPClient))
+	second.SetCache(cache)
+	schema, ok := second.Schema(graphQLTestEndpoint)
+	require.True(t, ok)
+	assert.NotNil(t, schema.Type("User"))
+}
+
+func TestGraphQLService_CacheMissThenIntrospect(t *testing.T) {
+	httpClient := new(MockHTTPClient)
+	httpClient.On("Execute", mock.Anything, mock.Anything).
+		Return(&domain.Response{StatusCode: 200, Body: graphQLTestIntrospection}, nil).Once()
+	service := newTestGraphQLService(httpClient)
+	service.SetCache(graphql.NewCache(t.TempDir()))
+
+	_, ok := service.Schema(graphQLTestEndpoint)
+	require.False(t, ok)
+
+	_, err := service.Introspect(context.Background(), domain.NewRequestWithMethodAndURL(domain.MethodPost, graphQLTestEndpoint), false)
+	require.NoError(t, err)
+	_, ok = service.Schema(graphQLTestEndpoint)
+	assert.True(t, ok)
+	httpClient.AssertExpectations(t)
+}
@@ -3,17 +3,54 @@ package app
 import (
 	"context"
 	"fmt"
+	"io"
 	"log/slog"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/williajm/curly/internal/domain"
 	"github.com/williajm/curly/internal/infrastructure/repository"
 )
 
+// DefaultRetentionInterval is how often RunRetention re-applies the retention policy.
+const DefaultRetentionInterval = time.Hour
+
+// RetentionPolicy controls which history entries EnforceRetention removes.
+type RetentionPolicy struct {
+	// MaxEntries is the number of most recent entries to keep (0 = unlimited).
+	MaxEntries int
+
+	// MaxAgeDays removes entries older than this many days (0 = keep forever).
+	MaxAgeDays int
+}
+
+// enabled reports whether the policy removes anything at all.
+func (p RetentionPolicy) enabled() bool {
+	return p.MaxEntries > 0 || p.MaxAgeDays > 0
+}
+
+// HistoryArchiver exports history entries before they are deleted.
+type HistoryArchiver interface {
+	// Archive persists entries and returns the location of the archive.
+	Archive(entries []*repository.HistoryEntry) (string, error)
+}
+
+// RequestReplayer re-sends a request recorded in history.
+// RequestService implements it.
+type RequestReplayer interface {
+	// ExecuteReplay executes req and records it to history as a replay of
+	// the history entry replayOf.
+	ExecuteReplay(ctx context.Context, req *domain.Request, replayOf string) (*domain.Response, error)
+}
+
 // HistoryService manages request execution history.
 // It provides operations to retrieve, save, and cleanup history entries.
 type HistoryService struct {
-	repo   repository.HistoryRepository
-	logger *slog.Logger
+	repo     repository.HistoryRepository
+	archiver HistoryArchiver
+	replayer RequestReplayer
+	logger   *slog.Logger
 }
 
 // NewHistoryService creates a new HistoryService with the provided dependencies.
@@ -32,6 +69,17 @@ func NewHistoryService(repo repository.HistoryRepository, logger *slog.Logger) *
 	}
 }
 
+// SetArchiver makes CleanupOldHistory archive entries before deleting them.
+// Passing nil disables archiving.
+func (s *HistoryService) SetArchiver(archiver HistoryArchiver) {
+	s.archiver = archiver
+}
+
+// SetReplayer enables Replay, which re-sends requests through replayer.
+func (s *HistoryService) SetReplayer(replayer RequestReplayer) {
+	s.replayer = replayer
+}
+
 // GetHistory retrieves all history entries with optional pagination.
 // If limit is 0, all entries are returned.
 // Results are ordered by executed_at descending (newest first).
@@ -55,6 +103,105 @@ func (s *HistoryService) GetHistory(ctx context.Context, limit int) ([]*reposito
 	return entries, nil
 }
 
+// SearchHistory retrieves the history entries filter matches, newest first.
+// If limit is 0, all matching entries are returned.
+func (s *HistoryService) SearchHistory(ctx context.Context, filter repository.HistoryFilter, limit int) ([]*repository.HistoryEntry, error) {
+	s.logger.Debug("searching history",
+		"status_class", filter.StatusClass,
+		"method", filter.Method,
+		"url", filter.URLContains,
+		"failed_only", filter.FailedOnly,
+		"limit", limit,
+	)
+
+	entries, err := s.repo.FindMatching(ctx, filter, limit)
+	if err != nil {
+		s.logger.Error("failed to search history", "error", err)
+		return nil, fmt.Errorf("failed to search history: %w", err)
+	}
+
+	s.logger.Debug("history searched successfully", "count", len(entries))
+	return entries, nil
+}
+
+// GetHistoryPage retrieves a page of the history entries filter matches, newest
+// first: at most limit entries after skipping the offset newest. It also
+// returns how many entries filter matches in all, for showing how far
+// through them the page is. An empty filter matches every entry.
+func (s *HistoryService) GetHistoryPage(ctx context.Context, filter repository.HistoryFilter, offset, limit int) ([]*repository.HistoryEntry, int, error) {
+	s.logger.Debug("retrieving history page", "offset", offset, "limit", limit)
+
+	total, err := s.repo.CountMatching(ctx, filter)
+	if err != nil {
+		s.logger.Error("failed to count history", "error", err)
+		return nil, 0, fmt.Errorf("failed to count history: %w", err)
+	}
+
+	entries, err := s.repo.FindPage(ctx, filter, offset, limit)
+	if err != nil {
+		s.logger.Error("failed to retrieve history page",
+			"offset", offset,
+			"limit", limit,
+			"error", err,
+		)
+		return nil, 0, fmt.Errorf("failed to retrieve history: %w", err)
+	}
+
+	s.logger.Debug("history page retrieved successfully",
+		"count", len(entries),
+		"total", total,
+	)
+
+	return entries, total, nil
+}
+
+// ParseHistoryQuery parses a history search such as "status:4xx method:POST
+// url:users" into a filter. Words without a prefix are matched against the
+// URL, so "users" alone is the same as "url:users".
+func ParseHistoryQuery(query string) (repository.HistoryFilter, error) {
+	var filter repository.HistoryFilter
+	var words []string
+
+	for _, field := range strings.Fields(query) {
+		key, value, ok := strings.Cut(field, ":")
+		switch strings.ToLower(key) {
+		case "status":
+			class, err := parseStatusClass(value)
+			if err != nil {
+				return repository.HistoryFilter{}, err
+			}
+			filter.StatusClass = class
+			continue
+		case "method":
+			filter.Method = strings.ToUpper(value)
+			continue
+		case "url":
+			if ok {
+				words = append(words, value)
+				continue
+			}
+		}
+		words = append(words, field)
+	}
+
+	filter.URLContains = strings.Join(words, " ")
+	return filter, nil
+}
+
+// parseStatusClass parses a status class written as "4xx", "4" or a status
+// code such as "404", whose class is used.
+func parseStatusClass(value string) (int, error) {
+	digits := strings.TrimRight(strings.ToLower(value), "x")
+	class, err := strconv.Atoi(digits)
+	if err == nil && len(digits) == 3 {
+		class /= 100
+	}
+	if err != nil || class < 1 || class > 5 || (len(digits) != 1 && len(digits) != 3) {
+		return 0, fmt.Errorf("invalid status class %q: use 1xx to 5xx", value)
+	}
+	return class, nil
+}
+
 // GetRequestHistory retrieves all history entries for a specific request.
 // If limit is 0, all entries for the request are returned.
 // Results are ordered by executed_at descending (newest first).
@@ -83,6 +230,129 @@ func (s *HistoryService) GetRequestHistory(ctx context.Context, requestID string
 	return entries, nil
 }
 
+// GetRunHistory retrieves the history entries recorded by a collection run,
+// in execution order.
+func (s *HistoryService) GetRunHistory(ctx context.Context, runID string) ([]*repository.HistoryEntry, error) {
+	s.logger.Debug("retrieving run history", "run_id", runID)
+
+	entries, err := s.repo.FindByRunID(ctx, runID)
+	if err != nil {
+		s.logger.Error("failed to retrieve run history",
+			"run_id", runID,
+			"error", err,
+		)
+		return nil, fmt.Errorf("failed to retrieve run history: %w", err)
+	}
+
+	return entries, nil
+}
+
+// GetEntry retrieves a single history entry by ID, including its full response body
+// even when the body was offloaded from the history table.
+func (s *HistoryService) GetEntry(ctx context.Context, id string) (*repository.HistoryEntry, error) {
+	s.logger.Debug("retrieving history entry", "history_id", id)
+
+	entry, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		s.logger.Error("failed to retrieve history entry",
+			"history_id", id,
+			"error", err,
+		)
+		return nil, fmt.Errorf("failed to retrieve history entry: %w", err)
+	}
+
+	return entry, nil
+}
+
+// OpenEntry retrieves a single history entry with a reader of its full
+// response body, which the caller must close. An offloaded body is left as
+// its preview in the entry and read from the body store as the reader is
+// read, so a large body need not be held in memory whole.
+func (s *HistoryService) OpenEntry(ctx context.Context, id string) (*repository.HistoryEntry, io.ReadCloser, error) {
+	opener, ok := s.repo.(repository.HistoryBodyOpener)
+	if !ok {
+		entry, err := s.GetEntry(ctx, id)
+		if err != nil {
+			return nil, nil, err
+		}
+		return entry, io.NopCloser(strings.NewReader(entry.ResponseBody)), nil
+	}
+
+	s.logger.Debug("opening history entry", "history_id", id)
+
+	entry, body, err := opener.OpenByID(ctx, id)
+	if err != nil {
+		s.logger.Error("failed to retrieve history entry",
+			"history_id", id,
+			"error", err,
+		)
+		return nil, nil, fmt.Errorf("failed to retrieve history entry: %w", err)
+	}
+
+	return entry, body, nil
+}
+
+// GetRequest reconstructs the request exactly as it was sent for a history
+// entry, from the snapshot recorded with it. Entries recorded before
+// snapshots were kept cannot be reconstructed.
+func (s *HistoryService) GetRequest(ctx context.Context, historyID string) (*domain.Request, error) {
+	// Only the request snapshot is needed, so an offloaded body is not read.
+	entry, body, err := s.OpenEntry(ctx, historyID)
+	if err != nil {
+		return nil, err
+	}
+	_ = body.Close()
+
+	req, err := entry.Request()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read request for history entry %s: %w", historyID, err)
+	}
+	return req, nil
+}
+
+// Replay re-sends the request recorded by a history entry, as it was sent
+// then rather than as the saved request is now. The new execution is
+// recorded to history linked to the original entry.
+func (s *HistoryService) Replay(ctx context.Context, historyID string) (*domain.Response, error) {
+	if s.replayer == nil {
+		return nil, fmt.Errorf("replay is not available")
+	}
+
+	req, err := s.GetRequest(ctx, historyID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("replaying history entry", "history_id", historyID, "request_id", req.ID)
+	return s.replayer.ExecuteReplay(ctx, req, historyID)
+}
+
+// GetStats returns per-request execution statistics aggregated by the repository.
+func (s *HistoryService) GetStats(ctx context.Context) ([]*repository.RequestStats, error) {
+	s.logger.Debug("retrieving history stats")
+
+	stats, err := s.repo.Stats(ctx)
+	if err != nil {
+		s.logger.Error("failed to retrieve history stats", "error", err)
+		return nil, fmt.Errorf("failed to retrieve history stats: %w", err)
+	}
+
+	return stats, nil
+}
+
+// GetHostStats returns per-host execution statistics aggregated by the repository.
+func (s *HistoryService) GetHostStats(ctx context.Context) ([]*repository.HostStats, error) {
+	s.logger.Debug("retrieving history host stats")
+
+	stats, err := s.repo.HostStats(ctx)
+	if err != nil {
+		s.logger.Error("failed to retrieve history host stats", "error", err)
+		return nil, fmt.Errorf("failed to retrieve history host stats: %w", err)
+	}
+
+	return stats, nil
+}
+
 // DeleteHistory removes a history entry by ID.
 // Returns an error if the entry is not found.
 func (s *HistoryService) DeleteHistory(ctx context.Context, id string) error {
@@ -101,7 +371,8 @@ func (s *HistoryService) DeleteHistory(ctx context.Context, id string) error {
 }
 
 // CleanupOldHistory deletes all history entries older than the specified number of days.
-// Returns the number of entries deleted.
+// If an archiver is set, the entries are archived first and nothing is deleted
+// when archiving fails. Returns the number of entries deleted.
 func (s *HistoryService) CleanupOldHistory(ctx context.Context, daysToKeep int) (int64, error) {
 	if daysToKeep < 0 {
 		return 0, fmt.Errorf("daysToKeep must be non-negative, got: %d", daysToKeep)
@@ -116,6 +387,12 @@ func (s *HistoryService) CleanupOldHistory(ctx context.Context, daysToKeep int)
 		"cutoff_timestamp", cutoffTimestamp,
 	)
 
+	if s.archiver != nil {
+		if err := s.archiveOlderThan(ctx, cutoffTimestamp); err != nil {
+			return 0, err
+		}
+	}
+
 	count, err := s.repo.DeleteOlderThan(ctx, cutoffTimestamp)
 	if err != nil {
 		s.logger.Error("failed to cleanup old history",
@@ -134,6 +411,59 @@ func (s *HistoryService) CleanupOldHistory(ctx context.Context, daysToKeep int)
 	return count, nil
 }
 
+// archiveOlderThan archives all history entries older than the cutoff timestamp.
+func (s *HistoryService) archiveOlderThan(ctx context.Context, cutoffTimestamp string) error {
+	entries, err := s.repo.FindOlderThan(ctx, cutoffTimestamp)
+	if err != nil {
+		s.logger.Error("failed to load history for archiving",
+			"cutoff_timestamp", cutoffTimestamp,
+			"error", err,
+		)
+		return fmt.Errorf("failed to load history for archiving: %w", err)
+	}
+
+	if len(entries) == 0 {
+		return nil
+	}
+	if err := readFullBodies(ctx, s.repo, entries); err != nil {
+		s.logger.Error("failed to load history for archiving", "error", err)
+		return fmt.Errorf("failed to load history for archiving: %w", err)
+	}
+
+	path, err := s.archiver.Archive(entries)
+	if err != nil {
+		s.logger.Error("failed to archive old history",
+			"cutoff_timestamp", cutoffTimestamp,
+			"count", len(entries),
+			"error", err,
+		)
+		return fmt.Errorf("failed to archive old history: %w", err)
+	}
+
+	s.logger.Info("old history archived",
+		"count", len(entries),
+		"path", path,
+	)
+
+	return nil
+}
+
+// readFullBodies reads again, with FindByID, the entries whose response body
+// is offloaded, since lists only hold a preview of it.
+func readFullBodies(ctx context.Context, repo repository.HistoryRepository, entries []*repository.HistoryEntry) error {
+	for i, entry := range entries {
+		if entry.ResponseBodyRef == "" {
+			continue
+		}
+		full, err := repo.FindByID(ctx, entry.ID)
+		if err != nil {
+			return err
+		}
+		entries[i] = full
+	}
+	return nil
+}
+
 // SaveExecution saves a request execution result to history.
 // This is typically called after executing a request to record its outcome.
 func (s *HistoryService) SaveExecution(ctx context.Context, entry *repository.HistoryEntry) error {
@@ -163,3 +493,70 @@ func (s *HistoryService) SaveExecution(ctx context.Context, entry *repository.Hi
 
 	return nil
 }
+
+// EnforceRetention trims history by age and then by count according to policy.
+// Returns the total number of entries removed.
+func (s *HistoryService) EnforceRetention(ctx context.Context, policy RetentionPolicy) (int64, error) {
+	var total int64
+
+	if policy.MaxAgeDays > 0 {
+		count, err := s.CleanupOldHistory(ctx, policy.MaxAgeDays)
+		if err != nil {
+			return total, err
+		}
+		total += count
+	}
+
+	if policy.MaxEntries > 0 {
+		count, err := s.repo.DeleteExceptNewest(ctx, policy.MaxEntries)
+		if err != nil {
+			s.logger.Error("failed to trim history",
+				"max_entries", policy.MaxEntries,
+				"error", err,
+			)
+			return total, fmt.Errorf("failed to trim history: %w", err)
+		}
+		if count > 0 {
+			s.logger.Info("trimmed history to max entries",
+				"max_entries", policy.MaxEntries,
+				"deleted_count", count,
+			)
+		}
+		total += count
+	}
+
+	return total, nil
+}
+
+// RunRetention enforces policy immediately and then every interval until ctx is cancelled.
+// Errors are logged rather than returned so a failed pass does not stop later ones.
+// It returns immediately if the policy is disabled.
+func (s *HistoryService) RunRetention(ctx context.Context, policy RetentionPolicy, interval time.Duration) {
+	if !policy.enabled() {
+		return
+	}
+	if interval <= 0 {
+		interval = DefaultRetentionInterval
+	}
+
+	s.logger.Info("starting history retention",
+		"max_entries", policy.MaxEntries,
+		"max_age_days", policy.MaxAgeDays,
+		"interval", interval,
+	)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := s.EnforceRetention(ctx, policy); err != nil {
+			s.logger.Warn("history retention pass failed", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
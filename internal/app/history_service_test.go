@@ -3,12 +3,16 @@ package app
 import (
 	"context"
 	"errors"
+	"io"
 	"log/slog"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/williajm/curly/internal/domain"
 	"github.com/williajm/curly/internal/infrastructure/repository"
 )
 
@@ -67,6 +71,36 @@ func TestGetHistory_Success(t *testing.T) {
 	repo.AssertExpectations(t)
 }
 
+func TestGetHistoryPage(t *testing.T) {
+	repo := new(MockHistoryRepository)
+	service := NewHistoryService(repo, slog.Default())
+
+	filter := repository.HistoryFilter{StatusClass: 4}
+	page := []*repository.HistoryEntry{{ID: "entry-51", StatusCode: 404}}
+	repo.On("CountMatching", mock.Anything, filter).Return(51, nil)
+	repo.On("FindPage", mock.Anything, filter, 50, 50).Return(page, nil)
+
+	entries, total, err := service.GetHistoryPage(context.Background(), filter, 50, 50)
+
+	require.NoError(t, err)
+	assert.Equal(t, page, entries)
+	assert.Equal(t, 51, total)
+	repo.AssertExpectations(t)
+}
+
+func TestGetHistoryPage_Error(t *testing.T) {
+	repo := new(MockHistoryRepository)
+	service := NewHistoryService(repo, slog.Default())
+
+	repo.On("CountMatching", mock.Anything, repository.HistoryFilter{}).Return(0, errors.New("database error"))
+
+	entries, _, err := service.GetHistoryPage(context.Background(), repository.HistoryFilter{}, 0, 50)
+
+	assert.ErrorContains(t, err, "failed to count history")
+	assert.Nil(t, entries)
+	repo.AssertNotCalled(t, "FindPage", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
 func TestGetHistory_Error(t *testing.T) {
 	repo := new(MockHistoryRepository)
 	logger := slog.Default()
@@ -160,6 +194,234 @@ func TestGetRequestHistory_Error(t *testing.T) {
 	repo.AssertExpectations(t)
 }
 
+func TestGetRunHistory(t *testing.T) {
+	repo := new(MockHistoryRepository)
+	service := NewHistoryService(repo, slog.Default())
+
+	entries := []*repository.HistoryEntry{{ID: "entry-1", RunID: "run-1"}}
+	repo.On("FindByRunID", mock.Anything, "run-1").Return(entries, nil).Once()
+	repo.On("FindByRunID", mock.Anything, "run-2").Return(nil, errors.New("database error")).Once()
+
+	got, err := service.GetRunHistory(context.Background(), "run-1")
+	assert.NoError(t, err)
+	assert.Equal(t, entries, got)
+
+	_, err = service.GetRunHistory(context.Background(), "run-2")
+	assert.ErrorContains(t, err, "failed to retrieve run history")
+
+	repo.AssertExpectations(t)
+}
+
+func TestSearchHistory(t *testing.T) {
+	repo := new(MockHistoryRepository)
+	service := NewHistoryService(repo, slog.Default())
+
+	filter := repository.HistoryFilter{StatusClass: 4, Method: "POST"}
+	entries := []*repository.HistoryEntry{{ID: "entry-1", StatusCode: 404}}
+	repo.On("FindMatching", mock.Anything, filter, 100).Return(entries, nil).Once()
+	repo.On("FindMatching", mock.Anything, repository.HistoryFilter{FailedOnly: true}, 0).Return(nil, errors.New("database error")).Once()
+
+	got, err := service.SearchHistory(context.Background(), filter, 100)
+	assert.NoError(t, err)
+	assert.Equal(t, entries, got)
+
+	_, err = service.SearchHistory(context.Background(), repository.HistoryFilter{FailedOnly: true}, 0)
+	assert.ErrorContains(t, err, "failed to search history")
+
+	repo.AssertExpectations(t)
+}
+
+func TestParseHistoryQuery(t *testing.T) {
+	tests := []struct {
+		query   string
+		want    repository.HistoryFilter
+		wantErr bool
+	}{
+		{query: "", want: repository.HistoryFilter{}},
+		{query: "status:4xx method:post url:users", want: repository.HistoryFilter{StatusClass: 4, Method: "POST", URLContains: "users"}},
+		{query: "Status:5 /api v2", want: repository.HistoryFilter{StatusClass: 5, URLContains: "/api v2"}},
+		{query: "status:404", want: repository.HistoryFilter{StatusClass: 4}},
+		{query: "https://example.com", want: repository.HistoryFilter{URLContains: "https://example.com"}},
+		{query: "status:6xx", wantErr: true},
+		{query: "status:40", wantErr: true},
+		{query: "status:", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			got, err := ParseHistoryQuery(tt.query)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestGetEntry_Success(t *testing.T) {
+	repo := new(MockHistoryRepository)
+	service := NewHistoryService(repo, slog.Default())
+
+	entry := &repository.HistoryEntry{ID: "entry-1", ResponseBody: "full body"}
+	repo.On("FindByID", mock.Anything, "entry-1").Return(entry, nil)
+
+	got, err := service.GetEntry(context.Background(), "entry-1")
+
+	assert.NoError(t, err)
+	assert.Equal(t, entry, got)
+	repo.AssertExpectations(t)
+}
+
+func TestGetEntry_Error(t *testing.T) {
+	repo := new(MockHistoryRepository)
+	service := NewHistoryService(repo, slog.Default())
+
+	repo.On("FindByID", mock.Anything, "missing").Return(nil, repository.ErrNotFound)
+
+	got, err := service.GetEntry(context.Background(), "missing")
+
+	assert.Nil(t, got)
+	assert.ErrorIs(t, err, repository.ErrNotFound)
+	repo.AssertExpectations(t)
+}
+
+// openingHistoryRepository is a history repository that streams bodies.
+type openingHistoryRepository struct {
+	*MockHistoryRepository
+}
+
+func (r openingHistoryRepository) OpenByID(ctx context.Context, id string) (*repository.HistoryEntry, io.ReadCloser, error) {
+	args := r.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, nil, args.Error(2)
+	}
+	return args.Get(0).(*repository.HistoryEntry), args.Get(1).(io.ReadCloser), args.Error(2)
+}
+
+func TestOpenEntry(t *testing.T) {
+	t.Run("streams from a repository that offloads bodies", func(t *testing.T) {
+		repo := openingHistoryRepository{new(MockHistoryRepository)}
+		service := NewHistoryService(repo, slog.Default())
+
+		entry := &repository.HistoryEntry{ID: "entry-1", ResponseBody: "preview", ResponseBodyRef: "ref"}
+		repo.On("OpenByID", mock.Anything, "entry-1").Return(entry, io.NopCloser(strings.NewReader("full body")), nil)
+
+		got, body, err := service.OpenEntry(context.Background(), "entry-1")
+		require.NoError(t, err)
+		assert.Equal(t, "preview", got.ResponseBody)
+		data, err := io.ReadAll(body)
+		require.NoError(t, err)
+		assert.Equal(t, "full body", string(data))
+		repo.AssertNotCalled(t, "FindByID", mock.Anything, mock.Anything)
+	})
+
+	t.Run("reads the entry body otherwise", func(t *testing.T) {
+		repo := new(MockHistoryRepository)
+		service := NewHistoryService(repo, slog.Default())
+
+		repo.On("FindByID", mock.Anything, "entry-1").Return(&repository.HistoryEntry{ID: "entry-1", ResponseBody: "full body"}, nil)
+
+		_, body, err := service.OpenEntry(context.Background(), "entry-1")
+		require.NoError(t, err)
+		data, err := io.ReadAll(body)
+		require.NoError(t, err)
+		assert.Equal(t, "full body", string(data))
+	})
+
+	t.Run("error", func(t *testing.T) {
+		repo := openingHistoryRepository{new(MockHistoryRepository)}
+		service := NewHistoryService(repo, slog.Default())
+
+		repo.On("OpenByID", mock.Anything, "missing").Return(nil, nil, repository.ErrNotFound)
+
+		_, _, err := service.OpenEntry(context.Background(), "missing")
+		assert.ErrorIs(t, err, repository.ErrNotFound)
+	})
+}
+
+func TestReplay(t *testing.T) {
+	historyRepo := new(MockHistoryRepository)
+	httpClient := new(MockHTTPClient)
+	service := NewHistoryService(historyRepo, slog.Default())
+	service.SetReplayer(NewRequestService(new(MockRequestRepository), httpClient, historyRepo, slog.Default()))
+
+	// The saved request may have changed since; the snapshot is what gets sent.
+	sent := domain.NewRequestWithMethodAndURL(domain.MethodPost, "https://api.example.com/items")
+	sent.Body = `{"name":"widget"}`
+	sent.AuthConfig = domain.NewBearerAuth("token-123")
+	snapshot, err := repository.MarshalRequestSnapshot(sent)
+	require.NoError(t, err)
+	historyRepo.On("FindByID", mock.Anything, "orig").
+		Return(&repository.HistoryEntry{ID: "orig", RequestID: sent.ID, RequestSnapshot: snapshot}, nil)
+
+	httpClient.On("Execute", mock.Anything, mock.MatchedBy(func(req *domain.Request) bool {
+		return req.ID == sent.ID && req.URL == sent.URL && req.Body == sent.Body &&
+			req.AuthConfig.Type() == "bearer"
+	})).Return(&domain.Response{StatusCode: 201, Headers: map[string]string{}}, nil)
+
+	var saved []*repository.HistoryEntry
+	historyRepo.On("SaveBatch", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		saved = args.Get(1).([]*repository.HistoryEntry)
+	}).Return(nil)
+
+	resp, err := service.Replay(context.Background(), "orig")
+	require.NoError(t, err)
+	assert.Equal(t, 201, resp.StatusCode)
+
+	require.Len(t, saved, 1)
+	assert.Equal(t, "orig", saved[0].ReplayOf)
+	assert.Equal(t, sent.ID, saved[0].RequestID)
+	assert.Equal(t, snapshot, saved[0].RequestSnapshot)
+	httpClient.AssertExpectations(t)
+}
+
+func TestReplay_Errors(t *testing.T) {
+	historyRepo := new(MockHistoryRepository)
+	service := NewHistoryService(historyRepo, slog.Default())
+
+	_, err := service.Replay(context.Background(), "orig")
+	assert.ErrorContains(t, err, "replay is not available")
+
+	service.SetReplayer(NewRequestService(new(MockRequestRepository), new(MockHTTPClient), historyRepo, slog.Default()))
+	historyRepo.On("FindByID", mock.Anything, "old").Return(&repository.HistoryEntry{ID: "old"}, nil)
+	historyRepo.On("FindByID", mock.Anything, "missing").Return(nil, repository.ErrNotFound)
+
+	_, err = service.Replay(context.Background(), "old")
+	assert.ErrorIs(t, err, repository.ErrNoSnapshot)
+
+	_, err = service.Replay(context.Background(), "missing")
+	assert.ErrorIs(t, err, repository.ErrNotFound)
+}
+
+func TestGetStats_Success(t *testing.T) {
+	repo := new(MockHistoryRepository)
+	service := NewHistoryService(repo, slog.Default())
+
+	stats := []*repository.RequestStats{{RequestID: "req-1", Count: 3, SuccessCount: 2}}
+	repo.On("Stats", mock.Anything).Return(stats, nil)
+
+	got, err := service.GetStats(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, stats, got)
+	repo.AssertExpectations(t)
+}
+
+func TestGetStats_Error(t *testing.T) {
+	repo := new(MockHistoryRepository)
+	service := NewHistoryService(repo, slog.Default())
+
+	repo.On("Stats", mock.Anything).Return(nil, errors.New("database error"))
+
+	got, err := service.GetStats(context.Background())
+
+	assert.Nil(t, got)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to retrieve history stats")
+}
+
 func TestDeleteHistory_Success(t *testing.T) {
 	repo := new(MockHistoryRepository)
 	logger := slog.Default()
@@ -260,6 +522,99 @@ func TestCleanupOldHistory_Error(t *testing.T) {
 	repo.AssertExpectations(t)
 }
 
+// MockHistoryArchiver is a mock implementation of HistoryArchiver.
+type MockHistoryArchiver struct {
+	mock.Mock
+}
+
+func (m *MockHistoryArchiver) Archive(entries []*repository.HistoryEntry) (string, error) {
+	args := m.Called(entries)
+	return args.String(0), args.Error(1)
+}
+
+func TestCleanupOldHistory_ArchivesBeforeDelete(t *testing.T) {
+	repo := new(MockHistoryRepository)
+	archiver := new(MockHistoryArchiver)
+	service := NewHistoryService(repo, slog.Default())
+	service.SetArchiver(archiver)
+
+	old := []*repository.HistoryEntry{
+		{ID: "hist-1", ExecutedAt: "2024-01-01T10:00:00Z"},
+		{ID: "hist-2", ExecutedAt: "2024-01-02T10:00:00Z"},
+	}
+
+	var archived bool
+	repo.On("FindOlderThan", mock.Anything, mock.AnythingOfType("string")).Return(old, nil)
+	archiver.On("Archive", old).Run(func(mock.Arguments) { archived = true }).Return("/tmp/history.ndjson.gz", nil)
+	repo.On("DeleteOlderThan", mock.Anything, mock.AnythingOfType("string")).Run(func(mock.Arguments) {
+		assert.True(t, archived, "entries must be archived before they are deleted")
+	}).Return(int64(2), nil)
+
+	count, err := service.CleanupOldHistory(context.Background(), 30)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+	repo.AssertExpectations(t)
+	archiver.AssertExpectations(t)
+}
+
+func TestCleanupOldHistory_ArchivesFullBodies(t *testing.T) {
+	repo := new(MockHistoryRepository)
+	archiver := new(MockHistoryArchiver)
+	service := NewHistoryService(repo, slog.Default())
+	service.SetArchiver(archiver)
+
+	// The entry listed holds a preview of its offloaded body.
+	listed := &repository.HistoryEntry{ID: "hist-1", ResponseBody: "[{", ResponseBodyRef: "abc"}
+	full := &repository.HistoryEntry{ID: "hist-1", ResponseBody: `[{"id":1}]`, ResponseBodyRef: "abc"}
+	inline := &repository.HistoryEntry{ID: "hist-2", ResponseBody: "ok"}
+	repo.On("FindOlderThan", mock.Anything, mock.AnythingOfType("string")).Return([]*repository.HistoryEntry{listed, inline}, nil)
+	repo.On("FindByID", mock.Anything, "hist-1").Return(full, nil)
+	archiver.On("Archive", []*repository.HistoryEntry{full, inline}).Return("/tmp/history.ndjson.gz", nil)
+	repo.On("DeleteOlderThan", mock.Anything, mock.AnythingOfType("string")).Return(int64(2), nil)
+
+	_, err := service.CleanupOldHistory(context.Background(), 30)
+
+	require.NoError(t, err)
+	archiver.AssertExpectations(t)
+	repo.AssertNotCalled(t, "FindByID", mock.Anything, "hist-2")
+}
+
+func TestCleanupOldHistory_ArchiveFailureSkipsDelete(t *testing.T) {
+	repo := new(MockHistoryRepository)
+	archiver := new(MockHistoryArchiver)
+	service := NewHistoryService(repo, slog.Default())
+	service.SetArchiver(archiver)
+
+	old := []*repository.HistoryEntry{{ID: "hist-1", ExecutedAt: "2024-01-01T10:00:00Z"}}
+	repo.On("FindOlderThan", mock.Anything, mock.AnythingOfType("string")).Return(old, nil)
+	archiver.On("Archive", old).Return("", errors.New("disk full"))
+
+	count, err := service.CleanupOldHistory(context.Background(), 30)
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to archive old history")
+	assert.Equal(t, int64(0), count)
+	repo.AssertNotCalled(t, "DeleteOlderThan", mock.Anything, mock.Anything)
+	archiver.AssertExpectations(t)
+}
+
+func TestCleanupOldHistory_NothingToArchive(t *testing.T) {
+	repo := new(MockHistoryRepository)
+	archiver := new(MockHistoryArchiver)
+	service := NewHistoryService(repo, slog.Default())
+	service.SetArchiver(archiver)
+
+	repo.On("FindOlderThan", mock.Anything, mock.AnythingOfType("string")).Return([]*repository.HistoryEntry{}, nil)
+	repo.On("DeleteOlderThan", mock.Anything, mock.AnythingOfType("string")).Return(int64(0), nil)
+
+	count, err := service.CleanupOldHistory(context.Background(), 30)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), count)
+	archiver.AssertNotCalled(t, "Archive", mock.Anything)
+}
+
 func TestCleanupOldHistory_ZeroDays(t *testing.T) {
 	repo := new(MockHistoryRepository)
 	logger := slog.Default()
@@ -333,3 +688,102 @@ func TestSaveExecution_Error(t *testing.T) {
 
 	repo.AssertExpectations(t)
 }
+
+func TestEnforceRetention(t *testing.T) {
+	tests := []struct {
+		name      string
+		policy    RetentionPolicy
+		setup     func(repo *MockHistoryRepository)
+		wantCount int64
+	}{
+		{
+			name:   "age and count",
+			policy: RetentionPolicy{MaxEntries: 100, MaxAgeDays: 30},
+			setup: func(repo *MockHistoryRepository) {
+				repo.On("DeleteOlderThan", mock.Anything, mock.AnythingOfType("string")).Return(int64(3), nil)
+				repo.On("DeleteExceptNewest", mock.Anything, 100).Return(int64(2), nil)
+			},
+			wantCount: 5,
+		},
+		{
+			name:   "count only",
+			policy: RetentionPolicy{MaxEntries: 10},
+			setup: func(repo *MockHistoryRepository) {
+				repo.On("DeleteExceptNewest", mock.Anything, 10).Return(int64(4), nil)
+			},
+			wantCount: 4,
+		},
+		{
+			name:   "age only",
+			policy: RetentionPolicy{MaxAgeDays: 7},
+			setup: func(repo *MockHistoryRepository) {
+				repo.On("DeleteOlderThan", mock.Anything, mock.AnythingOfType("string")).Return(int64(1), nil)
+			},
+			wantCount: 1,
+		},
+		{
+			name:      "disabled",
+			policy:    RetentionPolicy{},
+			setup:     func(repo *MockHistoryRepository) {},
+			wantCount: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := new(MockHistoryRepository)
+			tt.setup(repo)
+			service := NewHistoryService(repo, slog.Default())
+
+			count, err := service.EnforceRetention(context.Background(), tt.policy)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantCount, count)
+			repo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestEnforceRetention_Error(t *testing.T) {
+	repo := new(MockHistoryRepository)
+	service := NewHistoryService(repo, slog.Default())
+
+	repo.On("DeleteExceptNewest", mock.Anything, 10).Return(int64(0), errors.New("database error"))
+
+	_, err := service.EnforceRetention(context.Background(), RetentionPolicy{MaxEntries: 10})
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to trim history")
+}
+
+func TestRunRetention_RunsImmediatelyAndStopsOnCancel(t *testing.T) {
+	repo := new(MockHistoryRepository)
+	service := NewHistoryService(repo, slog.Default())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	repo.On("DeleteExceptNewest", mock.Anything, 10).Return(int64(0), nil).Run(func(mock.Arguments) {
+		cancel()
+	})
+
+	done := make(chan struct{})
+	go func() {
+		service.RunRetention(ctx, RetentionPolicy{MaxEntries: 10}, time.Hour)
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("RunRetention did not stop after context cancellation")
+	}
+	repo.AssertNumberOfCalls(t, "DeleteExceptNewest", 1)
+}
+
+func TestRunRetention_DisabledPolicyReturns(t *testing.T) {
+	repo := new(MockHistoryRepository)
+	service := NewHistoryService(repo, slog.Default())
+
+	service.RunRetention(context.Background(), RetentionPolicy{}, time.Hour)
+
+	repo.AssertNotCalled(t, "DeleteExceptNewest", mock.Anything, mock.Anything)
+}
@@ -0,0 +1,213 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/williajm/curly/internal/domain"
+	"github.com/williajm/curly/internal/infrastructure/curl"
+	"github.com/williajm/curly/internal/infrastructure/openapi"
+	"github.com/williajm/curly/internal/infrastructure/postman"
+	"github.com/williajm/curly/internal/infrastructure/repository"
+	"github.com/williajm/curly/internal/infrastructure/sharelink"
+)
+
+// ImportService creates saved requests from external API descriptions, curl
+// commands and share links.
+type ImportService struct {
+	repo   repository.RequestRepository
+	logger *slog.Logger
+}
+
+// NewImportService creates a new ImportService with the provided dependencies.
+// The repository is required and must not be nil.
+func NewImportService(repo repository.RequestRepository, logger *slog.Logger) *ImportService {
+	if repo == nil {
+		panic("request repository cannot be nil")
+	}
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	return &ImportService{
+		repo:   repo,
+		logger: logger,
+	}
+}
+
+// ImportOpenAPI saves one request per operation in an OpenAPI 3.x or Swagger 2.0
+// document. Every generated request is validated before any is saved.
+// It returns the created requests.
+func (s *ImportService) ImportOpenAPI(ctx context.Context, data []byte, opts openapi.Options) ([]*domain.Request, error) {
+	requests, err := openapi.Parse(data, opts)
+	if err != nil {
+		s.logger.Error("failed to parse OpenAPI document", "error", err)
+		return nil, fmt.Errorf("failed to parse OpenAPI document: %w", err)
+	}
+
+	if err := s.save(ctx, requests); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("OpenAPI document imported", "count", len(requests))
+	return requests, nil
+}
+
+// ImportPostman saves the requests of a Postman v2.0 or v2.1 collection in
+// folder, which defaults to the collection's name. Every request is validated
+// before any is saved. The result's warnings list what was left out.
+func (s *ImportService) ImportPostman(ctx context.Context, data []byte, folder string) (*postman.Result, error) {
+	result, err := postman.Parse(data, folder)
+	if err != nil {
+		s.logger.Error("failed to parse Postman collection", "error", err)
+		return nil, fmt.Errorf("failed to parse Postman collection: %w", err)
+	}
+
+	if err := s.save(ctx, result.Requests); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Postman collection imported", "count", len(result.Requests), "warnings", len(result.Warnings))
+	return result, nil
+}
+
+// ContractResult summarizes attaching OpenAPI contracts to saved requests.
+type ContractResult struct {
+	// Folder is the folder whose requests were matched.
+	Folder string
+
+	// Attached lists the requests that now carry a contract.
+	Attached []*domain.Request
+
+	// Unmatched lists the requests no operation in the document matches.
+	// Their contracts are left unchanged.
+	Unmatched []*domain.Request
+}
+
+// AttachContracts stores the response contract of the matching OpenAPI
+// operation on each request in folder, so every later execution is checked
+// against the document. The folder defaults to the document's title.
+func (s *ImportService) AttachContracts(ctx context.Context, data []byte, folder string) (*ContractResult, error) {
+	set, err := openapi.ParseContracts(data)
+	if err != nil {
+		s.logger.Error("failed to parse OpenAPI document", "error", err)
+		return nil, fmt.Errorf("failed to parse OpenAPI document: %w", err)
+	}
+	if folder == "" {
+		folder = set.Title
+	}
+
+	requests, err := s.repo.FindByFolder(ctx, folder)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list requests in folder %q: %w", folder, err)
+	}
+
+	result := &ContractResult{Folder: folder}
+	for _, req := range requests {
+		contract := set.Match(req.Method, req.URL)
+		if contract == nil {
+			result.Unmatched = append(result.Unmatched, req)
+			continue
+		}
+		if req.ResponseContract, err = openapi.MarshalContract(contract); err != nil {
+			return nil, err
+		}
+		req.UpdatedAt = time.Now()
+		if err := s.repo.Update(ctx, req); err != nil {
+			s.logger.Error("failed to attach contract", "request_id", req.ID, "error", err)
+			return nil, fmt.Errorf("failed to update request %q: %w", req.Name, err)
+		}
+		result.Attached = append(result.Attached, req)
+	}
+
+	s.logger.Info("OpenAPI contracts attached",
+		"folder", folder,
+		"attached", len(result.Attached),
+		"unmatched", len(result.Unmatched),
+	)
+	return result, nil
+}
+
+// ParseCurl parses a curl command line into a request without saving it.
+// The result's warnings list options the request cannot represent.
+func (s *ImportService) ParseCurl(command string) (*curl.Result, error) {
+	result, err := curl.Parse(command)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse curl command: %w", err)
+	}
+	return result, nil
+}
+
+// ImportCurl parses a curl command line and saves the request in folder.
+func (s *ImportService) ImportCurl(ctx context.Context, command, folder string) (*curl.Result, error) {
+	result, err := s.ParseCurl(command)
+	if err != nil {
+		s.logger.Error("failed to parse curl command", "error", err)
+		return nil, err
+	}
+
+	result.Request.Folder = folder
+	if err := s.save(ctx, []*domain.Request{result.Request}); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("curl command imported",
+		"request_id", result.Request.ID,
+		"warnings", len(result.Warnings),
+	)
+	return result, nil
+}
+
+// ParseLink decodes a share link into a request without saving it.
+// The result lists the secrets the sender left out.
+func (s *ImportService) ParseLink(link string) (*sharelink.Result, error) {
+	result, err := sharelink.Decode(link)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse share link: %w", err)
+	}
+	return result, nil
+}
+
+// ImportLink decodes a share link and saves the request in folder. The
+// scripts the link carries are only saved if keepScripts is set, once the
+// user has seen them, since they run whenever the request is sent.
+func (s *ImportService) ImportLink(ctx context.Context, link, folder string, keepScripts bool) (*sharelink.Result, error) {
+	result, err := s.ParseLink(link)
+	if err != nil {
+		s.logger.Error("failed to parse share link", "error", err)
+		return nil, err
+	}
+
+	if !keepScripts {
+		result.DropScripts()
+	}
+	result.Request.Folder = folder
+	if err := s.save(ctx, []*domain.Request{result.Request}); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("share link imported",
+		"request_id", result.Request.ID,
+		"stripped", len(result.Stripped),
+	)
+	return result, nil
+}
+
+// save validates and creates requests, stopping at the first failure.
+func (s *ImportService) save(ctx context.Context, requests []*domain.Request) error {
+	for _, req := range requests {
+		if err := req.Validate(); err != nil {
+			return fmt.Errorf("invalid request %q: %w", req.Name, err)
+		}
+	}
+
+	for _, req := range requests {
+		if err := s.repo.Create(ctx, req); err != nil {
+			s.logger.Error("failed to create imported request", "name", req.Name, "error", err)
+			return fmt.Errorf("failed to create request %q: %w", req.Name, err)
+		}
+	}
+	return nil
+}
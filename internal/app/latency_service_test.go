@@ -0,0 +1,167 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/williajm/curly/internal/infrastructure/repository"
+)
+
+// latencyHistory builds history entries, newest first, with the given
+// response times in milliseconds.
+func latencyHistory(requestID string, times ...int64) []*repository.HistoryEntry {
+	entries := make([]*repository.HistoryEntry, len(times))
+	for i, ms := range times {
+		entries[i] = &repository.HistoryEntry{
+			RequestID:      requestID,
+			StatusCode:     200,
+			Status:         "200 OK",
+			ResponseTimeMs: ms,
+		}
+	}
+	return entries
+}
+
+// repeat returns n copies of ms.
+func repeat(ms int64, n int) []int64 {
+	times := make([]int64, n)
+	for i := range times {
+		times[i] = ms
+	}
+	return times
+}
+
+func TestNewLatencyService_PanicsOnNilRepository(t *testing.T) {
+	assert.Panics(t, func() { NewLatencyService(nil, slog.Default()) })
+}
+
+func TestLatencyService_Analyze(t *testing.T) {
+	opts := LatencyOptions{Recent: 3, Baseline: 5, MinSamples: 2, Ratio: 2, MinIncreaseMs: 50}
+	limit := 2 * (opts.Recent + opts.Baseline)
+
+	tests := []struct {
+		name    string
+		entries []*repository.HistoryEntry
+		want    *LatencyRegression
+	}{
+		{
+			name:    "regressed",
+			entries: latencyHistory("req", append([]int64{400, 420, 380}, repeat(100, 5)...)...),
+			want: &LatencyRegression{
+				RequestID:       "req",
+				RecentP50Ms:     400,
+				RecentP95Ms:     420,
+				BaselineP50Ms:   100,
+				BaselineP95Ms:   100,
+				RecentSamples:   3,
+				BaselineSamples: 5,
+			},
+		},
+		{
+			name:    "steady",
+			entries: latencyHistory("req", repeat(100, 8)...),
+		},
+		{
+			name:    "fast request noise",
+			entries: latencyHistory("req", append([]int64{9, 9, 9}, repeat(4, 5)...)...),
+		},
+		{
+			name:    "not enough history",
+			entries: latencyHistory("req", 400, 400, 400, 100),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			historyRepo := new(MockHistoryRepository)
+			service := NewLatencyService(historyRepo, slog.Default())
+			service.SetOptions(opts)
+			historyRepo.On("FindByRequestID", mock.Anything, "req", limit).Return(tt.entries, nil)
+
+			got, err := service.Analyze(context.Background(), "req")
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestLatencyService_Analyze_SkipsFailuresAndLoadSummaries(t *testing.T) {
+	historyRepo := new(MockHistoryRepository)
+	service := NewLatencyService(historyRepo, slog.Default())
+	service.SetOptions(LatencyOptions{Recent: 2, Baseline: 2, MinSamples: 2})
+
+	entries := latencyHistory("req", 5000, 5000, 900, 900, 100, 100)
+	entries[0].Error = "timeout"
+	entries[1].Status = loadSummaryStatusPrefix + " 100 requests"
+	historyRepo.On("FindByRequestID", mock.Anything, "req", 8).Return(entries, nil)
+
+	got, err := service.Analyze(context.Background(), "req")
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	assert.Equal(t, int64(900), got.RecentP95Ms)
+	assert.Equal(t, int64(100), got.BaselineP95Ms)
+	assert.InDelta(t, 9.0, got.Ratio(), 0.001)
+	assert.Equal(t, "p95 900ms vs 100ms baseline (9.0x slower over the last 2 runs)", got.String())
+}
+
+func TestLatencyService_AnalyzeAll(t *testing.T) {
+	historyRepo := new(MockHistoryRepository)
+	service := NewLatencyService(historyRepo, slog.Default())
+	service.SetOptions(LatencyOptions{Recent: 2, Baseline: 2, MinSamples: 2})
+
+	historyRepo.On("FindByRequestID", mock.Anything, "slow", 8).Return(latencyHistory("slow", 900, 900, 100, 100), nil).Once()
+	historyRepo.On("FindByRequestID", mock.Anything, "fast", 8).Return(latencyHistory("fast", 100, 100, 100, 100), nil).Once()
+
+	got, err := service.AnalyzeAll(context.Background(), []string{"slow", "", "fast", "slow"})
+	require.NoError(t, err)
+	require.Len(t, got, 1)
+	assert.Equal(t, "slow", got["slow"].RequestID)
+	historyRepo.AssertExpectations(t)
+
+	historyRepo.On("FindByRequestID", mock.Anything, "broken", 8).Return(nil, errors.New("db error"))
+	_, err = service.AnalyzeAll(context.Background(), []string{"broken"})
+	assert.ErrorContains(t, err, "failed to analyze latency")
+}
+
+func TestLatencyService_Trend(t *testing.T) {
+	historyRepo := new(MockHistoryRepository)
+	service := NewLatencyService(historyRepo, slog.Default())
+
+	// Newest first; the error is skipped and only the last 4 responses count.
+	entries := latencyHistory("req", 300, 5000, 100, 200, 400, 900)
+	entries[1].Error = "timeout"
+	historyRepo.On("FindByRequestID", mock.Anything, "req", 8).Return(entries, nil).Once()
+
+	got, err := service.Trend(context.Background(), "req", 4)
+	require.NoError(t, err)
+	assert.Equal(t, &LatencyTrend{
+		RequestID: "req",
+		SamplesMs: []int64{400, 200, 100, 300},
+		MinMs:     100,
+		AvgMs:     250,
+		P95Ms:     400,
+	}, got)
+
+	historyRepo.On("FindByRequestID", mock.Anything, "new", 8).Return([]*repository.HistoryEntry{}, nil).Once()
+	got, err = service.Trend(context.Background(), "new", 4)
+	require.NoError(t, err)
+	assert.Empty(t, got.SamplesMs)
+	assert.Zero(t, got.P95Ms)
+
+	historyRepo.On("FindByRequestID", mock.Anything, "broken", 8).Return(nil, errors.New("db error"))
+	_, err = service.Trend(context.Background(), "broken", 4)
+	assert.ErrorContains(t, err, "failed to load latency trend")
+}
+
+func TestPercentile(t *testing.T) {
+	values := []int64{50, 10, 40, 20, 30}
+	assert.Equal(t, int64(10), percentile(values, 0))
+	assert.Equal(t, int64(30), percentile(values, 0.5))
+	assert.Equal(t, int64(50), percentile(values, 0.95))
+	assert.Equal(t, []int64{50, 10, 40, 20, 30}, values)
+}
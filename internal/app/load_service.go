@@ -0,0 +1,404 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/williajm/curly/internal/domain"
+	"github.com/williajm/curly/internal/infrastructure/faker"
+	"github.com/williajm/curly/internal/infrastructure/http"
+	"github.com/williajm/curly/internal/infrastructure/repository"
+)
+
+// LoadOptions controls a load test. At least one of Iterations and Duration
+// must be set; when both are, the test stops at whichever comes first.
+type LoadOptions struct {
+	// Workers is the number of concurrent workers (default 1).
+	Workers int
+
+	// Iterations is the total number of requests to send across all workers.
+	Iterations int
+
+	// Duration is how long to keep sending requests.
+	Duration time.Duration
+}
+
+// LoadReport summarizes a load test.
+type LoadReport struct {
+	// Request is the request that was sent.
+	Request *domain.Request
+
+	// Workers is the number of concurrent workers used.
+	Workers int
+
+	// StartedAt is when the test began and Elapsed how long it took.
+	StartedAt time.Time
+	Elapsed   time.Duration
+
+	// Requests is the number of requests completed.
+	Requests int
+
+	// Failures counts requests that errored or returned a status other than 2xx/3xx.
+	Failures int
+
+	// StatusCodes counts responses by status code.
+	StatusCodes map[int]int
+
+	// Errors counts transport errors by message.
+	Errors map[string]int
+
+	// Latency distribution of completed requests, including failures.
+	Min, Mean, Max     time.Duration
+	P50, P90, P95, P99 time.Duration
+}
+
+// Throughput returns completed requests per second.
+func (r *LoadReport) Throughput() float64 {
+	if r.Elapsed <= 0 {
+		return 0
+	}
+	return float64(r.Requests) / r.Elapsed.Seconds()
+}
+
+// ErrorRate returns the fraction of requests that failed, between 0 and 1.
+func (r *LoadReport) ErrorRate() float64 {
+	if r.Requests == 0 {
+		return 0
+	}
+	return float64(r.Failures) / float64(r.Requests)
+}
+
+// Format renders the report as aligned text.
+func (r *LoadReport) Format() string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "Requests:    %d (%d failed, %.1f%%)\n", r.Requests, r.Failures, r.ErrorRate()*100)
+	fmt.Fprintf(&b, "Workers:     %d\n", r.Workers)
+	fmt.Fprintf(&b, "Elapsed:     %s\n", r.Elapsed.Round(time.Millisecond))
+	fmt.Fprintf(&b, "Throughput:  %.1f req/s\n", r.Throughput())
+	fmt.Fprintf(&b, "Latency:     min %s  mean %s  p50 %s  p90 %s  p95 %s  p99 %s  max %s\n",
+		roundLatency(r.Min), roundLatency(r.Mean), roundLatency(r.P50), roundLatency(r.P90),
+		roundLatency(r.P95), roundLatency(r.P99), roundLatency(r.Max))
+
+	codes := make([]int, 0, len(r.StatusCodes))
+	for code := range r.StatusCodes {
+		codes = append(codes, code)
+	}
+	sort.Ints(codes)
+	if len(codes) > 0 {
+		parts := make([]string, len(codes))
+		for i, code := range codes {
+			parts[i] = fmt.Sprintf("%d×%d", code, r.StatusCodes[code])
+		}
+		fmt.Fprintf(&b, "Status:      %s\n", strings.Join(parts, "  "))
+	}
+
+	messages := make([]string, 0, len(r.Errors))
+	for msg := range r.Errors {
+		messages = append(messages, msg)
+	}
+	sort.Strings(messages)
+	for _, msg := range messages {
+		fmt.Fprintf(&b, "Error:       %s (×%d)\n", msg, r.Errors[msg])
+	}
+
+	return b.String()
+}
+
+// roundLatency rounds a latency for display.
+func roundLatency(d time.Duration) time.Duration {
+	if d < time.Millisecond {
+		return d.Round(time.Microsecond)
+	}
+	return d.Round(100 * time.Microsecond)
+}
+
+// loadSummaryStatusPrefix starts the status of a load test's history record,
+// which tells it apart from single executions.
+const loadSummaryStatusPrefix = "Load test:"
+
+// loadSummary is the JSON body of the history record of a load test.
+type loadSummary struct {
+	Workers     int            `json:"workers"`
+	Requests    int            `json:"requests"`
+	Failures    int            `json:"failures"`
+	ElapsedMs   float64        `json:"elapsed_ms"`
+	Throughput  float64        `json:"requests_per_second"`
+	MinMs       float64        `json:"min_ms"`
+	MeanMs      float64        `json:"mean_ms"`
+	P50Ms       float64        `json:"p50_ms"`
+	P90Ms       float64        `json:"p90_ms"`
+	P95Ms       float64        `json:"p95_ms"`
+	P99Ms       float64        `json:"p99_ms"`
+	MaxMs       float64        `json:"max_ms"`
+	StatusCodes map[int]int    `json:"status_codes,omitempty"`
+	Errors      map[string]int `json:"errors,omitempty"`
+}
+
+// loadSample is the outcome of one request sent during a load test.
+type loadSample struct {
+	latency time.Duration
+	status  int
+	err     error
+}
+
+// LoadService sends a request repeatedly from concurrent workers and
+// summarizes the latency, throughput and error rate.
+type LoadService struct {
+	requests    *RequestService
+	httpClient  http.Client
+	historyRepo repository.HistoryRepository
+	faker       *faker.Faker
+	logger      *slog.Logger
+}
+
+// NewLoadService creates a new LoadService with the provided dependencies.
+// The request service resolves the request tested, as it does for a normal
+// send. All dependencies except the logger are required and must not be nil.
+func NewLoadService(requests *RequestService, httpClient http.Client, historyRepo repository.HistoryRepository, logger *slog.Logger) *LoadService {
+	if requests == nil {
+		panic("request service cannot be nil")
+	}
+	if httpClient == nil {
+		panic("http client cannot be nil")
+	}
+	if historyRepo == nil {
+		panic("history repository cannot be nil")
+	}
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	return &LoadService{
+		requests:    requests,
+		httpClient:  httpClient,
+		historyRepo: historyRepo,
+		faker:       faker.NewRandom(),
+		logger:      logger,
+	}
+}
+
+// Run load tests req and records the result to history as a single summary
+// entry, whose body is the report as JSON. Individual requests are not
+// recorded. The request is resolved once before the test starts, as for a
+// normal send: its {{name}} references are filled from the variables in
+// effect and its pre-request script is run. Fake data placeholders are filled
+// afresh for every request sent. Cancelling ctx stops the test early and
+// reports what completed.
+func (s *LoadService) Run(ctx context.Context, req *domain.Request, opts LoadOptions) (*LoadReport, error) {
+	sent, err := s.requests.Resolve(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	// Catch unknown placeholders once rather than in every worker.
+	if _, err := s.faker.ExpandRequest(sent); err != nil {
+		return nil, fmt.Errorf("invalid request: %w", err)
+	}
+	if opts.Workers <= 0 {
+		opts.Workers = 1
+	}
+	if opts.Iterations < 0 || opts.Duration < 0 {
+		return nil, fmt.Errorf("iterations and duration cannot be negative")
+	}
+	if opts.Iterations == 0 && opts.Duration == 0 {
+		return nil, fmt.Errorf("a load test needs iterations or a duration")
+	}
+
+	s.logger.Info("starting load test",
+		"request_id", req.ID,
+		"workers", opts.Workers,
+		"iterations", opts.Iterations,
+		"duration", opts.Duration,
+	)
+
+	runCtx := ctx
+	if opts.Duration > 0 {
+		var cancel context.CancelFunc
+		runCtx, cancel = context.WithTimeout(ctx, opts.Duration)
+		defer cancel()
+	}
+
+	started := time.Now()
+	samples := s.sendAll(runCtx, sent, opts)
+	report := summarize(req, opts.Workers, started, time.Since(started), samples)
+
+	s.saveSummary(ctx, report)
+
+	s.logger.Info("load test finished",
+		"request_id", req.ID,
+		"requests", report.Requests,
+		"failures", report.Failures,
+		"elapsed_ms", report.Elapsed.Milliseconds(),
+	)
+
+	return report, nil
+}
+
+// sendAll runs the workers until the iterations are used up or ctx ends.
+// Requests cut short by ctx ending are not counted.
+func (s *LoadService) sendAll(ctx context.Context, req *domain.Request, opts LoadOptions) []loadSample {
+	var (
+		issued  atomic.Int64
+		mu      sync.Mutex
+		samples []loadSample
+		wg      sync.WaitGroup
+	)
+
+	for range opts.Workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			var local []loadSample
+			for ctx.Err() == nil {
+				if opts.Iterations > 0 && issued.Add(1) > int64(opts.Iterations) {
+					break
+				}
+
+				// Run checked that the placeholders expand.
+				sent, _ := s.faker.ExpandRequest(req)
+				start := time.Now()
+				resp, err := s.httpClient.Execute(ctx, sent)
+				if err != nil && ctx.Err() != nil {
+					break
+				}
+
+				sample := loadSample{latency: time.Since(start), err: err}
+				if resp != nil {
+					sample.status = resp.StatusCode
+				}
+				local = append(local, sample)
+			}
+
+			mu.Lock()
+			samples = append(samples, local...)
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	return samples
+}
+
+// summarize aggregates samples into a report. Percentiles use the
+// nearest-rank method, like the history statistics.
+func summarize(req *domain.Request, workers int, started time.Time, elapsed time.Duration, samples []loadSample) *LoadReport {
+	report := &LoadReport{
+		Request:     req,
+		Workers:     workers,
+		StartedAt:   started.UTC(),
+		Elapsed:     elapsed,
+		Requests:    len(samples),
+		StatusCodes: make(map[int]int),
+		Errors:      make(map[string]int),
+	}
+	if len(samples) == 0 {
+		return report
+	}
+
+	latencies := make([]time.Duration, len(samples))
+	var total time.Duration
+	for i, sample := range samples {
+		latencies[i] = sample.latency
+		total += sample.latency
+
+		switch {
+		case sample.err != nil:
+			report.Failures++
+			report.Errors[sample.err.Error()]++
+		case sample.status < 200 || sample.status >= 400:
+			report.Failures++
+			report.StatusCodes[sample.status]++
+		default:
+			report.StatusCodes[sample.status]++
+		}
+	}
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+
+	rank := func(p float64) time.Duration {
+		i := int(math.Ceil(p*float64(len(latencies)))) - 1
+		return latencies[max(0, i)]
+	}
+	report.Min = latencies[0]
+	report.Max = latencies[len(latencies)-1]
+	report.Mean = total / time.Duration(len(latencies))
+	report.P50 = rank(0.50)
+	report.P90 = rank(0.90)
+	report.P95 = rank(0.95)
+	report.P99 = rank(0.99)
+
+	return report
+}
+
+// saveSummary records the report as one history entry. It is best effort:
+// failures are logged but not returned.
+func (s *LoadService) saveSummary(ctx context.Context, report *LoadReport) {
+	ms := func(d time.Duration) float64 { return float64(d.Microseconds()) / 1000 }
+	body, err := json.Marshal(loadSummary{
+		Workers:     report.Workers,
+		Requests:    report.Requests,
+		Failures:    report.Failures,
+		ElapsedMs:   ms(report.Elapsed),
+		Throughput:  report.Throughput(),
+		MinMs:       ms(report.Min),
+		MeanMs:      ms(report.Mean),
+		P50Ms:       ms(report.P50),
+		P90Ms:       ms(report.P90),
+		P95Ms:       ms(report.P95),
+		P99Ms:       ms(report.P99),
+		MaxMs:       ms(report.Max),
+		StatusCodes: report.StatusCodes,
+		Errors:      report.Errors,
+	})
+	if err != nil {
+		s.logger.Error("failed to marshal load test summary", "error", err)
+		return
+	}
+
+	entry := &repository.HistoryEntry{
+		ID:              uuid.New().String(),
+		RequestID:       report.Request.ID,
+		ExecutedAt:      report.StartedAt.Format(time.RFC3339),
+		StatusCode:      mostCommonStatus(report.StatusCodes),
+		Status:          fmt.Sprintf("%s %d requests, %.1f%% failed", loadSummaryStatusPrefix, report.Requests, report.ErrorRate()*100),
+		ResponseTimeMs:  report.P50.Milliseconds(),
+		ResponseHeaders: `{"Content-Type":"application/json"}`,
+		ResponseBody:    string(body),
+	}
+	if report.Requests == 0 || report.Failures == report.Requests {
+		entry.Error = "load test: every request failed"
+	}
+	if snapshot, err := repository.MarshalRequestSnapshot(report.Request); err != nil {
+		s.logger.Error("failed to snapshot request", "request_id", report.Request.ID, "error", err)
+	} else {
+		entry.RequestSnapshot = snapshot
+	}
+
+	// Save does not touch the request's usage metadata, so a load test
+	// counts as a single history record rather than thousands of runs.
+	// A stopped test is still recorded.
+	if err := s.historyRepo.Save(context.WithoutCancel(ctx), entry); err != nil {
+		s.logger.Error("failed to save load test summary", "error", err)
+	}
+}
+
+// mostCommonStatus returns the status code with the highest count, the
+// lowest code on ties, or 0 when there were no responses.
+func mostCommonStatus(codes map[int]int) int {
+	best, bestCount := 0, 0
+	for code, count := range codes {
+		if count > bestCount || count == bestCount && code < best {
+			best, bestCount = code, count
+		}
+	}
+	return best
+}
@@ -0,0 +1,161 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/williajm/curly/internal/domain"
+	"github.com/williajm/curly/internal/infrastructure/repository"
+)
+
+// newTestLoadService returns a LoadService sending through httpClient, with
+// a request service that resolves from no variables.
+func newTestLoadService(httpClient *MockHTTPClient, historyRepo *MockHistoryRepository) *LoadService {
+	requests := NewRequestService(new(MockRequestRepository), httpClient, historyRepo, slog.Default())
+	return NewLoadService(requests, httpClient, historyRepo, slog.Default())
+}
+
+func TestNewLoadService_PanicsOnNilDependencies(t *testing.T) {
+	requests := NewRequestService(new(MockRequestRepository), new(MockHTTPClient), new(MockHistoryRepository), slog.Default())
+	assert.Panics(t, func() { NewLoadService(nil, new(MockHTTPClient), new(MockHistoryRepository), slog.Default()) })
+	assert.Panics(t, func() { NewLoadService(requests, nil, new(MockHistoryRepository), slog.Default()) })
+	assert.Panics(t, func() { NewLoadService(requests, new(MockHTTPClient), nil, slog.Default()) })
+}
+
+func TestLoadService_RunIterations(t *testing.T) {
+	httpClient := new(MockHTTPClient)
+	historyRepo := new(MockHistoryRepository)
+	service := newTestLoadService(httpClient, historyRepo)
+
+	req := domain.NewRequestWithMethodAndURL("GET", "https://api.example.com/health")
+	httpClient.On("Execute", mock.Anything, req).Return(&domain.Response{StatusCode: 200, Headers: map[string]string{}}, nil)
+
+	var saved *repository.HistoryEntry
+	historyRepo.On("Save", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		saved = args.Get(1).(*repository.HistoryEntry)
+	}).Return(nil).Once()
+
+	report, err := service.Run(context.Background(), req, LoadOptions{Workers: 4, Iterations: 50})
+	require.NoError(t, err)
+
+	assert.Equal(t, 50, report.Requests)
+	assert.Equal(t, 0, report.Failures)
+	assert.Equal(t, 4, report.Workers)
+	assert.Equal(t, map[int]int{200: 50}, report.StatusCodes)
+	assert.Zero(t, report.ErrorRate())
+	httpClient.AssertNumberOfCalls(t, "Execute", 50)
+
+	// A single summary entry is recorded, with the report as its body.
+	require.NotNil(t, saved)
+	assert.Equal(t, req.ID, saved.RequestID)
+	assert.Equal(t, 200, saved.StatusCode)
+	assert.Empty(t, saved.Error)
+	assert.NotEmpty(t, saved.RequestSnapshot)
+
+	var summary loadSummary
+	require.NoError(t, json.Unmarshal([]byte(saved.ResponseBody), &summary))
+	assert.Equal(t, 50, summary.Requests)
+	assert.Equal(t, 4, summary.Workers)
+	historyRepo.AssertExpectations(t)
+}
+
+func TestLoadService_RunDuration(t *testing.T) {
+	httpClient := new(MockHTTPClient)
+	historyRepo := new(MockHistoryRepository)
+	service := newTestLoadService(httpClient, historyRepo)
+
+	req := domain.NewRequestWithMethodAndURL("GET", "https://api.example.com/health")
+	httpClient.On("Execute", mock.Anything, req).
+		After(5*time.Millisecond).
+		Return(&domain.Response{StatusCode: 503, Headers: map[string]string{}}, nil)
+	historyRepo.On("Save", mock.Anything, mock.Anything).Return(nil).Once()
+
+	report, err := service.Run(context.Background(), req, LoadOptions{Workers: 2, Duration: 50 * time.Millisecond})
+	require.NoError(t, err)
+
+	assert.Positive(t, report.Requests)
+	assert.Equal(t, report.Requests, report.Failures)
+	assert.InDelta(t, 1.0, report.ErrorRate(), 0.0001)
+	assert.Less(t, report.Elapsed, time.Second)
+	historyRepo.AssertExpectations(t)
+}
+
+func TestLoadService_RunRequiresIterationsOrDuration(t *testing.T) {
+	service := newTestLoadService(new(MockHTTPClient), new(MockHistoryRepository))
+	req := domain.NewRequestWithMethodAndURL("GET", "https://api.example.com/health")
+
+	_, err := service.Run(context.Background(), req, LoadOptions{Workers: 2})
+	assert.Error(t, err)
+
+	_, err = service.Run(context.Background(), req, LoadOptions{Iterations: -1})
+	assert.Error(t, err)
+}
+
+func TestLoadService_RunResolvesVariables(t *testing.T) {
+	httpClient := new(MockHTTPClient)
+	historyRepo := new(MockHistoryRepository)
+	requests := NewRequestService(new(MockRequestRepository), httpClient, historyRepo, slog.Default())
+	requests.SetVariables(staticVariables{"base_url": "https://api.example.com", "token": "s3cret"})
+	service := NewLoadService(requests, httpClient, historyRepo, slog.Default())
+
+	req := domain.NewRequestWithMethodAndURL("GET", "{{base_url}}/health")
+	req.Headers["Authorization"] = "Bearer {{token}}"
+	httpClient.On("Execute", mock.Anything, mock.MatchedBy(func(sent *domain.Request) bool {
+		return sent.URL == "https://api.example.com/health" && sent.Headers["Authorization"] == "Bearer s3cret"
+	})).Return(&domain.Response{StatusCode: 200, Headers: map[string]string{}}, nil)
+	historyRepo.On("Save", mock.Anything, mock.Anything).Return(nil).Once()
+
+	report, err := service.Run(context.Background(), req, LoadOptions{Workers: 2, Iterations: 10})
+	require.NoError(t, err)
+	assert.Equal(t, map[int]int{200: 10}, report.StatusCodes)
+	httpClient.AssertNumberOfCalls(t, "Execute", 10)
+
+	// A reference without a value stops the test before anything is sent.
+	req.URL = "{{missing}}/health"
+	_, err = service.Run(context.Background(), req, LoadOptions{Iterations: 10})
+	assert.ErrorIs(t, err, domain.ErrUndefinedVariable)
+	httpClient.AssertNumberOfCalls(t, "Execute", 10)
+}
+
+func TestSummarize(t *testing.T) {
+	req := domain.NewRequestWithMethodAndURL("GET", "https://api.example.com/health")
+
+	var samples []loadSample
+	for i := 1; i <= 100; i++ {
+		sample := loadSample{latency: time.Duration(i) * time.Millisecond, status: 200}
+		switch {
+		case i%25 == 0:
+			sample = loadSample{latency: sample.latency, err: errors.New("connection refused")}
+		case i%10 == 0:
+			sample.status = 500
+		}
+		samples = append(samples, sample)
+	}
+
+	report := summarize(req, 4, time.Now(), 2*time.Second, samples)
+
+	assert.Equal(t, 100, report.Requests)
+	assert.Equal(t, 12, report.Failures) // 4 errors and 8 server errors.
+	assert.Equal(t, map[int]int{200: 88, 500: 8}, report.StatusCodes)
+	assert.Equal(t, map[string]int{"connection refused": 4}, report.Errors)
+	assert.InDelta(t, 50.0, report.Throughput(), 0.0001)
+	assert.InDelta(t, 0.12, report.ErrorRate(), 0.0001)
+
+	assert.Equal(t, 1*time.Millisecond, report.Min)
+	assert.Equal(t, 100*time.Millisecond, report.Max)
+	assert.Equal(t, 50500*time.Microsecond, report.Mean)
+	assert.Equal(t, 50*time.Millisecond, report.P50)
+	assert.Equal(t, 90*time.Millisecond, report.P90)
+	assert.Equal(t, 95*time.Millisecond, report.P95)
+	assert.Equal(t, 99*time.Millisecond, report.P99)
+
+	assert.Contains(t, report.Format(), "Throughput:  50.0 req/s")
+	assert.Equal(t, 200, mostCommonStatus(report.StatusCodes))
+}
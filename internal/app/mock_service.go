@@ -0,0 +1,350 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/williajm/curly/internal/domain"
+	"github.com/williajm/curly/internal/infrastructure/repository"
+)
+
+// mockExampleSearch is how many of a request's most recent executions are
+// searched for a response to serve.
+const mockExampleSearch = 20
+
+// mockShutdownTimeout is how long Serve waits for requests in progress when
+// it stops.
+const mockShutdownTimeout = 5 * time.Second
+
+// mockSkippedHeaders are response headers that describe the original
+// transfer rather than the response, so a mock does not repeat them.
+var mockSkippedHeaders = []string{"Content-Length", "Transfer-Encoding", "Content-Encoding", "Connection", "Date", "Keep-Alive"}
+
+// MockRoute is a saved request served by the mock server: requests with its
+// method and path get its example response.
+type MockRoute struct {
+	// Request is the saved request.
+	Request *domain.Request
+
+	// Method is the request's method, and Path its URL path, in which
+	// segments that are variables ({{id}}, :id or {id}) match any segment.
+	Method string
+	Path   string
+
+	// Example is the response served, the most recent successful one
+	// recorded for the request.
+	Example *repository.HistoryEntry
+
+	segments  []string
+	wildcards int
+}
+
+// MockService serves the recorded responses of saved requests over HTTP, so
+// a client can be developed or tested against a collection without its API.
+type MockService struct {
+	requestRepo repository.RequestRepository
+	historyRepo repository.HistoryRepository
+	logger      *slog.Logger
+}
+
+// NewMockService creates a new MockService with the provided dependencies.
+// The repositories are required and must not be nil.
+func NewMockService(requestRepo repository.RequestRepository, historyRepo repository.HistoryRepository, logger *slog.Logger) *MockService {
+	if requestRepo == nil {
+		panic("request repository cannot be nil")
+	}
+	if historyRepo == nil {
+		panic("history repository cannot be nil")
+	}
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	return &MockService{
+		requestRepo: requestRepo,
+		historyRepo: historyRepo,
+		logger:      logger,
+	}
+}
+
+// Routes returns a route for each saved request in folder and the folders
+// nested in it, or for every saved request if folder is empty. Its example
+// is the most recent recorded response that is not an error; requests with
+// none are returned as missing.
+func (s *MockService) Routes(ctx context.Context, folder string) (routes []*MockRoute, missing []*domain.Request, err error) {
+	requests, err := requestsInFolder(ctx, s.requestRepo, folder)
+	if err != nil {
+		s.logger.Error("failed to load requests for mock", "error", err)
+		return nil, nil, err
+	}
+	if len(requests) == 0 {
+		if folder == "" {
+			return nil, nil, fmt.Errorf("there are no saved requests to mock")
+		}
+		return nil, nil, fmt.Errorf("folder %q has no requests", folder)
+	}
+
+	for _, req := range requests {
+		example, err := s.example(ctx, req)
+		if err != nil {
+			return nil, nil, err
+		}
+		if example == nil {
+			missing = append(missing, req)
+			continue
+		}
+		route := &MockRoute{
+			Request: req,
+			Method:  strings.ToUpper(req.Method),
+			Path:    mockPath(req.URL),
+			Example: example,
+		}
+		route.segments = splitPath(route.Path)
+		for _, segment := range route.segments {
+			if isPathVariable(segment) {
+				route.wildcards++
+			}
+		}
+		routes = append(routes, route)
+	}
+	return routes, missing, nil
+}
+
+// example returns the most recent successful execution of req with its full
+// body, or nil if there is none.
+func (s *MockService) example(ctx context.Context, req *domain.Request) (*repository.HistoryEntry, error) {
+	entries, err := s.historyRepo.FindByRequestID(ctx, req.ID, mockExampleSearch)
+	if err != nil {
+		s.logger.Error("failed to load history for mock", "request_id", req.ID, "error", err)
+		return nil, fmt.Errorf("failed to load history of %q: %w", req.Name, err)
+	}
+	for _, entry := range entries {
+		if entry.StatusCode == 0 || entry.Error != "" {
+			continue
+		}
+		if entry.ResponseBodyRef == "" {
+			return entry, nil
+		}
+		// Offloaded bodies are only loaded by FindByID.
+		full, err := s.historyRepo.FindByID(ctx, entry.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load the response of %q: %w", req.Name, err)
+		}
+		return full, nil
+	}
+	return nil, nil
+}
+
+// Handler returns an HTTP handler that serves each request with the example
+// of the route that matches its method and path, preferring routes with fewer
+// variable segments. It answers 404 when no route has the path and 405 when
+// none has the method. Each request is written to log as a line and recorded
+// to history as an ad-hoc execution, so mock traffic does not count towards
+// the saved requests' statistics; the entries of one handler share a run ID.
+func (s *MockService) Handler(routes []*MockRoute, log io.Writer) http.Handler {
+	return &mockHandler{
+		service: s,
+		routes:  routes,
+		log:     log,
+		runID:   uuid.New().String(),
+	}
+}
+
+// Serve serves handler on listener until ctx is done, then waits up to
+// mockShutdownTimeout for requests in progress to finish.
+func (s *MockService) Serve(ctx context.Context, listener net.Listener, handler http.Handler) error {
+	server := &http.Server{
+		Handler:           handler,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- server.Serve(listener)
+	}()
+
+	select {
+	case err := <-errCh:
+		return fmt.Errorf("mock server failed: %w", err)
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), mockShutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("failed to stop mock server: %w", err)
+	}
+	s.logger.Info("mock server stopped", "address", listener.Addr().String())
+	return nil
+}
+
+// mockHandler is the http.Handler returned by MockService.Handler.
+type mockHandler struct {
+	service *MockService
+	routes  []*MockRoute
+	runID   string
+
+	logMu sync.Mutex
+	log   io.Writer
+}
+
+// ServeHTTP implements http.Handler.
+func (h *mockHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	body, _ := io.ReadAll(r.Body)
+
+	route, status := h.match(r.Method, r.URL.Path)
+	name := "-"
+	var served *domain.Response
+	if route != nil {
+		name = route.Request.Name
+		served = route.Example.Response()
+		for header, value := range served.Headers {
+			if !slices.ContainsFunc(mockSkippedHeaders, func(skipped string) bool { return strings.EqualFold(skipped, header) }) {
+				w.Header().Set(header, value)
+			}
+		}
+		w.WriteHeader(served.StatusCode)
+		_, _ = io.WriteString(w, served.Body)
+	} else {
+		served = domain.NewResponse()
+		served.StatusCode = status
+		served.Status = fmt.Sprintf("%d %s", status, http.StatusText(status))
+		served.Headers = map[string]string{"Content-Type": "application/json"}
+		message, _ := json.Marshal(map[string]string{"error": fmt.Sprintf("no saved request matches %s %s", r.Method, r.URL.Path)})
+		served.Body = string(message) + "\n"
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		_, _ = io.WriteString(w, served.Body)
+	}
+	served.Duration = time.Since(start)
+
+	h.logMu.Lock()
+	_, _ = fmt.Fprintf(h.log, "%s %s %s %d %s\n", start.Format("15:04:05"), r.Method, r.URL.RequestURI(), served.StatusCode, name)
+	h.logMu.Unlock()
+
+	h.record(r, string(body), start, served)
+}
+
+// match returns the route for method and path, or nil and the status to
+// answer with when there is none.
+func (h *mockHandler) match(method, path string) (*MockRoute, int) {
+	segments := splitPath(path)
+	var best *MockRoute
+	pathFound := false
+	for _, route := range h.routes {
+		if !matchSegments(route.segments, segments) {
+			continue
+		}
+		pathFound = true
+		if route.Method != strings.ToUpper(method) && (route.Method != http.MethodGet || method != http.MethodHead) {
+			continue
+		}
+		if best == nil || route.wildcards < best.wildcards {
+			best = route
+		}
+	}
+	switch {
+	case best != nil:
+		return best, http.StatusOK
+	case pathFound:
+		return nil, http.StatusMethodNotAllowed
+	default:
+		return nil, http.StatusNotFound
+	}
+}
+
+// record saves the request and the response served to history.
+func (h *mockHandler) record(r *http.Request, body string, executedAt time.Time, served *domain.Response) {
+	received := domain.NewRequestWithMethodAndURL(r.Method, "http://"+r.Host+r.URL.RequestURI())
+	received.Name = "Mock " + r.Method + " " + r.URL.Path
+	for header, values := range r.Header {
+		received.Headers[header] = strings.Join(values, ", ")
+	}
+	received.Body = body
+
+	entry := &repository.HistoryEntry{
+		ID:             uuid.New().String(),
+		ExecutedAt:     executedAt.Format(time.RFC3339),
+		StatusCode:     served.StatusCode,
+		Status:         served.Status,
+		ResponseTimeMs: served.DurationMillis(),
+		ResponseBody:   served.Body,
+		RunID:          h.runID,
+	}
+	if snapshot, err := repository.MarshalRequestSnapshot(received); err == nil {
+		entry.RequestSnapshot = snapshot
+	}
+	if headers, err := json.Marshal(served.Headers); err == nil {
+		entry.ResponseHeaders = string(headers)
+	}
+
+	// The client has its response, so the request's context may be done.
+	if err := h.service.historyRepo.Save(context.Background(), entry); err != nil {
+		h.service.logger.Error("failed to record mock request", "method", r.Method, "path", r.URL.Path, "error", err)
+	}
+}
+
+// mockPath returns the path of a saved request's URL, which may start with a
+// variable such as {{baseUrl}} in place of the scheme and host.
+func mockPath(rawURL string) string {
+	path := rawURL
+	if i := strings.Index(path, "://"); i >= 0 {
+		path = path[i+3:]
+		if slash := strings.Index(path, "/"); slash >= 0 {
+			path = path[slash:]
+		} else {
+			path = "/"
+		}
+	} else if strings.HasPrefix(path, "{{") {
+		if end := strings.Index(path, "}}"); end >= 0 {
+			path = path[end+2:]
+		}
+	}
+	if i := strings.IndexAny(path, "?#"); i >= 0 {
+		path = path[:i]
+	}
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	return path
+}
+
+// splitPath splits path into its segments, ignoring a trailing slash.
+func splitPath(path string) []string {
+	path = strings.Trim(path, "/")
+	if path == "" {
+		return nil
+	}
+	return strings.Split(path, "/")
+}
+
+// matchSegments reports whether a route's segments match a request's.
+func matchSegments(route, path []string) bool {
+	if len(route) != len(path) {
+		return false
+	}
+	for i, segment := range route {
+		if segment != path[i] && !isPathVariable(segment) {
+			return false
+		}
+	}
+	return true
+}
+
+// isPathVariable reports whether a path segment is a variable: {{id}}, :id
+// or {id}.
+func isPathVariable(segment string) bool {
+	return strings.HasPrefix(segment, ":") ||
+		(strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}"))
+}
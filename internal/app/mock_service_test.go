@@ -0,0 +1,187 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/williajm/curly/internal/domain"
+	"github.com/williajm/curly/internal/infrastructure/repository"
+)
+
+func mockTestRequests() []*domain.Request {
+	list := domain.NewRequestWithMethodAndURL("GET", "{{baseUrl}}/payments?limit=10")
+	list.Name = "List payments"
+	list.Folder = "payments"
+
+	get := domain.NewRequestWithMethodAndURL("GET", "https://api.example.com/payments/{{id}}")
+	get.Name = "Get payment"
+	get.Folder = "payments"
+	get.Position = 1
+
+	latest := domain.NewRequestWithMethodAndURL("GET", "https://api.example.com/payments/latest")
+	latest.Name = "Latest payment"
+	latest.Folder = "payments"
+	latest.Position = 2
+
+	refund := domain.NewRequestWithMethodAndURL("POST", "https://api.example.com/payments/:id/refund")
+	refund.Name = "Refund"
+	refund.Folder = "payments"
+	refund.Position = 3
+
+	return []*domain.Request{list, get, latest, refund}
+}
+
+func TestNewMockService_NilRepos(t *testing.T) {
+	assert.Panics(t, func() {
+		NewMockService(nil, new(MockHistoryRepository), slog.Default())
+	})
+	assert.Panics(t, func() {
+		NewMockService(new(MockRequestRepository), nil, slog.Default())
+	})
+}
+
+func TestMockService_Routes(t *testing.T) {
+	requests := mockTestRequests()
+	requestRepo := new(MockRequestRepository)
+	requestRepo.On("FindAll", mock.Anything).Return(requests, nil)
+
+	historyRepo := new(MockHistoryRepository)
+	historyRepo.On("FindByRequestID", mock.Anything, requests[0].ID, mockExampleSearch).Return([]*repository.HistoryEntry{
+		{ID: "failed", RequestID: requests[0].ID, Error: "connection refused"},
+		{ID: "ok", RequestID: requests[0].ID, StatusCode: 200, Status: "200 OK", ResponseBody: "[]"},
+	}, nil)
+	historyRepo.On("FindByRequestID", mock.Anything, requests[1].ID, mockExampleSearch).Return([]*repository.HistoryEntry{
+		{ID: "offloaded", RequestID: requests[1].ID, StatusCode: 200, ResponseBody: "preview", ResponseBodyRef: "abc"},
+	}, nil)
+	historyRepo.On("FindByID", mock.Anything, "offloaded").Return(&repository.HistoryEntry{ID: "offloaded", StatusCode: 200, ResponseBody: "full body"}, nil)
+	historyRepo.On("FindByRequestID", mock.Anything, mock.Anything, mockExampleSearch).Return([]*repository.HistoryEntry{}, nil)
+
+	service := NewMockService(requestRepo, historyRepo, slog.Default())
+	routes, missing, err := service.Routes(context.Background(), "payments")
+	require.NoError(t, err)
+
+	require.Len(t, routes, 2)
+	assert.Equal(t, "/payments", routes[0].Path)
+	assert.Equal(t, "ok", routes[0].Example.ID)
+	assert.Equal(t, "/payments/{{id}}", routes[1].Path)
+	assert.Equal(t, "full body", routes[1].Example.ResponseBody)
+
+	require.Len(t, missing, 2)
+	assert.Equal(t, "Latest payment", missing[0].Name)
+	assert.Equal(t, "Refund", missing[1].Name)
+
+	_, _, err = service.Routes(context.Background(), "orders")
+	assert.ErrorContains(t, err, `folder "orders" has no requests`)
+}
+
+func TestMockService_Handler(t *testing.T) {
+	requests := mockTestRequests()
+	example := func(status int, body string) *repository.HistoryEntry {
+		return &repository.HistoryEntry{
+			StatusCode:      status,
+			Status:          http.StatusText(status),
+			ResponseHeaders: `{"Content-Type":"application/json","Content-Length":"999","X-Request-Id":"abc"}`,
+			ResponseBody:    body,
+		}
+	}
+	routes := []*MockRoute{
+		{Request: requests[1], Method: "GET", Path: "/payments/{{id}}", Example: example(200, `{"id":"p1"}`), segments: []string{"payments", "{{id}}"}, wildcards: 1},
+		{Request: requests[2], Method: "GET", Path: "/payments/latest", Example: example(200, `{"id":"latest"}`), segments: []string{"payments", "latest"}},
+		{Request: requests[3], Method: "POST", Path: "/payments/:id/refund", Example: example(201, `{"refunded":true}`), segments: []string{"payments", ":id", "refund"}, wildcards: 1},
+	}
+
+	historyRepo := new(MockHistoryRepository)
+	var saved []*repository.HistoryEntry
+	historyRepo.On("Save", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		saved = append(saved, args.Get(1).(*repository.HistoryEntry))
+	}).Return(nil)
+
+	var log bytes.Buffer
+	handler := NewMockService(new(MockRequestRepository), historyRepo, slog.Default()).Handler(routes, &log)
+
+	serve := func(method, path, body string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(method, path, strings.NewReader(body)))
+		return recorder
+	}
+
+	resp := serve("GET", "/payments/p1", "")
+	assert.Equal(t, 200, resp.Code)
+	assert.Equal(t, `{"id":"p1"}`, resp.Body.String())
+	assert.Equal(t, "abc", resp.Header().Get("X-Request-Id"))
+	assert.Empty(t, resp.Header().Get("Content-Length"))
+
+	// A literal segment beats a variable.
+	resp = serve("GET", "/payments/latest/", "")
+	assert.Equal(t, `{"id":"latest"}`, resp.Body.String())
+
+	resp = serve("POST", "/payments/p1/refund", `{"amount":5}`)
+	assert.Equal(t, 201, resp.Code)
+
+	resp = serve("DELETE", "/payments/p1", "")
+	assert.Equal(t, http.StatusMethodNotAllowed, resp.Code)
+
+	resp = serve("GET", "/orders", "")
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+	assert.Contains(t, resp.Body.String(), "no saved request matches GET /orders")
+
+	lines := strings.Split(strings.TrimSpace(log.String()), "\n")
+	require.Len(t, lines, 5)
+	assert.Contains(t, lines[0], "GET /payments/p1 200 Get payment")
+	assert.Contains(t, lines[4], "GET /orders 404 -")
+
+	require.Len(t, saved, 5)
+	for _, entry := range saved {
+		assert.Empty(t, entry.RequestID)
+		assert.Equal(t, saved[0].RunID, entry.RunID)
+	}
+	refund, err := saved[2].Request()
+	require.NoError(t, err)
+	assert.Equal(t, "POST", refund.Method)
+	assert.Equal(t, `{"amount":5}`, refund.Body)
+	assert.Equal(t, 201, saved[2].StatusCode)
+}
+
+func TestMockService_Serve(t *testing.T) {
+	service := NewMockService(new(MockRequestRepository), new(MockHistoryRepository), slog.Default())
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- service.Serve(ctx, listener, http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusTeapot)
+		}))
+	}()
+
+	resp, err := http.Get("http://" + listener.Addr().String() + "/")
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+	assert.Equal(t, http.StatusTeapot, resp.StatusCode)
+
+	cancel()
+	assert.NoError(t, <-done)
+}
+
+func TestMockPath(t *testing.T) {
+	tests := map[string]string{
+		"https://api.example.com/users/{{id}}?expand=1": "/users/{{id}}",
+		"https://api.example.com":                       "/",
+		"{{baseUrl}}/users#top":                         "/users",
+		"{{baseUrl}}":                                   "/",
+		"/health":                                       "/health",
+	}
+	for rawURL, want := range tests {
+		assert.Equal(t, want, mockPath(rawURL), rawURL)
+	}
+}
@@ -0,0 +1,84 @@
+package app
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/williajm/curly/internal/domain"
+	"github.com/williajm/curly/internal/infrastructure/script"
+)
+
+// ValidateScripts checks that the pre-request and post-response scripts of
+// req compile. Empty scripts are valid.
+func ValidateScripts(req *domain.Request) error {
+	if err := script.Compile(script.PreRequest, req.PreRequestScript); err != nil {
+		return err
+	}
+	return script.Compile(script.PostResponse, req.PostResponseScript)
+}
+
+// runPreRequestScript runs the pre-request script of sent, the request about
+// to be sent, and returns the request with its changes. A request without a
+// script is returned as is.
+func (s *RequestService) runPreRequestScript(ctx context.Context, sent *domain.Request) (*domain.Request, error) {
+	if sent.PreRequestScript == "" {
+		return sent, nil
+	}
+	vars, err := s.activeVariables(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := script.RunPreRequest(ctx, sent.PreRequestScript, sent, vars)
+	if err != nil {
+		s.logger.Warn("pre-request script failed", "request_id", sent.ID, "error", err)
+		return nil, err
+	}
+	s.applyScriptResult(sent, script.PreRequest, result)
+	return result.Request, nil
+}
+
+// runPostResponseScript runs the post-response script of req on resp and
+// returns the assertions that failed. A script that fails is itself a
+// failure.
+func (s *RequestService) runPostResponseScript(ctx context.Context, req *domain.Request, resp *domain.Response) []string {
+	if req.PostResponseScript == "" {
+		return nil
+	}
+	vars, err := s.activeVariables(ctx)
+	if err != nil {
+		return []string{fmt.Sprintf("%s: %v", script.PostResponse, err)}
+	}
+
+	result, err := script.RunPostResponse(ctx, req.PostResponseScript, req, resp, vars)
+	if err != nil {
+		s.logger.Warn("post-response script failed", "request_id", req.ID, "error", err)
+		return []string{err.Error()}
+	}
+	s.applyScriptResult(req, script.PostResponse, result)
+	return result.AssertionFailures
+}
+
+// applyScriptResult keeps the variables a script set and logs what it
+// logged.
+func (s *RequestService) applyScriptResult(req *domain.Request, name string, result *script.Result) {
+	for _, line := range result.Logs {
+		s.logger.Info(name+" log", "request_id", req.ID, "message", line)
+	}
+	if len(result.Variables) == 0 {
+		return
+	}
+
+	s.scriptVarsMu.Lock()
+	defer s.scriptVarsMu.Unlock()
+	if s.scriptVars == nil {
+		s.scriptVars = map[string]string{}
+	}
+	for name, value := range result.Variables {
+		if value == nil {
+			delete(s.scriptVars, name)
+		} else {
+			s.scriptVars[name] = *value
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package app
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/williajm/curly/internal/domain"
+	"github.com/williajm/curly/internal/infrastructure/repository"
+)
+
+func TestExecuteBatch_Scripts(t *testing.T) {
+	httpClient := new(MockHTTPClient)
+	historyRepo := new(MockHistoryRepository)
+	service := NewRequestService(new(MockRequestRepository), httpClient, historyRepo, slog.Default())
+	service.SetVariables(staticVariables{"baseUrl": "https://api.example.com", "secret": "k"})
+
+	login := domain.NewRequestWithMethodAndURL("POST", "{{baseUrl}}/login")
+	login.PostResponseScript = `
+		assert(response.status === 200, "login failed");
+		vars.set("token", response.json().token);
+	`
+	orders := domain.NewRequestWithMethodAndURL("GET", "{{baseUrl}}/orders")
+	orders.Headers["Authorization"] = "Bearer {{token}}"
+	orders.PreRequestScript = `request.headers["X-Signature"] = crypto.hmacSha256(vars.get("secret"), request.url);`
+	orders.PostResponseScript = `assert(response.json().length === 2, "expected 2 orders");`
+
+	var sent []*domain.Request
+	httpClient.On("Execute", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		sent = append(sent, args.Get(1).(*domain.Request))
+	}).Return(&domain.Response{StatusCode: 200, Status: "200 OK", Body: `{"token":"t0k3n"}`}, nil).Once()
+	httpClient.On("Execute", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		sent = append(sent, args.Get(1).(*domain.Request))
+	}).Return(&domain.Response{StatusCode: 200, Status: "200 OK", Body: `[{"id":1}]`}, nil).Once()
+	var saved []*repository.HistoryEntry
+	historyRepo.On("SaveBatch", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		saved = args.Get(1).([]*repository.HistoryEntry)
+	}).Return(nil)
+
+	results := service.ExecuteBatch(context.Background(), []*domain.Request{login, orders})
+	require.Len(t, results, 2)
+	require.NoError(t, results[0].Err)
+	require.NoError(t, results[1].Err)
+	assert.True(t, results[0].Passed())
+
+	// The token the first request's script set is used by the second, which
+	// its own script signed.
+	require.Len(t, sent, 2)
+	assert.Equal(t, "Bearer t0k3n", sent[1].Headers["Authorization"])
+	assert.Equal(t, "d8939ed24ee48bddd31e352283b86b6d017195d41b23e09789d0bb4335e34e19", sent[1].Headers["X-Signature"])
+	assert.Same(t, sent[1], results[1].Sent)
+	assert.NotContains(t, orders.Headers, "X-Signature", "the saved request is left as it was")
+
+	assert.Equal(t, []string{"expected 2 orders"}, results[1].Response.AssertionFailures)
+	if assert.Len(t, saved, 2) {
+		assert.Contains(t, saved[1].RequestSnapshot, "X-Signature")
+	}
+}
+
+func TestExecuteRequest_PreRequestScriptFails(t *testing.T) {
+	httpClient := new(MockHTTPClient)
+	service := NewRequestService(new(MockRequestRepository), httpClient, new(MockHistoryRepository), slog.Default())
+
+	req := domain.NewRequestWithMethodAndURL("GET", "https://api.example.com/orders")
+	req.PreRequestScript = `throw new Error("no signing key")`
+
+	_, err := service.ExecuteRequest(context.Background(), req)
+	assert.ErrorContains(t, err, "pre-request script failed: Error: no signing key")
+
+	results := service.ExecuteBatch(context.Background(), []*domain.Request{req})
+	assert.ErrorContains(t, results[0].Err, "no signing key")
+	httpClient.AssertNotCalled(t, "Execute", mock.Anything, mock.Anything)
+}
+
+func TestExecuteRequest_PostResponseScriptFails(t *testing.T) {
+	httpClient := new(MockHTTPClient)
+	service := NewRequestService(new(MockRequestRepository), httpClient, new(MockHistoryRepository), slog.Default())
+
+	req := domain.NewRequestWithMethodAndURL("GET", "https://api.example.com/page")
+	req.PostResponseScript = `response.json()`
+	httpClient.On("Execute", mock.Anything, mock.Anything).Return(&domain.Response{StatusCode: 200, Body: "<html></html>"}, nil)
+
+	resp, err := service.ExecuteRequest(context.Background(), req)
+	require.NoError(t, err)
+	require.Len(t, resp.AssertionFailures, 1)
+	assert.Contains(t, resp.AssertionFailures[0], "post-response script failed")
+	assert.Contains(t, resp.AssertionFailures[0], "response body is not JSON")
+}
+
+func TestSaveRequest_InvalidScript(t *testing.T) {
+	repo := new(MockRequestRepository)
+	service := NewRequestService(repo, new(MockHTTPClient), new(MockHistoryRepository), slog.Default())
+
+	req := domain.NewRequestWithMethodAndURL("GET", "https://api.example.com/users")
+	req.PostResponseScript = "assert(response.status === 200"
+
+	err := service.SaveRequest(context.Background(), req)
+	assert.ErrorContains(t, err, "invalid post-response script")
+	repo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+
+	_, err = service.CreateRequest(context.Background(), req)
+	assert.ErrorContains(t, err, "invalid post-response script")
+}
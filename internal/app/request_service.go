@@ -7,23 +7,51 @@ package app
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
+	"net/url"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/williajm/curly/internal/domain"
+	"github.com/williajm/curly/internal/infrastructure/faker"
 	"github.com/williajm/curly/internal/infrastructure/http"
+	"github.com/williajm/curly/internal/infrastructure/jsonschema"
+	"github.com/williajm/curly/internal/infrastructure/openapi"
 	"github.com/williajm/curly/internal/infrastructure/repository"
 )
 
+// ErrExecutionFailed is wrapped by the errors of requests that could not be
+// sent or got no response, such as connection failures and timeouts, as
+// opposed to requests that were invalid.
+var ErrExecutionFailed = errors.New("failed to execute request")
+
 // RequestService orchestrates the full lifecycle of HTTP requests.
 // It handles creation, validation, execution, persistence, and retrieval of requests.
 type RequestService struct {
 	repo        repository.RequestRepository
 	httpClient  http.Client
 	historyRepo repository.HistoryRepository
+	faker       *faker.Faker
+	variables   VariableSource
+	saveHook    SaveHook
 	logger      *slog.Logger
+
+	// scriptVars are the variables set by request scripts, which last as
+	// long as the service and take precedence over the variable source.
+	scriptVarsMu sync.Mutex
+	scriptVars   map[string]string
+}
+
+// SaveHook can change a request that is about to be saved, or refuse to let
+// it be saved by returning an error.
+type SaveHook interface {
+	OnSave(ctx context.Context, req *domain.Request) error
 }
 
 // NewRequestService creates a new RequestService with the provided dependencies.
@@ -51,10 +79,28 @@ func NewRequestService(
 		repo:        repo,
 		httpClient:  httpClient,
 		historyRepo: historyRepo,
+		faker:       faker.NewRandom(),
 		logger:      logger,
 	}
 }
 
+// SetFaker replaces the generator used to fill {{fake.NAME}} placeholders,
+// for example with a seeded one so the values are reproducible.
+func (s *RequestService) SetFaker(f *faker.Faker) {
+	s.faker = f
+}
+
+// SetVariables makes requests resolve their {{name}} references from
+// source, typically the active environment, before they are sent.
+func (s *RequestService) SetVariables(source VariableSource) {
+	s.variables = source
+}
+
+// SetSaveHook makes SaveRequest pass every request to hook before saving it.
+func (s *RequestService) SetSaveHook(hook SaveHook) {
+	s.saveHook = hook
+}
+
 // CreateRequest creates a new request with validation.
 // It generates a unique ID and sets timestamps.
 // Returns an error if the request is invalid or cannot be persisted.
@@ -79,6 +125,12 @@ func (s *RequestService) CreateRequest(_ context.Context, req *domain.Request) (
 		)
 		return nil, fmt.Errorf("invalid request: %w", err)
 	}
+	if err := ValidateResponseSchema(req.ResponseSchema); err != nil {
+		return nil, err
+	}
+	if err := ValidateScripts(req); err != nil {
+		return nil, err
+	}
 
 	s.logger.Info("creating request",
 		"request_id", req.ID,
@@ -91,7 +143,9 @@ func (s *RequestService) CreateRequest(_ context.Context, req *domain.Request) (
 }
 
 // ExecuteRequest executes an HTTP request and returns the response.
-// It validates the request, applies authentication, and captures timing metrics.
+// It validates the request, resolves its variables, fills its fake data
+// placeholders, runs its scripts, applies authentication, and captures
+// timing metrics.
 // The request is NOT saved to the repository - use ExecuteAndSave for that.
 func (s *RequestService) ExecuteRequest(ctx context.Context, req *domain.Request) (*domain.Response, error) {
 	// Validate request before execution.
@@ -102,6 +156,13 @@ func (s *RequestService) ExecuteRequest(ctx context.Context, req *domain.Request
 		)
 		return nil, fmt.Errorf("invalid request: %w", err)
 	}
+	sent, err := s.prepare(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("invalid request: %w", err)
+	}
+	if sent, err = s.runPreRequestScript(ctx, sent); err != nil {
+		return nil, err
+	}
 
 	s.logger.Info("executing request",
 		"request_id", req.ID,
@@ -110,7 +171,7 @@ func (s *RequestService) ExecuteRequest(ctx context.Context, req *domain.Request
 	)
 
 	// Execute HTTP request.
-	resp, err := s.httpClient.Execute(ctx, req)
+	resp, err := s.httpClient.Execute(ctx, sent)
 	if err != nil {
 		s.logger.Error("request execution failed",
 			"request_id", req.ID,
@@ -118,7 +179,7 @@ func (s *RequestService) ExecuteRequest(ctx context.Context, req *domain.Request
 			"url", req.URL,
 			"error", err,
 		)
-		return nil, fmt.Errorf("failed to execute request: %w", err)
+		return nil, fmt.Errorf("%w: %w", ErrExecutionFailed, err)
 	}
 
 	s.logger.Info("request executed successfully",
@@ -126,14 +187,26 @@ func (s *RequestService) ExecuteRequest(ctx context.Context, req *domain.Request
 		"status_code", resp.StatusCode,
 		"duration_ms", resp.DurationMillis(),
 	)
+	s.checkResponse(ctx, sent, resp)
 
 	return resp, nil
 }
 
-// SaveRequest persists a request to the repository.
+// SaveRequest persists a request to the repository, after the save hook,
+// if any, has seen it.
 // If the request already exists (by ID), it will be updated.
 // Returns an error if the request cannot be saved.
 func (s *RequestService) SaveRequest(ctx context.Context, req *domain.Request) error {
+	if s.saveHook != nil {
+		if err := s.saveHook.OnSave(ctx, req); err != nil {
+			s.logger.Warn("save hook refused request",
+				"request_id", req.ID,
+				"error", err,
+			)
+			return err
+		}
+	}
+
 	// Validate before saving.
 	if err := req.Validate(); err != nil {
 		s.logger.Warn("cannot save invalid request",
@@ -142,6 +215,15 @@ func (s *RequestService) SaveRequest(ctx context.Context, req *domain.Request) e
 		)
 		return fmt.Errorf("invalid request: %w", err)
 	}
+	if err := ValidateResponseSchema(req.ResponseSchema); err != nil {
+		return err
+	}
+	if err := ValidateScripts(req); err != nil {
+		return err
+	}
+	if err := domain.ValidateExtractPath(req.ResponseFilter); err != nil {
+		return fmt.Errorf("invalid response filter: %w", err)
+	}
 
 	s.logger.Info("saving request",
 		"request_id", req.ID,
@@ -177,6 +259,32 @@ func (s *RequestService) SaveRequest(ctx context.Context, req *domain.Request) e
 	return nil
 }
 
+// SaveRequestToFolder saves a request like SaveRequest and files it in folder
+// (the empty folder is the top level). A request already saved in another
+// folder is moved to the end of folder.
+func (s *RequestService) SaveRequestToFolder(ctx context.Context, req *domain.Request, folder string) error {
+	existing, err := s.repo.FindByID(ctx, req.ID)
+	move := err == nil && existing != nil && existing.Folder != folder
+
+	req.Folder = folder
+	if err := s.SaveRequest(ctx, req); err != nil {
+		return err
+	}
+	if !move {
+		return nil
+	}
+
+	if err := s.repo.MoveMany(ctx, []string{req.ID}, folder); err != nil {
+		s.logger.Error("failed to move saved request",
+			"request_id", req.ID,
+			"folder", folder,
+			"error", err,
+		)
+		return fmt.Errorf("failed to move request: %w", err)
+	}
+	return nil
+}
+
 // LoadRequest retrieves a saved request by ID.
 // Returns an error if the request is not found.
 func (s *RequestService) LoadRequest(ctx context.Context, id string) (*domain.Request, error) {
@@ -199,6 +307,71 @@ func (s *RequestService) LoadRequest(ctx context.Context, id string) (*domain.Re
 	return req, nil
 }
 
+// SetResponseFilter remembers filter, a JSONPath or jq path, as the response
+// filter of the saved request with the given ID; "" clears it. A request that
+// has not been saved has nowhere to keep the filter, so it is left alone.
+func (s *RequestService) SetResponseFilter(ctx context.Context, id, filter string) error {
+	filter = strings.TrimSpace(filter)
+	if err := domain.ValidateExtractPath(filter); err != nil {
+		return fmt.Errorf("invalid response filter: %w", err)
+	}
+
+	req, err := s.repo.FindByID(ctx, id)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to load request: %w", err)
+	}
+	if req.ResponseFilter == filter {
+		return nil
+	}
+
+	req.ResponseFilter = filter
+	if err := s.repo.Update(ctx, req); err != nil {
+		return fmt.Errorf("failed to save response filter: %w", err)
+	}
+	s.logger.Debug("response filter saved", "request_id", id, "filter", filter)
+	return nil
+}
+
+// FindRequest looks up a saved request by reference: its full ID, its name
+// (case-insensitively), or a unique prefix of its ID.
+// Returns an error wrapping repository.ErrNotFound if nothing matches, or an
+// error if the reference matches more than one request.
+func (s *RequestService) FindRequest(ctx context.Context, ref string) (*domain.Request, error) {
+	requests, err := s.ListRequests(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var byName, byPrefix []*domain.Request
+	for _, req := range requests {
+		if req.ID == ref {
+			return req, nil
+		}
+		if strings.EqualFold(req.Name, ref) {
+			byName = append(byName, req)
+		}
+		if ref != "" && strings.HasPrefix(req.ID, ref) {
+			byPrefix = append(byPrefix, req)
+		}
+	}
+
+	for _, matches := range [][]*domain.Request{byName, byPrefix} {
+		switch len(matches) {
+		case 0:
+			continue
+		case 1:
+			return matches[0], nil
+		default:
+			return nil, fmt.Errorf("request %q is ambiguous: %d requests match", ref, len(matches))
+		}
+	}
+
+	return nil, fmt.Errorf("request %q: %w", ref, repository.ErrNotFound)
+}
+
 // ListRequests retrieves all saved requests.
 // Results are ordered by created_at descending (newest first).
 func (s *RequestService) ListRequests(ctx context.Context) ([]*domain.Request, error) {
@@ -214,6 +387,86 @@ func (s *RequestService) ListRequests(ctx context.Context) ([]*domain.Request, e
 	return requests, nil
 }
 
+// UsedHeaders returns the headers set on saved requests, by name, with the
+// distinct values each was set to, sorted, for offering them again. Names
+// that differ only in case are merged under the first spelling found. The
+// values of headers that usually carry credentials are left out.
+func (s *RequestService) UsedHeaders(ctx context.Context) (map[string][]string, error) {
+	requests, err := s.repo.FindAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list requests: %w", err)
+	}
+
+	names := make(map[string]string)
+	used := make(map[string][]string)
+	for _, req := range requests {
+		for name, value := range req.Headers {
+			key := strings.ToLower(name)
+			if _, ok := names[key]; !ok {
+				names[key] = name
+				used[name] = nil
+			}
+			name = names[key]
+			if value != "" && !domain.IsSecretName(name) && !slices.Contains(used[name], value) {
+				used[name] = append(used[name], value)
+			}
+		}
+	}
+	for _, values := range used {
+		slices.Sort(values)
+	}
+	return used, nil
+}
+
+// urlHistoryLimit is how many history entries KnownURLs reads.
+const urlHistoryLimit = 500
+
+// KnownURLs returns the URLs of recently executed requests, newest first,
+// then those of saved requests, and then the hosts they are on, such as
+// "https://api.example.com", for completing URLs as they are typed.
+// Duplicates are left out.
+func (s *RequestService) KnownURLs(ctx context.Context) ([]string, error) {
+	entries, err := s.historyRepo.FindAll(ctx, urlHistoryLimit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve history: %w", err)
+	}
+	requests, err := s.repo.FindAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list requests: %w", err)
+	}
+
+	var urls, hosts []string
+	seen := make(map[string]bool)
+	add := func(raw string) {
+		if raw == "" || seen[raw] {
+			return
+		}
+		seen[raw] = true
+		urls = append(urls, raw)
+		if parsed, err := url.Parse(raw); err == nil && parsed.Scheme != "" && parsed.Host != "" {
+			host := parsed.Scheme + "://" + parsed.Host
+			if !slices.Contains(hosts, host) {
+				hosts = append(hosts, host)
+			}
+		}
+	}
+	for _, entry := range entries {
+		// Entries recorded before request snapshots have no URL.
+		if req, err := entry.Request(); err == nil {
+			add(req.URL)
+		}
+	}
+	for _, req := range requests {
+		add(req.URL)
+	}
+	for _, host := range hosts {
+		if !seen[host] {
+			urls = append(urls, host)
+		}
+	}
+	return urls, nil
+}
+
 // DeleteRequest removes a saved request by ID.
 // Returns an error if the request is not found.
 func (s *RequestService) DeleteRequest(ctx context.Context, id string) error {
@@ -231,15 +484,60 @@ func (s *RequestService) DeleteRequest(ctx context.Context, id string) error {
 	return nil
 }
 
+// ExecutionResult is the outcome of executing one request in a batch.
+type ExecutionResult struct {
+	// Request is the request that was executed.
+	Request *domain.Request
+
+	// Sent is the request as sent, with its variables resolved and its fake
+	// data placeholders filled (nil if it was not executed).
+	Sent *domain.Request
+
+	// Response is the HTTP response, nil if the request failed.
+	Response *domain.Response
+
+	// Err is the validation or execution error, nil on success.
+	Err error
+
+	// ExecutedAt is when the request was sent (zero if it was not executed).
+	ExecutedAt time.Time
+
+	// RunID is the collection run the execution belongs to (empty outside a run).
+	RunID string
+
+	// ReplayOf is the history entry the execution replayed (empty if it was not a replay).
+	ReplayOf string
+}
+
 // ExecuteAndSave executes a request and saves the result to history.
 // This is an atomic operation that:.
-// 1. Validates the request.
+// 1. Validates the request, resolves its variables and fills its fake data placeholders.
 // 2. Executes the HTTP request.
-// 3. Saves the execution to history (even if the HTTP request failed).
+// 3. Saves the execution to history (even if the HTTP request failed) and
+// updates the request's usage metadata in the same transaction.
 // 4. Returns the response.
 //
 // If saving to history fails, it logs the error but doesn't fail the request.
 func (s *RequestService) ExecuteAndSave(ctx context.Context, req *domain.Request) (*domain.Response, error) {
+	return s.executeAndSave(ctx, req, "")
+}
+
+// ExecuteAdHoc executes a request that is not saved, like ExecuteAndSave.
+// The execution is recorded to history without a request, its entry keeping
+// the request as sent, and no saved request is created or updated.
+func (s *RequestService) ExecuteAdHoc(ctx context.Context, req *domain.Request) (*domain.Response, error) {
+	req.ID = ""
+	return s.executeAndSave(ctx, req, "")
+}
+
+// ExecuteReplay is ExecuteAndSave with the history entry linked to the
+// history entry replayOf, whose request is being re-sent.
+func (s *RequestService) ExecuteReplay(ctx context.Context, req *domain.Request, replayOf string) (*domain.Response, error) {
+	return s.executeAndSave(ctx, req, replayOf)
+}
+
+// executeAndSave implements ExecuteAndSave and ExecuteReplay.
+func (s *RequestService) executeAndSave(ctx context.Context, req *domain.Request, replayOf string) (*domain.Response, error) {
 	// Validate request.
 	if err := req.Validate(); err != nil {
 		s.logger.Warn("request validation failed",
@@ -248,74 +546,489 @@ func (s *RequestService) ExecuteAndSave(ctx context.Context, req *domain.Request
 		)
 		return nil, fmt.Errorf("invalid request: %w", err)
 	}
+	sent, err := s.prepare(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("invalid request: %w", err)
+	}
+	if sent, err = s.runPreRequestScript(ctx, sent); err != nil {
+		return nil, err
+	}
 
 	s.logger.Info("executing and saving request",
 		"request_id", req.ID,
 		"method", req.Method,
 		"url", req.URL,
+		"replay_of", replayOf,
 	)
 
-	// Execute HTTP request.
-	resp, err := s.httpClient.Execute(ctx, req)
+	executedAt := time.Now().UTC()
+	resp, err := s.execute(ctx, sent)
 
-	// Create history entry regardless of success or failure.
-	historyEntry := &repository.HistoryEntry{
-		ID:             uuid.New().String(),
-		RequestID:      req.ID,
-		ExecutedAt:     time.Now().UTC().Format(time.RFC3339),
-		ResponseTimeMs: 0,
+	// A request canceled by the caller is not recorded.
+	if errors.Is(ctx.Err(), context.Canceled) {
+		return nil, fmt.Errorf("failed to execute request: %w", context.Canceled)
 	}
+	s.saveExecutions(ctx, []ExecutionResult{{Request: req, Sent: sent, Response: resp, Err: err, ExecutedAt: executedAt, ReplayOf: replayOf}})
 
+	// Return the original error if execution failed.
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrExecutionFailed, err)
+	}
+
+	return resp, nil
+}
+
+// ExecuteBatch executes requests in order and records every execution to history
+// in a single transaction once all of them have run, which keeps runs of many
+// requests from paying for one write per request.
+// Invalid requests are reported in their result and are not executed or recorded.
+// Execution stops early if ctx is cancelled; the remaining results carry ctx's error.
+func (s *RequestService) ExecuteBatch(ctx context.Context, reqs []*domain.Request) []ExecutionResult {
+	return s.ExecuteRun(ctx, "", reqs)
+}
+
+// ExecuteRun is ExecuteBatch with every history entry tagged with runID, so
+// the executions of a collection run can be read back together. The
+// executions that completed are recorded even if ctx is cancelled, and the
+// progress of the batch is reported to the RunProgressFunc of ctx, if any.
+func (s *RequestService) ExecuteRun(ctx context.Context, runID string, reqs []*domain.Request) []ExecutionResult {
+	s.logger.Info("executing request batch", "count", len(reqs), "run_id", runID)
+
+	notify := runProgressFrom(ctx)
+	results := make([]ExecutionResult, len(reqs))
+	executed := make([]ExecutionResult, 0, len(reqs))
+
+	for i, req := range reqs {
+		results[i].Request = req
+		results[i].RunID = runID
+
+		started := func() { notify(RunProgress{Requests: reqs, Index: i}) }
+		if execution := s.executeRunRequest(ctx, &results[i], started); execution != nil {
+			executed = append(executed, *execution)
+		}
+
+		result := results[i]
+		notify(RunProgress{Requests: reqs, Index: i, Result: &result})
+	}
+
+	s.saveExecutions(context.WithoutCancel(ctx), executed)
+
+	return results
+}
+
+// executeRunRequest executes the request of result and fills result in. It
+// returns the execution to record, or nil if the request was not sent.
+// started is called just before the request is sent.
+func (s *RequestService) executeRunRequest(ctx context.Context, result *ExecutionResult, started func()) *ExecutionResult {
+	req := result.Request
+	if err := ctx.Err(); err != nil {
+		result.Err = err
+		return nil
+	}
+
+	if err := req.Validate(); err != nil {
+		result.Err = fmt.Errorf("invalid request: %w", err)
+		return nil
+	}
+	sent, err := s.prepare(ctx, req)
+	if err != nil {
+		result.Err = fmt.Errorf("invalid request: %w", err)
+		return nil
+	}
+	if sent, err = s.runPreRequestScript(ctx, sent); err != nil {
+		result.Err = err
+		return nil
+	}
+
+	started()
+	executedAt := time.Now().UTC()
+	resp, err := s.execute(ctx, sent)
+
+	result.Sent = sent
+	result.Response = resp
+	result.ExecutedAt = executedAt
+	if err != nil {
+		result.Err = fmt.Errorf("%w: %w", ErrExecutionFailed, err)
+	}
+	return &ExecutionResult{Request: req, Sent: sent, Response: resp, Err: err, ExecutedAt: executedAt, RunID: result.RunID}
+}
+
+// ResolveVariables returns req with its {{name}} references resolved from
+// the variables set by request scripts and the variable source, and the
+// names it references that have no value. A request without references is
+// returned as is.
+func (s *RequestService) ResolveVariables(ctx context.Context, req *domain.Request) (*domain.Request, []string, error) {
+	if !req.HasVariables() {
+		return req, nil, nil
+	}
+
+	vars, err := s.activeVariables(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	resolved, unresolved := req.ResolveVariables(vars)
+	return resolved, unresolved, nil
+}
+
+// activeVariables returns the variables in effect: those of the variable
+// source, overridden by those set by request scripts.
+func (s *RequestService) activeVariables(ctx context.Context) (map[string]string, error) {
+	vars := map[string]string{}
+	if s.variables != nil {
+		active, err := s.variables.ActiveVariables(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load variables: %w", err)
+		}
+		for name, value := range active {
+			vars[name] = value
+		}
+	}
+
+	s.scriptVarsMu.Lock()
+	defer s.scriptVarsMu.Unlock()
+	for name, value := range s.scriptVars {
+		vars[name] = value
+	}
+	return vars, nil
+}
+
+// RawRequest returns req in HTTP/1.1 wire format as it will be sent, with its
+// variables resolved, its fake data placeholders filled and its auth applied,
+// as built by the HTTP client. Fake data differs between calls, so a later
+// send fills in other values. Passing a response's Sent request shows the
+// request as it was sent.
+func (s *RequestService) RawRequest(ctx context.Context, req *domain.Request) (string, error) {
+	sent, err := s.prepare(ctx, req)
+	if err != nil {
+		return "", fmt.Errorf("invalid request: %w", err)
+	}
+	raw, err := http.DumpRequest(sent)
+	if err != nil {
+		return "", fmt.Errorf("failed to build raw request: %w", err)
+	}
+	return raw, nil
+}
+
+// Resolve returns req as ExecuteRequest would send it, but with its fake data
+// placeholders left in: validated, with its variables resolved and its
+// pre-request script run. It is for callers that send a request many times,
+// filling the placeholders afresh each time.
+func (s *RequestService) Resolve(ctx context.Context, req *domain.Request) (*domain.Request, error) {
+	if err := req.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid request: %w", err)
+	}
+	sent, err := s.resolve(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("invalid request: %w", err)
+	}
+	return s.runPreRequestScript(ctx, sent)
+}
+
+// prepare returns the request as it will be sent: with its variables
+// resolved and its fake data placeholders filled. It fails if a variable is
+// undefined or the resolved request is invalid.
+func (s *RequestService) prepare(ctx context.Context, req *domain.Request) (*domain.Request, error) {
+	sent, err := s.resolve(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return s.faker.ExpandRequest(sent)
+}
+
+// resolve returns req with its variables resolved. It fails if a variable is
+// undefined or the resolved request is invalid.
+func (s *RequestService) resolve(ctx context.Context, req *domain.Request) (*domain.Request, error) {
+	sent, unresolved, err := s.ResolveVariables(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if len(unresolved) > 0 {
+		return nil, fmt.Errorf("%w: %s", domain.ErrUndefinedVariable, strings.Join(unresolved, ", "))
+	}
+	if sent != req {
+		if err := sent.Validate(); err != nil {
+			return nil, err
+		}
+	}
+	return sent, nil
+}
+
+// execute sends a single request and logs the outcome.
+func (s *RequestService) execute(ctx context.Context, req *domain.Request) (*domain.Response, error) {
+	resp, err := s.httpClient.Execute(ctx, req)
 	if err != nil {
-		// Request failed - record the error.
-		historyEntry.Error = err.Error()
 		s.logger.Error("request execution failed",
 			"request_id", req.ID,
 			"error", err,
 		)
-	} else {
-		// Request succeeded - record the response.
-		historyEntry.StatusCode = resp.StatusCode
-		historyEntry.Status = resp.Status
-		historyEntry.ResponseTimeMs = resp.DurationMillis()
-		historyEntry.ResponseBody = resp.Body
-
-		// Convert headers map to JSON string using proper JSON marshaling.
-		headersBytes, err := json.Marshal(resp.Headers)
-		if err != nil {
-			// If marshaling fails, use empty JSON object.
-			s.logger.Error("failed to marshal response headers", "error", err)
-			historyEntry.ResponseHeaders = "{}"
-		} else {
-			historyEntry.ResponseHeaders = string(headersBytes)
-		}
+		return nil, err
+	}
 
-		s.logger.Info("request executed successfully",
+	s.logger.Info("request executed successfully",
+		"request_id", req.ID,
+		"status_code", resp.StatusCode,
+		"duration_ms", resp.DurationMillis(),
+	)
+	s.checkResponse(ctx, req, resp)
+	resp.Sent = req
+	return resp, nil
+}
+
+// checkResponse checks the response against the request's response schema
+// and response contract, and runs its post-response script, recording each
+// mismatch and failed assertion as an assertion failure after any that hooks
+// added.
+func (s *RequestService) checkResponse(ctx context.Context, req *domain.Request, resp *domain.Response) {
+	failures := append(schemaFailures(req, resp), contractFailures(req, resp)...)
+	failures = append(failures, s.runPostResponseScript(ctx, req, resp)...)
+	if len(failures) > 0 {
+		s.logger.Warn("response failed assertions",
 			"request_id", req.ID,
-			"status_code", resp.StatusCode,
-			"duration_ms", resp.DurationMillis(),
+			"failures", len(failures),
 		)
 	}
+	resp.AssertionFailures = append(resp.AssertionFailures, failures...)
+}
+
+// schemaFailures validates the response body against the request's response
+// schema. A schema that does not compile or a body that is not JSON is itself
+// a failure.
+func schemaFailures(req *domain.Request, resp *domain.Response) []string {
+	if strings.TrimSpace(req.ResponseSchema) == "" {
+		return nil
+	}
+
+	schema, err := jsonschema.Compile([]byte(req.ResponseSchema))
+	switch {
+	case err != nil:
+		return []string{fmt.Sprintf("invalid response schema: %v", err)}
+	case !json.Valid([]byte(resp.Body)):
+		return []string{"$: response body is not JSON"}
+	}
+
+	// The body is valid JSON, so Validate cannot fail.
+	violations, _ := schema.Validate([]byte(resp.Body))
+	failures := make([]string, len(violations))
+	for i, v := range violations {
+		failures[i] = v.String()
+	}
+	return failures
+}
+
+// contractFailures checks the response's status, headers and body against the
+// OpenAPI contract of the request's operation.
+func contractFailures(req *domain.Request, resp *domain.Response) []string {
+	if req.ResponseContract == "" {
+		return nil
+	}
+	contract, err := openapi.UnmarshalContract(req.ResponseContract)
+	if err != nil {
+		return []string{fmt.Sprintf("invalid response contract: %v", err)}
+	}
+	return contract.Check(resp)
+}
+
+// ValidateResponseSchema checks that schema is a JSON Schema curly can use.
+// An empty schema is valid and means the response is not checked.
+func ValidateResponseSchema(schema string) error {
+	if strings.TrimSpace(schema) == "" {
+		return nil
+	}
+	if _, err := jsonschema.Compile([]byte(schema)); err != nil {
+		return fmt.Errorf("invalid response schema: %w", err)
+	}
+	return nil
+}
+
+// saveExecutions records executions to history in one transaction, updating
+// the usage metadata of the saved requests involved.
+// It is best effort: failures are logged but not returned, so that a history
+// problem never hides the outcome of a request.
+func (s *RequestService) saveExecutions(ctx context.Context, results []ExecutionResult) {
+	if len(results) == 0 {
+		return
+	}
 
-	// Save to history (best effort - don't fail the request if history save fails).
-	if saveErr := s.historyRepo.Save(ctx, historyEntry); saveErr != nil {
+	entries := make([]*repository.HistoryEntry, len(results))
+	for i, result := range results {
+		entries[i] = s.newHistoryEntry(result)
+	}
+
+	if err := s.historyRepo.SaveBatch(ctx, entries); err != nil {
 		s.logger.Error("failed to save execution to history",
-			"request_id", req.ID,
-			"history_id", historyEntry.ID,
-			"error", saveErr,
+			"count", len(entries),
+			"error", err,
 		)
-		// Continue - we don't want to fail the request just because history save failed.
+		return
+	}
+
+	// Mirror the persisted usage metadata on the in-memory requests.
+	for _, result := range results {
+		result.Request.ExecutionCount++
+		if result.ExecutedAt.After(result.Request.LastExecutedAt) {
+			result.Request.LastExecutedAt = result.ExecutedAt
+		}
+	}
+
+	s.logger.Debug("executions saved to history", "count", len(entries))
+}
+
+// newHistoryEntry builds the history entry for an execution, recording a
+// snapshot of the request as sent, and the response on success or the error
+// on failure. The snapshot has variables resolved and fake data placeholders
+// filled, so a replay re-sends the same values.
+func (s *RequestService) newHistoryEntry(result ExecutionResult) *repository.HistoryEntry {
+	entry := &repository.HistoryEntry{
+		ID:         uuid.New().String(),
+		RequestID:  result.Request.ID,
+		ExecutedAt: result.ExecutedAt.Format(time.RFC3339),
+		RunID:      result.RunID,
+		ReplayOf:   result.ReplayOf,
+	}
+
+	sent := result.Sent
+	if sent == nil {
+		sent = result.Request
+	}
+	snapshot, err := repository.MarshalRequestSnapshot(sent)
+	if err != nil {
+		// The entry is still worth recording; it just cannot be replayed.
+		s.logger.Error("failed to snapshot request", "request_id", result.Request.ID, "error", err)
 	} else {
-		s.logger.Debug("execution saved to history",
-			"request_id", req.ID,
-			"history_id", historyEntry.ID,
+		entry.RequestSnapshot = snapshot
+	}
+
+	if result.Response == nil {
+		if result.Err != nil {
+			entry.Error = result.Err.Error()
+		}
+		return entry
+	}
+
+	resp := result.Response
+	entry.StatusCode = resp.StatusCode
+	entry.Status = resp.Status
+	entry.ResponseTimeMs = resp.DurationMillis()
+	entry.ResponseBody = resp.Body
+	entry.AssertionFailures = repository.MarshalAssertionFailures(resp.AssertionFailures)
+
+	// Convert headers map to JSON string using proper JSON marshaling.
+	headersBytes, err := json.Marshal(resp.Headers)
+	if err != nil {
+		// If marshaling fails, use empty JSON object.
+		s.logger.Error("failed to marshal response headers", "error", err)
+		entry.ResponseHeaders = "{}"
+	} else {
+		entry.ResponseHeaders = string(headersBytes)
+	}
+
+	return entry
+}
+
+// ListRecentlyUsedRequests retrieves saved requests with the most recently executed first.
+// If limit is 0, all requests are returned.
+func (s *RequestService) ListRecentlyUsedRequests(ctx context.Context, limit int) ([]*domain.Request, error) {
+	s.logger.Debug("listing recently used requests", "limit", limit)
+
+	requests, err := s.repo.FindRecentlyUsed(ctx, limit)
+	if err != nil {
+		s.logger.Error("failed to list recently used requests", "error", err)
+		return nil, fmt.Errorf("failed to list recently used requests: %w", err)
+	}
+
+	return requests, nil
+}
+
+// ListFolder retrieves the saved requests in a folder in their curated order.
+// The empty folder is the top level.
+func (s *RequestService) ListFolder(ctx context.Context, folder string) ([]*domain.Request, error) {
+	s.logger.Debug("listing folder", "folder", folder)
+
+	requests, err := s.repo.FindByFolder(ctx, folder)
+	if err != nil {
+		s.logger.Error("failed to list folder", "folder", folder, "error", err)
+		return nil, fmt.Errorf("failed to list folder: %w", err)
+	}
+
+	return requests, nil
+}
+
+// ListFolders returns the distinct folders that hold saved requests, sorted.
+// The top level is the empty folder and is listed when it holds any request.
+func (s *RequestService) ListFolders(ctx context.Context) ([]string, error) {
+	requests, err := s.ListRequests(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	var folders []string
+	for _, req := range requests {
+		if !seen[req.Folder] {
+			seen[req.Folder] = true
+			folders = append(folders, req.Folder)
+		}
+	}
+	sort.Strings(folders)
+
+	return folders, nil
+}
+
+// MoveRequest places a saved request at position within folder, which may be
+// a different folder from the one it is in. Out-of-range positions are clamped.
+func (s *RequestService) MoveRequest(ctx context.Context, id, folder string, position int) error {
+	s.logger.Info("moving request",
+		"request_id", id,
+		"folder", folder,
+		"position", position,
+	)
+
+	if err := s.repo.Move(ctx, id, folder, position); err != nil {
+		s.logger.Error("failed to move request",
+			"request_id", id,
+			"error", err,
 		)
+		return fmt.Errorf("failed to move request: %w", err)
 	}
 
-	// Return the original error if execution failed.
+	return nil
+}
+
+// MoveRequestUp swaps a saved request with the one before it in its folder.
+// Moving the first request is a no-op.
+func (s *RequestService) MoveRequestUp(ctx context.Context, id string) error {
+	return s.shiftRequest(ctx, id, -1)
+}
+
+// MoveRequestDown swaps a saved request with the one after it in its folder.
+// Moving the last request is a no-op.
+func (s *RequestService) MoveRequestDown(ctx context.Context, id string) error {
+	return s.shiftRequest(ctx, id, 1)
+}
+
+// shiftRequest moves a request by delta places within its folder.
+func (s *RequestService) shiftRequest(ctx context.Context, id string, delta int) error {
+	req, err := s.LoadRequest(ctx, id)
 	if err != nil {
-		return nil, fmt.Errorf("failed to execute request: %w", err)
+		return err
 	}
 
-	return resp, nil
+	siblings, err := s.ListFolder(ctx, req.Folder)
+	if err != nil {
+		return err
+	}
+
+	// Work from the index rather than the stored position so gaps don't matter.
+	for i, sibling := range siblings {
+		if sibling.ID != id {
+			continue
+		}
+		target := i + delta
+		if target < 0 || target >= len(siblings) {
+			return nil
+		}
+		return s.MoveRequest(ctx, id, req.Folder, target)
+	}
+
+	return nil
 }
@@ -3,13 +3,17 @@ package app
 import (
 	"context"
 	"errors"
+	"fmt"
 	"log/slog"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 	"github.com/williajm/curly/internal/domain"
+	"github.com/williajm/curly/internal/infrastructure/faker"
 	"github.com/williajm/curly/internal/infrastructure/repository"
 )
 
@@ -55,6 +59,47 @@ func (m *MockRequestRepository) Delete(ctx context.Context, id string) error {
 }
 
 // MockHTTPClient is a mock implementation of http.Client.
+func (m *MockRequestRepository) FindRecentlyUsed(ctx context.Context, limit int) ([]*domain.Request, error) {
+	args := m.Called(ctx, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Request), args.Error(1)
+}
+
+func (m *MockRequestRepository) RecordExecution(ctx context.Context, id string, executedAt time.Time) error {
+	args := m.Called(ctx, id, executedAt)
+	return args.Error(0)
+}
+
+func (m *MockRequestRepository) FindByFolder(ctx context.Context, folder string) ([]*domain.Request, error) {
+	args := m.Called(ctx, folder)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Request), args.Error(1)
+}
+
+func (m *MockRequestRepository) Move(ctx context.Context, id, folder string, position int) error {
+	args := m.Called(ctx, id, folder, position)
+	return args.Error(0)
+}
+
+func (m *MockRequestRepository) DeleteMany(ctx context.Context, ids []string) error {
+	args := m.Called(ctx, ids)
+	return args.Error(0)
+}
+
+func (m *MockRequestRepository) MoveMany(ctx context.Context, ids []string, folder string) error {
+	args := m.Called(ctx, ids, folder)
+	return args.Error(0)
+}
+
+func (m *MockRequestRepository) Retag(ctx context.Context, ids []string, add, remove []string) error {
+	args := m.Called(ctx, ids, add, remove)
+	return args.Error(0)
+}
+
 type MockHTTPClient struct {
 	mock.Mock
 }
@@ -77,6 +122,11 @@ func (m *MockHistoryRepository) Save(ctx context.Context, entry *repository.Hist
 	return args.Error(0)
 }
 
+func (m *MockHistoryRepository) SaveBatch(ctx context.Context, entries []*repository.HistoryEntry) error {
+	args := m.Called(ctx, entries)
+	return args.Error(0)
+}
+
 func (m *MockHistoryRepository) FindByID(ctx context.Context, id string) (*repository.HistoryEntry, error) {
 	args := m.Called(ctx, id)
 	if args.Get(0) == nil {
@@ -101,16 +151,128 @@ func (m *MockHistoryRepository) FindByRequestID(ctx context.Context, requestID s
 	return args.Get(0).([]*repository.HistoryEntry), args.Error(1)
 }
 
+func (m *MockHistoryRepository) FindByRunID(ctx context.Context, runID string) ([]*repository.HistoryEntry, error) {
+	args := m.Called(ctx, runID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*repository.HistoryEntry), args.Error(1)
+}
+
 func (m *MockHistoryRepository) Delete(ctx context.Context, id string) error {
 	args := m.Called(ctx, id)
 	return args.Error(0)
 }
 
+func (m *MockHistoryRepository) FindOlderThan(ctx context.Context, timestamp string) ([]*repository.HistoryEntry, error) {
+	args := m.Called(ctx, timestamp)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*repository.HistoryEntry), args.Error(1)
+}
+
 func (m *MockHistoryRepository) DeleteOlderThan(ctx context.Context, timestamp string) (int64, error) {
 	args := m.Called(ctx, timestamp)
 	return args.Get(0).(int64), args.Error(1)
 }
 
+func (m *MockHistoryRepository) DeleteExceptNewest(ctx context.Context, keep int) (int64, error) {
+	args := m.Called(ctx, keep)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockHistoryRepository) FindMatching(ctx context.Context, filter repository.HistoryFilter, limit int) ([]*repository.HistoryEntry, error) {
+	args := m.Called(ctx, filter, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*repository.HistoryEntry), args.Error(1)
+}
+
+func (m *MockHistoryRepository) FindPage(ctx context.Context, filter repository.HistoryFilter, offset, limit int) ([]*repository.HistoryEntry, error) {
+	args := m.Called(ctx, filter, offset, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*repository.HistoryEntry), args.Error(1)
+}
+
+func (m *MockHistoryRepository) CountMatching(ctx context.Context, filter repository.HistoryFilter) (int, error) {
+	args := m.Called(ctx, filter)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockHistoryRepository) DeleteMatching(ctx context.Context, filter repository.HistoryFilter) (int64, error) {
+	args := m.Called(ctx, filter)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockHistoryRepository) Stats(ctx context.Context) ([]*repository.RequestStats, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*repository.RequestStats), args.Error(1)
+}
+
+func (m *MockHistoryRepository) HostStats(ctx context.Context) ([]*repository.HostStats, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*repository.HostStats), args.Error(1)
+}
+
+// MockEnvironmentRepository is a mock implementation of repository.EnvironmentRepository.
+type MockEnvironmentRepository struct {
+	mock.Mock
+}
+
+func (m *MockEnvironmentRepository) Create(ctx context.Context, env *domain.Environment) error {
+	args := m.Called(ctx, env)
+	return args.Error(0)
+}
+
+func (m *MockEnvironmentRepository) Update(ctx context.Context, env *domain.Environment) error {
+	args := m.Called(ctx, env)
+	return args.Error(0)
+}
+
+func (m *MockEnvironmentRepository) Delete(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockEnvironmentRepository) FindAll(ctx context.Context) ([]*domain.Environment, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Environment), args.Error(1)
+}
+
+func (m *MockEnvironmentRepository) FindByName(ctx context.Context, name string) (*domain.Environment, error) {
+	args := m.Called(ctx, name)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Environment), args.Error(1)
+}
+
+func (m *MockEnvironmentRepository) FindActive(ctx context.Context) (*domain.Environment, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Environment), args.Error(1)
+}
+
+func (m *MockEnvironmentRepository) SetActive(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
 func TestNewRequestService(t *testing.T) {
 	repo := new(MockRequestRepository)
 	httpClient := new(MockHTTPClient)
@@ -306,6 +468,64 @@ func TestSaveRequest_Update(t *testing.T) {
 	repo.AssertExpectations(t)
 }
 
+func TestSaveRequestToFolder(t *testing.T) {
+	t.Run("new request", func(t *testing.T) {
+		repo := new(MockRequestRepository)
+		service := NewRequestService(repo, new(MockHTTPClient), new(MockHistoryRepository), slog.Default())
+
+		req := domain.NewRequestWithMethodAndURL("GET", "https://api.example.com/test")
+		repo.On("FindByID", mock.Anything, req.ID).Return(nil, repository.ErrNotFound)
+		repo.On("Create", mock.Anything, mock.MatchedBy(func(r *domain.Request) bool {
+			return r.Folder == "users"
+		})).Return(nil)
+
+		require.NoError(t, service.SaveRequestToFolder(context.Background(), req, "users"))
+		repo.AssertExpectations(t)
+		repo.AssertNotCalled(t, "MoveMany", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("same folder", func(t *testing.T) {
+		repo := new(MockRequestRepository)
+		service := NewRequestService(repo, new(MockHTTPClient), new(MockHistoryRepository), slog.Default())
+
+		req := domain.NewRequestWithMethodAndURL("GET", "https://api.example.com/test")
+		req.Folder = "users"
+		repo.On("FindByID", mock.Anything, req.ID).Return(req.Clone(), nil)
+		repo.On("Update", mock.Anything, req).Return(nil)
+
+		require.NoError(t, service.SaveRequestToFolder(context.Background(), req, "users"))
+		repo.AssertExpectations(t)
+		repo.AssertNotCalled(t, "MoveMany", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("other folder", func(t *testing.T) {
+		repo := new(MockRequestRepository)
+		service := NewRequestService(repo, new(MockHTTPClient), new(MockHistoryRepository), slog.Default())
+
+		req := domain.NewRequestWithMethodAndURL("GET", "https://api.example.com/test")
+		repo.On("FindByID", mock.Anything, req.ID).Return(req.Clone(), nil)
+		repo.On("Update", mock.Anything, req).Return(nil)
+		repo.On("MoveMany", mock.Anything, []string{req.ID}, "users/admin").Return(nil)
+
+		require.NoError(t, service.SaveRequestToFolder(context.Background(), req, "users/admin"))
+		assert.Equal(t, "users/admin", req.Folder)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("move fails", func(t *testing.T) {
+		repo := new(MockRequestRepository)
+		service := NewRequestService(repo, new(MockHTTPClient), new(MockHistoryRepository), slog.Default())
+
+		req := domain.NewRequestWithMethodAndURL("GET", "https://api.example.com/test")
+		repo.On("FindByID", mock.Anything, req.ID).Return(req.Clone(), nil)
+		repo.On("Update", mock.Anything, req).Return(nil)
+		repo.On("MoveMany", mock.Anything, []string{req.ID}, "users").Return(errors.New("db down"))
+
+		err := service.SaveRequestToFolder(context.Background(), req, "users")
+		assert.ErrorContains(t, err, "failed to move request")
+	})
+}
+
 func TestSaveRequest_InvalidRequest(t *testing.T) {
 	repo := new(MockRequestRepository)
 	httpClient := new(MockHTTPClient)
@@ -389,6 +609,58 @@ func TestListRequests_Success(t *testing.T) {
 	repo.AssertExpectations(t)
 }
 
+func TestUsedHeaders(t *testing.T) {
+	repo := new(MockRequestRepository)
+	service := NewRequestService(repo, new(MockHTTPClient), new(MockHistoryRepository), slog.Default())
+
+	a := domain.NewRequestWithMethodAndURL("GET", "https://api.example.com/a")
+	a.Headers = map[string]string{"X-Tenant": "acme", "Authorization": "Bearer s3cret"}
+	b := domain.NewRequestWithMethodAndURL("GET", "https://api.example.com/b")
+	b.Headers = map[string]string{"X-Tenant": "globex", "X-Auth-Token": "s3cret"}
+	c := domain.NewRequestWithMethodAndURL("GET", "https://api.example.com/c")
+	c.Headers = map[string]string{"X-Tenant": "acme", "Accept": ""}
+	repo.On("FindAll", mock.Anything).Return([]*domain.Request{a, b, c}, nil)
+
+	used, err := service.UsedHeaders(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]string{
+		"X-Tenant":      {"acme", "globex"},
+		"Authorization": nil,
+		"X-Auth-Token":  nil,
+		"Accept":        nil,
+	}, used)
+}
+
+func TestKnownURLs(t *testing.T) {
+	repo := new(MockRequestRepository)
+	historyRepo := new(MockHistoryRepository)
+	service := NewRequestService(repo, new(MockHTTPClient), historyRepo, slog.Default())
+
+	historyRepo.On("FindAll", mock.Anything, urlHistoryLimit).Return([]*repository.HistoryEntry{
+		{ID: "newest", RequestSnapshot: `{"method":"GET","url":"https://api.example.com/users/1"}`},
+		{ID: "no-snapshot"},
+		{ID: "oldest", RequestSnapshot: `{"method":"GET","url":"https://api.example.com/users"}`},
+	}, nil)
+	repo.On("FindAll", mock.Anything).Return([]*domain.Request{
+		domain.NewRequestWithMethodAndURL("GET", "https://api.example.com/users"),
+		domain.NewRequestWithMethodAndURL("GET", "http://localhost:8080/health"),
+		domain.NewRequestWithMethodAndURL("GET", "{{baseUrl}}/orders"),
+	}, nil)
+
+	urls, err := service.KnownURLs(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"https://api.example.com/users/1",
+		"https://api.example.com/users",
+		"http://localhost:8080/health",
+		"{{baseUrl}}/orders",
+		"https://api.example.com",
+		"http://localhost:8080",
+	}, urls)
+}
+
 func TestListRequests_Error(t *testing.T) {
 	repo := new(MockRequestRepository)
 	httpClient := new(MockHTTPClient)
@@ -465,18 +737,349 @@ func TestExecuteAndSave_Success(t *testing.T) {
 	}
 
 	httpClient.On("Execute", mock.Anything, req).Return(expectedResp, nil)
-	historyRepo.On("Save", mock.Anything, mock.AnythingOfType("*repository.HistoryEntry")).Return(nil)
+	historyRepo.On("SaveBatch", mock.Anything, mock.AnythingOfType("[]*repository.HistoryEntry")).Return(nil)
 
 	resp, err := service.ExecuteAndSave(context.Background(), req)
 
 	assert.NoError(t, err)
 	assert.NotNil(t, resp)
 	assert.Equal(t, 200, resp.StatusCode)
+	assert.Equal(t, int64(1), req.ExecutionCount)
+	assert.False(t, req.LastExecutedAt.IsZero())
 
 	httpClient.AssertExpectations(t)
 	historyRepo.AssertExpectations(t)
 }
 
+func TestExecuteAndSave_Canceled(t *testing.T) {
+	repo := new(MockRequestRepository)
+	httpClient := new(MockHTTPClient)
+	historyRepo := new(MockHistoryRepository)
+	logger := slog.Default()
+
+	service := NewRequestService(repo, httpClient, historyRepo, logger)
+
+	req := domain.NewRequestWithMethodAndURL("GET", "https://api.example.com/test")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	httpClient.On("Execute", mock.Anything, mock.Anything).
+		Run(func(mock.Arguments) { cancel() }).
+		Return(nil, fmt.Errorf("request canceled: %w", context.Canceled))
+
+	resp, err := service.ExecuteAndSave(ctx, req)
+
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Nil(t, resp)
+	assert.Zero(t, req.ExecutionCount)
+	historyRepo.AssertNotCalled(t, "SaveBatch", mock.Anything, mock.Anything)
+}
+
+func TestExecuteAdHoc_RecordsHistoryOnly(t *testing.T) {
+	repo := new(MockRequestRepository)
+	httpClient := new(MockHTTPClient)
+	historyRepo := new(MockHistoryRepository)
+	service := NewRequestService(repo, httpClient, historyRepo, slog.Default())
+
+	req := domain.NewRequestWithMethodAndURL("GET", "https://api.example.com/users")
+	req.Headers["X-Trace"] = "1"
+	httpClient.On("Execute", mock.Anything, mock.Anything).Return(&domain.Response{StatusCode: 200, Headers: map[string]string{}}, nil)
+	var saved []*repository.HistoryEntry
+	historyRepo.On("SaveBatch", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		saved = args.Get(1).([]*repository.HistoryEntry)
+	}).Return(nil)
+
+	resp, err := service.ExecuteAdHoc(context.Background(), req)
+	require.NoError(t, err)
+	assert.Equal(t, 200, resp.StatusCode)
+
+	// The entry belongs to no request and records what was sent.
+	require.Len(t, saved, 1)
+	assert.Empty(t, saved[0].RequestID)
+	sent, err := saved[0].Request()
+	require.NoError(t, err)
+	assert.Equal(t, "https://api.example.com/users", sent.URL)
+	assert.Equal(t, "1", sent.Headers["X-Trace"])
+	repo.AssertNotCalled(t, "FindByFolder", mock.Anything, mock.Anything)
+	repo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	repo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+}
+
+func TestExecuteAdHoc_Invalid(t *testing.T) {
+	historyRepo := new(MockHistoryRepository)
+	service := NewRequestService(new(MockRequestRepository), new(MockHTTPClient), historyRepo, slog.Default())
+
+	_, err := service.ExecuteAdHoc(context.Background(), domain.NewRequestWithMethodAndURL("GET", "ftp://example.com"))
+	assert.ErrorIs(t, err, domain.ErrInvalidURL)
+	historyRepo.AssertNotCalled(t, "SaveBatch", mock.Anything, mock.Anything)
+}
+
+func TestExecuteAndSave_RecordsRequestSnapshot(t *testing.T) {
+	httpClient := new(MockHTTPClient)
+	historyRepo := new(MockHistoryRepository)
+	service := NewRequestService(new(MockRequestRepository), httpClient, historyRepo, slog.Default())
+
+	// An unsaved request: the snapshot is the only record of what was sent.
+	req := domain.NewRequestWithMethodAndURL("PUT", "https://api.example.com/items/1")
+	req.Headers["Content-Type"] = "application/json"
+	req.Body = `{"name":"widget"}`
+	req.AuthConfig = domain.NewBasicAuth("user", "pass")
+
+	httpClient.On("Execute", mock.Anything, req).Return(nil, errors.New("connection refused"))
+	var saved []*repository.HistoryEntry
+	historyRepo.On("SaveBatch", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		saved = args.Get(1).([]*repository.HistoryEntry)
+	}).Return(nil)
+
+	_, err := service.ExecuteAndSave(context.Background(), req)
+	assert.Error(t, err)
+
+	if assert.Len(t, saved, 1) {
+		got, err := saved[0].Request()
+		assert.NoError(t, err)
+		assert.Equal(t, "PUT", got.Method)
+		assert.Equal(t, req.URL, got.URL)
+		assert.Equal(t, req.Headers, got.Headers)
+		assert.Equal(t, req.Body, got.Body)
+		assert.Equal(t, "basic", got.AuthConfig.Type())
+		assert.Empty(t, saved[0].ReplayOf)
+	}
+}
+
+func TestExecuteAndSave_FillsFakePlaceholders(t *testing.T) {
+	httpClient := new(MockHTTPClient)
+	historyRepo := new(MockHistoryRepository)
+	service := NewRequestService(new(MockRequestRepository), httpClient, historyRepo, slog.Default())
+	service.SetFaker(faker.New(1))
+
+	req := domain.NewRequestWithMethodAndURL("POST", "https://api.example.com/users")
+	req.Body = `{"email": "{{fake.email}}"}`
+
+	var sent *domain.Request
+	httpClient.On("Execute", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		sent = args.Get(1).(*domain.Request)
+	}).Return(&domain.Response{StatusCode: 201, Status: "201 Created"}, nil)
+	var saved []*repository.HistoryEntry
+	historyRepo.On("SaveBatch", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		saved = args.Get(1).([]*repository.HistoryEntry)
+	}).Return(nil)
+
+	_, err := service.ExecuteAndSave(context.Background(), req)
+	require.NoError(t, err)
+
+	require.NotNil(t, sent)
+	assert.NotContains(t, sent.Body, "fake.")
+	assert.Equal(t, `{"email": "{{fake.email}}"}`, req.Body, "the saved request keeps its placeholders")
+	if assert.Len(t, saved, 1) {
+		got, err := saved[0].Request()
+		require.NoError(t, err)
+		assert.Equal(t, sent.Body, got.Body, "history records the values that were sent")
+	}
+
+	req.Body = `{"size": "{{fake.shoeSize}}"}`
+	_, err = service.ExecuteAndSave(context.Background(), req)
+	assert.ErrorContains(t, err, `unknown fake generator "shoeSize"`)
+	httpClient.AssertNumberOfCalls(t, "Execute", 1)
+}
+
+// staticVariables is a VariableSource with fixed variables.
+type staticVariables map[string]string
+
+func (v staticVariables) ActiveVariables(context.Context) (map[string]string, error) {
+	return v, nil
+}
+
+func TestExecuteAndSave_ResolvesVariables(t *testing.T) {
+	httpClient := new(MockHTTPClient)
+	historyRepo := new(MockHistoryRepository)
+	service := NewRequestService(new(MockRequestRepository), httpClient, historyRepo, slog.Default())
+	service.SetVariables(staticVariables{"baseUrl": "https://api.example.com", "token": "s3cret"})
+
+	req := domain.NewRequestWithMethodAndURL("GET", "{{baseUrl}}/users")
+	req.SetAuth(domain.NewBearerAuth("{{token}}"))
+
+	var sent *domain.Request
+	httpClient.On("Execute", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		sent = args.Get(1).(*domain.Request)
+	}).Return(&domain.Response{StatusCode: 200, Status: "200 OK"}, nil)
+	historyRepo.On("SaveBatch", mock.Anything, mock.Anything).Return(nil)
+
+	resp, err := service.ExecuteAndSave(context.Background(), req)
+	require.NoError(t, err)
+
+	require.NotNil(t, sent)
+	assert.Same(t, sent, resp.Sent)
+	assert.Equal(t, "https://api.example.com/users", sent.URL)
+	assert.Equal(t, "s3cret", sent.AuthConfig.(*domain.BearerAuth).Token)
+	assert.Equal(t, "{{baseUrl}}/users", req.URL, "the saved request keeps its variables")
+
+	// An undefined variable fails before anything is sent.
+	req.URL = "{{host}}/users"
+	_, err = service.ExecuteAndSave(context.Background(), req)
+	assert.ErrorIs(t, err, domain.ErrUndefinedVariable)
+	assert.ErrorContains(t, err, "host")
+
+	// So does a URL that is invalid once resolved.
+	service.SetVariables(staticVariables{"baseUrl": "not a url", "token": "s3cret"})
+	req.URL = "{{baseUrl}}/users"
+	_, err = service.ExecuteAndSave(context.Background(), req)
+	assert.ErrorIs(t, err, domain.ErrInvalidURL)
+	httpClient.AssertNumberOfCalls(t, "Execute", 1)
+}
+
+func TestRawRequest(t *testing.T) {
+	httpClient := new(MockHTTPClient)
+	service := NewRequestService(new(MockRequestRepository), httpClient, new(MockHistoryRepository), slog.Default())
+	service.SetVariables(staticVariables{"baseUrl": "https://api.example.com", "token": "s3cret"})
+
+	req := domain.NewRequestWithMethodAndURL("POST", "{{baseUrl}}/users")
+	req.SetAuth(domain.NewBearerAuth("{{token}}"))
+	req.Body = `{"id":"{{fake.uuid}}"}`
+
+	raw, err := service.RawRequest(context.Background(), req)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(raw, "POST /users HTTP/1.1\r\n"), raw)
+	assert.Contains(t, raw, "Host: api.example.com\r\n")
+	assert.Contains(t, raw, "Authorization: Bearer s3cret\r\n")
+	assert.NotContains(t, raw, "{{fake.uuid}}")
+	httpClient.AssertNotCalled(t, "Execute", mock.Anything, mock.Anything)
+
+	req.URL = "{{host}}/users"
+	_, err = service.RawRequest(context.Background(), req)
+	assert.ErrorIs(t, err, domain.ErrUndefinedVariable)
+}
+
+func TestResolveVariables_WithoutSource(t *testing.T) {
+	service := NewRequestService(new(MockRequestRepository), new(MockHTTPClient), new(MockHistoryRepository), slog.Default())
+
+	req := domain.NewRequestWithMethodAndURL("GET", "{{baseUrl}}/users")
+	resolved, unresolved, err := service.ResolveVariables(context.Background(), req)
+	require.NoError(t, err)
+	assert.Equal(t, req.URL, resolved.URL)
+	assert.Equal(t, []string{"baseUrl"}, unresolved)
+
+	req.URL = "https://api.example.com/users"
+	resolved, unresolved, err = service.ResolveVariables(context.Background(), req)
+	require.NoError(t, err)
+	assert.Same(t, req, resolved)
+	assert.Empty(t, unresolved)
+}
+
+func TestExecuteAndSave_ValidatesResponseSchema(t *testing.T) {
+	httpClient := new(MockHTTPClient)
+	historyRepo := new(MockHistoryRepository)
+	service := NewRequestService(new(MockRequestRepository), httpClient, historyRepo, slog.Default())
+
+	req := domain.NewRequestWithMethodAndURL("GET", "https://api.example.com/users/1")
+	req.ResponseSchema = `{"type":"object","required":["id","name"],"properties":{"id":{"type":"integer"}}}`
+
+	httpClient.On("Execute", mock.Anything, req).Return(&domain.Response{
+		StatusCode: 200,
+		Headers:    map[string]string{},
+		Body:       `{"id":"1"}`,
+	}, nil)
+	var saved []*repository.HistoryEntry
+	historyRepo.On("SaveBatch", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		saved = args.Get(1).([]*repository.HistoryEntry)
+	}).Return(nil)
+
+	resp, err := service.ExecuteAndSave(context.Background(), req)
+	require.NoError(t, err)
+
+	want := []string{`$: missing required property "name"`, "$.id: expected integer, got string"}
+	assert.Equal(t, want, resp.AssertionFailures)
+	assert.False(t, ExecutionResult{Request: req, Response: resp}.Passed())
+	if assert.Len(t, saved, 1) {
+		assert.Equal(t, want, saved[0].Failures())
+	}
+}
+
+func TestExecuteRequest_ResponseSchemaFailures(t *testing.T) {
+	httpClient := new(MockHTTPClient)
+	service := NewRequestService(new(MockRequestRepository), httpClient, new(MockHistoryRepository), slog.Default())
+
+	req := domain.NewRequestWithMethodAndURL("GET", "https://api.example.com/page")
+	req.ResponseSchema = `{"type":"object"}`
+	httpClient.On("Execute", mock.Anything, req).Return(&domain.Response{StatusCode: 200, Body: "<html></html>"}, nil)
+
+	resp, err := service.ExecuteRequest(context.Background(), req)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"$: response body is not JSON"}, resp.AssertionFailures)
+
+	// Without a schema nothing is checked.
+	plain := domain.NewRequestWithMethodAndURL("GET", "https://api.example.com/page")
+	httpClient.On("Execute", mock.Anything, plain).Return(&domain.Response{StatusCode: 200, Body: "<html></html>"}, nil)
+	resp, err = service.ExecuteRequest(context.Background(), plain)
+	require.NoError(t, err)
+	assert.Empty(t, resp.AssertionFailures)
+}
+
+func TestExecuteRequest_ResponseContractFailures(t *testing.T) {
+	httpClient := new(MockHTTPClient)
+	service := NewRequestService(new(MockRequestRepository), httpClient, new(MockHistoryRepository), slog.Default())
+
+	req := domain.NewRequestWithMethodAndURL("GET", "https://api.example.com/users/1")
+	req.ResponseSchema = `{"required":["name"]}`
+	req.ResponseContract = `{"method":"GET","path":"/users/{id}","responses":{"200":{` +
+		`"content":{"application/json":{"type":"object","properties":{"id":{"type":"integer"}}}}}}}`
+	httpClient.On("Execute", mock.Anything, req).Return(&domain.Response{
+		StatusCode: 200,
+		Headers:    map[string]string{"Content-Type": "application/json"},
+		Body:       `{"id":"1"}`,
+	}, nil)
+
+	resp, err := service.ExecuteRequest(context.Background(), req)
+	require.NoError(t, err)
+	assert.Equal(t, []string{`$: missing required property "name"`, "$.id: expected integer, got string"}, resp.AssertionFailures)
+
+	broken := domain.NewRequestWithMethodAndURL("GET", "https://api.example.com/users/2")
+	broken.ResponseContract = "{"
+	httpClient.On("Execute", mock.Anything, broken).Return(&domain.Response{StatusCode: 200}, nil)
+	resp, err = service.ExecuteRequest(context.Background(), broken)
+	require.NoError(t, err)
+	require.Len(t, resp.AssertionFailures, 1)
+	assert.Contains(t, resp.AssertionFailures[0], "invalid response contract")
+}
+
+func TestSetResponseFilter(t *testing.T) {
+	repo := new(MockRequestRepository)
+	service := NewRequestService(repo, new(MockHTTPClient), new(MockHistoryRepository), slog.Default())
+
+	saved := domain.NewRequestWithMethodAndURL("GET", "https://api.example.com/users")
+	repo.On("FindByID", mock.Anything, saved.ID).Return(saved, nil)
+	repo.On("Update", mock.Anything, mock.MatchedBy(func(req *domain.Request) bool {
+		return req.ResponseFilter == "$.users[*].id"
+	})).Return(nil).Once()
+
+	require.NoError(t, service.SetResponseFilter(context.Background(), saved.ID, " $.users[*].id "))
+	assert.Equal(t, "$.users[*].id", saved.ResponseFilter)
+
+	// Setting the same filter again does not write.
+	require.NoError(t, service.SetResponseFilter(context.Background(), saved.ID, "$.users[*].id"))
+
+	// Unsaved requests are left alone.
+	repo.On("FindByID", mock.Anything, "unsaved").Return(nil, repository.ErrNotFound)
+	require.NoError(t, service.SetResponseFilter(context.Background(), "unsaved", ".id"))
+
+	err := service.SetResponseFilter(context.Background(), saved.ID, "$.users[")
+	assert.ErrorContains(t, err, "invalid response filter")
+	repo.AssertExpectations(t)
+}
+
+func TestSaveRequest_InvalidResponseSchema(t *testing.T) {
+	repo := new(MockRequestRepository)
+	service := NewRequestService(repo, new(MockHTTPClient), new(MockHistoryRepository), slog.Default())
+
+	req := domain.NewRequestWithMethodAndURL("GET", "https://api.example.com/users")
+	req.ResponseSchema = `{"pattern": "("}`
+
+	err := service.SaveRequest(context.Background(), req)
+	assert.ErrorContains(t, err, "invalid response schema")
+	repo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+
+	_, err = service.CreateRequest(context.Background(), req)
+	assert.ErrorContains(t, err, "invalid response schema")
+}
+
 func TestExecuteAndSave_HTTPError(t *testing.T) {
 	repo := new(MockRequestRepository)
 	httpClient := new(MockHTTPClient)
@@ -489,13 +1092,14 @@ func TestExecuteAndSave_HTTPError(t *testing.T) {
 
 	httpClient.On("Execute", mock.Anything, req).Return(nil, errors.New("network error"))
 	// History should still be saved even on error.
-	historyRepo.On("Save", mock.Anything, mock.AnythingOfType("*repository.HistoryEntry")).Return(nil)
+	historyRepo.On("SaveBatch", mock.Anything, mock.AnythingOfType("[]*repository.HistoryEntry")).Return(nil)
 
 	resp, err := service.ExecuteAndSave(context.Background(), req)
 
 	assert.Error(t, err)
 	assert.Nil(t, resp)
 	assert.Contains(t, err.Error(), "failed to execute request")
+	assert.ErrorIs(t, err, ErrExecutionFailed)
 
 	httpClient.AssertExpectations(t)
 	historyRepo.AssertExpectations(t)
@@ -520,7 +1124,7 @@ func TestExecuteAndSave_HistorySaveError(t *testing.T) {
 	}
 
 	httpClient.On("Execute", mock.Anything, req).Return(expectedResp, nil)
-	historyRepo.On("Save", mock.Anything, mock.AnythingOfType("*repository.HistoryEntry")).Return(errors.New("db error"))
+	historyRepo.On("SaveBatch", mock.Anything, mock.AnythingOfType("[]*repository.HistoryEntry")).Return(errors.New("db error"))
 
 	// Should still succeed even if history save fails.
 	resp, err := service.ExecuteAndSave(context.Background(), req)
@@ -549,6 +1153,225 @@ func TestExecuteAndSave_ValidationError(t *testing.T) {
 	assert.Error(t, err)
 	assert.Nil(t, resp)
 	assert.Contains(t, err.Error(), "invalid request")
+	assert.NotErrorIs(t, err, ErrExecutionFailed)
+}
+
+func TestExecuteAndSave_RecordsErrorInHistory(t *testing.T) {
+	repo := new(MockRequestRepository)
+	httpClient := new(MockHTTPClient)
+	historyRepo := new(MockHistoryRepository)
+
+	service := NewRequestService(repo, httpClient, historyRepo, slog.Default())
+
+	req := domain.NewRequestWithMethodAndURL("GET", "https://api.example.com/test")
+
+	httpClient.On("Execute", mock.Anything, req).Return(nil, errors.New("connection refused"))
+	historyRepo.On("SaveBatch", mock.Anything, mock.MatchedBy(func(entries []*repository.HistoryEntry) bool {
+		return len(entries) == 1 &&
+			entries[0].RequestID == req.ID &&
+			entries[0].Error == "connection refused" &&
+			entries[0].StatusCode == 0
+	})).Return(nil)
+
+	_, err := service.ExecuteAndSave(context.Background(), req)
+
+	assert.Error(t, err)
+	historyRepo.AssertExpectations(t)
+}
+
+func TestExecuteBatch(t *testing.T) {
+	repo := new(MockRequestRepository)
+	httpClient := new(MockHTTPClient)
+	historyRepo := new(MockHistoryRepository)
+
+	service := NewRequestService(repo, httpClient, historyRepo, slog.Default())
+
+	ok := domain.NewRequestWithMethodAndURL("GET", "https://api.example.com/ok")
+	failing := domain.NewRequestWithMethodAndURL("GET", "https://api.example.com/down")
+	invalid := domain.NewRequestWithMethodAndURL("GET", "")
+
+	okResp := &domain.Response{StatusCode: 200, Status: "200 OK", Headers: map[string]string{}}
+	httpClient.On("Execute", mock.Anything, ok).Return(okResp, nil)
+	httpClient.On("Execute", mock.Anything, failing).Return(nil, errors.New("timeout"))
+
+	// Both executed requests are written in one batch; the invalid one is not recorded.
+	historyRepo.On("SaveBatch", mock.Anything, mock.MatchedBy(func(entries []*repository.HistoryEntry) bool {
+		return len(entries) == 2 &&
+			entries[0].RequestID == ok.ID && entries[0].StatusCode == 200 &&
+			entries[1].RequestID == failing.ID && entries[1].Error == "timeout"
+	})).Return(nil).Once()
+
+	results := service.ExecuteBatch(context.Background(), []*domain.Request{ok, failing, invalid})
+
+	require.Len(t, results, 3)
+	assert.NoError(t, results[0].Err)
+	assert.Equal(t, okResp, results[0].Response)
+	assert.False(t, results[0].ExecutedAt.IsZero())
+	assert.Error(t, results[1].Err)
+	assert.Nil(t, results[1].Response)
+	assert.ErrorContains(t, results[2].Err, "invalid request")
+	assert.True(t, results[2].ExecutedAt.IsZero())
+
+	assert.Equal(t, int64(1), ok.ExecutionCount)
+	assert.Equal(t, int64(0), invalid.ExecutionCount)
+
+	httpClient.AssertExpectations(t)
+	historyRepo.AssertExpectations(t)
+}
+
+func TestExecuteBatch_StopsWhenCancelled(t *testing.T) {
+	httpClient := new(MockHTTPClient)
+	historyRepo := new(MockHistoryRepository)
+
+	service := NewRequestService(new(MockRequestRepository), httpClient, historyRepo, slog.Default())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	req := domain.NewRequestWithMethodAndURL("GET", "https://api.example.com/test")
+	results := service.ExecuteBatch(ctx, []*domain.Request{req})
+
+	require.Len(t, results, 1)
+	assert.ErrorIs(t, results[0].Err, context.Canceled)
+	httpClient.AssertNotCalled(t, "Execute", mock.Anything, mock.Anything)
+	historyRepo.AssertNotCalled(t, "SaveBatch", mock.Anything, mock.Anything)
+}
+
+func TestListRecentlyUsedRequests(t *testing.T) {
+	repo := new(MockRequestRepository)
+	service := NewRequestService(repo, new(MockHTTPClient), new(MockHistoryRepository), slog.Default())
+
+	requests := []*domain.Request{domain.NewRequest(), domain.NewRequest()}
+	repo.On("FindRecentlyUsed", mock.Anything, 10).Return(requests, nil).Once()
+	repo.On("FindRecentlyUsed", mock.Anything, 0).Return(nil, errors.New("db error")).Once()
+
+	got, err := service.ListRecentlyUsedRequests(context.Background(), 10)
+	assert.NoError(t, err)
+	assert.Equal(t, requests, got)
+
+	_, err = service.ListRecentlyUsedRequests(context.Background(), 0)
+	assert.Error(t, err)
+	repo.AssertExpectations(t)
+}
+
+func TestMoveRequest(t *testing.T) {
+	repo := new(MockRequestRepository)
+	service := NewRequestService(repo, new(MockHTTPClient), new(MockHistoryRepository), slog.Default())
+
+	repo.On("Move", mock.Anything, "req-1", "users", 2).Return(nil).Once()
+	repo.On("Move", mock.Anything, "missing", "", 0).Return(repository.ErrNotFound).Once()
+
+	assert.NoError(t, service.MoveRequest(context.Background(), "req-1", "users", 2))
+
+	err := service.MoveRequest(context.Background(), "missing", "", 0)
+	assert.ErrorIs(t, err, repository.ErrNotFound)
+	repo.AssertExpectations(t)
+}
+
+func TestMoveRequestUpDown(t *testing.T) {
+	folder := []*domain.Request{
+		{ID: "a", Folder: "users", Position: 0},
+		{ID: "b", Folder: "users", Position: 1},
+		{ID: "c", Folder: "users", Position: 5}, // gaps are tolerated
+	}
+
+	tests := []struct {
+		name     string
+		id       string
+		up       bool
+		wantMove int // -1 means no move expected
+	}{
+		{"up from middle", "b", true, 0},
+		{"down from middle", "b", false, 2},
+		{"up from first", "a", true, -1},
+		{"down from last", "c", false, -1},
+		{"up from last with gap", "c", true, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := new(MockRequestRepository)
+			service := NewRequestService(repo, new(MockHTTPClient), new(MockHistoryRepository), slog.Default())
+
+			for _, req := range folder {
+				repo.On("FindByID", mock.Anything, req.ID).Return(req, nil).Maybe()
+			}
+			repo.On("FindByFolder", mock.Anything, "users").Return(folder, nil)
+			if tt.wantMove >= 0 {
+				repo.On("Move", mock.Anything, tt.id, "users", tt.wantMove).Return(nil).Once()
+			}
+
+			var err error
+			if tt.up {
+				err = service.MoveRequestUp(context.Background(), tt.id)
+			} else {
+				err = service.MoveRequestDown(context.Background(), tt.id)
+			}
+
+			assert.NoError(t, err)
+			repo.AssertExpectations(t)
+			if tt.wantMove < 0 {
+				repo.AssertNotCalled(t, "Move", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestListFolder(t *testing.T) {
+	repo := new(MockRequestRepository)
+	service := NewRequestService(repo, new(MockHTTPClient), new(MockHistoryRepository), slog.Default())
+
+	requests := []*domain.Request{{ID: "a"}, {ID: "b"}}
+	repo.On("FindByFolder", mock.Anything, "users").Return(requests, nil).Once()
+	repo.On("FindByFolder", mock.Anything, "broken").Return(nil, errors.New("db error")).Once()
+
+	got, err := service.ListFolder(context.Background(), "users")
+	assert.NoError(t, err)
+	assert.Equal(t, requests, got)
+
+	_, err = service.ListFolder(context.Background(), "broken")
+	assert.Error(t, err)
+}
+
+func TestFindRequest(t *testing.T) {
+	repo := new(MockRequestRepository)
+	service := NewRequestService(repo, new(MockHTTPClient), new(MockHistoryRepository), slog.Default())
+
+	users := &domain.Request{ID: "abc123", Name: "List Users"}
+	orders := &domain.Request{ID: "abd456", Name: "Orders"}
+	dupA := &domain.Request{ID: "fff001", Name: "Health"}
+	dupB := &domain.Request{ID: "fff002", Name: "health"}
+	repo.On("FindAll", mock.Anything).Return([]*domain.Request{users, orders, dupA, dupB}, nil)
+
+	tests := []struct {
+		name    string
+		ref     string
+		want    *domain.Request
+		wantErr string
+	}{
+		{name: "full id", ref: "abd456", want: orders},
+		{name: "name ignores case", ref: "list users", want: users},
+		{name: "unique prefix", ref: "abc", want: users},
+		{name: "ambiguous prefix", ref: "ab", wantErr: "ambiguous"},
+		{name: "ambiguous name", ref: "HEALTH", wantErr: "ambiguous"},
+		{name: "missing", ref: "nope", wantErr: "not found"},
+		{name: "empty", ref: "", wantErr: "not found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := service.FindRequest(context.Background(), tt.ref)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Same(t, tt.want, got)
+		})
+	}
+
+	_, err := service.FindRequest(context.Background(), "nope")
+	assert.ErrorIs(t, err, repository.ErrNotFound)
 }
 
 func TestSaveRequest_CreateError(t *testing.T) {
@@ -0,0 +1,143 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/williajm/curly/internal/domain"
+	"github.com/williajm/curly/internal/infrastructure/http"
+	"github.com/williajm/curly/internal/infrastructure/sse"
+)
+
+// StreamEvent is a server-sent event with the time it arrived.
+type StreamEvent struct {
+	sse.Event
+	Received time.Time
+}
+
+// EventStream is a response of server-sent events being received; see
+// RequestService.OpenStream. It is not safe for concurrent use.
+type EventStream struct {
+	// Response has the status and headers of the response. Its body, the
+	// stream as received, and its duration are filled in by Close.
+	Response *domain.Response
+
+	service *RequestService
+	ctx     context.Context
+	result  ExecutionResult
+	body    io.ReadCloser
+	events  *sse.Reader
+	raw     strings.Builder
+	count   int
+}
+
+// OpenStream sends req, which should accept an event stream, and returns the
+// stream of events its response carries as they arrive, without waiting for
+// the response to end. Responses that are not event streams are read whole,
+// recorded to history and returned with a nil stream, as ExecuteAndSave
+// would. The stream lasts until the server closes it or ctx is canceled.
+func (s *RequestService) OpenStream(ctx context.Context, req *domain.Request) (*EventStream, *domain.Response, error) {
+	streamer, ok := s.httpClient.(http.Streamer)
+	if !ok {
+		resp, err := s.ExecuteAndSave(ctx, req)
+		return nil, resp, err
+	}
+
+	if err := req.Validate(); err != nil {
+		return nil, nil, fmt.Errorf("invalid request: %w", err)
+	}
+	sent, err := s.prepare(ctx, req)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid request: %w", err)
+	}
+	if sent, err = s.runPreRequestScript(ctx, sent); err != nil {
+		return nil, nil, err
+	}
+
+	s.logger.Info("opening event stream",
+		"request_id", req.ID,
+		"method", req.Method,
+		"url", req.URL,
+	)
+
+	result := ExecutionResult{Request: req, Sent: sent, ExecutedAt: time.Now().UTC()}
+	resp, body, err := streamer.Stream(ctx, sent)
+	if errors.Is(ctx.Err(), context.Canceled) {
+		// A request canceled by the caller is not recorded.
+		if body != nil {
+			_ = body.Close()
+		}
+		return nil, nil, fmt.Errorf("failed to execute request: %w", context.Canceled)
+	}
+	if err != nil {
+		s.logger.Error("request execution failed", "request_id", req.ID, "error", err)
+		result.Err = err
+		s.saveExecutions(ctx, []ExecutionResult{result})
+		return nil, nil, fmt.Errorf("%w: %w", ErrExecutionFailed, err)
+	}
+	resp.Sent = sent
+
+	if !sse.IsEventStream(resp.ContentType()) {
+		data, err := io.ReadAll(body)
+		_ = body.Close()
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read response body: %w", err)
+		}
+		resp.Body = string(data)
+		resp.ContentLength = int64(len(data))
+		resp.Duration = time.Since(resp.Timestamp)
+		s.checkResponse(ctx, sent, resp)
+		result.Response = resp
+		s.saveExecutions(ctx, []ExecutionResult{result})
+		return nil, resp, nil
+	}
+
+	stream := &EventStream{
+		Response: resp,
+		service:  s,
+		ctx:      ctx,
+		result:   result,
+		body:     body,
+	}
+	stream.events = sse.NewReader(io.TeeReader(body, &stream.raw))
+	return stream, nil, nil
+}
+
+// Next returns the next event, blocking until it arrives. It returns io.EOF
+// once the server has closed the stream, and ctx's error once the context
+// OpenStream was given is canceled.
+func (st *EventStream) Next() (StreamEvent, error) {
+	event, err := st.events.Next()
+	if err != nil {
+		if ctxErr := st.ctx.Err(); ctxErr != nil {
+			return StreamEvent{}, ctxErr
+		}
+		return StreamEvent{}, err
+	}
+	st.count++
+	return StreamEvent{Event: event, Received: time.Now()}, nil
+}
+
+// Close ends the stream and records it to history, with the stream as
+// received as its body, and returns the completed response.
+func (st *EventStream) Close(ctx context.Context) *domain.Response {
+	_ = st.body.Close()
+
+	resp := st.Response
+	resp.Body = st.raw.String()
+	resp.ContentLength = int64(len(resp.Body))
+	resp.Duration = time.Since(resp.Timestamp)
+
+	st.service.logger.Info("event stream closed",
+		"request_id", st.result.Request.ID,
+		"events", st.count,
+		"duration_ms", resp.DurationMillis(),
+	)
+	st.result.Response = resp
+	st.service.saveExecutions(ctx, []ExecutionResult{st.result})
+	return resp
+}
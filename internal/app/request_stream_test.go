@@ -0,0 +1,136 @@
+package app
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/williajm/curly/internal/domain"
+	"github.com/williajm/curly/internal/infrastructure/repository"
+)
+
+// streamingHTTPClient is a MockHTTPClient that streams the response it was
+// given, with body as its unread body.
+type streamingHTTPClient struct {
+	MockHTTPClient
+	resp *domain.Response
+	body io.Reader
+}
+
+func (c *streamingHTTPClient) Stream(_ context.Context, _ *domain.Request) (*domain.Response, io.ReadCloser, error) {
+	return c.resp, io.NopCloser(c.body), nil
+}
+
+func TestOpenStream(t *testing.T) {
+	historyRepo := new(MockHistoryRepository)
+	client := &streamingHTTPClient{
+		resp: &domain.Response{
+			StatusCode: 200,
+			Status:     "200 OK",
+			Headers:    map[string]string{"Content-Type": "text/event-stream"},
+			Timestamp:  time.Now(),
+		},
+		body: strings.NewReader("data: one\n\nevent: update\ndata: two\n\n"),
+	}
+	service := NewRequestService(new(MockRequestRepository), client, historyRepo, slog.Default())
+
+	var saved []*repository.HistoryEntry
+	historyRepo.On("SaveBatch", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		saved = args.Get(1).([]*repository.HistoryEntry)
+	}).Return(nil).Once()
+
+	req := domain.NewRequestWithMethodAndURL(domain.MethodGet, "https://api.example.com/events")
+	req.Headers["Accept"] = "text/event-stream"
+	stream, resp, err := service.OpenStream(context.Background(), req)
+	require.NoError(t, err)
+	require.NotNil(t, stream)
+	assert.Nil(t, resp)
+	assert.Equal(t, 200, stream.Response.StatusCode)
+
+	first, err := stream.Next()
+	require.NoError(t, err)
+	assert.Equal(t, "message", first.Type)
+	assert.Equal(t, "one", first.Data)
+	assert.False(t, first.Received.IsZero())
+
+	second, err := stream.Next()
+	require.NoError(t, err)
+	assert.Equal(t, "update", second.Type)
+
+	_, err = stream.Next()
+	assert.ErrorIs(t, err, io.EOF)
+
+	// The stream is recorded once closed, with what was received as its body.
+	historyRepo.AssertNotCalled(t, "SaveBatch", mock.Anything, mock.Anything)
+	final := stream.Close(context.Background())
+	assert.Equal(t, "data: one\n\nevent: update\ndata: two\n\n", final.Body)
+	assert.Equal(t, int64(len(final.Body)), final.ContentLength)
+	require.Len(t, saved, 1)
+	assert.Equal(t, req.ID, saved[0].RequestID)
+	assert.Equal(t, int64(1), req.ExecutionCount)
+}
+
+func TestOpenStream_NotAnEventStream(t *testing.T) {
+	historyRepo := new(MockHistoryRepository)
+	client := &streamingHTTPClient{
+		resp: &domain.Response{
+			StatusCode: 401,
+			Status:     "401 Unauthorized",
+			Headers:    map[string]string{"Content-Type": "application/json"},
+			Timestamp:  time.Now(),
+		},
+		body: strings.NewReader(`{"error": "unauthorized"}`),
+	}
+	service := NewRequestService(new(MockRequestRepository), client, historyRepo, slog.Default())
+	historyRepo.On("SaveBatch", mock.Anything, mock.Anything).Return(nil).Once()
+
+	req := domain.NewRequestWithMethodAndURL(domain.MethodGet, "https://api.example.com/events")
+	stream, resp, err := service.OpenStream(context.Background(), req)
+	require.NoError(t, err)
+	assert.Nil(t, stream)
+	assert.Equal(t, `{"error": "unauthorized"}`, resp.Body)
+	assert.Same(t, client.resp, resp)
+	historyRepo.AssertExpectations(t)
+}
+
+func TestOpenStream_WithoutStreamingClient(t *testing.T) {
+	httpClient := new(MockHTTPClient)
+	historyRepo := new(MockHistoryRepository)
+	service := NewRequestService(new(MockRequestRepository), httpClient, historyRepo, slog.Default())
+
+	whole := &domain.Response{StatusCode: 200, Status: "200 OK", Body: "data: one\n\n"}
+	httpClient.On("Execute", mock.Anything, mock.Anything).Return(whole, nil).Once()
+	historyRepo.On("SaveBatch", mock.Anything, mock.Anything).Return(nil).Once()
+
+	stream, resp, err := service.OpenStream(context.Background(), domain.NewRequestWithMethodAndURL(domain.MethodGet, "https://api.example.com/events"))
+	require.NoError(t, err)
+	assert.Nil(t, stream)
+	assert.Same(t, whole, resp)
+}
+
+func TestEventStream_Canceled(t *testing.T) {
+	historyRepo := new(MockHistoryRepository)
+	reader, writer := io.Pipe()
+	client := &streamingHTTPClient{
+		resp: &domain.Response{StatusCode: 200, Status: "200 OK", Headers: map[string]string{"Content-Type": "text/event-stream"}},
+		body: reader,
+	}
+	service := NewRequestService(new(MockRequestRepository), client, historyRepo, slog.Default())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stream, _, err := service.OpenStream(ctx, domain.NewRequestWithMethodAndURL(domain.MethodGet, "https://api.example.com/events"))
+	require.NoError(t, err)
+
+	// Canceling the request ends the stream, as the HTTP client's body
+	// reader then fails.
+	cancel()
+	_ = writer.CloseWithError(io.ErrUnexpectedEOF)
+	_, err = stream.Next()
+	assert.ErrorIs(t, err, context.Canceled)
+}
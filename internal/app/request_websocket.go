@@ -0,0 +1,208 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/williajm/curly/internal/domain"
+	"github.com/williajm/curly/internal/infrastructure/http"
+	"github.com/williajm/curly/internal/infrastructure/websocket"
+)
+
+// ErrWebSocketUnsupported is returned by OpenWebSocket when the HTTP client
+// cannot hand over an upgraded connection.
+var ErrWebSocketUnsupported = errors.New("the HTTP client does not support WebSocket connections")
+
+// WebSocketMessage is a message of a WebSocket session, in either direction.
+type WebSocketMessage struct {
+	// Sent is true for messages sent to the server, false for those received.
+	Sent bool
+
+	// Binary is true for binary messages, whose Data is not text.
+	Binary bool
+
+	Data string
+	At   time.Time
+}
+
+// WebSocketSession is an open WebSocket connection; see
+// RequestService.OpenWebSocket. Messages may be sent while another goroutine
+// waits in Receive.
+type WebSocketSession struct {
+	// Response has the status and headers of the handshake. Its body, the
+	// session's transcript, and its duration are filled in by Close.
+	Response *domain.Response
+
+	service *RequestService
+	result  ExecutionResult
+	conn    *websocket.Conn
+
+	mu       sync.Mutex
+	messages []WebSocketMessage
+
+	closeOnce sync.Once
+}
+
+// OpenWebSocket opens a WebSocket connection to req's ws or wss URL, sending
+// its headers and authentication with the handshake. The session lasts until
+// either side closes it, and is recorded to history by Close, with its
+// transcript as the response body.
+func (s *RequestService) OpenWebSocket(ctx context.Context, req *domain.Request) (*WebSocketSession, error) {
+	streamer, ok := s.httpClient.(http.Streamer)
+	if !ok {
+		return nil, ErrWebSocketUnsupported
+	}
+
+	// The handshake is an HTTP request to the same address.
+	handshake := req.Clone()
+	handshake.Method = domain.MethodGet
+	handshake.URL = websocket.HTTPURL(req.URL)
+	handshake.Body = ""
+	if err := handshake.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid request: %w", err)
+	}
+	sent, err := s.prepare(ctx, handshake)
+	if err != nil {
+		return nil, fmt.Errorf("invalid request: %w", err)
+	}
+
+	// History shows the session under its WebSocket URL.
+	snapshot := sent.Clone()
+	snapshot.URL = websocket.WSURL(sent.URL)
+
+	key, err := websocket.NewKey()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open connection: %w", err)
+	}
+	upgrade := sent.Clone()
+	upgrade.Headers["Connection"] = "Upgrade"
+	upgrade.Headers["Upgrade"] = "websocket"
+	upgrade.Headers["Sec-WebSocket-Version"] = "13"
+	upgrade.Headers["Sec-WebSocket-Key"] = key
+
+	s.logger.Info("opening websocket",
+		"request_id", req.ID,
+		"url", snapshot.URL,
+	)
+
+	resp, body, err := streamer.Stream(ctx, upgrade)
+	if err != nil {
+		s.logger.Error("websocket handshake failed", "request_id", req.ID, "error", err)
+		return nil, fmt.Errorf("failed to open connection: %w", err)
+	}
+	rwc, ok := body.(io.ReadWriteCloser)
+	if resp.StatusCode != 101 || !ok {
+		_ = body.Close()
+		return nil, fmt.Errorf("failed to open connection: the server answered %s", resp.Status)
+	}
+	if accept := resp.GetHeader("Sec-WebSocket-Accept"); accept != websocket.AcceptKey(key) {
+		_ = body.Close()
+		return nil, errors.New("failed to open connection: the server's Sec-WebSocket-Accept is wrong")
+	}
+	resp.Sent = snapshot
+
+	return &WebSocketSession{
+		Response: resp,
+		service:  s,
+		result:   ExecutionResult{Request: req, Sent: snapshot, ExecutedAt: resp.Timestamp.UTC()},
+		conn:     websocket.NewConn(rwc),
+	}, nil
+}
+
+// Send sends text to the server as a text message and returns it as logged.
+func (ws *WebSocketSession) Send(text string) (WebSocketMessage, error) {
+	if err := ws.conn.WriteMessage(websocket.TextMessage, []byte(text)); err != nil {
+		return WebSocketMessage{}, err
+	}
+	return ws.log(WebSocketMessage{Sent: true, Data: text, At: time.Now()}), nil
+}
+
+// Receive returns the next message from the server, blocking until it
+// arrives. It returns io.EOF once the session was closed, by either side,
+// normally, and another error if the connection failed.
+func (ws *WebSocketSession) Receive() (WebSocketMessage, error) {
+	messageType, data, err := ws.conn.ReadMessage()
+	if err != nil {
+		var closeErr *websocket.CloseError
+		switch {
+		case errors.Is(err, websocket.ErrClosed):
+			return WebSocketMessage{}, io.EOF
+		case errors.As(err, &closeErr) && (closeErr.Code == websocket.CloseNormal ||
+			closeErr.Code == websocket.CloseGoingAway || closeErr.Code == websocket.CloseNoStatus):
+			return WebSocketMessage{}, io.EOF
+		}
+		return WebSocketMessage{}, err
+	}
+	return ws.log(WebSocketMessage{
+		Binary: messageType == websocket.BinaryMessage,
+		Data:   string(data),
+		At:     time.Now(),
+	}), nil
+}
+
+// log adds message to the transcript and returns it.
+func (ws *WebSocketSession) log(message WebSocketMessage) WebSocketMessage {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+	ws.messages = append(ws.messages, message)
+	return message
+}
+
+// Transcript returns the messages so far as text, one per line: its
+// direction, "→" for sent and "←" for received, the time and the message.
+func (ws *WebSocketSession) Transcript() string {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+
+	var b strings.Builder
+	for _, message := range ws.messages {
+		b.WriteString(FormatWebSocketMessage(message))
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// FormatWebSocketMessage returns message as a line of a transcript. Binary
+// messages are shown by their size.
+func FormatWebSocketMessage(message WebSocketMessage) string {
+	direction := "←"
+	if message.Sent {
+		direction = "→"
+	}
+	data := message.Data
+	if message.Binary {
+		data = fmt.Sprintf("[binary, %d bytes]", len(message.Data))
+	}
+	return fmt.Sprintf("%s %s %s", direction, message.At.Format("15:04:05.000"), data)
+}
+
+// Close closes the connection, if the server has not already, and records
+// the session to history, with its transcript as the body, then returns the
+// completed response. Closing a session more than once records it once.
+func (ws *WebSocketSession) Close(ctx context.Context) *domain.Response {
+	ws.closeOnce.Do(func() {
+		_ = ws.conn.Close()
+
+		resp := ws.Response
+		resp.Body = ws.Transcript()
+		resp.ContentLength = int64(len(resp.Body))
+		resp.Duration = time.Since(resp.Timestamp)
+
+		ws.mu.Lock()
+		count := len(ws.messages)
+		ws.mu.Unlock()
+		ws.service.logger.Info("websocket closed",
+			"request_id", ws.result.Request.ID,
+			"messages", count,
+			"duration_ms", resp.DurationMillis(),
+		)
+		ws.result.Response = resp
+		ws.service.saveExecutions(ctx, []ExecutionResult{ws.result})
+	})
+	return ws.Response
+}
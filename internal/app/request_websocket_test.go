@@ -0,0 +1,121 @@
+package app
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/williajm/curly/internal/domain"
+	"github.com/williajm/curly/internal/infrastructure/repository"
+	"github.com/williajm/curly/internal/infrastructure/websocket"
+)
+
+// upgradingHTTPClient is a MockHTTPClient that answers handshakes by
+// switching protocols, handing over one end of a pipe whose other end is
+// server.
+type upgradingHTTPClient struct {
+	MockHTTPClient
+	server net.Conn
+	sent   *domain.Request
+}
+
+func (c *upgradingHTTPClient) Stream(_ context.Context, req *domain.Request) (*domain.Response, io.ReadCloser, error) {
+	c.sent = req
+	client, server := net.Pipe()
+	c.server = server
+	return &domain.Response{
+		StatusCode: 101,
+		Status:     "101 Switching Protocols",
+		Headers:    map[string]string{"Sec-Websocket-Accept": websocket.AcceptKey(req.Headers["Sec-WebSocket-Key"])},
+		Timestamp:  time.Now(),
+	}, client, nil
+}
+
+func TestOpenWebSocket(t *testing.T) {
+	historyRepo := new(MockHistoryRepository)
+	client := &upgradingHTTPClient{}
+	service := NewRequestService(new(MockRequestRepository), client, historyRepo, slog.Default())
+
+	var saved []*repository.HistoryEntry
+	historyRepo.On("SaveBatch", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		saved = args.Get(1).([]*repository.HistoryEntry)
+	}).Return(nil).Once()
+
+	req := domain.NewRequestWithMethodAndURL(domain.MethodGet, "wss://api.example.com/socket")
+	req.AuthConfig = domain.NewBearerAuth("secret")
+	session, err := service.OpenWebSocket(context.Background(), req)
+	require.NoError(t, err)
+	require.NotNil(t, session)
+	defer client.server.Close()
+
+	// The handshake is sent over HTTPS, with the request's authentication.
+	assert.Equal(t, "https://api.example.com/socket", client.sent.URL)
+	assert.Equal(t, "websocket", client.sent.Headers["Upgrade"])
+	assert.Equal(t, domain.AuthTypeBearer, client.sent.AuthConfig.Type())
+
+	// A server that echoes one message.
+	server := websocket.NewConn(client.server)
+	go func() {
+		_, data, err := server.ReadMessage()
+		if err == nil {
+			_ = server.WriteMessage(websocket.TextMessage, []byte("echo: "+string(data)))
+		}
+		_, _, _ = server.ReadMessage()
+	}()
+
+	sent, err := session.Send(`{"type": "ping"}`)
+	require.NoError(t, err)
+	assert.True(t, sent.Sent)
+
+	received, err := session.Receive()
+	require.NoError(t, err)
+	assert.False(t, received.Sent)
+	assert.Equal(t, `echo: {"type": "ping"}`, received.Data)
+
+	// The session is recorded once, however often it is closed, with the
+	// transcript as its body.
+	final := session.Close(context.Background())
+	session.Close(context.Background())
+	_, err = session.Receive()
+	assert.ErrorIs(t, err, io.EOF)
+
+	lines := strings.Split(strings.TrimSuffix(final.Body, "\n"), "\n")
+	require.Len(t, lines, 2)
+	assert.True(t, strings.HasPrefix(lines[0], "→ "))
+	assert.True(t, strings.HasSuffix(lines[0], ` {"type": "ping"}`))
+	assert.True(t, strings.HasPrefix(lines[1], "← "))
+	require.Len(t, saved, 1)
+	assert.Contains(t, saved[0].RequestSnapshot, "wss://api.example.com/socket")
+	historyRepo.AssertExpectations(t)
+}
+
+func TestOpenWebSocket_Refused(t *testing.T) {
+	client := &streamingHTTPClient{
+		resp: &domain.Response{StatusCode: 404, Status: "404 Not Found"},
+		body: strings.NewReader("not found"),
+	}
+	service := NewRequestService(new(MockRequestRepository), client, new(MockHistoryRepository), slog.Default())
+
+	_, err := service.OpenWebSocket(context.Background(), domain.NewRequestWithMethodAndURL(domain.MethodGet, "ws://localhost/socket"))
+	assert.ErrorContains(t, err, "404 Not Found")
+}
+
+func TestOpenWebSocket_WithoutStreamingClient(t *testing.T) {
+	service := NewRequestService(new(MockRequestRepository), new(MockHTTPClient), new(MockHistoryRepository), slog.Default())
+
+	_, err := service.OpenWebSocket(context.Background(), domain.NewRequestWithMethodAndURL(domain.MethodGet, "ws://localhost/socket"))
+	assert.ErrorIs(t, err, ErrWebSocketUnsupported)
+}
+
+func TestFormatWebSocketMessage(t *testing.T) {
+	at := time.Date(2025, 1, 2, 15, 4, 5, 0, time.UTC)
+	assert.Equal(t, "→ 15:04:05.000 hello", FormatWebSocketMessage(WebSocketMessage{Sent: true, Data: "hello", At: at}))
+	assert.Equal(t, "← 15:04:05.000 [binary, 3 bytes]", FormatWebSocketMessage(WebSocketMessage{Binary: true, Data: "abc", At: at}))
+}
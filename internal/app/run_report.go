@@ -0,0 +1,236 @@
+package app
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// topLevelSuite names the test suite of a run of the top-level requests in
+// JUnit reports.
+const topLevelSuite = "(top level)"
+
+// runSummary is the JSON form of a RunReport.
+type runSummary struct {
+	RunID       string              `json:"run_id"`
+	Folder      string              `json:"folder"`
+	StartedAt   time.Time           `json:"started_at"`
+	DurationMs  int64               `json:"duration_ms"`
+	Total       int                 `json:"total"`
+	Passed      int                 `json:"passed"`
+	Failed      int                 `json:"failed"`
+	Results     []resultSummary     `json:"results"`
+	Regressions []regressionSummary `json:"regressions"`
+}
+
+type resultSummary struct {
+	RequestID         string   `json:"request_id"`
+	Name              string   `json:"name"`
+	Method            string   `json:"method"`
+	URL               string   `json:"url"`
+	Passed            bool     `json:"passed"`
+	StatusCode        int      `json:"status_code,omitempty"`
+	Status            string   `json:"status,omitempty"`
+	DurationMs        int64    `json:"duration_ms"`
+	Error             string   `json:"error,omitempty"`
+	AssertionFailures []string `json:"assertion_failures"`
+}
+
+type regressionSummary struct {
+	RequestID     string `json:"request_id"`
+	Name          string `json:"name"`
+	RecentP95Ms   int64  `json:"recent_p95_ms"`
+	BaselineP95Ms int64  `json:"baseline_p95_ms"`
+	Message       string `json:"message"`
+}
+
+// WriteJSON writes the report as an indented JSON summary: the totals, one
+// entry per request in execution order, and the latency regressions.
+func (r *RunReport) WriteJSON(w io.Writer) error {
+	summary := runSummary{
+		RunID:       r.RunID,
+		Folder:      r.Folder,
+		StartedAt:   r.StartedAt,
+		DurationMs:  r.Duration.Milliseconds(),
+		Total:       len(r.Results),
+		Passed:      r.Passed(),
+		Failed:      r.Failed(),
+		Results:     []resultSummary{},
+		Regressions: []regressionSummary{},
+	}
+	for _, result := range r.Results {
+		entry := resultSummary{
+			RequestID:         result.Request.ID,
+			Name:              result.Request.Name,
+			Method:            result.Request.Method,
+			URL:               result.Request.URL,
+			Passed:            result.Passed(),
+			AssertionFailures: []string{},
+		}
+		if result.Err != nil {
+			entry.Error = result.Err.Error()
+		}
+		if resp := result.Response; resp != nil {
+			entry.StatusCode = resp.StatusCode
+			entry.Status = resp.Status
+			entry.DurationMs = resp.DurationMillis()
+			entry.AssertionFailures = append(entry.AssertionFailures, resp.AssertionFailures...)
+		}
+		summary.Results = append(summary.Results, entry)
+	}
+	names := r.requestNames()
+	for _, regression := range r.Regressions {
+		summary.Regressions = append(summary.Regressions, regressionSummary{
+			RequestID:     regression.RequestID,
+			Name:          names[regression.RequestID],
+			RecentP95Ms:   regression.RecentP95Ms,
+			BaselineP95Ms: regression.BaselineP95Ms,
+			Message:       regression.String(),
+		})
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(summary)
+}
+
+// junitTestSuites is the root element of a JUnit XML report.
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Errors   int              `xml:"errors,attr"`
+	Time     string           `xml:"time,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	ID        string          `xml:"id,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Errors    int             `xml:"errors,attr"`
+	Time      string          `xml:"time,attr"`
+	Timestamp string          `xml:"timestamp,attr"`
+	Cases     []junitTestCase `xml:"testcase"`
+	SystemOut string          `xml:"system-out,omitempty"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitProblem `xml:"failure"`
+	Error     *junitProblem `xml:"error"`
+}
+
+// junitProblem is a failure (the request returned a failing response) or an
+// error (it got no response).
+type junitProblem struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// WriteJUnit writes the report as JUnit XML, as read by CI servers: the run
+// is one test suite named after the folder, with a test case per request.
+// Requests that got no response are errors and those that got a failing
+// status or failed assertions are failures. Latency regressions are listed in
+// the suite's output, since they do not fail the run.
+func (r *RunReport) WriteJUnit(w io.Writer) error {
+	suiteName := r.Folder
+	if suiteName == "" {
+		suiteName = topLevelSuite
+	}
+	suite := junitTestSuite{
+		Name:      suiteName,
+		ID:        r.RunID,
+		Tests:     len(r.Results),
+		Time:      junitSeconds(r.Duration),
+		Timestamp: r.StartedAt.UTC().Format(time.RFC3339),
+	}
+
+	for _, result := range r.Results {
+		testCase := junitTestCase{
+			Name:      result.Request.Name,
+			ClassName: suiteName,
+		}
+		resp := result.Response
+		var elapsed time.Duration
+		if resp != nil {
+			elapsed = resp.Duration
+		}
+		testCase.Time = junitSeconds(elapsed)
+		switch {
+		case result.Passed():
+		case resp == nil:
+			message := "no response"
+			if result.Err != nil {
+				message = result.Err.Error()
+			}
+			testCase.Error = &junitProblem{Message: message, Type: "error", Text: message}
+			suite.Errors++
+		case len(resp.AssertionFailures) > 0:
+			testCase.Failure = &junitProblem{
+				Message: fmt.Sprintf("%d assertion failures", len(resp.AssertionFailures)),
+				Type:    "assertion",
+				Text:    strings.Join(resp.AssertionFailures, "\n"),
+			}
+			suite.Failures++
+		default:
+			message := "status " + resp.Status
+			if result.Err != nil {
+				message = result.Err.Error()
+			}
+			testCase.Failure = &junitProblem{Message: message, Type: "status", Text: message}
+			suite.Failures++
+		}
+		suite.Cases = append(suite.Cases, testCase)
+	}
+
+	if len(r.Regressions) > 0 {
+		names := r.requestNames()
+		lines := []string{"Latency regressions:"}
+		for _, regression := range r.Regressions {
+			lines = append(lines, fmt.Sprintf("  %s: %s", names[regression.RequestID], regression))
+		}
+		suite.SystemOut = strings.Join(lines, "\n")
+	}
+
+	report := junitTestSuites{
+		Name:     "curly",
+		Tests:    suite.Tests,
+		Failures: suite.Failures,
+		Errors:   suite.Errors,
+		Time:     suite.Time,
+		Suites:   []junitTestSuite{suite},
+	}
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// requestNames maps the IDs of the run's requests to their names.
+func (r *RunReport) requestNames() map[string]string {
+	names := make(map[string]string, len(r.Results))
+	for _, result := range r.Results {
+		names[result.Request.ID] = result.Request.Name
+	}
+	return names
+}
+
+// junitSeconds formats a duration in seconds, as JUnit's time attributes are.
+func junitSeconds(d time.Duration) string {
+	return fmt.Sprintf("%.3f", d.Seconds())
+}
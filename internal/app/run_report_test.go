@@ -0,0 +1,136 @@
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/williajm/curly/internal/domain"
+)
+
+// sampleRunReport returns a report of a run in which one request passed, one
+// failed its assertions, one returned 500 and one got no response.
+func sampleRunReport() *RunReport {
+	request := func(name string) *domain.Request {
+		req := domain.NewRequestWithMethodAndURL("GET", "https://api.example.com/"+name)
+		req.Name = name
+		return req
+	}
+	ok := request("ok")
+	invalid := request("invalid")
+	broken := request("broken")
+	down := request("down")
+
+	return &RunReport{
+		RunID:     "run-1",
+		Folder:    "smoke",
+		StartedAt: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+		Duration:  1500 * time.Millisecond,
+		Results: []ExecutionResult{
+			{Request: ok, Response: &domain.Response{StatusCode: 200, Status: "200 OK", Duration: 120 * time.Millisecond}},
+			{Request: invalid, Response: &domain.Response{StatusCode: 200, Status: "200 OK", AssertionFailures: []string{"id: required", "name: expected string"}}},
+			{Request: broken, Response: &domain.Response{StatusCode: 500, Status: "500 Internal Server Error"}},
+			{Request: down, Err: errors.New("connection refused")},
+		},
+		Regressions: []*LatencyRegression{
+			{RequestID: ok.ID, RecentP95Ms: 800, BaselineP95Ms: 300, RecentSamples: 10, BaselineSamples: 40},
+		},
+	}
+}
+
+func TestRunReport_WriteJSON(t *testing.T) {
+	report := sampleRunReport()
+
+	var out bytes.Buffer
+	require.NoError(t, report.WriteJSON(&out))
+
+	var summary runSummary
+	require.NoError(t, json.Unmarshal(out.Bytes(), &summary))
+	assert.Equal(t, "run-1", summary.RunID)
+	assert.Equal(t, "smoke", summary.Folder)
+	assert.Equal(t, int64(1500), summary.DurationMs)
+	assert.Equal(t, 4, summary.Total)
+	assert.Equal(t, 1, summary.Passed)
+	assert.Equal(t, 3, summary.Failed)
+
+	require.Len(t, summary.Results, 4)
+	assert.Equal(t, "ok", summary.Results[0].Name)
+	assert.True(t, summary.Results[0].Passed)
+	assert.Equal(t, 200, summary.Results[0].StatusCode)
+	assert.Equal(t, int64(120), summary.Results[0].DurationMs)
+	assert.Equal(t, []string{"id: required", "name: expected string"}, summary.Results[1].AssertionFailures)
+	assert.False(t, summary.Results[2].Passed)
+	assert.Equal(t, "connection refused", summary.Results[3].Error)
+	assert.Empty(t, summary.Results[3].AssertionFailures)
+
+	require.Len(t, summary.Regressions, 1)
+	assert.Equal(t, "ok", summary.Regressions[0].Name)
+	assert.Equal(t, int64(800), summary.Regressions[0].RecentP95Ms)
+}
+
+func TestRunReport_WriteJSON_EmptyListsAreArrays(t *testing.T) {
+	report := &RunReport{RunID: "run-1"}
+
+	var out bytes.Buffer
+	require.NoError(t, report.WriteJSON(&out))
+
+	assert.Contains(t, out.String(), `"results": []`)
+	assert.Contains(t, out.String(), `"regressions": []`)
+}
+
+func TestRunReport_WriteJUnit(t *testing.T) {
+	report := sampleRunReport()
+
+	var out bytes.Buffer
+	require.NoError(t, report.WriteJUnit(&out))
+	assert.True(t, bytes.HasPrefix(out.Bytes(), []byte(xml.Header)))
+
+	var suites junitTestSuites
+	require.NoError(t, xml.Unmarshal(out.Bytes(), &suites))
+	assert.Equal(t, 4, suites.Tests)
+	assert.Equal(t, 2, suites.Failures)
+	assert.Equal(t, 1, suites.Errors)
+	require.Len(t, suites.Suites, 1)
+
+	suite := suites.Suites[0]
+	assert.Equal(t, "smoke", suite.Name)
+	assert.Equal(t, "run-1", suite.ID)
+	assert.Equal(t, "1.500", suite.Time)
+	assert.Equal(t, "2026-01-02T03:04:05Z", suite.Timestamp)
+	assert.Contains(t, suite.SystemOut, "ok: p95 800ms vs 300ms baseline")
+
+	require.Len(t, suite.Cases, 4)
+	assert.Equal(t, "ok", suite.Cases[0].Name)
+	assert.Equal(t, "smoke", suite.Cases[0].ClassName)
+	assert.Equal(t, "0.120", suite.Cases[0].Time)
+	assert.Nil(t, suite.Cases[0].Failure)
+	assert.Nil(t, suite.Cases[0].Error)
+
+	require.NotNil(t, suite.Cases[1].Failure)
+	assert.Equal(t, "assertion", suite.Cases[1].Failure.Type)
+	assert.Equal(t, "id: required\nname: expected string", suite.Cases[1].Failure.Text)
+
+	require.NotNil(t, suite.Cases[2].Failure)
+	assert.Equal(t, "status 500 Internal Server Error", suite.Cases[2].Failure.Message)
+
+	require.NotNil(t, suite.Cases[3].Error)
+	assert.Equal(t, "connection refused", suite.Cases[3].Error.Message)
+}
+
+func TestRunReport_WriteJUnit_TopLevel(t *testing.T) {
+	report := &RunReport{RunID: "run-1"}
+
+	var out bytes.Buffer
+	require.NoError(t, report.WriteJUnit(&out))
+
+	var suites junitTestSuites
+	require.NoError(t, xml.Unmarshal(out.Bytes(), &suites))
+	require.Len(t, suites.Suites, 1)
+	assert.Equal(t, topLevelSuite, suites.Suites[0].Name)
+	assert.Zero(t, suites.Tests)
+}
@@ -0,0 +1,186 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/williajm/curly/internal/domain"
+	"github.com/williajm/curly/internal/infrastructure/repository"
+)
+
+// RunnerService executes the saved requests of a folder, in their curated
+// order, as a single collection run.
+type RunnerService struct {
+	requests *RequestService
+	latency  *LatencyService
+	logger   *slog.Logger
+}
+
+// RunReport summarizes a collection run.
+type RunReport struct {
+	// RunID tags the history entries recorded by the run.
+	RunID string
+
+	// Folder is the folder that was run (empty for the top level).
+	Folder string
+
+	// StartedAt is when the run began.
+	StartedAt time.Time
+
+	// Duration is how long the whole run took.
+	Duration time.Duration
+
+	// Results holds one result per request, in execution order.
+	Results []ExecutionResult
+
+	// Regressions lists the requests whose latency regressed, counting this
+	// run, in execution order. It is empty unless a latency service is set.
+	Regressions []*LatencyRegression
+}
+
+// Passed returns the number of requests that passed.
+func (r *RunReport) Passed() int {
+	passed := 0
+	for _, result := range r.Results {
+		if result.Passed() {
+			passed++
+		}
+	}
+	return passed
+}
+
+// Failed returns the number of requests that failed.
+func (r *RunReport) Failed() int {
+	return len(r.Results) - r.Passed()
+}
+
+// Passed reports whether the execution returned a 2xx or 3xx status without
+// error and passed its assertions.
+func (r ExecutionResult) Passed() bool {
+	return r.Err == nil && r.Response != nil && (r.Response.IsSuccess() || r.Response.IsRedirect()) &&
+		len(r.Response.AssertionFailures) == 0
+}
+
+// RunProgress reports the progress of a collection run: a request is about
+// to be sent, or has finished.
+type RunProgress struct {
+	// Requests are the requests of the run, in execution order.
+	Requests []*domain.Request
+
+	// Index is the position in Requests of the request reported on.
+	Index int
+
+	// Result is nil while the request is being sent, and its result once it
+	// has finished. Requests that are not sent, because they are invalid or
+	// the run was cancelled, are only reported once finished.
+	Result *ExecutionResult
+}
+
+// RunProgressFunc is called with the progress of a collection run. It is
+// called from the goroutine executing the run, which waits for it to return.
+type RunProgressFunc func(RunProgress)
+
+// runProgressKey is the context key of a RunProgressFunc.
+type runProgressKey struct{}
+
+// WithRunProgress returns a context that makes a run report its progress
+// to fn.
+func WithRunProgress(ctx context.Context, fn RunProgressFunc) context.Context {
+	return context.WithValue(ctx, runProgressKey{}, fn)
+}
+
+// runProgressFrom returns the RunProgressFunc of ctx, or one that does
+// nothing if it has none.
+func runProgressFrom(ctx context.Context) RunProgressFunc {
+	if fn, ok := ctx.Value(runProgressKey{}).(RunProgressFunc); ok {
+		return fn
+	}
+	return func(RunProgress) {}
+}
+
+// NewRunnerService creates a new RunnerService.
+// The request service is required and must not be nil.
+func NewRunnerService(requests *RequestService, logger *slog.Logger) *RunnerService {
+	if requests == nil {
+		panic("request service cannot be nil")
+	}
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	return &RunnerService{
+		requests: requests,
+		logger:   logger,
+	}
+}
+
+// SetLatencyService makes runs report the requests whose latency regressed.
+// Passing nil disables the check.
+func (s *RunnerService) SetLatencyService(latency *LatencyService) {
+	s.latency = latency
+}
+
+// Run executes every request in folder sequentially and records each
+// execution to history tagged with a new run ID.
+// Failing requests do not stop the run; cancelling ctx does, and the
+// requests that were not reached are reported as failed. Progress is
+// reported as the run goes to the RunProgressFunc set by WithRunProgress.
+func (s *RunnerService) Run(ctx context.Context, folder string) (*RunReport, error) {
+	requests, err := s.requests.ListFolder(ctx, folder)
+	if err != nil {
+		return nil, err
+	}
+	if len(requests) == 0 {
+		return nil, fmt.Errorf("folder %q has no requests", folder)
+	}
+
+	report := &RunReport{
+		RunID:     uuid.New().String(),
+		Folder:    folder,
+		StartedAt: time.Now().UTC(),
+	}
+
+	s.logger.Info("starting collection run",
+		"run_id", report.RunID,
+		"folder", folder,
+		"count", len(requests),
+	)
+
+	report.Results = s.requests.ExecuteRun(ctx, report.RunID, requests)
+	report.Duration = time.Since(report.StartedAt)
+	s.checkLatency(ctx, report)
+
+	s.logger.Info("collection run finished",
+		"run_id", report.RunID,
+		"passed", report.Passed(),
+		"failed", report.Failed(),
+		"duration_ms", report.Duration.Milliseconds(),
+	)
+
+	return report, nil
+}
+
+// checkLatency adds the latency regressions of the run's requests to report.
+// It is best effort: a failed analysis is logged and does not fail the run.
+func (s *RunnerService) checkLatency(ctx context.Context, report *RunReport) {
+	if s.latency == nil {
+		return
+	}
+	ids := make([]string, len(report.Results))
+	for i, result := range report.Results {
+		ids[i] = result.Request.ID
+	}
+	regressions, err := s.latency.AnalyzeAll(ctx, ids)
+	if err != nil {
+		s.logger.Warn("latency analysis failed", "run_id", report.RunID, "error", err)
+		return
+	}
+	for _, id := range repository.DistinctIDs(ids) {
+		if regression, ok := regressions[id]; ok {
+			report.Regressions = append(report.Regressions, regression)
+		}
+	}
+}
@@ -0,0 +1,183 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/williajm/curly/internal/domain"
+	"github.com/williajm/curly/internal/infrastructure/repository"
+)
+
+func TestNewRunnerService_PanicsOnNilRequestService(t *testing.T) {
+	assert.Panics(t, func() { NewRunnerService(nil, slog.Default()) })
+}
+
+func TestRunnerService_Run(t *testing.T) {
+	repo := new(MockRequestRepository)
+	httpClient := new(MockHTTPClient)
+	historyRepo := new(MockHistoryRepository)
+	runner := NewRunnerService(NewRequestService(repo, httpClient, historyRepo, slog.Default()), slog.Default())
+
+	login := domain.NewRequestWithMethodAndURL("POST", "https://api.example.com/login")
+	missing := domain.NewRequestWithMethodAndURL("GET", "https://api.example.com/missing")
+	down := domain.NewRequestWithMethodAndURL("GET", "https://api.example.com/down")
+	repo.On("FindByFolder", mock.Anything, "smoke").Return([]*domain.Request{login, missing, down}, nil)
+
+	httpClient.On("Execute", mock.Anything, login).Return(&domain.Response{StatusCode: 200, Headers: map[string]string{}}, nil)
+	httpClient.On("Execute", mock.Anything, missing).Return(&domain.Response{StatusCode: 404, Headers: map[string]string{}}, nil)
+	httpClient.On("Execute", mock.Anything, down).Return(nil, errors.New("connection refused"))
+
+	var saved []*repository.HistoryEntry
+	historyRepo.On("SaveBatch", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		saved = args.Get(1).([]*repository.HistoryEntry)
+	}).Return(nil).Once()
+
+	report, err := runner.Run(context.Background(), "smoke")
+	require.NoError(t, err)
+
+	assert.NotEmpty(t, report.RunID)
+	assert.Equal(t, "smoke", report.Folder)
+	require.Len(t, report.Results, 3)
+	assert.True(t, report.Results[0].Passed())
+	assert.False(t, report.Results[1].Passed())
+	assert.False(t, report.Results[2].Passed())
+	assert.Equal(t, 1, report.Passed())
+	assert.Equal(t, 2, report.Failed())
+
+	// Every execution, including failures, is recorded under the run ID.
+	require.Len(t, saved, 3)
+	for _, entry := range saved {
+		assert.Equal(t, report.RunID, entry.RunID)
+	}
+
+	httpClient.AssertExpectations(t)
+	historyRepo.AssertExpectations(t)
+}
+
+func TestRunnerService_Run_Errors(t *testing.T) {
+	repo := new(MockRequestRepository)
+	runner := NewRunnerService(NewRequestService(repo, new(MockHTTPClient), new(MockHistoryRepository), slog.Default()), slog.Default())
+
+	repo.On("FindByFolder", mock.Anything, "empty").Return([]*domain.Request{}, nil)
+	repo.On("FindByFolder", mock.Anything, "broken").Return(nil, errors.New("db error"))
+
+	_, err := runner.Run(context.Background(), "empty")
+	assert.ErrorContains(t, err, `folder "empty" has no requests`)
+
+	_, err = runner.Run(context.Background(), "broken")
+	assert.ErrorContains(t, err, "failed to list folder")
+}
+
+func TestRunnerService_Run_ReportsProgress(t *testing.T) {
+	repo := new(MockRequestRepository)
+	httpClient := new(MockHTTPClient)
+	historyRepo := new(MockHistoryRepository)
+	runner := NewRunnerService(NewRequestService(repo, httpClient, historyRepo, slog.Default()), slog.Default())
+
+	login := domain.NewRequestWithMethodAndURL("POST", "https://api.example.com/login")
+	invalid := domain.NewRequestWithMethodAndURL("GET", "not a url")
+	users := domain.NewRequestWithMethodAndURL("GET", "https://api.example.com/users")
+	repo.On("FindByFolder", mock.Anything, "smoke").Return([]*domain.Request{login, invalid, users}, nil)
+	httpClient.On("Execute", mock.Anything, mock.Anything).Return(&domain.Response{StatusCode: 200, Headers: map[string]string{}}, nil)
+	historyRepo.On("SaveBatch", mock.Anything, mock.Anything).Return(nil)
+
+	var events []string
+	ctx := WithRunProgress(context.Background(), func(p RunProgress) {
+		assert.Len(t, p.Requests, 3)
+		switch {
+		case p.Result == nil:
+			events = append(events, fmt.Sprintf("start %d", p.Index))
+		case p.Result.Passed():
+			events = append(events, fmt.Sprintf("pass %d", p.Index))
+		default:
+			events = append(events, fmt.Sprintf("fail %d", p.Index))
+		}
+	})
+
+	_, err := runner.Run(ctx, "smoke")
+	require.NoError(t, err)
+
+	// Invalid requests are not sent, so they are only reported as finished.
+	assert.Equal(t, []string{"start 0", "pass 0", "fail 1", "start 2", "pass 2"}, events)
+}
+
+func TestRunnerService_Run_Cancelled(t *testing.T) {
+	repo := new(MockRequestRepository)
+	httpClient := new(MockHTTPClient)
+	historyRepo := new(MockHistoryRepository)
+	runner := NewRunnerService(NewRequestService(repo, httpClient, historyRepo, slog.Default()), slog.Default())
+
+	first := domain.NewRequestWithMethodAndURL("GET", "https://api.example.com/first")
+	second := domain.NewRequestWithMethodAndURL("GET", "https://api.example.com/second")
+	repo.On("FindByFolder", mock.Anything, "smoke").Return([]*domain.Request{first, second}, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	httpClient.On("Execute", mock.Anything, first).Run(func(mock.Arguments) { cancel() }).
+		Return(&domain.Response{StatusCode: 200, Headers: map[string]string{}}, nil).Once()
+
+	// The execution that completed is still recorded.
+	var saved []*repository.HistoryEntry
+	historyRepo.On("SaveBatch", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		require.NoError(t, args.Get(0).(context.Context).Err())
+		saved = args.Get(1).([]*repository.HistoryEntry)
+	}).Return(nil).Once()
+
+	report, err := runner.Run(ctx, "smoke")
+	require.NoError(t, err)
+	require.Len(t, report.Results, 2)
+	assert.True(t, report.Results[0].Passed())
+	assert.ErrorIs(t, report.Results[1].Err, context.Canceled)
+	assert.Len(t, saved, 1)
+
+	httpClient.AssertExpectations(t)
+}
+
+func TestListFolders(t *testing.T) {
+	repo := new(MockRequestRepository)
+	service := NewRequestService(repo, new(MockHTTPClient), new(MockHistoryRepository), slog.Default())
+
+	top := domain.NewRequest()
+	users := domain.NewRequest()
+	users.Folder = "users"
+	admin := domain.NewRequest()
+	admin.Folder = "admin"
+	moreUsers := domain.NewRequest()
+	moreUsers.Folder = "users"
+	repo.On("FindAll", mock.Anything).Return([]*domain.Request{users, top, admin, moreUsers}, nil)
+
+	folders, err := service.ListFolders(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"", "admin", "users"}, folders)
+}
+
+func TestRunnerService_Run_ReportsLatencyRegressions(t *testing.T) {
+	repo := new(MockRequestRepository)
+	httpClient := new(MockHTTPClient)
+	historyRepo := new(MockHistoryRepository)
+	runner := NewRunnerService(NewRequestService(repo, httpClient, historyRepo, slog.Default()), slog.Default())
+	latency := NewLatencyService(historyRepo, slog.Default())
+	latency.SetOptions(LatencyOptions{Recent: 2, Baseline: 2, MinSamples: 2})
+	runner.SetLatencyService(latency)
+
+	slow := domain.NewRequestWithMethodAndURL("GET", "https://api.example.com/slow")
+	fast := domain.NewRequestWithMethodAndURL("GET", "https://api.example.com/fast")
+	repo.On("FindByFolder", mock.Anything, "smoke").Return([]*domain.Request{fast, slow}, nil)
+	httpClient.On("Execute", mock.Anything, mock.Anything).Return(&domain.Response{StatusCode: 200, Headers: map[string]string{}}, nil)
+	historyRepo.On("SaveBatch", mock.Anything, mock.Anything).Return(nil)
+	historyRepo.On("FindByRequestID", mock.Anything, slow.ID, 8).Return(latencyHistory(slow.ID, 900, 900, 100, 100), nil)
+	historyRepo.On("FindByRequestID", mock.Anything, fast.ID, 8).Return(latencyHistory(fast.ID, 100, 100, 100, 100), nil)
+
+	report, err := runner.Run(context.Background(), "smoke")
+	require.NoError(t, err)
+	require.Len(t, report.Regressions, 1)
+	assert.Equal(t, slow.ID, report.Regressions[0].RequestID)
+
+	// Latency regressions are warnings; they do not fail the run.
+	assert.Equal(t, 0, report.Failed())
+}
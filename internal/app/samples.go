@@ -0,0 +1,60 @@
+package app
+
+import (
+	"context"
+
+	"github.com/williajm/curly/internal/domain"
+)
+
+// SamplesFolder is the folder sample requests are created in.
+const SamplesFolder = "Samples"
+
+// sampleRequests returns requests against httpbin.org that show what curly
+// can send: query parameters, JSON bodies, auth, error statuses, slow
+// responses and images.
+func sampleRequests() []*domain.Request {
+	getQuery := domain.NewRequestWithMethodAndURL(domain.MethodGet, "https://httpbin.org/get")
+	getQuery.Name = "Echo query parameters"
+	getQuery.SetQueryParam("greeting", "hello")
+
+	postJSON := domain.NewRequestWithMethodAndURL(domain.MethodPost, "https://httpbin.org/post")
+	postJSON.Name = "Post JSON"
+	postJSON.SetHeader("Content-Type", "application/json")
+	postJSON.Body = "{\n  \"name\": \"curly\",\n  \"tags\": [\"api\", \"terminal\"]\n}"
+
+	bearer := domain.NewRequestWithMethodAndURL(domain.MethodGet, "https://httpbin.org/bearer")
+	bearer.Name = "Bearer token"
+	bearer.SetAuth(domain.NewBearerAuth("sample-token"))
+
+	basic := domain.NewRequestWithMethodAndURL(domain.MethodGet, "https://httpbin.org/basic-auth/curly/secret")
+	basic.Name = "Basic auth"
+	basic.SetAuth(domain.NewBasicAuth("curly", "secret"))
+
+	notFound := domain.NewRequestWithMethodAndURL(domain.MethodGet, "https://httpbin.org/status/404")
+	notFound.Name = "Not found"
+
+	slow := domain.NewRequestWithMethodAndURL(domain.MethodGet, "https://httpbin.org/delay/2")
+	slow.Name = "Slow response"
+
+	image := domain.NewRequestWithMethodAndURL(domain.MethodGet, "https://httpbin.org/image/png")
+	image.Name = "Image"
+
+	requests := []*domain.Request{getQuery, postJSON, bearer, basic, notFound, slow, image}
+	for i, req := range requests {
+		req.Folder = SamplesFolder
+		req.Position = i
+	}
+	return requests
+}
+
+// CreateSamples saves a few sample requests against httpbin.org in the
+// SamplesFolder folder, to try curly out with. It returns the created requests.
+func (s *ImportService) CreateSamples(ctx context.Context) ([]*domain.Request, error) {
+	requests := sampleRequests()
+	if err := s.save(ctx, requests); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("sample requests created", "count", len(requests))
+	return requests, nil
+}
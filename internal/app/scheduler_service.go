@@ -0,0 +1,190 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/williajm/curly/internal/infrastructure/cron"
+)
+
+// notificationBuffer is how many undelivered notifications are kept before
+// new ones are dropped.
+const notificationBuffer = 16
+
+// Schedule runs a saved request or a folder of requests on a cron schedule.
+// Exactly one of Request and Folder must be set.
+type Schedule struct {
+	// Name identifies the schedule in logs and notifications.
+	// It defaults to the request or folder.
+	Name string
+
+	// Spec is the cron expression, e.g. "*/5 * * * *" or "@every 30s".
+	Spec string
+
+	// Request is the ID, unique ID prefix, or name of a saved request.
+	Request string
+
+	// Folder is a folder of saved requests to run as a collection.
+	Folder string
+}
+
+// Notification reports a failed scheduled execution.
+type Notification struct {
+	// Schedule is the name of the schedule that failed.
+	Schedule string
+
+	// At is when the execution finished.
+	At time.Time
+
+	// Message describes the failure.
+	Message string
+}
+
+// SchedulerService executes saved requests and collections on cron schedules
+// while curly is running. Executions are recorded to history like any other,
+// and failures are published as notifications.
+type SchedulerService struct {
+	requests      *RequestService
+	runner        *RunnerService
+	notifications chan Notification
+	logger        *slog.Logger
+}
+
+// NewSchedulerService creates a new SchedulerService.
+// The request and runner services are required and must not be nil.
+func NewSchedulerService(requests *RequestService, runner *RunnerService, logger *slog.Logger) *SchedulerService {
+	if requests == nil {
+		panic("request service cannot be nil")
+	}
+	if runner == nil {
+		panic("runner service cannot be nil")
+	}
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	return &SchedulerService{
+		requests:      requests,
+		runner:        runner,
+		notifications: make(chan Notification, notificationBuffer),
+		logger:        logger,
+	}
+}
+
+// Notifications returns the channel failed executions are published on.
+// Notifications are dropped while the channel's buffer is full.
+func (s *SchedulerService) Notifications() <-chan Notification {
+	return s.notifications
+}
+
+// Start validates schedules and then runs each in the background until ctx
+// is cancelled. Nothing is started if any schedule is invalid.
+func (s *SchedulerService) Start(ctx context.Context, schedules []Schedule) error {
+	schedules = append([]Schedule(nil), schedules...)
+	specs := make([]*cron.Schedule, len(schedules))
+	for i := range schedules {
+		sched := &schedules[i]
+		if (sched.Request == "") == (sched.Folder == "") {
+			return fmt.Errorf("schedule %d: exactly one of request and folder must be set", i+1)
+		}
+		if sched.Name == "" {
+			sched.Name = sched.Request + sched.Folder
+		}
+
+		spec, err := cron.Parse(sched.Spec)
+		if err != nil {
+			return fmt.Errorf("schedule %q: %w", sched.Name, err)
+		}
+		specs[i] = spec
+	}
+
+	for i, sched := range schedules {
+		s.logger.Info("starting schedule",
+			"schedule", sched.Name,
+			"spec", sched.Spec,
+		)
+		go s.loop(ctx, sched, specs[i])
+	}
+
+	return nil
+}
+
+// loop fires sched at each activation time until ctx is cancelled.
+func (s *SchedulerService) loop(ctx context.Context, sched Schedule, spec *cron.Schedule) {
+	for {
+		next := spec.Next(time.Now())
+		if next.IsZero() {
+			s.logger.Warn("schedule never activates", "schedule", sched.Name, "spec", sched.Spec)
+			return
+		}
+
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+			s.fire(ctx, sched)
+		}
+	}
+}
+
+// fire executes sched once and publishes a notification if it fails.
+func (s *SchedulerService) fire(ctx context.Context, sched Schedule) {
+	s.logger.Info("running scheduled execution", "schedule", sched.Name)
+
+	var failure string
+	if sched.Folder != "" {
+		failure = s.runFolder(ctx, sched.Folder)
+	} else {
+		failure = s.runRequest(ctx, sched.Request)
+	}
+	if failure == "" {
+		return
+	}
+
+	s.logger.Warn("scheduled execution failed",
+		"schedule", sched.Name,
+		"failure", failure,
+	)
+
+	select {
+	case s.notifications <- Notification{Schedule: sched.Name, At: time.Now().UTC(), Message: failure}:
+	default:
+		s.logger.Warn("dropped schedule notification", "schedule", sched.Name)
+	}
+}
+
+// runRequest executes a saved request and describes its failure, if any.
+func (s *SchedulerService) runRequest(ctx context.Context, ref string) string {
+	req, err := s.requests.FindRequest(ctx, ref)
+	if err != nil {
+		return err.Error()
+	}
+
+	resp, err := s.requests.ExecuteAndSave(ctx, req)
+	result := ExecutionResult{Request: req, Response: resp, Err: err}
+	switch {
+	case err != nil:
+		return err.Error()
+	case len(resp.AssertionFailures) > 0:
+		return fmt.Sprintf("%s failed %d assertions: %s", req.Name, len(resp.AssertionFailures), resp.AssertionFailures[0])
+	case !result.Passed():
+		return fmt.Sprintf("%s returned status %d", req.Name, resp.StatusCode)
+	}
+	return ""
+}
+
+// runFolder runs a folder as a collection and describes its failures, if any.
+func (s *SchedulerService) runFolder(ctx context.Context, folder string) string {
+	report, err := s.runner.Run(ctx, folder)
+	if err != nil {
+		return err.Error()
+	}
+	if report.Failed() > 0 {
+		return fmt.Sprintf("%d of %d requests failed (run %s)", report.Failed(), len(report.Results), report.RunID)
+	}
+	return ""
+}
@@ -0,0 +1,125 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/williajm/curly/internal/domain"
+)
+
+// newTestScheduler returns a scheduler backed by fresh mocks.
+func newTestScheduler() (*SchedulerService, *MockRequestRepository, *MockHTTPClient, *MockHistoryRepository) {
+	repo := new(MockRequestRepository)
+	httpClient := new(MockHTTPClient)
+	historyRepo := new(MockHistoryRepository)
+	requests := NewRequestService(repo, httpClient, historyRepo, slog.Default())
+	scheduler := NewSchedulerService(requests, NewRunnerService(requests, slog.Default()), slog.Default())
+	return scheduler, repo, httpClient, historyRepo
+}
+
+func TestNewSchedulerService_PanicsOnNilDependencies(t *testing.T) {
+	requests := NewRequestService(new(MockRequestRepository), new(MockHTTPClient), new(MockHistoryRepository), slog.Default())
+
+	assert.Panics(t, func() { NewSchedulerService(nil, NewRunnerService(requests, nil), nil) })
+	assert.Panics(t, func() { NewSchedulerService(requests, nil, nil) })
+}
+
+func TestSchedulerService_Start_Validates(t *testing.T) {
+	scheduler, _, _, _ := newTestScheduler()
+
+	err := scheduler.Start(context.Background(), []Schedule{{Spec: "@hourly"}})
+	assert.ErrorContains(t, err, "exactly one of request and folder")
+
+	err = scheduler.Start(context.Background(), []Schedule{{Spec: "@hourly", Request: "a", Folder: "b"}})
+	assert.ErrorContains(t, err, "exactly one of request and folder")
+
+	err = scheduler.Start(context.Background(), []Schedule{{Spec: "every minute", Request: "Health"}})
+	assert.ErrorContains(t, err, `schedule "Health": expected 5 fields`)
+
+	err = scheduler.Start(context.Background(), []Schedule{{Spec: "@every 1ns", Request: "Health"}})
+	assert.ErrorContains(t, err, `schedule "Health": @every duration must be at least 1s`)
+}
+
+func TestSchedulerService_FiresUntilCancelled(t *testing.T) {
+	scheduler, repo, httpClient, historyRepo := newTestScheduler()
+
+	req := domain.NewRequestWithMethodAndURL("GET", "https://api.example.com/health")
+	req.Name = "Health"
+	repo.On("FindAll", mock.Anything).Return([]*domain.Request{req}, nil)
+	historyRepo.On("SaveBatch", mock.Anything, mock.Anything).Return(nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	fired := make(chan struct{}, 1)
+	httpClient.On("Execute", mock.Anything, req).Return(nil, errors.New("connection refused")).Run(func(mock.Arguments) {
+		select {
+		case fired <- struct{}{}:
+		default:
+		}
+	})
+
+	require.NoError(t, scheduler.Start(ctx, []Schedule{{Spec: "@every 1s", Request: "health"}}))
+
+	select {
+	case <-fired:
+	case <-time.After(3 * time.Second):
+		t.Fatal("schedule did not fire")
+	}
+
+	select {
+	case n := <-scheduler.Notifications():
+		assert.Equal(t, "health", n.Schedule)
+		assert.Contains(t, n.Message, "connection refused")
+	case <-time.After(time.Second):
+		t.Fatal("no notification for the failed execution")
+	}
+}
+
+func TestSchedulerService_Fire(t *testing.T) {
+	scheduler, repo, httpClient, historyRepo := newTestScheduler()
+
+	healthy := domain.NewRequestWithMethodAndURL("GET", "https://api.example.com/ok")
+	healthy.Name = "OK"
+	broken := domain.NewRequestWithMethodAndURL("GET", "https://api.example.com/broken")
+	broken.Name = "Broken"
+	broken.Folder = "smoke"
+	repo.On("FindAll", mock.Anything).Return([]*domain.Request{healthy, broken}, nil)
+	repo.On("FindByFolder", mock.Anything, "smoke").Return([]*domain.Request{broken}, nil)
+	historyRepo.On("SaveBatch", mock.Anything, mock.Anything).Return(nil)
+	httpClient.On("Execute", mock.Anything, healthy).Return(&domain.Response{StatusCode: 204, Headers: map[string]string{}}, nil)
+	httpClient.On("Execute", mock.Anything, broken).Return(&domain.Response{StatusCode: 503, Headers: map[string]string{}}, nil)
+
+	ctx := context.Background()
+
+	// Passing executions are silent.
+	scheduler.fire(ctx, Schedule{Name: "ok", Request: "OK"})
+	assert.Empty(t, scheduler.Notifications())
+
+	scheduler.fire(ctx, Schedule{Name: "broken", Request: "Broken"})
+	n := <-scheduler.Notifications()
+	assert.Equal(t, "Broken returned status 503", n.Message)
+
+	scheduler.fire(ctx, Schedule{Name: "smoke", Folder: "smoke"})
+	n = <-scheduler.Notifications()
+	assert.Contains(t, n.Message, "1 of 1 requests failed")
+
+	scheduler.fire(ctx, Schedule{Name: "missing", Request: "nope"})
+	n = <-scheduler.Notifications()
+	assert.Contains(t, n.Message, "not found")
+}
+
+func TestSchedulerService_DropsNotificationsWhenFull(t *testing.T) {
+	scheduler, repo, _, _ := newTestScheduler()
+	repo.On("FindAll", mock.Anything).Return([]*domain.Request{}, nil)
+
+	for i := 0; i < notificationBuffer+1; i++ {
+		scheduler.fire(context.Background(), Schedule{Name: "missing", Request: "nope"})
+	}
+	assert.Len(t, scheduler.Notifications(), notificationBuffer)
+}
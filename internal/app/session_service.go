@@ -0,0 +1,113 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/williajm/curly/internal/domain"
+	"github.com/williajm/curly/internal/infrastructure/repository"
+)
+
+// RestoredSession is where an earlier session left the TUI, ready to
+// restore.
+type RestoredSession struct {
+	// Session is the session as it was last saved.
+	Session *domain.Session
+
+	// Request is the saved request that was open in the request builder, or
+	// nil if none was or it has since been deleted.
+	Request *domain.Request
+
+	// Response is the latest response recorded for Request, or nil if it
+	// has none.
+	Response *domain.Response
+}
+
+// SessionService remembers where the TUI was left when it exited, so that
+// the next launch starts there again.
+type SessionService struct {
+	sessions repository.SessionRepository
+	requests repository.RequestRepository
+	history  repository.HistoryRepository
+	logger   *slog.Logger
+}
+
+// NewSessionService creates a new SessionService with the provided
+// dependencies. The repositories are required and must not be nil.
+func NewSessionService(sessions repository.SessionRepository, requests repository.RequestRepository, history repository.HistoryRepository, logger *slog.Logger) *SessionService {
+	if sessions == nil {
+		panic("session repository cannot be nil")
+	}
+	if requests == nil {
+		panic("request repository cannot be nil")
+	}
+	if history == nil {
+		panic("history repository cannot be nil")
+	}
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	return &SessionService{
+		sessions: sessions,
+		requests: requests,
+		history:  history,
+		logger:   logger,
+	}
+}
+
+// Save remembers session as where the TUI was left.
+func (s *SessionService) Save(ctx context.Context, session *domain.Session) error {
+	session.UpdatedAt = time.Now()
+	if err := s.sessions.Save(ctx, session); err != nil {
+		s.logger.Error("failed to save session", "error", err)
+		return fmt.Errorf("failed to save session: %w", err)
+	}
+	s.logger.Debug("session saved", "request_id", session.RequestID, "tab", session.Tab)
+	return nil
+}
+
+// Restore returns where the last session left the TUI, with the request
+// that was open and its latest response, or nil if no session was saved.
+func (s *SessionService) Restore(ctx context.Context) (*RestoredSession, error) {
+	session, err := s.sessions.Find(ctx)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, nil
+		}
+		s.logger.Error("failed to load session", "error", err)
+		return nil, fmt.Errorf("failed to load session: %w", err)
+	}
+
+	restored := &RestoredSession{Session: session}
+	if session.RequestID == "" {
+		return restored, nil
+	}
+
+	req, err := s.requests.FindByID(ctx, session.RequestID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			s.logger.Debug("request of the session is not saved", "request_id", session.RequestID)
+			return restored, nil
+		}
+		return nil, fmt.Errorf("failed to load the request of the session: %w", err)
+	}
+	restored.Request = req
+
+	entries, err := s.history.FindByRequestID(ctx, req.ID, 1)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load the response of the session: %w", err)
+	}
+	if len(entries) > 0 {
+		// The entry is read again for its full body, which may be offloaded.
+		entry, err := s.history.FindByID(ctx, entries[0].ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load the response of the session: %w", err)
+		}
+		restored.Response = entry.Response()
+	}
+	return restored, nil
+}
@@ -0,0 +1,141 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/williajm/curly/internal/domain"
+	"github.com/williajm/curly/internal/infrastructure/repository"
+)
+
+// MockSessionRepository is a mock implementation of repository.SessionRepository.
+type MockSessionRepository struct {
+	mock.Mock
+}
+
+func (m *MockSessionRepository) Save(ctx context.Context, session *domain.Session) error {
+	args := m.Called(ctx, session)
+	return args.Error(0)
+}
+
+func (m *MockSessionRepository) Find(ctx context.Context) (*domain.Session, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Session), args.Error(1)
+}
+
+func TestNewSessionService_NilRepositories(t *testing.T) {
+	assert.Panics(t, func() {
+		NewSessionService(nil, new(MockRequestRepository), new(MockHistoryRepository), slog.Default())
+	})
+	assert.Panics(t, func() {
+		NewSessionService(new(MockSessionRepository), nil, new(MockHistoryRepository), slog.Default())
+	})
+	assert.Panics(t, func() {
+		NewSessionService(new(MockSessionRepository), new(MockRequestRepository), nil, slog.Default())
+	})
+}
+
+func TestSessionService_Save(t *testing.T) {
+	sessions := new(MockSessionRepository)
+	service := NewSessionService(sessions, new(MockRequestRepository), new(MockHistoryRepository), slog.Default())
+	ctx := context.Background()
+
+	session := &domain.Session{RequestID: "req-1", Tab: "Response", ResponseOffset: 40}
+	sessions.On("Save", ctx, session).Return(nil).Once()
+	require.NoError(t, service.Save(ctx, session))
+	assert.WithinDuration(t, time.Now(), session.UpdatedAt, time.Minute)
+
+	sessions.On("Save", ctx, session).Return(errors.New("disk full")).Once()
+	assert.ErrorContains(t, service.Save(ctx, session), "failed to save session: disk full")
+	sessions.AssertExpectations(t)
+}
+
+func TestSessionService_Restore(t *testing.T) {
+	sessions := new(MockSessionRepository)
+	requests := new(MockRequestRepository)
+	history := new(MockHistoryRepository)
+	service := NewSessionService(sessions, requests, history, slog.Default())
+	ctx := context.Background()
+
+	req := domain.NewRequestWithMethodAndURL("GET", "https://api.example.com/users")
+	session := &domain.Session{RequestID: req.ID, Tab: "Response", ResponseOffset: 40}
+	sessions.On("Find", ctx).Return(session, nil)
+	requests.On("FindByID", ctx, req.ID).Return(req, nil)
+	history.On("FindByRequestID", ctx, req.ID, 1).Return([]*repository.HistoryEntry{{ID: "h1", ResponseBody: "[{"}}, nil)
+	history.On("FindByID", ctx, "h1").Return(&repository.HistoryEntry{
+		ID:         "h1",
+		RequestID:  req.ID,
+		StatusCode: 200,
+		Status:     "200 OK",
+		ExecutedAt: "2026-10-17T10:00:00Z",
+		// The full body, of which the entry listed held a preview.
+		ResponseBody: `[{"id":1}]`,
+	}, nil)
+
+	restored, err := service.Restore(ctx)
+	require.NoError(t, err)
+	assert.Same(t, session, restored.Session)
+	assert.Same(t, req, restored.Request)
+	require.NotNil(t, restored.Response)
+	assert.Equal(t, 200, restored.Response.StatusCode)
+	assert.Equal(t, `[{"id":1}]`, restored.Response.Body)
+}
+
+func TestSessionService_Restore_NoResponse(t *testing.T) {
+	sessions := new(MockSessionRepository)
+	requests := new(MockRequestRepository)
+	history := new(MockHistoryRepository)
+	service := NewSessionService(sessions, requests, history, slog.Default())
+	ctx := context.Background()
+
+	req := domain.NewRequestWithMethodAndURL("GET", "https://api.example.com/users")
+	sessions.On("Find", ctx).Return(&domain.Session{RequestID: req.ID, Tab: "Request"}, nil)
+	requests.On("FindByID", ctx, req.ID).Return(req, nil)
+	history.On("FindByRequestID", ctx, req.ID, 1).Return([]*repository.HistoryEntry{}, nil)
+
+	restored, err := service.Restore(ctx)
+	require.NoError(t, err)
+	assert.Same(t, req, restored.Request)
+	assert.Nil(t, restored.Response)
+}
+
+func TestSessionService_Restore_DeletedRequest(t *testing.T) {
+	sessions := new(MockSessionRepository)
+	requests := new(MockRequestRepository)
+	history := new(MockHistoryRepository)
+	service := NewSessionService(sessions, requests, history, slog.Default())
+	ctx := context.Background()
+
+	sessions.On("Find", ctx).Return(&domain.Session{RequestID: "gone", Tab: "History", HistoryIndex: 3}, nil)
+	requests.On("FindByID", ctx, "gone").Return(nil, repository.ErrNotFound)
+
+	restored, err := service.Restore(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 3, restored.Session.HistoryIndex, "the rest of the session is still restored")
+	assert.Nil(t, restored.Request)
+	history.AssertNotCalled(t, "FindByRequestID", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestSessionService_Restore_NoSession(t *testing.T) {
+	sessions := new(MockSessionRepository)
+	service := NewSessionService(sessions, new(MockRequestRepository), new(MockHistoryRepository), slog.Default())
+	ctx := context.Background()
+
+	sessions.On("Find", ctx).Return(nil, repository.ErrNotFound).Once()
+	restored, err := service.Restore(ctx)
+	require.NoError(t, err)
+	assert.Nil(t, restored)
+
+	sessions.On("Find", ctx).Return(nil, errors.New("database is locked")).Once()
+	_, err = service.Restore(ctx)
+	assert.ErrorContains(t, err, "failed to load session: database is locked")
+}
@@ -0,0 +1,175 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/williajm/curly/internal/infrastructure/repository"
+)
+
+// StatsReport is the aggregate execution statistics of history, per saved
+// request and per host, as `curly stats` exports them.
+type StatsReport struct {
+	// Requests are the statistics of each request, as returned by GetStats.
+	Requests []*repository.RequestStats
+
+	// Hosts are the statistics of each host, as returned by GetHostStats.
+	Hosts []*repository.HostStats
+
+	// Names maps request IDs to the names of the requests still saved.
+	Names map[string]string
+}
+
+// statsSummary is the JSON form of a StatsReport.
+type statsSummary struct {
+	Requests []requestStatsSummary `json:"requests"`
+	Hosts    []hostStatsSummary    `json:"hosts"`
+}
+
+// statsFigures are the figures common to requests and hosts.
+type statsFigures struct {
+	Executions    int64   `json:"executions"`
+	Successes     int64   `json:"successes"`
+	Failures      int64   `json:"failures"`
+	ErrorRate     float64 `json:"error_rate"`
+	P50Ms         int64   `json:"p50_ms"`
+	P95Ms         int64   `json:"p95_ms"`
+	P99Ms         int64   `json:"p99_ms"`
+	LastFailureAt string  `json:"last_failure_at,omitempty"`
+	LastFailure   string  `json:"last_failure,omitempty"`
+}
+
+type requestStatsSummary struct {
+	RequestID string `json:"request_id"`
+	Name      string `json:"name,omitempty"`
+	statsFigures
+}
+
+type hostStatsSummary struct {
+	Host string `json:"host"`
+	statsFigures
+}
+
+// WriteJSON writes the report as indented JSON: one entry per request, with
+// ad-hoc executions under an empty request ID, and one per host.
+func (r *StatsReport) WriteJSON(w io.Writer) error {
+	summary := statsSummary{
+		Requests: []requestStatsSummary{},
+		Hosts:    []hostStatsSummary{},
+	}
+	for _, s := range r.Requests {
+		summary.Requests = append(summary.Requests, requestStatsSummary{
+			RequestID:    s.RequestID,
+			Name:         r.Names[s.RequestID],
+			statsFigures: figures(s.Count, s.SuccessCount, s.P50Ms, s.P95Ms, s.P99Ms, s.LastFailureAt, s.LastFailure),
+		})
+	}
+	for _, s := range r.Hosts {
+		summary.Hosts = append(summary.Hosts, hostStatsSummary{
+			Host:         s.Host,
+			statsFigures: figures(s.Count, s.SuccessCount, s.P50Ms, s.P95Ms, s.P99Ms, s.LastFailureAt, s.LastFailure),
+		})
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(summary)
+}
+
+// figures returns the JSON figures of count executions.
+func figures(count, successes, p50, p95, p99 int64, lastFailureAt, lastFailure string) statsFigures {
+	f := statsFigures{
+		Executions:    count,
+		Successes:     successes,
+		Failures:      count - successes,
+		P50Ms:         p50,
+		P95Ms:         p95,
+		P99Ms:         p99,
+		LastFailureAt: lastFailureAt,
+		LastFailure:   lastFailure,
+	}
+	if count > 0 {
+		f.ErrorRate = float64(f.Failures) / float64(count)
+	}
+	return f
+}
+
+// metricFamily is one metric of the Prometheus text format.
+type metricFamily struct {
+	name, kind, help string
+	samples          []metricSample
+}
+
+// metricSample is one value of a metric with its labels, in order.
+type metricSample struct {
+	labels []string
+	value  float64
+}
+
+// WritePrometheus writes the report in the Prometheus text exposition
+// format, so it can be served to a scraper or pushed to a Pushgateway. Each
+// host has its executions, failures, error rate and latency percentiles; so
+// has each request, labeled with its ID and name.
+func (r *StatsReport) WritePrometheus(w io.Writer) error {
+	hosts := []*metricFamily{
+		{name: "curly_host_executions_total", kind: "counter", help: "Requests executed per host, from history."},
+		{name: "curly_host_failures_total", kind: "counter", help: "Executions per host that failed: an error, a status outside 2xx and 3xx, or failed assertions."},
+		{name: "curly_host_error_ratio", kind: "gauge", help: "Fraction of executions per host that failed."},
+		{name: "curly_host_response_time_ms", kind: "gauge", help: "Response time percentiles per host, in milliseconds."},
+	}
+	for _, s := range r.Hosts {
+		addSamples(hosts, []string{"host", s.Host}, figures(s.Count, s.SuccessCount, s.P50Ms, s.P95Ms, s.P99Ms, "", ""))
+	}
+
+	requests := []*metricFamily{
+		{name: "curly_request_executions_total", kind: "counter", help: "Executions per saved request, from history."},
+		{name: "curly_request_failures_total", kind: "counter", help: "Executions per saved request that failed."},
+		{name: "curly_request_error_ratio", kind: "gauge", help: "Fraction of executions per saved request that failed."},
+		{name: "curly_request_response_time_ms", kind: "gauge", help: "Response time percentiles per saved request, in milliseconds."},
+	}
+	for _, s := range r.Requests {
+		labels := []string{"request_id", s.RequestID, "request", r.Names[s.RequestID]}
+		addSamples(requests, labels, figures(s.Count, s.SuccessCount, s.P50Ms, s.P95Ms, s.P99Ms, "", ""))
+	}
+
+	var b strings.Builder
+	for _, family := range append(hosts, requests...) {
+		fmt.Fprintf(&b, "# HELP %s %s\n# TYPE %s %s\n", family.name, family.help, family.name, family.kind)
+		for _, sample := range family.samples {
+			b.WriteString(family.name)
+			b.WriteString("{")
+			for i := 0; i < len(sample.labels); i += 2 {
+				if i > 0 {
+					b.WriteString(",")
+				}
+				fmt.Fprintf(&b, "%s=\"%s\"", sample.labels[i], escapeLabel(sample.labels[i+1]))
+			}
+			fmt.Fprintf(&b, "} %s\n", strconv.FormatFloat(sample.value, 'f', -1, 64))
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// addSamples adds the figures of one host or request, labeled with labels,
+// to the families made by WritePrometheus, in order.
+func addSamples(families []*metricFamily, labels []string, f statsFigures) {
+	families[0].samples = append(families[0].samples, metricSample{labels, float64(f.Executions)})
+	families[1].samples = append(families[1].samples, metricSample{labels, float64(f.Failures)})
+	families[2].samples = append(families[2].samples, metricSample{labels, f.ErrorRate})
+	for _, q := range []struct {
+		quantile string
+		ms       int64
+	}{{"0.5", f.P50Ms}, {"0.95", f.P95Ms}, {"0.99", f.P99Ms}} {
+		quantile := append(append([]string{}, labels...), "quantile", q.quantile)
+		families[3].samples = append(families[3].samples, metricSample{quantile, float64(q.ms)})
+	}
+}
+
+// escapeLabel escapes a label value for the Prometheus text format.
+func escapeLabel(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+}
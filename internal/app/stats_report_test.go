@@ -0,0 +1,72 @@
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/williajm/curly/internal/infrastructure/repository"
+)
+
+// sampleStatsReport returns a report of a saved request, ad-hoc executions
+// and the hosts they went to.
+func sampleStatsReport() *StatsReport {
+	return &StatsReport{
+		Requests: []*repository.RequestStats{
+			{RequestID: "req-1", Count: 4, SuccessCount: 3, P50Ms: 20, P95Ms: 80, P99Ms: 95, LastFailureAt: "2026-01-02T03:04:05Z", LastFailure: "503"},
+			{RequestID: "", Count: 1, SuccessCount: 1, P50Ms: 7, P95Ms: 7, P99Ms: 7},
+		},
+		Hosts: []*repository.HostStats{
+			{Host: "api.example.com", Count: 5, SuccessCount: 4, P50Ms: 15, P95Ms: 80, P99Ms: 95},
+		},
+		Names: map[string]string{"": "(ad-hoc)", "req-1": `Get "users"`},
+	}
+}
+
+func TestStatsReport_WriteJSON(t *testing.T) {
+	var out bytes.Buffer
+	require.NoError(t, sampleStatsReport().WriteJSON(&out))
+
+	var summary statsSummary
+	require.NoError(t, json.Unmarshal(out.Bytes(), &summary))
+	require.Len(t, summary.Requests, 2)
+	assert.Equal(t, "req-1", summary.Requests[0].RequestID)
+	assert.Equal(t, `Get "users"`, summary.Requests[0].Name)
+	assert.Equal(t, int64(4), summary.Requests[0].Executions)
+	assert.Equal(t, int64(1), summary.Requests[0].Failures)
+	assert.InDelta(t, 0.25, summary.Requests[0].ErrorRate, 0.0001)
+	assert.Equal(t, "503", summary.Requests[0].LastFailure)
+	assert.Equal(t, "(ad-hoc)", summary.Requests[1].Name)
+
+	require.Len(t, summary.Hosts, 1)
+	assert.Equal(t, "api.example.com", summary.Hosts[0].Host)
+	assert.Equal(t, int64(95), summary.Hosts[0].P99Ms)
+	assert.InDelta(t, 0.2, summary.Hosts[0].ErrorRate, 0.0001)
+}
+
+func TestStatsReport_WriteJSON_Empty(t *testing.T) {
+	var out bytes.Buffer
+	require.NoError(t, (&StatsReport{}).WriteJSON(&out))
+	assert.JSONEq(t, `{"requests":[],"hosts":[]}`, out.String())
+}
+
+func TestStatsReport_WritePrometheus(t *testing.T) {
+	var out bytes.Buffer
+	require.NoError(t, sampleStatsReport().WritePrometheus(&out))
+
+	got := out.String()
+	for _, want := range []string{
+		"# TYPE curly_host_executions_total counter\n",
+		`curly_host_executions_total{host="api.example.com"} 5` + "\n",
+		`curly_host_failures_total{host="api.example.com"} 1` + "\n",
+		`curly_host_error_ratio{host="api.example.com"} 0.2` + "\n",
+		`curly_host_response_time_ms{host="api.example.com",quantile="0.95"} 80` + "\n",
+		`curly_request_executions_total{request_id="req-1",request="Get \"users\""} 4` + "\n",
+		`curly_request_response_time_ms{request_id="",request="(ad-hoc)",quantile="0.5"} 7` + "\n",
+	} {
+		assert.Contains(t, got, want)
+	}
+}
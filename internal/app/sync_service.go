@@ -0,0 +1,604 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"maps"
+	"net/url"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/williajm/curly/internal/domain"
+	"github.com/williajm/curly/internal/infrastructure/filesync"
+	"github.com/williajm/curly/internal/infrastructure/repository"
+)
+
+// ImportResult summarizes what an import changed.
+type ImportResult struct {
+	// Created is the number of requests that did not exist yet.
+	Created int
+
+	// Updated is the number of existing requests whose file differed,
+	// including requests that only moved.
+	Updated int
+
+	// Unchanged is the number of requests that already matched their file.
+	Unchanged int
+
+	// Deleted is the number of requests removed because the directory no
+	// longer has a file for them. It is only non-zero when pruning.
+	Deleted int
+
+	// Redacted names the requests whose files hold redacted secrets that
+	// no saved request could fill in, so they still need setting.
+	Redacted []string
+
+	// EnvironmentsCreated, EnvironmentsUpdated, EnvironmentsUnchanged and
+	// EnvironmentsDeleted count the environments like the fields above count
+	// requests.
+	EnvironmentsCreated   int
+	EnvironmentsUpdated   int
+	EnvironmentsUnchanged int
+	EnvironmentsDeleted   int
+
+	// RedactedEnvironments names the environments whose files hold redacted
+	// secret values that no saved environment could fill in.
+	RedactedEnvironments []string
+}
+
+// SyncService mirrors saved requests and environments to and from a
+// directory of YAML files so a workspace can be versioned in git.
+type SyncService struct {
+	repo         repository.RequestRepository
+	environments repository.EnvironmentRepository
+	logger       *slog.Logger
+}
+
+// NewSyncService creates a new SyncService with the provided dependencies.
+// The repositories are required and must not be nil.
+func NewSyncService(repo repository.RequestRepository, environments repository.EnvironmentRepository, logger *slog.Logger) *SyncService {
+	if repo == nil {
+		panic("request repository cannot be nil")
+	}
+	if environments == nil {
+		panic("environment repository cannot be nil")
+	}
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	return &SyncService{
+		repo:         repo,
+		environments: environments,
+		logger:       logger,
+	}
+}
+
+// Export writes every saved request and environment to dir, removing files of
+// those that no longer exist.
+// Unless includeSecrets is set, auth credentials, URL passwords, headers
+// and query parameters whose names look secret, and the values of secret
+// variables are written as domain.SecretMask, which Import fills back in
+// from the saved request or environment. Which environment is active is not
+// exported. It returns the number of files written or removed.
+func (s *SyncService) Export(ctx context.Context, dir string, includeSecrets bool) (int, error) {
+	requests, err := s.repo.FindAll(ctx)
+	if err != nil {
+		s.logger.Error("failed to load requests for export", "error", err)
+		return 0, fmt.Errorf("failed to load requests: %w", err)
+	}
+	envs, err := s.environments.FindAll(ctx)
+	if err != nil {
+		s.logger.Error("failed to load environments for export", "error", err)
+		return 0, fmt.Errorf("failed to load environments: %w", err)
+	}
+	if !includeSecrets {
+		for i, req := range requests {
+			requests[i] = maskSecrets(req)
+		}
+		for i, env := range envs {
+			envs[i] = maskVariables(env)
+		}
+	}
+
+	changed, err := filesync.WriteRequests(dir, requests)
+	if err != nil {
+		s.logger.Error("failed to export requests", "dir", dir, "error", err)
+		return changed, fmt.Errorf("failed to export requests: %w", err)
+	}
+	changedEnvs, err := filesync.WriteEnvironments(dir, envs)
+	changed += changedEnvs
+	if err != nil {
+		s.logger.Error("failed to export environments", "dir", dir, "error", err)
+		return changed, fmt.Errorf("failed to export environments: %w", err)
+	}
+
+	s.logger.Info("requests exported",
+		"dir", dir,
+		"count", len(requests),
+		"environments", len(envs),
+		"changed", changed,
+	)
+
+	return changed, nil
+}
+
+// Import creates or updates saved requests from the files in dir, then moves
+// them into the folders and positions recorded in the files, and creates or
+// updates environments from their files likewise.
+// Secrets redacted by Export keep the values of the saved request or
+// environment. Requests and environments that exist only in the database are
+// left untouched unless prune is set, in which case they are deleted.
+// Environments are left alone if dir has no environments directory, as in
+// exports that predate them. Every file is validated before anything is
+// written.
+func (s *SyncService) Import(ctx context.Context, dir string, prune bool) (*ImportResult, error) {
+	requests, envs, err := s.readFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ImportResult{}
+	changed := make(map[string]bool, len(requests))
+	for _, req := range requests {
+		if req.ID == "" {
+			req.ID = uuid.New().String()
+		}
+		updated, err := s.importRequest(ctx, req, result)
+		if err != nil {
+			return result, err
+		}
+		if updated {
+			changed[req.ID] = true
+		}
+	}
+
+	moved, err := s.applyOrder(ctx, requests)
+	if err != nil {
+		return result, err
+	}
+	for id := range moved {
+		if !changed[id] {
+			result.Updated++
+			changed[id] = true
+		}
+	}
+	result.Unchanged = len(requests) - len(changed)
+
+	if prune {
+		deleted, err := s.prune(ctx, requests)
+		result.Deleted = deleted
+		if err != nil {
+			return result, err
+		}
+	}
+
+	if envs != nil {
+		if err := s.importEnvironments(ctx, envs, prune, result); err != nil {
+			return result, err
+		}
+	}
+
+	s.logger.Info("requests imported",
+		"dir", dir,
+		"created", result.Created,
+		"updated", result.Updated,
+		"unchanged", result.Unchanged,
+		"deleted", result.Deleted,
+		"environments_created", result.EnvironmentsCreated,
+		"environments_updated", result.EnvironmentsUpdated,
+		"environments_deleted", result.EnvironmentsDeleted,
+	)
+
+	return result, nil
+}
+
+// readFiles reads and validates the request and environment files in dir.
+// The environments are nil if dir has no environments directory.
+func (s *SyncService) readFiles(dir string) ([]*domain.Request, []*domain.Environment, error) {
+	requests, err := filesync.ReadRequests(dir)
+	if err != nil {
+		s.logger.Error("failed to read request files", "dir", dir, "error", err)
+		return nil, nil, fmt.Errorf("failed to read request files: %w", err)
+	}
+	envs, err := filesync.ReadEnvironments(dir)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		envs = nil
+	case err != nil:
+		s.logger.Error("failed to read environment files", "dir", dir, "error", err)
+		return nil, nil, fmt.Errorf("failed to read environment files: %w", err)
+	}
+
+	for _, req := range requests {
+		if err := req.Validate(); err != nil {
+			return nil, nil, fmt.Errorf("invalid request %q: %w", req.Name, err)
+		}
+	}
+	for _, env := range envs {
+		if err := env.Validate(); err != nil {
+			return nil, nil, fmt.Errorf("invalid environment %q: %w", env.Name, err)
+		}
+	}
+	return requests, envs, nil
+}
+
+// importRequest creates req, or updates the saved request with its ID,
+// filling in the secrets redacted on export from the saved request. It counts
+// the outcome in result and reports whether a request was written.
+func (s *SyncService) importRequest(ctx context.Context, req *domain.Request, result *ImportResult) (bool, error) {
+	existing, err := s.repo.FindByID(ctx, req.ID)
+	if err == nil {
+		restoreSecrets(req, existing)
+	}
+	if hasMaskedSecrets(req) {
+		result.Redacted = append(result.Redacted, req.Name)
+	}
+
+	switch {
+	case errors.Is(err, repository.ErrNotFound):
+		now := time.Now()
+		created := req.Clone()
+		created.CreatedAt = now
+		created.UpdatedAt = now
+		if err := s.repo.Create(ctx, created); err != nil {
+			return false, fmt.Errorf("failed to create request %q: %w", req.Name, err)
+		}
+		result.Created++
+		return true, nil
+
+	case err != nil:
+		return false, fmt.Errorf("failed to load request %q: %w", req.Name, err)
+
+	case sameDefinition(existing, req):
+		return false, nil
+
+	default:
+		existing.Name = req.Name
+		existing.Method = req.Method
+		existing.URL = req.URL
+		existing.Headers = req.Headers
+		existing.QueryParams = req.QueryParams
+		existing.DisabledQueryParams = req.DisabledQueryParams
+		existing.Body = req.Body
+		existing.AuthConfig = req.AuthConfig
+		existing.ResponseSchema = req.ResponseSchema
+		existing.ResponseContract = req.ResponseContract
+		existing.ResponseFilter = req.ResponseFilter
+		existing.PreRequestScript = req.PreRequestScript
+		existing.PostResponseScript = req.PostResponseScript
+		existing.Tags = req.Tags
+		if err := s.repo.Update(ctx, existing); err != nil {
+			return false, fmt.Errorf("failed to update request %q: %w", req.Name, err)
+		}
+		result.Updated++
+		return true, nil
+	}
+}
+
+// applyOrder moves requests into the folders and positions given by their files.
+// Requests are placed in ascending position order, so each lands after those
+// already placed and the files' order is reproduced even when other requests
+// share the folder. It returns the IDs of the requests that moved.
+func (s *SyncService) applyOrder(ctx context.Context, requests []*domain.Request) (map[string]bool, error) {
+	ordered := make([]*domain.Request, len(requests))
+	copy(ordered, requests)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		if ordered[i].Folder != ordered[j].Folder {
+			return ordered[i].Folder < ordered[j].Folder
+		}
+		return ordered[i].Position < ordered[j].Position
+	})
+
+	moved := make(map[string]bool)
+	for _, req := range ordered {
+		current, err := s.repo.FindByID(ctx, req.ID)
+		if err != nil {
+			return moved, fmt.Errorf("failed to load request %q: %w", req.Name, err)
+		}
+		if current.Folder == req.Folder && current.Position == req.Position {
+			continue
+		}
+		if err := s.repo.Move(ctx, req.ID, req.Folder, req.Position); err != nil {
+			return moved, fmt.Errorf("failed to move request %q: %w", req.Name, err)
+		}
+		moved[req.ID] = true
+	}
+
+	return moved, nil
+}
+
+// prune deletes the saved requests that are not among requests, whose files
+// were removed from the directory. It returns the number deleted.
+func (s *SyncService) prune(ctx context.Context, requests []*domain.Request) (int, error) {
+	keep := make(map[string]bool, len(requests))
+	for _, req := range requests {
+		keep[req.ID] = true
+	}
+
+	saved, err := s.repo.FindAll(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to load requests: %w", err)
+	}
+
+	deleted := 0
+	for _, req := range saved {
+		if keep[req.ID] {
+			continue
+		}
+		if err := s.repo.Delete(ctx, req.ID); err != nil {
+			return deleted, fmt.Errorf("failed to delete request %q: %w", req.Name, err)
+		}
+		s.logger.Debug("request pruned", "request_id", req.ID)
+		deleted++
+	}
+
+	return deleted, nil
+}
+
+// importEnvironments creates envs, or updates the saved environments with
+// their IDs, or else their names, filling in the secret values redacted on
+// export from the saved environment. When prune is set, saved environments
+// without a file are deleted. The outcome is counted in result.
+func (s *SyncService) importEnvironments(ctx context.Context, envs []*domain.Environment, prune bool, result *ImportResult) error {
+	saved, err := s.environments.FindAll(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load environments: %w", err)
+	}
+	byID := make(map[string]*domain.Environment, len(saved))
+	byName := make(map[string]*domain.Environment, len(saved))
+	for _, env := range saved {
+		byID[env.ID] = env
+		byName[env.Name] = env
+	}
+
+	keep := make(map[string]bool, len(envs))
+	for _, env := range envs {
+		existing, ok := byID[env.ID]
+		if !ok {
+			existing = byName[env.Name]
+		}
+		if existing != nil {
+			keep[existing.ID] = true
+			restoreVariables(env, existing)
+		}
+		if hasMaskedVariables(env) {
+			result.RedactedEnvironments = append(result.RedactedEnvironments, env.Name)
+		}
+		if err := s.importEnvironment(ctx, env, existing, result); err != nil {
+			return err
+		}
+	}
+
+	if !prune {
+		return nil
+	}
+	for _, env := range saved {
+		if keep[env.ID] {
+			continue
+		}
+		if err := s.environments.Delete(ctx, env.ID); err != nil {
+			return fmt.Errorf("failed to delete environment %q: %w", env.Name, err)
+		}
+		s.logger.Debug("environment pruned", "environment_id", env.ID)
+		result.EnvironmentsDeleted++
+	}
+	return nil
+}
+
+// importEnvironment creates env, or updates existing to match it if it is not
+// nil, and counts the outcome in result.
+func (s *SyncService) importEnvironment(ctx context.Context, env, existing *domain.Environment, result *ImportResult) error {
+	switch {
+	case existing == nil:
+		created := domain.NewEnvironment(env.Name)
+		if env.ID != "" {
+			created.ID = env.ID
+		}
+		created.Variables, created.Secrets = env.Variables, env.Secrets
+		if err := s.environments.Create(ctx, created); err != nil {
+			return fmt.Errorf("failed to create environment %q: %w", env.Name, err)
+		}
+		result.EnvironmentsCreated++
+
+	case existing.Name == env.Name && reflect.DeepEqual(existing.Variables, env.Variables) &&
+		slices.Equal(existing.Secrets, env.Secrets):
+		result.EnvironmentsUnchanged++
+
+	default:
+		existing.Name = env.Name
+		existing.Variables, existing.Secrets = env.Variables, env.Secrets
+		existing.UpdatedAt = time.Now()
+		if err := s.environments.Update(ctx, existing); err != nil {
+			return fmt.Errorf("failed to update environment %q: %w", env.Name, err)
+		}
+		result.EnvironmentsUpdated++
+	}
+	return nil
+}
+
+// maskVariables returns a copy of env with the values of its secret
+// variables masked, keeping empty values and {{name}} references.
+func maskVariables(env *domain.Environment) *domain.Environment {
+	masked := env.Clone()
+	for _, name := range env.Secrets {
+		masked.Variables[name] = maskValue(masked.Variables[name])
+	}
+	return masked
+}
+
+// restoreVariables replaces the secret values of env that were redacted on
+// export with the values saved in existing.
+func restoreVariables(env, existing *domain.Environment) {
+	for _, name := range env.Secrets {
+		if saved, ok := existing.Variables[name]; ok && env.Variables[name] == domain.SecretMask {
+			env.Variables[name] = saved
+		}
+	}
+}
+
+// hasMaskedVariables reports whether env still holds a secret value redacted
+// on export.
+func hasMaskedVariables(env *domain.Environment) bool {
+	for _, name := range env.Secrets {
+		if env.Variables[name] == domain.SecretMask {
+			return true
+		}
+	}
+	return false
+}
+
+// restoreSecrets replaces the secrets of req that were redacted on export
+// with the values saved in existing.
+func restoreSecrets(req, existing *domain.Request) {
+	if authSecret(req.AuthConfig) == domain.SecretMask &&
+		existing.AuthConfig != nil && existing.AuthConfig.Type() == req.AuthConfig.Type() {
+		req.AuthConfig = withAuthSecret(req.AuthConfig, authSecret(existing.AuthConfig))
+	}
+
+	if password, _ := urlPassword(req.URL); password == domain.SecretMask {
+		if saved, ok := urlPassword(existing.URL); ok {
+			parsed, _ := url.Parse(req.URL)
+			parsed.User = url.UserPassword(parsed.User.Username(), saved)
+			req.URL = parsed.String()
+		}
+	}
+
+	req.URL = restoreURLQuery(req.URL, existing.URL)
+	restoreValues(req.Headers, existing.Headers)
+	restoreValues(req.QueryParams, existing.QueryParams)
+	restoreValues(req.DisabledQueryParams, existing.DisabledQueryParams)
+}
+
+// restoreValues replaces the redacted values in values with those saved.
+func restoreValues(values, saved map[string]string) {
+	for name, value := range values {
+		if savedValue, ok := saved[name]; ok && value == domain.SecretMask {
+			values[name] = savedValue
+		}
+	}
+}
+
+// restoreURLQuery returns rawURL with the query parameters redacted on export
+// set to the values of the parameters with the same names in savedURL, in
+// order.
+func restoreURLQuery(rawURL, savedURL string) string {
+	base, params, fragment := splitURLQuery(rawURL)
+	_, savedParams, _ := splitURLQuery(savedURL)
+	saved := make(map[string][]string)
+	for _, param := range savedParams {
+		raw, value, _ := strings.Cut(param, "=")
+		name := unescapeQuery(raw)
+		saved[name] = append(saved[name], value)
+	}
+
+	for i, param := range params {
+		raw, value, found := strings.Cut(param, "=")
+		name := unescapeQuery(raw)
+		if !found || unescapeQuery(value) != domain.SecretMask || len(saved[name]) == 0 {
+			continue
+		}
+		params[i] = raw + "=" + saved[name][0]
+		saved[name] = saved[name][1:]
+	}
+	return joinURLQuery(base, params, fragment)
+}
+
+// hasMaskedURLQuery reports whether the query string of rawURL still holds a
+// value redacted on export.
+func hasMaskedURLQuery(rawURL string) bool {
+	_, params, _ := splitURLQuery(rawURL)
+	for _, param := range params {
+		if _, value, _ := strings.Cut(param, "="); unescapeQuery(value) == domain.SecretMask {
+			return true
+		}
+	}
+	return false
+}
+
+// hasMaskedSecrets reports whether req still holds a secret redacted on
+// export.
+func hasMaskedSecrets(req *domain.Request) bool {
+	password, _ := urlPassword(req.URL)
+	return authSecret(req.AuthConfig) == domain.SecretMask ||
+		password == domain.SecretMask ||
+		hasMaskedURLQuery(req.URL) ||
+		slices.Contains(slices.Collect(maps.Values(req.Headers)), domain.SecretMask) ||
+		slices.Contains(slices.Collect(maps.Values(req.QueryParams)), domain.SecretMask) ||
+		slices.Contains(slices.Collect(maps.Values(req.DisabledQueryParams)), domain.SecretMask)
+}
+
+// authSecret returns the secret of an auth configuration: the Basic password,
+// Bearer token or API key value.
+func authSecret(auth domain.AuthConfig) string {
+	switch a := auth.(type) {
+	case *domain.BasicAuth:
+		return a.Password
+	case *domain.BearerAuth:
+		return a.Token
+	case *domain.APIKeyAuth:
+		return a.Value
+	default:
+		return ""
+	}
+}
+
+// withAuthSecret returns a copy of auth with its secret replaced by secret.
+func withAuthSecret(auth domain.AuthConfig, secret string) domain.AuthConfig {
+	switch a := auth.(type) {
+	case *domain.BasicAuth:
+		return domain.NewBasicAuth(a.Username, secret)
+	case *domain.BearerAuth:
+		return domain.NewBearerAuth(secret)
+	case *domain.APIKeyAuth:
+		return domain.NewAPIKeyAuth(a.Key, secret, a.Location)
+	default:
+		return auth
+	}
+}
+
+// urlPassword returns the password in rawURL and whether it has one.
+func urlPassword(rawURL string) (string, bool) {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || parsed.User == nil {
+		return "", false
+	}
+	return parsed.User.Password()
+}
+
+// sameDefinition reports whether two requests are identical apart from
+// timestamps and execution counters.
+func sameDefinition(a, b *domain.Request) bool {
+	return a.Name == b.Name &&
+		a.Method == b.Method &&
+		a.URL == b.URL &&
+		a.Body == b.Body &&
+		a.ResponseSchema == b.ResponseSchema &&
+		a.ResponseContract == b.ResponseContract &&
+		a.ResponseFilter == b.ResponseFilter &&
+		a.PreRequestScript == b.PreRequestScript &&
+		a.PostResponseScript == b.PostResponseScript &&
+		slices.Equal(domain.NormalizeTags(a.Tags), domain.NormalizeTags(b.Tags)) &&
+		maps.Equal(a.Headers, b.Headers) &&
+		maps.Equal(a.QueryParams, b.QueryParams) &&
+		maps.Equal(a.DisabledQueryParams, b.DisabledQueryParams) &&
+		sameAuth(a.AuthConfig, b.AuthConfig)
+}
+
+// sameAuth compares auth configurations, treating nil as no authentication.
+func sameAuth(a, b domain.AuthConfig) bool {
+	if a == nil {
+		a = domain.NewNoAuth()
+	}
+	if b == nil {
+		b = domain.NewNoAuth()
+	}
+	return reflect.DeepEqual(a, b)
+}
@@ -0,0 +1,71 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/williajm/curly/internal/infrastructure/http"
+	"github.com/williajm/curly/internal/infrastructure/release"
+)
+
+// UpdateStatus is the result of checking for a newer release.
+type UpdateStatus struct {
+	// Current is the running version.
+	Current string
+
+	// Latest is the latest release.
+	Latest *release.Release
+
+	// Available reports whether Latest is newer than Current. It is never
+	// set for development builds.
+	Available bool
+}
+
+// UpdateService checks GitHub for releases newer than the running version.
+type UpdateService struct {
+	httpClient http.Client
+	current    string
+	logger     *slog.Logger
+}
+
+// NewUpdateService creates a new UpdateService that compares releases with
+// current, the running version. The HTTP client is required and must not be
+// nil.
+func NewUpdateService(httpClient http.Client, current string, logger *slog.Logger) *UpdateService {
+	if httpClient == nil {
+		panic("http client cannot be nil")
+	}
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	return &UpdateService{
+		httpClient: httpClient,
+		current:    current,
+		logger:     logger,
+	}
+}
+
+// Check fetches the latest release and reports whether it is newer than the
+// running version.
+func (s *UpdateService) Check(ctx context.Context) (*UpdateStatus, error) {
+	resp, err := s.httpClient.Execute(ctx, release.LatestRequest())
+	if err != nil {
+		s.logger.Warn("update check failed", "error", err)
+		return nil, fmt.Errorf("failed to check for updates: %w", err)
+	}
+	latest, err := release.ParseLatest(resp)
+	if err != nil {
+		s.logger.Warn("update check failed", "error", err)
+		return nil, fmt.Errorf("failed to check for updates: %w", err)
+	}
+
+	status := &UpdateStatus{
+		Current:   s.current,
+		Latest:    latest,
+		Available: release.Newer(latest.Version, s.current),
+	}
+	s.logger.Info("checked for updates", "current", s.current, "latest", latest.Version, "available", status.Available)
+	return status, nil
+}
@@ -0,0 +1,65 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/williajm/curly/internal/domain"
+	"github.com/williajm/curly/internal/infrastructure/release"
+)
+
+const updateTestRelease = `{"tag_name": "v1.4.0", "html_url": "https://github.com/williajm/curly/releases/tag/v1.4.0"}`
+
+func TestNewUpdateService_NilClient(t *testing.T) {
+	assert.Panics(t, func() {
+		NewUpdateService(nil, "v1.3.0", slog.Default())
+	})
+}
+
+func TestUpdateService_Check(t *testing.T) {
+	tests := []struct {
+		current   string
+		available bool
+	}{
+		{"v1.3.0", true},
+		{"v1.4.0", false},
+		{"v1.4.0-2-g1a2b3c4", false},
+		{"dev", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.current, func(t *testing.T) {
+			httpClient := new(MockHTTPClient)
+			service := NewUpdateService(httpClient, tt.current, slog.Default())
+
+			httpClient.On("Execute", mock.Anything, mock.MatchedBy(func(r *domain.Request) bool {
+				return r.URL == release.LatestURL
+			})).Return(&domain.Response{StatusCode: 200, Status: "200 OK", Body: updateTestRelease}, nil).Once()
+
+			status, err := service.Check(context.Background())
+			require.NoError(t, err)
+			assert.Equal(t, tt.current, status.Current)
+			assert.Equal(t, "v1.4.0", status.Latest.Version)
+			assert.Equal(t, "https://github.com/williajm/curly/releases/tag/v1.4.0", status.Latest.URL)
+			assert.Equal(t, tt.available, status.Available)
+			httpClient.AssertExpectations(t)
+		})
+	}
+}
+
+func TestUpdateService_Check_Errors(t *testing.T) {
+	httpClient := new(MockHTTPClient)
+	service := NewUpdateService(httpClient, "v1.3.0", slog.Default())
+
+	httpClient.On("Execute", mock.Anything, mock.Anything).Return(nil, errors.New("dial tcp: no route to host")).Once()
+	_, err := service.Check(context.Background())
+	assert.ErrorContains(t, err, "failed to check for updates: dial tcp: no route to host")
+
+	httpClient.On("Execute", mock.Anything, mock.Anything).Return(&domain.Response{StatusCode: 404, Status: "404 Not Found"}, nil).Once()
+	_, err = service.Check(context.Background())
+	assert.ErrorIs(t, err, release.ErrNoReleases)
+}
@@ -0,0 +1,60 @@
+package app
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/williajm/curly/internal/infrastructure/workspace"
+)
+
+// WorkspaceService lists the available workspaces and reports the active one.
+// Switching is done by reopening storage for another workspace, so the service
+// itself never changes which database is in use.
+type WorkspaceService struct {
+	manager *workspace.Manager
+	current string
+	logger  *slog.Logger
+}
+
+// NewWorkspaceService creates a new WorkspaceService for the active workspace.
+// The manager is required and must not be nil. An empty current name is the default workspace.
+func NewWorkspaceService(manager *workspace.Manager, current string, logger *slog.Logger) *WorkspaceService {
+	if manager == nil {
+		panic("workspace manager cannot be nil")
+	}
+	if logger == nil {
+		logger = slog.Default()
+	}
+	if current == "" {
+		current = workspace.Default
+	}
+
+	return &WorkspaceService{
+		manager: manager,
+		current: current,
+		logger:  logger,
+	}
+}
+
+// Current returns the name of the active workspace.
+func (s *WorkspaceService) Current() string {
+	return s.current
+}
+
+// List returns the names of all workspaces, including the active one even if
+// its database has not been created yet.
+func (s *WorkspaceService) List() ([]string, error) {
+	names, err := s.manager.List()
+	if err != nil {
+		s.logger.Error("failed to list workspaces", "error", err)
+		return nil, fmt.Errorf("failed to list workspaces: %w", err)
+	}
+
+	for _, name := range names {
+		if name == s.current {
+			return names, nil
+		}
+	}
+
+	return append(names, s.current), nil
+}
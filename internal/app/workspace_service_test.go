@@ -0,0 +1,40 @@
+package app
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/williajm/curly/internal/infrastructure/workspace"
+)
+
+func TestNewWorkspaceService_NilManager(t *testing.T) {
+	assert.Panics(t, func() {
+		NewWorkspaceService(nil, "", slog.Default())
+	})
+}
+
+func TestWorkspaceService_Current(t *testing.T) {
+	manager := workspace.NewManager(t.TempDir(), "curly.db")
+
+	assert.Equal(t, workspace.Default, NewWorkspaceService(manager, "", nil).Current())
+	assert.Equal(t, "client-a", NewWorkspaceService(manager, "client-a", nil).Current())
+}
+
+func TestWorkspaceService_List(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "personal.db"), nil, 0600))
+	manager := workspace.NewManager(dir, filepath.Join(dir, "..", "curly.db"))
+
+	names, err := NewWorkspaceService(manager, "", nil).List()
+	require.NoError(t, err)
+	assert.Equal(t, []string{workspace.Default, "personal"}, names)
+
+	// A new workspace is listed before its database exists.
+	names, err = NewWorkspaceService(manager, "client-a", nil).List()
+	require.NoError(t, err)
+	assert.Equal(t, []string{workspace.Default, "personal", "client-a"}, names)
+}
@@ -0,0 +1,132 @@
+package domain
+
+import (
+	"fmt"
+	"mime"
+	"net/url"
+	"path"
+	"strings"
+	"unicode/utf8"
+)
+
+// hexDumpWidth is the number of bytes on each line of a hex dump.
+const hexDumpWidth = 16
+
+// binaryMediaTypes are media types whose bodies are binary, besides the
+// image, audio, video and font types.
+var binaryMediaTypes = map[string]bool{
+	"application/octet-stream": true,
+	"application/pdf":          true,
+	"application/zip":          true,
+	"application/gzip":         true,
+	"application/x-gzip":       true,
+	"application/x-tar":        true,
+	"application/x-protobuf":   true,
+	"application/protobuf":     true,
+	"application/wasm":         true,
+}
+
+// IsBinary reports whether the body is binary rather than text: its
+// Content-Type names a binary type, or it holds NUL bytes or invalid UTF-8.
+func (r *Response) IsBinary() bool {
+	if r.Body == "" {
+		return false
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(r.ContentType())
+	switch {
+	case binaryMediaTypes[mediaType]:
+		return true
+	case mediaType == "image/svg+xml":
+		// SVG images are XML.
+	case strings.HasPrefix(mediaType, "image/"), strings.HasPrefix(mediaType, "audio/"),
+		strings.HasPrefix(mediaType, "video/"), strings.HasPrefix(mediaType, "font/"):
+		return true
+	}
+
+	return strings.IndexByte(r.Body, 0) >= 0 || !utf8.ValidString(r.Body)
+}
+
+// HexDump returns data in the style of xxd: each line holds the offset, 16
+// bytes in hex grouped in pairs, and the bytes as ASCII with "." for bytes
+// that are not printable.
+func HexDump(data string) []string {
+	lines := make([]string, 0, (len(data)+hexDumpWidth-1)/hexDumpWidth)
+	for offset := 0; offset < len(data); offset += hexDumpWidth {
+		chunk := data[offset:min(offset+hexDumpWidth, len(data))]
+
+		var b strings.Builder
+		fmt.Fprintf(&b, "%08x: ", offset)
+		for i := range hexDumpWidth {
+			if i < len(chunk) {
+				fmt.Fprintf(&b, "%02x", chunk[i])
+			} else {
+				b.WriteString("  ")
+			}
+			if i%2 == 1 {
+				b.WriteByte(' ')
+			}
+		}
+		b.WriteByte(' ')
+		for i := range len(chunk) {
+			c := chunk[i]
+			if c < 0x20 || c > 0x7e {
+				c = '.'
+			}
+			b.WriteByte(c)
+		}
+		lines = append(lines, b.String())
+	}
+	return lines
+}
+
+// SuggestedFilename returns a file name to save the body under: the name in
+// the Content-Disposition header, else the last segment of the URL the
+// request was sent to, else "response" with an extension for the
+// Content-Type. Directories are stripped, so the name is safe to join to a
+// directory.
+func (r *Response) SuggestedFilename() string {
+	if _, params, err := mime.ParseMediaType(r.GetHeader("Content-Disposition")); err == nil {
+		if name := safeFilename(params["filename"]); name != "" {
+			return name
+		}
+	}
+
+	if r.Sent != nil {
+		if u, err := url.Parse(r.Sent.URL); err == nil {
+			if name := safeFilename(path.Base(u.Path)); name != "" {
+				return name
+			}
+		}
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(r.ContentType())
+	return "response" + extensionForType(mediaType)
+}
+
+// extensionForType returns the file extension for mediaType, preferring the
+// one named after its subtype (".jpeg" rather than ".jfif"), or "" if it has
+// none.
+func extensionForType(mediaType string) string {
+	exts, err := mime.ExtensionsByType(mediaType)
+	if err != nil || len(exts) == 0 {
+		return ""
+	}
+	_, subtype, _ := strings.Cut(mediaType, "/")
+	for _, ext := range exts {
+		if ext[1:] == subtype {
+			return ext
+		}
+	}
+	return exts[0]
+}
+
+// safeFilename returns the last element of name, or "" if that is not a
+// usable file name.
+func safeFilename(name string) string {
+	name = path.Base(strings.ReplaceAll(name, `\`, "/"))
+	if name == "." || name == ".." || name == "/" {
+		return ""
+	}
+	return name
+}
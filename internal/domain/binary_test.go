@@ -0,0 +1,108 @@
+package domain
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestIsBinary tests telling binary bodies from text.
+func TestIsBinary(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		want        bool
+	}{
+		{name: "json", contentType: contentTypeJSON, body: `{"id":1}`, want: false},
+		{name: "utf-8 text", contentType: "text/plain; charset=utf-8", body: "héllo", want: false},
+		{name: "empty octet stream", contentType: "application/octet-stream", body: "", want: false},
+		{name: "octet stream", contentType: "application/octet-stream", body: "abc", want: true},
+		{name: "png", contentType: "image/png", body: "\x89PNG\r\n", want: true},
+		{name: "svg", contentType: "image/svg+xml", body: "<svg/>", want: false},
+		{name: "nul bytes without content type", body: "a\x00b", want: true},
+		{name: "invalid utf-8 as text", contentType: "text/plain", body: "\xff\xfe", want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := NewResponse()
+			if tt.contentType != "" {
+				resp.Headers["Content-Type"] = tt.contentType
+			}
+			resp.Body = tt.body
+
+			if got := resp.IsBinary(); got != tt.want {
+				t.Errorf("IsBinary() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestHexDump tests the xxd-style dump of a body.
+func TestHexDump(t *testing.T) {
+	got := HexDump("Hello, world!\n\x00\x01\xffAB")
+	want := []string{
+		"00000000: 4865 6c6c 6f2c 2077 6f72 6c64 210a 0001  Hello, world!...",
+		"00000010: ff41 42                                  .AB",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("HexDump() =\n%q\nwant\n%q", got, want)
+	}
+
+	if got := HexDump(""); len(got) != 0 {
+		t.Errorf("HexDump(\"\") = %q, want no lines", got)
+	}
+}
+
+// TestSuggestedFilename tests choosing a file name to save a body under.
+func TestSuggestedFilename(t *testing.T) {
+	tests := []struct {
+		name    string
+		headers map[string]string
+		url     string
+		want    string
+	}{
+		{
+			name:    "content disposition",
+			headers: map[string]string{"Content-Disposition": `attachment; filename="report.pdf"`},
+			url:     "https://example.com/download",
+			want:    "report.pdf",
+		},
+		{
+			name:    "content disposition with directories",
+			headers: map[string]string{"Content-Disposition": `attachment; filename="../../etc/passwd"`},
+			want:    "passwd",
+		},
+		{
+			name: "url path",
+			url:  "https://example.com/images/logo.png?size=2",
+			want: "logo.png",
+		},
+		{
+			name:    "content type",
+			headers: map[string]string{"Content-Type": "image/jpeg"},
+			url:     "https://example.com/",
+			want:    "response.jpeg",
+		},
+		{
+			name: "nothing known",
+			want: "response",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := NewResponse()
+			for k, v := range tt.headers {
+				resp.Headers[k] = v
+			}
+			if tt.url != "" {
+				resp.Sent = NewRequestWithMethodAndURL("GET", tt.url)
+			}
+
+			if got := resp.SuggestedFilename(); got != tt.want {
+				t.Errorf("SuggestedFilename() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
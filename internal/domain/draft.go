@@ -0,0 +1,17 @@
+package domain
+
+import "time"
+
+// Draft is the request builder's unsaved state, kept so that it can be
+// restored if curly exits, or crashes, before the request is saved.
+type Draft struct {
+	// ID identifies the draft. Each TUI session keeps one draft.
+	ID string
+
+	// Request is the request as the builder held it. Its ID is that of the
+	// saved request it changes, if any.
+	Request *Request
+
+	// UpdatedAt is when the draft was last saved.
+	UpdatedAt time.Time
+}
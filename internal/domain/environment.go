@@ -0,0 +1,210 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Sentinel errors for environments.
+var (
+	// ErrEmptyEnvironmentName indicates an environment was given no name.
+	ErrEmptyEnvironmentName = errors.New("environment name cannot be empty")
+
+	// ErrInvalidVariableName indicates a variable name cannot be referenced as {{name}}.
+	ErrInvalidVariableName = errors.New("invalid variable name")
+
+	// ErrUndefinedVariable indicates a request references a variable that has no value.
+	ErrUndefinedVariable = errors.New("undefined variable")
+)
+
+// SecretMask replaces the value of a secret wherever it is shown.
+const SecretMask = "••••••"
+
+// secretPrefix marks a secret in the text form of an environment's variables.
+const secretPrefix = "secret "
+
+// Environment is a named set of variables, such as a base URL and a token,
+// that fill the {{name}} references in a request when it is active.
+type Environment struct {
+	// ID is a unique identifier for this environment.
+	ID string
+
+	// Name is the environment's unique, human-readable name, such as "staging".
+	Name string
+
+	// Variables maps variable names to their values.
+	Variables map[string]string
+
+	// Secrets lists, sorted, the variables whose values are masked when shown.
+	Secrets []string
+
+	// CreatedAt is when this environment was created.
+	CreatedAt time.Time
+
+	// UpdatedAt is when this environment was last modified.
+	UpdatedAt time.Time
+}
+
+// NewEnvironment creates an empty environment with a generated ID.
+func NewEnvironment(name string) *Environment {
+	now := time.Now()
+	return &Environment{
+		ID:        uuid.New().String(),
+		Name:      name,
+		Variables: make(map[string]string),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
+
+// Validate checks that the environment has a name and that every variable
+// name can be referenced as {{name}}.
+func (e *Environment) Validate() error {
+	if strings.TrimSpace(e.Name) == "" {
+		return ErrEmptyEnvironmentName
+	}
+	for name := range e.Variables {
+		if err := ValidateVariableName(name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ValidateVariableName checks that name can be referenced as {{name}}: it
+// starts with a letter or underscore, holds only letters, digits, '_', '.'
+// and '-', and does not start with "fake.", which is kept for fake data.
+func ValidateVariableName(name string) error {
+	if !variableName.MatchString(name) || strings.HasPrefix(name, fakePrefix) {
+		return fmt.Errorf("%w: %q", ErrInvalidVariableName, name)
+	}
+	return nil
+}
+
+// IsSecret reports whether the named variable is a secret.
+func (e *Environment) IsSecret(name string) bool {
+	i := sort.SearchStrings(e.Secrets, name)
+	return i < len(e.Secrets) && e.Secrets[i] == name
+}
+
+// MaskedVariables returns a copy of the variables with the value of every
+// secret replaced by SecretMask, for display.
+func (e *Environment) MaskedVariables() map[string]string {
+	masked := make(map[string]string, len(e.Variables))
+	for name, value := range e.Variables {
+		if e.IsSecret(name) {
+			value = SecretMask
+		}
+		masked[name] = value
+	}
+	return masked
+}
+
+// Set sets a variable's value and whether it is a secret.
+func (e *Environment) Set(name, value string, secret bool) {
+	if e.Variables == nil {
+		e.Variables = make(map[string]string)
+	}
+	e.Variables[name] = value
+
+	secrets := make(map[string]bool, len(e.Secrets)+1)
+	for _, s := range e.Secrets {
+		secrets[s] = true
+	}
+	secrets[name] = secret
+	e.Secrets = nil
+	for s, ok := range secrets {
+		if ok {
+			e.Secrets = append(e.Secrets, s)
+		}
+	}
+	sort.Strings(e.Secrets)
+	e.UpdatedAt = time.Now()
+}
+
+// Unset removes a variable.
+func (e *Environment) Unset(name string) {
+	delete(e.Variables, name)
+	if e.IsSecret(name) {
+		i := sort.SearchStrings(e.Secrets, name)
+		e.Secrets = append(e.Secrets[:i:i], e.Secrets[i+1:]...)
+	}
+	e.UpdatedAt = time.Now()
+}
+
+// FormatVariables returns the variables as NAME=value lines sorted by name,
+// the form ParseVariables reads. Secrets are prefixed with "secret ".
+func (e *Environment) FormatVariables() string {
+	names := make([]string, 0, len(e.Variables))
+	for name := range e.Variables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	for _, name := range names {
+		if e.IsSecret(name) {
+			b.WriteString(secretPrefix)
+		}
+		b.WriteString(name + "=" + e.Variables[name] + "\n")
+	}
+	return b.String()
+}
+
+// ParseVariables replaces the environment's variables with those in text:
+// one NAME=value per line, optionally prefixed with "secret " to mark the
+// variable as a secret. Blank lines and lines starting with '#' are ignored.
+// The environment is left unchanged if any line is invalid.
+func (e *Environment) ParseVariables(text string) error {
+	vars := make(map[string]string)
+	secrets := make(map[string]bool)
+	for i, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		secret := false
+		if rest, ok := strings.CutPrefix(line, secretPrefix); ok {
+			line, secret = strings.TrimSpace(rest), true
+		}
+		name, value, ok := strings.Cut(line, "=")
+		if !ok {
+			return fmt.Errorf("line %d: expected NAME=value", i+1)
+		}
+		name = strings.TrimSpace(name)
+		if err := ValidateVariableName(name); err != nil {
+			return fmt.Errorf("line %d: %w", i+1, err)
+		}
+
+		vars[name] = strings.TrimSpace(value)
+		secrets[name] = secret
+	}
+
+	e.Variables = vars
+	e.Secrets = nil
+	for name, secret := range secrets {
+		if secret {
+			e.Secrets = append(e.Secrets, name)
+		}
+	}
+	sort.Strings(e.Secrets)
+	e.UpdatedAt = time.Now()
+	return nil
+}
+
+// Clone creates a deep copy of the environment.
+func (e *Environment) Clone() *Environment {
+	clone := *e
+	clone.Variables = make(map[string]string, len(e.Variables))
+	for k, v := range e.Variables {
+		clone.Variables[k] = v
+	}
+	clone.Secrets = append([]string(nil), e.Secrets...)
+	return &clone
+}
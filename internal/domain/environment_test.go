@@ -0,0 +1,80 @@
+package domain
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestEnvironment_ParseVariables(t *testing.T) {
+	env := NewEnvironment("staging")
+	text := "# staging\nbaseUrl = https://staging.example.com\n\nsecret token=abc=123\n"
+	if err := env.ParseVariables(text); err != nil {
+		t.Fatalf("ParseVariables() error = %v", err)
+	}
+
+	want := map[string]string{"baseUrl": "https://staging.example.com", "token": "abc=123"}
+	if !reflect.DeepEqual(env.Variables, want) {
+		t.Errorf("Variables = %v, want %v", env.Variables, want)
+	}
+	if !env.IsSecret("token") || env.IsSecret("baseUrl") {
+		t.Errorf("Secrets = %v, want [token]", env.Secrets)
+	}
+
+	formatted := env.FormatVariables()
+	if formatted != "baseUrl=https://staging.example.com\nsecret token=abc=123\n" {
+		t.Errorf("FormatVariables() = %q", formatted)
+	}
+}
+
+func TestEnvironment_ParseVariablesInvalid(t *testing.T) {
+	env := NewEnvironment("staging")
+	env.Set("keep", "me", false)
+
+	for _, text := range []string{"noequals", "bad name=1", "fake.email=x"} {
+		if err := env.ParseVariables(text); err == nil {
+			t.Errorf("ParseVariables(%q) expected an error", text)
+		}
+	}
+	if env.Variables["keep"] != "me" {
+		t.Error("expected the variables to be unchanged after an error")
+	}
+}
+
+func TestEnvironment_SetUnset(t *testing.T) {
+	env := NewEnvironment("dev")
+	env.Set("token", "abc", true)
+	env.Set("host", "localhost", false)
+
+	if !reflect.DeepEqual(env.Secrets, []string{"token"}) {
+		t.Errorf("Secrets = %v, want [token]", env.Secrets)
+	}
+	masked := env.MaskedVariables()
+	if masked["token"] != SecretMask || masked["host"] != "localhost" {
+		t.Errorf("MaskedVariables() = %v", masked)
+	}
+
+	env.Set("token", "abc", false)
+	if env.IsSecret("token") {
+		t.Error("expected token to no longer be a secret")
+	}
+
+	env.Set("token", "abc", true)
+	env.Unset("token")
+	if _, ok := env.Variables["token"]; ok || env.IsSecret("token") {
+		t.Error("expected token to be removed")
+	}
+}
+
+func TestEnvironment_Validate(t *testing.T) {
+	env := NewEnvironment(" ")
+	if err := env.Validate(); !errors.Is(err, ErrEmptyEnvironmentName) {
+		t.Errorf("Validate() error = %v, want ErrEmptyEnvironmentName", err)
+	}
+
+	env.Name = "dev"
+	env.Variables["1bad"] = "x"
+	if err := env.Validate(); !errors.Is(err, ErrInvalidVariableName) {
+		t.Errorf("Validate() error = %v, want ErrInvalidVariableName", err)
+	}
+}
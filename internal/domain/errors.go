@@ -53,3 +53,12 @@ var (
 	// ErrInvalidAPIKeyLocation indicates the API key location is not supported.
 	ErrInvalidAPIKeyLocation = errors.New("invalid API key location (must be 'header' or 'query')")
 )
+
+// Sentinel errors for response extraction.
+var (
+	// ErrInvalidPath indicates an extraction path is not valid JSONPath or jq syntax.
+	ErrInvalidPath = errors.New("invalid extraction path")
+
+	// ErrBodyNotJSON indicates values were extracted from a response whose body is not JSON.
+	ErrBodyNotJSON = errors.New("response body is not JSON")
+)
@@ -0,0 +1,657 @@
+package domain
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Extract selects values from the response's JSON body with a path.
+//
+// Paths use JSONPath or the equivalent jq syntax, which can be mixed:
+//
+//	$.users[0].name     .users[0].name       first user's name
+//	$.users[*].id       .users[].id          every user's id
+//	$..id                                    every id at any depth
+//	$.users[-1]         .users[-1]           last user
+//	$.users[1:3]                             second and third users
+//	$['first name']     .["first name"]      a key that is not a plain name
+//	$.users[?(@.age >= 18 && @.active)]      users matching a filter
+//
+// Filters compare a value under @ with a number, string, true, false or null
+// using ==, !=, <, <=, > or >=, combine comparisons with && and ||, and with
+// no comparison test that the value exists. "$", "." or an empty path select
+// the whole body.
+//
+// It returns every match in document order, with object members in key
+// order; a path that matches nothing returns no values and no error. Numbers
+// are json.Number so that large integers such as IDs keep their digits.
+// It fails with ErrInvalidPath if the path cannot be parsed and with
+// ErrBodyNotJSON if the body is not JSON.
+func (r *Response) Extract(path string) ([]any, error) {
+	segments, err := parsePath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	dec := json.NewDecoder(strings.NewReader(r.Body))
+	dec.UseNumber()
+	var doc any
+	if err := dec.Decode(&doc); err != nil {
+		return nil, ErrBodyNotJSON
+	}
+	if _, err := dec.Token(); !errors.Is(err, io.EOF) {
+		return nil, ErrBodyNotJSON // Trailing content after the JSON value.
+	}
+
+	return evaluate(segments, doc), nil
+}
+
+// ValidateExtractPath checks that path can be used with Extract, so that a
+// bad path is reported before any request is sent.
+func ValidateExtractPath(path string) error {
+	_, err := parsePath(path)
+	return err
+}
+
+// ExtractText is Extract with the matches rendered as text, one per line:
+// strings as they are and other values as indented JSON. It is what commands
+// print and captures store.
+func (r *Response) ExtractText(path string) (string, error) {
+	values, err := r.Extract(path)
+	if err != nil {
+		return "", err
+	}
+	return FormatExtracted(values), nil
+}
+
+// FormatExtracted renders extracted values as ExtractText does.
+func FormatExtracted(values []any) string {
+	lines := make([]string, len(values))
+	for i, v := range values {
+		if s, ok := v.(string); ok {
+			lines[i] = s
+			continue
+		}
+		encoded, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			encoded = []byte(fmt.Sprint(v))
+		}
+		lines[i] = string(encoded)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// segmentKind classifies the steps of a path.
+type segmentKind int
+
+const (
+	// segmentNames selects object members by name.
+	segmentNames segmentKind = iota
+	// segmentWildcard selects every array item or object member.
+	segmentWildcard
+	// segmentIndices selects array items by index; negative ones count from the end.
+	segmentIndices
+	// segmentSlice selects a range of array items.
+	segmentSlice
+	// segmentFilter selects the array items or object members matching a filter.
+	segmentFilter
+)
+
+// segment is one step of a path.
+type segment struct {
+	kind segmentKind
+
+	// recursive applies the step to the current values and all their
+	// descendants, as in $..name.
+	recursive bool
+
+	names   []string
+	indices []int
+	slice   [3]*int // start, end, step
+	filter  filterExpr
+}
+
+// filterExpr is a filter condition, evaluated against a candidate value.
+type filterExpr interface {
+	match(v any) bool
+}
+
+// pathParser parses a path into segments.
+type pathParser struct {
+	src string
+	pos int
+}
+
+// parsePath parses a JSONPath or jq path. Filters parse their @ paths with it too.
+func parsePath(path string) ([]segment, error) {
+	p := &pathParser{src: strings.TrimSpace(path)}
+	segments, err := p.segments("$")
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPath, err)
+	}
+	return segments, nil
+}
+
+// segments parses a path whose optional root marker is root ("$" or "@").
+func (p *pathParser) segments(root string) ([]segment, error) {
+	if strings.HasPrefix(p.src, root) {
+		p.pos = len(root)
+	}
+	if p.src[p.pos:] == "." {
+		return nil, nil // The jq identity.
+	}
+
+	var segments []segment
+	for p.pos < len(p.src) {
+		var (
+			seg segment
+			err error
+		)
+		switch {
+		case strings.HasPrefix(p.src[p.pos:], ".."):
+			p.pos += 2
+			if p.peek() == '[' {
+				seg, err = p.bracket()
+			} else {
+				seg, err = p.name()
+			}
+			seg.recursive = true
+		case p.peek() == '.':
+			p.pos++
+			if p.peek() == '[' {
+				seg, err = p.bracket()
+			} else {
+				seg, err = p.name()
+			}
+		case p.peek() == '[':
+			seg, err = p.bracket()
+		case p.pos == 0:
+			seg, err = p.name() // A path such as "data.items" without a root.
+		default:
+			err = fmt.Errorf("unexpected %q at offset %d", p.src[p.pos:p.pos+1], p.pos)
+		}
+		if err != nil {
+			return nil, err
+		}
+		segments = append(segments, seg)
+	}
+	return segments, nil
+}
+
+func (p *pathParser) peek() byte {
+	if p.pos < len(p.src) {
+		return p.src[p.pos]
+	}
+	return 0
+}
+
+// name parses a member name or "*" after a dot.
+func (p *pathParser) name() (segment, error) {
+	if p.peek() == '*' {
+		p.pos++
+		return segment{kind: segmentWildcard}, nil
+	}
+	start := p.pos
+	for _, r := range p.src[p.pos:] {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' && r != '-' {
+			break
+		}
+		p.pos += len(string(r))
+	}
+	if p.pos == start {
+		return segment{}, fmt.Errorf("expected a name at offset %d", start)
+	}
+	return segment{kind: segmentNames, names: []string{p.src[start:p.pos]}}, nil
+}
+
+// bracket parses a [...] step.
+func (p *pathParser) bracket() (segment, error) {
+	open := p.pos
+	end, err := closingBracket(p.src, open)
+	if err != nil {
+		return segment{}, err
+	}
+	inner := strings.TrimSpace(p.src[open+1 : end])
+	p.pos = end + 1
+
+	switch {
+	case inner == "" || inner == "*":
+		return segment{kind: segmentWildcard}, nil
+	case inner[0] == '?':
+		filter, err := parseFilter(inner[1:])
+		if err != nil {
+			return segment{}, err
+		}
+		return segment{kind: segmentFilter, filter: filter}, nil
+	case inner[0] == '\'' || inner[0] == '"':
+		return parseNames(inner)
+	case strings.Contains(inner, ":"):
+		return parseSlice(inner)
+	default:
+		return parseIndices(inner)
+	}
+}
+
+// parseNames parses the quoted member names of a ['a','b'] step.
+func parseNames(inner string) (segment, error) {
+	var names []string
+	for _, part := range splitOutsideQuotes(inner, ",") {
+		name, err := unquote(strings.TrimSpace(part))
+		if err != nil {
+			return segment{}, err
+		}
+		names = append(names, name)
+	}
+	return segment{kind: segmentNames, names: names}, nil
+}
+
+// parseSlice parses a [start:end:step] step, any part of which may be left out.
+func parseSlice(inner string) (segment, error) {
+	parts := strings.Split(inner, ":")
+	if len(parts) > 3 {
+		return segment{}, fmt.Errorf("invalid slice [%s]", inner)
+	}
+	seg := segment{kind: segmentSlice}
+	for i, part := range parts {
+		if part = strings.TrimSpace(part); part == "" {
+			continue
+		}
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			return segment{}, fmt.Errorf("invalid slice [%s]", inner)
+		}
+		seg.slice[i] = &n
+	}
+	if seg.slice[2] != nil && *seg.slice[2] == 0 {
+		return segment{}, fmt.Errorf("slice step cannot be zero")
+	}
+	return seg, nil
+}
+
+// parseIndices parses a [0,-1] step.
+func parseIndices(inner string) (segment, error) {
+	seg := segment{kind: segmentIndices}
+	for _, part := range strings.Split(inner, ",") {
+		n, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil {
+			return segment{}, fmt.Errorf("invalid index [%s]", inner)
+		}
+		seg.indices = append(seg.indices, n)
+	}
+	return seg, nil
+}
+
+// closingBracket finds the ] matching the [ at open, skipping quoted text
+// and nested brackets.
+func closingBracket(src string, open int) (int, error) {
+	depth := 0
+	var quote byte
+	for i := open; i < len(src); i++ {
+		c := src[i]
+		switch {
+		case quote != 0:
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == '[':
+			depth++
+		case c == ']':
+			if depth--; depth == 0 {
+				return i, nil
+			}
+		}
+	}
+	return 0, fmt.Errorf("unclosed [ at offset %d", open)
+}
+
+// splitOutsideQuotes splits s around sep where sep is not quoted.
+func splitOutsideQuotes(s, sep string) []string {
+	var (
+		parts []string
+		quote byte
+		start int
+	)
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote != 0:
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case strings.HasPrefix(s[i:], sep):
+			parts = append(parts, s[start:i])
+			start = i + len(sep)
+			i += len(sep) - 1
+		}
+	}
+	return append(parts, s[start:])
+}
+
+// unquote decodes a single- or double-quoted string.
+func unquote(s string) (string, error) {
+	if len(s) < 2 || s[0] != s[len(s)-1] || (s[0] != '\'' && s[0] != '"') {
+		return "", fmt.Errorf("invalid quoted name %s", s)
+	}
+	if s[0] == '\'' {
+		inner := strings.ReplaceAll(s[1:len(s)-1], `\'`, `'`)
+		s = strconv.Quote(inner)
+	}
+	name, err := strconv.Unquote(s)
+	if err != nil {
+		return "", fmt.Errorf("invalid quoted name %s", s)
+	}
+	return name, nil
+}
+
+// filterOr matches if any of its terms does.
+type filterOr []filterExpr
+
+func (f filterOr) match(v any) bool {
+	for _, term := range f {
+		if term.match(v) {
+			return true
+		}
+	}
+	return false
+}
+
+// filterAnd matches if all of its terms do.
+type filterAnd []filterExpr
+
+func (f filterAnd) match(v any) bool {
+	for _, term := range f {
+		if !term.match(v) {
+			return false
+		}
+	}
+	return true
+}
+
+// filterComparison compares the value at an @ path with a literal. Without
+// an operator it tests that the path exists.
+type filterComparison struct {
+	path    []segment
+	op      string
+	literal any
+}
+
+// filterOperators lists the comparison operators, longest first so that <=
+// is not read as <.
+var filterOperators = []string{"==", "!=", "<=", ">=", "<", ">"}
+
+// parseFilter parses a filter after its "?", with or without parentheses.
+func parseFilter(src string) (filterExpr, error) {
+	src = strings.TrimSpace(src)
+	if strings.HasPrefix(src, "(") && strings.HasSuffix(src, ")") {
+		src = src[1 : len(src)-1]
+	}
+
+	var or filterOr
+	for _, alternative := range splitOutsideQuotes(src, "||") {
+		var and filterAnd
+		for _, term := range splitOutsideQuotes(alternative, "&&") {
+			cmp, err := parseComparison(strings.TrimSpace(term))
+			if err != nil {
+				return nil, err
+			}
+			and = append(and, cmp)
+		}
+		or = append(or, and)
+	}
+	return or, nil
+}
+
+// parseComparison parses "@.path", or "@.path OP literal".
+func parseComparison(term string) (*filterComparison, error) {
+	if !strings.HasPrefix(term, "@") {
+		return nil, fmt.Errorf("filter %q must start with @", term)
+	}
+
+	left, op, right := term, "", ""
+	for _, candidate := range filterOperators {
+		if parts := splitOutsideQuotes(term, candidate); len(parts) == 2 {
+			left, op, right = strings.TrimSpace(parts[0]), candidate, strings.TrimSpace(parts[1])
+			break
+		}
+	}
+
+	p := &pathParser{src: left}
+	path, err := p.segments("@")
+	if err != nil {
+		return nil, err
+	}
+	cmp := &filterComparison{path: path, op: op}
+	if op == "" {
+		return cmp, nil
+	}
+
+	if strings.HasPrefix(right, "'") {
+		if cmp.literal, err = unquote(right); err != nil {
+			return nil, err
+		}
+		return cmp, nil
+	}
+	dec := json.NewDecoder(strings.NewReader(right))
+	dec.UseNumber()
+	if err := dec.Decode(&cmp.literal); err != nil || right == "" {
+		return nil, fmt.Errorf("invalid filter value %q", right)
+	}
+	return cmp, nil
+}
+
+func (f *filterComparison) match(v any) bool {
+	values := evaluate(f.path, v)
+	if f.op == "" {
+		return len(values) > 0
+	}
+	if len(values) == 0 {
+		return f.op == "!="
+	}
+
+	left, right := values[0], f.literal
+	switch f.op {
+	case "==":
+		return equalValues(left, right)
+	case "!=":
+		return !equalValues(left, right)
+	}
+
+	c, ok := compareValues(left, right)
+	if !ok {
+		return false
+	}
+	switch f.op {
+	case "<":
+		return c < 0
+	case "<=":
+		return c <= 0
+	case ">":
+		return c > 0
+	default:
+		return c >= 0
+	}
+}
+
+// equalValues compares two decoded JSON values, numbers by value.
+func equalValues(a, b any) bool {
+	if c, ok := compareValues(a, b); ok {
+		return c == 0
+	}
+	ja, errA := json.Marshal(a)
+	jb, errB := json.Marshal(b)
+	return errA == nil && errB == nil && bytes.Equal(ja, jb)
+}
+
+// compareValues orders two numbers or two strings. ok is false for other values.
+func compareValues(a, b any) (int, bool) {
+	if na, ok := a.(json.Number); ok {
+		if nb, ok := b.(json.Number); ok {
+			fa, errA := na.Float64()
+			fb, errB := nb.Float64()
+			if errA != nil || errB != nil {
+				return 0, false
+			}
+			switch {
+			case fa < fb:
+				return -1, true
+			case fa > fb:
+				return 1, true
+			default:
+				return 0, true
+			}
+		}
+	}
+	if sa, ok := a.(string); ok {
+		if sb, ok := b.(string); ok {
+			return strings.Compare(sa, sb), true
+		}
+	}
+	return 0, false
+}
+
+// evaluate applies segments to doc and returns the matches.
+func evaluate(segments []segment, doc any) []any {
+	nodes := []any{doc}
+	for _, seg := range segments {
+		var next []any
+		for _, node := range nodes {
+			if seg.recursive {
+				for _, n := range descendants(node, nil) {
+					next = seg.apply(n, next)
+				}
+			} else {
+				next = seg.apply(node, next)
+			}
+		}
+		nodes = next
+	}
+	if nodes == nil {
+		return []any{}
+	}
+	return nodes
+}
+
+// apply appends the values the segment selects from node to out.
+func (s segment) apply(node any, out []any) []any {
+	switch s.kind {
+	case segmentNames:
+		if obj, ok := node.(map[string]any); ok {
+			for _, name := range s.names {
+				if v, ok := obj[name]; ok {
+					out = append(out, v)
+				}
+			}
+		}
+	case segmentWildcard:
+		out = append(out, children(node)...)
+	case segmentIndices:
+		if arr, ok := node.([]any); ok {
+			out = append(out, s.indicesOf(arr)...)
+		}
+	case segmentSlice:
+		if arr, ok := node.([]any); ok {
+			out = append(out, s.sliceOf(arr)...)
+		}
+	case segmentFilter:
+		for _, child := range children(node) {
+			if s.filter.match(child) {
+				out = append(out, child)
+			}
+		}
+	}
+	return out
+}
+
+// indicesOf returns the items of arr at the segment's indices, counting
+// negative indices from the end and skipping those out of range.
+func (s segment) indicesOf(arr []any) []any {
+	var out []any
+	for _, i := range s.indices {
+		if i < 0 {
+			i += len(arr)
+		}
+		if i >= 0 && i < len(arr) {
+			out = append(out, arr[i])
+		}
+	}
+	return out
+}
+
+// sliceOf applies a [start:end:step] slice to arr, with Python semantics.
+func (s segment) sliceOf(arr []any) []any {
+	n := len(arr)
+	step := 1
+	if s.slice[2] != nil {
+		step = *s.slice[2]
+	}
+	bound := func(p *int, def int) int {
+		if p == nil {
+			return def
+		}
+		i := *p
+		if i < 0 {
+			i += n
+		}
+		if step > 0 {
+			return min(max(i, 0), n)
+		}
+		return min(max(i, -1), n-1)
+	}
+
+	var out []any
+	if step > 0 {
+		for i := bound(s.slice[0], 0); i < bound(s.slice[1], n); i += step {
+			out = append(out, arr[i])
+		}
+	} else {
+		for i := bound(s.slice[0], n-1); i > bound(s.slice[1], -1); i += step {
+			out = append(out, arr[i])
+		}
+	}
+	return out
+}
+
+// children returns an array's items or an object's members in key order.
+func children(node any) []any {
+	switch v := node.(type) {
+	case []any:
+		return v
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		out := make([]any, len(keys))
+		for i, k := range keys {
+			out[i] = v[k]
+		}
+		return out
+	default:
+		return nil
+	}
+}
+
+// descendants appends node and everything nested in it, depth first, to out.
+func descendants(node any, out []any) []any {
+	out = append(out, node)
+	for _, child := range children(node) {
+		out = descendants(child, out)
+	}
+	return out
+}
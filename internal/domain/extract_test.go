@@ -0,0 +1,125 @@
+package domain
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+const extractBody = `{
+  "total": 3,
+  "next": null,
+  "users": [
+    {"id": 9007199254740993, "name": "Ada", "age": 36, "active": true, "tags": ["admin"]},
+    {"id": 2, "name": "Alan", "age": 41, "active": false},
+    {"id": 3, "name": "Grace", "age": 17, "address": {"city": "Arlington"}}
+  ],
+  "first name": "Ada"
+}`
+
+// extracted encodes extract results as compact JSON for comparison.
+func extracted(t *testing.T, resp *Response, path string) string {
+	t.Helper()
+	values, err := resp.Extract(path)
+	if err != nil {
+		t.Fatalf("Extract(%q) error = %v", path, err)
+	}
+	encoded, err := json.Marshal(values)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(encoded)
+}
+
+func TestExtract(t *testing.T) {
+	resp := &Response{Body: extractBody}
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{path: "$.total", want: `[3]`},
+		{path: ".total", want: `[3]`},
+		{path: "total", want: `[3]`},
+		{path: "$.next", want: `[null]`},
+		{path: "$.users[0].name", want: `["Ada"]`},
+		{path: ".users[0].name", want: `["Ada"]`},
+		{path: "$.users[0].id", want: `[9007199254740993]`},
+		{path: "$.users[-1].name", want: `["Grace"]`},
+		{path: "$.users[0,2].name", want: `["Ada","Grace"]`},
+		{path: "$.users[*].name", want: `["Ada","Alan","Grace"]`},
+		{path: ".users[].name", want: `["Ada","Alan","Grace"]`},
+		{path: "$.users[1:].id", want: `[2,3]`},
+		{path: "$.users[:1].id", want: `[9007199254740993]`},
+		{path: "$.users[::-1].id", want: `[3,2,9007199254740993]`},
+		{path: "$['first name']", want: `["Ada"]`},
+		{path: `.["first name"]`, want: `["Ada"]`},
+		{path: "$..city", want: `["Arlington"]`},
+		{path: "$.users[0].tags[*]", want: `["admin"]`},
+		{path: "$.users[?(@.age >= 18)].name", want: `["Ada","Alan"]`},
+		{path: "$.users[?@.age < 18].name", want: `["Grace"]`},
+		{path: "$.users[?(@.name == 'Alan')].id", want: `[2]`},
+		{path: `$.users[?(@.name != "Ada")].id`, want: `[2,3]`},
+		{path: "$.users[?(@.active == true && @.age > 30)].name", want: `["Ada"]`},
+		{path: "$.users[?(@.address || @.tags)].name", want: `["Ada","Grace"]`},
+		{path: "$.missing", want: `[]`},
+		{path: "$.users[5]", want: `[]`},
+		{path: "$.total.deeper", want: `[]`},
+	}
+	for _, tt := range tests {
+		if got := extracted(t, resp, tt.path); got != tt.want {
+			t.Errorf("Extract(%q) = %s, want %s", tt.path, got, tt.want)
+		}
+	}
+
+	for _, path := range []string{"", "$", "."} {
+		values, err := resp.Extract(path)
+		if err != nil || len(values) != 1 {
+			t.Errorf("Extract(%q) = %v, %v; want the whole body", path, values, err)
+		}
+	}
+}
+
+func TestExtract_Errors(t *testing.T) {
+	resp := &Response{Body: extractBody}
+	for _, path := range []string{"$.users[", "$.users[x]", "$.users[1:2:0]", "$.users[?(.age > 1)]", "$.users[?(@.age > )]", "$ users", "$.['unclosed]"} {
+		if _, err := resp.Extract(path); !errors.Is(err, ErrInvalidPath) {
+			t.Errorf("Extract(%q) error = %v, want ErrInvalidPath", path, err)
+		}
+	}
+
+	for _, body := range []string{"<html>", "", `{"a": 1} trailing`} {
+		resp := &Response{Body: body}
+		if _, err := resp.Extract("$.a"); !errors.Is(err, ErrBodyNotJSON) {
+			t.Errorf("Extract on %q error = %v, want ErrBodyNotJSON", body, err)
+		}
+	}
+}
+
+func TestExtractText(t *testing.T) {
+	resp := &Response{Body: extractBody}
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{path: "$.users[*].name", want: "Ada\nAlan\nGrace"},
+		{path: "$.users[2].address", want: "{\n  \"city\": \"Arlington\"\n}"},
+		{path: "$.users[0].active", want: "true"},
+		{path: "$.missing", want: ""},
+	}
+	for _, tt := range tests {
+		got, err := resp.ExtractText(tt.path)
+		if err != nil {
+			t.Fatalf("ExtractText(%q) error = %v", tt.path, err)
+		}
+		if got != tt.want {
+			t.Errorf("ExtractText(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+
+	if got := FormatExtracted([]any{"a", json.Number("1")}); !reflect.DeepEqual(got, "a\n1") {
+		t.Errorf("FormatExtracted() = %q", got)
+	}
+}
@@ -0,0 +1,88 @@
+package domain
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"io"
+	"strings"
+)
+
+// FormattedBody returns the body pretty-printed for reading: JSON and XML
+// bodies are indented by two spaces. It reports false, returning the body
+// unchanged, when the body is neither or cannot be parsed. Body itself is
+// never modified, so copies and saves keep the bytes as received.
+func (r *Response) FormattedBody() (string, bool) {
+	trimmed := strings.TrimSpace(r.Body)
+	if trimmed == "" {
+		return r.Body, false
+	}
+
+	if r.IsJSON() || json.Valid([]byte(trimmed)) {
+		var buf bytes.Buffer
+		if err := json.Indent(&buf, []byte(trimmed), "", "  "); err == nil {
+			return buf.String(), true
+		}
+	}
+	if r.IsXML() || strings.HasPrefix(trimmed, "<?xml") {
+		if formatted, err := indentXML(trimmed); err == nil {
+			return formatted, true
+		}
+	}
+	return r.Body, false
+}
+
+// indentXML re-encodes an XML document with one element per line. Namespace
+// prefixes are kept as written rather than resolved.
+func indentXML(body string) (string, error) {
+	dec := xml.NewDecoder(strings.NewReader(body))
+	var buf bytes.Buffer
+	enc := xml.NewEncoder(&buf)
+	enc.Indent("", "  ")
+
+	for {
+		tok, err := dec.RawToken()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return "", err
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			t.Name = prefixedName(t.Name)
+			attrs := make([]xml.Attr, len(t.Attr))
+			for i, attr := range t.Attr {
+				attrs[i] = xml.Attr{Name: prefixedName(attr.Name), Value: attr.Value}
+			}
+			t.Attr = attrs
+			tok = t
+		case xml.EndElement:
+			t.Name = prefixedName(t.Name)
+			tok = t
+		case xml.CharData:
+			// Whitespace between elements is replaced by the indentation.
+			if len(bytes.TrimSpace(t)) == 0 {
+				continue
+			}
+		}
+		if err := enc.EncodeToken(xml.CopyToken(tok)); err != nil {
+			return "", err
+		}
+	}
+	if err := enc.Flush(); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// prefixedName folds a raw token's namespace prefix into its local name, so
+// the encoder writes it back unchanged.
+func prefixedName(name xml.Name) xml.Name {
+	if name.Space == "" {
+		return name
+	}
+	return xml.Name{Local: name.Space + ":" + name.Local}
+}
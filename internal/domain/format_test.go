@@ -0,0 +1,77 @@
+package domain
+
+import "testing"
+
+// TestFormattedBody tests pretty-printing of JSON and XML bodies.
+func TestFormattedBody(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		want        string
+		wantOK      bool
+	}{
+		{
+			name:        "json",
+			contentType: contentTypeJSON,
+			body:        `{"id":1,"tags":["a","b"]}`,
+			want:        "{\n  \"id\": 1,\n  \"tags\": [\n    \"a\",\n    \"b\"\n  ]\n}",
+			wantOK:      true,
+		},
+		{
+			name:   "json without content type",
+			body:   " [1,2] \n",
+			want:   "[\n  1,\n  2\n]",
+			wantOK: true,
+		},
+		{
+			name:        "xml",
+			contentType: "application/xml",
+			body:        `<users><user id="1">Ada &amp; co</user></users>`,
+			want:        "<users>\n  <user id=\"1\">Ada &amp; co</user>\n</users>",
+			wantOK:      true,
+		},
+		{
+			name:        "xml with namespaces",
+			contentType: "text/xml",
+			body:        "<soap:Envelope xmlns:soap=\"urn:s\">\n<soap:Body/></soap:Envelope>",
+			want:        "<soap:Envelope xmlns:soap=\"urn:s\">\n  <soap:Body></soap:Body>\n</soap:Envelope>",
+			wantOK:      true,
+		},
+		{
+			name:        "invalid json",
+			contentType: contentTypeJSON,
+			body:        `{"id":`,
+			want:        `{"id":`,
+		},
+		{
+			name:        "plain text",
+			contentType: "text/plain",
+			body:        "hello",
+			want:        "hello",
+		},
+		{
+			name: "empty",
+			body: "",
+			want: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := NewResponse()
+			if tt.contentType != "" {
+				resp.Headers["Content-Type"] = tt.contentType
+			}
+			resp.Body = tt.body
+
+			got, ok := resp.FormattedBody()
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("FormattedBody() = %q, %v, want %q, %v", got, ok, tt.want, tt.wantOK)
+			}
+			if resp.Body != tt.body {
+				t.Errorf("Body changed to %q", resp.Body)
+			}
+		})
+	}
+}
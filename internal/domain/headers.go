@@ -0,0 +1,121 @@
+package domain
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// CommonHeaders lists request headers commonly set by hand, offered as
+// completions in the header editor.
+var CommonHeaders = []string{
+	"Accept",
+	"Accept-Charset",
+	"Accept-Encoding",
+	"Accept-Language",
+	"Authorization",
+	"Cache-Control",
+	"Connection",
+	"Content-Encoding",
+	"Content-Language",
+	"Content-Length",
+	"Content-Type",
+	"Cookie",
+	"DNT",
+	"Expect",
+	"Forwarded",
+	"From",
+	"Host",
+	"If-Match",
+	"If-Modified-Since",
+	"If-None-Match",
+	"If-Range",
+	"If-Unmodified-Since",
+	"Origin",
+	"Pragma",
+	"Prefer",
+	"Proxy-Authorization",
+	"Range",
+	"Referer",
+	"TE",
+	"User-Agent",
+	"X-API-Key",
+	"X-Correlation-ID",
+	"X-Forwarded-For",
+	"X-Forwarded-Host",
+	"X-Forwarded-Proto",
+	"X-Request-ID",
+	"X-Requested-With",
+}
+
+// mediaTypes are common media types, for the headers that take them.
+var mediaTypes = []string{
+	"application/json",
+	"application/xml",
+	"application/x-www-form-urlencoded",
+	"multipart/form-data",
+	"text/plain",
+	"text/html",
+	"text/csv",
+	"application/octet-stream",
+	"application/graphql-response+json",
+	"application/problem+json",
+	"application/ld+json",
+	"application/vnd.api+json",
+	"application/x-ndjson",
+	"application/pdf",
+	"image/png",
+	"image/jpeg",
+}
+
+// commonHeaderValues lists common values of headers, by canonical name.
+var commonHeaderValues = map[string][]string{
+	"Accept":           append([]string{"*/*"}, mediaTypes...),
+	"Accept-Encoding":  {"gzip, deflate, br", "gzip", "deflate", "br", "identity"},
+	"Accept-Language":  {"en-US,en;q=0.9", "en"},
+	"Authorization":    {"Bearer ", "Basic "},
+	"Cache-Control":    {"no-cache", "no-store", "max-age=0", "must-revalidate", "private", "public"},
+	"Connection":       {"keep-alive", "close"},
+	"Content-Encoding": {"gzip", "deflate", "br", "identity"},
+	"Content-Type":     mediaTypes,
+	"Dnt":              {"1"},
+	"Expect":           {"100-continue"},
+	"Pragma":           {"no-cache"},
+	"Prefer":           {"return=minimal", "return=representation", "respond-async"},
+	"Te":               {"trailers"},
+	"X-Requested-With": {"XMLHttpRequest"},
+}
+
+// CommonHeaderValues returns common values of the header name, ignoring its
+// case, or nil if none are known.
+func CommonHeaderValues(name string) []string {
+	return commonHeaderValues[http.CanonicalHeaderKey(name)]
+}
+
+// secretWords mark header and query parameter names whose values are treated
+// as secrets. Names are compared lowercased with '-' and '_' removed.
+var secretWords = []string{
+	"authorization", "token", "secret", "password", "passwd", "apikey",
+	"accesskey", "signature", "session", "cookie", "credential",
+}
+
+// secretNames are short names that are secrets on their own but too common
+// as parts of other names to match as words.
+var secretNames = []string{"key", "sig", "auth", "code"}
+
+// IsSecretName reports whether a header or query parameter name suggests its
+// value is a credential, such as "Authorization", "X-API-Key" or
+// "access_token". Such values are left out of share links, synced files and
+// logs, and are not offered for reuse.
+func IsSecretName(name string) bool {
+	normalized := strings.NewReplacer("-", "", "_", "").Replace(strings.ToLower(name))
+	if slices.Contains(secretNames, normalized) {
+		return true
+	}
+	for _, word := range secretWords {
+		if strings.Contains(normalized, word) {
+			return true
+		}
+	}
+	return false
+}
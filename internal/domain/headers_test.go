@@ -0,0 +1,56 @@
+package domain
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestCommonHeaderValues tests looking up common header values by name.
+func TestCommonHeaderValues(t *testing.T) {
+	if got := CommonHeaderValues("content-type"); !reflect.DeepEqual(got, mediaTypes) {
+		t.Errorf("CommonHeaderValues(content-type) = %v, want the media types", got)
+	}
+	if got := CommonHeaderValues("X-Unknown"); got != nil {
+		t.Errorf("CommonHeaderValues(X-Unknown) = %v, want nil", got)
+	}
+	for _, name := range CommonHeaders {
+		if err := ValidateHeaderName(name); err != nil {
+			t.Errorf("common header %q is invalid: %v", name, err)
+		}
+	}
+}
+
+// TestIsSecretName tests recognizing headers and query parameters that carry
+// credentials.
+func TestIsSecretName(t *testing.T) {
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{name: "Authorization", want: true},
+		{name: "cookie", want: true},
+		{name: "X-API-Key", want: true},
+		{name: "X-Auth-Token", want: true},
+		{name: "Proxy-Authorization", want: true},
+		{name: "api_key", want: true},
+		{name: "access_token", want: true},
+		{name: "client_secret", want: true},
+		{name: "key", want: true},
+		{name: "sig", want: true},
+		{name: "X-Amz-Signature", want: true},
+		{name: "Content-Type", want: false},
+		{name: "X-Tenant", want: false},
+		{name: "page", want: false},
+		{name: "keyword", want: false},
+		{name: "monkey", want: false},
+		{name: "X-Request-ID", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsSecretName(tt.name); got != tt.want {
+				t.Errorf("IsSecretName(%q) = %v, want %v", tt.name, got, tt.want)
+			}
+		})
+	}
+}
@@ -1,7 +1,10 @@
 package domain
 
 import (
+	"errors"
+	"fmt"
 	"net/url"
+	"sort"
 	"strings"
 	"time"
 
@@ -66,6 +69,10 @@ type Request struct {
 	// Keys are parameter names, values are parameter values.
 	QueryParams map[string]string
 
+	// DisabledQueryParams are query parameters turned off in the request
+	// builder. They are kept with the request but not sent.
+	DisabledQueryParams map[string]string
+
 	// Body is the request body content.
 	// For JSON requests, this should be the JSON string.
 	Body string
@@ -79,6 +86,44 @@ type Request struct {
 
 	// UpdatedAt is the timestamp when this request was last modified.
 	UpdatedAt time.Time
+
+	// LastExecutedAt is when this saved request was last executed (zero if never).
+	LastExecutedAt time.Time
+
+	// ExecutionCount is how many times this saved request has been executed.
+	ExecutionCount int64
+
+	// Folder groups saved requests into a collection ("" is the top level).
+	Folder string
+
+	// Position is the 0-based order of this request within its folder.
+	Position int
+
+	// ResponseSchema is a JSON Schema the response body is validated against
+	// after execution ("" for none).
+	ResponseSchema string
+
+	// ResponseContract describes the responses the request's OpenAPI operation
+	// documents, encoded by the openapi package ("" for none). Executions are
+	// checked against it.
+	ResponseContract string
+
+	// ResponseFilter is the JSONPath or jq path last applied to the response
+	// in the response view ("" for the whole body). See Response.Extract.
+	ResponseFilter string
+
+	// PreRequestScript is JavaScript run before the request is sent, after
+	// its variables are resolved, which can change the request and set
+	// variables ("" for none).
+	PreRequestScript string
+
+	// PostResponseScript is JavaScript run once the response is received,
+	// which can assert on the response and set variables ("" for none).
+	PostResponseScript string
+
+	// Tags label the request for filtering and bulk actions. They are kept
+	// sorted and unique; see NormalizeTags.
+	Tags []string
 }
 
 // NewRequest creates a new Request with default values.
@@ -154,24 +199,37 @@ func (r *Request) ValidateMethod() error {
 }
 
 // ValidateURL checks if the URL is valid and has a supported scheme.
+// A URL with {{name}} variable references, such as "{{baseUrl}}/users", is
+// only checked once its variables are resolved.
 func (r *Request) ValidateURL() error {
 	if strings.TrimSpace(r.URL) == "" {
 		return ErrEmptyURL
 	}
+	if HasVariables(r.URL) {
+		return nil
+	}
 
 	parsed, err := url.Parse(r.URL)
 	if err != nil {
-		return ErrInvalidURL
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return fmt.Errorf("%w: %v", ErrInvalidURL, err)
 	}
 
 	// Ensure the URL has a scheme (http or https).
-	if parsed.Scheme != "http" && parsed.Scheme != "https" {
-		return ErrInvalidURL
+	switch parsed.Scheme {
+	case "http", "https":
+	case "":
+		return fmt.Errorf("%w: missing http:// or https://", ErrInvalidURL)
+	default:
+		return fmt.Errorf("%w: scheme %q is not http or https", ErrInvalidURL, parsed.Scheme)
 	}
 
 	// Ensure the URL has a host.
 	if parsed.Host == "" {
-		return ErrInvalidURL
+		return fmt.Errorf("%w: missing host", ErrInvalidURL)
 	}
 
 	return nil
@@ -181,17 +239,44 @@ func (r *Request) ValidateURL() error {
 // Header names cannot be empty or contain invalid characters.
 func (r *Request) ValidateHeaders() error {
 	for name := range r.Headers {
-		if strings.TrimSpace(name) == "" {
-			return ErrInvalidHeaderName
-		}
-		// Basic validation: header names should not contain colons, spaces, or newlines.
-		if strings.ContainsAny(name, ":\n\r") {
-			return ErrInvalidHeaderName
+		if err := ValidateHeaderName(name); err != nil {
+			return err
 		}
 	}
 	return nil
 }
 
+// ValidateHeaderName checks that a header name is not empty and contains no
+// colons or newlines.
+func ValidateHeaderName(name string) error {
+	if strings.TrimSpace(name) == "" {
+		return ErrInvalidHeaderName
+	}
+	if strings.ContainsAny(name, ":\n\r") {
+		return ErrInvalidHeaderName
+	}
+	return nil
+}
+
+// FullURL returns the URL with QueryParams merged into its query string.
+// A parameter already in the URL is replaced by the one in QueryParams.
+func (r *Request) FullURL() (string, error) {
+	parsed, err := url.Parse(r.URL)
+	if err != nil {
+		return "", ErrInvalidURL
+	}
+	if len(r.QueryParams) == 0 {
+		return parsed.String(), nil
+	}
+
+	query := parsed.Query()
+	for key, value := range r.QueryParams {
+		query.Set(key, value)
+	}
+	parsed.RawQuery = query.Encode()
+	return parsed.String(), nil
+}
+
 // ValidateQueryParams checks if all query parameter names are valid.
 func (r *Request) ValidateQueryParams() error {
 	for name := range r.QueryParams {
@@ -248,6 +333,22 @@ func (r *Request) Clone() *Request {
 		UpdatedAt:   r.UpdatedAt,
 		Headers:     make(map[string]string),
 		QueryParams: make(map[string]string),
+
+		LastExecutedAt: r.LastExecutedAt,
+		ExecutionCount: r.ExecutionCount,
+		Folder:         r.Folder,
+		Position:       r.Position,
+		ResponseSchema: r.ResponseSchema,
+
+		ResponseContract: r.ResponseContract,
+		ResponseFilter:   r.ResponseFilter,
+
+		PreRequestScript:   r.PreRequestScript,
+		PostResponseScript: r.PostResponseScript,
+	}
+
+	if r.Tags != nil {
+		clone.Tags = append([]string{}, r.Tags...)
 	}
 
 	// Deep copy maps.
@@ -257,10 +358,43 @@ func (r *Request) Clone() *Request {
 	for k, v := range r.QueryParams {
 		clone.QueryParams[k] = v
 	}
+	if r.DisabledQueryParams != nil {
+		clone.DisabledQueryParams = make(map[string]string, len(r.DisabledQueryParams))
+		for k, v := range r.DisabledQueryParams {
+			clone.DisabledQueryParams[k] = v
+		}
+	}
 
 	return clone
 }
 
+// NormalizeTags trims tags, drops empty ones and duplicates, and sorts the
+// rest. It returns nil if no tags remain.
+func NormalizeTags(tags []string) []string {
+	seen := make(map[string]bool, len(tags))
+	var out []string
+	for _, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		out = append(out, tag)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// HasTag reports whether the request is labelled with tag.
+func (r *Request) HasTag(tag string) bool {
+	for _, t := range r.Tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
 // IsBodyAllowed returns true if the HTTP method allows a request body.
 func (r *Request) IsBodyAllowed() bool {
 	method := strings.ToUpper(r.Method)
@@ -2,6 +2,7 @@ package domain
 
 import (
 	"errors"
+	"reflect"
 	"testing"
 	"time"
 )
@@ -130,6 +131,28 @@ func TestValidateURL(t *testing.T) {
 	}
 }
 
+// TestValidateURL_Reasons tests that invalid URLs say what is wrong.
+func TestValidateURL_Reasons(t *testing.T) {
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{"example.com", "invalid URL format: missing http:// or https://"},
+		{"ftp://example.com", `invalid URL format: scheme "ftp" is not http or https`},
+		{"https://", "invalid URL format: missing host"},
+		{"://invalid", "invalid URL format: missing protocol scheme"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			err := (&Request{URL: tt.url}).ValidateURL()
+			if err == nil || err.Error() != tt.want {
+				t.Errorf("ValidateURL() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
 // TestValidateHeaders tests the ValidateHeaders function.
 func TestValidateHeaders(t *testing.T) {
 	tests := []struct {
@@ -436,6 +459,8 @@ func TestClone(t *testing.T) {
 	original.QueryParams = map[string]string{"version": "v1"}
 	original.Body = testJSONBody
 	original.AuthConfig = NewBearerAuth("token123")
+	original.PreRequestScript = `request.headers["X-Id"] = crypto.randomUUID();`
+	original.PostResponseScript = "assert(response.status === 200);"
 
 	clone := original.Clone()
 
@@ -458,6 +483,9 @@ func TestClone(t *testing.T) {
 	if clone.AuthConfig != original.AuthConfig {
 		t.Error("AuthConfig not copied correctly")
 	}
+	if clone.PreRequestScript != original.PreRequestScript || clone.PostResponseScript != original.PostResponseScript {
+		t.Error("scripts not copied correctly")
+	}
 
 	// Test that maps are deep copied.
 	clone.Headers["X-Custom"] = testValue
@@ -479,6 +507,57 @@ func TestClone(t *testing.T) {
 	}
 }
 
+// TestCloneTags tests that tags are deep copied.
+func TestCloneDisabledQueryParams(t *testing.T) {
+	original := NewRequest()
+	original.DisabledQueryParams = map[string]string{"debug": "1"}
+
+	clone := original.Clone()
+	clone.DisabledQueryParams["debug"] = "2"
+	if original.DisabledQueryParams["debug"] != "1" {
+		t.Error("modifying clone's disabled query params affected original")
+	}
+	if NewRequest().Clone().DisabledQueryParams != nil {
+		t.Error("a request without disabled query params should clone without them")
+	}
+}
+
+func TestCloneTags(t *testing.T) {
+	original := NewRequest()
+	original.Tags = []string{"smoke", "users"}
+
+	clone := original.Clone()
+	clone.Tags[0] = "changed"
+	if original.Tags[0] != "smoke" {
+		t.Error("modifying clone's tags affected original")
+	}
+	if !clone.HasTag("users") || clone.HasTag("smoke") {
+		t.Errorf("unexpected clone tags: %v", clone.Tags)
+	}
+}
+
+// TestNormalizeTags tests tag trimming, deduplication and sorting.
+func TestNormalizeTags(t *testing.T) {
+	tests := []struct {
+		name string
+		tags []string
+		want []string
+	}{
+		{"nil", nil, nil},
+		{"only blanks", []string{"", "  "}, nil},
+		{"sorted and unique", []string{"users", " smoke ", "users", "auth"}, []string{"auth", "smoke", "users"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NormalizeTags(tt.tags)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("NormalizeTags(%q) = %q, want %q", tt.tags, got, tt.want)
+			}
+		})
+	}
+}
+
 // TestIsBodyAllowed tests the IsBodyAllowed method.
 func TestIsBodyAllowed(t *testing.T) {
 	tests := []struct {
@@ -621,3 +700,32 @@ func BenchmarkClone(b *testing.B) {
 		_ = req.Clone()
 	}
 }
+
+func TestFullURL(t *testing.T) {
+	tests := []struct {
+		name   string
+		url    string
+		params map[string]string
+		want   string
+	}{
+		{name: "no params", url: "https://api.example.com/users?page=1", want: "https://api.example.com/users?page=1"},
+		{name: "adds params", url: "https://api.example.com/users", params: map[string]string{"page": "2", "q": "a b"}, want: "https://api.example.com/users?page=2&q=a+b"},
+		{name: "replaces params", url: "https://api.example.com/users?page=1&sort=name", params: map[string]string{"page": "2"}, want: "https://api.example.com/users?page=2&sort=name"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := &Request{URL: tt.url, QueryParams: tt.params}
+			got, err := req.FullURL()
+			if err != nil {
+				t.Fatalf("FullURL() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("FullURL() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := (&Request{URL: "://bad"}).FullURL(); !errors.Is(err, ErrInvalidURL) {
+		t.Errorf("FullURL() error = %v, want ErrInvalidURL", err)
+	}
+}
@@ -17,6 +17,11 @@ type Response struct {
 	// Keys are header names, values are header values.
 	Headers map[string]string
 
+	// SetCookies holds the response's Set-Cookie header values, one per
+	// cookie. Headers joins them with commas, which cookie dates also contain,
+	// so they cannot be split apart again from there.
+	SetCookies []string
+
 	// Body is the response body content as a string.
 	Body string
 
@@ -32,6 +37,22 @@ type Response struct {
 	// RequestID is the ID of the request that generated this response.
 	// This links the response back to its originating request.
 	RequestID string
+
+	// AssertionFailures lists the ways the response failed the request's
+	// checks, such as violations of its response schema. Empty when it passed.
+	AssertionFailures []string
+
+	// Sent is the request as it was sent, with its variables resolved and
+	// fake data placeholders filled; nil if not known.
+	Sent *Request
+
+	// TLS describes the connection of an HTTPS response; nil for plain HTTP
+	// and for responses loaded from history, which do not keep it.
+	TLS *TLSInfo
+
+	// Timing breaks the time of the request down into its phases; nil if
+	// they were not measured, as for responses loaded from history.
+	Timing *Timing
 }
 
 // NewResponse creates a new Response with default values.
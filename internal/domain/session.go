@@ -0,0 +1,23 @@
+package domain
+
+import "time"
+
+// Session is where the TUI was left when it last exited, so that it can
+// start there again.
+type Session struct {
+	// RequestID is the ID of the request open in the request builder, which
+	// is only found again if it was saved.
+	RequestID string
+
+	// Tab is the name of the tab shown, such as "History".
+	Tab string
+
+	// ResponseOffset is the first row of the response body in view.
+	ResponseOffset int
+
+	// HistoryIndex is the position of the cursor in the history list.
+	HistoryIndex int
+
+	// UpdatedAt is when the session was last saved.
+	UpdatedAt time.Time
+}
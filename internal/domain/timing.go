@@ -0,0 +1,66 @@
+package domain
+
+import "time"
+
+// Timing breaks down where the time of a request went. Phases that did not
+// happen are zero: a reused connection has no DNS lookup, connect or TLS
+// handshake, and plain HTTP has no TLS handshake. When redirects are
+// followed, it describes the last request.
+type Timing struct {
+	// DNS is the time taken to look up the host.
+	DNS time.Duration
+
+	// Connect is the time taken to open the TCP connection.
+	Connect time.Duration
+
+	// TLS is the time taken by the TLS handshake.
+	TLS time.Duration
+
+	// Wait is the time from having a connection to the first byte of the
+	// response: sending the request and the server's processing.
+	Wait time.Duration
+
+	// Download is the time taken to receive the response body.
+	Download time.Duration
+
+	// ReusedConnection is true if the request went over a connection kept
+	// alive from an earlier one.
+	ReusedConnection bool
+}
+
+// TimingPhase is a phase of a request, with when it started relative to
+// the start of the first.
+type TimingPhase struct {
+	Name     string
+	Start    time.Duration
+	Duration time.Duration
+}
+
+// Total returns the time taken by all the phases.
+func (t *Timing) Total() time.Duration {
+	return t.DNS + t.Connect + t.TLS + t.Wait + t.Download
+}
+
+// Phases returns the phases that happened, in order, each starting when
+// the one before it ended.
+func (t *Timing) Phases() []TimingPhase {
+	all := []TimingPhase{
+		{Name: "DNS lookup", Duration: t.DNS},
+		{Name: "Connect", Duration: t.Connect},
+		{Name: "TLS handshake", Duration: t.TLS},
+		{Name: "Waiting (TTFB)", Duration: t.Wait},
+		{Name: "Download", Duration: t.Download},
+	}
+
+	var phases []TimingPhase
+	var start time.Duration
+	for _, phase := range all {
+		if phase.Duration <= 0 {
+			continue
+		}
+		phase.Start = start
+		start += phase.Duration
+		phases = append(phases, phase)
+	}
+	return phases
+}
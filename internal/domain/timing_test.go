@@ -0,0 +1,57 @@
+package domain
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+// TestTimingPhases tests the Phases and Total methods.
+func TestTimingPhases(t *testing.T) {
+	ms := time.Millisecond
+	tests := []struct {
+		name   string
+		timing Timing
+		want   []TimingPhase
+		total  time.Duration
+	}{
+		{
+			name:   "new TLS connection",
+			timing: Timing{DNS: 5 * ms, Connect: 10 * ms, TLS: 20 * ms, Wait: 50 * ms, Download: 15 * ms},
+			want: []TimingPhase{
+				{Name: "DNS lookup", Start: 0, Duration: 5 * ms},
+				{Name: "Connect", Start: 5 * ms, Duration: 10 * ms},
+				{Name: "TLS handshake", Start: 15 * ms, Duration: 20 * ms},
+				{Name: "Waiting (TTFB)", Start: 35 * ms, Duration: 50 * ms},
+				{Name: "Download", Start: 85 * ms, Duration: 15 * ms},
+			},
+			total: 100 * ms,
+		},
+		{
+			name:   "reused connection",
+			timing: Timing{Wait: 30 * ms, Download: 2 * ms, ReusedConnection: true},
+			want: []TimingPhase{
+				{Name: "Waiting (TTFB)", Start: 0, Duration: 30 * ms},
+				{Name: "Download", Start: 30 * ms, Duration: 2 * ms},
+			},
+			total: 32 * ms,
+		},
+		{
+			name:   "nothing measured",
+			timing: Timing{},
+			want:   nil,
+			total:  0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.timing.Phases(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Phases() = %+v, want %+v", got, tt.want)
+			}
+			if got := tt.timing.Total(); got != tt.total {
+				t.Errorf("Total() = %v, want %v", got, tt.total)
+			}
+		})
+	}
+}
@@ -0,0 +1,48 @@
+package domain
+
+import "time"
+
+// TLSInfo describes the TLS connection a response was received over.
+type TLSInfo struct {
+	// Version is the protocol version negotiated, such as "TLS 1.3".
+	Version string
+
+	// CipherSuite is the name of the cipher suite negotiated, such as
+	// "TLS_AES_128_GCM_SHA256".
+	CipherSuite string
+
+	// NegotiatedProtocol is the application protocol agreed by ALPN, such
+	// as "h2"; empty if none was.
+	NegotiatedProtocol string
+
+	// ServerName is the host name the client asked for.
+	ServerName string
+
+	// Certificates is the chain the server presented, its own certificate
+	// first.
+	Certificates []Certificate
+}
+
+// Certificate describes an X.509 certificate of a server's chain.
+type Certificate struct {
+	Subject            string
+	Issuer             string
+	DNSNames           []string
+	SerialNumber       string
+	SignatureAlgorithm string
+	PublicKeyAlgorithm string
+	NotBefore          time.Time
+	NotAfter           time.Time
+}
+
+// ExpiresIn returns how long the certificate is valid for after now;
+// negative once it has expired.
+func (c Certificate) ExpiresIn(now time.Time) time.Duration {
+	return c.NotAfter.Sub(now)
+}
+
+// ValidAt reports whether now falls within the certificate's validity
+// period.
+func (c Certificate) ValidAt(now time.Time) bool {
+	return !now.Before(c.NotBefore) && !now.After(c.NotAfter)
+}
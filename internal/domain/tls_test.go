@@ -0,0 +1,38 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+// TestCertificateValidity tests the ExpiresIn and ValidAt methods.
+func TestCertificateValidity(t *testing.T) {
+	cert := Certificate{
+		NotBefore: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		NotAfter:  time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC),
+	}
+
+	tests := []struct {
+		name      string
+		now       time.Time
+		expiresIn time.Duration
+		valid     bool
+	}{
+		{"before validity", time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC), 91 * 24 * time.Hour, false},
+		{"first moment", cert.NotBefore, 90 * 24 * time.Hour, true},
+		{"during validity", time.Date(2025, 3, 2, 0, 0, 0, 0, time.UTC), 30 * 24 * time.Hour, true},
+		{"last moment", cert.NotAfter, 0, true},
+		{"expired", time.Date(2025, 4, 2, 0, 0, 0, 0, time.UTC), -24 * time.Hour, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := cert.ExpiresIn(tt.now); got != tt.expiresIn {
+				t.Errorf("ExpiresIn() = %v, want %v", got, tt.expiresIn)
+			}
+			if got := cert.ValidAt(tt.now); got != tt.valid {
+				t.Errorf("ValidAt() = %v, want %v", got, tt.valid)
+			}
+		})
+	}
+}
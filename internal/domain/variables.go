@@ -0,0 +1,116 @@
+package domain
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// variablePattern matches a {{name}} reference, allowing spaces inside the
+// braces. Names starting with "fake." are placeholders for generated data
+// and are never treated as variables.
+var variablePattern = regexp.MustCompile(`\{\{\s*([A-Za-z_][A-Za-z0-9_.-]*)\s*\}\}`)
+
+// variableName matches a name that can be referenced as {{name}}.
+var variableName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.-]*$`)
+
+// fakePrefix marks {{fake.NAME}} placeholders, which are filled by the
+// fake data generator rather than from an environment.
+const fakePrefix = "fake."
+
+// HasVariables reports whether s contains a {{name}} variable reference.
+func HasVariables(s string) bool {
+	for _, match := range variablePattern.FindAllStringSubmatch(s, -1) {
+		if !strings.HasPrefix(match[1], fakePrefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// ExpandVariables replaces each {{name}} reference in s with its value in
+// vars. References to unknown names are left as they are and returned,
+// sorted and without duplicates, in unresolved.
+func ExpandVariables(s string, vars map[string]string) (expanded string, unresolved []string) {
+	missing := make(map[string]bool)
+	expanded = variablePattern.ReplaceAllStringFunc(s, func(match string) string {
+		name := variablePattern.FindStringSubmatch(match)[1]
+		if strings.HasPrefix(name, fakePrefix) {
+			return match
+		}
+		if value, ok := vars[name]; ok {
+			return value
+		}
+		missing[name] = true
+		return match
+	})
+	return expanded, sortedKeys(missing)
+}
+
+// HasVariables reports whether any part of the request that ResolveVariables
+// rewrites contains a {{name}} variable reference.
+func (r *Request) HasVariables() bool {
+	found := false
+	r.eachTemplate(func(s string) string {
+		found = found || HasVariables(s)
+		return s
+	})
+	return found
+}
+
+// ResolveVariables returns a copy of the request with the {{name}} references
+// in its URL, header and query values, body and auth fields replaced by their
+// values in vars. Names without a value are left in place and returned in
+// unresolved. A request without variables is returned as is.
+func (r *Request) ResolveVariables(vars map[string]string) (resolved *Request, unresolved []string) {
+	if !r.HasVariables() {
+		return r, nil
+	}
+
+	missing := make(map[string]bool)
+	resolved = r.Clone()
+	resolved.eachTemplate(func(s string) string {
+		expanded, names := ExpandVariables(s, vars)
+		for _, name := range names {
+			missing[name] = true
+		}
+		return expanded
+	})
+	return resolved, sortedKeys(missing)
+}
+
+// eachTemplate replaces every field that may hold variable references with
+// the result of fn. Auth configs are replaced rather than modified, since
+// clones share them.
+func (r *Request) eachTemplate(fn func(string) string) {
+	r.URL = fn(r.URL)
+	r.Body = fn(r.Body)
+	for k, v := range r.Headers {
+		r.Headers[k] = fn(v)
+	}
+	for k, v := range r.QueryParams {
+		r.QueryParams[k] = fn(v)
+	}
+
+	switch auth := r.AuthConfig.(type) {
+	case *BasicAuth:
+		r.AuthConfig = &BasicAuth{Username: fn(auth.Username), Password: fn(auth.Password)}
+	case *BearerAuth:
+		r.AuthConfig = &BearerAuth{Token: fn(auth.Token)}
+	case *APIKeyAuth:
+		r.AuthConfig = &APIKeyAuth{Key: fn(auth.Key), Value: fn(auth.Value), Location: auth.Location}
+	}
+}
+
+// sortedKeys returns the keys of set, sorted, or nil if it is empty.
+func sortedKeys(set map[string]bool) []string {
+	if len(set) == 0 {
+		return nil
+	}
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,100 @@
+package domain
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestHasVariables(t *testing.T) {
+	tests := []struct {
+		s    string
+		want bool
+	}{
+		{"{{baseUrl}}/users", true},
+		{"https://x.test/{{ id }}", true},
+		{"{{fake.email}}", false},
+		{"{{fake.email}} {{token}}", true},
+		{"https://x.test/users", false},
+		{"{{ not a name }}", false},
+	}
+
+	for _, tt := range tests {
+		if got := HasVariables(tt.s); got != tt.want {
+			t.Errorf("HasVariables(%q) = %v, want %v", tt.s, got, tt.want)
+		}
+	}
+}
+
+func TestExpandVariables(t *testing.T) {
+	vars := map[string]string{"host": "api.example.com", "id": "42"}
+
+	got, unresolved := ExpandVariables("https://{{host}}/users/{{ id }}?x={{missing}}&y={{fake.uuid}}&z={{missing}}", vars)
+	want := "https://api.example.com/users/42?x={{missing}}&y={{fake.uuid}}&z={{missing}}"
+	if got != want {
+		t.Errorf("expanded = %q, want %q", got, want)
+	}
+	if !reflect.DeepEqual(unresolved, []string{"missing"}) {
+		t.Errorf("unresolved = %v, want [missing]", unresolved)
+	}
+
+	if _, unresolved := ExpandVariables("{{host}}", vars); unresolved != nil {
+		t.Errorf("unresolved = %v, want nil", unresolved)
+	}
+}
+
+func TestResolveVariables(t *testing.T) {
+	req := NewRequestWithMethodAndURL(MethodPost, "{{baseUrl}}/users")
+	req.SetHeader("X-Tenant", "{{tenant}}")
+	req.SetQueryParam("page", "{{page}}")
+	req.Body = `{"owner":"{{user}}"}`
+	req.SetAuth(NewBearerAuth("{{token}}"))
+
+	vars := map[string]string{"baseUrl": "https://api.example.com", "tenant": "acme", "token": "s3cret", "user": "ada"}
+	resolved, unresolved := req.ResolveVariables(vars)
+
+	if resolved == req {
+		t.Fatal("expected a copy of the request")
+	}
+	if resolved.URL != testURL {
+		t.Errorf("URL = %q, want %q", resolved.URL, testURL)
+	}
+	if resolved.Headers["X-Tenant"] != "acme" {
+		t.Errorf("X-Tenant = %q, want acme", resolved.Headers["X-Tenant"])
+	}
+	if resolved.QueryParams["page"] != "{{page}}" {
+		t.Errorf("page = %q, want it left unresolved", resolved.QueryParams["page"])
+	}
+	if resolved.Body != `{"owner":"ada"}` {
+		t.Errorf("Body = %q", resolved.Body)
+	}
+	if token := resolved.AuthConfig.(*BearerAuth).Token; token != "s3cret" {
+		t.Errorf("Token = %q, want s3cret", token)
+	}
+	if !reflect.DeepEqual(unresolved, []string{"page"}) {
+		t.Errorf("unresolved = %v, want [page]", unresolved)
+	}
+
+	// The original request is unchanged.
+	if req.URL != "{{baseUrl}}/users" || req.AuthConfig.(*BearerAuth).Token != "{{token}}" {
+		t.Error("expected the original request to be unchanged")
+	}
+}
+
+func TestResolveVariables_NoVariables(t *testing.T) {
+	req := NewRequestWithMethodAndURL(MethodGet, testURL)
+
+	resolved, unresolved := req.ResolveVariables(map[string]string{"a": "b"})
+	if resolved != req {
+		t.Error("expected the request itself when it has no variables")
+	}
+	if unresolved != nil {
+		t.Errorf("unresolved = %v, want nil", unresolved)
+	}
+}
+
+func TestValidateURL_Variables(t *testing.T) {
+	req := NewRequestWithMethodAndURL(MethodGet, "{{baseUrl}}/users")
+	if err := req.ValidateURL(); err != nil {
+		t.Errorf("expected a URL with variables to be accepted, got %v", err)
+	}
+}
@@ -0,0 +1,103 @@
+// Package clipboard copies text to the system clipboard.
+//
+// It uses the platform's clipboard command when one is available and falls
+// back to the OSC 52 escape sequence, which asks the terminal to set the
+// clipboard and so also works over SSH.
+package clipboard
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// MethodOSC52 is the method reported when the terminal was asked to set the
+// clipboard.
+const MethodOSC52 = "OSC 52"
+
+// command is a clipboard command and the environment variable, if any, that
+// must be set for it to work.
+type command struct {
+	name string
+	args []string
+	env  string
+}
+
+// commands are tried in order. Over SSH none is used, as they would set the
+// remote machine's clipboard.
+var commands = []command{
+	{name: "pbcopy"},
+	{name: "wl-copy", env: "WAYLAND_DISPLAY"},
+	{name: "xclip", args: []string{"-selection", "clipboard"}, env: "DISPLAY"},
+	{name: "xsel", args: []string{"--clipboard", "--input"}, env: "DISPLAY"},
+	{name: "clip.exe"},
+}
+
+// Clipboard copies text using the first method that works.
+type Clipboard struct {
+	getenv   func(string) string
+	lookPath func(string) (string, error)
+	run      func(path string, args []string, input string) error
+	terminal io.Writer
+}
+
+// New returns a Clipboard for the current environment that writes OSC 52
+// sequences to terminal.
+func New(terminal io.Writer) *Clipboard {
+	return &Clipboard{
+		getenv:   os.Getenv,
+		lookPath: exec.LookPath,
+		run:      runCommand,
+		terminal: terminal,
+	}
+}
+
+// Copy puts text on the clipboard and returns the method used: the name of
+// a clipboard command or MethodOSC52. OSC 52 cannot report whether the
+// terminal honoured it, so it only fails if the sequence cannot be written.
+func (c *Clipboard) Copy(text string) (string, error) {
+	if !c.remote() {
+		for _, cmd := range commands {
+			if cmd.env != "" && c.getenv(cmd.env) == "" {
+				continue
+			}
+			path, err := c.lookPath(cmd.name)
+			if err != nil {
+				continue
+			}
+			if err := c.run(path, cmd.args, text); err == nil {
+				return cmd.name, nil
+			}
+		}
+	}
+
+	if _, err := io.WriteString(c.terminal, OSC52(text, c.getenv("TMUX") != "")); err != nil {
+		return "", fmt.Errorf("failed to write to the terminal: %w", err)
+	}
+	return MethodOSC52, nil
+}
+
+// remote reports whether curly is running over SSH.
+func (c *Clipboard) remote() bool {
+	return c.getenv("SSH_TTY") != "" || c.getenv("SSH_CONNECTION") != ""
+}
+
+// OSC52 returns the escape sequence that asks the terminal to put text on the
+// clipboard. Inside tmux the sequence is wrapped so tmux passes it on.
+func OSC52(text string, tmux bool) string {
+	seq := "\x1b]52;c;" + base64.StdEncoding.EncodeToString([]byte(text)) + "\a"
+	if tmux {
+		return "\x1bPtmux;" + strings.ReplaceAll(seq, "\x1b", "\x1b\x1b") + "\x1b\\"
+	}
+	return seq
+}
+
+// runCommand runs a clipboard command with input on its standard input.
+func runCommand(path string, args []string, input string) error {
+	cmd := exec.Command(path, args...) // #nosec G204 -- path is one of the fixed commands
+	cmd.Stdin = strings.NewReader(input)
+	return cmd.Run()
+}
@@ -0,0 +1,94 @@
+package clipboard
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeClipboard returns a Clipboard with the given environment and installed
+// commands, recording the commands it runs.
+func fakeClipboard(env map[string]string, installed map[string]error, ran *[]string) (*Clipboard, *bytes.Buffer) {
+	var terminal bytes.Buffer
+	return &Clipboard{
+		getenv: func(key string) string { return env[key] },
+		lookPath: func(name string) (string, error) {
+			if _, ok := installed[name]; ok {
+				return "/usr/bin/" + name, nil
+			}
+			return "", errors.New("not found")
+		},
+		run: func(path string, _ []string, _ string) error {
+			*ran = append(*ran, path)
+			for name, err := range installed {
+				if path == "/usr/bin/"+name {
+					return err
+				}
+			}
+			return nil
+		},
+		terminal: &terminal,
+	}, &terminal
+}
+
+func TestCopy_UsesCommand(t *testing.T) {
+	var ran []string
+	c, terminal := fakeClipboard(
+		map[string]string{"DISPLAY": ":0"},
+		map[string]error{"xclip": nil, "xsel": nil},
+		&ran,
+	)
+
+	method, err := c.Copy("hello")
+	require.NoError(t, err)
+	assert.Equal(t, "xclip", method)
+	assert.Equal(t, []string{"/usr/bin/xclip"}, ran)
+	assert.Empty(t, terminal.String())
+}
+
+func TestCopy_SkipsFailingAndUnusableCommands(t *testing.T) {
+	var ran []string
+	c, _ := fakeClipboard(
+		map[string]string{"DISPLAY": ":0"},
+		map[string]error{"wl-copy": nil, "xclip": errors.New("no display"), "xsel": nil},
+		&ran,
+	)
+
+	method, err := c.Copy("hello")
+	require.NoError(t, err)
+	assert.Equal(t, "xsel", method)
+	// wl-copy needs WAYLAND_DISPLAY, so only xclip and xsel were run.
+	assert.Equal(t, []string{"/usr/bin/xclip", "/usr/bin/xsel"}, ran)
+}
+
+func TestCopy_FallsBackToOSC52(t *testing.T) {
+	var ran []string
+	c, terminal := fakeClipboard(nil, nil, &ran)
+
+	method, err := c.Copy("hello")
+	require.NoError(t, err)
+	assert.Equal(t, MethodOSC52, method)
+	assert.Equal(t, "\x1b]52;c;aGVsbG8=\a", terminal.String())
+}
+
+func TestCopy_UsesOSC52OverSSH(t *testing.T) {
+	var ran []string
+	c, terminal := fakeClipboard(
+		map[string]string{"SSH_TTY": "/dev/pts/0", "DISPLAY": ":0"},
+		map[string]error{"xclip": nil},
+		&ran,
+	)
+
+	method, err := c.Copy("hello")
+	require.NoError(t, err)
+	assert.Equal(t, MethodOSC52, method)
+	assert.Empty(t, ran)
+	assert.NotEmpty(t, terminal.String())
+}
+
+func TestOSC52_Tmux(t *testing.T) {
+	assert.Equal(t, "\x1bPtmux;\x1b\x1b]52;c;aGk=\a\x1b\\", OSC52("hi", true))
+}
@@ -0,0 +1,241 @@
+// Package codegen renders saved requests as code snippets in other languages.
+package codegen
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/williajm/curly/internal/domain"
+)
+
+// Supported snippet languages.
+const (
+	// LanguageCurl is a shell command using curl.
+	LanguageCurl = "curl"
+
+	// LanguageGo is a Go program using net/http.
+	LanguageGo = "go"
+
+	// LanguagePython is a Python script using the requests library.
+	LanguagePython = "python"
+
+	// LanguageJavaScript is JavaScript using the Fetch API.
+	LanguageJavaScript = "javascript"
+)
+
+// Languages lists the supported languages in the order they are offered.
+var Languages = []string{
+	LanguageCurl,
+	LanguageGo,
+	LanguagePython,
+	LanguageJavaScript,
+}
+
+// snippet is the request as it would be sent: query parameters merged into
+// the URL and auth applied to the headers or query.
+type snippet struct {
+	method  string
+	url     string
+	headers [][2]string
+	body    string
+}
+
+// Generate renders req as a snippet in language.
+func Generate(req *domain.Request, language string) (string, error) {
+	s, err := prepare(req)
+	if err != nil {
+		return "", err
+	}
+
+	switch language {
+	case LanguageCurl:
+		return s.curl(), nil
+	case LanguageGo:
+		return s.golang(), nil
+	case LanguagePython:
+		return s.python(), nil
+	case LanguageJavaScript:
+		return s.javascript(), nil
+	default:
+		return "", fmt.Errorf("unsupported language %q (supported: %s)", language, strings.Join(Languages, ", "))
+	}
+}
+
+// prepare resolves the URL, headers and body that would be sent for req.
+func prepare(req *domain.Request) (*snippet, error) {
+	parsed, err := url.Parse(req.URL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse URL: %w", err)
+	}
+	if len(req.QueryParams) > 0 {
+		query := parsed.Query()
+		for key, value := range req.QueryParams {
+			query.Set(key, value)
+		}
+		parsed.RawQuery = query.Encode()
+	}
+
+	httpReq, err := http.NewRequest(strings.ToUpper(req.Method), parsed.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build request: %w", err)
+	}
+	for name, value := range req.Headers {
+		httpReq.Header.Set(name, value)
+	}
+	if req.AuthConfig != nil {
+		if err := req.AuthConfig.Apply(httpReq); err != nil {
+			return nil, fmt.Errorf("failed to apply auth: %w", err)
+		}
+	}
+
+	s := &snippet{
+		method: httpReq.Method,
+		url:    httpReq.URL.String(),
+		body:   req.Body,
+	}
+	names := make([]string, 0, len(httpReq.Header))
+	for name := range httpReq.Header {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		s.headers = append(s.headers, [2]string{name, httpReq.Header.Get(name)})
+	}
+
+	return s, nil
+}
+
+// curl renders a shell command.
+func (s *snippet) curl() string {
+	var parts []string
+	switch s.method {
+	case http.MethodGet:
+		parts = append(parts, "curl "+shellQuote(s.url))
+	case http.MethodHead:
+		parts = append(parts, "curl --head "+shellQuote(s.url))
+	default:
+		parts = append(parts, "curl -X "+s.method+" "+shellQuote(s.url))
+	}
+	for _, h := range s.headers {
+		parts = append(parts, "-H "+shellQuote(h[0]+": "+h[1]))
+	}
+	if s.body != "" {
+		parts = append(parts, "--data-raw "+shellQuote(s.body))
+	}
+	return strings.Join(parts, " \\\n  ") + "\n"
+}
+
+// golang renders a Go program.
+func (s *snippet) golang() string {
+	var b strings.Builder
+
+	b.WriteString("package main\n\nimport (\n\t\"fmt\"\n\t\"io\"\n\t\"log\"\n\t\"net/http\"\n")
+	if s.body != "" {
+		b.WriteString("\t\"strings\"\n")
+	}
+	b.WriteString(")\n\nfunc main() {\n")
+
+	body := "nil"
+	if s.body != "" {
+		fmt.Fprintf(&b, "\tbody := strings.NewReader(%s)\n", goQuote(s.body))
+		body = "body"
+	}
+	fmt.Fprintf(&b, "\treq, err := http.NewRequest(%s, %s, %s)\n", strconv.Quote(s.method), goQuote(s.url), body)
+	b.WriteString("\tif err != nil {\n\t\tlog.Fatal(err)\n\t}\n")
+	for _, h := range s.headers {
+		fmt.Fprintf(&b, "\treq.Header.Set(%s, %s)\n", strconv.Quote(h[0]), goQuote(h[1]))
+	}
+
+	b.WriteString("\n\tresp, err := http.DefaultClient.Do(req)\n")
+	b.WriteString("\tif err != nil {\n\t\tlog.Fatal(err)\n\t}\n")
+	b.WriteString("\tdefer resp.Body.Close()\n\n")
+	b.WriteString("\tdata, err := io.ReadAll(resp.Body)\n")
+	b.WriteString("\tif err != nil {\n\t\tlog.Fatal(err)\n\t}\n")
+	b.WriteString("\tfmt.Println(resp.Status)\n")
+	b.WriteString("\tfmt.Println(string(data))\n")
+	b.WriteString("}\n")
+
+	return b.String()
+}
+
+// python renders a script using the requests library.
+func (s *snippet) python() string {
+	var b strings.Builder
+
+	b.WriteString("import requests\n\n")
+	fmt.Fprintf(&b, "url = %s\n", jsonQuote(s.url))
+
+	args := []string{jsonQuote(s.method), "url"}
+	if len(s.headers) > 0 {
+		b.WriteString("headers = {\n")
+		for _, h := range s.headers {
+			fmt.Fprintf(&b, "    %s: %s,\n", jsonQuote(h[0]), jsonQuote(h[1]))
+		}
+		b.WriteString("}\n")
+		args = append(args, "headers=headers")
+	}
+	if s.body != "" {
+		fmt.Fprintf(&b, "data = %s\n", jsonQuote(s.body))
+		args = append(args, "data=data")
+	}
+
+	fmt.Fprintf(&b, "\nresponse = requests.request(%s)\n", strings.Join(args, ", "))
+	b.WriteString("print(response.status_code)\n")
+	b.WriteString("print(response.text)\n")
+
+	return b.String()
+}
+
+// javascript renders a Fetch API call.
+func (s *snippet) javascript() string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "const response = await fetch(%s, {\n", jsonQuote(s.url))
+	fmt.Fprintf(&b, "  method: %s,\n", jsonQuote(s.method))
+	if len(s.headers) > 0 {
+		b.WriteString("  headers: {\n")
+		for _, h := range s.headers {
+			fmt.Fprintf(&b, "    %s: %s,\n", jsonQuote(h[0]), jsonQuote(h[1]))
+		}
+		b.WriteString("  },\n")
+	}
+	if s.body != "" {
+		fmt.Fprintf(&b, "  body: %s,\n", jsonQuote(s.body))
+	}
+	b.WriteString("});\n\n")
+	b.WriteString("console.log(response.status);\n")
+	b.WriteString("console.log(await response.text());\n")
+
+	return b.String()
+}
+
+// shellQuote quotes s for POSIX shells.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// goQuote quotes s as a Go string literal. Multi-line text and text with
+// double quotes, such as JSON bodies, use a raw string when one can hold it.
+func goQuote(s string) string {
+	if strings.ContainsAny(s, "\n\"") && !strings.ContainsAny(s, "`\r") && utf8.ValidString(s) {
+		return "`" + s + "`"
+	}
+	return strconv.Quote(s)
+}
+
+// jsonQuote quotes s as a JSON string, which is also a valid Python and
+// JavaScript string literal.
+func jsonQuote(s string) string {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	_ = enc.Encode(s) // Encoding a string cannot fail.
+	return strings.TrimSuffix(buf.String(), "\n")
+}
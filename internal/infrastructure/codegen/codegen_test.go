@@ -0,0 +1,158 @@
+package codegen
+
+import (
+	"go/parser"
+	"go/token"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/williajm/curly/internal/domain"
+)
+
+func postRequest() *domain.Request {
+	req := domain.NewRequestWithMethodAndURL(domain.MethodPost, "https://api.example.com/items?debug=1")
+	req.SetHeader("content-type", "application/json")
+	req.SetQueryParam("page", "2")
+	req.Body = `{"name": "it's <new>"}`
+	req.SetAuth(domain.NewBearerAuth("tok"))
+	return req
+}
+
+func TestGenerate_Curl(t *testing.T) {
+	got, err := Generate(postRequest(), LanguageCurl)
+	require.NoError(t, err)
+	assert.Equal(t, `curl -X POST 'https://api.example.com/items?debug=1&page=2' \
+  -H 'Authorization: Bearer tok' \
+  -H 'Content-Type: application/json' \
+  --data-raw '{"name": "it'\''s <new>"}'
+`, got)
+}
+
+func TestGenerate_CurlMethods(t *testing.T) {
+	get := domain.NewRequestWithMethodAndURL(domain.MethodGet, "https://api.example.com")
+	got, err := Generate(get, LanguageCurl)
+	require.NoError(t, err)
+	assert.Equal(t, "curl 'https://api.example.com'\n", got)
+
+	head := domain.NewRequestWithMethodAndURL(domain.MethodHead, "https://api.example.com")
+	got, err = Generate(head, LanguageCurl)
+	require.NoError(t, err)
+	assert.Equal(t, "curl --head 'https://api.example.com'\n", got)
+}
+
+func TestGenerate_Go(t *testing.T) {
+	got, err := Generate(postRequest(), LanguageGo)
+	require.NoError(t, err)
+	assert.Equal(t, "package main\n"+
+		"\n"+
+		"import (\n"+
+		"\t\"fmt\"\n"+
+		"\t\"io\"\n"+
+		"\t\"log\"\n"+
+		"\t\"net/http\"\n"+
+		"\t\"strings\"\n"+
+		")\n"+
+		"\n"+
+		"func main() {\n"+
+		"\tbody := strings.NewReader(`{\"name\": \"it's <new>\"}`)\n"+
+		"\treq, err := http.NewRequest(\"POST\", \"https://api.example.com/items?debug=1&page=2\", body)\n"+
+		"\tif err != nil {\n"+
+		"\t\tlog.Fatal(err)\n"+
+		"\t}\n"+
+		"\treq.Header.Set(\"Authorization\", \"Bearer tok\")\n"+
+		"\treq.Header.Set(\"Content-Type\", \"application/json\")\n"+
+		"\n"+
+		"\tresp, err := http.DefaultClient.Do(req)\n"+
+		"\tif err != nil {\n"+
+		"\t\tlog.Fatal(err)\n"+
+		"\t}\n"+
+		"\tdefer resp.Body.Close()\n"+
+		"\n"+
+		"\tdata, err := io.ReadAll(resp.Body)\n"+
+		"\tif err != nil {\n"+
+		"\t\tlog.Fatal(err)\n"+
+		"\t}\n"+
+		"\tfmt.Println(resp.Status)\n"+
+		"\tfmt.Println(string(data))\n"+
+		"}\n", got)
+}
+
+func TestGenerate_GoCompilesForAnyBody(t *testing.T) {
+	bodies := []string{"", "plain", "line one\nline two", "has `backquote` and \"quotes\"", "crlf\r\nbody"}
+	for _, body := range bodies {
+		req := domain.NewRequestWithMethodAndURL(domain.MethodPut, "https://api.example.com")
+		req.Body = body
+		req.SetAuth(domain.NewAPIKeyAuth("api_key", "k", domain.APIKeyLocationQuery))
+
+		got, err := Generate(req, LanguageGo)
+		require.NoError(t, err)
+		_, err = parser.ParseFile(token.NewFileSet(), "main.go", got, parser.AllErrors)
+		assert.NoError(t, err, "body %q produced:\n%s", body, got)
+		assert.Contains(t, got, "https://api.example.com?api_key=k")
+	}
+}
+
+func TestGenerate_Python(t *testing.T) {
+	got, err := Generate(postRequest(), LanguagePython)
+	require.NoError(t, err)
+	assert.Equal(t, `import requests
+
+url = "https://api.example.com/items?debug=1&page=2"
+headers = {
+    "Authorization": "Bearer tok",
+    "Content-Type": "application/json",
+}
+data = "{\"name\": \"it's <new>\"}"
+
+response = requests.request("POST", url, headers=headers, data=data)
+print(response.status_code)
+print(response.text)
+`, got)
+}
+
+func TestGenerate_JavaScript(t *testing.T) {
+	got, err := Generate(postRequest(), LanguageJavaScript)
+	require.NoError(t, err)
+	assert.Equal(t, `const response = await fetch("https://api.example.com/items?debug=1&page=2", {
+  method: "POST",
+  headers: {
+    "Authorization": "Bearer tok",
+    "Content-Type": "application/json",
+  },
+  body: "{\"name\": \"it's <new>\"}",
+});
+
+console.log(response.status);
+console.log(await response.text());
+`, got)
+}
+
+func TestGenerate_MinimalRequest(t *testing.T) {
+	req := domain.NewRequestWithMethodAndURL(domain.MethodGet, "https://api.example.com")
+
+	python, err := Generate(req, LanguagePython)
+	require.NoError(t, err)
+	assert.Contains(t, python, `requests.request("GET", url)`)
+	assert.NotContains(t, python, "headers")
+
+	js, err := Generate(req, LanguageJavaScript)
+	require.NoError(t, err)
+	assert.NotContains(t, js, "headers")
+	assert.NotContains(t, js, "body")
+}
+
+func TestGenerate_Errors(t *testing.T) {
+	_, err := Generate(postRequest(), "cobol")
+	assert.ErrorContains(t, err, `unsupported language "cobol"`)
+
+	req := postRequest()
+	req.SetAuth(domain.NewBasicAuth("", ""))
+	_, err = Generate(req, LanguageCurl)
+	assert.ErrorContains(t, err, "failed to apply auth")
+
+	req = postRequest()
+	req.URL = "://bad"
+	_, err = Generate(req, LanguageCurl)
+	assert.ErrorContains(t, err, "failed to parse URL")
+}
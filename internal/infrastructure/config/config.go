@@ -13,16 +13,45 @@ import (
 
 // Config holds all application configuration.
 type Config struct {
+	// Workspace selects a named database in database.workspaces_dir.
+	// Empty or "default" uses database.path.
+	Workspace string `mapstructure:"workspace"`
+
 	Database DatabaseConfig `mapstructure:"database"`
 	HTTP     HTTPConfig     `mapstructure:"http"`
+	GraphQL  GraphQLConfig  `mapstructure:"graphql"`
 	UI       UIConfig       `mapstructure:"ui"`
 	History  HistoryConfig  `mapstructure:"history"`
 	Logging  LoggingConfig  `mapstructure:"logging"`
+
+	// Schedules run saved requests or folders periodically while curly is running.
+	Schedules []ScheduleConfig `mapstructure:"schedules"`
+
+	// Hosts holds settings for the requests sent to particular hosts.
+	Hosts []HostConfig `mapstructure:"hosts"`
+
+	// Hooks run external programs before requests are sent, after responses
+	// are received and before requests are saved.
+	Hooks []HookConfig `mapstructure:"hooks"`
 }
 
 // DatabaseConfig holds database-related configuration.
 type DatabaseConfig struct {
-	Path string `mapstructure:"path"`
+	Driver              string `mapstructure:"driver"`
+	Path                string `mapstructure:"path"`
+	DSN                 string `mapstructure:"dsn"`
+	BackupBeforeMigrate bool   `mapstructure:"backup_before_migrate"`
+	BackupDir           string `mapstructure:"backup_dir"`
+	MaxBackups          int    `mapstructure:"max_backups"`
+	WorkspacesDir       string `mapstructure:"workspaces_dir"`
+
+	// SQLite connection tuning.
+	JournalMode     string        `mapstructure:"journal_mode"`
+	Synchronous     string        `mapstructure:"synchronous"`
+	BusyTimeout     time.Duration `mapstructure:"busy_timeout"`
+	MaxOpenConns    int           `mapstructure:"max_open_conns"`
+	MaxIdleConns    int           `mapstructure:"max_idle_conns"`
+	ConnMaxLifetime time.Duration `mapstructure:"conn_max_lifetime"`
 }
 
 // HTTPConfig holds HTTP client configuration.
@@ -33,19 +62,146 @@ type HTTPConfig struct {
 	InsecureSkipTLS bool          `mapstructure:"insecure_skip_tls"`
 }
 
+// GraphQLConfig holds GraphQL settings.
+type GraphQLConfig struct {
+	// SchemaCacheDir is where introspected schemas are cached.
+	SchemaCacheDir string `mapstructure:"schema_cache_dir"`
+}
+
 // UIConfig holds UI preferences.
 type UIConfig struct {
+	// Theme names a built-in theme ("dark" or "light") or one of Themes.
 	Theme              string `mapstructure:"theme"`
 	SyntaxHighlighting bool   `mapstructure:"syntax_highlighting"`
 	ShowResponseTime   bool   `mapstructure:"show_response_time"`
 	DefaultTab         string `mapstructure:"default_tab"`
+
+	// ImagePreview is how image responses are previewed: "auto" to detect
+	// the terminal's graphics protocol, "kitty", "iterm", "sixel", "blocks"
+	// for Unicode half blocks, or "off" for a hex dump.
+	ImagePreview string `mapstructure:"image_preview"`
+
+	// CheckForUpdates checks on startup whether a newer release of curly is
+	// out, and shows a hint in the status bar if so.
+	CheckForUpdates bool `mapstructure:"check_for_updates"`
+
+	// AutosaveInterval is how often the request builder's unsaved changes
+	// are saved as a draft, to be offered back after a crash or an
+	// accidental quit. 0 turns drafts off.
+	AutosaveInterval time.Duration `mapstructure:"autosave_interval"`
+
+	// RestoreSession reopens the request, tab and scroll positions the TUI
+	// was left at when it last exited, instead of starting on DefaultTab.
+	// A DefaultTab set in the config file is still the tab shown on launch.
+	RestoreSession bool `mapstructure:"restore_session"`
+
+	// DefaultTabSet reports whether the config file sets DefaultTab, rather
+	// than leaving it at its default. It is not a setting itself.
+	DefaultTabSet bool
+
+	// Themes are user-defined palettes, keyed by name.
+	Themes map[string]ThemeConfig `mapstructure:"themes"`
+
+	// Keybindings rebind the TUI's global shortcuts: each key is an action,
+	// such as "finder", and each value a key, such as "ctrl+k".
+	Keybindings map[string]string `mapstructure:"keybindings"`
+
+	// Layout is the pane layout, which the TUI saves to the layout file.
+	Layout LayoutConfig `mapstructure:"layout"`
+}
+
+// LayoutConfig holds the pane layout of the TUI.
+type LayoutConfig struct {
+	// Split shows the response beside the request builder.
+	Split bool `mapstructure:"split"`
+
+	// SplitRatio is the share of the width, in percent, given to the request
+	// builder when Split is set.
+	SplitRatio int `mapstructure:"split_ratio"`
+}
+
+// ThemeConfig is a user-defined palette. It starts from the built-in Base
+// theme ("dark" when empty) and replaces the colors that are set. Colors are
+// hex ("#7C3AED") or ANSI color numbers ("205").
+type ThemeConfig struct {
+	Base         string `mapstructure:"base"`
+	Primary      string `mapstructure:"primary"`
+	Secondary    string `mapstructure:"secondary"`
+	Text         string `mapstructure:"text"`
+	TextDimmed   string `mapstructure:"text_dimmed"`
+	Background   string `mapstructure:"background"`
+	Border       string `mapstructure:"border"`
+	BorderActive string `mapstructure:"border_active"`
+	Success      string `mapstructure:"success"`
+	Error        string `mapstructure:"error"`
+	Warning      string `mapstructure:"warning"`
+	Info         string `mapstructure:"info"`
+
+	// Status colors status classes, keyed "2xx" to "5xx".
+	Status map[string]string `mapstructure:"status"`
+
+	// Methods colors HTTP methods, keyed by method.
+	Methods map[string]string `mapstructure:"methods"`
 }
 
 // HistoryConfig holds history management settings.
 type HistoryConfig struct {
-	MaxEntries       int  `mapstructure:"max_entries"`
-	AutoCleanup      bool `mapstructure:"auto_cleanup"`
-	CleanupAfterDays int  `mapstructure:"cleanup_after_days"`
+	MaxEntries           int    `mapstructure:"max_entries"`
+	AutoCleanup          bool   `mapstructure:"auto_cleanup"`
+	CleanupAfterDays     int    `mapstructure:"cleanup_after_days"`
+	OffloadThreshold     int    `mapstructure:"offload_threshold"`
+	BodiesDir            string `mapstructure:"bodies_dir"`
+	ArchiveBeforeCleanup bool   `mapstructure:"archive_before_cleanup"`
+	ArchiveDir           string `mapstructure:"archive_dir"`
+}
+
+// ScheduleConfig runs a saved request or a folder of requests on a cron
+// schedule. Exactly one of Request and Folder should be set.
+type ScheduleConfig struct {
+	Name    string `mapstructure:"name"`
+	Cron    string `mapstructure:"cron"`
+	Request string `mapstructure:"request"`
+	Folder  string `mapstructure:"folder"`
+}
+
+// HostConfig holds settings applied to every request whose host matches
+// Pattern, such as "api.example.com" or "*.internal.corp". Only the first
+// matching host applies. Headers and Auth do not replace a request's own.
+type HostConfig struct {
+	Pattern         string            `mapstructure:"pattern"`
+	Headers         map[string]string `mapstructure:"headers"`
+	Auth            HostAuthConfig    `mapstructure:"auth"`
+	Timeout         time.Duration     `mapstructure:"timeout"`
+	Proxy           string            `mapstructure:"proxy"`
+	InsecureSkipTLS bool              `mapstructure:"insecure_skip_tls"`
+	CACert          string            `mapstructure:"ca_cert"`
+	ClientCert      string            `mapstructure:"client_cert"`
+	ClientKey       string            `mapstructure:"client_key"`
+}
+
+// HookConfig runs Command with Args at Event: "pre_request", "post_response"
+// or "on_save". The program receives the request, and the response, as JSON
+// on stdin and may print changes to it. Pattern limits the hook to requests
+// whose host matches it, as for hosts.
+type HookConfig struct {
+	Event   string        `mapstructure:"event"`
+	Command string        `mapstructure:"command"`
+	Args    []string      `mapstructure:"args"`
+	Pattern string        `mapstructure:"pattern"`
+	Timeout time.Duration `mapstructure:"timeout"`
+}
+
+// HostAuthConfig authenticates the requests sent to a host. Type is "basic"
+// with Username and Password, "bearer" with Token, or "apikey" with Key,
+// Value and Location ("header", the default, or "query"); empty means none.
+type HostAuthConfig struct {
+	Type     string `mapstructure:"type"`
+	Username string `mapstructure:"username"`
+	Password string `mapstructure:"password"`
+	Token    string `mapstructure:"token"`
+	Key      string `mapstructure:"key"`
+	Value    string `mapstructure:"value"`
+	Location string `mapstructure:"location"`
 }
 
 // LoggingConfig holds logging configuration.
@@ -53,43 +209,40 @@ type LoggingConfig struct {
 	Enabled bool   `mapstructure:"enabled"`
 	Path    string `mapstructure:"path"`
 	Level   string `mapstructure:"level"`
+
+	// Wire logs every request and response in full, like curl -v, with
+	// credentials masked.
+	Wire bool `mapstructure:"wire"`
+
+	// WireDir, if set, receives a capture file per session for the wire
+	// log instead of Path.
+	WireDir string `mapstructure:"wire_dir"`
+
+	// WireBodyLimit is how many bytes of each body the wire log shows.
+	WireBodyLimit int `mapstructure:"wire_body_limit"`
 }
 
+// layoutFile is the name of the file the TUI saves its pane layout to.
+const layoutFile = "layout.yaml"
+
 // Load loads configuration from file, environment variables, and defaults.
 // It returns the merged configuration and any error encountered.
 func Load(configPath string) (*Config, error) {
-	v := viper.New()
+	v, err := newViper(configPath)
+	if err != nil {
+		return nil, err
+	}
 
 	// Set defaults.
 	setDefaults(v)
 
-	// Set config file path if provided.
-	if configPath != "" {
-		v.SetConfigFile(configPath)
-	} else {
-		// Default config locations.
-		configDir, err := getConfigDir()
-		if err != nil {
-			return nil, fmt.Errorf("failed to get config directory: %w", err)
-		}
-
-		v.SetConfigName("config")
-		v.SetConfigType("yaml")
-		v.AddConfigPath(configDir)
-		v.AddConfigPath(".") // Also look in current directory
-	}
-
 	// Read environment variables with CURLY_ prefix.
 	v.SetEnvPrefix("CURLY")
 	v.AutomaticEnv()
 
 	// Read config file.
-	if err := v.ReadInConfig(); err != nil {
-		// Config file is optional, only error on read errors (not file not found).
-		var configNotFound viper.ConfigFileNotFoundError
-		if !errors.As(err, &configNotFound) {
-			return nil, fmt.Errorf("failed to read config file: %w", err)
-		}
+	if _, err := readConfigFile(v); err != nil {
+		return nil, err
 	}
 
 	// Unmarshal into config struct.
@@ -98,6 +251,13 @@ func Load(configPath string) (*Config, error) {
 		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
 	}
 
+	cfg.UI.DefaultTabSet = v.InConfig("ui.default_tab")
+
+	// The layout saved by the TUI overrides the configured one.
+	if err := loadLayout(configPath, &cfg.UI.Layout); err != nil {
+		return nil, err
+	}
+
 	// Expand paths.
 	if err := expandPaths(&cfg); err != nil {
 		return nil, fmt.Errorf("failed to expand paths: %w", err)
@@ -106,12 +266,69 @@ func Load(configPath string) (*Config, error) {
 	return &cfg, nil
 }
 
+// newViper returns a viper instance that reads the config file at configPath,
+// or config.yaml from the config directory or the current directory when
+// configPath is empty.
+func newViper(configPath string) (*viper.Viper, error) {
+	v := viper.New()
+
+	// Set config file path if provided.
+	if configPath != "" {
+		v.SetConfigFile(configPath)
+		return v, nil
+	}
+
+	// Default config locations.
+	configDir, err := getConfigDir()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get config directory: %w", err)
+	}
+
+	v.SetConfigName("config")
+	v.SetConfigType("yaml")
+	v.AddConfigPath(configDir)
+	v.AddConfigPath(".") // Also look in current directory
+	return v, nil
+}
+
+// readConfigFile reads the config file into v and reports whether there was
+// one. The config file is optional, so only read errors are returned, naming
+// the file so a syntax error points at it.
+func readConfigFile(v *viper.Viper) (bool, error) {
+	if err := v.ReadInConfig(); err != nil {
+		var configNotFound viper.ConfigFileNotFoundError
+		if errors.As(err, &configNotFound) {
+			return false, nil
+		}
+		if path := v.ConfigFileUsed(); path != "" {
+			return false, fmt.Errorf("failed to read config file %s: %w", path, err)
+		}
+		return false, fmt.Errorf("failed to read config file: %w", err)
+	}
+	return true, nil
+}
+
 // setDefaults sets default configuration values.
 func setDefaults(v *viper.Viper) {
 	homeDir, _ := os.UserHomeDir()
 
+	// Workspace defaults.
+	v.SetDefault("workspace", "")
+
 	// Database defaults.
+	v.SetDefault("database.driver", "sqlite")
+	v.SetDefault("database.dsn", "")
 	v.SetDefault("database.path", filepath.Join(homeDir, ".local", "share", "curly", "curly.db"))
+	v.SetDefault("database.backup_before_migrate", true)
+	v.SetDefault("database.backup_dir", filepath.Join(homeDir, ".local", "share", "curly", "backups"))
+	v.SetDefault("database.max_backups", 5)
+	v.SetDefault("database.workspaces_dir", filepath.Join(homeDir, ".local", "share", "curly", "workspaces"))
+	v.SetDefault("database.journal_mode", "WAL")
+	v.SetDefault("database.synchronous", "NORMAL")
+	v.SetDefault("database.busy_timeout", 5*time.Second)
+	v.SetDefault("database.max_open_conns", 4)
+	v.SetDefault("database.max_idle_conns", 4)
+	v.SetDefault("database.conn_max_lifetime", time.Duration(0))
 
 	// HTTP defaults.
 	v.SetDefault("http.timeout", "30s")
@@ -119,21 +336,89 @@ func setDefaults(v *viper.Viper) {
 	v.SetDefault("http.follow_redirects", true)
 	v.SetDefault("http.insecure_skip_tls", false)
 
+	// GraphQL defaults.
+	v.SetDefault("graphql.schema_cache_dir", filepath.Join(homeDir, ".cache", "curly", "graphql"))
+
 	// UI defaults.
 	v.SetDefault("ui.theme", "dark")
 	v.SetDefault("ui.syntax_highlighting", true)
 	v.SetDefault("ui.show_response_time", true)
 	v.SetDefault("ui.default_tab", "request")
+	v.SetDefault("ui.image_preview", "auto")
+	v.SetDefault("ui.check_for_updates", false)
+	v.SetDefault("ui.autosave_interval", 5*time.Second)
+	v.SetDefault("ui.restore_session", true)
+	v.SetDefault("ui.layout.split", false)
+	v.SetDefault("ui.layout.split_ratio", 50)
 
 	// History defaults.
 	v.SetDefault("history.max_entries", 1000)
 	v.SetDefault("history.auto_cleanup", true)
 	v.SetDefault("history.cleanup_after_days", 90)
+	v.SetDefault("history.offload_threshold", 256*1024)
+	v.SetDefault("history.bodies_dir", filepath.Join(homeDir, ".local", "share", "curly", "bodies"))
+	v.SetDefault("history.archive_before_cleanup", false)
+	v.SetDefault("history.archive_dir", filepath.Join(homeDir, ".local", "share", "curly", "archive"))
 
 	// Logging defaults.
 	v.SetDefault("logging.enabled", true)
 	v.SetDefault("logging.path", filepath.Join(homeDir, ".cache", "curly", "curly.log"))
 	v.SetDefault("logging.level", "info")
+	v.SetDefault("logging.wire", false)
+	v.SetDefault("logging.wire_dir", "")
+	v.SetDefault("logging.wire_body_limit", 4096)
+}
+
+// LayoutPath returns the path of the layout file, layout.yaml, which sits
+// next to the config file at configPath, or in the config directory when
+// configPath is empty.
+func LayoutPath(configPath string) (string, error) {
+	if configPath != "" {
+		return filepath.Join(filepath.Dir(configPath), layoutFile), nil
+	}
+	configDir, err := getConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get config directory: %w", err)
+	}
+	return filepath.Join(configDir, layoutFile), nil
+}
+
+// SaveLayout saves the pane layout to the layout file for configPath.
+func SaveLayout(configPath string, layout LayoutConfig) error {
+	path, err := LayoutPath(configPath)
+	if err != nil {
+		return err
+	}
+
+	v := viper.New()
+	v.Set("ui.layout.split", layout.Split)
+	v.Set("ui.layout.split_ratio", layout.SplitRatio)
+	if err := v.WriteConfigAs(path); err != nil {
+		return fmt.Errorf("failed to save layout: %w", err)
+	}
+	return nil
+}
+
+// loadLayout reads the layout file for configPath into layout, if it exists.
+// Settings missing from the file are left unchanged.
+func loadLayout(configPath string, layout *LayoutConfig) error {
+	path, err := LayoutPath(configPath)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+
+	v := viper.New()
+	v.SetConfigFile(path)
+	if err := v.ReadInConfig(); err != nil {
+		return fmt.Errorf("failed to read layout file: %w", err)
+	}
+	if err := v.UnmarshalKey("ui.layout", layout); err != nil {
+		return fmt.Errorf("failed to unmarshal layout: %w", err)
+	}
+	return nil
 }
 
 // expandPaths expands ~ and environment variables in file paths.
@@ -145,11 +430,59 @@ func expandPaths(cfg *Config) error {
 		return fmt.Errorf("failed to expand database path: %w", err)
 	}
 
+	cfg.Database.BackupDir, err = expandPath(cfg.Database.BackupDir)
+	if err != nil {
+		return fmt.Errorf("failed to expand backup directory: %w", err)
+	}
+
+	cfg.Database.WorkspacesDir, err = expandPath(cfg.Database.WorkspacesDir)
+	if err != nil {
+		return fmt.Errorf("failed to expand workspaces directory: %w", err)
+	}
+
+	cfg.GraphQL.SchemaCacheDir, err = expandPath(cfg.GraphQL.SchemaCacheDir)
+	if err != nil {
+		return fmt.Errorf("failed to expand GraphQL schema cache directory: %w", err)
+	}
+
+	cfg.History.BodiesDir, err = expandPath(cfg.History.BodiesDir)
+	if err != nil {
+		return fmt.Errorf("failed to expand bodies directory: %w", err)
+	}
+
+	cfg.History.ArchiveDir, err = expandPath(cfg.History.ArchiveDir)
+	if err != nil {
+		return fmt.Errorf("failed to expand archive directory: %w", err)
+	}
+
 	cfg.Logging.Path, err = expandPath(cfg.Logging.Path)
 	if err != nil {
 		return fmt.Errorf("failed to expand logging path: %w", err)
 	}
 
+	cfg.Logging.WireDir, err = expandPath(cfg.Logging.WireDir)
+	if err != nil {
+		return fmt.Errorf("failed to expand wire log directory: %w", err)
+	}
+
+	for i := range cfg.Hosts {
+		host := &cfg.Hosts[i]
+		for _, certPath := range []*string{&host.CACert, &host.ClientCert, &host.ClientKey} {
+			*certPath, err = expandPath(*certPath)
+			if err != nil {
+				return fmt.Errorf("failed to expand certificate path of host %s: %w", host.Pattern, err)
+			}
+		}
+	}
+
+	for i := range cfg.Hooks {
+		hook := &cfg.Hooks[i]
+		hook.Command, err = expandPath(hook.Command)
+		if err != nil {
+			return fmt.Errorf("failed to expand command of hook %s: %w", hook.Command, err)
+		}
+	}
+
 	return nil
 }
 
@@ -197,12 +530,17 @@ func EnsureDirectories(cfg *Config) error {
 	}
 
 	// Create log directory.
-	if cfg.Logging.Enabled {
+	if cfg.Logging.Enabled || cfg.Logging.Wire && cfg.Logging.WireDir == "" {
 		logDir := filepath.Dir(cfg.Logging.Path)
 		if err := os.MkdirAll(logDir, 0750); err != nil {
 			return fmt.Errorf("failed to create log directory: %w", err)
 		}
 	}
+	if cfg.Logging.Wire && cfg.Logging.WireDir != "" {
+		if err := os.MkdirAll(cfg.Logging.WireDir, 0750); err != nil {
+			return fmt.Errorf("failed to create wire log directory: %w", err)
+		}
+	}
 
 	// Create config directory.
 	configDir, err := getConfigDir()
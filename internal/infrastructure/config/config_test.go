@@ -27,13 +27,42 @@ func TestLoad_Defaults(t *testing.T) {
 	assert.True(t, cfg.UI.SyntaxHighlighting)
 	assert.True(t, cfg.UI.ShowResponseTime)
 	assert.Equal(t, "request", cfg.UI.DefaultTab)
+	assert.False(t, cfg.UI.DefaultTabSet)
+	assert.Equal(t, "auto", cfg.UI.ImagePreview)
+	assert.False(t, cfg.UI.CheckForUpdates)
+	assert.Equal(t, 5*time.Second, cfg.UI.AutosaveInterval)
+	assert.True(t, cfg.UI.RestoreSession)
+	assert.False(t, cfg.UI.Layout.Split)
+	assert.Equal(t, 50, cfg.UI.Layout.SplitRatio)
+
+	assert.Equal(t, "sqlite", cfg.Database.Driver)
+	assert.Empty(t, cfg.Database.DSN)
+	assert.True(t, cfg.Database.BackupBeforeMigrate)
+	assert.Equal(t, 5, cfg.Database.MaxBackups)
+	assert.NotEmpty(t, cfg.Database.BackupDir)
+	assert.NotEmpty(t, cfg.Database.WorkspacesDir)
+	assert.Empty(t, cfg.Workspace)
+	assert.Equal(t, "WAL", cfg.Database.JournalMode)
+	assert.Equal(t, "NORMAL", cfg.Database.Synchronous)
+	assert.Equal(t, 5*time.Second, cfg.Database.BusyTimeout)
+	assert.Equal(t, 4, cfg.Database.MaxOpenConns)
+	assert.Equal(t, 4, cfg.Database.MaxIdleConns)
+	assert.Zero(t, cfg.Database.ConnMaxLifetime)
+
+	assert.NotEmpty(t, cfg.GraphQL.SchemaCacheDir)
 
 	assert.Equal(t, 1000, cfg.History.MaxEntries)
 	assert.True(t, cfg.History.AutoCleanup)
 	assert.Equal(t, 90, cfg.History.CleanupAfterDays)
+	assert.Equal(t, 256*1024, cfg.History.OffloadThreshold)
+	assert.NotEmpty(t, cfg.History.BodiesDir)
+	assert.False(t, cfg.History.ArchiveBeforeCleanup)
+	assert.NotEmpty(t, cfg.History.ArchiveDir)
 
 	assert.True(t, cfg.Logging.Enabled)
 	assert.Equal(t, "info", cfg.Logging.Level)
+	assert.False(t, cfg.Logging.Wire)
+	assert.Equal(t, 4096, cfg.Logging.WireBodyLimit)
 }
 
 func TestLoad_CustomConfigFile(t *testing.T) {
@@ -43,7 +72,9 @@ func TestLoad_CustomConfigFile(t *testing.T) {
 
 	configContent := `
 database:
+  driver: postgres
   path: /tmp/test.db
+  dsn: postgres://curly@localhost:5432/curly
 
 http:
   timeout: 60s
@@ -56,6 +87,9 @@ ui:
   syntax_highlighting: false
   show_response_time: false
   default_tab: history
+  check_for_updates: true
+  autosave_interval: 0s
+  restore_session: false
 
 history:
   max_entries: 500
@@ -66,6 +100,16 @@ logging:
   enabled: false
   path: /tmp/test.log
   level: debug
+  wire: true
+  wire_dir: /tmp/wire
+  wire_body_limit: 512
+
+schedules:
+  - name: uptime
+    cron: "*/5 * * * *"
+    request: Health
+  - cron: "@daily"
+    folder: smoke
 `
 
 	err := os.WriteFile(configFile, []byte(configContent), 0600)
@@ -77,7 +121,9 @@ logging:
 	require.NotNil(t, cfg)
 
 	// Verify custom values.
+	assert.Equal(t, "postgres", cfg.Database.Driver)
 	assert.Equal(t, "/tmp/test.db", cfg.Database.Path)
+	assert.Equal(t, "postgres://curly@localhost:5432/curly", cfg.Database.DSN)
 	assert.Equal(t, 60*time.Second, cfg.HTTP.Timeout)
 	assert.Equal(t, 5, cfg.HTTP.MaxRedirects)
 	assert.False(t, cfg.HTTP.FollowRedirects)
@@ -87,6 +133,10 @@ logging:
 	assert.False(t, cfg.UI.SyntaxHighlighting)
 	assert.False(t, cfg.UI.ShowResponseTime)
 	assert.Equal(t, "history", cfg.UI.DefaultTab)
+	assert.True(t, cfg.UI.DefaultTabSet)
+	assert.True(t, cfg.UI.CheckForUpdates)
+	assert.Zero(t, cfg.UI.AutosaveInterval)
+	assert.False(t, cfg.UI.RestoreSession)
 
 	assert.Equal(t, 500, cfg.History.MaxEntries)
 	assert.False(t, cfg.History.AutoCleanup)
@@ -95,6 +145,159 @@ logging:
 	assert.False(t, cfg.Logging.Enabled)
 	assert.Equal(t, "/tmp/test.log", cfg.Logging.Path)
 	assert.Equal(t, "debug", cfg.Logging.Level)
+	assert.True(t, cfg.Logging.Wire)
+	assert.Equal(t, "/tmp/wire", cfg.Logging.WireDir)
+	assert.Equal(t, 512, cfg.Logging.WireBodyLimit)
+
+	assert.Equal(t, []ScheduleConfig{
+		{Name: "uptime", Cron: "*/5 * * * *", Request: "Health"},
+		{Cron: "@daily", Folder: "smoke"},
+	}, cfg.Schedules)
+}
+
+func TestLoad_Themes(t *testing.T) {
+	tmpDir := t.TempDir()
+	configFile := filepath.Join(tmpDir, "config.yaml")
+
+	configContent := `
+ui:
+  theme: solar
+  themes:
+    solar:
+      base: light
+      primary: "#B58900"
+      border_active: "33"
+      status:
+        2xx: "#859900"
+      methods:
+        GET: "#268BD2"
+`
+	require.NoError(t, os.WriteFile(configFile, []byte(configContent), 0600))
+
+	cfg, err := Load(configFile)
+	require.NoError(t, err)
+
+	assert.Equal(t, "solar", cfg.UI.Theme)
+	require.Contains(t, cfg.UI.Themes, "solar")
+	theme := cfg.UI.Themes["solar"]
+	assert.Equal(t, "light", theme.Base)
+	assert.Equal(t, "#B58900", theme.Primary)
+	assert.Equal(t, "33", theme.BorderActive)
+	assert.Equal(t, map[string]string{"2xx": "#859900"}, theme.Status)
+	// Keys are case-insensitive in configuration files.
+	assert.Equal(t, map[string]string{"get": "#268BD2"}, theme.Methods)
+}
+
+func TestLoad_Keybindings(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "config.yaml")
+	configContent := `
+ui:
+  keybindings:
+    Finder: ctrl+k
+    copy_curl_secrets: alt+C
+`
+	require.NoError(t, os.WriteFile(configFile, []byte(configContent), 0600))
+
+	cfg, err := Load(configFile)
+	require.NoError(t, err)
+
+	// Actions are case-insensitive; keys keep their case.
+	assert.Equal(t, map[string]string{"finder": "ctrl+k", "copy_curl_secrets": "alt+C"}, cfg.UI.Keybindings)
+}
+
+func TestLoad_Hosts(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	configFile := filepath.Join(t.TempDir(), "config.yaml")
+	configContent := `
+hosts:
+  - pattern: "*.internal.corp"
+    headers:
+      X-Team: payments
+    auth:
+      type: bearer
+      token: s3cret
+    timeout: 10s
+    proxy: http://proxy.internal.corp:3128
+    ca_cert: ~/certs/corp-ca.pem
+`
+	require.NoError(t, os.WriteFile(configFile, []byte(configContent), 0600))
+
+	cfg, err := Load(configFile)
+	require.NoError(t, err)
+
+	require.Len(t, cfg.Hosts, 1)
+	host := cfg.Hosts[0]
+	assert.Equal(t, "*.internal.corp", host.Pattern)
+	// Header names are lowercased like all keys; requests canonicalize them.
+	assert.Equal(t, map[string]string{"x-team": "payments"}, host.Headers)
+	assert.Equal(t, HostAuthConfig{Type: "bearer", Token: "s3cret"}, host.Auth)
+	assert.Equal(t, 10*time.Second, host.Timeout)
+	assert.Equal(t, "http://proxy.internal.corp:3128", host.Proxy)
+	assert.Equal(t, filepath.Join(os.Getenv("HOME"), "certs", "corp-ca.pem"), host.CACert)
+	assert.Empty(t, cfg.Validate())
+}
+
+func TestLoad_Hooks(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	configFile := filepath.Join(t.TempDir(), "config.yaml")
+	configContent := `
+hooks:
+  - event: pre_request
+    command: ~/bin/sign-request
+    args: [--key-id, payments]
+    pattern: "*.internal.corp"
+    timeout: 5s
+  - event: on_save
+    command: strip-secrets
+`
+	require.NoError(t, os.WriteFile(configFile, []byte(configContent), 0600))
+
+	cfg, err := Load(configFile)
+	require.NoError(t, err)
+
+	assert.Equal(t, []HookConfig{
+		{
+			Event:   "pre_request",
+			Command: filepath.Join(os.Getenv("HOME"), "bin", "sign-request"),
+			Args:    []string{"--key-id", "payments"},
+			Pattern: "*.internal.corp",
+			Timeout: 5 * time.Second,
+		},
+		{Event: "on_save", Command: "strip-secrets"},
+	}, cfg.Hooks)
+	assert.Empty(t, cfg.Validate())
+}
+
+func TestLayout_SaveAndLoad(t *testing.T) {
+	tmpDir := t.TempDir()
+	configFile := filepath.Join(tmpDir, "config.yaml")
+	require.NoError(t, os.WriteFile(configFile, []byte("ui:\n  layout:\n    split: false\n    split_ratio: 40\n"), 0600))
+
+	cfg, err := Load(configFile)
+	require.NoError(t, err)
+	assert.Equal(t, LayoutConfig{Split: false, SplitRatio: 40}, cfg.UI.Layout)
+
+	path, err := LayoutPath(configFile)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(tmpDir, "layout.yaml"), path)
+
+	// The saved layout overrides the configured one.
+	require.NoError(t, SaveLayout(configFile, LayoutConfig{Split: true, SplitRatio: 65}))
+	cfg, err = Load(configFile)
+	require.NoError(t, err)
+	assert.Equal(t, LayoutConfig{Split: true, SplitRatio: 65}, cfg.UI.Layout)
+}
+
+func TestLayout_PartialFile(t *testing.T) {
+	tmpDir := t.TempDir()
+	configFile := filepath.Join(tmpDir, "config.yaml")
+	require.NoError(t, os.WriteFile(configFile, []byte("ui:\n  layout:\n    split_ratio: 30\n"), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "layout.yaml"), []byte("ui:\n  layout:\n    split: true\n"), 0600))
+
+	cfg, err := Load(configFile)
+	require.NoError(t, err)
+	assert.True(t, cfg.UI.Layout.Split)
+	assert.Equal(t, 30, cfg.UI.Layout.SplitRatio, "settings missing from the layout file are kept")
 }
 
 func TestExpandPath(t *testing.T) {
@@ -212,7 +415,7 @@ database:
 	// Load config should fail.
 	_, err = Load(configFile)
 	assert.Error(t, err)
-	assert.Contains(t, err.Error(), "failed to read config file")
+	assert.Contains(t, err.Error(), "failed to read config file "+configFile)
 }
 
 func TestEnsureDirectories_LoggingDisabled(t *testing.T) {
@@ -328,3 +531,175 @@ func TestEnsureDirectories_NestedPaths(t *testing.T) {
 	assert.DirExists(t, dbDir)
 	assert.DirExists(t, logDir)
 }
+
+func TestDefaultFile_MatchesDefaults(t *testing.T) {
+	tmpDir := t.TempDir()
+	configFile := filepath.Join(tmpDir, "config.yaml")
+	require.NoError(t, WriteDefault(configFile, false))
+
+	fromFile, err := Load(configFile)
+	require.NoError(t, err)
+	defaults, err := Load(emptyConfigFile(t))
+	require.NoError(t, err)
+	assert.Equal(t, defaults, fromFile)
+
+	unknown, err := UnknownKeys(configFile)
+	require.NoError(t, err)
+	assert.Empty(t, unknown)
+	assert.Empty(t, fromFile.Validate())
+}
+
+func TestWriteDefault_ExistingFile(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "curly", "config.yaml")
+	require.NoError(t, WriteDefault(configFile, false))
+	require.NoError(t, os.WriteFile(configFile, []byte("workspace: work\n"), 0600))
+
+	err := WriteDefault(configFile, false)
+	assert.ErrorContains(t, err, "already exists")
+	data, err := os.ReadFile(configFile)
+	require.NoError(t, err)
+	assert.Equal(t, "workspace: work\n", string(data))
+
+	require.NoError(t, WriteDefault(configFile, true))
+	data, err = os.ReadFile(configFile)
+	require.NoError(t, err)
+	assert.Equal(t, DefaultFile, string(data))
+}
+
+func TestPath(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Chdir(t.TempDir())
+	configDir, err := getConfigDir()
+	require.NoError(t, err)
+
+	path, exists, err := Path("")
+	require.NoError(t, err)
+	assert.False(t, exists)
+	assert.Equal(t, filepath.Join(configDir, "config.yaml"), path)
+
+	require.NoError(t, WriteDefault(filepath.Join(configDir, "config.yaml"), false))
+	path, exists, err = Path("")
+	require.NoError(t, err)
+	assert.True(t, exists)
+	assert.Equal(t, filepath.Join(configDir, "config.yaml"), path)
+
+	path, exists, err = Path("/nonexistent/curly.yaml")
+	require.NoError(t, err)
+	assert.False(t, exists)
+	assert.Equal(t, "/nonexistent/curly.yaml", path)
+}
+
+func TestUnknownKeys(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "config.yaml")
+	configContent := `
+databse:
+  path: /tmp/test.db
+http:
+  timout: 10s
+ui:
+  themes:
+    mine:
+      primary: "#ffffff"
+      primry: "#000000"
+schedules:
+  - cron: "@daily"
+    folder: smoke
+    Reqest: Health
+hosts:
+  - pattern: localhost
+    headers:
+      X-Anything: allowed
+    auth:
+      tokn: s3cret
+`
+	require.NoError(t, os.WriteFile(configFile, []byte(configContent), 0600))
+
+	unknown, err := UnknownKeys(configFile)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"databse", "hosts[0].auth.tokn", "http.timout", "schedules[0].reqest", "ui.themes.mine.primry"}, unknown)
+}
+
+func TestConfig_Validate(t *testing.T) {
+	cfg, err := Load(emptyConfigFile(t))
+	require.NoError(t, err)
+	assert.Empty(t, cfg.Validate())
+
+	cfg.Database.Driver = "postgres"
+	cfg.Database.JournalMode = "wal"
+	cfg.Database.Synchronous = "sometimes"
+	cfg.HTTP.Timeout = -time.Second
+	cfg.UI.AutosaveInterval = -time.Second
+	cfg.Logging.Level = "verbose"
+	cfg.Logging.WireBodyLimit = -1
+	cfg.Schedules = []ScheduleConfig{
+		{Cron: "@daily", Request: "Health"},
+		{Cron: "every day", Request: "Health", Folder: "smoke"},
+	}
+	cfg.Hosts = []HostConfig{
+		{Pattern: "api.example.com", Auth: HostAuthConfig{Type: "basic", Username: "me"}},
+		{Timeout: -time.Second, Proxy: "proxy:3128", ClientCert: "me.pem"},
+		{Pattern: "[", Auth: HostAuthConfig{Type: "apikey", Location: "cookie"}},
+		{Pattern: "*", Auth: HostAuthConfig{Type: "digest"}},
+	}
+	cfg.Hooks = []HookConfig{
+		{Event: "pre_request", Command: "sign"},
+		{Event: "pre_send", Pattern: "[", Timeout: -time.Second},
+		{Command: "sign"},
+	}
+
+	assert.Equal(t, []string{
+		"database.dsn: required by the postgres driver",
+		`database.synchronous: "sometimes" is not one of OFF, NORMAL, FULL, EXTRA`,
+		"http.timeout: must not be negative",
+		"ui.autosave_interval: must not be negative",
+		`logging.level: "verbose" is not one of debug, info, warn, error`,
+		"logging.wire_body_limit: must not be negative",
+		"schedules[1]: exactly one of request and folder must be set",
+		"schedules[1].cron: expected 5 fields (minute hour day-of-month month day-of-week), got 2",
+		"hosts[1].pattern: required",
+		"hosts[1].timeout: must not be negative",
+		`hosts[1].proxy: "proxy:3128" is not a URL`,
+		"hosts[1]: client_cert and client_key must be set together",
+		"hosts[2].pattern: syntax error in pattern",
+		"hosts[2].auth.key: required by apikey authentication",
+		`hosts[2].auth.location: "cookie" is not one of header, query`,
+		`hosts[3].auth.type: "digest" is not one of basic, bearer, apikey`,
+		`hooks[1].event: "pre_send" is not one of pre_request, post_response, on_save`,
+		"hooks[1].command: required",
+		"hooks[1].pattern: syntax error in pattern",
+		"hooks[1].timeout: must not be negative",
+		"hooks[2].event: required",
+	}, cfg.Validate())
+}
+
+func TestMarshal(t *testing.T) {
+	cfg, err := Load(emptyConfigFile(t))
+	require.NoError(t, err)
+	cfg.UI.Themes = map[string]ThemeConfig{"mine": {Base: "light", Status: map[string]string{"2xx": "#00ff00"}}}
+	cfg.Schedules = []ScheduleConfig{{Name: "uptime", Cron: "*/5 * * * *", Request: "Health"}}
+	cfg.Hosts = []HostConfig{{Pattern: "*.internal.corp", Headers: map[string]string{"x-team": "payments"}, Auth: HostAuthConfig{Type: "bearer", Token: "s3cret"}, Timeout: 10 * time.Second}}
+	cfg.Hooks = []HookConfig{{Event: "pre_request", Command: "/usr/local/bin/sign", Args: []string{"--key-id", "payments"}}}
+
+	data, err := Marshal(cfg)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "workspace: \"\"\ndatabase:\n  driver: sqlite\n")
+	assert.Contains(t, string(data), "  timeout: 30s\n")
+
+	// The output is a config file that loads back to the same settings, with
+	// the default tab now set in it.
+	configFile := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(configFile, data, 0600))
+	loaded, err := Load(configFile)
+	require.NoError(t, err)
+	cfg.UI.DefaultTabSet = true
+	assert.Equal(t, cfg, loaded)
+}
+
+// emptyConfigFile returns the path of an empty config file, which loads the
+// defaults.
+func emptyConfigFile(t *testing.T) string {
+	t.Helper()
+	configFile := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(configFile, nil, 0600))
+	return configFile
+}
@@ -0,0 +1,232 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/williajm/curly/internal/infrastructure/cron"
+)
+
+// Valid values of the settings Validate checks against a fixed list.
+var (
+	drivers      = []string{"sqlite", "postgres"}
+	journalModes = []string{"DELETE", "TRUNCATE", "PERSIST", "MEMORY", "WAL", "OFF"}
+	syncModes    = []string{"OFF", "NORMAL", "FULL", "EXTRA"}
+	logLevels    = []string{"debug", "info", "warn", "error"}
+	authTypes    = []string{"basic", "bearer", "apikey"}
+	keyLocations = []string{"header", "query"}
+	hookEvents   = []string{"pre_request", "post_response", "on_save"}
+)
+
+// UnknownKeys returns the keys in the config file for configPath that curly
+// does not read, such as misspelled settings, which Load silently ignores.
+// It returns nil if there is no config file.
+func UnknownKeys(configPath string) ([]string, error) {
+	v, err := newViper(configPath)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := readConfigFile(v); err != nil {
+		return nil, err
+	}
+
+	var unknown []string
+	collectUnknownKeys("", v.AllSettings(), reflect.TypeOf(Config{}), &unknown)
+	sort.Strings(unknown)
+	return unknown, nil
+}
+
+// collectUnknownKeys appends to unknown the keys of value, the setting at
+// prefix, that have no counterpart in t.
+func collectUnknownKeys(prefix string, value any, t reflect.Type, unknown *[]string) {
+	switch t.Kind() {
+	case reflect.Struct:
+		settings, ok := value.(map[string]any)
+		if !ok {
+			return
+		}
+		for key, setting := range settings {
+			field, ok := fieldByTag(t, key)
+			if !ok {
+				*unknown = append(*unknown, prefix+key)
+				continue
+			}
+			collectUnknownKeys(prefix+key+".", setting, field.Type, unknown)
+		}
+
+	case reflect.Map:
+		settings, ok := value.(map[string]any)
+		if !ok {
+			return
+		}
+		for key, setting := range settings {
+			collectUnknownKeys(prefix+key+".", setting, t.Elem(), unknown)
+		}
+
+	case reflect.Slice:
+		items, ok := value.([]any)
+		if !ok {
+			return
+		}
+		for i, item := range items {
+			itemPrefix := fmt.Sprintf("%s[%d].", strings.TrimSuffix(prefix, "."), i)
+			if settings, ok := item.(map[string]any); ok {
+				item = lowerKeys(settings)
+			}
+			collectUnknownKeys(itemPrefix, item, t.Elem(), unknown)
+		}
+	}
+}
+
+// fieldByTag returns the field of t whose mapstructure tag is key.
+func fieldByTag(t reflect.Type, key string) (reflect.StructField, bool) {
+	for i := range t.NumField() {
+		field := t.Field(i)
+		if strings.EqualFold(field.Tag.Get("mapstructure"), key) {
+			return field, true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+// lowerKeys lowercases the keys of settings, as viper does for all keys but
+// those of maps inside lists.
+func lowerKeys(settings map[string]any) map[string]any {
+	lowered := make(map[string]any, len(settings))
+	for key, value := range settings {
+		lowered[strings.ToLower(key)] = value
+	}
+	return lowered
+}
+
+// Validate checks the settings of cfg that Load cannot: values outside their
+// allowed set, negative sizes and durations, and invalid schedules, hosts
+// and hooks. It returns a description of each problem, naming the setting.
+// Themes, keybindings, the default tab, the image preview and the
+// certificate files of hosts are checked where they are used.
+func (c *Config) Validate() []string {
+	v := &validator{}
+
+	v.oneOf("database.driver", c.Database.Driver, drivers, false)
+	if c.Database.Driver == "postgres" && c.Database.DSN == "" {
+		v.add("database.dsn: required by the postgres driver")
+	}
+	v.oneOf("database.journal_mode", c.Database.JournalMode, journalModes, true)
+	v.oneOf("database.synchronous", c.Database.Synchronous, syncModes, true)
+	v.notNegative("database.max_backups", int64(c.Database.MaxBackups))
+	v.notNegative("database.busy_timeout", int64(c.Database.BusyTimeout))
+	v.notNegative("database.max_open_conns", int64(c.Database.MaxOpenConns))
+	v.notNegative("database.max_idle_conns", int64(c.Database.MaxIdleConns))
+	v.notNegative("database.conn_max_lifetime", int64(c.Database.ConnMaxLifetime))
+
+	v.notNegative("http.timeout", int64(c.HTTP.Timeout))
+	v.notNegative("http.max_redirects", int64(c.HTTP.MaxRedirects))
+
+	v.notNegative("ui.autosave_interval", int64(c.UI.AutosaveInterval))
+
+	v.notNegative("history.max_entries", int64(c.History.MaxEntries))
+	v.notNegative("history.cleanup_after_days", int64(c.History.CleanupAfterDays))
+	v.notNegative("history.offload_threshold", int64(c.History.OffloadThreshold))
+
+	v.oneOf("logging.level", c.Logging.Level, logLevels, false)
+	v.notNegative("logging.wire_body_limit", int64(c.Logging.WireBodyLimit))
+
+	for i, schedule := range c.Schedules {
+		v.schedule(fmt.Sprintf("schedules[%d]", i), schedule)
+	}
+	for i, host := range c.Hosts {
+		v.host(fmt.Sprintf("hosts[%d]", i), host)
+	}
+	for i, hook := range c.Hooks {
+		v.hook(fmt.Sprintf("hooks[%d]", i), hook)
+	}
+
+	return v.problems
+}
+
+// validator accumulates the problems found by Validate.
+type validator struct {
+	problems []string
+}
+
+// add records a problem.
+func (v *validator) add(format string, args ...any) {
+	v.problems = append(v.problems, fmt.Sprintf(format, args...))
+}
+
+// oneOf checks that a setting, if set, has one of the allowed values,
+// ignoring case if fold is set.
+func (v *validator) oneOf(key, value string, allowed []string, fold bool) {
+	if value == "" {
+		return
+	}
+	for _, a := range allowed {
+		if value == a || (fold && strings.EqualFold(value, a)) {
+			return
+		}
+	}
+	v.add("%s: %q is not one of %s", key, value, strings.Join(allowed, ", "))
+}
+
+// notNegative checks that a size or duration setting is not negative.
+func (v *validator) notNegative(key string, value int64) {
+	if value < 0 {
+		v.add("%s: must not be negative", key)
+	}
+}
+
+// schedule checks a scheduled execution.
+func (v *validator) schedule(key string, schedule ScheduleConfig) {
+	if (schedule.Request == "") == (schedule.Folder == "") {
+		v.add("%s: exactly one of request and folder must be set", key)
+	}
+	if _, err := cron.Parse(schedule.Cron); err != nil {
+		v.add("%s.cron: %v", key, err)
+	}
+}
+
+// host checks the settings for a host pattern.
+func (v *validator) host(key string, host HostConfig) {
+	if host.Pattern == "" {
+		v.add("%s.pattern: required", key)
+	} else if _, err := path.Match(host.Pattern, ""); err != nil {
+		v.add("%s.pattern: %v", key, err)
+	}
+	v.notNegative(key+".timeout", int64(host.Timeout))
+	if host.Proxy != "" {
+		if proxy, err := url.Parse(host.Proxy); err != nil || proxy.Scheme == "" || proxy.Host == "" {
+			v.add("%s.proxy: %q is not a URL", key, host.Proxy)
+		}
+	}
+	if (host.ClientCert == "") != (host.ClientKey == "") {
+		v.add("%s: client_cert and client_key must be set together", key)
+	}
+	v.oneOf(key+".auth.type", host.Auth.Type, authTypes, false)
+	if host.Auth.Type == "apikey" {
+		if host.Auth.Key == "" {
+			v.add("%s.auth.key: required by apikey authentication", key)
+		}
+		v.oneOf(key+".auth.location", host.Auth.Location, keyLocations, false)
+	}
+}
+
+// hook checks a hook.
+func (v *validator) hook(key string, hook HookConfig) {
+	if hook.Event == "" {
+		v.add("%s.event: required", key)
+	}
+	v.oneOf(key+".event", hook.Event, hookEvents, false)
+	if hook.Command == "" {
+		v.add("%s.command: required", key)
+	}
+	if hook.Pattern != "" {
+		if _, err := path.Match(hook.Pattern, ""); err != nil {
+			v.add("%s.pattern: %v", key, err)
+		}
+	}
+	v.notNegative(key+".timeout", int64(hook.Timeout))
+}
@@ -0,0 +1,246 @@
+// Package cron parses cron expressions and computes their activation times.
+//
+// Expressions use the standard five fields (minute, hour, day of month,
+// month, day of week) with lists, ranges, steps and month or weekday names,
+// plus the shorthands @hourly, @daily, @midnight, @weekly, @monthly, @yearly,
+// @annually and @every <duration>.
+package cron
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule is a parsed cron expression.
+type Schedule struct {
+	// every is the fixed interval of an @every expression (zero otherwise).
+	every time.Duration
+
+	// Allowed values of each field, as bit sets.
+	minute, hour, dom, month, dow uint64
+
+	// domStar and dowStar record unrestricted day fields, which decide how
+	// the two day fields combine.
+	domStar, dowStar bool
+}
+
+// field describes the range and names of a cron field.
+type field struct {
+	name     string
+	min, max int
+	names    map[string]int
+}
+
+var (
+	minuteField = field{name: "minute", min: 0, max: 59}
+	hourField   = field{name: "hour", min: 0, max: 23}
+	domField    = field{name: "day of month", min: 1, max: 31}
+	monthField  = field{name: "month", min: 1, max: 12, names: map[string]int{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	}}
+	// Day of week accepts 7 as an alias for Sunday.
+	dowField = field{name: "day of week", min: 0, max: 7, names: map[string]int{
+		"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	}}
+)
+
+// shorthands maps the @ expressions to their five-field equivalents.
+var shorthands = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// MinInterval is the shortest @every interval Parse accepts. Shorter
+// intervals would fire requests in a busy loop.
+const MinInterval = time.Second
+
+// searchLimit bounds how far ahead Next looks for an activation time.
+// Every valid expression other than an impossible date such as 30 February
+// activates within this window.
+const searchLimit = 5 * 366 * 24 * time.Hour
+
+// Parse parses a cron expression.
+func Parse(spec string) (*Schedule, error) {
+	spec = strings.TrimSpace(spec)
+
+	if rest, ok := strings.CutPrefix(spec, "@every "); ok {
+		every, err := time.ParseDuration(strings.TrimSpace(rest))
+		if err != nil {
+			return nil, fmt.Errorf("invalid @every duration: %w", err)
+		}
+		if every <= 0 {
+			return nil, fmt.Errorf("@every duration must be positive, got %s", every)
+		}
+		if every < MinInterval {
+			return nil, fmt.Errorf("@every duration must be at least %s, got %s", MinInterval, every)
+		}
+		return &Schedule{every: every}, nil
+	}
+
+	if expanded, ok := shorthands[strings.ToLower(spec)]; ok {
+		spec = expanded
+	} else if strings.HasPrefix(spec, "@") {
+		return nil, fmt.Errorf("unknown shorthand %q", spec)
+	}
+
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("expected 5 fields (minute hour day-of-month month day-of-week), got %d", len(fields))
+	}
+
+	s := &Schedule{
+		domStar: fields[2] == "*",
+		dowStar: fields[4] == "*",
+	}
+	var err error
+	if s.minute, err = parseField(fields[0], minuteField); err != nil {
+		return nil, err
+	}
+	if s.hour, err = parseField(fields[1], hourField); err != nil {
+		return nil, err
+	}
+	if s.dom, err = parseField(fields[2], domField); err != nil {
+		return nil, err
+	}
+	if s.month, err = parseField(fields[3], monthField); err != nil {
+		return nil, err
+	}
+	if s.dow, err = parseField(fields[4], dowField); err != nil {
+		return nil, err
+	}
+	if s.dow&(1<<7) != 0 {
+		s.dow |= 1 << 0
+	}
+
+	return s, nil
+}
+
+// parseField parses a comma-separated list of values, ranges and steps.
+func parseField(text string, f field) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(text, ",") {
+		b, err := parsePart(part, f)
+		if err != nil {
+			return 0, fmt.Errorf("invalid %s %q: %w", f.name, text, err)
+		}
+		bits |= b
+	}
+	return bits, nil
+}
+
+// parsePart parses one list element: *, a value, or a range, with an optional step.
+func parsePart(part string, f field) (uint64, error) {
+	rangeText, stepText, hasStep := strings.Cut(part, "/")
+
+	step := 1
+	if hasStep {
+		var err error
+		step, err = strconv.Atoi(stepText)
+		if err != nil || step <= 0 {
+			return 0, fmt.Errorf("step must be a positive number")
+		}
+	}
+
+	var lo, hi int
+	switch {
+	case rangeText == "*":
+		lo, hi = f.min, f.max
+	case strings.Contains(rangeText, "-"):
+		loText, hiText, _ := strings.Cut(rangeText, "-")
+		var err error
+		if lo, err = parseValue(loText, f); err != nil {
+			return 0, err
+		}
+		if hi, err = parseValue(hiText, f); err != nil {
+			return 0, err
+		}
+		if lo > hi {
+			return 0, fmt.Errorf("range start %d is after end %d", lo, hi)
+		}
+	default:
+		var err error
+		if lo, err = parseValue(rangeText, f); err != nil {
+			return 0, err
+		}
+		// "5/15" means every 15 starting at 5.
+		hi = lo
+		if hasStep {
+			hi = f.max
+		}
+	}
+
+	var bits uint64
+	for v := lo; v <= hi; v += step {
+		bits |= 1 << uint(v)
+	}
+	return bits, nil
+}
+
+// parseValue parses a number or name within the field's range.
+func parseValue(text string, f field) (int, error) {
+	if v, ok := f.names[strings.ToLower(text)]; ok {
+		return v, nil
+	}
+	v, err := strconv.Atoi(text)
+	if err != nil {
+		return 0, fmt.Errorf("%q is not a number", text)
+	}
+	if v < f.min || v > f.max {
+		return 0, fmt.Errorf("%d is outside %d-%d", v, f.min, f.max)
+	}
+	return v, nil
+}
+
+// Next returns the first activation time after t, in t's location.
+// Field expressions activate on whole minutes. It returns the zero time if
+// the expression never activates, such as "0 0 30 2 *".
+func (s *Schedule) Next(t time.Time) time.Time {
+	if s.every > 0 {
+		return t.Add(s.every)
+	}
+
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.Add(searchLimit)
+
+	for t.Before(limit) {
+		if s.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if !s.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if s.hour&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+			continue
+		}
+		if s.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+
+	return time.Time{}
+}
+
+// dayMatches applies cron's day rule: when both day fields are restricted,
+// a day matches if either does.
+func (s *Schedule) dayMatches(t time.Time) bool {
+	domMatch := s.dom&(1<<uint(t.Day())) != 0
+	dowMatch := s.dow&(1<<uint(t.Weekday())) != 0
+
+	if s.domStar || s.dowStar {
+		return domMatch && dowMatch
+	}
+	return domMatch || dowMatch
+}
@@ -0,0 +1,90 @@
+package cron
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// base is a Wednesday.
+var base = time.Date(2025, 1, 15, 10, 30, 45, 0, time.UTC)
+
+func TestNext(t *testing.T) {
+	tests := []struct {
+		spec string
+		want time.Time
+	}{
+		{spec: "* * * * *", want: time.Date(2025, 1, 15, 10, 31, 0, 0, time.UTC)},
+		{spec: "*/15 * * * *", want: time.Date(2025, 1, 15, 10, 45, 0, 0, time.UTC)},
+		{spec: "5/20 * * * *", want: time.Date(2025, 1, 15, 10, 45, 0, 0, time.UTC)},
+		{spec: "0 9-17 * * *", want: time.Date(2025, 1, 15, 11, 0, 0, 0, time.UTC)},
+		{spec: "0 8,20 * * *", want: time.Date(2025, 1, 15, 20, 0, 0, 0, time.UTC)},
+		{spec: "30 2 * * *", want: time.Date(2025, 1, 16, 2, 30, 0, 0, time.UTC)},
+		{spec: "0 0 * * MON-FRI", want: time.Date(2025, 1, 16, 0, 0, 0, 0, time.UTC)},
+		{spec: "0 0 * * 7", want: time.Date(2025, 1, 19, 0, 0, 0, 0, time.UTC)},
+		{spec: "0 0 1 mar *", want: time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)},
+		{spec: "0 0 29 2 *", want: time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)},
+		// Both day fields restricted: either may match.
+		{spec: "0 0 20 * sat", want: time.Date(2025, 1, 18, 0, 0, 0, 0, time.UTC)},
+		{spec: "@hourly", want: time.Date(2025, 1, 15, 11, 0, 0, 0, time.UTC)},
+		{spec: "@daily", want: time.Date(2025, 1, 16, 0, 0, 0, 0, time.UTC)},
+		{spec: "@weekly", want: time.Date(2025, 1, 19, 0, 0, 0, 0, time.UTC)},
+		{spec: "@monthly", want: time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)},
+		{spec: "@yearly", want: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{spec: "@every 90s", want: base.Add(90 * time.Second)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			s, err := Parse(tt.spec)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, s.Next(base))
+		})
+	}
+}
+
+func TestNext_Impossible(t *testing.T) {
+	s, err := Parse("0 0 30 2 *")
+	require.NoError(t, err)
+	assert.True(t, s.Next(base).IsZero())
+}
+
+func TestNext_KeepsLocation(t *testing.T) {
+	loc := time.FixedZone("UTC+2", 2*60*60)
+	s, err := Parse("0 9 * * *")
+	require.NoError(t, err)
+
+	next := s.Next(time.Date(2025, 1, 15, 10, 0, 0, 0, loc))
+	assert.Equal(t, time.Date(2025, 1, 16, 9, 0, 0, 0, loc), next)
+}
+
+func TestParse_Errors(t *testing.T) {
+	tests := []struct {
+		spec string
+		want string
+	}{
+		{spec: "", want: "expected 5 fields"},
+		{spec: "* * * *", want: "expected 5 fields"},
+		{spec: "60 * * * *", want: "invalid minute"},
+		{spec: "* 24 * * *", want: "invalid hour"},
+		{spec: "* * 0 * *", want: "invalid day of month"},
+		{spec: "* * * foo *", want: "invalid month"},
+		{spec: "* * * * 8", want: "invalid day of week"},
+		{spec: "*/0 * * * *", want: "step must be a positive number"},
+		{spec: "10-5 * * * *", want: "range start 10 is after end 5"},
+		{spec: "@fortnightly", want: "unknown shorthand"},
+		{spec: "@every soon", want: "invalid @every duration"},
+		{spec: "@every -1m", want: "must be positive"},
+		{spec: "@every 1ns", want: "must be at least 1s, got 1ns"},
+		{spec: "@every 999ms", want: "must be at least 1s, got 999ms"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			_, err := Parse(tt.spec)
+			assert.ErrorContains(t, err, tt.want)
+		})
+	}
+}
@@ -0,0 +1,630 @@
+// Package curl builds saved requests from curl command lines, such as the
+// snippets found in API documentation or copied from browser developer tools.
+package curl
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/williajm/curly/internal/domain"
+)
+
+// formBoundary separates the parts of a multipart body built from -F options.
+const formBoundary = "curlyformboundary7MA4YWxkTrZu0gW"
+
+// Result is a parsed curl command.
+type Result struct {
+	// Request is the request the command would send.
+	Request *domain.Request
+
+	// Warnings describe options that were recognised but cannot be represented
+	// by a saved request, or that were not recognised at all.
+	Warnings []string
+}
+
+// shortOptions maps the short options curly understands to their long names.
+var shortOptions = map[byte]string{
+	'X': "--request",
+	'H': "--header",
+	'd': "--data",
+	'u': "--user",
+	'F': "--form",
+	'A': "--user-agent",
+	'e': "--referer",
+	'b': "--cookie",
+	'k': "--insecure",
+	'G': "--get",
+	'I': "--head",
+	'o': "--output",
+	'm': "--max-time",
+	'x': "--proxy",
+	'w': "--write-out",
+	'c': "--cookie-jar",
+	'E': "--cert",
+	's': "--silent",
+	'S': "--show-error",
+	'L': "--location",
+	'v': "--verbose",
+	'i': "--include",
+	'f': "--fail",
+	'g': "--globoff",
+	'N': "--no-buffer",
+}
+
+// valueOptions lists the long options that take an argument. Those that
+// setValue does not handle are accepted but do not affect the request.
+var valueOptions = map[string]bool{
+	"--request":         true,
+	"--header":          true,
+	"--data":            true,
+	"--data-ascii":      true,
+	"--data-raw":        true,
+	"--data-binary":     true,
+	"--data-urlencode":  true,
+	"--json":            true,
+	"--user":            true,
+	"--form":            true,
+	"--form-string":     true,
+	"--user-agent":      true,
+	"--referer":         true,
+	"--cookie":          true,
+	"--url":             true,
+	"--oauth2-bearer":   true,
+	"--output":          true,
+	"--max-time":        true,
+	"--connect-timeout": true,
+	"--proxy":           true,
+	"--write-out":       true,
+	"--cookie-jar":      true,
+	"--cert":            true,
+	"--key":             true,
+	"--cacert":          true,
+	"--retry":           true,
+	"--max-redirs":      true,
+	"--resolve":         true,
+	"--limit-rate":      true,
+}
+
+// flagOptions lists the long options without an argument. Those that setFlag
+// does not handle are accepted but do not affect the request.
+var flagOptions = map[string]bool{
+	"--insecure":   true,
+	"--get":        true,
+	"--head":       true,
+	"--silent":     true,
+	"--show-error": true,
+	"--location":   true,
+	"--verbose":    true,
+	"--include":    true,
+	"--fail":       true,
+	"--globoff":    true,
+	"--no-buffer":  true,
+	"--compressed": true,
+	"--http1.1":    true,
+	"--http2":      true,
+}
+
+// parser accumulates the state of a command while its options are read.
+type parser struct {
+	method   string
+	head     bool
+	get      bool
+	urls     []string
+	headers  [][2]string
+	data     []string
+	json     []string
+	form     []string
+	auth     domain.AuthConfig
+	warnings []string
+}
+
+// Parse parses a curl command line. Line continuations (backslash-newline on
+// Unix, caret-newline on Windows), single, double and $'...' quoting are understood.
+func Parse(command string) (*Result, error) {
+	args, err := split(command)
+	if err != nil {
+		return nil, err
+	}
+	if len(args) == 0 || args[0] != "curl" {
+		return nil, fmt.Errorf("not a curl command")
+	}
+
+	p := &parser{}
+	if err := p.parseArgs(args[1:]); err != nil {
+		return nil, err
+	}
+	return p.result()
+}
+
+// parseArgs reads options and URLs.
+func (p *parser) parseArgs(args []string) error {
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+
+		switch {
+		case arg == "--":
+			p.urls = append(p.urls, args[i+1:]...)
+			return nil
+
+		case strings.HasPrefix(arg, "--"):
+			if flagOptions[arg] {
+				p.setFlag(arg)
+				continue
+			}
+			if !valueOptions[arg] {
+				p.warnf("ignored unknown option %s", arg)
+				continue
+			}
+			if i+1 >= len(args) {
+				return fmt.Errorf("option %s requires a value", arg)
+			}
+			i++
+			if err := p.setValue(arg, args[i]); err != nil {
+				return err
+			}
+
+		case strings.HasPrefix(arg, "-") && len(arg) > 1:
+			consumed, err := p.parseShort(arg, args[i+1:])
+			if err != nil {
+				return err
+			}
+			i += consumed
+
+		default:
+			p.urls = append(p.urls, arg)
+		}
+	}
+	return nil
+}
+
+// parseShort reads a group of short options such as -sSL or -XPOST.
+// It returns the number of following arguments consumed as a value.
+func (p *parser) parseShort(arg string, rest []string) (int, error) {
+	for j := 1; j < len(arg); j++ {
+		opt := "-" + arg[j:j+1]
+
+		name, ok := shortOptions[arg[j]]
+		if !ok {
+			p.warnf("ignored unknown option %s", opt)
+			continue
+		}
+		if flagOptions[name] {
+			p.setFlag(name)
+			continue
+		}
+
+		// The value is either the rest of this argument or the next one.
+		if j+1 < len(arg) {
+			return 0, p.setValue(name, arg[j+1:])
+		}
+		if len(rest) == 0 {
+			return 0, fmt.Errorf("option %s requires a value", opt)
+		}
+		return 1, p.setValue(name, rest[0])
+	}
+	return 0, nil
+}
+
+// setFlag applies an option without an argument.
+func (p *parser) setFlag(name string) {
+	switch name {
+	case "--insecure":
+		p.warnf("--insecure is not stored per request; set http.insecure_skip_tls in the config instead")
+	case "--get":
+		p.get = true
+	case "--head":
+		p.head = true
+	}
+}
+
+// setValue applies an option with an argument.
+func (p *parser) setValue(name, value string) error {
+	switch name {
+	case "--request":
+		p.method = strings.ToUpper(value)
+
+	case "--header":
+		p.addHeader(value)
+
+	case "--data", "--data-ascii", "--data-binary", "--data-raw", "--data-urlencode",
+		"--json", "--form", "--form-string":
+		return p.addBody(name, value)
+
+	case "--user":
+		username, password, found := strings.Cut(value, ":")
+		if !found {
+			p.warnf("--user has no password; curl would prompt for it")
+		}
+		p.auth = domain.NewBasicAuth(username, password)
+
+	case "--oauth2-bearer":
+		p.auth = domain.NewBearerAuth(value)
+
+	case "--user-agent":
+		p.headers = append(p.headers, [2]string{"User-Agent", value})
+
+	case "--referer":
+		p.headers = append(p.headers, [2]string{"Referer", value})
+
+	case "--cookie":
+		if !strings.Contains(value, "=") {
+			p.warnf("ignored cookie file %s", value)
+			return nil
+		}
+		p.headers = append(p.headers, [2]string{"Cookie", value})
+
+	case "--url":
+		p.urls = append(p.urls, value)
+	}
+	return nil
+}
+
+// addBody records the value of a data, JSON or form option.
+func (p *parser) addBody(name, value string) error {
+	switch name {
+	case "--data", "--data-ascii", "--data-binary":
+		if strings.HasPrefix(value, "@") {
+			return fmt.Errorf("%s %s: reading data from a file is not supported", name, value)
+		}
+		p.data = append(p.data, value)
+
+	case "--data-raw":
+		p.data = append(p.data, value)
+
+	case "--data-urlencode":
+		encoded, err := urlencode(value)
+		if err != nil {
+			return err
+		}
+		p.data = append(p.data, encoded)
+
+	case "--json":
+		if strings.HasPrefix(value, "@") {
+			return fmt.Errorf("--json %s: reading data from a file is not supported", value)
+		}
+		p.json = append(p.json, value)
+
+	case "--form", "--form-string":
+		field, err := formField(name, value)
+		if err != nil {
+			return err
+		}
+		p.form = append(p.form, field)
+	}
+	return nil
+}
+
+// addHeader records a -H value. "Name:" (which makes curl drop the header) is
+// ignored and "Name;" sends the header with an empty value, as curl does.
+// A bearer Authorization header becomes bearer auth.
+func (p *parser) addHeader(value string) {
+	name, val, found := strings.Cut(value, ":")
+	if !found {
+		if empty, ok := strings.CutSuffix(value, ";"); ok {
+			p.headers = append(p.headers, [2]string{strings.TrimSpace(empty), ""})
+		} else {
+			p.warnf("ignored malformed header %q", value)
+		}
+		return
+	}
+
+	name = strings.TrimSpace(name)
+	val = strings.TrimSpace(val)
+	if val == "" {
+		return
+	}
+
+	if strings.EqualFold(name, "Authorization") {
+		if token, ok := cutPrefixFold(val, "Bearer "); ok {
+			p.auth = domain.NewBearerAuth(strings.TrimSpace(token))
+			return
+		}
+	}
+	p.headers = append(p.headers, [2]string{name, val})
+}
+
+// result assembles the parsed request.
+func (p *parser) result() (*Result, error) {
+	if len(p.urls) == 0 {
+		return nil, fmt.Errorf("no URL in curl command")
+	}
+	if len(p.urls) > 1 {
+		p.warnf("ignored %d additional URLs", len(p.urls)-1)
+	}
+
+	rawURL := p.urls[0]
+	if !strings.Contains(rawURL, "://") {
+		rawURL = "http://" + rawURL
+	}
+
+	req := domain.NewRequestWithMethodAndURL(domain.MethodGet, rawURL)
+	for _, header := range p.headers {
+		// Assigned directly because SetHeader drops empty values.
+		req.Headers[header[0]] = header[1]
+	}
+	if p.auth != nil {
+		req.SetAuth(p.auth)
+	}
+
+	hasBody := false
+	switch {
+	case len(p.data) > 0 && p.get:
+		separator := "?"
+		if strings.Contains(req.URL, "?") {
+			separator = "&"
+		}
+		req.URL += separator + strings.Join(p.data, "&")
+
+	case len(p.data) > 0:
+		req.Body = strings.Join(p.data, "&")
+		p.defaultHeader(req, "Content-Type", "application/x-www-form-urlencoded")
+		hasBody = true
+
+	case len(p.json) > 0:
+		req.Body = strings.Join(p.json, "")
+		p.defaultHeader(req, "Content-Type", "application/json")
+		p.defaultHeader(req, "Accept", "application/json")
+		hasBody = true
+
+	case len(p.form) > 0:
+		req.Body = strings.Join(p.form, "") + "--" + formBoundary + "--\r\n"
+		req.SetHeader("Content-Type", "multipart/form-data; boundary="+formBoundary)
+		hasBody = true
+	}
+
+	switch {
+	case p.method != "":
+		req.Method = p.method
+	case p.head:
+		req.Method = domain.MethodHead
+	case hasBody:
+		req.Method = domain.MethodPost
+	}
+	req.Name = req.Method + " " + req.URL
+
+	return &Result{Request: req, Warnings: p.warnings}, nil
+}
+
+// defaultHeader sets a header unless the command already set it.
+func (p *parser) defaultHeader(req *domain.Request, name, value string) {
+	for existing := range req.Headers {
+		if strings.EqualFold(existing, name) {
+			return
+		}
+	}
+	req.SetHeader(name, value)
+}
+
+// warnf records a warning.
+func (p *parser) warnf(format string, args ...any) {
+	p.warnings = append(p.warnings, fmt.Sprintf(format, args...))
+}
+
+// urlencode encodes a --data-urlencode value: "content", "=content" or "name=content".
+func urlencode(value string) (string, error) {
+	name, content, found := strings.Cut(value, "=")
+	if !found {
+		if strings.Contains(value, "@") {
+			return "", fmt.Errorf("--data-urlencode %s: reading data from a file is not supported", value)
+		}
+		return url.QueryEscape(value), nil
+	}
+	if name == "" {
+		return url.QueryEscape(content), nil
+	}
+	return name + "=" + url.QueryEscape(content), nil
+}
+
+// formField encodes a -F or --form-string value as one multipart part.
+func formField(option, value string) (string, error) {
+	name, content, found := strings.Cut(value, "=")
+	if !found {
+		return "", fmt.Errorf("%s %q: expected name=value", option, value)
+	}
+	if option == "--form" && (strings.HasPrefix(content, "@") || strings.HasPrefix(content, "<")) {
+		return "", fmt.Errorf("%s %s: uploading files is not supported", option, value)
+	}
+
+	return "--" + formBoundary + "\r\n" +
+		"Content-Disposition: form-data; name=\"" + name + "\"\r\n\r\n" +
+		content + "\r\n", nil
+}
+
+// cutPrefixFold is strings.CutPrefix ignoring case.
+func cutPrefixFold(s, prefix string) (string, bool) {
+	if len(s) >= len(prefix) && strings.EqualFold(s[:len(prefix)], prefix) {
+		return s[len(prefix):], true
+	}
+	return s, false
+}
+
+// split breaks a command line into arguments using shell quoting rules.
+func split(command string) ([]string, error) {
+	var (
+		args    []string
+		current strings.Builder
+		inArg   bool
+	)
+	runes := []rune(command)
+
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case r == '\\' || r == '^':
+			// Line continuation.
+			if n := newlineLen(runes, i+1); n > 0 {
+				i += n
+				continue
+			}
+			i = escaped(runes, i, &current)
+			inArg = true
+
+		case isBlank(r):
+			if inArg {
+				args = append(args, current.String())
+				current.Reset()
+				inArg = false
+			}
+
+		case r == '\'':
+			end, err := singleQuoted(runes, i+1, &current)
+			if err != nil {
+				return nil, err
+			}
+			i = end
+			inArg = true
+
+		case startsANSIQuote(runes, i):
+			end, err := ansiQuoted(runes, i+2, &current)
+			if err != nil {
+				return nil, err
+			}
+			i = end
+			inArg = true
+
+		case r == '"':
+			end, err := doubleQuoted(runes, i+1, &current)
+			if err != nil {
+				return nil, err
+			}
+			i = end
+			inArg = true
+
+		default:
+			current.WriteRune(r)
+			inArg = true
+		}
+	}
+	if inArg {
+		args = append(args, current.String())
+	}
+
+	return args, nil
+}
+
+// isBlank reports whether r separates arguments.
+func isBlank(r rune) bool {
+	return r == ' ' || r == '\t' || r == '\n' || r == '\r'
+}
+
+// startsANSIQuote reports whether a $'...' string starts at i.
+func startsANSIQuote(runes []rune, i int) bool {
+	return runes[i] == '$' && i+1 < len(runes) && runes[i+1] == '\''
+}
+
+// escaped reads the character escaped by the backslash (or, in Windows
+// commands, the caret) at i and returns the index of the last rune read.
+// A caret escapes nothing and is kept.
+func escaped(runes []rune, i int, out *strings.Builder) int {
+	if runes[i] == '^' {
+		out.WriteRune('^')
+		return i
+	}
+	if i+1 < len(runes) {
+		i++
+		out.WriteRune(runes[i])
+	}
+	return i
+}
+
+// newlineLen returns the length of the line ending at i, or 0 if there is none.
+func newlineLen(runes []rune, i int) int {
+	switch {
+	case i < len(runes) && runes[i] == '\n':
+		return 1
+	case i+1 < len(runes) && runes[i] == '\r' && runes[i+1] == '\n':
+		return 2
+	default:
+		return 0
+	}
+}
+
+// indexRune returns the index of r in runes at or after start, or -1.
+func indexRune(runes []rune, start int, r rune) int {
+	for i := start; i < len(runes); i++ {
+		if runes[i] == r {
+			return i
+		}
+	}
+	return -1
+}
+
+// singleQuoted reads a single-quoted string starting after the opening quote
+// and returns the index of the closing quote.
+func singleQuoted(runes []rune, start int, out *strings.Builder) (int, error) {
+	end := indexRune(runes, start, '\'')
+	if end < 0 {
+		return 0, fmt.Errorf("unterminated single quote")
+	}
+	out.WriteString(string(runes[start:end]))
+	return end, nil
+}
+
+// doubleQuoted reads a double-quoted string starting after the opening quote
+// and returns the index of the closing quote.
+func doubleQuoted(runes []rune, start int, out *strings.Builder) (int, error) {
+	for i := start; i < len(runes); i++ {
+		switch runes[i] {
+		case '"':
+			return i, nil
+		case '\\':
+			if i+1 < len(runes) {
+				switch runes[i+1] {
+				case '"', '\\', '$', '`':
+					i++
+					out.WriteRune(runes[i])
+					continue
+				case '\n':
+					i++
+					continue
+				}
+			}
+			out.WriteRune('\\')
+		default:
+			out.WriteRune(runes[i])
+		}
+	}
+	return 0, fmt.Errorf("unterminated double quote")
+}
+
+// ansiQuoted reads a $'...' string starting after the opening quote and
+// returns the index of the closing quote.
+func ansiQuoted(runes []rune, start int, out *strings.Builder) (int, error) {
+	for i := start; i < len(runes); i++ {
+		switch runes[i] {
+		case '\'':
+			return i, nil
+		case '\\':
+			if i+1 >= len(runes) {
+				return 0, fmt.Errorf("unterminated $' quote")
+			}
+			i++
+			switch runes[i] {
+			case 'n':
+				out.WriteRune('\n')
+			case 't':
+				out.WriteRune('\t')
+			case 'r':
+				out.WriteRune('\r')
+			case 'x':
+				end := i + 1
+				for end < len(runes) && end < i+3 && strings.ContainsRune("0123456789abcdefABCDEF", runes[end]) {
+					end++
+				}
+				code, err := strconv.ParseUint(string(runes[i+1:end]), 16, 8)
+				if err != nil {
+					return 0, fmt.Errorf("invalid \\x escape in $' quote")
+				}
+				out.WriteByte(byte(code))
+				i = end - 1
+			default:
+				out.WriteRune(runes[i])
+			}
+		default:
+			out.WriteRune(runes[i])
+		}
+	}
+	return 0, fmt.Errorf("unterminated $' quote")
+}
@@ -0,0 +1,203 @@
+package curl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/williajm/curly/internal/domain"
+)
+
+func TestParse_Simple(t *testing.T) {
+	result, err := Parse("curl https://api.example.com/users?page=2")
+	require.NoError(t, err)
+
+	req := result.Request
+	assert.Equal(t, domain.MethodGet, req.Method)
+	assert.Equal(t, "https://api.example.com/users?page=2", req.URL)
+	assert.Equal(t, "GET https://api.example.com/users?page=2", req.Name)
+	assert.Empty(t, req.Body)
+	assert.Empty(t, result.Warnings)
+	assert.NoError(t, req.Validate())
+}
+
+func TestParse_MultilineWithHeadersAndData(t *testing.T) {
+	cmd := `curl -X PUT 'https://api.example.com/users/1' \
+  -H 'Content-Type: application/json' \
+  -H "X-Request-ID: abc \"quoted\"" \
+  -d '{"name": "Ada"}'`
+
+	result, err := Parse(cmd)
+	require.NoError(t, err)
+
+	req := result.Request
+	assert.Equal(t, domain.MethodPut, req.Method)
+	assert.Equal(t, "https://api.example.com/users/1", req.URL)
+	assert.Equal(t, "application/json", req.Headers["Content-Type"])
+	assert.Equal(t, `abc "quoted"`, req.Headers["X-Request-ID"])
+	assert.Equal(t, `{"name": "Ada"}`, req.Body)
+}
+
+func TestParse_DataDefaults(t *testing.T) {
+	result, err := Parse(`curl https://api.example.com/login -d user=ada --data-raw '@home' --data-urlencode 'msg=hello world'`)
+	require.NoError(t, err)
+
+	req := result.Request
+	assert.Equal(t, domain.MethodPost, req.Method)
+	assert.Equal(t, "user=ada&@home&msg=hello+world", req.Body)
+	assert.Equal(t, "application/x-www-form-urlencoded", req.Headers["Content-Type"])
+}
+
+func TestParse_GetMovesDataToQuery(t *testing.T) {
+	result, err := Parse(`curl -G https://api.example.com/search?lang=en -d q=go --data-urlencode "tag=a b"`)
+	require.NoError(t, err)
+
+	req := result.Request
+	assert.Equal(t, domain.MethodGet, req.Method)
+	assert.Equal(t, "https://api.example.com/search?lang=en&q=go&tag=a+b", req.URL)
+	assert.Empty(t, req.Body)
+	assert.NotContains(t, req.Headers, "Content-Type")
+}
+
+func TestParse_JSON(t *testing.T) {
+	result, err := Parse(`curl --json '{"a":1}' https://api.example.com/items`)
+	require.NoError(t, err)
+
+	req := result.Request
+	assert.Equal(t, domain.MethodPost, req.Method)
+	assert.Equal(t, `{"a":1}`, req.Body)
+	assert.Equal(t, "application/json", req.Headers["Content-Type"])
+	assert.Equal(t, "application/json", req.Headers["Accept"])
+}
+
+func TestParse_Auth(t *testing.T) {
+	tests := []struct {
+		name string
+		cmd  string
+		want domain.AuthConfig
+	}{
+		{
+			name: "basic",
+			cmd:  "curl -u ada:secret https://api.example.com",
+			want: domain.NewBasicAuth("ada", "secret"),
+		},
+		{
+			name: "bearer header",
+			cmd:  `curl -H "authorization: bearer tok123" https://api.example.com`,
+			want: domain.NewBearerAuth("tok123"),
+		},
+		{
+			name: "oauth2 bearer",
+			cmd:  "curl --oauth2-bearer tok456 https://api.example.com",
+			want: domain.NewBearerAuth("tok456"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Parse(tt.cmd)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, result.Request.AuthConfig)
+			assert.NotContains(t, result.Request.Headers, "authorization")
+		})
+	}
+}
+
+func TestParse_UserWithoutPasswordWarns(t *testing.T) {
+	result, err := Parse("curl -u ada https://api.example.com")
+	require.NoError(t, err)
+	assert.Equal(t, domain.NewBasicAuth("ada", ""), result.Request.AuthConfig)
+	assert.Len(t, result.Warnings, 1)
+}
+
+func TestParse_Form(t *testing.T) {
+	result, err := Parse(`curl -F name=ada --form-string 'note=@literal' https://api.example.com/upload`)
+	require.NoError(t, err)
+
+	req := result.Request
+	assert.Equal(t, domain.MethodPost, req.Method)
+	assert.Equal(t, "multipart/form-data; boundary="+formBoundary, req.Headers["Content-Type"])
+	assert.Equal(t, "--"+formBoundary+"\r\n"+
+		"Content-Disposition: form-data; name=\"name\"\r\n\r\nada\r\n"+
+		"--"+formBoundary+"\r\n"+
+		"Content-Disposition: form-data; name=\"note\"\r\n\r\n@literal\r\n"+
+		"--"+formBoundary+"--\r\n", req.Body)
+}
+
+func TestParse_ShortOptionGroups(t *testing.T) {
+	result, err := Parse(`curl -sSLkXDELETE -HAccept:text/plain -A agent/1.0 api.example.com/items/3`)
+	require.NoError(t, err)
+
+	req := result.Request
+	assert.Equal(t, domain.MethodDelete, req.Method)
+	assert.Equal(t, "http://api.example.com/items/3", req.URL)
+	assert.Equal(t, "text/plain", req.Headers["Accept"])
+	assert.Equal(t, "agent/1.0", req.Headers["User-Agent"])
+	require.Len(t, result.Warnings, 1)
+	assert.Contains(t, result.Warnings[0], "--insecure")
+}
+
+func TestParse_HeadersAndIgnoredOptions(t *testing.T) {
+	result, err := Parse(`curl --compressed -o out.json --max-time 5 -b 'sid=1' -e https://ref.example.com ` +
+		`-H 'X-Empty;' -H 'X-Removed:' --frobnicate --url https://api.example.com -I https://other.example.com`)
+	require.NoError(t, err)
+
+	req := result.Request
+	assert.Equal(t, domain.MethodHead, req.Method)
+	assert.Equal(t, "https://api.example.com", req.URL)
+	assert.Equal(t, map[string]string{
+		"Cookie":  "sid=1",
+		"Referer": "https://ref.example.com",
+		"X-Empty": "",
+	}, req.Headers)
+	assert.Equal(t, []string{
+		"ignored unknown option --frobnicate",
+		"ignored 1 additional URLs",
+	}, result.Warnings)
+}
+
+func TestParse_BrowserCopy(t *testing.T) {
+	// Chrome's "Copy as cURL (bash)" uses $'...' quoting for bodies with escapes.
+	cmd := "curl 'https://api.example.com/graphql' \\\r\n" +
+		"  -H 'content-type: application/json' \\\r\n" +
+		"  --data-raw $'{\"query\":\"{ me { name } }\",\\n\"note\":\"it\\'s\\x21\"}'"
+
+	result, err := Parse(cmd)
+	require.NoError(t, err)
+	assert.Equal(t, "{\"query\":\"{ me { name } }\",\n\"note\":\"it's!\"}", result.Request.Body)
+}
+
+func TestParse_WindowsContinuation(t *testing.T) {
+	result, err := Parse("curl ^\r\n -X POST ^\n https://api.example.com")
+	require.NoError(t, err)
+	assert.Equal(t, domain.MethodPost, result.Request.Method)
+}
+
+func TestParse_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		cmd  string
+		want string
+	}{
+		{name: "empty", cmd: "  ", want: "not a curl command"},
+		{name: "other command", cmd: "wget https://example.com", want: "not a curl command"},
+		{name: "no url", cmd: "curl -X POST", want: "no URL"},
+		{name: "missing value", cmd: "curl https://example.com -H", want: "requires a value"},
+		{name: "missing long value", cmd: "curl https://example.com --data", want: "requires a value"},
+		{name: "unterminated single", cmd: "curl 'https://example.com", want: "unterminated single quote"},
+		{name: "unterminated double", cmd: `curl "https://example.com`, want: "unterminated double quote"},
+		{name: "unterminated ansi", cmd: `curl $'https://example.com`, want: "unterminated $' quote"},
+		{name: "data file", cmd: "curl -d @body.json https://example.com", want: "reading data from a file"},
+		{name: "json file", cmd: "curl --json @body.json https://example.com", want: "reading data from a file"},
+		{name: "urlencode file", cmd: "curl --data-urlencode @body.txt https://example.com", want: "reading data from a file"},
+		{name: "form file", cmd: "curl -F file=@photo.png https://example.com", want: "uploading files"},
+		{name: "form without value", cmd: "curl -F name https://example.com", want: "expected name=value"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(tt.cmd)
+			assert.ErrorContains(t, err, tt.want)
+		})
+	}
+}
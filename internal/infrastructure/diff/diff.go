@@ -0,0 +1,346 @@
+// Package diff compares response bodies and headers, structurally for JSON
+// and line by line for other text.
+package diff
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Kind is the kind of a structural change.
+type Kind string
+
+// Change kinds.
+const (
+	// Added means the value exists only in the second document.
+	Added Kind = "added"
+
+	// Removed means the value exists only in the first document.
+	Removed Kind = "removed"
+
+	// Changed means the value differs between the documents.
+	Changed Kind = "changed"
+)
+
+// Change is a single structural difference.
+type Change struct {
+	// Path locates the value, e.g. "$.users[0].name" or a header name.
+	Path string
+
+	// Kind is how the value changed.
+	Kind Kind
+
+	// Old and New are the values as compact JSON (or raw header values);
+	// Old is empty for additions and New for removals.
+	Old string
+	New string
+}
+
+// String renders the change on one line.
+func (c Change) String() string {
+	switch c.Kind {
+	case Added:
+		return fmt.Sprintf("+ %s: %s", c.Path, c.New)
+	case Removed:
+		return fmt.Sprintf("- %s: %s", c.Path, c.Old)
+	default:
+		return fmt.Sprintf("~ %s: %s → %s", c.Path, c.Old, c.New)
+	}
+}
+
+// Op is the operation of a line in a line diff.
+type Op int
+
+// Line operations.
+const (
+	// Equal lines appear in both texts.
+	Equal Op = iota
+
+	// Delete lines appear only in the first text.
+	Delete
+
+	// Insert lines appear only in the second text.
+	Insert
+)
+
+// Line is one line of a line diff.
+type Line struct {
+	Op   Op
+	Text string
+}
+
+// String renders the line with a unified-diff style prefix.
+func (l Line) String() string {
+	switch l.Op {
+	case Delete:
+		return "-" + l.Text
+	case Insert:
+		return "+" + l.Text
+	default:
+		return " " + l.Text
+	}
+}
+
+// maxLineCells bounds the work Lines does on the differing middle of two
+// texts; beyond it the middle is reported as replaced wholesale.
+const maxLineCells = 4_000_000
+
+// JSON structurally compares two JSON documents. Object keys are compared
+// in sorted order and arrays element by element.
+// It returns an error if either document is not valid JSON.
+func JSON(a, b []byte) ([]Change, error) {
+	va, err := decode(a)
+	if err != nil {
+		return nil, fmt.Errorf("first document is not JSON: %w", err)
+	}
+	vb, err := decode(b)
+	if err != nil {
+		return nil, fmt.Errorf("second document is not JSON: %w", err)
+	}
+
+	var changes []Change
+	compare("$", va, vb, &changes)
+	return changes, nil
+}
+
+// IsJSON reports whether data is a single valid JSON document.
+func IsJSON(data []byte) bool {
+	_, err := decode(data)
+	return err == nil
+}
+
+// Indent pretty-prints a JSON document, returning data unchanged if it is
+// not valid JSON.
+func Indent(data []byte) string {
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, bytes.TrimSpace(data), "", "  "); err != nil {
+		return string(data)
+	}
+	return buf.String()
+}
+
+// decode parses a single JSON document, keeping numbers exact.
+func decode(data []byte) (any, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	if dec.More() {
+		return nil, fmt.Errorf("unexpected data after the document")
+	}
+	return v, nil
+}
+
+// compare appends the differences between a and b at path to changes.
+func compare(path string, a, b any, changes *[]Change) {
+	switch av := a.(type) {
+	case map[string]any:
+		if bv, ok := b.(map[string]any); ok {
+			compareObjects(path, av, bv, changes)
+			return
+		}
+	case []any:
+		if bv, ok := b.([]any); ok {
+			compareArrays(path, av, bv, changes)
+			return
+		}
+	}
+
+	oldText, newText := compact(a), compact(b)
+	if oldText != newText {
+		*changes = append(*changes, Change{Path: path, Kind: Changed, Old: oldText, New: newText})
+	}
+}
+
+// compareObjects compares object members in sorted key order.
+func compareObjects(path string, a, b map[string]any, changes *[]Change) {
+	keys := make([]string, 0, len(a)+len(b))
+	for k := range a {
+		keys = append(keys, k)
+	}
+	for k := range b {
+		if _, ok := a[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		child := path + memberPath(k)
+		av, inA := a[k]
+		bv, inB := b[k]
+		switch {
+		case !inB:
+			*changes = append(*changes, Change{Path: child, Kind: Removed, Old: compact(av)})
+		case !inA:
+			*changes = append(*changes, Change{Path: child, Kind: Added, New: compact(bv)})
+		default:
+			compare(child, av, bv, changes)
+		}
+	}
+}
+
+// compareArrays compares elements at the same index.
+func compareArrays(path string, a, b []any, changes *[]Change) {
+	for i := 0; i < len(a) || i < len(b); i++ {
+		child := fmt.Sprintf("%s[%d]", path, i)
+		switch {
+		case i >= len(b):
+			*changes = append(*changes, Change{Path: child, Kind: Removed, Old: compact(a[i])})
+		case i >= len(a):
+			*changes = append(*changes, Change{Path: child, Kind: Added, New: compact(b[i])})
+		default:
+			compare(child, a[i], b[i], changes)
+		}
+	}
+}
+
+// memberPath renders an object key as a path segment, quoting keys that
+// are not plain identifiers.
+func memberPath(key string) string {
+	plain := key != ""
+	for i, r := range key {
+		if !(r == '_' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || i > 0 && r >= '0' && r <= '9') {
+			plain = false
+			break
+		}
+	}
+	if plain {
+		return "." + key
+	}
+	return "[" + strconv.Quote(key) + "]"
+}
+
+// compact renders a decoded value as compact JSON.
+func compact(v any) string {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	_ = enc.Encode(v) // Decoded JSON values always encode.
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+// Map compares two string maps, such as response headers, by key.
+// Changes are ordered by key.
+func Map(a, b map[string]string) []Change {
+	keys := make([]string, 0, len(a)+len(b))
+	for k := range a {
+		keys = append(keys, k)
+	}
+	for k := range b {
+		if _, ok := a[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	var changes []Change
+	for _, k := range keys {
+		av, inA := a[k]
+		bv, inB := b[k]
+		switch {
+		case !inB:
+			changes = append(changes, Change{Path: k, Kind: Removed, Old: av})
+		case !inA:
+			changes = append(changes, Change{Path: k, Kind: Added, New: bv})
+		case av != bv:
+			changes = append(changes, Change{Path: k, Kind: Changed, Old: av, New: bv})
+		}
+	}
+	return changes
+}
+
+// Lines computes a line diff of two texts using their longest common
+// subsequence of lines.
+func Lines(a, b string) []Line {
+	al, bl := splitLines(a), splitLines(b)
+
+	// Common prefix and suffix need no search.
+	prefix := 0
+	for prefix < len(al) && prefix < len(bl) && al[prefix] == bl[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(al)-prefix && suffix < len(bl)-prefix && al[len(al)-1-suffix] == bl[len(bl)-1-suffix] {
+		suffix++
+	}
+
+	var lines []Line
+	for _, text := range al[:prefix] {
+		lines = append(lines, Line{Op: Equal, Text: text})
+	}
+	lines = append(lines, lcs(al[prefix:len(al)-suffix], bl[prefix:len(bl)-suffix])...)
+	for _, text := range al[len(al)-suffix:] {
+		lines = append(lines, Line{Op: Equal, Text: text})
+	}
+	return lines
+}
+
+// lcs diffs a and b with a dynamic-programming longest common subsequence.
+func lcs(a, b []string) []Line {
+	if len(a)*len(b) > maxLineCells {
+		return replaceAll(a, b)
+	}
+
+	// table[i][j] is the LCS length of a[i:] and b[j:].
+	table := make([][]int, len(a)+1)
+	for i := range table {
+		table[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				table[i][j] = table[i+1][j+1] + 1
+			} else {
+				table[i][j] = max(table[i+1][j], table[i][j+1])
+			}
+		}
+	}
+
+	var lines []Line
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			lines = append(lines, Line{Op: Equal, Text: a[i]})
+			i++
+			j++
+		case table[i+1][j] >= table[i][j+1]:
+			lines = append(lines, Line{Op: Delete, Text: a[i]})
+			i++
+		default:
+			lines = append(lines, Line{Op: Insert, Text: b[j]})
+			j++
+		}
+	}
+	return append(lines, replaceAll(a[i:], b[j:])...)
+}
+
+// replaceAll reports every line of a as deleted and every line of b as inserted.
+func replaceAll(a, b []string) []Line {
+	lines := make([]Line, 0, len(a)+len(b))
+	for _, text := range a {
+		lines = append(lines, Line{Op: Delete, Text: text})
+	}
+	for _, text := range b {
+		lines = append(lines, Line{Op: Insert, Text: text})
+	}
+	return lines
+}
+
+// splitLines splits text into lines without their terminators.
+// Empty text has no lines.
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	return strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+}
@@ -0,0 +1,92 @@
+package diff
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJSON(t *testing.T) {
+	a := `{"id": 1, "name": "Ada", "tags": ["x", "y"], "meta": {"v": 1.0}, "gone": null, "content-type": "a"}`
+	b := `{"id": 1, "name": "Grace", "tags": ["x"], "meta": {"v": 2}, "new": {"k": true}, "content-type": "b"}`
+
+	changes, err := JSON([]byte(a), []byte(b))
+	require.NoError(t, err)
+	assert.Equal(t, []Change{
+		{Path: `$["content-type"]`, Kind: Changed, Old: `"a"`, New: `"b"`},
+		{Path: "$.gone", Kind: Removed, Old: "null"},
+		{Path: "$.meta.v", Kind: Changed, Old: "1.0", New: "2"},
+		{Path: "$.name", Kind: Changed, Old: `"Ada"`, New: `"Grace"`},
+		{Path: "$.new", Kind: Added, New: `{"k":true}`},
+		{Path: "$.tags[1]", Kind: Removed, Old: `"y"`},
+	}, changes)
+}
+
+func TestJSON_TypeChangeAndIdentical(t *testing.T) {
+	changes, err := JSON([]byte(`{"a": [1]}`), []byte(`{"a": {"0": 1}}`))
+	require.NoError(t, err)
+	assert.Equal(t, []Change{{Path: "$.a", Kind: Changed, Old: "[1]", New: `{"0":1}`}}, changes)
+
+	changes, err = JSON([]byte(`{"a":1,"b":2}`), []byte("{\n  \"b\": 2,\n  \"a\": 1\n}"))
+	require.NoError(t, err)
+	assert.Empty(t, changes)
+}
+
+func TestJSON_Invalid(t *testing.T) {
+	_, err := JSON([]byte("not json"), []byte("{}"))
+	assert.ErrorContains(t, err, "first document is not JSON")
+
+	_, err = JSON([]byte("{}"), []byte("{} {}"))
+	assert.ErrorContains(t, err, "second document is not JSON")
+
+	assert.True(t, IsJSON([]byte(` [1, 2] `)))
+	assert.False(t, IsJSON([]byte("<html>")))
+}
+
+func TestMap(t *testing.T) {
+	changes := Map(
+		map[string]string{"Content-Type": "text/plain", "X-Old": "1", "Server": "a"},
+		map[string]string{"Content-Type": "application/json", "X-New": "2", "Server": "a"},
+	)
+	assert.Equal(t, []Change{
+		{Path: "Content-Type", Kind: Changed, Old: "text/plain", New: "application/json"},
+		{Path: "X-New", Kind: Added, New: "2"},
+		{Path: "X-Old", Kind: Removed, Old: "1"},
+	}, changes)
+}
+
+func TestLines(t *testing.T) {
+	lines := Lines("a\nb\nc\nd\n", "a\nx\nc\nd\ne")
+	assert.Equal(t, []Line{
+		{Op: Equal, Text: "a"},
+		{Op: Delete, Text: "b"},
+		{Op: Insert, Text: "x"},
+		{Op: Equal, Text: "c"},
+		{Op: Equal, Text: "d"},
+		{Op: Insert, Text: "e"},
+	}, lines)
+
+	assert.Empty(t, Lines("", ""))
+	assert.Equal(t, []Line{{Op: Insert, Text: "new"}}, Lines("", "new"))
+	assert.Equal(t, []Line{{Op: Equal, Text: "same"}}, Lines("same\r\n", "same"))
+}
+
+func TestLines_Render(t *testing.T) {
+	var out []string
+	for _, l := range Lines("one\ntwo", "one\nthree") {
+		out = append(out, l.String())
+	}
+	assert.Equal(t, []string{" one", "-two", "+three"}, out)
+}
+
+func TestChange_String(t *testing.T) {
+	assert.Equal(t, "+ $.a: 1", Change{Path: "$.a", Kind: Added, New: "1"}.String())
+	assert.Equal(t, "- $.a: 1", Change{Path: "$.a", Kind: Removed, Old: "1"}.String())
+	assert.Equal(t, "~ $.a: 1 → 2", Change{Path: "$.a", Kind: Changed, Old: "1", New: "2"}.String())
+}
+
+func TestIndent(t *testing.T) {
+	assert.Equal(t, "{\n  \"a\": 1\n}", Indent([]byte(` {"a":1} `)))
+	assert.Equal(t, "plain", Indent([]byte("plain")))
+}
@@ -0,0 +1,231 @@
+// Package faker generates realistic random values for request bodies, either
+// from {{fake.NAME}} placeholders or from a JSON Schema.
+package faker
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/williajm/curly/internal/domain"
+)
+
+// placeholder matches {{fake.NAME}}, allowing spaces inside the braces.
+var placeholder = regexp.MustCompile(`\{\{\s*fake\.([A-Za-z0-9_]+)\s*\}\}`)
+
+// Faker generates random values. It is safe for concurrent use.
+type Faker struct {
+	mu  sync.Mutex
+	rnd *rand.Rand
+}
+
+// New creates a Faker whose values are determined by seed, so the same seed
+// always produces the same sequence.
+func New(seed uint64) *Faker {
+	return &Faker{rnd: rand.New(rand.NewPCG(seed, seed))} // #nosec G404 -- test data, not secrets
+}
+
+// NewRandom creates a Faker seeded from the clock.
+func NewRandom() *Faker {
+	return New(uint64(time.Now().UnixNano())) // #nosec G115 -- any seed will do
+}
+
+// generator produces one kind of value. Values never contain quotes or
+// backslashes, so they can be placed inside JSON strings as they are.
+type generator func(r *rand.Rand) string
+
+var generators = map[string]generator{
+	"name":      func(r *rand.Rand) string { return pick(r, firstNames) + " " + pick(r, lastNames) },
+	"firstName": func(r *rand.Rand) string { return pick(r, firstNames) },
+	"lastName":  func(r *rand.Rand) string { return pick(r, lastNames) },
+	"email":     email,
+	"username":  username,
+	"uuid":      uuid,
+	"phone": func(r *rand.Rand) string {
+		return fmt.Sprintf("+1-%03d-555-%04d", 200+r.IntN(800), r.IntN(10000))
+	},
+	"company": func(r *rand.Rand) string { return pick(r, lastNames) + " " + pick(r, companySuffixes) },
+	"city":    func(r *rand.Rand) string { return pick(r, cities) },
+	"country": func(r *rand.Rand) string { return pick(r, countries) },
+	"street": func(r *rand.Rand) string {
+		return strconv.Itoa(1+r.IntN(9999)) + " " + pick(r, lastNames) + " " + pick(r, streetSuffixes)
+	},
+	"zip": func(r *rand.Rand) string { return fmt.Sprintf("%05d", r.IntN(100000)) },
+	"url": func(r *rand.Rand) string {
+		return "https://" + strings.ToLower(pick(r, lastNames)) + ".example.com/" + pick(r, words)
+	},
+	"ipv4": func(r *rand.Rand) string {
+		return fmt.Sprintf("%d.%d.%d.%d", 1+r.IntN(223), r.IntN(256), r.IntN(256), 1+r.IntN(254))
+	},
+	"word":     func(r *rand.Rand) string { return pick(r, words) },
+	"sentence": sentence,
+	"int":      func(r *rand.Rand) string { return strconv.Itoa(r.IntN(1000)) },
+	"bool":     func(r *rand.Rand) string { return strconv.FormatBool(r.IntN(2) == 1) },
+	"date":     func(r *rand.Rand) string { return randomTime(r).Format(time.DateOnly) },
+	"datetime": func(r *rand.Rand) string { return randomTime(r).Format(time.RFC3339) },
+}
+
+// Generators lists the names accepted by Value and in placeholders, sorted.
+func Generators() []string {
+	names := make([]string, 0, len(generators))
+	for name := range generators {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Value generates a value of the named kind, such as "email" or "uuid".
+func (f *Faker) Value(name string) (string, error) {
+	gen, ok := generators[name]
+	if !ok {
+		return "", fmt.Errorf("unknown fake generator %q (available: %s)", name, strings.Join(Generators(), ", "))
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return gen(f.rnd), nil
+}
+
+// HasPlaceholders reports whether s contains a {{fake.NAME}} placeholder.
+func HasPlaceholders(s string) bool {
+	return placeholder.MatchString(s)
+}
+
+// Expand replaces every {{fake.NAME}} placeholder in s with a fresh value.
+// Other {{...}} text is left alone. It fails on the first unknown name.
+func (f *Faker) Expand(s string) (string, error) {
+	var err error
+	out := placeholder.ReplaceAllStringFunc(s, func(match string) string {
+		if err != nil {
+			return match
+		}
+		var value string
+		value, err = f.Value(placeholder.FindStringSubmatch(match)[1])
+		return value
+	})
+	if err != nil {
+		return "", err
+	}
+	return out, nil
+}
+
+// ExpandRequest returns req with the placeholders in its URL, header and
+// query values and body replaced. A request without placeholders is returned
+// as is; otherwise the result is a copy and req is not modified.
+func (f *Faker) ExpandRequest(req *domain.Request) (*domain.Request, error) {
+	if !requestHasPlaceholders(req) {
+		return req, nil
+	}
+
+	expanded := req.Clone()
+	var err error
+	if expanded.URL, err = f.Expand(req.URL); err != nil {
+		return nil, err
+	}
+	if expanded.Body, err = f.Expand(req.Body); err != nil {
+		return nil, err
+	}
+	for k, v := range req.Headers {
+		if expanded.Headers[k], err = f.Expand(v); err != nil {
+			return nil, err
+		}
+	}
+	for k, v := range req.QueryParams {
+		if expanded.QueryParams[k], err = f.Expand(v); err != nil {
+			return nil, err
+		}
+	}
+	return expanded, nil
+}
+
+// requestHasPlaceholders reports whether any part of req that ExpandRequest
+// rewrites contains a placeholder.
+func requestHasPlaceholders(req *domain.Request) bool {
+	if HasPlaceholders(req.URL) || HasPlaceholders(req.Body) {
+		return true
+	}
+	for _, v := range req.Headers {
+		if HasPlaceholders(v) {
+			return true
+		}
+	}
+	for _, v := range req.QueryParams {
+		if HasPlaceholders(v) {
+			return true
+		}
+	}
+	return false
+}
+
+func email(r *rand.Rand) string {
+	return strings.ToLower(pick(r, firstNames)+"."+pick(r, lastNames)) + "@" + pick(r, domains)
+}
+
+func username(r *rand.Rand) string {
+	return strings.ToLower(pick(r, firstNames)) + strconv.Itoa(r.IntN(1000))
+}
+
+// uuid returns a random version 4 UUID.
+func uuid(r *rand.Rand) string {
+	var b [16]byte
+	for i := range b {
+		b[i] = byte(r.IntN(256))
+	}
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+func sentence(r *rand.Rand) string {
+	n := 4 + r.IntN(6)
+	parts := make([]string, n)
+	for i := range parts {
+		parts[i] = pick(r, words)
+	}
+	s := strings.Join(parts, " ")
+	return strings.ToUpper(s[:1]) + s[1:] + "."
+}
+
+// randomTime returns a time within about five years of 2020, at whole seconds.
+func randomTime(r *rand.Rand) time.Time {
+	base := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	return base.Add(time.Duration(r.Int64N(5*365*24*3600)) * time.Second)
+}
+
+func pick(r *rand.Rand, values []string) string {
+	return values[r.IntN(len(values))]
+}
+
+var (
+	firstNames = []string{
+		"Ada", "Alan", "Amara", "Ben", "Chloe", "Diego", "Elena", "Farah", "Grace", "Hiro",
+		"Ines", "Jamal", "Kai", "Lena", "Mateo", "Nadia", "Omar", "Priya", "Quinn", "Rosa",
+		"Sven", "Tara", "Umar", "Vera", "Wei", "Yara", "Zoe",
+	}
+	lastNames = []string{
+		"Anders", "Baker", "Chen", "Dubois", "Evans", "Fischer", "Garcia", "Hughes", "Ito", "Jensen",
+		"Kowalski", "Lopez", "Moreau", "Nakamura", "Okafor", "Patel", "Rossi", "Silva", "Turner",
+		"Usman", "Varga", "Walker", "Young", "Zhang",
+	}
+	domains         = []string{"example.com", "example.net", "example.org", "mail.example.com"}
+	companySuffixes = []string{"Inc", "Ltd", "Group", "Labs", "Systems", "Partners"}
+	streetSuffixes  = []string{"Street", "Avenue", "Road", "Lane", "Way", "Court"}
+	cities          = []string{
+		"Amsterdam", "Austin", "Berlin", "Bristol", "Cape Town", "Dublin", "Kyoto", "Lagos", "Lisbon",
+		"Melbourne", "Montreal", "Oslo", "Seoul", "Toronto", "Valencia",
+	}
+	countries = []string{
+		"Australia", "Brazil", "Canada", "Germany", "Ireland", "Japan", "Kenya", "Mexico", "Netherlands",
+		"Nigeria", "Norway", "Portugal", "South Korea", "Spain", "United Kingdom",
+	}
+	words = []string{
+		"alpha", "amber", "bridge", "cloud", "delta", "ember", "forest", "garden", "harbor", "island",
+		"jasper", "kernel", "lantern", "meadow", "nebula", "orbit", "pixel", "quartz", "river", "summit",
+		"timber", "umbra", "velvet", "willow", "zephyr",
+	}
+)
@@ -0,0 +1,94 @@
+package faker
+
+import (
+	"net/mail"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/williajm/curly/internal/domain"
+)
+
+func TestValue_AllGenerators(t *testing.T) {
+	f := New(1)
+	for _, name := range Generators() {
+		for range 20 {
+			v, err := f.Value(name)
+			require.NoError(t, err, name)
+			assert.NotEmpty(t, v, name)
+			assert.NotContains(t, v, `"`, name)
+			assert.NotContains(t, v, `\`, name)
+		}
+	}
+}
+
+func TestValue_Formats(t *testing.T) {
+	f := New(2)
+
+	email, err := f.Value("email")
+	require.NoError(t, err)
+	_, err = mail.ParseAddress(email)
+	assert.NoError(t, err, email)
+
+	uuid, err := f.Value("uuid")
+	require.NoError(t, err)
+	assert.Regexp(t, regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`), uuid)
+
+	date, err := f.Value("datetime")
+	require.NoError(t, err)
+	assert.Regexp(t, regexp.MustCompile(`^\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}Z$`), date)
+
+	_, err = f.Value("shoeSize")
+	assert.ErrorContains(t, err, `unknown fake generator "shoeSize"`)
+}
+
+func TestNew_Deterministic(t *testing.T) {
+	a, err := New(42).Expand("{{fake.name}} {{fake.uuid}}")
+	require.NoError(t, err)
+	b, err := New(42).Expand("{{fake.name}} {{fake.uuid}}")
+	require.NoError(t, err)
+	assert.Equal(t, a, b)
+}
+
+func TestExpand(t *testing.T) {
+	f := New(3)
+
+	out, err := f.Expand(`{"id": "{{fake.uuid}}", "email": "{{ fake.email }}", "token": "{{token}}"}`)
+	require.NoError(t, err)
+	assert.NotContains(t, out, "fake.")
+	assert.Contains(t, out, `"token": "{{token}}"`)
+	assert.False(t, HasPlaceholders(out))
+
+	_, err = f.Expand("{{fake.nope}}")
+	assert.ErrorContains(t, err, "unknown fake generator")
+
+	out, err = f.Expand("no placeholders")
+	require.NoError(t, err)
+	assert.Equal(t, "no placeholders", out)
+}
+
+func TestExpandRequest(t *testing.T) {
+	f := New(4)
+
+	plain := domain.NewRequestWithMethodAndURL(domain.MethodGet, "https://api.example.com/users")
+	got, err := f.ExpandRequest(plain)
+	require.NoError(t, err)
+	assert.Same(t, plain, got)
+
+	req := domain.NewRequestWithMethodAndURL(domain.MethodPost, "https://api.example.com/users/{{fake.uuid}}")
+	req.SetHeader("X-Request-ID", "{{fake.uuid}}")
+	req.SetQueryParam("ref", "{{fake.word}}")
+	req.Body = `{"name": "{{fake.name}}"}`
+
+	got, err = f.ExpandRequest(req)
+	require.NoError(t, err)
+	assert.NotSame(t, req, got)
+	assert.False(t, strings.Contains(got.URL+got.Body+got.Headers["X-Request-ID"]+got.QueryParams["ref"], "fake."))
+	assert.Equal(t, "{{fake.uuid}}", req.Headers["X-Request-ID"], "the original is not modified")
+
+	req.Body = "{{fake.nope}}"
+	_, err = f.ExpandRequest(req)
+	assert.Error(t, err)
+}
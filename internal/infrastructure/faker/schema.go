@@ -0,0 +1,300 @@
+package faker
+
+import (
+	"math"
+	"math/rand/v2"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// maxDepth bounds how deeply values are generated so that recursive schemas
+// terminate.
+const maxDepth = 8
+
+// propertyHints picks a generator from an object property's name when the
+// schema says no more than "string". Keys are lower case; the first hint
+// contained in the name wins, so more specific hints come first.
+var propertyHints = []struct {
+	contains  string
+	generator string
+}{
+	{"description", "sentence"},
+	{"comment", "sentence"},
+	{"message", "sentence"},
+	{"email", "email"},
+	{"username", "username"},
+	{"firstname", "firstName"},
+	{"first_name", "firstName"},
+	{"lastname", "lastName"},
+	{"last_name", "lastName"},
+	{"surname", "lastName"},
+	{"company", "company"},
+	{"name", "name"},
+	{"phone", "phone"},
+	{"city", "city"},
+	{"country", "country"},
+	{"street", "street"},
+	{"address", "street"},
+	{"zip", "zip"},
+	{"postcode", "zip"},
+	{"postal", "zip"},
+	{"url", "url"},
+	{"website", "url"},
+	{"uuid", "uuid"},
+	{"ipaddress", "ipv4"},
+	{"ip_address", "ipv4"},
+}
+
+// FromSchema generates a random value matching a JSON Schema given as decoded
+// JSON. Local $refs such as #/components/schemas/User are resolved against
+// root, which may be the schema itself or the document containing it.
+//
+// Objects get every declared property, arrays one to three items, and strings
+// a value matching their format or, failing that, their property name, so an
+// "email" property gets an email address. Enums and consts are honoured,
+// and minimum/maximum and length bounds are respected.
+func (f *Faker) FromSchema(root, schema any) any {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	g := &schemaGenerator{root: root, rnd: f.rnd}
+	return g.value(schema, "", 0)
+}
+
+type schemaGenerator struct {
+	root any
+	rnd  *rand.Rand
+}
+
+// value generates a value for schema. name is the property the value is for,
+// if any.
+func (g *schemaGenerator) value(schema any, name string, depth int) any {
+	s := g.resolve(schema)
+	if s == nil || depth > maxDepth {
+		return nil
+	}
+
+	if v, ok := s["const"]; ok {
+		return v
+	}
+	if enum, ok := s["enum"].([]any); ok && len(enum) > 0 {
+		return enum[g.rnd.IntN(len(enum))]
+	}
+	if v, ok := g.combined(s, name, depth); ok {
+		return v
+	}
+	return g.typed(s, name, depth)
+}
+
+// combined generates a value for an allOf, oneOf or anyOf schema: the merged
+// fields of every allOf part, or a random alternative. It reports false if
+// the schema combines nothing.
+func (g *schemaGenerator) combined(s map[string]any, name string, depth int) (any, bool) {
+	if parts, ok := s["allOf"].([]any); ok && len(parts) > 0 {
+		merged := map[string]any{}
+		for _, part := range parts {
+			if fields, ok := g.value(part, name, depth+1).(map[string]any); ok {
+				for k, v := range fields {
+					merged[k] = v
+				}
+			}
+		}
+		return merged, true
+	}
+	for _, key := range []string{"oneOf", "anyOf"} {
+		if options, ok := s[key].([]any); ok && len(options) > 0 {
+			return g.value(options[g.rnd.IntN(len(options))], name, depth+1), true
+		}
+	}
+	return nil, false
+}
+
+// typed generates a value from a schema's type.
+func (g *schemaGenerator) typed(s map[string]any, name string, depth int) any {
+	switch schemaType(s) {
+	case "object":
+		return g.object(s, depth)
+	case "array":
+		lo, hi := g.bounds(s, "minItems", "maxItems", 1, 3)
+		items := make([]any, lo+g.rnd.IntN(hi-lo+1))
+		for i := range items {
+			items[i] = g.value(s["items"], name, depth+1)
+		}
+		return items
+	case "integer":
+		lo, hi := g.bounds(s, "minimum", "maximum", 1, 1000)
+		return lo + g.rnd.IntN(hi-lo+1)
+	case "number":
+		lo, hi := g.bounds(s, "minimum", "maximum", 0, 1000)
+		return math.Round((float64(lo)+g.rnd.Float64()*float64(hi-lo))*100) / 100
+	case "boolean":
+		return g.rnd.IntN(2) == 1
+	case "string":
+		return g.str(s, name)
+	default:
+		return nil
+	}
+}
+
+// object generates a value for each of an object schema's properties.
+func (g *schemaGenerator) object(s map[string]any, depth int) map[string]any {
+	props, _ := s["properties"].(map[string]any)
+	names := make([]string, 0, len(props))
+	for prop := range props {
+		names = append(names, prop)
+	}
+	sort.Strings(names) // Consume random numbers in a stable order.
+	fields := make(map[string]any, len(props))
+	for _, prop := range names {
+		fields[prop] = g.value(props[prop], prop, depth+1)
+	}
+	return fields
+}
+
+// str generates a string for a schema by its format, then by the property
+// name, and clips it to the schema's length bounds.
+func (g *schemaGenerator) str(s map[string]any, name string) string {
+	var v string
+	switch format, _ := s["format"].(string); format {
+	case "email":
+		v = generators["email"](g.rnd)
+	case "uuid":
+		v = generators["uuid"](g.rnd)
+	case "date-time":
+		v = generators["datetime"](g.rnd)
+	case "date":
+		v = generators["date"](g.rnd)
+	case "uri", "url":
+		v = generators["url"](g.rnd)
+	case "ipv4":
+		v = generators["ipv4"](g.rnd)
+	default:
+		v = g.byName(name)
+	}
+
+	lo, hi := g.bounds(s, "minLength", "maxLength", 0, math.MaxInt32)
+	for len(v) < lo {
+		v += " " + pick(g.rnd, words)
+	}
+	if len(v) > hi {
+		v = strings.TrimSpace(v[:hi])
+	}
+	return v
+}
+
+// byName generates a string suited to a property name.
+func (g *schemaGenerator) byName(name string) string {
+	lower := strings.ToLower(name)
+	for _, hint := range propertyHints {
+		if lower != "" && strings.Contains(lower, hint.contains) {
+			return generators[hint.generator](g.rnd)
+		}
+	}
+	return pick(g.rnd, words)
+}
+
+// bounds reads an inclusive integer range from the schema keywords minKey and
+// maxKey, using lo and hi for missing ones. An open side is placed near the
+// other so that, say, minimum 5000 alone yields values close to 5000. Bounds
+// are clamped to 32 bits so the range never overflows.
+func (g *schemaGenerator) bounds(s map[string]any, minKey, maxKey string, lo, hi int) (int, int) {
+	minV, hasMin := number(s[minKey])
+	maxV, hasMax := number(s[maxKey])
+	minV = math.Max(minV, math.MinInt32)
+	maxV = math.Min(maxV, math.MaxInt32)
+	switch {
+	case hasMin && hasMax:
+		lo, hi = int(math.Ceil(minV)), int(math.Floor(maxV))
+	case hasMin:
+		lo = int(math.Ceil(minV))
+		hi = max(hi, min(lo+hi, math.MaxInt32))
+	case hasMax:
+		hi = int(math.Floor(maxV))
+		lo = min(lo, hi)
+	}
+	if hi < lo {
+		hi = lo
+	}
+	return lo, hi
+}
+
+// resolve follows $refs and returns the schema as an object, or nil if it is
+// missing, unresolvable or not an object. The boolean schema true is an empty
+// object.
+func (g *schemaGenerator) resolve(schema any) map[string]any {
+	for range maxDepth {
+		if b, ok := schema.(bool); ok && b {
+			return map[string]any{}
+		}
+		s, ok := schema.(map[string]any)
+		if !ok {
+			return nil
+		}
+		ref, ok := s["$ref"].(string)
+		if !ok {
+			return s
+		}
+		schema = pointer(g.root, ref)
+	}
+	return nil
+}
+
+// pointer returns the value at a local reference such as #/definitions/User,
+// or nil if there is none.
+func pointer(root any, ref string) any {
+	path, ok := strings.CutPrefix(ref, "#")
+	if !ok {
+		return nil
+	}
+	v := root
+	for _, token := range strings.Split(strings.TrimPrefix(path, "/"), "/") {
+		if token == "" {
+			continue
+		}
+		if unescaped, err := url.PathUnescape(token); err == nil {
+			token = unescaped
+		}
+		token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+		node, ok := v.(map[string]any)
+		if !ok {
+			return nil
+		}
+		v = node[token]
+	}
+	return v
+}
+
+// schemaType returns a schema's type. A list of types uses the first one that
+// is not "null", and schemas with properties but no type are objects.
+func schemaType(s map[string]any) string {
+	switch t := s["type"].(type) {
+	case string:
+		return t
+	case []any:
+		for _, v := range t {
+			if name, ok := v.(string); ok && name != "null" {
+				return name
+			}
+		}
+	}
+	if _, ok := s["properties"]; ok {
+		return "object"
+	}
+	return ""
+}
+
+// number converts a decoded JSON or YAML number.
+func number(v any) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case uint64:
+		return float64(n), true
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,91 @@
+package faker
+
+import (
+	"encoding/json"
+	"net/mail"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func decode(t *testing.T, s string) any {
+	t.Helper()
+	var v any
+	require.NoError(t, json.Unmarshal([]byte(s), &v))
+	return v
+}
+
+func TestFromSchema_Object(t *testing.T) {
+	schema := decode(t, `{
+	  "type": "object",
+	  "properties": {
+	    "id": {"type": "string", "format": "uuid"},
+	    "email": {"type": "string"},
+	    "age": {"type": "integer", "minimum": 18, "maximum": 30},
+	    "score": {"type": "number", "minimum": 0, "maximum": 1},
+	    "active": {"type": "boolean"},
+	    "role": {"enum": ["admin", "editor"]},
+	    "kind": {"const": "user"},
+	    "code": {"type": "string", "minLength": 20, "maxLength": 24},
+	    "tags": {"type": "array", "items": {"type": "string"}, "minItems": 2, "maxItems": 2},
+	    "nickname": {"type": ["null", "string"], "maxLength": 3}
+	  }
+	}`)
+
+	f := New(5)
+	for range 20 {
+		got, ok := f.FromSchema(schema, schema).(map[string]any)
+		require.True(t, ok)
+
+		assert.Len(t, got["id"], 36)
+		_, err := mail.ParseAddress(got["email"].(string))
+		assert.NoError(t, err)
+		assert.GreaterOrEqual(t, got["age"], 18)
+		assert.LessOrEqual(t, got["age"], 30)
+		assert.GreaterOrEqual(t, got["score"], 0.0)
+		assert.LessOrEqual(t, got["score"], 1.0)
+		assert.IsType(t, true, got["active"])
+		assert.Contains(t, []any{"admin", "editor"}, got["role"])
+		assert.Equal(t, "user", got["kind"])
+		assert.GreaterOrEqual(t, len(got["code"].(string)), 20)
+		assert.LessOrEqual(t, len(got["code"].(string)), 24)
+		assert.Len(t, got["tags"], 2)
+		assert.LessOrEqual(t, len(got["nickname"].(string)), 3)
+
+		// The result always encodes as JSON.
+		_, err = json.Marshal(got)
+		assert.NoError(t, err)
+	}
+}
+
+func TestFromSchema_Refs(t *testing.T) {
+	doc := decode(t, `{
+	  "components": {"schemas": {
+	    "Node": {"type": "object", "properties": {
+	      "name": {"type": "string"},
+	      "child": {"$ref": "#/components/schemas/Node"}
+	    }},
+	    "Base": {"type": "object", "properties": {"id": {"type": "integer"}}},
+	    "Pet": {"allOf": [
+	      {"$ref": "#/components/schemas/Base"},
+	      {"type": "object", "properties": {"species": {"oneOf": [{"const": "cat"}, {"const": "dog"}]}}}
+	    ]}
+	  }}
+	}`)
+
+	f := New(6)
+	pet := f.FromSchema(doc, decode(t, `{"$ref": "#/components/schemas/Pet"}`)).(map[string]any)
+	assert.Contains(t, pet, "id")
+	assert.Contains(t, []any{"cat", "dog"}, pet["species"])
+
+	// A recursive schema stops at the depth limit.
+	node := f.FromSchema(doc, decode(t, `{"$ref": "#/components/schemas/Node"}`))
+	depth := 0
+	for n, ok := node.(map[string]any); ok; n, ok = n["child"].(map[string]any) {
+		depth++
+	}
+	assert.LessOrEqual(t, depth, maxDepth+1)
+
+	assert.Nil(t, f.FromSchema(doc, decode(t, `{"$ref": "#/components/schemas/Missing"}`)))
+}
@@ -0,0 +1,106 @@
+package filesync
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+
+	"github.com/williajm/curly/internal/domain"
+	"gopkg.in/yaml.v3"
+)
+
+// EnvironmentsDir is the subdirectory holding one YAML file per environment.
+const EnvironmentsDir = "environments"
+
+// environmentFile is the on-disk form of an environment.
+// Which environment is active is left out, as each user picks their own.
+type environmentFile struct {
+	ID        string            `yaml:"id"`
+	Name      string            `yaml:"name"`
+	Variables map[string]string `yaml:"variables,omitempty"`
+	Secrets   []string          `yaml:"secrets,omitempty"`
+}
+
+// EnvironmentFileName returns the deterministic file name for an environment:
+// a slug of its name followed by the first characters of its ID.
+func EnvironmentFileName(env *domain.Environment) string {
+	return fileName(env.Name, env.ID, "environment")
+}
+
+// WriteEnvironments mirrors environments into dir/environments, one file per
+// environment, in the same way WriteRequests mirrors requests. It returns the
+// number of files written or removed.
+func WriteEnvironments(dir string, envs []*domain.Environment) (int, error) {
+	files := make([]file, 0, len(envs))
+	for _, env := range envs {
+		data, err := MarshalEnvironment(env)
+		if err != nil {
+			return 0, err
+		}
+		files = append(files, file{name: EnvironmentFileName(env), id: env.ID, data: data})
+	}
+	return writeFiles(filepath.Join(dir, EnvironmentsDir), "environment", files)
+}
+
+// ReadEnvironments loads all environment files from dir/environments in file
+// name order. Environments without an ID are returned with an empty ID for
+// the caller to assign. The error wraps fs.ErrNotExist if the directory does
+// not exist.
+func ReadEnvironments(dir string) ([]*domain.Environment, error) {
+	files, err := readFiles(filepath.Join(dir, EnvironmentsDir), "environment")
+	if err != nil {
+		return nil, err
+	}
+
+	envs := make([]*domain.Environment, 0, len(files))
+	for _, f := range files {
+		env, err := UnmarshalEnvironment(f.data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", f.name, err)
+		}
+		envs = append(envs, env)
+	}
+
+	return envs, nil
+}
+
+// MarshalEnvironment encodes an environment as YAML.
+func MarshalEnvironment(env *domain.Environment) ([]byte, error) {
+	data, err := encodeYAML(&environmentFile{
+		ID:        env.ID,
+		Name:      env.Name,
+		Variables: env.Variables,
+		Secrets:   env.Secrets,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode environment: %w", err)
+	}
+	return data, nil
+}
+
+// UnmarshalEnvironment decodes an environment from YAML.
+// Timestamps are left zero.
+func UnmarshalEnvironment(data []byte) (*domain.Environment, error) {
+	var file environmentFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to decode environment: %w", err)
+	}
+
+	env := &domain.Environment{
+		ID:        file.ID,
+		Name:      file.Name,
+		Variables: file.Variables,
+	}
+	if env.Variables == nil {
+		env.Variables = make(map[string]string)
+	}
+	// Secrets are kept sorted, and only name variables that exist.
+	for _, name := range file.Secrets {
+		if _, ok := env.Variables[name]; ok && !slices.Contains(env.Secrets, name) {
+			env.Secrets = append(env.Secrets, name)
+		}
+	}
+	slices.Sort(env.Secrets)
+
+	return env, nil
+}
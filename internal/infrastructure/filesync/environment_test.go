@@ -0,0 +1,87 @@
+package filesync
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/williajm/curly/internal/domain"
+)
+
+func testEnvironment(id, name string) *domain.Environment {
+	env := domain.NewEnvironment(name)
+	env.ID = id
+	env.Set("base_url", "https://api.example.com", false)
+	env.Set("token", "s3cret", true)
+	return env
+}
+
+func TestMarshalEnvironment_RoundTrip(t *testing.T) {
+	env := testEnvironment("0123456789abcdef", "Staging")
+
+	data, err := MarshalEnvironment(env)
+	require.NoError(t, err)
+	assert.Equal(t, `id: 0123456789abcdef
+name: Staging
+variables:
+  base_url: https://api.example.com
+  token: s3cret
+secrets:
+  - token
+`, string(data))
+
+	got, err := UnmarshalEnvironment(data)
+	require.NoError(t, err)
+	assert.Equal(t, env.ID, got.ID)
+	assert.Equal(t, env.Name, got.Name)
+	assert.Equal(t, env.Variables, got.Variables)
+	assert.Equal(t, env.Secrets, got.Secrets)
+}
+
+func TestUnmarshalEnvironment(t *testing.T) {
+	got, err := UnmarshalEnvironment([]byte("name: dev\nvariables:\n  b: 2\n  a: 1\nsecrets: [b, a, missing, a]\n"))
+	require.NoError(t, err)
+	assert.Empty(t, got.ID)
+	assert.Equal(t, []string{"a", "b"}, got.Secrets)
+
+	got, err = UnmarshalEnvironment([]byte("name: empty\n"))
+	require.NoError(t, err)
+	assert.NotNil(t, got.Variables)
+
+	_, err = UnmarshalEnvironment([]byte("variables: [unterminated"))
+	assert.Error(t, err)
+}
+
+func TestWriteReadEnvironments(t *testing.T) {
+	dir := t.TempDir()
+	dev := testEnvironment("aaaaaaaa-1111", "dev")
+	prod := testEnvironment("bbbbbbbb-2222", "prod")
+
+	changed, err := WriteEnvironments(dir, []*domain.Environment{prod, dev})
+	require.NoError(t, err)
+	assert.Equal(t, 2, changed)
+
+	changed, err = WriteEnvironments(dir, []*domain.Environment{prod, dev})
+	require.NoError(t, err)
+	assert.Equal(t, 0, changed)
+
+	envs, err := ReadEnvironments(dir)
+	require.NoError(t, err)
+	require.Len(t, envs, 2)
+	assert.Equal(t, "dev", envs[0].Name)
+	assert.Equal(t, "prod", envs[1].Name)
+
+	// Deleting an environment removes its file.
+	changed, err = WriteEnvironments(dir, []*domain.Environment{dev})
+	require.NoError(t, err)
+	assert.Equal(t, 1, changed)
+	_, err = os.Stat(filepath.Join(dir, EnvironmentsDir, "prod-bbbbbbbb.yaml"))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestReadEnvironments_MissingDir(t *testing.T) {
+	_, err := ReadEnvironments(t.TempDir())
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
@@ -0,0 +1,353 @@
+// Package filesync mirrors saved requests and environments to a directory of
+// YAML files.
+//
+// Each request is written to its own file under a "requests" subdirectory, and
+// each environment under an "environments" subdirectory, so a workspace can be
+// versioned in git. File names are derived from the name and ID, keys are
+// written in a fixed order and maps are sorted, so exporting an unchanged
+// workspace produces byte-identical files and edits to different requests
+// never touch the same file.
+package filesync
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/williajm/curly/internal/domain"
+	"gopkg.in/yaml.v3"
+)
+
+// RequestsDir is the subdirectory holding one YAML file per request.
+const RequestsDir = "requests"
+
+// fileExt is the extension of request files.
+const fileExt = ".yaml"
+
+// idPrefixLen is how many characters of the request ID are kept in file names.
+const idPrefixLen = 8
+
+// requestFile is the on-disk form of a request.
+// Volatile fields such as timestamps and execution counters are left out so
+// that running a request never changes its file. Folder and position are kept
+// so collections round-trip in their curated order.
+type requestFile struct {
+	ID          string            `yaml:"id"`
+	Folder      string            `yaml:"folder,omitempty"`
+	Position    int               `yaml:"position"`
+	Name        string            `yaml:"name"`
+	Method      string            `yaml:"method"`
+	URL         string            `yaml:"url"`
+	Headers     map[string]string `yaml:"headers,omitempty"`
+	QueryParams map[string]string `yaml:"query_params,omitempty"`
+	Body        string            `yaml:"body,omitempty"`
+	Auth        *authFile         `yaml:"auth,omitempty"`
+	Tags        []string          `yaml:"tags,omitempty"`
+
+	DisabledQueryParams map[string]string `yaml:"disabled_query_params,omitempty"`
+
+	ResponseSchema   string `yaml:"response_schema,omitempty"`
+	ResponseContract string `yaml:"response_contract,omitempty"`
+	ResponseFilter   string `yaml:"response_filter,omitempty"`
+
+	PreRequestScript   string `yaml:"pre_request_script,omitempty"`
+	PostResponseScript string `yaml:"post_response_script,omitempty"`
+}
+
+// authFile is the on-disk form of an auth configuration.
+type authFile struct {
+	Type     string `yaml:"type"`
+	Username string `yaml:"username,omitempty"`
+	Password string `yaml:"password,omitempty"`
+	Token    string `yaml:"token,omitempty"`
+	Key      string `yaml:"key,omitempty"`
+	Value    string `yaml:"value,omitempty"`
+	Location string `yaml:"location,omitempty"`
+}
+
+// FileName returns the deterministic file name for a request:
+// a slug of its name followed by the first characters of its ID.
+func FileName(req *domain.Request) string {
+	return fileName(req.Name, req.ID, "request")
+}
+
+// fileName returns a slug of name followed by the first characters of id,
+// using fallback in place of a name with nothing to slug.
+func fileName(name, id, fallback string) string {
+	if len(id) > idPrefixLen {
+		id = id[:idPrefixLen]
+	}
+
+	slug := slugify(name)
+	if slug == "" {
+		slug = fallback
+	}
+
+	return slug + "-" + id + fileExt
+}
+
+// WriteRequests mirrors requests into dir/requests, one file per request.
+// Files whose content is unchanged are left alone, and request files that no
+// longer correspond to a request are removed. It returns the number of files
+// written or removed.
+func WriteRequests(dir string, requests []*domain.Request) (int, error) {
+	files := make([]file, 0, len(requests))
+	for _, req := range requests {
+		data, err := MarshalRequest(req)
+		if err != nil {
+			return 0, err
+		}
+		files = append(files, file{name: FileName(req), id: req.ID, data: data})
+	}
+	return writeFiles(filepath.Join(dir, RequestsDir), "request", files)
+}
+
+// ReadRequests loads all request files from dir/requests in file name order.
+// Requests without an ID are returned with an empty ID for the caller to assign.
+func ReadRequests(dir string) ([]*domain.Request, error) {
+	files, err := readFiles(filepath.Join(dir, RequestsDir), "request")
+	if err != nil {
+		return nil, err
+	}
+
+	requests := make([]*domain.Request, 0, len(files))
+	for _, f := range files {
+		req, err := UnmarshalRequest(f.data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", f.name, err)
+		}
+		requests = append(requests, req)
+	}
+
+	return requests, nil
+}
+
+// file is a file written to or read from a sync directory.
+type file struct {
+	name string
+	id   string
+	data []byte
+}
+
+// writeFiles mirrors files of the given kind, such as "request", into dir.
+// Files whose content is unchanged are left alone, and files that are not
+// among files are removed. It returns the number of files written or removed.
+func writeFiles(dir, kind string, files []file) (int, error) {
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		return 0, fmt.Errorf("failed to create %ss directory: %w", kind, err)
+	}
+
+	changed := 0
+	keep := make(map[string]bool, len(files))
+
+	for _, f := range files {
+		if keep[f.name] {
+			return changed, fmt.Errorf("duplicate %s file name %q for %s %s", kind, f.name, kind, f.id)
+		}
+		keep[f.name] = true
+
+		path := filepath.Join(dir, f.name)
+		if existing, err := os.ReadFile(path); err == nil && bytes.Equal(existing, f.data) { // #nosec G304 -- path is built from the sync directory
+			continue
+		}
+
+		if err := os.WriteFile(path, f.data, 0600); err != nil {
+			return changed, fmt.Errorf("failed to write %s file: %w", kind, err)
+		}
+		changed++
+	}
+
+	// Remove files of what was deleted or renamed.
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return changed, fmt.Errorf("failed to read %ss directory: %w", kind, err)
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != fileExt || keep[entry.Name()] {
+			continue
+		}
+		if err := os.Remove(filepath.Join(dir, entry.Name())); err != nil {
+			return changed, fmt.Errorf("failed to remove stale %s file: %w", kind, err)
+		}
+		changed++
+	}
+
+	return changed, nil
+}
+
+// readFiles reads the files of the given kind from dir in file name order.
+func readFiles(dir, kind string) ([]file, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %ss directory: %w", kind, err)
+	}
+
+	var files []file
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != fileExt {
+			continue
+		}
+
+		data, err := os.ReadFile(filepath.Join(dir, entry.Name())) // #nosec G304 -- path is built from the sync directory
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s file: %w", kind, err)
+		}
+		files = append(files, file{name: entry.Name(), data: data})
+	}
+
+	return files, nil
+}
+
+// MarshalRequest encodes a request as YAML.
+func MarshalRequest(req *domain.Request) ([]byte, error) {
+	file := requestFile{
+		ID:          req.ID,
+		Folder:      req.Folder,
+		Position:    req.Position,
+		Name:        req.Name,
+		Method:      req.Method,
+		URL:         req.URL,
+		Headers:     req.Headers,
+		QueryParams: req.QueryParams,
+		Body:        req.Body,
+		Tags:        domain.NormalizeTags(req.Tags),
+
+		DisabledQueryParams: req.DisabledQueryParams,
+
+		ResponseSchema:   req.ResponseSchema,
+		ResponseContract: req.ResponseContract,
+		ResponseFilter:   req.ResponseFilter,
+
+		PreRequestScript:   req.PreRequestScript,
+		PostResponseScript: req.PostResponseScript,
+	}
+
+	auth, err := encodeAuth(req.AuthConfig)
+	if err != nil {
+		return nil, err
+	}
+	file.Auth = auth
+
+	data, err := encodeYAML(&file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode request: %w", err)
+	}
+	return data, nil
+}
+
+// encodeYAML encodes v as YAML indented by two spaces.
+func encodeYAML(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// UnmarshalRequest decodes a request from YAML.
+// Timestamps and execution counters are left zero.
+func UnmarshalRequest(data []byte) (*domain.Request, error) {
+	var file requestFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to decode request: %w", err)
+	}
+
+	auth, err := decodeAuth(file.Auth)
+	if err != nil {
+		return nil, err
+	}
+
+	req := &domain.Request{
+		ID:          file.ID,
+		Folder:      file.Folder,
+		Position:    file.Position,
+		Name:        file.Name,
+		Method:      strings.ToUpper(file.Method),
+		URL:         file.URL,
+		Headers:     file.Headers,
+		QueryParams: file.QueryParams,
+		Body:        file.Body,
+		AuthConfig:  auth,
+		Tags:        domain.NormalizeTags(file.Tags),
+
+		DisabledQueryParams: file.DisabledQueryParams,
+
+		ResponseSchema:   file.ResponseSchema,
+		ResponseContract: file.ResponseContract,
+		ResponseFilter:   file.ResponseFilter,
+
+		PreRequestScript:   file.PreRequestScript,
+		PostResponseScript: file.PostResponseScript,
+	}
+	if req.Headers == nil {
+		req.Headers = make(map[string]string)
+	}
+	if req.QueryParams == nil {
+		req.QueryParams = make(map[string]string)
+	}
+
+	return req, nil
+}
+
+// encodeAuth converts an auth configuration to its file form (nil for no auth).
+func encodeAuth(auth domain.AuthConfig) (*authFile, error) {
+	switch a := auth.(type) {
+	case nil, *domain.NoAuth:
+		return nil, nil
+	case *domain.BasicAuth:
+		return &authFile{Type: domain.AuthTypeBasic, Username: a.Username, Password: a.Password}, nil
+	case *domain.BearerAuth:
+		return &authFile{Type: domain.AuthTypeBearer, Token: a.Token}, nil
+	case *domain.APIKeyAuth:
+		return &authFile{Type: domain.AuthTypeAPIKey, Key: a.Key, Value: a.Value, Location: string(a.Location)}, nil
+	default:
+		return nil, fmt.Errorf("unsupported auth type: %T", auth)
+	}
+}
+
+// decodeAuth converts the file form of an auth configuration back to a domain type.
+func decodeAuth(auth *authFile) (domain.AuthConfig, error) {
+	if auth == nil {
+		return domain.NewNoAuth(), nil
+	}
+
+	switch auth.Type {
+	case "", domain.AuthTypeNone:
+		return domain.NewNoAuth(), nil
+	case domain.AuthTypeBasic:
+		return domain.NewBasicAuth(auth.Username, auth.Password), nil
+	case domain.AuthTypeBearer:
+		return domain.NewBearerAuth(auth.Token), nil
+	case domain.AuthTypeAPIKey:
+		return domain.NewAPIKeyAuth(auth.Key, auth.Value, domain.APIKeyLocation(auth.Location)), nil
+	default:
+		return nil, fmt.Errorf("unknown auth type: %s", auth.Type)
+	}
+}
+
+// slugify lowercases name and replaces runs of anything but letters and digits with a dash.
+func slugify(name string) string {
+	var b strings.Builder
+	dash := false
+
+	for _, r := range strings.ToLower(name) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+			dash = false
+			continue
+		}
+		if !dash && b.Len() > 0 {
+			b.WriteByte('-')
+			dash = true
+		}
+	}
+
+	return strings.TrimSuffix(b.String(), "-")
+}
@@ -0,0 +1,195 @@
+package filesync
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/williajm/curly/internal/domain"
+)
+
+func testRequest(id, name string) *domain.Request {
+	req := domain.NewRequestWithMethodAndURL(domain.MethodPost, "https://api.example.com/users")
+	req.ID = id
+	req.Name = name
+	req.Headers = map[string]string{"Content-Type": "application/json", "Accept": "application/json"}
+	req.QueryParams = map[string]string{"page": "1"}
+	req.Body = "{\n  \"name\": \"test\"\n}"
+	req.AuthConfig = domain.NewBearerAuth("secret-token")
+	return req
+}
+
+func TestFileName(t *testing.T) {
+	tests := []struct {
+		name string
+		id   string
+		want string
+	}{
+		{"Get Users", "0123456789abcdef", "get-users-01234567.yaml"},
+		{"  POST /api/v1/users?x=1 ", "abc", "post-api-v1-users-x-1-abc.yaml"},
+		{"", "0123456789abcdef", "request-01234567.yaml"},
+		{"日本", "0123456789abcdef", "request-01234567.yaml"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, FileName(&domain.Request{ID: tt.id, Name: tt.name}))
+		})
+	}
+}
+
+func TestMarshalRequest_RoundTrip(t *testing.T) {
+	tests := []domain.AuthConfig{
+		domain.NewNoAuth(),
+		domain.NewBasicAuth("user", "pass"),
+		domain.NewBearerAuth("token"),
+		domain.NewAPIKeyAuth("X-API-Key", "key", domain.APIKeyLocationHeader),
+	}
+
+	for _, auth := range tests {
+		t.Run(auth.Type(), func(t *testing.T) {
+			req := testRequest("0123456789abcdef", "Create User")
+			req.AuthConfig = auth
+			req.Folder = "users"
+			req.Position = 3
+			req.ResponseSchema = "{\n  \"type\": \"object\"\n}"
+			req.ResponseContract = `{"method":"POST","path":"/users","responses":{"201":{}}}`
+			req.ResponseFilter = "$.id"
+			req.DisabledQueryParams = map[string]string{"debug": "true"}
+			req.PreRequestScript = "request.headers[\"X-Ts\"] = String(Date.now());\n"
+			req.PostResponseScript = "assert(response.status === 201);"
+			req.Tags = []string{"smoke", "users"}
+
+			data, err := MarshalRequest(req)
+			require.NoError(t, err)
+
+			got, err := UnmarshalRequest(data)
+			require.NoError(t, err)
+			assert.Equal(t, req.ID, got.ID)
+			assert.Equal(t, req.Folder, got.Folder)
+			assert.Equal(t, req.Position, got.Position)
+			assert.Equal(t, req.Name, got.Name)
+			assert.Equal(t, req.Method, got.Method)
+			assert.Equal(t, req.URL, got.URL)
+			assert.Equal(t, req.Headers, got.Headers)
+			assert.Equal(t, req.QueryParams, got.QueryParams)
+			assert.Equal(t, req.Body, got.Body)
+			assert.Equal(t, auth, got.AuthConfig)
+			assert.Equal(t, req.ResponseSchema, got.ResponseSchema)
+			assert.Equal(t, req.ResponseContract, got.ResponseContract)
+			assert.Equal(t, req.ResponseFilter, got.ResponseFilter)
+			assert.Equal(t, req.DisabledQueryParams, got.DisabledQueryParams)
+			assert.Equal(t, req.PreRequestScript, got.PreRequestScript)
+			assert.Equal(t, req.PostResponseScript, got.PostResponseScript)
+			assert.Equal(t, req.Tags, got.Tags)
+		})
+	}
+}
+
+func TestMarshalRequest_StableFormatting(t *testing.T) {
+	req := testRequest("0123456789abcdef", "Create User")
+	req.Folder = "users"
+	req.Position = 2
+	data, err := MarshalRequest(req)
+	require.NoError(t, err)
+
+	want := `id: 0123456789abcdef
+folder: users
+position: 2
+name: Create User
+method: POST
+url: https://api.example.com/users
+headers:
+  Accept: application/json
+  Content-Type: application/json
+query_params:
+  page: "1"
+body: |-
+  {
+    "name": "test"
+  }
+auth:
+  type: bearer
+  token: secret-token
+`
+	assert.Equal(t, want, string(data))
+}
+
+func TestUnmarshalRequest_Invalid(t *testing.T) {
+	_, err := UnmarshalRequest([]byte("auth:\n  type: kerberos\n"))
+	assert.Error(t, err)
+
+	_, err = UnmarshalRequest([]byte("name: [unterminated"))
+	assert.Error(t, err)
+}
+
+func TestWriteRequests(t *testing.T) {
+	dir := t.TempDir()
+	a := testRequest("aaaaaaaa-1111", "Alpha")
+	b := testRequest("bbbbbbbb-2222", "Beta")
+
+	changed, err := WriteRequests(dir, []*domain.Request{a, b})
+	require.NoError(t, err)
+	assert.Equal(t, 2, changed)
+
+	// Exporting again without changes touches nothing.
+	changed, err = WriteRequests(dir, []*domain.Request{a, b})
+	require.NoError(t, err)
+	assert.Equal(t, 0, changed)
+
+	// Renaming a request replaces its file; deleting one removes its file.
+	a.Name = "Alpha Renamed"
+	changed, err = WriteRequests(dir, []*domain.Request{a})
+	require.NoError(t, err)
+	assert.Equal(t, 3, changed)
+
+	entries, err := os.ReadDir(filepath.Join(dir, RequestsDir))
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "alpha-renamed-aaaaaaaa.yaml", entries[0].Name())
+}
+
+func TestWriteRequests_LeavesOtherFiles(t *testing.T) {
+	dir := t.TempDir()
+	reqDir := filepath.Join(dir, RequestsDir)
+	require.NoError(t, os.MkdirAll(reqDir, 0750))
+	require.NoError(t, os.WriteFile(filepath.Join(reqDir, "README.md"), []byte("notes"), 0600))
+
+	_, err := WriteRequests(dir, nil)
+	require.NoError(t, err)
+
+	_, err = os.Stat(filepath.Join(reqDir, "README.md"))
+	assert.NoError(t, err)
+}
+
+func TestReadRequests(t *testing.T) {
+	dir := t.TempDir()
+	_, err := WriteRequests(dir, []*domain.Request{
+		testRequest("bbbbbbbb-2222", "Beta"),
+		testRequest("aaaaaaaa-1111", "Alpha"),
+	})
+	require.NoError(t, err)
+
+	// A hand-written file without an ID.
+	manual := "name: Health\nmethod: get\nurl: https://api.example.com/health\n"
+	require.NoError(t, os.WriteFile(filepath.Join(dir, RequestsDir, "health.yaml"), []byte(manual), 0600))
+
+	requests, err := ReadRequests(dir)
+	require.NoError(t, err)
+	require.Len(t, requests, 3)
+
+	assert.Equal(t, "Alpha", requests[0].Name)
+	assert.Equal(t, "Beta", requests[1].Name)
+	assert.Equal(t, "Health", requests[2].Name)
+	assert.Empty(t, requests[2].ID)
+	assert.Equal(t, domain.MethodGet, requests[2].Method)
+	assert.NotNil(t, requests[2].Headers)
+	assert.Equal(t, domain.AuthTypeNone, requests[2].AuthConfig.Type())
+}
+
+func TestReadRequests_MissingDir(t *testing.T) {
+	_, err := ReadRequests(filepath.Join(t.TempDir(), "missing"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,105 @@
+// Package fuzzy ranks text by how well it matches a short typed pattern,
+// such as "gtusr" for "GET /users", the way editor file finders do.
+package fuzzy
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Scoring weights. Matches at the start of words and runs of consecutive
+// characters rank higher; characters skipped between matches cost a little.
+const (
+	scoreMatch       = 16
+	bonusBoundary    = 8
+	bonusConsecutive = 12
+	bonusFirst       = 8
+	penaltyGap       = 1
+)
+
+// Match reports whether every character of pattern appears in text, in
+// order and ignoring case, and scores how well it does: higher is better.
+// Positions holds the byte offset in text of each matched character. An
+// empty pattern matches everything with a score of 0.
+func Match(pattern, text string) (score int, positions []int, ok bool) {
+	pattern = strings.ToLower(strings.Join(strings.Fields(pattern), ""))
+	if pattern == "" {
+		return 0, nil, true
+	}
+
+	want := []rune(pattern)
+	prev := rune(0)
+	last := -1 // Rune index of the previous match.
+	n := 0
+	for i, r := range text {
+		if len(positions) == len(want) {
+			break
+		}
+		if unicode.ToLower(r) == want[len(positions)] {
+			score += scoreMatch
+			switch {
+			case n == 0:
+				score += bonusFirst + bonusBoundary
+			case isBoundary(prev, r):
+				score += bonusBoundary
+			}
+			if last >= 0 {
+				if gap := n - last - 1; gap == 0 {
+					score += bonusConsecutive
+				} else {
+					score -= gap * penaltyGap
+				}
+			}
+			positions = append(positions, i)
+			last = n
+		}
+		prev = r
+		n++
+	}
+
+	if len(positions) < len(want) {
+		return 0, nil, false
+	}
+	return score, positions, true
+}
+
+// isBoundary reports whether r starts a word after prev: after a separator
+// or where lower case turns to upper case, as in "getUser".
+func isBoundary(prev, r rune) bool {
+	if !unicode.IsLetter(prev) && !unicode.IsDigit(prev) {
+		return true
+	}
+	return unicode.IsLower(prev) && unicode.IsUpper(r)
+}
+
+// Result is a candidate that matched, with its score.
+type Result struct {
+	// Index is the candidate's index in the slice passed to Rank.
+	Index int
+
+	// Score is the candidate's best score over its fields.
+	Score int
+}
+
+// Rank matches pattern against each candidate, whose fields are scored
+// separately so that, say, a name and a URL do not run together, and
+// returns the candidates that match best first. Ties keep their original
+// order, so an empty pattern returns every candidate unchanged.
+func Rank(pattern string, candidates [][]string) []Result {
+	var results []Result
+	for i, fields := range candidates {
+		best, matched := 0, false
+		for _, field := range fields {
+			if score, _, ok := Match(pattern, field); ok && (!matched || score > best) {
+				best, matched = score, true
+			}
+		}
+		if matched {
+			results = append(results, Result{Index: i, Score: best})
+		}
+	}
+
+	sort.SliceStable(results, func(i, j int) bool { return results[i].Score > results[j].Score })
+	return results
+}
@@ -0,0 +1,72 @@
+package fuzzy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatch(t *testing.T) {
+	score, positions, ok := Match("gus", "Get Users")
+	require.True(t, ok)
+	assert.Equal(t, []int{0, 4, 5}, positions)
+	assert.Positive(t, score)
+
+	_, _, ok = Match("usg", "Get Users")
+	assert.False(t, ok, "characters must appear in order")
+
+	_, positions, ok = Match("é", "Café")
+	require.True(t, ok)
+	assert.Equal(t, []int{3}, positions)
+
+	score, positions, ok = Match("  ", "anything")
+	assert.True(t, ok)
+	assert.Zero(t, score)
+	assert.Nil(t, positions)
+}
+
+func TestMatch_Spaces(t *testing.T) {
+	_, _, ok := Match("get users", "getUsers")
+	assert.True(t, ok, "spaces in the pattern are ignored")
+}
+
+func TestMatch_ScoresBoundariesAndRuns(t *testing.T) {
+	consecutive, _, _ := Match("user", "list users")
+	scattered, _, _ := Match("user", "unused error")
+	assert.Greater(t, consecutive, scattered)
+
+	boundary, _, _ := Match("gu", "getUser")
+	middle, _, _ := Match("gu", "beguiled")
+	assert.Greater(t, boundary, middle)
+}
+
+func TestRank(t *testing.T) {
+	candidates := [][]string{
+		{"Delete order", "https://api.example.com/orders/1"},
+		{"List users", "https://api.example.com/users"},
+		{"Health", "https://api.example.com/health", "smoke"},
+	}
+
+	results := Rank("users", candidates)
+	require.Len(t, results, 1)
+	assert.Equal(t, 1, results[0].Index)
+
+	// Tags are fields like any other.
+	results = Rank("smoke", candidates)
+	require.Len(t, results, 1)
+	assert.Equal(t, 2, results[0].Index)
+
+	// The best field decides: "ord" starts a word in "Delete order".
+	results = Rank("ord", candidates)
+	require.Len(t, results, 1)
+	assert.Equal(t, 0, results[0].Index)
+
+	results = Rank("", candidates)
+	require.Len(t, results, 3)
+	for i, result := range results {
+		assert.Equal(t, i, result.Index)
+	}
+
+	assert.Empty(t, Rank("zzz", candidates))
+}
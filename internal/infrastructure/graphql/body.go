@@ -0,0 +1,66 @@
+package graphql
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// requestBody is the JSON body of a GraphQL request over HTTP.
+type requestBody struct {
+	Query         string          `json:"query"`
+	OperationName string          `json:"operationName,omitempty"`
+	Variables     json.RawMessage `json:"variables,omitempty"`
+}
+
+// BuildBody returns the JSON request body for query and its variables, which
+// must be empty or a JSON object. The operation name selects which of the
+// query's operations to run, and may be empty when it has only one.
+func BuildBody(query, variables, operationName string) (string, error) {
+	body := requestBody{Query: query, OperationName: operationName}
+	if trimmed := strings.TrimSpace(variables); trimmed != "" {
+		var object map[string]json.RawMessage
+		if err := json.Unmarshal([]byte(trimmed), &object); err != nil {
+			return "", fmt.Errorf("variables must be a JSON object: %w", err)
+		}
+		var compact bytes.Buffer
+		if err := json.Compact(&compact, []byte(trimmed)); err != nil {
+			return "", fmt.Errorf("variables must be a JSON object: %w", err)
+		}
+		body.Variables = compact.Bytes()
+	}
+
+	data, err := json.Marshal(body)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode GraphQL body: %w", err)
+	}
+	return string(data), nil
+}
+
+// SplitBody is the inverse of BuildBody: it returns the query of a JSON
+// GraphQL request body, its variables, indented, or "" if it has none, and
+// its operation name. It returns false for bodies that are not JSON GraphQL
+// requests.
+func SplitBody(body string) (query, variables, operationName string, ok bool) {
+	var decoded struct {
+		Query         *string         `json:"query"`
+		OperationName *string         `json:"operationName"`
+		Variables     json.RawMessage `json:"variables"`
+	}
+	if err := json.Unmarshal([]byte(body), &decoded); err != nil || decoded.Query == nil {
+		return "", "", "", false
+	}
+
+	if len(decoded.Variables) > 0 && string(decoded.Variables) != "null" {
+		var indented bytes.Buffer
+		if err := json.Indent(&indented, decoded.Variables, "", "  "); err != nil {
+			return "", "", "", false
+		}
+		variables = indented.String()
+	}
+	if decoded.OperationName != nil {
+		operationName = *decoded.OperationName
+	}
+	return *decoded.Query, variables, operationName, true
+}
@@ -0,0 +1,55 @@
+package graphql
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildBody(t *testing.T) {
+	body, err := BuildBody("{ user(id: $id) { name } }", "", "")
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"query": "{ user(id: $id) { name } }"}`, body)
+
+	body, err = BuildBody("query($id: ID!) { user(id: $id) { name } }", "{\n  \"id\": 1\n}", "")
+	require.NoError(t, err)
+	assert.Equal(t, `{"query":"query($id: ID!) { user(id: $id) { name } }","variables":{"id":1}}`, body)
+
+	body, err = BuildBody("query A { a } query B { b }", "", "B")
+	require.NoError(t, err)
+	assert.Equal(t, `{"query":"query A { a } query B { b }","operationName":"B"}`, body)
+
+	for _, variables := range []string{"[1]", "{", `"id"`} {
+		_, err = BuildBody("{ a }", variables, "")
+		assert.ErrorContains(t, err, "variables must be a JSON object", variables)
+	}
+}
+
+func TestSplitBody(t *testing.T) {
+	query, variables, operationName, ok := SplitBody(`{"query": "{ a }", "variables": {"id": 1}}`)
+	require.True(t, ok)
+	assert.Equal(t, "{ a }", query)
+	assert.Equal(t, "{\n  \"id\": 1\n}", variables)
+	assert.Empty(t, operationName)
+
+	query, variables, operationName, ok = SplitBody(`{"query": "query A { a } query B { b }", "operationName": "B", "variables": null}`)
+	require.True(t, ok)
+	assert.Equal(t, "query A { a } query B { b }", query)
+	assert.Empty(t, variables)
+	assert.Equal(t, "B", operationName)
+
+	for _, body := range []string{"", "{ a }", `{"name": "x"}`, `[1]`} {
+		_, _, _, ok = SplitBody(body)
+		assert.False(t, ok, body)
+	}
+
+	// BuildBody and SplitBody round-trip.
+	body, err := BuildBody("{ a }", "{\n  \"id\": 1\n}", "")
+	require.NoError(t, err)
+	query, variables, operationName, ok = SplitBody(body)
+	require.True(t, ok)
+	assert.Equal(t, "{ a }", query)
+	assert.Equal(t, "{\n  \"id\": 1\n}", variables)
+	assert.Empty(t, operationName)
+}
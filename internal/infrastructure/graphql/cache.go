@@ -0,0 +1,73 @@
+package graphql
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// ErrNotCached is returned by Cache.Load when no schema is cached for an endpoint.
+var ErrNotCached = errors.New("schema not cached")
+
+// Cache stores introspected schemas on disk, one file per endpoint, so they
+// survive restarts.
+type Cache struct {
+	dir string
+}
+
+// NewCache creates a cache rooted at dir. The directory is created on first write.
+func NewCache(dir string) *Cache {
+	return &Cache{dir: dir}
+}
+
+// Load returns the schema cached for endpoint, or ErrNotCached.
+func (c *Cache) Load(endpoint string) (*Schema, error) {
+	data, err := os.ReadFile(c.path(endpoint))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNotCached
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cached schema: %w", err)
+	}
+	return UnmarshalSchema(data)
+}
+
+// Save caches the schema of endpoint, replacing any previous one.
+func (c *Cache) Save(endpoint string, schema *Schema) error {
+	data, err := MarshalSchema(schema)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(c.dir, 0750); err != nil {
+		return fmt.Errorf("failed to create schema cache directory: %w", err)
+	}
+
+	// Write to a temporary file and rename so readers never see a partial schema.
+	path := c.path(endpoint)
+	tmp, err := os.CreateTemp(c.dir, filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create schema file: %w", err)
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to write schema: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to close schema file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to store schema: %w", err)
+	}
+	return nil
+}
+
+// path returns the file for endpoint, named by the hash of its URL.
+func (c *Cache) path(endpoint string) string {
+	sum := sha256.Sum256([]byte(endpoint))
+	return filepath.Join(c.dir, hex.EncodeToString(sum[:])+".json")
+}
@@ -0,0 +1,384 @@
+package graphql
+
+import (
+	"sort"
+	"strings"
+)
+
+// Suggestion is a completion candidate.
+type Suggestion struct {
+	// Label is the text to insert, such as a field or argument name.
+	Label string
+
+	// Detail describes the candidate, such as the field's type.
+	Detail string
+}
+
+// Completion lists the candidates for the word being typed at a position.
+type Completion struct {
+	// Prefix is the part of the word already typed; candidates start with it.
+	Prefix string
+
+	Suggestions []Suggestion
+}
+
+// frameKind classifies the nesting levels tracked while completing.
+type frameKind int
+
+const (
+	// frameSelection is a selection set on a type.
+	frameSelection frameKind = iota
+	// frameInputs is an argument list or an input object value.
+	frameInputs
+	// frameList is a list value.
+	frameList
+	// frameOther is any other bracketed region, such as variable definitions.
+	frameOther
+)
+
+// frame is one level of nesting at the completion position.
+type frame struct {
+	kind frameKind
+
+	// typ is the type a selection set selects from (nil if unknown).
+	typ *Type
+
+	// inputs are the arguments or input fields that may be given, given
+	// records those already present, and value is the type of the input
+	// whose value follows the last colon.
+	inputs []*InputValue
+	given  map[string]bool
+	value  *TypeRef
+
+	// elem is a list value's item type.
+	elem *TypeRef
+}
+
+// Complete suggests what can be typed at a byte offset in query: root
+// operation keywords, fields of the enclosing selection set, arguments and
+// input fields, enum and boolean values, type names after "on", and
+// directives after "@". Nothing is suggested inside strings and comments.
+func (s *Schema) Complete(query string, offset int) Completion {
+	if offset < 0 || offset > len(query) {
+		offset = len(query)
+	}
+	res, err := scan(query[:offset])
+	if err != nil || res.endsInComment {
+		return Completion{}
+	}
+	tokens := res.tokens[:len(res.tokens)-1] // Drop EOF.
+
+	var c Completion
+	if n := len(tokens); n > 0 && tokens[n-1].kind == tokenName && tokens[n-1].end == offset {
+		c.Prefix = tokens[n-1].value
+		tokens = tokens[:n-1]
+	}
+
+	for _, candidate := range s.candidates(tokens) {
+		if strings.HasPrefix(candidate.Label, c.Prefix) && candidate.Label != c.Prefix {
+			c.Suggestions = append(c.Suggestions, candidate)
+		}
+	}
+	return c
+}
+
+// candidates lists everything that may follow tokens.
+func (s *Schema) candidates(tokens []token) []Suggestion {
+	stack := s.walk(tokens)
+
+	var prev token
+	if len(tokens) > 0 {
+		prev = tokens[len(tokens)-1]
+	}
+	switch {
+	case prev.is("$"):
+		return nil
+	case prev.is("@"):
+		return s.directiveSuggestions()
+	case prev.is("on"):
+		return s.typeSuggestions()
+	}
+
+	if len(stack) == 0 {
+		if prev.kind == tokenEOF || prev.is("}") {
+			return keywordSuggestions("query", "mutation", "subscription", "fragment")
+		}
+		return nil
+	}
+
+	return s.frameSuggestions(stack[len(stack)-1], prev)
+}
+
+// frameSuggestions lists what may follow prev inside the innermost frame.
+func (s *Schema) frameSuggestions(top *frame, prev token) []Suggestion {
+	switch top.kind {
+	case frameSelection:
+		if prev.is("...") {
+			return keywordSuggestions("on")
+		}
+		if top.typ == nil {
+			return nil
+		}
+		return s.fieldSuggestions(top.typ)
+	case frameInputs:
+		if prev.is(":") {
+			return s.valueSuggestions(top.value)
+		}
+		var out []Suggestion
+		for _, in := range top.inputs {
+			if !top.given[in.Name] {
+				out = append(out, Suggestion{Label: in.Name, Detail: in.Type.String()})
+			}
+		}
+		return out
+	case frameList:
+		return s.valueSuggestions(top.elem)
+	}
+	return nil
+}
+
+// walker tracks the frames enclosing each token as a query is walked.
+type walker struct {
+	schema *Schema
+	stack  []*frame
+
+	// next is the type the next selection set selects from, and args the
+	// arguments the next argument list may give.
+	next    *Type
+	nextSet bool
+	args    []*InputValue
+}
+
+// walk follows tokens and returns the frames enclosing the position after
+// the last one.
+func (s *Schema) walk(tokens []token) []*frame {
+	w := &walker{schema: s}
+	for i, t := range tokens {
+		var prev token
+		if i > 0 {
+			prev = tokens[i-1]
+		}
+
+		switch {
+		case t.is("{"):
+			w.openBrace(prev)
+		case t.is("("):
+			w.openParen()
+		case t.is("["):
+			w.openBracket()
+		case t.is("}"), t.is(")"), t.is("]"):
+			w.close()
+		case t.kind == tokenName:
+			aliased := i+1 < len(tokens) && tokens[i+1].is(":")
+			w.name(t, prev, aliased)
+		}
+	}
+	return w.stack
+}
+
+// top returns the innermost frame, or nil outside any.
+func (w *walker) top() *frame {
+	if len(w.stack) == 0 {
+		return nil
+	}
+	return w.stack[len(w.stack)-1]
+}
+
+// push enters a frame.
+func (w *walker) push(f *frame) {
+	w.stack = append(w.stack, f)
+}
+
+// close leaves the innermost frame.
+func (w *walker) close() {
+	if len(w.stack) > 0 {
+		w.stack = w.stack[:len(w.stack)-1]
+	}
+}
+
+// setNext records the type the next selection set selects from.
+func (w *walker) setNext(t *Type) {
+	w.next, w.nextSet = t, true
+}
+
+// openBrace enters a selection set, or an input object value.
+func (w *walker) openBrace(prev token) {
+	f := w.top()
+	switch {
+	case f == nil:
+		if !w.nextSet {
+			w.setNext(w.schema.RootType("query"))
+		}
+		w.push(&frame{kind: frameSelection, typ: w.next})
+	case f.kind == frameSelection:
+		if prev.is("...") {
+			w.setNext(f.typ)
+		}
+		w.push(&frame{kind: frameSelection, typ: w.next})
+	case f.kind == frameInputs || f.kind == frameList:
+		if it := w.schema.Type(f.valueType().NamedType()); it != nil && it.Kind == KindInputObject {
+			w.push(&frame{kind: frameInputs, inputs: it.InputFields, given: map[string]bool{}})
+		} else {
+			w.push(&frame{kind: frameOther})
+		}
+	default:
+		w.push(&frame{kind: frameOther})
+	}
+	w.next, w.nextSet = nil, false
+}
+
+// openParen enters an argument list, or variable definitions.
+func (w *walker) openParen() {
+	if f := w.top(); f != nil && f.kind == frameSelection {
+		w.push(&frame{kind: frameInputs, inputs: w.args, given: map[string]bool{}})
+	} else {
+		w.push(&frame{kind: frameOther})
+	}
+}
+
+// openBracket enters a list value.
+func (w *walker) openBracket() {
+	if f := w.top(); f != nil && (f.kind == frameInputs || f.kind == frameList) {
+		w.push(&frame{kind: frameList, elem: listItem(f.valueType())})
+	} else {
+		w.push(&frame{kind: frameOther})
+	}
+}
+
+// name follows a name token: a directive, a type condition, an operation
+// type, a field, or an argument or input field. aliased reports whether a
+// colon follows, making a field name an alias.
+func (w *walker) name(t, prev token, aliased bool) {
+	f := w.top()
+	switch {
+	case prev.is("@"):
+		w.args = nil
+		for _, d := range w.schema.Directives {
+			if d.Name == t.value {
+				w.args = d.Args
+			}
+		}
+	case prev.is("on"):
+		w.setNext(w.schema.Type(t.value))
+	case f == nil:
+		switch t.value {
+		case "query", "mutation", "subscription":
+			w.setNext(w.schema.RootType(t.value))
+		}
+	case f.kind == frameSelection:
+		if t.is("on") && prev.is("...") {
+			return
+		}
+		if aliased {
+			return // An alias; the field name follows.
+		}
+		w.field(f, t.value)
+	case f.kind == frameInputs && !prev.is(":") && !prev.is("$"):
+		f.give(t.value)
+	}
+}
+
+// give records that an argument or input field is given, and that its value
+// follows.
+func (f *frame) give(name string) {
+	f.given[name] = true
+	f.value = nil
+	for _, in := range f.inputs {
+		if in.Name == name {
+			f.value = in.Type
+		}
+	}
+}
+
+// field follows a field selected in f, whose arguments and type the next
+// argument list and selection set use.
+func (w *walker) field(f *frame, name string) {
+	field := w.schema.Field(f.typ, name)
+	if field == nil {
+		w.args = nil
+		w.setNext(nil)
+		return
+	}
+	w.args = field.Args
+	w.setNext(w.schema.Type(field.Type.NamedType()))
+}
+
+// valueType returns the type of the value being given in an input or list
+// frame.
+func (f *frame) valueType() *TypeRef {
+	if f.kind == frameList {
+		return f.elem
+	}
+	return f.value
+}
+
+// listItem returns the item type of a list type reference.
+func listItem(ref *TypeRef) *TypeRef {
+	if ref != nil && ref.Kind == KindNonNull {
+		ref = ref.OfType
+	}
+	if ref != nil && ref.Kind == KindList {
+		return ref.OfType
+	}
+	return ref // A single value is accepted where a list is expected.
+}
+
+// fieldSuggestions lists the fields of t, skipping deprecated ones.
+func (s *Schema) fieldSuggestions(t *Type) []Suggestion {
+	var out []Suggestion
+	for _, f := range append(append([]*Field{}, t.Fields...), s.metaFields(t)...) {
+		if !f.IsDeprecated {
+			out = append(out, Suggestion{Label: f.Name, Detail: f.Type.String()})
+		}
+	}
+	return out
+}
+
+// valueSuggestions lists the literal values of an enum or boolean type.
+func (s *Schema) valueSuggestions(ref *TypeRef) []Suggestion {
+	name := ref.NamedType()
+	if name == "Boolean" {
+		return []Suggestion{{Label: "true", Detail: name}, {Label: "false", Detail: name}}
+	}
+	t := s.Type(name)
+	if t == nil || t.Kind != KindEnum {
+		return nil
+	}
+	var out []Suggestion
+	for _, v := range t.EnumValues {
+		if !v.IsDeprecated {
+			out = append(out, Suggestion{Label: v.Name, Detail: name})
+		}
+	}
+	return out
+}
+
+// typeSuggestions lists the composite types, for fragment type conditions.
+func (s *Schema) typeSuggestions() []Suggestion {
+	var out []Suggestion
+	for _, t := range s.Types {
+		if t.Composite() && !strings.HasPrefix(t.Name, "__") {
+			out = append(out, Suggestion{Label: t.Name, Detail: strings.ToLower(t.Kind)})
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Label < out[j].Label })
+	return out
+}
+
+// directiveSuggestions lists the directives the schema supports.
+func (s *Schema) directiveSuggestions() []Suggestion {
+	out := make([]Suggestion, 0, len(s.Directives))
+	for _, d := range s.Directives {
+		out = append(out, Suggestion{Label: d.Name, Detail: "directive"})
+	}
+	return out
+}
+
+// keywordSuggestions lists keywords.
+func keywordSuggestions(keywords ...string) []Suggestion {
+	out := make([]Suggestion, len(keywords))
+	for i, k := range keywords {
+		out[i] = Suggestion{Label: k, Detail: "keyword"}
+	}
+	return out
+}
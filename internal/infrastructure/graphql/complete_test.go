@@ -0,0 +1,67 @@
+package graphql
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// complete completes at the position marked by "|" and returns the labels.
+func complete(t *testing.T, s *Schema, query string) (string, []string) {
+	t.Helper()
+	offset := strings.Index(query, "|")
+	c := s.Complete(strings.Replace(query, "|", "", 1), offset)
+	var labels []string
+	for _, sug := range c.Suggestions {
+		labels = append(labels, sug.Label)
+	}
+	return c.Prefix, labels
+}
+
+func TestComplete(t *testing.T) {
+	s := testSchema(t)
+
+	tests := []struct {
+		name   string
+		query  string
+		prefix string
+		want   []string
+	}{
+		{name: "keywords", query: "|", want: []string{"query", "mutation", "subscription", "fragment"}},
+		{name: "keyword prefix", query: "mu|", prefix: "mu", want: []string{"mutation"}},
+		{name: "root fields", query: "{ |", want: []string{"user", "users", "search", "__typename", "__schema", "__type"}},
+		{name: "field prefix", query: "query Q { users { na| } }", prefix: "na", want: []string{"name"}},
+		{name: "nested", query: "{ user(id: 1) { posts { author { r| } } } }", prefix: "r", want: []string{"role"}},
+		{name: "after alias", query: "{ a: us|", prefix: "us", want: []string{"user", "users"}},
+		{name: "arguments", query: "{ users(|", want: []string{"role", "first"}},
+		{name: "remaining arguments", query: "{ users(role: ADMIN |", want: []string{"first"}},
+		{name: "enum value", query: "{ users(role: |", want: []string{"ADMIN", "EDITOR"}},
+		{name: "input fields", query: "mutation { createUser(input: {name: \"a\" |", want: []string{"role", "tags"}},
+		{name: "list of enums", query: "mutation { createUser(input: {tags: [ADMIN |", want: []string{"ADMIN", "EDITOR"}},
+		{name: "boolean", query: "{ users @include(if: |", want: []string{"true", "false"}},
+		{name: "directives", query: "{ users @|", want: []string{"include", "skip"}},
+		{name: "type condition", query: "{ search(term: \"a\") { ... on |", want: []string{"Mutation", "Node", "Post", "Query", "SearchResult", "User"}},
+		{name: "inline fragment", query: "{ search(term: \"a\") { ... on Post { t| } } }", prefix: "t", want: []string{"title"}},
+		{name: "on keyword", query: "{ search(term: \"a\") { ...|", want: []string{"on"}},
+		{name: "fragment definition", query: "fragment F on Post { author { |", want: []string{"id", "name", "role", "posts", "__typename"}},
+		{name: "after directive arguments", query: "{ users @skip(if: false) { i|", prefix: "i", want: []string{"id"}},
+		{name: "after closed operation", query: "{ users { id } } |", want: []string{"query", "mutation", "subscription", "fragment"}},
+		{name: "unknown field", query: "{ nope { |", want: nil},
+		{name: "variable", query: "{ user(id: $|", want: nil},
+		{name: "inside string", query: "{ search(term: \"us|", want: nil},
+		{name: "inside comment", query: "{ # us|", want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prefix, labels := complete(t, s, tt.query)
+			assert.Equal(t, tt.prefix, prefix)
+			assert.Equal(t, tt.want, labels)
+		})
+	}
+}
+
+func TestComplete_Detail(t *testing.T) {
+	c := testSchema(t).Complete("{ users { po", 12)
+	assert.Equal(t, []Suggestion{{Label: "posts", Detail: "[Post]"}}, c.Suggestions)
+}
@@ -0,0 +1,605 @@
+package graphql
+
+import (
+	"fmt"
+	"strings"
+)
+
+// tokenKind classifies lexical tokens.
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenPunct
+	tokenName
+	tokenNumber
+	tokenString
+)
+
+// token is a lexical token of a GraphQL document. Start and end are byte
+// offsets into the source.
+type token struct {
+	kind       tokenKind
+	value      string
+	start, end int
+}
+
+// is reports whether the token is the punctuator or name v.
+func (t token) is(v string) bool {
+	return (t.kind == tokenPunct || t.kind == tokenName) && t.value == v
+}
+
+// byteOrderMark is ignored like whitespace.
+const byteOrderMark = "\uFEFF"
+
+// scanResult holds the tokens of a source and whether it ends inside a
+// comment, which matters when completing at the end of a partial query.
+type scanResult struct {
+	tokens        []token
+	endsInComment bool
+}
+
+// scan splits src into tokens, skipping whitespace, commas and comments.
+// The last token is always tokenEOF.
+func scan(src string) (*scanResult, *Error) {
+	res := &scanResult{}
+	i := 0
+	for {
+		i, res.endsInComment = skipIgnored(src, i)
+		if i >= len(src) {
+			res.tokens = append(res.tokens, token{kind: tokenEOF, start: i, end: i})
+			return res, nil
+		}
+
+		t, err := scanToken(src, i)
+		if err != nil {
+			return res, err
+		}
+		res.tokens = append(res.tokens, t)
+		i = t.end
+	}
+}
+
+// skipIgnored returns the end of the whitespace, commas and comments starting
+// at i, and whether src ends inside a comment.
+func skipIgnored(src string, i int) (int, bool) {
+	for i < len(src) {
+		c := src[i]
+		if c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == ',' {
+			i++
+			continue
+		}
+		if strings.HasPrefix(src[i:], byteOrderMark) {
+			i += len(byteOrderMark)
+			continue
+		}
+		if c != '#' {
+			break
+		}
+		end := strings.IndexAny(src[i:], "\r\n")
+		if end < 0 {
+			return len(src), true
+		}
+		i += end
+	}
+	return i, false
+}
+
+// scanToken scans the token starting at i.
+func scanToken(src string, i int) (token, *Error) {
+	start := i
+	c := src[i]
+	switch {
+	case strings.HasPrefix(src[i:], "..."):
+		return token{kind: tokenPunct, value: "...", start: start, end: i + 3}, nil
+	case strings.IndexByte("!$&():=@[]{|}", c) >= 0:
+		return token{kind: tokenPunct, value: string(c), start: start, end: i + 1}, nil
+	case c == '_' || isLetter(c):
+		for i < len(src) && (src[i] == '_' || isLetter(src[i]) || isDigit(src[i])) {
+			i++
+		}
+		return token{kind: tokenName, value: src[start:i], start: start, end: i}, nil
+	case c == '-' || isDigit(c):
+		i = scanNumber(src, i)
+		return token{kind: tokenNumber, value: src[start:i], start: start, end: i}, nil
+	case c == '"':
+		end, err := scanString(src, i)
+		if err != nil {
+			return token{}, err
+		}
+		return token{kind: tokenString, value: src[start:end], start: start, end: end}, nil
+	default:
+		return token{}, errorAt(src, start, fmt.Sprintf("unexpected character %q", rune(c)))
+	}
+}
+
+// scanNumber returns the end of the number starting at i.
+func scanNumber(src string, i int) int {
+	if src[i] == '-' {
+		i++
+	}
+	digits := func() {
+		for i < len(src) && isDigit(src[i]) {
+			i++
+		}
+	}
+	digits()
+	if i < len(src) && src[i] == '.' {
+		i++
+		digits()
+	}
+	if i < len(src) && (src[i] == 'e' || src[i] == 'E') {
+		i++
+		if i < len(src) && (src[i] == '+' || src[i] == '-') {
+			i++
+		}
+		digits()
+	}
+	return i
+}
+
+// scanString returns the end of the string or block string starting at i.
+func scanString(src string, i int) (int, *Error) {
+	start := i
+	if strings.HasPrefix(src[i:], `"""`) {
+		for i += 3; i < len(src); i++ {
+			if strings.HasPrefix(src[i:], `\"""`) {
+				i += 3
+				continue
+			}
+			if strings.HasPrefix(src[i:], `"""`) {
+				return i + 3, nil
+			}
+		}
+		return 0, errorAt(src, start, "unterminated string")
+	}
+
+	for i++; i < len(src); i++ {
+		switch src[i] {
+		case '\\':
+			i++
+		case '"':
+			return i + 1, nil
+		case '\n', '\r':
+			return 0, errorAt(src, start, "unterminated string")
+		}
+	}
+	return 0, errorAt(src, start, "unterminated string")
+}
+
+func isLetter(c byte) bool { return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' }
+
+func isDigit(c byte) bool { return c >= '0' && c <= '9' }
+
+// document is a parsed executable GraphQL document.
+type document struct {
+	operations []*operation
+	fragments  []*fragment
+}
+
+// operation is a query, mutation or subscription.
+type operation struct {
+	kind       string
+	name       string
+	start      int
+	variables  []*variable
+	selections []*selection
+}
+
+// variable is a variable definition of an operation.
+type variable struct {
+	name       string
+	typeName   string
+	typ        *TypeRef
+	hasDefault bool
+	start      int
+}
+
+// fragment is a named fragment definition.
+type fragment struct {
+	name       string
+	typeCond   string
+	start      int
+	condStart  int
+	selections []*selection
+}
+
+// selection is a field, a fragment spread or an inline fragment.
+type selection struct {
+	start int
+
+	// Fields have a name, an optional alias, arguments and sub-selections.
+	name         string
+	alias        string
+	args         []*argument
+	hasSubfields bool
+
+	// Fragment spreads name the fragment.
+	spread string
+
+	// Inline fragments have an optional type condition.
+	inline   bool
+	typeCond string
+
+	selections []*selection
+}
+
+// argument is an argument passed to a field.
+type argument struct {
+	name  string
+	start int
+}
+
+// parser builds a document from tokens.
+type parser struct {
+	src    string
+	tokens []token
+	pos    int
+}
+
+// parse parses an executable GraphQL document.
+func parse(src string) (*document, *Error) {
+	res, err := scan(src)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{src: src, tokens: res.tokens}
+
+	doc := &document{}
+	if p.peek().kind == tokenEOF {
+		return nil, errorAt(src, 0, "document has no operations")
+	}
+	for p.peek().kind != tokenEOF {
+		t := p.peek()
+		switch {
+		case t.is("{"), t.is("query"), t.is("mutation"), t.is("subscription"):
+			op, err := p.operation()
+			if err != nil {
+				return nil, err
+			}
+			doc.operations = append(doc.operations, op)
+		case t.is("fragment"):
+			frag, err := p.fragment()
+			if err != nil {
+				return nil, err
+			}
+			doc.fragments = append(doc.fragments, frag)
+		default:
+			return nil, p.unexpected(t)
+		}
+	}
+	return doc, nil
+}
+
+func (p *parser) peek() token { return p.tokens[p.pos] }
+
+func (p *parser) advance() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEOF {
+		p.pos++
+	}
+	return t
+}
+
+// expect consumes the punctuator v.
+func (p *parser) expect(v string) (token, *Error) {
+	t := p.peek()
+	if t.kind != tokenPunct || t.value != v {
+		return t, p.errorf(t, "expected %q, found %s", v, describe(t))
+	}
+	return p.advance(), nil
+}
+
+// name consumes a name.
+func (p *parser) name() (token, *Error) {
+	t := p.peek()
+	if t.kind != tokenName {
+		return t, p.errorf(t, "expected a name, found %s", describe(t))
+	}
+	return p.advance(), nil
+}
+
+func (p *parser) errorf(t token, format string, args ...any) *Error {
+	return errorAt(p.src, t.start, fmt.Sprintf(format, args...))
+}
+
+func (p *parser) unexpected(t token) *Error {
+	return p.errorf(t, "unexpected %s", describe(t))
+}
+
+// describe names a token for error messages.
+func describe(t token) string {
+	if t.kind == tokenEOF {
+		return "end of document"
+	}
+	return fmt.Sprintf("%q", t.value)
+}
+
+func (p *parser) operation() (*operation, *Error) {
+	op := &operation{kind: "query", start: p.peek().start}
+	if p.peek().is("{") {
+		sels, err := p.selectionSet()
+		op.selections = sels
+		return op, err
+	}
+
+	op.kind = p.advance().value
+	if p.peek().kind == tokenName {
+		op.name = p.advance().value
+	}
+	if p.peek().is("(") {
+		vars, err := p.variables()
+		if err != nil {
+			return nil, err
+		}
+		op.variables = vars
+	}
+	if err := p.directives(); err != nil {
+		return nil, err
+	}
+	sels, err := p.selectionSet()
+	op.selections = sels
+	return op, err
+}
+
+func (p *parser) variables() ([]*variable, *Error) {
+	p.advance() // (
+	var vars []*variable
+	for !p.peek().is(")") {
+		dollar, err := p.expect("$")
+		if err != nil {
+			return nil, err
+		}
+		name, err := p.name()
+		if err != nil {
+			return nil, err
+		}
+		if _, err := p.expect(":"); err != nil {
+			return nil, err
+		}
+		typeTok, typ, err := p.typeRef()
+		if err != nil {
+			return nil, err
+		}
+		v := &variable{name: name.value, typeName: typeTok.value, typ: typ, start: dollar.start}
+		if p.peek().is("=") {
+			p.advance()
+			if err := p.value(); err != nil {
+				return nil, err
+			}
+			v.hasDefault = true
+		}
+		if err := p.directives(); err != nil {
+			return nil, err
+		}
+		vars = append(vars, v)
+	}
+	if len(vars) == 0 {
+		return nil, p.errorf(p.peek(), "expected a variable definition")
+	}
+	p.advance() // )
+	return vars, nil
+}
+
+// typeRef parses a type reference and returns its named type's token and
+// the reference. The named type's kind is left empty: it depends on the
+// schema.
+func (p *parser) typeRef() (token, *TypeRef, *Error) {
+	var named token
+	var ref *TypeRef
+	if p.peek().is("[") {
+		p.advance()
+		inner, innerRef, err := p.typeRef()
+		if err != nil {
+			return inner, nil, err
+		}
+		if _, err := p.expect("]"); err != nil {
+			return inner, nil, err
+		}
+		named = inner
+		ref = &TypeRef{Kind: KindList, OfType: innerRef}
+	} else {
+		t, err := p.name()
+		if err != nil {
+			return t, nil, err
+		}
+		named = t
+		ref = &TypeRef{Name: t.value}
+	}
+	if p.peek().is("!") {
+		p.advance()
+		ref = &TypeRef{Kind: KindNonNull, OfType: ref}
+	}
+	return named, ref, nil
+}
+
+func (p *parser) fragment() (*fragment, *Error) {
+	start := p.advance().start // fragment
+	name, err := p.name()
+	if err != nil {
+		return nil, err
+	}
+	if name.value == "on" {
+		return nil, p.errorf(name, "expected a fragment name, found \"on\"")
+	}
+	if t := p.peek(); !t.is("on") {
+		return nil, p.errorf(t, "expected \"on\", found %s", describe(t))
+	}
+	p.advance()
+	cond, err := p.name()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.directives(); err != nil {
+		return nil, err
+	}
+	sels, err := p.selectionSet()
+	if err != nil {
+		return nil, err
+	}
+	return &fragment{name: name.value, typeCond: cond.value, start: start, condStart: cond.start, selections: sels}, nil
+}
+
+func (p *parser) selectionSet() ([]*selection, *Error) {
+	if _, err := p.expect("{"); err != nil {
+		return nil, err
+	}
+	var sels []*selection
+	for !p.peek().is("}") {
+		if p.peek().kind == tokenEOF {
+			return nil, p.errorf(p.peek(), "expected \"}\", found end of document")
+		}
+		sel, err := p.selection()
+		if err != nil {
+			return nil, err
+		}
+		sels = append(sels, sel)
+	}
+	if len(sels) == 0 {
+		return nil, p.errorf(p.peek(), "expected a selection")
+	}
+	p.advance() // }
+	return sels, nil
+}
+
+func (p *parser) selection() (*selection, *Error) {
+	t := p.peek()
+	if t.is("...") {
+		return p.fragmentSelection()
+	}
+
+	name, err := p.name()
+	if err != nil {
+		return nil, err
+	}
+	sel := &selection{start: name.start, name: name.value}
+	if p.peek().is(":") {
+		p.advance()
+		field, err := p.name()
+		if err != nil {
+			return nil, err
+		}
+		sel.alias, sel.name = name.value, field.value
+	}
+	if p.peek().is("(") {
+		if sel.args, err = p.arguments(); err != nil {
+			return nil, err
+		}
+	}
+	if err := p.directives(); err != nil {
+		return nil, err
+	}
+	if p.peek().is("{") {
+		sel.hasSubfields = true
+		if sel.selections, err = p.selectionSet(); err != nil {
+			return nil, err
+		}
+	}
+	return sel, nil
+}
+
+func (p *parser) fragmentSelection() (*selection, *Error) {
+	start := p.advance().start // ...
+	sel := &selection{start: start}
+
+	t := p.peek()
+	if t.kind == tokenName && !t.is("on") {
+		p.advance()
+		sel.spread = t.value
+		return sel, p.directives()
+	}
+
+	sel.inline = true
+	if t.is("on") {
+		p.advance()
+		cond, err := p.name()
+		if err != nil {
+			return nil, err
+		}
+		sel.typeCond = cond.value
+	}
+	if err := p.directives(); err != nil {
+		return nil, err
+	}
+	sels, err := p.selectionSet()
+	sel.selections = sels
+	return sel, err
+}
+
+func (p *parser) arguments() ([]*argument, *Error) {
+	p.advance() // (
+	var args []*argument
+	for !p.peek().is(")") {
+		name, err := p.name()
+		if err != nil {
+			return nil, err
+		}
+		if _, err := p.expect(":"); err != nil {
+			return nil, err
+		}
+		if err := p.value(); err != nil {
+			return nil, err
+		}
+		args = append(args, &argument{name: name.value, start: name.start})
+	}
+	if len(args) == 0 {
+		return nil, p.errorf(p.peek(), "expected an argument")
+	}
+	p.advance() // )
+	return args, nil
+}
+
+func (p *parser) directives() *Error {
+	for p.peek().is("@") {
+		p.advance()
+		if _, err := p.name(); err != nil {
+			return err
+		}
+		if p.peek().is("(") {
+			if _, err := p.arguments(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// value parses and discards a value.
+func (p *parser) value() *Error {
+	t := p.peek()
+	switch {
+	case t.is("$"):
+		p.advance()
+		_, err := p.name()
+		return err
+	case t.kind == tokenNumber || t.kind == tokenString || t.kind == tokenName:
+		p.advance()
+		return nil
+	case t.is("["):
+		p.advance()
+		for !p.peek().is("]") {
+			if err := p.value(); err != nil {
+				return err
+			}
+		}
+		p.advance()
+		return nil
+	case t.is("{"):
+		p.advance()
+		for !p.peek().is("}") {
+			if _, err := p.name(); err != nil {
+				return err
+			}
+			if _, err := p.expect(":"); err != nil {
+				return err
+			}
+			if err := p.value(); err != nil {
+				return err
+			}
+		}
+		p.advance()
+		return nil
+	default:
+		return p.errorf(t, "expected a value, found %s", describe(t))
+	}
+}
@@ -0,0 +1,251 @@
+package graphql
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// Operation is a query, mutation or subscription defined by a document.
+type Operation struct {
+	// Kind is "query", "mutation" or "subscription".
+	Kind string
+
+	// Name is empty for anonymous operations.
+	Name string
+
+	Variables []Variable
+}
+
+// Variable is a variable an operation declares.
+type Variable struct {
+	Name string
+
+	// Type is the declared type. Its named type's kind is empty, since the
+	// document alone does not say what kind of type it is.
+	Type *TypeRef
+
+	HasDefault bool
+}
+
+// Required reports whether a value must be given for the variable: its type
+// is non-null and it has no default.
+func (v Variable) Required() bool {
+	return v.Type != nil && v.Type.Kind == KindNonNull && !v.HasDefault
+}
+
+// Operations returns the operations a query defines, in order, or nil if it
+// does not parse.
+func Operations(query string) []Operation {
+	doc, err := parse(query)
+	if err != nil {
+		return nil
+	}
+	ops := make([]Operation, 0, len(doc.operations))
+	for _, op := range doc.operations {
+		operation := Operation{Kind: op.kind, Name: op.name}
+		for _, v := range op.variables {
+			operation.Variables = append(operation.Variables, Variable{Name: v.name, Type: v.typ, HasDefault: v.hasDefault})
+		}
+		ops = append(ops, operation)
+	}
+	return ops
+}
+
+// CheckVariables checks a JSON object of variables against those the
+// operation declares: required variables must be given and not null, and
+// undeclared variables are reported. When schema is not nil, the values are
+// also checked against their types. Problems are described in the order the
+// variables are declared. Variables that are not a JSON object are left to
+// BuildBody to report.
+func (op Operation) CheckVariables(schema *Schema, variables string) []string {
+	values := map[string]any{}
+	if trimmed := strings.TrimSpace(variables); trimmed != "" {
+		decoder := json.NewDecoder(bytes.NewReader([]byte(trimmed)))
+		decoder.UseNumber()
+		if err := decoder.Decode(&values); err != nil {
+			return nil
+		}
+	}
+
+	c := &variableChecker{schema: schema}
+	declared := map[string]bool{}
+	for _, v := range op.Variables {
+		declared[v.Name] = true
+		value, ok := values[v.Name]
+		if !ok {
+			if v.Required() {
+				c.errorf("$"+v.Name, "required variable of type %s is missing", v.Type)
+			}
+			continue
+		}
+		if value == nil && v.HasDefault {
+			continue
+		}
+		c.check("$"+v.Name, v.Type, value)
+	}
+
+	var undeclared []string
+	for name := range values {
+		if !declared[name] {
+			undeclared = append(undeclared, name)
+		}
+	}
+	sort.Strings(undeclared)
+	for _, name := range undeclared {
+		c.errorf("$"+name, "not declared by the operation")
+	}
+	return c.problems
+}
+
+// variableChecker accumulates the problems found in variable values.
+type variableChecker struct {
+	schema   *Schema
+	problems []string
+}
+
+func (c *variableChecker) errorf(path, format string, args ...any) {
+	c.problems = append(c.problems, path+": "+fmt.Sprintf(format, args...))
+}
+
+// check checks value, at path, against ref. Without a schema, only nulls
+// given for non-null types are reported.
+func (c *variableChecker) check(path string, ref *TypeRef, value any) {
+	if ref.Kind == KindNonNull {
+		if value == nil {
+			c.errorf(path, "must not be null")
+			return
+		}
+		c.check(path, ref.OfType, value)
+		return
+	}
+	if value == nil || c.schema == nil {
+		return
+	}
+
+	if ref.Kind == KindList {
+		items, ok := value.([]any)
+		if !ok {
+			// A single value stands for a list of one.
+			c.check(path, ref.OfType, value)
+			return
+		}
+		for i, item := range items {
+			c.check(fmt.Sprintf("%s[%d]", path, i), ref.OfType, item)
+		}
+		return
+	}
+
+	c.checkNamed(path, ref.Name, value)
+}
+
+// checkNamed checks a non-null value, at path, against the named type.
+// Values of types the schema does not define are not checked.
+func (c *variableChecker) checkNamed(path, typeName string, value any) {
+	t := c.schema.Type(typeName)
+	if t == nil {
+		return
+	}
+	switch t.Kind {
+	case KindScalar:
+		if !scalarAccepts(t.Name, value) {
+			c.errorf(path, "expected %s, found %s", t.Name, describeValue(value))
+		}
+	case KindEnum:
+		name, ok := value.(string)
+		if !ok || !hasEnumValue(t, name) {
+			c.errorf(path, "expected a value of enum %s, found %s", t.Name, describeValue(value))
+		}
+	case KindInputObject:
+		object, ok := value.(map[string]any)
+		if !ok {
+			c.errorf(path, "expected an object of type %s, found %s", t.Name, describeValue(value))
+			return
+		}
+		c.inputObject(path, t, object)
+	}
+}
+
+// inputObject checks the fields of an input object value.
+func (c *variableChecker) inputObject(path string, t *Type, object map[string]any) {
+	known := map[string]bool{}
+	for _, field := range t.InputFields {
+		known[field.Name] = true
+		value, ok := object[field.Name]
+		if !ok {
+			if field.Required() {
+				c.errorf(path+"."+field.Name, "required field of type %s is missing", field.Type)
+			}
+			continue
+		}
+		c.check(path+"."+field.Name, field.Type, value)
+	}
+
+	var unknown []string
+	for name := range object {
+		if !known[name] {
+			unknown = append(unknown, name)
+		}
+	}
+	sort.Strings(unknown)
+	for _, name := range unknown {
+		c.errorf(path+"."+name, "unknown field of type %s", t.Name)
+	}
+}
+
+// scalarAccepts reports whether value is valid input for a built-in scalar.
+// Custom scalars accept anything.
+func scalarAccepts(scalar string, value any) bool {
+	switch scalar {
+	case "Int":
+		n, ok := value.(json.Number)
+		if !ok {
+			return false
+		}
+		i, err := n.Int64()
+		return err == nil && i >= math.MinInt32 && i <= math.MaxInt32
+	case "Float":
+		_, ok := value.(json.Number)
+		return ok
+	case "String":
+		_, ok := value.(string)
+		return ok
+	case "Boolean":
+		_, ok := value.(bool)
+		return ok
+	case "ID":
+		if n, ok := value.(json.Number); ok {
+			_, err := n.Int64()
+			return err == nil
+		}
+		_, ok := value.(string)
+		return ok
+	}
+	return true
+}
+
+func hasEnumValue(t *Type, name string) bool {
+	for _, value := range t.EnumValues {
+		if value.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// describeValue names a JSON value for messages.
+func describeValue(value any) string {
+	switch v := value.(type) {
+	case map[string]any:
+		return "an object"
+	case []any:
+		return "a list"
+	case string:
+		return fmt.Sprintf("%q", v)
+	default:
+		return fmt.Sprint(v)
+	}
+}
@@ -0,0 +1,85 @@
+package graphql
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOperations(t *testing.T) {
+	ops := Operations(`
+query GetUser($id: ID!, $first: Int = 10, $roles: [Role!]) { user(id: $id) { name } }
+mutation Create($input: UserInput!) { createUser(input: $input) { id } }
+{ users { id } }
+fragment F on User { id }`)
+	require.Len(t, ops, 3)
+
+	assert.Equal(t, "query", ops[0].Kind)
+	assert.Equal(t, "GetUser", ops[0].Name)
+	require.Len(t, ops[0].Variables, 3)
+	assert.Equal(t, "ID!", ops[0].Variables[0].Type.String())
+	assert.True(t, ops[0].Variables[0].Required())
+	assert.True(t, ops[0].Variables[1].HasDefault)
+	assert.False(t, ops[0].Variables[1].Required())
+	assert.Equal(t, "[Role!]", ops[0].Variables[2].Type.String())
+	assert.False(t, ops[0].Variables[2].Required())
+
+	assert.Equal(t, "mutation", ops[1].Kind)
+	assert.Equal(t, "Create", ops[1].Name)
+	assert.Equal(t, "query", ops[2].Kind)
+	assert.Empty(t, ops[2].Name)
+	assert.Empty(t, ops[2].Variables)
+
+	assert.Nil(t, Operations("query {"))
+}
+
+func TestCheckVariables(t *testing.T) {
+	schema := testSchema(t)
+	ops := Operations(`
+query GetUser($id: ID!, $first: Int = 10, $roles: [Role!]) { user(id: $id) { name } }
+mutation Create($input: UserInput!) { createUser(input: $input) { id } }`)
+	require.Len(t, ops, 2)
+	getUser, create := ops[0], ops[1]
+
+	tests := []struct {
+		name      string
+		op        Operation
+		schema    *Schema
+		variables string
+		want      []string
+	}{
+		{"valid", getUser, schema, `{"id": "1", "first": 5, "roles": ["ADMIN"]}`, nil},
+		{"integer ID", getUser, schema, `{"id": 1}`, nil},
+		{"single value for a list", getUser, schema, `{"id": "1", "roles": "EDITOR"}`, nil},
+		{"null for a default", getUser, schema, `{"id": "1", "first": null}`, nil},
+		{"missing", getUser, nil, ``, []string{"$id: required variable of type ID! is missing"}},
+		{"null", getUser, nil, `{"id": null}`, []string{"$id: must not be null"}},
+		{"undeclared", getUser, nil, `{"id": 1, "b": 2, "a": 1}`, []string{
+			"$a: not declared by the operation",
+			"$b: not declared by the operation",
+		}},
+		{"types not checked without schema", getUser, nil, `{"id": true, "first": "x"}`, nil},
+		{"scalars", getUser, schema, `{"id": true, "first": 1.5}`, []string{
+			"$id: expected ID, found true",
+			"$first: expected Int, found 1.5",
+		}},
+		{"list items", getUser, schema, `{"id": "1", "roles": ["ADMIN", null, "OWNER"]}`, []string{
+			"$roles[1]: must not be null",
+			`$roles[2]: expected a value of enum Role, found "OWNER"`,
+		}},
+		{"input object", create, schema, `{"input": {"role": "ADMIN", "tags": [1], "age": 3}}`, []string{
+			"$input.name: required field of type String! is missing",
+			"$input.tags[0]: expected a value of enum Role, found 1",
+			"$input.age: unknown field of type UserInput",
+		}},
+		{"not an object", create, schema, `{"input": [1]}`, []string{"$input: expected an object of type UserInput, found a list"}},
+		{"invalid JSON is left to BuildBody", getUser, schema, `{`, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.op.CheckVariables(tt.schema, tt.variables))
+		})
+	}
+}
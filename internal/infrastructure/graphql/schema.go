@@ -0,0 +1,328 @@
+// Package graphql reads GraphQL schemas from introspection results and checks
+// and completes queries against them.
+package graphql
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Type kinds, as reported by introspection.
+const (
+	KindScalar      = "SCALAR"
+	KindObject      = "OBJECT"
+	KindInterface   = "INTERFACE"
+	KindUnion       = "UNION"
+	KindEnum        = "ENUM"
+	KindInputObject = "INPUT_OBJECT"
+	KindList        = "LIST"
+	KindNonNull     = "NON_NULL"
+)
+
+// IntrospectionQuery is the standard query that asks a server for its schema.
+const IntrospectionQuery = `query IntrospectionQuery {
+  __schema {
+    queryType { name }
+    mutationType { name }
+    subscriptionType { name }
+    types { ...FullType }
+    directives { name description args { ...InputValue } }
+  }
+}
+
+fragment FullType on __Type {
+  kind
+  name
+  description
+  fields(includeDeprecated: true) {
+    name
+    description
+    args { ...InputValue }
+    type { ...TypeRef }
+    isDeprecated
+  }
+  inputFields { ...InputValue }
+  interfaces { ...TypeRef }
+  enumValues(includeDeprecated: true) { name description isDeprecated }
+  possibleTypes { ...TypeRef }
+}
+
+fragment InputValue on __InputValue {
+  name
+  description
+  type { ...TypeRef }
+  defaultValue
+}
+
+fragment TypeRef on __Type {
+  kind
+  name
+  ofType { kind name ofType { kind name ofType { kind name ofType { kind name
+    ofType { kind name ofType { kind name ofType { kind name } } } } } } }
+}
+`
+
+// Schema is a GraphQL schema as described by introspection.
+type Schema struct {
+	QueryType        *NamedRef    `json:"queryType"`
+	MutationType     *NamedRef    `json:"mutationType"`
+	SubscriptionType *NamedRef    `json:"subscriptionType"`
+	Types            []*Type      `json:"types"`
+	Directives       []*Directive `json:"directives"`
+
+	byName map[string]*Type
+}
+
+// NamedRef names a root operation type.
+type NamedRef struct {
+	Name string `json:"name"`
+}
+
+// Type is a named type in the schema.
+type Type struct {
+	Kind          string        `json:"kind"`
+	Name          string        `json:"name"`
+	Description   string        `json:"description,omitempty"`
+	Fields        []*Field      `json:"fields,omitempty"`
+	InputFields   []*InputValue `json:"inputFields,omitempty"`
+	Interfaces    []*TypeRef    `json:"interfaces,omitempty"`
+	EnumValues    []*EnumValue  `json:"enumValues,omitempty"`
+	PossibleTypes []*TypeRef    `json:"possibleTypes,omitempty"`
+}
+
+// Field is a field of an object or interface type.
+type Field struct {
+	Name         string        `json:"name"`
+	Description  string        `json:"description,omitempty"`
+	Args         []*InputValue `json:"args,omitempty"`
+	Type         *TypeRef      `json:"type"`
+	IsDeprecated bool          `json:"isDeprecated,omitempty"`
+}
+
+// InputValue is an argument or a field of an input object type.
+type InputValue struct {
+	Name         string   `json:"name"`
+	Description  string   `json:"description,omitempty"`
+	Type         *TypeRef `json:"type"`
+	DefaultValue *string  `json:"defaultValue,omitempty"`
+}
+
+// EnumValue is a value of an enum type.
+type EnumValue struct {
+	Name         string `json:"name"`
+	Description  string `json:"description,omitempty"`
+	IsDeprecated bool   `json:"isDeprecated,omitempty"`
+}
+
+// Directive is a directive the schema supports, such as @include.
+type Directive struct {
+	Name        string        `json:"name"`
+	Description string        `json:"description,omitempty"`
+	Args        []*InputValue `json:"args,omitempty"`
+}
+
+// TypeRef refers to a type, possibly wrapped in lists and non-null markers.
+type TypeRef struct {
+	Kind   string   `json:"kind"`
+	Name   string   `json:"name,omitempty"`
+	OfType *TypeRef `json:"ofType,omitempty"`
+}
+
+// NamedType returns the name of the type inside any wrappers.
+func (r *TypeRef) NamedType() string {
+	for r != nil && r.Name == "" {
+		r = r.OfType
+	}
+	if r == nil {
+		return ""
+	}
+	return r.Name
+}
+
+// String formats the reference as in GraphQL, for example [User!]!.
+func (r *TypeRef) String() string {
+	switch {
+	case r == nil:
+		return ""
+	case r.Kind == KindNonNull:
+		return r.OfType.String() + "!"
+	case r.Kind == KindList:
+		return "[" + r.OfType.String() + "]"
+	default:
+		return r.Name
+	}
+}
+
+// Required reports whether an input value must be given: its type is non-null
+// and it has no default.
+func (v *InputValue) Required() bool {
+	return v.Type != nil && v.Type.Kind == KindNonNull && v.DefaultValue == nil
+}
+
+// introspectionResponse is the body a server returns for IntrospectionQuery.
+type introspectionResponse struct {
+	Data *struct {
+		Schema *Schema `json:"__schema"`
+	} `json:"data"`
+	Errors []struct {
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+// ParseIntrospection reads the schema from the response to IntrospectionQuery.
+func ParseIntrospection(data []byte) (*Schema, error) {
+	var resp introspectionResponse
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return nil, fmt.Errorf("failed to parse introspection response: %w", err)
+	}
+	if resp.Data == nil || resp.Data.Schema == nil {
+		if len(resp.Errors) > 0 {
+			messages := make([]string, len(resp.Errors))
+			for i, e := range resp.Errors {
+				messages[i] = e.Message
+			}
+			return nil, fmt.Errorf("introspection failed: %s", strings.Join(messages, "; "))
+		}
+		return nil, fmt.Errorf("introspection response has no schema")
+	}
+
+	schema := resp.Data.Schema
+	if schema.QueryType == nil || schema.QueryType.Name == "" {
+		return nil, fmt.Errorf("introspection response has no query type")
+	}
+	schema.index()
+	if schema.Type(schema.QueryType.Name) == nil {
+		return nil, fmt.Errorf("query type %q is not defined", schema.QueryType.Name)
+	}
+	return schema, nil
+}
+
+// MarshalSchema encodes a schema for caching.
+func MarshalSchema(s *Schema) ([]byte, error) {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal schema: %w", err)
+	}
+	return data, nil
+}
+
+// UnmarshalSchema decodes a schema written by MarshalSchema.
+func UnmarshalSchema(data []byte) (*Schema, error) {
+	var s Schema
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal schema: %w", err)
+	}
+	s.index()
+	return &s, nil
+}
+
+// index builds the lookup table of types by name.
+func (s *Schema) index() {
+	s.byName = make(map[string]*Type, len(s.Types))
+	for _, t := range s.Types {
+		if t != nil {
+			s.byName[t.Name] = t
+		}
+	}
+}
+
+// Type returns the named type, or nil if the schema does not define it.
+func (s *Schema) Type(name string) *Type {
+	return s.byName[name]
+}
+
+// RootType returns the root type of an operation ("query", "mutation" or
+// "subscription"), or nil if the schema does not support the operation.
+func (s *Schema) RootType(operation string) *Type {
+	var ref *NamedRef
+	switch operation {
+	case "query":
+		ref = s.QueryType
+	case "mutation":
+		ref = s.MutationType
+	case "subscription":
+		ref = s.SubscriptionType
+	}
+	if ref == nil {
+		return nil
+	}
+	return s.Type(ref.Name)
+}
+
+// Field returns the field of t with the given name, including the
+// __typename meta-field and, on the query type, __schema and __type.
+// It returns nil if t has no such field.
+func (s *Schema) Field(t *Type, name string) *Field {
+	if t == nil {
+		return nil
+	}
+	for _, f := range t.Fields {
+		if f.Name == name {
+			return f
+		}
+	}
+	for _, f := range s.metaFields(t) {
+		if f.Name == name {
+			return f
+		}
+	}
+	return nil
+}
+
+// metaFields returns the introspection fields available on t.
+func (s *Schema) metaFields(t *Type) []*Field {
+	if !t.Composite() {
+		return nil
+	}
+	nonNull := func(kind, name string) *TypeRef {
+		return &TypeRef{Kind: KindNonNull, OfType: &TypeRef{Kind: kind, Name: name}}
+	}
+	fields := []*Field{{Name: "__typename", Type: nonNull(KindScalar, "String")}}
+	if s.QueryType != nil && t.Name == s.QueryType.Name {
+		fields = append(fields,
+			&Field{Name: "__schema", Type: nonNull(KindObject, "__Schema")},
+			&Field{Name: "__type", Type: &TypeRef{Kind: KindObject, Name: "__Type"}, Args: []*InputValue{
+				{Name: "name", Type: nonNull(KindScalar, "String")},
+			}},
+		)
+	}
+	return fields
+}
+
+// Composite reports whether selections can be made on t: it is an object,
+// interface or union.
+func (t *Type) Composite() bool {
+	return t.Kind == KindObject || t.Kind == KindInterface || t.Kind == KindUnion
+}
+
+// QueryFromBody extracts the GraphQL query from a request body: the "query"
+// member of a JSON body, or the body itself when it is a raw query, as sent
+// with Content-Type application/graphql. Raw reports the latter, in which
+// case offsets in the query are offsets in the body. It returns false for
+// bodies that are not GraphQL.
+func QueryFromBody(body string) (query string, raw bool, ok bool) {
+	trimmed := strings.TrimSpace(body)
+	if trimmed == "" {
+		return "", false, false
+	}
+	if json.Valid([]byte(trimmed)) {
+		var request struct {
+			Query *string `json:"query"`
+		}
+		if json.Unmarshal([]byte(trimmed), &request) != nil || request.Query == nil {
+			return "", false, false
+		}
+		return *request.Query, false, true
+	}
+
+	res, err := scan(trimmed)
+	if err != nil || len(res.tokens) == 0 {
+		return "", false, false
+	}
+	switch first := res.tokens[0]; {
+	case first.is("{"), first.is("query"), first.is("mutation"), first.is("subscription"), first.is("fragment"):
+		return body, true, true
+	}
+	return "", false, false
+}
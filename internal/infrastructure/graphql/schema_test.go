@@ -0,0 +1,169 @@
+package graphql
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// introspection is a trimmed introspection response for a small blog API.
+const introspection = `{"data": {"__schema": {
+  "queryType": {"name": "Query"},
+  "mutationType": {"name": "Mutation"},
+  "subscriptionType": null,
+  "directives": [
+    {"name": "include", "args": [{"name": "if", "type": {"kind": "NON_NULL", "ofType": {"kind": "SCALAR", "name": "Boolean"}}}]},
+    {"name": "skip", "args": [{"name": "if", "type": {"kind": "NON_NULL", "ofType": {"kind": "SCALAR", "name": "Boolean"}}}]}
+  ],
+  "types": [
+    {"kind": "OBJECT", "name": "Query", "fields": [
+      {"name": "user", "type": {"kind": "OBJECT", "name": "User"}, "args": [
+        {"name": "id", "type": {"kind": "NON_NULL", "ofType": {"kind": "SCALAR", "name": "ID"}}}
+      ]},
+      {"name": "users", "type": {"kind": "NON_NULL", "ofType": {"kind": "LIST", "ofType": {"kind": "NON_NULL", "ofType": {"kind": "OBJECT", "name": "User"}}}}, "args": [
+        {"name": "role", "type": {"kind": "ENUM", "name": "Role"}},
+        {"name": "first", "type": {"kind": "SCALAR", "name": "Int"}, "defaultValue": "10"}
+      ]},
+      {"name": "search", "type": {"kind": "LIST", "ofType": {"kind": "UNION", "name": "SearchResult"}}, "args": [
+        {"name": "term", "type": {"kind": "NON_NULL", "ofType": {"kind": "SCALAR", "name": "String"}}}
+      ]},
+      {"name": "legacyUsers", "isDeprecated": true, "type": {"kind": "LIST", "ofType": {"kind": "OBJECT", "name": "User"}}}
+    ]},
+    {"kind": "OBJECT", "name": "Mutation", "fields": [
+      {"name": "createUser", "type": {"kind": "OBJECT", "name": "User"}, "args": [
+        {"name": "input", "type": {"kind": "NON_NULL", "ofType": {"kind": "INPUT_OBJECT", "name": "UserInput"}}}
+      ]}
+    ]},
+    {"kind": "INTERFACE", "name": "Node", "fields": [
+      {"name": "id", "type": {"kind": "NON_NULL", "ofType": {"kind": "SCALAR", "name": "ID"}}}
+    ]},
+    {"kind": "OBJECT", "name": "User", "interfaces": [{"kind": "INTERFACE", "name": "Node"}], "fields": [
+      {"name": "id", "type": {"kind": "NON_NULL", "ofType": {"kind": "SCALAR", "name": "ID"}}},
+      {"name": "name", "type": {"kind": "SCALAR", "name": "String"}},
+      {"name": "role", "type": {"kind": "ENUM", "name": "Role"}},
+      {"name": "posts", "type": {"kind": "LIST", "ofType": {"kind": "OBJECT", "name": "Post"}}}
+    ]},
+    {"kind": "OBJECT", "name": "Post", "fields": [
+      {"name": "title", "type": {"kind": "SCALAR", "name": "String"}},
+      {"name": "author", "type": {"kind": "OBJECT", "name": "User"}}
+    ]},
+    {"kind": "UNION", "name": "SearchResult", "possibleTypes": [{"kind": "OBJECT", "name": "User"}, {"kind": "OBJECT", "name": "Post"}]},
+    {"kind": "INPUT_OBJECT", "name": "UserInput", "inputFields": [
+      {"name": "name", "type": {"kind": "NON_NULL", "ofType": {"kind": "SCALAR", "name": "String"}}},
+      {"name": "role", "type": {"kind": "ENUM", "name": "Role"}},
+      {"name": "tags", "type": {"kind": "LIST", "ofType": {"kind": "ENUM", "name": "Role"}}}
+    ]},
+    {"kind": "ENUM", "name": "Role", "enumValues": [{"name": "ADMIN"}, {"name": "EDITOR"}, {"name": "GUEST", "isDeprecated": true}]},
+    {"kind": "SCALAR", "name": "ID"},
+    {"kind": "SCALAR", "name": "String"},
+    {"kind": "SCALAR", "name": "Int"},
+    {"kind": "SCALAR", "name": "Boolean"},
+    {"kind": "OBJECT", "name": "__Schema", "fields": [
+      {"name": "types", "type": {"kind": "LIST", "ofType": {"kind": "OBJECT", "name": "__Type"}}}
+    ]},
+    {"kind": "OBJECT", "name": "__Type", "fields": [
+      {"name": "name", "type": {"kind": "SCALAR", "name": "String"}}
+    ]}
+  ]
+}}}`
+
+func testSchema(t *testing.T) *Schema {
+	t.Helper()
+	s, err := ParseIntrospection([]byte(introspection))
+	require.NoError(t, err)
+	return s
+}
+
+func TestParseIntrospection(t *testing.T) {
+	s := testSchema(t)
+
+	assert.Equal(t, "Query", s.RootType("query").Name)
+	assert.Equal(t, "Mutation", s.RootType("mutation").Name)
+	assert.Nil(t, s.RootType("subscription"))
+
+	users := s.Field(s.Type("Query"), "users")
+	require.NotNil(t, users)
+	assert.Equal(t, "[User!]!", users.Type.String())
+	assert.Equal(t, "User", users.Type.NamedType())
+	assert.False(t, users.Args[1].Required())
+	assert.True(t, s.Field(s.Type("Query"), "user").Args[0].Required())
+
+	assert.NotNil(t, s.Field(s.Type("Post"), "__typename"))
+	assert.NotNil(t, s.Field(s.Type("Query"), "__schema"))
+	assert.Nil(t, s.Field(s.Type("Post"), "__schema"))
+	assert.Nil(t, s.Field(s.Type("Post"), "missing"))
+}
+
+func TestParseIntrospection_Errors(t *testing.T) {
+	tests := map[string]struct {
+		body string
+		want string
+	}{
+		"not json":     {body: `<html>`, want: "failed to parse introspection response"},
+		"errors":       {body: `{"errors": [{"message": "introspection is disabled"}]}`, want: "introspection failed: introspection is disabled"},
+		"no schema":    {body: `{"data": {}}`, want: "introspection response has no schema"},
+		"no query":     {body: `{"data": {"__schema": {"types": []}}}`, want: "introspection response has no query type"},
+		"missing type": {body: `{"data": {"__schema": {"queryType": {"name": "Query"}, "types": []}}}`, want: `query type "Query" is not defined`},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := ParseIntrospection([]byte(tt.body))
+			assert.ErrorContains(t, err, tt.want)
+		})
+	}
+}
+
+func TestMarshalSchema_RoundTrip(t *testing.T) {
+	s := testSchema(t)
+
+	data, err := MarshalSchema(s)
+	require.NoError(t, err)
+	got, err := UnmarshalSchema(data)
+	require.NoError(t, err)
+
+	assert.Equal(t, s.Types, got.Types)
+	assert.NotNil(t, got.Type("UserInput"))
+
+	_, err = UnmarshalSchema([]byte("{"))
+	assert.ErrorContains(t, err, "failed to unmarshal schema")
+}
+
+func TestQueryFromBody(t *testing.T) {
+	tests := []struct {
+		body  string
+		query string
+		raw   bool
+		ok    bool
+	}{
+		{body: `{"query": "{ users { id } }", "variables": {}}`, query: "{ users { id } }", ok: true},
+		{body: "\n{ users { id } }", query: "\n{ users { id } }", raw: true, ok: true},
+		{body: "query Q { users {", query: "query Q { users {", raw: true, ok: true},
+		{body: `{"name": "Ada"}`},
+		{body: `{"query": 5}`},
+		{body: `name=Ada`},
+		{body: `  `},
+	}
+	for _, tt := range tests {
+		query, raw, ok := QueryFromBody(tt.body)
+		assert.Equal(t, tt.query, query, tt.body)
+		assert.Equal(t, tt.raw, raw, tt.body)
+		assert.Equal(t, tt.ok, ok, tt.body)
+	}
+}
+
+func TestCache(t *testing.T) {
+	cache := NewCache(t.TempDir())
+	const endpoint = "https://api.example.com/graphql"
+
+	_, err := cache.Load(endpoint)
+	assert.ErrorIs(t, err, ErrNotCached)
+
+	require.NoError(t, cache.Save(endpoint, testSchema(t)))
+	got, err := cache.Load(endpoint)
+	require.NoError(t, err)
+	assert.Equal(t, "Query", got.RootType("query").Name)
+
+	_, err = cache.Load("https://other.example.com/graphql")
+	assert.ErrorIs(t, err, ErrNotCached)
+}
@@ -0,0 +1,159 @@
+package graphql
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Error is a problem found in a query, located by 1-based line and column.
+type Error struct {
+	Line    int
+	Column  int
+	Message string
+
+	offset int
+}
+
+// String formats the error as "line:column: message".
+func (e Error) String() string {
+	return fmt.Sprintf("%d:%d: %s", e.Line, e.Column, e.Message)
+}
+
+// errorAt creates an error located at a byte offset of src.
+func errorAt(src string, offset int, message string) *Error {
+	before := src[:offset]
+	line := strings.Count(before, "\n") + 1
+	column := len([]rune(before[strings.LastIndex(before, "\n")+1:])) + 1
+	return &Error{Line: line, Column: column, Message: message, offset: offset}
+}
+
+// Validate checks a query against the schema. It reports syntax errors and
+// selections the schema cannot answer: unknown types, fields, arguments and
+// fragments, missing required arguments, and leaf or composite fields with
+// the wrong kind of selection. Errors are ordered by position.
+func (s *Schema) Validate(query string) []Error {
+	doc, err := parse(query)
+	if err != nil {
+		return []Error{*err}
+	}
+
+	v := &validator{schema: s, src: query, fragments: map[string]*fragment{}}
+	for _, frag := range doc.fragments {
+		if _, ok := v.fragments[frag.name]; ok {
+			v.errorf(frag.start, "fragment %q is defined more than once", frag.name)
+		}
+		v.fragments[frag.name] = frag
+	}
+
+	for _, op := range doc.operations {
+		for _, variable := range op.variables {
+			t := s.Type(variable.typeName)
+			switch {
+			case t == nil:
+				v.errorf(variable.start, "unknown type %q", variable.typeName)
+			case t.Kind != KindScalar && t.Kind != KindEnum && t.Kind != KindInputObject:
+				v.errorf(variable.start, "variable $%s cannot be of output type %q", variable.name, t.Name)
+			}
+		}
+
+		root := s.RootType(op.kind)
+		if root == nil {
+			v.errorf(op.start, "schema does not support %s operations", op.kind)
+			continue
+		}
+		v.selections(root, op.selections)
+	}
+
+	for _, frag := range doc.fragments {
+		t := s.Type(frag.typeCond)
+		if t == nil || !t.Composite() {
+			v.errorf(frag.condStart, "unknown type %q", frag.typeCond)
+			continue
+		}
+		v.selections(t, frag.selections)
+	}
+
+	sort.SliceStable(v.errors, func(i, j int) bool { return v.errors[i].offset < v.errors[j].offset })
+	return v.errors
+}
+
+// validator accumulates the errors found while walking a document.
+type validator struct {
+	schema    *Schema
+	src       string
+	fragments map[string]*fragment
+	errors    []Error
+}
+
+func (v *validator) errorf(offset int, format string, args ...any) {
+	v.errors = append(v.errors, *errorAt(v.src, offset, fmt.Sprintf(format, args...)))
+}
+
+// selections checks selections made on parent.
+func (v *validator) selections(parent *Type, sels []*selection) {
+	for _, sel := range sels {
+		switch {
+		case sel.spread != "":
+			if _, ok := v.fragments[sel.spread]; !ok {
+				v.errorf(sel.start, "unknown fragment %q", sel.spread)
+			}
+		case sel.inline:
+			t := parent
+			if sel.typeCond != "" {
+				if t = v.schema.Type(sel.typeCond); t == nil || !t.Composite() {
+					v.errorf(sel.start, "unknown type %q", sel.typeCond)
+					continue
+				}
+			}
+			v.selections(t, sel.selections)
+		default:
+			v.field(parent, sel)
+		}
+	}
+}
+
+// field checks a field selection, its arguments and its sub-selections.
+func (v *validator) field(parent *Type, sel *selection) {
+	field := v.schema.Field(parent, sel.name)
+	if field == nil {
+		v.errorf(sel.start, "cannot query field %q on type %q", sel.name, parent.Name)
+		return
+	}
+
+	given := map[string]bool{}
+	for _, arg := range sel.args {
+		given[arg.name] = true
+		if !hasInput(field.Args, arg.name) {
+			v.errorf(arg.start, "unknown argument %q on field \"%s.%s\"", arg.name, parent.Name, field.Name)
+		}
+	}
+	for _, arg := range field.Args {
+		if arg.Required() && !given[arg.Name] {
+			v.errorf(sel.start, "missing required argument %q on field \"%s.%s\"", arg.Name, parent.Name, field.Name)
+		}
+	}
+
+	t := v.schema.Type(field.Type.NamedType())
+	if t == nil {
+		return
+	}
+	switch {
+	case t.Composite() && !sel.hasSubfields:
+		v.errorf(sel.start, "field %q of type %q must have a selection of subfields", sel.name, field.Type)
+	case !t.Composite() && sel.hasSubfields:
+		v.errorf(sel.start, "field %q of type %q cannot have a selection of subfields", sel.name, field.Type)
+	case t.Composite():
+		v.selections(t, sel.selections)
+	}
+}
+
+// hasInput reports whether inputs contains one named name.
+func hasInput(inputs []*InputValue, name string) bool {
+	for _, in := range inputs {
+		if in.Name == name {
+			return true
+		}
+	}
+	return false
+}
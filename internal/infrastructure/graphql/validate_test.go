@@ -0,0 +1,105 @@
+package graphql
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func messages(errs []Error) []string {
+	out := make([]string, len(errs))
+	for i, e := range errs {
+		out[i] = e.String()
+	}
+	return out
+}
+
+func TestValidate_Valid(t *testing.T) {
+	s := testSchema(t)
+
+	queries := []string{
+		`{ users { id name } }`,
+		`query Users($role: Role, $first: Int = 5) {
+			admins: users(role: $role, first: $first) @include(if: true) { ...UserFields __typename }
+			search(term: "ada") { ... on User { name } ... on Post { title author { id } } }
+			__schema { types { name } }
+		}
+		fragment UserFields on User { id posts { title } }`,
+		`mutation { createUser(input: {name: "Ada", role: ADMIN, tags: [EDITOR]}) { id } }`,
+		"# A comment\n{ user(id: 1) { ... { name } } }",
+		`{ user(id: "1") { name } search(term: """block "string" """) { __typename } }`,
+	}
+	for _, q := range queries {
+		assert.Empty(t, messages(s.Validate(q)), q)
+	}
+}
+
+func TestValidate_Errors(t *testing.T) {
+	s := testSchema(t)
+
+	tests := []struct {
+		name  string
+		query string
+		want  []string
+	}{
+		{
+			name:  "unknown field",
+			query: "{\n  users { id email }\n}",
+			want:  []string{`2:14: cannot query field "email" on type "User"`},
+		},
+		{
+			name:  "arguments",
+			query: `{ user { name } users(limit: 5) { id } }`,
+			want: []string{
+				`1:3: missing required argument "id" on field "Query.user"`,
+				`1:23: unknown argument "limit" on field "Query.users"`,
+			},
+		},
+		{
+			name:  "subfields",
+			query: `{ users user(id: 1) { name { first } } }`,
+			want: []string{
+				`1:3: field "users" of type "[User!]!" must have a selection of subfields`,
+				`1:23: field "name" of type "String" cannot have a selection of subfields`,
+			},
+		},
+		{
+			name:  "fragments",
+			query: `{ users { ...Missing ... on Nope { id } } } fragment F on Unknown { id }`,
+			want: []string{
+				`1:11: unknown fragment "Missing"`,
+				`1:22: unknown type "Nope"`,
+				`1:59: unknown type "Unknown"`,
+			},
+		},
+		{
+			name:  "union fields",
+			query: `{ search(term: "a") { name } }`,
+			want:  []string{`1:23: cannot query field "name" on type "SearchResult"`},
+		},
+		{
+			name:  "unsupported operation",
+			query: `subscription { users { id } }`,
+			want:  []string{"1:1: schema does not support subscription operations"},
+		},
+		{
+			name:  "variable types",
+			query: `query ($a: Unknown, $b: [User]) { users { id } }`,
+			want: []string{
+				`1:8: unknown type "Unknown"`,
+				`1:21: variable $b cannot be of output type "User"`,
+			},
+		},
+		{name: "syntax", query: `{ users { id }`, want: []string{`1:15: expected "}", found end of document`}},
+		{name: "empty selection", query: `{ users { } }`, want: []string{"1:11: expected a selection"}},
+		{name: "unterminated string", query: `{ user(id: "1) { id } }`, want: []string{"1:12: unterminated string"}},
+		{name: "bad character", query: `{ users { id; } }`, want: []string{`1:13: unexpected character ';'`}},
+		{name: "schema definition", query: `type User { id: ID }`, want: []string{`1:1: unexpected "type"`}},
+		{name: "empty", query: "  # nothing\n", want: []string{"1:1: document has no operations"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, messages(s.Validate(tt.query)))
+		})
+	}
+}
@@ -0,0 +1,346 @@
+// Package hooks runs external programs at points in a request's lifecycle,
+// so that requests can be signed, given secrets or checked in ways curly
+// does not know about, without changing curly.
+//
+// A hook receives the request, and the response after it is received, as a
+// JSON event on stdin, and may print JSON changes to stdout:
+//
+//	{"event": "pre_request", "request": {"method": "GET", "url": "...", "headers": {...}, ...}}
+//
+//	{"headers": {"X-Signature": "...", "X-Debug": null}, "query_params": {"ts": "1700000000"}}
+//
+// A null value removes a header or query parameter. Printing nothing changes
+// nothing, and an "error", or exiting with a non-zero status, stops the
+// request or save with that error.
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/williajm/curly/internal/domain"
+	"github.com/williajm/curly/internal/infrastructure/http"
+)
+
+// Events at which hooks run.
+const (
+	// EventPreRequest runs before a request is sent, after its variables
+	// are resolved. It can change the request's method, URL, headers, query
+	// parameters and body.
+	EventPreRequest = "pre_request"
+
+	// EventPostResponse runs once a response is received. It can change the
+	// response's headers and body and add assertion failures.
+	EventPostResponse = "post_response"
+
+	// EventOnSave runs before a request is saved. It can change the request
+	// as EventPreRequest can, and the changes are saved.
+	EventOnSave = "on_save"
+)
+
+// Events lists the events hooks can run at.
+var Events = []string{EventPreRequest, EventPostResponse, EventOnSave}
+
+// DefaultTimeout is how long a hook may run unless configured otherwise.
+const DefaultTimeout = 10 * time.Second
+
+// Hook is an external program run at an event.
+type Hook struct {
+	// Event is when the hook runs: EventPreRequest, EventPostResponse or
+	// EventOnSave.
+	Event string
+
+	// Command is the program to run, and Args its arguments. No shell is
+	// involved.
+	Command string
+	Args    []string
+
+	// Pattern, if set, limits the hook to requests whose host matches it, as
+	// http.Host patterns do.
+	Pattern string
+
+	// Timeout is how long the hook may run (DefaultTimeout if zero).
+	Timeout time.Duration
+}
+
+// matches reports whether the hook runs for req.
+func (h *Hook) matches(req *domain.Request) bool {
+	if h.Pattern == "" {
+		return true
+	}
+	u, err := url.Parse(req.URL)
+	if err != nil {
+		return false
+	}
+	return (&http.Host{Pattern: h.Pattern}).Matches(u)
+}
+
+// name returns how errors refer to the hook.
+func (h *Hook) name() string {
+	return fmt.Sprintf("%s hook %s", h.Event, h.Command)
+}
+
+// Runner runs the hooks of each event in order, each seeing the changes of
+// the ones before it. It implements http.Hook.
+type Runner struct {
+	hooks []Hook
+	run   func(ctx context.Context, hook *Hook, input []byte) ([]byte, error)
+}
+
+// NewRunner returns a Runner of hooks.
+func NewRunner(hooks []Hook) *Runner {
+	return &Runner{hooks: hooks, run: runCommand}
+}
+
+// Has reports whether any hook runs at event.
+func (r *Runner) Has(event string) bool {
+	for _, hook := range r.hooks {
+		if hook.Event == event {
+			return true
+		}
+	}
+	return false
+}
+
+// event is the JSON a hook receives on stdin.
+type event struct {
+	Event    string    `json:"event"`
+	Request  *request  `json:"request"`
+	Response *response `json:"response,omitempty"`
+}
+
+type request struct {
+	ID          string            `json:"id,omitempty"`
+	Name        string            `json:"name,omitempty"`
+	Folder      string            `json:"folder,omitempty"`
+	Method      string            `json:"method"`
+	URL         string            `json:"url"`
+	Headers     map[string]string `json:"headers"`
+	QueryParams map[string]string `json:"query_params"`
+	Body        string            `json:"body"`
+	AuthType    string            `json:"auth_type,omitempty"`
+}
+
+type response struct {
+	StatusCode int               `json:"status_code"`
+	Status     string            `json:"status"`
+	Headers    map[string]string `json:"headers"`
+	Body       string            `json:"body"`
+	DurationMs int64             `json:"duration_ms"`
+}
+
+// changes is the JSON a hook may print to stdout. Unset fields leave things
+// as they are.
+type changes struct {
+	Method            *string            `json:"method"`
+	URL               *string            `json:"url"`
+	Headers           map[string]*string `json:"headers"`
+	QueryParams       map[string]*string `json:"query_params"`
+	Body              *string            `json:"body"`
+	AssertionFailures []string           `json:"assertion_failures"`
+	Error             string             `json:"error"`
+}
+
+// BeforeRequest runs the pre_request hooks that match req and returns req
+// with their changes, leaving req itself as it is.
+func (r *Runner) BeforeRequest(ctx context.Context, req *domain.Request) (*domain.Request, error) {
+	return r.changeRequest(ctx, EventPreRequest, req)
+}
+
+// AfterResponse runs the post_response hooks that match req and applies
+// their changes to resp.
+func (r *Runner) AfterResponse(ctx context.Context, req *domain.Request, resp *domain.Response) error {
+	for i := range r.hooks {
+		hook := &r.hooks[i]
+		if hook.Event != EventPostResponse || !hook.matches(req) {
+			continue
+		}
+		c, err := r.call(ctx, hook, event{Event: hook.Event, Request: requestOf(req), Response: responseOf(resp)})
+		if err != nil {
+			return err
+		}
+		if c.Method != nil || c.URL != nil || c.QueryParams != nil {
+			return fmt.Errorf("%s: only headers, body and assertion_failures can change after the response", hook.name())
+		}
+		if resp.Headers == nil && len(c.Headers) > 0 {
+			resp.Headers = make(map[string]string, len(c.Headers))
+		}
+		applyValues(resp.Headers, c.Headers)
+		if c.Body != nil {
+			resp.Body = *c.Body
+			resp.ContentLength = int64(len(resp.Body))
+		}
+		resp.AssertionFailures = append(resp.AssertionFailures, c.AssertionFailures...)
+	}
+	return nil
+}
+
+// OnSave runs the on_save hooks that match req and applies their changes to
+// req, which is about to be saved.
+func (r *Runner) OnSave(ctx context.Context, req *domain.Request) error {
+	changed, err := r.changeRequest(ctx, EventOnSave, req)
+	if err != nil {
+		return err
+	}
+	*req = *changed
+	return nil
+}
+
+// changeRequest runs the hooks of eventName that match req and returns a copy
+// of req with their changes, or req itself if no hook ran.
+func (r *Runner) changeRequest(ctx context.Context, eventName string, req *domain.Request) (*domain.Request, error) {
+	result := req
+	for i := range r.hooks {
+		hook := &r.hooks[i]
+		if hook.Event != eventName || !hook.matches(result) {
+			continue
+		}
+		c, err := r.call(ctx, hook, event{Event: hook.Event, Request: requestOf(result)})
+		if err != nil {
+			return nil, err
+		}
+		if len(c.AssertionFailures) > 0 {
+			return nil, fmt.Errorf("%s: assertion_failures can only be added after the response", hook.name())
+		}
+		if result == req {
+			// Clone also gives the request non-nil maps to change.
+			result = req.Clone()
+		}
+		if c.Method != nil {
+			result.Method = strings.ToUpper(*c.Method)
+		}
+		if c.URL != nil {
+			result.URL = *c.URL
+		}
+		applyValues(result.Headers, c.Headers)
+		applyValues(result.QueryParams, c.QueryParams)
+		if c.Body != nil {
+			result.Body = *c.Body
+		}
+		if err := result.Validate(); err != nil {
+			return nil, fmt.Errorf("%s made the request invalid: %w", hook.name(), err)
+		}
+	}
+	return result, nil
+}
+
+// call runs hook with e as its input and returns the changes it printed.
+func (r *Runner) call(ctx context.Context, hook *Hook, e event) (*changes, error) {
+	input, err := json.Marshal(e)
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to encode event: %w", hook.name(), err)
+	}
+	output, err := r.run(ctx, hook, input)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", hook.name(), err)
+	}
+
+	var c changes
+	if len(bytes.TrimSpace(output)) > 0 {
+		if err := json.Unmarshal(output, &c); err != nil {
+			return nil, fmt.Errorf("%s printed invalid JSON: %w", hook.name(), err)
+		}
+	}
+	if c.Error != "" {
+		return nil, fmt.Errorf("%s: %s", hook.name(), c.Error)
+	}
+	return &c, nil
+}
+
+// applyValues sets the values of changes in values, or removes them where
+// they are null. Names match ignoring case, as header names do.
+func applyValues(values map[string]string, changes map[string]*string) {
+	names := make([]string, 0, len(changes))
+	for name := range changes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		for existing := range values {
+			if strings.EqualFold(existing, name) {
+				delete(values, existing)
+			}
+		}
+		if value := changes[name]; value != nil {
+			values[name] = *value
+		}
+	}
+}
+
+// requestOf returns the JSON form of req.
+func requestOf(req *domain.Request) *request {
+	r := &request{
+		ID:          req.ID,
+		Name:        req.Name,
+		Folder:      req.Folder,
+		Method:      req.Method,
+		URL:         req.URL,
+		Headers:     req.Headers,
+		QueryParams: req.QueryParams,
+		Body:        req.Body,
+	}
+	if r.Headers == nil {
+		r.Headers = map[string]string{}
+	}
+	if r.QueryParams == nil {
+		r.QueryParams = map[string]string{}
+	}
+	if req.AuthConfig != nil && req.AuthConfig.Type() != domain.AuthTypeNone {
+		r.AuthType = req.AuthConfig.Type()
+	}
+	return r
+}
+
+// responseOf returns the JSON form of resp.
+func responseOf(resp *domain.Response) *response {
+	r := &response{
+		StatusCode: resp.StatusCode,
+		Status:     resp.Status,
+		Headers:    resp.Headers,
+		Body:       resp.Body,
+		DurationMs: resp.DurationMillis(),
+	}
+	if r.Headers == nil {
+		r.Headers = map[string]string{}
+	}
+	return r
+}
+
+// runCommand runs the program of hook with input on stdin and returns what
+// it printed to stdout. The event's name is also in CURLY_HOOK_EVENT.
+func runCommand(ctx context.Context, hook *Hook, input []byte) ([]byte, error) {
+	timeout := hook.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	// #nosec G204 -- Running the configured program is the point.
+	cmd := exec.CommandContext(ctx, hook.Command, hook.Args...)
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Env = append(os.Environ(), "CURLY_HOOK_EVENT="+hook.Event)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return nil, fmt.Errorf("timed out after %s", timeout)
+		}
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			return nil, fmt.Errorf("%w: %s", err, message)
+		}
+		return nil, err
+	}
+	return stdout.Bytes(), nil
+}
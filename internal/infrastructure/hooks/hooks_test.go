@@ -0,0 +1,214 @@
+package hooks
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/williajm/curly/internal/domain"
+)
+
+// fakeRunner returns a Runner of hooks that answers each call with the
+// output of respond, recording the events it receives.
+func fakeRunner(hooks []Hook, respond func(hook *Hook) (string, error), received *[]event) *Runner {
+	return &Runner{
+		hooks: hooks,
+		run: func(_ context.Context, hook *Hook, input []byte) ([]byte, error) {
+			var e event
+			if err := json.Unmarshal(input, &e); err != nil {
+				return nil, err
+			}
+			*received = append(*received, e)
+			output, err := respond(hook)
+			return []byte(output), err
+		},
+	}
+}
+
+func newRequest() *domain.Request {
+	req := domain.NewRequestWithMethodAndURL("GET", "https://api.example.com/users")
+	req.Headers["Accept"] = "application/json"
+	req.Headers["X-Debug"] = "1"
+	return req
+}
+
+func TestRunner_BeforeRequest(t *testing.T) {
+	var received []event
+	runner := fakeRunner([]Hook{
+		{Event: EventPreRequest, Command: "sign"},
+		{Event: EventPreRequest, Command: "other", Pattern: "*.other.com"},
+		{Event: EventPostResponse, Command: "check"},
+	}, func(*Hook) (string, error) {
+		return `{"method": "post", "headers": {"x-debug": null, "X-Signature": "abc"}, "query_params": {"ts": "1"}, "body": "{}"}`, nil
+	}, &received)
+
+	req := newRequest()
+	changed, err := runner.BeforeRequest(context.Background(), req)
+	require.NoError(t, err)
+
+	require.Len(t, received, 1)
+	assert.Equal(t, EventPreRequest, received[0].Event)
+	assert.Equal(t, "https://api.example.com/users", received[0].Request.URL)
+	assert.Nil(t, received[0].Response)
+
+	assert.Equal(t, "POST", changed.Method)
+	assert.Equal(t, map[string]string{"Accept": "application/json", "X-Signature": "abc"}, changed.Headers)
+	assert.Equal(t, map[string]string{"ts": "1"}, changed.QueryParams)
+	assert.Equal(t, "{}", changed.Body)
+
+	// The original request is left as it was.
+	assert.Equal(t, "GET", req.Method)
+	assert.Equal(t, "1", req.Headers["X-Debug"])
+	assert.Empty(t, req.QueryParams)
+}
+
+func TestRunner_BeforeRequest_NoChanges(t *testing.T) {
+	var received []event
+	runner := fakeRunner([]Hook{{Event: EventPreRequest, Command: "noop"}}, func(*Hook) (string, error) {
+		return "\n", nil
+	}, &received)
+
+	req := newRequest()
+	changed, err := runner.BeforeRequest(context.Background(), req)
+	require.NoError(t, err)
+	assert.Len(t, received, 1)
+	assert.Equal(t, req.Headers, changed.Headers)
+}
+
+func TestRunner_BeforeRequest_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		output  string
+		err     error
+		wantErr string
+	}{
+		{"failure", "", errors.New("exit status 1: no key"), "pre_request hook sign: exit status 1: no key"},
+		{"error field", `{"error": "token expired"}`, nil, "pre_request hook sign: token expired"},
+		{"invalid json", "signed", nil, "pre_request hook sign printed invalid JSON"},
+		{"invalid request", `{"url": ""}`, nil, "pre_request hook sign made the request invalid"},
+		{"assertions", `{"assertion_failures": ["no"]}`, nil, "assertion_failures can only be added after the response"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var received []event
+			runner := fakeRunner([]Hook{{Event: EventPreRequest, Command: "sign"}}, func(*Hook) (string, error) {
+				return tt.output, tt.err
+			}, &received)
+
+			_, err := runner.BeforeRequest(context.Background(), newRequest())
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestRunner_AfterResponse(t *testing.T) {
+	var received []event
+	runner := fakeRunner([]Hook{
+		{Event: EventPostResponse, Command: "check"},
+		{Event: EventPostResponse, Command: "decrypt"},
+	}, func(hook *Hook) (string, error) {
+		if hook.Command == "check" {
+			return `{"assertion_failures": ["missing X-Request-Id"]}`, nil
+		}
+		return `{"headers": {"X-Decrypted": "true"}, "body": "plain"}`, nil
+	}, &received)
+
+	resp := &domain.Response{
+		StatusCode:        200,
+		Status:            "200 OK",
+		Body:              "cipher text",
+		Duration:          150 * time.Millisecond,
+		AssertionFailures: []string{"status was 200"},
+	}
+	require.NoError(t, runner.AfterResponse(context.Background(), newRequest(), resp))
+
+	require.Len(t, received, 2)
+	require.NotNil(t, received[0].Response)
+	assert.Equal(t, 200, received[0].Response.StatusCode)
+	assert.Equal(t, int64(150), received[0].Response.DurationMs)
+	assert.Equal(t, "cipher text", received[0].Response.Body)
+
+	assert.Equal(t, "plain", resp.Body)
+	assert.Equal(t, int64(5), resp.ContentLength)
+	assert.Equal(t, map[string]string{"X-Decrypted": "true"}, resp.Headers)
+	assert.Equal(t, []string{"status was 200", "missing X-Request-Id"}, resp.AssertionFailures)
+}
+
+func TestRunner_AfterResponse_RequestChanges(t *testing.T) {
+	var received []event
+	runner := fakeRunner([]Hook{{Event: EventPostResponse, Command: "check"}}, func(*Hook) (string, error) {
+		return `{"url": "https://elsewhere.com"}`, nil
+	}, &received)
+
+	err := runner.AfterResponse(context.Background(), newRequest(), &domain.Response{StatusCode: 200})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "only headers, body and assertion_failures can change")
+}
+
+func TestRunner_OnSave(t *testing.T) {
+	var received []event
+	runner := fakeRunner([]Hook{
+		{Event: EventOnSave, Command: "tag", Pattern: "api.example.com"},
+		{Event: EventOnSave, Command: "skipped", Pattern: "*.internal"},
+	}, func(*Hook) (string, error) {
+		return `{"headers": {"X-Team": "payments"}}`, nil
+	}, &received)
+
+	req := newRequest()
+	require.NoError(t, runner.OnSave(context.Background(), req))
+	assert.Len(t, received, 1)
+	assert.Equal(t, EventOnSave, received[0].Event)
+	assert.Equal(t, "payments", req.Headers["X-Team"])
+}
+
+func TestRunner_Has(t *testing.T) {
+	runner := NewRunner([]Hook{{Event: EventOnSave, Command: "tag"}})
+	assert.True(t, runner.Has(EventOnSave))
+	assert.False(t, runner.Has(EventPreRequest))
+	assert.False(t, NewRunner(nil).Has(EventOnSave))
+}
+
+func TestRunCommand(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("hook scripts need a POSIX shell")
+	}
+	dir := t.TempDir()
+	script := filepath.Join(dir, "sign.sh")
+	require.NoError(t, os.WriteFile(script, []byte(`#!/bin/sh
+input=$(cat)
+case "$input" in
+*'"event":"pre_request"'*) ;;
+*) echo "unexpected input" >&2; exit 2 ;;
+esac
+printf '{"headers": {"X-Event": "%s", "X-Arg": "%s"}}' "$CURLY_HOOK_EVENT" "$1"
+`), 0700))
+	failing := filepath.Join(dir, "fail.sh")
+	require.NoError(t, os.WriteFile(failing, []byte("#!/bin/sh\necho 'no signing key' >&2\nexit 1\n"), 0700))
+	slow := filepath.Join(dir, "slow.sh")
+	require.NoError(t, os.WriteFile(slow, []byte("#!/bin/sh\nexec sleep 5\n"), 0700))
+
+	changed, err := NewRunner([]Hook{{Event: EventPreRequest, Command: script, Args: []string{"v1"}}}).
+		BeforeRequest(context.Background(), newRequest())
+	require.NoError(t, err)
+	assert.Equal(t, "pre_request", changed.Headers["X-Event"])
+	assert.Equal(t, "v1", changed.Headers["X-Arg"])
+
+	_, err = NewRunner([]Hook{{Event: EventPreRequest, Command: failing}}).
+		BeforeRequest(context.Background(), newRequest())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no signing key")
+
+	_, err = NewRunner([]Hook{{Event: EventPreRequest, Command: slow, Timeout: 100 * time.Millisecond}}).
+		BeforeRequest(context.Background(), newRequest())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "timed out after 100ms")
+}
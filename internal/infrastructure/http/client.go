@@ -26,10 +26,22 @@ import (
 // domain.Response with timing and metadata.
 type Client interface {
 	// Execute sends the HTTP request and returns the response with timing information.
-	// The context can be used for cancellation and timeout control.
+	// The context can be used for cancellation and timeout control, and to
+	// follow the transfer with WithProgress.
 	Execute(ctx context.Context, req *domain.Request) (*domain.Response, error)
 }
 
+// Streamer is implemented by clients that can hand over a response body as
+// it arrives, for responses that stay open, such as event streams.
+type Streamer interface {
+	// Stream sends the HTTP request and returns the response's status and
+	// headers, with the time they took, and its body unread. The client's
+	// overall timeout does not apply; canceling ctx ends the stream. The
+	// caller must close the body. The body of a 101 Switching Protocols
+	// response is the upgraded connection, an io.ReadWriteCloser.
+	Stream(ctx context.Context, req *domain.Request) (*domain.Response, io.ReadCloser, error)
+}
+
 // Config holds HTTP client configuration options.
 type Config struct {
 	// Timeout is the maximum duration for the entire request (including redirects).
@@ -60,6 +72,10 @@ type Config struct {
 
 	// IdleConnTimeout is the maximum time an idle connection remains open.
 	IdleConnTimeout time.Duration
+
+	// Hosts holds settings for particular hosts. Each request uses the first
+	// host that matches it.
+	Hosts []Host
 }
 
 // DefaultConfig returns a Config with sensible default values.
@@ -78,20 +94,131 @@ func DefaultConfig() *Config {
 	}
 }
 
+// Middleware wraps a transport, such as to trace, record or replay the requests
+// that pass through it.
+type Middleware func(http.RoundTripper) http.RoundTripper
+
+// RoundTripperFunc adapts an ordinary function to http.RoundTripper, which is
+// convenient for middleware and for fixture transports in tests.
+type RoundTripperFunc func(*http.Request) (*http.Response, error)
+
+// RoundTrip calls f(req).
+func (f RoundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// Hook changes requests before they are sent and responses once they are
+// received, such as to sign requests or add credentials.
+type Hook interface {
+	// BeforeRequest returns the request to send in place of req, which it
+	// must not change. An error stops the request.
+	BeforeRequest(ctx context.Context, req *domain.Request) (*domain.Request, error)
+
+	// AfterResponse changes resp, the response to req. An error fails the
+	// request.
+	AfterResponse(ctx context.Context, req *domain.Request, resp *domain.Response) error
+}
+
+// Option customizes a client created by NewClient.
+type Option func(*options)
+
+// options holds the settings applied by Option values.
+type options struct {
+	transport  http.RoundTripper
+	middleware []Middleware
+	hook       Hook
+}
+
+// WithTransport replaces the transport built from Config. The Config's dial,
+// TLS and keep-alive settings then do not apply; Timeout and the redirect
+// policy still do.
+func WithTransport(transport http.RoundTripper) Option {
+	return func(o *options) {
+		o.transport = transport
+	}
+}
+
+// WithMiddleware wraps the transport in middleware. The first middleware is
+// the outermost: it sees each request first and each response last. Repeated
+// options append to the chain.
+func WithMiddleware(middleware ...Middleware) Option {
+	return func(o *options) {
+		o.middleware = append(o.middleware, middleware...)
+	}
+}
+
+// WithHook runs hook around every request the client sends. Requests are
+// changed before the per-host settings apply. Stream only runs BeforeRequest,
+// since the body of a stream is read later.
+func WithHook(hook Hook) Option {
+	return func(o *options) {
+		o.hook = hook
+	}
+}
+
 // httpClient is the concrete implementation of the Client interface.
 type httpClient struct {
 	client *http.Client
 	config *Config
+	hosts  []*hostRoute
+	hook   Hook
 }
 
 // NewClient creates a new HTTP client with the provided configuration.
-// If config is nil, DefaultConfig() is used.
-func NewClient(config *Config) Client {
+// If config is nil, DefaultConfig() is used. Options can replace or wrap the
+// transport, so embedders can add tracing, recording or fixtures.
+func NewClient(config *Config, opts ...Option) Client {
 	if config == nil {
 		config = DefaultConfig()
 	}
 
-	// Create custom transport with configured timeouts.
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	// Create custom transport with configured timeouts. Only the settings
+	// of a host can fail to apply.
+	transport, _ := newTransport(config, nil)
+
+	// Configure redirect policy.
+	checkRedirect := func(_ *http.Request, via []*http.Request) error {
+		if !config.FollowRedirects {
+			return http.ErrUseLastResponse
+		}
+		if len(via) >= config.MaxRedirects {
+			return fmt.Errorf("stopped after %d redirects", config.MaxRedirects)
+		}
+		return nil
+	}
+
+	var roundTripper http.RoundTripper = transport
+	if o.transport != nil {
+		roundTripper = o.transport
+	}
+	hosts := newHostRoutes(config)
+	if o.transport == nil && len(hosts) > 0 {
+		roundTripper = &hostTransport{routes: hosts, fallback: roundTripper}
+	}
+	for i := len(o.middleware) - 1; i >= 0; i-- {
+		roundTripper = o.middleware[i](roundTripper)
+	}
+
+	return &httpClient{
+		client: &http.Client{
+			Transport:     roundTripper,
+			CheckRedirect: checkRedirect,
+			Timeout:       config.Timeout,
+		},
+		config: config,
+		hosts:  hosts,
+		hook:   o.hook,
+	}
+}
+
+// newTransport creates a transport with the timeouts of config and the proxy
+// and TLS settings of host, if it is not nil.
+func newTransport(config *Config, host *Host) (*http.Transport, error) {
 	transport := &http.Transport{
 		DialContext: (&net.Dialer{
 			Timeout:   config.DialTimeout,
@@ -110,52 +237,62 @@ func NewClient(config *Config) Client {
 			InsecureSkipVerify: true, // #nosec G402 -- Intentionally allow insecure TLS for testing self-signed certificates
 		}
 	}
+	if host == nil {
+		return transport, nil
+	}
 
-	// Configure redirect policy.
-	checkRedirect := func(_ *http.Request, via []*http.Request) error {
-		if !config.FollowRedirects {
-			return http.ErrUseLastResponse
-		}
-		if len(via) >= config.MaxRedirects {
-			return fmt.Errorf("stopped after %d redirects", config.MaxRedirects)
+	if host.Proxy != "" {
+		proxyURL, err := url.Parse(host.Proxy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid proxy URL: %w", err)
 		}
-		return nil
+		transport.Proxy = http.ProxyURL(proxyURL)
 	}
-
-	return &httpClient{
-		client: &http.Client{
-			Transport:     transport,
-			CheckRedirect: checkRedirect,
-			Timeout:       config.Timeout,
-		},
-		config: config,
+	if host.InsecureSkipTLS || host.CACert != "" || host.ClientCert != "" {
+		tlsConfig, err := hostTLSConfig(host)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.InsecureSkipVerify = tlsConfig.InsecureSkipVerify || config.InsecureSkipTLS
+		transport.TLSClientConfig = tlsConfig
 	}
+	return transport, nil
 }
 
 // Execute converts the domain request to an HTTP request, executes it,.
 // and converts the HTTP response back to a domain response with timing metrics.
 func (c *httpClient) Execute(ctx context.Context, req *domain.Request) (*domain.Response, error) {
-	// Validate the request before processing.
-	if err := req.Validate(); err != nil {
-		return nil, fmt.Errorf("invalid request: %w", err)
+	if c.hook != nil {
+		var err error
+		if req, err = c.hook.BeforeRequest(ctx, req); err != nil {
+			return nil, err
+		}
 	}
-
-	// Build the HTTP request.
-	httpReq, err := c.buildHTTPRequest(ctx, req)
+	httpReq, err := BuildRequest(ctx, req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to build HTTP request: %w", err)
+		return nil, err
 	}
-
-	// Apply authentication.
-	if req.AuthConfig != nil {
-		if err := req.AuthConfig.Apply(httpReq); err != nil {
-			return nil, fmt.Errorf("failed to apply authentication: %w", err)
+	client := c.client
+	if host := matchHost(c.hosts, httpReq.URL); host != nil {
+		if err := host.apply(httpReq, req); err != nil {
+			return nil, err
+		}
+		if host.Timeout > 0 {
+			withTimeout := *c.client
+			withTimeout.Timeout = host.Timeout
+			client = &withTimeout
 		}
 	}
+	progress := progressFrom(ctx)
+	if progress != nil && httpReq.Body != nil {
+		httpReq.Body = newProgressReader(httpReq.Body, progress, true, httpReq.ContentLength)
+	}
+	var trace timingTrace
+	httpReq = httpReq.WithContext(trace.withTimingTrace(httpReq.Context()))
 
 	// Execute the request and measure timing.
 	startTime := time.Now()
-	httpResp, err := c.client.Do(httpReq)
+	httpResp, err := client.Do(httpReq)
 	duration := time.Since(startTime)
 
 	if err != nil {
@@ -164,20 +301,85 @@ func (c *httpClient) Execute(ctx context.Context, req *domain.Request) (*domain.
 	defer func() {
 		_ = httpResp.Body.Close()
 	}()
+	if progress != nil {
+		httpResp.Body = newProgressReader(httpResp.Body, progress, false, httpResp.ContentLength)
+	}
 
 	// Convert HTTP response to domain response.
 	resp, err := c.buildDomainResponse(httpResp, duration, startTime, req.ID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to process response: %w", err)
 	}
+	resp.Timing = trace.timing(time.Now())
 
+	if c.hook != nil {
+		if err := c.hook.AfterResponse(ctx, req, resp); err != nil {
+			return nil, err
+		}
+	}
 	return resp, nil
 }
 
+// Stream implements Streamer.
+func (c *httpClient) Stream(ctx context.Context, req *domain.Request) (*domain.Response, io.ReadCloser, error) {
+	if c.hook != nil {
+		var err error
+		if req, err = c.hook.BeforeRequest(ctx, req); err != nil {
+			return nil, nil, err
+		}
+	}
+	httpReq, err := BuildRequest(ctx, req)
+	if err != nil {
+		return nil, nil, err
+	}
+	if host := matchHost(c.hosts, httpReq.URL); host != nil {
+		if err := host.apply(httpReq, req); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	// The timeout covers reading the body, which may never end.
+	client := *c.client
+	client.Timeout = 0
+
+	startTime := time.Now()
+	httpResp, err := client.Do(httpReq)
+	duration := time.Since(startTime)
+	if err != nil {
+		return nil, nil, c.handleRequestError(err, duration, startTime)
+	}
+
+	return responseHead(httpResp, duration, startTime, req.ID), httpResp.Body, nil
+}
+
+// BuildRequest converts a domain.Request to the *http.Request that Execute
+// sends, with its authentication applied.
+func BuildRequest(ctx context.Context, req *domain.Request) (*http.Request, error) {
+	// Validate the request before processing.
+	if err := req.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid request: %w", err)
+	}
+
+	// Build the HTTP request.
+	httpReq, err := buildHTTPRequest(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build HTTP request: %w", err)
+	}
+
+	// Apply authentication.
+	if req.AuthConfig != nil {
+		if err := req.AuthConfig.Apply(httpReq); err != nil {
+			return nil, fmt.Errorf("failed to apply authentication: %w", err)
+		}
+	}
+
+	return httpReq, nil
+}
+
 // buildHTTPRequest converts a domain.Request to an *http.Request.
-func (c *httpClient) buildHTTPRequest(ctx context.Context, req *domain.Request) (*http.Request, error) {
+func buildHTTPRequest(ctx context.Context, req *domain.Request) (*http.Request, error) {
 	// Parse and build URL with query parameters.
-	requestURL, err := c.buildURL(req)
+	requestURL, err := req.FullURL()
 	if err != nil {
 		return nil, err
 	}
@@ -207,29 +409,6 @@ func (c *httpClient) buildHTTPRequest(ctx context.Context, req *domain.Request)
 	return httpReq, nil
 }
 
-// buildURL constructs the full URL with query parameters merged correctly.
-func (c *httpClient) buildURL(req *domain.Request) (string, error) {
-	// Parse the base URL.
-	parsedURL, err := url.Parse(req.URL)
-	if err != nil {
-		return "", fmt.Errorf("failed to parse URL: %w", err)
-	}
-
-	// If there are no query parameters, return as-is.
-	if len(req.QueryParams) == 0 {
-		return parsedURL.String(), nil
-	}
-
-	// Merge query parameters from URL and request.
-	query := parsedURL.Query()
-	for key, value := range req.QueryParams {
-		query.Set(key, value)
-	}
-	parsedURL.RawQuery = query.Encode()
-
-	return parsedURL.String(), nil
-}
-
 // buildDomainResponse converts an *http.Response to a domain.Response.
 func (c *httpClient) buildDomainResponse(httpResp *http.Response, duration time.Duration, timestamp time.Time, requestID string) (*domain.Response, error) {
 	// Read response body.
@@ -238,6 +417,20 @@ func (c *httpClient) buildDomainResponse(httpResp *http.Response, duration time.
 		return nil, fmt.Errorf("failed to read response body: %w", err)
 	}
 
+	resp := responseHead(httpResp, duration, timestamp, requestID)
+	resp.Body = string(bodyBytes)
+
+	// If ContentLength is -1 (unknown), use actual body length.
+	if resp.ContentLength == -1 {
+		resp.ContentLength = int64(len(bodyBytes))
+	}
+
+	return resp, nil
+}
+
+// responseHead converts the status and headers of an *http.Response to a
+// domain.Response without a body.
+func responseHead(httpResp *http.Response, duration time.Duration, timestamp time.Time, requestID string) *domain.Response {
 	// Convert headers to map.
 	headers := make(map[string]string)
 	for name, values := range httpResp.Header {
@@ -247,24 +440,45 @@ func (c *httpClient) buildDomainResponse(httpResp *http.Response, duration time.
 		}
 	}
 
-	// Build domain response.
-	resp := &domain.Response{
+	return &domain.Response{
 		StatusCode:    httpResp.StatusCode,
 		Status:        httpResp.Status,
 		Headers:       headers,
-		Body:          string(bodyBytes),
+		SetCookies:    httpResp.Header.Values("Set-Cookie"),
 		ContentLength: httpResp.ContentLength,
 		Duration:      duration,
 		Timestamp:     timestamp,
 		RequestID:     requestID,
+		TLS:           tlsInfo(httpResp.TLS),
 	}
+}
 
-	// If ContentLength is -1 (unknown), use actual body length.
-	if resp.ContentLength == -1 {
-		resp.ContentLength = int64(len(bodyBytes))
+// tlsInfo describes the TLS connection a response was received over, or
+// returns nil if it was not received over TLS.
+func tlsInfo(state *tls.ConnectionState) *domain.TLSInfo {
+	if state == nil {
+		return nil
 	}
 
-	return resp, nil
+	info := &domain.TLSInfo{
+		Version:            tls.VersionName(state.Version),
+		CipherSuite:        tls.CipherSuiteName(state.CipherSuite),
+		NegotiatedProtocol: state.NegotiatedProtocol,
+		ServerName:         state.ServerName,
+	}
+	for _, cert := range state.PeerCertificates {
+		info.Certificates = append(info.Certificates, domain.Certificate{
+			Subject:            cert.Subject.String(),
+			Issuer:             cert.Issuer.String(),
+			DNSNames:           cert.DNSNames,
+			SerialNumber:       strings.ToUpper(cert.SerialNumber.Text(16)),
+			SignatureAlgorithm: cert.SignatureAlgorithm.String(),
+			PublicKeyAlgorithm: cert.PublicKeyAlgorithm.String(),
+			NotBefore:          cert.NotBefore,
+			NotAfter:           cert.NotAfter,
+		})
+	}
+	return info
 }
 
 // handleRequestError converts HTTP client errors to user-friendly error messages.
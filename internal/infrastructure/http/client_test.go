@@ -3,10 +3,12 @@ package http
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"slices"
 	"strings"
 	"testing"
 	"time"
@@ -35,6 +37,123 @@ func TestNewClient(t *testing.T) {
 	})
 }
 
+// TestNewClient_WithTransport verifies that a replacement transport serves requests.
+func TestNewClient_WithTransport(t *testing.T) {
+	fixture := RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{
+			StatusCode: http.StatusTeapot,
+			Status:     "418 I'm a teapot",
+			Header:     http.Header{"X-Fixture": {"yes"}},
+			Body:       io.NopCloser(strings.NewReader("recorded " + req.URL.Path)),
+			Request:    req,
+		}, nil
+	})
+	client := NewClient(nil, WithTransport(fixture))
+
+	req := domain.NewRequestWithMethodAndURL(domain.MethodGet, "https://unreachable.invalid/pets")
+	resp, err := client.Execute(context.Background(), req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.StatusCode != http.StatusTeapot {
+		t.Errorf("expected status 418, got %d", resp.StatusCode)
+	}
+	if resp.Body != "recorded /pets" {
+		t.Errorf("expected fixture body, got %q", resp.Body)
+	}
+	if resp.Headers["X-Fixture"] != "yes" {
+		t.Errorf("expected fixture header, got %v", resp.Headers)
+	}
+}
+
+// TestNewClient_WithMiddleware verifies that middleware wraps the transport in order.
+func TestNewClient_WithMiddleware(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprint(w, r.Header.Get("X-Trace"))
+	}))
+	defer server.Close()
+
+	var calls []string
+	tracer := func(name string) Middleware {
+		return func(next http.RoundTripper) http.RoundTripper {
+			return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				calls = append(calls, name)
+				req.Header.Add("X-Trace", name)
+				resp, err := next.RoundTrip(req)
+				calls = append(calls, name+" done")
+				return resp, err
+			})
+		}
+	}
+	client := NewClient(nil, WithMiddleware(tracer("outer")), WithMiddleware(tracer("inner")))
+
+	resp, err := client.Execute(context.Background(), domain.NewRequestWithMethodAndURL(domain.MethodGet, server.URL))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.Body != "outer" {
+		t.Errorf("expected the server to see the outer trace header first, got %q", resp.Body)
+	}
+	want := []string{"outer", "inner", "inner done", "outer done"}
+	if strings.Join(calls, ",") != strings.Join(want, ",") {
+		t.Errorf("expected calls %v, got %v", want, calls)
+	}
+}
+
+// signingHook adds a header to requests and records the responses it sees.
+type signingHook struct {
+	err       error
+	responses int
+}
+
+func (h *signingHook) BeforeRequest(_ context.Context, req *domain.Request) (*domain.Request, error) {
+	if h.err != nil {
+		return nil, h.err
+	}
+	signed := req.Clone()
+	signed.Headers["X-Signature"] = "signed"
+	return signed, nil
+}
+
+func (h *signingHook) AfterResponse(_ context.Context, req *domain.Request, resp *domain.Response) error {
+	h.responses++
+	if req.Headers["X-Signature"] != "signed" {
+		return errors.New("hook saw the unsigned request")
+	}
+	resp.AssertionFailures = append(resp.AssertionFailures, "checked by hook")
+	return nil
+}
+
+func TestNewClient_WithHook(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprint(w, r.Header.Get("X-Signature"))
+	}))
+	defer server.Close()
+
+	hook := &signingHook{}
+	client := NewClient(nil, WithHook(hook))
+	req := domain.NewRequestWithMethodAndURL(domain.MethodGet, server.URL)
+
+	resp, err := client.Execute(context.Background(), req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.Body != "signed" {
+		t.Errorf("expected the server to see the signed request, got %q", resp.Body)
+	}
+	if hook.responses != 1 || len(resp.AssertionFailures) != 1 {
+		t.Errorf("expected the hook to check the response once, got %d calls and failures %v", hook.responses, resp.AssertionFailures)
+	}
+	if _, ok := req.Headers["X-Signature"]; ok {
+		t.Error("expected the caller's request to be left unsigned")
+	}
+
+	hook.err = errors.New("no signing key")
+	if _, err := client.Execute(context.Background(), req); err == nil || err.Error() != "no signing key" {
+		t.Errorf("expected the hook's error, got %v", err)
+	}
+}
+
 // TestDefaultConfig verifies default configuration values.
 func TestDefaultConfig(t *testing.T) {
 	config := DefaultConfig()
@@ -716,6 +835,8 @@ func TestExecute_ResponseMetadata(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
 		w.Header().Set("Content-Type", "text/plain")
 		w.Header().Set("X-Custom-Header", "CustomValue")
+		w.Header().Add("Set-Cookie", "session=abc; Expires=Wed, 21 Oct 2026 07:28:00 GMT")
+		w.Header().Add("Set-Cookie", "theme=dark")
 		w.WriteHeader(http.StatusOK)
 		_, _ = fmt.Fprint(w, responseBody)
 	}))
@@ -747,6 +868,12 @@ func TestExecute_ResponseMetadata(t *testing.T) {
 		t.Errorf("expected X-Custom-Header 'CustomValue', got %q", resp.GetHeader("X-Custom-Header"))
 	}
 
+	// Set-Cookie values are kept apart.
+	wantCookies := []string{"session=abc; Expires=Wed, 21 Oct 2026 07:28:00 GMT", "theme=dark"}
+	if !slices.Equal(resp.SetCookies, wantCookies) {
+		t.Errorf("expected SetCookies %q, got %q", wantCookies, resp.SetCookies)
+	}
+
 	// Verify body.
 	if resp.Body != responseBody {
 		t.Errorf("expected body %q, got %q", responseBody, resp.Body)
@@ -779,6 +906,90 @@ func TestExecute_ResponseMetadata(t *testing.T) {
 }
 
 // TestExecute_StatusCodes tests various HTTP status codes.
+func TestExecute_TLS(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	// The test server's certificate is self-signed.
+	client := NewClient(&Config{Timeout: 5 * time.Second, InsecureSkipTLS: true})
+	resp, err := client.Execute(context.Background(), domain.NewRequestWithMethodAndURL(domain.MethodGet, server.URL))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if resp.TLS == nil {
+		t.Fatal("expected TLS details for an HTTPS response")
+	}
+	if !strings.HasPrefix(resp.TLS.Version, "TLS 1.") {
+		t.Errorf("unexpected TLS version %q", resp.TLS.Version)
+	}
+	if resp.TLS.CipherSuite == "" {
+		t.Error("expected the cipher suite to be named")
+	}
+	if len(resp.TLS.Certificates) == 0 {
+		t.Fatal("expected the server's certificate chain")
+	}
+	leaf := resp.TLS.Certificates[0]
+	cert := server.Certificate()
+	if leaf.Subject != cert.Subject.String() || !leaf.NotAfter.Equal(cert.NotAfter) {
+		t.Errorf("unexpected certificate %+v", leaf)
+	}
+
+	// Plain HTTP responses have none.
+	plain := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer plain.Close()
+	resp, err = client.Execute(context.Background(), domain.NewRequestWithMethodAndURL(domain.MethodGet, plain.URL))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.TLS != nil {
+		t.Errorf("expected no TLS details for a plain HTTP response, got %+v", resp.TLS)
+	}
+}
+
+func TestExecute_Timing(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(20 * time.Millisecond)
+		_, _ = io.WriteString(w, "ok")
+	}))
+	defer server.Close()
+
+	client := NewClient(&Config{Timeout: 5 * time.Second, InsecureSkipTLS: true})
+	req := domain.NewRequestWithMethodAndURL(domain.MethodGet, server.URL)
+	resp, err := client.Execute(context.Background(), req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	timing := resp.Timing
+	if timing == nil {
+		t.Fatal("expected the timing to be measured")
+	}
+	if timing.ReusedConnection {
+		t.Error("expected the first request to open a connection")
+	}
+	if timing.TLS <= 0 {
+		t.Errorf("expected a TLS handshake, got %v", timing.TLS)
+	}
+	if timing.Wait < 20*time.Millisecond {
+		t.Errorf("expected the wait to include the server's delay, got %v", timing.Wait)
+	}
+
+	// A second request reuses the connection, so only waits and downloads.
+	resp, err = client.Execute(context.Background(), req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.Timing == nil || !resp.Timing.ReusedConnection {
+		t.Fatalf("expected a reused connection, got %+v", resp.Timing)
+	}
+	if resp.Timing.Connect != 0 || resp.Timing.TLS != 0 {
+		t.Errorf("expected no connect or TLS handshake, got %+v", resp.Timing)
+	}
+}
+
 func TestExecute_StatusCodes(t *testing.T) {
 	testCases := []struct {
 		name       string
@@ -820,3 +1031,100 @@ func TestExecute_StatusCodes(t *testing.T) {
 		})
 	}
 }
+
+// TestStream verifies that a streamed body arrives as it is written and
+// outlives the client's timeout.
+func TestStream(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "data: first\n\n")
+		w.(http.Flusher).Flush()
+		<-release
+		time.Sleep(100 * time.Millisecond)
+		fmt.Fprint(w, "data: second\n\n")
+	}))
+	defer server.Close()
+
+	client := NewClient(&Config{Timeout: 50 * time.Millisecond})
+	req := domain.NewRequestWithMethodAndURL(domain.MethodGet, server.URL)
+	resp, body, err := client.(Streamer).Stream(context.Background(), req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("expected status 200, got %d", resp.StatusCode)
+	}
+	if got := resp.Headers["Content-Type"]; got != "text/event-stream" {
+		t.Errorf("expected event stream content type, got %q", got)
+	}
+	if resp.Body != "" {
+		t.Errorf("expected the body to be left unread, got %q", resp.Body)
+	}
+
+	// The first event is readable before the server finishes.
+	first := make([]byte, len("data: first\n\n"))
+	if _, err := io.ReadFull(body, first); err != nil {
+		t.Fatalf("unexpected error reading the first event: %v", err)
+	}
+	close(release)
+
+	rest, err := io.ReadAll(body)
+	if err != nil {
+		t.Fatalf("unexpected error reading the stream: %v", err)
+	}
+	if string(first)+string(rest) != "data: first\n\ndata: second\n\n" {
+		t.Errorf("unexpected stream %q", string(first)+string(rest))
+	}
+}
+
+func TestStream_SwitchingProtocols(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Upgrade") != "echo" {
+			http.Error(w, "upgrade required", http.StatusUpgradeRequired)
+			return
+		}
+		conn, rw, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		fmt.Fprint(rw, "HTTP/1.1 101 Switching Protocols\r\nConnection: Upgrade\r\nUpgrade: echo\r\n\r\n")
+		_ = rw.Flush()
+		line, _ := rw.ReadString('\n')
+		fmt.Fprint(rw, "echo: "+line)
+		_ = rw.Flush()
+	}))
+	defer server.Close()
+
+	client := NewClient(&Config{Timeout: time.Second})
+	req := domain.NewRequestWithMethodAndURL(domain.MethodGet, server.URL)
+	req.Headers["Connection"] = "Upgrade"
+	req.Headers["Upgrade"] = "echo"
+	resp, body, err := client.(Streamer).Stream(context.Background(), req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer body.Close()
+
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		t.Fatalf("expected status 101, got %d", resp.StatusCode)
+	}
+	// The upgraded connection is handed over both ways.
+	conn, ok := body.(io.ReadWriteCloser)
+	if !ok {
+		t.Fatalf("expected the body of a 101 response to be writable")
+	}
+	if _, err := fmt.Fprint(conn, "hello\n"); err != nil {
+		t.Fatalf("unexpected error writing: %v", err)
+	}
+	reply := make([]byte, len("echo: hello\n"))
+	if _, err := io.ReadFull(conn, reply); err != nil {
+		t.Fatalf("unexpected error reading: %v", err)
+	}
+	if string(reply) != "echo: hello\n" {
+		t.Errorf("unexpected reply %q", reply)
+	}
+}
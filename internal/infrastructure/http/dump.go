@@ -0,0 +1,27 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"net/http/httputil"
+
+	"github.com/williajm/curly/internal/domain"
+)
+
+// DumpRequest returns req in HTTP/1.1 wire format: the request line, every
+// header including authentication and those the transport adds, such as
+// Host and User-Agent, and the encoded body. It builds the request with
+// BuildRequest, as Execute does, and writes it through a transport, so it
+// shows exactly what Execute sends. Nothing is sent over the network.
+func DumpRequest(req *domain.Request) (string, error) {
+	httpReq, err := BuildRequest(context.Background(), req)
+	if err != nil {
+		return "", err
+	}
+
+	dump, err := httputil.DumpRequestOut(httpReq, true)
+	if err != nil {
+		return "", fmt.Errorf("failed to dump request: %w", err)
+	}
+	return string(dump), nil
+}
@@ -0,0 +1,45 @@
+package http
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/williajm/curly/internal/domain"
+)
+
+// TestDumpRequest verifies that the dump shows the request as it is sent.
+func TestDumpRequest(t *testing.T) {
+	req := domain.NewRequestWithMethodAndURL("POST", "https://api.example.com/users?page=2")
+	req.QueryParams = map[string]string{"sort": "name"}
+	req.Headers = map[string]string{"Content-Type": "application/json"}
+	req.AuthConfig = &domain.BearerAuth{Token: "secret-token"}
+	req.Body = `{"name":"Ada"}`
+
+	dump, err := DumpRequest(req)
+	if err != nil {
+		t.Fatalf("DumpRequest() error = %v", err)
+	}
+
+	want := []string{
+		"POST /users?page=2&sort=name HTTP/1.1\r\n",
+		"Host: api.example.com\r\n",
+		"Authorization: Bearer secret-token\r\n",
+		"Content-Type: application/json\r\n",
+		"Content-Length: 14\r\n",
+		"\r\n\r\n" + `{"name":"Ada"}`,
+	}
+	for _, w := range want {
+		if !strings.Contains(dump, w) {
+			t.Errorf("DumpRequest() = %q, want it to contain %q", dump, w)
+		}
+	}
+}
+
+// TestDumpRequest_Invalid verifies that invalid requests are not dumped.
+func TestDumpRequest_Invalid(t *testing.T) {
+	req := domain.NewRequestWithMethodAndURL("GET", "")
+
+	if _, err := DumpRequest(req); err == nil {
+		t.Error("DumpRequest() error = nil, want an error for a request without a URL")
+	}
+}
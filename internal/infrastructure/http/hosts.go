@@ -0,0 +1,171 @@
+package http
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/williajm/curly/internal/domain"
+)
+
+// Host holds settings for the requests sent to the hosts that match Pattern.
+type Host struct {
+	// Pattern is a hostname, such as "api.example.com", in which * matches
+	// any characters, so "*.internal.corp" matches every host in that domain.
+	// A pattern with a port, such as "localhost:8080", only matches that port.
+	Pattern string
+
+	// Headers are added to requests that do not set them.
+	Headers map[string]string
+
+	// Auth authenticates requests that have no authentication of their own.
+	Auth domain.AuthConfig
+
+	// Timeout replaces Config.Timeout when set.
+	Timeout time.Duration
+
+	// Proxy is the URL of a proxy to send requests through.
+	Proxy string
+
+	// InsecureSkipTLS disables TLS certificate verification.
+	InsecureSkipTLS bool
+
+	// CACert is a PEM file of certificate authorities trusted in addition to
+	// the system's.
+	CACert string
+
+	// ClientCert and ClientKey are PEM files of a certificate and its key,
+	// presented when the server asks for a client certificate.
+	ClientCert string
+	ClientKey  string
+}
+
+// Matches reports whether the host of u matches the pattern.
+func (h *Host) Matches(u *url.URL) bool {
+	pattern := strings.ToLower(h.Pattern)
+	host := strings.ToLower(u.Hostname())
+	if strings.Contains(pattern, ":") {
+		host = strings.ToLower(u.Host)
+	}
+	matched, err := path.Match(pattern, host)
+	return err == nil && matched
+}
+
+// hasTransport reports whether the host needs a transport of its own.
+func (h *Host) hasTransport() bool {
+	return h.Proxy != "" || h.InsecureSkipTLS || h.CACert != "" || h.ClientCert != ""
+}
+
+// hostRoute is a Host with the transport its requests are sent through, or
+// the error that prevented building it.
+type hostRoute struct {
+	*Host
+	transport http.RoundTripper
+	err       error
+}
+
+// newHostRoutes builds a route for each host, with its own transport if its
+// proxy or TLS settings differ from the client's.
+func newHostRoutes(config *Config) []*hostRoute {
+	routes := make([]*hostRoute, 0, len(config.Hosts))
+	for i := range config.Hosts {
+		route := &hostRoute{Host: &config.Hosts[i]}
+		if route.hasTransport() {
+			transport, err := newTransport(config, route.Host)
+			if err != nil {
+				route.err = fmt.Errorf("invalid settings for host %s: %w", route.Pattern, err)
+			} else {
+				route.transport = transport
+			}
+		}
+		routes = append(routes, route)
+	}
+	return routes
+}
+
+// matchHost returns the first route whose host matches u, or nil.
+func matchHost(routes []*hostRoute, u *url.URL) *hostRoute {
+	for _, route := range routes {
+		if route.Matches(u) {
+			return route
+		}
+	}
+	return nil
+}
+
+// apply adds the host's headers and authentication to httpReq, where req,
+// the request it was built from, does not set them.
+func (r *hostRoute) apply(httpReq *http.Request, req *domain.Request) error {
+	if r.err != nil {
+		return r.err
+	}
+	for name, value := range r.Headers {
+		if _, ok := httpReq.Header[http.CanonicalHeaderKey(name)]; !ok {
+			httpReq.Header.Set(name, value)
+		}
+	}
+	if r.Auth != nil && (req.AuthConfig == nil || req.AuthConfig.Type() == domain.AuthTypeNone) {
+		if err := r.Auth.Apply(httpReq); err != nil {
+			return fmt.Errorf("failed to apply authentication for host %s: %w", r.Pattern, err)
+		}
+	}
+	return nil
+}
+
+// hostTransport sends each request through the transport of the first host
+// that matches it, so the settings also apply to redirects to other hosts.
+type hostTransport struct {
+	routes   []*hostRoute
+	fallback http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *hostTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	route := matchHost(t.routes, req.URL)
+	switch {
+	case route == nil:
+		return t.fallback.RoundTrip(req)
+	case route.err != nil:
+		return nil, route.err
+	case route.transport != nil:
+		return route.transport.RoundTrip(req)
+	default:
+		return t.fallback.RoundTrip(req)
+	}
+}
+
+// hostTLSConfig returns the TLS configuration for host, loading its
+// certificate files.
+func hostTLSConfig(host *Host) (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		InsecureSkipVerify: host.InsecureSkipTLS, // #nosec G402 -- Intentionally allow insecure TLS for hosts the user configures
+	}
+	if host.CACert != "" {
+		pem, err := os.ReadFile(host.CACert) // #nosec G304 -- path is chosen by the user
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA certificate: %w", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in %s", host.CACert)
+		}
+		tlsConfig.RootCAs = pool
+	}
+	if host.ClientCert != "" {
+		cert, err := tls.LoadX509KeyPair(host.ClientCert, host.ClientKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	return tlsConfig, nil
+}
@@ -0,0 +1,174 @@
+package http
+
+import (
+	"context"
+	"encoding/pem"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/williajm/curly/internal/domain"
+)
+
+func TestHost_Matches(t *testing.T) {
+	tests := []struct {
+		pattern string
+		url     string
+		want    bool
+	}{
+		{"api.example.com", "https://api.example.com/users", true},
+		{"api.example.com", "https://API.Example.com", true},
+		{"api.example.com", "https://api.example.com:8443/", true},
+		{"api.example.com", "https://www.example.com/", false},
+		{"*.internal.corp", "http://billing.internal.corp/", true},
+		{"*.internal.corp", "http://eu.billing.internal.corp/", true},
+		{"*.internal.corp", "http://internal.corp/", false},
+		{"localhost:8080", "http://localhost:8080/health", true},
+		{"localhost:8080", "http://localhost:9090/health", false},
+		{"localhost:8080", "http://localhost/health", false},
+		{"*", "https://anything.example/", true},
+	}
+
+	for _, tt := range tests {
+		u, err := url.Parse(tt.url)
+		if err != nil {
+			t.Fatalf("failed to parse %s: %v", tt.url, err)
+		}
+		host := &Host{Pattern: tt.pattern}
+		if got := host.Matches(u); got != tt.want {
+			t.Errorf("Host{%q}.Matches(%s) = %v, want %v", tt.pattern, tt.url, got, tt.want)
+		}
+	}
+}
+
+func TestExecute_HostHeadersAndAuth(t *testing.T) {
+	var received http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r.Header.Clone()
+	}))
+	defer server.Close()
+
+	client := NewClient(&Config{
+		Timeout: 5 * time.Second,
+		Hosts: []Host{
+			{Pattern: "other.example"},
+			{
+				Pattern: "127.0.0.1",
+				Headers: map[string]string{"X-Team": "payments", "Accept": "application/json"},
+				Auth:    domain.NewBearerAuth("host-token"),
+			},
+			{Pattern: "*", Headers: map[string]string{"X-Fallback": "yes"}},
+		},
+	})
+
+	req := domain.NewRequestWithMethodAndURL(domain.MethodGet, server.URL)
+	req.Headers["Accept"] = "text/plain"
+	if _, err := client.Execute(context.Background(), req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := received.Get("X-Team"); got != "payments" {
+		t.Errorf("expected the host's header, got %q", got)
+	}
+	if got := received.Get("Accept"); got != "text/plain" {
+		t.Errorf("expected the request's own header to win, got %q", got)
+	}
+	if got := received.Get("Authorization"); got != "Bearer host-token" {
+		t.Errorf("expected the host's authentication, got %q", got)
+	}
+	if got := received.Get("X-Fallback"); got != "" {
+		t.Errorf("expected only the first matching host to apply, got %q", got)
+	}
+
+	req.SetAuth(domain.NewBasicAuth("user", "pass"))
+	if _, err := client.Execute(context.Background(), req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := received.Get("Authorization"); !strings.HasPrefix(got, "Basic ") {
+		t.Errorf("expected the request's own authentication, got %q", got)
+	}
+}
+
+func TestExecute_HostTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+	}))
+	defer server.Close()
+
+	client := NewClient(&Config{
+		Timeout: 5 * time.Second,
+		Hosts:   []Host{{Pattern: "127.0.0.1", Timeout: 20 * time.Millisecond}},
+	})
+	_, err := client.Execute(context.Background(), domain.NewRequestWithMethodAndURL(domain.MethodGet, server.URL))
+	if err == nil || !strings.Contains(err.Error(), "timeout") {
+		t.Errorf("expected the host's timeout to apply, got %v", err)
+	}
+}
+
+func TestExecute_HostTLS(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+	req := domain.NewRequestWithMethodAndURL(domain.MethodGet, server.URL)
+
+	// The test server's certificate is self-signed, so it is rejected unless
+	// a host setting trusts it.
+	if _, err := NewClient(&Config{Timeout: 5 * time.Second}).Execute(context.Background(), req); err == nil {
+		t.Fatal("expected the self-signed certificate to be rejected")
+	}
+
+	insecure := NewClient(&Config{
+		Timeout: 5 * time.Second,
+		Hosts:   []Host{{Pattern: "127.0.0.1", InsecureSkipTLS: true}},
+	})
+	if _, err := insecure.Execute(context.Background(), req); err != nil {
+		t.Errorf("expected insecure_skip_tls to apply, got %v", err)
+	}
+
+	caCert := filepath.Join(t.TempDir(), "ca.pem")
+	data := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	if err := os.WriteFile(caCert, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+	trusted := NewClient(&Config{
+		Timeout: 5 * time.Second,
+		Hosts:   []Host{{Pattern: "127.0.0.1", CACert: caCert}},
+	})
+	if _, err := trusted.Execute(context.Background(), req); err != nil {
+		t.Errorf("expected the CA certificate to be trusted, got %v", err)
+	}
+
+	missing := NewClient(&Config{
+		Timeout: 5 * time.Second,
+		Hosts:   []Host{{Pattern: "127.0.0.1", CACert: filepath.Join(t.TempDir(), "missing.pem")}},
+	})
+	_, err := missing.Execute(context.Background(), req)
+	if err == nil || !strings.Contains(err.Error(), "invalid settings for host 127.0.0.1") {
+		t.Errorf("expected the missing CA certificate to be reported, got %v", err)
+	}
+}
+
+func TestExecute_HostProxy(t *testing.T) {
+	var proxied string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = r.URL.String()
+		_, _ = io.WriteString(w, "from proxy")
+	}))
+	defer proxy.Close()
+
+	client := NewClient(&Config{
+		Timeout: 5 * time.Second,
+		Hosts:   []Host{{Pattern: "*.internal.corp", Proxy: proxy.URL}},
+	})
+	resp, err := client.Execute(context.Background(), domain.NewRequestWithMethodAndURL(domain.MethodGet, "http://billing.internal.corp/invoices"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.Body != "from proxy" || proxied != "http://billing.internal.corp/invoices" {
+		t.Errorf("expected the request to go through the proxy, got %q for %q", resp.Body, proxied)
+	}
+}
@@ -0,0 +1,84 @@
+package http
+
+import (
+	"context"
+	"io"
+	"time"
+)
+
+// Progress reports how much of a request or response body has been
+// transferred.
+type Progress struct {
+	// Upload is true while the request body is sent, false while the
+	// response body is received.
+	Upload bool
+
+	// Done is the number of bytes transferred so far.
+	Done int64
+
+	// Total is the size of the body, or -1 if the server did not say.
+	Total int64
+}
+
+// ProgressFunc is called with the progress of a transfer. It is called from
+// the goroutine executing the request and should return quickly.
+type ProgressFunc func(Progress)
+
+// progressInterval is how often a ProgressFunc is called while a body is
+// transferred.
+const progressInterval = 100 * time.Millisecond
+
+// progressKey is the context key of a ProgressFunc.
+type progressKey struct{}
+
+// WithProgress returns a context that makes Execute report the progress of
+// the request and response bodies to fn: at most every progressInterval,
+// and once more when a body has been transferred in full.
+func WithProgress(ctx context.Context, fn ProgressFunc) context.Context {
+	return context.WithValue(ctx, progressKey{}, fn)
+}
+
+// progressFrom returns the ProgressFunc of ctx, or nil if it has none.
+func progressFrom(ctx context.Context) ProgressFunc {
+	fn, _ := ctx.Value(progressKey{}).(ProgressFunc)
+	return fn
+}
+
+// progressReader counts the bytes read from a body and reports them.
+type progressReader struct {
+	io.ReadCloser
+	fn       ProgressFunc
+	upload   bool
+	total    int64
+	interval time.Duration
+
+	done     int64
+	reported time.Time
+	finished bool
+}
+
+// newProgressReader wraps body so that reading it reports progress to fn.
+func newProgressReader(body io.ReadCloser, fn ProgressFunc, upload bool, total int64) *progressReader {
+	return &progressReader{ReadCloser: body, fn: fn, upload: upload, total: total, interval: progressInterval}
+}
+
+func (r *progressReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+
+	r.done += int64(n)
+	now := time.Now()
+	report := false
+	switch {
+	case r.finished:
+	case err == io.EOF || (r.total >= 0 && r.done >= r.total):
+		r.finished = true
+		report = true
+	case now.Sub(r.reported) >= r.interval:
+		report = true
+	}
+	if report {
+		r.reported = now
+		r.fn(Progress{Upload: r.upload, Done: r.done, Total: r.total})
+	}
+	return n, err
+}
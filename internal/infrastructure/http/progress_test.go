@@ -0,0 +1,79 @@
+package http
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/williajm/curly/internal/domain"
+)
+
+func TestExecute_Progress(t *testing.T) {
+	download := strings.Repeat("d", 256*1024)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.Copy(io.Discard, r.Body)
+		_, _ = io.WriteString(w, download)
+	}))
+	defer server.Close()
+
+	var reports []Progress
+	ctx := WithProgress(context.Background(), func(p Progress) {
+		reports = append(reports, p)
+	})
+	req := domain.NewRequestWithMethodAndURL(domain.MethodPost, server.URL)
+	req.Body = strings.Repeat("u", 64*1024)
+
+	client := NewClient(&Config{Timeout: 5 * time.Second})
+	if _, err := client.Execute(ctx, req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// The upload is reported in full, then the download.
+	var upload, downloaded *Progress
+	for i := range reports {
+		if reports[i].Upload {
+			if downloaded != nil {
+				t.Fatalf("upload reported after the download began: %+v", reports)
+			}
+			upload = &reports[i]
+		} else {
+			downloaded = &reports[i]
+		}
+	}
+	if upload == nil || upload.Done != int64(len(req.Body)) || upload.Total != int64(len(req.Body)) {
+		t.Errorf("unexpected final upload progress %+v", upload)
+	}
+	if downloaded == nil || downloaded.Done != int64(len(download)) {
+		t.Errorf("unexpected final download progress %+v", downloaded)
+	}
+}
+
+func TestProgressReader(t *testing.T) {
+	var reports []Progress
+	r := newProgressReader(io.NopCloser(strings.NewReader("abcdef")), func(p Progress) {
+		reports = append(reports, p)
+	}, false, -1)
+	r.interval = time.Hour
+
+	buf := make([]byte, 2)
+	for {
+		if _, err := r.Read(buf); err != nil {
+			break
+		}
+	}
+
+	// The first read is reported, the next ones only once the body ends.
+	want := []Progress{{Done: 2, Total: -1}, {Done: 6, Total: -1}}
+	if len(reports) != len(want) {
+		t.Fatalf("expected %d reports, got %+v", len(want), reports)
+	}
+	for i := range want {
+		if reports[i] != want[i] {
+			t.Errorf("report %d = %+v, want %+v", i, reports[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package http
+
+import (
+	"context"
+	"crypto/tls"
+	"net/http/httptrace"
+	"sync"
+	"time"
+
+	"github.com/williajm/curly/internal/domain"
+)
+
+// timingTrace records when the phases of a request start and end, through
+// the hooks of an httptrace.ClientTrace. The hooks are called from the
+// transport's goroutines.
+type timingTrace struct {
+	mu sync.Mutex
+	traceTimes
+}
+
+// traceTimes are the times a timingTrace records.
+type traceTimes struct {
+	dnsStart, dnsDone         time.Time
+	connectStart, connectDone time.Time
+	tlsStart, tlsDone         time.Time
+	gotConn, firstByte        time.Time
+	reused                    bool
+}
+
+// withTimingTrace returns a context that records the timing of the request
+// sent with it to t.
+func (t *timingTrace) withTimingTrace(ctx context.Context) context.Context {
+	return httptrace.WithClientTrace(ctx, &httptrace.ClientTrace{
+		// Each request of a redirect chain starts over.
+		GetConn: func(string) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.traceTimes = traceTimes{}
+		},
+		DNSStart: func(httptrace.DNSStartInfo) { t.mark(&t.dnsStart) },
+		DNSDone:  func(httptrace.DNSDoneInfo) { t.mark(&t.dnsDone) },
+		ConnectStart: func(string, string) {
+			// Several addresses may be tried; the first attempt starts the
+			// phase and the last to finish ends it.
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			if t.connectStart.IsZero() {
+				t.connectStart = time.Now()
+			}
+		},
+		ConnectDone:          func(string, string, error) { t.mark(&t.connectDone) },
+		TLSHandshakeStart:    func() { t.mark(&t.tlsStart) },
+		TLSHandshakeDone:     func(tls.ConnectionState, error) { t.mark(&t.tlsDone) },
+		GotFirstResponseByte: func() { t.mark(&t.firstByte) },
+		GotConn: func(info httptrace.GotConnInfo) {
+			t.mark(&t.gotConn)
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.reused = info.Reused
+		},
+	})
+}
+
+// mark records the current time in field.
+func (t *timingTrace) mark(field *time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	*field = time.Now()
+}
+
+// timing returns the phases recorded, for a response whose body was read by
+// bodyDone, or nil if the request never reached the network, as with a
+// custom transport.
+func (t *timingTrace) timing(bodyDone time.Time) *domain.Timing {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.gotConn.IsZero() || t.firstByte.IsZero() {
+		return nil
+	}
+	return &domain.Timing{
+		DNS:              between(t.dnsStart, t.dnsDone),
+		Connect:          between(t.connectStart, t.connectDone),
+		TLS:              between(t.tlsStart, t.tlsDone),
+		Wait:             between(t.gotConn, t.firstByte),
+		Download:         between(t.firstByte, bodyDone),
+		ReusedConnection: t.reused,
+	}
+}
+
+// between returns the time from start to end, or zero if either is unknown.
+func between(start, end time.Time) time.Duration {
+	if start.IsZero() || end.IsZero() || end.Before(start) {
+		return 0
+	}
+	return end.Sub(start)
+}
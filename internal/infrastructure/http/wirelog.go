@@ -0,0 +1,257 @@
+package http
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+	"unicode/utf8"
+
+	"github.com/williajm/curly/internal/domain"
+)
+
+// WireLog returns middleware that writes every request and response passing
+// through the transport to w, in the manner of curl -v: lines starting with
+// "> " are sent, "< " received and "* " are notes. Bodies are cut to
+// maxBody bytes, or left out when it is 0. The values of headers, query
+// parameters, and JSON and form body fields that carry credentials are
+// masked, so OAuth token exchanges can be logged.
+//
+// Each exchange is numbered, and its request, response and response body are
+// each written at once, so exchanges running concurrently stay readable. The
+// response body is written as the caller reads it, when it is read to the end
+// or closed, so streamed responses are not held back.
+func WireLog(w io.Writer, maxBody int) Middleware {
+	log := &wireLog{w: w, maxBody: maxBody}
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			id := log.seq.Add(1)
+			log.request(id, req)
+
+			start := time.Now()
+			resp, err := next.RoundTrip(req)
+			if err != nil {
+				log.write("* #%d failed after %s: %v\n", id, time.Since(start).Round(time.Millisecond), err)
+				return nil, err
+			}
+			log.response(id, resp, time.Since(start))
+			if resp.Body != nil && resp.Body != http.NoBody {
+				resp.Body = &wireBody{ReadCloser: resp.Body, log: log, id: id, contentType: resp.Header.Get("Content-Type")}
+			}
+			return resp, nil
+		})
+	}
+}
+
+// wireLog is the destination of WireLog.
+type wireLog struct {
+	mu      sync.Mutex
+	w       io.Writer
+	maxBody int
+	seq     atomic.Int64
+}
+
+// write writes one block of lines to the log. Write errors are ignored: the
+// log must not fail requests.
+func (l *wireLog) write(format string, args ...any) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	_, _ = fmt.Fprintf(l.w, format, args...)
+}
+
+// request logs the request line, headers and body of req.
+func (l *wireLog) request(id int64, req *http.Request) {
+	var b strings.Builder
+	// Credentials in the URL are left out; the client sends them as an
+	// Authorization header, which is masked.
+	redacted := *req.URL
+	redacted.User = nil
+	redacted.RawQuery = redactQuery(req.URL.RawQuery)
+	fmt.Fprintf(&b, "* #%d %s %s\n", id, time.Now().Format(time.RFC3339), redacted.String())
+	fmt.Fprintf(&b, "> %s %s %s\n", req.Method, redacted.RequestURI(), req.Proto)
+	host := req.Host
+	if host == "" {
+		host = req.URL.Host
+	}
+	fmt.Fprintf(&b, "> Host: %s\n", host)
+	headers := req.Header.Clone()
+	if req.ContentLength > 0 && headers.Get("Content-Length") == "" {
+		headers.Set("Content-Length", fmt.Sprint(req.ContentLength))
+	}
+	writeHeaders(&b, "> ", headers)
+
+	if req.Body != nil && req.Body != http.NoBody && l.maxBody > 0 {
+		if req.GetBody == nil {
+			b.WriteString("* request body not shown: it can only be read once\n")
+		} else if body, err := req.GetBody(); err != nil {
+			fmt.Fprintf(&b, "* request body not shown: %v\n", err)
+		} else {
+			data, _ := io.ReadAll(io.LimitReader(body, int64(l.maxBody)+1))
+			_ = body.Close()
+			total := req.ContentLength
+			if total < 0 {
+				total = int64(len(data))
+			}
+			l.writeBody(&b, "> ", data, total, req.Header.Get("Content-Type"))
+		}
+	}
+	l.write("%s", b.String())
+}
+
+// response logs the status line and headers of resp.
+func (l *wireLog) response(id int64, resp *http.Response, elapsed time.Duration) {
+	var b strings.Builder
+	fmt.Fprintf(&b, "* #%d response after %s\n", id, elapsed.Round(time.Millisecond))
+	fmt.Fprintf(&b, "< %s %s\n", resp.Proto, resp.Status)
+	writeHeaders(&b, "< ", resp.Header)
+	l.write("%s", b.String())
+}
+
+// writeBody writes the start of a body of total bytes to b, at most maxBody
+// bytes of it, with prefix on every line. Credentials in the body are masked
+// according to its contentType.
+func (l *wireLog) writeBody(b *strings.Builder, prefix string, data []byte, total int64, contentType string) {
+	if len(data) > l.maxBody {
+		data = data[:l.maxBody]
+	}
+	if !isText(data) {
+		fmt.Fprintf(b, "* %d bytes of binary data not shown\n", total)
+		return
+	}
+	text := redactBody(string(bytes.ToValidUTF8(data, nil)), contentType)
+	b.WriteString(prefix + "\n")
+	for _, line := range strings.Split(strings.TrimSuffix(text, "\n"), "\n") {
+		b.WriteString(prefix + strings.TrimSuffix(line, "\r") + "\n")
+	}
+	if rest := total - int64(len(data)); rest > 0 {
+		fmt.Fprintf(b, "* %d more bytes not shown\n", rest)
+	}
+}
+
+// isText reports whether data looks like text: valid UTF-8, apart from a
+// character cut off at the end, without NUL bytes.
+func isText(data []byte) bool {
+	if bytes.IndexByte(data, 0) >= 0 {
+		return false
+	}
+	for i := 0; i < utf8.UTFMax-1 && len(data) > 0 && !utf8.Valid(data); i++ {
+		data = data[:len(data)-1]
+	}
+	return utf8.Valid(data)
+}
+
+// writeHeaders writes headers to b sorted by name, one per line with prefix,
+// masking those that carry credentials.
+func writeHeaders(b *strings.Builder, prefix string, headers http.Header) {
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		for _, value := range headers[name] {
+			if domain.IsSecretName(name) || strings.EqualFold(name, "Set-Cookie") {
+				value = domain.SecretMask
+			}
+			fmt.Fprintf(b, "%s%s: %s\n", prefix, name, value)
+		}
+	}
+}
+
+// redactQuery returns the encoded query with the values of parameters whose
+// names suggest credentials, such as api_key or token, masked. The query
+// keeps its order and encoding otherwise.
+func redactQuery(query string) string {
+	if query == "" {
+		return query
+	}
+	params := strings.Split(query, "&")
+	for i, param := range params {
+		raw, _, found := strings.Cut(param, "=")
+		name, err := url.QueryUnescape(raw)
+		if err != nil {
+			name = raw
+		}
+		if found && domain.IsSecretName(name) {
+			params[i] = raw + "=" + domain.SecretMask
+		}
+	}
+	return strings.Join(params, "&")
+}
+
+// jsonMember matches a member of a JSON object whose value is a string or
+// another scalar. A string cut off at the end of a shown body still matches,
+// so the start of a secret is not shown either.
+var jsonMember = regexp.MustCompile(`"((?:[^"\\]|\\.)*)"(\s*:\s*)("(?:[^"\\]|\\.)*(?:"|$)|[-+.\w]+)`)
+
+// redactBody returns body with the values of JSON members and form fields
+// whose names suggest credentials, such as access_token or client_secret,
+// masked. Other bodies are returned unchanged.
+func redactBody(body, contentType string) string {
+	mediaType, _, _ := strings.Cut(strings.ToLower(contentType), ";")
+	mediaType = strings.TrimSpace(mediaType)
+	switch {
+	case mediaType == "application/x-www-form-urlencoded":
+		return redactQuery(body)
+	case strings.HasSuffix(mediaType, "json"):
+		return jsonMember.ReplaceAllStringFunc(body, func(member string) string {
+			m := jsonMember.FindStringSubmatch(member)
+			if !domain.IsSecretName(m[1]) {
+				return member
+			}
+			return `"` + m[1] + `"` + m[2] + `"` + domain.SecretMask + `"`
+		})
+	}
+	return body
+}
+
+// wireBody logs a response body once it has been read to the end or closed.
+type wireBody struct {
+	io.ReadCloser
+	log         *wireLog
+	id          int64
+	contentType string
+	data        []byte
+	total       int64
+	logged      bool
+}
+
+// Read reads from the body, keeping the start of it for the log.
+func (b *wireBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.total += int64(n)
+	if keep := b.log.maxBody + 1 - len(b.data); keep > 0 {
+		b.data = append(b.data, p[:min(n, keep)]...)
+	}
+	if err == io.EOF {
+		b.flush("")
+	}
+	return n, err
+}
+
+// Close closes the body, logging it if it was not read to the end.
+func (b *wireBody) Close() error {
+	b.flush(" (closed before the end)")
+	return b.ReadCloser.Close()
+}
+
+// flush logs the body read so far, once.
+func (b *wireBody) flush(note string) {
+	if b.logged {
+		return
+	}
+	b.logged = true
+	var out strings.Builder
+	fmt.Fprintf(&out, "* #%d response body, %d bytes%s\n", b.id, b.total, note)
+	if b.log.maxBody > 0 && b.total > 0 {
+		b.log.writeBody(&out, "< ", b.data, b.total, b.contentType)
+	}
+	b.log.write("%s", out.String())
+}
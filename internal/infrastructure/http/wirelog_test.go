@@ -0,0 +1,198 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/williajm/curly/internal/domain"
+)
+
+func TestWireLog(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Set-Cookie", "session=abc123")
+		_, _ = io.WriteString(w, `{"id":1,"name":"widget"}`)
+	}))
+	defer server.Close()
+
+	var log bytes.Buffer
+	client := NewClient(nil, WithMiddleware(WireLog(&log, 10)))
+
+	req := domain.NewRequestWithMethodAndURL(domain.MethodPost, server.URL+"/items?page=2&api_key=k3y")
+	req.Headers["Content-Type"] = "application/json"
+	req.Headers["X-Api-Key"] = "s3cret"
+	req.Body = `{"name":"widget"}`
+	resp, err := client.Execute(context.Background(), req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.Body != `{"id":1,"name":"widget"}` {
+		t.Errorf("expected the whole body to reach the caller, got %q", resp.Body)
+	}
+
+	got := log.String()
+	for _, want := range []string{
+		"> POST /items?page=2&api_key=" + domain.SecretMask + " HTTP/1.1\n",
+		"> Host: " + strings.TrimPrefix(server.URL, "http://") + "\n",
+		"> Content-Type: application/json\n",
+		"> X-Api-Key: " + domain.SecretMask + "\n",
+		"> {\"name\":\"w\n* 7 more bytes not shown\n",
+		"< HTTP/1.1 200 OK\n",
+		"< Set-Cookie: " + domain.SecretMask + "\n",
+		"* #1 response body, 24 bytes\n",
+		"< {\"id\":1,\"n\n* 14 more bytes not shown\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected the log to contain %q, got:\n%s", want, got)
+		}
+	}
+	for _, secret := range []string{"s3cret", "k3y", "abc123"} {
+		if strings.Contains(got, secret) {
+			t.Errorf("expected %q to be masked, got:\n%s", secret, got)
+		}
+	}
+}
+
+func TestWireLog_URLCredentials(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	var log bytes.Buffer
+	client := NewClient(nil, WithMiddleware(WireLog(&log, 10)))
+
+	url := strings.Replace(server.URL, "http://", "http://alice:hunter2@", 1) + "/x?api_key=k3y"
+	if _, err := client.Execute(context.Background(), domain.NewRequestWithMethodAndURL(domain.MethodGet, url)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	got := log.String()
+	if want := server.URL + "/x?api_key=" + domain.SecretMask + "\n"; !strings.Contains(got, want) {
+		t.Errorf("expected the log to contain %q, got:\n%s", want, got)
+	}
+	for _, secret := range []string{"alice", "hunter2", "k3y"} {
+		if strings.Contains(got, secret) {
+			t.Errorf("expected %q to be left out, got:\n%s", secret, got)
+		}
+	}
+}
+
+func TestWireLog_TokenExchange(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		_, _ = io.WriteString(w, `{"access_token":"at-s3cret","token_type":"Bearer","expires_in":3600,"refresh_token":"rt-s3cret"}`)
+	}))
+	defer server.Close()
+
+	var log bytes.Buffer
+	client := NewClient(nil, WithMiddleware(WireLog(&log, 1000)))
+
+	req := domain.NewRequestWithMethodAndURL(domain.MethodPost, server.URL+"/token")
+	req.Headers["Content-Type"] = "application/x-www-form-urlencoded"
+	req.Body = "grant_type=refresh_token&refresh_token=rt-old&client_id=app&client_secret=cs-s3cret"
+	if _, err := client.Execute(context.Background(), req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	got := log.String()
+	for _, want := range []string{
+		"> grant_type=refresh_token&refresh_token=" + domain.SecretMask + "&client_id=app&client_secret=" + domain.SecretMask + "\n",
+		`< {"access_token":"` + domain.SecretMask + `","token_type":"` + domain.SecretMask + `","expires_in":3600,"refresh_token":"` + domain.SecretMask + `"}` + "\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected the log to contain %q, got:\n%s", want, got)
+		}
+	}
+	for _, secret := range []string{"rt-old", "cs-s3cret", "at-s3cret", "rt-s3cret"} {
+		if strings.Contains(got, secret) {
+			t.Errorf("expected %q to be masked, got:\n%s", secret, got)
+		}
+	}
+}
+
+func TestRedactBody(t *testing.T) {
+	mask := domain.SecretMask
+	tests := []struct {
+		name        string
+		body        string
+		contentType string
+		want        string
+	}{
+		{"json", `{"token": "abc", "name": "x"}`, "application/json", `{"token": "` + mask + `", "name": "x"}`},
+		{"nested json", `{"auth":{"password":"p\"w","user":"u"}}`, "application/vnd.api+json", `{"auth":{"password":"` + mask + `","user":"u"}}`},
+		{"json number", `{"api_key":12345}`, "application/json", `{"api_key":"` + mask + `"}`},
+		{"json cut off", `{"id":1,"access_token":"abcd`, "application/json", `{"id":1,"access_token":"` + mask + `"`},
+		{"form", "user=u&password=p", "application/x-www-form-urlencoded; charset=utf-8", "user=u&password=" + mask},
+		{"text", `"token":"abc"`, "text/plain", `"token":"abc"`},
+	}
+
+	for _, tt := range tests {
+		if got := redactBody(tt.body, tt.contentType); got != tt.want {
+			t.Errorf("%s: redactBody(%q) = %q, want %q", tt.name, tt.body, got, tt.want)
+		}
+	}
+}
+
+func TestWireLog_NoBodies(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, "hello")
+	}))
+	defer server.Close()
+
+	var log bytes.Buffer
+	client := NewClient(nil, WithMiddleware(WireLog(&log, 0)))
+
+	req := domain.NewRequestWithMethodAndURL(domain.MethodPut, server.URL)
+	req.Body = "payload"
+	if _, err := client.Execute(context.Background(), req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	got := log.String()
+	if strings.Contains(got, "payload") || strings.Contains(got, "hello") {
+		t.Errorf("expected bodies to be left out, got:\n%s", got)
+	}
+	if !strings.Contains(got, "* #1 response body, 5 bytes\n") {
+		t.Errorf("expected the body size to be noted, got:\n%s", got)
+	}
+}
+
+func TestWireLog_Failure(t *testing.T) {
+	var log bytes.Buffer
+	failing := RoundTripperFunc(func(*http.Request) (*http.Response, error) {
+		return nil, io.ErrUnexpectedEOF
+	})
+	client := NewClient(nil, WithTransport(failing), WithMiddleware(WireLog(&log, 100)))
+
+	req := domain.NewRequestWithMethodAndURL(domain.MethodGet, "http://example.invalid/")
+	if _, err := client.Execute(context.Background(), req); err == nil {
+		t.Fatal("expected an error")
+	}
+	if !strings.Contains(log.String(), "* #1 failed after") {
+		t.Errorf("expected the failure to be logged, got:\n%s", log.String())
+	}
+}
+
+func TestIsText(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		want bool
+	}{
+		{"ascii", []byte("hello"), true},
+		{"utf-8", []byte("héllo"), true},
+		{"cut character", []byte("h\xc3"), true},
+		{"nul", []byte("a\x00b"), false},
+		{"binary", []byte{0xff, 0xfe, 0x41, 0x42, 0x43, 0x44}, false},
+	}
+
+	for _, tt := range tests {
+		if got := isText(tt.data); got != tt.want {
+			t.Errorf("%s: isText(%q) = %v, want %v", tt.name, tt.data, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,245 @@
+// Package imagepreview renders images for display in the terminal.
+//
+// Terminals that speak a graphics protocol (kitty, iTerm2 or sixel) are sent
+// the image itself; any other terminal gets an approximation drawn with
+// Unicode half blocks in 24-bit color.
+package imagepreview
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/gif"  // Register the GIF decoder.
+	_ "image/jpeg" // Register the JPEG decoder.
+	"image/png"
+	"strings"
+)
+
+// Protocol is a way of showing images in the terminal.
+type Protocol string
+
+// Protocols. ProtocolAuto chooses one with Detect.
+const (
+	ProtocolAuto   Protocol = "auto"
+	ProtocolKitty  Protocol = "kitty"
+	ProtocolITerm  Protocol = "iterm"
+	ProtocolSixel  Protocol = "sixel"
+	ProtocolBlocks Protocol = "blocks"
+	ProtocolOff    Protocol = "off"
+)
+
+// Protocols lists the protocols that can be configured.
+var Protocols = []Protocol{ProtocolAuto, ProtocolKitty, ProtocolITerm, ProtocolSixel, ProtocolBlocks, ProtocolOff}
+
+// Cell size in pixels assumed for the sixel protocol, which draws in pixels
+// rather than cells.
+const (
+	cellWidth  = 10
+	cellHeight = 20
+)
+
+// KittyClear is the kitty graphics escape sequence that deletes the images
+// on screen, which otherwise stay when the text around them is redrawn.
+const KittyClear = "\x1b_Ga=d,q=2\x1b\\"
+
+// kittyChunkSize is the most base64 data sent in one kitty escape sequence.
+const kittyChunkSize = 4096
+
+// ParseProtocol returns the protocol named s, ignoring case; the empty
+// string is ProtocolAuto.
+func ParseProtocol(s string) (Protocol, error) {
+	if s == "" {
+		return ProtocolAuto, nil
+	}
+	for _, p := range Protocols {
+		if strings.EqualFold(s, string(p)) {
+			return p, nil
+		}
+	}
+	return "", fmt.Errorf("unknown image preview %q (want one of %s)", s, joinProtocols())
+}
+
+// joinProtocols lists the protocols for error messages.
+func joinProtocols() string {
+	names := make([]string, len(Protocols))
+	for i, p := range Protocols {
+		names[i] = string(p)
+	}
+	return strings.Join(names, ", ")
+}
+
+// Detect chooses the protocol of the terminal described by the environment
+// variables getenv returns. Inside tmux, which does not pass graphics on by
+// default, and in terminals it does not recognize, it chooses ProtocolBlocks.
+func Detect(getenv func(string) string) Protocol {
+	if getenv("TMUX") != "" {
+		return ProtocolBlocks
+	}
+
+	term := getenv("TERM")
+	program := getenv("TERM_PROGRAM")
+	switch {
+	case getenv("KITTY_WINDOW_ID") != "", term == "xterm-kitty", term == "xterm-ghostty", program == "ghostty":
+		return ProtocolKitty
+	case program == "iTerm.app", program == "WezTerm", getenv("LC_TERMINAL") == "iTerm2":
+		return ProtocolITerm
+	case strings.Contains(term, "sixel"), term == "foot", strings.HasPrefix(term, "mlterm"):
+		return ProtocolSixel
+	}
+	return ProtocolBlocks
+}
+
+// Image is a decoded image.
+type Image struct {
+	// Format is the image format, such as "png".
+	Format string
+
+	img image.Image
+}
+
+// Decode decodes a PNG, JPEG or GIF image.
+func Decode(data string) (*Image, error) {
+	img, format, err := image.Decode(strings.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	return &Image{Format: format, img: img}, nil
+}
+
+// Width returns the width of the image in pixels.
+func (i *Image) Width() int {
+	return i.img.Bounds().Dx()
+}
+
+// Height returns the height of the image in pixels.
+func (i *Image) Height() int {
+	return i.img.Bounds().Dy()
+}
+
+// Render returns the lines that show the image with protocol p, scaled to
+// fit cols by rows cells and keeping its aspect ratio. Graphics protocols
+// draw the image from the first line, and the lines after it are left blank
+// to hold the space. ProtocolOff and ProtocolAuto render nothing.
+func (i *Image) Render(p Protocol, cols, rows int) []string {
+	cols, rows = i.fit(cols, rows)
+	if cols == 0 || rows == 0 {
+		return nil
+	}
+
+	var first string
+	switch p {
+	case ProtocolBlocks:
+		return i.renderBlocks(cols, rows)
+	case ProtocolKitty:
+		first = i.renderKitty(cols, rows)
+	case ProtocolITerm:
+		first = i.renderITerm(cols, rows)
+	case ProtocolSixel:
+		first = i.renderSixel(cols, rows)
+	default:
+		return nil
+	}
+
+	lines := make([]string, rows)
+	lines[0] = first
+	return lines
+}
+
+// fit returns the size in cells, at most cols by rows, that shows the image
+// at its aspect ratio without enlarging it. A cell is taken to be twice as
+// tall as it is wide.
+func (i *Image) fit(cols, rows int) (int, int) {
+	w, h := i.Width(), i.Height()
+	if w == 0 || h == 0 || cols <= 0 || rows <= 0 {
+		return 0, 0
+	}
+
+	// The natural size, with one cell for cellWidth by cellHeight pixels.
+	fitCols := min(cols, max(w/cellWidth, 1))
+	fitRows := max(fitCols*h*cellWidth/(w*cellHeight), 1)
+	if fitRows > rows {
+		fitRows = rows
+		fitCols = max(fitRows*w*cellHeight/(h*cellWidth), 1)
+	}
+	return min(fitCols, cols), fitRows
+}
+
+// scaled returns the image resized to w by h pixels, by nearest neighbor.
+func (i *Image) scaled(w, h int) *image.RGBA {
+	src := i.img.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := range h {
+		sy := src.Min.Y + y*src.Dy()/h
+		for x := range w {
+			sx := src.Min.X + x*src.Dx()/w
+			dst.Set(x, y, i.img.At(sx, sy))
+		}
+	}
+	return dst
+}
+
+// renderBlocks draws the image with the upper half block, its foreground
+// the upper pixel and its background the lower one.
+func (i *Image) renderBlocks(cols, rows int) []string {
+	img := i.scaled(cols, rows*2)
+	lines := make([]string, rows)
+	for row := range rows {
+		var b strings.Builder
+		for x := range cols {
+			top := opaque(img.RGBAAt(x, row*2))
+			bottom := opaque(img.RGBAAt(x, row*2+1))
+			fmt.Fprintf(&b, "\x1b[38;2;%d;%d;%dm\x1b[48;2;%d;%d;%dm▀",
+				top.R, top.G, top.B, bottom.R, bottom.G, bottom.B)
+		}
+		b.WriteString("\x1b[0m")
+		lines[row] = b.String()
+	}
+	return lines
+}
+
+// opaque blends c onto a black background.
+func opaque(c color.RGBA) color.RGBA {
+	// RGBA values are alpha-premultiplied, so this is the blend.
+	return color.RGBA{R: c.R, G: c.G, B: c.B, A: 0xff}
+}
+
+// encodePNG returns the image as PNG, resized to w by h pixels.
+func (i *Image) encodePNG(w, h int) []byte {
+	var buf bytes.Buffer
+	// Encoding an in-memory RGBA image does not fail.
+	_ = png.Encode(&buf, i.scaled(w, h))
+	return buf.Bytes()
+}
+
+// renderKitty returns the kitty graphics escape sequences that show the
+// image over cols by rows cells without moving the cursor. Any image shown
+// before is deleted first, and the terminal is asked not to reply.
+func (i *Image) renderKitty(cols, rows int) string {
+	data := base64.StdEncoding.EncodeToString(i.encodePNG(cols*cellWidth, rows*cellHeight))
+
+	var b strings.Builder
+	b.WriteString(KittyClear)
+	for start := 0; start < len(data); start += kittyChunkSize {
+		end := min(start+kittyChunkSize, len(data))
+		more := 0
+		if end < len(data) {
+			more = 1
+		}
+		if start == 0 {
+			fmt.Fprintf(&b, "\x1b_Ga=T,f=100,q=2,C=1,c=%d,r=%d,m=%d;%s\x1b\\", cols, rows, more, data[start:end])
+		} else {
+			fmt.Fprintf(&b, "\x1b_Gm=%d;%s\x1b\\", more, data[start:end])
+		}
+	}
+	return b.String()
+}
+
+// renderITerm returns the iTerm2 inline image escape sequence that shows the
+// image over cols by rows cells.
+func (i *Image) renderITerm(cols, rows int) string {
+	data := i.encodePNG(cols*cellWidth, rows*cellHeight)
+	return fmt.Sprintf("\x1b]1337;File=inline=1;size=%d;width=%d;height=%d;preserveAspectRatio=1:%s\a",
+		len(data), cols, rows, base64.StdEncoding.EncodeToString(data))
+}
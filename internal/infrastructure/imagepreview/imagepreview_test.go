@@ -0,0 +1,118 @@
+package imagepreview
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testPNG returns a w by h PNG, red on top and blue below.
+func testPNG(t *testing.T, w, h int) string {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := range h {
+		for x := range w {
+			c := color.RGBA{R: 0xff, A: 0xff}
+			if y >= h/2 {
+				c = color.RGBA{B: 0xff, A: 0xff}
+			}
+			img.Set(x, y, c)
+		}
+	}
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, img))
+	return buf.String()
+}
+
+func TestParseProtocol(t *testing.T) {
+	p, err := ParseProtocol("Kitty")
+	require.NoError(t, err)
+	assert.Equal(t, ProtocolKitty, p)
+
+	p, err = ParseProtocol("")
+	require.NoError(t, err)
+	assert.Equal(t, ProtocolAuto, p)
+
+	_, err = ParseProtocol("ascii")
+	assert.ErrorContains(t, err, "unknown image preview")
+}
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		name string
+		env  map[string]string
+		want Protocol
+	}{
+		{name: "kitty", env: map[string]string{"TERM": "xterm-kitty"}, want: ProtocolKitty},
+		{name: "iterm", env: map[string]string{"TERM_PROGRAM": "iTerm.app"}, want: ProtocolITerm},
+		{name: "wezterm", env: map[string]string{"TERM_PROGRAM": "WezTerm"}, want: ProtocolITerm},
+		{name: "foot", env: map[string]string{"TERM": "foot"}, want: ProtocolSixel},
+		{name: "tmux", env: map[string]string{"TERM": "xterm-kitty", "TMUX": "/tmp/tmux"}, want: ProtocolBlocks},
+		{name: "unknown", env: map[string]string{"TERM": "xterm-256color"}, want: ProtocolBlocks},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Detect(func(key string) string { return tt.env[key] })
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestDecode(t *testing.T) {
+	img, err := Decode(testPNG(t, 40, 20))
+	require.NoError(t, err)
+	assert.Equal(t, "png", img.Format)
+	assert.Equal(t, 40, img.Width())
+	assert.Equal(t, 20, img.Height())
+
+	_, err = Decode("not an image")
+	assert.Error(t, err)
+}
+
+func TestRender_Blocks(t *testing.T) {
+	img, err := Decode(testPNG(t, 200, 200))
+	require.NoError(t, err)
+
+	// A square image is half as many cells tall as wide.
+	lines := img.Render(ProtocolBlocks, 10, 50)
+	require.Len(t, lines, 5)
+	assert.Equal(t, 10, strings.Count(lines[0], "▀"))
+	assert.Contains(t, lines[0], "\x1b[38;2;255;0;0m\x1b[48;2;255;0;0m▀")
+	assert.Contains(t, lines[4], "\x1b[38;2;0;0;255m\x1b[48;2;0;0;255m▀")
+
+	// The height limits the width too.
+	lines = img.Render(ProtocolBlocks, 100, 4)
+	require.Len(t, lines, 4)
+	assert.Equal(t, 8, strings.Count(lines[0], "▀"))
+
+	// Images are not enlarged.
+	small, err := Decode(testPNG(t, 20, 20))
+	require.NoError(t, err)
+	assert.Len(t, small.Render(ProtocolBlocks, 80, 40), 1)
+}
+
+func TestRender_Graphics(t *testing.T) {
+	img, err := Decode(testPNG(t, 200, 200))
+	require.NoError(t, err)
+
+	lines := img.Render(ProtocolKitty, 10, 50)
+	require.Len(t, lines, 5)
+	assert.True(t, strings.HasPrefix(lines[0], "\x1b_Ga=d,q=2\x1b\\\x1b_Ga=T,f=100,q=2,C=1,c=10,r=5,"))
+	assert.Empty(t, lines[1])
+
+	lines = img.Render(ProtocolITerm, 10, 50)
+	assert.True(t, strings.HasPrefix(lines[0], "\x1b]1337;File=inline=1;"))
+	assert.Contains(t, lines[0], "width=10;height=5;")
+
+	lines = img.Render(ProtocolSixel, 10, 50)
+	assert.True(t, strings.HasPrefix(lines[0], "\x1bPq\"1;1;100;100#5;2;0;0;100#180;2;100;0;0"))
+	assert.True(t, strings.HasSuffix(lines[0], "\x1b\\"))
+
+	assert.Empty(t, img.Render(ProtocolOff, 10, 50))
+}
@@ -0,0 +1,105 @@
+package imagepreview
+
+import (
+	"fmt"
+	"strings"
+)
+
+// sixelLevels is the number of levels of each primary in the sixel palette,
+// a color cube of sixelLevels³ colors.
+const sixelLevels = 6
+
+// renderSixel returns the sixel escape sequence that draws the image over
+// cols by rows cells, in colors from a 6×6×6 color cube.
+func (i *Image) renderSixel(cols, rows int) string {
+	w, h := cols*cellWidth, rows*cellHeight
+	img := i.scaled(w, h)
+
+	// Map each pixel to its color in the cube.
+	pixels := make([]int, w*h)
+	used := make([]bool, sixelLevels*sixelLevels*sixelLevels)
+	for y := range h {
+		for x := range w {
+			c := opaque(img.RGBAAt(x, y))
+			index := (level(c.R)*sixelLevels+level(c.G))*sixelLevels + level(c.B)
+			pixels[y*w+x] = index
+			used[index] = true
+		}
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "\x1bPq\"1;1;%d;%d", w, h)
+	for index, ok := range used {
+		if !ok {
+			continue
+		}
+		r, g, bl := index/(sixelLevels*sixelLevels), index/sixelLevels%sixelLevels, index%sixelLevels
+		fmt.Fprintf(&b, "#%d;2;%d;%d;%d", index, percent(r), percent(g), percent(bl))
+	}
+
+	// Each band of six rows is drawn once per color in it.
+	row := make([]byte, w)
+	for top := 0; top < h; top += 6 {
+		inBand := make([]bool, len(used))
+		for y := top; y < min(top+6, h); y++ {
+			for x := range w {
+				inBand[pixels[y*w+x]] = true
+			}
+		}
+
+		first := true
+		for index, ok := range inBand {
+			if !ok {
+				continue
+			}
+			for x := range w {
+				var bits byte
+				for dy := 0; dy < 6 && top+dy < h; dy++ {
+					if pixels[(top+dy)*w+x] == index {
+						bits |= 1 << dy
+					}
+				}
+				row[x] = '?' + bits
+			}
+			if !first {
+				b.WriteByte('$')
+			}
+			first = false
+			fmt.Fprintf(&b, "#%d", index)
+			writeSixelRun(&b, row)
+		}
+		b.WriteByte('-')
+	}
+
+	b.WriteString("\x1b\\")
+	return b.String()
+}
+
+// writeSixelRun writes row of sixel characters, compressing repeats.
+func writeSixelRun(b *strings.Builder, row []byte) {
+	for x := 0; x < len(row); {
+		n := 1
+		for x+n < len(row) && row[x+n] == row[x] {
+			n++
+		}
+		if n > 3 {
+			fmt.Fprintf(b, "!%d%c", n, row[x])
+		} else {
+			for range n {
+				b.WriteByte(row[x])
+			}
+		}
+		x += n
+	}
+}
+
+// level returns the level in the color cube nearest to v.
+func level(v uint8) int {
+	return (int(v)*(sixelLevels-1) + 127) / 255
+}
+
+// percent returns a level in the color cube as a sixel color component,
+// from 0 to 100.
+func percent(level int) int {
+	return level * 100 / (sixelLevels - 1)
+}
@@ -0,0 +1,704 @@
+// Package jsonschema validates JSON documents against a JSON Schema.
+//
+// It implements the validation keywords shared by drafts 4 to 2020-12, plus
+// OpenAPI 3.0's nullable. References must be local ("#/definitions/user").
+// Annotations such as format, title and description are ignored.
+package jsonschema
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxRefDepth bounds how many references are followed without descending
+// into the document, which stops self-referencing schemas looping forever.
+const maxRefDepth = 64
+
+// Violation is a way in which a document does not match a schema.
+type Violation struct {
+	// Path locates the offending value, e.g. "$.users[0].name".
+	Path string
+
+	// Message describes the problem.
+	Message string
+}
+
+// String renders the violation on one line.
+func (v Violation) String() string {
+	return v.Path + ": " + v.Message
+}
+
+// Schema is a compiled JSON Schema.
+type Schema struct {
+	root     any
+	patterns map[string]*regexp.Regexp
+}
+
+// Compile parses a JSON Schema document. It returns an error if the document
+// is not JSON, is not a schema, has an invalid pattern, or has a reference
+// that cannot be resolved.
+func Compile(data []byte) (*Schema, error) {
+	root, err := decode(data)
+	if err != nil {
+		return nil, fmt.Errorf("schema is not JSON: %w", err)
+	}
+	switch root.(type) {
+	case map[string]any, bool:
+	default:
+		return nil, fmt.Errorf("schema must be an object or a boolean")
+	}
+
+	s := &Schema{root: root, patterns: make(map[string]*regexp.Regexp)}
+	if err := s.check(root); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Validate validates a JSON document against the schema. It returns an
+// error, rather than violations, if the document is not JSON.
+func (s *Schema) Validate(doc []byte) ([]Violation, error) {
+	v, err := decode(doc)
+	if err != nil {
+		return nil, fmt.Errorf("document is not JSON: %w", err)
+	}
+
+	var violations []Violation
+	s.validate("$", s.root, v, 0, &violations)
+	return violations, nil
+}
+
+// decode parses a single JSON document, keeping numbers exact.
+func decode(data []byte) (any, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	if dec.More() {
+		return nil, fmt.Errorf("unexpected data after the document")
+	}
+	return v, nil
+}
+
+// check walks the schema, compiling patterns and resolving references up front
+// so validation never fails part way through.
+func (s *Schema) check(node any) error {
+	switch n := node.(type) {
+	case map[string]any:
+		for key, child := range n {
+			switch key {
+			case "enum", "const", "default", "example", "examples":
+				// Values, not schemas.
+				continue
+			}
+			if err := s.checkKeyword(key, child); err != nil {
+				return err
+			}
+			if err := s.check(child); err != nil {
+				return err
+			}
+		}
+	case []any:
+		for _, child := range n {
+			if err := s.check(child); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// checkKeyword compiles the patterns of a pattern or patternProperties
+// keyword and resolves the reference of a $ref keyword.
+func (s *Schema) checkKeyword(key string, value any) error {
+	switch key {
+	case "pattern":
+		return s.compilePattern(value)
+	case "patternProperties":
+		props, _ := value.(map[string]any)
+		for pattern := range props {
+			if err := s.compilePattern(pattern); err != nil {
+				return err
+			}
+		}
+	case "$ref":
+		ref, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("$ref must be a string")
+		}
+		if _, err := s.resolve(ref); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// compilePattern compiles and caches a regular expression.
+func (s *Schema) compilePattern(v any) error {
+	pattern, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("pattern must be a string")
+	}
+	if _, ok := s.patterns[pattern]; ok {
+		return nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return fmt.Errorf("invalid pattern %q: %w", pattern, err)
+	}
+	s.patterns[pattern] = re
+	return nil
+}
+
+// resolve finds the schema a local reference such as "#/definitions/user" points to.
+func (s *Schema) resolve(ref string) (any, error) {
+	if ref == "#" {
+		return s.root, nil
+	}
+	if !strings.HasPrefix(ref, "#/") {
+		return nil, fmt.Errorf("unsupported $ref %q: only local references are supported", ref)
+	}
+
+	node := s.root
+	for _, token := range strings.Split(ref[2:], "/") {
+		if unescaped, err := url.PathUnescape(token); err == nil {
+			token = unescaped
+		}
+		token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+
+		switch n := node.(type) {
+		case map[string]any:
+			child, ok := n[token]
+			if !ok {
+				return nil, fmt.Errorf("unresolvable $ref %q", ref)
+			}
+			node = child
+		case []any:
+			i, err := strconv.Atoi(token)
+			if err != nil || i < 0 || i >= len(n) {
+				return nil, fmt.Errorf("unresolvable $ref %q", ref)
+			}
+			node = n[i]
+		default:
+			return nil, fmt.Errorf("unresolvable $ref %q", ref)
+		}
+	}
+	return node, nil
+}
+
+// validate appends the ways v, found at path, does not match schema.
+// refDepth counts the references followed at this path.
+func (s *Schema) validate(path string, schema, v any, refDepth int, out *[]Violation) {
+	report := func(format string, args ...any) {
+		*out = append(*out, Violation{Path: path, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if allowed, ok := schema.(bool); ok {
+		if !allowed {
+			report("no value is allowed here")
+		}
+		return
+	}
+	sch, ok := schema.(map[string]any)
+	if !ok {
+		return
+	}
+
+	if !s.validateRef(path, sch, v, refDepth, out, report) {
+		return
+	}
+
+	if v == nil && sch["nullable"] == true {
+		return
+	}
+
+	if t, ok := sch["type"]; ok && !matchesType(t, v) {
+		report("expected %s, got %s", describeType(t), typeOf(v))
+		return
+	}
+
+	validateValue(sch, v, report)
+
+	switch val := v.(type) {
+	case json.Number:
+		s.validateNumber(sch, val, report)
+	case string:
+		s.validateString(sch, val, report)
+	case []any:
+		s.validateArray(path, sch, val, refDepth, out, report)
+	case map[string]any:
+		s.validateObject(path, sch, val, refDepth, out, report)
+	}
+
+	s.validateCombinators(path, sch, v, refDepth, out, report)
+}
+
+// validateRef validates v against the schema a $ref keyword points to. It
+// reports whether the rest of the schema should be checked, which it should
+// not if the reference cannot be followed.
+func (s *Schema) validateRef(path string, sch map[string]any, v any, refDepth int, out *[]Violation, report func(string, ...any)) bool {
+	ref, ok := sch["$ref"].(string)
+	if !ok {
+		return true
+	}
+	if refDepth >= maxRefDepth {
+		report("schema references nest too deeply")
+		return false
+	}
+	target, err := s.resolve(ref)
+	if err != nil {
+		report("%v", err)
+		return false
+	}
+	s.validate(path, target, v, refDepth+1, out)
+	return true
+}
+
+// validateValue checks the enum and const keywords.
+func validateValue(sch map[string]any, v any, report func(string, ...any)) {
+	if enum, ok := sch["enum"].([]any); ok && !containsValue(enum, v) {
+		report("must be one of %s", compact(enum))
+	}
+	if c, ok := sch["const"]; ok && !equal(c, v) {
+		report("must be %s", compact(c))
+	}
+}
+
+// validateNumber checks the numeric keywords.
+func (s *Schema) validateNumber(sch map[string]any, n json.Number, report func(string, ...any)) {
+	value, ok := rat(n)
+	if !ok {
+		return
+	}
+
+	validateMinimum(sch, value, report)
+	validateMaximum(sch, value, report)
+	if step, ok := ratKeyword(sch, "multipleOf"); ok && step.Sign() > 0 {
+		if !new(big.Rat).Quo(value, step).IsInt() {
+			report("must be a multiple of %s", step.RatString())
+		}
+	}
+}
+
+// validateMinimum checks minimum and exclusiveMinimum, which is a boolean
+// modifier of minimum before draft 6 and a bound of its own since.
+func validateMinimum(sch map[string]any, value *big.Rat, report func(string, ...any)) {
+	exclusive := sch["exclusiveMinimum"] == true
+	if min, ok := ratKeyword(sch, "minimum"); ok {
+		if c := value.Cmp(min); c < 0 || c == 0 && exclusive {
+			if exclusive {
+				report("must be greater than %s", min.RatString())
+			} else {
+				report("must be at least %s", min.RatString())
+			}
+		}
+	}
+	if min, ok := ratKeyword(sch, "exclusiveMinimum"); ok && value.Cmp(min) <= 0 {
+		report("must be greater than %s", min.RatString())
+	}
+}
+
+// validateMaximum checks maximum and exclusiveMaximum, which is a boolean
+// modifier of maximum before draft 6 and a bound of its own since.
+func validateMaximum(sch map[string]any, value *big.Rat, report func(string, ...any)) {
+	exclusive := sch["exclusiveMaximum"] == true
+	if max, ok := ratKeyword(sch, "maximum"); ok {
+		if c := value.Cmp(max); c > 0 || c == 0 && exclusive {
+			if exclusive {
+				report("must be less than %s", max.RatString())
+			} else {
+				report("must be at most %s", max.RatString())
+			}
+		}
+	}
+	if max, ok := ratKeyword(sch, "exclusiveMaximum"); ok && value.Cmp(max) >= 0 {
+		report("must be less than %s", max.RatString())
+	}
+}
+
+// validateString checks the string keywords.
+func (s *Schema) validateString(sch map[string]any, str string, report func(string, ...any)) {
+	length := utf8.RuneCountInString(str)
+	if min, ok := intKeyword(sch, "minLength"); ok && length < min {
+		report("must be at least %d characters, got %d", min, length)
+	}
+	if max, ok := intKeyword(sch, "maxLength"); ok && length > max {
+		report("must be at most %d characters, got %d", max, length)
+	}
+	if pattern, ok := sch["pattern"].(string); ok && !s.patterns[pattern].MatchString(str) {
+		report("must match pattern %q", pattern)
+	}
+}
+
+// validateArray checks the array keywords and validates the elements.
+func (s *Schema) validateArray(path string, sch map[string]any, arr []any, refDepth int, out *[]Violation, report func(string, ...any)) {
+	if min, ok := intKeyword(sch, "minItems"); ok && len(arr) < min {
+		report("must have at least %d items, got %d", min, len(arr))
+	}
+	if max, ok := intKeyword(sch, "maxItems"); ok && len(arr) > max {
+		report("must have at most %d items, got %d", max, len(arr))
+	}
+	if sch["uniqueItems"] == true {
+		validateUnique(arr, report)
+	}
+
+	// Positional schemas come from prefixItems (2020-12) or an items array
+	// (earlier drafts); the remaining items use items or additionalItems.
+	var tuple []any
+	rest, hasRest := sch["items"]
+	if prefix, ok := sch["prefixItems"].([]any); ok {
+		tuple = prefix
+	} else if items, ok := rest.([]any); ok {
+		tuple = items
+		rest, hasRest = sch["additionalItems"]
+	}
+
+	for i, item := range arr {
+		itemPath := fmt.Sprintf("%s[%d]", path, i)
+		switch {
+		case i < len(tuple):
+			s.validate(itemPath, tuple[i], item, 0, out)
+		case hasRest:
+			s.validate(itemPath, rest, item, 0, out)
+		}
+	}
+
+	if contains, ok := sch["contains"]; ok && !s.containsMatch(contains, arr, refDepth) {
+		report("must contain an item matching the contains schema")
+	}
+}
+
+// validateUnique reports the first pair of equal items.
+func validateUnique(arr []any, report func(string, ...any)) {
+	for i := 1; i < len(arr); i++ {
+		for j := 0; j < i; j++ {
+			if equal(arr[i], arr[j]) {
+				report("items %d and %d are equal; items must be unique", j, i)
+				return
+			}
+		}
+	}
+}
+
+// validateObject checks the object keywords and validates the members.
+func (s *Schema) validateObject(path string, sch map[string]any, obj map[string]any, refDepth int, out *[]Violation, report func(string, ...any)) {
+	if min, ok := intKeyword(sch, "minProperties"); ok && len(obj) < min {
+		report("must have at least %d properties, got %d", min, len(obj))
+	}
+	if max, ok := intKeyword(sch, "maxProperties"); ok && len(obj) > max {
+		report("must have at most %d properties, got %d", max, len(obj))
+	}
+	validateRequired(sch, obj, report)
+
+	properties, _ := sch["properties"].(map[string]any)
+	patternProperties, _ := sch["patternProperties"].(map[string]any)
+	additional, hasAdditional := sch["additionalProperties"]
+
+	keys := make([]string, 0, len(obj))
+	for k := range obj {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		childPath := path + memberPath(k)
+		matched := false
+		if propSchema, ok := properties[k]; ok {
+			s.validate(childPath, propSchema, obj[k], 0, out)
+			matched = true
+		}
+		for pattern, propSchema := range patternProperties {
+			if s.patterns[pattern].MatchString(k) {
+				s.validate(childPath, propSchema, obj[k], 0, out)
+				matched = true
+			}
+		}
+		if !matched && hasAdditional {
+			if additional == false {
+				*out = append(*out, Violation{Path: childPath, Message: "property is not allowed"})
+			} else {
+				s.validate(childPath, additional, obj[k], 0, out)
+			}
+		}
+	}
+}
+
+// validateRequired checks the required keyword.
+func validateRequired(sch map[string]any, obj map[string]any, report func(string, ...any)) {
+	required, _ := sch["required"].([]any)
+	for _, r := range required {
+		if name, ok := r.(string); ok {
+			if _, present := obj[name]; !present {
+				report("missing required property %q", name)
+			}
+		}
+	}
+}
+
+// validateCombinators checks allOf, anyOf, oneOf, not and if/then/else.
+func (s *Schema) validateCombinators(path string, sch map[string]any, v any, refDepth int, out *[]Violation, report func(string, ...any)) {
+	if all, ok := sch["allOf"].([]any); ok {
+		for _, sub := range all {
+			s.validate(path, sub, v, refDepth, out)
+		}
+	}
+	if anyOf, ok := sch["anyOf"].([]any); ok && !s.matchesAnyOf(anyOf, v, refDepth) {
+		report("does not match any of the allowed schemas")
+	}
+	if oneOf, ok := sch["oneOf"].([]any); ok {
+		s.validateOneOf(oneOf, v, refDepth, report)
+	}
+	if not, ok := sch["not"]; ok && s.matches(not, v, refDepth) {
+		report("must not match the excluded schema")
+	}
+	s.validateConditional(path, sch, v, refDepth, out)
+}
+
+// validateOneOf checks that v matches exactly one of schemas.
+func (s *Schema) validateOneOf(schemas []any, v any, refDepth int, report func(string, ...any)) {
+	count := 0
+	for _, sub := range schemas {
+		if s.matches(sub, v, refDepth) {
+			count++
+		}
+	}
+	switch {
+	case count == 0:
+		report("does not match any of the allowed schemas")
+	case count > 1:
+		report("matches %d schemas, expected exactly one", count)
+	}
+}
+
+// validateConditional validates v against then if it matches the if schema,
+// and against else if it does not.
+func (s *Schema) validateConditional(path string, sch map[string]any, v any, refDepth int, out *[]Violation) {
+	cond, ok := sch["if"]
+	if !ok {
+		return
+	}
+	branch := "else"
+	if s.matches(cond, v, refDepth) {
+		branch = "then"
+	}
+	if sub, ok := sch[branch]; ok {
+		s.validate(path, sub, v, refDepth, out)
+	}
+}
+
+// matches reports whether v matches schema without recording violations.
+func (s *Schema) matches(schema, v any, refDepth int) bool {
+	var violations []Violation
+	s.validate("$", schema, v, refDepth, &violations)
+	return len(violations) == 0
+}
+
+// containsMatch reports whether any of values matches schema.
+func (s *Schema) containsMatch(schema any, values []any, refDepth int) bool {
+	for _, v := range values {
+		if s.matches(schema, v, refDepth) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesAnyOf reports whether v matches any of schemas.
+func (s *Schema) matchesAnyOf(schemas []any, v any, refDepth int) bool {
+	for _, sub := range schemas {
+		if s.matches(sub, v, refDepth) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesType reports whether v has the type (or one of the types) named by t.
+func matchesType(t, v any) bool {
+	switch tt := t.(type) {
+	case string:
+		return hasType(tt, v)
+	case []any:
+		for _, name := range tt {
+			if s, ok := name.(string); ok && hasType(s, v) {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// hasType reports whether v is of the named JSON Schema type.
+func hasType(name string, v any) bool {
+	switch name {
+	case "integer":
+		n, ok := v.(json.Number)
+		if !ok {
+			return false
+		}
+		r, ok := rat(n)
+		return ok && r.IsInt()
+	case "number":
+		_, ok := v.(json.Number)
+		return ok
+	default:
+		return typeOf(v) == name
+	}
+}
+
+// typeOf names the JSON type of v. Integral numbers are reported as integers.
+func typeOf(v any) string {
+	switch val := v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	case json.Number:
+		if r, ok := rat(val); ok && r.IsInt() {
+			return "integer"
+		}
+		return "number"
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+// describeType renders a type keyword for messages.
+func describeType(t any) string {
+	if names, ok := t.([]any); ok {
+		parts := make([]string, len(names))
+		for i, name := range names {
+			parts[i] = fmt.Sprint(name)
+		}
+		return strings.Join(parts, " or ")
+	}
+	return fmt.Sprint(t)
+}
+
+// rat parses a JSON number exactly.
+func rat(n json.Number) (*big.Rat, bool) {
+	return new(big.Rat).SetString(n.String())
+}
+
+// ratKeyword reads a numeric keyword.
+func ratKeyword(sch map[string]any, key string) (*big.Rat, bool) {
+	n, ok := sch[key].(json.Number)
+	if !ok {
+		return nil, false
+	}
+	return rat(n)
+}
+
+// intKeyword reads a non-negative integer keyword.
+func intKeyword(sch map[string]any, key string) (int, bool) {
+	n, ok := sch[key].(json.Number)
+	if !ok {
+		return 0, false
+	}
+	i, err := strconv.Atoi(n.String())
+	if err != nil || i < 0 {
+		return 0, false
+	}
+	return i, true
+}
+
+// containsValue reports whether values contains a value equal to v.
+func containsValue(values []any, v any) bool {
+	for _, candidate := range values {
+		if equal(candidate, v) {
+			return true
+		}
+	}
+	return false
+}
+
+// equal compares decoded JSON values, treating numbers by value.
+func equal(a, b any) bool {
+	switch av := a.(type) {
+	case json.Number:
+		bv, ok := b.(json.Number)
+		if !ok {
+			return false
+		}
+		ar, aok := rat(av)
+		br, bok := rat(bv)
+		return aok && bok && ar.Cmp(br) == 0
+	case []any:
+		bv, ok := b.([]any)
+		return ok && equalArrays(av, bv)
+	case map[string]any:
+		bv, ok := b.(map[string]any)
+		return ok && equalObjects(av, bv)
+	default:
+		return a == b
+	}
+}
+
+// equalArrays compares decoded JSON arrays item by item.
+func equalArrays(a, b []any) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !equal(a[i], b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// equalObjects compares decoded JSON objects member by member.
+func equalObjects(a, b map[string]any) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for k, v := range a {
+		other, ok := b[k]
+		if !ok || !equal(v, other) {
+			return false
+		}
+	}
+	return true
+}
+
+// memberPath renders an object key as a path segment, quoting keys that
+// are not plain identifiers.
+func memberPath(key string) string {
+	plain := key != ""
+	for i, r := range key {
+		if !(r == '_' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || i > 0 && r >= '0' && r <= '9') {
+			plain = false
+			break
+		}
+	}
+	if plain {
+		return "." + key
+	}
+	return "[" + strconv.Quote(key) + "]"
+}
+
+// compact renders a decoded value as compact JSON.
+func compact(v any) string {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	_ = enc.Encode(v) // Decoded JSON values always encode.
+	return strings.TrimSuffix(buf.String(), "\n")
+}
@@ -0,0 +1,170 @@
+package jsonschema
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const userSchema = `{
+	"type": "object",
+	"required": ["id", "name", "email"],
+	"additionalProperties": false,
+	"properties": {
+		"id": {"type": "integer", "minimum": 1},
+		"name": {"type": "string", "minLength": 1, "maxLength": 5},
+		"email": {"type": "string", "pattern": "^[^@]+@[^@]+$"},
+		"role": {"enum": ["admin", "user"]},
+		"score": {"type": "number", "exclusiveMaximum": 100, "multipleOf": 0.5},
+		"tags": {"type": "array", "items": {"type": "string"}, "maxItems": 2, "uniqueItems": true},
+		"manager": {"$ref": "#/definitions/ref"}
+	},
+	"definitions": {
+		"ref": {"type": "object", "required": ["id"], "nullable": true}
+	}
+}`
+
+func validate(t *testing.T, schema, doc string) []Violation {
+	t.Helper()
+	s, err := Compile([]byte(schema))
+	require.NoError(t, err)
+	violations, err := s.Validate([]byte(doc))
+	require.NoError(t, err)
+	return violations
+}
+
+func TestValidate_Valid(t *testing.T) {
+	doc := `{"id": 7, "name": "Ada", "email": "ada@example.com", "role": "admin",
+		"score": 99.5, "tags": ["a", "b"], "manager": {"id": 1}}`
+	assert.Empty(t, validate(t, userSchema, doc))
+
+	assert.Empty(t, validate(t, userSchema, `{"id": 1.0, "name": "Ada", "email": "a@b", "manager": null}`))
+}
+
+func TestValidate_Violations(t *testing.T) {
+	doc := `{"id": 0, "name": "Augusta", "role": "guest", "score": 100.25,
+		"tags": ["a", "a", 3], "manager": {}, "extra-field": true}`
+
+	assert.Equal(t, []Violation{
+		{Path: "$", Message: `missing required property "email"`},
+		{Path: `$["extra-field"]`, Message: "property is not allowed"},
+		{Path: "$.id", Message: "must be at least 1"},
+		{Path: "$.manager", Message: `missing required property "id"`},
+		{Path: "$.name", Message: "must be at most 5 characters, got 7"},
+		{Path: "$.role", Message: `must be one of ["admin","user"]`},
+		{Path: "$.score", Message: "must be less than 100"},
+		{Path: "$.score", Message: "must be a multiple of 1/2"},
+		{Path: "$.tags", Message: "must have at most 2 items, got 3"},
+		{Path: "$.tags", Message: "items 0 and 1 are equal; items must be unique"},
+		{Path: "$.tags[2]", Message: "expected string, got integer"},
+	}, validate(t, userSchema, doc))
+}
+
+func TestValidate_TypeMismatch(t *testing.T) {
+	assert.Equal(t, []Violation{{Path: "$", Message: "expected object, got array"}},
+		validate(t, userSchema, `[]`))
+	assert.Equal(t, []Violation{{Path: "$", Message: "expected string or null, got number"}},
+		validate(t, `{"type": ["string", "null"]}`, `1.5`))
+	assert.Equal(t, []Violation{{Path: "$", Message: "expected integer, got number"}},
+		validate(t, `{"type": "integer"}`, `1.5`))
+	assert.Empty(t, validate(t, `{"type": "number"}`, `1e3`))
+}
+
+func TestValidate_Combinators(t *testing.T) {
+	schema := `{"oneOf": [{"type": "integer"}, {"type": "number", "minimum": 10}]}`
+	assert.Empty(t, validate(t, schema, `1`))
+	assert.Empty(t, validate(t, schema, `10.5`))
+	assert.Equal(t, []Violation{{Path: "$", Message: "matches 2 schemas, expected exactly one"}},
+		validate(t, schema, `12`))
+	assert.Equal(t, []Violation{{Path: "$", Message: "does not match any of the allowed schemas"}},
+		validate(t, schema, `"x"`))
+
+	assert.Equal(t, []Violation{{Path: "$", Message: "does not match any of the allowed schemas"}},
+		validate(t, `{"anyOf": [{"type": "string"}, {"type": "boolean"}]}`, `null`))
+	assert.Equal(t, []Violation{{Path: "$", Message: "must not match the excluded schema"}},
+		validate(t, `{"not": {"const": 3}}`, `3.0`))
+
+	allOf := `{"allOf": [{"required": ["a"]}, {"required": ["b"]}]}`
+	assert.Equal(t, []Violation{{Path: "$", Message: `missing required property "b"`}},
+		validate(t, allOf, `{"a": 1}`))
+
+	cond := `{"if": {"properties": {"kind": {"const": "card"}}}, "then": {"required": ["number"]}, "else": {"required": ["iban"]}}`
+	assert.Equal(t, []Violation{{Path: "$", Message: `missing required property "number"`}},
+		validate(t, cond, `{"kind": "card"}`))
+	assert.Equal(t, []Violation{{Path: "$", Message: `missing required property "iban"`}},
+		validate(t, cond, `{"kind": "bank"}`))
+}
+
+func TestValidate_Arrays(t *testing.T) {
+	tuple := `{"prefixItems": [{"type": "string"}, {"type": "integer"}], "items": false}`
+	assert.Empty(t, validate(t, tuple, `["a", 1]`))
+	assert.Equal(t, []Violation{
+		{Path: "$[1]", Message: "expected integer, got string"},
+		{Path: "$[2]", Message: "no value is allowed here"},
+	}, validate(t, tuple, `["a", "b", true]`))
+
+	legacy := `{"items": [{"type": "string"}], "additionalItems": {"type": "boolean"}}`
+	assert.Equal(t, []Violation{{Path: "$[2]", Message: "expected boolean, got integer"}},
+		validate(t, legacy, `["a", true, 1]`))
+
+	assert.Equal(t, []Violation{{Path: "$", Message: "must contain an item matching the contains schema"}},
+		validate(t, `{"contains": {"const": "x"}, "minItems": 1}`, `["a", "b"]`))
+}
+
+func TestValidate_PatternPropertiesAndRecursion(t *testing.T) {
+	schema := `{
+		"type": "object",
+		"properties": {"children": {"type": "array", "items": {"$ref": "#"}}},
+		"patternProperties": {"^x-": {"type": "string"}},
+		"additionalProperties": {"type": "integer"}
+	}`
+	doc := `{"x-id": "a", "size": 2, "children": [{"x-id": 1, "size": "big", "children": []}]}`
+
+	assert.Equal(t, []Violation{
+		{Path: "$.children[0].size", Message: "expected integer, got string"},
+		{Path: `$.children[0]["x-id"]`, Message: "expected string, got integer"},
+	}, validate(t, schema, doc))
+}
+
+func TestValidate_RefLoop(t *testing.T) {
+	violations := validate(t, `{"$ref": "#"}`, `{}`)
+	assert.Equal(t, []Violation{{Path: "$", Message: "schema references nest too deeply"}}, violations)
+}
+
+func TestValidate_InvalidDocument(t *testing.T) {
+	s, err := Compile([]byte(`{}`))
+	require.NoError(t, err)
+
+	_, err = s.Validate([]byte("<html>"))
+	assert.ErrorContains(t, err, "document is not JSON")
+
+	_, err = s.Validate([]byte("{} {}"))
+	assert.Error(t, err)
+}
+
+func TestCompile_Invalid(t *testing.T) {
+	tests := map[string]string{
+		"not json":       `{"type": `,
+		"not a schema":   `"string"`,
+		"bad pattern":    `{"pattern": "("}`,
+		"remote ref":     `{"$ref": "https://example.com/schema.json"}`,
+		"unresolved ref": `{"$ref": "#/definitions/missing"}`,
+	}
+	for name, schema := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := Compile([]byte(schema))
+			assert.Error(t, err)
+		})
+	}
+
+	s, err := Compile([]byte(`true`))
+	require.NoError(t, err)
+	violations, err := s.Validate([]byte(`[1, 2]`))
+	require.NoError(t, err)
+	assert.Empty(t, violations)
+}
+
+func TestViolation_String(t *testing.T) {
+	assert.Equal(t, "$.id: must be at least 1", Violation{Path: "$.id", Message: "must be at least 1"}.String())
+}
@@ -0,0 +1,202 @@
+// Package oauth implements the client side of the OAuth 2.0 device
+// authorization grant (RFC 8628), which signs in without a browser on the
+// machine making requests: the user opens a verification URL anywhere,
+// enters a short code, and the client polls the token endpoint until they do.
+//
+// The package builds the requests and parses the responses; sending them is
+// left to the caller's HTTP client.
+package oauth
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/williajm/curly/internal/domain"
+)
+
+// DeviceGrantType is the grant_type of device access token requests.
+const DeviceGrantType = "urn:ietf:params:oauth:grant-type:device_code"
+
+// DefaultInterval is the number of seconds to wait between token requests
+// when the server does not say.
+const DefaultInterval = 5
+
+// SlowDownStep is the number of seconds the interval grows by each time the
+// server asks the client to slow down.
+const SlowDownStep = 5
+
+// Errors the token endpoint returns while the user has not finished signing
+// in, or once they never will.
+var (
+	// ErrAuthorizationPending means the user has not entered the code yet.
+	ErrAuthorizationPending = errors.New("authorization pending")
+
+	// ErrSlowDown means the client is polling too often.
+	ErrSlowDown = errors.New("slow down")
+
+	// ErrAccessDenied means the user declined to sign in.
+	ErrAccessDenied = errors.New("access denied")
+
+	// ErrExpiredToken means the device code expired before the user signed in.
+	ErrExpiredToken = errors.New("device code expired")
+)
+
+// DeviceConfig describes the authorization server and client to sign in with.
+type DeviceConfig struct {
+	// DeviceAuthURL is the device authorization endpoint.
+	DeviceAuthURL string
+
+	// TokenURL is the token endpoint.
+	TokenURL string
+
+	// ClientID identifies the client to the server.
+	ClientID string
+
+	// Scope is the space-separated scope requested, or "" for the default.
+	Scope string
+}
+
+// Validate checks that the endpoints and client ID are set.
+func (c DeviceConfig) Validate() error {
+	if strings.TrimSpace(c.DeviceAuthURL) == "" {
+		return fmt.Errorf("device authorization URL is required")
+	}
+	if strings.TrimSpace(c.TokenURL) == "" {
+		return fmt.Errorf("token URL is required")
+	}
+	if strings.TrimSpace(c.ClientID) == "" {
+		return fmt.Errorf("client ID is required")
+	}
+	return nil
+}
+
+// DeviceCode is the device authorization response: the code to show the
+// user, where to enter it, and the code the client polls with.
+type DeviceCode struct {
+	DeviceCode      string `json:"device_code"`
+	UserCode        string `json:"user_code"`
+	VerificationURI string `json:"verification_uri"`
+
+	// VerificationURIComplete, if set, includes the user code, so the user
+	// need not type it.
+	VerificationURIComplete string `json:"verification_uri_complete"`
+
+	// ExpiresIn is the lifetime of the codes in seconds.
+	ExpiresIn int `json:"expires_in"`
+
+	// Interval is the number of seconds to wait between token requests.
+	Interval int `json:"interval"`
+}
+
+// Token is a successful access token response.
+type Token struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int    `json:"expires_in"`
+	Scope        string `json:"scope"`
+}
+
+// errorResponse is the error body of the OAuth endpoints.
+type errorResponse struct {
+	Error       string `json:"error"`
+	Description string `json:"error_description"`
+}
+
+// DeviceCodeRequest returns the request for a device code.
+func DeviceCodeRequest(cfg DeviceConfig) *domain.Request {
+	form := url.Values{"client_id": {cfg.ClientID}}
+	if cfg.Scope != "" {
+		form.Set("scope", cfg.Scope)
+	}
+	return formRequest(cfg.DeviceAuthURL, form)
+}
+
+// TokenRequest returns the request that polls for the access token of
+// deviceCode.
+func TokenRequest(cfg DeviceConfig, deviceCode string) *domain.Request {
+	return formRequest(cfg.TokenURL, url.Values{
+		"grant_type":  {DeviceGrantType},
+		"device_code": {deviceCode},
+		"client_id":   {cfg.ClientID},
+	})
+}
+
+// formRequest returns a POST of form to endpoint that asks for JSON back.
+func formRequest(endpoint string, form url.Values) *domain.Request {
+	req := domain.NewRequestWithMethodAndURL(domain.MethodPost, endpoint)
+	req.Name = "OAuth device flow"
+	req.Headers["Content-Type"] = "application/x-www-form-urlencoded"
+	// GitHub, among others, answers in form encoding unless asked for JSON.
+	req.Headers["Accept"] = "application/json"
+	req.Body = form.Encode()
+	return req
+}
+
+// ParseDeviceCode parses the device authorization response body.
+func ParseDeviceCode(body string) (*DeviceCode, error) {
+	var code DeviceCode
+	if err := json.Unmarshal([]byte(body), &code); err != nil {
+		return nil, fmt.Errorf("invalid device authorization response: %w", err)
+	}
+	if err := parseError(body); err != nil {
+		return nil, err
+	}
+	if code.VerificationURI == "" {
+		// Google names it verification_url.
+		var google struct {
+			VerificationURL string `json:"verification_url"`
+		}
+		_ = json.Unmarshal([]byte(body), &google)
+		code.VerificationURI = google.VerificationURL
+	}
+	if code.DeviceCode == "" || code.UserCode == "" || code.VerificationURI == "" {
+		return nil, fmt.Errorf("device authorization response is missing device_code, user_code or verification_uri")
+	}
+	if code.Interval <= 0 {
+		code.Interval = DefaultInterval
+	}
+	return &code, nil
+}
+
+// ParseToken parses a token response body. While the user has not signed in
+// it returns ErrAuthorizationPending or ErrSlowDown, and once they never will
+// ErrAccessDenied, ErrExpiredToken or another error.
+func ParseToken(body string) (*Token, error) {
+	var token Token
+	if err := json.Unmarshal([]byte(body), &token); err != nil {
+		return nil, fmt.Errorf("invalid token response: %w", err)
+	}
+	if err := parseError(body); err != nil {
+		return nil, err
+	}
+	if token.AccessToken == "" {
+		return nil, fmt.Errorf("token response has no access_token")
+	}
+	return &token, nil
+}
+
+// parseError returns the error an OAuth response body reports, if any.
+func parseError(body string) error {
+	var resp errorResponse
+	if err := json.Unmarshal([]byte(body), &resp); err != nil || resp.Error == "" {
+		return nil
+	}
+	switch resp.Error {
+	case "authorization_pending":
+		return ErrAuthorizationPending
+	case "slow_down":
+		return ErrSlowDown
+	case "access_denied":
+		return ErrAccessDenied
+	case "expired_token":
+		return ErrExpiredToken
+	}
+	if resp.Description != "" {
+		return fmt.Errorf("%s: %s", resp.Error, resp.Description)
+	}
+	return errors.New(resp.Error)
+}
@@ -0,0 +1,129 @@
+package oauth
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/williajm/curly/internal/domain"
+)
+
+var testConfig = DeviceConfig{
+	DeviceAuthURL: "https://auth.example.com/device/code",
+	TokenURL:      "https://auth.example.com/token",
+	ClientID:      "curly",
+	Scope:         "read write",
+}
+
+func TestDeviceConfig_Validate(t *testing.T) {
+	assert.NoError(t, testConfig.Validate())
+
+	cfg := testConfig
+	cfg.TokenURL = " "
+	assert.ErrorContains(t, cfg.Validate(), "token URL")
+
+	cfg = testConfig
+	cfg.ClientID = ""
+	assert.ErrorContains(t, cfg.Validate(), "client ID")
+}
+
+func TestDeviceCodeRequest(t *testing.T) {
+	req := DeviceCodeRequest(testConfig)
+	assert.Equal(t, domain.MethodPost, req.Method)
+	assert.Equal(t, testConfig.DeviceAuthURL, req.URL)
+	assert.Equal(t, "application/x-www-form-urlencoded", req.Headers["Content-Type"])
+	assert.Equal(t, "application/json", req.Headers["Accept"])
+
+	form, err := url.ParseQuery(req.Body)
+	require.NoError(t, err)
+	assert.Equal(t, "curly", form.Get("client_id"))
+	assert.Equal(t, "read write", form.Get("scope"))
+
+	cfg := testConfig
+	cfg.Scope = ""
+	form, err = url.ParseQuery(DeviceCodeRequest(cfg).Body)
+	require.NoError(t, err)
+	assert.NotContains(t, form, "scope")
+}
+
+func TestTokenRequest(t *testing.T) {
+	req := TokenRequest(testConfig, "dev-123")
+	assert.Equal(t, testConfig.TokenURL, req.URL)
+
+	form, err := url.ParseQuery(req.Body)
+	require.NoError(t, err)
+	assert.Equal(t, DeviceGrantType, form.Get("grant_type"))
+	assert.Equal(t, "dev-123", form.Get("device_code"))
+	assert.Equal(t, "curly", form.Get("client_id"))
+}
+
+func TestParseDeviceCode(t *testing.T) {
+	code, err := ParseDeviceCode(`{
+		"device_code": "dev-123",
+		"user_code": "WDJB-MJHT",
+		"verification_uri": "https://example.com/device",
+		"verification_uri_complete": "https://example.com/device?user_code=WDJB-MJHT",
+		"expires_in": 1800,
+		"interval": 10
+	}`)
+	require.NoError(t, err)
+	assert.Equal(t, "dev-123", code.DeviceCode)
+	assert.Equal(t, "WDJB-MJHT", code.UserCode)
+	assert.Equal(t, "https://example.com/device", code.VerificationURI)
+	assert.Equal(t, "https://example.com/device?user_code=WDJB-MJHT", code.VerificationURIComplete)
+	assert.Equal(t, 1800, code.ExpiresIn)
+	assert.Equal(t, 10, code.Interval)
+
+	// Google's spelling, and the default interval.
+	code, err = ParseDeviceCode(`{"device_code": "d", "user_code": "u", "verification_url": "https://google.com/device"}`)
+	require.NoError(t, err)
+	assert.Equal(t, "https://google.com/device", code.VerificationURI)
+	assert.Equal(t, DefaultInterval, code.Interval)
+}
+
+func TestParseDeviceCode_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{name: "not JSON", body: "<html>", want: "invalid device authorization response"},
+		{name: "error", body: `{"error": "invalid_client", "error_description": "Unknown client"}`, want: "invalid_client: Unknown client"},
+		{name: "missing fields", body: `{"device_code": "d"}`, want: "missing"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseDeviceCode(tt.body)
+			assert.ErrorContains(t, err, tt.want)
+		})
+	}
+}
+
+func TestParseToken(t *testing.T) {
+	token, err := ParseToken(`{"access_token": "abc", "token_type": "Bearer", "refresh_token": "r", "expires_in": 3600, "scope": "read"}`)
+	require.NoError(t, err)
+	assert.Equal(t, "abc", token.AccessToken)
+	assert.Equal(t, "Bearer", token.TokenType)
+	assert.Equal(t, "r", token.RefreshToken)
+	assert.Equal(t, 3600, token.ExpiresIn)
+
+	tests := []struct {
+		body string
+		want error
+	}{
+		{body: `{"error": "authorization_pending"}`, want: ErrAuthorizationPending},
+		{body: `{"error": "slow_down"}`, want: ErrSlowDown},
+		{body: `{"error": "access_denied"}`, want: ErrAccessDenied},
+		{body: `{"error": "expired_token"}`, want: ErrExpiredToken},
+	}
+	for _, tt := range tests {
+		_, err := ParseToken(tt.body)
+		assert.ErrorIs(t, err, tt.want, tt.body)
+	}
+
+	_, err = ParseToken(`{"error": "invalid_grant"}`)
+	assert.EqualError(t, err, "invalid_grant")
+	_, err = ParseToken(`{"token_type": "Bearer"}`)
+	assert.ErrorContains(t, err, "no access_token")
+}
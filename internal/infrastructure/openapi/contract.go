@@ -0,0 +1,570 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/williajm/curly/internal/domain"
+	"github.com/williajm/curly/internal/infrastructure/jsonschema"
+	"gopkg.in/yaml.v3"
+)
+
+// Contract is what an operation's responses must look like according to an
+// OpenAPI document. It is self-contained: schemas carry the definitions they
+// reference, so a contract can be stored with a request and checked later
+// without the document.
+type Contract struct {
+	// Method and Path identify the operation, e.g. GET /users/{id}.
+	Method string `json:"method"`
+	Path   string `json:"path"`
+
+	// Responses maps documented status codes ("200", "4XX" or "default")
+	// to the response expected for them.
+	Responses map[string]*ResponseContract `json:"responses"`
+}
+
+// ResponseContract describes one documented response.
+type ResponseContract struct {
+	// Headers maps header names to their description.
+	Headers map[string]*HeaderContract `json:"headers,omitempty"`
+
+	// Content maps media types to the JSON Schema of the body, or null when
+	// the body is not described. No content means the body is not checked.
+	Content map[string]json.RawMessage `json:"content,omitempty"`
+}
+
+// HeaderContract describes a documented response header.
+type HeaderContract struct {
+	Required bool            `json:"required,omitempty"`
+	Schema   json.RawMessage `json:"schema,omitempty"`
+}
+
+// ContractSet holds the contracts of every operation in a document.
+type ContractSet struct {
+	// Title is the document's title, the folder its requests are imported into.
+	Title string
+
+	// Contracts lists the operations ordered by path and then method.
+	Contracts []*Contract
+}
+
+// ParseContracts reads the response contracts of every operation in an
+// OpenAPI 3.x or Swagger 2.0 document in JSON or YAML.
+func ParseContracts(data []byte) (*ContractSet, error) {
+	raw, err := decodeRaw(data)
+	if err != nil {
+		return nil, err
+	}
+	openAPI, _ := raw["openapi"].(string)
+	swagger, _ := raw["swagger"].(string)
+	if !strings.HasPrefix(openAPI, "3.") && swagger != "2.0" {
+		return nil, fmt.Errorf("unsupported document: expected openapi 3.x or swagger 2.0")
+	}
+
+	set := &ContractSet{}
+	if info, ok := raw["info"].(map[string]any); ok {
+		set.Title, _ = info["title"].(string)
+	}
+
+	paths, _ := raw["paths"].(map[string]any)
+	names := make([]string, 0, len(paths))
+	for path := range paths {
+		names = append(names, path)
+	}
+	sort.Strings(names)
+
+	for _, path := range names {
+		for _, method := range methods {
+			contract, err := operationContract(raw, path, method)
+			if err != nil {
+				return nil, fmt.Errorf("%s %s: %w", method, path, err)
+			}
+			if contract != nil {
+				set.Contracts = append(set.Contracts, contract)
+			}
+		}
+	}
+	return set, nil
+}
+
+// Match returns the contract of the operation a request calls, or nil if no
+// operation matches. The URL's path must end with the operation's path, whose
+// {parameters} match any single segment; when several operations match, the
+// one with the longest literal path wins.
+func (s *ContractSet) Match(method, rawURL string) *Contract {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return nil
+	}
+	path := strings.TrimSuffix(parsed.Path, "/")
+
+	var best *Contract
+	bestLen := -1
+	for _, c := range s.Contracts {
+		if !strings.EqualFold(c.Method, method) || !pathPattern(c.Path).MatchString(path) {
+			continue
+		}
+		if n := len(templateParam.ReplaceAllString(c.Path, "")); n > bestLen {
+			best, bestLen = c, n
+		}
+	}
+	return best
+}
+
+// templateParam matches a {parameter} in an operation path.
+var templateParam = regexp.MustCompile(`\{[^/{}]+\}`)
+
+// pathPattern matches URL paths ending with an operation path.
+func pathPattern(template string) *regexp.Regexp {
+	var b strings.Builder
+	b.WriteString(`(^|/)`)
+	rest := strings.Trim(template, "/")
+	for _, loc := range templateParam.FindAllStringIndex(rest, -1) {
+		b.WriteString(regexp.QuoteMeta(rest[:loc[0]]))
+		b.WriteString(`[^/]+`)
+		rest = rest[loc[1]:]
+	}
+	b.WriteString(regexp.QuoteMeta(rest))
+	b.WriteString(`$`)
+	return regexp.MustCompile(strings.Replace(b.String(), `(^|/)$`, `^$`, 1))
+}
+
+// MarshalContract encodes a contract for storage with a request.
+func MarshalContract(c *Contract) (string, error) {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal contract: %w", err)
+	}
+	return string(data), nil
+}
+
+// UnmarshalContract decodes a contract written by MarshalContract.
+func UnmarshalContract(data string) (*Contract, error) {
+	var c Contract
+	if err := json.Unmarshal([]byte(data), &c); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal contract: %w", err)
+	}
+	return &c, nil
+}
+
+// Check compares a response with the contract and describes each mismatch:
+// an undocumented status, a missing or invalid header, an undocumented
+// content type, or a body that does not match its schema. An operation that
+// documents no responses accepts any.
+func (c *Contract) Check(resp *domain.Response) []string {
+	if len(c.Responses) == 0 {
+		return nil
+	}
+	operation := c.Method + " " + c.Path
+	rc := c.response(resp.StatusCode)
+	if rc == nil {
+		return []string{fmt.Sprintf("status: %d is not a documented response of %s", resp.StatusCode, operation)}
+	}
+
+	failures := checkHeaders(rc, resp)
+
+	if len(rc.Content) == 0 || strings.EqualFold(c.Method, domain.MethodHead) {
+		return failures
+	}
+
+	mediaType := strings.ToLower(strings.TrimSpace(strings.Split(resp.ContentType(), ";")[0]))
+	schema, ok := matchMediaType(rc.Content, mediaType)
+	if !ok {
+		types := make([]string, 0, len(rc.Content))
+		for t := range rc.Content {
+			types = append(types, t)
+		}
+		sort.Strings(types)
+		return append(failures, fmt.Sprintf("content-type: %q is not documented for status %d (expected %s)",
+			mediaType, resp.StatusCode, strings.Join(types, ", ")))
+	}
+	if len(schema) == 0 || string(schema) == "null" || !isJSON(mediaType) {
+		return failures
+	}
+
+	compiled, err := jsonschema.Compile(schema)
+	if err != nil {
+		return append(failures, fmt.Sprintf("invalid schema for status %d: %v", resp.StatusCode, err))
+	}
+	if !json.Valid([]byte(resp.Body)) {
+		return append(failures, "$: response body is not JSON")
+	}
+	violations, _ := compiled.Validate([]byte(resp.Body)) // The body is valid JSON.
+	for _, v := range violations {
+		failures = append(failures, v.String())
+	}
+	return failures
+}
+
+// checkHeaders describes each documented header of rc that resp is missing
+// or whose value does not match its schema, in name order.
+func checkHeaders(rc *ResponseContract, resp *domain.Response) []string {
+	var failures []string
+
+	names := make([]string, 0, len(rc.Headers))
+	for name := range rc.Headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		// OpenAPI ignores Content-Type here; it is described by content.
+		if strings.EqualFold(name, "Content-Type") {
+			continue
+		}
+		header := rc.Headers[name]
+		value := resp.GetHeader(name)
+		if value == "" {
+			if header.Required {
+				failures = append(failures, fmt.Sprintf("header %s: missing required header", name))
+			}
+			continue
+		}
+		for _, msg := range checkHeader(header.Schema, value) {
+			failures = append(failures, fmt.Sprintf("header %s: %s", name, msg))
+		}
+	}
+	return failures
+}
+
+// response finds the documented response for a status: an exact match, then
+// its range (such as 4XX), then the default response.
+func (c *Contract) response(status int) *ResponseContract {
+	code := strconv.Itoa(status)
+	for _, key := range []string{code, code[:1] + "XX", "default"} {
+		if rc, ok := c.Responses[key]; ok {
+			return rc
+		}
+	}
+	return nil
+}
+
+// matchMediaType finds the content entry for a media type, trying an exact
+// match, then a wildcard subtype such as application/*, then */*.
+func matchMediaType(content map[string]json.RawMessage, mediaType string) (json.RawMessage, bool) {
+	mainType, _, _ := strings.Cut(mediaType, "/")
+	for _, candidate := range []string{mediaType, mainType + "/*", "*/*"} {
+		for t, schema := range content {
+			if strings.EqualFold(strings.TrimSpace(strings.Split(t, ";")[0]), candidate) {
+				return schema, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// checkHeader validates a header value against its schema. Values are
+// compared as JSON numbers or booleans when the schema expects them.
+func checkHeader(schema json.RawMessage, value string) []string {
+	if len(schema) == 0 {
+		return nil
+	}
+	compiled, err := jsonschema.Compile(schema)
+	if err != nil {
+		return []string{fmt.Sprintf("invalid schema: %v", err)}
+	}
+
+	doc, _ := json.Marshal(value)
+	if json.Valid([]byte(value)) {
+		var decoded any
+		_ = json.Unmarshal([]byte(value), &decoded)
+		switch decoded.(type) {
+		case float64, bool:
+			doc = []byte(value)
+		}
+	}
+
+	violations, _ := compiled.Validate(doc) // doc is always valid JSON.
+	messages := make([]string, len(violations))
+	for i, v := range violations {
+		messages[i] = v.Message
+	}
+	return messages
+}
+
+// operationContract builds the contract of one operation, or returns nil if
+// the path has no such operation.
+func operationContract(raw map[string]any, path, method string) (*Contract, error) {
+	item, _ := nested(raw, "paths", path).(map[string]any)
+	op, _ := item[strings.ToLower(method)].(map[string]any)
+	if op == nil {
+		return nil, nil
+	}
+
+	_, swagger := raw["swagger"]
+	produces := []string{"application/json"}
+	if list := stringList(op["produces"]); len(list) > 0 {
+		produces = list
+	} else if list := stringList(raw["produces"]); len(list) > 0 {
+		produces = list
+	}
+
+	contract := &Contract{Method: method, Path: path, Responses: map[string]*ResponseContract{}}
+	responses, _ := op["responses"].(map[string]any)
+	for code, value := range responses {
+		resp, err := resolveRef(raw, value)
+		if err != nil {
+			return nil, err
+		}
+		respObj, _ := resp.(map[string]any)
+
+		rc := &ResponseContract{}
+		if err := addHeaders(raw, rc, respObj, swagger); err != nil {
+			return nil, err
+		}
+		if err := addContent(raw, rc, respObj, swagger, produces); err != nil {
+			return nil, err
+		}
+		if code != "default" {
+			code = strings.ToUpper(code)
+		}
+		contract.Responses[code] = rc
+	}
+
+	return contract, nil
+}
+
+// addContent copies a response's documented body schemas into rc, keyed by
+// media type. Swagger 2.0 documents one schema for every type the operation
+// produces.
+func addContent(raw map[string]any, rc *ResponseContract, resp map[string]any, swagger bool, produces []string) error {
+	var err error
+	if swagger {
+		if schema, ok := resp["schema"]; ok {
+			rc.Content = map[string]json.RawMessage{}
+			for _, t := range produces {
+				if rc.Content[t], err = embedSchema(raw, schema); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+
+	content, ok := resp["content"].(map[string]any)
+	if !ok {
+		return nil
+	}
+	rc.Content = map[string]json.RawMessage{}
+	for t, media := range content {
+		mediaObj, _ := media.(map[string]any)
+		schema, ok := mediaObj["schema"]
+		if !ok {
+			rc.Content[t] = nil
+			continue
+		}
+		if rc.Content[t], err = embedSchema(raw, schema); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addHeaders copies a response's documented headers into rc.
+func addHeaders(raw map[string]any, rc *ResponseContract, resp map[string]any, swagger bool) error {
+	headers, _ := resp["headers"].(map[string]any)
+	for name, value := range headers {
+		h, err := resolveRef(raw, value)
+		if err != nil {
+			return err
+		}
+		header, _ := h.(map[string]any)
+
+		hc := &HeaderContract{}
+		hc.Required, _ = header["required"].(bool)
+
+		// Swagger 2.0 describes headers inline, as a schema without a wrapper.
+		schema := header["schema"]
+		if swagger {
+			schema = header
+		}
+		if schema != nil {
+			if hc.Schema, err = embedSchema(raw, schema); err != nil {
+				return err
+			}
+		}
+		if rc.Headers == nil {
+			rc.Headers = map[string]*HeaderContract{}
+		}
+		rc.Headers[name] = hc
+	}
+	return nil
+}
+
+// embedSchema encodes a schema with copies of the definitions it references,
+// placed at the same paths as in the document so its $refs still resolve.
+func embedSchema(raw map[string]any, schema any) (json.RawMessage, error) {
+	root := map[string]any{"allOf": []any{schema}}
+
+	seen := map[string]bool{}
+	pending := collectRefs(schema, nil)
+	for len(pending) > 0 {
+		ref := pending[0]
+		pending = pending[1:]
+		if seen[ref] {
+			continue
+		}
+		seen[ref] = true
+
+		tokens, ok := refTokens(ref)
+		if !ok {
+			return nil, fmt.Errorf("unsupported schema reference %q", ref)
+		}
+		target := nested(raw, tokens...)
+		if target == nil {
+			return nil, fmt.Errorf("unresolved schema reference %q", ref)
+		}
+		place(root, tokens, target)
+		pending = collectRefs(target, pending)
+	}
+
+	data, err := json.Marshal(root)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode schema: %w", err)
+	}
+	return data, nil
+}
+
+// collectRefs appends the $ref values found anywhere in v to refs.
+func collectRefs(v any, refs []string) []string {
+	switch node := v.(type) {
+	case map[string]any:
+		if ref, ok := node["$ref"].(string); ok {
+			refs = append(refs, ref)
+		}
+		for _, child := range node {
+			refs = collectRefs(child, refs)
+		}
+	case []any:
+		for _, child := range node {
+			refs = collectRefs(child, refs)
+		}
+	}
+	return refs
+}
+
+// resolveRef follows a $ref to a response or header in the document.
+func resolveRef(raw map[string]any, v any) (any, error) {
+	for range maxSchemaDepth {
+		node, ok := v.(map[string]any)
+		if !ok {
+			return v, nil
+		}
+		ref, ok := node["$ref"].(string)
+		if !ok {
+			return v, nil
+		}
+		tokens, ok := refTokens(ref)
+		if !ok {
+			return nil, fmt.Errorf("unsupported reference %q", ref)
+		}
+		if v = nested(raw, tokens...); v == nil {
+			return nil, fmt.Errorf("unresolved reference %q", ref)
+		}
+	}
+	return nil, fmt.Errorf("references nest too deeply")
+}
+
+// refTokens splits a local reference such as #/components/schemas/User into
+// its unescaped JSON pointer tokens.
+func refTokens(ref string) ([]string, bool) {
+	pointer, ok := strings.CutPrefix(ref, "#/")
+	if !ok {
+		return nil, false
+	}
+	tokens := strings.Split(pointer, "/")
+	for i, token := range tokens {
+		if unescaped, err := url.PathUnescape(token); err == nil {
+			token = unescaped
+		}
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+	}
+	return tokens, true
+}
+
+// nested walks object keys from v, returning nil if any is missing.
+func nested(v any, keys ...string) any {
+	for _, key := range keys {
+		node, ok := v.(map[string]any)
+		if !ok {
+			return nil
+		}
+		v = node[key]
+	}
+	return v
+}
+
+// place stores value in root at the object path given by keys.
+func place(root map[string]any, keys []string, value any) {
+	node := root
+	for _, key := range keys[:len(keys)-1] {
+		child, ok := node[key].(map[string]any)
+		if !ok {
+			child = map[string]any{}
+			node[key] = child
+		}
+		node = child
+	}
+	node[keys[len(keys)-1]] = value
+}
+
+// stringList converts a decoded list of strings.
+func stringList(v any) []string {
+	list, _ := v.([]any)
+	var out []string
+	for _, item := range list {
+		if s, ok := item.(string); ok {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// decodeRaw unmarshals a JSON or YAML document into generic values with
+// string keys, keeping every schema keyword.
+func decodeRaw(data []byte) (map[string]any, error) {
+	var v any
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '{' {
+		if err := json.Unmarshal(trimmed, &v); err != nil {
+			return nil, fmt.Errorf("failed to parse document: %w", err)
+		}
+	} else if err := yaml.Unmarshal(data, &v); err != nil {
+		return nil, fmt.Errorf("failed to parse document: %w", err)
+	}
+
+	doc, ok := normalize(v).(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("failed to parse document: expected an object")
+	}
+	return doc, nil
+}
+
+// normalize converts YAML maps with non-string keys, such as response codes,
+// to maps with string keys.
+func normalize(v any) any {
+	switch node := v.(type) {
+	case map[string]any:
+		for k, child := range node {
+			node[k] = normalize(child)
+		}
+		return node
+	case map[any]any:
+		converted := make(map[string]any, len(node))
+		for k, child := range node {
+			converted[fmt.Sprint(k)] = normalize(child)
+		}
+		return converted
+	case []any:
+		for i, child := range node {
+			node[i] = normalize(child)
+		}
+		return node
+	default:
+		return v
+	}
+}
@@ -0,0 +1,310 @@
+package openapi
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/williajm/curly/internal/domain"
+)
+
+const usersV3 = `
+openapi: 3.1.0
+info:
+  title: Users
+paths:
+  /users:
+    get:
+      responses:
+        "200":
+          headers:
+            X-Total-Count:
+              required: true
+              schema: {type: integer, minimum: 0}
+          content:
+            application/json:
+              schema:
+                type: array
+                items: {$ref: '#/components/schemas/User'}
+        4XX:
+          $ref: '#/components/responses/Problem'
+  /users/{id}:
+    get:
+      responses:
+        "200":
+          content:
+            application/json:
+              schema: {$ref: '#/components/schemas/User'}
+            text/*: {}
+        default:
+          $ref: '#/components/responses/Problem'
+    head:
+      responses:
+        "200":
+          content:
+            application/json:
+              schema: {$ref: '#/components/schemas/User'}
+  /users/me:
+    get:
+      responses:
+        "204":
+          description: No content
+components:
+  responses:
+    Problem:
+      content:
+        application/problem+json:
+          schema:
+            type: object
+            required: [title]
+  schemas:
+    User:
+      type: object
+      required: [id, name]
+      properties:
+        id: {type: integer}
+        name: {type: string}
+        manager: {$ref: '#/components/schemas/Manager'}
+    Manager:
+      type: object
+      required: [id]
+`
+
+const usersV2 = `{
+  "swagger": "2.0",
+  "info": {"title": "Users"},
+  "produces": ["application/json"],
+  "paths": {
+    "/users/{id}": {
+      "get": {
+        "responses": {
+          "200": {
+            "schema": {"$ref": "#/definitions/User"},
+            "headers": {"X-Rate-Limit": {"type": "integer"}}
+          }
+        }
+      }
+    }
+  },
+  "definitions": {
+    "User": {"type": "object", "required": ["id"]}
+  }
+}`
+
+func contract(t *testing.T, doc, method, url string) *Contract {
+	t.Helper()
+	set, err := ParseContracts([]byte(doc))
+	require.NoError(t, err)
+	c := set.Match(method, url)
+	require.NotNil(t, c, "no contract for %s %s", method, url)
+	return c
+}
+
+func jsonResponse(status int, body string) *domain.Response {
+	return &domain.Response{
+		StatusCode: status,
+		Headers:    map[string]string{"Content-Type": "application/json; charset=utf-8"},
+		Body:       body,
+	}
+}
+
+func TestParseContracts(t *testing.T) {
+	set, err := ParseContracts([]byte(usersV3))
+	require.NoError(t, err)
+	assert.Equal(t, "Users", set.Title)
+
+	var ops []string
+	for _, c := range set.Contracts {
+		ops = append(ops, c.Method+" "+c.Path)
+	}
+	assert.Equal(t, []string{"GET /users", "GET /users/me", "GET /users/{id}", "HEAD /users/{id}"}, ops)
+
+	_, err = ParseContracts([]byte("swagger: '1.2'"))
+	assert.ErrorContains(t, err, "unsupported document")
+
+	_, err = ParseContracts([]byte(`
+openapi: 3.0.0
+paths:
+  /a:
+    get:
+      responses:
+        "200": {$ref: '#/components/responses/Missing'}
+`))
+	assert.ErrorContains(t, err, `GET /a: unresolved reference "#/components/responses/Missing"`)
+}
+
+func TestContractSet_Match(t *testing.T) {
+	set, err := ParseContracts([]byte(usersV3))
+	require.NoError(t, err)
+
+	tests := []struct {
+		method, url, want string
+	}{
+		{"GET", "https://api.example.com/v1/users", "/users"},
+		{"GET", "https://api.example.com/v1/users/", "/users"},
+		{"GET", "https://api.example.com/users/42?expand=true", "/users/{id}"},
+		{"get", "https://api.example.com/users/me", "/users/me"},
+		{"HEAD", "https://api.example.com/users/42", "/users/{id}"},
+		{"DELETE", "https://api.example.com/users/42", ""},
+		{"GET", "https://api.example.com/accounts", ""},
+		{"GET", "https://api.example.com/superusers", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.url, func(t *testing.T) {
+			c := set.Match(tt.method, tt.url)
+			if tt.want == "" {
+				assert.Nil(t, c)
+				return
+			}
+			require.NotNil(t, c)
+			assert.Equal(t, tt.want, c.Path)
+		})
+	}
+}
+
+func TestContract_Check(t *testing.T) {
+	list := contract(t, usersV3, "GET", "https://api.example.com/users")
+	user := contract(t, usersV3, "GET", "https://api.example.com/users/1")
+
+	valid := jsonResponse(200, `[{"id": 1, "name": "Ada", "manager": {"id": 2}}]`)
+	valid.Headers["X-Total-Count"] = "1"
+	assert.Empty(t, list.Check(valid))
+
+	tests := []struct {
+		name     string
+		contract *Contract
+		resp     *domain.Response
+		want     []string
+	}{
+		{
+			name:     "undocumented status",
+			contract: list,
+			resp:     jsonResponse(500, `{}`),
+			want:     []string{"status: 500 is not a documented response of GET /users"},
+		},
+		{
+			name:     "missing required header and body violations",
+			contract: list,
+			resp:     jsonResponse(200, `[{"id": "1", "manager": {}}]`),
+			want: []string{
+				"header X-Total-Count: missing required header",
+				`$[0]: missing required property "name"`,
+				"$[0].id: expected integer, got string",
+				`$[0].manager: missing required property "id"`,
+			},
+		},
+		{
+			name:     "invalid header",
+			contract: list,
+			resp: &domain.Response{
+				StatusCode: 200,
+				Headers:    map[string]string{"Content-Type": "application/json", "X-Total-Count": "many"},
+				Body:       `[]`,
+			},
+			want: []string{"header X-Total-Count: expected integer, got string"},
+		},
+		{
+			name:     "status range",
+			contract: list,
+			resp: &domain.Response{
+				StatusCode: 404,
+				Headers:    map[string]string{"Content-Type": "application/problem+json"},
+				Body:       `{}`,
+			},
+			want: []string{`$: missing required property "title"`},
+		},
+		{
+			name:     "undocumented content type",
+			contract: user,
+			resp: &domain.Response{
+				StatusCode: 200,
+				Headers:    map[string]string{"Content-Type": "application/xml"},
+				Body:       `<user/>`,
+			},
+			want: []string{`content-type: "application/xml" is not documented for status 200 (expected application/json, text/*)`},
+		},
+		{
+			name:     "wildcard content type is not validated",
+			contract: user,
+			resp: &domain.Response{
+				StatusCode: 200,
+				Headers:    map[string]string{"Content-Type": "text/plain"},
+				Body:       `Ada`,
+			},
+		},
+		{
+			name:     "body is not JSON",
+			contract: user,
+			resp:     jsonResponse(200, `<html>`),
+			want:     []string{"$: response body is not JSON"},
+		},
+		{
+			name:     "default response",
+			contract: user,
+			resp: &domain.Response{
+				StatusCode: 503,
+				Headers:    map[string]string{"Content-Type": "application/problem+json"},
+				Body:       `{"title": "Unavailable"}`,
+			},
+		},
+		{
+			name:     "head has no body",
+			contract: contract(t, usersV3, "HEAD", "https://api.example.com/users/1"),
+			resp:     &domain.Response{StatusCode: 200},
+		},
+		{
+			name:     "response without content",
+			contract: contract(t, usersV3, "GET", "https://api.example.com/users/me"),
+			resp:     &domain.Response{StatusCode: 204, Body: "ignored"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.contract.Check(tt.resp))
+		})
+	}
+}
+
+func TestContract_Check_Swagger(t *testing.T) {
+	c := contract(t, usersV2, "GET", "https://api.example.com/users/7")
+
+	resp := jsonResponse(200, `{}`)
+	resp.Headers["X-Rate-Limit"] = "ten"
+	assert.Equal(t, []string{
+		"header X-Rate-Limit: expected integer, got string",
+		`$: missing required property "id"`,
+	}, c.Check(resp))
+
+	resp = jsonResponse(200, `{"id": 7}`)
+	resp.Headers["X-Rate-Limit"] = "10"
+	assert.Empty(t, c.Check(resp))
+}
+
+func TestContract_MarshalRoundTrip(t *testing.T) {
+	c := contract(t, usersV3, "GET", "https://api.example.com/users/1")
+
+	data, err := MarshalContract(c)
+	require.NoError(t, err)
+	got, err := UnmarshalContract(data)
+	require.NoError(t, err)
+	assert.Equal(t, c.Check(jsonResponse(200, `{"id": 1}`)), got.Check(jsonResponse(200, `{"id": 1}`)))
+	assert.Equal(t, []string{`$: missing required property "name"`}, got.Check(jsonResponse(200, `{"id": 1}`)))
+
+	_, err = UnmarshalContract("{")
+	assert.ErrorContains(t, err, "failed to unmarshal contract")
+}
+
+func TestParse_Contracts(t *testing.T) {
+	requests, err := Parse([]byte(usersV3), Options{BaseURL: "https://api.example.com", Contracts: true})
+	require.NoError(t, err)
+	require.NotEmpty(t, requests)
+	for _, req := range requests {
+		c, err := UnmarshalContract(req.ResponseContract)
+		require.NoError(t, err, req.Name)
+		assert.Equal(t, req.Method, c.Method)
+	}
+
+	requests, err = Parse([]byte(usersV3), Options{BaseURL: "https://api.example.com"})
+	require.NoError(t, err)
+	assert.Empty(t, requests[0].ResponseContract)
+}
@@ -0,0 +1,762 @@
+// Package openapi scaffolds saved requests from OpenAPI 3.x and Swagger 2.0 documents.
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/williajm/curly/internal/domain"
+	"github.com/williajm/curly/internal/infrastructure/faker"
+	"gopkg.in/yaml.v3"
+)
+
+// maxSchemaDepth bounds how deeply example bodies are generated so that
+// recursive schemas terminate.
+const maxSchemaDepth = 8
+
+// methods lists the operations of a path item in the order they are imported.
+var methods = []string{
+	domain.MethodGet,
+	domain.MethodPost,
+	domain.MethodPut,
+	domain.MethodPatch,
+	domain.MethodDelete,
+	domain.MethodHead,
+	domain.MethodOptions,
+}
+
+// Options controls how a document is turned into requests.
+type Options struct {
+	// Folder is the folder the requests are placed in.
+	// If empty, the document's title is used.
+	Folder string
+
+	// BaseURL replaces the document's servers. It is required when the
+	// document only declares relative server URLs or none at all.
+	BaseURL string
+
+	// Contracts attaches each operation's response contract to its request,
+	// so every execution is checked against the document.
+	Contracts bool
+
+	// Fake, if set, fills JSON bodies with random realistic data generated
+	// from the body schema instead of the document's examples.
+	Fake *faker.Faker
+}
+
+type document struct {
+	OpenAPI    string               `yaml:"openapi"`
+	Swagger    string               `yaml:"swagger"`
+	Info       info                 `yaml:"info"`
+	Servers    []server             `yaml:"servers"`
+	Paths      map[string]*pathItem `yaml:"paths"`
+	Components components           `yaml:"components"`
+
+	// Swagger 2.0 fields.
+	Host        string                `yaml:"host"`
+	BasePath    string                `yaml:"basePath"`
+	Schemes     []string              `yaml:"schemes"`
+	Consumes    []string              `yaml:"consumes"`
+	Definitions map[string]*schema    `yaml:"definitions"`
+	Parameters  map[string]*parameter `yaml:"parameters"`
+}
+
+type info struct {
+	Title string `yaml:"title"`
+}
+
+type server struct {
+	URL       string                    `yaml:"url"`
+	Variables map[string]serverVariable `yaml:"variables"`
+}
+
+type serverVariable struct {
+	Default string `yaml:"default"`
+}
+
+type components struct {
+	Schemas       map[string]*schema      `yaml:"schemas"`
+	Parameters    map[string]*parameter   `yaml:"parameters"`
+	RequestBodies map[string]*requestBody `yaml:"requestBodies"`
+}
+
+type pathItem struct {
+	Parameters []*parameter `yaml:"parameters"`
+	Get        *operation   `yaml:"get"`
+	Post       *operation   `yaml:"post"`
+	Put        *operation   `yaml:"put"`
+	Patch      *operation   `yaml:"patch"`
+	Delete     *operation   `yaml:"delete"`
+	Head       *operation   `yaml:"head"`
+	Options    *operation   `yaml:"options"`
+}
+
+type operation struct {
+	OperationID string       `yaml:"operationId"`
+	Summary     string       `yaml:"summary"`
+	Parameters  []*parameter `yaml:"parameters"`
+	RequestBody *requestBody `yaml:"requestBody"`
+	Consumes    []string     `yaml:"consumes"`
+}
+
+type parameter struct {
+	Ref      string  `yaml:"$ref"`
+	Name     string  `yaml:"name"`
+	In       string  `yaml:"in"`
+	Required bool    `yaml:"required"`
+	Example  any     `yaml:"example"`
+	Schema   *schema `yaml:"schema"`
+
+	// Swagger 2.0 describes non-body parameters inline.
+	Type    string  `yaml:"type"`
+	Format  string  `yaml:"format"`
+	Default any     `yaml:"default"`
+	Enum    []any   `yaml:"enum"`
+	Items   *schema `yaml:"items"`
+}
+
+type requestBody struct {
+	Ref     string               `yaml:"$ref"`
+	Content map[string]mediaType `yaml:"content"`
+}
+
+type mediaType struct {
+	Schema   *schema            `yaml:"schema"`
+	Example  any                `yaml:"example"`
+	Examples map[string]example `yaml:"examples"`
+}
+
+type example struct {
+	Value any `yaml:"value"`
+}
+
+type schema struct {
+	Ref        string             `yaml:"$ref"`
+	Type       any                `yaml:"type"`
+	Format     string             `yaml:"format"`
+	Properties map[string]*schema `yaml:"properties"`
+	Items      *schema            `yaml:"items"`
+	Example    any                `yaml:"example"`
+	Default    any                `yaml:"default"`
+	Enum       []any              `yaml:"enum"`
+	AllOf      []*schema          `yaml:"allOf"`
+	OneOf      []*schema          `yaml:"oneOf"`
+	AnyOf      []*schema          `yaml:"anyOf"`
+}
+
+// Parse reads an OpenAPI 3.x or Swagger 2.0 document in JSON or YAML and
+// returns one request per operation, ordered by path and then method.
+// Path parameters without an example keep their {name} placeholder.
+func Parse(data []byte, opts Options) ([]*domain.Request, error) {
+	doc, err := decode(data)
+	if err != nil {
+		return nil, err
+	}
+
+	swagger, err := doc.isSwagger()
+	if err != nil {
+		return nil, err
+	}
+
+	base, err := doc.baseURL(opts.BaseURL, swagger)
+	if err != nil {
+		return nil, err
+	}
+
+	folder := opts.Folder
+	if folder == "" {
+		folder = doc.Info.Title
+	}
+
+	contracts := map[string]*Contract{}
+	if opts.Contracts {
+		if contracts, err = contractIndex(data); err != nil {
+			return nil, err
+		}
+	}
+
+	var raw map[string]any
+	if opts.Fake != nil {
+		if raw, err = decodeRaw(data); err != nil {
+			return nil, err
+		}
+	}
+
+	var requests []*domain.Request
+	for _, path := range doc.sortedPaths() {
+		item := doc.Paths[path]
+		for _, method := range methods {
+			op := item.operation(method)
+			if op == nil {
+				continue
+			}
+			req, err := doc.request(base, path, method, item, op, swagger)
+			if err != nil {
+				return nil, fmt.Errorf("%s %s: %w", method, path, err)
+			}
+			if err := fakeBody(req, raw, path, method, swagger, opts.Fake); err != nil {
+				return nil, fmt.Errorf("%s %s: %w", method, path, err)
+			}
+			req.Folder = folder
+			req.Position = len(requests)
+			if req.ResponseContract, err = marshalContract(contracts[method+" "+path]); err != nil {
+				return nil, err
+			}
+			requests = append(requests, req)
+		}
+	}
+
+	return requests, nil
+}
+
+// isSwagger reports whether the document is Swagger 2.0 rather than
+// OpenAPI 3.x, or returns an error if it is neither.
+func (d *document) isSwagger() (bool, error) {
+	switch {
+	case strings.HasPrefix(d.OpenAPI, "3."):
+		return false, nil
+	case d.Swagger == "2.0":
+		return true, nil
+	default:
+		return false, fmt.Errorf("unsupported document: expected openapi 3.x or swagger 2.0")
+	}
+}
+
+// sortedPaths returns the document's paths in order.
+func (d *document) sortedPaths() []string {
+	paths := make([]string, 0, len(d.Paths))
+	for path := range d.Paths {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// contractIndex parses the response contracts of a document, keyed by
+// method and path.
+func contractIndex(data []byte) (map[string]*Contract, error) {
+	set, err := ParseContracts(data)
+	if err != nil {
+		return nil, err
+	}
+	contracts := make(map[string]*Contract, len(set.Contracts))
+	for _, c := range set.Contracts {
+		contracts[c.Method+" "+c.Path] = c
+	}
+	return contracts, nil
+}
+
+// marshalContract encodes an operation's contract, or returns an empty
+// string if the operation has none.
+func marshalContract(c *Contract) (string, error) {
+	if c == nil {
+		return "", nil
+	}
+	return MarshalContract(c)
+}
+
+// decode unmarshals a JSON or YAML document.
+// JSON is converted through a generic value because it may contain tab
+// indentation, which YAML does not allow.
+func decode(data []byte) (*document, error) {
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '{' {
+		var raw any
+		if err := json.Unmarshal(trimmed, &raw); err != nil {
+			return nil, fmt.Errorf("failed to parse document: %w", err)
+		}
+		converted, err := yaml.Marshal(raw)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse document: %w", err)
+		}
+		data = converted
+	}
+
+	var doc document
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse document: %w", err)
+	}
+	return &doc, nil
+}
+
+// baseURL returns the URL operation paths are appended to.
+// Server variables are replaced by their defaults.
+func (d *document) baseURL(override string, swagger bool) (string, error) {
+	var base string
+	switch {
+	case swagger && d.Host != "":
+		scheme := "https"
+		if len(d.Schemes) > 0 {
+			scheme = d.Schemes[0]
+		}
+		base = scheme + "://" + d.Host + d.BasePath
+	case swagger:
+		base = d.BasePath
+	case len(d.Servers) > 0:
+		base = d.Servers[0].URL
+		for name, variable := range d.Servers[0].Variables {
+			base = strings.ReplaceAll(base, "{"+name+"}", variable.Default)
+		}
+	}
+
+	if override != "" {
+		parsed, err := url.Parse(base)
+		if err != nil || parsed.IsAbs() {
+			base = override
+		} else {
+			// Keep a relative server path such as /v1 under the override.
+			base = strings.TrimSuffix(override, "/") + "/" + strings.TrimPrefix(base, "/")
+		}
+	}
+
+	parsed, err := url.Parse(base)
+	if err != nil || !parsed.IsAbs() || parsed.Host == "" {
+		return "", fmt.Errorf("document has no absolute server URL; a base URL is required")
+	}
+	return strings.TrimSuffix(base, "/"), nil
+}
+
+// operation returns the operation for method, or nil if the path has none.
+func (p *pathItem) operation(method string) *operation {
+	if p == nil {
+		return nil
+	}
+	switch method {
+	case domain.MethodGet:
+		return p.Get
+	case domain.MethodPost:
+		return p.Post
+	case domain.MethodPut:
+		return p.Put
+	case domain.MethodPatch:
+		return p.Patch
+	case domain.MethodDelete:
+		return p.Delete
+	case domain.MethodHead:
+		return p.Head
+	case domain.MethodOptions:
+		return p.Options
+	default:
+		return nil
+	}
+}
+
+// request builds the request for a single operation.
+func (d *document) request(base, path, method string, item *pathItem, op *operation, swagger bool) (*domain.Request, error) {
+	req := domain.NewRequestWithMethodAndURL(method, "")
+	req.Name = operationName(method, path, op)
+
+	params, err := d.parameters(item.Parameters, op.Parameters)
+	if err != nil {
+		return nil, err
+	}
+
+	form := url.Values{}
+	for _, param := range params {
+		if path, err = d.applyParameter(req, op, param, path, form); err != nil {
+			return nil, err
+		}
+	}
+	if len(form) > 0 {
+		req.Body = form.Encode()
+		req.SetHeader("Content-Type", "application/x-www-form-urlencoded")
+	}
+
+	if !swagger && op.RequestBody != nil {
+		if err := d.setBody(req, op.RequestBody); err != nil {
+			return nil, err
+		}
+	}
+
+	req.URL = base + path
+	return req, nil
+}
+
+// applyParameter sets a parameter's example value on req according to its
+// location, collecting form fields in form. It returns path with the
+// parameter filled in.
+func (d *document) applyParameter(req *domain.Request, op *operation, param *parameter, path string, form url.Values) (string, error) {
+	value, ok := d.parameterValue(param)
+	switch param.In {
+	case "path":
+		if ok {
+			path = strings.ReplaceAll(path, "{"+param.Name+"}", url.PathEscape(value))
+		}
+	case "query":
+		if ok || param.Required {
+			req.SetQueryParam(param.Name, value)
+		}
+	case "header":
+		if ok || param.Required {
+			req.SetHeader(param.Name, value)
+		}
+	case "body":
+		body, err := json.MarshalIndent(d.sample(param.Schema, 0), "", "  ")
+		if err != nil {
+			return "", fmt.Errorf("failed to encode example body: %w", err)
+		}
+		req.Body = string(body)
+		req.SetHeader("Content-Type", contentType(d.consumes(op), "application/json"))
+	case "formData":
+		form.Set(param.Name, value)
+	}
+	return path, nil
+}
+
+// operationName picks a readable name for an operation.
+func operationName(method, path string, op *operation) string {
+	switch {
+	case op.Summary != "":
+		return op.Summary
+	case op.OperationID != "":
+		return op.OperationID
+	default:
+		return method + " " + path
+	}
+}
+
+// parameters resolves and merges path-level and operation-level parameters.
+// Operation parameters override path parameters with the same name and location.
+func (d *document) parameters(pathParams, opParams []*parameter) ([]*parameter, error) {
+	var merged []*parameter
+	index := make(map[string]int)
+	for _, list := range [][]*parameter{pathParams, opParams} {
+		for _, param := range list {
+			resolved, err := d.resolveParameter(param)
+			if err != nil {
+				return nil, err
+			}
+			key := resolved.In + ":" + resolved.Name
+			if i, ok := index[key]; ok {
+				merged[i] = resolved
+				continue
+			}
+			index[key] = len(merged)
+			merged = append(merged, resolved)
+		}
+	}
+	return merged, nil
+}
+
+// resolveParameter follows a parameter $ref.
+func (d *document) resolveParameter(param *parameter) (*parameter, error) {
+	if param == nil {
+		return &parameter{}, nil
+	}
+	if param.Ref == "" {
+		return param, nil
+	}
+
+	var resolved *parameter
+	if name, ok := strings.CutPrefix(param.Ref, "#/components/parameters/"); ok {
+		resolved = d.Components.Parameters[name]
+	} else if name, ok := strings.CutPrefix(param.Ref, "#/parameters/"); ok {
+		resolved = d.Parameters[name]
+	}
+	if resolved == nil {
+		return nil, fmt.Errorf("unresolved parameter reference %q", param.Ref)
+	}
+	return resolved, nil
+}
+
+// parameterValue returns the example value for a parameter.
+// ok is false when the document gives no explicit value and one was generated from the type.
+func (d *document) parameterValue(param *parameter) (string, bool) {
+	for _, v := range []any{param.Example, param.Default, first(param.Enum)} {
+		if v != nil {
+			return formatValue(v), true
+		}
+	}
+
+	s := param.Schema
+	if s == nil {
+		s = &schema{Type: param.Type, Format: param.Format, Items: param.Items}
+	}
+	s = d.resolveSchema(s)
+	for _, v := range []any{s.Example, s.Default, first(s.Enum)} {
+		if v != nil {
+			return formatValue(v), true
+		}
+	}
+
+	return formatValue(d.sample(s, 0)), false
+}
+
+// setBody fills the request body from an OpenAPI 3 request body, preferring JSON.
+func (d *document) setBody(req *domain.Request, body *requestBody) error {
+	if body.Ref != "" {
+		name, _ := strings.CutPrefix(body.Ref, "#/components/requestBodies/")
+		resolved := d.Components.RequestBodies[name]
+		if resolved == nil {
+			return fmt.Errorf("unresolved request body reference %q", body.Ref)
+		}
+		body = resolved
+	}
+	if len(body.Content) == 0 {
+		return nil
+	}
+
+	types := make([]string, 0, len(body.Content))
+	for t := range body.Content {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+	mediaTypeName := contentType(types, types[0])
+	media := body.Content[mediaTypeName]
+
+	value := media.Example
+	if value == nil && len(media.Examples) > 0 {
+		names := make([]string, 0, len(media.Examples))
+		for name := range media.Examples {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		value = media.Examples[names[0]].Value
+	}
+	if value == nil {
+		value = d.sample(media.Schema, 0)
+	}
+
+	req.SetHeader("Content-Type", mediaTypeName)
+	switch {
+	case isJSON(mediaTypeName):
+		encoded, err := json.MarshalIndent(value, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to encode example body: %w", err)
+		}
+		req.Body = string(encoded)
+	case mediaTypeName == "application/x-www-form-urlencoded":
+		form := url.Values{}
+		if fields, ok := value.(map[string]any); ok {
+			for k, v := range fields {
+				form.Set(k, formatValue(v))
+			}
+		}
+		req.Body = form.Encode()
+	default:
+		if s, ok := value.(string); ok {
+			req.Body = s
+		}
+	}
+	return nil
+}
+
+// fakeBody replaces a JSON request body with random data generated from the
+// operation's body schema. Requests without a JSON body, or without a faker,
+// are left alone.
+func fakeBody(req *domain.Request, raw map[string]any, path, method string, swagger bool, f *faker.Faker) error {
+	mediaTypeName := req.Headers["Content-Type"]
+	if f == nil || !isJSON(mediaTypeName) {
+		return nil
+	}
+	op, _ := nested(raw, "paths", path, strings.ToLower(method)).(map[string]any)
+
+	var schema any
+	if swagger {
+		params, _ := nested(raw, "paths", path, "parameters").([]any)
+		opParams, _ := op["parameters"].([]any)
+		for _, p := range append(params, opParams...) {
+			param, err := resolveRef(raw, p)
+			if err != nil {
+				return err
+			}
+			if nested(param, "in") == "body" {
+				schema = nested(param, "schema")
+			}
+		}
+	} else {
+		body, err := resolveRef(raw, op["requestBody"])
+		if err != nil {
+			return err
+		}
+		schema = nested(body, "content", mediaTypeName, "schema")
+	}
+	if schema == nil {
+		return nil
+	}
+
+	encoded, err := json.MarshalIndent(f.FromSchema(raw, schema), "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode fake body: %w", err)
+	}
+	req.Body = string(encoded)
+	return nil
+}
+
+// consumes returns the media types a Swagger 2.0 operation accepts.
+func (d *document) consumes(op *operation) []string {
+	if len(op.Consumes) > 0 {
+		return op.Consumes
+	}
+	return d.Consumes
+}
+
+// contentType picks a JSON media type from types, falling back to fallback.
+func contentType(types []string, fallback string) string {
+	for _, t := range types {
+		if isJSON(t) {
+			return t
+		}
+	}
+	return fallback
+}
+
+// isJSON reports whether a media type carries JSON.
+func isJSON(mediaType string) bool {
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
+
+// resolveSchema follows schema $refs. Unknown references resolve to an empty schema.
+func (d *document) resolveSchema(s *schema) *schema {
+	for i := 0; s != nil && s.Ref != "" && i < maxSchemaDepth; i++ {
+		var next *schema
+		if name, ok := strings.CutPrefix(s.Ref, "#/components/schemas/"); ok {
+			next = d.Components.Schemas[name]
+		} else if name, ok := strings.CutPrefix(s.Ref, "#/definitions/"); ok {
+			next = d.Definitions[name]
+		}
+		s = next
+	}
+	if s == nil || s.Ref != "" {
+		return &schema{}
+	}
+	return s
+}
+
+// sample generates an example value for a schema, preferring the examples,
+// defaults and enums it declares.
+func (d *document) sample(s *schema, depth int) any {
+	if s == nil || depth > maxSchemaDepth {
+		return nil
+	}
+	s = d.resolveSchema(s)
+
+	for _, v := range []any{s.Example, s.Default, first(s.Enum)} {
+		if v != nil {
+			return v
+		}
+	}
+
+	if v, ok := d.sampleCombined(s, depth); ok {
+		return v
+	}
+	return d.sampleType(s, depth)
+}
+
+// sampleCombined generates an example value for an allOf, oneOf or anyOf
+// schema: the merged fields of every allOf part, or the first alternative.
+// It reports false if the schema combines nothing.
+func (d *document) sampleCombined(s *schema, depth int) (any, bool) {
+	if len(s.AllOf) > 0 {
+		merged := map[string]any{}
+		for _, part := range s.AllOf {
+			if fields, ok := d.sample(part, depth+1).(map[string]any); ok {
+				for k, v := range fields {
+					merged[k] = v
+				}
+			}
+		}
+		return merged, true
+	}
+	if len(s.OneOf) > 0 {
+		return d.sample(s.OneOf[0], depth+1), true
+	}
+	if len(s.AnyOf) > 0 {
+		return d.sample(s.AnyOf[0], depth+1), true
+	}
+	return nil, false
+}
+
+// sampleType generates an example value from a schema's type.
+func (d *document) sampleType(s *schema, depth int) any {
+	switch schemaType(s) {
+	case "object":
+		fields := map[string]any{}
+		for name, prop := range s.Properties {
+			fields[name] = d.sample(prop, depth+1)
+		}
+		return fields
+	case "array":
+		if s.Items == nil {
+			return []any{}
+		}
+		return []any{d.sample(s.Items, depth+1)}
+	case "integer":
+		return 0
+	case "number":
+		return 0.0
+	case "boolean":
+		return false
+	case "string":
+		return sampleString(s.Format)
+	default:
+		return nil
+	}
+}
+
+// schemaType returns a schema's type. OpenAPI 3.1 allows a list of types, in
+// which case the first non-null one is used, and schemas with properties but
+// no type are treated as objects.
+func schemaType(s *schema) string {
+	switch t := s.Type.(type) {
+	case string:
+		return t
+	case []any:
+		for _, v := range t {
+			if name, ok := v.(string); ok && name != "null" {
+				return name
+			}
+		}
+	}
+	if len(s.Properties) > 0 {
+		return "object"
+	}
+	return ""
+}
+
+// sampleString returns a placeholder string matching a format.
+func sampleString(format string) string {
+	switch format {
+	case "date-time":
+		return "2024-01-01T00:00:00Z"
+	case "date":
+		return "2024-01-01"
+	case "email":
+		return "user@example.com"
+	case "uuid":
+		return "00000000-0000-0000-0000-000000000000"
+	case "uri", "url":
+		return "https://example.com"
+	default:
+		return "string"
+	}
+}
+
+// first returns the first element of values, or nil if it is empty.
+func first(values []any) any {
+	if len(values) == 0 {
+		return nil
+	}
+	return values[0]
+}
+
+// formatValue renders a parameter value as text.
+func formatValue(v any) string {
+	switch value := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return value
+	case int, int64, float64, bool:
+		return fmt.Sprint(value)
+	default:
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return fmt.Sprint(value)
+		}
+		return string(encoded)
+	}
+}
@@ -0,0 +1,358 @@
+package openapi
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/williajm/curly/internal/domain"
+	"github.com/williajm/curly/internal/infrastructure/faker"
+)
+
+const petstoreV3 = `
+openapi: 3.0.3
+info:
+  title: Petstore
+servers:
+  - url: https://{env}.example.com/v1
+    variables:
+      env:
+        default: api
+paths:
+  /pets:
+    get:
+      summary: List pets
+      parameters:
+        - name: limit
+          in: query
+          schema:
+            type: integer
+            example: 20
+        - name: tag
+          in: query
+          schema:
+            type: string
+        - $ref: '#/components/parameters/TraceID'
+    post:
+      operationId: createPet
+      requestBody:
+        content:
+          application/json:
+            schema:
+              $ref: '#/components/schemas/NewPet'
+  /pets/{petId}:
+    parameters:
+      - name: petId
+        in: path
+        required: true
+        schema:
+          type: string
+    get:
+      responses: {}
+    delete:
+      summary: Delete pet
+      parameters:
+        - name: petId
+          in: path
+          required: true
+          example: 42
+components:
+  parameters:
+    TraceID:
+      name: X-Trace-ID
+      in: header
+      required: true
+      schema:
+        type: string
+        format: uuid
+  schemas:
+    NewPet:
+      type: object
+      properties:
+        name:
+          type: string
+          example: Rex
+        tags:
+          type: array
+          items:
+            type: string
+        owner:
+          $ref: '#/components/schemas/Owner'
+    Owner:
+      type: object
+      properties:
+        email:
+          type: string
+          format: email
+        pets:
+          type: array
+          items:
+            $ref: '#/components/schemas/NewPet'
+`
+
+func findRequest(t *testing.T, requests []*domain.Request, method, url string) *domain.Request {
+	t.Helper()
+	for _, req := range requests {
+		if req.Method == method && req.URL == url {
+			return req
+		}
+	}
+	t.Fatalf("no %s %s request in %d results", method, url, len(requests))
+	return nil
+}
+
+func TestParse_OpenAPI3(t *testing.T) {
+	requests, err := Parse([]byte(petstoreV3), Options{})
+	require.NoError(t, err)
+	require.Len(t, requests, 4)
+
+	// Ordered by path, then method, with positions following that order.
+	var order []string
+	for i, req := range requests {
+		order = append(order, req.Method+" "+req.URL)
+		assert.Equal(t, "Petstore", req.Folder)
+		assert.Equal(t, i, req.Position)
+		assert.NoError(t, req.Validate())
+	}
+	assert.Equal(t, []string{
+		"GET https://api.example.com/v1/pets",
+		"POST https://api.example.com/v1/pets",
+		"GET https://api.example.com/v1/pets/{petId}",
+		"DELETE https://api.example.com/v1/pets/42",
+	}, order)
+
+	list := requests[0]
+	assert.Equal(t, "List pets", list.Name)
+	assert.Equal(t, map[string]string{"limit": "20"}, list.QueryParams)
+	assert.Equal(t, "00000000-0000-0000-0000-000000000000", list.Headers["X-Trace-ID"])
+
+	create := requests[1]
+	assert.Equal(t, "createPet", create.Name)
+	assert.Equal(t, "application/json", create.Headers["Content-Type"])
+	var body map[string]any
+	require.NoError(t, json.Unmarshal([]byte(create.Body), &body))
+	assert.Equal(t, "Rex", body["name"])
+	assert.Equal(t, []any{"string"}, body["tags"])
+	assert.Equal(t, "user@example.com", body["owner"].(map[string]any)["email"])
+
+	assert.Equal(t, "GET /pets/{petId}", requests[2].Name)
+}
+
+func TestParse_OperationParameterOverridesPathParameter(t *testing.T) {
+	doc := `
+openapi: 3.0.0
+info: {title: Pets}
+servers: [{url: "https://api.example.com"}]
+paths:
+  /pets/{petId}:
+    parameters:
+      - {name: petId, in: path, required: true, schema: {type: string}}
+    delete:
+      parameters:
+        - {name: petId, in: path, required: true, example: 42}
+`
+	requests, err := Parse([]byte(doc), Options{})
+	require.NoError(t, err)
+	require.Len(t, requests, 1)
+	assert.Equal(t, "https://api.example.com/pets/42", requests[0].URL)
+}
+
+func TestParse_Swagger2JSON(t *testing.T) {
+	doc := `{
+	"swagger": "2.0",
+	"info": {"title": "Users"},
+	"host": "users.example.com",
+	"basePath": "/api",
+	"schemes": ["http"],
+	"consumes": ["application/json"],
+	"definitions": {
+		"User": {"properties": {"name": {"type": "string"}, "age": {"type": "integer"}}}
+	},
+	"paths": {
+		"/users": {
+			"post": {
+				"summary": "Create user",
+				"parameters": [{"name": "user", "in": "body", "schema": {"$ref": "#/definitions/User"}}]
+			}
+		},
+		"/login": {
+			"post": {
+				"parameters": [
+					{"name": "username", "in": "formData", "type": "string", "default": "alice"},
+					{"name": "remember", "in": "formData", "type": "boolean"}
+				]
+			}
+		}
+	}
+}`
+	requests, err := Parse([]byte(doc), Options{Folder: "imported"})
+	require.NoError(t, err)
+	require.Len(t, requests, 2)
+
+	login := findRequest(t, requests, domain.MethodPost, "http://users.example.com/api/login")
+	assert.Equal(t, "imported", login.Folder)
+	assert.Equal(t, "application/x-www-form-urlencoded", login.Headers["Content-Type"])
+	assert.Equal(t, "remember=false&username=alice", login.Body)
+
+	create := findRequest(t, requests, domain.MethodPost, "http://users.example.com/api/users")
+	assert.Equal(t, "Create user", create.Name)
+	assert.JSONEq(t, `{"name": "string", "age": 0}`, create.Body)
+	assert.Equal(t, "application/json", create.Headers["Content-Type"])
+}
+
+func TestParse_BaseURL(t *testing.T) {
+	doc := `
+openapi: 3.1.0
+info: {title: Relative}
+servers: [{url: /v2}]
+paths:
+  /status:
+    get: {}
+`
+	_, err := Parse([]byte(doc), Options{})
+	assert.ErrorContains(t, err, "base URL is required")
+
+	requests, err := Parse([]byte(doc), Options{BaseURL: "http://localhost:8080/"})
+	require.NoError(t, err)
+	assert.Equal(t, "http://localhost:8080/v2/status", requests[0].URL)
+
+	absolute := `
+openapi: 3.0.0
+info: {title: Absolute}
+servers: [{url: "https://prod.example.com"}]
+paths:
+  /status:
+    get: {}
+`
+	requests, err = Parse([]byte(absolute), Options{BaseURL: "http://localhost:8080"})
+	require.NoError(t, err)
+	assert.Equal(t, "http://localhost:8080/status", requests[0].URL)
+}
+
+func TestParse_MediaTypeExamples(t *testing.T) {
+	doc := `
+openapi: 3.0.0
+info: {title: Examples}
+servers: [{url: "https://api.example.com"}]
+paths:
+  /orders:
+    post:
+      requestBody:
+        content:
+          application/xml:
+            schema: {type: string}
+          application/vnd.api+json:
+            examples:
+              b: {value: {id: 2}}
+              a: {value: {id: 1}}
+  /notes:
+    put:
+      requestBody:
+        content:
+          text/plain:
+            example: hello
+`
+	requests, err := Parse([]byte(doc), Options{})
+	require.NoError(t, err)
+
+	order := findRequest(t, requests, domain.MethodPost, "https://api.example.com/orders")
+	assert.Equal(t, "application/vnd.api+json", order.Headers["Content-Type"])
+	assert.JSONEq(t, `{"id": 1}`, order.Body)
+
+	note := findRequest(t, requests, domain.MethodPut, "https://api.example.com/notes")
+	assert.Equal(t, "text/plain", note.Headers["Content-Type"])
+	assert.Equal(t, "hello", note.Body)
+}
+
+func TestParse_SchemaComposition(t *testing.T) {
+	doc := `
+openapi: 3.1.0
+info: {title: Composition}
+servers: [{url: "https://api.example.com"}]
+paths:
+  /items:
+    post:
+      requestBody:
+        content:
+          application/json:
+            schema:
+              allOf:
+                - {properties: {id: {type: [integer, "null"]}}}
+                - {properties: {kind: {enum: [book, film]}}}
+                - {properties: {price: {oneOf: [{type: number}, {type: string}]}}}
+`
+	requests, err := Parse([]byte(doc), Options{})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"id": 0, "kind": "book", "price": 0}`, requests[0].Body)
+}
+
+func TestParse_Fake(t *testing.T) {
+	requests, err := Parse([]byte(petstoreV3), Options{Fake: faker.New(1)})
+	require.NoError(t, err)
+
+	create := findRequest(t, requests, domain.MethodPost, "https://api.example.com/v1/pets")
+	var body map[string]any
+	require.NoError(t, json.Unmarshal([]byte(create.Body), &body))
+	assert.IsType(t, "", body["name"])
+	assert.NotEmpty(t, body["tags"])
+	email := body["owner"].(map[string]any)["email"]
+	assert.Contains(t, email, "@")
+	assert.NotEqual(t, "user@example.com", email)
+
+	swagger := `{
+	"swagger": "2.0",
+	"info": {"title": "Users"},
+	"host": "users.example.com",
+	"definitions": {"User": {"properties": {"email": {"type": "string"}, "age": {"type": "integer", "minimum": 18, "maximum": 18}}}},
+	"paths": {"/users": {"post": {"parameters": [{"name": "user", "in": "body", "schema": {"$ref": "#/definitions/User"}}]}}}
+}`
+	requests, err = Parse([]byte(swagger), Options{Fake: faker.New(1)})
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal([]byte(requests[0].Body), &body))
+	assert.EqualValues(t, 18, body["age"])
+	assert.Contains(t, body["email"], "@")
+}
+
+func TestParse_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		doc  string
+		want string
+	}{
+		{
+			name: "invalid yaml",
+			doc:  "openapi: [",
+			want: "failed to parse document",
+		},
+		{
+			name: "invalid json",
+			doc:  `{"openapi": `,
+			want: "failed to parse document",
+		},
+		{
+			name: "unsupported version",
+			doc:  "swagger: '1.2'\n",
+			want: "unsupported document",
+		},
+		{
+			name: "unresolved parameter",
+			doc: `
+openapi: 3.0.0
+servers: [{url: "https://api.example.com"}]
+paths:
+  /x:
+    get:
+      parameters: [{$ref: '#/components/parameters/Missing'}]
+`,
+			want: "unresolved parameter reference",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse([]byte(tt.doc), Options{})
+			assert.ErrorContains(t, err, tt.want)
+		})
+	}
+}
@@ -0,0 +1,180 @@
+package postman
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+
+	"github.com/williajm/curly/internal/domain"
+)
+
+// schemaV21 identifies the collection format written by Export.
+const schemaV21 = "https://schema.getpostman.com/json/collection/v2.1.0/collection.json"
+
+// exportItem is a request or folder of an exported collection.
+type exportItem struct {
+	Name    string         `json:"name"`
+	Item    []*exportItem  `json:"item,omitempty"`
+	Request *exportRequest `json:"request,omitempty"`
+}
+
+type exportRequest struct {
+	Method string       `json:"method"`
+	Header []exportPair `json:"header"`
+	URL    exportURL    `json:"url"`
+	Body   *exportBody  `json:"body,omitempty"`
+	Auth   *exportAuth  `json:"auth,omitempty"`
+}
+
+type exportURL struct {
+	Raw string `json:"raw"`
+}
+
+type exportPair struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+	Type  string `json:"type,omitempty"`
+}
+
+type exportBody struct {
+	Mode    string         `json:"mode"`
+	Raw     string         `json:"raw"`
+	Options *exportOptions `json:"options,omitempty"`
+}
+
+type exportOptions struct {
+	Raw struct {
+		Language string `json:"language"`
+	} `json:"raw"`
+}
+
+type exportAuth struct {
+	Type   string       `json:"type"`
+	Bearer []exportPair `json:"bearer,omitempty"`
+	Basic  []exportPair `json:"basic,omitempty"`
+	APIKey []exportPair `json:"apikey,omitempty"`
+}
+
+// Export writes requests as a Postman v2.1 collection named name. Requests
+// in root are placed at the top of the collection and those in folders nested
+// in it, such as "Shop/Users" with root "Shop", in Postman folders; so Parse
+// with folder root reads them back into the same folders. Within a folder,
+// requests keep their curated order and come before its subfolders.
+func Export(name, root string, requests []*domain.Request) ([]byte, error) {
+	sorted := make([]*domain.Request, len(requests))
+	copy(sorted, requests)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].Folder != sorted[j].Folder {
+			return sorted[i].Folder < sorted[j].Folder
+		}
+		return sorted[i].Position < sorted[j].Position
+	})
+
+	top := &exportItem{Name: name, Item: []*exportItem{}}
+	folders := map[string]*exportItem{"": top}
+	var folderOf func(path string) *exportItem
+	folderOf = func(path string) *exportItem {
+		if folder, ok := folders[path]; ok {
+			return folder
+		}
+		parent, base := "", path
+		if i := strings.LastIndex(path, "/"); i >= 0 {
+			parent, base = path[:i], path[i+1:]
+		}
+		folder := &exportItem{Name: base, Item: []*exportItem{}}
+		folders[path] = folder
+		enclosing := folderOf(parent)
+		enclosing.Item = append(enclosing.Item, folder)
+		return folder
+	}
+
+	// Requests are added before subfolders are created for them, so the
+	// sorted order puts a folder's requests ahead of its subfolders.
+	for _, req := range sorted {
+		folder := folderOf(relativeFolder(root, req.Folder))
+		folder.Item = append(folder.Item, &exportItem{Name: req.Name, Request: exportRequestOf(req)})
+	}
+
+	collection := struct {
+		Info struct {
+			Name   string `json:"name"`
+			Schema string `json:"schema"`
+		} `json:"info"`
+		Item []*exportItem `json:"item"`
+	}{Item: top.Item}
+	collection.Info.Name = name
+	collection.Info.Schema = schemaV21
+
+	return json.MarshalIndent(collection, "", "  ")
+}
+
+// relativeFolder returns the path of folder inside root.
+func relativeFolder(root, folder string) string {
+	if root == "" {
+		return folder
+	}
+	if folder == root {
+		return ""
+	}
+	return strings.TrimPrefix(folder, root+"/")
+}
+
+// exportRequestOf converts a saved request.
+func exportRequestOf(req *domain.Request) *exportRequest {
+	fullURL, err := req.FullURL()
+	if err != nil {
+		fullURL = req.URL
+	}
+	r := &exportRequest{
+		Method: req.Method,
+		Header: []exportPair{},
+		URL:    exportURL{Raw: fullURL},
+	}
+
+	names := make([]string, 0, len(req.Headers))
+	for name := range req.Headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	contentType := ""
+	for _, name := range names {
+		r.Header = append(r.Header, exportPair{Key: name, Value: req.Headers[name]})
+		if strings.EqualFold(name, "Content-Type") {
+			contentType = strings.ToLower(req.Headers[name])
+		}
+	}
+
+	if req.Body != "" {
+		r.Body = &exportBody{Mode: "raw", Raw: req.Body}
+		language := ""
+		switch {
+		case strings.Contains(contentType, "json"):
+			language = "json"
+		case strings.Contains(contentType, "xml"):
+			language = "xml"
+		}
+		if language != "" {
+			r.Body.Options = &exportOptions{}
+			r.Body.Options.Raw.Language = language
+		}
+	}
+
+	switch auth := req.AuthConfig.(type) {
+	case *domain.BearerAuth:
+		r.Auth = &exportAuth{Type: "bearer", Bearer: []exportPair{
+			{Key: "token", Value: auth.Token, Type: "string"},
+		}}
+	case *domain.BasicAuth:
+		r.Auth = &exportAuth{Type: "basic", Basic: []exportPair{
+			{Key: "username", Value: auth.Username, Type: "string"},
+			{Key: "password", Value: auth.Password, Type: "string"},
+		}}
+	case *domain.APIKeyAuth:
+		r.Auth = &exportAuth{Type: "apikey", APIKey: []exportPair{
+			{Key: "key", Value: auth.Key, Type: "string"},
+			{Key: "value", Value: auth.Value, Type: "string"},
+			{Key: "in", Value: string(auth.Location), Type: "string"},
+		}}
+	}
+	return r
+}
@@ -0,0 +1,93 @@
+package postman
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/williajm/curly/internal/domain"
+)
+
+func exportRequests() []*domain.Request {
+	create := domain.NewRequestWithMethodAndURL("POST", "https://shop.example.com/users")
+	create.Name = "Create user"
+	create.Folder = "Shop/Users"
+	create.Position = 1
+	create.Headers["Content-Type"] = "application/json"
+	create.Body = `{"name": "Ada"}`
+	create.SetAuth(domain.NewBearerAuth("{{token}}"))
+
+	list := domain.NewRequestWithMethodAndURL("GET", "https://shop.example.com/users")
+	list.Name = "List users"
+	list.Folder = "Shop/Users"
+	list.SetQueryParam("page", "2")
+	list.SetAuth(domain.NewAPIKeyAuth("api_key", "secret", domain.APIKeyLocationQuery))
+
+	health := domain.NewRequestWithMethodAndURL("GET", "https://shop.example.com/health")
+	health.Name = "Health"
+	health.Folder = "Shop"
+	health.SetAuth(domain.NewBasicAuth("ada", "pw"))
+
+	return []*domain.Request{create, list, health}
+}
+
+func TestExport_RoundTrip(t *testing.T) {
+	data, err := Export("Shop", "Shop", exportRequests())
+	require.NoError(t, err)
+
+	result, err := Parse(data, "Shop")
+	require.NoError(t, err)
+	assert.Empty(t, result.Warnings)
+	require.Len(t, result.Requests, 3)
+
+	health, list, create := result.Requests[0], result.Requests[1], result.Requests[2]
+
+	assert.Equal(t, "Health", health.Name)
+	assert.Equal(t, "Shop", health.Folder)
+	assert.Equal(t, domain.NewBasicAuth("ada", "pw"), health.AuthConfig)
+
+	assert.Equal(t, "List users", list.Name)
+	assert.Equal(t, "Shop/Users", list.Folder)
+	assert.Equal(t, "https://shop.example.com/users?page=2", list.URL)
+	assert.Equal(t, domain.NewAPIKeyAuth("api_key", "secret", domain.APIKeyLocationQuery), list.AuthConfig)
+
+	assert.Equal(t, "Create user", create.Name)
+	assert.Equal(t, "POST", create.Method)
+	assert.Equal(t, `{"name": "Ada"}`, create.Body)
+	assert.Equal(t, "application/json", create.Headers["Content-Type"])
+	assert.Equal(t, domain.NewBearerAuth("{{token}}"), create.AuthConfig)
+}
+
+func TestExport_Structure(t *testing.T) {
+	data, err := Export("Workspace", "", exportRequests())
+	require.NoError(t, err)
+
+	var c struct {
+		Info struct {
+			Name   string `json:"name"`
+			Schema string `json:"schema"`
+		} `json:"info"`
+		Item []struct {
+			Name string `json:"name"`
+			Item []struct {
+				Name string `json:"name"`
+				Item []struct {
+					Name string `json:"name"`
+				} `json:"item"`
+			} `json:"item"`
+		} `json:"item"`
+	}
+	require.NoError(t, json.Unmarshal(data, &c))
+
+	assert.Equal(t, "Workspace", c.Info.Name)
+	assert.Equal(t, schemaV21, c.Info.Schema)
+	require.Len(t, c.Item, 1)
+	assert.Equal(t, "Shop", c.Item[0].Name)
+	require.Len(t, c.Item[0].Item, 2)
+	assert.Equal(t, "Health", c.Item[0].Item[0].Name)
+	assert.Equal(t, "Users", c.Item[0].Item[1].Name)
+	require.Len(t, c.Item[0].Item[1].Item, 2)
+	assert.Equal(t, "List users", c.Item[0].Item[1].Item[0].Name)
+	assert.Equal(t, "Create user", c.Item[0].Item[1].Item[1].Name)
+}
@@ -0,0 +1,342 @@
+// Package postman builds saved requests from Postman collections in the
+// v2.0 and v2.1 formats, as exported from the Postman app.
+package postman
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/williajm/curly/internal/domain"
+)
+
+// formBoundary separates the parts of a multipart body built from form data.
+const formBoundary = "curlyformboundary7MA4YWxkTrZu0gW"
+
+// Result is an imported collection.
+type Result struct {
+	// Requests are the collection's requests, in the collection's order.
+	Requests []*domain.Request
+
+	// Warnings describe parts of the collection that a saved request cannot
+	// represent, such as scripts and file uploads.
+	Warnings []string
+}
+
+type collection struct {
+	Info     info       `json:"info"`
+	Item     []*item    `json:"item"`
+	Auth     *auth      `json:"auth"`
+	Variable []variable `json:"variable"`
+	Event    []any      `json:"event"`
+}
+
+type info struct {
+	Name   string `json:"name"`
+	Schema string `json:"schema"`
+}
+
+// item is a request or, when it has items of its own, a folder.
+type item struct {
+	Name    string   `json:"name"`
+	Item    []*item  `json:"item"`
+	Request *request `json:"request"`
+	Auth    *auth    `json:"auth"`
+	Event   []any    `json:"event"`
+}
+
+type request struct {
+	Method string     `json:"method"`
+	URL    requestURL `json:"url"`
+	Header []keyValue `json:"header"`
+	Body   *body      `json:"body"`
+	Auth   *auth      `json:"auth"`
+}
+
+// requestURL is a URL given as a string or as an object with its raw form.
+type requestURL struct {
+	Raw string `json:"raw"`
+}
+
+func (u *requestURL) UnmarshalJSON(data []byte) error {
+	if len(data) > 0 && data[0] == '"' {
+		return json.Unmarshal(data, &u.Raw)
+	}
+	type object requestURL
+	return json.Unmarshal(data, (*object)(u))
+}
+
+type keyValue struct {
+	Key      string `json:"key"`
+	Value    string `json:"value"`
+	Type     string `json:"type"`
+	Disabled bool   `json:"disabled"`
+}
+
+type body struct {
+	Mode       string     `json:"mode"`
+	Raw        string     `json:"raw"`
+	URLEncoded []keyValue `json:"urlencoded"`
+	FormData   []keyValue `json:"formdata"`
+	GraphQL    *graphQL   `json:"graphql"`
+	Options    struct {
+		Raw struct {
+			Language string `json:"language"`
+		} `json:"raw"`
+	} `json:"options"`
+}
+
+type graphQL struct {
+	Query     string `json:"query"`
+	Variables string `json:"variables"`
+}
+
+// auth holds its settings per type: v2.1 as a list of key/value pairs, v2.0
+// as an object.
+type auth struct {
+	Type   string          `json:"type"`
+	Bearer json.RawMessage `json:"bearer"`
+	Basic  json.RawMessage `json:"basic"`
+	APIKey json.RawMessage `json:"apikey"`
+}
+
+type variable struct {
+	Key string `json:"key"`
+}
+
+// Parse reads a Postman collection and returns its requests. The top-level
+// requests are placed in folder, or in a folder named after the collection if
+// it is empty, and Postman folders become folders nested in it, so "Users" in
+// the collection "Shop" becomes "Shop/Users". Auth is inherited from the
+// enclosing folders and collection as in Postman.
+func Parse(data []byte, folder string) (*Result, error) {
+	var c collection
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("invalid collection: %w", err)
+	}
+	if c.Info.Schema != "" && !strings.Contains(c.Info.Schema, "/v2.") {
+		return nil, fmt.Errorf("unsupported collection schema %s: expected v2.0 or v2.1", c.Info.Schema)
+	}
+	if c.Info.Name == "" || c.Item == nil {
+		return nil, fmt.Errorf("not a Postman collection: expected info.name and item")
+	}
+
+	p := &parser{positions: map[string]int{}}
+	if len(c.Variable) > 0 {
+		names := make([]string, len(c.Variable))
+		for i, v := range c.Variable {
+			names[i] = v.Key
+		}
+		p.warnf("collection variables are not imported; define %s in an environment", strings.Join(names, ", "))
+	}
+	if len(c.Event) > 0 {
+		p.warnf("%s: scripts are not imported", c.Info.Name)
+	}
+	if folder == "" {
+		folder = c.Info.Name
+	}
+	p.items(folder, c.Item, c.Auth)
+
+	if len(p.result.Requests) == 0 {
+		return nil, fmt.Errorf("collection %q has no requests", c.Info.Name)
+	}
+	return &p.result, nil
+}
+
+type parser struct {
+	result    Result
+	positions map[string]int
+}
+
+func (p *parser) warnf(format string, args ...any) {
+	p.result.Warnings = append(p.result.Warnings, fmt.Sprintf(format, args...))
+}
+
+// items imports the items of folder, which inherit inherited auth.
+func (p *parser) items(folder string, items []*item, inherited *auth) {
+	for _, it := range items {
+		if it == nil {
+			continue
+		}
+		itemAuth := inherited
+		if it.Auth != nil {
+			itemAuth = it.Auth
+		}
+		if len(it.Event) > 0 {
+			p.warnf("%s/%s: scripts are not imported", folder, it.Name)
+		}
+
+		if it.Request == nil {
+			p.items(folder+"/"+it.Name, it.Item, itemAuth)
+			continue
+		}
+		if it.Request.Auth != nil {
+			itemAuth = it.Request.Auth
+		}
+		req := p.request(folder+"/"+it.Name, it.Request, itemAuth)
+		req.Name = it.Name
+		req.Folder = folder
+		req.Position = p.positions[folder]
+		p.positions[folder]++
+		p.result.Requests = append(p.result.Requests, req)
+	}
+}
+
+// request converts a Postman request. path names it in warnings.
+func (p *parser) request(path string, r *request, a *auth) *domain.Request {
+	method := strings.ToUpper(r.Method)
+	if method == "" {
+		method = domain.MethodGet
+	}
+	req := domain.NewRequestWithMethodAndURL(method, r.URL.Raw)
+
+	for _, header := range r.Header {
+		if header.Disabled || header.Key == "" {
+			continue
+		}
+		// Assigned directly because SetHeader drops empty values.
+		req.Headers[header.Key] = header.Value
+	}
+	if r.Body != nil {
+		p.body(path, req, r.Body)
+	}
+	if a != nil {
+		if auth := p.auth(path, a); auth != nil {
+			req.SetAuth(auth)
+		}
+	}
+	return req
+}
+
+// body sets the body of req, and its Content-Type unless one is set.
+func (p *parser) body(path string, req *domain.Request, b *body) {
+	switch b.Mode {
+	case "", "none":
+	case "raw":
+		req.Body = b.Raw
+		switch b.Options.Raw.Language {
+		case "json":
+			defaultHeader(req, "Content-Type", "application/json")
+		case "xml":
+			defaultHeader(req, "Content-Type", "application/xml")
+		}
+	case "urlencoded":
+		var pairs []string
+		for _, field := range b.URLEncoded {
+			if !field.Disabled {
+				pairs = append(pairs, url.QueryEscape(field.Key)+"="+url.QueryEscape(field.Value))
+			}
+		}
+		req.Body = strings.Join(pairs, "&")
+		defaultHeader(req, "Content-Type", "application/x-www-form-urlencoded")
+	case "formdata":
+		req.Body = p.formData(path, b.FormData)
+		req.Headers["Content-Type"] = "multipart/form-data; boundary=" + formBoundary
+	case "graphql":
+		if b.GraphQL == nil {
+			return
+		}
+		req.Body = p.graphQL(path, b.GraphQL)
+		defaultHeader(req, "Content-Type", "application/json")
+	default:
+		p.warnf("%s: %s body is not imported", path, b.Mode)
+	}
+}
+
+// formData encodes the enabled fields of a form-data body as a multipart
+// body. File fields are skipped.
+func (p *parser) formData(path string, fields []keyValue) string {
+	var parts strings.Builder
+	for _, field := range fields {
+		if field.Disabled {
+			continue
+		}
+		if field.Type == "file" {
+			p.warnf("%s: file field %q is not imported", path, field.Key)
+			continue
+		}
+		fmt.Fprintf(&parts, "--%s\r\nContent-Disposition: form-data; name=%q\r\n\r\n%s\r\n", formBoundary, field.Key, field.Value)
+	}
+	return parts.String() + "--" + formBoundary + "--\r\n"
+}
+
+// graphQL encodes a GraphQL body as a JSON request. Variables that are not
+// valid JSON are dropped.
+func (p *parser) graphQL(path string, gql *graphQL) string {
+	payload := map[string]any{"query": gql.Query}
+	if vars := strings.TrimSpace(gql.Variables); vars != "" {
+		payload["variables"] = json.RawMessage(vars)
+	}
+	encoded, err := json.MarshalIndent(payload, "", "  ")
+	if err != nil {
+		p.warnf("%s: GraphQL variables are not valid JSON and are not imported", path)
+		encoded, _ = json.MarshalIndent(map[string]any{"query": gql.Query}, "", "  ")
+	}
+	return string(encoded)
+}
+
+// auth converts Postman auth, or returns nil for none or an unsupported type.
+func (p *parser) auth(path string, a *auth) domain.AuthConfig {
+	switch a.Type {
+	case "", "noauth":
+		return nil
+	case "bearer":
+		settings := authSettings(a.Bearer)
+		return domain.NewBearerAuth(settings["token"])
+	case "basic":
+		settings := authSettings(a.Basic)
+		return domain.NewBasicAuth(settings["username"], settings["password"])
+	case "apikey":
+		settings := authSettings(a.APIKey)
+		location := domain.APIKeyLocationHeader
+		if settings["in"] == "query" {
+			location = domain.APIKeyLocationQuery
+		}
+		return domain.NewAPIKeyAuth(settings["key"], settings["value"], location)
+	default:
+		p.warnf("%s: %s auth is not imported", path, a.Type)
+		return nil
+	}
+}
+
+// authSettings reads the settings of an auth type, given as a list of
+// key/value pairs (v2.1) or as an object (v2.0).
+func authSettings(raw json.RawMessage) map[string]string {
+	settings := map[string]string{}
+	var pairs []struct {
+		Key   string `json:"key"`
+		Value any    `json:"value"`
+	}
+	if err := json.Unmarshal(raw, &pairs); err == nil {
+		for _, pair := range pairs {
+			settings[pair.Key] = settingValue(pair.Value)
+		}
+		return settings
+	}
+	var object map[string]any
+	if err := json.Unmarshal(raw, &object); err == nil {
+		for key, value := range object {
+			settings[key] = settingValue(value)
+		}
+	}
+	return settings
+}
+
+// settingValue formats an auth setting, which is usually a string.
+func settingValue(value any) string {
+	if value == nil {
+		return ""
+	}
+	return fmt.Sprint(value)
+}
+
+// defaultHeader sets a header unless the request already has it, in any case.
+func defaultHeader(req *domain.Request, name, value string) {
+	for existing := range req.Headers {
+		if strings.EqualFold(existing, name) {
+			return
+		}
+	}
+	req.Headers[name] = value
+}
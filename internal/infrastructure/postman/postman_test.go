@@ -0,0 +1,161 @@
+package postman
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/williajm/curly/internal/domain"
+)
+
+const shop = `{
+  "info": {
+    "name": "Shop",
+    "schema": "https://schema.getpostman.com/json/collection/v2.1.0/collection.json"
+  },
+  "auth": {"type": "bearer", "bearer": [{"key": "token", "value": "{{token}}", "type": "string"}]},
+  "variable": [{"key": "baseUrl", "value": "https://shop.example.com"}],
+  "item": [
+    {
+      "name": "Health",
+      "request": {"method": "GET", "url": "{{baseUrl}}/health", "auth": {"type": "noauth"}}
+    },
+    {
+      "name": "Users",
+      "item": [
+        {
+          "name": "Create user",
+          "event": [{"listen": "test", "script": {"exec": ["pm.test()"]}}],
+          "request": {
+            "method": "POST",
+            "url": {"raw": "{{baseUrl}}/users?notify=true", "host": ["{{baseUrl}}"], "path": ["users"]},
+            "header": [
+              {"key": "X-Trace", "value": "1"},
+              {"key": "X-Debug", "value": "1", "disabled": true}
+            ],
+            "body": {"mode": "raw", "raw": "{\"name\": \"Ada\"}", "options": {"raw": {"language": "json"}}}
+          }
+        },
+        {
+          "name": "Log in",
+          "request": {
+            "method": "post",
+            "url": "https://shop.example.com/login",
+            "auth": {"type": "basic", "basic": [{"key": "username", "value": "ada"}, {"key": "password", "value": "secret"}]},
+            "body": {"mode": "urlencoded", "urlencoded": [
+              {"key": "remember me", "value": "yes & no"},
+              {"key": "skip", "value": "1", "disabled": true}
+            ]}
+          }
+        },
+        {
+          "name": "Upload avatar",
+          "request": {
+            "method": "PUT",
+            "url": "https://shop.example.com/avatar",
+            "auth": {"type": "oauth2"},
+            "body": {"mode": "formdata", "formdata": [
+              {"key": "caption", "value": "me", "type": "text"},
+              {"key": "file", "type": "file", "src": "/tmp/me.png"}
+            ]}
+          }
+        }
+      ]
+    }
+  ]
+}`
+
+func TestParse(t *testing.T) {
+	result, err := Parse([]byte(shop), "")
+	require.NoError(t, err)
+	require.Len(t, result.Requests, 4)
+
+	health := result.Requests[0]
+	assert.Equal(t, "Health", health.Name)
+	assert.Equal(t, "Shop", health.Folder)
+	assert.Equal(t, domain.MethodGet, health.Method)
+	assert.Equal(t, "{{baseUrl}}/health", health.URL)
+	assert.Equal(t, domain.AuthTypeNone, health.AuthConfig.Type())
+
+	create := result.Requests[1]
+	assert.Equal(t, "Shop/Users", create.Folder)
+	assert.Equal(t, 0, create.Position)
+	assert.Equal(t, "{{baseUrl}}/users?notify=true", create.URL)
+	assert.Equal(t, map[string]string{"X-Trace": "1", "Content-Type": "application/json"}, create.Headers)
+	assert.Equal(t, `{"name": "Ada"}`, create.Body)
+	assert.Equal(t, domain.NewBearerAuth("{{token}}"), create.AuthConfig)
+
+	login := result.Requests[2]
+	assert.Equal(t, 1, login.Position)
+	assert.Equal(t, domain.MethodPost, login.Method)
+	assert.Equal(t, "remember+me=yes+%26+no", login.Body)
+	assert.Equal(t, "application/x-www-form-urlencoded", login.Headers["Content-Type"])
+	assert.Equal(t, domain.NewBasicAuth("ada", "secret"), login.AuthConfig)
+
+	upload := result.Requests[3]
+	assert.Contains(t, upload.Body, "name=\"caption\"\r\n\r\nme\r\n")
+	assert.NotContains(t, upload.Body, "avatar.png")
+	assert.Equal(t, "multipart/form-data; boundary="+formBoundary, upload.Headers["Content-Type"])
+	assert.Equal(t, domain.AuthTypeNone, upload.AuthConfig.Type())
+
+	assert.Equal(t, []string{
+		"collection variables are not imported; define baseUrl in an environment",
+		"Shop/Users/Create user: scripts are not imported",
+		`Shop/Users/Upload avatar: file field "file" is not imported`,
+		"Shop/Users/Upload avatar: oauth2 auth is not imported",
+	}, result.Warnings)
+}
+
+func TestParse_Folder(t *testing.T) {
+	result, err := Parse([]byte(shop), "Imported")
+	require.NoError(t, err)
+	assert.Equal(t, "Imported", result.Requests[0].Folder)
+	assert.Equal(t, "Imported/Users", result.Requests[1].Folder)
+}
+
+func TestParse_V20(t *testing.T) {
+	collection := `{
+	  "info": {"name": "Legacy", "schema": "https://schema.getpostman.com/json/collection/v2.0.0/collection.json"},
+	  "item": [
+	    {
+	      "name": "Search",
+	      "request": {
+	        "method": "POST",
+	        "url": "https://api.example.com/graphql",
+	        "auth": {"type": "apikey", "apikey": {"key": "X-API-Key", "value": "abc", "in": "header"}},
+	        "body": {"mode": "graphql", "graphql": {"query": "{ me { id } }", "variables": "{\"a\": 1}"}}
+	      }
+	    }
+	  ]
+	}`
+
+	result, err := Parse([]byte(collection), "")
+	require.NoError(t, err)
+	require.Len(t, result.Requests, 1)
+
+	search := result.Requests[0]
+	assert.Equal(t, domain.NewAPIKeyAuth("X-API-Key", "abc", domain.APIKeyLocationHeader), search.AuthConfig)
+	assert.JSONEq(t, `{"query": "{ me { id } }", "variables": {"a": 1}}`, search.Body)
+	assert.Equal(t, "application/json", search.Headers["Content-Type"])
+	assert.Empty(t, result.Warnings)
+}
+
+func TestParse_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{"not JSON", `openapi: 3.0.0`, "invalid collection"},
+		{"not a collection", `{"openapi": "3.0.0"}`, "not a Postman collection"},
+		{"v1 schema", `{"info": {"name": "Old", "schema": "https://schema.getpostman.com/json/collection/v1.0.0/collection.json"}, "item": []}`, "unsupported collection schema"},
+		{"no requests", `{"info": {"name": "Empty"}, "item": [{"name": "Folder", "item": []}]}`, `collection "Empty" has no requests`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse([]byte(tt.data), "")
+			assert.ErrorContains(t, err, tt.want)
+		})
+	}
+}
@@ -0,0 +1,184 @@
+// Package release finds the latest release of curly on GitHub and compares
+// versions, so that users can be told when an update is out.
+//
+// The package builds the request for the latest release and parses the
+// response; sending it is left to the caller's HTTP client.
+package release
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/williajm/curly/internal/domain"
+)
+
+// LatestURL is the GitHub API endpoint of curly's latest release.
+const LatestURL = "https://api.github.com/repos/williajm/curly/releases/latest"
+
+// ErrNoReleases is returned when no release has been published.
+var ErrNoReleases = errors.New("no releases published")
+
+// Release is a published release.
+type Release struct {
+	// Version is the release's tag, such as "v1.4.0".
+	Version string
+
+	// URL is the release's page, with its notes and downloads.
+	URL string
+
+	// PublishedAt is when the release was published.
+	PublishedAt time.Time
+}
+
+// LatestRequest returns the request for the latest release.
+func LatestRequest() *domain.Request {
+	req := domain.NewRequestWithMethodAndURL(domain.MethodGet, LatestURL)
+	req.Name = "Latest curly release"
+	req.Headers["Accept"] = "application/vnd.github+json"
+	req.Headers["User-Agent"] = "curly"
+	return req
+}
+
+// ParseLatest parses the response to LatestRequest.
+func ParseLatest(resp *domain.Response) (*Release, error) {
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, ErrNoReleases
+	}
+	if !resp.IsSuccess() {
+		return nil, fmt.Errorf("GitHub returned %s", resp.Status)
+	}
+
+	var body struct {
+		TagName     string    `json:"tag_name"`
+		HTMLURL     string    `json:"html_url"`
+		PublishedAt time.Time `json:"published_at"`
+	}
+	if err := json.Unmarshal([]byte(resp.Body), &body); err != nil {
+		return nil, fmt.Errorf("invalid release: %w", err)
+	}
+	if body.TagName == "" {
+		return nil, errors.New("invalid release: no tag_name")
+	}
+	return &Release{Version: body.TagName, URL: body.HTMLURL, PublishedAt: body.PublishedAt}, nil
+}
+
+// IsRelease reports whether version is a release version, such as "v1.4.0"
+// or "1.4.0-rc.1", rather than a development build.
+func IsRelease(version string) bool {
+	_, ok := parse(version)
+	return ok
+}
+
+// Newer reports whether latest is a later version than current. It is false
+// when either is not a release version, so development builds are never
+// told to update.
+//
+// A version git describe gives for a build after a tag, such as
+// "v1.4.0-3-g1a2b3c4", counts as that tag.
+func Newer(latest, current string) bool {
+	l, ok := parse(latest)
+	if !ok {
+		return false
+	}
+	c, ok := parse(current)
+	if !ok {
+		return false
+	}
+	return l.compare(c) > 0
+}
+
+// describeSuffix matches what git describe adds to a tag for later commits
+// and uncommitted changes.
+var describeSuffix = regexp.MustCompile(`(-\d+-g[0-9a-f]+)?(-dirty)?$`)
+
+// semver is a parsed version.
+type semver struct {
+	core       [3]int
+	prerelease []string
+}
+
+// parse parses version as semantic versioning, with an optional "v" and
+// without build metadata. Missing minor and patch numbers are zero.
+func parse(version string) (semver, bool) {
+	version = strings.TrimPrefix(strings.TrimSpace(version), "v")
+	version, _, _ = strings.Cut(version, "+")
+	version = describeSuffix.ReplaceAllString(version, "")
+
+	var v semver
+	core, pre, hasPre := strings.Cut(version, "-")
+	if hasPre {
+		if pre == "" {
+			return semver{}, false
+		}
+		v.prerelease = strings.Split(pre, ".")
+	}
+	parts := strings.Split(core, ".")
+	if len(parts) > 3 {
+		return semver{}, false
+	}
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return semver{}, false
+		}
+		v.core[i] = n
+	}
+	return v, true
+}
+
+// compare returns -1, 0 or 1 as v is earlier than, the same as or later than
+// other, by the precedence rules of semantic versioning.
+func (v semver) compare(other semver) int {
+	for i := range v.core {
+		if v.core[i] != other.core[i] {
+			return sign(v.core[i] - other.core[i])
+		}
+	}
+	// A prerelease comes before its release.
+	switch {
+	case len(v.prerelease) == 0 && len(other.prerelease) == 0:
+		return 0
+	case len(v.prerelease) == 0:
+		return 1
+	case len(other.prerelease) == 0:
+		return -1
+	}
+	for i := 0; i < len(v.prerelease) && i < len(other.prerelease); i++ {
+		if c := compareIdentifier(v.prerelease[i], other.prerelease[i]); c != 0 {
+			return c
+		}
+	}
+	return sign(len(v.prerelease) - len(other.prerelease))
+}
+
+// compareIdentifier compares prerelease identifiers: numbers numerically and
+// below words, and words alphabetically.
+func compareIdentifier(a, b string) int {
+	an, aErr := strconv.Atoi(a)
+	bn, bErr := strconv.Atoi(b)
+	switch {
+	case aErr == nil && bErr == nil:
+		return sign(an - bn)
+	case aErr == nil:
+		return -1
+	case bErr == nil:
+		return 1
+	}
+	return strings.Compare(a, b)
+}
+
+func sign(n int) int {
+	switch {
+	case n < 0:
+		return -1
+	case n > 0:
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,100 @@
+package release
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/williajm/curly/internal/domain"
+)
+
+func TestLatestRequest(t *testing.T) {
+	req := LatestRequest()
+	require.NoError(t, req.Validate())
+	assert.Equal(t, "GET", req.Method)
+	assert.Equal(t, LatestURL, req.URL)
+	assert.Equal(t, "application/vnd.github+json", req.Headers["Accept"])
+}
+
+func TestParseLatest(t *testing.T) {
+	resp := &domain.Response{
+		StatusCode: 200,
+		Status:     "200 OK",
+		Body: `{
+			"tag_name": "v1.4.0",
+			"html_url": "https://github.com/williajm/curly/releases/tag/v1.4.0",
+			"published_at": "2026-10-01T12:00:00Z",
+			"assets": []
+		}`,
+	}
+	rel, err := ParseLatest(resp)
+	require.NoError(t, err)
+	assert.Equal(t, &Release{
+		Version:     "v1.4.0",
+		URL:         "https://github.com/williajm/curly/releases/tag/v1.4.0",
+		PublishedAt: time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC),
+	}, rel)
+}
+
+func TestParseLatest_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		resp    *domain.Response
+		wantErr string
+	}{
+		{"no releases", &domain.Response{StatusCode: 404, Status: "404 Not Found"}, "no releases published"},
+		{"rate limited", &domain.Response{StatusCode: 403, Status: "403 Forbidden"}, "GitHub returned 403 Forbidden"},
+		{"invalid json", &domain.Response{StatusCode: 200, Body: "<html>"}, "invalid release"},
+		{"no tag", &domain.Response{StatusCode: 200, Body: `{"html_url": "x"}`}, "invalid release: no tag_name"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseLatest(tt.resp)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestNewer(t *testing.T) {
+	tests := []struct {
+		latest, current string
+		want            bool
+	}{
+		{"v1.4.0", "v1.3.9", true},
+		{"v1.4.0", "1.3.9", true},
+		{"v1.10.0", "v1.9.0", true},
+		{"v2.0.0", "v1.99.99", true},
+		{"v1.4.0", "v1.4.0", false},
+		{"v1.3.0", "v1.4.0", false},
+		{"v1.4", "v1.4.0", false},
+		{"v1.4.1", "v1.4", true},
+		{"v1.4.0", "v1.4.0-rc.1", true},
+		{"v1.4.0-rc.2", "v1.4.0-rc.1", true},
+		{"v1.4.0-rc.10", "v1.4.0-rc.2", true},
+		{"v1.4.0-rc.1", "v1.4.0-beta", true},
+		{"v1.4.0-rc.1", "v1.4.0", false},
+		{"v1.4.0", "v1.4.0-3-g1a2b3c4", false},
+		{"v1.4.0", "v1.4.0-3-g1a2b3c4-dirty", false},
+		{"v1.4.1", "v1.4.0-3-g1a2b3c4", true},
+		{"v1.4.0", "v1.4.0+build.5", false},
+		{"v1.4.0", "dev", false},
+		{"v1.4.0", "1a2b3c4", false},
+		{"v1.4.0", "", false},
+		{"nightly", "v1.4.0", false},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, Newer(tt.latest, tt.current), "Newer(%q, %q)", tt.latest, tt.current)
+	}
+}
+
+func TestIsRelease(t *testing.T) {
+	assert.True(t, IsRelease("v1.4.0"))
+	assert.True(t, IsRelease("1.4.0-rc.1"))
+	assert.True(t, IsRelease("v1.4.0-3-g1a2b3c4-dirty"))
+	assert.False(t, IsRelease("dev"))
+	assert.False(t, IsRelease("v1.4.0-"))
+	assert.False(t, IsRelease("v1.2.3.4"))
+}
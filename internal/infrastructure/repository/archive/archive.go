@@ -0,0 +1,168 @@
+// Package archive exports history entries to compressed files before they are deleted.
+//
+// Each archive is a gzip-compressed NDJSON file with one history entry per
+// line, so long-term latency data survives cleanup and can be processed with
+// standard tools such as zcat and jq.
+package archive
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/williajm/curly/internal/infrastructure/repository"
+)
+
+// fileExt is the extension of archive files.
+const fileExt = ".ndjson.gz"
+
+// record is the serialized form of a history entry.
+// Offloaded bodies keep their preview and reference rather than the full content.
+type record struct {
+	ID              string `json:"id"`
+	RequestID       string `json:"request_id,omitempty"`
+	ExecutedAt      string `json:"executed_at"`
+	StatusCode      int    `json:"status_code"`
+	Status          string `json:"status,omitempty"`
+	ResponseTimeMs  int64  `json:"response_time_ms"`
+	ResponseHeaders string `json:"response_headers,omitempty"`
+	ResponseBody    string `json:"response_body,omitempty"`
+	ResponseBodyRef string `json:"response_body_ref,omitempty"`
+	Error           string `json:"error,omitempty"`
+	RunID           string `json:"run_id,omitempty"`
+	RequestSnapshot string `json:"request_snapshot,omitempty"`
+	ReplayOf        string `json:"replay_of,omitempty"`
+
+	AssertionFailures string `json:"assertion_failures,omitempty"`
+}
+
+// Archiver writes history archives into a directory.
+type Archiver struct {
+	dir string
+	now func() time.Time
+}
+
+// NewArchiver creates an archiver writing to dir. The directory is created on first write.
+func NewArchiver(dir string) *Archiver {
+	return &Archiver{dir: dir, now: time.Now}
+}
+
+// Archive writes entries to a new archive file and returns its path.
+// The file is only visible under its final name once fully written.
+func (a *Archiver) Archive(entries []*repository.HistoryEntry) (string, error) {
+	if len(entries) == 0 {
+		return "", fmt.Errorf("no history entries to archive")
+	}
+
+	if err := os.MkdirAll(a.dir, 0750); err != nil {
+		return "", fmt.Errorf("failed to create archive directory: %w", err)
+	}
+
+	name := "history-" + a.now().UTC().Format("20060102T150405.000000000Z")
+	tmp, err := os.CreateTemp(a.dir, name+".*.tmp")
+	if err != nil {
+		return "", fmt.Errorf("failed to create archive file: %w", err)
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+
+	if err := writeEntries(tmp, entries); err != nil {
+		_ = tmp.Close()
+		return "", err
+	}
+	if err := tmp.Close(); err != nil {
+		return "", fmt.Errorf("failed to close archive file: %w", err)
+	}
+
+	path := filepath.Join(a.dir, name+fileExt)
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return "", fmt.Errorf("failed to store archive: %w", err)
+	}
+
+	return path, nil
+}
+
+// Read loads all history entries from an archive file.
+func Read(path string) ([]*repository.HistoryEntry, error) {
+	f, err := os.Open(path) // #nosec G304 -- path is an archive chosen by the user
+	if err != nil {
+		return nil, fmt.Errorf("failed to open archive: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress archive: %w", err)
+	}
+	defer func() { _ = zr.Close() }()
+
+	var entries []*repository.HistoryEntry
+	dec := json.NewDecoder(zr)
+	for dec.More() {
+		var rec record
+		if err := dec.Decode(&rec); err != nil {
+			return nil, fmt.Errorf("failed to decode archive entry: %w", err)
+		}
+		entries = append(entries, &repository.HistoryEntry{
+			ID:              rec.ID,
+			RequestID:       rec.RequestID,
+			ExecutedAt:      rec.ExecutedAt,
+			StatusCode:      rec.StatusCode,
+			Status:          rec.Status,
+			ResponseTimeMs:  rec.ResponseTimeMs,
+			ResponseHeaders: rec.ResponseHeaders,
+			ResponseBody:    rec.ResponseBody,
+			ResponseBodyRef: rec.ResponseBodyRef,
+			Error:           rec.Error,
+			RunID:           rec.RunID,
+			RequestSnapshot: rec.RequestSnapshot,
+			ReplayOf:        rec.ReplayOf,
+
+			AssertionFailures: rec.AssertionFailures,
+		})
+	}
+
+	return entries, nil
+}
+
+// writeEntries writes entries as gzip-compressed NDJSON to f.
+func writeEntries(f *os.File, entries []*repository.HistoryEntry) error {
+	zw := gzip.NewWriter(f)
+	bw := bufio.NewWriter(zw)
+	enc := json.NewEncoder(bw)
+
+	for _, e := range entries {
+		rec := record{
+			ID:              e.ID,
+			RequestID:       e.RequestID,
+			ExecutedAt:      e.ExecutedAt,
+			StatusCode:      e.StatusCode,
+			Status:          e.Status,
+			ResponseTimeMs:  e.ResponseTimeMs,
+			ResponseHeaders: e.ResponseHeaders,
+			ResponseBody:    e.ResponseBody,
+			ResponseBodyRef: e.ResponseBodyRef,
+			Error:           e.Error,
+			RunID:           e.RunID,
+			RequestSnapshot: e.RequestSnapshot,
+			ReplayOf:        e.ReplayOf,
+
+			AssertionFailures: e.AssertionFailures,
+		}
+		if err := enc.Encode(rec); err != nil {
+			return fmt.Errorf("failed to write archive entry: %w", err)
+		}
+	}
+
+	if err := bw.Flush(); err != nil {
+		return fmt.Errorf("failed to write archive: %w", err)
+	}
+	if err := zw.Close(); err != nil {
+		return fmt.Errorf("failed to compress archive: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,105 @@
+package archive
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/williajm/curly/internal/infrastructure/repository"
+)
+
+func testEntries() []*repository.HistoryEntry {
+	return []*repository.HistoryEntry{
+		{
+			ID:              "hist-1",
+			RequestID:       "req-1",
+			ExecutedAt:      "2024-01-01T10:00:00Z",
+			StatusCode:      200,
+			Status:          "200 OK",
+			ResponseTimeMs:  42,
+			ResponseHeaders: `{"Content-Type":["application/json"]}`,
+			ResponseBody:    `{"ok":true}`,
+			RequestSnapshot: `{"method":"GET","url":"https://api.example.com"}`,
+
+			AssertionFailures: `["$.ok: expected boolean, got string"]`,
+		},
+		{
+			ID:             "hist-2",
+			ExecutedAt:     "2024-01-01T11:00:00Z",
+			ResponseTimeMs: 1500,
+			Error:          "connection refused",
+			RunID:          "run-1",
+			ReplayOf:       "hist-1",
+		},
+	}
+}
+
+func TestArchiver_ArchiveRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	archiver := NewArchiver(filepath.Join(dir, "archive"))
+
+	path, err := archiver.Archive(testEntries())
+	require.NoError(t, err)
+	assert.True(t, strings.HasSuffix(path, ".ndjson.gz"))
+	assert.Equal(t, filepath.Join(dir, "archive"), filepath.Dir(path))
+
+	got, err := Read(path)
+	require.NoError(t, err)
+	assert.Equal(t, testEntries(), got)
+}
+
+func TestArchiver_WritesOneJSONObjectPerLine(t *testing.T) {
+	archiver := NewArchiver(t.TempDir())
+
+	path, err := archiver.Archive(testEntries())
+	require.NoError(t, err)
+
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer func() { _ = f.Close() }()
+	zr, err := gzip.NewReader(f)
+	require.NoError(t, err)
+	data, err := io.ReadAll(zr)
+	require.NoError(t, err)
+
+	lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	require.Len(t, lines, 2)
+	assert.Contains(t, lines[0], `"response_time_ms":42`)
+	assert.Contains(t, lines[1], `"error":"connection refused"`)
+}
+
+func TestArchiver_DistinctFilesAndNoTempLeftovers(t *testing.T) {
+	dir := t.TempDir()
+	archiver := NewArchiver(dir)
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	archiver.now = func() time.Time {
+		now = now.Add(time.Second)
+		return now
+	}
+
+	path1, err := archiver.Archive(testEntries())
+	require.NoError(t, err)
+	path2, err := archiver.Archive(testEntries())
+	require.NoError(t, err)
+	assert.NotEqual(t, path1, path2)
+
+	files, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, files, 2)
+	for _, f := range files {
+		assert.True(t, strings.HasSuffix(f.Name(), fileExt), f.Name())
+	}
+}
+
+func TestArchiver_EmptyEntries(t *testing.T) {
+	archiver := NewArchiver(t.TempDir())
+
+	_, err := archiver.Archive(nil)
+	assert.Error(t, err)
+}
@@ -0,0 +1,207 @@
+package bodystore
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/williajm/curly/internal/infrastructure/repository"
+)
+
+// PreviewSize is the number of bytes of an offloaded body kept in the history table.
+const PreviewSize = 1024
+
+// sweepGrace is how long a body written is kept by a sweep even if no entry
+// references it, since the entry it was written for may not be saved yet.
+const sweepGrace = time.Minute
+
+// sweepBatch is how many references a sweep looks up in one query.
+const sweepBatch = 500
+
+// HistoryRepository wraps a repository.HistoryRepository and offloads response
+// bodies larger than a threshold to a Store.
+//
+// Offloaded entries keep a preview in ResponseBody and the body's hash in
+// ResponseBodyRef. FindByID restores the full body, and OpenByID streams it
+// from the store; list queries, including FindOlderThan and FindMatching,
+// return the preview so that browsing history stays fast, and callers that
+// need the body read the entry with FindByID or OpenByID.
+// Deleting entries removes the bodies no entry references any longer.
+type HistoryRepository struct {
+	repository.HistoryRepository
+
+	store     *Store
+	threshold int
+}
+
+// NewHistoryRepository creates a history repository that offloads bodies
+// larger than threshold bytes to store.
+func NewHistoryRepository(inner repository.HistoryRepository, store *Store, threshold int) *HistoryRepository {
+	return &HistoryRepository{
+		HistoryRepository: inner,
+		store:             store,
+		threshold:         threshold,
+	}
+}
+
+// Save persists a history entry, offloading its body if it exceeds the threshold.
+// The caller's entry is not modified.
+func (r *HistoryRepository) Save(ctx context.Context, entry *repository.HistoryEntry) error {
+	offloaded, err := r.offload(entry)
+	if err != nil {
+		return err
+	}
+	return r.HistoryRepository.Save(ctx, offloaded)
+}
+
+// SaveBatch persists entries in one transaction, offloading large bodies first.
+// The caller's entries are not modified.
+func (r *HistoryRepository) SaveBatch(ctx context.Context, entries []*repository.HistoryEntry) error {
+	offloaded := make([]*repository.HistoryEntry, len(entries))
+	for i, entry := range entries {
+		var err error
+		if offloaded[i], err = r.offload(entry); err != nil {
+			return err
+		}
+	}
+	return r.HistoryRepository.SaveBatch(ctx, offloaded)
+}
+
+// offload returns entry unchanged if its body is small enough, or a copy whose
+// body has been moved to the store.
+func (r *HistoryRepository) offload(entry *repository.HistoryEntry) (*repository.HistoryEntry, error) {
+	if entry == nil || entry.ResponseBodyRef != "" || len(entry.ResponseBody) <= r.threshold {
+		return entry, nil
+	}
+
+	ref, err := r.store.Put([]byte(entry.ResponseBody))
+	if err != nil {
+		return nil, fmt.Errorf("failed to offload response body: %w", err)
+	}
+
+	offloaded := *entry
+	offloaded.ResponseBody = preview(entry.ResponseBody)
+	offloaded.ResponseBodyRef = ref
+
+	return &offloaded, nil
+}
+
+// FindByID retrieves a history entry with its full response body.
+func (r *HistoryRepository) FindByID(ctx context.Context, id string) (*repository.HistoryEntry, error) {
+	entry, err := r.HistoryRepository.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if entry.ResponseBodyRef != "" {
+		body, err := r.store.Get(entry.ResponseBodyRef)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load response body: %w", err)
+		}
+		entry.ResponseBody = string(body)
+	}
+
+	return entry, nil
+}
+
+// OpenByID retrieves a history entry with the preview of an offloaded body,
+// and a reader of its full body that decompresses the stored body as it is
+// read.
+func (r *HistoryRepository) OpenByID(ctx context.Context, id string) (*repository.HistoryEntry, io.ReadCloser, error) {
+	entry, err := r.HistoryRepository.FindByID(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if entry.ResponseBodyRef == "" {
+		return entry, io.NopCloser(strings.NewReader(entry.ResponseBody)), nil
+	}
+	body, err := r.store.Open(entry.ResponseBodyRef)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load response body: %w", err)
+	}
+
+	return entry, body, nil
+}
+
+// Delete removes a history entry, and its body if no other entry shares it.
+func (r *HistoryRepository) Delete(ctx context.Context, id string) error {
+	if err := r.HistoryRepository.Delete(ctx, id); err != nil {
+		return err
+	}
+	r.sweep(ctx)
+	return nil
+}
+
+// DeleteOlderThan removes the history entries older than timestamp, and the
+// bodies no remaining entry shares.
+func (r *HistoryRepository) DeleteOlderThan(ctx context.Context, timestamp string) (int64, error) {
+	count, err := r.HistoryRepository.DeleteOlderThan(ctx, timestamp)
+	if err == nil && count > 0 {
+		r.sweep(ctx)
+	}
+	return count, err
+}
+
+// DeleteExceptNewest removes all but the keep newest history entries, and the
+// bodies no remaining entry shares.
+func (r *HistoryRepository) DeleteExceptNewest(ctx context.Context, keep int) (int64, error) {
+	count, err := r.HistoryRepository.DeleteExceptNewest(ctx, keep)
+	if err == nil && count > 0 {
+		r.sweep(ctx)
+	}
+	return count, err
+}
+
+// DeleteMatching removes the history entries filter matches, and the bodies
+// no remaining entry shares.
+func (r *HistoryRepository) DeleteMatching(ctx context.Context, filter repository.HistoryFilter) (int64, error) {
+	count, err := r.HistoryRepository.DeleteMatching(ctx, filter)
+	if err == nil && count > 0 {
+		r.sweep(ctx)
+	}
+	return count, err
+}
+
+// sweep removes the bodies stored that no history entry references. The
+// entries are already deleted, so a sweep that fails only leaves files
+// behind for the next one to remove.
+func (r *HistoryRepository) sweep(ctx context.Context) {
+	refs, err := r.store.Refs(time.Now().Add(-sweepGrace))
+	if err != nil {
+		return
+	}
+
+	for batch := range slices.Chunk(refs, sweepBatch) {
+		entries, err := r.HistoryRepository.FindMatching(ctx, repository.HistoryFilter{BodyRefs: batch}, 0)
+		if err != nil {
+			return
+		}
+		referenced := make(map[string]bool, len(entries))
+		for _, entry := range entries {
+			referenced[entry.ResponseBodyRef] = true
+		}
+		for _, ref := range batch {
+			if !referenced[ref] {
+				_ = r.store.Remove(ref)
+			}
+		}
+	}
+}
+
+// preview returns the first PreviewSize bytes of body without splitting a UTF-8 sequence.
+func preview(body string) string {
+	if len(body) <= PreviewSize {
+		return body
+	}
+
+	end := PreviewSize
+	for end > 0 && !utf8.RuneStart(body[end]) {
+		end--
+	}
+	return body[:end]
+}
@@ -0,0 +1,230 @@
+package bodystore
+
+import (
+	"context"
+	"database/sql"
+	"io"
+	"os"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/williajm/curly/internal/infrastructure/repository"
+	"github.com/williajm/curly/internal/infrastructure/repository/sqlite"
+)
+
+func setupHistoryRepo(t *testing.T, threshold int) (*HistoryRepository, *sqlite.HistoryRepository) {
+	t.Helper()
+
+	db, err := sql.Open("sqlite", ":memory:")
+	require.NoError(t, err)
+	db.SetMaxOpenConns(1)
+	require.NoError(t, sqlite.MigrateDB(db))
+	t.Cleanup(func() { _ = db.Close() })
+
+	inner := sqlite.NewHistoryRepository(db)
+	return NewHistoryRepository(inner, NewStore(t.TempDir()), threshold), inner
+}
+
+func newEntry(id, body string) *repository.HistoryEntry {
+	return &repository.HistoryEntry{
+		ID:           id,
+		ExecutedAt:   time.Now().UTC().Format(time.RFC3339),
+		StatusCode:   200,
+		Status:       "200 OK",
+		ResponseBody: body,
+	}
+}
+
+func TestHistoryRepository_OffloadsLargeBodies(t *testing.T) {
+	repo, inner := setupHistoryRepo(t, 4096)
+	ctx := context.Background()
+
+	large := strings.Repeat("x", 10000)
+	entry := newEntry("large", large)
+	require.NoError(t, repo.Save(ctx, entry))
+
+	// The caller's entry is left untouched.
+	assert.Equal(t, large, entry.ResponseBody)
+	assert.Empty(t, entry.ResponseBodyRef)
+
+	// Only a preview and reference are stored in the database.
+	stored, err := inner.FindByID(ctx, "large")
+	require.NoError(t, err)
+	assert.Len(t, stored.ResponseBody, PreviewSize)
+	assert.NotEmpty(t, stored.ResponseBodyRef)
+
+	// FindByID restores the full body.
+	got, err := repo.FindByID(ctx, "large")
+	require.NoError(t, err)
+	assert.Equal(t, large, got.ResponseBody)
+
+	// Lists return the preview.
+	all, err := repo.FindAll(ctx, 0)
+	require.NoError(t, err)
+	require.Len(t, all, 1)
+	assert.Len(t, all[0].ResponseBody, PreviewSize)
+}
+
+func TestHistoryRepository_OpenByID(t *testing.T) {
+	repo, _ := setupHistoryRepo(t, 4096)
+	ctx := context.Background()
+
+	large := strings.Repeat("x", 10000)
+	require.NoError(t, repo.Save(ctx, newEntry("large", large)))
+	require.NoError(t, repo.Save(ctx, newEntry("small", "ok")))
+
+	// An offloaded body is left as its preview, and streamed from the store.
+	entry, body, err := repo.OpenByID(ctx, "large")
+	require.NoError(t, err)
+	assert.Len(t, entry.ResponseBody, PreviewSize)
+	data, err := io.ReadAll(body)
+	require.NoError(t, err)
+	require.NoError(t, body.Close())
+	assert.Equal(t, large, string(data))
+
+	// A body kept inline is read from the entry.
+	entry, body, err = repo.OpenByID(ctx, "small")
+	require.NoError(t, err)
+	assert.Equal(t, "ok", entry.ResponseBody)
+	data, err = io.ReadAll(body)
+	require.NoError(t, err)
+	assert.Equal(t, "ok", string(data))
+
+	_, _, err = repo.OpenByID(ctx, "missing")
+	assert.ErrorIs(t, err, repository.ErrNotFound)
+}
+
+func TestHistoryRepository_SaveBatchOffloads(t *testing.T) {
+	repo, inner := setupHistoryRepo(t, 4096)
+	ctx := context.Background()
+
+	large := strings.Repeat("y", 10000)
+	require.NoError(t, repo.SaveBatch(ctx, []*repository.HistoryEntry{
+		newEntry("batch-large", large),
+		newEntry("batch-small", "ok"),
+	}))
+
+	stored, err := inner.FindByID(ctx, "batch-large")
+	require.NoError(t, err)
+	assert.NotEmpty(t, stored.ResponseBodyRef)
+
+	got, err := repo.FindByID(ctx, "batch-large")
+	require.NoError(t, err)
+	assert.Equal(t, large, got.ResponseBody)
+
+	small, err := inner.FindByID(ctx, "batch-small")
+	require.NoError(t, err)
+	assert.Equal(t, "ok", small.ResponseBody)
+}
+
+func TestHistoryRepository_KeepsSmallBodiesInline(t *testing.T) {
+	repo, inner := setupHistoryRepo(t, 4096)
+	ctx := context.Background()
+
+	require.NoError(t, repo.Save(ctx, newEntry("small", `{"ok":true}`)))
+
+	stored, err := inner.FindByID(ctx, "small")
+	require.NoError(t, err)
+	assert.Equal(t, `{"ok":true}`, stored.ResponseBody)
+	assert.Empty(t, stored.ResponseBodyRef)
+}
+
+// ageBody makes the body stored under ref old enough to be swept.
+func ageBody(t *testing.T, repo *HistoryRepository, ref string) {
+	t.Helper()
+	old := time.Now().Add(-time.Hour)
+	require.NoError(t, os.Chtimes(repo.store.path(ref), old, old))
+}
+
+func TestHistoryRepository_DeleteRemovesBodies(t *testing.T) {
+	repo, inner := setupHistoryRepo(t, 4096)
+	ctx := context.Background()
+
+	shared := strings.Repeat("s", 10000)
+	own := strings.Repeat("o", 10000)
+	require.NoError(t, repo.SaveBatch(ctx, []*repository.HistoryEntry{
+		newEntry("shared-1", shared),
+		newEntry("shared-2", shared),
+		newEntry("own", own),
+	}))
+	stored, err := inner.FindByID(ctx, "shared-1")
+	require.NoError(t, err)
+	sharedRef := stored.ResponseBodyRef
+	stored, err = inner.FindByID(ctx, "own")
+	require.NoError(t, err)
+	ownRef := stored.ResponseBodyRef
+	ageBody(t, repo, sharedRef)
+	ageBody(t, repo, ownRef)
+
+	require.NoError(t, repo.Delete(ctx, "own"))
+	assert.NoFileExists(t, repo.store.path(ownRef))
+	assert.FileExists(t, repo.store.path(sharedRef), "a body still referenced is kept")
+
+	require.NoError(t, repo.Delete(ctx, "shared-1"))
+	got, err := repo.FindByID(ctx, "shared-2")
+	require.NoError(t, err)
+	assert.Equal(t, shared, got.ResponseBody)
+
+	deleted, err := repo.DeleteMatching(ctx, repository.HistoryFilter{IDs: []string{"shared-2"}})
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, deleted)
+	assert.NoFileExists(t, repo.store.path(sharedRef))
+}
+
+func TestHistoryRepository_RetentionRemovesBodies(t *testing.T) {
+	repo, inner := setupHistoryRepo(t, 4096)
+	ctx := context.Background()
+
+	old := newEntry("old", strings.Repeat("a", 10000))
+	old.ExecutedAt = time.Now().Add(-48 * time.Hour).UTC().Format(time.RFC3339)
+	older := newEntry("older", strings.Repeat("b", 10000))
+	older.ExecutedAt = time.Now().Add(-72 * time.Hour).UTC().Format(time.RFC3339)
+	require.NoError(t, repo.SaveBatch(ctx, []*repository.HistoryEntry{old, older, newEntry("new", "ok")}))
+
+	refs := map[string]string{}
+	for _, id := range []string{"old", "older"} {
+		stored, err := inner.FindByID(ctx, id)
+		require.NoError(t, err)
+		refs[id] = stored.ResponseBodyRef
+		ageBody(t, repo, stored.ResponseBodyRef)
+	}
+
+	deleted, err := repo.DeleteOlderThan(ctx, time.Now().Add(-60*time.Hour).UTC().Format(time.RFC3339))
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, deleted)
+	assert.NoFileExists(t, repo.store.path(refs["older"]))
+	assert.FileExists(t, repo.store.path(refs["old"]))
+
+	deleted, err = repo.DeleteExceptNewest(ctx, 1)
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, deleted)
+	assert.NoFileExists(t, repo.store.path(refs["old"]))
+}
+
+func TestHistoryRepository_SweepKeepsRecentBodies(t *testing.T) {
+	repo, inner := setupHistoryRepo(t, 4096)
+	ctx := context.Background()
+
+	// A body just written may be for an entry another process is about to
+	// save, so it outlives the entry deleted here until it is older.
+	require.NoError(t, repo.Save(ctx, newEntry("recent", strings.Repeat("r", 10000))))
+	stored, err := inner.FindByID(ctx, "recent")
+	require.NoError(t, err)
+
+	require.NoError(t, repo.Delete(ctx, "recent"))
+	assert.FileExists(t, repo.store.path(stored.ResponseBodyRef))
+}
+
+func TestPreview_DoesNotSplitRunes(t *testing.T) {
+	body := strings.Repeat("é", PreviewSize)
+
+	got := preview(body)
+	assert.True(t, utf8.ValidString(got))
+	assert.LessOrEqual(t, len(got), PreviewSize)
+	assert.True(t, strings.HasPrefix(body, got))
+}
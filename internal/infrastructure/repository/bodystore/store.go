@@ -0,0 +1,186 @@
+// Package bodystore keeps large response bodies out of the history table.
+//
+// Bodies are gzip-compressed and stored on disk under their SHA-256 hash, so
+// identical responses are written once no matter how often they recur.
+package bodystore
+
+import (
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// fileExt is the extension of stored body files.
+const fileExt = ".gz"
+
+// Store is a content-addressed store of compressed bodies in a directory.
+type Store struct {
+	dir string
+}
+
+// NewStore creates a store rooted at dir. The directory is created on first write.
+func NewStore(dir string) *Store {
+	return &Store{dir: dir}
+}
+
+// Put stores body and returns its reference, the hex SHA-256 of the content.
+// Storing a body that already exists only marks it as written now, so that a
+// sweep does not remove it before the entry that reuses it is saved.
+func (s *Store) Put(body []byte) (string, error) {
+	sum := sha256.Sum256(body)
+	ref := hex.EncodeToString(sum[:])
+	path := s.path(ref)
+
+	if _, err := os.Stat(path); err == nil {
+		now := time.Now()
+		_ = os.Chtimes(path, now, now)
+		return ref, nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		return "", fmt.Errorf("failed to create body directory: %w", err)
+	}
+
+	// Write to a temporary file and rename so readers never see a partial body.
+	tmp, err := os.CreateTemp(filepath.Dir(path), ref+".*.tmp")
+	if err != nil {
+		return "", fmt.Errorf("failed to create body file: %w", err)
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+
+	zw := gzip.NewWriter(tmp)
+	if _, err := zw.Write(body); err != nil {
+		_ = tmp.Close()
+		return "", fmt.Errorf("failed to write body: %w", err)
+	}
+	if err := zw.Close(); err != nil {
+		_ = tmp.Close()
+		return "", fmt.Errorf("failed to compress body: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return "", fmt.Errorf("failed to close body file: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return "", fmt.Errorf("failed to store body: %w", err)
+	}
+
+	return ref, nil
+}
+
+// Get returns the body stored under ref.
+func (s *Store) Get(ref string) ([]byte, error) {
+	r, err := s.Open(ref)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = r.Close() }()
+
+	body, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress body: %w", err)
+	}
+
+	return body, nil
+}
+
+// Open returns a reader of the body stored under ref, decompressed as it is
+// read, so that a large body can be read a chunk at a time. The caller must
+// close it.
+func (s *Store) Open(ref string) (io.ReadCloser, error) {
+	if !validRef(ref) {
+		return nil, fmt.Errorf("invalid body reference %q", ref)
+	}
+
+	f, err := os.Open(s.path(ref))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read body: %w", err)
+	}
+
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		_ = f.Close()
+		return nil, fmt.Errorf("failed to decompress body: %w", err)
+	}
+
+	return &bodyReader{Reader: zr, file: f}, nil
+}
+
+// bodyReader decompresses a stored body, closing its file when closed.
+type bodyReader struct {
+	*gzip.Reader
+	file *os.File
+}
+
+// Close closes the decompressor and the file.
+func (r *bodyReader) Close() error {
+	err := r.Reader.Close()
+	if closeErr := r.file.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// Refs returns the references of the bodies stored that were last written
+// before cutoff. A store that has not been written to holds none.
+func (s *Store) Refs(cutoff time.Time) ([]string, error) {
+	var refs []string
+	err := filepath.WalkDir(s.dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) && path == s.dir {
+				return fs.SkipAll
+			}
+			return err
+		}
+		ref, ok := strings.CutSuffix(d.Name(), fileExt)
+		if d.IsDir() || !ok || !validRef(ref) {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		if info.ModTime().Before(cutoff) {
+			refs = append(refs, ref)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list bodies: %w", err)
+	}
+	return refs, nil
+}
+
+// Remove deletes the body stored under ref. Removing a body that is not
+// stored is a no-op.
+func (s *Store) Remove(ref string) error {
+	if !validRef(ref) {
+		return fmt.Errorf("invalid body reference %q", ref)
+	}
+	if err := os.Remove(s.path(ref)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to remove body: %w", err)
+	}
+	return nil
+}
+
+// path returns the file for ref, fanned out by its first two characters.
+func (s *Store) path(ref string) string {
+	return filepath.Join(s.dir, ref[:2], ref+fileExt)
+}
+
+// validRef reports whether ref is a hex SHA-256, which also rules out path traversal.
+func validRef(ref string) bool {
+	if len(ref) != sha256.Size*2 {
+		return false
+	}
+	_, err := hex.DecodeString(ref)
+	return err == nil
+}
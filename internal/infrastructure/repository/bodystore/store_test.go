@@ -0,0 +1,143 @@
+package bodystore
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStore_PutGet(t *testing.T) {
+	store := NewStore(t.TempDir())
+	body := []byte(strings.Repeat(`{"id":1,"name":"test"}`, 1000))
+
+	ref, err := store.Put(body)
+	require.NoError(t, err)
+	assert.Len(t, ref, 64)
+
+	got, err := store.Get(ref)
+	require.NoError(t, err)
+	assert.Equal(t, body, got)
+}
+
+func TestStore_Open(t *testing.T) {
+	store := NewStore(t.TempDir())
+	body := []byte(strings.Repeat("line of a large body\n", 10000))
+
+	ref, err := store.Put(body)
+	require.NoError(t, err)
+
+	r, err := store.Open(ref)
+	require.NoError(t, err)
+	defer func() { _ = r.Close() }()
+
+	// The body is read a chunk at a time.
+	chunk := make([]byte, 4096)
+	n, err := io.ReadFull(r, chunk)
+	require.NoError(t, err)
+	assert.Equal(t, body[:n], chunk)
+	rest, err := io.ReadAll(r)
+	require.NoError(t, err)
+	assert.Equal(t, body[n:], rest)
+
+	_, err = store.Open("../../etc/passwd")
+	assert.Error(t, err)
+	_, err = store.Open(strings.Repeat("0", 64))
+	assert.Error(t, err)
+}
+
+func TestStore_PutIsContentAddressed(t *testing.T) {
+	dir := t.TempDir()
+	store := NewStore(dir)
+
+	ref1, err := store.Put([]byte("same body"))
+	require.NoError(t, err)
+	ref2, err := store.Put([]byte("same body"))
+	require.NoError(t, err)
+	ref3, err := store.Put([]byte("other body"))
+	require.NoError(t, err)
+
+	assert.Equal(t, ref1, ref2)
+	assert.NotEqual(t, ref1, ref3)
+
+	entries, err := os.ReadDir(filepath.Join(dir, ref1[:2]))
+	require.NoError(t, err)
+	assert.Len(t, entries, 1, "identical bodies should be stored once")
+}
+
+func TestStore_Compresses(t *testing.T) {
+	dir := t.TempDir()
+	store := NewStore(dir)
+	body := []byte(strings.Repeat("a", 100000))
+
+	ref, err := store.Put(body)
+	require.NoError(t, err)
+
+	info, err := os.Stat(store.path(ref))
+	require.NoError(t, err)
+	assert.Less(t, info.Size(), int64(len(body)/10))
+}
+
+func TestStore_GetErrors(t *testing.T) {
+	store := NewStore(t.TempDir())
+
+	tests := []struct {
+		name string
+		ref  string
+	}{
+		{name: "path traversal", ref: "../../etc/passwd"},
+		{name: "not hex", ref: strings.Repeat("z", 64)},
+		{name: "missing", ref: strings.Repeat("a", 64)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := store.Get(tt.ref)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestStore_RefsAndRemove(t *testing.T) {
+	store := NewStore(filepath.Join(t.TempDir(), "bodies"))
+
+	refs, err := store.Refs(time.Now())
+	require.NoError(t, err)
+	assert.Empty(t, refs, "a store not written to holds no bodies")
+
+	ref, err := store.Put([]byte("body"))
+	require.NoError(t, err)
+
+	refs, err = store.Refs(time.Now().Add(time.Second))
+	require.NoError(t, err)
+	assert.Equal(t, []string{ref}, refs)
+	refs, err = store.Refs(time.Now().Add(-time.Minute))
+	require.NoError(t, err)
+	assert.Empty(t, refs, "bodies written since the cutoff are left out")
+
+	require.NoError(t, store.Remove(ref))
+	_, err = store.Get(ref)
+	require.Error(t, err)
+	require.NoError(t, store.Remove(ref), "removing a missing body is a no-op")
+	assert.Error(t, store.Remove("../../etc/passwd"))
+}
+
+func TestStore_PutRefreshesExistingBody(t *testing.T) {
+	store := NewStore(t.TempDir())
+
+	ref, err := store.Put([]byte("body"))
+	require.NoError(t, err)
+	old := time.Now().Add(-time.Hour)
+	require.NoError(t, os.Chtimes(store.path(ref), old, old))
+
+	_, err = store.Put([]byte("body"))
+	require.NoError(t, err)
+	info, err := os.Stat(store.path(ref))
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now(), info.ModTime(), time.Minute)
+}
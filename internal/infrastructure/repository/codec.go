@@ -0,0 +1,273 @@
+package repository
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/williajm/curly/internal/domain"
+)
+
+// MarshalAuthConfig converts an AuthConfig to the JSON stored alongside its type.
+func MarshalAuthConfig(auth domain.AuthConfig) ([]byte, error) {
+	if auth == nil {
+		return json.Marshal(map[string]interface{}{})
+	}
+
+	switch a := auth.(type) {
+	case *domain.NoAuth:
+		return json.Marshal(map[string]interface{}{})
+	case *domain.BasicAuth:
+		return json.Marshal(map[string]interface{}{
+			"username": a.Username,
+			"password": a.Password,
+		})
+	case *domain.BearerAuth:
+		return json.Marshal(map[string]interface{}{
+			"token": a.Token,
+		})
+	case *domain.APIKeyAuth:
+		return json.Marshal(map[string]interface{}{
+			"key":      a.Key,
+			"value":    a.Value,
+			"location": a.Location,
+		})
+	default:
+		return nil, fmt.Errorf("unsupported auth type: %T", auth)
+	}
+}
+
+// UnmarshalAuthConfig reconstructs an AuthConfig from its stored type and JSON.
+func UnmarshalAuthConfig(authType, configJSON string) (domain.AuthConfig, error) {
+	switch authType {
+	case "", "none":
+		return domain.NewNoAuth(), nil
+	case "basic":
+		var config struct {
+			Username string `json:"username"`
+			Password string `json:"password"`
+		}
+		if err := json.Unmarshal([]byte(configJSON), &config); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal basic auth: %w", err)
+		}
+		return domain.NewBasicAuth(config.Username, config.Password), nil
+	case "bearer":
+		var config struct {
+			Token string `json:"token"`
+		}
+		if err := json.Unmarshal([]byte(configJSON), &config); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal bearer auth: %w", err)
+		}
+		return domain.NewBearerAuth(config.Token), nil
+	case "apikey":
+		var config struct {
+			Key      string                `json:"key"`
+			Value    string                `json:"value"`
+			Location domain.APIKeyLocation `json:"location"`
+		}
+		if err := json.Unmarshal([]byte(configJSON), &config); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal apikey auth: %w", err)
+		}
+		return domain.NewAPIKeyAuth(config.Key, config.Value, config.Location), nil
+	default:
+		return nil, fmt.Errorf("unknown auth type: %s", authType)
+	}
+}
+
+// requestSnapshot is the stored form of a request as executed.
+type requestSnapshot struct {
+	ID          string            `json:"id,omitempty"`
+	Name        string            `json:"name,omitempty"`
+	Method      string            `json:"method"`
+	URL         string            `json:"url"`
+	Headers     map[string]string `json:"headers,omitempty"`
+	QueryParams map[string]string `json:"query_params,omitempty"`
+	Body        string            `json:"body,omitempty"`
+	AuthType    string            `json:"auth_type,omitempty"`
+	AuthConfig  json.RawMessage   `json:"auth_config,omitempty"`
+
+	DisabledQueryParams map[string]string `json:"disabled_query_params,omitempty"`
+
+	ResponseSchema   string `json:"response_schema,omitempty"`
+	ResponseContract string `json:"response_contract,omitempty"`
+	ResponseFilter   string `json:"response_filter,omitempty"`
+
+	PostResponseScript string `json:"post_response_script,omitempty"`
+}
+
+// MarshalRequestSnapshot serializes the parts of a request that determine
+// what is sent, including its auth credentials, so it can be re-sent later.
+// The response schema, contract and post-response script are kept so a replay
+// is checked the same way, and the response filter so a loaded entry shows the
+// same values. The disabled query parameters are kept so a loaded draft still
+// lists them. The pre-request script is not, since its changes are already
+// in the request as sent. Usage metadata and ordering are not included.
+func MarshalRequestSnapshot(req *domain.Request) (string, error) {
+	authConfig, err := MarshalAuthConfig(req.AuthConfig)
+	if err != nil {
+		return "", err
+	}
+	authType := ""
+	if req.AuthConfig != nil {
+		authType = req.AuthConfig.Type()
+	}
+
+	data, err := json.Marshal(requestSnapshot{
+		ID:          req.ID,
+		Name:        req.Name,
+		Method:      req.Method,
+		URL:         req.URL,
+		Headers:     req.Headers,
+		QueryParams: req.QueryParams,
+		Body:        req.Body,
+		AuthType:    authType,
+		AuthConfig:  authConfig,
+
+		DisabledQueryParams: req.DisabledQueryParams,
+
+		ResponseSchema:   req.ResponseSchema,
+		ResponseContract: req.ResponseContract,
+		ResponseFilter:   req.ResponseFilter,
+
+		PostResponseScript: req.PostResponseScript,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal request snapshot: %w", err)
+	}
+	return string(data), nil
+}
+
+// Request reconstructs the request recorded in the entry's RequestSnapshot.
+// It returns ErrNoSnapshot if the entry has no snapshot.
+func (e *HistoryEntry) Request() (*domain.Request, error) {
+	if e.RequestSnapshot == "" {
+		return nil, ErrNoSnapshot
+	}
+	return UnmarshalRequestSnapshot(e.RequestSnapshot)
+}
+
+// UnmarshalRequestSnapshot reconstructs a request from a snapshot written by
+// MarshalRequestSnapshot.
+func UnmarshalRequestSnapshot(data string) (*domain.Request, error) {
+	var snap requestSnapshot
+	if err := json.Unmarshal([]byte(data), &snap); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal request snapshot: %w", err)
+	}
+
+	authConfig, err := UnmarshalAuthConfig(snap.AuthType, string(snap.AuthConfig))
+	if err != nil {
+		return nil, err
+	}
+
+	req := domain.NewRequestWithMethodAndURL(snap.Method, snap.URL)
+	if snap.ID != "" {
+		req.ID = snap.ID
+	}
+	req.Name = snap.Name
+	req.Body = snap.Body
+	req.AuthConfig = authConfig
+	req.ResponseSchema = snap.ResponseSchema
+	req.ResponseContract = snap.ResponseContract
+	req.ResponseFilter = snap.ResponseFilter
+	req.PostResponseScript = snap.PostResponseScript
+	for k, v := range snap.Headers {
+		req.Headers[k] = v
+	}
+	for k, v := range snap.QueryParams {
+		req.QueryParams[k] = v
+	}
+	req.DisabledQueryParams = snap.DisabledQueryParams
+	return req, nil
+}
+
+// MarshalQueryParams serializes query parameters as a JSON object. No
+// parameters are stored as "{}".
+func MarshalQueryParams(params map[string]string) string {
+	if params == nil {
+		return "{}"
+	}
+	data, _ := json.Marshal(params) // A string map always marshals.
+	return string(data)
+}
+
+// UnmarshalQueryParams reconstructs query parameters stored by
+// MarshalQueryParams. It returns nil if there are none.
+func UnmarshalQueryParams(data string) (map[string]string, error) {
+	var params map[string]string
+	if data != "" {
+		if err := json.Unmarshal([]byte(data), &params); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal query params: %w", err)
+		}
+	}
+	if len(params) == 0 {
+		return nil, nil
+	}
+	return params, nil
+}
+
+// MarshalAssertionFailures serializes assertion failures for
+// HistoryEntry.AssertionFailures. It returns "" when there are none.
+func MarshalAssertionFailures(failures []string) string {
+	if len(failures) == 0 {
+		return ""
+	}
+	data, _ := json.Marshal(failures) // A string slice always marshals.
+	return string(data)
+}
+
+// Failures returns the entry's assertion failures, or nil if the response
+// passed its checks. Unreadable data is reported as a single failure rather
+// than hidden.
+func (e *HistoryEntry) Failures() []string {
+	if e.AssertionFailures == "" {
+		return nil
+	}
+	var failures []string
+	if err := json.Unmarshal([]byte(e.AssertionFailures), &failures); err != nil {
+		return []string{e.AssertionFailures}
+	}
+	return failures
+}
+
+// Headers decodes the entry's response headers. Values stored as lists are
+// joined the way HTTP combines repeated headers. It returns nil if the entry
+// has no headers.
+func (e *HistoryEntry) Headers() (map[string]string, error) {
+	if e.ResponseHeaders == "" {
+		return nil, nil
+	}
+
+	var single map[string]string
+	if err := json.Unmarshal([]byte(e.ResponseHeaders), &single); err == nil {
+		return single, nil
+	}
+
+	var multi map[string][]string
+	if err := json.Unmarshal([]byte(e.ResponseHeaders), &multi); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response headers: %w", err)
+	}
+	joined := make(map[string]string, len(multi))
+	for name, values := range multi {
+		joined[name] = strings.Join(values, ", ")
+	}
+	return joined, nil
+}
+
+// Response reconstructs the response recorded in the entry. Headers that
+// cannot be decoded are left out, as is a timestamp that cannot be parsed.
+func (e *HistoryEntry) Response() *domain.Response {
+	resp := domain.NewResponse()
+	resp.StatusCode = e.StatusCode
+	resp.Status = e.Status
+	if headers, err := e.Headers(); err == nil && headers != nil {
+		resp.Headers = headers
+	}
+	resp.Body = e.ResponseBody
+	resp.ContentLength = int64(len(e.ResponseBody))
+	resp.Duration = time.Duration(e.ResponseTimeMs) * time.Millisecond
+	resp.Timestamp, _ = time.Parse(time.RFC3339, e.ExecutedAt)
+	resp.RequestID = e.RequestID
+	resp.AssertionFailures = e.Failures()
+	return resp
+}
@@ -0,0 +1,167 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/williajm/curly/internal/domain"
+)
+
+func TestAuthConfigCodec_RoundTrip(t *testing.T) {
+	tests := []struct {
+		name string
+		auth domain.AuthConfig
+	}{
+		{name: "none", auth: domain.NewNoAuth()},
+		{name: "basic", auth: domain.NewBasicAuth("user", "pass")},
+		{name: "bearer", auth: domain.NewBearerAuth("token-123")},
+		{name: "apikey", auth: domain.NewAPIKeyAuth("X-API-Key", "secret", domain.APIKeyLocationQuery)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := MarshalAuthConfig(tt.auth)
+			require.NoError(t, err)
+
+			got, err := UnmarshalAuthConfig(tt.auth.Type(), string(data))
+			require.NoError(t, err)
+			assert.Equal(t, tt.auth, got)
+		})
+	}
+}
+
+func TestUnmarshalAuthConfig_Errors(t *testing.T) {
+	_, err := UnmarshalAuthConfig("oauth", "{}")
+	assert.Error(t, err)
+
+	_, err = UnmarshalAuthConfig("basic", "not json")
+	assert.Error(t, err)
+}
+
+func TestMarshalAuthConfig_Nil(t *testing.T) {
+	data, err := MarshalAuthConfig(nil)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{}`, string(data))
+}
+
+func TestRequestSnapshot_RoundTrip(t *testing.T) {
+	req := domain.NewRequestWithMethodAndURL(domain.MethodPost, "https://api.example.com/items")
+	req.Name = "Create item"
+	req.Headers["Content-Type"] = "application/json"
+	req.QueryParams["dry_run"] = "true"
+	req.Body = `{"name":"widget"}`
+	req.AuthConfig = domain.NewBearerAuth("token-123")
+	req.ResponseSchema = `{"type":"object"}`
+	req.ResponseContract = `{"method":"POST","path":"/items","responses":{}}`
+	req.ResponseFilter = "$.id"
+	req.DisabledQueryParams = map[string]string{"debug": "true"}
+	req.PreRequestScript = `request.headers["X-Id"] = crypto.randomUUID();`
+	req.PostResponseScript = "assert(response.status === 201);"
+	req.ExecutionCount = 7
+
+	data, err := MarshalRequestSnapshot(req)
+	require.NoError(t, err)
+
+	got, err := UnmarshalRequestSnapshot(data)
+	require.NoError(t, err)
+	assert.Equal(t, req.ID, got.ID)
+	assert.Equal(t, req.Name, got.Name)
+	assert.Equal(t, req.Method, got.Method)
+	assert.Equal(t, req.URL, got.URL)
+	assert.Equal(t, req.Headers, got.Headers)
+	assert.Equal(t, req.QueryParams, got.QueryParams)
+	assert.Equal(t, req.Body, got.Body)
+	assert.Equal(t, req.AuthConfig, got.AuthConfig)
+	assert.Equal(t, req.ResponseSchema, got.ResponseSchema)
+	assert.Equal(t, req.ResponseContract, got.ResponseContract)
+	assert.Equal(t, req.ResponseFilter, got.ResponseFilter)
+	assert.Equal(t, req.DisabledQueryParams, got.DisabledQueryParams)
+	assert.Equal(t, req.PostResponseScript, got.PostResponseScript)
+
+	// Usage metadata and the pre-request script are not part of the snapshot.
+	assert.Zero(t, got.ExecutionCount)
+	assert.Empty(t, got.PreRequestScript)
+}
+
+func TestHistoryEntry_Request(t *testing.T) {
+	req := domain.NewRequestWithMethodAndURL(domain.MethodDelete, "https://api.example.com/items/1")
+	snapshot, err := MarshalRequestSnapshot(req)
+	require.NoError(t, err)
+
+	got, err := (&HistoryEntry{RequestSnapshot: snapshot}).Request()
+	require.NoError(t, err)
+	assert.Equal(t, domain.MethodDelete, got.Method)
+	assert.Equal(t, req.URL, got.URL)
+
+	_, err = (&HistoryEntry{}).Request()
+	assert.ErrorIs(t, err, ErrNoSnapshot)
+}
+
+func TestUnmarshalRequestSnapshot_Errors(t *testing.T) {
+	_, err := UnmarshalRequestSnapshot("not json")
+	assert.Error(t, err)
+
+	_, err = UnmarshalRequestSnapshot(`{"method":"GET","url":"https://example.com","auth_type":"oauth"}`)
+	assert.Error(t, err)
+}
+
+func TestAssertionFailures_RoundTrip(t *testing.T) {
+	assert.Empty(t, MarshalAssertionFailures(nil))
+	assert.Nil(t, (&HistoryEntry{}).Failures())
+
+	failures := []string{"$.id: expected integer, got string", `$: missing required property "name"`}
+	entry := &HistoryEntry{AssertionFailures: MarshalAssertionFailures(failures)}
+	assert.Equal(t, failures, entry.Failures())
+
+	// Unreadable data is surfaced rather than dropped.
+	assert.Equal(t, []string{"oops"}, (&HistoryEntry{AssertionFailures: "oops"}).Failures())
+}
+
+func TestHistoryEntry_Headers(t *testing.T) {
+	headers, err := (&HistoryEntry{ResponseHeaders: `{"Content-Type":"application/json"}`}).Headers()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"Content-Type": "application/json"}, headers)
+
+	headers, err = (&HistoryEntry{ResponseHeaders: `{"Vary":["Accept","Origin"]}`}).Headers()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"Vary": "Accept, Origin"}, headers)
+
+	headers, err = (&HistoryEntry{}).Headers()
+	require.NoError(t, err)
+	assert.Nil(t, headers)
+
+	_, err = (&HistoryEntry{ResponseHeaders: "not json"}).Headers()
+	assert.Error(t, err)
+}
+
+func TestHistoryEntry_Response(t *testing.T) {
+	entry := &HistoryEntry{
+		RequestID:         "req-1",
+		ExecutedAt:        "2026-03-01T12:00:00Z",
+		StatusCode:        404,
+		Status:            "404 Not Found",
+		ResponseTimeMs:    120,
+		ResponseHeaders:   `{"Content-Type":"application/json"}`,
+		ResponseBody:      `{"error":"not found"}`,
+		AssertionFailures: MarshalAssertionFailures([]string{"status: want 200"}),
+	}
+
+	resp := entry.Response()
+	assert.Equal(t, 404, resp.StatusCode)
+	assert.Equal(t, "404 Not Found", resp.Status)
+	assert.Equal(t, "application/json", resp.ContentType())
+	assert.Equal(t, entry.ResponseBody, resp.Body)
+	assert.Equal(t, int64(len(entry.ResponseBody)), resp.ContentLength)
+	assert.Equal(t, int64(120), resp.DurationMillis())
+	assert.Equal(t, time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC), resp.Timestamp)
+	assert.Equal(t, "req-1", resp.RequestID)
+	assert.Equal(t, []string{"status: want 200"}, resp.AssertionFailures)
+
+	// Unreadable headers are left out rather than failing the response.
+	resp = (&HistoryEntry{ResponseHeaders: "not json"}).Response()
+	assert.Empty(t, resp.Headers)
+	assert.True(t, resp.Timestamp.IsZero())
+}
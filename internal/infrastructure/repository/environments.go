@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/williajm/curly/internal/domain"
+)
+
+// MarshalVariables serializes an environment's variables and secret names
+// as JSON, the object and array stored in its variables and secrets columns.
+func MarshalVariables(env *domain.Environment) (variables, secrets string, err error) {
+	vars := env.Variables
+	if vars == nil {
+		vars = map[string]string{}
+	}
+	data, err := json.Marshal(vars)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to serialize variables: %w", err)
+	}
+
+	names := append([]string{}, env.Secrets...)
+	sort.Strings(names)
+	secretData, err := json.Marshal(names)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to serialize secrets: %w", err)
+	}
+	return string(data), string(secretData), nil
+}
+
+// UnmarshalVariables restores an environment's variables and secret names
+// stored by MarshalVariables.
+func UnmarshalVariables(env *domain.Environment, variables, secrets string) error {
+	env.Variables = map[string]string{}
+	if variables != "" {
+		if err := json.Unmarshal([]byte(variables), &env.Variables); err != nil {
+			return fmt.Errorf("failed to deserialize variables: %w", err)
+		}
+	}
+
+	env.Secrets = nil
+	if secrets != "" {
+		if err := json.Unmarshal([]byte(secrets), &env.Secrets); err != nil {
+			return fmt.Errorf("failed to deserialize secrets: %w", err)
+		}
+	}
+	if len(env.Secrets) == 0 {
+		env.Secrets = nil
+	}
+	sort.Strings(env.Secrets)
+	return nil
+}
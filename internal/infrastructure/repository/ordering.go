@@ -0,0 +1,52 @@
+package repository
+
+// MoveID returns ids with id placed at position, preserving the relative order
+// of the others. id is removed first if present, and position is clamped to the
+// valid range, so moving past either end places it first or last.
+func MoveID(ids []string, id string, position int) []string {
+	ordered := make([]string, 0, len(ids)+1)
+	for _, other := range ids {
+		if other != id {
+			ordered = append(ordered, other)
+		}
+	}
+
+	position = max(0, min(position, len(ordered)))
+
+	ordered = append(ordered, "")
+	copy(ordered[position+1:], ordered[position:])
+	ordered[position] = id
+
+	return ordered
+}
+
+// AppendIDs returns ids with moved placed at the end in the given order,
+// preserving the relative order of the others. IDs in moved that are already
+// in ids are taken out of their current place first.
+func AppendIDs(ids, moved []string) []string {
+	skip := make(map[string]bool, len(moved))
+	for _, id := range moved {
+		skip[id] = true
+	}
+
+	ordered := make([]string, 0, len(ids)+len(moved))
+	for _, id := range ids {
+		if !skip[id] {
+			ordered = append(ordered, id)
+		}
+	}
+	return append(ordered, moved...)
+}
+
+// DistinctIDs returns ids without duplicates, keeping the first occurrence of each.
+func DistinctIDs(ids []string) []string {
+	seen := make(map[string]bool, len(ids))
+	distinct := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			distinct = append(distinct, id)
+		}
+	}
+	return distinct
+}
@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMoveID(t *testing.T) {
+	tests := []struct {
+		name     string
+		ids      []string
+		id       string
+		position int
+		want     []string
+	}{
+		{"move down", []string{"a", "b", "c"}, "a", 1, []string{"b", "a", "c"}},
+		{"move up", []string{"a", "b", "c"}, "c", 1, []string{"a", "c", "b"}},
+		{"move to end", []string{"a", "b", "c"}, "a", 2, []string{"b", "c", "a"}},
+		{"same position", []string{"a", "b", "c"}, "b", 1, []string{"a", "b", "c"}},
+		{"clamp below", []string{"a", "b", "c"}, "b", -5, []string{"b", "a", "c"}},
+		{"clamp above", []string{"a", "b", "c"}, "a", 99, []string{"b", "c", "a"}},
+		{"insert new", []string{"a", "b"}, "x", 1, []string{"a", "x", "b"}},
+		{"insert into empty", nil, "x", 3, []string{"x"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, MoveID(tt.ids, tt.id, tt.position))
+		})
+	}
+}
+
+func TestAppendIDs(t *testing.T) {
+	tests := []struct {
+		name  string
+		ids   []string
+		moved []string
+		want  []string
+	}{
+		{"from other folders", []string{"a", "b"}, []string{"x", "y"}, []string{"a", "b", "x", "y"}},
+		{"already in folder", []string{"a", "b", "c"}, []string{"a", "x"}, []string{"b", "c", "a", "x"}},
+		{"into empty", nil, []string{"x"}, []string{"x"}},
+		{"nothing moved", []string{"a"}, nil, []string{"a"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, AppendIDs(tt.ids, tt.moved))
+		})
+	}
+}
+
+func TestDistinctIDs(t *testing.T) {
+	assert.Equal(t, []string{"b", "a", "c"}, DistinctIDs([]string{"b", "a", "b", "c", "a"}))
+	assert.Empty(t, DistinctIDs(nil))
+}
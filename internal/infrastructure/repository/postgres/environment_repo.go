@@ -0,0 +1,187 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/williajm/curly/internal/domain"
+	"github.com/williajm/curly/internal/infrastructure/repository"
+)
+
+// environmentColumns lists the columns selected for an environment, in scan order.
+const environmentColumns = `id, name, variables, secrets, created_at, updated_at`
+
+// EnvironmentRepository implements repository.EnvironmentRepository using PostgreSQL.
+type EnvironmentRepository struct {
+	db *sql.DB
+}
+
+// NewEnvironmentRepository creates a new PostgreSQL-backed environment repository.
+func NewEnvironmentRepository(db *sql.DB) *EnvironmentRepository {
+	return &EnvironmentRepository{db: db}
+}
+
+// Create persists a new environment to the database.
+func (r *EnvironmentRepository) Create(ctx context.Context, env *domain.Environment) error {
+	if env == nil {
+		return fmt.Errorf("environment cannot be nil")
+	}
+	if err := env.Validate(); err != nil {
+		return fmt.Errorf("invalid environment: %w", err)
+	}
+
+	variables, secrets, err := repository.MarshalVariables(env)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO environments (id, name, variables, secrets, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`
+	_, err = r.db.ExecContext(ctx, query,
+		env.ID,
+		env.Name,
+		variables,
+		secrets,
+		env.CreatedAt.UTC(),
+		env.UpdatedAt.UTC(),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create environment: %w", err)
+	}
+
+	return nil
+}
+
+// Update replaces an existing environment's name and variables.
+func (r *EnvironmentRepository) Update(ctx context.Context, env *domain.Environment) error {
+	if env == nil {
+		return fmt.Errorf("environment cannot be nil")
+	}
+	if err := env.Validate(); err != nil {
+		return fmt.Errorf("invalid environment: %w", err)
+	}
+
+	env.UpdatedAt = time.Now()
+	variables, secrets, err := repository.MarshalVariables(env)
+	if err != nil {
+		return err
+	}
+
+	query := `UPDATE environments SET name = $1, variables = $2, secrets = $3, updated_at = $4 WHERE id = $5`
+	result, err := r.db.ExecContext(ctx, query, env.Name, variables, secrets, env.UpdatedAt.UTC(), env.ID)
+	if err != nil {
+		return fmt.Errorf("failed to update environment: %w", err)
+	}
+
+	return requireRowsAffected(result)
+}
+
+// Delete removes an environment from the database.
+func (r *EnvironmentRepository) Delete(ctx context.Context, id string) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM environments WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete environment: %w", err)
+	}
+
+	return requireRowsAffected(result)
+}
+
+// FindAll retrieves all environments ordered by name.
+func (r *EnvironmentRepository) FindAll(ctx context.Context) ([]*domain.Environment, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT `+environmentColumns+` FROM environments ORDER BY name`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query environments: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var envs []*domain.Environment
+	for rows.Next() {
+		env, err := scanEnvironment(rows)
+		if err != nil {
+			return nil, err
+		}
+		envs = append(envs, env)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+
+	return envs, nil
+}
+
+// FindByName retrieves an environment by its name.
+func (r *EnvironmentRepository) FindByName(ctx context.Context, name string) (*domain.Environment, error) {
+	return r.findOne(ctx, `SELECT `+environmentColumns+` FROM environments WHERE name = $1`, name)
+}
+
+// FindActive retrieves the active environment.
+func (r *EnvironmentRepository) FindActive(ctx context.Context) (*domain.Environment, error) {
+	return r.findOne(ctx, `SELECT `+environmentColumns+` FROM environments WHERE active`)
+}
+
+// SetActive makes one environment active, or none if id is empty.
+func (r *EnvironmentRepository) SetActive(ctx context.Context, id string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.ExecContext(ctx, `UPDATE environments SET active = FALSE WHERE active`); err != nil {
+		return fmt.Errorf("failed to deactivate environments: %w", err)
+	}
+	if id != "" {
+		result, err := tx.ExecContext(ctx, `UPDATE environments SET active = TRUE WHERE id = $1`, id)
+		if err != nil {
+			return fmt.Errorf("failed to activate environment: %w", err)
+		}
+		if err := requireRowsAffected(result); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// findOne retrieves the single environment selected by query.
+func (r *EnvironmentRepository) findOne(ctx context.Context, query string, args ...any) (*domain.Environment, error) {
+	env, err := scanEnvironment(r.db.QueryRowContext(ctx, query, args...))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, repository.ErrNotFound
+		}
+		return nil, err
+	}
+	return env, nil
+}
+
+// scanEnvironment reads an environment selected with environmentColumns.
+// It returns sql.ErrNoRows unwrapped so callers can map it to ErrNotFound.
+func scanEnvironment(row rowScanner) (*domain.Environment, error) {
+	var (
+		env                domain.Environment
+		variables, secrets string
+	)
+
+	if err := row.Scan(&env.ID, &env.Name, &variables, &secrets, &env.CreatedAt, &env.UpdatedAt); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to scan environment: %w", err)
+	}
+
+	if err := repository.UnmarshalVariables(&env, variables, secrets); err != nil {
+		return nil, err
+	}
+
+	return &env, nil
+}
@@ -0,0 +1,472 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/williajm/curly/internal/infrastructure/repository"
+)
+
+// historyColumns lists the columns selected for a history entry, in scan order.
+const historyColumns = `id, request_id, executed_at, status_code, status, response_time_ms, response_headers, response_body, response_body_ref, error, run_id, request_snapshot, replay_of, assertion_failures`
+
+// insertHistoryQuery inserts a history entry with the arguments from historyArgs.
+const insertHistoryQuery = `
+	INSERT INTO history (id, request_id, executed_at, status_code, status, response_time_ms, response_headers, response_body, response_body_ref, error, run_id, request_snapshot, replay_of, assertion_failures)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+`
+
+// HistoryRepository implements repository.HistoryRepository using PostgreSQL.
+type HistoryRepository struct {
+	db *sql.DB
+}
+
+// NewHistoryRepository creates a new PostgreSQL-backed history repository.
+func NewHistoryRepository(db *sql.DB) *HistoryRepository {
+	return &HistoryRepository{db: db}
+}
+
+// Save persists a history entry to the database.
+func (r *HistoryRepository) Save(ctx context.Context, entry *repository.HistoryEntry) error {
+	if entry == nil {
+		return fmt.Errorf("history entry cannot be nil")
+	}
+
+	args, err := historyArgs(entry)
+	if err != nil {
+		return err
+	}
+
+	if _, err := r.db.ExecContext(ctx, insertHistoryQuery, args...); err != nil {
+		return fmt.Errorf("failed to save history entry: %w", err)
+	}
+
+	return nil
+}
+
+// SaveBatch persists entries and updates their requests' usage in one transaction.
+func (r *HistoryRepository) SaveBatch(ctx context.Context, entries []*repository.HistoryEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	insert, err := tx.PrepareContext(ctx, insertHistoryQuery)
+	if err != nil {
+		return fmt.Errorf("failed to prepare history insert: %w", err)
+	}
+	defer func() { _ = insert.Close() }()
+
+	for _, entry := range entries {
+		if entry == nil {
+			return fmt.Errorf("history entry cannot be nil")
+		}
+		args, err := historyArgs(entry)
+		if err != nil {
+			return err
+		}
+		if _, err := insert.ExecContext(ctx, args...); err != nil {
+			return fmt.Errorf("failed to save history entry %s: %w", entry.ID, err)
+		}
+	}
+
+	// Requests that are not saved (ad-hoc executions) simply match no rows.
+	usageQuery := `
+		UPDATE requests
+		SET execution_count = execution_count + $1,
+			last_executed_at = GREATEST(last_executed_at, $2)
+		WHERE id = $3
+	`
+	for _, u := range repository.SummarizeUsage(entries) {
+		lastExecutedAt, err := parseTimestamp(u.LastExecutedAt)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, usageQuery, u.Count, lastExecutedAt, u.RequestID); err != nil {
+			return fmt.Errorf("failed to update request usage: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit history batch: %w", err)
+	}
+
+	return nil
+}
+
+// FindByID retrieves a history entry by its ID.
+func (r *HistoryRepository) FindByID(ctx context.Context, id string) (*repository.HistoryEntry, error) {
+	query := `SELECT ` + historyColumns + ` FROM history WHERE id = $1`
+
+	entry, err := scanHistoryEntry(r.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, repository.ErrNotFound
+		}
+		return nil, err
+	}
+
+	return entry, nil
+}
+
+// FindAll retrieves all history entries ordered by executed_at descending.
+func (r *HistoryRepository) FindAll(ctx context.Context, limit int) ([]*repository.HistoryEntry, error) {
+	query := `SELECT ` + historyColumns + ` FROM history ORDER BY executed_at DESC`
+	if limit > 0 {
+		query += fmt.Sprintf(" LIMIT %d", limit)
+	}
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query history: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	return scanHistoryEntries(rows)
+}
+
+// FindByRequestID retrieves all history entries for a specific request.
+func (r *HistoryRepository) FindByRequestID(ctx context.Context, requestID string, limit int) ([]*repository.HistoryEntry, error) {
+	query := `SELECT ` + historyColumns + ` FROM history WHERE request_id = $1 ORDER BY executed_at DESC`
+	if limit > 0 {
+		query += fmt.Sprintf(" LIMIT %d", limit)
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, requestID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query history by request ID: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	return scanHistoryEntries(rows)
+}
+
+// FindByRunID retrieves the history entries recorded by a collection run, in execution order.
+func (r *HistoryRepository) FindByRunID(ctx context.Context, runID string) ([]*repository.HistoryEntry, error) {
+	query := `SELECT ` + historyColumns + ` FROM history WHERE run_id = $1 ORDER BY executed_at ASC, id ASC`
+
+	rows, err := r.db.QueryContext(ctx, query, runID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query history by run ID: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	return scanHistoryEntries(rows)
+}
+
+// Delete removes a history entry from the database.
+func (r *HistoryRepository) Delete(ctx context.Context, id string) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM history WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete history entry: %w", err)
+	}
+
+	return requireRowsAffected(result)
+}
+
+// FindOlderThan retrieves all history entries older than the specified timestamp, oldest first.
+func (r *HistoryRepository) FindOlderThan(ctx context.Context, timestamp string) ([]*repository.HistoryEntry, error) {
+	cutoff, err := parseTimestamp(timestamp)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `SELECT ` + historyColumns + ` FROM history WHERE executed_at < $1 ORDER BY executed_at ASC`
+
+	rows, err := r.db.QueryContext(ctx, query, cutoff)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query old history entries: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	return scanHistoryEntries(rows)
+}
+
+// DeleteOlderThan removes all history entries older than the specified timestamp.
+func (r *HistoryRepository) DeleteOlderThan(ctx context.Context, timestamp string) (int64, error) {
+	cutoff, err := parseTimestamp(timestamp)
+	if err != nil {
+		return 0, err
+	}
+
+	result, err := r.db.ExecContext(ctx, `DELETE FROM history WHERE executed_at < $1`, cutoff)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete old history entries: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return rowsAffected, nil
+}
+
+// DeleteExceptNewest removes all but the keep most recently executed entries.
+func (r *HistoryRepository) DeleteExceptNewest(ctx context.Context, keep int) (int64, error) {
+	if keep <= 0 {
+		return 0, fmt.Errorf("keep must be positive, got: %d", keep)
+	}
+
+	query := `
+		DELETE FROM history
+		WHERE id NOT IN (
+			SELECT id FROM history ORDER BY executed_at DESC, id DESC LIMIT $1
+		)
+	`
+
+	result, err := r.db.ExecContext(ctx, query, keep)
+	if err != nil {
+		return 0, fmt.Errorf("failed to trim history entries: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return rowsAffected, nil
+}
+
+// FindMatching retrieves the history entries filter matches, newest first.
+func (r *HistoryRepository) FindMatching(ctx context.Context, filter repository.HistoryFilter, limit int) ([]*repository.HistoryEntry, error) {
+	where, args, err := historyFilterWhere(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `SELECT ` + historyColumns + ` FROM history WHERE ` + where + ` ORDER BY executed_at DESC`
+	if limit > 0 {
+		query += fmt.Sprintf(" LIMIT %d", limit)
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query matching history: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	return scanHistoryEntries(rows)
+}
+
+// FindPage retrieves limit of the history entries filter matches, newest
+// first, after skipping the offset newest.
+func (r *HistoryRepository) FindPage(ctx context.Context, filter repository.HistoryFilter, offset, limit int) ([]*repository.HistoryEntry, error) {
+	where, args, err := historyFilterWhere(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `SELECT ` + historyColumns + ` FROM history WHERE ` + where +
+		fmt.Sprintf(` ORDER BY executed_at DESC, id DESC LIMIT $%d OFFSET $%d`, len(args)+1, len(args)+2)
+
+	rows, err := r.db.QueryContext(ctx, query, append(args, limit, offset)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query history page: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	return scanHistoryEntries(rows)
+}
+
+// CountMatching returns how many history entries filter matches.
+func (r *HistoryRepository) CountMatching(ctx context.Context, filter repository.HistoryFilter) (int, error) {
+	where, args, err := historyFilterWhere(filter)
+	if err != nil {
+		return 0, err
+	}
+
+	var count int
+	if err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM history WHERE `+where, args...).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count history entries: %w", err)
+	}
+
+	return count, nil
+}
+
+// DeleteMatching removes the history entries filter matches.
+func (r *HistoryRepository) DeleteMatching(ctx context.Context, filter repository.HistoryFilter) (int64, error) {
+	where, args, err := historyFilterWhere(filter)
+	if err != nil {
+		return 0, err
+	}
+
+	result, err := r.db.ExecContext(ctx, `DELETE FROM history WHERE `+where, args...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete history entries: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return rowsAffected, nil
+}
+
+// historyFilterWhere builds the WHERE condition and arguments for filter.
+func historyFilterWhere(filter repository.HistoryFilter) (string, []any, error) {
+	conditions := []string{"TRUE"}
+	var args []any
+	arg := func(value any) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	if len(filter.IDs) > 0 {
+		placeholders := make([]string, len(filter.IDs))
+		for i, id := range filter.IDs {
+			placeholders[i] = arg(id)
+		}
+		conditions = append(conditions, "id IN ("+strings.Join(placeholders, ", ")+")")
+	}
+	if len(filter.RequestIDs) > 0 {
+		placeholders := make([]string, len(filter.RequestIDs))
+		for i, id := range filter.RequestIDs {
+			placeholders[i] = arg(id)
+		}
+		conditions = append(conditions, "request_id IN ("+strings.Join(placeholders, ", ")+")")
+	}
+	if filter.Before != "" {
+		before, err := parseTimestamp(filter.Before)
+		if err != nil {
+			return "", nil, err
+		}
+		conditions = append(conditions, "executed_at < "+arg(before))
+	}
+	if filter.After != "" {
+		after, err := parseTimestamp(filter.After)
+		if err != nil {
+			return "", nil, err
+		}
+		conditions = append(conditions, "executed_at >= "+arg(after))
+	}
+	if filter.FailedOnly {
+		conditions = append(conditions, failureCondition)
+	}
+	if filter.StatusClass != 0 {
+		conditions = append(conditions, "status_code >= "+arg(filter.StatusClass*100)+" AND status_code < "+arg((filter.StatusClass+1)*100))
+	}
+	if filter.Method != "" {
+		conditions = append(conditions, "upper(NULLIF(request_snapshot, '')::json->>'method') = upper("+arg(filter.Method)+")")
+	}
+	if filter.URLContains != "" {
+		conditions = append(conditions, "strpos(lower(NULLIF(request_snapshot, '')::json->>'url'), lower("+arg(filter.URLContains)+")) > 0")
+	}
+	if len(filter.BodyRefs) > 0 {
+		placeholders := make([]string, len(filter.BodyRefs))
+		for i, ref := range filter.BodyRefs {
+			placeholders[i] = arg(ref)
+		}
+		conditions = append(conditions, "response_body_ref IN ("+strings.Join(placeholders, ", ")+")")
+	}
+
+	return strings.Join(conditions, " AND "), args, nil
+}
+
+// scanHistoryEntry reads a history entry selected with historyColumns.
+func scanHistoryEntry(row rowScanner) (*repository.HistoryEntry, error) {
+	entry := &repository.HistoryEntry{}
+	var (
+		requestID, status, headers, body, bodyRef sql.NullString
+		errorMsg, runID, snapshot, replayOf       sql.NullString
+		failures                                  sql.NullString
+		statusCode                                sql.NullInt64
+		responseTime                              sql.NullInt64
+		executedAt                                time.Time
+	)
+
+	err := row.Scan(&entry.ID, &requestID, &executedAt, &statusCode, &status, &responseTime, &headers, &body, &bodyRef, &errorMsg, &runID, &snapshot, &replayOf, &failures)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to scan history entry: %w", err)
+	}
+
+	entry.RequestID = requestID.String
+	entry.ExecutedAt = executedAt.UTC().Format(time.RFC3339)
+	entry.StatusCode = int(statusCode.Int64)
+	entry.Status = status.String
+	entry.ResponseTimeMs = responseTime.Int64
+	entry.ResponseHeaders = headers.String
+	entry.ResponseBody = body.String
+	entry.ResponseBodyRef = bodyRef.String
+	entry.Error = errorMsg.String
+	entry.RunID = runID.String
+	entry.RequestSnapshot = snapshot.String
+	entry.ReplayOf = replayOf.String
+	entry.AssertionFailures = failures.String
+
+	return entry, nil
+}
+
+// scanHistoryEntries reads all remaining rows into history entries.
+func scanHistoryEntries(rows *sql.Rows) ([]*repository.HistoryEntry, error) {
+	var entries []*repository.HistoryEntry
+
+	for rows.Next() {
+		entry, err := scanHistoryEntry(rows)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+
+	return entries, nil
+}
+
+// historyArgs returns the insertHistoryQuery arguments for entry.
+func historyArgs(entry *repository.HistoryEntry) ([]any, error) {
+	executedAt, err := parseTimestamp(entry.ExecutedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return []any{
+		entry.ID,
+		nullString(entry.RequestID),
+		executedAt,
+		entry.StatusCode,
+		entry.Status,
+		entry.ResponseTimeMs,
+		entry.ResponseHeaders,
+		entry.ResponseBody,
+		nullString(entry.ResponseBodyRef),
+		nullString(entry.Error),
+		nullString(entry.RunID),
+		nullString(entry.RequestSnapshot),
+		nullString(entry.ReplayOf),
+		nullString(entry.AssertionFailures),
+	}, nil
+}
+
+// parseTimestamp parses the RFC3339 timestamps used by repository.HistoryEntry.
+func parseTimestamp(value string) (time.Time, error) {
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid timestamp %q: %w", value, err)
+	}
+	return t, nil
+}
+
+// nullString converts a string to sql.NullString, setting Valid to false if the string is empty.
+func nullString(s string) sql.NullString {
+	return sql.NullString{
+		String: s,
+		Valid:  s != "",
+	}
+}
@@ -0,0 +1,389 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/williajm/curly/internal/domain"
+	"github.com/williajm/curly/internal/infrastructure/repository"
+)
+
+// dsnEnv names the environment variable holding a DSN for a disposable test database.
+const dsnEnv = "CURLY_TEST_POSTGRES_DSN"
+
+// setupTestDB connects to the database named by CURLY_TEST_POSTGRES_DSN,
+// skipping the test if it is unset. All tables are emptied before the test runs.
+func setupTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+
+	dsn := os.Getenv(dsnEnv)
+	if dsn == "" {
+		t.Skipf("%s not set; skipping PostgreSQL tests", dsnEnv)
+	}
+
+	db, err := Open(&Config{DSN: dsn})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+
+	_, err = db.Exec(`TRUNCATE history, requests, environments, drafts, session`)
+	require.NoError(t, err)
+
+	return db
+}
+
+func newTestRequest(name string) *domain.Request {
+	req := domain.NewRequest()
+	req.Name = name
+	req.Method = "POST"
+	req.URL = "https://api.example.com/users"
+	req.SetHeader("Content-Type", "application/json")
+	req.SetQueryParam("page", "1")
+	req.Body = `{"name":"test"}`
+	req.SetAuth(domain.NewBasicAuth("user", "pass"))
+	return req
+}
+
+func TestRequestRepository_CRUD(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewRequestRepository(db)
+	ctx := context.Background()
+
+	req := newTestRequest("Create User")
+	require.NoError(t, repo.Create(ctx, req))
+
+	got, err := repo.FindByID(ctx, req.ID)
+	require.NoError(t, err)
+	assert.Equal(t, req.Name, got.Name)
+	assert.Equal(t, req.Headers, got.Headers)
+	assert.Equal(t, req.QueryParams, got.QueryParams)
+	assert.Equal(t, req.Body, got.Body)
+	assert.Equal(t, req.AuthConfig, got.AuthConfig)
+
+	executedAt := time.Now().UTC().Truncate(time.Second)
+	require.NoError(t, repo.RecordExecution(ctx, req.ID, executedAt))
+	recent, err := repo.FindRecentlyUsed(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, recent, 1)
+	assert.Equal(t, int64(1), recent[0].ExecutionCount)
+	assert.True(t, executedAt.Equal(recent[0].LastExecutedAt))
+
+	got.Name = "Create Admin"
+	require.NoError(t, repo.Update(ctx, got))
+
+	all, err := repo.FindAll(ctx)
+	require.NoError(t, err)
+	require.Len(t, all, 1)
+	assert.Equal(t, "Create Admin", all[0].Name)
+
+	second := newTestRequest("List Users")
+	require.NoError(t, repo.Create(ctx, second))
+	assert.Equal(t, 1, second.Position)
+
+	require.NoError(t, repo.Move(ctx, second.ID, "", 0))
+	ordered, err := repo.FindByFolder(ctx, "")
+	require.NoError(t, err)
+	require.Len(t, ordered, 2)
+	assert.Equal(t, second.ID, ordered[0].ID)
+	assert.Equal(t, 1, ordered[1].Position)
+
+	require.NoError(t, repo.Move(ctx, second.ID, "users", 0))
+	moved, err := repo.FindByID(ctx, second.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "users", moved.Folder)
+	assert.True(t, errors.Is(repo.Move(ctx, "missing", "", 0), repository.ErrNotFound))
+	require.NoError(t, repo.Delete(ctx, second.ID))
+
+	require.NoError(t, repo.Delete(ctx, req.ID))
+	_, err = repo.FindByID(ctx, req.ID)
+	assert.True(t, errors.Is(err, repository.ErrNotFound))
+	assert.True(t, errors.Is(repo.Delete(ctx, req.ID), repository.ErrNotFound))
+}
+
+func TestHistoryRepository(t *testing.T) {
+	db := setupTestDB(t)
+	requests := NewRequestRepository(db)
+	history := NewHistoryRepository(db)
+	ctx := context.Background()
+
+	req := newTestRequest("Get Users")
+	require.NoError(t, requests.Create(ctx, req))
+
+	now := time.Now().UTC()
+	old := &repository.HistoryEntry{
+		ID:         uuid.New().String(),
+		RequestID:  req.ID,
+		ExecutedAt: now.AddDate(0, 0, -30).Format(time.RFC3339),
+		StatusCode: 500,
+		Status:     "500 Internal Server Error",
+	}
+	recent := &repository.HistoryEntry{
+		ID:              uuid.New().String(),
+		RequestID:       req.ID,
+		ExecutedAt:      now.Format(time.RFC3339),
+		StatusCode:      200,
+		Status:          "200 OK",
+		ResponseTimeMs:  42,
+		ResponseBody:    `{"users":[]}`,
+		RunID:           "run-1",
+		RequestSnapshot: `{"method":"GET","url":"https://api.example.com/users"}`,
+		ReplayOf:        "hist-0",
+	}
+	require.NoError(t, history.SaveBatch(ctx, []*repository.HistoryEntry{old, recent}))
+
+	got, err := history.FindByID(ctx, recent.ID)
+	require.NoError(t, err)
+	assert.Equal(t, recent, got)
+
+	saved, err := requests.FindByID(ctx, req.ID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), saved.ExecutionCount)
+	assert.Equal(t, recent.ExecutedAt, saved.LastExecutedAt.UTC().Format(time.RFC3339))
+
+	entries, err := history.FindByRequestID(ctx, req.ID, 1)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, recent.ID, entries[0].ID)
+
+	entries, err = history.FindByRunID(ctx, "run-1")
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, recent.ID, entries[0].ID)
+
+	expired, err := history.FindOlderThan(ctx, now.AddDate(0, 0, -7).Format(time.RFC3339))
+	require.NoError(t, err)
+	require.Len(t, expired, 1)
+	assert.Equal(t, old.ID, expired[0].ID)
+
+	deleted, err := history.DeleteOlderThan(ctx, now.AddDate(0, 0, -7).Format(time.RFC3339))
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), deleted)
+
+	require.NoError(t, history.Save(ctx, old))
+	stats, err := history.Stats(ctx)
+	require.NoError(t, err)
+	require.Len(t, stats, 1)
+	assert.Equal(t, int64(2), stats[0].Count)
+	assert.Equal(t, int64(1), stats[0].SuccessCount)
+	assert.Equal(t, int64(42), stats[0].P50Ms)
+	assert.Equal(t, old.ExecutedAt, stats[0].LastFailureAt)
+
+	hosts, err := history.HostStats(ctx)
+	require.NoError(t, err)
+	require.Len(t, hosts, 2)
+	assert.Equal(t, "", hosts[0].Host)
+	assert.Equal(t, "api.example.com", hosts[1].Host)
+	assert.Equal(t, int64(1), hosts[1].SuccessCount)
+
+	deleted, err = history.DeleteExceptNewest(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), deleted)
+
+	// Deleting the request cascades to its history.
+	require.NoError(t, requests.Delete(ctx, req.ID))
+	entries, err = history.FindAll(ctx, 0)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestHistoryRepository_FindMatching(t *testing.T) {
+	db := setupTestDB(t)
+	history := NewHistoryRepository(db)
+	ctx := context.Background()
+
+	now := time.Now().UTC()
+	for _, entry := range []*repository.HistoryEntry{
+		{ID: "get-users-ok", ExecutedAt: now.Add(-4 * time.Hour).Format(time.RFC3339), StatusCode: 200, Status: "200 OK",
+			RequestSnapshot: `{"method":"GET","url":"https://api.example.com/Users"}`},
+		{ID: "post-users-404", ExecutedAt: now.Add(-3 * time.Hour).Format(time.RFC3339), StatusCode: 404, Status: "404 Not Found",
+			RequestSnapshot: `{"method":"POST","url":"https://api.example.com/users"}`, ResponseBodyRef: "ref-404"},
+		{ID: "post-orders-err", ExecutedAt: now.Add(-2 * time.Hour).Format(time.RFC3339), Error: "connection refused",
+			RequestSnapshot: `{"method":"POST","url":"https://api.example.com/orders"}`},
+		{ID: "no-snapshot-400", ExecutedAt: now.Add(-1 * time.Hour).Format(time.RFC3339), StatusCode: 400, Status: "400 Bad Request"},
+	} {
+		require.NoError(t, history.Save(ctx, entry))
+	}
+
+	tests := []struct {
+		name   string
+		filter repository.HistoryFilter
+		limit  int
+		want   []string
+	}{
+		{"empty filter", repository.HistoryFilter{}, 0, []string{"no-snapshot-400", "post-orders-err", "post-users-404", "get-users-ok"}},
+		{"limit", repository.HistoryFilter{}, 2, []string{"no-snapshot-400", "post-orders-err"}},
+		{"before", repository.HistoryFilter{Before: now.Add(-150 * time.Minute).Format(time.RFC3339)}, 0, []string{"post-users-404", "get-users-ok"}},
+		{"after", repository.HistoryFilter{After: now.Add(-150 * time.Minute).Format(time.RFC3339)}, 0, []string{"no-snapshot-400", "post-orders-err"}},
+		{"failed only", repository.HistoryFilter{FailedOnly: true, URLContains: "example"}, 0, []string{"post-orders-err", "post-users-404"}},
+		{"ids", repository.HistoryFilter{IDs: []string{"get-users-ok", "post-orders-err", "missing"}}, 0, []string{"post-orders-err", "get-users-ok"}},
+		{"body refs", repository.HistoryFilter{BodyRefs: []string{"ref-404", "ref-unused"}}, 0, []string{"post-users-404"}},
+		{"no match", repository.HistoryFilter{StatusClass: 5}, 0, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			found, err := history.FindMatching(ctx, tt.filter, tt.limit)
+			require.NoError(t, err)
+			var ids []string
+			for _, entry := range found {
+				ids = append(ids, entry.ID)
+			}
+			assert.Equal(t, tt.want, ids)
+		})
+	}
+
+	_, err := history.FindMatching(ctx, repository.HistoryFilter{Before: "yesterday"}, 0)
+	assert.Error(t, err)
+}
+
+func TestRequestRepository_Bulk(t *testing.T) {
+	db := setupTestDB(t)
+	requests := NewRequestRepository(db)
+	history := NewHistoryRepository(db)
+	ctx := context.Background()
+
+	var ids []string
+	for _, name := range []string{"a", "b", "c"} {
+		req := newTestRequest(name)
+		require.NoError(t, requests.Create(ctx, req))
+		ids = append(ids, req.ID)
+	}
+
+	require.NoError(t, requests.Retag(ctx, ids[:2], []string{"smoke", "users"}, []string{"users"}))
+	got, err := requests.FindByID(ctx, ids[0])
+	require.NoError(t, err)
+	assert.Equal(t, []string{"smoke"}, got.Tags)
+
+	require.NoError(t, requests.MoveMany(ctx, []string{ids[2], ids[0]}, "auth"))
+	folder, err := requests.FindByFolder(ctx, "auth")
+	require.NoError(t, err)
+	require.Len(t, folder, 2)
+	assert.Equal(t, ids[2], folder[0].ID)
+	assert.Equal(t, ids[0], folder[1].ID)
+
+	now := time.Now().UTC().Truncate(time.Second)
+	for i, code := range []int{200, 500} {
+		require.NoError(t, history.Save(ctx, &repository.HistoryEntry{
+			ID:              uuid.New().String(),
+			RequestID:       ids[1],
+			ExecutedAt:      now.Add(time.Duration(i) * time.Second).Format(time.RFC3339),
+			StatusCode:      code,
+			Status:          fmt.Sprint(code),
+			RequestSnapshot: fmt.Sprintf(`{"method":"POST","url":"https://api.example.com/%d"}`, code),
+		}))
+	}
+	found, err := history.FindMatching(ctx, repository.HistoryFilter{StatusClass: 5, Method: "post", URLContains: "EXAMPLE"}, 0)
+	require.NoError(t, err)
+	require.Len(t, found, 1)
+	assert.Equal(t, 500, found[0].StatusCode)
+
+	count, err := history.CountMatching(ctx, repository.HistoryFilter{StatusClass: 5})
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+	page, err := history.FindPage(ctx, repository.HistoryFilter{}, 1, 10)
+	require.NoError(t, err)
+	require.Len(t, page, 1)
+	assert.Equal(t, 200, page[0].StatusCode)
+
+	deleted, err := history.DeleteMatching(ctx, repository.HistoryFilter{RequestIDs: ids[1:2], FailedOnly: true})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), deleted)
+
+	err = requests.DeleteMany(ctx, []string{ids[0], uuid.New().String()})
+	assert.ErrorIs(t, err, repository.ErrNotFound)
+	require.NoError(t, requests.DeleteMany(ctx, ids))
+	all, err := requests.FindAll(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, all)
+}
+
+func TestEnvironmentRepository(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewEnvironmentRepository(db)
+	ctx := context.Background()
+
+	dev := domain.NewEnvironment("dev")
+	dev.Set("baseUrl", "http://localhost:8080", false)
+	dev.Set("token", "s3cret", true)
+	prod := domain.NewEnvironment("prod")
+	require.NoError(t, repo.Create(ctx, dev))
+	require.NoError(t, repo.Create(ctx, prod))
+	assert.Error(t, repo.Create(ctx, domain.NewEnvironment("dev")), "names are unique")
+
+	found, err := repo.FindByName(ctx, "dev")
+	require.NoError(t, err)
+	assert.Equal(t, dev.Variables, found.Variables)
+	assert.Equal(t, []string{"token"}, found.Secrets)
+
+	_, err = repo.FindActive(ctx)
+	assert.ErrorIs(t, err, repository.ErrNotFound)
+	require.NoError(t, repo.SetActive(ctx, dev.ID))
+	require.NoError(t, repo.SetActive(ctx, prod.ID))
+	active, err := repo.FindActive(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, prod.ID, active.ID)
+	assert.ErrorIs(t, repo.SetActive(ctx, uuid.New().String()), repository.ErrNotFound)
+
+	require.NoError(t, repo.Delete(ctx, prod.ID))
+	_, err = repo.FindActive(ctx)
+	assert.ErrorIs(t, err, repository.ErrNotFound)
+
+	all, err := repo.FindAll(ctx)
+	require.NoError(t, err)
+	require.Len(t, all, 1)
+	assert.Equal(t, "dev", all[0].Name)
+}
+
+func TestDraftRepository(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewDraftRepository(db)
+	ctx := context.Background()
+
+	older := &domain.Draft{ID: uuid.New().String(), Request: newTestRequest("Older"), UpdatedAt: time.Now().Add(-time.Hour)}
+	draft := &domain.Draft{ID: uuid.New().String(), Request: newTestRequest("Create User"), UpdatedAt: time.Now()}
+	require.NoError(t, repo.Save(ctx, older))
+	require.NoError(t, repo.Save(ctx, draft))
+	draft.Request.URL = "https://api.example.com/accounts"
+	require.NoError(t, repo.Save(ctx, draft), "saving again replaces the draft")
+
+	all, err := repo.FindAll(ctx)
+	require.NoError(t, err)
+	require.Len(t, all, 2)
+	assert.Equal(t, draft.ID, all[0].ID)
+	assert.Equal(t, "https://api.example.com/accounts", all[0].Request.URL)
+	assert.Equal(t, draft.Request.ID, all[0].Request.ID)
+	assert.Equal(t, draft.Request.AuthConfig, all[0].Request.AuthConfig)
+
+	require.NoError(t, repo.Delete(ctx, older.ID))
+	assert.ErrorIs(t, repo.Delete(ctx, older.ID), repository.ErrNotFound)
+}
+
+func TestSessionRepository(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewSessionRepository(db)
+	ctx := context.Background()
+
+	_, err := repo.Find(ctx)
+	assert.ErrorIs(t, err, repository.ErrNotFound)
+
+	require.NoError(t, repo.Save(ctx, &domain.Session{RequestID: "req-1", Tab: "Response", UpdatedAt: time.Now()}))
+	now := time.Now().Truncate(time.Second)
+	require.NoError(t, repo.Save(ctx, &domain.Session{RequestID: "req-2", Tab: "History", ResponseOffset: 12, HistoryIndex: 7, UpdatedAt: now}),
+		"saving again replaces the session")
+
+	session, err := repo.Find(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "req-2", session.RequestID)
+	assert.Equal(t, "History", session.Tab)
+	assert.Equal(t, 12, session.ResponseOffset)
+	assert.Equal(t, 7, session.HistoryIndex)
+	assert.True(t, now.Equal(session.UpdatedAt))
+}
@@ -0,0 +1,528 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/williajm/curly/internal/domain"
+	"github.com/williajm/curly/internal/infrastructure/repository"
+)
+
+// requestColumns lists the columns selected for a request, in scan order.
+const requestColumns = `id, name, method, url, headers, query_params, body, auth_type, auth_config, created_at, updated_at, last_executed_at, execution_count, folder, position, response_schema, response_contract, tags, response_filter, pre_request_script, post_response_script, disabled_query_params`
+
+// RequestRepository implements repository.RequestRepository using PostgreSQL.
+type RequestRepository struct {
+	db *sql.DB
+}
+
+// NewRequestRepository creates a new PostgreSQL-backed request repository.
+func NewRequestRepository(db *sql.DB) *RequestRepository {
+	return &RequestRepository{db: db}
+}
+
+// Create persists a new request to the database.
+func (r *RequestRepository) Create(ctx context.Context, req *domain.Request) error {
+	if req == nil {
+		return fmt.Errorf("request cannot be nil")
+	}
+
+	if err := req.Validate(); err != nil {
+		return fmt.Errorf("invalid request: %w", err)
+	}
+
+	fields, err := encodeRequest(req)
+	if err != nil {
+		return err
+	}
+
+	// Append the request to the end of its folder.
+	query := `
+		INSERT INTO requests (id, name, method, url, headers, query_params, body, auth_type, auth_config, created_at, updated_at, response_schema, response_contract, tags, response_filter, pre_request_script, post_response_script, disabled_query_params, folder, position)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, (SELECT COALESCE(MAX(position), -1) + 1 FROM requests WHERE folder = $19))
+		RETURNING position
+	`
+
+	err = r.db.QueryRowContext(ctx, query,
+		req.ID,
+		req.Name,
+		req.Method,
+		req.URL,
+		fields.headers,
+		fields.queryParams,
+		req.Body,
+		fields.authType,
+		fields.authConfig,
+		req.CreatedAt.UTC(),
+		req.UpdatedAt.UTC(),
+		req.ResponseSchema,
+		req.ResponseContract,
+		fields.tags,
+		req.ResponseFilter,
+		req.PreRequestScript,
+		req.PostResponseScript,
+		repository.MarshalQueryParams(req.DisabledQueryParams),
+		req.Folder,
+	).Scan(&req.Position)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	return nil
+}
+
+// FindByID retrieves a request by its ID.
+func (r *RequestRepository) FindByID(ctx context.Context, id string) (*domain.Request, error) {
+	query := `SELECT ` + requestColumns + ` FROM requests WHERE id = $1`
+
+	req, err := scanRequest(r.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, repository.ErrNotFound
+		}
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// FindAll retrieves all requests ordered by created_at descending.
+func (r *RequestRepository) FindAll(ctx context.Context) ([]*domain.Request, error) {
+	query := `SELECT ` + requestColumns + ` FROM requests ORDER BY created_at DESC`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query requests: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	return scanRequests(rows)
+}
+
+// Update modifies an existing request.
+func (r *RequestRepository) Update(ctx context.Context, req *domain.Request) error {
+	if req == nil {
+		return fmt.Errorf("request cannot be nil")
+	}
+
+	if err := req.Validate(); err != nil {
+		return fmt.Errorf("invalid request: %w", err)
+	}
+
+	req.UpdatedAt = time.Now()
+
+	fields, err := encodeRequest(req)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		UPDATE requests
+		SET name = $1, method = $2, url = $3, headers = $4, query_params = $5, body = $6, auth_type = $7, auth_config = $8, response_schema = $9, response_contract = $10, tags = $11, response_filter = $12, pre_request_script = $13, post_response_script = $14, disabled_query_params = $15, updated_at = $16
+		WHERE id = $17
+	`
+
+	result, err := r.db.ExecContext(ctx, query,
+		req.Name,
+		req.Method,
+		req.URL,
+		fields.headers,
+		fields.queryParams,
+		req.Body,
+		fields.authType,
+		fields.authConfig,
+		req.ResponseSchema,
+		req.ResponseContract,
+		fields.tags,
+		req.ResponseFilter,
+		req.PreRequestScript,
+		req.PostResponseScript,
+		repository.MarshalQueryParams(req.DisabledQueryParams),
+		req.UpdatedAt.UTC(),
+		req.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update request: %w", err)
+	}
+
+	return requireRowsAffected(result)
+}
+
+// Delete removes a request from the database.
+func (r *RequestRepository) Delete(ctx context.Context, id string) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM requests WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete request: %w", err)
+	}
+
+	return requireRowsAffected(result)
+}
+
+// FindRecentlyUsed retrieves requests ordered by last execution, most recent first.
+func (r *RequestRepository) FindRecentlyUsed(ctx context.Context, limit int) ([]*domain.Request, error) {
+	query := `SELECT ` + requestColumns + ` FROM requests ORDER BY last_executed_at DESC NULLS LAST, created_at DESC`
+	if limit > 0 {
+		query += fmt.Sprintf(" LIMIT %d", limit)
+	}
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query recently used requests: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	return scanRequests(rows)
+}
+
+// RecordExecution increments a request's execution count and sets its last execution time.
+func (r *RequestRepository) RecordExecution(ctx context.Context, id string, executedAt time.Time) error {
+	query := `UPDATE requests SET execution_count = execution_count + 1, last_executed_at = $1 WHERE id = $2`
+
+	result, err := r.db.ExecContext(ctx, query, executedAt.UTC(), id)
+	if err != nil {
+		return fmt.Errorf("failed to record request execution: %w", err)
+	}
+
+	return requireRowsAffected(result)
+}
+
+// FindByFolder retrieves the requests in a folder ordered by position.
+func (r *RequestRepository) FindByFolder(ctx context.Context, folder string) ([]*domain.Request, error) {
+	query := `SELECT ` + requestColumns + ` FROM requests WHERE folder = $1 ORDER BY position, created_at, id`
+
+	rows, err := r.db.QueryContext(ctx, query, folder)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query folder: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	return scanRequests(rows)
+}
+
+// Move places a request at position within folder and renumbers the affected folders.
+func (r *RequestRepository) Move(ctx context.Context, id, folder string, position int) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	// Lock the moved row so concurrent moves of the same request serialize.
+	var oldFolder string
+	err = tx.QueryRowContext(ctx, `SELECT folder FROM requests WHERE id = $1 FOR UPDATE`, id).Scan(&oldFolder)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return repository.ErrNotFound
+		}
+		return fmt.Errorf("failed to load request: %w", err)
+	}
+
+	ids, err := folderIDs(ctx, tx, folder)
+	if err != nil {
+		return err
+	}
+	if err := renumber(ctx, tx, folder, repository.MoveID(ids, id, position)); err != nil {
+		return err
+	}
+
+	// Close the gap left in the folder the request came from.
+	if oldFolder != folder {
+		ids, err := folderIDs(ctx, tx, oldFolder)
+		if err != nil {
+			return err
+		}
+		if err := renumber(ctx, tx, oldFolder, ids); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit move: %w", err)
+	}
+
+	return nil
+}
+
+// folderIDs returns the IDs of the requests in folder in their current order.
+func folderIDs(ctx context.Context, tx *sql.Tx, folder string) ([]string, error) {
+	rows, err := tx.QueryContext(ctx, `SELECT id FROM requests WHERE folder = $1 ORDER BY position, created_at, id`, folder)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query folder: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan request id: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+
+	return ids, nil
+}
+
+// renumber assigns ids consecutive positions from 0 within folder.
+func renumber(ctx context.Context, tx *sql.Tx, folder string, ids []string) error {
+	for i, id := range ids {
+		if _, err := tx.ExecContext(ctx, `UPDATE requests SET folder = $1, position = $2 WHERE id = $3`, folder, i, id); err != nil {
+			return fmt.Errorf("failed to update request position: %w", err)
+		}
+	}
+	return nil
+}
+
+// DeleteMany removes requests in a single transaction.
+func (r *RequestRepository) DeleteMany(ctx context.Context, ids []string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	for _, id := range repository.DistinctIDs(ids) {
+		result, err := tx.ExecContext(ctx, `DELETE FROM requests WHERE id = $1`, id)
+		if err != nil {
+			return fmt.Errorf("failed to delete request: %w", err)
+		}
+		if err := requireRowsAffected(result); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit delete: %w", err)
+	}
+
+	return nil
+}
+
+// MoveMany appends requests to the end of folder and renumbers the affected folders.
+func (r *RequestRepository) MoveMany(ctx context.Context, ids []string, folder string) error {
+	ids = repository.DistinctIDs(ids)
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	// Lock the moved rows so concurrent moves of the same requests serialize.
+	oldFolders := make(map[string]bool)
+	for _, id := range ids {
+		var oldFolder string
+		err := tx.QueryRowContext(ctx, `SELECT folder FROM requests WHERE id = $1 FOR UPDATE`, id).Scan(&oldFolder)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return repository.ErrNotFound
+			}
+			return fmt.Errorf("failed to load request: %w", err)
+		}
+		oldFolders[oldFolder] = true
+	}
+
+	folderOrder, err := folderIDs(ctx, tx, folder)
+	if err != nil {
+		return err
+	}
+	if err := renumber(ctx, tx, folder, repository.AppendIDs(folderOrder, ids)); err != nil {
+		return err
+	}
+
+	// Close the gaps left in the folders the requests came from.
+	for oldFolder := range oldFolders {
+		if oldFolder == folder {
+			continue
+		}
+		remaining, err := folderIDs(ctx, tx, oldFolder)
+		if err != nil {
+			return err
+		}
+		if err := renumber(ctx, tx, oldFolder, remaining); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit move: %w", err)
+	}
+
+	return nil
+}
+
+// Retag adds and removes tags on requests in a single transaction.
+func (r *RequestRepository) Retag(ctx context.Context, ids []string, add, remove []string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	now := time.Now().UTC()
+	for _, id := range repository.DistinctIDs(ids) {
+		var tagsJSON string
+		err := tx.QueryRowContext(ctx, `SELECT tags FROM requests WHERE id = $1 FOR UPDATE`, id).Scan(&tagsJSON)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return repository.ErrNotFound
+			}
+			return fmt.Errorf("failed to load request: %w", err)
+		}
+		tags, err := repository.UnmarshalTags(tagsJSON)
+		if err != nil {
+			return err
+		}
+
+		retagged := repository.MarshalTags(repository.Retag(tags, add, remove))
+		if retagged == repository.MarshalTags(tags) {
+			continue
+		}
+		if _, err := tx.ExecContext(ctx, `UPDATE requests SET tags = $1, updated_at = $2 WHERE id = $3`, retagged, now, id); err != nil {
+			return fmt.Errorf("failed to update request tags: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit retag: %w", err)
+	}
+
+	return nil
+}
+
+// encodedRequest holds the serialized columns of a request.
+type encodedRequest struct {
+	headers     string
+	queryParams string
+	authType    string
+	authConfig  string
+	tags        string
+}
+
+// encodeRequest serializes the JSON columns of a request.
+func encodeRequest(req *domain.Request) (encodedRequest, error) {
+	headersJSON, err := json.Marshal(req.Headers)
+	if err != nil {
+		return encodedRequest{}, fmt.Errorf("failed to serialize headers: %w", err)
+	}
+
+	queryParamsJSON, err := json.Marshal(req.QueryParams)
+	if err != nil {
+		return encodedRequest{}, fmt.Errorf("failed to serialize query params: %w", err)
+	}
+
+	authConfigJSON, err := repository.MarshalAuthConfig(req.AuthConfig)
+	if err != nil {
+		return encodedRequest{}, fmt.Errorf("failed to serialize auth config: %w", err)
+	}
+
+	authType := ""
+	if req.AuthConfig != nil {
+		authType = req.AuthConfig.Type()
+	}
+
+	return encodedRequest{
+		headers:     string(headersJSON),
+		queryParams: string(queryParamsJSON),
+		authType:    authType,
+		authConfig:  string(authConfigJSON),
+		tags:        repository.MarshalTags(req.Tags),
+	}, nil
+}
+
+// rowScanner is satisfied by *sql.Row and *sql.Rows.
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// scanRequest reads a request selected with requestColumns.
+func scanRequest(row rowScanner) (*domain.Request, error) {
+	var (
+		req                                        domain.Request
+		headersJSON, queryParamsJSON, authConfigJS sql.NullString
+		body, authType                             sql.NullString
+		lastExecutedAt                             sql.NullTime
+		tagsJSON, disabledJSON                     string
+	)
+
+	err := row.Scan(&req.ID, &req.Name, &req.Method, &req.URL, &headersJSON, &queryParamsJSON, &body, &authType, &authConfigJS, &req.CreatedAt, &req.UpdatedAt, &lastExecutedAt, &req.ExecutionCount, &req.Folder, &req.Position, &req.ResponseSchema, &req.ResponseContract, &tagsJSON, &req.ResponseFilter, &req.PreRequestScript, &req.PostResponseScript, &disabledJSON)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to scan request: %w", err)
+	}
+
+	req.Body = body.String
+	if lastExecutedAt.Valid {
+		req.LastExecutedAt = lastExecutedAt.Time
+	}
+	req.Headers = map[string]string{}
+	req.QueryParams = map[string]string{}
+
+	if headersJSON.String != "" {
+		if err := json.Unmarshal([]byte(headersJSON.String), &req.Headers); err != nil {
+			return nil, fmt.Errorf("failed to deserialize headers: %w", err)
+		}
+	}
+	if queryParamsJSON.String != "" {
+		if err := json.Unmarshal([]byte(queryParamsJSON.String), &req.QueryParams); err != nil {
+			return nil, fmt.Errorf("failed to deserialize query params: %w", err)
+		}
+	}
+
+	authConfig, err := repository.UnmarshalAuthConfig(authType.String, authConfigJS.String)
+	if err != nil {
+		return nil, fmt.Errorf("failed to deserialize auth config: %w", err)
+	}
+	req.AuthConfig = authConfig
+
+	if req.Tags, err = repository.UnmarshalTags(tagsJSON); err != nil {
+		return nil, err
+	}
+	if req.DisabledQueryParams, err = repository.UnmarshalQueryParams(disabledJSON); err != nil {
+		return nil, err
+	}
+
+	return &req, nil
+}
+
+// scanRequests reads all remaining rows into requests.
+func scanRequests(rows *sql.Rows) ([]*domain.Request, error) {
+	var requests []*domain.Request
+
+	for rows.Next() {
+		req, err := scanRequest(rows)
+		if err != nil {
+			return nil, err
+		}
+		requests = append(requests, req)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+
+	return requests, nil
+}
+
+// requireRowsAffected returns repository.ErrNotFound if a statement changed no rows.
+func requireRowsAffected(result sql.Result) error {
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return repository.ErrNotFound
+	}
+
+	return nil
+}
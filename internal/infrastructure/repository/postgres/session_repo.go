@@ -0,0 +1,73 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/williajm/curly/internal/domain"
+	"github.com/williajm/curly/internal/infrastructure/repository"
+)
+
+// SessionRepository implements repository.SessionRepository using PostgreSQL.
+type SessionRepository struct {
+	db *sql.DB
+}
+
+// NewSessionRepository creates a new PostgreSQL-backed session repository.
+func NewSessionRepository(db *sql.DB) *SessionRepository {
+	return &SessionRepository{db: db}
+}
+
+// Save replaces the session.
+func (r *SessionRepository) Save(ctx context.Context, session *domain.Session) error {
+	if session == nil {
+		return fmt.Errorf("session cannot be nil")
+	}
+
+	query := `
+		INSERT INTO session (id, request_id, tab, response_offset, history_index, updated_at)
+		VALUES (1, $1, $2, $3, $4, $5)
+		ON CONFLICT (id) DO UPDATE SET
+			request_id = excluded.request_id,
+			tab = excluded.tab,
+			response_offset = excluded.response_offset,
+			history_index = excluded.history_index,
+			updated_at = excluded.updated_at
+	`
+	_, err := r.db.ExecContext(ctx, query,
+		session.RequestID,
+		session.Tab,
+		session.ResponseOffset,
+		session.HistoryIndex,
+		session.UpdatedAt.UTC(),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save session: %w", err)
+	}
+
+	return nil
+}
+
+// Find retrieves the session.
+func (r *SessionRepository) Find(ctx context.Context) (*domain.Session, error) {
+	var session domain.Session
+
+	query := `SELECT request_id, tab, response_offset, history_index, updated_at FROM session WHERE id = 1`
+	err := r.db.QueryRowContext(ctx, query).Scan(
+		&session.RequestID,
+		&session.Tab,
+		&session.ResponseOffset,
+		&session.HistoryIndex,
+		&session.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, repository.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to find session: %w", err)
+	}
+
+	return &session, nil
+}
@@ -0,0 +1,117 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/williajm/curly/internal/infrastructure/repository"
+)
+
+// failureCondition matches history rows that did not succeed.
+const failureCondition = `(error IS NOT NULL OR status_code IS NULL OR status_code < 200 OR status_code >= 400 OR assertion_failures IS NOT NULL)`
+
+// statsQuery aggregates history grouped by the rid column of the keyed CTE
+// that keys defines, using PostgreSQL's ordered-set aggregates for the
+// latency percentiles.
+func statsQuery(keys string) string {
+	return `
+	WITH ` + keys + `,
+	totals AS (
+		SELECT
+			rid,
+			COUNT(*) AS total,
+			COUNT(*) FILTER (WHERE NOT ` + failureCondition + `) AS successes,
+			MAX(executed_at) FILTER (WHERE ` + failureCondition + `) AS last_failure_at,
+			percentile_disc(0.50) WITHIN GROUP (ORDER BY response_time_ms) FILTER (WHERE error IS NULL) AS p50,
+			percentile_disc(0.95) WITHIN GROUP (ORDER BY response_time_ms) FILTER (WHERE error IS NULL) AS p95,
+			percentile_disc(0.99) WITHIN GROUP (ORDER BY response_time_ms) FILTER (WHERE error IS NULL) AS p99
+		FROM keyed
+		GROUP BY rid
+	)
+	SELECT
+		t.rid,
+		t.total,
+		t.successes,
+		COALESCE(t.p50, 0),
+		COALESCE(t.p95, 0),
+		COALESCE(t.p99, 0),
+		t.last_failure_at,
+		COALESCE((
+			SELECT COALESCE(h.error, CASE WHEN h.assertion_failures IS NOT NULL THEN 'assertions failed' END, h.status, '')
+			FROM keyed h
+			WHERE h.rid = t.rid AND h.executed_at = t.last_failure_at AND ` + failureCondition + `
+			LIMIT 1
+		), '')
+	FROM totals t
+	ORDER BY t.total DESC, t.rid
+`
+}
+
+// requestKeys keys history by request, with ad-hoc executions under an empty key.
+const requestKeys = `keyed AS (SELECT history.*, COALESCE(request_id, '') AS rid FROM history)`
+
+// hostKeys keys history by the host of the URL in the request snapshot, in
+// lowercase and with any port, less any user info. Entries without a
+// snapshot go under an empty key.
+const hostKeys = `keyed AS (
+		SELECT history.*,
+			COALESCE(lower(substring(NULLIF(request_snapshot, '')::json->>'url' FROM '^[^:/?#]+://(?:[^@/?#]*@)?([^/?#]+)')), '') AS rid
+		FROM history
+	)`
+
+// Stats aggregates history per request in the database.
+func (r *HistoryRepository) Stats(ctx context.Context) ([]*repository.RequestStats, error) {
+	return r.stats(ctx, requestKeys)
+}
+
+// HostStats aggregates history per host in the database.
+func (r *HistoryRepository) HostStats(ctx context.Context) ([]*repository.HostStats, error) {
+	stats, err := r.stats(ctx, hostKeys)
+	if err != nil {
+		return nil, err
+	}
+	hosts := make([]*repository.HostStats, len(stats))
+	for i, s := range stats {
+		hosts[i] = &repository.HostStats{
+			Host:          s.RequestID,
+			Count:         s.Count,
+			SuccessCount:  s.SuccessCount,
+			P50Ms:         s.P50Ms,
+			P95Ms:         s.P95Ms,
+			P99Ms:         s.P99Ms,
+			LastFailureAt: s.LastFailureAt,
+			LastFailure:   s.LastFailure,
+		}
+	}
+	return hosts, nil
+}
+
+// stats aggregates history by the key keys defines.
+func (r *HistoryRepository) stats(ctx context.Context, keys string) ([]*repository.RequestStats, error) {
+	rows, err := r.db.QueryContext(ctx, statsQuery(keys))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query history stats: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var stats []*repository.RequestStats
+	for rows.Next() {
+		s := &repository.RequestStats{}
+		var lastFailureAt sql.NullTime
+		if err := rows.Scan(&s.RequestID, &s.Count, &s.SuccessCount, &s.P50Ms, &s.P95Ms, &s.P99Ms, &lastFailureAt, &s.LastFailure); err != nil {
+			return nil, fmt.Errorf("failed to scan history stats: %w", err)
+		}
+		if lastFailureAt.Valid {
+			s.LastFailureAt = lastFailureAt.Time.UTC().Format(time.RFC3339)
+		}
+		stats = append(stats, s)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+
+	return stats, nil
+}
@@ -1,17 +1,31 @@
 // Package repository defines interfaces for data persistence operations.
+//
+// Storage backends (SQLite, PostgreSQL) live in sub-packages and implement
+// these interfaces, so services never depend on a specific database.
 package repository
 
 import (
 	"context"
+	"errors"
+	"io"
+	"time"
 
 	"github.com/williajm/curly/internal/domain"
 )
 
+// ErrNotFound is returned by repository implementations when a record does not exist.
+var ErrNotFound = errors.New("not found")
+
+// ErrNoSnapshot is returned when a history entry was recorded without a
+// request snapshot, as entries recorded before snapshots were kept are.
+var ErrNoSnapshot = errors.New("history entry has no request snapshot")
+
 // RequestRepository defines operations for persisting and retrieving HTTP requests.
 // Implementations should handle serialization of complex fields (headers, auth config).
 // and ensure proper transactional semantics where appropriate.
 type RequestRepository interface {
 	// Create persists a new request to the repository.
+	// The request is appended to the end of its folder and req.Position is set accordingly.
 	// Returns an error if the request already exists or validation fails.
 	Create(ctx context.Context, req *domain.Request) error
 
@@ -23,13 +37,47 @@ type RequestRepository interface {
 	// Results are ordered by created_at descending (newest first).
 	FindAll(ctx context.Context) ([]*domain.Request, error)
 
-	// Update modifies an existing request.
+	// Update modifies an existing request. Its folder and position are left
+	// unchanged; use Move to change them.
 	// Returns ErrNotFound if the request does not exist.
 	Update(ctx context.Context, req *domain.Request) error
 
 	// Delete removes a request from the repository.
 	// Returns ErrNotFound if the request does not exist.
 	Delete(ctx context.Context, id string) error
+
+	// FindRecentlyUsed retrieves saved requests ordered by last execution, most recent first.
+	// Requests that were never executed follow, newest first.
+	// Limit controls the maximum number of requests returned (0 = unlimited).
+	FindRecentlyUsed(ctx context.Context, limit int) ([]*domain.Request, error)
+
+	// RecordExecution increments a request's execution count and sets its last execution time.
+	// Returns ErrNotFound if the request does not exist.
+	RecordExecution(ctx context.Context, id string, executedAt time.Time) error
+
+	// FindByFolder retrieves the requests in a folder ordered by position.
+	FindByFolder(ctx context.Context, folder string) ([]*domain.Request, error)
+
+	// Move places a request at position within folder, which may differ from its
+	// current folder. Positions in the affected folders are renumbered from 0 and
+	// out-of-range positions are clamped to the start or end.
+	// Returns ErrNotFound if the request does not exist.
+	Move(ctx context.Context, id, folder string, position int) error
+
+	// DeleteMany removes requests in a single transaction. Duplicate IDs are
+	// ignored. Returns ErrNotFound, deleting nothing, if any request does not exist.
+	DeleteMany(ctx context.Context, ids []string) error
+
+	// MoveMany appends requests to the end of folder, in the order given, in a
+	// single transaction. Requests already in folder are moved to the end too,
+	// and the folders they came from are renumbered from 0.
+	// Returns ErrNotFound, moving nothing, if any request does not exist.
+	MoveMany(ctx context.Context, ids []string, folder string) error
+
+	// Retag adds the tags in add to, and removes those in remove from, each
+	// request in a single transaction. A tag in both is removed.
+	// Returns ErrNotFound, changing nothing, if any request does not exist.
+	Retag(ctx context.Context, ids []string, add, remove []string) error
 }
 
 // HistoryEntry represents a single execution of an HTTP request.
@@ -57,10 +105,30 @@ type HistoryEntry struct {
 	ResponseHeaders string
 
 	// ResponseBody is the response body content.
+	// When ResponseBodyRef is set it holds only a preview of the body.
 	ResponseBody string
 
+	// ResponseBodyRef is the content hash of a response body offloaded to disk
+	// (empty when the body is stored inline).
+	ResponseBodyRef string
+
 	// Error contains the error message if the request failed, empty on success.
 	Error string
+
+	// RunID groups the executions of one collection run (empty outside a run).
+	RunID string
+
+	// RequestSnapshot is the request as executed, serialized with
+	// MarshalRequestSnapshot (empty for entries recorded before snapshots).
+	RequestSnapshot string
+
+	// ReplayOf is the ID of the history entry this execution replayed
+	// (empty when it was not a replay).
+	ReplayOf string
+
+	// AssertionFailures lists the checks the response failed, such as schema
+	// violations, serialized with MarshalAssertionFailures (empty when none).
+	AssertionFailures string
 }
 
 // HistoryRepository defines operations for persisting and retrieving request execution history.
@@ -69,6 +137,12 @@ type HistoryRepository interface {
 	// This records the result of executing a request.
 	Save(ctx context.Context, entry *HistoryEntry) error
 
+	// SaveBatch persists entries in a single transaction and, in the same
+	// transaction, updates the usage metadata (execution count and last
+	// execution time) of the saved requests they belong to.
+	// Either all entries are saved or none are.
+	SaveBatch(ctx context.Context, entries []*HistoryEntry) error
+
 	// FindByID retrieves a history entry by its unique identifier.
 	// Returns ErrNotFound if the entry does not exist.
 	FindByID(ctx context.Context, id string) (*HistoryEntry, error)
@@ -83,11 +157,229 @@ type HistoryRepository interface {
 	// Limit controls the maximum number of entries returned (0 = unlimited).
 	FindByRequestID(ctx context.Context, requestID string, limit int) ([]*HistoryEntry, error)
 
+	// FindByRunID retrieves the history entries recorded by a collection run.
+	// Results are ordered by executed_at ascending (execution order).
+	FindByRunID(ctx context.Context, runID string) ([]*HistoryEntry, error)
+
 	// Delete removes a history entry from the repository.
 	// Returns ErrNotFound if the entry does not exist.
 	Delete(ctx context.Context, id string) error
 
+	// FindOlderThan retrieves all history entries older than the specified timestamp,
+	// i.e. the entries DeleteOlderThan would remove, oldest first.
+	FindOlderThan(ctx context.Context, timestamp string) ([]*HistoryEntry, error)
+
 	// DeleteOlderThan removes all history entries older than the specified timestamp.
 	// Returns the number of entries deleted.
 	DeleteOlderThan(ctx context.Context, timestamp string) (int64, error)
+
+	// DeleteExceptNewest removes all but the keep most recently executed entries.
+	// Keep must be positive. Returns the number of entries deleted.
+	DeleteExceptNewest(ctx context.Context, keep int) (int64, error)
+
+	// FindMatching retrieves the history entries filter matches.
+	// Results are ordered by executed_at descending (newest first).
+	// Limit controls the maximum number of entries returned (0 = unlimited).
+	FindMatching(ctx context.Context, filter HistoryFilter, limit int) ([]*HistoryEntry, error)
+
+	// FindPage retrieves a page of the history entries filter matches: at
+	// most limit entries, newest first, after skipping the offset newest.
+	// Entries executed at the same time are ordered by ID, so that pages do
+	// not overlap. An empty filter matches every entry.
+	FindPage(ctx context.Context, filter HistoryFilter, offset, limit int) ([]*HistoryEntry, error)
+
+	// CountMatching returns how many history entries filter matches.
+	// An empty filter matches every entry.
+	CountMatching(ctx context.Context, filter HistoryFilter) (int, error)
+
+	// DeleteMatching removes the history entries filter matches in a single
+	// statement. An empty filter matches every entry.
+	// Returns the number of entries deleted.
+	DeleteMatching(ctx context.Context, filter HistoryFilter) (int64, error)
+
+	// Stats aggregates history per request. Ad-hoc executions are grouped
+	// under an empty RequestID. Results are ordered by Count descending.
+	Stats(ctx context.Context) ([]*RequestStats, error)
+
+	// HostStats aggregates history per host of the request as executed.
+	// Entries recorded before request snapshots are grouped under an empty
+	// Host. Results are ordered by Count descending.
+	HostStats(ctx context.Context) ([]*HostStats, error)
+}
+
+// HistoryBodyOpener is implemented by history repositories that keep large
+// response bodies outside the history table, so that a body can be read a
+// chunk at a time rather than held in memory whole.
+type HistoryBodyOpener interface {
+	// OpenByID retrieves a history entry like FindByID, but leaves an
+	// offloaded response body as its preview and returns a reader of the
+	// full body. The caller must close the reader.
+	// Returns ErrNotFound if the entry does not exist.
+	OpenByID(ctx context.Context, id string) (*HistoryEntry, io.ReadCloser, error)
+}
+
+// EnvironmentRepository defines operations for persisting environments and
+// tracking which one is active.
+type EnvironmentRepository interface {
+	// Create persists a new environment.
+	// Returns an error if the environment is invalid or its name is taken.
+	Create(ctx context.Context, env *domain.Environment) error
+
+	// Update replaces an existing environment's name and variables.
+	// Returns ErrNotFound if the environment does not exist.
+	Update(ctx context.Context, env *domain.Environment) error
+
+	// Delete removes an environment. Deleting the active environment leaves
+	// none active. Returns ErrNotFound if the environment does not exist.
+	Delete(ctx context.Context, id string) error
+
+	// FindAll retrieves all environments ordered by name.
+	FindAll(ctx context.Context) ([]*domain.Environment, error)
+
+	// FindByName retrieves an environment by its name.
+	// Returns ErrNotFound if no environment has the name.
+	FindByName(ctx context.Context, name string) (*domain.Environment, error)
+
+	// FindActive retrieves the active environment.
+	// Returns ErrNotFound if no environment is active.
+	FindActive(ctx context.Context) (*domain.Environment, error)
+
+	// SetActive makes the environment with the given ID the only active one,
+	// or deactivates every environment if id is empty.
+	// Returns ErrNotFound, changing nothing, if the environment does not exist.
+	SetActive(ctx context.Context, id string) error
+}
+
+// DraftRepository defines operations for persisting the request builder's
+// unsaved changes.
+type DraftRepository interface {
+	// Save creates the draft, or replaces the one with the same ID.
+	Save(ctx context.Context, draft *domain.Draft) error
+
+	// FindAll retrieves all drafts, the most recently updated first.
+	FindAll(ctx context.Context) ([]*domain.Draft, error)
+
+	// Delete removes a draft.
+	// Returns ErrNotFound if the draft does not exist.
+	Delete(ctx context.Context, id string) error
+}
+
+// SessionRepository defines operations for persisting where the TUI was
+// left. There is at most one session.
+type SessionRepository interface {
+	// Save replaces the session.
+	Save(ctx context.Context, session *domain.Session) error
+
+	// Find retrieves the session.
+	// Returns ErrNotFound if no session was saved.
+	Find(ctx context.Context) (*domain.Session, error)
+}
+
+// HistoryFilter selects history entries. Criteria that are set must all
+// match; unset criteria match every entry.
+type HistoryFilter struct {
+	// IDs matches these history entries.
+	IDs []string
+
+	// RequestIDs matches the executions of any of these saved requests.
+	RequestIDs []string
+
+	// Before matches entries executed before this time, and After entries
+	// executed at or after it (RFC3339).
+	Before string
+	After  string
+
+	// FailedOnly matches executions that did not succeed; see RequestStats.
+	FailedOnly bool
+
+	// StatusClass matches responses whose status code is in this class,
+	// such as 4 for 4xx (0 matches any).
+	StatusClass int
+
+	// Method matches the method of the request as executed, ignoring case.
+	Method string
+
+	// URLContains matches requests whose URL contains this text, ignoring
+	// case. Method and URL come from the request snapshot, so entries
+	// recorded before snapshots never match them.
+	URLContains string
+
+	// BodyRefs matches entries whose response body is offloaded under any
+	// of these references.
+	BodyRefs []string
+}
+
+// IsEmpty reports whether the filter sets no criteria and so matches every entry.
+func (f HistoryFilter) IsEmpty() bool {
+	return len(f.IDs) == 0 && len(f.RequestIDs) == 0 && f.Before == "" && f.After == "" && !f.FailedOnly &&
+		f.StatusClass == 0 && f.Method == "" && f.URLContains == "" && len(f.BodyRefs) == 0
+}
+
+// RequestStats summarizes the execution history of a single request.
+// An execution succeeds if it returned a 2xx or 3xx status without error and
+// passed its assertions.
+type RequestStats struct {
+	// RequestID is the request the statistics cover (empty for ad-hoc executions).
+	RequestID string
+
+	// Count is the total number of executions.
+	Count int64
+
+	// SuccessCount is the number of successful executions.
+	SuccessCount int64
+
+	// P50Ms, P95Ms and P99Ms are response time percentiles in milliseconds,
+	// computed over executions that received a response.
+	P50Ms int64
+	P95Ms int64
+	P99Ms int64
+
+	// LastFailureAt is when the most recent failure happened (RFC3339, empty if none).
+	LastFailureAt string
+
+	// LastFailure describes the most recent failure: its error, "assertions failed",
+	// or its status if it completed.
+	LastFailure string
+}
+
+// SuccessRate returns the fraction of executions that succeeded, from 0 to 1.
+func (s *RequestStats) SuccessRate() float64 {
+	if s.Count == 0 {
+		return 0
+	}
+	return float64(s.SuccessCount) / float64(s.Count)
+}
+
+// HostStats summarizes the execution history of the requests sent to a
+// single host. Executions succeed as described for RequestStats.
+type HostStats struct {
+	// Host is the host the requests were sent to, in lowercase and with its
+	// port if the URL gave one (empty for entries without a snapshot).
+	Host string
+
+	// Count is the total number of executions.
+	Count int64
+
+	// SuccessCount is the number of successful executions.
+	SuccessCount int64
+
+	// P50Ms, P95Ms and P99Ms are response time percentiles in milliseconds,
+	// computed over executions that received a response.
+	P50Ms int64
+	P95Ms int64
+	P99Ms int64
+
+	// LastFailureAt is when the most recent failure happened (RFC3339, empty if none).
+	LastFailureAt string
+
+	// LastFailure describes the most recent failure, as for RequestStats.
+	LastFailure string
+}
+
+// SuccessRate returns the fraction of executions that succeeded, from 0 to 1.
+func (s *HostStats) SuccessRate() float64 {
+	if s.Count == 0 {
+		return 0
+	}
+	return float64(s.SuccessCount) / float64(s.Count)
 }
@@ -0,0 +1,208 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	sqlitedriver "modernc.org/sqlite"
+)
+
+// backupFilePrefix and backupFileExt name the files written by RotateBackup.
+const (
+	backupFilePrefix = "curly-"
+	backupFileExt    = ".db"
+)
+
+// ErrNotCurlyDatabase indicates a restore source is not a curly database.
+var ErrNotCurlyDatabase = errors.New("not a curly database")
+
+// backuper is implemented by the modernc.org/sqlite driver connection.
+type backuper interface {
+	NewBackup(dstURI string) (*sqlitedriver.Backup, error)
+	NewRestore(srcURI string) (*sqlitedriver.Backup, error)
+}
+
+// Backup writes an online, consistent copy of the database to destPath.
+// It uses the SQLite backup API, so it is safe to run while the database is in use.
+// An existing file at destPath is overwritten.
+func Backup(ctx context.Context, db *sql.DB, destPath string) error {
+	if err := os.MkdirAll(filepath.Dir(destPath), 0750); err != nil {
+		return fmt.Errorf("failed to create backup directory: %w", err)
+	}
+	if err := os.Remove(destPath); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to replace existing backup: %w", err)
+	}
+
+	return withBackuper(ctx, db, func(b backuper) error {
+		backup, err := b.NewBackup(destPath)
+		if err != nil {
+			return fmt.Errorf("failed to start backup: %w", err)
+		}
+		return runBackup(backup)
+	})
+}
+
+// Restore replaces the contents of the database with the backup at srcPath.
+// The source must be a curly database; anything else returns ErrNotCurlyDatabase.
+func Restore(ctx context.Context, db *sql.DB, srcPath string) error {
+	if err := validateBackupFile(ctx, srcPath); err != nil {
+		return err
+	}
+
+	return withBackuper(ctx, db, func(b backuper) error {
+		restore, err := b.NewRestore(srcPath)
+		if err != nil {
+			return fmt.Errorf("failed to start restore: %w", err)
+		}
+		return runBackup(restore)
+	})
+}
+
+// RotateBackup writes a timestamped backup into dir and removes the oldest
+// backups so that at most keep files remain. A keep value of 0 or less keeps all backups.
+// It returns the path of the backup that was written.
+func RotateBackup(ctx context.Context, db *sql.DB, dir string, keep int) (string, error) {
+	name := backupFilePrefix + time.Now().UTC().Format("20060102-150405.000") + backupFileExt
+	path := filepath.Join(dir, name)
+
+	if err := Backup(ctx, db, path); err != nil {
+		return "", err
+	}
+
+	if err := pruneBackups(dir, keep); err != nil {
+		return path, fmt.Errorf("failed to prune old backups: %w", err)
+	}
+
+	return path, nil
+}
+
+// withBackuper runs fn with the driver connection underlying a pooled connection.
+func withBackuper(ctx context.Context, db *sql.DB, fn func(b backuper) error) error {
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	defer func() { _ = conn.Close() }()
+
+	return conn.Raw(func(driverConn any) error {
+		b, ok := driverConn.(backuper)
+		if !ok {
+			return fmt.Errorf("database driver does not support online backup")
+		}
+		return fn(b)
+	})
+}
+
+// runBackup copies all pages and releases the backup handle.
+func runBackup(backup *sqlitedriver.Backup) error {
+	for {
+		more, err := backup.Step(-1)
+		if err != nil {
+			_ = backup.Finish()
+			return fmt.Errorf("backup step failed: %w", err)
+		}
+		if !more {
+			break
+		}
+	}
+
+	if err := backup.Finish(); err != nil {
+		return fmt.Errorf("failed to finish backup: %w", err)
+	}
+	return nil
+}
+
+// validateBackupFile checks that path is a readable SQLite database with curly's schema.
+func validateBackupFile(ctx context.Context, path string) error {
+	if _, err := os.Stat(path); err != nil {
+		return fmt.Errorf("cannot read backup: %w", err)
+	}
+
+	src, err := sql.Open("sqlite", "file:"+path+"?mode=ro")
+	if err != nil {
+		return fmt.Errorf("failed to open backup: %w", err)
+	}
+	defer func() { _ = src.Close() }()
+
+	var count int
+	query := `SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name IN ('schema_migrations', 'requests', 'history')`
+	if err := src.QueryRowContext(ctx, query).Scan(&count); err != nil {
+		return fmt.Errorf("%w: %s", ErrNotCurlyDatabase, err.Error())
+	}
+	if count != 3 {
+		return ErrNotCurlyDatabase
+	}
+
+	return nil
+}
+
+// pruneBackups deletes the oldest backups in dir beyond keep.
+func pruneBackups(dir string, keep int) error {
+	if keep <= 0 {
+		return nil
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+
+	var backups []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, backupFilePrefix) || !strings.HasSuffix(name, backupFileExt) {
+			continue
+		}
+		backups = append(backups, name)
+	}
+
+	// Timestamped names sort chronologically.
+	sort.Strings(backups)
+
+	for len(backups) > keep {
+		if err := os.Remove(filepath.Join(dir, backups[0])); err != nil {
+			return err
+		}
+		backups = backups[1:]
+	}
+
+	return nil
+}
+
+// hasPendingMigrations reports whether an existing database is missing any embedded migration.
+// A fresh database (no schema_migrations table) is not considered pending.
+func hasPendingMigrations(db *sql.DB) (bool, error) {
+	var tableCount int
+	err := db.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'schema_migrations'`).Scan(&tableCount)
+	if err != nil {
+		return false, err
+	}
+	if tableCount == 0 {
+		return false, nil
+	}
+
+	applied, err := getAppliedMigrations(db)
+	if err != nil {
+		return false, err
+	}
+
+	migrations, err := embeddedMigrations()
+	if err != nil {
+		return false, err
+	}
+
+	for _, migration := range migrations {
+		if !applied[migration.Version] {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
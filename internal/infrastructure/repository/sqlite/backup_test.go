@@ -0,0 +1,154 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupFileDB creates a migrated SQLite database backed by a file in a temp directory.
+func setupFileDB(t *testing.T) (*sql.DB, string) {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "curly.db")
+	db, err := Open(&Config{Path: path})
+	require.NoError(t, err)
+	require.NoError(t, MigrateDB(db))
+
+	t.Cleanup(func() { _ = db.Close() })
+	return db, path
+}
+
+func countRequests(t *testing.T, db *sql.DB) int {
+	t.Helper()
+	var count int
+	require.NoError(t, db.QueryRow("SELECT COUNT(*) FROM requests").Scan(&count))
+	return count
+}
+
+func TestBackup_CopiesDatabase(t *testing.T) {
+	db, _ := setupFileDB(t)
+	ctx := context.Background()
+
+	createTestRequest(t, ctx, NewRequestRepository(db), "req-1")
+
+	dest := filepath.Join(t.TempDir(), "nested", "backup.db")
+	require.NoError(t, Backup(ctx, db, dest))
+
+	backupDB, err := sql.Open("sqlite", dest)
+	require.NoError(t, err)
+	defer func() { _ = backupDB.Close() }()
+
+	assert.Equal(t, 1, countRequests(t, backupDB))
+}
+
+func TestRestore_ReplacesContents(t *testing.T) {
+	db, _ := setupFileDB(t)
+	ctx := context.Background()
+	repo := NewRequestRepository(db)
+
+	createTestRequest(t, ctx, repo, "req-1")
+
+	dest := filepath.Join(t.TempDir(), "backup.db")
+	require.NoError(t, Backup(ctx, db, dest))
+
+	createTestRequest(t, ctx, repo, "req-2")
+	require.Equal(t, 2, countRequests(t, db))
+
+	require.NoError(t, Restore(ctx, db, dest))
+	assert.Equal(t, 1, countRequests(t, db))
+}
+
+func TestRestore_RejectsNonCurlyDatabase(t *testing.T) {
+	db, _ := setupFileDB(t)
+	ctx := context.Background()
+
+	otherPath := filepath.Join(t.TempDir(), "other.db")
+	other, err := sql.Open("sqlite", otherPath)
+	require.NoError(t, err)
+	_, err = other.Exec("CREATE TABLE notes (id INTEGER PRIMARY KEY)")
+	require.NoError(t, err)
+	require.NoError(t, other.Close())
+
+	err = Restore(ctx, db, otherPath)
+	assert.True(t, errors.Is(err, ErrNotCurlyDatabase), "got %v", err)
+}
+
+func TestRestore_MissingFile(t *testing.T) {
+	db, _ := setupFileDB(t)
+
+	err := Restore(context.Background(), db, filepath.Join(t.TempDir(), "missing.db"))
+	assert.Error(t, err)
+}
+
+func TestRotateBackup_PrunesOldBackups(t *testing.T) {
+	db, _ := setupFileDB(t)
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	// Unrelated files must be left alone.
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("keep"), 0600))
+
+	var last string
+	for i := 0; i < 4; i++ {
+		path, err := RotateBackup(ctx, db, dir, 2)
+		require.NoError(t, err)
+		last = path
+	}
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+
+	var backups []string
+	for _, entry := range entries {
+		if filepath.Ext(entry.Name()) == backupFileExt {
+			backups = append(backups, entry.Name())
+		}
+	}
+	assert.Len(t, backups, 2)
+	assert.Contains(t, backups, filepath.Base(last))
+	assert.FileExists(t, filepath.Join(dir, "notes.txt"))
+}
+
+func TestHasPendingMigrations(t *testing.T) {
+	fresh, err := sql.Open("sqlite", ":memory:")
+	require.NoError(t, err)
+	defer func() { _ = fresh.Close() }()
+
+	pending, err := hasPendingMigrations(fresh)
+	require.NoError(t, err)
+	assert.False(t, pending, "fresh database should not be pending")
+
+	db, _ := setupFileDB(t)
+	pending, err = hasPendingMigrations(db)
+	require.NoError(t, err)
+	assert.False(t, pending, "fully migrated database should not be pending")
+
+	_, err = db.Exec("DELETE FROM schema_migrations WHERE version = 1")
+	require.NoError(t, err)
+	pending, err = hasPendingMigrations(db)
+	require.NoError(t, err)
+	assert.True(t, pending)
+}
+
+func TestOpen_BacksUpBeforePendingMigrations(t *testing.T) {
+	db, path := setupFileDB(t)
+	_, err := db.Exec("DELETE FROM schema_migrations WHERE version = 1")
+	require.NoError(t, err)
+	require.NoError(t, db.Close())
+
+	backupDir := t.TempDir()
+	reopened, err := Open(&Config{Path: path, BackupDir: backupDir, MaxBackups: 3})
+	require.NoError(t, err)
+	defer func() { _ = reopened.Close() }()
+
+	entries, err := os.ReadDir(backupDir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+}
@@ -2,36 +2,98 @@
 package sqlite
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
+	"net/url"
 	"os"
 	"path/filepath"
+	"strings"
+	"time"
 
 	_ "modernc.org/sqlite" // SQLite driver
 )
 
+// Connection defaults, used when the corresponding Config field is zero.
+const (
+	// DefaultJournalMode lets readers proceed while a write is in progress.
+	DefaultJournalMode = "WAL"
+
+	// DefaultSynchronous is safe with WAL and much faster than FULL.
+	DefaultSynchronous = "NORMAL"
+
+	// DefaultBusyTimeout is how long a connection waits for a lock before
+	// failing with "database is locked".
+	DefaultBusyTimeout = 5 * time.Second
+
+	// DefaultMaxOpenConns allows concurrent readers alongside a single writer.
+	DefaultMaxOpenConns = 4
+)
+
+// Valid values for Config.JournalMode and Config.Synchronous.
+var (
+	journalModes = []string{"DELETE", "TRUNCATE", "PERSIST", "MEMORY", "WAL", "OFF"}
+	syncModes    = []string{"OFF", "NORMAL", "FULL", "EXTRA"}
+)
+
 // Config holds database configuration options.
 type Config struct {
 	// Path is the file path to the SQLite database.
 	// Use ":memory:" for in-memory databases (useful for testing).
 	Path string
 
-	// MigrationsPath is the directory containing migration SQL files.
-	// If empty, migrations are not run automatically.
+	// MigrationsPath overrides the embedded migrations with SQL files from a
+	// directory on disk, which is useful while developing new migrations.
+	// If empty, the migrations embedded in the binary are applied.
 	MigrationsPath string
+
+	// BackupDir is the directory for automatic backups taken before pending
+	// migrations are applied to an existing database. If empty, no backup is taken.
+	BackupDir string
+
+	// MaxBackups is the number of automatic backups to keep in BackupDir.
+	// Older backups are removed. 0 keeps all backups.
+	MaxBackups int
+
+	// JournalMode is the SQLite journal mode (DELETE, TRUNCATE, PERSIST, MEMORY, WAL or OFF).
+	// If empty, DefaultJournalMode is used.
+	JournalMode string
+
+	// Synchronous is the SQLite synchronous level (OFF, NORMAL, FULL or EXTRA).
+	// If empty, DefaultSynchronous is used.
+	Synchronous string
+
+	// BusyTimeout is how long to wait for a locked database before giving up.
+	// If zero, DefaultBusyTimeout is used.
+	BusyTimeout time.Duration
+
+	// MaxOpenConns limits open connections. If zero, DefaultMaxOpenConns is used.
+	// In-memory databases always use a single connection, since every
+	// connection would otherwise see its own empty database.
+	MaxOpenConns int
+
+	// MaxIdleConns limits idle connections kept in the pool.
+	// If zero, it matches the maximum number of open connections.
+	MaxIdleConns int
+
+	// ConnMaxLifetime closes connections after this long (0 = never).
+	ConnMaxLifetime time.Duration
+
+	// ConnMaxIdleTime closes connections idle for this long (0 = never).
+	ConnMaxIdleTime time.Duration
 }
 
 // DefaultConfig returns the default database configuration.
 func DefaultConfig() *Config {
 	homeDir, _ := os.UserHomeDir()
 	return &Config{
-		Path:           filepath.Join(homeDir, ".local", "share", "curly", "curly.db"),
-		MigrationsPath: "migrations",
+		Path: filepath.Join(homeDir, ".local", "share", "curly", "curly.db"),
 	}
 }
 
 // Open opens a connection to the SQLite database and applies performance optimizations.
-// It also runs migrations if MigrationsPath is specified.
+// It also applies any pending migrations, from MigrationsPath if specified
+// or from the embedded migrations otherwise.
 func Open(config *Config) (*sql.DB, error) {
 	if config == nil {
 		config = DefaultConfig()
@@ -45,49 +107,130 @@ func Open(config *Config) (*sql.DB, error) {
 		}
 	}
 
-	// Open the database connection.
-	db, err := sql.Open("sqlite", config.Path)
+	dsn, err := buildDSN(config)
+	if err != nil {
+		return nil, err
+	}
+
+	// Open the database connection. The pragmas in the DSN are applied to
+	// every connection the pool opens, not just the first one.
+	db, err := sql.Open("sqlite", dsn)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
+	configurePool(db, config)
 
-	// Apply performance pragmas.
-	if err := applyPragmas(db); err != nil {
+	// Open a connection now so invalid settings fail here rather than on first use.
+	if err := db.Ping(); err != nil {
 		_ = db.Close()
 		return nil, fmt.Errorf("failed to apply pragmas: %w", err)
 	}
 
-	// Run migrations if path is specified.
-	if config.MigrationsPath != "" {
-		if err := runMigrations(db, config.MigrationsPath); err != nil {
+	// Back up an existing database before its schema is changed.
+	if config.BackupDir != "" && config.Path != ":memory:" {
+		if err := backupBeforeMigrations(db, config.BackupDir, config.MaxBackups); err != nil {
 			_ = db.Close()
-			return nil, fmt.Errorf("failed to run migrations: %w", err)
+			return nil, fmt.Errorf("failed to back up database before migrations: %w", err)
 		}
 	}
 
+	// Run migrations, preferring the override path when specified.
+	migrate := MigrateDB
+	if config.MigrationsPath != "" {
+		migrate = func(db *sql.DB) error { return runMigrations(db, config.MigrationsPath) }
+	}
+	if err := migrate(db); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("failed to run migrations: %w", err)
+	}
+
 	return db, nil
 }
 
-// applyPragmas configures SQLite for optimal performance.
-func applyPragmas(db *sql.DB) error {
-	pragmas := []string{
-		// Enable Write-Ahead Logging for better concurrency.
-		"PRAGMA journal_mode = WAL",
-		// Normal synchronous mode is safe with WAL and much faster.
-		"PRAGMA synchronous = NORMAL",
-		// 64MB cache size for better performance.
-		"PRAGMA cache_size = -64000",
-		// Enable foreign key constraints.
-		"PRAGMA foreign_keys = ON",
-		// Reduce memory usage for temp tables.
-		"PRAGMA temp_store = MEMORY",
-	}
-
-	for _, pragma := range pragmas {
-		if _, err := db.Exec(pragma); err != nil {
-			return fmt.Errorf("failed to execute pragma '%s': %w", pragma, err)
+// buildDSN returns the driver connection string for config, carrying the
+// connection pragmas and transaction locking mode as query parameters.
+func buildDSN(config *Config) (string, error) {
+	journalMode, err := pragmaValue("journal mode", config.JournalMode, DefaultJournalMode, journalModes)
+	if err != nil {
+		return "", err
+	}
+	synchronous, err := pragmaValue("synchronous level", config.Synchronous, DefaultSynchronous, syncModes)
+	if err != nil {
+		return "", err
+	}
+
+	busyTimeout := config.BusyTimeout
+	if busyTimeout == 0 {
+		busyTimeout = DefaultBusyTimeout
+	}
+	if busyTimeout < 0 {
+		return "", fmt.Errorf("busy timeout must be non-negative, got: %s", busyTimeout)
+	}
+
+	params := url.Values{}
+	params.Add("_pragma", fmt.Sprintf("busy_timeout(%d)", busyTimeout.Milliseconds()))
+	params.Add("_pragma", "journal_mode("+journalMode+")")
+	params.Add("_pragma", "synchronous("+synchronous+")")
+	// 64MB cache size for better performance.
+	params.Add("_pragma", "cache_size(-64000)")
+	// Enable foreign key constraints.
+	params.Add("_pragma", "foreign_keys(1)")
+	// Reduce memory usage for temp tables.
+	params.Add("_pragma", "temp_store(MEMORY)")
+	// Take the write lock when a transaction begins so concurrent writers wait
+	// on busy_timeout instead of failing when a read lock cannot be upgraded.
+	params.Set("_txlock", "immediate")
+
+	return config.Path + "?" + params.Encode(), nil
+}
+
+// pragmaValue normalizes a pragma setting, falling back to def when empty.
+func pragmaValue(name, value, def string, valid []string) (string, error) {
+	if value == "" {
+		return def, nil
+	}
+
+	value = strings.ToUpper(strings.TrimSpace(value))
+	for _, v := range valid {
+		if v == value {
+			return value, nil
 		}
 	}
 
-	return nil
+	return "", fmt.Errorf("invalid %s %q (expected one of %s)", name, value, strings.Join(valid, ", "))
+}
+
+// configurePool applies the connection pool limits from config.
+func configurePool(db *sql.DB, config *Config) {
+	maxOpen := config.MaxOpenConns
+	if maxOpen <= 0 {
+		maxOpen = DefaultMaxOpenConns
+	}
+	if config.Path == ":memory:" {
+		maxOpen = 1
+	}
+
+	maxIdle := config.MaxIdleConns
+	if maxIdle <= 0 || maxIdle > maxOpen {
+		maxIdle = maxOpen
+	}
+
+	db.SetMaxOpenConns(maxOpen)
+	db.SetMaxIdleConns(maxIdle)
+	db.SetConnMaxLifetime(config.ConnMaxLifetime)
+	db.SetConnMaxIdleTime(config.ConnMaxIdleTime)
+}
+
+// backupBeforeMigrations takes a rotating backup if the database has pending migrations.
+func backupBeforeMigrations(db *sql.DB, dir string, keep int) error {
+	pending, err := hasPendingMigrations(db)
+	if err != nil {
+		return fmt.Errorf("failed to check pending migrations: %w", err)
+	}
+	if !pending {
+		return nil
+	}
+
+	_, err = RotateBackup(context.Background(), db, dir, keep)
+	return err
 }
@@ -0,0 +1,143 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/williajm/curly/internal/infrastructure/repository"
+)
+
+func TestOpen_PragmasApplyToEveryConnection(t *testing.T) {
+	db, err := Open(&Config{Path: filepath.Join(t.TempDir(), "test.db"), BusyTimeout: 2 * time.Second})
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	ctx := context.Background()
+
+	// Hold two connections at once so the pool must open a second one.
+	conn1, err := db.Conn(ctx)
+	require.NoError(t, err)
+	defer func() { _ = conn1.Close() }()
+	conn2, err := db.Conn(ctx)
+	require.NoError(t, err)
+	defer func() { _ = conn2.Close() }()
+
+	tests := []struct {
+		pragma string
+		want   string
+	}{
+		{"journal_mode", "wal"},
+		{"synchronous", "1"},
+		{"busy_timeout", "2000"},
+		{"foreign_keys", "1"},
+	}
+
+	for _, tt := range tests {
+		for i, conn := range []*sql.Conn{conn1, conn2} {
+			var got string
+			require.NoError(t, conn.QueryRowContext(ctx, "PRAGMA "+tt.pragma).Scan(&got))
+			assert.Equal(t, tt.want, strings.ToLower(got), "connection %d: PRAGMA %s", i+1, tt.pragma)
+		}
+	}
+}
+
+func TestOpen_CustomSettings(t *testing.T) {
+	db, err := Open(&Config{
+		Path:         filepath.Join(t.TempDir(), "test.db"),
+		JournalMode:  "delete",
+		Synchronous:  "full",
+		MaxOpenConns: 2,
+	})
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	var journalMode string
+	require.NoError(t, db.QueryRow("PRAGMA journal_mode").Scan(&journalMode))
+	assert.Equal(t, "delete", journalMode)
+
+	var synchronous int
+	require.NoError(t, db.QueryRow("PRAGMA synchronous").Scan(&synchronous))
+	assert.Equal(t, 2, synchronous)
+
+	assert.Equal(t, 2, db.Stats().MaxOpenConnections)
+}
+
+func TestOpen_InMemoryUsesSingleConnection(t *testing.T) {
+	db, err := Open(&Config{Path: ":memory:", MaxOpenConns: 8})
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	assert.Equal(t, 1, db.Stats().MaxOpenConnections)
+}
+
+func TestOpen_InvalidSettings(t *testing.T) {
+	tests := []struct {
+		name   string
+		config Config
+	}{
+		{"journal mode", Config{JournalMode: "fast"}},
+		{"synchronous", Config{Synchronous: "sometimes"}},
+		{"busy timeout", Config{BusyTimeout: -time.Second}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.config.Path = filepath.Join(t.TempDir(), "test.db")
+			_, err := Open(&tt.config)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestOpen_ConcurrentReadsAndWrites(t *testing.T) {
+	db, err := Open(&Config{Path: filepath.Join(t.TempDir(), "test.db")})
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	repo := NewHistoryRepository(db)
+	ctx := context.Background()
+
+	const writers, perWriter = 4, 25
+	var wg sync.WaitGroup
+	errs := make(chan error, writers*perWriter*2)
+
+	for w := 0; w < writers; w++ {
+		wg.Add(2)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < perWriter; i++ {
+				entry := &repository.HistoryEntry{
+					ID:         fmt.Sprintf("hist-%d-%d", w, i),
+					ExecutedAt: time.Now().UTC().Format(time.RFC3339),
+					StatusCode: 200,
+				}
+				errs <- repo.SaveBatch(ctx, []*repository.HistoryEntry{entry})
+			}
+		}(w)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < perWriter; i++ {
+				_, err := repo.FindAll(ctx, 10)
+				errs <- err
+			}
+		}()
+	}
+
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		require.NoError(t, err)
+	}
+
+	all, err := repo.FindAll(ctx, 0)
+	require.NoError(t, err)
+	assert.Len(t, all, writers*perWriter)
+}
@@ -0,0 +1,103 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/williajm/curly/internal/domain"
+	"github.com/williajm/curly/internal/infrastructure/repository"
+)
+
+// DraftRepository implements repository.DraftRepository using SQLite.
+type DraftRepository struct {
+	db *sql.DB
+}
+
+// NewDraftRepository creates a new SQLite-backed draft repository.
+func NewDraftRepository(db *sql.DB) *DraftRepository {
+	return &DraftRepository{db: db}
+}
+
+// Save creates the draft, or replaces the one with the same ID.
+func (r *DraftRepository) Save(ctx context.Context, draft *domain.Draft) error {
+	if draft == nil || draft.Request == nil {
+		return fmt.Errorf("draft cannot be nil")
+	}
+	if draft.ID == "" {
+		return fmt.Errorf("draft ID cannot be empty")
+	}
+
+	request, err := repository.MarshalRequestSnapshot(draft.Request)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO drafts (id, request, updated_at)
+		VALUES (?, ?, ?)
+		ON CONFLICT (id) DO UPDATE SET request = excluded.request, updated_at = excluded.updated_at
+	`
+	_, err = r.db.ExecContext(ctx, query, draft.ID, request, draft.UpdatedAt.UTC().Format(time.RFC3339))
+	if err != nil {
+		return fmt.Errorf("failed to save draft: %w", err)
+	}
+
+	return nil
+}
+
+// FindAll retrieves all drafts, the most recently updated first.
+func (r *DraftRepository) FindAll(ctx context.Context) ([]*domain.Draft, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT id, request, updated_at FROM drafts ORDER BY updated_at DESC, id`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query drafts: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var drafts []*domain.Draft
+	for rows.Next() {
+		draft, err := scanDraft(rows)
+		if err != nil {
+			return nil, err
+		}
+		drafts = append(drafts, draft)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+
+	return drafts, nil
+}
+
+// Delete removes a draft from the database.
+func (r *DraftRepository) Delete(ctx context.Context, id string) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM drafts WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete draft: %w", err)
+	}
+
+	return requireRowsAffected(result)
+}
+
+// scanDraft reads a draft selected as id, request, updated_at.
+func scanDraft(row rowScanner) (*domain.Draft, error) {
+	var (
+		draft              domain.Draft
+		request, updatedAt string
+	)
+
+	if err := row.Scan(&draft.ID, &request, &updatedAt); err != nil {
+		return nil, fmt.Errorf("failed to scan draft: %w", err)
+	}
+
+	var err error
+	if draft.Request, err = repository.UnmarshalRequestSnapshot(request); err != nil {
+		return nil, err
+	}
+	if draft.UpdatedAt, err = time.Parse(time.RFC3339, updatedAt); err != nil {
+		return nil, fmt.Errorf("failed to parse updated_at: %w", err)
+	}
+
+	return &draft, nil
+}
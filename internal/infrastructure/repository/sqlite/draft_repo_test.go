@@ -0,0 +1,112 @@
+package sqlite
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/williajm/curly/internal/domain"
+)
+
+func newTestDraft(id, url string, updatedAt time.Time) *domain.Draft {
+	req := domain.NewRequestWithMethodAndURL("POST", url)
+	req.Headers["Content-Type"] = "application/json"
+	req.QueryParams["dry_run"] = "true"
+	req.Body = `{"name":"ada"}`
+	req.AuthConfig = domain.NewBearerAuth("t0k3n")
+	return &domain.Draft{ID: id, Request: req, UpdatedAt: updatedAt}
+}
+
+func TestDraftRepository_SaveAndFindAll(t *testing.T) {
+	db := setupTestDB(t)
+	defer func() { _ = db.Close() }()
+
+	repo := NewDraftRepository(db)
+	ctx := context.Background()
+
+	now := time.Now().Truncate(time.Second)
+	older := newTestDraft("session-1", "https://api.example.com/users", now.Add(-time.Hour))
+	draft := newTestDraft("session-2", "https://api.example.com/orders", now)
+	for _, d := range []*domain.Draft{older, draft} {
+		if err := repo.Save(ctx, d); err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+	}
+
+	// Saving again replaces the draft.
+	draft.Request.URL = "https://api.example.com/accounts"
+	if err := repo.Save(ctx, draft); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	all, err := repo.FindAll(ctx)
+	if err != nil {
+		t.Fatalf("FindAll() error = %v", err)
+	}
+	if len(all) != 2 {
+		t.Fatalf("FindAll() returned %d drafts, want 2", len(all))
+	}
+	if all[0].ID != "session-2" || all[1].ID != "session-1" {
+		t.Errorf("FindAll() order = %s, %s, want the most recent first", all[0].ID, all[1].ID)
+	}
+	found := all[0]
+	if !found.UpdatedAt.Equal(now) {
+		t.Errorf("UpdatedAt = %v, want %v", found.UpdatedAt, now)
+	}
+	if found.Request.ID != draft.Request.ID {
+		t.Errorf("Request.ID = %q, want %q", found.Request.ID, draft.Request.ID)
+	}
+	if found.Request.URL != "https://api.example.com/accounts" {
+		t.Errorf("Request.URL = %q, want the replaced URL", found.Request.URL)
+	}
+	if !reflect.DeepEqual(found.Request.Headers, draft.Request.Headers) {
+		t.Errorf("Request.Headers = %v, want %v", found.Request.Headers, draft.Request.Headers)
+	}
+	if found.Request.Body != draft.Request.Body {
+		t.Errorf("Request.Body = %q, want %q", found.Request.Body, draft.Request.Body)
+	}
+	if !reflect.DeepEqual(found.Request.AuthConfig, draft.Request.AuthConfig) {
+		t.Errorf("Request.AuthConfig = %v, want %v", found.Request.AuthConfig, draft.Request.AuthConfig)
+	}
+}
+
+func TestDraftRepository_Delete(t *testing.T) {
+	db := setupTestDB(t)
+	defer func() { _ = db.Close() }()
+
+	repo := NewDraftRepository(db)
+	ctx := context.Background()
+
+	if err := repo.Save(ctx, newTestDraft("session-1", "https://api.example.com/users", time.Now())); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if err := repo.Delete(ctx, "session-1"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if err := repo.Delete(ctx, "session-1"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Delete() of a deleted draft error = %v, want ErrNotFound", err)
+	}
+
+	all, err := repo.FindAll(ctx)
+	if err != nil {
+		t.Fatalf("FindAll() error = %v", err)
+	}
+	if len(all) != 0 {
+		t.Errorf("FindAll() returned %d drafts, want 0", len(all))
+	}
+}
+
+func TestDraftRepository_SaveInvalid(t *testing.T) {
+	db := setupTestDB(t)
+	defer func() { _ = db.Close() }()
+
+	repo := NewDraftRepository(db)
+	if err := repo.Save(context.Background(), nil); err == nil {
+		t.Error("Save(nil) error = nil, want an error")
+	}
+	if err := repo.Save(context.Background(), &domain.Draft{Request: domain.NewRequest()}); err == nil {
+		t.Error("Save() without an ID error = nil, want an error")
+	}
+}
@@ -0,0 +1,162 @@
+package sqlite
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/williajm/curly/internal/domain"
+)
+
+func newTestEnvironment(name string) *domain.Environment {
+	env := domain.NewEnvironment(name)
+	env.Set("baseUrl", "https://"+name+".example.com", false)
+	env.Set("token", "s3cret", true)
+	return env
+}
+
+func TestEnvironmentRepository_CreateAndFind(t *testing.T) {
+	db := setupTestDB(t)
+	defer func() { _ = db.Close() }()
+
+	repo := NewEnvironmentRepository(db)
+	ctx := context.Background()
+
+	env := newTestEnvironment("staging")
+	if err := repo.Create(ctx, env); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	found, err := repo.FindByName(ctx, "staging")
+	if err != nil {
+		t.Fatalf("FindByName() error = %v", err)
+	}
+	if found.ID != env.ID {
+		t.Errorf("ID = %q, want %q", found.ID, env.ID)
+	}
+	if !reflect.DeepEqual(found.Variables, env.Variables) {
+		t.Errorf("Variables = %v, want %v", found.Variables, env.Variables)
+	}
+	if !reflect.DeepEqual(found.Secrets, []string{"token"}) {
+		t.Errorf("Secrets = %v, want [token]", found.Secrets)
+	}
+
+	if _, err := repo.FindByName(ctx, "missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("FindByName(missing) error = %v, want ErrNotFound", err)
+	}
+
+	// Names are unique.
+	if err := repo.Create(ctx, newTestEnvironment("staging")); err == nil {
+		t.Error("expected an error creating a second environment with the same name")
+	}
+}
+
+func TestEnvironmentRepository_FindAll(t *testing.T) {
+	db := setupTestDB(t)
+	defer func() { _ = db.Close() }()
+
+	repo := NewEnvironmentRepository(db)
+	ctx := context.Background()
+
+	for _, name := range []string{"staging", "dev", "prod"} {
+		if err := repo.Create(ctx, newTestEnvironment(name)); err != nil {
+			t.Fatalf("Create(%s) error = %v", name, err)
+		}
+	}
+
+	envs, err := repo.FindAll(ctx)
+	if err != nil {
+		t.Fatalf("FindAll() error = %v", err)
+	}
+	var names []string
+	for _, env := range envs {
+		names = append(names, env.Name)
+	}
+	if !reflect.DeepEqual(names, []string{"dev", "prod", "staging"}) {
+		t.Errorf("names = %v, want sorted by name", names)
+	}
+}
+
+func TestEnvironmentRepository_UpdateAndDelete(t *testing.T) {
+	db := setupTestDB(t)
+	defer func() { _ = db.Close() }()
+
+	repo := NewEnvironmentRepository(db)
+	ctx := context.Background()
+
+	env := newTestEnvironment("dev")
+	if err := repo.Create(ctx, env); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	env.Name = "local"
+	env.Unset("token")
+	if err := repo.Update(ctx, env); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	found, err := repo.FindByName(ctx, "local")
+	if err != nil {
+		t.Fatalf("FindByName() error = %v", err)
+	}
+	if _, ok := found.Variables["token"]; ok || found.Secrets != nil {
+		t.Errorf("expected token to be removed, got %v %v", found.Variables, found.Secrets)
+	}
+
+	if err := repo.Delete(ctx, env.ID); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if err := repo.Delete(ctx, env.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Delete() twice error = %v, want ErrNotFound", err)
+	}
+	if err := repo.Update(ctx, env); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Update() after delete error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestEnvironmentRepository_SetActive(t *testing.T) {
+	db := setupTestDB(t)
+	defer func() { _ = db.Close() }()
+
+	repo := NewEnvironmentRepository(db)
+	ctx := context.Background()
+
+	dev, prod := newTestEnvironment("dev"), newTestEnvironment("prod")
+	for _, env := range []*domain.Environment{dev, prod} {
+		if err := repo.Create(ctx, env); err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+	}
+
+	if _, err := repo.FindActive(ctx); !errors.Is(err, ErrNotFound) {
+		t.Errorf("FindActive() error = %v, want ErrNotFound", err)
+	}
+
+	for _, env := range []*domain.Environment{dev, prod} {
+		if err := repo.SetActive(ctx, env.ID); err != nil {
+			t.Fatalf("SetActive() error = %v", err)
+		}
+		active, err := repo.FindActive(ctx)
+		if err != nil {
+			t.Fatalf("FindActive() error = %v", err)
+		}
+		if active.ID != env.ID {
+			t.Errorf("active = %q, want %q", active.Name, env.Name)
+		}
+	}
+
+	// An unknown ID changes nothing.
+	if err := repo.SetActive(ctx, "missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("SetActive(missing) error = %v, want ErrNotFound", err)
+	}
+	if active, err := repo.FindActive(ctx); err != nil || active.ID != prod.ID {
+		t.Errorf("expected prod to stay active, got %v, %v", active, err)
+	}
+
+	if err := repo.SetActive(ctx, ""); err != nil {
+		t.Fatalf("SetActive(\"\") error = %v", err)
+	}
+	if _, err := repo.FindActive(ctx); !errors.Is(err, ErrNotFound) {
+		t.Errorf("FindActive() after clearing error = %v, want ErrNotFound", err)
+	}
+}
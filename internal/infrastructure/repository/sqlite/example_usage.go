@@ -11,13 +11,14 @@ Example 1: Opening a database with migrations
 	)
 
 	// Use default config (database at ~/.local/share/curly/curly.db).
+	// The embedded migrations are applied automatically.
 	db, err := sqlite.Open(sqlite.DefaultConfig())
 	if err != nil {
 		log.Fatalf("failed to open database: %v", err)
 	}
 	defer db.Close()
 
-	// Or use custom config.
+	// Or use custom config, loading migrations from disk while developing new ones.
 	config := &sqlite.Config{
 		Path:           "/custom/path/to/database.db",
 		MigrationsPath: "migrations",
@@ -256,8 +257,7 @@ Example 5: Using in-memory database for testing
 	func TestMyFeature(t *testing.T) {
 		// Use in-memory database for testing.
 		config := &sqlite.Config{
-			Path:           ":memory:",
-			MigrationsPath: "../../../../migrations",
+			Path: ":memory:",
 		}
 
 		db, err := sqlite.Open(config)
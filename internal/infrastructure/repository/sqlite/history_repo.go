@@ -5,10 +5,20 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/williajm/curly/internal/infrastructure/repository"
 )
 
+// historyColumns lists the columns selected for a history entry, in scan order.
+const historyColumns = `id, request_id, executed_at, status_code, status, response_time_ms, response_headers, response_body, response_body_ref, error, run_id, request_snapshot, replay_of, assertion_failures`
+
+// insertHistoryQuery inserts a history entry with the arguments from historyArgs.
+const insertHistoryQuery = `
+	INSERT INTO history (id, request_id, executed_at, status_code, status, response_time_ms, response_headers, response_body, response_body_ref, error, run_id, request_snapshot, replay_of, assertion_failures)
+	VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+`
+
 // HistoryRepository implements repository.HistoryRepository using SQLite.
 type HistoryRepository struct {
 	db *sql.DB
@@ -25,25 +35,56 @@ func (r *HistoryRepository) Save(ctx context.Context, entry *repository.HistoryE
 		return fmt.Errorf("history entry cannot be nil")
 	}
 
-	query := `
-		INSERT INTO history (id, request_id, executed_at, status_code, status, response_time_ms, response_headers, response_body, error)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
-	`
+	_, err := r.db.ExecContext(ctx, insertHistoryQuery, historyArgs(entry)...)
+	if err != nil {
+		return fmt.Errorf("failed to save history entry: %w", err)
+	}
 
-	_, err := r.db.ExecContext(ctx, query,
-		entry.ID,
-		nullString(entry.RequestID),
-		entry.ExecutedAt,
-		entry.StatusCode,
-		entry.Status,
-		entry.ResponseTimeMs,
-		entry.ResponseHeaders,
-		entry.ResponseBody,
-		nullString(entry.Error),
-	)
+	return nil
+}
 
+// SaveBatch persists entries and updates their requests' usage in one transaction.
+func (r *HistoryRepository) SaveBatch(ctx context.Context, entries []*repository.HistoryEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
-		return fmt.Errorf("failed to save history entry: %w", err)
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	insert, err := tx.PrepareContext(ctx, insertHistoryQuery)
+	if err != nil {
+		return fmt.Errorf("failed to prepare history insert: %w", err)
+	}
+	defer func() { _ = insert.Close() }()
+
+	for _, entry := range entries {
+		if entry == nil {
+			return fmt.Errorf("history entry cannot be nil")
+		}
+		if _, err := insert.ExecContext(ctx, historyArgs(entry)...); err != nil {
+			return fmt.Errorf("failed to save history entry %s: %w", entry.ID, err)
+		}
+	}
+
+	// Requests that are not saved (ad-hoc executions) simply match no rows.
+	usageQuery := `
+		UPDATE requests
+		SET execution_count = execution_count + ?,
+			last_executed_at = MAX(COALESCE(last_executed_at, ''), ?)
+		WHERE id = ?
+	`
+	for _, u := range repository.SummarizeUsage(entries) {
+		if _, err := tx.ExecContext(ctx, usageQuery, u.Count, u.LastExecutedAt, u.RequestID); err != nil {
+			return fmt.Errorf("failed to update request usage: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit history batch: %w", err)
 	}
 
 	return nil
@@ -52,28 +93,12 @@ func (r *HistoryRepository) Save(ctx context.Context, entry *repository.HistoryE
 // FindByID retrieves a history entry by its ID.
 func (r *HistoryRepository) FindByID(ctx context.Context, id string) (*repository.HistoryEntry, error) {
 	query := `
-		SELECT id, request_id, executed_at, status_code, status, response_time_ms, response_headers, response_body, error
+		SELECT ` + historyColumns + `
 		FROM history
 		WHERE id = ?
 	`
 
-	row := r.db.QueryRowContext(ctx, query, id)
-
-	entry := &repository.HistoryEntry{}
-	var requestID, errorMsg sql.NullString
-
-	err := row.Scan(
-		&entry.ID,
-		&requestID,
-		&entry.ExecutedAt,
-		&entry.StatusCode,
-		&entry.Status,
-		&entry.ResponseTimeMs,
-		&entry.ResponseHeaders,
-		&entry.ResponseBody,
-		&errorMsg,
-	)
-
+	entry, err := scanHistoryEntry(r.db.QueryRowContext(ctx, query, id))
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, ErrNotFound
@@ -81,16 +106,13 @@ func (r *HistoryRepository) FindByID(ctx context.Context, id string) (*repositor
 		return nil, fmt.Errorf("failed to scan history entry: %w", err)
 	}
 
-	entry.RequestID = requestID.String
-	entry.Error = errorMsg.String
-
 	return entry, nil
 }
 
 // FindAll retrieves all history entries ordered by executed_at descending.
 func (r *HistoryRepository) FindAll(ctx context.Context, limit int) ([]*repository.HistoryEntry, error) {
 	query := `
-		SELECT id, request_id, executed_at, status_code, status, response_time_ms, response_headers, response_body, error
+		SELECT ` + historyColumns + `
 		FROM history
 		ORDER BY executed_at DESC
 	`
@@ -112,7 +134,7 @@ func (r *HistoryRepository) FindAll(ctx context.Context, limit int) ([]*reposito
 // FindByRequestID retrieves all history entries for a specific request.
 func (r *HistoryRepository) FindByRequestID(ctx context.Context, requestID string, limit int) ([]*repository.HistoryEntry, error) {
 	query := `
-		SELECT id, request_id, executed_at, status_code, status, response_time_ms, response_headers, response_body, error
+		SELECT ` + historyColumns + `
 		FROM history
 		WHERE request_id = ?
 		ORDER BY executed_at DESC
@@ -132,6 +154,24 @@ func (r *HistoryRepository) FindByRequestID(ctx context.Context, requestID strin
 	return scanHistoryEntries(rows)
 }
 
+// FindByRunID retrieves the history entries recorded by a collection run, in execution order.
+func (r *HistoryRepository) FindByRunID(ctx context.Context, runID string) ([]*repository.HistoryEntry, error) {
+	query := `
+		SELECT ` + historyColumns + `
+		FROM history
+		WHERE run_id = ?
+		ORDER BY executed_at ASC, rowid ASC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, runID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query history by run ID: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	return scanHistoryEntries(rows)
+}
+
 // Delete removes a history entry from the database.
 func (r *HistoryRepository) Delete(ctx context.Context, id string) error {
 	query := `DELETE FROM history WHERE id = ?`
@@ -153,6 +193,24 @@ func (r *HistoryRepository) Delete(ctx context.Context, id string) error {
 	return nil
 }
 
+// FindOlderThan retrieves all history entries older than the specified timestamp, oldest first.
+func (r *HistoryRepository) FindOlderThan(ctx context.Context, timestamp string) ([]*repository.HistoryEntry, error) {
+	query := `
+		SELECT ` + historyColumns + `
+		FROM history
+		WHERE executed_at < ?
+		ORDER BY executed_at ASC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, timestamp)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query old history entries: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	return scanHistoryEntries(rows)
+}
+
 // DeleteOlderThan removes all history entries older than the specified timestamp.
 func (r *HistoryRepository) DeleteOlderThan(ctx context.Context, timestamp string) (int64, error) {
 	query := `DELETE FROM history WHERE executed_at < ?`
@@ -170,33 +228,210 @@ func (r *HistoryRepository) DeleteOlderThan(ctx context.Context, timestamp strin
 	return rowsAffected, nil
 }
 
+// DeleteExceptNewest removes all but the keep most recently executed entries.
+func (r *HistoryRepository) DeleteExceptNewest(ctx context.Context, keep int) (int64, error) {
+	if keep <= 0 {
+		return 0, fmt.Errorf("keep must be positive, got: %d", keep)
+	}
+
+	query := `
+		DELETE FROM history
+		WHERE id NOT IN (
+			SELECT id FROM history ORDER BY executed_at DESC, id DESC LIMIT ?
+		)
+	`
+
+	result, err := r.db.ExecContext(ctx, query, keep)
+	if err != nil {
+		return 0, fmt.Errorf("failed to trim history entries: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return rowsAffected, nil
+}
+
+// FindMatching retrieves the history entries filter matches, newest first.
+func (r *HistoryRepository) FindMatching(ctx context.Context, filter repository.HistoryFilter, limit int) ([]*repository.HistoryEntry, error) {
+	where, args := historyFilterWhere(filter)
+	query := `
+		SELECT ` + historyColumns + `
+		FROM history
+		WHERE ` + where + `
+		ORDER BY executed_at DESC
+	`
+
+	// Add LIMIT clause if specified.
+	if limit > 0 {
+		query += fmt.Sprintf(" LIMIT %d", limit)
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query matching history: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	return scanHistoryEntries(rows)
+}
+
+// FindPage retrieves limit of the history entries filter matches, newest
+// first, after skipping the offset newest.
+func (r *HistoryRepository) FindPage(ctx context.Context, filter repository.HistoryFilter, offset, limit int) ([]*repository.HistoryEntry, error) {
+	where, args := historyFilterWhere(filter)
+	query := `
+		SELECT ` + historyColumns + `
+		FROM history
+		WHERE ` + where + `
+		ORDER BY executed_at DESC, id DESC
+		LIMIT ? OFFSET ?
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, append(args, limit, offset)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query history page: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	return scanHistoryEntries(rows)
+}
+
+// CountMatching returns how many history entries filter matches.
+func (r *HistoryRepository) CountMatching(ctx context.Context, filter repository.HistoryFilter) (int, error) {
+	where, args := historyFilterWhere(filter)
+
+	var count int
+	err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM history WHERE `+where, args...).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count history entries: %w", err)
+	}
+
+	return count, nil
+}
+
+// DeleteMatching removes the history entries filter matches.
+func (r *HistoryRepository) DeleteMatching(ctx context.Context, filter repository.HistoryFilter) (int64, error) {
+	where, args := historyFilterWhere(filter)
+
+	result, err := r.db.ExecContext(ctx, `DELETE FROM history WHERE `+where, args...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete history entries: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return rowsAffected, nil
+}
+
+// historyFilterWhere builds the WHERE condition and arguments for filter.
+func historyFilterWhere(filter repository.HistoryFilter) (string, []any) {
+	conditions := []string{"TRUE"}
+	var args []any
+
+	if len(filter.IDs) > 0 {
+		placeholders := strings.Repeat(", ?", len(filter.IDs))[2:]
+		conditions = append(conditions, "id IN ("+placeholders+")")
+		for _, id := range filter.IDs {
+			args = append(args, id)
+		}
+	}
+	if len(filter.RequestIDs) > 0 {
+		placeholders := strings.Repeat(", ?", len(filter.RequestIDs))[2:]
+		conditions = append(conditions, "request_id IN ("+placeholders+")")
+		for _, id := range filter.RequestIDs {
+			args = append(args, id)
+		}
+	}
+	if filter.Before != "" {
+		conditions = append(conditions, "executed_at < ?")
+		args = append(args, filter.Before)
+	}
+	if filter.After != "" {
+		conditions = append(conditions, "executed_at >= ?")
+		args = append(args, filter.After)
+	}
+	if filter.FailedOnly {
+		conditions = append(conditions, failureCondition)
+	}
+	if filter.StatusClass != 0 {
+		conditions = append(conditions, "status_code >= ? AND status_code < ?")
+		args = append(args, filter.StatusClass*100, (filter.StatusClass+1)*100)
+	}
+	if filter.Method != "" {
+		conditions = append(conditions, "upper(json_extract(request_snapshot, '$.method')) = upper(?)")
+		args = append(args, filter.Method)
+	}
+	if filter.URLContains != "" {
+		conditions = append(conditions, "instr(lower(json_extract(request_snapshot, '$.url')), lower(?)) > 0")
+		args = append(args, filter.URLContains)
+	}
+	if len(filter.BodyRefs) > 0 {
+		placeholders := strings.Repeat(", ?", len(filter.BodyRefs))[2:]
+		conditions = append(conditions, "response_body_ref IN ("+placeholders+")")
+		for _, ref := range filter.BodyRefs {
+			args = append(args, ref)
+		}
+	}
+
+	return strings.Join(conditions, " AND "), args
+}
+
+// rowScanner is satisfied by *sql.Row and *sql.Rows.
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// scanHistoryEntry reads a history entry selected with historyColumns.
+func scanHistoryEntry(row rowScanner) (*repository.HistoryEntry, error) {
+	entry := &repository.HistoryEntry{}
+	var requestID, bodyRef, errorMsg, runID, snapshot, replayOf, failures sql.NullString
+
+	err := row.Scan(
+		&entry.ID,
+		&requestID,
+		&entry.ExecutedAt,
+		&entry.StatusCode,
+		&entry.Status,
+		&entry.ResponseTimeMs,
+		&entry.ResponseHeaders,
+		&entry.ResponseBody,
+		&bodyRef,
+		&errorMsg,
+		&runID,
+		&snapshot,
+		&replayOf,
+		&failures,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	entry.RequestID = requestID.String
+	entry.ResponseBodyRef = bodyRef.String
+	entry.Error = errorMsg.String
+	entry.RunID = runID.String
+	entry.RequestSnapshot = snapshot.String
+	entry.ReplayOf = replayOf.String
+	entry.AssertionFailures = failures.String
+
+	return entry, nil
+}
+
 // scanHistoryEntries is a helper function to scan multiple rows into HistoryEntry structs.
 func scanHistoryEntries(rows *sql.Rows) ([]*repository.HistoryEntry, error) {
 	var entries []*repository.HistoryEntry
 
 	for rows.Next() {
-		entry := &repository.HistoryEntry{}
-		var requestID, errorMsg sql.NullString
-
-		err := rows.Scan(
-			&entry.ID,
-			&requestID,
-			&entry.ExecutedAt,
-			&entry.StatusCode,
-			&entry.Status,
-			&entry.ResponseTimeMs,
-			&entry.ResponseHeaders,
-			&entry.ResponseBody,
-			&errorMsg,
-		)
-
+		entry, err := scanHistoryEntry(rows)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan history entry: %w", err)
 		}
-
-		entry.RequestID = requestID.String
-		entry.Error = errorMsg.String
-
 		entries = append(entries, entry)
 	}
 
@@ -207,6 +442,26 @@ func scanHistoryEntries(rows *sql.Rows) ([]*repository.HistoryEntry, error) {
 	return entries, nil
 }
 
+// historyArgs returns the insertHistoryQuery arguments for entry.
+func historyArgs(entry *repository.HistoryEntry) []any {
+	return []any{
+		entry.ID,
+		nullString(entry.RequestID),
+		entry.ExecutedAt,
+		entry.StatusCode,
+		entry.Status,
+		entry.ResponseTimeMs,
+		entry.ResponseHeaders,
+		entry.ResponseBody,
+		nullString(entry.ResponseBodyRef),
+		nullString(entry.Error),
+		nullString(entry.RunID),
+		nullString(entry.RequestSnapshot),
+		nullString(entry.ReplayOf),
+		nullString(entry.AssertionFailures),
+	}
+}
+
 // nullString converts a string to sql.NullString, setting Valid to false if the string is empty.
 func nullString(s string) sql.NullString {
 	return sql.NullString{
@@ -3,6 +3,7 @@ package sqlite
 import (
 	"context"
 	"errors"
+	"fmt"
 	"testing"
 	"time"
 
@@ -506,6 +507,14 @@ func TestHistoryRepository_DeleteOlderThan(t *testing.T) {
 
 	// Delete entries older than 24 hours.
 	cutoff := now.Add(-24 * time.Hour).Format(time.RFC3339)
+	old, err := repo.FindOlderThan(ctx, cutoff)
+	if err != nil {
+		t.Fatalf("FindOlderThan() error = %v", err)
+	}
+	if len(old) != 2 || old[0].ID != "hist-old-1" || old[1].ID != "hist-old-2" {
+		t.Errorf("FindOlderThan() = %v, want [hist-old-1 hist-old-2] oldest first", old)
+	}
+
 	deleted, err := repo.DeleteOlderThan(ctx, cutoff)
 	if err != nil {
 		t.Fatalf("DeleteOlderThan() error = %v", err)
@@ -533,6 +542,392 @@ func TestHistoryRepository_DeleteOlderThan(t *testing.T) {
 	}
 }
 
+func TestHistoryRepository_DeleteMatching(t *testing.T) {
+	db := setupTestDB(t)
+	defer func() { _ = db.Close() }()
+
+	repo := NewHistoryRepository(db)
+	reqRepo := NewRequestRepository(db)
+	ctx := context.Background()
+
+	createTestRequest(t, ctx, reqRepo, "req-a")
+	createTestRequest(t, ctx, reqRepo, "req-b")
+
+	now := time.Now()
+	entries := []*repository.HistoryEntry{
+		{ID: "a-old-ok", RequestID: "req-a", ExecutedAt: now.Add(-48 * time.Hour).Format(time.RFC3339), StatusCode: 200, Status: "200 OK"},
+		{ID: "a-old-500", RequestID: "req-a", ExecutedAt: now.Add(-47 * time.Hour).Format(time.RFC3339), StatusCode: 500, Status: "500 Internal Server Error"},
+		{ID: "a-new-err", RequestID: "req-a", ExecutedAt: now.Add(-1 * time.Hour).Format(time.RFC3339), Error: "connection refused"},
+		{ID: "b-old-assert", RequestID: "req-b", ExecutedAt: now.Add(-46 * time.Hour).Format(time.RFC3339), StatusCode: 200, Status: "200 OK", AssertionFailures: `["$.id: required"]`},
+		{ID: "adhoc-new-ok", ExecutedAt: now.Add(-2 * time.Hour).Format(time.RFC3339), StatusCode: 204, Status: "204 No Content"},
+	}
+	for _, entry := range entries {
+		if err := repo.Save(ctx, entry); err != nil {
+			t.Fatalf("failed to save test entry: %v", err)
+		}
+	}
+
+	remaining := func() string {
+		t.Helper()
+		all, err := repo.FindAll(ctx, 0)
+		if err != nil {
+			t.Fatalf("FindAll() error = %v", err)
+		}
+		var ids []string
+		for _, entry := range all {
+			ids = append(ids, entry.ID)
+		}
+		return fmt.Sprint(ids)
+	}
+
+	steps := []struct {
+		filter  repository.HistoryFilter
+		deleted int64
+		want    string
+	}{
+		// Old failures of req-a only.
+		{repository.HistoryFilter{RequestIDs: []string{"req-a"}, Before: now.Add(-24 * time.Hour).Format(time.RFC3339), FailedOnly: true}, 1, "[a-new-err adhoc-new-ok b-old-assert a-old-ok]"},
+		// Failures of any request; assertion failures count.
+		{repository.HistoryFilter{FailedOnly: true}, 2, "[adhoc-new-ok a-old-ok]"},
+		// Everything from the last day.
+		{repository.HistoryFilter{After: now.Add(-24 * time.Hour).Format(time.RFC3339)}, 1, "[a-old-ok]"},
+		{repository.HistoryFilter{RequestIDs: []string{"req-b"}}, 0, "[a-old-ok]"},
+		// Chosen entries.
+		{repository.HistoryFilter{IDs: []string{"a-old-ok", "missing"}}, 1, "[]"},
+	}
+
+	for i, step := range steps {
+		deleted, err := repo.DeleteMatching(ctx, step.filter)
+		if err != nil {
+			t.Fatalf("step %d: DeleteMatching() error = %v", i, err)
+		}
+		if deleted != step.deleted {
+			t.Errorf("step %d: DeleteMatching() deleted %d entries, want %d", i, deleted, step.deleted)
+		}
+		if got := remaining(); got != step.want {
+			t.Errorf("step %d: remaining entries = %s, want %s", i, got, step.want)
+		}
+	}
+}
+
+func TestHistoryRepository_FindMatching(t *testing.T) {
+	db := setupTestDB(t)
+	defer func() { _ = db.Close() }()
+
+	repo := NewHistoryRepository(db)
+	ctx := context.Background()
+
+	now := time.Now()
+	entries := []*repository.HistoryEntry{
+		{ID: "get-users-ok", ExecutedAt: now.Add(-4 * time.Hour).Format(time.RFC3339), StatusCode: 200, Status: "200 OK",
+			RequestSnapshot: `{"method":"GET","url":"https://api.example.com/Users"}`},
+		{ID: "post-users-404", ExecutedAt: now.Add(-3 * time.Hour).Format(time.RFC3339), StatusCode: 404, Status: "404 Not Found",
+			RequestSnapshot: `{"method":"POST","url":"https://api.example.com/users"}`, ResponseBodyRef: "ref-404"},
+		{ID: "post-orders-err", ExecutedAt: now.Add(-2 * time.Hour).Format(time.RFC3339), Error: "connection refused",
+			RequestSnapshot: `{"method":"POST","url":"https://api.example.com/orders"}`},
+		{ID: "no-snapshot-400", ExecutedAt: now.Add(-1 * time.Hour).Format(time.RFC3339), StatusCode: 400, Status: "400 Bad Request"},
+	}
+	for _, entry := range entries {
+		if err := repo.Save(ctx, entry); err != nil {
+			t.Fatalf("failed to save test entry: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name   string
+		filter repository.HistoryFilter
+		limit  int
+		want   string
+	}{
+		{"empty filter", repository.HistoryFilter{}, 0, "[no-snapshot-400 post-orders-err post-users-404 get-users-ok]"},
+		{"limit", repository.HistoryFilter{}, 2, "[no-snapshot-400 post-orders-err]"},
+		{"status class", repository.HistoryFilter{StatusClass: 4}, 0, "[no-snapshot-400 post-users-404]"},
+		{"method ignores case", repository.HistoryFilter{Method: "post"}, 0, "[post-orders-err post-users-404]"},
+		{"url ignores case", repository.HistoryFilter{URLContains: "USERS"}, 0, "[post-users-404 get-users-ok]"},
+		{"failed only", repository.HistoryFilter{FailedOnly: true, URLContains: "example"}, 0, "[post-orders-err post-users-404]"},
+		{"combined", repository.HistoryFilter{StatusClass: 2, Method: "GET", URLContains: "users"}, 0, "[get-users-ok]"},
+		{"ids", repository.HistoryFilter{IDs: []string{"get-users-ok", "post-orders-err", "missing"}}, 0, "[post-orders-err get-users-ok]"},
+		{"body refs", repository.HistoryFilter{BodyRefs: []string{"ref-404", "ref-unused"}}, 0, "[post-users-404]"},
+		{"no match", repository.HistoryFilter{StatusClass: 5}, 0, "[]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			found, err := repo.FindMatching(ctx, tt.filter, tt.limit)
+			if err != nil {
+				t.Fatalf("FindMatching() error = %v", err)
+			}
+			ids := []string{}
+			for _, entry := range found {
+				ids = append(ids, entry.ID)
+			}
+			if got := fmt.Sprint(ids); got != tt.want {
+				t.Errorf("FindMatching() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHistoryRepository_FindPage(t *testing.T) {
+	db := setupTestDB(t)
+	defer func() { _ = db.Close() }()
+
+	repo := NewHistoryRepository(db)
+	ctx := context.Background()
+
+	// Entries 0 and 1 are executed at the same time, so ID orders them.
+	now := time.Now()
+	for i, minutes := range []int{5, 5, 4, 3, 2, 1} {
+		entry := &repository.HistoryEntry{
+			ID:         fmt.Sprintf("entry-%d", i),
+			ExecutedAt: now.Add(-time.Duration(minutes) * time.Minute).Format(time.RFC3339),
+			StatusCode: 200 + 100*(i%3),
+			Status:     "status",
+		}
+		if err := repo.Save(ctx, entry); err != nil {
+			t.Fatalf("failed to save test entry: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name   string
+		filter repository.HistoryFilter
+		offset int
+		limit  int
+		want   string
+	}{
+		{"first page", repository.HistoryFilter{}, 0, 2, "[entry-5 entry-4]"},
+		{"second page", repository.HistoryFilter{}, 2, 2, "[entry-3 entry-2]"},
+		{"ties ordered by id", repository.HistoryFilter{}, 4, 2, "[entry-1 entry-0]"},
+		{"past the end", repository.HistoryFilter{}, 6, 2, "[]"},
+		{"filtered", repository.HistoryFilter{StatusClass: 2}, 1, 2, "[entry-0]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			found, err := repo.FindPage(ctx, tt.filter, tt.offset, tt.limit)
+			if err != nil {
+				t.Fatalf("FindPage() error = %v", err)
+			}
+			ids := []string{}
+			for _, entry := range found {
+				ids = append(ids, entry.ID)
+			}
+			if got := fmt.Sprint(ids); got != tt.want {
+				t.Errorf("FindPage() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHistoryRepository_CountMatching(t *testing.T) {
+	db := setupTestDB(t)
+	defer func() { _ = db.Close() }()
+
+	repo := NewHistoryRepository(db)
+	ctx := context.Background()
+
+	for i, code := range []int{200, 404, 500, 201} {
+		entry := &repository.HistoryEntry{
+			ID:         fmt.Sprintf("entry-%d", i),
+			ExecutedAt: time.Now().Format(time.RFC3339),
+			StatusCode: code,
+			Status:     fmt.Sprint(code),
+		}
+		if err := repo.Save(ctx, entry); err != nil {
+			t.Fatalf("failed to save test entry: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name   string
+		filter repository.HistoryFilter
+		want   int
+	}{
+		{"empty filter", repository.HistoryFilter{}, 4},
+		{"status class", repository.HistoryFilter{StatusClass: 2}, 2},
+		{"failed only", repository.HistoryFilter{FailedOnly: true}, 2},
+		{"no match", repository.HistoryFilter{StatusClass: 3}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := repo.CountMatching(ctx, tt.filter)
+			if err != nil {
+				t.Fatalf("CountMatching() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("CountMatching() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHistoryRepository_DeleteExceptNewest(t *testing.T) {
+	db := setupTestDB(t)
+	defer func() { _ = db.Close() }()
+
+	repo := NewHistoryRepository(db)
+	ctx := context.Background()
+
+	now := time.Now().UTC()
+	for i := 0; i < 5; i++ {
+		entry := &repository.HistoryEntry{
+			ID:         fmt.Sprintf("hist-%d", i),
+			ExecutedAt: now.Add(time.Duration(i) * time.Minute).Format(time.RFC3339),
+			StatusCode: 200,
+			Status:     "200 OK",
+		}
+		if err := repo.Save(ctx, entry); err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+	}
+
+	deleted, err := repo.DeleteExceptNewest(ctx, 2)
+	if err != nil {
+		t.Fatalf("DeleteExceptNewest() error = %v", err)
+	}
+	if deleted != 3 {
+		t.Errorf("DeleteExceptNewest() deleted %d entries, want 3", deleted)
+	}
+
+	remaining, err := repo.FindAll(ctx, 0)
+	if err != nil {
+		t.Fatalf("FindAll() error = %v", err)
+	}
+	if len(remaining) != 2 || remaining[0].ID != "hist-4" || remaining[1].ID != "hist-3" {
+		t.Errorf("DeleteExceptNewest() kept wrong entries: %v", remaining)
+	}
+
+	// Trimming below the limit is a no-op.
+	deleted, err = repo.DeleteExceptNewest(ctx, 10)
+	if err != nil {
+		t.Fatalf("DeleteExceptNewest() error = %v", err)
+	}
+	if deleted != 0 {
+		t.Errorf("DeleteExceptNewest() deleted %d entries, want 0", deleted)
+	}
+
+	if _, err := repo.DeleteExceptNewest(ctx, 0); err == nil {
+		t.Error("DeleteExceptNewest(0) error = nil, want error")
+	}
+}
+
+func TestHistoryRepository_SaveBatch(t *testing.T) {
+	db := setupTestDB(t)
+	defer func() { _ = db.Close() }()
+
+	repo := NewHistoryRepository(db)
+	reqRepo := NewRequestRepository(db)
+	ctx := context.Background()
+
+	createTestRequest(t, ctx, reqRepo, "req-batch")
+
+	base := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	var entries []*repository.HistoryEntry
+	for i := 0; i < 3; i++ {
+		entries = append(entries, &repository.HistoryEntry{
+			ID:         fmt.Sprintf("batch-%d", i),
+			RequestID:  "req-batch",
+			ExecutedAt: base.Add(time.Duration(i) * time.Minute).Format(time.RFC3339),
+			StatusCode: 200,
+			Status:     "200 OK",
+		})
+	}
+	entries = append(entries, &repository.HistoryEntry{
+		ID:         "batch-adhoc",
+		ExecutedAt: base.Format(time.RFC3339),
+		StatusCode: 200,
+	})
+
+	if err := repo.SaveBatch(ctx, entries); err != nil {
+		t.Fatalf("SaveBatch() error = %v", err)
+	}
+
+	all, err := repo.FindAll(ctx, 0)
+	if err != nil {
+		t.Fatalf("FindAll() error = %v", err)
+	}
+	if len(all) != 4 {
+		t.Errorf("FindAll() returned %d entries, want 4", len(all))
+	}
+
+	req, err := reqRepo.FindByID(ctx, "req-batch")
+	if err != nil {
+		t.Fatalf("FindByID() error = %v", err)
+	}
+	if req.ExecutionCount != 3 {
+		t.Errorf("ExecutionCount = %d, want 3", req.ExecutionCount)
+	}
+	if want := base.Add(2 * time.Minute); !req.LastExecutedAt.Equal(want) {
+		t.Errorf("LastExecutedAt = %v, want %v", req.LastExecutedAt, want)
+	}
+}
+
+func TestHistoryRepository_FindByRunID(t *testing.T) {
+	db := setupTestDB(t)
+	defer func() { _ = db.Close() }()
+
+	repo := NewHistoryRepository(db)
+	ctx := context.Background()
+
+	executedAt := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC).Format(time.RFC3339)
+	entries := []*repository.HistoryEntry{
+		{ID: "run-b", ExecutedAt: executedAt, StatusCode: 200, RunID: "run-1"},
+		{ID: "run-a", ExecutedAt: executedAt, StatusCode: 500, RunID: "run-1"},
+		{ID: "other", ExecutedAt: executedAt, StatusCode: 200, RunID: "run-2"},
+		{ID: "adhoc", ExecutedAt: executedAt, StatusCode: 200},
+	}
+	if err := repo.SaveBatch(ctx, entries); err != nil {
+		t.Fatalf("SaveBatch() error = %v", err)
+	}
+
+	got, err := repo.FindByRunID(ctx, "run-1")
+	if err != nil {
+		t.Fatalf("FindByRunID() error = %v", err)
+	}
+	// Entries executed in the same second keep their insertion order.
+	if len(got) != 2 || got[0].ID != "run-b" || got[1].ID != "run-a" {
+		t.Fatalf("FindByRunID() = %v, want [run-b run-a]", got)
+	}
+	if got[0].RunID != "run-1" {
+		t.Errorf("RunID = %q, want %q", got[0].RunID, "run-1")
+	}
+
+	adhoc, err := repo.FindByID(ctx, "adhoc")
+	if err != nil {
+		t.Fatalf("FindByID() error = %v", err)
+	}
+	if adhoc.RunID != "" {
+		t.Errorf("RunID = %q, want empty", adhoc.RunID)
+	}
+}
+
+func TestHistoryRepository_SaveBatch_RollsBack(t *testing.T) {
+	db := setupTestDB(t)
+	defer func() { _ = db.Close() }()
+
+	repo := NewHistoryRepository(db)
+	ctx := context.Background()
+
+	entry := &repository.HistoryEntry{ID: "dup", ExecutedAt: time.Now().UTC().Format(time.RFC3339)}
+	fresh := &repository.HistoryEntry{ID: "fresh", ExecutedAt: entry.ExecutedAt}
+
+	// The duplicate ID fails the batch, so the first entry must not be kept either.
+	err := repo.SaveBatch(ctx, []*repository.HistoryEntry{fresh, entry, entry})
+	if err == nil {
+		t.Fatal("SaveBatch() error = nil, want duplicate key error")
+	}
+
+	if _, err := repo.FindByID(ctx, "fresh"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("FindByID(fresh) error = %v, want ErrNotFound after rollback", err)
+	}
+
+	if err := repo.SaveBatch(ctx, nil); err != nil {
+		t.Errorf("SaveBatch(nil) error = %v, want nil", err)
+	}
+}
+
 func TestHistoryRepository_NullHandling(t *testing.T) {
 	db := setupTestDB(t)
 	defer func() { _ = db.Close() }()
@@ -571,3 +966,75 @@ func TestHistoryRepository_NullHandling(t *testing.T) {
 		t.Errorf("FindByID() Error = %q, want empty string", got.Error)
 	}
 }
+
+func TestHistoryRepository_RequestSnapshotAndReplay(t *testing.T) {
+	db := setupTestDB(t)
+	defer func() { _ = db.Close() }()
+
+	repo := NewHistoryRepository(db)
+	ctx := context.Background()
+
+	executedAt := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC).Format(time.RFC3339)
+	original := &repository.HistoryEntry{
+		ID:              "original",
+		ExecutedAt:      executedAt,
+		StatusCode:      200,
+		RequestSnapshot: `{"method":"GET","url":"https://api.example.com/users"}`,
+	}
+	replay := &repository.HistoryEntry{
+		ID:              "replay",
+		ExecutedAt:      executedAt,
+		StatusCode:      200,
+		RequestSnapshot: original.RequestSnapshot,
+		ReplayOf:        original.ID,
+	}
+	if err := repo.SaveBatch(ctx, []*repository.HistoryEntry{original, replay}); err != nil {
+		t.Fatalf("SaveBatch() error = %v", err)
+	}
+
+	got, err := repo.FindByID(ctx, "replay")
+	if err != nil {
+		t.Fatalf("FindByID() error = %v", err)
+	}
+	if got.RequestSnapshot != original.RequestSnapshot {
+		t.Errorf("RequestSnapshot = %q, want %q", got.RequestSnapshot, original.RequestSnapshot)
+	}
+	if got.ReplayOf != "original" {
+		t.Errorf("ReplayOf = %q, want %q", got.ReplayOf, "original")
+	}
+
+	got, err = repo.FindByID(ctx, "original")
+	if err != nil {
+		t.Fatalf("FindByID() error = %v", err)
+	}
+	if got.ReplayOf != "" {
+		t.Errorf("ReplayOf = %q, want empty", got.ReplayOf)
+	}
+}
+
+func TestHistoryRepository_AssertionFailures(t *testing.T) {
+	db := setupTestDB(t)
+	defer func() { _ = db.Close() }()
+
+	repo := NewHistoryRepository(db)
+	ctx := context.Background()
+
+	failures := repository.MarshalAssertionFailures([]string{`$: missing required property "id"`})
+	entry := &repository.HistoryEntry{
+		ID:                "failed-checks",
+		ExecutedAt:        time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC).Format(time.RFC3339),
+		StatusCode:        200,
+		AssertionFailures: failures,
+	}
+	if err := repo.Save(ctx, entry); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	got, err := repo.FindByID(ctx, entry.ID)
+	if err != nil {
+		t.Fatalf("FindByID() error = %v", err)
+	}
+	if got.AssertionFailures != failures {
+		t.Errorf("AssertionFailures = %q, want %q", got.AssertionFailures, failures)
+	}
+}
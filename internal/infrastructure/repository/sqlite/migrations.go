@@ -3,10 +3,13 @@ package sqlite
 import (
 	"database/sql"
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"sort"
 	"strings"
+
+	schema "github.com/williajm/curly/migrations"
 )
 
 // Migration represents a single database migration.
@@ -16,20 +19,26 @@ type Migration struct {
 	SQL     string
 }
 
-// runMigrations executes all pending migrations in order.
+// runMigrations executes all pending migrations from a directory on disk, in order.
+// It is used when Config.MigrationsPath overrides the embedded migrations during development.
 // It tracks which migrations have been applied using a schema_migrations table.
 func runMigrations(db *sql.DB, migrationsPath string) error {
-	// Create migrations tracking table if it doesn't exist.
-	if err := createMigrationsTable(db); err != nil {
-		return fmt.Errorf("failed to create migrations table: %w", err)
-	}
-
 	// Load migration files.
 	migrations, err := loadMigrations(migrationsPath)
 	if err != nil {
 		return fmt.Errorf("failed to load migrations: %w", err)
 	}
 
+	return applyPendingMigrations(db, migrations)
+}
+
+// applyPendingMigrations applies every migration that is not yet recorded in schema_migrations.
+func applyPendingMigrations(db *sql.DB, migrations []Migration) error {
+	// Create migrations tracking table if it doesn't exist.
+	if err := createMigrationsTable(db); err != nil {
+		return fmt.Errorf("failed to create migrations table: %w", err)
+	}
+
 	// Get applied migrations.
 	appliedVersions, err := getAppliedMigrations(db)
 	if err != nil {
@@ -73,8 +82,12 @@ func loadMigrations(migrationsPath string) ([]Migration, error) {
 		return []Migration{}, nil
 	}
 
-	// Read directory contents.
-	entries, err := os.ReadDir(migrationsPath)
+	return loadMigrationsFS(os.DirFS(migrationsPath))
+}
+
+// loadMigrationsFS reads all migration files from the root of fsys, sorted by version.
+func loadMigrationsFS(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
 	if err != nil {
 		return nil, fmt.Errorf("failed to read migrations directory: %w", err)
 	}
@@ -86,7 +99,12 @@ func loadMigrations(migrationsPath string) ([]Migration, error) {
 			continue
 		}
 
-		migration, err := parseMigrationFile(filepath.Join(migrationsPath, entry.Name()))
+		sqlBytes, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration file %s: %w", entry.Name(), err)
+		}
+
+		migration, err := newMigration(entry.Name(), sqlBytes)
 		if err != nil {
 			return nil, fmt.Errorf("failed to parse migration file %s: %w", entry.Name(), err)
 		}
@@ -105,8 +123,17 @@ func loadMigrations(migrationsPath string) ([]Migration, error) {
 // parseMigrationFile parses a migration file and extracts version, name, and SQL.
 // Expected format: NNN_description.sql.
 func parseMigrationFile(filePath string) (Migration, error) {
-	filename := filepath.Base(filePath)
+	// Read SQL content.
+	sqlBytes, err := os.ReadFile(filePath) // #nosec G304 -- Migration file path is controlled by the application
+	if err != nil {
+		return Migration{}, fmt.Errorf("failed to read migration file: %w", err)
+	}
+
+	return newMigration(filepath.Base(filePath), sqlBytes)
+}
 
+// newMigration builds a Migration from its file name and SQL content.
+func newMigration(filename string, sqlBytes []byte) (Migration, error) {
 	// Extract version from filename (before first underscore).
 	parts := strings.SplitN(filename, "_", 2)
 	if len(parts) != 2 {
@@ -122,12 +149,6 @@ func parseMigrationFile(filePath string) (Migration, error) {
 	// Extract name (remove .sql extension).
 	name := strings.TrimSuffix(parts[1], ".sql")
 
-	// Read SQL content.
-	sqlBytes, err := os.ReadFile(filePath) // #nosec G304 -- Migration file path is controlled by the application
-	if err != nil {
-		return Migration{}, fmt.Errorf("failed to read migration file: %w", err)
-	}
-
 	return Migration{
 		Version: version,
 		Name:    name,
@@ -186,74 +207,18 @@ func applyMigration(db *sql.DB, migration Migration) error {
 	return nil
 }
 
-// embeddedMigrations contains the schema migrations embedded in the code.
-var embeddedMigrations = []Migration{
-	{
-		Version: 1,
-		Name:    "initial_schema",
-		SQL: `
--- Requests table
-CREATE TABLE IF NOT EXISTS requests (
-	id TEXT PRIMARY KEY,
-	name TEXT NOT NULL,
-	method TEXT NOT NULL,
-	url TEXT NOT NULL,
-	headers TEXT,
-	query_params TEXT,
-	body TEXT,
-	auth_type TEXT,
-	auth_config TEXT,
-	created_at TIMESTAMP NOT NULL,
-	updated_at TIMESTAMP NOT NULL
-);
-
--- History table
-CREATE TABLE IF NOT EXISTS history (
-	id TEXT PRIMARY KEY,
-	request_id TEXT,
-	executed_at TIMESTAMP NOT NULL,
-	status_code INTEGER,
-	status TEXT,
-	response_time_ms INTEGER,
-	response_headers TEXT,
-	response_body TEXT,
-	error TEXT,
-	FOREIGN KEY (request_id) REFERENCES requests(id) ON DELETE CASCADE
-);
-
--- Indexes for better query performance
-CREATE INDEX IF NOT EXISTS idx_requests_created_at ON requests(created_at DESC);
-CREATE INDEX IF NOT EXISTS idx_requests_updated_at ON requests(updated_at DESC);
-CREATE INDEX IF NOT EXISTS idx_history_executed_at ON history(executed_at DESC);
-CREATE INDEX IF NOT EXISTS idx_history_request_id ON history(request_id);
-		`,
-	},
+// embeddedMigrations returns the migrations compiled into the binary.
+func embeddedMigrations() ([]Migration, error) {
+	return loadMigrationsFS(schema.FS)
 }
 
 // MigrateDB runs embedded migrations on the database.
 // This is the recommended way to initialize the database schema.
 func MigrateDB(db *sql.DB) error {
-	// Create migrations tracking table if it doesn't exist.
-	if err := createMigrationsTable(db); err != nil {
-		return fmt.Errorf("failed to create migrations table: %w", err)
-	}
-
-	// Get applied migrations.
-	appliedVersions, err := getAppliedMigrations(db)
+	migrations, err := embeddedMigrations()
 	if err != nil {
-		return fmt.Errorf("failed to get applied migrations: %w", err)
-	}
-
-	// Apply pending migrations.
-	for _, migration := range embeddedMigrations {
-		if _, applied := appliedVersions[migration.Version]; applied {
-			continue
-		}
-
-		if err := applyMigration(db, migration); err != nil {
-			return fmt.Errorf("failed to apply migration %d (%s): %w", migration.Version, migration.Name, err)
-		}
+		return fmt.Errorf("failed to load embedded migrations: %w", err)
 	}
 
-	return nil
+	return applyPendingMigrations(db, migrations)
 }
@@ -1,6 +1,7 @@
 package sqlite
 
 import (
+	"context"
 	"database/sql"
 	"os"
 	"path/filepath"
@@ -8,6 +9,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/williajm/curly/migrations"
 )
 
 func TestMigrateDB_Success(t *testing.T) {
@@ -19,11 +21,14 @@ func TestMigrateDB_Success(t *testing.T) {
 	err = MigrateDB(db)
 	require.NoError(t, err)
 
+	embedded, err := embeddedMigrations()
+	require.NoError(t, err)
+
 	// Verify schema_migrations table exists.
 	var count int
 	err = db.QueryRow("SELECT COUNT(*) FROM schema_migrations").Scan(&count)
 	require.NoError(t, err)
-	assert.Equal(t, 1, count, "should have 1 migration applied")
+	assert.Equal(t, len(embedded), count, "should have every embedded migration applied")
 
 	// Verify requests table exists.
 	_, err = db.Exec("SELECT * FROM requests LIMIT 1")
@@ -57,11 +62,14 @@ func TestMigrateDB_Idempotent(t *testing.T) {
 	err = MigrateDB(db)
 	require.NoError(t, err)
 
-	// Verify only one migration record exists.
+	embedded, err := embeddedMigrations()
+	require.NoError(t, err)
+
+	// Verify each migration was recorded only once.
 	var count int
 	err = db.QueryRow("SELECT COUNT(*) FROM schema_migrations").Scan(&count)
 	require.NoError(t, err)
-	assert.Equal(t, 1, count, "should still have only 1 migration applied")
+	assert.Equal(t, len(embedded), count, "should still have each migration applied once")
 }
 
 func TestCreateMigrationsTable(t *testing.T) {
@@ -388,13 +396,12 @@ func TestRunMigrations_AppliesOnlyPending(t *testing.T) {
 }
 
 func TestMigrateDB_ForeignKeyConstraints(t *testing.T) {
-	db, err := sql.Open("sqlite", ":memory:")
+	// Open with the connection pragmas, which enable foreign keys.
+	dsn, err := buildDSN(&Config{Path: ":memory:"})
 	require.NoError(t, err)
-	defer func() { _ = db.Close() }()
-
-	// Apply pragmas to enable foreign keys before migrations.
-	err = applyPragmas(db)
+	db, err := sql.Open("sqlite", dsn)
 	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
 
 	// Run migrations.
 	err = MigrateDB(db)
@@ -431,3 +438,91 @@ func TestMigrateDB_ForeignKeyConstraints(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, 0, historyCount, "history should be deleted via CASCADE")
 }
+
+func TestEmbeddedMigrations(t *testing.T) {
+	migrations, err := embeddedMigrations()
+	require.NoError(t, err)
+	require.NotEmpty(t, migrations)
+
+	assert.Equal(t, 1, migrations[0].Version)
+	assert.Equal(t, "initial_schema", migrations[0].Name)
+
+	// Versions must be unique and ascending.
+	for i := 1; i < len(migrations); i++ {
+		assert.Greater(t, migrations[i].Version, migrations[i-1].Version)
+	}
+}
+
+func TestOpen_AppliesEmbeddedMigrations(t *testing.T) {
+	db, err := Open(&Config{Path: filepath.Join(t.TempDir(), "curly.db")})
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	_, err = db.Exec("SELECT id FROM requests LIMIT 1")
+	assert.NoError(t, err, "requests table should exist without a migrations directory")
+}
+
+func TestOpen_UsesMigrationsPathOverride(t *testing.T) {
+	tmpDir := t.TempDir()
+	err := os.WriteFile(filepath.Join(tmpDir, "001_custom.sql"), []byte("CREATE TABLE custom (id INTEGER);"), 0600)
+	require.NoError(t, err)
+
+	db, err := Open(&Config{Path: filepath.Join(t.TempDir(), "curly.db"), MigrationsPath: tmpDir})
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	_, err = db.Exec("SELECT id FROM custom LIMIT 1")
+	assert.NoError(t, err)
+
+	_, err = db.Exec("SELECT id FROM requests LIMIT 1")
+	assert.Error(t, err, "embedded migrations should not run when overridden")
+}
+
+func TestMigration004_BackfillsPositionsInCreationOrder(t *testing.T) {
+	migrationsDir := t.TempDir()
+	copyMigration := func(name string) {
+		data, err := migrations.FS.ReadFile(name)
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(filepath.Join(migrationsDir, name), data, 0600))
+	}
+	for _, name := range []string{"001_initial_schema.sql", "002_history_body_ref.sql", "003_request_usage.sql"} {
+		copyMigration(name)
+	}
+
+	db, err := sql.Open("sqlite", ":memory:")
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+	db.SetMaxOpenConns(1)
+	require.NoError(t, runMigrations(db, migrationsDir))
+
+	for _, row := range []struct{ id, createdAt string }{
+		{"newest", "2024-03-01T00:00:00Z"},
+		{"oldest", "2024-01-01T00:00:00Z"},
+		{"middle", "2024-02-01T00:00:00Z"},
+	} {
+		_, err := db.Exec(`
+			INSERT INTO requests (id, name, method, url, headers, query_params, body, auth_type, auth_config, created_at, updated_at)
+			VALUES (?, 'Test', 'GET', 'http://example.com', '{}', '{}', '', 'none', '{}', ?, ?)
+		`, row.id, row.createdAt, row.createdAt)
+		require.NoError(t, err)
+	}
+
+	copyMigration("004_request_ordering.sql")
+	require.NoError(t, runMigrations(db, migrationsDir))
+
+	// Apply the later migrations too, so the repository can read the table.
+	entries, err := migrations.FS.ReadDir(".")
+	require.NoError(t, err)
+	for _, entry := range entries {
+		copyMigration(entry.Name())
+	}
+	require.NoError(t, runMigrations(db, migrationsDir))
+
+	requests, err := NewRequestRepository(db).FindByFolder(context.Background(), "")
+	require.NoError(t, err)
+	var ids []string
+	for _, req := range requests {
+		ids = append(ids, req.ID)
+	}
+	assert.Equal(t, []string{"oldest", "middle", "newest"}, ids)
+}
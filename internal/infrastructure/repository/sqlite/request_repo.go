@@ -9,13 +9,18 @@ import (
 	"time"
 
 	"github.com/williajm/curly/internal/domain"
+	"github.com/williajm/curly/internal/infrastructure/repository"
 )
 
 // Common repository errors.
 var (
-	ErrNotFound = errors.New("not found")
+	// ErrNotFound is an alias of repository.ErrNotFound, kept for existing callers.
+	ErrNotFound = repository.ErrNotFound
 )
 
+// requestColumns lists the columns selected for a request, in scan order.
+const requestColumns = `id, name, method, url, headers, query_params, body, auth_type, auth_config, created_at, updated_at, last_executed_at, execution_count, folder, position, response_schema, response_contract, tags, response_filter, pre_request_script, post_response_script, disabled_query_params`
+
 // RequestRepository implements repository.RequestRepository using SQLite.
 type RequestRepository struct {
 	db *sql.DB
@@ -50,7 +55,7 @@ func (r *RequestRepository) Create(ctx context.Context, req *domain.Request) err
 	}
 
 	// Serialize auth config to JSON.
-	authConfigJSON, err := serializeAuthConfig(req.AuthConfig)
+	authConfigJSON, err := repository.MarshalAuthConfig(req.AuthConfig)
 	if err != nil {
 		return fmt.Errorf("failed to serialize auth config: %w", err)
 	}
@@ -61,12 +66,14 @@ func (r *RequestRepository) Create(ctx context.Context, req *domain.Request) err
 		authType = req.AuthConfig.Type()
 	}
 
+	// Append the request to the end of its folder.
 	query := `
-		INSERT INTO requests (id, name, method, url, headers, query_params, body, auth_type, auth_config, created_at, updated_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		INSERT INTO requests (id, name, method, url, headers, query_params, body, auth_type, auth_config, created_at, updated_at, response_schema, response_contract, tags, response_filter, pre_request_script, post_response_script, disabled_query_params, folder, position)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, (SELECT COALESCE(MAX(position), -1) + 1 FROM requests WHERE folder = ?))
+		RETURNING position
 	`
 
-	_, err = r.db.ExecContext(ctx, query,
+	err = r.db.QueryRowContext(ctx, query,
 		req.ID,
 		req.Name,
 		req.Method,
@@ -78,7 +85,16 @@ func (r *RequestRepository) Create(ctx context.Context, req *domain.Request) err
 		string(authConfigJSON),
 		req.CreatedAt.Format(time.RFC3339),
 		req.UpdatedAt.Format(time.RFC3339),
-	)
+		req.ResponseSchema,
+		req.ResponseContract,
+		repository.MarshalTags(req.Tags),
+		req.ResponseFilter,
+		req.PreRequestScript,
+		req.PostResponseScript,
+		repository.MarshalQueryParams(req.DisabledQueryParams),
+		req.Folder,
+		req.Folder,
+	).Scan(&req.Position)
 
 	if err != nil {
 		return fmt.Errorf("failed to create request: %w", err)
@@ -89,88 +105,286 @@ func (r *RequestRepository) Create(ctx context.Context, req *domain.Request) err
 
 // FindByID retrieves a request by its ID.
 func (r *RequestRepository) FindByID(ctx context.Context, id string) (*domain.Request, error) {
-	query := `
-		SELECT id, name, method, url, headers, query_params, body, auth_type, auth_config, created_at, updated_at
-		FROM requests
-		WHERE id = ?
-	`
+	query := `SELECT ` + requestColumns + ` FROM requests WHERE id = ?`
 
-	row := r.db.QueryRowContext(ctx, query, id)
-
-	var (
-		reqID           string
-		name            string
-		method          string
-		url             string
-		headersJSON     string
-		queryParamsJSON string
-		body            string
-		authType        string
-		authConfigJSON  string
-		createdAt       string
-		updatedAt       string
-	)
-
-	err := row.Scan(&reqID, &name, &method, &url, &headersJSON, &queryParamsJSON, &body, &authType, &authConfigJSON, &createdAt, &updatedAt)
+	req, err := scanRequest(r.db.QueryRowContext(ctx, query, id))
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, ErrNotFound
 		}
-		return nil, fmt.Errorf("failed to scan request: %w", err)
+		return nil, err
 	}
 
-	return buildRequest(reqID, name, method, url, headersJSON, queryParamsJSON, body, authType, authConfigJSON, createdAt, updatedAt)
+	return req, nil
 }
 
 // FindAll retrieves all requests ordered by created_at descending.
 func (r *RequestRepository) FindAll(ctx context.Context) ([]*domain.Request, error) {
+	query := `SELECT ` + requestColumns + ` FROM requests ORDER BY created_at DESC`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query requests: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	return scanRequests(rows)
+}
+
+// FindRecentlyUsed retrieves requests ordered by last execution, most recent first.
+func (r *RequestRepository) FindRecentlyUsed(ctx context.Context, limit int) ([]*domain.Request, error) {
 	query := `
-		SELECT id, name, method, url, headers, query_params, body, auth_type, auth_config, created_at, updated_at
+		SELECT ` + requestColumns + `
 		FROM requests
-		ORDER BY created_at DESC
+		ORDER BY last_executed_at IS NULL, last_executed_at DESC, created_at DESC
 	`
 
+	// Add LIMIT clause if specified.
+	if limit > 0 {
+		query += fmt.Sprintf(" LIMIT %d", limit)
+	}
+
 	rows, err := r.db.QueryContext(ctx, query)
 	if err != nil {
-		return nil, fmt.Errorf("failed to query requests: %w", err)
+		return nil, fmt.Errorf("failed to query recently used requests: %w", err)
 	}
 	defer func() { _ = rows.Close() }()
 
-	var requests []*domain.Request
+	return scanRequests(rows)
+}
 
-	for rows.Next() {
-		var (
-			reqID           string
-			name            string
-			method          string
-			url             string
-			headersJSON     string
-			queryParamsJSON string
-			body            string
-			authType        string
-			authConfigJSON  string
-			createdAt       string
-			updatedAt       string
-		)
-
-		err := rows.Scan(&reqID, &name, &method, &url, &headersJSON, &queryParamsJSON, &body, &authType, &authConfigJSON, &createdAt, &updatedAt)
-		if err != nil {
-			return nil, fmt.Errorf("failed to scan request: %w", err)
+// RecordExecution increments a request's execution count and sets its last execution time.
+func (r *RequestRepository) RecordExecution(ctx context.Context, id string, executedAt time.Time) error {
+	query := `
+		UPDATE requests
+		SET execution_count = execution_count + 1, last_executed_at = ?
+		WHERE id = ?
+	`
+
+	result, err := r.db.ExecContext(ctx, query, executedAt.UTC().Format(time.RFC3339), id)
+	if err != nil {
+		return fmt.Errorf("failed to record request execution: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// FindByFolder retrieves the requests in a folder ordered by position.
+func (r *RequestRepository) FindByFolder(ctx context.Context, folder string) ([]*domain.Request, error) {
+	query := `SELECT ` + requestColumns + ` FROM requests WHERE folder = ? ORDER BY position, created_at, id`
+
+	rows, err := r.db.QueryContext(ctx, query, folder)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query folder: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	return scanRequests(rows)
+}
+
+// Move places a request at position within folder and renumbers the affected folders.
+func (r *RequestRepository) Move(ctx context.Context, id, folder string, position int) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var oldFolder string
+	err = tx.QueryRowContext(ctx, `SELECT folder FROM requests WHERE id = ?`, id).Scan(&oldFolder)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrNotFound
 		}
+		return fmt.Errorf("failed to load request: %w", err)
+	}
+
+	ids, err := folderIDs(ctx, tx, folder)
+	if err != nil {
+		return err
+	}
+	if err := renumber(ctx, tx, folder, repository.MoveID(ids, id, position)); err != nil {
+		return err
+	}
 
-		req, err := buildRequest(reqID, name, method, url, headersJSON, queryParamsJSON, body, authType, authConfigJSON, createdAt, updatedAt)
+	// Close the gap left in the folder the request came from.
+	if oldFolder != folder {
+		ids, err := folderIDs(ctx, tx, oldFolder)
 		if err != nil {
-			return nil, err
+			return err
 		}
+		if err := renumber(ctx, tx, oldFolder, ids); err != nil {
+			return err
+		}
+	}
 
-		requests = append(requests, req)
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit move: %w", err)
 	}
 
+	return nil
+}
+
+// folderIDs returns the IDs of the requests in folder in their current order.
+func folderIDs(ctx context.Context, tx *sql.Tx, folder string) ([]string, error) {
+	rows, err := tx.QueryContext(ctx, `SELECT id FROM requests WHERE folder = ? ORDER BY position, created_at, id`, folder)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query folder: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan request id: %w", err)
+		}
+		ids = append(ids, id)
+	}
 	if err := rows.Err(); err != nil {
 		return nil, fmt.Errorf("error iterating rows: %w", err)
 	}
 
-	return requests, nil
+	return ids, nil
+}
+
+// renumber assigns ids consecutive positions from 0 within folder.
+func renumber(ctx context.Context, tx *sql.Tx, folder string, ids []string) error {
+	for i, id := range ids {
+		if _, err := tx.ExecContext(ctx, `UPDATE requests SET folder = ?, position = ? WHERE id = ?`, folder, i, id); err != nil {
+			return fmt.Errorf("failed to update request position: %w", err)
+		}
+	}
+	return nil
+}
+
+// DeleteMany removes requests in a single transaction.
+func (r *RequestRepository) DeleteMany(ctx context.Context, ids []string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	for _, id := range repository.DistinctIDs(ids) {
+		result, err := tx.ExecContext(ctx, `DELETE FROM requests WHERE id = ?`, id)
+		if err != nil {
+			return fmt.Errorf("failed to delete request: %w", err)
+		}
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("failed to get rows affected: %w", err)
+		}
+		if rowsAffected == 0 {
+			return ErrNotFound
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit delete: %w", err)
+	}
+
+	return nil
+}
+
+// MoveMany appends requests to the end of folder and renumbers the affected folders.
+func (r *RequestRepository) MoveMany(ctx context.Context, ids []string, folder string) error {
+	ids = repository.DistinctIDs(ids)
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	oldFolders := make(map[string]bool)
+	for _, id := range ids {
+		var oldFolder string
+		err := tx.QueryRowContext(ctx, `SELECT folder FROM requests WHERE id = ?`, id).Scan(&oldFolder)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrNotFound
+			}
+			return fmt.Errorf("failed to load request: %w", err)
+		}
+		oldFolders[oldFolder] = true
+	}
+
+	folderOrder, err := folderIDs(ctx, tx, folder)
+	if err != nil {
+		return err
+	}
+	if err := renumber(ctx, tx, folder, repository.AppendIDs(folderOrder, ids)); err != nil {
+		return err
+	}
+
+	// Close the gaps left in the folders the requests came from.
+	for oldFolder := range oldFolders {
+		if oldFolder == folder {
+			continue
+		}
+		remaining, err := folderIDs(ctx, tx, oldFolder)
+		if err != nil {
+			return err
+		}
+		if err := renumber(ctx, tx, oldFolder, remaining); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit move: %w", err)
+	}
+
+	return nil
+}
+
+// Retag adds and removes tags on requests in a single transaction.
+func (r *RequestRepository) Retag(ctx context.Context, ids []string, add, remove []string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	now := time.Now().Format(time.RFC3339)
+	for _, id := range repository.DistinctIDs(ids) {
+		var tagsJSON string
+		err := tx.QueryRowContext(ctx, `SELECT tags FROM requests WHERE id = ?`, id).Scan(&tagsJSON)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrNotFound
+			}
+			return fmt.Errorf("failed to load request: %w", err)
+		}
+		tags, err := repository.UnmarshalTags(tagsJSON)
+		if err != nil {
+			return err
+		}
+
+		retagged := repository.MarshalTags(repository.Retag(tags, add, remove))
+		if retagged == repository.MarshalTags(tags) {
+			continue
+		}
+		if _, err := tx.ExecContext(ctx, `UPDATE requests SET tags = ?, updated_at = ? WHERE id = ?`, retagged, now, id); err != nil {
+			return fmt.Errorf("failed to update request tags: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit retag: %w", err)
+	}
+
+	return nil
 }
 
 // Update modifies an existing request.
@@ -200,7 +414,7 @@ func (r *RequestRepository) Update(ctx context.Context, req *domain.Request) err
 	}
 
 	// Serialize auth config to JSON.
-	authConfigJSON, err := serializeAuthConfig(req.AuthConfig)
+	authConfigJSON, err := repository.MarshalAuthConfig(req.AuthConfig)
 	if err != nil {
 		return fmt.Errorf("failed to serialize auth config: %w", err)
 	}
@@ -213,7 +427,7 @@ func (r *RequestRepository) Update(ctx context.Context, req *domain.Request) err
 
 	query := `
 		UPDATE requests
-		SET name = ?, method = ?, url = ?, headers = ?, query_params = ?, body = ?, auth_type = ?, auth_config = ?, updated_at = ?
+		SET name = ?, method = ?, url = ?, headers = ?, query_params = ?, body = ?, auth_type = ?, auth_config = ?, response_schema = ?, response_contract = ?, tags = ?, response_filter = ?, pre_request_script = ?, post_response_script = ?, disabled_query_params = ?, updated_at = ?
 		WHERE id = ?
 	`
 
@@ -226,6 +440,13 @@ func (r *RequestRepository) Update(ctx context.Context, req *domain.Request) err
 		req.Body,
 		authType,
 		string(authConfigJSON),
+		req.ResponseSchema,
+		req.ResponseContract,
+		repository.MarshalTags(req.Tags),
+		req.ResponseFilter,
+		req.PreRequestScript,
+		req.PostResponseScript,
+		repository.MarshalQueryParams(req.DisabledQueryParams),
 		req.UpdatedAt.Format(time.RFC3339),
 		req.ID,
 	)
@@ -267,6 +488,90 @@ func (r *RequestRepository) Delete(ctx context.Context, id string) error {
 	return nil
 }
 
+// scanRequest reads a request selected with requestColumns.
+// It returns sql.ErrNoRows unwrapped so callers can map it to ErrNotFound.
+func scanRequest(row rowScanner) (*domain.Request, error) {
+	var (
+		reqID            string
+		name             string
+		method           string
+		url              string
+		headersJSON      string
+		queryParamsJSON  string
+		body             string
+		authType         string
+		authConfigJSON   string
+		createdAt        string
+		updatedAt        string
+		lastExecutedAt   sql.NullString
+		executionCount   int64
+		folder           string
+		position         int
+		responseSchema   string
+		responseContract string
+		tagsJSON         string
+		responseFilter   string
+		preScript        string
+		postScript       string
+		disabledJSON     string
+	)
+
+	err := row.Scan(&reqID, &name, &method, &url, &headersJSON, &queryParamsJSON, &body, &authType, &authConfigJSON, &createdAt, &updatedAt, &lastExecutedAt, &executionCount, &folder, &position, &responseSchema, &responseContract, &tagsJSON, &responseFilter, &preScript, &postScript, &disabledJSON)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to scan request: %w", err)
+	}
+
+	req, err := buildRequest(reqID, name, method, url, headersJSON, queryParamsJSON, body, authType, authConfigJSON, createdAt, updatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	req.ExecutionCount = executionCount
+	req.Folder = folder
+	req.Position = position
+	req.ResponseSchema = responseSchema
+	req.ResponseContract = responseContract
+	req.ResponseFilter = responseFilter
+	req.PreRequestScript = preScript
+	req.PostResponseScript = postScript
+	if req.Tags, err = repository.UnmarshalTags(tagsJSON); err != nil {
+		return nil, err
+	}
+	if req.DisabledQueryParams, err = repository.UnmarshalQueryParams(disabledJSON); err != nil {
+		return nil, err
+	}
+	if lastExecutedAt.Valid {
+		req.LastExecutedAt, err = time.Parse(time.RFC3339, lastExecutedAt.String)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse last_executed_at: %w", err)
+		}
+	}
+
+	return req, nil
+}
+
+// scanRequests reads all remaining rows into requests.
+func scanRequests(rows *sql.Rows) ([]*domain.Request, error) {
+	var requests []*domain.Request
+
+	for rows.Next() {
+		req, err := scanRequest(rows)
+		if err != nil {
+			return nil, err
+		}
+		requests = append(requests, req)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+
+	return requests, nil
+}
+
 // buildRequest constructs a domain.Request from database fields.
 func buildRequest(id, name, method, url, headersJSON, queryParamsJSON, body, authType, authConfigJSON, createdAt, updatedAt string) (*domain.Request, error) {
 	// Parse headers.
@@ -282,7 +587,7 @@ func buildRequest(id, name, method, url, headersJSON, queryParamsJSON, body, aut
 	}
 
 	// Parse auth config.
-	authConfig, err := deserializeAuthConfig(authType, authConfigJSON)
+	authConfig, err := repository.UnmarshalAuthConfig(authType, authConfigJSON)
 	if err != nil {
 		return nil, fmt.Errorf("failed to deserialize auth config: %w", err)
 	}
@@ -311,69 +616,3 @@ func buildRequest(id, name, method, url, headersJSON, queryParamsJSON, body, aut
 		UpdatedAt:   updatedTime,
 	}, nil
 }
-
-// serializeAuthConfig converts an AuthConfig to JSON.
-func serializeAuthConfig(auth domain.AuthConfig) ([]byte, error) {
-	if auth == nil {
-		return json.Marshal(map[string]interface{}{})
-	}
-
-	switch a := auth.(type) {
-	case *domain.NoAuth:
-		return json.Marshal(map[string]interface{}{})
-	case *domain.BasicAuth:
-		return json.Marshal(map[string]interface{}{
-			"username": a.Username,
-			"password": a.Password,
-		})
-	case *domain.BearerAuth:
-		return json.Marshal(map[string]interface{}{
-			"token": a.Token,
-		})
-	case *domain.APIKeyAuth:
-		return json.Marshal(map[string]interface{}{
-			"key":      a.Key,
-			"value":    a.Value,
-			"location": a.Location,
-		})
-	default:
-		return nil, fmt.Errorf("unsupported auth type: %T", auth)
-	}
-}
-
-// deserializeAuthConfig reconstructs an AuthConfig from JSON.
-func deserializeAuthConfig(authType, configJSON string) (domain.AuthConfig, error) {
-	switch authType {
-	case "", "none":
-		return domain.NewNoAuth(), nil
-	case "basic":
-		var config struct {
-			Username string `json:"username"`
-			Password string `json:"password"`
-		}
-		if err := json.Unmarshal([]byte(configJSON), &config); err != nil {
-			return nil, fmt.Errorf("failed to unmarshal basic auth: %w", err)
-		}
-		return domain.NewBasicAuth(config.Username, config.Password), nil
-	case "bearer":
-		var config struct {
-			Token string `json:"token"`
-		}
-		if err := json.Unmarshal([]byte(configJSON), &config); err != nil {
-			return nil, fmt.Errorf("failed to unmarshal bearer auth: %w", err)
-		}
-		return domain.NewBearerAuth(config.Token), nil
-	case "apikey":
-		var config struct {
-			Key      string                `json:"key"`
-			Value    string                `json:"value"`
-			Location domain.APIKeyLocation `json:"location"`
-		}
-		if err := json.Unmarshal([]byte(configJSON), &config); err != nil {
-			return nil, fmt.Errorf("failed to unmarshal apikey auth: %w", err)
-		}
-		return domain.NewAPIKeyAuth(config.Key, config.Value, config.Location), nil
-	default:
-		return nil, fmt.Errorf("unknown auth type: %s", authType)
-	}
-}
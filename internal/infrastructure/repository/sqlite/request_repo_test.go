@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"maps"
 	"testing"
 	"time"
 
@@ -15,21 +16,12 @@ import (
 func setupTestDB(t *testing.T) *sql.DB {
 	t.Helper()
 
-	db, err := sql.Open("sqlite", ":memory:")
+	// Open applies the connection pragmas and runs migrations.
+	db, err := Open(&Config{Path: ":memory:"})
 	if err != nil {
 		t.Fatalf("failed to open test database: %v", err)
 	}
 
-	// Apply pragmas.
-	if err := applyPragmas(db); err != nil {
-		t.Fatalf("failed to apply pragmas: %v", err)
-	}
-
-	// Run migrations.
-	if err := runMigrations(db, "../../../../migrations"); err != nil {
-		t.Fatalf("failed to run migrations: %v", err)
-	}
-
 	return db
 }
 
@@ -489,6 +481,520 @@ func verifyAuthConfig(t *testing.T, got, expected domain.AuthConfig) {
 	}
 }
 
+func TestRequestRepository_RecordExecution(t *testing.T) {
+	db := setupTestDB(t)
+	defer func() { _ = db.Close() }()
+
+	repo := NewRequestRepository(db)
+	ctx := context.Background()
+
+	createTestRequest(t, ctx, repo, "req-1")
+
+	first := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	second := first.Add(time.Hour)
+	for _, executedAt := range []time.Time{first, second} {
+		if err := repo.RecordExecution(ctx, "req-1", executedAt); err != nil {
+			t.Fatalf("RecordExecution() error = %v", err)
+		}
+	}
+
+	got, err := repo.FindByID(ctx, "req-1")
+	if err != nil {
+		t.Fatalf("FindByID() error = %v", err)
+	}
+	if got.ExecutionCount != 2 {
+		t.Errorf("ExecutionCount = %d, want 2", got.ExecutionCount)
+	}
+	if !got.LastExecutedAt.Equal(second) {
+		t.Errorf("LastExecutedAt = %v, want %v", got.LastExecutedAt, second)
+	}
+
+	if err := repo.RecordExecution(ctx, "missing", first); !errors.Is(err, ErrNotFound) {
+		t.Errorf("RecordExecution(missing) error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestRequestRepository_FindRecentlyUsed(t *testing.T) {
+	db := setupTestDB(t)
+	defer func() { _ = db.Close() }()
+
+	repo := NewRequestRepository(db)
+	ctx := context.Background()
+
+	for _, id := range []string{"never", "old", "recent"} {
+		createTestRequest(t, ctx, repo, id)
+	}
+
+	now := time.Now().UTC()
+	if err := repo.RecordExecution(ctx, "old", now.Add(-time.Hour)); err != nil {
+		t.Fatalf("RecordExecution() error = %v", err)
+	}
+	if err := repo.RecordExecution(ctx, "recent", now); err != nil {
+		t.Fatalf("RecordExecution() error = %v", err)
+	}
+
+	got, err := repo.FindRecentlyUsed(ctx, 0)
+	if err != nil {
+		t.Fatalf("FindRecentlyUsed() error = %v", err)
+	}
+
+	var ids []string
+	for _, req := range got {
+		ids = append(ids, req.ID)
+	}
+	want := []string{"recent", "old", "never"}
+	if fmt.Sprint(ids) != fmt.Sprint(want) {
+		t.Errorf("FindRecentlyUsed() order = %v, want %v", ids, want)
+	}
+
+	limited, err := repo.FindRecentlyUsed(ctx, 1)
+	if err != nil {
+		t.Fatalf("FindRecentlyUsed() error = %v", err)
+	}
+	if len(limited) != 1 || limited[0].ID != "recent" {
+		t.Errorf("FindRecentlyUsed(1) = %v, want [recent]", limited)
+	}
+}
+
+func TestRequestRepository_CreateAppendsToFolder(t *testing.T) {
+	db := setupTestDB(t)
+	defer func() { _ = db.Close() }()
+
+	repo := NewRequestRepository(db)
+	ctx := context.Background()
+
+	first := createTestRequest(t, ctx, repo, "top-1")
+	second := createTestRequest(t, ctx, repo, "top-2")
+
+	other := domain.NewRequestWithMethodAndURL("GET", "https://api.example.com/users")
+	other.ID = "users-1"
+	other.Folder = "users"
+	if err := repo.Create(ctx, other); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	if first.Position != 0 || second.Position != 1 {
+		t.Errorf("top-level positions = %d, %d, want 0, 1", first.Position, second.Position)
+	}
+	if other.Position != 0 {
+		t.Errorf("first request in folder position = %d, want 0", other.Position)
+	}
+
+	got, err := repo.FindByID(ctx, "users-1")
+	if err != nil {
+		t.Fatalf("FindByID() error = %v", err)
+	}
+	if got.Folder != "users" || got.Position != 0 {
+		t.Errorf("FindByID() folder, position = %q, %d, want \"users\", 0", got.Folder, got.Position)
+	}
+}
+
+func TestRequestRepository_Move(t *testing.T) {
+	db := setupTestDB(t)
+	defer func() { _ = db.Close() }()
+
+	repo := NewRequestRepository(db)
+	ctx := context.Background()
+
+	for _, id := range []string{"a", "b", "c", "d"} {
+		createTestRequest(t, ctx, repo, id)
+	}
+
+	folderOrder := func(folder string) string {
+		t.Helper()
+		requests, err := repo.FindByFolder(ctx, folder)
+		if err != nil {
+			t.Fatalf("FindByFolder() error = %v", err)
+		}
+		var ids []string
+		for i, req := range requests {
+			if req.Position != i {
+				t.Errorf("%s has position %d at index %d", req.ID, req.Position, i)
+			}
+			ids = append(ids, req.ID)
+		}
+		return fmt.Sprint(ids)
+	}
+
+	steps := []struct {
+		id       string
+		folder   string
+		position int
+		wantTop  string
+		wantAuth string
+	}{
+		{"d", "", 0, "[d a b c]", "[]"},
+		{"d", "", 99, "[a b c d]", "[]"},
+		{"b", "auth", 0, "[a c d]", "[b]"},
+		{"c", "auth", -1, "[a d]", "[c b]"},
+		{"c", "", 1, "[a c d]", "[b]"},
+	}
+
+	for _, step := range steps {
+		if err := repo.Move(ctx, step.id, step.folder, step.position); err != nil {
+			t.Fatalf("Move(%s, %q, %d) error = %v", step.id, step.folder, step.position, err)
+		}
+		if got := folderOrder(""); got != step.wantTop {
+			t.Errorf("after Move(%s, %q, %d) top level = %s, want %s", step.id, step.folder, step.position, got, step.wantTop)
+		}
+		if got := folderOrder("auth"); got != step.wantAuth {
+			t.Errorf("after Move(%s, %q, %d) auth = %s, want %s", step.id, step.folder, step.position, got, step.wantAuth)
+		}
+	}
+
+	if err := repo.Move(ctx, "missing", "", 0); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Move(missing) error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestRequestRepository_UpdateKeepsPosition(t *testing.T) {
+	db := setupTestDB(t)
+	defer func() { _ = db.Close() }()
+
+	repo := NewRequestRepository(db)
+	ctx := context.Background()
+
+	createTestRequest(t, ctx, repo, "a")
+	req := createTestRequest(t, ctx, repo, "b")
+
+	req.Name = "Renamed"
+	req.Folder = "elsewhere"
+	req.Position = 7
+	if err := repo.Update(ctx, req); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+
+	got, err := repo.FindByID(ctx, "b")
+	if err != nil {
+		t.Fatalf("FindByID() error = %v", err)
+	}
+	if got.Name != "Renamed" || got.Folder != "" || got.Position != 1 {
+		t.Errorf("after Update() name, folder, position = %q, %q, %d, want \"Renamed\", \"\", 1", got.Name, got.Folder, got.Position)
+	}
+}
+
+func TestRequestRepository_ResponseSchema(t *testing.T) {
+	db := setupTestDB(t)
+	defer func() { _ = db.Close() }()
+
+	repo := NewRequestRepository(db)
+	ctx := context.Background()
+
+	req := domain.NewRequestWithMethodAndURL(domain.MethodGet, "https://api.example.com/users")
+	req.ResponseSchema = `{"type":"array"}`
+	if err := repo.Create(ctx, req); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	got, err := repo.FindByID(ctx, req.ID)
+	if err != nil {
+		t.Fatalf("FindByID() error = %v", err)
+	}
+	if got.ResponseSchema != req.ResponseSchema {
+		t.Errorf("ResponseSchema = %q, want %q", got.ResponseSchema, req.ResponseSchema)
+	}
+
+	got.ResponseSchema = ""
+	if err := repo.Update(ctx, got); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	got, err = repo.FindByID(ctx, req.ID)
+	if err != nil {
+		t.Fatalf("FindByID() error = %v", err)
+	}
+	if got.ResponseSchema != "" {
+		t.Errorf("ResponseSchema after clearing = %q, want empty", got.ResponseSchema)
+	}
+}
+
+func TestRequestRepository_ResponseContract(t *testing.T) {
+	db := setupTestDB(t)
+	defer func() { _ = db.Close() }()
+
+	repo := NewRequestRepository(db)
+	ctx := context.Background()
+
+	req := domain.NewRequestWithMethodAndURL(domain.MethodGet, "https://api.example.com/users")
+	if err := repo.Create(ctx, req); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	req.ResponseContract = `{"method":"GET","path":"/users","responses":{"200":{}}}`
+	if err := repo.Update(ctx, req); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	got, err := repo.FindByID(ctx, req.ID)
+	if err != nil {
+		t.Fatalf("FindByID() error = %v", err)
+	}
+	if got.ResponseContract != req.ResponseContract {
+		t.Errorf("ResponseContract = %q, want %q", got.ResponseContract, req.ResponseContract)
+	}
+}
+
+func TestRequestRepository_ResponseFilter(t *testing.T) {
+	db := setupTestDB(t)
+	defer func() { _ = db.Close() }()
+
+	repo := NewRequestRepository(db)
+	ctx := context.Background()
+
+	req := domain.NewRequestWithMethodAndURL(domain.MethodGet, "https://api.example.com/users")
+	req.ResponseFilter = "$.users[*].id"
+	if err := repo.Create(ctx, req); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	got, err := repo.FindByID(ctx, req.ID)
+	if err != nil {
+		t.Fatalf("FindByID() error = %v", err)
+	}
+	if got.ResponseFilter != req.ResponseFilter {
+		t.Errorf("ResponseFilter = %q, want %q", got.ResponseFilter, req.ResponseFilter)
+	}
+
+	got.ResponseFilter = ".users[0]"
+	if err := repo.Update(ctx, got); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	got, err = repo.FindByID(ctx, req.ID)
+	if err != nil {
+		t.Fatalf("FindByID() error = %v", err)
+	}
+	if got.ResponseFilter != ".users[0]" {
+		t.Errorf("ResponseFilter after update = %q, want %q", got.ResponseFilter, ".users[0]")
+	}
+}
+
+func TestRequestRepository_Scripts(t *testing.T) {
+	db := setupTestDB(t)
+	defer func() { _ = db.Close() }()
+
+	repo := NewRequestRepository(db)
+	ctx := context.Background()
+
+	req := domain.NewRequestWithMethodAndURL(domain.MethodGet, "https://api.example.com/users")
+	req.PreRequestScript = `request.headers["X-Id"] = crypto.randomUUID();`
+	if err := repo.Create(ctx, req); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	got, err := repo.FindByID(ctx, req.ID)
+	if err != nil {
+		t.Fatalf("FindByID() error = %v", err)
+	}
+	if got.PreRequestScript != req.PreRequestScript || got.PostResponseScript != "" {
+		t.Errorf("scripts = %q, %q, want %q, %q", got.PreRequestScript, got.PostResponseScript, req.PreRequestScript, "")
+	}
+
+	got.PreRequestScript = ""
+	got.PostResponseScript = "assert(response.status === 200);"
+	if err := repo.Update(ctx, got); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	got, err = repo.FindByID(ctx, req.ID)
+	if err != nil {
+		t.Fatalf("FindByID() error = %v", err)
+	}
+	if got.PreRequestScript != "" || got.PostResponseScript != "assert(response.status === 200);" {
+		t.Errorf("scripts after update = %q, %q", got.PreRequestScript, got.PostResponseScript)
+	}
+}
+
+func TestRequestRepository_DisabledQueryParams(t *testing.T) {
+	db := setupTestDB(t)
+	defer func() { _ = db.Close() }()
+
+	repo := NewRequestRepository(db)
+	ctx := context.Background()
+
+	req := domain.NewRequestWithMethodAndURL(domain.MethodGet, "https://api.example.com/users")
+	req.QueryParams = map[string]string{"page": "2"}
+	req.DisabledQueryParams = map[string]string{"debug": "true"}
+	if err := repo.Create(ctx, req); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	got, err := repo.FindByID(ctx, req.ID)
+	if err != nil {
+		t.Fatalf("FindByID() error = %v", err)
+	}
+	if !maps.Equal(got.QueryParams, req.QueryParams) || !maps.Equal(got.DisabledQueryParams, req.DisabledQueryParams) {
+		t.Errorf("query params = %v, disabled %v; want %v, disabled %v", got.QueryParams, got.DisabledQueryParams, req.QueryParams, req.DisabledQueryParams)
+	}
+
+	got.DisabledQueryParams = nil
+	if err := repo.Update(ctx, got); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	got, err = repo.FindByID(ctx, req.ID)
+	if err != nil {
+		t.Fatalf("FindByID() error = %v", err)
+	}
+	if got.DisabledQueryParams != nil {
+		t.Errorf("DisabledQueryParams after update = %v, want none", got.DisabledQueryParams)
+	}
+}
+
+func TestRequestRepository_Tags(t *testing.T) {
+	db := setupTestDB(t)
+	defer func() { _ = db.Close() }()
+
+	repo := NewRequestRepository(db)
+	ctx := context.Background()
+
+	req := domain.NewRequestWithMethodAndURL(domain.MethodGet, "https://api.example.com/users")
+	req.Tags = []string{"users", "smoke", "users"}
+	if err := repo.Create(ctx, req); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	got, err := repo.FindByID(ctx, req.ID)
+	if err != nil {
+		t.Fatalf("FindByID() error = %v", err)
+	}
+	if fmt.Sprint(got.Tags) != "[smoke users]" {
+		t.Errorf("Tags = %v, want [smoke users]", got.Tags)
+	}
+
+	got.Tags = nil
+	if err := repo.Update(ctx, got); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	got, err = repo.FindByID(ctx, req.ID)
+	if err != nil {
+		t.Fatalf("FindByID() error = %v", err)
+	}
+	if got.Tags != nil {
+		t.Errorf("Tags after clearing = %v, want nil", got.Tags)
+	}
+}
+
+func TestRequestRepository_DeleteMany(t *testing.T) {
+	db := setupTestDB(t)
+	defer func() { _ = db.Close() }()
+
+	repo := NewRequestRepository(db)
+	ctx := context.Background()
+
+	for _, id := range []string{"a", "b", "c"} {
+		createTestRequest(t, ctx, repo, id)
+	}
+
+	// A missing request rolls back the whole delete.
+	if err := repo.DeleteMany(ctx, []string{"a", "missing"}); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("DeleteMany(a, missing) error = %v, want ErrNotFound", err)
+	}
+	if _, err := repo.FindByID(ctx, "a"); err != nil {
+		t.Errorf("FindByID(a) after failed DeleteMany error = %v, want nil", err)
+	}
+
+	if err := repo.DeleteMany(ctx, []string{"a", "c", "a"}); err != nil {
+		t.Fatalf("DeleteMany(a, c, a) error = %v", err)
+	}
+	all, err := repo.FindAll(ctx)
+	if err != nil {
+		t.Fatalf("FindAll() error = %v", err)
+	}
+	if len(all) != 1 || all[0].ID != "b" {
+		t.Errorf("after DeleteMany() requests = %v, want only b", all)
+	}
+}
+
+func TestRequestRepository_MoveMany(t *testing.T) {
+	db := setupTestDB(t)
+	defer func() { _ = db.Close() }()
+
+	repo := NewRequestRepository(db)
+	ctx := context.Background()
+
+	for _, id := range []string{"a", "b", "c", "d"} {
+		createTestRequest(t, ctx, repo, id)
+	}
+	if err := repo.Move(ctx, "d", "auth", 0); err != nil {
+		t.Fatalf("Move() error = %v", err)
+	}
+
+	folderOrder := func(folder string) string {
+		t.Helper()
+		requests, err := repo.FindByFolder(ctx, folder)
+		if err != nil {
+			t.Fatalf("FindByFolder() error = %v", err)
+		}
+		var ids []string
+		for i, req := range requests {
+			if req.Position != i {
+				t.Errorf("%s has position %d at index %d", req.ID, req.Position, i)
+			}
+			ids = append(ids, req.ID)
+		}
+		return fmt.Sprint(ids)
+	}
+
+	if err := repo.MoveMany(ctx, []string{"c", "a"}, "auth"); err != nil {
+		t.Fatalf("MoveMany() error = %v", err)
+	}
+	if got := folderOrder(""); got != "[b]" {
+		t.Errorf("top level = %s, want [b]", got)
+	}
+	if got := folderOrder("auth"); got != "[d c a]" {
+		t.Errorf("auth = %s, want [d c a]", got)
+	}
+
+	// Requests already in the folder move to its end.
+	if err := repo.MoveMany(ctx, []string{"d", "b"}, "auth"); err != nil {
+		t.Fatalf("MoveMany() error = %v", err)
+	}
+	if got := folderOrder("auth"); got != "[c a d b]" {
+		t.Errorf("auth = %s, want [c a d b]", got)
+	}
+
+	if err := repo.MoveMany(ctx, []string{"a", "missing"}, ""); !errors.Is(err, ErrNotFound) {
+		t.Errorf("MoveMany(a, missing) error = %v, want ErrNotFound", err)
+	}
+	if got := folderOrder("auth"); got != "[c a d b]" {
+		t.Errorf("auth after failed MoveMany = %s, want [c a d b]", got)
+	}
+}
+
+func TestRequestRepository_Retag(t *testing.T) {
+	db := setupTestDB(t)
+	defer func() { _ = db.Close() }()
+
+	repo := NewRequestRepository(db)
+	ctx := context.Background()
+
+	a := createTestRequest(t, ctx, repo, "a")
+	a.Tags = []string{"legacy", "users"}
+	if err := repo.Update(ctx, a); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	createTestRequest(t, ctx, repo, "b")
+
+	if err := repo.Retag(ctx, []string{"a", "b"}, []string{"smoke"}, []string{"legacy"}); err != nil {
+		t.Fatalf("Retag() error = %v", err)
+	}
+
+	want := map[string]string{"a": "[smoke users]", "b": "[smoke]"}
+	for id, tags := range want {
+		got, err := repo.FindByID(ctx, id)
+		if err != nil {
+			t.Fatalf("FindByID(%s) error = %v", id, err)
+		}
+		if fmt.Sprint(got.Tags) != tags {
+			t.Errorf("%s tags = %v, want %s", id, got.Tags, tags)
+		}
+	}
+
+	if err := repo.Retag(ctx, []string{"a", "missing"}, []string{"broken"}, nil); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Retag(a, missing) error = %v, want ErrNotFound", err)
+	}
+	got, err := repo.FindByID(ctx, "a")
+	if err != nil {
+		t.Fatalf("FindByID(a) error = %v", err)
+	}
+	if got.HasTag("broken") {
+		t.Errorf("failed Retag() changed tags to %v", got.Tags)
+	}
+}
+
 // verifyBasicAuth verifies BasicAuth credentials.
 func verifyBasicAuth(t *testing.T, got domain.AuthConfig, expected *domain.BasicAuth) {
 	t.Helper()
@@ -0,0 +1,80 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/williajm/curly/internal/domain"
+)
+
+// SessionRepository implements repository.SessionRepository using SQLite.
+type SessionRepository struct {
+	db *sql.DB
+}
+
+// NewSessionRepository creates a new SQLite-backed session repository.
+func NewSessionRepository(db *sql.DB) *SessionRepository {
+	return &SessionRepository{db: db}
+}
+
+// Save replaces the session.
+func (r *SessionRepository) Save(ctx context.Context, session *domain.Session) error {
+	if session == nil {
+		return fmt.Errorf("session cannot be nil")
+	}
+
+	query := `
+		INSERT INTO session (id, request_id, tab, response_offset, history_index, updated_at)
+		VALUES (1, ?, ?, ?, ?, ?)
+		ON CONFLICT (id) DO UPDATE SET
+			request_id = excluded.request_id,
+			tab = excluded.tab,
+			response_offset = excluded.response_offset,
+			history_index = excluded.history_index,
+			updated_at = excluded.updated_at
+	`
+	_, err := r.db.ExecContext(ctx, query,
+		session.RequestID,
+		session.Tab,
+		session.ResponseOffset,
+		session.HistoryIndex,
+		session.UpdatedAt.UTC().Format(time.RFC3339),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save session: %w", err)
+	}
+
+	return nil
+}
+
+// Find retrieves the session.
+func (r *SessionRepository) Find(ctx context.Context) (*domain.Session, error) {
+	var (
+		session   domain.Session
+		updatedAt string
+	)
+
+	query := `SELECT request_id, tab, response_offset, history_index, updated_at FROM session WHERE id = 1`
+	err := r.db.QueryRowContext(ctx, query).Scan(
+		&session.RequestID,
+		&session.Tab,
+		&session.ResponseOffset,
+		&session.HistoryIndex,
+		&updatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to find session: %w", err)
+	}
+
+	if session.UpdatedAt, err = time.Parse(time.RFC3339, updatedAt); err != nil {
+		return nil, fmt.Errorf("failed to parse updated_at: %w", err)
+	}
+
+	return &session, nil
+}
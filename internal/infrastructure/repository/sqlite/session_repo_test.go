@@ -0,0 +1,62 @@
+package sqlite
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/williajm/curly/internal/domain"
+)
+
+func TestSessionRepository_SaveAndFind(t *testing.T) {
+	db := setupTestDB(t)
+	defer func() { _ = db.Close() }()
+
+	repo := NewSessionRepository(db)
+	ctx := context.Background()
+
+	if _, err := repo.Find(ctx); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Find() before saving error = %v, want ErrNotFound", err)
+	}
+
+	now := time.Now().Truncate(time.Second)
+	if err := repo.Save(ctx, &domain.Session{RequestID: "req-1", Tab: "Response", ResponseOffset: 40, UpdatedAt: now.Add(-time.Hour)}); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	// Saving again replaces the session.
+	want := domain.Session{RequestID: "req-2", Tab: "History", ResponseOffset: 12, HistoryIndex: 7, UpdatedAt: now}
+	if err := repo.Save(ctx, &want); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	got, err := repo.Find(ctx)
+	if err != nil {
+		t.Fatalf("Find() error = %v", err)
+	}
+	if got.RequestID != want.RequestID || got.Tab != want.Tab ||
+		got.ResponseOffset != want.ResponseOffset || got.HistoryIndex != want.HistoryIndex {
+		t.Errorf("Find() = %+v, want %+v", got, want)
+	}
+	if !got.UpdatedAt.Equal(now) {
+		t.Errorf("UpdatedAt = %v, want %v", got.UpdatedAt, now)
+	}
+
+	var count int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM session`).Scan(&count); err != nil {
+		t.Fatalf("failed to count sessions: %v", err)
+	}
+	if count != 1 {
+		t.Errorf("session table has %d rows, want 1", count)
+	}
+}
+
+func TestSessionRepository_SaveNil(t *testing.T) {
+	db := setupTestDB(t)
+	defer func() { _ = db.Close() }()
+
+	if err := NewSessionRepository(db).Save(context.Background(), nil); err == nil {
+		t.Error("Save(nil) error = nil, want an error")
+	}
+}
@@ -0,0 +1,139 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/williajm/curly/internal/infrastructure/repository"
+)
+
+// failureCondition matches history rows that did not succeed.
+const failureCondition = `(error IS NOT NULL OR status_code IS NULL OR status_code < 200 OR status_code >= 400 OR assertion_failures IS NOT NULL)`
+
+// statsQuery aggregates history in a single pass over the table, grouped by
+// the rid column of the keyed CTE that keys defines. Percentiles use the
+// nearest-rank method: the smallest value whose rank is at least p * n among
+// executions that received a response.
+func statsQuery(keys string) string {
+	return `
+	WITH ` + keys + `,
+	ranked AS (
+		SELECT
+			rid,
+			response_time_ms,
+			ROW_NUMBER() OVER (PARTITION BY rid ORDER BY response_time_ms) AS rn,
+			COUNT(*) OVER (PARTITION BY rid) AS n
+		FROM keyed
+		WHERE error IS NULL
+	),
+	latency AS (
+		SELECT
+			rid,
+			MIN(CASE WHEN rn >= n * 0.50 THEN response_time_ms END) AS p50,
+			MIN(CASE WHEN rn >= n * 0.95 THEN response_time_ms END) AS p95,
+			MIN(CASE WHEN rn >= n * 0.99 THEN response_time_ms END) AS p99
+		FROM ranked
+		GROUP BY rid
+	),
+	totals AS (
+		SELECT
+			rid,
+			COUNT(*) AS total,
+			SUM(CASE WHEN ` + failureCondition + ` THEN 0 ELSE 1 END) AS successes,
+			MAX(CASE WHEN ` + failureCondition + ` THEN executed_at END) AS last_failure_at
+		FROM keyed
+		GROUP BY rid
+	)
+	SELECT
+		t.rid,
+		t.total,
+		t.successes,
+		COALESCE(l.p50, 0),
+		COALESCE(l.p95, 0),
+		COALESCE(l.p99, 0),
+		COALESCE(t.last_failure_at, ''),
+		COALESCE((
+			SELECT COALESCE(h.error, CASE WHEN h.assertion_failures IS NOT NULL THEN 'assertions failed' END, h.status, '')
+			FROM keyed h
+			WHERE h.rid = t.rid AND h.executed_at = t.last_failure_at AND ` + failureCondition + `
+			LIMIT 1
+		), '')
+	FROM totals t
+	LEFT JOIN latency l ON l.rid = t.rid
+	ORDER BY t.total DESC, t.rid
+`
+}
+
+// requestKeys keys history by request, with ad-hoc executions under an empty key.
+const requestKeys = `keyed AS (SELECT history.*, COALESCE(request_id, '') AS rid FROM history)`
+
+// hostKeys keys history by the host of the URL in the request snapshot, in
+// lowercase and with any port: the text between "://" and the path, query
+// or fragment, less any user info. Entries without a snapshot go under an
+// empty key.
+const hostKeys = `urls AS (
+		SELECT history.*,
+			replace(replace(CASE
+				WHEN instr(json_extract(request_snapshot, '$.url'), '://') > 0
+				THEN substr(json_extract(request_snapshot, '$.url'), instr(json_extract(request_snapshot, '$.url'), '://') + 3)
+				ELSE COALESCE(json_extract(request_snapshot, '$.url'), '')
+			END, '?', '/'), '#', '/') || '/' AS rest
+		FROM history
+	),
+	authorities AS (
+		SELECT urls.*, substr(rest, 1, instr(rest, '/') - 1) AS authority FROM urls
+	),
+	keyed AS (
+		SELECT authorities.*, lower(substr(authority, instr(authority, '@') + 1)) AS rid FROM authorities
+	)`
+
+// Stats aggregates history per request using SQL window functions.
+func (r *HistoryRepository) Stats(ctx context.Context) ([]*repository.RequestStats, error) {
+	return r.stats(ctx, requestKeys)
+}
+
+// HostStats aggregates history per host using SQL window functions.
+func (r *HistoryRepository) HostStats(ctx context.Context) ([]*repository.HostStats, error) {
+	stats, err := r.stats(ctx, hostKeys)
+	if err != nil {
+		return nil, err
+	}
+	hosts := make([]*repository.HostStats, len(stats))
+	for i, s := range stats {
+		hosts[i] = &repository.HostStats{
+			Host:          s.RequestID,
+			Count:         s.Count,
+			SuccessCount:  s.SuccessCount,
+			P50Ms:         s.P50Ms,
+			P95Ms:         s.P95Ms,
+			P99Ms:         s.P99Ms,
+			LastFailureAt: s.LastFailureAt,
+			LastFailure:   s.LastFailure,
+		}
+	}
+	return hosts, nil
+}
+
+// stats aggregates history by the key keys defines.
+func (r *HistoryRepository) stats(ctx context.Context, keys string) ([]*repository.RequestStats, error) {
+	rows, err := r.db.QueryContext(ctx, statsQuery(keys))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query history stats: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var stats []*repository.RequestStats
+	for rows.Next() {
+		s := &repository.RequestStats{}
+		if err := rows.Scan(&s.RequestID, &s.Count, &s.SuccessCount, &s.P50Ms, &s.P95Ms, &s.P99Ms, &s.LastFailureAt, &s.LastFailure); err != nil {
+			return nil, fmt.Errorf("failed to scan history stats: %w", err)
+		}
+		stats = append(stats, s)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+
+	return stats, nil
+}
@@ -0,0 +1,49 @@
+package repository
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/williajm/curly/internal/domain"
+)
+
+// MarshalTags serializes request tags as a JSON array, normalized with
+// domain.NormalizeTags. No tags are stored as "[]".
+func MarshalTags(tags []string) string {
+	tags = domain.NormalizeTags(tags)
+	if tags == nil {
+		return "[]"
+	}
+	data, _ := json.Marshal(tags) // A string slice always marshals.
+	return string(data)
+}
+
+// UnmarshalTags reconstructs tags stored by MarshalTags. It returns nil if
+// there are none.
+func UnmarshalTags(data string) ([]string, error) {
+	if data == "" {
+		return nil, nil
+	}
+	var tags []string
+	if err := json.Unmarshal([]byte(data), &tags); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal tags: %w", err)
+	}
+	return domain.NormalizeTags(tags), nil
+}
+
+// Retag returns tags with add added and remove removed. A tag in both is
+// removed. The result is normalized with domain.NormalizeTags.
+func Retag(tags, add, remove []string) []string {
+	drop := make(map[string]bool, len(remove))
+	for _, tag := range domain.NormalizeTags(remove) {
+		drop[tag] = true
+	}
+
+	var kept []string
+	for _, tag := range domain.NormalizeTags(append(append([]string{}, tags...), add...)) {
+		if !drop[tag] {
+			kept = append(kept, tag)
+		}
+	}
+	return kept
+}
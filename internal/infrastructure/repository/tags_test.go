@@ -0,0 +1,49 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTags_RoundTrip(t *testing.T) {
+	assert.Equal(t, "[]", MarshalTags(nil))
+	assert.Equal(t, `["auth","smoke"]`, MarshalTags([]string{"smoke", "auth", "smoke"}))
+
+	tags, err := UnmarshalTags(`["smoke","auth"]`)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"auth", "smoke"}, tags)
+
+	for _, empty := range []string{"", "[]"} {
+		tags, err := UnmarshalTags(empty)
+		require.NoError(t, err)
+		assert.Nil(t, tags)
+	}
+
+	_, err = UnmarshalTags("smoke")
+	assert.ErrorContains(t, err, "failed to unmarshal tags")
+}
+
+func TestRetag(t *testing.T) {
+	tests := []struct {
+		name   string
+		tags   []string
+		add    []string
+		remove []string
+		want   []string
+	}{
+		{"add", []string{"users"}, []string{"smoke"}, nil, []string{"smoke", "users"}},
+		{"add existing", []string{"users"}, []string{"users"}, nil, []string{"users"}},
+		{"remove", []string{"smoke", "users"}, nil, []string{"smoke"}, []string{"users"}},
+		{"remove missing", []string{"users"}, nil, []string{"smoke"}, []string{"users"}},
+		{"remove wins", nil, []string{"smoke"}, []string{" smoke"}, nil},
+		{"remove all", []string{"users"}, nil, []string{"users"}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Retag(tt.tags, tt.add, tt.remove))
+		})
+	}
+}
@@ -0,0 +1,45 @@
+package repository
+
+import "sort"
+
+// RequestUsage is the usage metadata a batch of history entries adds to one request.
+type RequestUsage struct {
+	RequestID string
+
+	// Count is the number of entries for the request.
+	Count int64
+
+	// LastExecutedAt is the latest ExecutedAt among those entries (RFC3339).
+	LastExecutedAt string
+}
+
+// SummarizeUsage groups entries by request so backends can update each
+// request's usage once per batch. Entries without a RequestID are skipped.
+// Results are ordered by RequestID so concurrent batches lock rows in the same order.
+func SummarizeUsage(entries []*HistoryEntry) []RequestUsage {
+	byRequest := map[string]*RequestUsage{}
+
+	for _, entry := range entries {
+		if entry == nil || entry.RequestID == "" {
+			continue
+		}
+
+		u, ok := byRequest[entry.RequestID]
+		if !ok {
+			u = &RequestUsage{RequestID: entry.RequestID}
+			byRequest[entry.RequestID] = u
+		}
+		u.Count++
+		if entry.ExecutedAt > u.LastExecutedAt {
+			u.LastExecutedAt = entry.ExecutedAt
+		}
+	}
+
+	usage := make([]RequestUsage, 0, len(byRequest))
+	for _, u := range byRequest {
+		usage = append(usage, *u)
+	}
+	sort.Slice(usage, func(i, j int) bool { return usage[i].RequestID < usage[j].RequestID })
+
+	return usage
+}
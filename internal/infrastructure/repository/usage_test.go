@@ -0,0 +1,25 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSummarizeUsage(t *testing.T) {
+	entries := []*HistoryEntry{
+		{RequestID: "b", ExecutedAt: "2025-01-01T10:00:00Z"},
+		{RequestID: "a", ExecutedAt: "2025-01-01T09:00:00Z"},
+		{RequestID: "b", ExecutedAt: "2025-01-01T11:00:00Z"},
+		{RequestID: "b", ExecutedAt: "2025-01-01T08:00:00Z"},
+		{RequestID: "", ExecutedAt: "2025-01-01T12:00:00Z"},
+		nil,
+	}
+
+	got := SummarizeUsage(entries)
+
+	assert.Equal(t, []RequestUsage{
+		{RequestID: "a", Count: 1, LastExecutedAt: "2025-01-01T09:00:00Z"},
+		{RequestID: "b", Count: 3, LastExecutedAt: "2025-01-01T11:00:00Z"},
+	}, got)
+}
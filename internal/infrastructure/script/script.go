@@ -0,0 +1,371 @@
+// Package script runs the JavaScript pre-request and post-response scripts
+// saved with requests, in an embedded interpreter without access to files,
+// the network or the environment.
+//
+// A script sees the request as request, with its method, url, headers, query
+// and body, and after the response, the response as response, with its
+// status, statusText, headers, body, time in milliseconds and json(). It can
+// also use:
+//
+//	vars.get(name), vars.set(name, value), vars.unset(name)
+//	assert(condition, message)                       // post-response only
+//	crypto.sha256(data), crypto.md5(data), crypto.hmacSha256(key, data)
+//	crypto.randomUUID(), btoa(text), atob(base64), console.log(...)
+//
+// The digests return hex, or base64 when "base64" is passed as their last
+// argument.
+package script
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/md5" // #nosec G501 -- Offered to scripts for legacy signing schemes.
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dop251/goja"
+	"github.com/google/uuid"
+
+	"github.com/williajm/curly/internal/domain"
+)
+
+// DefaultTimeout is how long a script may run.
+const DefaultTimeout = 5 * time.Second
+
+// Names of the scripts, as they appear in errors.
+const (
+	PreRequest   = "pre-request script"
+	PostResponse = "post-response script"
+)
+
+// Result is what a script did.
+type Result struct {
+	// Request is the request as the pre-request script left it. It is nil
+	// after the response.
+	Request *domain.Request
+
+	// Variables are the variables the script set, with nil for those it
+	// unset.
+	Variables map[string]*string
+
+	// AssertionFailures are the messages of the assertions that failed.
+	AssertionFailures []string
+
+	// Logs are the lines the script logged with console.log.
+	Logs []string
+}
+
+// Compile checks the syntax of source, named name in errors. An empty script
+// is valid.
+func Compile(name, source string) error {
+	if strings.TrimSpace(source) == "" {
+		return nil
+	}
+	if _, err := goja.Compile(name, source, false); err != nil {
+		return fmt.Errorf("invalid %s: %w", name, err)
+	}
+	return nil
+}
+
+// RunPreRequest runs source before req is sent, with vars as the variables
+// in effect. The request it returns is a copy of req with the script's
+// changes; req itself is left as it is.
+func RunPreRequest(ctx context.Context, source string, req *domain.Request, vars map[string]string) (*Result, error) {
+	s := newSession(vars)
+	if err := s.setRequest(req); err != nil {
+		return nil, err
+	}
+	if err := s.run(ctx, PreRequest, source); err != nil {
+		return nil, err
+	}
+	changed, err := s.request(req)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", PreRequest, err)
+	}
+	s.result.Request = changed
+	return &s.result, nil
+}
+
+// RunPostResponse runs source once resp, the response to req, is received,
+// with vars as the variables in effect. Changes to the request and response
+// are ignored.
+func RunPostResponse(ctx context.Context, source string, req *domain.Request, resp *domain.Response, vars map[string]string) (*Result, error) {
+	s := newSession(vars)
+	if err := s.setRequest(req); err != nil {
+		return nil, err
+	}
+	if err := s.setResponse(resp); err != nil {
+		return nil, err
+	}
+	s.define("assert", func(call goja.FunctionCall) goja.Value {
+		if !call.Argument(0).ToBoolean() {
+			message := "assertion failed"
+			if m := call.Argument(1); !goja.IsUndefined(m) {
+				message = m.String()
+			}
+			s.result.AssertionFailures = append(s.result.AssertionFailures, message)
+		}
+		return goja.Undefined()
+	})
+	if err := s.run(ctx, PostResponse, source); err != nil {
+		return nil, err
+	}
+	return &s.result, nil
+}
+
+// session is the interpreter of one script run and what the script did.
+type session struct {
+	vm     *goja.Runtime
+	vars   map[string]string
+	result Result
+}
+
+func newSession(vars map[string]string) *session {
+	s := &session{vm: goja.New(), vars: make(map[string]string, len(vars))}
+	for name, value := range vars {
+		s.vars[name] = value
+	}
+	s.result.Variables = map[string]*string{}
+
+	s.define("vars", map[string]any{
+		"get": func(name string) goja.Value {
+			if value, ok := s.vars[name]; ok {
+				return s.vm.ToValue(value)
+			}
+			return goja.Undefined()
+		},
+		"set": func(name string, value goja.Value) {
+			if goja.IsUndefined(value) || goja.IsNull(value) {
+				s.unset(name)
+				return
+			}
+			v := value.String()
+			s.vars[name] = v
+			s.result.Variables[name] = &v
+		},
+		"unset": s.unset,
+	})
+	s.define("console", map[string]any{
+		"log": func(call goja.FunctionCall) goja.Value {
+			parts := make([]string, len(call.Arguments))
+			for i, arg := range call.Arguments {
+				parts[i] = s.format(arg)
+			}
+			s.result.Logs = append(s.result.Logs, strings.Join(parts, " "))
+			return goja.Undefined()
+		},
+	})
+	s.define("crypto", map[string]any{
+		"sha256": func(data string, encoding ...string) string {
+			return digest(sha256.New(), data, encoding)
+		},
+		"md5": func(data string, encoding ...string) string {
+			return digest(md5.New(), data, encoding) // #nosec G401 -- See the import.
+		},
+		"hmacSha256": func(key, data string, encoding ...string) string {
+			return digest(hmac.New(sha256.New, []byte(key)), data, encoding)
+		},
+		"randomUUID": func() string { return uuid.NewString() },
+	})
+	s.define("btoa", func(text string) string {
+		return base64.StdEncoding.EncodeToString([]byte(text))
+	})
+	s.define("atob", func(encoded string) string {
+		decoded, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			panic(s.vm.NewTypeError("atob: %v", err))
+		}
+		return string(decoded)
+	})
+	return s
+}
+
+// define makes value a global of the script.
+func (s *session) define(name string, value any) {
+	// Set only fails for names that cannot be assigned, which these are not.
+	_ = s.vm.Set(name, value)
+}
+
+func (s *session) unset(name string) {
+	delete(s.vars, name)
+	s.result.Variables[name] = nil
+}
+
+// run runs source, named name in errors, stopping it if it runs past
+// DefaultTimeout or the deadline of ctx, or ctx is canceled.
+func (s *session) run(ctx context.Context, name, source string) error {
+	program, err := goja.Compile(name, source, false)
+	if err != nil {
+		return fmt.Errorf("invalid %s: %w", name, err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, DefaultTimeout)
+	defer cancel()
+	stop := context.AfterFunc(ctx, func() { s.vm.Interrupt(ctx.Err()) })
+	defer stop()
+
+	if _, err := s.vm.RunProgram(program); err != nil {
+		var interrupted *goja.InterruptedError
+		if errors.As(err, &interrupted) {
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				return fmt.Errorf("%s timed out", name)
+			}
+			return fmt.Errorf("%s: %w", name, ctx.Err())
+		}
+		var exception *goja.Exception
+		if errors.As(err, &exception) {
+			return fmt.Errorf("%s failed: %s", name, exception.Error())
+		}
+		return fmt.Errorf("%s failed: %w", name, err)
+	}
+	return nil
+}
+
+// scriptRequest is the JSON form of the request a script sees.
+type scriptRequest struct {
+	Name    string         `json:"name"`
+	Method  string         `json:"method"`
+	URL     string         `json:"url"`
+	Headers map[string]any `json:"headers"`
+	Query   map[string]any `json:"query"`
+	Body    string         `json:"body"`
+}
+
+func (s *session) setRequest(req *domain.Request) error {
+	return s.setJSON("request", scriptRequest{
+		Name:    req.Name,
+		Method:  req.Method,
+		URL:     req.URL,
+		Headers: anyValues(req.Headers),
+		Query:   anyValues(req.QueryParams),
+		Body:    req.Body,
+	})
+}
+
+func (s *session) setResponse(resp *domain.Response) error {
+	if err := s.setJSON("response", map[string]any{
+		"status":     resp.StatusCode,
+		"statusText": resp.Status,
+		"headers":    anyValues(resp.Headers),
+		"body":       resp.Body,
+		"time":       resp.DurationMillis(),
+	}); err != nil {
+		return err
+	}
+	response := s.vm.Get("response").ToObject(s.vm)
+	return response.Set("json", func() goja.Value {
+		value, err := s.parseJSON(resp.Body)
+		if err != nil {
+			panic(s.vm.NewTypeError("response body is not JSON: %v", err))
+		}
+		return value
+	})
+}
+
+// setJSON makes value, as JSON, the global name, so that the script sees
+// plain objects it can change freely.
+func (s *session) setJSON(name string, value any) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s for script: %w", name, err)
+	}
+	parsed, err := s.parseJSON(string(data))
+	if err != nil {
+		return fmt.Errorf("failed to pass %s to script: %w", name, err)
+	}
+	s.define(name, parsed)
+	return nil
+}
+
+// parseJSON parses text with the script's JSON.parse.
+func (s *session) parseJSON(text string) (goja.Value, error) {
+	parse, _ := goja.AssertFunction(s.vm.Get("JSON").ToObject(s.vm).Get("parse"))
+	return parse(goja.Undefined(), s.vm.ToValue(text))
+}
+
+// request returns a copy of req with the changes the script made to it.
+func (s *session) request(req *domain.Request) (*domain.Request, error) {
+	stringify, _ := goja.AssertFunction(s.vm.Get("JSON").ToObject(s.vm).Get("stringify"))
+	data, err := stringify(goja.Undefined(), s.vm.Get("request"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read request: %w", err)
+	}
+	var changed scriptRequest
+	if err := json.Unmarshal([]byte(data.String()), &changed); err != nil {
+		return nil, fmt.Errorf("request is no longer an object of method, url, headers, query and body: %w", err)
+	}
+
+	result := req.Clone()
+	result.Method = strings.ToUpper(changed.Method)
+	result.URL = changed.URL
+	result.Headers = stringValues(changed.Headers)
+	result.QueryParams = stringValues(changed.Query)
+	result.Body = changed.Body
+	if err := result.Validate(); err != nil {
+		return nil, fmt.Errorf("request is invalid: %w", err)
+	}
+	return result, nil
+}
+
+// format renders a value logged with console.log: strings as they are and
+// anything else as JSON where possible.
+func (s *session) format(value goja.Value) string {
+	if goja.IsUndefined(value) || goja.IsNull(value) {
+		return value.String()
+	}
+	if _, ok := value.Export().(string); ok {
+		return value.String()
+	}
+	stringify, _ := goja.AssertFunction(s.vm.Get("JSON").ToObject(s.vm).Get("stringify"))
+	if data, err := stringify(goja.Undefined(), value); err == nil && !goja.IsUndefined(data) {
+		return data.String()
+	}
+	return value.String()
+}
+
+// digest returns the hash of data as hex, or as base64 if encoding is
+// "base64".
+func digest(h hash.Hash, data string, encoding []string) string {
+	h.Write([]byte(data))
+	sum := h.Sum(nil)
+	if len(encoding) > 0 && encoding[0] == "base64" {
+		return base64.StdEncoding.EncodeToString(sum)
+	}
+	return hex.EncodeToString(sum)
+}
+
+func anyValues(values map[string]string) map[string]any {
+	result := make(map[string]any, len(values))
+	for name, value := range values {
+		result[name] = value
+	}
+	return result
+}
+
+// stringValues converts the headers or query parameters a script left to
+// strings. Null values are dropped, and numbers and booleans are written as
+// JavaScript writes them.
+func stringValues(values map[string]any) map[string]string {
+	result := make(map[string]string, len(values))
+	for name, value := range values {
+		switch value := value.(type) {
+		case nil:
+		case string:
+			result[name] = value
+		case float64:
+			result[name] = strconv.FormatFloat(value, 'f', -1, 64)
+		default:
+			data, _ := json.Marshal(value)
+			result[name] = string(data)
+		}
+	}
+	return result
+}
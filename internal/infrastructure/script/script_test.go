@@ -0,0 +1,159 @@
+package script
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/williajm/curly/internal/domain"
+)
+
+func newRequest() *domain.Request {
+	req := domain.NewRequestWithMethodAndURL("POST", "https://api.example.com/orders")
+	req.Headers["Content-Type"] = "application/json"
+	req.Headers["X-Debug"] = "1"
+	req.QueryParams["page"] = "2"
+	req.Body = `{"id":7}`
+	return req
+}
+
+func TestCompile(t *testing.T) {
+	assert.NoError(t, Compile(PreRequest, ""))
+	assert.NoError(t, Compile(PreRequest, "request.headers['X-Id'] = crypto.randomUUID();"))
+
+	err := Compile(PreRequest, "if (request {")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid pre-request script")
+}
+
+func TestRunPreRequest(t *testing.T) {
+	source := `
+		const signature = crypto.hmacSha256(vars.get("secret"), request.method + request.url + request.body);
+		request.headers["X-Signature"] = signature;
+		request.headers["X-Count"] = 3;
+		delete request.headers["X-Debug"];
+		request.query.ts = "1700000000";
+		request.method = "put";
+		vars.set("lastSignature", signature);
+		vars.unset("secret");
+		console.log("signed", {method: request.method});
+	`
+	req := newRequest()
+	result, err := RunPreRequest(context.Background(), source, req, map[string]string{"secret": "k"})
+	require.NoError(t, err)
+
+	changed := result.Request
+	assert.Equal(t, "PUT", changed.Method)
+	assert.Equal(t, "cce0f425b86b48b7ca136e352a9e80318aafe4679b188e831123d1ecbfbacfe8", changed.Headers["X-Signature"])
+	assert.Equal(t, "3", changed.Headers["X-Count"])
+	assert.NotContains(t, changed.Headers, "X-Debug")
+	assert.Equal(t, map[string]string{"page": "2", "ts": "1700000000"}, changed.QueryParams)
+	assert.Equal(t, req.ID, changed.ID)
+
+	signature := changed.Headers["X-Signature"]
+	assert.Equal(t, map[string]*string{"lastSignature": &signature, "secret": nil}, result.Variables)
+	assert.Equal(t, []string{`signed {"method":"put"}`}, result.Logs)
+
+	// The original request is left as it was.
+	assert.Equal(t, "POST", req.Method)
+	assert.Equal(t, "1", req.Headers["X-Debug"])
+	assert.NotContains(t, req.QueryParams, "ts")
+}
+
+func TestRunPreRequest_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		source  string
+		wantErr string
+	}{
+		{"syntax", "request.url = ", "invalid pre-request script"},
+		{"throw", `throw new Error("no signing key")`, "pre-request script failed: Error: no signing key"},
+		{"invalid request", `request.url = ""`, "pre-request script: request is invalid"},
+		{"request replaced", `request = 5`, "pre-request script: request is no longer an object"},
+		{"no assert", `assert(false)`, "assert is not defined"},
+		{"atob", `atob("%%")`, "atob: illegal base64 data"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := RunPreRequest(context.Background(), tt.source, newRequest(), nil)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestRunPreRequest_Timeout(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	_, err := RunPreRequest(ctx, "for (;;) {}", newRequest(), nil)
+	require.Error(t, err)
+	assert.Equal(t, "pre-request script timed out", err.Error())
+}
+
+func TestRunPostResponse(t *testing.T) {
+	source := `
+		assert(response.status === 201, "expected 201, got " + response.status);
+		assert(response.headers["Content-Type"] === "application/json");
+		const order = response.json();
+		assert(order.total > 100, "total too low");
+		assert(response.time < 1000);
+		vars.set("orderId", order.id);
+		request.url = "ignored";
+	`
+	resp := &domain.Response{
+		StatusCode: 200,
+		Status:     "200 OK",
+		Headers:    map[string]string{"Content-Type": "application/json"},
+		Body:       `{"id": 42, "total": 250}`,
+		Duration:   80 * time.Millisecond,
+	}
+	req := newRequest()
+	result, err := RunPostResponse(context.Background(), source, req, resp, nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"expected 201, got 200"}, result.AssertionFailures)
+	orderID := "42"
+	assert.Equal(t, map[string]*string{"orderId": &orderID}, result.Variables)
+	assert.Nil(t, result.Request)
+	assert.Equal(t, "https://api.example.com/orders", req.URL)
+}
+
+func TestRunPostResponse_DefaultMessageAndInvalidJSON(t *testing.T) {
+	resp := &domain.Response{StatusCode: 200, Body: "not json"}
+
+	result, err := RunPostResponse(context.Background(), "assert(0)", newRequest(), resp, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"assertion failed"}, result.AssertionFailures)
+
+	_, err = RunPostResponse(context.Background(), "response.json()", newRequest(), resp, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "response body is not JSON")
+}
+
+func TestBuiltins(t *testing.T) {
+	source := `
+		vars.set("sha", crypto.sha256("abc"));
+		vars.set("md5", crypto.md5("abc", "base64"));
+		vars.set("basic", btoa("user:pass"));
+		vars.set("decoded", atob("dXNlcjpwYXNz"));
+		vars.set("uuid", crypto.randomUUID().length);
+	`
+	result, err := RunPreRequest(context.Background(), source, newRequest(), nil)
+	require.NoError(t, err)
+
+	got := map[string]string{}
+	for name, value := range result.Variables {
+		got[name] = *value
+	}
+	assert.Equal(t, map[string]string{
+		"sha":     "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad",
+		"md5":     "kAFQmDzST7DWlj99KOF/cg==",
+		"basic":   "dXNlcjpwYXNz",
+		"decoded": "user:pass",
+		"uuid":    "36",
+	}, got)
+}
@@ -0,0 +1,338 @@
+// Package sharelink encodes requests as short strings that can be pasted into
+// chat and imported with one paste.
+//
+// A link is Prefix followed by the unpadded URL-safe base64 of a versioned
+// JSON payload. Credentials are stripped by default: auth settings, headers
+// and query parameters whose names look secret, and passwords in the URL are
+// left out, and the payload lists what was removed so the recipient knows
+// what to fill in. Scripts are left out by default too, since they run on
+// the recipient's machine with access to their variables.
+package sharelink
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/williajm/curly/internal/domain"
+)
+
+// Prefix starts every share link.
+const Prefix = "curly:"
+
+// Version is the payload version written by Encode. Decode rejects links
+// written by a newer version.
+const Version = 1
+
+// ErrInvalidLink is returned when a string is not a share link.
+var ErrInvalidLink = errors.New("invalid share link")
+
+// Options controls what Encode includes.
+type Options struct {
+	// IncludeSecrets keeps auth credentials, secret-looking headers and query
+	// parameters, and URL passwords in the link.
+	IncludeSecrets bool
+
+	// IncludeScripts keeps the pre-request and post-response scripts in the
+	// link.
+	IncludeScripts bool
+}
+
+// Result is a decoded share link.
+type Result struct {
+	// Request is a new, unsaved request built from the link.
+	Request *domain.Request
+
+	// Stripped describes the secrets and scripts the sender left out, such
+	// as "header Authorization".
+	Stripped []string
+}
+
+// Scripts describes the scripts the request carries, such as "pre-request
+// script". They run when the request is sent, so they should only be kept
+// once the recipient has seen them.
+func (r *Result) Scripts() []string {
+	var scripts []string
+	if r.Request.PreRequestScript != "" {
+		scripts = append(scripts, "pre-request script")
+	}
+	if r.Request.PostResponseScript != "" {
+		scripts = append(scripts, "post-response script")
+	}
+	return scripts
+}
+
+// DropScripts removes the scripts from the request.
+func (r *Result) DropScripts() {
+	r.Request.PreRequestScript = ""
+	r.Request.PostResponseScript = ""
+}
+
+// payload is the JSON form of a shared request.
+type payload struct {
+	Version     int               `json:"v"`
+	Name        string            `json:"name,omitempty"`
+	Method      string            `json:"method"`
+	URL         string            `json:"url"`
+	Headers     map[string]string `json:"headers,omitempty"`
+	QueryParams map[string]string `json:"query,omitempty"`
+	Body        string            `json:"body,omitempty"`
+	Auth        *authPayload      `json:"auth,omitempty"`
+	Tags        []string          `json:"tags,omitempty"`
+	Stripped    []string          `json:"stripped,omitempty"`
+
+	ResponseSchema   string `json:"response_schema,omitempty"`
+	ResponseContract string `json:"response_contract,omitempty"`
+
+	PreRequestScript   string `json:"pre_request_script,omitempty"`
+	PostResponseScript string `json:"post_response_script,omitempty"`
+}
+
+// authPayload is the JSON form of an auth configuration.
+type authPayload struct {
+	Type     string `json:"type"`
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+	Token    string `json:"token,omitempty"`
+	Key      string `json:"key,omitempty"`
+	Value    string `json:"value,omitempty"`
+	Location string `json:"location,omitempty"`
+}
+
+// Encode returns the share link for req. Unless opts.IncludeSecrets is set,
+// credentials are left out, and unless opts.IncludeScripts is set, scripts
+// are; the returned descriptions list them.
+func Encode(req *domain.Request, opts Options) (string, []string, error) {
+	p := payload{
+		Version:     Version,
+		Name:        req.Name,
+		Method:      req.Method,
+		URL:         req.URL,
+		Headers:     clone(req.Headers),
+		QueryParams: clone(req.QueryParams),
+		Body:        req.Body,
+		Tags:        req.Tags,
+
+		ResponseSchema:   req.ResponseSchema,
+		ResponseContract: req.ResponseContract,
+
+		PreRequestScript:   req.PreRequestScript,
+		PostResponseScript: req.PostResponseScript,
+	}
+
+	auth, err := encodeAuth(req.AuthConfig)
+	if err != nil {
+		return "", nil, err
+	}
+	p.Auth = auth
+
+	if !opts.IncludeSecrets {
+		strip(&p)
+	}
+	if !opts.IncludeScripts {
+		stripScripts(&p)
+	}
+
+	data, err := json.Marshal(p)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to encode share link: %w", err)
+	}
+	return Prefix + base64.RawURLEncoding.EncodeToString(data), p.Stripped, nil
+}
+
+// Decode parses a share link into a new request. Surrounding whitespace and
+// base64 padding are ignored. The request is not validated.
+func Decode(link string) (*Result, error) {
+	encoded, ok := strings.CutPrefix(strings.TrimSpace(link), Prefix)
+	if !ok {
+		return nil, fmt.Errorf("%w: it must start with %q", ErrInvalidLink, Prefix)
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(encoded, "="))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidLink, err)
+	}
+
+	var p payload
+	if err := json.Unmarshal(data, &p); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidLink, err)
+	}
+	if p.Version < 1 || p.Version > Version {
+		return nil, fmt.Errorf("%w: unsupported version %d", ErrInvalidLink, p.Version)
+	}
+
+	auth, err := decodeAuth(p.Auth)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidLink, err)
+	}
+
+	req := domain.NewRequest()
+	req.Name = p.Name
+	req.Method = p.Method
+	req.URL = p.URL
+	req.Body = p.Body
+	req.AuthConfig = auth
+	req.Tags = p.Tags
+	req.ResponseSchema = p.ResponseSchema
+	req.ResponseContract = p.ResponseContract
+	req.PreRequestScript = p.PreRequestScript
+	req.PostResponseScript = p.PostResponseScript
+	if p.Headers != nil {
+		req.Headers = p.Headers
+	}
+	if p.QueryParams != nil {
+		req.QueryParams = p.QueryParams
+	}
+
+	return &Result{Request: req, Stripped: p.Stripped}, nil
+}
+
+// stripScripts removes the scripts from p and records what was removed.
+func stripScripts(p *payload) {
+	if p.PreRequestScript != "" {
+		p.PreRequestScript = ""
+		p.Stripped = append(p.Stripped, "pre-request script")
+	}
+	if p.PostResponseScript != "" {
+		p.PostResponseScript = ""
+		p.Stripped = append(p.Stripped, "post-response script")
+	}
+}
+
+// IsLink reports whether s looks like a share link rather than, say, a curl
+// command.
+func IsLink(s string) bool {
+	return strings.HasPrefix(strings.TrimSpace(s), Prefix)
+}
+
+// strip removes the secrets from p and records what was removed.
+func strip(p *payload) {
+	if p.Auth != nil && p.Auth.Type != domain.AuthTypeNone {
+		p.Stripped = append(p.Stripped, p.Auth.Type+" auth credentials")
+		p.Auth = nil
+	}
+
+	if parsed, err := url.Parse(p.URL); err == nil && parsed.User != nil {
+		if _, ok := parsed.User.Password(); ok {
+			parsed.User = url.User(parsed.User.Username())
+			p.URL = parsed.String()
+			p.Stripped = append(p.Stripped, "URL password")
+		}
+	}
+
+	stripURLQuery(p)
+
+	for _, name := range sortedKeys(p.Headers) {
+		if domain.IsSecretName(name) {
+			delete(p.Headers, name)
+			p.Stripped = append(p.Stripped, "header "+name)
+		}
+	}
+	for _, name := range sortedKeys(p.QueryParams) {
+		if domain.IsSecretName(name) {
+			delete(p.QueryParams, name)
+			p.Stripped = append(p.Stripped, "query parameter "+name)
+		}
+	}
+}
+
+// stripURLQuery removes the parameters whose names look secret from the query
+// string written in p.URL, keeping the order and encoding of the others.
+func stripURLQuery(p *payload) {
+	base, query, found := strings.Cut(p.URL, "?")
+	if !found {
+		return
+	}
+	query, fragment, hasFragment := strings.Cut(query, "#")
+
+	params := strings.Split(query, "&")
+	var kept []string
+	for _, param := range params {
+		raw, _, _ := strings.Cut(param, "=")
+		name, err := url.QueryUnescape(raw)
+		if err != nil {
+			name = raw
+		}
+		if !domain.IsSecretName(name) {
+			kept = append(kept, param)
+			continue
+		}
+		if what := "query parameter " + name; !slices.Contains(p.Stripped, what) {
+			p.Stripped = append(p.Stripped, what)
+		}
+	}
+	if len(kept) == len(params) {
+		return
+	}
+
+	p.URL = base
+	if len(kept) > 0 {
+		p.URL += "?" + strings.Join(kept, "&")
+	}
+	if hasFragment {
+		p.URL += "#" + fragment
+	}
+}
+
+// encodeAuth converts an auth configuration to its payload form (nil for no auth).
+func encodeAuth(auth domain.AuthConfig) (*authPayload, error) {
+	switch a := auth.(type) {
+	case nil, *domain.NoAuth:
+		return nil, nil
+	case *domain.BasicAuth:
+		return &authPayload{Type: domain.AuthTypeBasic, Username: a.Username, Password: a.Password}, nil
+	case *domain.BearerAuth:
+		return &authPayload{Type: domain.AuthTypeBearer, Token: a.Token}, nil
+	case *domain.APIKeyAuth:
+		return &authPayload{Type: domain.AuthTypeAPIKey, Key: a.Key, Value: a.Value, Location: string(a.Location)}, nil
+	default:
+		return nil, fmt.Errorf("unsupported auth type: %T", auth)
+	}
+}
+
+// decodeAuth converts the payload form of an auth configuration back to a domain type.
+func decodeAuth(auth *authPayload) (domain.AuthConfig, error) {
+	if auth == nil {
+		return domain.NewNoAuth(), nil
+	}
+
+	switch auth.Type {
+	case "", domain.AuthTypeNone:
+		return domain.NewNoAuth(), nil
+	case domain.AuthTypeBasic:
+		return domain.NewBasicAuth(auth.Username, auth.Password), nil
+	case domain.AuthTypeBearer:
+		return domain.NewBearerAuth(auth.Token), nil
+	case domain.AuthTypeAPIKey:
+		return domain.NewAPIKeyAuth(auth.Key, auth.Value, domain.APIKeyLocation(auth.Location)), nil
+	default:
+		return nil, fmt.Errorf("unknown auth type: %s", auth.Type)
+	}
+}
+
+// clone copies m so stripping secrets does not modify the request.
+func clone(m map[string]string) map[string]string {
+	if len(m) == 0 {
+		return nil
+	}
+	out := make(map[string]string, len(m))
+	for k, v := range m {
+		out[k] = v
+	}
+	return out
+}
+
+// sortedKeys returns the keys of m in order, so stripped secrets are listed
+// deterministically.
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
@@ -0,0 +1,150 @@
+// Package sse reads server-sent event streams, as described by the HTML
+// Living Standard's "text/event-stream" format.
+package sse
+
+import (
+	"bufio"
+	"io"
+	"mime"
+	"strconv"
+	"strings"
+)
+
+// ContentType is the media type of server-sent event streams.
+const ContentType = "text/event-stream"
+
+// maxLineSize is the longest line of a stream that can be read.
+const maxLineSize = 1 << 20
+
+// Event is a server-sent event.
+type Event struct {
+	// Type is the event's type, "message" unless the server names another.
+	Type string
+
+	// ID is the event ID the server set, which carries over to later events.
+	ID string
+
+	// Data is the event's data, its data lines joined by newlines.
+	Data string
+
+	// Retry is the reconnection time in milliseconds the server asked for
+	// with the event, or 0.
+	Retry int
+}
+
+// Reader reads events from a stream.
+type Reader struct {
+	scanner *bufio.Scanner
+	lastID  string
+}
+
+// NewReader returns a Reader that reads events from r.
+func NewReader(r io.Reader) *Reader {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 4096), maxLineSize)
+	scanner.Split(scanLines)
+	return &Reader{scanner: scanner}
+}
+
+// Next returns the next event, blocking until it has arrived in full. At the
+// end of the stream it returns io.EOF; an event cut off by the end of the
+// stream is discarded.
+func (r *Reader) Next() (Event, error) {
+	var (
+		event   Event
+		data    strings.Builder
+		hasData bool
+	)
+	for r.scanner.Scan() {
+		line := r.scanner.Text()
+		if line == "" {
+			// A blank line dispatches the event, unless it has no data.
+			if !hasData {
+				event = Event{}
+				continue
+			}
+			event.Data = strings.TrimSuffix(data.String(), "\n")
+			event.ID = r.lastID
+			if event.Type == "" {
+				event.Type = "message"
+			}
+			return event, nil
+		}
+		if strings.HasPrefix(line, ":") {
+			// A comment, often sent to keep the connection open.
+			continue
+		}
+
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+		switch field {
+		case "event":
+			event.Type = value
+		case "data":
+			data.WriteString(value)
+			data.WriteByte('\n')
+			hasData = true
+		case "id":
+			if !strings.ContainsRune(value, 0) {
+				r.lastID = value
+			}
+		case "retry":
+			if n, err := strconv.Atoi(value); err == nil && n >= 0 {
+				event.Retry = n
+			}
+		}
+	}
+	if err := r.scanner.Err(); err != nil {
+		return Event{}, err
+	}
+	return Event{}, io.EOF
+}
+
+// scanLines is bufio.ScanLines that also ends lines at a lone carriage
+// return, as the format allows.
+func scanLines(data []byte, atEOF bool) (int, []byte, error) {
+	for i, b := range data {
+		switch b {
+		case '\n':
+			return i + 1, data[:i], nil
+		case '\r':
+			if i+1 < len(data) {
+				if data[i+1] == '\n' {
+					return i + 2, data[:i], nil
+				}
+				return i + 1, data[:i], nil
+			}
+			if atEOF {
+				return i + 1, data[:i], nil
+			}
+			// Wait to see whether a line feed follows.
+			return 0, nil, nil
+		}
+	}
+	if atEOF && len(data) > 0 {
+		return len(data), data, nil
+	}
+	return 0, nil, nil
+}
+
+// IsEventStream reports whether contentType is that of an event stream.
+func IsEventStream(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	return err == nil && mediaType == ContentType
+}
+
+// Accepted reports whether headers have an Accept header, in any case, that
+// asks for an event stream, as EventSource clients send.
+func Accepted(headers map[string]string) bool {
+	for name, value := range headers {
+		if !strings.EqualFold(name, "Accept") {
+			continue
+		}
+		for _, part := range strings.Split(value, ",") {
+			if IsEventStream(strings.TrimSpace(part)) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,66 @@
+package sse
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// readAll returns the events of stream.
+func readAll(t *testing.T, stream string) []Event {
+	t.Helper()
+	r := NewReader(strings.NewReader(stream))
+	var events []Event
+	for {
+		event, err := r.Next()
+		if err == io.EOF {
+			return events
+		}
+		require.NoError(t, err)
+		events = append(events, event)
+	}
+}
+
+func TestReader(t *testing.T) {
+	events := readAll(t, ": keep-alive\n\n"+
+		"data: first\n\n"+
+		"event: update\nid: 7\ndata: {\"a\": 1}\ndata: {\"b\": 2}\nretry: 3000\n\n"+
+		"data:no space\r\n\r\n"+
+		"data: cut off")
+
+	require.Len(t, events, 3)
+	assert.Equal(t, Event{Type: "message", Data: "first"}, events[0])
+	assert.Equal(t, Event{Type: "update", ID: "7", Data: "{\"a\": 1}\n{\"b\": 2}", Retry: 3000}, events[1])
+	// The ID carries over to later events.
+	assert.Equal(t, Event{Type: "message", ID: "7", Data: "no space"}, events[2])
+}
+
+func TestReader_EventsWithoutDataAreDropped(t *testing.T) {
+	events := readAll(t, "event: ping\n\nevent: tick\ndata: 1\n\n")
+	require.Len(t, events, 1)
+	assert.Equal(t, "tick", events[0].Type)
+}
+
+func TestReader_CarriageReturns(t *testing.T) {
+	events := readAll(t, "data: a\rdata: b\r\r")
+	require.Len(t, events, 1)
+	assert.Equal(t, "a\nb", events[0].Data)
+}
+
+func TestIsEventStream(t *testing.T) {
+	assert.True(t, IsEventStream("text/event-stream"))
+	assert.True(t, IsEventStream("text/event-stream; charset=utf-8"))
+	assert.False(t, IsEventStream("application/json"))
+	assert.False(t, IsEventStream(""))
+}
+
+func TestAccepted(t *testing.T) {
+	assert.True(t, Accepted(map[string]string{"Accept": "text/event-stream"}))
+	assert.True(t, Accepted(map[string]string{"accept": "application/json, text/event-stream"}))
+	assert.False(t, Accepted(map[string]string{"Accept": "application/json"}))
+	assert.False(t, Accepted(map[string]string{"Content-Type": "text/event-stream"}))
+	assert.False(t, Accepted(nil))
+}
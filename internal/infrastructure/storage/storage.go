@@ -0,0 +1,133 @@
+// Package storage selects and opens the configured repository backend.
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/williajm/curly/internal/infrastructure/repository"
+	"github.com/williajm/curly/internal/infrastructure/repository/bodystore"
+	"github.com/williajm/curly/internal/infrastructure/repository/postgres"
+	"github.com/williajm/curly/internal/infrastructure/repository/sqlite"
+)
+
+// Supported storage drivers.
+const (
+	DriverSQLite   = "sqlite"
+	DriverPostgres = "postgres"
+)
+
+// Config selects a storage backend and holds its settings.
+type Config struct {
+	// Driver is the backend to use: DriverSQLite or DriverPostgres.
+	// If empty, DriverSQLite is used.
+	Driver string
+
+	// SQLite configures the SQLite backend.
+	SQLite sqlite.Config
+
+	// DSN is the connection string for the PostgreSQL backend.
+	DSN string
+
+	// BodiesDir is where response bodies larger than OffloadThreshold bytes
+	// are stored instead of the history table. Offloading is disabled if
+	// either is unset, and for DriverPostgres, whose history is shared with
+	// machines that cannot read the directory.
+	BodiesDir        string
+	OffloadThreshold int
+}
+
+// Store bundles the repositories of an opened backend.
+type Store struct {
+	// Driver is the backend the store was opened with.
+	Driver string
+
+	Requests     repository.RequestRepository
+	History      repository.HistoryRepository
+	Environments repository.EnvironmentRepository
+	Drafts       repository.DraftRepository
+	Session      repository.SessionRepository
+
+	db *sql.DB
+}
+
+// Open opens the configured backend and applies its migrations.
+func Open(cfg *Config) (*Store, error) {
+	store, err := openBackend(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	offloadBodies(store, cfg)
+	return store, nil
+}
+
+// offloadBodies wraps the history repository of store so that large response
+// bodies are stored in cfg.BodiesDir, if the backend is local to this machine.
+func offloadBodies(store *Store, cfg *Config) {
+	if store.Driver == DriverPostgres || cfg.BodiesDir == "" || cfg.OffloadThreshold <= 0 {
+		return
+	}
+	store.History = bodystore.NewHistoryRepository(store.History, bodystore.NewStore(cfg.BodiesDir), cfg.OffloadThreshold)
+}
+
+// openBackend opens the database for the configured driver.
+func openBackend(cfg *Config) (*Store, error) {
+	if cfg == nil {
+		return nil, fmt.Errorf("storage config cannot be nil")
+	}
+
+	driver := cfg.Driver
+	if driver == "" {
+		driver = DriverSQLite
+	}
+
+	switch driver {
+	case DriverSQLite:
+		sqliteConfig := cfg.SQLite
+		db, err := sqlite.Open(&sqliteConfig)
+		if err != nil {
+			return nil, err
+		}
+		return &Store{
+			Driver:       driver,
+			Requests:     sqlite.NewRequestRepository(db),
+			History:      sqlite.NewHistoryRepository(db),
+			Environments: sqlite.NewEnvironmentRepository(db),
+			Drafts:       sqlite.NewDraftRepository(db),
+			Session:      sqlite.NewSessionRepository(db),
+			db:           db,
+		}, nil
+
+	case DriverPostgres:
+		if cfg.DSN == "" {
+			return nil, fmt.Errorf("database.dsn is required for the %s driver", DriverPostgres)
+		}
+		db, err := postgres.Open(&postgres.Config{DSN: cfg.DSN})
+		if err != nil {
+			return nil, err
+		}
+		return &Store{
+			Driver:       driver,
+			Requests:     postgres.NewRequestRepository(db),
+			History:      postgres.NewHistoryRepository(db),
+			Environments: postgres.NewEnvironmentRepository(db),
+			Drafts:       postgres.NewDraftRepository(db),
+			Session:      postgres.NewSessionRepository(db),
+			db:           db,
+		}, nil
+
+	default:
+		return nil, fmt.Errorf("unsupported database driver %q (expected %q or %q)", cfg.Driver, DriverSQLite, DriverPostgres)
+	}
+}
+
+// DB returns the underlying database handle.
+func (s *Store) DB() *sql.DB {
+	return s.db
+}
+
+// Close closes the underlying database.
+func (s *Store) Close() error {
+	return s.db.Close()
+}
@@ -0,0 +1,88 @@
+package storage
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/williajm/curly/internal/domain"
+	"github.com/williajm/curly/internal/infrastructure/repository/bodystore"
+	"github.com/williajm/curly/internal/infrastructure/repository/sqlite"
+)
+
+func TestOpen_SQLite(t *testing.T) {
+	tests := []struct {
+		name   string
+		driver string
+	}{
+		{name: "explicit driver", driver: DriverSQLite},
+		{name: "default driver", driver: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store, err := Open(&Config{Driver: tt.driver, SQLite: sqlite.Config{Path: ":memory:"}})
+			require.NoError(t, err)
+			defer func() { _ = store.Close() }()
+
+			assert.Equal(t, DriverSQLite, store.Driver)
+			assert.NotNil(t, store.DB())
+
+			req := domain.NewRequest()
+			req.Name = "Test"
+			req.Method = "GET"
+			req.URL = "https://example.com"
+			require.NoError(t, store.Requests.Create(context.Background(), req))
+
+			found, err := store.Requests.FindByID(context.Background(), req.ID)
+			require.NoError(t, err)
+			assert.Equal(t, req.Name, found.Name)
+		})
+	}
+}
+
+func TestOpen_Errors(t *testing.T) {
+	tests := []struct {
+		name   string
+		config *Config
+	}{
+		{name: "nil config", config: nil},
+		{name: "unknown driver", config: &Config{Driver: "mysql"}},
+		{name: "postgres without DSN", config: &Config{Driver: DriverPostgres}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store, err := Open(tt.config)
+			assert.Error(t, err)
+			assert.Nil(t, store)
+		})
+	}
+}
+
+func TestOpen_OffloadsLargeBodies(t *testing.T) {
+	store, err := Open(&Config{
+		SQLite:           sqlite.Config{Path: ":memory:"},
+		BodiesDir:        t.TempDir(),
+		OffloadThreshold: 16,
+	})
+	require.NoError(t, err)
+	defer func() { _ = store.Close() }()
+
+	assert.IsType(t, &bodystore.HistoryRepository{}, store.History)
+}
+
+func TestOffloadBodies_SkipsPostgres(t *testing.T) {
+	store, err := Open(&Config{SQLite: sqlite.Config{Path: ":memory:"}})
+	require.NoError(t, err)
+	defer func() { _ = store.Close() }()
+
+	// History in a shared database must not point at files on this machine.
+	store.Driver = DriverPostgres
+	history := store.History
+	offloadBodies(store, &Config{Driver: DriverPostgres, BodiesDir: t.TempDir(), OffloadThreshold: 16})
+
+	assert.Same(t, history, store.History)
+}
@@ -0,0 +1,355 @@
+// Package websocket implements the client side of the WebSocket protocol
+// (RFC 6455) over a connection that has already been upgraded, such as the
+// body of a "101 Switching Protocols" response, and the keys and URLs of the
+// opening handshake.
+package websocket
+
+import (
+	"bufio"
+	"crypto/rand"
+	"crypto/sha1" // #nosec G505 -- SHA-1 is what the handshake specifies.
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// acceptGUID is appended to the client's key to compute the server's accept
+// key.
+const acceptGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// MaxMessageSize is the largest message read; longer messages fail the
+// connection.
+const MaxMessageSize = 16 << 20
+
+// Opcodes.
+const (
+	opContinuation = 0x0
+	opText         = 0x1
+	opBinary       = 0x2
+	opClose        = 0x8
+	opPing         = 0x9
+	opPong         = 0xA
+)
+
+// Close codes.
+const (
+	CloseNormal        = 1000
+	CloseGoingAway     = 1001
+	CloseNoStatus      = 1005
+	CloseAbnormal      = 1006
+	CloseInvalidData   = 1007
+	CloseMessageTooBig = 1009
+)
+
+// MessageType is the type of a data message.
+type MessageType int
+
+// Message types.
+const (
+	TextMessage   MessageType = opText
+	BinaryMessage MessageType = opBinary
+)
+
+// CloseError is returned by ReadMessage once the server has closed the
+// connection, with the code and reason it gave.
+type CloseError struct {
+	Code   int
+	Reason string
+}
+
+func (e *CloseError) Error() string {
+	if e.Reason != "" {
+		return fmt.Sprintf("websocket closed: %d %s", e.Code, e.Reason)
+	}
+	return fmt.Sprintf("websocket closed: %d", e.Code)
+}
+
+// ErrClosed is returned when reading from or writing to a connection that
+// was closed with Close.
+var ErrClosed = errors.New("websocket connection closed")
+
+// NewKey returns a random Sec-WebSocket-Key for the opening handshake.
+func NewKey() (string, error) {
+	key := make([]byte, 16)
+	if _, err := rand.Read(key); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(key), nil
+}
+
+// AcceptKey returns the Sec-WebSocket-Accept value a server answers key with.
+func AcceptKey(key string) string {
+	sum := sha1.Sum([]byte(key + acceptGUID)) // #nosec G401 -- see the import.
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// HTTPURL returns the http or https URL that a ws or wss URL is opened with;
+// other URLs are returned as they are.
+func HTTPURL(raw string) string {
+	switch {
+	case hasScheme(raw, "ws"):
+		return "http" + raw[len("ws"):]
+	case hasScheme(raw, "wss"):
+		return "https" + raw[len("wss"):]
+	}
+	return raw
+}
+
+// WSURL is the inverse of HTTPURL: it returns the ws or wss URL of an http or
+// https URL.
+func WSURL(raw string) string {
+	switch {
+	case hasScheme(raw, "http"):
+		return "ws" + raw[len("http"):]
+	case hasScheme(raw, "https"):
+		return "wss" + raw[len("https"):]
+	}
+	return raw
+}
+
+// hasScheme reports whether raw starts with scheme and "://", ignoring case.
+func hasScheme(raw, scheme string) bool {
+	u, err := url.Parse(raw)
+	return err == nil && strings.EqualFold(u.Scheme, scheme) && strings.Contains(raw, "://")
+}
+
+// Conn is a client WebSocket connection. Messages may be read and written at
+// the same time, from different goroutines.
+type Conn struct {
+	rwc    io.ReadWriteCloser
+	br     *bufio.Reader
+	server bool // Frames are written unmasked, as a server writes them.
+
+	writeMu sync.Mutex
+	closed  bool
+}
+
+// NewConn returns the client connection over rwc, which must already be
+// upgraded to the WebSocket protocol.
+func NewConn(rwc io.ReadWriteCloser) *Conn {
+	return &Conn{rwc: rwc, br: bufio.NewReader(rwc)}
+}
+
+// ReadMessage returns the next data message, reassembled from its fragments.
+// Pings are answered as they arrive. Once the server closes the connection,
+// it returns a *CloseError after answering the close.
+func (c *Conn) ReadMessage() (MessageType, []byte, error) {
+	var (
+		messageType MessageType
+		message     []byte
+		fragmented  bool
+	)
+	for {
+		fin, opcode, payload, err := c.readDataFrame()
+		if err != nil {
+			return 0, nil, err
+		}
+
+		switch opcode {
+		case opText, opBinary:
+			if fragmented {
+				return 0, nil, c.fail(CloseInvalidData, "new message before the last one finished")
+			}
+			messageType = MessageType(opcode)
+			message = payload
+		case opContinuation:
+			if !fragmented {
+				return 0, nil, c.fail(CloseInvalidData, "continuation without a message")
+			}
+			message = append(message, payload...)
+		default:
+			return 0, nil, c.fail(CloseInvalidData, fmt.Sprintf("unknown opcode %d", opcode))
+		}
+
+		if len(message) > MaxMessageSize {
+			return 0, nil, c.fail(CloseMessageTooBig, "message too big")
+		}
+		if !fin {
+			fragmented = true
+			continue
+		}
+		if messageType == TextMessage && !utf8.Valid(message) {
+			return 0, nil, c.fail(CloseInvalidData, "text message is not UTF-8")
+		}
+		return messageType, message, nil
+	}
+}
+
+// readDataFrame returns the next frame that is not a control frame, answering
+// pings on the way. Once the server closes the connection, it returns a
+// *CloseError after answering the close.
+func (c *Conn) readDataFrame() (fin bool, opcode byte, payload []byte, err error) {
+	for {
+		fin, opcode, payload, err = c.readFrame()
+		if err != nil {
+			if c.isClosed() {
+				return false, 0, nil, ErrClosed
+			}
+			return false, 0, nil, err
+		}
+
+		switch opcode {
+		case opPing:
+			if err := c.writeFrame(opPong, payload); err != nil {
+				return false, 0, nil, err
+			}
+		case opPong:
+		case opClose:
+			closeErr := &CloseError{Code: CloseNoStatus}
+			if len(payload) >= 2 {
+				closeErr.Code = int(binary.BigEndian.Uint16(payload))
+				closeErr.Reason = string(payload[2:])
+			}
+			// Echo the close, as the protocol asks, then hang up.
+			_ = c.writeClose(closeErr.Code, "")
+			_ = c.rwc.Close()
+			return false, 0, nil, closeErr
+		default:
+			return fin, opcode, payload, nil
+		}
+	}
+}
+
+// WriteMessage sends data as a single message of type messageType.
+func (c *Conn) WriteMessage(messageType MessageType, data []byte) error {
+	if c.isClosed() {
+		return ErrClosed
+	}
+	return c.writeFrame(byte(messageType), data)
+}
+
+// Close sends a normal close to the server and closes the connection without
+// waiting for the server to answer. Reads in progress return ErrClosed.
+func (c *Conn) Close() error {
+	c.writeMu.Lock()
+	if c.closed {
+		c.writeMu.Unlock()
+		return nil
+	}
+	c.writeMu.Unlock()
+
+	_ = c.writeClose(CloseNormal, "")
+	c.writeMu.Lock()
+	c.closed = true
+	c.writeMu.Unlock()
+	return c.rwc.Close()
+}
+
+// isClosed reports whether Close was called.
+func (c *Conn) isClosed() bool {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	return c.closed
+}
+
+// fail closes the connection with code, for a protocol error described by
+// reason, which it returns as an error.
+func (c *Conn) fail(code int, reason string) error {
+	_ = c.writeClose(code, reason)
+	_ = c.rwc.Close()
+	return fmt.Errorf("websocket protocol error: %s", reason)
+}
+
+// writeClose sends a close frame with code and reason.
+func (c *Conn) writeClose(code int, reason string) error {
+	payload := make([]byte, 2, 2+len(reason))
+	binary.BigEndian.PutUint16(payload, uint16(code)) // #nosec G115 -- close codes fit.
+	return c.writeFrame(opClose, append(payload, reason...))
+}
+
+// readFrame reads one frame, unmasking its payload if it is masked.
+func (c *Conn) readFrame() (fin bool, opcode byte, payload []byte, err error) {
+	var header [2]byte
+	if _, err := io.ReadFull(c.br, header[:]); err != nil {
+		return false, 0, nil, err
+	}
+	fin = header[0]&0x80 != 0
+	opcode = header[0] & 0x0F
+	masked := header[1]&0x80 != 0
+
+	length := uint64(header[1] & 0x7F)
+	switch length {
+	case 126:
+		var ext [2]byte
+		if _, err := io.ReadFull(c.br, ext[:]); err != nil {
+			return false, 0, nil, err
+		}
+		length = uint64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		if _, err := io.ReadFull(c.br, ext[:]); err != nil {
+			return false, 0, nil, err
+		}
+		length = binary.BigEndian.Uint64(ext[:])
+	}
+	if length > MaxMessageSize {
+		return false, 0, nil, c.fail(CloseMessageTooBig, "message too big")
+	}
+
+	var mask [4]byte
+	if masked {
+		if _, err := io.ReadFull(c.br, mask[:]); err != nil {
+			return false, 0, nil, err
+		}
+	}
+
+	payload = make([]byte, length)
+	if _, err := io.ReadFull(c.br, payload); err != nil {
+		return false, 0, nil, err
+	}
+	if masked {
+		for i := range payload {
+			payload[i] ^= mask[i%4]
+		}
+	}
+	return fin, opcode, payload, nil
+}
+
+// writeFrame sends payload as one final frame with opcode, masked as the
+// protocol requires of clients.
+func (c *Conn) writeFrame(opcode byte, payload []byte) error {
+	frame := make([]byte, 0, 14+len(payload))
+	frame = append(frame, 0x80|opcode)
+
+	maskBit := byte(0x80)
+	if c.server {
+		maskBit = 0
+	}
+	switch n := len(payload); {
+	case n < 126:
+		frame = append(frame, maskBit|byte(n))
+	case n <= 0xFFFF:
+		frame = append(frame, maskBit|126)
+		frame = binary.BigEndian.AppendUint16(frame, uint16(n))
+	default:
+		frame = append(frame, maskBit|127)
+		frame = binary.BigEndian.AppendUint64(frame, uint64(n))
+	}
+
+	if c.server {
+		frame = append(frame, payload...)
+	} else {
+		var mask [4]byte
+		if _, err := rand.Read(mask[:]); err != nil {
+			return err
+		}
+		frame = append(frame, mask[:]...)
+		for i, b := range payload {
+			frame = append(frame, b^mask[i%4])
+		}
+	}
+
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	if c.closed {
+		return ErrClosed
+	}
+	_, err := c.rwc.Write(frame)
+	return err
+}
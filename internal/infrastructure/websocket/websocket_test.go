@@ -0,0 +1,140 @@
+package websocket
+
+import (
+	"errors"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// pipe returns a client connection and the server end it talks to.
+func pipe(t *testing.T) (*Conn, *Conn) {
+	t.Helper()
+	clientEnd, serverEnd := net.Pipe()
+	t.Cleanup(func() {
+		_ = clientEnd.Close()
+		_ = serverEnd.Close()
+	})
+	server := NewConn(serverEnd)
+	server.server = true
+	return NewConn(clientEnd), server
+}
+
+func TestAcceptKey(t *testing.T) {
+	// The example from RFC 6455, section 1.3.
+	assert.Equal(t, "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=", AcceptKey("dGhlIHNhbXBsZSBub25jZQ=="))
+}
+
+func TestNewKey(t *testing.T) {
+	first, err := NewKey()
+	require.NoError(t, err)
+	second, err := NewKey()
+	require.NoError(t, err)
+	assert.Len(t, first, 24)
+	assert.NotEqual(t, first, second)
+}
+
+func TestURLs(t *testing.T) {
+	assert.Equal(t, "http://localhost:8080/ws", HTTPURL("ws://localhost:8080/ws"))
+	assert.Equal(t, "https://example.com/socket?x=1", HTTPURL("wss://example.com/socket?x=1"))
+	assert.Equal(t, "https://example.com", HTTPURL("https://example.com"))
+	assert.Equal(t, "ws://localhost:8080/ws", WSURL("http://localhost:8080/ws"))
+	assert.Equal(t, "wss://example.com/socket", WSURL("https://example.com/socket"))
+	assert.Equal(t, "example.com", WSURL("example.com"))
+}
+
+func TestConn_Messages(t *testing.T) {
+	client, server := pipe(t)
+
+	go func() {
+		_ = client.WriteMessage(TextMessage, []byte(`{"hello": "world"}`))
+	}()
+	messageType, data, err := server.ReadMessage()
+	require.NoError(t, err)
+	assert.Equal(t, TextMessage, messageType)
+	assert.Equal(t, `{"hello": "world"}`, string(data))
+
+	// Long messages take the extended lengths.
+	long := strings.Repeat("x", 70000)
+	go func() {
+		_ = server.WriteMessage(BinaryMessage, []byte(long))
+	}()
+	messageType, data, err = client.ReadMessage()
+	require.NoError(t, err)
+	assert.Equal(t, BinaryMessage, messageType)
+	assert.Equal(t, long, string(data))
+}
+
+func TestConn_Fragments(t *testing.T) {
+	client, server := pipe(t)
+
+	go func() {
+		// A ping between the fragments is answered without ending the
+		// message.
+		_, _ = server.rwc.Write([]byte{opText, 3, 'o', 'n', 'e'})
+		_, _ = server.rwc.Write([]byte{0x80 | opPing, 1, 'p'})
+		_, _ = server.rwc.Write([]byte{0x80 | opContinuation, 4, ' ', 't', 'w', 'o'})
+	}()
+	go func() {
+		// Read the pong.
+		_, _, _, _ = server.readFrame()
+	}()
+
+	_, data, err := client.ReadMessage()
+	require.NoError(t, err)
+	assert.Equal(t, "one two", string(data))
+}
+
+func TestConn_ServerClose(t *testing.T) {
+	client, server := pipe(t)
+
+	echoed := make(chan []byte, 1)
+	go func() {
+		_, _ = server.rwc.Write([]byte{0x80 | opClose, 6, 0x03, 0xE9, 'b', 'y', 'e', '!'})
+		_, _, payload, _ := server.readFrame()
+		echoed <- payload
+	}()
+
+	_, _, err := client.ReadMessage()
+	var closeErr *CloseError
+	require.True(t, errors.As(err, &closeErr))
+	assert.Equal(t, CloseGoingAway, closeErr.Code)
+	assert.Equal(t, "bye!", closeErr.Reason)
+	assert.Equal(t, []byte{0x03, 0xE9}, <-echoed)
+}
+
+func TestConn_Close(t *testing.T) {
+	client, server := pipe(t)
+
+	received := make(chan error, 1)
+	go func() {
+		_, _, err := server.ReadMessage()
+		received <- err
+	}()
+	read := make(chan error, 1)
+	go func() {
+		_, _, err := client.ReadMessage()
+		read <- err
+	}()
+
+	require.NoError(t, client.Close())
+	var closeErr *CloseError
+	require.True(t, errors.As(<-received, &closeErr))
+	assert.Equal(t, CloseNormal, closeErr.Code)
+	assert.ErrorIs(t, <-read, ErrClosed)
+	assert.ErrorIs(t, client.WriteMessage(TextMessage, []byte("late")), ErrClosed)
+}
+
+func TestConn_InvalidText(t *testing.T) {
+	client, server := pipe(t)
+
+	go func() {
+		_, _ = server.rwc.Write([]byte{0x80 | opText, 2, 0xff, 0xfe})
+		_, _, _, _ = server.readFrame()
+	}()
+	_, _, err := client.ReadMessage()
+	assert.ErrorContains(t, err, "not UTF-8")
+}
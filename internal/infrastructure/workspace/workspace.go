@@ -0,0 +1,104 @@
+// Package workspace maps workspace names to separate SQLite databases.
+//
+// A workspace keeps its saved requests and history fully apart from other
+// workspaces, e.g. to separate work and personal APIs or different clients.
+// The default workspace is the configured database path; every other
+// workspace is a database file named after it in the workspaces directory,
+// with a directory of the same name for its backups, archives and bodies.
+package workspace
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Default is the name of the workspace backed by the configured database path.
+const Default = "default"
+
+// fileExt is the extension of workspace database files.
+const fileExt = ".db"
+
+// maxNameLen bounds workspace names so they stay usable as file names.
+const maxNameLen = 64
+
+// namePattern restricts names to characters that are safe in file names.
+var namePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_-]*$`)
+
+// Manager resolves and lists workspaces.
+type Manager struct {
+	dir         string
+	defaultPath string
+}
+
+// NewManager creates a manager for workspaces stored in dir.
+// defaultPath is the database used by the default workspace.
+func NewManager(dir, defaultPath string) *Manager {
+	return &Manager{dir: dir, defaultPath: defaultPath}
+}
+
+// ValidateName checks that name can be used as a workspace name.
+func ValidateName(name string) error {
+	if len(name) > maxNameLen || !namePattern.MatchString(name) {
+		return fmt.Errorf("invalid workspace name %q: use up to %d letters, digits, '-' or '_', starting with a letter or digit", name, maxNameLen)
+	}
+	return nil
+}
+
+// Path returns the database path for the named workspace.
+// An empty name selects the default workspace.
+func (m *Manager) Path(name string) (string, error) {
+	if name == "" || name == Default {
+		return m.defaultPath, nil
+	}
+	if err := ValidateName(name); err != nil {
+		return "", err
+	}
+	return filepath.Join(m.dir, name+fileExt), nil
+}
+
+// DataDir returns the directory holding the named workspace's backups,
+// archived history and offloaded response bodies, next to its database in the
+// workspaces directory. It returns "" for the default workspace, which uses
+// the configured directories.
+func (m *Manager) DataDir(name string) (string, error) {
+	if name == "" || name == Default {
+		return "", nil
+	}
+	if err := ValidateName(name); err != nil {
+		return "", err
+	}
+	return filepath.Join(m.dir, name), nil
+}
+
+// List returns the default workspace followed by all workspaces that have a
+// database in the workspaces directory, sorted by name.
+func (m *Manager) List() ([]string, error) {
+	names := []string{Default}
+
+	entries, err := os.ReadDir(m.dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return names, nil
+		}
+		return nil, fmt.Errorf("failed to read workspaces directory: %w", err)
+	}
+
+	var named []string
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != fileExt {
+			continue
+		}
+		name := strings.TrimSuffix(entry.Name(), fileExt)
+		if name == Default || ValidateName(name) != nil {
+			continue
+		}
+		named = append(named, name)
+	}
+	sort.Strings(named)
+
+	return append(names, named...), nil
+}
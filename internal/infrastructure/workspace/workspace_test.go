@@ -0,0 +1,76 @@
+package workspace
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateName(t *testing.T) {
+	valid := []string{"work", "client-a", "Client_B2", "1"}
+	for _, name := range valid {
+		assert.NoError(t, ValidateName(name), name)
+	}
+
+	invalid := []string{"", "-work", "../etc", "a/b", "with space", "dots.db", string(make([]byte, maxNameLen+1))}
+	for _, name := range invalid {
+		assert.Error(t, ValidateName(name), name)
+	}
+}
+
+func TestManager_Path(t *testing.T) {
+	m := NewManager("/data/workspaces", "/data/curly.db")
+
+	path, err := m.Path("")
+	require.NoError(t, err)
+	assert.Equal(t, "/data/curly.db", path)
+
+	path, err = m.Path(Default)
+	require.NoError(t, err)
+	assert.Equal(t, "/data/curly.db", path)
+
+	path, err = m.Path("client-a")
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join("/data/workspaces", "client-a.db"), path)
+
+	_, err = m.Path("../escape")
+	assert.Error(t, err)
+}
+
+func TestManager_DataDir(t *testing.T) {
+	m := NewManager("/data/workspaces", "/data/curly.db")
+
+	dir, err := m.DataDir(Default)
+	require.NoError(t, err)
+	assert.Empty(t, dir, "the default workspace keeps the configured directories")
+
+	dir, err = m.DataDir("client-a")
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join("/data/workspaces", "client-a"), dir)
+
+	_, err = m.DataDir("../escape")
+	assert.Error(t, err)
+}
+
+func TestManager_List(t *testing.T) {
+	dir := t.TempDir()
+	m := NewManager(filepath.Join(dir, "workspaces"), filepath.Join(dir, "curly.db"))
+
+	// A missing directory only has the default workspace.
+	names, err := m.List()
+	require.NoError(t, err)
+	assert.Equal(t, []string{Default}, names)
+
+	wsDir := filepath.Join(dir, "workspaces")
+	require.NoError(t, os.MkdirAll(filepath.Join(wsDir, "nested.db"), 0750))
+	for _, f := range []string{"personal.db", "client-a.db", "notes.txt", "default.db", "client-a.db-wal"} {
+		require.NoError(t, os.WriteFile(filepath.Join(wsDir, f), nil, 0600))
+	}
+
+	names, err = m.List()
+	require.NoError(t, err)
+	assert.Equal(t, []string{Default, "client-a", "personal"}, names)
+}
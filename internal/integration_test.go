@@ -98,6 +98,8 @@ func TestEndToEnd_RequestExecution(t *testing.T) {
 	assert.Equal(t, req.Name, loaded.Name)
 	assert.Equal(t, req.Method, loaded.Method)
 	assert.Equal(t, req.URL, loaded.URL)
+	assert.Equal(t, int64(1), loaded.ExecutionCount)
+	assert.False(t, loaded.LastExecutedAt.IsZero())
 
 	// Verify history was saved.
 	history, err := historyService.GetHistory(ctx, 10)
@@ -6,7 +6,7 @@ package presentation
 
 import (
 	tea "github.com/charmbracelet/bubbletea"
-	"github.com/williajm/curly/internal/app"
+	"github.com/williajm/curly/internal/infrastructure/imagepreview"
 	"github.com/williajm/curly/internal/presentation/models"
 )
 
@@ -14,20 +14,33 @@ import (
 //
 // It takes the application services as dependencies and wires them into the.
 // presentation layer models. The returned tea.Program is ready to run.
+// layout is the initial pane layout, and saveLayout, which may be nil, saves
+// the layout whenever the user changes it. imagePreview is how image
+// responses are previewed, keys are the keys of the global shortcuts, and
+// preferences select the tab shown on launch, what responses show and how
+// often drafts are saved.
 //
 // Example usage:.
 //
-//	program := presentation.NewApp(requestService, historyService, authService).
+//	services := models.Services{Request: requestService, History: historyService, Auth: authService}
+//	program := presentation.NewApp(services, layout, saveLayout, imagepreview.ProtocolAuto, models.DefaultKeymap(), models.DefaultPreferences()).
 //	if err := program.Start(); err != nil {.
 //		log.Fatal(err).
 //	}.
 func NewApp(
-	requestService *app.RequestService,
-	historyService *app.HistoryService,
-	authService *app.AuthService,
+	services models.Services,
+	layout models.Layout,
+	saveLayout func(models.Layout) error,
+	imagePreview imagepreview.Protocol,
+	keys models.Keymap,
+	preferences models.Preferences,
 ) *tea.Program {
 	// Create the main model with all services.
-	model := models.NewMainModel(requestService, historyService, authService)
+	model := models.NewMainModel(services)
+	model.SetLayout(layout, saveLayout)
+	model.SetImagePreview(imagePreview)
+	model.SetKeymap(keys)
+	model.SetPreferences(preferences)
 
 	// Create the Bubble Tea program with options.
 	program := tea.NewProgram(
@@ -44,13 +57,24 @@ func NewApp(
 // It creates a new Bubble Tea program and starts it immediately.
 // This is the simplest way to launch the TUI from main.go.
 //
+// It returns the workspace the user chose to switch to, or "" if they quit.
 // Returns an error if the program fails to start or encounters a runtime error.
 func RunApp(
-	requestService *app.RequestService,
-	historyService *app.HistoryService,
-	authService *app.AuthService,
-) error {
-	program := NewApp(requestService, historyService, authService)
-	_, err := program.Run()
-	return err
+	services models.Services,
+	layout models.Layout,
+	saveLayout func(models.Layout) error,
+	imagePreview imagepreview.Protocol,
+	keys models.Keymap,
+	preferences models.Preferences,
+) (string, error) {
+	program := NewApp(services, layout, saveLayout, imagePreview, keys, preferences)
+	final, err := program.Run()
+	if err != nil {
+		return "", err
+	}
+
+	if m, ok := final.(models.MainModel); ok {
+		return m.SwitchWorkspace(), nil
+	}
+	return "", nil
 }
@@ -0,0 +1,515 @@
+package components
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/williajm/curly/internal/presentation/styles"
+)
+
+// KeyValueRow is one row of a KeyValueEditor.
+type KeyValueRow struct {
+	Key   string
+	Value string
+
+	// Disabled rows are kept in the editor but left out of Map.
+	Disabled bool
+}
+
+// KeyValueConfig configures a KeyValueEditor.
+type KeyValueConfig struct {
+	// Noun names one row in messages, such as "header".
+	Noun string
+
+	// Validate checks a row with a non-empty key. It may be nil.
+	Validate func(key, value string) error
+
+	// FoldKeys treats keys that differ only in case as duplicates, as HTTP
+	// header names are.
+	FoldKeys bool
+
+	// Toggles lets Space enable and disable rows.
+	Toggles bool
+
+	// AllowDuplicates accepts repeated keys, as form fields may have. Map
+	// keeps only the last of them.
+	AllowDuplicates bool
+}
+
+// Editor columns.
+const (
+	columnKey = iota
+	columnValue
+)
+
+// keyColumnWidth is the width keys are padded to so values line up.
+const keyColumnWidth = 24
+
+// maxSuggestionsShown is how many completions are listed under a cell being
+// edited.
+const maxSuggestionsShown = 5
+
+// KeyValueEditor is an editable list of key-value rows, such as request
+// headers. Rows can be added, edited, deleted and reordered; invalid rows are
+// flagged inline and make Err return an error.
+type KeyValueEditor struct {
+	config KeyValueConfig
+	rows   []KeyValueRow
+
+	// Selection.
+	cursor  int
+	column  int
+	focused bool
+
+	// Cell editing. adding is set while editing the key of a row that was just
+	// added, so cancelling removes the row again.
+	input   textinput.Model
+	editing bool
+	adding  bool
+
+	// Completions offered while editing; see SetSuggestions.
+	keySuggestions   []string
+	valueSuggestions func(key string) []string
+}
+
+// NewKeyValueEditor creates an empty editor.
+func NewKeyValueEditor(config KeyValueConfig) KeyValueEditor {
+	input := textinput.New()
+	input.Prompt = ""
+	input.Width = keyColumnWidth
+
+	return KeyValueEditor{
+		config: config,
+		input:  input,
+	}
+}
+
+// SetRows replaces the rows and resets the selection.
+func (e *KeyValueEditor) SetRows(rows []KeyValueRow) {
+	e.rows = slices.Clone(rows)
+	e.cursor = 0
+	e.column = columnKey
+	e.stopEditing()
+}
+
+// SetMap replaces the rows with the entries of m, sorted by key.
+func (e *KeyValueEditor) SetMap(m map[string]string) {
+	e.SetMaps(m, nil)
+}
+
+// SetMaps replaces the rows with the entries of enabled and, disabled, those
+// of disabled, sorted by key.
+func (e *KeyValueEditor) SetMaps(enabled, disabled map[string]string) {
+	rows := make([]KeyValueRow, 0, len(enabled)+len(disabled))
+	for key, value := range enabled {
+		rows = append(rows, KeyValueRow{Key: key, Value: value})
+	}
+	for key, value := range disabled {
+		rows = append(rows, KeyValueRow{Key: key, Value: value, Disabled: true})
+	}
+	slices.SortStableFunc(rows, func(a, b KeyValueRow) int { return strings.Compare(a.Key, b.Key) })
+	e.SetRows(rows)
+}
+
+// Set replaces the value of the first row whose key matches key, honouring
+// FoldKeys, or appends a row if none does.
+func (e *KeyValueEditor) Set(key, value string) {
+	for i, row := range e.rows {
+		if row.Key == key || (e.config.FoldKeys && strings.EqualFold(row.Key, key)) {
+			e.rows[i].Value = value
+			e.rows[i].Disabled = false
+			return
+		}
+	}
+	e.rows = append(e.rows, KeyValueRow{Key: key, Value: value})
+}
+
+// Get returns the value of the first enabled row whose key matches key,
+// honouring FoldKeys.
+func (e KeyValueEditor) Get(key string) (string, bool) {
+	for _, row := range e.rows {
+		if !row.Disabled && (row.Key == key || (e.config.FoldKeys && strings.EqualFold(row.Key, key))) {
+			return row.Value, true
+		}
+	}
+	return "", false
+}
+
+// SetSuggestions sets the completions offered while a cell is edited: keys
+// for keys, leaving out those of other rows, and values(key) for the value
+// of a row with key. values may be nil.
+func (e *KeyValueEditor) SetSuggestions(keys []string, values func(key string) []string) {
+	e.keySuggestions = keys
+	e.valueSuggestions = values
+}
+
+// Rows returns the rows in order.
+func (e KeyValueEditor) Rows() []KeyValueRow {
+	return slices.Clone(e.rows)
+}
+
+// Map returns the enabled rows with a non-empty key as a map. If keys
+// repeat, the last row wins; Err reports the duplicate unless AllowDuplicates
+// is set.
+func (e KeyValueEditor) Map() map[string]string {
+	m := make(map[string]string, len(e.rows))
+	for _, row := range e.rows {
+		if !row.Disabled && strings.TrimSpace(row.Key) != "" {
+			m[row.Key] = row.Value
+		}
+	}
+	return m
+}
+
+// DisabledMap returns the disabled rows with a non-empty key as a map, or nil
+// if there are none. If keys repeat, the last row wins.
+func (e KeyValueEditor) DisabledMap() map[string]string {
+	var m map[string]string
+	for _, row := range e.rows {
+		if row.Disabled && strings.TrimSpace(row.Key) != "" {
+			if m == nil {
+				m = make(map[string]string)
+			}
+			m[row.Key] = row.Value
+		}
+	}
+	return m
+}
+
+// Err returns the first row's validation error, or nil if every row is valid.
+func (e KeyValueEditor) Err() error {
+	errs := e.rowErrors()
+	for i := range e.rows {
+		if errs[i] != nil {
+			return fmt.Errorf("%s %d: %w", e.config.Noun, i+1, errs[i])
+		}
+	}
+	return nil
+}
+
+// rowErrors validates every row. Blank and disabled rows are ignored.
+func (e KeyValueEditor) rowErrors() []error {
+	errs := make([]error, len(e.rows))
+	seen := make(map[string]bool, len(e.rows))
+	for i, row := range e.rows {
+		if row.Disabled {
+			continue
+		}
+		key := strings.TrimSpace(row.Key)
+		if key == "" {
+			if row.Value != "" {
+				errs[i] = fmt.Errorf("a %s with a value needs a name", e.config.Noun)
+			}
+			continue
+		}
+		if e.config.Validate != nil {
+			if err := e.config.Validate(row.Key, row.Value); err != nil {
+				errs[i] = err
+				continue
+			}
+		}
+		if e.config.FoldKeys {
+			key = strings.ToLower(key)
+		}
+		if seen[key] && !e.config.AllowDuplicates {
+			errs[i] = fmt.Errorf("duplicate %s %q", e.config.Noun, row.Key)
+		}
+		seen[key] = true
+	}
+	return errs
+}
+
+// Focus gives the editor keyboard focus.
+func (e *KeyValueEditor) Focus() {
+	e.focused = true
+}
+
+// Blur removes keyboard focus, keeping any cell being edited.
+func (e *KeyValueEditor) Blur() {
+	if e.editing {
+		e.commit()
+	}
+	e.focused = false
+}
+
+// Focused reports whether the editor has keyboard focus.
+func (e KeyValueEditor) Focused() bool {
+	return e.focused
+}
+
+// Editing reports whether a cell is being edited, in which case every key,
+// including q, Tab and Esc, belongs to the editor.
+func (e KeyValueEditor) Editing() bool {
+	return e.editing
+}
+
+// Update handles keyboard input while the editor is focused.
+//
+// Browsing: ↑/↓ (k/j) choose a row, ←/→ (h/l) choose the key or value, Enter
+// edits the cell, a adds a row, d deletes one, Shift+↑/↓ (K/J) move it and,
+// with Toggles, Space enables or disables it.
+// Editing: Enter or Tab keeps the change (Tab moves from key to value), Esc
+// discards it.
+func (e KeyValueEditor) Update(msg tea.Msg) (KeyValueEditor, tea.Cmd) {
+	key, ok := msg.(tea.KeyMsg)
+	if !ok || !e.focused {
+		return e, nil
+	}
+	if e.editing {
+		return e.updateEditing(key)
+	}
+
+	switch key.String() {
+	case "up", "k":
+		e.cursor = max(e.cursor-1, 0)
+	case "down", "j":
+		e.cursor = min(e.cursor+1, max(len(e.rows)-1, 0))
+	case "left", "h":
+		e.column = columnKey
+	case "right", "l":
+		e.column = columnValue
+	case "enter":
+		if len(e.rows) > 0 {
+			return e, e.startEditing()
+		}
+	case "a", "n":
+		e.rows = append(e.rows, KeyValueRow{})
+		e.cursor = len(e.rows) - 1
+		e.column = columnKey
+		e.adding = true
+		return e, e.startEditing()
+	case " ":
+		e.toggleRow()
+	case "d", "delete":
+		e.deleteRow()
+	case "shift+up", "K":
+		e.moveRow(-1)
+	case "shift+down", "J":
+		e.moveRow(1)
+	}
+	return e, nil
+}
+
+// toggleRow enables or disables the selected row, if the editor has toggles.
+func (e *KeyValueEditor) toggleRow() {
+	if e.config.Toggles && len(e.rows) > 0 {
+		e.rows[e.cursor].Disabled = !e.rows[e.cursor].Disabled
+	}
+}
+
+// deleteRow deletes the selected row, if any.
+func (e *KeyValueEditor) deleteRow() {
+	if len(e.rows) > 0 {
+		e.rows = slices.Delete(e.rows, e.cursor, e.cursor+1)
+		e.cursor = min(e.cursor, max(len(e.rows)-1, 0))
+	}
+}
+
+// moveRow swaps the selected row with the one delta rows away, if there is
+// one, keeping it selected.
+func (e *KeyValueEditor) moveRow(delta int) {
+	target := e.cursor + delta
+	if target < 0 || target >= len(e.rows) {
+		return
+	}
+	e.rows[e.cursor], e.rows[target] = e.rows[target], e.rows[e.cursor]
+	e.cursor = target
+}
+
+// updateEditing handles keyboard input while a cell is being edited.
+func (e KeyValueEditor) updateEditing(key tea.KeyMsg) (KeyValueEditor, tea.Cmd) {
+	switch key.String() {
+	case "enter":
+		e.commit()
+		return e, nil
+	case "tab":
+		// Tab completes first, then keeps the change.
+		if e.complete() {
+			return e, nil
+		}
+		e.commit()
+		if e.column == columnKey {
+			e.column = columnValue
+			return e, e.startEditing()
+		}
+		return e, nil
+	case "esc":
+		if e.adding {
+			e.deleteRow()
+		}
+		e.stopEditing()
+		return e, nil
+	}
+
+	var cmd tea.Cmd
+	e.input, cmd = e.input.Update(key)
+	return e, cmd
+}
+
+// startEditing opens the selected cell for editing.
+func (e *KeyValueEditor) startEditing() tea.Cmd {
+	row := e.rows[e.cursor]
+	value := row.Key
+	if e.column == columnValue {
+		value = row.Value
+	}
+	e.input.SetValue(value)
+	e.input.CursorEnd()
+	e.input.ShowSuggestions = true
+	e.input.SetSuggestions(e.suggestions())
+	e.editing = true
+	return e.input.Focus()
+}
+
+// suggestions returns the completions for the selected cell.
+func (e KeyValueEditor) suggestions() []string {
+	if e.column == columnValue {
+		if e.valueSuggestions == nil {
+			return nil
+		}
+		return e.valueSuggestions(e.rows[e.cursor].Key)
+	}
+
+	var keys []string
+	for _, key := range e.keySuggestions {
+		used := slices.ContainsFunc(e.rows, func(row KeyValueRow) bool {
+			return row.Key == key || (e.config.FoldKeys && strings.EqualFold(row.Key, key))
+		})
+		if !used || e.rows[e.cursor].Key == key {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// complete replaces the text being edited with the completion chosen, and
+// reports whether it did; it does not if the text is that completion already.
+func (e *KeyValueEditor) complete() bool {
+	suggestion := e.input.CurrentSuggestion()
+	if len(e.input.MatchedSuggestions()) == 0 || suggestion == e.input.Value() {
+		return false
+	}
+	e.input.SetValue(suggestion)
+	e.input.CursorEnd()
+	e.input.SetSuggestions(e.suggestions())
+	return true
+}
+
+// commit stores the edited cell.
+func (e *KeyValueEditor) commit() {
+	if e.column == columnKey {
+		e.rows[e.cursor].Key = strings.TrimSpace(e.input.Value())
+	} else {
+		e.rows[e.cursor].Value = e.input.Value()
+	}
+	e.stopEditing()
+}
+
+// stopEditing closes the cell editor.
+func (e *KeyValueEditor) stopEditing() {
+	e.editing = false
+	e.adding = false
+	e.input.Blur()
+}
+
+// View renders the rows, flagging invalid ones, and the editor's keys when focused.
+func (e KeyValueEditor) View() string {
+	var lines []string
+	errs := e.rowErrors()
+
+	if len(e.rows) == 0 {
+		lines = append(lines, fmt.Sprintf("  (no %ss)", e.config.Noun))
+	}
+	for i, row := range e.rows {
+		selected := e.focused && i == e.cursor
+		keyCell, valueCell := row.Key, row.Value
+		if selected {
+			if e.column == columnKey {
+				keyCell = e.cell(row.Key)
+			} else {
+				valueCell = e.cell(row.Value)
+			}
+		}
+
+		cursor := "  "
+		if selected {
+			cursor = "> "
+		}
+		if e.config.Toggles {
+			if row.Disabled {
+				cursor += "[ ] "
+			} else {
+				cursor += "[x] "
+			}
+		}
+		lines = append(lines, fmt.Sprintf("%s%-*s %s", cursor, keyColumnWidth, keyCell, valueCell))
+		if errs[i] != nil {
+			lines = append(lines, styles.ErrorStyle.Render("    ✗ "+errs[i].Error()))
+		}
+	}
+
+	if e.focused {
+		if e.editing {
+			if suggestions := e.renderSuggestions(); suggestions != "" {
+				lines = append(lines, suggestions)
+			}
+			lines = append(lines, "  Enter: keep • Tab: complete, or keep and edit value • Esc: discard")
+		} else {
+			help := "  a: add • Enter: edit • d: delete • ←→: key/value • Shift+↑↓: move"
+			if e.config.Toggles {
+				help += " • Space: on/off"
+			}
+			lines = append(lines, help)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// renderSuggestions lists the completions of the text being edited, the one
+// Tab takes bracketed, or every completion while nothing is typed. It returns
+// "" if there are none.
+func (e KeyValueEditor) renderSuggestions() string {
+	if e.input.Value() == "" {
+		// Completions are matched once something is typed.
+		available := e.input.AvailableSuggestions()
+		if len(available) == 0 {
+			return ""
+		}
+		parts := available[:min(len(available), maxSuggestionsShown)]
+		if hidden := len(available) - len(parts); hidden > 0 {
+			parts = append(slices.Clone(parts), fmt.Sprintf("+%d more", hidden))
+		}
+		return "    " + strings.Join(parts, " • ") + " (type to complete)"
+	}
+
+	matched := e.input.MatchedSuggestions()
+	if len(matched) == 0 || (len(matched) == 1 && matched[0] == e.input.Value()) {
+		return ""
+	}
+
+	current := e.input.CurrentSuggestionIndex()
+	first := max(0, min(current-maxSuggestionsShown/2, len(matched)-maxSuggestionsShown))
+	var parts []string
+	for i := first; i < min(first+maxSuggestionsShown, len(matched)); i++ {
+		if i == current {
+			parts = append(parts, "["+matched[i]+"]")
+		} else {
+			parts = append(parts, matched[i])
+		}
+	}
+	if hidden := len(matched) - len(parts); hidden > 0 {
+		parts = append(parts, fmt.Sprintf("+%d more", hidden))
+	}
+	return "    " + strings.Join(parts, " • ") + " (↑↓: choose)"
+}
+
+// cell renders the selected cell: the input while editing, bracketed otherwise.
+func (e KeyValueEditor) cell(text string) string {
+	if e.editing {
+		return e.input.View()
+	}
+	return "[" + text + "]"
+}
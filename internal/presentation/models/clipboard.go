@@ -0,0 +1,25 @@
+package models
+
+import (
+	"os"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/williajm/curly/internal/infrastructure/clipboard"
+)
+
+// clipboardMsg reports the outcome of copying something to the clipboard.
+type clipboardMsg struct {
+	what   string // What was copied, such as "response body"
+	method string // The clipboard command used, or clipboard.MethodOSC52
+	err    error
+}
+
+// copyToClipboard returns a command that puts text on the system clipboard,
+// falling back to asking the terminal with OSC 52. what describes the text
+// in the status bar.
+func copyToClipboard(what, text string) tea.Cmd {
+	return func() tea.Msg {
+		method, err := clipboard.New(os.Stdout).Copy(text)
+		return clipboardMsg{what: what, method: method, err: err}
+	}
+}
@@ -0,0 +1,133 @@
+package models
+
+import (
+	"slices"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/williajm/curly/internal/app"
+	"github.com/williajm/curly/internal/domain"
+	"github.com/williajm/curly/internal/infrastructure/sharelink"
+)
+
+// shareLinkOption is the menu entry, after the languages, that copies a share link.
+const shareLinkOption = "share link (credentials stripped)"
+
+// CodegenModel represents the "copy as…" menu.
+type CodegenModel struct {
+	// Services.
+	codegenService *app.CodegenService
+
+	// Request to render and the chosen language.
+	request       *domain.Request
+	selectedIndex int
+
+	// Last generated snippet, and the secrets left out of a share link.
+	snippet  string
+	stripped []string
+	errorMsg string
+}
+
+// NewCodegenModel creates a new "copy as…" menu model.
+func NewCodegenModel(codegenService *app.CodegenService) CodegenModel {
+	return CodegenModel{
+		codegenService: codegenService,
+	}
+}
+
+// Open resets the menu for req.
+func (m *CodegenModel) Open(req *domain.Request) {
+	m.request = req
+	m.snippet = ""
+	m.stripped = nil
+	m.errorMsg = ""
+}
+
+// Update handles messages and updates the model.
+func (m CodegenModel) Update(msg tea.Msg) (CodegenModel, tea.Cmd) {
+	key, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+
+	options := m.options()
+	switch key.String() {
+	case "up", "k":
+		if m.selectedIndex > 0 {
+			m.selectedIndex--
+		}
+	case "down", "j":
+		if m.selectedIndex < len(options)-1 {
+			m.selectedIndex++
+		}
+	case "enter":
+		var (
+			snippet  string
+			stripped []string
+			err      error
+		)
+		if options[m.selectedIndex] == shareLinkOption {
+			snippet, stripped, err = m.codegenService.ShareLink(m.request, sharelink.Options{})
+		} else {
+			snippet, err = m.codegenService.Generate(m.request, options[m.selectedIndex])
+		}
+		if err != nil {
+			m.snippet = ""
+			m.stripped = nil
+			m.errorMsg = err.Error()
+			return m, nil
+		}
+		m.errorMsg = ""
+		m.snippet = snippet
+		m.stripped = stripped
+		what := options[m.selectedIndex] + " snippet"
+		if options[m.selectedIndex] == shareLinkOption {
+			what = "share link"
+		}
+		return m, copyToClipboard(what, snippet)
+	}
+
+	return m, nil
+}
+
+// View renders the menu and the last generated snippet.
+func (m CodegenModel) View() string {
+	var sections []string
+
+	sections = append(sections, "══ Copy as… ══")
+	sections = append(sections, "")
+
+	for i, option := range m.options() {
+		cursor := "  "
+		if i == m.selectedIndex {
+			cursor = "> "
+		}
+		sections = append(sections, cursor+option)
+	}
+
+	if m.errorMsg != "" {
+		sections = append(sections, "")
+		sections = append(sections, "Error: "+m.errorMsg)
+	}
+
+	if m.snippet != "" {
+		sections = append(sections, "")
+		sections = append(sections, strings.TrimSuffix(m.snippet, "\n"))
+		if len(m.stripped) > 0 {
+			sections = append(sections, "")
+			sections = append(sections, "Left out: "+strings.Join(m.stripped, ", "))
+		}
+		sections = append(sections, "")
+		sections = append(sections, "Copied to the clipboard.")
+	}
+
+	sections = append(sections, "")
+	sections = append(sections, "↑↓: choose language • Enter: copy • Esc: close")
+
+	return strings.Join(sections, "\n")
+}
+
+// options returns the menu entries: the snippet languages, then the share link.
+func (m CodegenModel) options() []string {
+	return append(slices.Clone(m.codegenService.Languages()), shareLinkOption)
+}
@@ -0,0 +1,373 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/williajm/curly/internal/app"
+	"github.com/williajm/curly/internal/domain"
+	"github.com/williajm/curly/internal/presentation/styles"
+)
+
+// collectionRow is one visible line of the collection tree: a folder or a
+// request, indented by its depth.
+type collectionRow struct {
+	depth   int
+	folder  *app.CollectionNode
+	request *domain.Request
+}
+
+// CollectionsModel represents the collections panel, which shows saved
+// requests as a tree of folders.
+type CollectionsModel struct {
+	// Services.
+	collectionService *app.CollectionService
+	requestService    *app.RequestService
+
+	// Tree and the rows currently visible in it.
+	tree          *app.CollectionNode
+	rows          []collectionRow
+	expanded      map[string]bool
+	selectedIndex int
+	loading       bool
+	errorMsg      string
+
+	// cut is the request cut to be pasted into another folder.
+	cut *domain.Request
+
+	// renaming is the request being renamed in place, and nameInput holds
+	// its new name.
+	renaming  *domain.Request
+	nameInput textinput.Model
+
+	// chosen is set once a request has been chosen to open.
+	chosen *domain.Request
+}
+
+// Custom messages.
+type collectionsLoadedMsg struct {
+	tree *app.CollectionNode
+	err  error
+}
+
+type collectionMovedMsg struct {
+	folder string
+	err    error
+}
+
+// collectionRunMsg asks for every request in a folder to be run.
+type collectionRunMsg struct {
+	folder string
+}
+
+// NewCollectionsModel creates a new collections panel model.
+func NewCollectionsModel(collectionService *app.CollectionService, requestService *app.RequestService) CollectionsModel {
+	nameInput := textinput.New()
+	nameInput.Prompt = ""
+	nameInput.Placeholder = "Request name"
+	nameInput.CharLimit = 100
+
+	return CollectionsModel{
+		collectionService: collectionService,
+		requestService:    requestService,
+		nameInput:         nameInput,
+		expanded:          make(map[string]bool),
+	}
+}
+
+// Open starts loading the tree. Expanded folders and a cut request are kept.
+func (m *CollectionsModel) Open() tea.Cmd {
+	m.errorMsg = ""
+	m.chosen = nil
+	m.renaming = nil
+	m.nameInput.Blur()
+	return m.load()
+}
+
+// Chosen returns the request chosen to open, or nil if none has been.
+func (m CollectionsModel) Chosen() *domain.Request {
+	return m.chosen
+}
+
+// load returns a command that loads the collection tree.
+func (m *CollectionsModel) load() tea.Cmd {
+	m.loading = true
+	return func() tea.Msg {
+		tree, err := m.collectionService.Tree(context.Background())
+		return collectionsLoadedMsg{tree: tree, err: err}
+	}
+}
+
+// Update handles messages and updates the model.
+func (m CollectionsModel) Update(msg tea.Msg) (CollectionsModel, tea.Cmd) {
+	switch msg := msg.(type) {
+	case collectionsLoadedMsg:
+		m.loading = false
+		if msg.err != nil {
+			m.errorMsg = msg.err.Error()
+			return m, nil
+		}
+		m.tree = msg.tree
+		m.refreshRows()
+
+	case collectionMovedMsg:
+		m.loading = false
+		if msg.err != nil {
+			m.errorMsg = msg.err.Error()
+			return m, nil
+		}
+		// Show the request where it was pasted.
+		m.expandPath(msg.folder)
+		return m, m.load()
+
+	case collectionRenamedMsg:
+		m.loading = false
+		if msg.err != nil {
+			m.errorMsg = msg.err.Error()
+			return m, nil
+		}
+		return m, m.load()
+
+	case tea.KeyMsg:
+		if m.loading {
+			return m, nil
+		}
+		if m.renaming != nil {
+			return m.updateRename(msg)
+		}
+		return m.handleKey(msg)
+	}
+
+	return m, nil
+}
+
+// handleKey handles keyboard input for tree navigation.
+func (m CollectionsModel) handleKey(msg tea.KeyMsg) (CollectionsModel, tea.Cmd) {
+	if len(m.rows) == 0 {
+		return m, nil
+	}
+	row := m.rows[m.selectedIndex]
+	if m.moveCursor(msg.String()) {
+		return m, nil
+	}
+
+	switch msg.String() {
+	case "right", "l":
+		if row.folder != nil && !m.expanded[row.folder.Path] {
+			m.expanded[row.folder.Path] = true
+			m.refreshRows()
+		}
+
+	case "left", "h":
+		m.collapse(row)
+
+	case "enter":
+		if row.folder != nil {
+			m.expanded[row.folder.Path] = !m.expanded[row.folder.Path]
+			m.refreshRows()
+			return m, nil
+		}
+		m.chosen = row.request
+
+	case "x":
+		m.toggleCut(row)
+
+	case "p":
+		return m, m.paste(row)
+
+	case "n":
+		// Rename the selected request in place.
+		if row.request != nil {
+			return m, m.startRename(row.request)
+		}
+
+	case "r":
+		// Run every request in the selected folder.
+		folder := m.rowFolder(row)
+		return m, func() tea.Msg { return collectionRunMsg{folder: folder} }
+	}
+
+	return m, nil
+}
+
+// moveCursor handles the navigation keys, reporting whether key was one.
+func (m *CollectionsModel) moveCursor(key string) bool {
+	switch key {
+	case "up", "k":
+		m.selectedIndex = max(m.selectedIndex-1, 0)
+	case "down", "j":
+		m.selectedIndex = min(m.selectedIndex+1, len(m.rows)-1)
+	case "home", "g":
+		m.selectedIndex = 0
+	case "end", "G":
+		m.selectedIndex = len(m.rows) - 1
+	default:
+		return false
+	}
+	return true
+}
+
+// collapse collapses the selected folder if it is open, or otherwise moves
+// up to the enclosing folder.
+func (m *CollectionsModel) collapse(row collectionRow) {
+	if row.folder != nil && m.expanded[row.folder.Path] {
+		m.expanded[row.folder.Path] = false
+		m.refreshRows()
+		return
+	}
+	for i := m.selectedIndex - 1; i >= 0; i-- {
+		if m.rows[i].folder != nil && m.rows[i].depth < row.depth {
+			m.selectedIndex = i
+			return
+		}
+	}
+}
+
+// toggleCut cuts the selected request, or cancels the cut.
+func (m *CollectionsModel) toggleCut(row collectionRow) {
+	if row.request == nil {
+		return
+	}
+	if m.cut != nil && m.cut.ID == row.request.ID {
+		m.cut = nil
+	} else {
+		m.cut = row.request
+	}
+}
+
+// paste moves the cut request to the end of the selected folder.
+func (m *CollectionsModel) paste(row collectionRow) tea.Cmd {
+	if m.cut == nil {
+		return nil
+	}
+	folder := m.rowFolder(row)
+	if folder == m.cut.Folder {
+		m.cut = nil
+		return nil
+	}
+	id := m.cut.ID
+	m.cut = nil
+	m.loading = true
+	service := m.collectionService
+	return func() tea.Msg {
+		err := service.MoveToFolder(context.Background(), []string{id}, folder)
+		return collectionMovedMsg{folder: folder, err: err}
+	}
+}
+
+// rowFolder returns the folder a row stands for: the folder itself, or the
+// folder holding the request.
+func (m CollectionsModel) rowFolder(row collectionRow) string {
+	if row.folder != nil {
+		return row.folder.Path
+	}
+	return row.request.Folder
+}
+
+// expandPath expands folder and every folder enclosing it.
+func (m *CollectionsModel) expandPath(folder string) {
+	for path := folder; path != ""; {
+		m.expanded[path] = true
+		i := strings.LastIndex(path, app.FolderSeparator)
+		if i < 0 {
+			break
+		}
+		path = path[:i]
+	}
+}
+
+// refreshRows lists the visible rows: the top-level folders and requests,
+// and the contents of every expanded folder.
+func (m *CollectionsModel) refreshRows() {
+	m.rows = nil
+	if m.tree != nil {
+		m.appendRows(m.tree, 0)
+	}
+	m.selectedIndex = min(m.selectedIndex, max(len(m.rows)-1, 0))
+}
+
+// appendRows appends the rows for the contents of node at depth.
+func (m *CollectionsModel) appendRows(node *app.CollectionNode, depth int) {
+	for _, folder := range node.Folders {
+		m.rows = append(m.rows, collectionRow{depth: depth, folder: folder})
+		if m.expanded[folder.Path] {
+			m.appendRows(folder, depth+1)
+		}
+	}
+	for _, req := range node.Requests {
+		m.rows = append(m.rows, collectionRow{depth: depth, request: req})
+	}
+}
+
+// View renders the collection tree.
+func (m CollectionsModel) View() string {
+	var sections []string
+
+	sections = append(sections, "══ Collections ══")
+	sections = append(sections, "")
+
+	if m.loading && m.tree == nil {
+		sections = append(sections, "Loading collections...")
+		return strings.Join(sections, "\n")
+	}
+
+	if len(m.rows) == 0 {
+		sections = append(sections, "No saved requests yet.")
+	}
+	for i, row := range m.rows {
+		cursor := "  "
+		if i == m.selectedIndex {
+			cursor = "> "
+		}
+		indent := strings.Repeat("  ", row.depth)
+
+		if row.folder != nil {
+			marker := "▸"
+			if m.expanded[row.folder.Path] {
+				marker = "▾"
+			}
+			sections = append(sections, fmt.Sprintf("%s%s%s %s/ (%d)", cursor, indent, marker, row.folder.Name, row.folder.Count()))
+			continue
+		}
+
+		label := requestLabel(row.request)
+		if m.renaming != nil && m.renaming.ID == row.request.ID {
+			label = m.nameInput.View()
+		}
+		line := fmt.Sprintf("%s%s  %s %s", cursor, indent, styles.RenderMethod(row.request.Method, 7), label)
+		if m.cut != nil && m.cut.ID == row.request.ID {
+			line += "  ✂"
+		}
+		sections = append(sections, line)
+	}
+
+	if m.errorMsg != "" {
+		sections = append(sections, "")
+		sections = append(sections, "Error: "+m.errorMsg)
+	}
+
+	if m.cut != nil {
+		sections = append(sections, "")
+		sections = append(sections, "Cut "+requestLabel(m.cut)+": choose a folder and press p to paste")
+	}
+
+	sections = append(sections, "")
+	if m.renaming != nil {
+		sections = append(sections, "Enter: save name • Esc: cancel")
+	} else {
+		sections = append(sections, "↑↓: navigate • →/←: expand/collapse • Enter: open • n: rename • x: cut • p: paste • r: run folder • Esc: close")
+	}
+
+	return strings.Join(sections, "\n")
+}
+
+// requestLabel returns the name of a saved request, or its URL if unnamed.
+func requestLabel(req *domain.Request) string {
+	if req.Name != "" {
+		return req.Name
+	}
+	return req.URL
+}
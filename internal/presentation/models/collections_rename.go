@@ -0,0 +1,69 @@
+package models
+
+import (
+	"context"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/williajm/curly/internal/domain"
+)
+
+// collectionRenamedMsg reports the outcome of renaming a saved request.
+type collectionRenamedMsg struct {
+	request *domain.Request
+	err     error
+}
+
+// startRename opens the name input on the request under the cursor,
+// prefilled with its current name.
+func (m *CollectionsModel) startRename(req *domain.Request) tea.Cmd {
+	m.renaming = req
+	m.errorMsg = ""
+	m.nameInput.SetValue(req.Name)
+	m.nameInput.CursorEnd()
+	return m.nameInput.Focus()
+}
+
+// updateRename handles keyboard input while a request is being renamed:
+// Enter saves the new name and Esc keeps the old one.
+func (m CollectionsModel) updateRename(msg tea.KeyMsg) (CollectionsModel, tea.Cmd) {
+	switch msg.String() {
+	case KeyCtrlC:
+		return m, tea.Quit
+	case "enter":
+		name := strings.TrimSpace(m.nameInput.Value())
+		req := m.renaming
+		m.renaming = nil
+		m.nameInput.Blur()
+		if name == req.Name {
+			return m, nil
+		}
+		return m, m.rename(req, name)
+	case "esc":
+		m.renaming = nil
+		m.nameInput.Blur()
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.nameInput, cmd = m.nameInput.Update(msg)
+	return m, cmd
+}
+
+// rename returns a command that saves req under a new name. A copy is saved
+// so the tree keeps the old name until it is reloaded.
+func (m *CollectionsModel) rename(req *domain.Request, name string) tea.Cmd {
+	renamed := req.Clone()
+	renamed.Name = name
+	m.loading = true
+	return func() tea.Msg {
+		err := m.requestService.SaveRequest(context.Background(), renamed)
+		return collectionRenamedMsg{request: renamed, err: err}
+	}
+}
+
+// Renaming reports whether a request is being renamed, so that keys such as
+// Esc are handled by the name input rather than closing the panel.
+func (m CollectionsModel) Renaming() bool {
+	return m.renaming != nil
+}
@@ -0,0 +1,195 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textarea"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/williajm/curly/internal/app"
+	"github.com/williajm/curly/internal/domain"
+	"github.com/williajm/curly/internal/infrastructure/sharelink"
+)
+
+// ImportedRequest is a request parsed by the import dialog.
+type ImportedRequest struct {
+	// Request is the parsed request. It is not saved.
+	Request *domain.Request
+
+	// Warnings describe what the pasted text could not carry, such as
+	// unsupported curl options or secrets left out of a share link.
+	Warnings []string
+
+	// Source names what was pasted, such as "curl command".
+	Source string
+
+	// Scripts describe the scripts a share link carries, such as
+	// "pre-request script".
+	Scripts []string
+}
+
+// CurlImportModel represents the "Import curl" dialog, which also accepts
+// share links.
+type CurlImportModel struct {
+	// Services.
+	importService *app.ImportService
+
+	// Command input.
+	input    textarea.Model
+	errorMsg string
+
+	// result is set once a command or link has been parsed.
+	result *ImportedRequest
+
+	// pending is a parsed share link whose scripts the user has yet to keep
+	// or leave out.
+	pending *ImportedRequest
+}
+
+// NewCurlImportModel creates a new curl import dialog model.
+func NewCurlImportModel(importService *app.ImportService) CurlImportModel {
+	input := textarea.New()
+	input.Placeholder = "curl -X POST https://api.example.com/items -H 'Content-Type: application/json' -d '{}'"
+	input.SetWidth(80)
+	input.SetHeight(8)
+	// Enter imports the command; pasted newlines are still accepted.
+	input.KeyMap.InsertNewline.SetEnabled(false)
+
+	return CurlImportModel{
+		importService: importService,
+		input:         input,
+	}
+}
+
+// Open clears the dialog and focuses the command input.
+func (m *CurlImportModel) Open() tea.Cmd {
+	m.input.Reset()
+	m.errorMsg = ""
+	m.result = nil
+	m.pending = nil
+	return m.input.Focus()
+}
+
+// Update handles messages and updates the model. A share link that carries
+// scripts is only loaded once the user has seen them and chosen to keep
+// them or leave them out, since they run whenever the request is sent.
+func (m CurlImportModel) Update(msg tea.Msg) (CurlImportModel, tea.Cmd) {
+	key, isKey := msg.(tea.KeyMsg)
+	if m.pending != nil {
+		if !isKey {
+			return m, nil
+		}
+		switch key.String() {
+		case "y":
+			m.result, m.pending = m.pending, nil
+		case "n":
+			req := m.pending.Request
+			req.PreRequestScript = ""
+			req.PostResponseScript = ""
+			m.pending.Warnings = append(m.pending.Warnings, "left out its "+strings.Join(m.pending.Scripts, " and "))
+			m.result, m.pending = m.pending, nil
+		}
+		return m, nil
+	}
+
+	if isKey && key.String() == "enter" {
+		result, err := m.parse(m.input.Value())
+		if err != nil {
+			m.errorMsg = err.Error()
+			return m, nil
+		}
+		m.errorMsg = ""
+		if len(result.Scripts) > 0 {
+			m.pending = result
+		} else {
+			m.result = result
+		}
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(msg)
+	return m, cmd
+}
+
+// parse parses text as a share link if it starts with the link prefix, and as
+// a curl command otherwise.
+func (m CurlImportModel) parse(text string) (*ImportedRequest, error) {
+	if sharelink.IsLink(text) {
+		result, err := m.importService.ParseLink(text)
+		if err != nil {
+			return nil, err
+		}
+		warnings := make([]string, len(result.Stripped))
+		for i, stripped := range result.Stripped {
+			warnings[i] = "no " + stripped
+		}
+		return &ImportedRequest{Request: result.Request, Warnings: warnings, Source: "share link", Scripts: result.Scripts()}, nil
+	}
+
+	result, err := m.importService.ParseCurl(text)
+	if err != nil {
+		return nil, err
+	}
+	return &ImportedRequest{Request: result.Request, Warnings: result.Warnings, Source: "curl command"}, nil
+}
+
+// View renders the curl import dialog.
+func (m CurlImportModel) View() string {
+	var sections []string
+
+	sections = append(sections, "══ Import curl or share link ══")
+	sections = append(sections, "")
+	if m.pending != nil {
+		return strings.Join(append(sections, m.renderScripts()...), "\n")
+	}
+	sections = append(sections, "Paste a curl command or a share link:")
+	sections = append(sections, m.input.View())
+
+	if m.errorMsg != "" {
+		sections = append(sections, "")
+		sections = append(sections, "Error: "+m.errorMsg)
+	}
+
+	sections = append(sections, "")
+	sections = append(sections, "Enter: load into request builder • Esc: cancel")
+
+	return strings.Join(sections, "\n")
+}
+
+// maxScriptLines is how many lines of each script the confirmation shows.
+const maxScriptLines = 12
+
+// renderScripts renders the scripts of the pending share link and asks
+// whether to keep them.
+func (m CurlImportModel) renderScripts() []string {
+	req := m.pending.Request
+	sections := []string{
+		"This link carries scripts. They run whenever the request is sent and",
+		"can read and change your variables, so only keep scripts you trust.",
+	}
+	for _, script := range []struct{ name, source string }{
+		{"Pre-request script", req.PreRequestScript},
+		{"Post-response script", req.PostResponseScript},
+	} {
+		if script.source == "" {
+			continue
+		}
+		sections = append(sections, "", script.name+":")
+		lines := strings.Split(strings.TrimRight(script.source, "\n"), "\n")
+		for i, line := range lines {
+			if i == maxScriptLines {
+				sections = append(sections, fmt.Sprintf("  … %d more lines", len(lines)-i))
+				break
+			}
+			sections = append(sections, "  "+line)
+		}
+	}
+	sections = append(sections, "", "y: keep scripts • n: leave them out • Esc: cancel")
+	return sections
+}
+
+// Result returns the parsed command or link, or nil if none has been imported yet.
+func (m CurlImportModel) Result() *ImportedRequest {
+	return m.result
+}
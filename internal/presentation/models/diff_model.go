@@ -0,0 +1,265 @@
+package models
+
+import (
+	"context"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/williajm/curly/internal/app"
+	"github.com/williajm/curly/internal/infrastructure/diff"
+	"github.com/williajm/curly/internal/presentation/styles"
+)
+
+// defaultDiffWidth is used before the terminal size is known.
+const defaultDiffWidth = 100
+
+// diffPageSize is how many body rows the diff view shows at once.
+const diffPageSize = 20
+
+// DiffModel represents the side-by-side response comparison.
+type DiffModel struct {
+	// Services.
+	diffService *app.DiffService
+
+	// Comparison being shown.
+	diff     *app.ResponseDiff
+	loading  bool
+	errorMsg string
+
+	// Scroll position within the body rows.
+	offset int
+
+	// UI dimensions.
+	width int
+}
+
+// Custom messages.
+type compareRequestedMsg struct {
+	idA, idB string
+}
+
+type diffLoadedMsg struct {
+	diff *app.ResponseDiff
+	err  error
+}
+
+// NewDiffModel creates a new response comparison model.
+func NewDiffModel(diffService *app.DiffService) DiffModel {
+	return DiffModel{
+		diffService: diffService,
+	}
+}
+
+// Open starts loading the comparison of two history entries.
+func (m *DiffModel) Open(idA, idB string) tea.Cmd {
+	m.diff = nil
+	m.errorMsg = ""
+	m.offset = 0
+	m.loading = true
+	return func() tea.Msg {
+		d, err := m.diffService.CompareIDs(context.Background(), idA, idB)
+		return diffLoadedMsg{diff: d, err: err}
+	}
+}
+
+// SetWidth sets the width available to the two columns.
+func (m *DiffModel) SetWidth(width int) {
+	m.width = width
+}
+
+// Update handles messages and updates the model.
+func (m DiffModel) Update(msg tea.Msg) (DiffModel, tea.Cmd) {
+	switch msg := msg.(type) {
+	case diffLoadedMsg:
+		m.loading = false
+		if msg.err != nil {
+			m.errorMsg = msg.err.Error()
+			return m, nil
+		}
+		m.diff = msg.diff
+
+	case tea.KeyMsg:
+		maxOffset := max(0, len(m.rows())-diffPageSize)
+		switch msg.String() {
+		case "up", "k":
+			m.offset = max(0, m.offset-1)
+		case "down", "j":
+			m.offset = min(maxOffset, m.offset+1)
+		case "pgup":
+			m.offset = max(0, m.offset-diffPageSize)
+		case "pgdown", " ":
+			m.offset = min(maxOffset, m.offset+diffPageSize)
+		}
+	}
+
+	return m, nil
+}
+
+// View renders the status and header changes above the two bodies side by side.
+func (m DiffModel) View() string {
+	var sections []string
+
+	sections = append(sections, "══ Compare Responses ══")
+	sections = append(sections, "")
+
+	if m.loading {
+		sections = append(sections, "Loading responses...")
+		return strings.Join(sections, "\n")
+	}
+	if m.errorMsg != "" {
+		sections = append(sections, "Error: "+m.errorMsg)
+		sections = append(sections, "")
+		sections = append(sections, "Esc: close")
+		return strings.Join(sections, "\n")
+	}
+	if m.diff == nil {
+		return strings.Join(sections, "\n")
+	}
+
+	sections = append(sections, m.renderSummary()...)
+	sections = append(sections, "")
+	sections = append(sections, "Headers:")
+	sections = append(sections, renderChanges(m.diff.Headers)...)
+	if m.diff.JSON {
+		sections = append(sections, "")
+		sections = append(sections, "JSON changes:")
+		sections = append(sections, renderChanges(m.diff.Body)...)
+	}
+
+	width := m.width
+	if width <= 0 {
+		width = defaultDiffWidth
+	}
+	column := max(10, (width-3)/2)
+
+	sections = append(sections, "")
+	sections = append(sections, fitColumn(m.diff.A.ID, column)+"   "+fitColumn(m.diff.B.ID, column))
+	sections = append(sections, strings.Repeat("─", column)+"   "+strings.Repeat("─", column))
+
+	rows := m.rows()
+	end := min(len(rows), m.offset+diffPageSize)
+	for _, row := range rows[m.offset:end] {
+		sections = append(sections, renderDiffRow(row, column))
+	}
+
+	sections = append(sections, "")
+	sections = append(sections, "↑↓/PgUp/PgDn: scroll • Esc: close")
+
+	return strings.Join(sections, "\n")
+}
+
+// renderSummary renders the entries and status: the text rendering up to the
+// headers, with the entries colored like a unified diff and a changed status
+// flagged.
+func (m DiffModel) renderSummary() []string {
+	summary, _, _ := strings.Cut(m.diff.Format(), "\nHeaders:\n")
+	lines := strings.Split(summary, "\n")
+	for i, line := range lines {
+		switch {
+		case strings.HasPrefix(line, "---"):
+			lines[i] = styles.ErrorStyle.Render(line)
+		case strings.HasPrefix(line, "+++"):
+			lines[i] = styles.SuccessStyle.Render(line)
+		case strings.HasPrefix(line, "Status:") && m.diff.StatusChanged:
+			lines[i] = styles.WarningStyle.Render(line)
+		}
+	}
+	return lines
+}
+
+// renderDiffRow renders a row of the side-by-side view with columns of the
+// given width, coloring the sides that differ.
+func renderDiffRow(row diffRow, column int) string {
+	// Fit before styling so that color codes don't skew the columns.
+	left, right := fitColumn(row.left, column), fitColumn(row.right, column)
+	switch row.marker {
+	case "<":
+		left = styles.ErrorStyle.Render(left)
+	case ">":
+		right = styles.SuccessStyle.Render(right)
+	case "|":
+		left, right = styles.ErrorStyle.Render(left), styles.SuccessStyle.Render(right)
+	}
+	marker := row.marker
+	if marker != " " {
+		marker = styles.WarningStyle.Render(marker)
+	}
+	return left + " " + marker + " " + right
+}
+
+// renderChanges renders one indented line per change, colored by kind:
+// additions green, removals red and changed values yellow.
+func renderChanges(changes []diff.Change) []string {
+	if len(changes) == 0 {
+		return []string{"  (no changes)"}
+	}
+	lines := make([]string, len(changes))
+	for i, c := range changes {
+		style := styles.WarningStyle
+		switch c.Kind {
+		case diff.Added:
+			style = styles.SuccessStyle
+		case diff.Removed:
+			style = styles.ErrorStyle
+		}
+		lines[i] = "  " + style.Render(c.String())
+	}
+	return lines
+}
+
+// diffRow is one row of the side-by-side view.
+type diffRow struct {
+	left, right string
+
+	// marker is " " for unchanged lines, "|" for changed lines, "<" for
+	// lines only on the left and ">" for lines only on the right.
+	marker string
+}
+
+// rows pairs the body line diff into side-by-side rows, aligning each run
+// of deleted lines with the inserted lines that follow it.
+func (m DiffModel) rows() []diffRow {
+	if m.diff == nil {
+		return nil
+	}
+
+	var rows []diffRow
+	lines := m.diff.BodyLines
+	for i := 0; i < len(lines); {
+		if lines[i].Op == diff.Equal {
+			rows = append(rows, diffRow{left: lines[i].Text, right: lines[i].Text, marker: " "})
+			i++
+			continue
+		}
+
+		var deleted, inserted []string
+		for i < len(lines) && lines[i].Op == diff.Delete {
+			deleted = append(deleted, lines[i].Text)
+			i++
+		}
+		for i < len(lines) && lines[i].Op == diff.Insert {
+			inserted = append(inserted, lines[i].Text)
+			i++
+		}
+		for j := 0; j < len(deleted) || j < len(inserted); j++ {
+			switch {
+			case j >= len(inserted):
+				rows = append(rows, diffRow{left: deleted[j], marker: "<"})
+			case j >= len(deleted):
+				rows = append(rows, diffRow{right: inserted[j], marker: ">"})
+			default:
+				rows = append(rows, diffRow{left: deleted[j], right: inserted[j], marker: "|"})
+			}
+		}
+	}
+	return rows
+}
+
+// fitColumn pads or truncates text to exactly width runes.
+func fitColumn(text string, width int) string {
+	runes := []rune(strings.ReplaceAll(text, "\t", "    "))
+	if len(runes) > width {
+		return string(runes[:width-1]) + "…"
+	}
+	return string(runes) + strings.Repeat(" ", width-len(runes))
+}
@@ -0,0 +1,152 @@
+package models
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/williajm/curly/internal/app"
+)
+
+// draftTickMsg asks for the request builder's unsaved changes to be saved
+// as a draft.
+type draftTickMsg struct{}
+
+// draftSavedMsg reports the result of saving or discarding the session's
+// draft.
+type draftSavedMsg struct {
+	err error
+}
+
+// draftRecoveredMsg reports the draft an earlier session left, if any.
+type draftRecoveredMsg struct {
+	recovered *app.RecoveredDraft
+	err       error
+}
+
+// draftDeletedMsg reports the result of deleting a recovered draft.
+type draftDeletedMsg struct {
+	err error
+}
+
+// recoverDraft returns a command that looks for a draft an earlier session
+// left.
+func (m MainModel) recoverDraft() tea.Cmd {
+	if m.draftService == nil {
+		return nil
+	}
+	service := m.draftService
+	return func() tea.Msg {
+		recovered, err := service.Recover(context.Background())
+		return draftRecoveredMsg{recovered: recovered, err: err}
+	}
+}
+
+// scheduleAutosave returns a command that asks for the draft to be saved
+// once the autosave interval has passed.
+func (m MainModel) scheduleAutosave() tea.Cmd {
+	if m.draftService == nil || m.autosaveInterval <= 0 {
+		return nil
+	}
+	return tea.Tick(m.autosaveInterval, func(time.Time) tea.Msg {
+		return draftTickMsg{}
+	})
+}
+
+// autosave returns a command that saves the request builder's unsaved
+// changes as the session's draft, or discards the draft once there are
+// none.
+func (m MainModel) autosave() tea.Cmd {
+	if m.draftService == nil {
+		return nil
+	}
+	service := m.draftService
+	if !m.requestModel.Modified() {
+		return func() tea.Msg {
+			return draftSavedMsg{err: service.Discard(context.Background())}
+		}
+	}
+	req := m.requestModel.Draft()
+	return func() tea.Msg {
+		return draftSavedMsg{err: service.Save(context.Background(), req)}
+	}
+}
+
+// exit returns a command that saves the draft, so that changes made since
+// the last autosave are kept too, and where the TUI was left, and then
+// quits.
+func (m MainModel) exit() tea.Cmd {
+	return tea.Sequence(m.autosave(), m.saveSession(), tea.Quit)
+}
+
+// deleteDraft returns a command that deletes the recovered draft with the
+// given ID.
+func (m MainModel) deleteDraft(id string) tea.Cmd {
+	service := m.draftService
+	return func() tea.Msg {
+		return draftDeletedMsg{err: service.Delete(context.Background(), id)}
+	}
+}
+
+// handleDraftKey handles keyboard input while the draft recovery prompt is
+// open: r restores the draft, d discards it, Esc leaves it to be offered
+// again on the next launch and Ctrl+C quits.
+func (m *MainModel) handleDraftKey(msg tea.KeyMsg) tea.Cmd {
+	recovered := m.recovered
+	switch msg.String() {
+	case "r", "enter":
+		m.overlay = overlayNone
+		m.recovered = nil
+		req := recovered.Draft.Request
+		m.requestModel.RestoreDraft(req, recovered.Saved)
+		m.responseModel.SetFilter(req.ResponseFilter)
+		m.activeTab = TabRequest
+		m.statusMsg = "Restored the draft of " + draftLabel(recovered)
+		// The draft becomes this session's before the old one is deleted.
+		return tea.Sequence(m.autosave(), m.deleteDraft(recovered.Draft.ID))
+
+	case "d":
+		m.overlay = overlayNone
+		m.recovered = nil
+		m.statusMsg = "Discarded the draft of " + draftLabel(recovered)
+		return m.deleteDraft(recovered.Draft.ID)
+
+	case "esc":
+		m.overlay = overlayNone
+		m.recovered = nil
+		m.statusMsg = "The draft will be offered again next time"
+
+	case KeyCtrlC:
+		m.overlay = overlayNone
+		return m.quit()
+	}
+	return nil
+}
+
+// draftLabel describes the request of a recovered draft.
+func draftLabel(recovered *app.RecoveredDraft) string {
+	if recovered.Saved == nil {
+		if label := requestLabel(recovered.Draft.Request); label != "" {
+			return "new request " + label
+		}
+		return "a new request"
+	}
+	return requestLabel(recovered.Saved)
+}
+
+// renderDraft renders the draft recovery prompt.
+func (m MainModel) renderDraft() string {
+	var sections []string
+
+	sections = append(sections, "══ Unsaved Draft ══")
+	sections = append(sections, "")
+	if m.recovered != nil {
+		sections = append(sections, "curly exited before changes to "+draftLabel(m.recovered)+" were saved.")
+		sections = append(sections, "The draft was last saved "+m.recovered.Draft.UpdatedAt.Local().Format("Jan 2 15:04")+".")
+	}
+	sections = append(sections, "")
+	sections = append(sections, "r: restore • d: discard • Esc: decide next time")
+
+	return strings.Join(sections, "\n")
+}
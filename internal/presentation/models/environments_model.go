@@ -0,0 +1,333 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textarea"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/williajm/curly/internal/app"
+	"github.com/williajm/curly/internal/domain"
+)
+
+// EnvironmentsModel represents the environment selector, which lists the
+// environments, switches the active one and edits their variables.
+type EnvironmentsModel struct {
+	// Services.
+	environmentService *app.EnvironmentService
+
+	// Environments, listed after a "(none)" entry that deactivates them all.
+	envs          []*domain.Environment
+	active        string // ID of the active environment
+	selectedIndex int
+	loading       bool
+	errorMsg      string
+
+	// nameInput names a new environment while creating is set.
+	nameInput textinput.Model
+	creating  bool
+
+	// editor edits the variables of editing, one NAME=value per line.
+	editor  textarea.Model
+	editing *domain.Environment
+}
+
+// Custom messages.
+type environmentsLoadedMsg struct {
+	envs   []*domain.Environment
+	active *domain.Environment
+	err    error
+}
+
+// environmentChosenMsg reports the environment made active in the selector
+// (nil for none).
+type environmentChosenMsg struct {
+	env *domain.Environment
+	err error
+}
+
+// environmentChangedMsg reports the active environment when it is first
+// loaded or its variables were edited.
+type environmentChangedMsg struct {
+	env *domain.Environment
+	err error
+}
+
+type environmentSavedMsg struct {
+	env *domain.Environment
+	err error
+}
+
+// NewEnvironmentsModel creates a new environment selector model.
+func NewEnvironmentsModel(environmentService *app.EnvironmentService) EnvironmentsModel {
+	nameInput := textinput.New()
+	nameInput.Placeholder = "staging"
+	nameInput.CharLimit = 100
+
+	editor := textarea.New()
+	editor.Placeholder = "baseUrl=https://api.example.com\nsecret token=..."
+	editor.SetWidth(80)
+	editor.SetHeight(12)
+	editor.ShowLineNumbers = false
+
+	return EnvironmentsModel{
+		environmentService: environmentService,
+		nameInput:          nameInput,
+		editor:             editor,
+	}
+}
+
+// Open resets the selector and starts loading the environments.
+func (m *EnvironmentsModel) Open() tea.Cmd {
+	m.errorMsg = ""
+	m.creating = false
+	m.editing = nil
+	return m.load()
+}
+
+// Busy reports whether text is being typed, so that keys such as "q" and
+// Esc belong to the selector.
+func (m EnvironmentsModel) Busy() bool {
+	return m.creating || m.editing != nil
+}
+
+// load returns a command that loads the environments and the active one.
+func (m *EnvironmentsModel) load() tea.Cmd {
+	m.loading = true
+	return func() tea.Msg {
+		ctx := context.Background()
+		envs, err := m.environmentService.List(ctx)
+		if err != nil {
+			return environmentsLoadedMsg{err: err}
+		}
+		active, err := m.environmentService.Active(ctx)
+		return environmentsLoadedMsg{envs: envs, active: active, err: err}
+	}
+}
+
+// Update handles messages and updates the model.
+func (m EnvironmentsModel) Update(msg tea.Msg) (EnvironmentsModel, tea.Cmd) {
+	switch msg := msg.(type) {
+	case environmentsLoadedMsg:
+		m.loading = false
+		if msg.err != nil {
+			m.errorMsg = msg.err.Error()
+			return m, nil
+		}
+		m.envs = msg.envs
+		m.active = ""
+		m.selectedIndex = 0
+		if msg.active != nil {
+			m.active = msg.active.ID
+		}
+		for i, env := range m.envs {
+			if env.ID == m.active {
+				m.selectedIndex = i + 1
+			}
+		}
+		return m, nil
+
+	case environmentChosenMsg:
+		m.loading = false
+		if msg.err != nil {
+			m.errorMsg = msg.err.Error()
+		}
+		return m, nil
+
+	case environmentSavedMsg:
+		m.loading = false
+		if msg.err != nil {
+			m.errorMsg = msg.err.Error()
+			return m, nil
+		}
+		m.errorMsg = ""
+		m.creating = false
+		m.editing = nil
+		cmds := []tea.Cmd{m.load()}
+		if msg.env.ID == m.active {
+			// Variables of the active environment changed.
+			env := msg.env
+			cmds = append(cmds, func() tea.Msg { return environmentChangedMsg{env: env} })
+		}
+		return m, tea.Batch(cmds...)
+
+	case tea.KeyMsg:
+		if m.loading {
+			return m, nil
+		}
+		switch {
+		case m.creating:
+			return m.updateCreate(msg)
+		case m.editing != nil:
+			return m.updateEdit(msg)
+		}
+		return m.handleKey(msg)
+	}
+
+	return m, nil
+}
+
+// handleKey handles keyboard input for the list.
+func (m EnvironmentsModel) handleKey(msg tea.KeyMsg) (EnvironmentsModel, tea.Cmd) {
+	switch msg.String() {
+	case "up", "k":
+		m.selectedIndex = max(m.selectedIndex-1, 0)
+
+	case "down", "j":
+		m.selectedIndex = min(m.selectedIndex+1, len(m.envs))
+
+	case "enter":
+		// Make the selected environment active, or none for "(none)".
+		name := ""
+		if env := m.selected(); env != nil {
+			name = env.Name
+		}
+		m.loading = true
+		return m, func() tea.Msg {
+			env, err := m.environmentService.Use(context.Background(), name)
+			return environmentChosenMsg{env: env, err: err}
+		}
+
+	case "n":
+		m.creating = true
+		m.errorMsg = ""
+		m.nameInput.Reset()
+		return m, m.nameInput.Focus()
+
+	case "e":
+		env := m.selected()
+		if env == nil {
+			return m, nil
+		}
+		m.editing = env.Clone()
+		m.errorMsg = ""
+		m.editor.SetValue(env.FormatVariables())
+		return m, m.editor.Focus()
+	}
+
+	return m, nil
+}
+
+// updateCreate handles keys while a new environment is being named.
+func (m EnvironmentsModel) updateCreate(msg tea.KeyMsg) (EnvironmentsModel, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.creating = false
+		m.nameInput.Blur()
+		return m, nil
+	case "enter":
+		name := m.nameInput.Value()
+		m.loading = true
+		return m, func() tea.Msg {
+			env, err := m.environmentService.Create(context.Background(), name)
+			return environmentSavedMsg{env: env, err: err}
+		}
+	}
+
+	var cmd tea.Cmd
+	m.nameInput, cmd = m.nameInput.Update(msg)
+	return m, cmd
+}
+
+// updateEdit handles keys while an environment's variables are edited:
+// Ctrl+S saves them and Esc discards the changes.
+func (m EnvironmentsModel) updateEdit(msg tea.KeyMsg) (EnvironmentsModel, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.editing = nil
+		m.editor.Blur()
+		return m, nil
+	case "ctrl+s":
+		env := m.editing
+		if err := env.ParseVariables(m.editor.Value()); err != nil {
+			m.errorMsg = err.Error()
+			return m, nil
+		}
+		m.loading = true
+		return m, func() tea.Msg {
+			err := m.environmentService.Save(context.Background(), env)
+			return environmentSavedMsg{env: env, err: err}
+		}
+	}
+
+	var cmd tea.Cmd
+	m.editor, cmd = m.editor.Update(msg)
+	return m, cmd
+}
+
+// selected returns the selected environment, or nil for "(none)".
+func (m EnvironmentsModel) selected() *domain.Environment {
+	if m.selectedIndex == 0 || m.selectedIndex > len(m.envs) {
+		return nil
+	}
+	return m.envs[m.selectedIndex-1]
+}
+
+// View renders the environment list, or the name input or variable editor.
+func (m EnvironmentsModel) View() string {
+	var sections []string
+
+	sections = append(sections, "══ Environments ══")
+	sections = append(sections, "")
+
+	switch {
+	case m.creating:
+		sections = append(sections, "New environment name:")
+		sections = append(sections, m.nameInput.View())
+		sections = append(sections, m.renderError()...)
+		sections = append(sections, "")
+		sections = append(sections, "Enter: create • Esc: cancel")
+		return strings.Join(sections, "\n")
+
+	case m.editing != nil:
+		sections = append(sections, fmt.Sprintf("Variables of %s (NAME=value per line; prefix with \"secret \" to mask):", m.editing.Name))
+		sections = append(sections, m.editor.View())
+		sections = append(sections, m.renderError()...)
+		sections = append(sections, "")
+		sections = append(sections, "Ctrl+S: save • Esc: discard")
+		return strings.Join(sections, "\n")
+	}
+
+	if m.loading && m.envs == nil {
+		sections = append(sections, "Loading environments...")
+		return strings.Join(sections, "\n")
+	}
+
+	names := []string{"(none)"}
+	ids := []string{""}
+	for _, env := range m.envs {
+		names = append(names, fmt.Sprintf("%s (%d variables)", env.Name, len(env.Variables)))
+		ids = append(ids, env.ID)
+	}
+	for i, name := range names {
+		cursor := "  "
+		if i == m.selectedIndex {
+			cursor = "> "
+		}
+		if ids[i] == m.active {
+			name += " ✓"
+		}
+		sections = append(sections, cursor+name)
+	}
+
+	if env := m.selected(); env != nil && len(env.Variables) > 0 {
+		sections = append(sections, "")
+		sections = append(sections, indent(sortedLines(env.MaskedVariables()))...)
+	}
+
+	sections = append(sections, m.renderError()...)
+	sections = append(sections, "")
+	sections = append(sections, "↑↓: navigate • Enter: use • n: new • e: edit variables • Esc: close")
+
+	return strings.Join(sections, "\n")
+}
+
+// renderError renders the error message, if any.
+func (m EnvironmentsModel) renderError() []string {
+	if m.errorMsg == "" {
+		return nil
+	}
+	return []string{"", "Error: " + m.errorMsg}
+}
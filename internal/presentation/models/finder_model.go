@@ -0,0 +1,196 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/williajm/curly/internal/app"
+	"github.com/williajm/curly/internal/domain"
+	"github.com/williajm/curly/internal/infrastructure/fuzzy"
+	"github.com/williajm/curly/internal/presentation/styles"
+)
+
+// maxFinderResults is how many matches the finder lists.
+const maxFinderResults = 15
+
+// FinderModel represents the fuzzy finder, which opens a saved request by
+// typing part of its name, URL or tags.
+type FinderModel struct {
+	// Services.
+	requestService *app.RequestService
+
+	// Query input.
+	input textinput.Model
+
+	// Saved requests, most recently used first, and those matching the query.
+	requests      []*domain.Request
+	results       []fuzzy.Result
+	selectedIndex int
+	loading       bool
+	errorMsg      string
+
+	// chosen is set once a request has been chosen to open.
+	chosen *domain.Request
+}
+
+// Custom messages.
+type finderLoadedMsg struct {
+	requests []*domain.Request
+	err      error
+}
+
+// NewFinderModel creates a new fuzzy finder model.
+func NewFinderModel(requestService *app.RequestService) FinderModel {
+	input := textinput.New()
+	input.Placeholder = "request name, URL or tag"
+	input.CharLimit = 200
+
+	return FinderModel{
+		requestService: requestService,
+		input:          input,
+	}
+}
+
+// Open clears the query, focuses it and starts loading the saved requests.
+func (m *FinderModel) Open() tea.Cmd {
+	m.input.Reset()
+	m.errorMsg = ""
+	m.chosen = nil
+	m.selectedIndex = 0
+	m.loading = true
+	load := func() tea.Msg {
+		requests, err := m.requestService.ListRecentlyUsedRequests(context.Background(), 0)
+		return finderLoadedMsg{requests: requests, err: err}
+	}
+	return tea.Batch(m.input.Focus(), load)
+}
+
+// Chosen returns the request chosen to open, or nil if none has been.
+func (m FinderModel) Chosen() *domain.Request {
+	return m.chosen
+}
+
+// Update handles messages and updates the model.
+func (m FinderModel) Update(msg tea.Msg) (FinderModel, tea.Cmd) {
+	switch msg := msg.(type) {
+	case finderLoadedMsg:
+		m.loading = false
+		if msg.err != nil {
+			m.errorMsg = msg.err.Error()
+			return m, nil
+		}
+		m.requests = msg.requests
+		m.search()
+		return m, nil
+
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "up":
+			m.selectedIndex = max(m.selectedIndex-1, 0)
+			return m, nil
+		case "down":
+			m.selectedIndex = min(m.selectedIndex+1, max(m.shown()-1, 0))
+			return m, nil
+		case "enter":
+			if m.shown() > 0 {
+				m.chosen = m.requests[m.results[m.selectedIndex].Index]
+			}
+			return m, nil
+		}
+	}
+
+	var cmd tea.Cmd
+	query := m.input.Value()
+	m.input, cmd = m.input.Update(msg)
+	if m.input.Value() != query {
+		m.selectedIndex = 0
+		m.search()
+	}
+	return m, cmd
+}
+
+// search ranks the saved requests against the query.
+func (m *FinderModel) search() {
+	candidates := make([][]string, len(m.requests))
+	for i, req := range m.requests {
+		candidates[i] = append([]string{req.Name, req.URL}, req.Tags...)
+	}
+	m.results = fuzzy.Rank(m.input.Value(), candidates)
+}
+
+// shown returns how many results are listed.
+func (m FinderModel) shown() int {
+	return min(len(m.results), maxFinderResults)
+}
+
+// View renders the query and the best matches.
+func (m FinderModel) View() string {
+	var sections []string
+
+	sections = append(sections, "══ Open Request ══")
+	sections = append(sections, "")
+	sections = append(sections, "> "+m.input.View())
+	sections = append(sections, "")
+
+	switch {
+	case m.loading:
+		sections = append(sections, "Loading requests...")
+	case len(m.requests) == 0:
+		sections = append(sections, "No saved requests yet.")
+	case len(m.results) == 0:
+		sections = append(sections, "No matching requests.")
+	}
+
+	for i := 0; i < m.shown(); i++ {
+		req := m.requests[m.results[i].Index]
+		cursor := "  "
+		if i == m.selectedIndex {
+			cursor = "> "
+		}
+
+		line := fmt.Sprintf("%s%s %s  %s", cursor, styles.RenderMethod(req.Method, 7), highlightMatch(m.input.Value(), requestLabel(req)), req.URL)
+		if req.Folder != "" {
+			line += "  [" + req.Folder + "]"
+		}
+		if len(req.Tags) > 0 {
+			line += "  #" + strings.Join(req.Tags, " #")
+		}
+		sections = append(sections, line)
+	}
+	if n := len(m.results) - m.shown(); n > 0 {
+		sections = append(sections, fmt.Sprintf("  … %d more", n))
+	}
+
+	if m.errorMsg != "" {
+		sections = append(sections, "")
+		sections = append(sections, "Error: "+m.errorMsg)
+	}
+
+	sections = append(sections, "")
+	sections = append(sections, "Type to search • ↑↓: choose • Enter: open • Esc: close")
+
+	return strings.Join(sections, "\n")
+}
+
+// highlightMatch highlights the characters of text that pattern matched.
+func highlightMatch(pattern, text string) string {
+	_, positions, ok := fuzzy.Match(pattern, text)
+	if !ok || len(positions) == 0 {
+		return text
+	}
+
+	var b strings.Builder
+	next := 0
+	for i, r := range text {
+		if next < len(positions) && positions[next] == i {
+			b.WriteString(styles.SearchMatchStyle.Render(string(r)))
+			next++
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -0,0 +1,141 @@
+package models
+
+import (
+	"context"
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/williajm/curly/internal/infrastructure/repository"
+)
+
+// historyBulkDeletedMsg reports the outcome of deleting the selected entries.
+type historyBulkDeletedMsg struct {
+	deleted int64
+	err     error
+}
+
+// historyExportedMsg reports the outcome of exporting history entries.
+type historyExportedMsg struct {
+	path  string
+	count int
+	err   error
+}
+
+// toggleSelected selects the entry under the cursor, or clears its
+// selection, and moves the cursor down.
+func (m *HistoryModel) toggleSelected() {
+	if len(m.entries) == 0 {
+		return
+	}
+	id := m.entries[m.selectedIndex].ID
+	if m.selected[id] {
+		delete(m.selected, id)
+	} else {
+		m.selected[id] = true
+	}
+	if m.selectedIndex < len(m.entries)-1 {
+		m.selectedIndex++
+	}
+}
+
+// toggleSelectAll selects every listed entry, which are those the filter
+// matches, or clears the selection if they are all selected already.
+func (m *HistoryModel) toggleSelectAll() {
+	if len(m.entries) > 0 && len(m.selected) == len(m.entries) {
+		clear(m.selected)
+		return
+	}
+	for _, entry := range m.entries {
+		m.selected[entry.ID] = true
+	}
+}
+
+// pruneSelection drops selected entries that are no longer listed, so bulk
+// actions only apply to entries the user can see.
+func (m *HistoryModel) pruneSelection() {
+	listed := make(map[string]bool, len(m.entries))
+	for _, entry := range m.entries {
+		listed[entry.ID] = true
+	}
+	for id := range m.selected {
+		if !listed[id] {
+			delete(m.selected, id)
+		}
+	}
+}
+
+// selectedIDs returns the IDs of the selected entries in list order.
+func (m HistoryModel) selectedIDs() []string {
+	var ids []string
+	for _, entry := range m.entries {
+		if m.selected[entry.ID] {
+			ids = append(ids, entry.ID)
+		}
+	}
+	return ids
+}
+
+// actionIDs returns the entries a bulk action applies to: the selected
+// entries, or the entry under the cursor if none are selected.
+func (m HistoryModel) actionIDs() []string {
+	if ids := m.selectedIDs(); len(ids) > 0 {
+		return ids
+	}
+	if len(m.entries) == 0 {
+		return nil
+	}
+	return []string{m.entries[m.selectedIndex].ID}
+}
+
+// deleteSelected creates a command that deletes the selected entries in one
+// transaction.
+func (m *HistoryModel) deleteSelected() tea.Cmd {
+	filter := repository.HistoryFilter{IDs: m.selectedIDs()}
+	m.loading = true
+	return func() tea.Msg {
+		deleted, err := m.bulkService.DeleteHistory(context.Background(), filter)
+		return historyBulkDeletedMsg{deleted: deleted, err: err}
+	}
+}
+
+// exportSelected creates a command that exports the entries actionIDs
+// returns to an archive.
+func (m *HistoryModel) exportSelected() tea.Cmd {
+	ids := m.actionIDs()
+	if len(ids) == 0 {
+		return nil
+	}
+	filter := repository.HistoryFilter{IDs: ids}
+	return func() tea.Msg {
+		path, count, err := m.bulkService.ExportHistory(context.Background(), filter)
+		return historyExportedMsg{path: path, count: count, err: err}
+	}
+}
+
+// handleBulkDeletedMsg clears the selection and reloads the history once the
+// selected entries are deleted.
+func (m HistoryModel) handleBulkDeletedMsg(msg historyBulkDeletedMsg) (HistoryModel, tea.Cmd) {
+	m.loading = false
+	if msg.err != nil {
+		m.errorMsg = msg.err.Error()
+		return m, nil
+	}
+	clear(m.selected)
+	return m, m.loadHistory()
+}
+
+// renderSelection renders the number of selected entries and the keys that
+// act on them, or "" if none are selected.
+func (m HistoryModel) renderSelection() string {
+	if len(m.selected) == 0 {
+		return ""
+	}
+	if m.confirmDelete {
+		return fmt.Sprintf("Delete %d selected entries? d: confirm • any other key: cancel", len(m.selected))
+	}
+	hints := "d: delete • x: export • *: select all/none • space: toggle"
+	if len(m.selected) == 2 {
+		hints = "c: compare • " + hints
+	}
+	return fmt.Sprintf("%d selected • %s", len(m.selected), hints)
+}
@@ -0,0 +1,323 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/williajm/curly/internal/domain"
+	"github.com/williajm/curly/internal/infrastructure/repository"
+)
+
+// detailChunkSize is how much of an offloaded response body the detail view
+// reads at a time from the body store.
+const detailChunkSize = 64 * 1024
+
+// historyDetailLoadedMsg carries a history entry, and a reader of its full
+// response body, for the detail view.
+type historyDetailLoadedMsg struct {
+	entry *repository.HistoryEntry
+	body  io.ReadCloser
+	err   error
+}
+
+// loadDetail creates a command that loads a history entry for the detail view.
+func (m *HistoryModel) loadDetail(id string) tea.Cmd {
+	m.loading = true
+	return func() tea.Msg {
+		entry, body, err := m.historyService.OpenEntry(context.Background(), id)
+		return historyDetailLoadedMsg{entry: entry, body: body, err: err}
+	}
+}
+
+// handleDetailLoadedMsg opens the detail view on the loaded entry. A body
+// kept in the history table is shown whole; an offloaded one is read a chunk
+// at a time, as the view is scrolled towards the end of what is read.
+func (m HistoryModel) handleDetailLoadedMsg(msg historyDetailLoadedMsg) (HistoryModel, tea.Cmd) {
+	m.loading = false
+	if msg.err != nil {
+		m.errorMsg = msg.err.Error()
+		return m, nil
+	}
+	m.errorMsg = ""
+	m.closeDetail()
+	m.detail = msg.entry
+	content := renderDetail(msg.entry)
+	if msg.entry.ResponseBodyRef != "" {
+		m.detailBody = &detailBody{reader: msg.body, header: content}
+	} else {
+		_ = msg.body.Close()
+	}
+	m.detailView.Width = max(m.width, 80)
+	m.detailView.Height = max(m.height-8, 10) // Leave room for the title and help line.
+	m.detailView.SetContent(content)
+	m.detailView.GotoTop()
+	m.pageDetailBody()
+	return m, nil
+}
+
+// closeDetail closes the detail view, and the body it was reading.
+func (m *HistoryModel) closeDetail() {
+	if m.detailBody != nil {
+		m.detailBody.close()
+	}
+	m.detail = nil
+	m.detailBody = nil
+}
+
+// pageDetailBody reads more of an offloaded body while the view is within a
+// page of the end of what is read.
+func (m *HistoryModel) pageDetailBody() {
+	body := m.detailBody
+	if body == nil {
+		return
+	}
+	for !body.done && m.detailView.YOffset+2*m.detailView.Height >= m.detailView.TotalLineCount() {
+		body.readChunk(detailChunkSize)
+		m.detailView.SetContent(body.content())
+	}
+}
+
+// detailBody is an offloaded response body being read into the detail view.
+// Only the part read so far is held in memory.
+type detailBody struct {
+	reader  io.ReadCloser
+	header  string
+	text    strings.Builder
+	pending []byte // The start of a UTF-8 sequence split by the last chunk.
+	done    bool
+	err     error
+}
+
+// readChunk reads up to size more bytes of the body.
+func (b *detailBody) readChunk(size int) {
+	chunk := make([]byte, size)
+	n, err := io.ReadFull(b.reader, chunk)
+	chunk = append(b.pending, chunk[:n]...)
+	b.pending = nil
+	if err == nil {
+		// Hold back a UTF-8 sequence the chunk ends part way through.
+		start := len(chunk) - 1
+		for start > 0 && len(chunk)-start < utf8.UTFMax && !utf8.RuneStart(chunk[start]) {
+			start--
+		}
+		if start >= 0 && !utf8.FullRune(chunk[start:]) {
+			b.pending = append(b.pending, chunk[start:]...)
+			chunk = chunk[:start]
+		}
+	}
+	b.text.Write(chunk)
+
+	if err != nil {
+		b.done = true
+		if !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+			b.err = err
+		}
+		b.close()
+	}
+}
+
+// readAll reads the rest of the body and returns the whole of it.
+func (b *detailBody) readAll() string {
+	for !b.done {
+		b.readChunk(detailChunkSize)
+	}
+	return b.text.String()
+}
+
+// content returns the detail view's content: the entry's details, and the
+// body as far as it is read.
+func (b *detailBody) content() string {
+	content := b.header + "\n" + b.text.String()
+	switch {
+	case b.err != nil:
+		content += "\n\nFailed to read the rest of the body: " + b.err.Error()
+	case !b.done:
+		content += "\n… (scroll for more)"
+	case b.text.Len() == 0:
+		content += "Empty"
+	}
+	return content
+}
+
+// close closes the reader of the body.
+func (b *detailBody) close() {
+	_ = b.reader.Close()
+}
+
+// updateDetail handles keys while the detail view is open: esc or v closes
+// it, y copies the response body, Enter and p load or replay the entry's
+// request and the other keys scroll.
+func (m HistoryModel) updateDetail(msg tea.KeyMsg) (HistoryModel, tea.Cmd) {
+	switch msg.String() {
+	case KeyCtrlC:
+		return m, tea.Quit
+	case "esc", "v":
+		m.closeDetail()
+		return m, nil
+	case "y":
+		if m.detailBody != nil {
+			body := m.detailBody.readAll()
+			m.detailView.SetContent(m.detailBody.content())
+			return m, copyToClipboard("response body", body)
+		}
+		return m, copyToClipboard("response body", m.detail.ResponseBody)
+	case "enter", "p":
+		m.closeDetail()
+		return m.handleKeyMsg(msg)
+	}
+
+	var cmd tea.Cmd
+	m.detailView, cmd = m.detailView.Update(msg)
+	m.pageDetailBody()
+	return m, cmd
+}
+
+// renderDetailView renders the detail view of the open entry.
+func (m HistoryModel) renderDetailView() string {
+	sections := []string{
+		"══ History entry ══",
+		"",
+		m.detailView.View(),
+		"",
+		fmt.Sprintf("↑↓/PgUp/PgDn: scroll (%d%%) • Enter: load • p: replay • y: copy body • esc: back", int(m.detailView.ScrollPercent()*100)),
+	}
+	return strings.Join(sections, "\n")
+}
+
+// renderDetail renders everything recorded for an entry: the outcome and
+// timing, the request as it was sent, and the response headers and body.
+func renderDetail(entry *repository.HistoryEntry) string {
+	resp := entry.Response()
+
+	executed := entry.ExecutedAt
+	if !resp.Timestamp.IsZero() {
+		executed = resp.Timestamp.Local().Format("2006-01-02 15:04:05")
+	}
+	status := resp.Status
+	if status == "" {
+		status = "Error"
+		if resp.StatusCode != 0 {
+			status = fmt.Sprintf("%d", resp.StatusCode)
+		}
+	}
+
+	lines := []string{
+		fmt.Sprintf("%-14s %s", "Executed:", executed),
+		fmt.Sprintf("%-14s %s", "Status:", status),
+		fmt.Sprintf("%-14s %dms", "Time:", entry.ResponseTimeMs),
+		fmt.Sprintf("%-14s %s", "Size:", detailSize(entry, resp)),
+	}
+	if entry.RunID != "" {
+		lines = append(lines, fmt.Sprintf("%-14s %s", "Run:", entry.RunID))
+	}
+	if entry.ReplayOf != "" {
+		lines = append(lines, fmt.Sprintf("%-14s %s", "Replay of:", entry.ReplayOf))
+	}
+	if entry.Error != "" {
+		lines = append(lines, fmt.Sprintf("%-14s %s", "Error:", entry.Error))
+	}
+	if failures := resp.AssertionFailures; len(failures) > 0 {
+		lines = append(lines, fmt.Sprintf("%-14s %d failed", "Assertions:", len(failures)))
+		for _, failure := range failures {
+			lines = append(lines, "  ✗ "+failure)
+		}
+	}
+
+	lines = append(lines, "", "── Request ──")
+	if req, err := entry.Request(); err == nil {
+		lines = append(lines, renderDetailRequest(req)...)
+	} else {
+		lines = append(lines, "Not recorded (request "+entry.RequestID+")")
+	}
+
+	lines = append(lines, "", "── Response headers ──")
+	if len(resp.Headers) == 0 {
+		lines = append(lines, "None")
+	}
+	lines = append(lines, sortedLines(resp.Headers)...)
+
+	if entry.ResponseBodyRef != "" {
+		// The body is paged in after the heading; see detailBody.
+		return strings.Join(append(lines, "", "── Response body (as received) ──"), "\n")
+	}
+	body, formatted := resp.FormattedBody()
+	heading := "── Response body ──"
+	if formatted {
+		heading = "── Response body (pretty) ──"
+	}
+	lines = append(lines, "", heading)
+	if body == "" {
+		body = "Empty"
+	}
+	lines = append(lines, body)
+
+	return strings.Join(lines, "\n")
+}
+
+// detailSize describes the size of an entry's response body. An offloaded
+// body is not read to measure it, so its Content-Length is shown, if sent.
+func detailSize(entry *repository.HistoryEntry, resp *domain.Response) string {
+	size := resp.ContentLength
+	if entry.ResponseBodyRef != "" {
+		n, err := strconv.ParseInt(resp.GetHeader("Content-Length"), 10, 64)
+		if err != nil {
+			return "over " + formatSize(size) + ", stored outside the database"
+		}
+		size = n
+	}
+	return fmt.Sprintf("%s (%d bytes)", formatSize(size), size)
+}
+
+// renderDetailRequest renders a request snapshot: its method and URL, auth
+// type, query parameters, headers and body.
+func renderDetailRequest(req *domain.Request) []string {
+	lines := []string{req.Method + " " + req.URL}
+	if req.Name != "" {
+		lines = append(lines, fmt.Sprintf("%-14s %s", "Name:", req.Name))
+	}
+	if req.AuthConfig != nil && req.AuthConfig.Type() != domain.AuthTypeNone {
+		lines = append(lines, fmt.Sprintf("%-14s %s", "Auth:", req.AuthConfig.Type()))
+	}
+	if len(req.QueryParams) > 0 {
+		lines = append(lines, "Query:")
+		lines = append(lines, indent(sortedLines(req.QueryParams))...)
+	}
+	if len(req.Headers) > 0 {
+		lines = append(lines, "Headers:")
+		lines = append(lines, indent(sortedLines(req.Headers))...)
+	}
+	if req.Body != "" {
+		lines = append(lines, "Body:", req.Body)
+	}
+	return lines
+}
+
+// sortedLines returns values as "Name: value" lines, sorted by name.
+func sortedLines(values map[string]string) []string {
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	lines := make([]string, len(names))
+	for i, name := range names {
+		lines[i] = name + ": " + values[name]
+	}
+	return lines
+}
+
+// indent indents lines by two spaces.
+func indent(lines []string) []string {
+	for i, line := range lines {
+		lines[i] = "  " + line
+	}
+	return lines
+}
@@ -0,0 +1,93 @@
+package models
+
+import (
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/williajm/curly/internal/app"
+	"github.com/williajm/curly/internal/infrastructure/repository"
+)
+
+// startFilter focuses the filter bar with the current query.
+func (m *HistoryModel) startFilter() tea.Cmd {
+	m.filtering = true
+	m.filterInput.SetValue(m.query)
+	m.filterInput.CursorEnd()
+	return m.filterInput.Focus()
+}
+
+// updateFilter handles keys while the filter bar has focus. Enter applies the
+// query and reloads the history; esc leaves the current query unchanged.
+func (m HistoryModel) updateFilter(msg tea.KeyMsg) (HistoryModel, tea.Cmd) {
+	switch msg.String() {
+	case KeyCtrlC:
+		return m, tea.Quit
+	case "enter":
+		query := strings.TrimSpace(m.filterInput.Value())
+		filter, err := app.ParseHistoryQuery(query)
+		if err != nil {
+			m.errorMsg = err.Error()
+			return m, nil
+		}
+		m.filtering = false
+		m.filterInput.Blur()
+		filter.FailedOnly = m.filter.FailedOnly
+		m.query, m.filter = query, filter
+		m.selectedIndex = 0
+		return m, m.loadHistory()
+	case "esc":
+		m.filtering = false
+		m.filterInput.Blur()
+		m.errorMsg = ""
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.filterInput, cmd = m.filterInput.Update(msg)
+	return m, cmd
+}
+
+// Filtering reports whether the filter bar has focus, so that keys such as
+// "q" are typed into it rather than handled globally.
+func (m HistoryModel) Filtering() bool {
+	return m.filtering
+}
+
+// toggleFailedOnly shows only failed executions, or every execution again,
+// and reloads the history.
+func (m *HistoryModel) toggleFailedOnly() tea.Cmd {
+	m.filter.FailedOnly = !m.filter.FailedOnly
+	m.selectedIndex = 0
+	return m.loadHistory()
+}
+
+// clearFilter removes the query and the failures-only toggle and reloads the
+// history. It returns nil if no filter was set.
+func (m *HistoryModel) clearFilter() tea.Cmd {
+	if m.filter.IsEmpty() {
+		return nil
+	}
+	m.query = ""
+	m.filter = repository.HistoryFilter{}
+	m.selectedIndex = 0
+	return m.loadHistory()
+}
+
+// renderFilter renders the filter bar, or the filter applied, if any.
+func (m HistoryModel) renderFilter() string {
+	if m.filtering {
+		return "Filter: " + m.filterInput.View()
+	}
+	if m.filter.IsEmpty() {
+		return ""
+	}
+
+	var parts []string
+	if m.query != "" {
+		parts = append(parts, m.query)
+	}
+	if m.filter.FailedOnly {
+		parts = append(parts, "failures only")
+	}
+	return "Filter: " + strings.Join(parts, " • ") + " (esc: clear)"
+}
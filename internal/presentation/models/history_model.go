@@ -6,41 +6,134 @@ import (
 	"strings"
 	"time"
 
+	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/williajm/curly/internal/app"
+	"github.com/williajm/curly/internal/domain"
 	"github.com/williajm/curly/internal/infrastructure/repository"
+	"github.com/williajm/curly/internal/presentation/styles"
 )
 
 // HistoryModel represents the history browser.
 type HistoryModel struct {
 	// Services.
 	historyService *app.HistoryService
+	latencyService *app.LatencyService
+	bulkService    *app.BulkService
 
-	// History entries.
+	// History entries, loaded a page at a time as the cursor nears the end
+	// of those loaded. total is how many entries the filter matches,
+	// loadingMore is set while the next page loads, and generation counts
+	// reloads, so that pages loaded before one are dropped.
 	entries       []*repository.HistoryEntry
+	total         int
 	selectedIndex int
 	loading       bool
+	loadingMore   bool
+	generation    int
 	errorMsg      string
 
+	// Filter bar. query is the text applied, parsed into filter; the
+	// failures-only toggle is kept in filter.FailedOnly.
+	filterInput textinput.Model
+	filtering   bool
+	query       string
+	filter      repository.HistoryFilter
+
+	// Detail view of a single entry, shown instead of the list while open.
+	// An offloaded response body is paged in by detailBody as the view
+	// scrolls; see history_detail.go.
+	detail     *repository.HistoryEntry
+	detailView viewport.Model
+	detailBody *detailBody
+
+	// markedID is the entry marked for comparison.
+	markedID string
+
+	// selected holds the IDs of the entries selected for bulk actions, and
+	// confirmDelete is set while deleting them awaits confirmation.
+	selected      map[string]bool
+	confirmDelete bool
+
+	// Statistics panel.
+	showStats bool
+	stats     []*repository.RequestStats
+
+	// regressions are the latency regressions of the listed requests, by request ID.
+	regressions map[string]*app.LatencyRegression
+
+	// trends are the latency trends of saved requests, by request ID; a nil
+	// trend is still loading.
+	trends map[string]*app.LatencyTrend
+
 	// UI dimensions.
 	width  int
 	height int
 }
 
+// historyPageSize is how many history entries are loaded at a time, and
+// historyPrefetch how close to the last loaded entry the cursor gets before
+// the next page is loaded.
+const (
+	historyPageSize = 50
+	historyPrefetch = 10
+)
+
+// historyChromeLines is the number of lines around the history list: the
+// tabs and status bar, the title, filter, column headings, footer, trend,
+// selection and key hints.
+const historyChromeLines = 20
+
 // Custom messages.
+
+// historyLoadedMsg carries entries loaded from offset on, replacing those
+// from there, and the total the filter matches.
 type historyLoadedMsg struct {
-	entries []*repository.HistoryEntry
-	err     error
+	entries     []*repository.HistoryEntry
+	generation  int
+	offset      int
+	total       int
+	regressions map[string]*app.LatencyRegression
+	err         error
 }
 
 type historyDeletedMsg struct {
 	err error
 }
 
+type historyReplayedMsg struct {
+	response *domain.Response
+	err      error
+}
+
+type historyRequestLoadedMsg struct {
+	request *domain.Request
+	err     error
+}
+
+type historyStatsLoadedMsg struct {
+	stats []*repository.RequestStats
+	err   error
+}
+
 // NewHistoryModel creates a new history browser model.
-func NewHistoryModel(historyService *app.HistoryService) HistoryModel {
+// latencyService may be nil, in which case latency regressions are not shown,
+// and bulkService may be nil, which disables deleting and exporting many
+// entries at once.
+func NewHistoryModel(historyService *app.HistoryService, latencyService *app.LatencyService, bulkService *app.BulkService) HistoryModel {
+	filterInput := textinput.New()
+	filterInput.Placeholder = "status:4xx method:POST url:users"
+	filterInput.CharLimit = 200
+
 	return HistoryModel{
 		historyService: historyService,
+		latencyService: latencyService,
+		bulkService:    bulkService,
+		filterInput:    filterInput,
+		selected:       make(map[string]bool),
+		trends:         make(map[string]*app.LatencyTrend),
+		detailView:     viewport.New(80, 20),
 		entries:        []*repository.HistoryEntry{},
 		selectedIndex:  0,
 		loading:        false,
@@ -49,7 +142,9 @@ func NewHistoryModel(historyService *app.HistoryService) HistoryModel {
 
 // Init initializes the model and loads history.
 func (m HistoryModel) Init() tea.Cmd {
-	return m.loadHistory()
+	// Changes Init makes to the model are not kept, so the first page is
+	// loaded without counting as a reload.
+	return m.loadPage(0, historyPageSize)
 }
 
 // Update handles messages and updates the model.
@@ -59,7 +154,15 @@ func (m HistoryModel) Update(msg tea.Msg) (HistoryModel, tea.Cmd) {
 		if m.loading {
 			return m, nil
 		}
-		return m.handleKeyMsg(msg)
+		if m.filtering {
+			return m.updateFilter(msg)
+		}
+		if m.detail != nil {
+			return m.updateDetail(msg)
+		}
+		var cmd tea.Cmd
+		m, cmd = m.handleKeyMsg(msg)
+		return m, tea.Batch(cmd, m.loadTrend(), m.loadMore())
 
 	case historyLoadedMsg:
 		return m.handleHistoryLoadedMsg(msg)
@@ -67,9 +170,30 @@ func (m HistoryModel) Update(msg tea.Msg) (HistoryModel, tea.Cmd) {
 	case historyDeletedMsg:
 		return m.handleHistoryDeletedMsg(msg)
 
+	case historyBulkDeletedMsg:
+		return m.handleBulkDeletedMsg(msg)
+
+	case latencyTrendMsg:
+		return m.handleTrendMsg(msg)
+
+	case historyDetailLoadedMsg:
+		return m.handleDetailLoadedMsg(msg)
+
+	case historyStatsLoadedMsg:
+		m.loading = false
+		if msg.err != nil {
+			m.errorMsg = msg.err.Error()
+		} else {
+			m.stats = msg.stats
+			m.errorMsg = ""
+		}
+		return m, nil
+
 	case tea.WindowSizeMsg:
 		m.width = msg.Width
 		m.height = msg.Height
+		m.detailView.Width = max(msg.Width, 80)
+		m.detailView.Height = max(msg.Height-8, 10)
 	}
 
 	return m, nil
@@ -77,59 +201,235 @@ func (m HistoryModel) Update(msg tea.Msg) (HistoryModel, tea.Cmd) {
 
 // handleKeyMsg handles keyboard input for history navigation.
 func (m HistoryModel) handleKeyMsg(msg tea.KeyMsg) (HistoryModel, tea.Cmd) {
+	// Deleting the selection needs a second "d"; any other key cancels it.
+	if m.confirmDelete {
+		m.confirmDelete = false
+		if msg.String() == "d" {
+			return m, m.deleteSelected()
+		}
+		return m, nil
+	}
+	if m.moveCursor(msg.String()) {
+		return m, nil
+	}
+
 	switch msg.String() {
 	case KeyCtrlC:
 		return m, tea.Quit
 
+	case "r":
+		// Refresh history or statistics.
+		if m.showStats {
+			return m, m.loadStats()
+		}
+		return m, m.loadHistory()
+
+	case "s":
+		// Toggle the statistics panel.
+		m.showStats = !m.showStats
+		if m.showStats {
+			return m, m.loadStats()
+		}
+		return m, nil
+
+	case "delete", "d":
+		return m.handleDeleteKey()
+
+	case "m":
+		// Mark (or unmark) the selected entry for comparison.
+		m.toggleMarked()
+		return m, nil
+
+	case "c":
+		// Compare the two selected entries, older first, or the marked entry
+		// with the one under the cursor.
+		return m, m.compareEntries()
+	}
+
+	if m.showStats {
+		return m, nil
+	}
+	return m.handleListKey(msg)
+}
+
+// moveCursor moves the cursor for a navigation key and reports whether key
+// was one.
+func (m *HistoryModel) moveCursor(key string) bool {
+	switch key {
 	case "up", "k":
 		if m.selectedIndex > 0 {
 			m.selectedIndex--
 		}
-
 	case "down", "j":
 		if m.selectedIndex < len(m.entries)-1 {
 			m.selectedIndex++
 		}
+	case "home", "g":
+		m.selectedIndex = 0
+	case "end", "G":
+		m.selectedIndex = max(0, len(m.entries)-1)
+	default:
+		return false
+	}
+	return true
+}
 
+// handleListKey handles the keys that act on the history list, while it is
+// shown in place of the statistics.
+func (m HistoryModel) handleListKey(msg tea.KeyMsg) (HistoryModel, tea.Cmd) {
+	switch msg.String() {
 	case "enter":
-		// Load selected history entry into request builder.
-		// This will be handled by the main model.
-		return m, nil
+		// Load the selected entry's request, as it was sent, into the request builder.
+		if len(m.entries) > 0 {
+			return m, m.loadEntryRequest(m.entries[m.selectedIndex].ID)
+		}
 
-	case "delete", "d":
-		// Delete selected history entry.
+	case "v":
+		// Show everything recorded for the selected entry.
 		if len(m.entries) > 0 {
-			return m, m.deleteEntry(m.entries[m.selectedIndex].ID)
+			return m, m.loadDetail(m.entries[m.selectedIndex].ID)
 		}
 
-	case "r":
-		// Refresh history.
-		return m, m.loadHistory()
+	case "p":
+		// Replay the selected entry's request as it was sent.
+		if len(m.entries) > 0 {
+			return m, m.replayEntry(m.entries[m.selectedIndex].ID)
+		}
 
-	case "home", "g":
-		m.selectedIndex = 0
+	case " ", "*", "x":
+		return m.handleBulkKey(msg.String())
 
-	case "end", "G":
-		if len(m.entries) > 0 {
-			m.selectedIndex = len(m.entries) - 1
+	case "/", "f":
+		// Filter the history by status class, method and URL.
+		return m, m.startFilter()
+
+	case "e":
+		// Show only failed executions, or every execution again.
+		return m, m.toggleFailedOnly()
+
+	case "esc":
+		// Clear the selection first, then the filter.
+		if len(m.selected) > 0 {
+			clear(m.selected)
+			return m, nil
 		}
+		return m, m.clearFilter()
+	}
+
+	return m, nil
+}
+
+// handleBulkKey handles the keys that select entries for bulk actions and
+// export them, if bulk actions are available.
+func (m HistoryModel) handleBulkKey(key string) (HistoryModel, tea.Cmd) {
+	if m.bulkService == nil {
+		return m, nil
 	}
+	switch key {
+	case " ":
+		// Select (or deselect) the entry for bulk actions.
+		m.toggleSelected()
+	case "*":
+		// Select every listed entry, or none.
+		m.toggleSelectAll()
+	case "x":
+		// Export the selected entries, or the entry under the cursor.
+		return m, m.exportSelected()
+	}
+	return m, nil
+}
 
+// handleDeleteKey deletes the selected entries, once confirmed, or the entry
+// under the cursor.
+func (m HistoryModel) handleDeleteKey() (HistoryModel, tea.Cmd) {
+	if len(m.selected) > 0 && m.bulkService != nil && !m.showStats {
+		m.confirmDelete = true
+		return m, nil
+	}
+	if len(m.entries) > 0 {
+		return m, m.deleteEntry(m.entries[m.selectedIndex].ID)
+	}
 	return m, nil
 }
 
+// toggleMarked marks the entry under the cursor for comparison, or unmarks it.
+func (m *HistoryModel) toggleMarked() {
+	if len(m.entries) == 0 {
+		return
+	}
+	id := m.entries[m.selectedIndex].ID
+	if m.markedID == id {
+		m.markedID = ""
+	} else {
+		m.markedID = id
+	}
+}
+
+// compareEntries returns a command that compares the two selected entries,
+// older first, or the marked entry with the one under the cursor. It returns
+// nil if there is nothing to compare.
+func (m HistoryModel) compareEntries() tea.Cmd {
+	if ids := m.selectedIDs(); len(ids) == 2 {
+		idA, idB := ids[1], ids[0]
+		return func() tea.Msg { return compareRequestedMsg{idA: idA, idB: idB} }
+	}
+	if len(m.entries) > 0 && m.markedID != "" && m.markedID != m.entries[m.selectedIndex].ID {
+		idA, idB := m.markedID, m.entries[m.selectedIndex].ID
+		return func() tea.Msg { return compareRequestedMsg{idA: idA, idB: idB} }
+	}
+	return nil
+}
+
 // handleHistoryLoadedMsg handles the history loaded message.
 func (m HistoryModel) handleHistoryLoadedMsg(msg historyLoadedMsg) (HistoryModel, tea.Cmd) {
+	if msg.offset > 0 {
+		return m.handlePageLoadedMsg(msg)
+	}
+	// A reload since this one started has the entries to show.
+	if msg.generation != m.generation {
+		return m, nil
+	}
 	m.loading = false
+	m.loadingMore = false
 	if msg.err != nil {
 		m.errorMsg = msg.err.Error()
 	} else {
 		m.entries = msg.entries
+		m.total = msg.total
+		m.regressions = msg.regressions
 		m.errorMsg = ""
+		m.pruneSelection()
 		// Ensure selected index is valid.
 		if m.selectedIndex >= len(m.entries) {
 			m.selectedIndex = max(0, len(m.entries)-1)
 		}
+		// New executions change the trends.
+		clear(m.trends)
+		return m, m.loadTrend()
+	}
+	return m, nil
+}
+
+// handlePageLoadedMsg appends a further page of entries, unless the history
+// was reloaded while it loaded.
+func (m HistoryModel) handlePageLoadedMsg(msg historyLoadedMsg) (HistoryModel, tea.Cmd) {
+	if msg.generation != m.generation || msg.offset != len(m.entries) {
+		return m, nil
+	}
+	m.loadingMore = false
+	if msg.err != nil {
+		m.errorMsg = msg.err.Error()
+		return m, nil
+	}
+	m.entries = append(m.entries, msg.entries...)
+	m.total = msg.total
+	if m.regressions == nil {
+		m.regressions = make(map[string]*app.LatencyRegression)
+	}
+	for id, regression := range msg.regressions {
+		if _, ok := m.regressions[id]; !ok {
+			m.regressions[id] = regression
+		}
 	}
 	return m, nil
 }
@@ -148,6 +448,10 @@ func (m HistoryModel) handleHistoryDeletedMsg(msg historyDeletedMsg) (HistoryMod
 
 // View renders the history browser.
 func (m HistoryModel) View() string {
+	if m.detail != nil {
+		return m.renderDetailView()
+	}
+
 	var sections []string
 
 	sections = append(sections, "══ History ══")
@@ -163,68 +467,289 @@ func (m HistoryModel) View() string {
 		sections = append(sections, "")
 	}
 
-	if len(m.entries) == 0 {
-		sections = append(sections, "No history entries yet.")
+	if m.showStats {
+		return strings.Join(append(sections, m.statsView()...), "\n")
+	}
+
+	if filter := m.renderFilter(); filter != "" {
+		sections = append(sections, filter)
 		sections = append(sections, "")
-		sections = append(sections, "r: refresh • q: quit")
-		return strings.Join(sections, "\n")
+	}
+
+	if len(m.entries) == 0 {
+		return strings.Join(append(sections, m.emptyView()...), "\n")
 	}
 
 	// Header.
-	header := fmt.Sprintf("%-20s %-8s %-40s %-8s", "Time", "Method", "URL", "Status")
+	header := fmt.Sprintf("    %-20s %-8s %-40s %-8s", "Time", "Method", "URL", "Status")
 	sections = append(sections, header)
 	sections = append(sections, strings.Repeat("─", 80))
 
-	// Entries.
-	for i, entry := range m.entries {
-		cursor := "  "
-		if i == m.selectedIndex {
-			cursor = "> "
-		}
+	flagged, warnings := m.latencyWarnings()
+	first, last := m.listWindow()
+	for i := first; i < last; i++ {
+		sections = append(sections, m.renderEntry(i, flagged))
+	}
+
+	sections = append(sections, m.renderListFooter())
+
+	if trend := m.renderTrend(); trend != "" {
+		sections = append(sections, "")
+		sections = append(sections, trend)
+	}
+
+	if len(warnings) > 0 {
+		sections = append(sections, "")
+		sections = append(sections, "Latency regressions:")
+		sections = append(sections, warnings...)
+	}
+
+	if selection := m.renderSelection(); selection != "" {
+		sections = append(sections, "")
+		sections = append(sections, selection)
+	}
 
-		// Truncate URL if too long.
-		url := entry.RequestID // We don't have URL in history entry, use ID for now
-		if len(url) > 40 {
-			url = url[:37] + "..."
+	sections = append(sections, "")
+	sections = append(sections, "↑↓: navigate • Enter: load • v: details • p: replay • d: delete • space: select • x: export • m: mark • c: compare with marked • /: filter • e: failures only • s: stats • r: refresh • q: quit")
+
+	return strings.Join(sections, "\n")
+}
+
+// emptyView renders the list when no entries are loaded, with the keys that
+// apply to an empty history or to a filter that matched nothing.
+func (m HistoryModel) emptyView() []string {
+	if m.filter.IsEmpty() {
+		return []string{"No history entries yet.", "", "r: refresh • q: quit"}
+	}
+	return []string{
+		"No history entries match the filter.",
+		"",
+		"/: edit filter • e: failures only • esc: clear filter • q: quit",
+	}
+}
+
+// latencyWarnings flags the newest entry of each request whose latency
+// regressed, whether it is in view or not, keyed by request ID, and lists
+// the regressions.
+func (m HistoryModel) latencyWarnings() (map[string]string, []string) {
+	flagged := make(map[string]string)
+	var warnings []string
+	for _, entry := range m.entries {
+		regression, ok := m.regressions[entry.RequestID]
+		if !ok || flagged[entry.RequestID] != "" {
+			continue
 		}
+		flagged[entry.RequestID] = entry.ID
+		_, name := entryTarget(entry)
+		if req, err := entry.Request(); err == nil && req.Name != "" {
+			name = req.Name
+		}
+		warnings = append(warnings, fmt.Sprintf("  ⚠ %s: %s", name, regression))
+	}
+	return flagged, warnings
+}
+
+// renderEntry renders the list row of the entry at index i.
+func (m HistoryModel) renderEntry(i int, flagged map[string]string) string {
+	entry := m.entries[i]
+	cursor := "   "
+	if i == m.selectedIndex {
+		cursor = ">  "
+	}
+	if entry.ID == m.markedID {
+		cursor = cursor[:1] + "*" + cursor[2:]
+	}
+	if m.selected[entry.ID] {
+		cursor = cursor[:2] + "✓"
+	}
+
+	method, url := entryTarget(entry)
+
+	status := fmt.Sprintf("%d", entry.StatusCode)
+	if entry.StatusCode == 0 {
+		status = "Error"
+	}
+	if entry.AssertionFailures != "" {
+		status += " ✗"
+	}
+	if flagged[entry.RequestID] == entry.ID {
+		status += " ⚠"
+	}
+
+	// Pad before styling so that color codes don't skew the columns.
+	statusStyle := styles.GetStatusStyle(entry.StatusCode)
+	if entry.StatusCode == 0 {
+		statusStyle = styles.ErrorStyle
+	}
+	return fmt.Sprintf("%s %-20s %s %-40s %s",
+		cursor,
+		entryTimestamp(entry.ExecutedAt),
+		styles.RenderMethod(method, 8),
+		url,
+		statusStyle.Render(fmt.Sprintf("%-8s", status)),
+	)
+}
+
+// entryTimestamp formats an RFC3339 execution time as date and time only.
+func entryTimestamp(executedAt string) string {
+	if t, err := time.Parse(time.RFC3339, executedAt); err == nil {
+		return t.Format("2006-01-02 15:04:05")
+	}
+	if len(executedAt) > 19 {
+		// Fallback to truncation if parse fails but string is long enough.
+		return executedAt[:19]
+	}
+	return executedAt
+}
+
+// entryTarget returns the method and URL of entry's request, the URL cut to
+// fit its column. Entries recorded before request snapshots only know their
+// request ID.
+func entryTarget(entry *repository.HistoryEntry) (string, string) {
+	method, url := "-", entry.RequestID
+	if req, err := entry.Request(); err == nil {
+		method, url = req.Method, req.URL
+	}
+	if len(url) > 40 {
+		url = url[:37] + "..."
+	}
+	return method, url
+}
+
+// listWindow returns the range of entries that fit on screen, scrolled to
+// keep the cursor in view.
+func (m HistoryModel) listWindow() (int, int) {
+	rows := max(m.height-historyChromeLines, 5)
+	if len(m.entries) <= rows {
+		return 0, len(m.entries)
+	}
+	first := min(max(m.selectedIndex-rows/2, 0), len(m.entries)-rows)
+	return first, first + rows
+}
+
+// renderListFooter renders how many of the matching entries are loaded, and
+// whether more are loading.
+func (m HistoryModel) renderListFooter() string {
+	footer := fmt.Sprintf("Showing %s of %s", formatCount(len(m.entries)), formatCount(max(m.total, len(m.entries))))
+	if m.loadingMore {
+		footer += " • loading more..."
+	}
+	return styles.HelpStyle.Render(footer)
+}
+
+// formatCount formats n with thousands separators, such as "3,214".
+func formatCount(n int) string {
+	s := fmt.Sprint(n)
+	for i := len(s) - 3; i > 0; i -= 3 {
+		s = s[:i] + "," + s[i:]
+	}
+	return s
+}
+
+// statsView renders the per-request statistics panel.
+func (m HistoryModel) statsView() []string {
+	var lines []string
+
+	if len(m.stats) == 0 {
+		lines = append(lines, "No statistics yet.")
+		lines = append(lines, "")
+		lines = append(lines, "s: back to history • r: refresh • q: quit")
+		return lines
+	}
+
+	lines = append(lines, fmt.Sprintf("%-38s %6s %8s %7s %7s %7s  %s", "Request", "Runs", "Success", "p50", "p95", "p99", "Last failure"))
+	lines = append(lines, strings.Repeat("─", 100))
 
-		status := fmt.Sprintf("%d", entry.StatusCode)
-		if entry.StatusCode == 0 {
-			status = "Error"
+	for _, s := range m.stats {
+		request := s.RequestID
+		if request == "" {
+			request = "(ad-hoc)"
 		}
 
-		// Format timestamp safely - parse RFC3339 and format to date+time only.
-		timestamp := entry.ExecutedAt
-		if t, err := time.Parse(time.RFC3339, entry.ExecutedAt); err == nil {
-			timestamp = t.Format("2006-01-02 15:04:05")
-		} else if len(entry.ExecutedAt) > 19 {
-			// Fallback to truncation if parse fails but string is long enough.
-			timestamp = entry.ExecutedAt[:19]
+		lastFailure := "-"
+		if s.LastFailureAt != "" {
+			lastFailure = s.LastFailure
+			if t, err := time.Parse(time.RFC3339, s.LastFailureAt); err == nil {
+				lastFailure = t.Format("2006-01-02 15:04:05") + " " + s.LastFailure
+			}
 		}
 
-		line := fmt.Sprintf("%s%-20s %-8s %-40s %-8s",
-			cursor,
-			timestamp,
-			"GET", // We don't have method in history, default to GET
-			url,
-			status,
-		)
-		sections = append(sections, line)
+		lines = append(lines, fmt.Sprintf("%-38s %6d %7.1f%% %5dms %5dms %5dms  %s",
+			request, s.Count, s.SuccessRate()*100, s.P50Ms, s.P95Ms, s.P99Ms, lastFailure))
 	}
 
-	sections = append(sections, "")
-	sections = append(sections, "↑↓: navigate • Enter: load • d: delete • r: refresh • q: quit")
+	lines = append(lines, "")
+	lines = append(lines, "s: back to history • r: refresh • q: quit")
 
-	return strings.Join(sections, "\n")
+	return lines
 }
 
-// loadHistory creates a command to load history from the service.
+// loadEntryRequest creates a command that reconstructs a history entry's request.
+func (m *HistoryModel) loadEntryRequest(id string) tea.Cmd {
+	return func() tea.Msg {
+		req, err := m.historyService.GetRequest(context.Background(), id)
+		return historyRequestLoadedMsg{request: req, err: err}
+	}
+}
+
+// replayEntry creates a command that re-sends a history entry's request.
+func (m *HistoryModel) replayEntry(id string) tea.Cmd {
+	return func() tea.Msg {
+		resp, err := m.historyService.Replay(context.Background(), id)
+		return historyReplayedMsg{response: resp, err: err}
+	}
+}
+
+// loadStats creates a command to load per-request statistics from the service.
+func (m *HistoryModel) loadStats() tea.Cmd {
+	m.loading = true
+	return func() tea.Msg {
+		stats, err := m.historyService.GetStats(context.Background())
+		return historyStatsLoadedMsg{stats: stats, err: err}
+	}
+}
+
+// loadHistory creates a command to load history from the service, keeping
+// only the entries the filter matches. Enough pages are loaded to reach the
+// cursor, so that refreshing keeps its place.
 func (m *HistoryModel) loadHistory() tea.Cmd {
 	m.loading = true
+	m.generation++
+	pages := m.selectedIndex/historyPageSize + 1
+	return m.loadPage(0, pages*historyPageSize)
+}
+
+// loadMore creates a command to load the next page of history once the
+// cursor nears the last entry loaded, or returns nil if it is not near it,
+// every entry is loaded or the page is loading already.
+func (m *HistoryModel) loadMore() tea.Cmd {
+	if m.loadingMore || m.showStats || len(m.entries) >= m.total ||
+		m.selectedIndex < len(m.entries)-historyPrefetch {
+		return nil
+	}
+	m.loadingMore = true
+	return m.loadPage(len(m.entries), historyPageSize)
+}
+
+// loadPage creates a command to load limit of the entries the filter
+// matches, from offset on, with the latency regressions of their requests.
+func (m *HistoryModel) loadPage(offset, limit int) tea.Cmd {
+	filter, generation := m.filter, m.generation
 	return func() tea.Msg {
 		ctx := context.Background()
-		entries, err := m.historyService.GetHistory(ctx, 100) // Load last 100 entries
-		return historyLoadedMsg{entries: entries, err: err}
+		entries, total, err := m.historyService.GetHistoryPage(ctx, filter, offset, limit)
+		if err != nil || m.latencyService == nil {
+			return historyLoadedMsg{entries: entries, generation: generation, offset: offset, total: total, err: err}
+		}
+
+		ids := make([]string, len(entries))
+		for i, entry := range entries {
+			ids[i] = entry.RequestID
+		}
+		// The analysis is advisory, so its failure (already logged) does not
+		// hide the history.
+		regressions, _ := m.latencyService.AnalyzeAll(ctx, ids)
+		return historyLoadedMsg{entries: entries, generation: generation, offset: offset, total: total, regressions: regressions}
 	}
 }
 
@@ -246,7 +771,7 @@ func (m *HistoryModel) GetSelectedEntry() *repository.HistoryEntry {
 	return nil
 }
 
-// GetEntries returns all history entries.
+// GetEntries returns the history entries loaded so far.
 func (m *HistoryModel) GetEntries() []*repository.HistoryEntry {
 	return m.entries
 }
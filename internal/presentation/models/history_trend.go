@@ -0,0 +1,92 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/williajm/curly/internal/app"
+)
+
+// trendSamples is how many recent executions the latency sparkline shows.
+const trendSamples = 30
+
+// sparkBlocks are the sparkline bars, from lowest to highest.
+var sparkBlocks = []rune("▁▂▃▄▅▆▇█")
+
+// latencyTrendMsg carries the latency trend of a saved request.
+type latencyTrendMsg struct {
+	requestID string
+	trend     *app.LatencyTrend
+	err       error
+}
+
+// loadTrend returns a command loading the latency trend of the saved request
+// under the cursor, or nil if it is loaded or loading already, the entry
+// belongs to an unsaved request, or latency analysis is unavailable.
+func (m *HistoryModel) loadTrend() tea.Cmd {
+	entry := m.GetSelectedEntry()
+	if m.latencyService == nil || entry == nil || entry.RequestID == "" {
+		return nil
+	}
+	requestID := entry.RequestID
+	if _, ok := m.trends[requestID]; ok {
+		return nil
+	}
+	// A nil trend marks the request as loading.
+	m.trends[requestID] = nil
+	return func() tea.Msg {
+		trend, err := m.latencyService.Trend(context.Background(), requestID, trendSamples)
+		return latencyTrendMsg{requestID: requestID, trend: trend, err: err}
+	}
+}
+
+// handleTrendMsg records a loaded latency trend. A failure, already logged,
+// only leaves the sparkline out.
+func (m HistoryModel) handleTrendMsg(msg latencyTrendMsg) (HistoryModel, tea.Cmd) {
+	if msg.err != nil {
+		delete(m.trends, msg.requestID)
+		return m, nil
+	}
+	m.trends[msg.requestID] = msg.trend
+	return m, nil
+}
+
+// renderTrend renders the latency sparkline of the saved request under the
+// cursor, such as "Latency (last 12): ▁▂▁▃█▂  min 98ms • avg 143ms • p95 410ms",
+// or "" if there is none.
+func (m HistoryModel) renderTrend() string {
+	entry := m.GetSelectedEntry()
+	if entry == nil || entry.RequestID == "" {
+		return ""
+	}
+	trend := m.trends[entry.RequestID]
+	if trend == nil || len(trend.SamplesMs) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("Latency (last %d): %s  min %dms • avg %dms • p95 %dms",
+		len(trend.SamplesMs), sparkline(trend.SamplesMs), trend.MinMs, trend.AvgMs, trend.P95Ms)
+}
+
+// sparkline renders values as a row of bars scaled between their minimum
+// and maximum.
+func sparkline(values []int64) string {
+	if len(values) == 0 {
+		return ""
+	}
+	lowest, highest := slices.Min(values), slices.Max(values)
+
+	var b strings.Builder
+	for _, v := range values {
+		level := 0
+		if highest > lowest {
+			// Round to the nearest bar.
+			span := highest - lowest
+			level = int(((v-lowest)*int64(len(sparkBlocks)-1)*2 + span) / (2 * span))
+		}
+		b.WriteRune(sparkBlocks[level])
+	}
+	return b.String()
+}
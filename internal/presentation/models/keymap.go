@@ -0,0 +1,168 @@
+package models
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Actions of the global shortcuts that the keybindings config can rebind.
+const (
+	ActionQuit            = "quit"
+	ActionHelp            = "help"
+	ActionSave            = "save"
+	ActionImport          = "import"
+	ActionFinder          = "finder"
+	ActionEnvironments    = "environments"
+	ActionPreview         = "preview"
+	ActionCopyAs          = "copy_as"
+	ActionRunCollection   = "run_collection"
+	ActionCollections     = "collections"
+	ActionLoadTest        = "load_test"
+	ActionWorkspaces      = "workspaces"
+	ActionOAuth           = "oauth"
+	ActionCopyCurl        = "copy_curl"
+	ActionCopyCurlSecrets = "copy_curl_secrets"
+	ActionToggleSplit     = "toggle_split"
+	ActionNarrowSplit     = "narrow_split"
+	ActionWidenSplit      = "widen_split"
+)
+
+// reservedKeys keep their meaning whatever the keybindings: Ctrl+C always
+// quits, and the others move between tabs and fields or close dialogs.
+var reservedKeys = []string{KeyCtrlC, "esc", "enter", "tab", "shift+tab", "1", "2", "3", "4"}
+
+// Keymap maps each action to the key that triggers it, in the form Bubble
+// Tea reports keys: "ctrl+o", "alt+c", "alt+C" for Alt+Shift+C, "f2".
+type Keymap map[string]string
+
+// DefaultKeymap returns the built-in key of every action.
+func DefaultKeymap() Keymap {
+	return Keymap{
+		ActionQuit:            "q",
+		ActionHelp:            "?",
+		ActionSave:            KeyCtrlS,
+		ActionImport:          KeyCtrlG,
+		ActionFinder:          KeyCtrlP,
+		ActionEnvironments:    KeyCtrlE,
+		ActionPreview:         KeyCtrlQ,
+		ActionCopyAs:          KeyCtrlY,
+		ActionRunCollection:   KeyCtrlX,
+		ActionCollections:     KeyCtrlB,
+		ActionLoadTest:        KeyCtrlL,
+		ActionWorkspaces:      KeyCtrlO,
+		ActionOAuth:           KeyAltO,
+		ActionCopyCurl:        KeyAltC,
+		ActionCopyCurlSecrets: KeyAltShiftC,
+		ActionToggleSplit:     KeyCtrlBackslash,
+		ActionNarrowSplit:     KeyAltMinus,
+		ActionWidenSplit:      KeyAltEquals,
+	}
+}
+
+// Bind returns a copy of k with the actions in bindings bound to their keys.
+// Modifier names are case-insensitive, so "Ctrl+K" binds ctrl+k. It returns
+// an error for an unknown action, a reserved key, or a key that ends up bound
+// to two actions.
+func (k Keymap) Bind(bindings map[string]string) (Keymap, error) {
+	bound := make(Keymap, len(k))
+	for action, key := range k {
+		bound[action] = key
+	}
+
+	actions := make([]string, 0, len(bindings))
+	for action := range bindings {
+		actions = append(actions, action)
+	}
+	sort.Strings(actions)
+	for _, action := range actions {
+		if _, ok := k[strings.ToLower(action)]; !ok {
+			return nil, fmt.Errorf("unknown action %q (available: %s)", action, strings.Join(k.Actions(), ", "))
+		}
+		key := normalizeKey(bindings[action])
+		if key == "" {
+			return nil, fmt.Errorf("no key given for %s", action)
+		}
+		for _, reserved := range reservedKeys {
+			if key == reserved {
+				return nil, fmt.Errorf("%s cannot be bound to %s, which is reserved", action, key)
+			}
+		}
+		bound[strings.ToLower(action)] = key
+	}
+
+	owners := make(map[string]string, len(bound))
+	for _, action := range bound.Actions() {
+		key := bound[action]
+		if other, ok := owners[key]; ok {
+			return nil, fmt.Errorf("%s is bound to both %s and %s", key, other, action)
+		}
+		owners[key] = action
+	}
+	return bound, nil
+}
+
+// Actions returns the actions of k, sorted.
+func (k Keymap) Actions() []string {
+	actions := make([]string, 0, len(k))
+	for action := range k {
+		actions = append(actions, action)
+	}
+	sort.Strings(actions)
+	return actions
+}
+
+// Label returns the key of action as it is written in help, such as
+// "Ctrl+O" or "Alt+Shift+C".
+func (k Keymap) Label(action string) string {
+	parts := strings.Split(k[action], "+")
+	last := parts[len(parts)-1]
+	if last == "" && len(parts) > 1 {
+		// The key is "+" itself, as in "ctrl++".
+		parts, last = parts[:len(parts)-2], "+"
+	} else {
+		parts = parts[:len(parts)-1]
+	}
+
+	var label []string
+	for _, modifier := range parts {
+		label = append(label, capitalize(modifier))
+	}
+	if len(last) == 1 && last != strings.ToLower(last) && len(parts) > 0 {
+		label = append(label, "Shift")
+	}
+	if len(parts) > 0 || len(last) > 1 {
+		last = capitalize(last)
+	}
+	return strings.Join(append(label, last), "+")
+}
+
+// normalizeKey lowercases key, apart from a final single character without
+// Ctrl, whose case distinguishes "alt+c" from "alt+C" and "n" from "N".
+func normalizeKey(key string) string {
+	key = strings.TrimSpace(key)
+	i := strings.LastIndex(key, "+")
+	if i == len(key)-1 && i > 0 {
+		// The key is "+" itself.
+		i = strings.LastIndex(key[:i], "+")
+	}
+	modifiers, last := strings.ToLower(key[:i+1]), key[i+1:]
+	if len(last) == 1 && !strings.Contains(modifiers, "ctrl") {
+		return modifiers + last
+	}
+	return strings.ToLower(key)
+}
+
+// capitalize uppercases the first letter of s.
+func capitalize(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
+
+// SetKeymap sets the keys of the global shortcuts.
+func (m *MainModel) SetKeymap(keys Keymap) {
+	m.keys = keys
+	m.statusMsg = "Press " + keys.Label(ActionHelp) + " for help"
+}
@@ -5,4 +5,67 @@ package models
 const (
 	// KeyCtrlC represents the Ctrl+C keyboard combination for quitting.
 	KeyCtrlC = "ctrl+c"
+
+	// KeyCtrlO represents the Ctrl+O keyboard combination for the workspace switcher.
+	KeyCtrlO = "ctrl+o"
+
+	// KeyCtrlG represents the Ctrl+G keyboard combination for the curl and share link import dialog.
+	KeyCtrlG = "ctrl+g"
+
+	// KeyCtrlY represents the Ctrl+Y keyboard combination for the "copy as…" menu.
+	KeyCtrlY = "ctrl+y"
+
+	// KeyCtrlX represents the Ctrl+X keyboard combination for the collection run panel.
+	KeyCtrlX = "ctrl+x"
+
+	// KeyCtrlB represents the Ctrl+B keyboard combination for the collections panel.
+	KeyCtrlB = "ctrl+b"
+
+	// KeyCtrlP represents the Ctrl+P keyboard combination for the fuzzy request finder.
+	KeyCtrlP = "ctrl+p"
+
+	// KeyCtrlE represents the Ctrl+E keyboard combination for the environment selector.
+	KeyCtrlE = "ctrl+e"
+
+	// KeyCtrlS represents the Ctrl+S keyboard combination for the save dialog.
+	KeyCtrlS = "ctrl+s"
+
+	// KeyCtrlQ represents the Ctrl+Q keyboard combination for the request preview.
+	KeyCtrlQ = "ctrl+q"
+
+	// KeyCtrlBackslash represents the Ctrl+\ keyboard combination for showing
+	// or collapsing the response pane beside the request builder.
+	KeyCtrlBackslash = "ctrl+\\"
+
+	// KeyAltMinus and KeyAltEquals represent the Alt+- and Alt+= keyboard
+	// combinations for narrowing and widening the request builder pane.
+	KeyAltMinus  = "alt+-"
+	KeyAltEquals = "alt+="
+
+	// KeyCtrlL represents the Ctrl+L keyboard combination for the load test panel.
+	KeyCtrlL = "ctrl+l"
+
+	// KeyCtrlT represents the Ctrl+T keyboard combination for GraphQL schema introspection.
+	KeyCtrlT = "ctrl+t"
+
+	// KeyAltN represents the Alt+N keyboard combination for toggling line
+	// numbers in the body editor.
+	KeyAltN = "alt+n"
+
+	// KeyCtrlSpace represents the Ctrl+Space keyboard combination for GraphQL completion.
+	KeyCtrlSpace = "ctrl+@"
+
+	// KeyAltC and KeyAltShiftC represent the Alt+C and Alt+Shift+C keyboard
+	// combinations for copying the request builder's request as a curl
+	// command, with its secrets masked or as it is.
+	KeyAltC      = "alt+c"
+	KeyAltShiftC = "alt+C"
+
+	// KeyAltO represents the Alt+O keyboard combination for the OAuth device
+	// sign-in dialog.
+	KeyAltO = "alt+o"
+
+	// KeyAltJ represents the Alt+J keyboard combination for checking that
+	// WebSocket messages are JSON before they are sent.
+	KeyAltJ = "alt+j"
 )
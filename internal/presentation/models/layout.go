@@ -0,0 +1,146 @@
+package models
+
+import (
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const (
+	// DefaultSplitRatio is the share of the width, in percent, given to the
+	// request builder when the response is shown beside it.
+	DefaultSplitRatio = 50
+
+	// minSplitRatio and maxSplitRatio bound the split ratio, and
+	// splitRatioStep is how far Alt+- and Alt+= move it.
+	minSplitRatio  = 20
+	maxSplitRatio  = 80
+	splitRatioStep = 5
+
+	// minSplitWidth is the narrowest terminal that shows the panes side by
+	// side; narrower terminals fall back to tabs.
+	minSplitWidth = 100
+
+	// paneSeparator separates the request builder from the response pane.
+	paneSeparator = " │ "
+)
+
+// Layout is the pane layout of the Request tab.
+type Layout struct {
+	// Split shows the response beside the request builder.
+	Split bool
+
+	// SplitRatio is the share of the width, in percent, given to the request
+	// builder when Split is set.
+	SplitRatio int
+}
+
+// normalized returns the layout with its split ratio within bounds.
+func (l Layout) normalized() Layout {
+	if l.SplitRatio == 0 {
+		l.SplitRatio = DefaultSplitRatio
+	}
+	l.SplitRatio = min(max(l.SplitRatio, minSplitRatio), maxSplitRatio)
+	return l
+}
+
+// layoutSavedMsg reports the outcome of saving the layout.
+type layoutSavedMsg struct {
+	err error
+}
+
+// SetLayout sets the pane layout, and the function that saves it whenever it
+// changes (nil to keep changes for this session only).
+func (m *MainModel) SetLayout(layout Layout, save func(Layout) error) {
+	m.layout = layout.normalized()
+	m.saveLayout = save
+}
+
+// splitShown reports whether the response is shown beside the request
+// builder, which needs the Request tab and a wide enough terminal.
+func (m MainModel) splitShown() bool {
+	return m.layout.Split && m.activeTab == TabRequest && m.width >= minSplitWidth
+}
+
+// paneWidths returns the widths of the request builder and response panes
+// when they are side by side.
+func (m MainModel) paneWidths() (int, int) {
+	available := m.width - lipgloss.Width(paneSeparator)
+	request := available * m.layout.SplitRatio / 100
+	return request, available - request
+}
+
+// resizePanes tells the request builder and the response viewer how wide
+// they are, which depends on whether they are side by side, and the
+// WebSocket tab the size of the screen.
+func (m *MainModel) resizePanes() {
+	requestWidth, responseWidth := m.width, m.width
+	if m.splitShown() {
+		requestWidth, responseWidth = m.paneWidths()
+	}
+	m.requestModel, _ = m.requestModel.Update(tea.WindowSizeMsg{Width: requestWidth, Height: m.height})
+	m.responseModel, _ = m.responseModel.Update(tea.WindowSizeMsg{Width: responseWidth, Height: m.height})
+	m.webSocketModel, _ = m.webSocketModel.Update(tea.WindowSizeMsg{Width: m.width, Height: m.height})
+}
+
+// handleLayoutKey handles the layout keys, by default Ctrl+\ to show or
+// collapse the response pane, and Alt+- and Alt+= to narrow and widen the
+// request builder. It reports whether the key was handled.
+func (m *MainModel) handleLayoutKey(key string) (bool, tea.Cmd) {
+	switch key {
+	case m.keys[ActionToggleSplit]:
+		m.layout.Split = !m.layout.Split
+		switch {
+		case m.layout.Split && m.width < minSplitWidth:
+			m.statusMsg = "Response pane shown when the terminal is at least 100 columns wide"
+		case m.layout.Split:
+			m.statusMsg = "Response pane shown"
+		default:
+			m.statusMsg = "Response pane collapsed"
+		}
+
+	case m.keys[ActionNarrowSplit], m.keys[ActionWidenSplit]:
+		if !m.splitShown() {
+			return false, nil
+		}
+		step := splitRatioStep
+		if key == m.keys[ActionNarrowSplit] {
+			step = -step
+		}
+		ratio := min(max(m.layout.SplitRatio+step, minSplitRatio), maxSplitRatio)
+		if ratio == m.layout.SplitRatio {
+			return true, nil
+		}
+		m.layout.SplitRatio = ratio
+
+	default:
+		return false, nil
+	}
+
+	m.resizePanes()
+	return true, m.saveLayoutCmd()
+}
+
+// saveLayoutCmd returns a command that saves the layout, or nil if layout
+// changes are not saved.
+func (m MainModel) saveLayoutCmd() tea.Cmd {
+	if m.saveLayout == nil {
+		return nil
+	}
+	save, layout := m.saveLayout, m.layout
+	return func() tea.Msg {
+		return layoutSavedMsg{err: save(layout)}
+	}
+}
+
+// renderSplit renders the request builder and the response side by side.
+func (m MainModel) renderSplit() string {
+	requestWidth, responseWidth := m.paneWidths()
+	request := lipgloss.NewStyle().Width(requestWidth).MaxWidth(requestWidth).Render(m.requestModel.View())
+	response := lipgloss.NewStyle().Width(responseWidth).MaxWidth(responseWidth).Render(m.responseModel.PaneView())
+
+	height := max(lipgloss.Height(request), lipgloss.Height(response))
+	separator := strings.TrimSuffix(strings.Repeat(paneSeparator+"\n", height), "\n")
+	return lipgloss.JoinHorizontal(lipgloss.Top, request, separator, response)
+}
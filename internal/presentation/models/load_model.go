@@ -0,0 +1,224 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/williajm/curly/internal/app"
+	"github.com/williajm/curly/internal/domain"
+)
+
+// Load test form fields.
+const (
+	loadFieldWorkers = iota
+	loadFieldRequests
+	loadFieldDuration
+	loadFieldCount
+)
+
+// LoadModel represents the load test panel.
+type LoadModel struct {
+	// Services.
+	loadService *app.LoadService
+
+	// Request to load test.
+	request *domain.Request
+
+	// Form inputs: workers, total requests and duration.
+	inputs     []textinput.Model
+	focusIndex int
+	errorMsg   string
+
+	// running is set while a test is in progress; cancel stops it.
+	running bool
+	cancel  context.CancelFunc
+
+	// Last completed test.
+	report *app.LoadReport
+}
+
+// Custom messages.
+type loadFinishedMsg struct {
+	report *app.LoadReport
+	err    error
+}
+
+// NewLoadModel creates a new load test panel model.
+func NewLoadModel(loadService *app.LoadService) LoadModel {
+	inputs := make([]textinput.Model, loadFieldCount)
+	for i := range inputs {
+		inputs[i] = textinput.New()
+		inputs[i].Width = 12
+	}
+	inputs[loadFieldWorkers].SetValue("10")
+	inputs[loadFieldRequests].SetValue("100")
+	inputs[loadFieldDuration].Placeholder = "e.g. 30s"
+
+	return LoadModel{
+		loadService: loadService,
+		inputs:      inputs,
+	}
+}
+
+// Open sets the request to load test and focuses the form.
+// The last test's results stay visible.
+func (m *LoadModel) Open(req *domain.Request) tea.Cmd {
+	m.request = req
+	m.errorMsg = ""
+	m.focusIndex = loadFieldWorkers
+	return m.updateFocus()
+}
+
+// Running reports whether a test is in progress.
+func (m LoadModel) Running() bool {
+	return m.running
+}
+
+// Stop cancels the test in progress, if any. What completed is still reported.
+func (m *LoadModel) Stop() {
+	if m.cancel != nil {
+		m.cancel()
+	}
+}
+
+// Update handles messages and updates the model.
+func (m LoadModel) Update(msg tea.Msg) (LoadModel, tea.Cmd) {
+	switch msg := msg.(type) {
+	case loadFinishedMsg:
+		m.running = false
+		m.cancel = nil
+		if msg.err != nil {
+			m.errorMsg = msg.err.Error()
+			return m, nil
+		}
+		m.errorMsg = ""
+		m.report = msg.report
+		return m, nil
+
+	case tea.KeyMsg:
+		if m.running {
+			return m, nil
+		}
+		switch msg.String() {
+		case "tab", "down":
+			m.focusIndex = (m.focusIndex + 1) % loadFieldCount
+			return m, m.updateFocus()
+		case "shift+tab", "up":
+			m.focusIndex = (m.focusIndex - 1 + loadFieldCount) % loadFieldCount
+			return m, m.updateFocus()
+		case "enter":
+			return m, m.start()
+		}
+	}
+
+	var cmd tea.Cmd
+	m.inputs[m.focusIndex], cmd = m.inputs[m.focusIndex].Update(msg)
+	return m, cmd
+}
+
+// updateFocus focuses the current input and blurs the others.
+func (m *LoadModel) updateFocus() tea.Cmd {
+	var cmd tea.Cmd
+	for i := range m.inputs {
+		if i == m.focusIndex {
+			cmd = m.inputs[i].Focus()
+		} else {
+			m.inputs[i].Blur()
+		}
+	}
+	return cmd
+}
+
+// start validates the form and returns a command that runs the test.
+func (m *LoadModel) start() tea.Cmd {
+	opts, err := m.options()
+	if err != nil {
+		m.errorMsg = err.Error()
+		return nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	m.running = true
+	m.cancel = cancel
+	m.errorMsg = ""
+
+	req := m.request
+	return func() tea.Msg {
+		defer cancel()
+		report, err := m.loadService.Run(ctx, req, opts)
+		return loadFinishedMsg{report: report, err: err}
+	}
+}
+
+// options parses the form. Empty fields are left unset.
+func (m LoadModel) options() (app.LoadOptions, error) {
+	var opts app.LoadOptions
+
+	if v := strings.TrimSpace(m.inputs[loadFieldWorkers].Value()); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			return opts, fmt.Errorf("workers must be a positive number")
+		}
+		opts.Workers = n
+	}
+	if v := strings.TrimSpace(m.inputs[loadFieldRequests].Value()); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return opts, fmt.Errorf("requests must be a number")
+		}
+		opts.Iterations = n
+	}
+	if v := strings.TrimSpace(m.inputs[loadFieldDuration].Value()); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d < 0 {
+			return opts, fmt.Errorf("duration must look like 30s or 2m")
+		}
+		opts.Duration = d
+	}
+
+	return opts, nil
+}
+
+// View renders the form and the last test's results.
+func (m LoadModel) View() string {
+	var sections []string
+
+	sections = append(sections, "══ Load Test ══")
+	sections = append(sections, "")
+	if m.request != nil {
+		sections = append(sections, fmt.Sprintf("%s %s", m.request.Method, m.request.URL))
+		sections = append(sections, "")
+	}
+
+	labels := []string{"Workers:  ", "Requests: ", "Duration: "}
+	for i, input := range m.inputs {
+		sections = append(sections, labels[i]+input.View())
+	}
+
+	if m.errorMsg != "" {
+		sections = append(sections, "")
+		sections = append(sections, "Error: "+m.errorMsg)
+	}
+
+	if m.running {
+		sections = append(sections, "")
+		sections = append(sections, "Running load test...")
+	} else if m.report != nil {
+		sections = append(sections, "")
+		sections = append(sections, strings.TrimSuffix(m.report.Format(), "\n"))
+	}
+
+	sections = append(sections, "")
+	if m.running {
+		sections = append(sections, "Esc: stop")
+	} else {
+		sections = append(sections, "Tab: next field • Enter: start • Esc: close")
+	}
+
+	return strings.Join(sections, "\n")
+}
@@ -2,17 +2,29 @@
 package models
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"strings"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/williajm/curly/internal/app"
+	"github.com/williajm/curly/internal/domain"
+	"github.com/williajm/curly/internal/infrastructure/codegen"
+	"github.com/williajm/curly/internal/infrastructure/imagepreview"
+	"github.com/williajm/curly/internal/presentation/styles"
 )
 
+// scheduleNotificationMsg reports a failed scheduled execution.
+type scheduleNotificationMsg app.Notification
+
 // Tab indices.
 const (
 	TabRequest = iota
 	TabResponse
 	TabHistory
+	TabWebSocket
 )
 
 // MainModel is the root model with tab navigation.
@@ -26,37 +38,196 @@ type MainModel struct {
 	responseModel ResponseModel
 	historyModel  HistoryModel
 
+	// WebSocket session tab.
+	webSocketModel WebSocketModel
+
+	// overlay is the dialog or panel shown in place of the tabs, if any.
+	overlay overlay
+
+	// Workspace switcher (nil service disables it).
+	workspaceModel WorkspaceModel
+	switchTo       string
+
+	// Curl import dialog (nil service disables it).
+	curlImportModel CurlImportModel
+
+	// "Copy as…" menu.
+	codegenModel CodegenModel
+
+	// Response comparison (nil service disables it).
+	diffModel DiffModel
+
+	// Collection run panel (nil service disables it).
+	runnerModel RunnerModel
+
+	// Fuzzy request finder.
+	finderModel FinderModel
+
+	// Collections panel (nil service disables it).
+	collectionsModel CollectionsModel
+
+	// Load test panel (nil service disables it).
+	loadModel LoadModel
+
+	// Environment selector (nil service disables it) and the active
+	// environment (nil for none).
+	environmentsModel EnvironmentsModel
+	environment       *domain.Environment
+
+	// Request preview with the environment's variables filled in.
+	previewModel PreviewModel
+
+	// Raw request view, in HTTP wire format.
+	rawModel RawRequestModel
+
+	// Save dialog, naming the request and choosing its folder.
+	saveModel SaveRequestModel
+
+	// OAuth device sign-in dialog.
+	oauthModel OAuthDeviceModel
+
+	// Welcome screen, shown on first run.
+	welcomeModel WelcomeModel
+
+	// Unsaved changes prompt, and the action waiting on it.
+	unsaved *unsavedAction
+
 	// Services (injected from app initialization).
-	requestService *app.RequestService
-	historyService *app.HistoryService
-	authService    *app.AuthService
+	requestService     *app.RequestService
+	historyService     *app.HistoryService
+	authService        *app.AuthService
+	workspaceService   *app.WorkspaceService
+	importService      *app.ImportService
+	codegenService     *app.CodegenService
+	runnerService      *app.RunnerService
+	schedulerService   *app.SchedulerService
+	diffService        *app.DiffService
+	loadService        *app.LoadService
+	collectionService  *app.CollectionService
+	environmentService *app.EnvironmentService
+	updateService      *app.UpdateService
+	draftService       *app.DraftService
+	sessionService     *app.SessionService
+
+	// updateHint tells the user a newer release is out.
+	updateHint string
+
+	// keepDefaultTab keeps the tab chosen by SetPreferences when the last
+	// session is restored.
+	keepDefaultTab bool
+
+	// Draft of the request builder's unsaved changes: how often it is
+	// saved, and a draft an earlier session left, offered for restoring.
+	autosaveInterval time.Duration
+	recovered        *app.RecoveredDraft
+
+	// Pane layout of the Request tab, and the function that saves it (nil to
+	// keep changes for this session only).
+	layout     Layout
+	saveLayout func(Layout) error
+
+	// keys are the keys of the global shortcuts.
+	keys Keymap
 
 	// UI state.
 	width     int
 	height    int
-	showHelp  bool
 	statusMsg string
 
 	// Flags.
 	quitting bool
 }
 
-// NewMainModel creates a new main model with all sub-models.
-func NewMainModel(
-	requestService *app.RequestService,
-	historyService *app.HistoryService,
-	authService *app.AuthService,
-) MainModel {
+// Services are the application services the TUI works with. Request,
+// History and Auth are required; the others may be nil, which disables the
+// features they provide.
+type Services struct {
+	Request *app.RequestService
+	History *app.HistoryService
+	Auth    *app.AuthService
+
+	// Workspace, Import, Codegen and Runner enable the workspace switcher,
+	// the curl import dialog, the "copy as…" menu and the collection run
+	// panel.
+	Workspace *app.WorkspaceService
+	Import    *app.ImportService
+	Codegen   *app.CodegenService
+	Runner    *app.RunnerService
+
+	// Scheduler runs the configured schedules; it is nil when there are none.
+	Scheduler *app.SchedulerService
+
+	// Diff and Load enable response comparison and the load test panel.
+	Diff *app.DiffService
+	Load *app.LoadService
+
+	// GraphQL enables GraphQL introspection, validation and completion in
+	// the body editor.
+	GraphQL *app.GraphQLService
+
+	// Latency shows latency regressions in the history view.
+	Latency *app.LatencyService
+
+	// Collection, Environment and Bulk enable the collections panel, the
+	// environment selector and bulk actions in the history.
+	Collection  *app.CollectionService
+	Environment *app.EnvironmentService
+	Bulk        *app.BulkService
+
+	// Update checks for a newer release on launch.
+	Update *app.UpdateService
+
+	// Draft keeps drafts of unsaved changes.
+	Draft *app.DraftService
+
+	// Session reopens where the last session was left; without it launches
+	// start afresh on the default tab.
+	Session *app.SessionService
+}
+
+// NewMainModel creates a new main model with all sub-models, working with
+// services.
+func NewMainModel(services Services) MainModel {
 	return MainModel{
-		tabs:           []string{"Request", "Response", "History"},
-		activeTab:      TabRequest,
-		requestModel:   NewRequestModel(requestService, authService),
-		responseModel:  NewResponseModel(),
-		historyModel:   NewHistoryModel(historyService),
-		requestService: requestService,
-		historyService: historyService,
-		authService:    authService,
-		statusMsg:      "Press ? for help",
+		tabs:               tabNames,
+		activeTab:          TabRequest,
+		requestModel:       NewRequestModel(services.Request, services.Auth, services.GraphQL),
+		responseModel:      NewResponseModel(),
+		historyModel:       NewHistoryModel(services.History, services.Latency, services.Bulk),
+		webSocketModel:     NewWebSocketModel(services.Request),
+		workspaceModel:     NewWorkspaceModel(services.Workspace),
+		curlImportModel:    NewCurlImportModel(services.Import),
+		codegenModel:       NewCodegenModel(services.Codegen),
+		finderModel:        NewFinderModel(services.Request),
+		runnerModel:        NewRunnerModel(services.Request, services.Runner),
+		collectionsModel:   NewCollectionsModel(services.Collection, services.Request),
+		diffModel:          NewDiffModel(services.Diff),
+		loadModel:          NewLoadModel(services.Load),
+		environmentsModel:  NewEnvironmentsModel(services.Environment),
+		previewModel:       NewPreviewModel(),
+		rawModel:           NewRawRequestModel(services.Request),
+		saveModel:          NewSaveRequestModel(services.Request),
+		oauthModel:         NewOAuthDeviceModel(services.Auth),
+		welcomeModel:       NewWelcomeModel(services.Request, services.History, services.Import),
+		layout:             Layout{SplitRatio: DefaultSplitRatio},
+		keys:               DefaultKeymap(),
+		requestService:     services.Request,
+		historyService:     services.History,
+		authService:        services.Auth,
+		workspaceService:   services.Workspace,
+		importService:      services.Import,
+		codegenService:     services.Codegen,
+		runnerService:      services.Runner,
+		schedulerService:   services.Scheduler,
+		diffService:        services.Diff,
+		loadService:        services.Load,
+		collectionService:  services.Collection,
+		environmentService: services.Environment,
+		updateService:      services.Update,
+		draftService:       services.Draft,
+		sessionService:     services.Session,
+		autosaveInterval:   DefaultPreferences().AutosaveInterval,
+		statusMsg:          "Press ? for help",
 	}
 }
 
@@ -66,13 +237,69 @@ func (m MainModel) Init() tea.Cmd {
 		m.requestModel.Init(),
 		m.responseModel.Init(),
 		m.historyModel.Init(),
+		m.waitForNotification(),
+		m.loadActiveEnvironment(),
+		m.checkFirstRun(),
+		m.checkForUpdate(),
+		m.recoverDraft(),
+		m.scheduleAutosave(),
+		m.loadSession(),
 	)
 }
 
+// updateCheckedMsg reports whether a newer release is out.
+type updateCheckedMsg struct {
+	status *app.UpdateStatus
+	err    error
+}
+
+// checkForUpdate returns a command that checks for a newer release.
+func (m MainModel) checkForUpdate() tea.Cmd {
+	if m.updateService == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		status, err := m.updateService.Check(context.Background())
+		return updateCheckedMsg{status: status, err: err}
+	}
+}
+
+// checkFirstRun returns a command that checks whether the welcome screen
+// should be shown.
+func (m MainModel) checkFirstRun() tea.Cmd {
+	if m.importService == nil {
+		return nil
+	}
+	return m.welcomeModel.CheckFirstRun()
+}
+
+// loadActiveEnvironment returns a command that loads the active environment.
+func (m MainModel) loadActiveEnvironment() tea.Cmd {
+	if m.environmentService == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		env, err := m.environmentService.Active(context.Background())
+		return environmentChangedMsg{env: env, err: err}
+	}
+}
+
 // Update handles messages and updates the model.
 func (m MainModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
-	var cmds []tea.Cmd
+	tab := m.activeTab
+	updated, cmd := m.update(msg)
+
+	// The response pane is only beside the request builder on the Request
+	// tab, so the response viewer's width changes with the tab.
+	if next, ok := updated.(MainModel); ok && next.activeTab != tab && next.layout.Split {
+		next.resizePanes()
+		return next, cmd
+	}
+	return updated, cmd
+}
 
+// update handles messages for Update.
+func (m MainModel) update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
 		// Handle global keyboard shortcuts.
@@ -83,58 +310,776 @@ func (m MainModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case tea.WindowSizeMsg:
 		m.width = msg.Width
 		m.height = msg.Height
+		m.diffModel.SetWidth(msg.Width)
+		m.resizePanes()
+		if m.activeTab != TabHistory {
+			return m, nil
+		}
+	}
 
-	case requestSentMsg:
-		return m.handleRequestSentMsg(msg)
-
-	case historyLoadedMsg, historyDeletedMsg:
-		// Pass history messages to history model.
-		var cmd tea.Cmd
-		m.historyModel, cmd = m.historyModel.Update(msg)
-		return m, cmd
+	for _, handle := range messageHandlers {
+		if handled, cmd := handle(&m, msg); handled {
+			return m, cmd
+		}
 	}
 
 	// Don't pass messages to sub-models if help is showing.
-	if m.showHelp {
+	if m.overlay == overlayHelp {
 		return m, nil
 	}
 
 	// Delegate to active sub-model.
-	cmd := m.delegateToActiveTab(msg)
-	cmds = append(cmds, cmd)
+	return m, m.delegateToActiveTab(msg)
+}
+
+// messageHandlers handle the messages of each part of the TUI, reporting
+// whether they handled msg. Messages none of them handle go to the active
+// tab.
+var messageHandlers = []func(m *MainModel, msg tea.Msg) (bool, tea.Cmd){
+	(*MainModel).handleRequestMsg,
+	(*MainModel).handleResponseMsg,
+	(*MainModel).handleHistoryMsg,
+	(*MainModel).handleCollectionMsg,
+	(*MainModel).handleEnvironmentMsg,
+	(*MainModel).handleSessionMsg,
+	(*MainModel).handleConnectionMsg,
+}
+
+// handleRequestMsg handles the messages of the request builder.
+func (m *MainModel) handleRequestMsg(msg tea.Msg) (bool, tea.Cmd) {
+	var cmd tea.Cmd
+	switch msg := msg.(type) {
+	case layoutSavedMsg:
+		m.setErrorStatus("Cannot save layout", msg.err)
+
+	case requestSentMsg:
+		cmd = m.handleRequestSentMsg(msg)
+
+	case headerSuggestionsMsg, urlSuggestionsMsg, transferProgressMsg:
+		m.requestModel, cmd = m.requestModel.Update(msg)
+
+	case graphqlSchemaMsg:
+		// Introspection may finish after the user has left the request tab.
+		m.requestModel, cmd = m.requestModel.Update(msg)
+		if msg.err != nil {
+			m.statusMsg = "GraphQL introspection failed"
+		} else {
+			m.statusMsg = fmt.Sprintf("GraphQL schema loaded: %d types", len(msg.schema.Types))
+		}
+
+	case clipboardMsg:
+		if !m.setErrorStatus("Cannot copy "+msg.what, msg.err) {
+			m.statusMsg = fmt.Sprintf("Copied %s to the clipboard (%s)", msg.what, msg.method)
+		}
+
+	case copyRequestMsg:
+		cmd = m.copyRequestAsCurl(false)
+
+	case responseFilterMsg:
+		// Remember the filter for the request, whether or not it is saved yet.
+		req := m.requestModel.GetRequest()
+		req.ResponseFilter = msg.filter
+		cmd = m.saveResponseFilter(req.ID, msg.filter)
+
+	case responseFilterSavedMsg:
+		m.setErrorStatus("Cannot save response filter", msg.err)
+
+	case rawRequestMsg:
+		m.rawModel, cmd = m.rawModel.Update(msg)
+
+	case showSentRequestMsg:
+		resp := m.responseModel.GetResponse()
+		if resp == nil || resp.Sent == nil {
+			m.statusMsg = "The request behind this response is not known"
+			return true, nil
+		}
+		cmd = m.openRaw(resp.Sent, true)
+
+	default:
+		return false, nil
+	}
+	return true, cmd
+}
+
+// handleResponseMsg handles the messages of the response viewer.
+func (m *MainModel) handleResponseMsg(msg tea.Msg) (bool, tea.Cmd) {
+	switch msg := msg.(type) {
+	case streamOpenedMsg:
+		m.responseModel.StartStream(msg.stream.Response)
+		if !m.splitShown() {
+			m.activeTab = TabResponse
+		}
+		m.statusMsg = "Receiving events... (Esc to stop)"
+		return true, nextStreamEvent(msg.stream)
+
+	case streamEventMsg:
+		m.responseModel.AddEvent(msg.event)
+		return true, nextStreamEvent(msg.stream)
+
+	case streamClosedMsg:
+		return true, m.handleStreamClosedMsg(msg)
 
-	return m, tea.Batch(cmds...)
+	case transcriptSavedMsg:
+		if !m.setErrorStatus("Cannot save events", msg.err) {
+			m.statusMsg = "Saved events to " + msg.path
+		}
+
+	case bodySavedMsg:
+		if !m.setErrorStatus("Cannot save response body", msg.err) {
+			m.statusMsg = "Saved response body to " + msg.path
+		}
+
+	default:
+		return false, nil
+	}
+	return true, nil
+}
+
+// handleHistoryMsg handles the messages of the history view and the
+// response comparison.
+func (m *MainModel) handleHistoryMsg(msg tea.Msg) (bool, tea.Cmd) {
+	var cmd tea.Cmd
+	switch msg := msg.(type) {
+	case historyLoadedMsg, historyDeletedMsg, historyDetailLoadedMsg, latencyTrendMsg:
+		m.historyModel, cmd = m.historyModel.Update(msg)
+
+	case historyBulkDeletedMsg:
+		if !m.setErrorStatus("Cannot delete history", msg.err) {
+			m.statusMsg = fmt.Sprintf("Deleted %d history entries", msg.deleted)
+		}
+		m.historyModel, cmd = m.historyModel.Update(msg)
+
+	case historyExportedMsg:
+		switch {
+		case m.setErrorStatus("Cannot export history", msg.err):
+		case msg.count == 0:
+			m.statusMsg = "No history entries to export"
+		default:
+			m.statusMsg = fmt.Sprintf("Exported %d history entries to %s", msg.count, msg.path)
+		}
+
+	case historyRequestLoadedMsg:
+		if !m.setErrorStatus("Cannot load request", msg.err) {
+			cmd = m.openRequest(msg.request, "Loaded request from history")
+		}
+
+	case historyReplayedMsg:
+		// Failed replays are recorded too, so history is reloaded either way.
+		if !m.setErrorStatus("Replay failed", msg.err) {
+			m.responseModel.SetResponse(msg.response)
+			m.activeTab = TabResponse
+			m.statusMsg = "Replayed request from history"
+		}
+		cmd = m.historyModel.loadHistory()
+
+	case compareRequestedMsg:
+		if m.diffService != nil {
+			m.overlay = overlayDiff
+			cmd = m.diffModel.Open(msg.idA, msg.idB)
+		}
+
+	case diffLoadedMsg:
+		m.diffModel, cmd = m.diffModel.Update(msg)
+
+	default:
+		return false, nil
+	}
+	return true, cmd
+}
+
+// handleCollectionMsg handles the messages of the dialogs and panels
+// working on saved requests: the request finder, save dialog, collections
+// panel, collection runs and workspaces.
+func (m *MainModel) handleCollectionMsg(msg tea.Msg) (bool, tea.Cmd) {
+	var cmd tea.Cmd
+	switch msg := msg.(type) {
+	case workspacesLoadedMsg:
+		m.workspaceModel, cmd = m.workspaceModel.Update(msg)
+
+	case finderLoadedMsg:
+		m.finderModel, cmd = m.finderModel.Update(msg)
+
+	case collectionsLoadedMsg, collectionMovedMsg:
+		m.collectionsModel, cmd = m.collectionsModel.Update(msg)
+
+	case saveFoldersLoadedMsg:
+		m.saveModel, cmd = m.saveModel.Update(msg)
+
+	case requestSavedMsg:
+		cmd = m.handleRequestSavedMsg(msg)
+
+	case collectionRenamedMsg:
+		m.collectionsModel, cmd = m.collectionsModel.Update(msg)
+		if msg.err == nil {
+			m.requestModel.Renamed(msg.request)
+			m.statusMsg = "Renamed to " + requestLabel(msg.request)
+		}
+
+	case collectionRunMsg:
+		if m.runnerService == nil {
+			m.statusMsg = "Cannot run folder: collection runs are not available"
+			return true, nil
+		}
+		m.statusMsg = "Running collection..."
+		cmd = tea.Batch(m.openOverlay(overlayRunner), m.runnerModel.run(msg.folder))
+
+	case foldersLoadedMsg, runProgressMsg:
+		m.runnerModel, cmd = m.runnerModel.Update(msg)
+
+	case runFinishedMsg:
+		m.runnerModel, cmd = m.runnerModel.Update(msg)
+		switch {
+		case msg.err != nil:
+			m.statusMsg = "Run failed"
+		case msg.aborted:
+			m.statusMsg = "Run aborted: " + m.runnerModel.Counts()
+		default:
+			m.statusMsg = "Run finished: " + m.runnerModel.Counts()
+		}
+
+	default:
+		return false, nil
+	}
+	return true, cmd
+}
+
+// handleRequestSavedMsg closes the save dialog once the request is saved,
+// and runs the action that was waiting for it.
+func (m *MainModel) handleRequestSavedMsg(msg requestSavedMsg) tea.Cmd {
+	var cmd tea.Cmd
+	m.saveModel, cmd = m.saveModel.Update(msg)
+	if msg.err != nil {
+		return cmd
+	}
+	m.closeOverlay(overlaySave)
+	m.requestModel.Saved(msg.request)
+	cmd = tea.Batch(cmd, m.requestModel.loadHeaderSuggestions(), m.requestModel.loadURLSuggestions())
+	m.statusMsg = "Saved " + requestLabel(msg.request)
+	if msg.request.Folder != "" {
+		m.statusMsg += " to " + msg.request.Folder
+	}
+	return tea.Batch(cmd, m.runUnsaved())
+}
+
+// handleEnvironmentMsg handles the messages of the environment selector,
+// the OAuth sign-in dialog and the notifications shown in the status bar.
+func (m *MainModel) handleEnvironmentMsg(msg tea.Msg) (bool, tea.Cmd) {
+	var cmd tea.Cmd
+	switch msg := msg.(type) {
+	case environmentsLoadedMsg, environmentSavedMsg:
+		m.environmentsModel, cmd = m.environmentsModel.Update(msg)
+
+	case environmentChosenMsg:
+		m.environmentsModel, cmd = m.environmentsModel.Update(msg)
+		if msg.err == nil {
+			m.closeOverlay(overlayEnvironments)
+			m.setEnvironment(msg.env)
+			m.statusMsg = "Environment: " + m.environmentName()
+		}
+
+	case environmentChangedMsg:
+		if !m.setErrorStatus("Cannot load environment", msg.err) {
+			m.setEnvironment(msg.env)
+		}
+
+	case deviceCodeMsg:
+		m.oauthModel, cmd = m.oauthModel.Update(msg)
+
+	case deviceTokenMsg:
+		m.oauthModel, cmd = m.oauthModel.Update(msg)
+		if msg.err == nil && msg.auth != nil {
+			m.requestModel.SetAuth(msg.auth)
+			m.closeOverlay(overlayOAuth)
+			m.statusMsg = "Signed in: bearer token attached to the request"
+		}
+
+	case updateCheckedMsg:
+		// A failed check is only logged: it should not get in the way.
+		if msg.err == nil && msg.status.Available {
+			m.updateHint = "↑ " + msg.status.Latest.Version + " available (curly upgrade --check)"
+		}
+
+	case scheduleNotificationMsg:
+		m.statusMsg = fmt.Sprintf("⚠ Schedule %q failed at %s: %s",
+			msg.Schedule, msg.At.Local().Format("15:04:05"), msg.Message)
+		cmd = m.waitForNotification()
+
+	default:
+		return false, nil
+	}
+	return true, cmd
+}
+
+// handleSessionMsg handles the messages about the session: drafts of
+// unsaved changes, where the last session was left and the welcome screen.
+func (m *MainModel) handleSessionMsg(msg tea.Msg) (bool, tea.Cmd) {
+	var cmd tea.Cmd
+	switch msg := msg.(type) {
+	case draftTickMsg:
+		cmd = tea.Batch(m.autosave(), m.scheduleAutosave())
+
+	case draftSavedMsg:
+		m.setErrorStatus("Cannot save draft", msg.err)
+
+	case draftRecoveredMsg:
+		if !m.setErrorStatus("Cannot load draft", msg.err) && msg.recovered != nil {
+			m.recovered = msg.recovered
+			m.overlay = overlayDraft
+		}
+
+	case draftDeletedMsg:
+		m.setErrorStatus("Cannot delete draft", msg.err)
+
+	case sessionRestoredMsg:
+		if !m.setErrorStatus("Cannot restore the last session", msg.err) && msg.restored != nil {
+			cmd = m.restoreSession(msg.restored)
+		}
+
+	case firstRunCheckedMsg:
+		if msg.firstRun && m.overlay == overlayNone {
+			m.overlay = overlayWelcome
+		}
+
+	case welcomeFinishedMsg:
+		cmd = m.handleWelcomeFinishedMsg(msg)
+
+	default:
+		return false, nil
+	}
+	return true, cmd
+}
+
+// handleWelcomeFinishedMsg closes the welcome screen once the user is done
+// with it, opening the request it created, if any.
+func (m *MainModel) handleWelcomeFinishedMsg(msg welcomeFinishedMsg) tea.Cmd {
+	var cmd tea.Cmd
+	m.welcomeModel, cmd = m.welcomeModel.Update(msg)
+	if msg.err != nil {
+		return cmd
+	}
+	m.closeOverlay(overlayWelcome)
+	if msg.request == nil {
+		m.statusMsg = msg.status
+		return cmd
+	}
+	return tea.Batch(cmd, m.openRequest(msg.request, msg.status))
+}
+
+// handleConnectionMsg handles the messages of the WebSocket tab and the load
+// test panel.
+func (m *MainModel) handleConnectionMsg(msg tea.Msg) (bool, tea.Cmd) {
+	var cmd tea.Cmd
+	switch msg := msg.(type) {
+	case wsConnectedMsg:
+		m.webSocketModel, cmd = m.webSocketModel.Update(msg)
+		if msg.err != nil {
+			m.statusMsg = "WebSocket connection failed"
+		} else {
+			m.statusMsg = "Connected to " + msg.session.Response.Sent.URL
+		}
+
+	case wsReceivedMsg, wsSentMsg:
+		m.webSocketModel, cmd = m.webSocketModel.Update(msg)
+
+	case wsClosedMsg:
+		// The session was recorded to history as it closed.
+		m.webSocketModel, cmd = m.webSocketModel.Update(msg)
+		if !m.setErrorStatus("WebSocket failed", msg.err) {
+			m.statusMsg = "WebSocket closed"
+		}
+		cmd = tea.Batch(cmd, m.historyModel.loadHistory())
+
+	case loadFinishedMsg:
+		m.loadModel, cmd = m.loadModel.Update(msg)
+		if msg.err != nil {
+			m.statusMsg = "Load test failed"
+		} else {
+			m.statusMsg = fmt.Sprintf("Load test finished: %d requests, %.1f%% failed, %.1f req/s",
+				msg.report.Requests, msg.report.ErrorRate()*100, msg.report.Throughput())
+		}
+
+	default:
+		return false, nil
+	}
+	return true, cmd
+}
+
+// setErrorStatus shows err in the status bar after what, if err is set, and
+// reports whether it was.
+func (m *MainModel) setErrorStatus(what string, err error) bool {
+	if err == nil {
+		return false
+	}
+	m.statusMsg = what + ": " + err.Error()
+	return true
 }
 
 // handleGlobalKey handles global keyboard shortcuts.
 // Returns true if the key was handled (and further processing should stop).
+// An overlay takes every key while it is shown.
 func (m *MainModel) handleGlobalKey(msg tea.KeyMsg) (bool, tea.Cmd) {
+	if handle, ok := overlayKeyHandlers[m.overlay]; ok {
+		return true, handle(m, msg)
+	}
+	if handled, cmd := m.handleInputKey(msg); handled {
+		return true, cmd
+	}
+
 	key := msg.String()
+	for _, shortcut := range overlayShortcuts {
+		if key == m.keys[shortcut.action] && m.overlayAvailable(shortcut.overlay) {
+			return true, m.openOverlay(shortcut.overlay)
+		}
+	}
+
+	// Handle the layout keys.
+	if handled, cmd := m.handleLayoutKey(key); handled {
+		return true, cmd
+	}
 
 	// Handle quit keys.
-	if (key == KeyCtrlC || key == "q") && !m.showHelp {
-		m.quitting = true
-		return true, tea.Quit
+	if key == KeyCtrlC || key == m.keys[ActionQuit] {
+		return true, m.quit()
 	}
 
-	// Handle help toggle.
-	if key == "?" {
-		m.showHelp = !m.showHelp
-		return true, nil
+	return m.handleTabNavigation(key)
+}
+
+// handleInputKey handles the keys that go to the tabs before the global
+// shortcuts, so that filters, fields and messages can be typed. The save and
+// OAuth sign-in dialogs open, and the request copies as curl, even while a
+// field is being edited. It reports whether the key was handled.
+func (m *MainModel) handleInputKey(msg tea.KeyMsg) (bool, tea.Cmd) {
+	if handled, cmd := m.handleFilterKey(msg); handled {
+		return true, cmd
 	}
 
-	// Handle escape (close help).
-	if key == "esc" && m.showHelp {
-		m.showHelp = false
+	key := msg.String()
+	switch {
+	// Esc cancels a request being sent, whichever tab is shown.
+	case key == "esc" && m.requestModel.CancelSend():
+		m.statusMsg = "Canceling request..."
 		return true, nil
+
+	case key == m.keys[ActionSave]:
+		return true, m.openOverlay(overlaySave)
+
+	case key == m.keys[ActionOAuth] && m.activeTab == TabRequest:
+		return true, m.openOverlay(overlayOAuth)
+
+	case (key == m.keys[ActionCopyCurl] || key == m.keys[ActionCopyCurlSecrets]) && m.activeTab == TabRequest:
+		return true, m.copyRequestAsCurl(key == m.keys[ActionCopyCurl])
 	}
 
-	// Handle tab navigation when help is not showing.
-	if !m.showHelp {
-		return m.handleTabNavigation(key)
+	return m.handleFieldKey(msg)
+}
+
+// handleFilterKey passes keys to the response and history filter boxes and
+// the response search box while they are open. It reports whether the key
+// was handled.
+func (m *MainModel) handleFilterKey(msg tea.KeyMsg) (bool, tea.Cmd) {
+	var cmd tea.Cmd
+	switch {
+	case m.activeTab == TabResponse && (m.responseModel.Filtering() || m.responseModel.Searching()):
+		m.responseModel, cmd = m.responseModel.Update(msg)
+	case m.activeTab == TabHistory && m.historyModel.Filtering():
+		m.historyModel, cmd = m.historyModel.Update(msg)
+	default:
+		return false, nil
 	}
+	return true, cmd
+}
 
-	return false, nil
+// handleFieldKey passes keys to the field being edited in the request
+// builder, letting Tab move between its fields, and to the WebSocket tab's
+// inputs, whose handshake sends the request builder's headers and auth. It
+// reports whether the key was handled.
+func (m *MainModel) handleFieldKey(msg tea.KeyMsg) (bool, tea.Cmd) {
+	key := msg.String()
+	var cmd tea.Cmd
+	switch {
+	case m.activeTab == TabRequest && (m.requestModel.Editing() || key == "tab" || key == "shift+tab"):
+		m.requestModel, cmd = m.requestModel.Update(msg)
+	case m.activeTab == TabWebSocket && m.webSocketModel.Typing() && key != KeyCtrlC:
+		m.webSocketModel.SetRequest(m.requestModel.GetRequest())
+		m.webSocketModel, cmd = m.webSocketModel.Update(msg)
+	default:
+		return false, nil
+	}
+	return true, cmd
+}
+
+// handleWorkspaceKey handles keyboard input while the workspace switcher is open.
+// Choosing another workspace quits the program so the caller can reopen storage.
+func (m *MainModel) handleWorkspaceKey(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "esc", m.keys[ActionWorkspaces]:
+		m.overlay = overlayNone
+		return nil
+	case KeyCtrlC, m.keys[ActionQuit]:
+		m.overlay = overlayNone
+		return m.quit()
+	}
+
+	var cmd tea.Cmd
+	m.workspaceModel, cmd = m.workspaceModel.Update(msg)
+
+	chosen := m.workspaceModel.Chosen()
+	if chosen == "" {
+		return cmd
+	}
+
+	m.overlay = overlayNone
+	if chosen == m.workspaceService.Current() {
+		return cmd
+	}
+	return tea.Batch(cmd, m.confirmUnsaved("switch workspace", func(m *MainModel) tea.Cmd {
+		m.switchTo = chosen
+		m.quitting = true
+		return m.exit()
+	}))
+}
+
+// handleWelcomeKey handles keyboard input while the welcome screen is shown.
+// Esc closes it, unless a collection path is being typed, and Ctrl+C quits.
+func (m *MainModel) handleWelcomeKey(msg tea.KeyMsg) tea.Cmd {
+	switch {
+	case msg.String() == KeyCtrlC:
+		m.overlay = overlayNone
+		return m.quit()
+	case msg.String() == "esc" && !m.welcomeModel.ChoosingFile():
+		m.overlay = overlayNone
+		return nil
+	}
+
+	var cmd tea.Cmd
+	m.welcomeModel, cmd = m.welcomeModel.Update(msg)
+	return cmd
+}
+
+// handleCurlImportKey handles keyboard input while the curl import dialog is open.
+// A parsed command is loaded into the request builder without being saved.
+func (m *MainModel) handleCurlImportKey(msg tea.KeyMsg) tea.Cmd {
+	if msg.String() == "esc" {
+		m.overlay = overlayNone
+		return nil
+	}
+
+	var cmd tea.Cmd
+	m.curlImportModel, cmd = m.curlImportModel.Update(msg)
+
+	result := m.curlImportModel.Result()
+	if result == nil {
+		return cmd
+	}
+
+	m.overlay = overlayNone
+	status := "Imported " + result.Source
+	if len(result.Warnings) > 0 {
+		status += " (" + strings.Join(result.Warnings, "; ") + ")"
+	}
+	return tea.Batch(cmd, m.openRequest(result.Request, status))
+}
+
+// handleCodegenKey handles keyboard input while the "copy as…" menu is open.
+func (m *MainModel) handleCodegenKey(msg tea.KeyMsg) tea.Cmd {
+	if msg.String() == "esc" || msg.String() == m.keys[ActionCopyAs] {
+		m.overlay = overlayNone
+		return nil
+	}
+
+	var cmd tea.Cmd
+	m.codegenModel, cmd = m.codegenModel.Update(msg)
+	return cmd
+}
+
+// handleRunnerKey handles keyboard input while the collection run panel is open.
+// Esc aborts a run in progress, and closes the panel otherwise. Closing the
+// panel with Ctrl+X does not stop a run in progress; its summary is shown in
+// the status bar when it finishes.
+func (m *MainModel) handleRunnerKey(msg tea.KeyMsg) tea.Cmd {
+	if msg.String() == "esc" && m.runnerModel.Running() {
+		m.runnerModel.Stop()
+		m.statusMsg = "Aborting run..."
+		return nil
+	}
+	if msg.String() == "esc" || msg.String() == m.keys[ActionRunCollection] {
+		m.overlay = overlayNone
+		return nil
+	}
+
+	var cmd tea.Cmd
+	m.runnerModel, cmd = m.runnerModel.Update(msg)
+	if m.runnerModel.Running() {
+		m.statusMsg = "Running collection..."
+	}
+	return cmd
+}
+
+// handleFinderKey handles keyboard input while the request finder is open.
+// A chosen request is loaded into the request builder.
+func (m *MainModel) handleFinderKey(msg tea.KeyMsg) tea.Cmd {
+	if msg.String() == "esc" || msg.String() == m.keys[ActionFinder] {
+		m.overlay = overlayNone
+		return nil
+	}
+
+	var cmd tea.Cmd
+	m.finderModel, cmd = m.finderModel.Update(msg)
+
+	req := m.finderModel.Chosen()
+	if req == nil {
+		return cmd
+	}
+
+	m.overlay = overlayNone
+	return tea.Batch(cmd, m.openRequest(req, "Opened "+requestLabel(req)))
+}
+
+// handleEnvironmentsKey handles keyboard input while the environment
+// selector is open. Esc and Ctrl+E close it unless a name or variables are
+// being typed.
+func (m *MainModel) handleEnvironmentsKey(msg tea.KeyMsg) tea.Cmd {
+	if !m.environmentsModel.Busy() && (msg.String() == "esc" || msg.String() == m.keys[ActionEnvironments]) {
+		m.overlay = overlayNone
+		return nil
+	}
+
+	var cmd tea.Cmd
+	m.environmentsModel, cmd = m.environmentsModel.Update(msg)
+	return cmd
+}
+
+// handlePreviewKey handles keyboard input while the request preview is open.
+// Enter sends the request unless a variable is undefined.
+func (m *MainModel) handlePreviewKey(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "esc", m.keys[ActionPreview]:
+		m.overlay = overlayNone
+	case "r":
+		m.overlay = overlayNone
+		return m.openRaw(m.requestModel.GetRequest(), false)
+	case "enter":
+		if !m.previewModel.Sendable() || m.requestModel.IsLoading() {
+			return nil
+		}
+		m.overlay = overlayNone
+		m.activeTab = TabRequest
+		return m.requestModel.sendRequest()
+	}
+	return nil
+}
+
+// copyRequestAsCurl returns a command copying the request builder's request
+// as a curl command, with its secrets masked if mask is set.
+func (m *MainModel) copyRequestAsCurl(mask bool) tea.Cmd {
+	if m.codegenService == nil {
+		m.statusMsg = "Cannot copy as curl: code generation is not available"
+		return nil
+	}
+	generate, what := m.codegenService.Generate, "request as curl"
+	if mask {
+		generate, what = m.codegenService.GenerateMasked, "request as curl (secrets masked)"
+	}
+	snippet, err := generate(m.requestModel.GetRequest(), codegen.LanguageCurl)
+	if err != nil {
+		m.statusMsg = "Cannot copy as curl: " + err.Error()
+		return nil
+	}
+	return copyToClipboard(what, snippet)
+}
+
+// openRaw shows req in HTTP wire format; sent reports whether it was sent
+// already.
+func (m *MainModel) openRaw(req *domain.Request, sent bool) tea.Cmd {
+	m.overlay = overlayRaw
+	m.rawModel.SetSize(m.width, m.height)
+	return m.rawModel.Open(req, sent)
+}
+
+// setEnvironment records the active environment and shows its variables in
+// the request builder's URL preview.
+func (m *MainModel) setEnvironment(env *domain.Environment) {
+	m.environment = env
+	m.requestModel.SetEnvironment(env)
+}
+
+// environmentName returns the name of the active environment, or "none".
+func (m MainModel) environmentName() string {
+	if m.environment == nil {
+		return "none"
+	}
+	return m.environment.Name
+}
+
+// handleCollectionsKey handles keyboard input while the collections panel is
+// open. A chosen request is loaded into the request builder.
+func (m *MainModel) handleCollectionsKey(msg tea.KeyMsg) tea.Cmd {
+	if !m.collectionsModel.Renaming() && (msg.String() == "esc" || msg.String() == m.keys[ActionCollections]) {
+		m.overlay = overlayNone
+		return nil
+	}
+
+	var cmd tea.Cmd
+	m.collectionsModel, cmd = m.collectionsModel.Update(msg)
+
+	req := m.collectionsModel.Chosen()
+	if req == nil {
+		return cmd
+	}
+
+	m.overlay = overlayNone
+	return tea.Batch(cmd, m.openRequest(req, "Opened "+requestLabel(req)))
+}
+
+// handleLoadKey handles keyboard input while the load test panel is open.
+// Esc stops a test in progress, and closes the panel otherwise.
+func (m *MainModel) handleLoadKey(msg tea.KeyMsg) tea.Cmd {
+	if msg.String() == "esc" {
+		if m.loadModel.Running() {
+			m.loadModel.Stop()
+			return nil
+		}
+		m.overlay = overlayNone
+		return nil
+	}
+
+	var cmd tea.Cmd
+	m.loadModel, cmd = m.loadModel.Update(msg)
+	if m.loadModel.Running() {
+		m.statusMsg = "Running load test..."
+	}
+	return cmd
+}
+
+// handleOAuthKey handles keyboard input while the OAuth sign-in dialog is
+// open. Esc cancels a sign-in in progress, and closes the dialog otherwise.
+func (m *MainModel) handleOAuthKey(msg tea.KeyMsg) tea.Cmd {
+	if msg.String() == "esc" {
+		if m.oauthModel.Active() {
+			m.oauthModel.Stop()
+			m.statusMsg = "Sign-in canceled"
+			return nil
+		}
+		m.overlay = overlayNone
+		return nil
+	}
+
+	var cmd tea.Cmd
+	m.oauthModel, cmd = m.oauthModel.Update(msg)
+	return cmd
+}
+
+// waitForNotification returns a command that delivers the next failed
+// scheduled execution, or nil when no schedules are running.
+func (m MainModel) waitForNotification() tea.Cmd {
+	if m.schedulerService == nil {
+		return nil
+	}
+	notifications := m.schedulerService.Notifications()
+	return func() tea.Msg {
+		return scheduleNotificationMsg(<-notifications)
+	}
 }
 
 // handleTabNavigation handles tab switching keyboard shortcuts.
@@ -160,31 +1105,78 @@ func (m *MainModel) handleTabNavigation(key string) (bool, tea.Cmd) {
 	case "3":
 		m.activeTab = TabHistory
 		return true, nil
+
+	case "4":
+		m.activeTab = TabWebSocket
+		return true, nil
 	}
 
 	return false, nil
 }
 
-// handleRequestSentMsg handles the request completion message.
-func (m *MainModel) handleRequestSentMsg(msg requestSentMsg) (tea.Model, tea.Cmd) {
-	var cmds []tea.Cmd
+// saveResponseFilter returns a command that remembers filter as the response
+// filter of the saved request with the given ID.
+func (m MainModel) saveResponseFilter(id, filter string) tea.Cmd {
+	return func() tea.Msg {
+		err := m.requestService.SetResponseFilter(context.Background(), id, filter)
+		return responseFilterSavedMsg{err: err}
+	}
+}
 
+// handleRequestSentMsg handles the request completion message.
+func (m *MainModel) handleRequestSentMsg(msg requestSentMsg) tea.Cmd {
 	// Update request model with the message.
 	var cmd tea.Cmd
 	m.requestModel, cmd = m.requestModel.Update(msg)
-	cmds = append(cmds, cmd)
 
 	// Also update response model with the new response.
 	if msg.response != nil {
 		m.responseModel.SetResponse(msg.response)
-		// Switch to response tab to show the result.
-		m.activeTab = TabResponse
-		m.statusMsg = "Request completed successfully"
+		// Switch to response tab to show the result, unless it is beside
+		// the request builder.
+		if !m.splitShown() {
+			m.activeTab = TabResponse
+		}
+		if n := len(msg.response.AssertionFailures); n > 0 {
+			m.statusMsg = fmt.Sprintf("Request completed with %d assertion failures", n)
+		} else {
+			m.statusMsg = "Request completed successfully"
+		}
+	} else if errors.Is(msg.err, context.Canceled) {
+		m.statusMsg = "Request canceled"
 	} else if msg.err != nil {
 		m.statusMsg = "Request failed"
 	}
 
-	return m, tea.Batch(cmds...)
+	return cmd
+}
+
+// handleStreamClosedMsg handles the end of an event stream: the events stay
+// in view, and the request builder can send again.
+func (m *MainModel) handleStreamClosedMsg(msg streamClosedMsg) tea.Cmd {
+	events := len(m.responseModel.events)
+	var end string
+	switch {
+	case msg.err == nil:
+		end = "closed by server"
+		m.statusMsg = fmt.Sprintf("Stream closed by the server after %d events", events)
+	case errors.Is(msg.err, context.Canceled):
+		end = "stopped"
+		m.statusMsg = fmt.Sprintf("Stream stopped after %d events", events)
+	default:
+		end = "failed"
+		m.statusMsg = fmt.Sprintf("Stream failed after %d events: %v", events, msg.err)
+	}
+	m.responseModel.FinishStream(msg.response, end)
+
+	// Stopping the stream is not an error to show in the request builder.
+	sent := requestSentMsg{}
+	if end == "failed" {
+		sent.err = msg.err
+	}
+	var cmd tea.Cmd
+	m.requestModel, cmd = m.requestModel.Update(sent)
+	return cmd
 }
 
 // delegateToActiveTab delegates messages to the currently active tab's model.
@@ -198,20 +1190,48 @@ func (m *MainModel) delegateToActiveTab(msg tea.Msg) tea.Cmd {
 		m.responseModel, cmd = m.responseModel.Update(msg)
 	case TabHistory:
 		m.historyModel, cmd = m.historyModel.Update(msg)
+	case TabWebSocket:
+		m.webSocketModel.SetRequest(m.requestModel.GetRequest())
+		m.webSocketModel, cmd = m.webSocketModel.Update(msg)
 	}
 
 	return cmd
 }
 
-// View renders the main view with tabs.
+// View renders the main view with tabs. Kitty images stay on screen until
+// deleted, so they are cleared whenever the response pane does not show one.
 func (m MainModel) View() string {
+	view := m.view()
+	if m.responseModel.KittyImages() && !m.imageInView() {
+		return imagepreview.KittyClear + view
+	}
+	return view
+}
+
+// imageInView reports whether the response pane, showing an image preview,
+// is on screen.
+func (m MainModel) imageInView() bool {
+	return !m.quitting && m.overlay == overlayNone &&
+		(m.activeTab == TabResponse || m.splitShown()) && m.responseModel.ImageShown()
+}
+
+// SetImagePreview sets how image responses are previewed.
+func (m *MainModel) SetImagePreview(p imagepreview.Protocol) {
+	m.responseModel.SetImagePreview(p)
+}
+
+// view renders the main view for View.
+func (m MainModel) view() string {
 	if m.quitting {
+		if m.switchTo != "" {
+			return "Switching to workspace " + m.switchTo + "...\n"
+		}
 		return "Thanks for using curly!\n"
 	}
 
-	// Show help overlay if active.
-	if m.showHelp {
-		return m.renderHelp()
+	// Show the overlay if one is open.
+	if render, ok := overlayViews[m.overlay]; ok {
+		return render(m)
 	}
 
 	var sections []string
@@ -226,10 +1246,15 @@ func (m MainModel) View() string {
 	switch m.activeTab {
 	case TabRequest:
 		activeView = m.requestModel.View()
+		if m.splitShown() {
+			activeView = m.renderSplit()
+		}
 	case TabResponse:
 		activeView = m.responseModel.View()
 	case TabHistory:
 		activeView = m.historyModel.View()
+	case TabWebSocket:
+		activeView = m.webSocketModel.View()
 	}
 	sections = append(sections, activeView)
 
@@ -240,25 +1265,52 @@ func (m MainModel) View() string {
 	return strings.Join(sections, "\n")
 }
 
-// renderTabs renders the tab navigation.
+// renderTabs renders the tab navigation, followed by the active workspace.
+// The Request tab is marked with "*" while the request has unsaved changes.
 func (m MainModel) renderTabs() string {
 	var parts []string
 	for i, tab := range m.tabs {
+		if i == TabRequest && m.requestModel.Modified() {
+			tab += "*"
+		}
 		if i == m.activeTab {
 			parts = append(parts, "["+tab+"]")
 		} else {
 			parts = append(parts, " "+tab+" ")
 		}
 	}
+	if m.workspaceService != nil {
+		parts = append(parts, "  workspace: "+m.workspaceService.Current())
+	}
 	return strings.Join(parts, " ")
 }
 
-// renderStatusBar renders the bottom status bar.
+// renderStatusBar renders the bottom status bar: the active environment when
+// environments are available, whether the request was changed, a summary of
+// the last response colored by its status class, a hint when a newer release
+// is out, and the status message.
 func (m MainModel) renderStatusBar() string {
-	if m.statusMsg != "" {
-		return m.statusMsg
+	var parts []string
+	if m.environmentService != nil {
+		parts = append(parts, "env: "+m.environmentName()+" ("+m.keys.Label(ActionEnvironments)+")")
+	}
+	if m.requestModel.Modified() {
+		parts = append(parts, "● modified")
+	}
+	if summary := m.responseModel.StatusSummary(); summary != "" {
+		resp := m.responseModel.GetResponse()
+		parts = append(parts, styles.RenderStatusCode(resp.StatusCode, summary))
+	}
+	if m.updateHint != "" {
+		parts = append(parts, m.updateHint)
 	}
-	return "Press ? for help"
+
+	status := m.statusMsg
+	if status == "" {
+		status = "Press ? for help"
+	}
+	parts = append(parts, status)
+	return strings.Join(parts, " │ ")
 }
 
 // renderHelp renders the help screen.
@@ -270,17 +1322,23 @@ func (m MainModel) renderHelp() string {
 	sections = append(sections, "                    CURLY - HELP & SHORTCUTS")
 	sections = append(sections, "════════════════════════════════════════════════════════════")
 	sections = append(sections, "")
-	sections = append(sections, "GLOBAL: q/Ctrl+C=quit • ?=help • Tab=next tab • 1/2/3=jump to tab")
+	k := m.keys.Label
+	sections = append(sections, "GLOBAL: "+k(ActionQuit)+"/Ctrl+C=quit • "+k(ActionHelp)+"=help • Tab=next tab • 1-4=jump to tab • "+
+		k(ActionWorkspaces)+"=workspaces • "+k(ActionImport)+"=import curl • "+k(ActionCopyAs)+"=copy as code • "+
+		k(ActionRunCollection)+"=run collection • "+k(ActionLoadTest)+"=load test • "+k(ActionEnvironments)+"=environments • "+
+		k(ActionPreview)+"=preview request • "+k(ActionToggleSplit)+"=response pane • "+k(ActionNarrowSplit)+"/"+k(ActionWidenSplit)+": resize panes")
+	sections = append(sections, "")
+	sections = append(sections, "REQUEST: Tab=next field • Ctrl+Enter=send • ←/→=change method/auth • Ctrl+T=GraphQL schema • Ctrl+Space=complete query")
 	sections = append(sections, "")
-	sections = append(sections, "REQUEST: Tab=next field • Ctrl+Enter=send • ←/→=change method/auth")
+	sections = append(sections, "RESPONSE: h=toggle headers/body • /=filter body by JSONPath or jq path • Esc=clear filter • ↑↓=scroll")
 	sections = append(sections, "")
-	sections = append(sections, "RESPONSE: h=toggle headers/body • ↑↓=scroll")
+	sections = append(sections, "HISTORY: ↑↓=navigate • Enter=load • p=replay • d=delete • m=mark • c=compare with marked • r=refresh")
 	sections = append(sections, "")
-	sections = append(sections, "HISTORY: ↑↓=navigate • Enter=load • d=delete • r=refresh")
+	sections = append(sections, "WEBSOCKET: Enter=connect/disconnect or send • Tab=URL/message • Alt+J=JSON only • Esc=leave input • d=disconnect • x=clear log")
 	sections = append(sections, "")
 	sections = append(sections, "════════════════════════════════════════════════════════════")
 	sections = append(sections, "")
-	sections = append(sections, "                   Press ESC or ? to close")
+	sections = append(sections, "                   Press ESC or "+k(ActionHelp)+" to close")
 	sections = append(sections, "")
 
 	return strings.Join(sections, "\n")
@@ -291,6 +1349,12 @@ func (m MainModel) GetActiveTab() int {
 	return m.activeTab
 }
 
+// SwitchWorkspace returns the workspace the user chose to switch to, or "" if
+// the program exited for any other reason.
+func (m MainModel) SwitchWorkspace() string {
+	return m.switchTo
+}
+
 // SetStatusMessage sets the status bar message.
 func (m *MainModel) SetStatusMessage(msg string) {
 	m.statusMsg = msg
@@ -0,0 +1,257 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/williajm/curly/internal/app"
+	"github.com/williajm/curly/internal/domain"
+	"github.com/williajm/curly/internal/infrastructure/oauth"
+)
+
+// OAuth device sign-in form fields.
+const (
+	oauthFieldDeviceURL = iota
+	oauthFieldTokenURL
+	oauthFieldClientID
+	oauthFieldScope
+	oauthFieldCount
+)
+
+// OAuthDeviceModel represents the OAuth device sign-in dialog: a form for
+// the authorization server, then the code for the user to enter elsewhere
+// while the token endpoint is polled.
+type OAuthDeviceModel struct {
+	// Services.
+	authService *app.AuthService
+
+	// Form inputs, kept between openings for the session.
+	inputs     []textinput.Model
+	focusIndex int
+	errorMsg   string
+
+	// config is what the sign-in in progress was started with.
+	config oauth.DeviceConfig
+
+	// code is the device code being waited on, nil before the server
+	// sends it. ctx is the sign-in in progress, canceled by cancel, which
+	// is nil when there is none.
+	code    *oauth.DeviceCode
+	expires time.Time
+	ctx     context.Context
+	cancel  context.CancelFunc
+}
+
+// Custom messages.
+type deviceCodeMsg struct {
+	code *oauth.DeviceCode
+	err  error
+}
+
+type deviceTokenMsg struct {
+	auth domain.AuthConfig
+	err  error
+}
+
+// NewOAuthDeviceModel creates a new OAuth device sign-in dialog model.
+func NewOAuthDeviceModel(authService *app.AuthService) OAuthDeviceModel {
+	inputs := make([]textinput.Model, oauthFieldCount)
+	for i := range inputs {
+		inputs[i] = textinput.New()
+		inputs[i].Width = 50
+	}
+	inputs[oauthFieldDeviceURL].Placeholder = "https://auth.example.com/oauth/device/code"
+	inputs[oauthFieldTokenURL].Placeholder = "https://auth.example.com/oauth/token"
+	inputs[oauthFieldScope].Placeholder = "optional, space separated"
+
+	return OAuthDeviceModel{
+		authService: authService,
+		inputs:      inputs,
+	}
+}
+
+// Open shows the form, with the values entered last time.
+func (m *OAuthDeviceModel) Open() tea.Cmd {
+	m.errorMsg = ""
+	m.code = nil
+	m.focusIndex = oauthFieldDeviceURL
+	return m.updateFocus()
+}
+
+// Active reports whether a sign-in is in progress.
+func (m OAuthDeviceModel) Active() bool {
+	return m.cancel != nil
+}
+
+// Stop cancels the sign-in in progress, if any, and returns to the form.
+func (m *OAuthDeviceModel) Stop() {
+	if m.cancel != nil {
+		m.cancel()
+	}
+	m.ctx, m.cancel = nil, nil
+	m.code = nil
+}
+
+// Update handles messages and updates the model.
+func (m OAuthDeviceModel) Update(msg tea.Msg) (OAuthDeviceModel, tea.Cmd) {
+	switch msg := msg.(type) {
+	case deviceCodeMsg:
+		cmd := m.codeReceived(msg)
+		return m, cmd
+
+	case deviceTokenMsg:
+		if m.cancel == nil || errors.Is(msg.err, context.Canceled) {
+			return m, nil
+		}
+		m.Stop()
+		if msg.err != nil {
+			m.errorMsg = "Sign-in failed: " + msg.err.Error()
+		}
+		return m, nil
+
+	case tea.KeyMsg:
+		if m.Active() {
+			if msg.String() == "y" && m.code != nil {
+				return m, copyToClipboard("sign-in code", m.code.UserCode)
+			}
+			return m, nil
+		}
+		switch msg.String() {
+		case "tab", "down":
+			m.focusIndex = (m.focusIndex + 1) % oauthFieldCount
+			return m, m.updateFocus()
+		case "shift+tab", "up":
+			m.focusIndex = (m.focusIndex - 1 + oauthFieldCount) % oauthFieldCount
+			return m, m.updateFocus()
+		case "enter":
+			return m, m.start()
+		}
+	}
+
+	var cmd tea.Cmd
+	m.inputs[m.focusIndex], cmd = m.inputs[m.focusIndex].Update(msg)
+	return m, cmd
+}
+
+// codeReceived shows the device code and starts polling for the token, or
+// reports why the code could not be requested.
+func (m *OAuthDeviceModel) codeReceived(msg deviceCodeMsg) tea.Cmd {
+	if m.cancel == nil || errors.Is(msg.err, context.Canceled) {
+		// Canceled while the code was requested.
+		return nil
+	}
+	if msg.err != nil {
+		m.Stop()
+		m.errorMsg = msg.err.Error()
+		return nil
+	}
+	m.code = msg.code
+	m.expires = time.Now().Add(time.Duration(msg.code.ExpiresIn) * time.Second)
+	return m.await()
+}
+
+// updateFocus focuses the current input and blurs the others.
+func (m *OAuthDeviceModel) updateFocus() tea.Cmd {
+	var cmd tea.Cmd
+	for i := range m.inputs {
+		if i == m.focusIndex {
+			cmd = m.inputs[i].Focus()
+		} else {
+			m.inputs[i].Blur()
+		}
+	}
+	return cmd
+}
+
+// start validates the form and returns a command that requests a device
+// code.
+func (m *OAuthDeviceModel) start() tea.Cmd {
+	cfg := oauth.DeviceConfig{
+		DeviceAuthURL: strings.TrimSpace(m.inputs[oauthFieldDeviceURL].Value()),
+		TokenURL:      strings.TrimSpace(m.inputs[oauthFieldTokenURL].Value()),
+		ClientID:      strings.TrimSpace(m.inputs[oauthFieldClientID].Value()),
+		Scope:         strings.TrimSpace(m.inputs[oauthFieldScope].Value()),
+	}
+	if err := cfg.Validate(); err != nil {
+		m.errorMsg = err.Error()
+		return nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	m.config = cfg
+	m.ctx, m.cancel = ctx, cancel
+	m.errorMsg = ""
+
+	authService := m.authService
+	return func() tea.Msg {
+		code, err := authService.StartDeviceLogin(ctx, cfg)
+		return deviceCodeMsg{code: code, err: err}
+	}
+}
+
+// await returns a command that polls for the token until the user signs in.
+func (m OAuthDeviceModel) await() tea.Cmd {
+	authService, ctx, cfg, code := m.authService, m.ctx, m.config, m.code
+	return func() tea.Msg {
+		auth, err := authService.AwaitDeviceToken(ctx, cfg, code)
+		return deviceTokenMsg{auth: auth, err: err}
+	}
+}
+
+// View renders the form, or the code to enter while signing in.
+func (m OAuthDeviceModel) View() string {
+	var sections []string
+
+	sections = append(sections, "══ OAuth Device Sign-in ══")
+	sections = append(sections, "")
+
+	if m.code != nil {
+		sections = append(sections, "Open this page on any device and enter the code:")
+		sections = append(sections, "")
+		sections = append(sections, "  "+m.code.VerificationURI)
+		sections = append(sections, "")
+		sections = append(sections, "  Code: "+m.code.UserCode)
+		if m.code.VerificationURIComplete != "" {
+			sections = append(sections, "")
+			sections = append(sections, "Or open this page, which includes the code:")
+			sections = append(sections, "  "+m.code.VerificationURIComplete)
+		}
+		sections = append(sections, "")
+		waiting := "Waiting for sign-in..."
+		if m.code.ExpiresIn > 0 {
+			waiting = fmt.Sprintf("Waiting for sign-in (the code expires at %s)...", m.expires.Format("15:04"))
+		}
+		sections = append(sections, waiting)
+		sections = append(sections, "")
+		sections = append(sections, "y: copy code • Esc: cancel")
+		return strings.Join(sections, "\n")
+	}
+
+	labels := []string{"Device URL: ", "Token URL:  ", "Client ID:  ", "Scope:      "}
+	for i, input := range m.inputs {
+		sections = append(sections, labels[i]+input.View())
+	}
+
+	if m.errorMsg != "" {
+		sections = append(sections, "")
+		sections = append(sections, "Error: "+m.errorMsg)
+	}
+
+	sections = append(sections, "")
+	if m.Active() {
+		sections = append(sections, "Requesting a sign-in code...")
+		sections = append(sections, "")
+		sections = append(sections, "Esc: cancel")
+	} else {
+		sections = append(sections, "The token is attached to the request as bearer auth.")
+		sections = append(sections, "")
+		sections = append(sections, "Tab: next field • Enter: sign in • Esc: close")
+	}
+
+	return strings.Join(sections, "\n")
+}
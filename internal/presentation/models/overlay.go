@@ -0,0 +1,204 @@
+package models
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// overlay is a dialog or panel shown in place of the tabs. At most one is
+// shown at a time, and it takes every key until it closes.
+type overlay int
+
+// Overlays.
+const (
+	overlayNone overlay = iota
+	overlayHelp
+	overlayDraft
+	overlayWelcome
+	overlayWorkspaces
+	overlayCurlImport
+	overlayCodegen
+	overlayDiff
+	overlayRunner
+	overlayFinder
+	overlayCollections
+	overlayLoad
+	overlayEnvironments
+	overlayPreview
+	overlayRaw
+	overlaySave
+	overlayOAuth
+	overlayUnsaved
+)
+
+// overlayKeyHandlers handle keyboard input while their overlay is shown.
+var overlayKeyHandlers = map[overlay]func(m *MainModel, msg tea.KeyMsg) tea.Cmd{
+	overlayHelp:         (*MainModel).handleHelpKey,
+	overlayDraft:        (*MainModel).handleDraftKey,
+	overlayWelcome:      (*MainModel).handleWelcomeKey,
+	overlayWorkspaces:   (*MainModel).handleWorkspaceKey,
+	overlayCurlImport:   (*MainModel).handleCurlImportKey,
+	overlayCodegen:      (*MainModel).handleCodegenKey,
+	overlayDiff:         (*MainModel).handleDiffKey,
+	overlayRunner:       (*MainModel).handleRunnerKey,
+	overlayFinder:       (*MainModel).handleFinderKey,
+	overlayCollections:  (*MainModel).handleCollectionsKey,
+	overlayLoad:         (*MainModel).handleLoadKey,
+	overlayEnvironments: (*MainModel).handleEnvironmentsKey,
+	overlayPreview:      (*MainModel).handlePreviewKey,
+	overlayRaw:          (*MainModel).handleRawKey,
+	overlaySave:         (*MainModel).handleSaveKey,
+	overlayOAuth:        (*MainModel).handleOAuthKey,
+	overlayUnsaved:      (*MainModel).handleUnsavedKey,
+}
+
+// overlayViews render their overlay.
+var overlayViews = map[overlay]func(m MainModel) string{
+	overlayHelp:         MainModel.renderHelp,
+	overlayDraft:        MainModel.renderDraft,
+	overlayWelcome:      func(m MainModel) string { return m.welcomeModel.View() },
+	overlayWorkspaces:   func(m MainModel) string { return m.workspaceModel.View() },
+	overlayCurlImport:   func(m MainModel) string { return m.curlImportModel.View() },
+	overlayCodegen:      func(m MainModel) string { return m.codegenModel.View() },
+	overlayDiff:         func(m MainModel) string { return m.diffModel.View() },
+	overlayRunner:       func(m MainModel) string { return m.runnerModel.View() },
+	overlayFinder:       func(m MainModel) string { return m.finderModel.View() },
+	overlayCollections:  func(m MainModel) string { return m.collectionsModel.View() },
+	overlayLoad:         func(m MainModel) string { return m.loadModel.View() },
+	overlayEnvironments: func(m MainModel) string { return m.environmentsModel.View() },
+	overlayPreview:      func(m MainModel) string { return m.previewModel.View() },
+	overlayRaw:          func(m MainModel) string { return m.rawModel.View() },
+	overlaySave:         func(m MainModel) string { return m.saveModel.View() },
+	overlayOAuth:        func(m MainModel) string { return m.oauthModel.View() },
+	overlayUnsaved:      MainModel.renderUnsaved,
+}
+
+// overlayShortcuts are the global shortcuts that open an overlay from the
+// tabs once no input field has taken the key.
+var overlayShortcuts = []struct {
+	action  string
+	overlay overlay
+}{
+	{ActionImport, overlayCurlImport},
+	{ActionFinder, overlayFinder},
+	{ActionEnvironments, overlayEnvironments},
+	{ActionPreview, overlayPreview},
+	{ActionCopyAs, overlayCodegen},
+	{ActionRunCollection, overlayRunner},
+	{ActionCollections, overlayCollections},
+	{ActionLoadTest, overlayLoad},
+	{ActionWorkspaces, overlayWorkspaces},
+	{ActionHelp, overlayHelp},
+}
+
+// overlayAvailable reports whether the service behind o is set, so that o
+// can be opened.
+func (m *MainModel) overlayAvailable(o overlay) bool {
+	switch o {
+	case overlayCurlImport:
+		return m.importService != nil
+	case overlayCodegen:
+		return m.codegenService != nil
+	case overlayRunner:
+		return m.runnerService != nil
+	case overlayCollections:
+		return m.collectionService != nil
+	case overlayLoad:
+		return m.loadService != nil
+	case overlayEnvironments:
+		return m.environmentService != nil
+	case overlayWorkspaces:
+		return m.workspaceService != nil
+	default:
+		return true
+	}
+}
+
+// openOverlay shows o, opening its model on the request builder's request
+// where it needs one.
+func (m *MainModel) openOverlay(o overlay) tea.Cmd {
+	m.overlay = o
+	switch o {
+	case overlayCurlImport:
+		return m.curlImportModel.Open()
+	case overlayFinder:
+		return m.finderModel.Open()
+	case overlayEnvironments:
+		return m.environmentsModel.Open()
+	case overlayPreview:
+		m.previewModel.Open(m.requestModel.GetRequest(), m.environment)
+	case overlayCodegen:
+		m.codegenModel.Open(m.requestModel.GetRequest())
+	case overlayRunner:
+		return m.runnerModel.Open()
+	case overlayCollections:
+		return m.collectionsModel.Open()
+	case overlayLoad:
+		return m.loadModel.Open(m.requestModel.GetRequest())
+	case overlayWorkspaces:
+		return m.workspaceModel.Open()
+	case overlaySave:
+		return m.saveModel.Open(m.requestModel.GetRequest())
+	case overlayOAuth:
+		return m.oauthModel.Open()
+	}
+	return nil
+}
+
+// closeOverlay hides o if it is the overlay shown.
+func (m *MainModel) closeOverlay(o overlay) {
+	if m.overlay == o {
+		m.overlay = overlayNone
+	}
+}
+
+// handleHelpKey handles keyboard input while the help screen is shown.
+// Esc and the help key close it, and Ctrl+C quits.
+func (m *MainModel) handleHelpKey(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "esc", m.keys[ActionHelp]:
+		m.overlay = overlayNone
+	case KeyCtrlC:
+		m.overlay = overlayNone
+		return m.quit()
+	}
+	return nil
+}
+
+// handleDiffKey handles keyboard input while the response comparison is
+// shown.
+func (m *MainModel) handleDiffKey(msg tea.KeyMsg) tea.Cmd {
+	if msg.String() == "esc" {
+		m.overlay = overlayNone
+		return nil
+	}
+
+	var cmd tea.Cmd
+	m.diffModel, cmd = m.diffModel.Update(msg)
+	return cmd
+}
+
+// handleRawKey handles keyboard input while the raw request view is shown.
+func (m *MainModel) handleRawKey(msg tea.KeyMsg) tea.Cmd {
+	if msg.String() == "esc" {
+		m.overlay = overlayNone
+		return nil
+	}
+
+	var cmd tea.Cmd
+	m.rawModel, cmd = m.rawModel.Update(msg)
+	return cmd
+}
+
+// handleSaveKey handles keyboard input while the save dialog is open.
+// Canceling it also cancels an action waiting for the request to be saved.
+func (m *MainModel) handleSaveKey(msg tea.KeyMsg) tea.Cmd {
+	if msg.String() == "esc" {
+		m.overlay = overlayNone
+		m.unsaved = nil
+		return nil
+	}
+
+	var cmd tea.Cmd
+	m.saveModel, cmd = m.saveModel.Update(msg)
+	return cmd
+}
@@ -0,0 +1,68 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// tabNames are the names of the tabs, in order.
+var tabNames = []string{"Request", "Response", "History", "WebSocket"}
+
+// Preferences are the display settings of the TUI, and how often it saves
+// drafts.
+type Preferences struct {
+	// DefaultTab is the tab shown on launch, such as TabHistory, unless the
+	// last session is restored and KeepDefaultTab is not set.
+	DefaultTab int
+
+	// KeepDefaultTab shows DefaultTab on launch even when the last session
+	// is restored, as when the tab is configured explicitly.
+	KeepDefaultTab bool
+
+	// ShowResponseTime shows how long each response took beside its status.
+	ShowResponseTime bool
+
+	// SyntaxHighlighting colors the keys, strings, numbers and literals of
+	// JSON bodies shown pretty.
+	SyntaxHighlighting bool
+
+	// AutosaveInterval is how often the request builder's unsaved changes
+	// are saved as a draft, when drafts are kept.
+	AutosaveInterval time.Duration
+}
+
+// DefaultPreferences returns the preferences used unless configured.
+func DefaultPreferences() Preferences {
+	return Preferences{
+		DefaultTab:         TabRequest,
+		ShowResponseTime:   true,
+		SyntaxHighlighting: true,
+		AutosaveInterval:   5 * time.Second,
+	}
+}
+
+// TabNamed returns the tab called name, ignoring case.
+func TabNamed(name string) (int, error) {
+	for i, tab := range tabNames {
+		if strings.EqualFold(tab, name) {
+			return i, nil
+		}
+	}
+	available := make([]string, len(tabNames))
+	for i, tab := range tabNames {
+		available[i] = strings.ToLower(tab)
+	}
+	return 0, fmt.Errorf("unknown tab %q (available: %s)", name, strings.Join(available, ", "))
+}
+
+// SetPreferences applies the preferences. It is meant to be called
+// before the program starts, as it also selects the tab shown.
+func (m *MainModel) SetPreferences(p Preferences) {
+	m.activeTab = p.DefaultTab
+	m.keepDefaultTab = p.KeepDefaultTab
+	m.responseModel.showTime = p.ShowResponseTime
+	m.responseModel.syntax = p.SyntaxHighlighting
+	m.runnerModel.showTime = p.ShowResponseTime
+	m.autosaveInterval = p.AutosaveInterval
+}
@@ -0,0 +1,98 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/williajm/curly/internal/domain"
+)
+
+// PreviewModel represents the request preview, which shows the request with
+// the active environment's variables filled in, before it is sent.
+type PreviewModel struct {
+	// Request with its variables resolved, the values of secrets masked.
+	request    *domain.Request
+	envName    string
+	unresolved []string
+}
+
+// NewPreviewModel creates a new request preview model.
+func NewPreviewModel() PreviewModel {
+	return PreviewModel{}
+}
+
+// Open resolves the variables of req with those of env (nil for none),
+// masking the values of secrets.
+func (m *PreviewModel) Open(req *domain.Request, env *domain.Environment) {
+	var vars map[string]string
+	m.envName = "none"
+	if env != nil {
+		vars = env.MaskedVariables()
+		m.envName = env.Name
+	}
+	m.request, m.unresolved = req.ResolveVariables(vars)
+}
+
+// Sendable reports whether every variable of the request is defined.
+func (m PreviewModel) Sendable() bool {
+	return len(m.unresolved) == 0
+}
+
+// View renders the resolved request.
+func (m PreviewModel) View() string {
+	var sections []string
+
+	sections = append(sections, "══ Preview ══")
+	sections = append(sections, "")
+	sections = append(sections, "Environment: "+m.envName)
+	sections = append(sections, "")
+
+	if m.request != nil {
+		sections = append(sections, renderPreviewRequest(m.request)...)
+	}
+
+	if len(m.unresolved) > 0 {
+		sections = append(sections, "")
+		sections = append(sections, "Undefined: "+strings.Join(m.unresolved, ", "))
+		sections = append(sections, "Define them in the environment (Ctrl+E) before sending.")
+	}
+
+	sections = append(sections, "")
+	if m.Sendable() {
+		sections = append(sections, "Enter: send • r: raw request • Esc: close")
+	} else {
+		sections = append(sections, "Esc: close")
+	}
+
+	return strings.Join(sections, "\n")
+}
+
+// renderPreviewRequest renders the request as it will be sent, including
+// the credentials of its auth config. {{fake.*}} placeholders are shown as
+// they are; they are filled in when the request is sent.
+func renderPreviewRequest(req *domain.Request) []string {
+	lines := []string{req.Method + " " + req.URL}
+	if len(req.QueryParams) > 0 {
+		lines = append(lines, "Query:")
+		lines = append(lines, indent(sortedLines(req.QueryParams))...)
+	}
+	if len(req.Headers) > 0 {
+		lines = append(lines, "Headers:")
+		lines = append(lines, indent(sortedLines(req.Headers))...)
+	}
+	switch auth := req.AuthConfig.(type) {
+	case *domain.BasicAuth:
+		lines = append(lines, "Auth: basic")
+		lines = append(lines, indent([]string{"Username: " + auth.Username, "Password: " + auth.Password})...)
+	case *domain.BearerAuth:
+		lines = append(lines, "Auth: bearer")
+		lines = append(lines, indent([]string{"Token: " + auth.Token})...)
+	case *domain.APIKeyAuth:
+		lines = append(lines, fmt.Sprintf("Auth: API key in %s", auth.Location))
+		lines = append(lines, indent([]string{auth.Key + ": " + auth.Value})...)
+	}
+	if req.Body != "" {
+		lines = append(lines, "Body:", req.Body)
+	}
+	return lines
+}
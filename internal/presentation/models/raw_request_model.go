@@ -0,0 +1,116 @@
+package models
+
+import (
+	"context"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/williajm/curly/internal/app"
+	"github.com/williajm/curly/internal/domain"
+)
+
+// RawRequestModel represents the raw request view, which shows a request in
+// HTTP wire format exactly as the HTTP client sends it: the request line, the
+// final headers including auth, and the encoded body.
+type RawRequestModel struct {
+	// Services.
+	requestService *app.RequestService
+
+	// sent reports whether the request shown is one that was sent, rather
+	// than the one in the request builder.
+	sent     bool
+	loading  bool
+	errorMsg string
+	view     viewport.Model
+}
+
+// rawRequestMsg carries a request in wire format.
+type rawRequestMsg struct {
+	raw string
+	err error
+}
+
+// showSentRequestMsg asks for the request behind the response to be shown in
+// wire format; the response view does not show requests itself.
+type showSentRequestMsg struct{}
+
+// NewRawRequestModel creates a new raw request model.
+func NewRawRequestModel(requestService *app.RequestService) RawRequestModel {
+	return RawRequestModel{
+		requestService: requestService,
+		view:           viewport.New(80, 20),
+	}
+}
+
+// Open starts building the wire format of req. sent reports whether req was
+// sent already, in which case it is shown as it was sent.
+func (m *RawRequestModel) Open(req *domain.Request, sent bool) tea.Cmd {
+	m.sent = sent
+	m.loading = true
+	m.errorMsg = ""
+	m.view.SetContent("")
+	req = req.Clone()
+	return func() tea.Msg {
+		raw, err := m.requestService.RawRequest(context.Background(), req)
+		return rawRequestMsg{raw: raw, err: err}
+	}
+}
+
+// SetSize sets the space available to the view.
+func (m *RawRequestModel) SetSize(width, height int) {
+	m.view.Width = max(width, 40)
+	m.view.Height = max(height-8, 5) // Leave room for the title and help line.
+}
+
+// Update handles messages and updates the model.
+func (m RawRequestModel) Update(msg tea.Msg) (RawRequestModel, tea.Cmd) {
+	switch msg := msg.(type) {
+	case rawRequestMsg:
+		m.loading = false
+		if msg.err != nil {
+			m.errorMsg = msg.err.Error()
+			return m, nil
+		}
+		m.view.SetContent(strings.ReplaceAll(msg.raw, "\r\n", "\n"))
+		m.view.GotoTop()
+		return m, nil
+
+	case tea.KeyMsg:
+		var cmd tea.Cmd
+		m.view, cmd = m.view.Update(msg)
+		return m, cmd
+	}
+
+	return m, nil
+}
+
+// View renders the request in wire format.
+func (m RawRequestModel) View() string {
+	var sections []string
+
+	title := "══ Raw Request ══"
+	if m.sent {
+		title = "══ Raw Request (as sent) ══"
+	}
+	sections = append(sections, title)
+	sections = append(sections, "")
+
+	switch {
+	case m.loading:
+		sections = append(sections, "Building request...")
+	case m.errorMsg != "":
+		sections = append(sections, "Error: "+m.errorMsg)
+	default:
+		sections = append(sections, m.view.View())
+		if !m.sent {
+			sections = append(sections, "")
+			sections = append(sections, "Fake data ({{fake.*}}) is filled in again when the request is sent.")
+		}
+	}
+
+	sections = append(sections, "")
+	sections = append(sections, "↑↓/PgUp/PgDn: scroll • Esc: close")
+
+	return strings.Join(sections, "\n")
+}
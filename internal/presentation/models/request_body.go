@@ -0,0 +1,446 @@
+package models
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/filepicker"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/williajm/curly/internal/domain"
+	"github.com/williajm/curly/internal/infrastructure/graphql"
+	"github.com/williajm/curly/internal/presentation/components"
+)
+
+// Body modes, in the order the body type selector shows them.
+const (
+	bodyRaw = iota
+	bodyJSON
+	bodyForm
+	bodyGraphQL
+	bodyFile
+)
+
+// bodyModeNames labels the body modes in the selector.
+var bodyModeNames = []string{"Raw", "JSON", "Form", "GraphQL", "File"}
+
+// Content types set when the body type is chosen.
+const (
+	contentTypeHeader = "Content-Type"
+	contentTypeJSON   = "application/json"
+	contentTypeForm   = "application/x-www-form-urlencoded"
+	contentTypeBinary = "application/octet-stream"
+)
+
+// filePickerHeight is how many directory entries the file picker shows.
+const filePickerHeight = 8
+
+// The GraphQL variables editor sits beside the query editor, this wide, when
+// the window has room for both; otherwise it goes below, as wide as the query.
+const (
+	variablesPaneWidth  = 36
+	variablesPaneGap    = "  "
+	variablesPaneHeight = 4 // when below the query
+)
+
+// newBodyFilePicker creates the file picker for file bodies.
+func newBodyFilePicker() filepicker.Model {
+	picker := filepicker.New()
+	picker.AutoHeight = false
+	picker.ShowPermissions = false
+	picker.SetHeight(filePickerHeight)
+	return picker
+}
+
+// handleBodyTypeField handles keyboard input for the body type selector.
+func (m *RequestModel) handleBodyTypeField(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "left", "h":
+		if m.bodyMode > 0 {
+			return m.setBodyMode(m.bodyMode - 1)
+		}
+	case "right", "l":
+		if m.bodyMode < len(bodyModeNames)-1 {
+			return m.setBodyMode(m.bodyMode + 1)
+		}
+	}
+	return nil
+}
+
+// setBodyMode switches the body editor and sets the Content-Type header to
+// match. Raw bodies keep whatever Content-Type the headers have, and file
+// bodies set it once a file is chosen.
+func (m *RequestModel) setBodyMode(mode int) tea.Cmd {
+	m.bodyMode = mode
+	m.bodyFileError = ""
+
+	switch mode {
+	case bodyJSON, bodyGraphQL:
+		m.headersEditor.Set(contentTypeHeader, contentTypeJSON)
+	case bodyForm:
+		m.headersEditor.Set(contentTypeHeader, contentTypeForm)
+	case bodyFile:
+		return m.filePicker.Init()
+	}
+	return nil
+}
+
+// handleFilePicker passes input to the file picker and loads the chosen file.
+func (m *RequestModel) handleFilePicker(msg tea.Msg) tea.Cmd {
+	var cmd tea.Cmd
+	m.filePicker, cmd = m.filePicker.Update(msg)
+	if ok, path := m.filePicker.DidSelectFile(msg); ok {
+		m.loadBodyFile(path)
+	}
+	return cmd
+}
+
+// loadBodyFile makes the contents of path the body and sets the Content-Type
+// from its extension.
+func (m *RequestModel) loadBodyFile(path string) {
+	data, err := os.ReadFile(path) // #nosec G304 -- path is chosen by the user
+	if err != nil {
+		m.bodyFileError = err.Error()
+		return
+	}
+
+	m.bodyFileError = ""
+	m.bodyFilePath = path
+	m.bodyFile = string(data)
+
+	contentType := mime.TypeByExtension(filepath.Ext(path))
+	if contentType == "" {
+		contentType = contentTypeBinary
+	}
+	m.headersEditor.Set(contentTypeHeader, contentType)
+}
+
+// bodyValue returns the body the current mode produces.
+func (m RequestModel) bodyValue() string {
+	switch m.bodyMode {
+	case bodyForm:
+		return encodeForm(m.formEditor.Rows())
+	case bodyGraphQL:
+		operationName := m.selectedOperation().Name
+		body, err := graphql.BuildBody(m.bodyTextArea.Value(), m.variablesTextArea.Value(), operationName)
+		if err != nil {
+			// Invalid variables are reported by bodyErr; send the query alone.
+			body, _ = graphql.BuildBody(m.bodyTextArea.Value(), "", operationName)
+		}
+		return body
+	case bodyFile:
+		return m.bodyFile
+	default:
+		return m.bodyTextArea.Value()
+	}
+}
+
+// bodyErr reports a body that should not be sent: invalid JSON, invalid
+// GraphQL variables, invalid form rows or no chosen file.
+func (m RequestModel) bodyErr() error {
+	switch m.bodyMode {
+	case bodyJSON:
+		if body := m.bodyTextArea.Value(); strings.TrimSpace(body) != "" {
+			if err := validateJSON(body); err != nil {
+				return fmt.Errorf("invalid JSON body: %w", err)
+			}
+		}
+	case bodyForm:
+		return m.formEditor.Err()
+	case bodyGraphQL:
+		if _, err := graphql.BuildBody(m.bodyTextArea.Value(), m.variablesTextArea.Value(), ""); err != nil {
+			return fmt.Errorf("invalid GraphQL variables: %w", err)
+		}
+	case bodyFile:
+		if m.bodyFilePath == "" {
+			return fmt.Errorf("choose a file for the body")
+		}
+	}
+	return nil
+}
+
+// formatJSONBody indents a valid JSON body. Invalid bodies are left alone.
+func (m *RequestModel) formatJSONBody() {
+	body := m.bodyTextArea.Value()
+	if strings.TrimSpace(body) == "" || validateJSON(body) != nil {
+		return
+	}
+	var indented bytes.Buffer
+	if err := json.Indent(&indented, []byte(strings.TrimSpace(body)), "", "  "); err == nil && indented.String() != body {
+		m.bodyTextArea.SetValue(indented.String())
+	}
+}
+
+// loadBody chooses the body mode for req from its Content-Type and fills in
+// that mode's editor.
+func (m *RequestModel) loadBody(req *domain.Request) {
+	m.bodyMode = bodyRaw
+	m.bodyFile = ""
+	m.bodyFilePath = ""
+	m.bodyFileError = ""
+	m.variablesTextArea.SetValue("")
+	m.operationName = ""
+	m.formEditor.SetRows(nil)
+	m.bodyTextArea.SetValue(req.Body)
+
+	contentType, _ := m.headersEditor.Get(contentTypeHeader)
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch {
+	case mediaType == contentTypeForm:
+		if rows, err := decodeForm(req.Body); err == nil {
+			m.bodyMode = bodyForm
+			m.formEditor.SetRows(rows)
+		}
+	case mediaType == contentTypeJSON || strings.HasSuffix(mediaType, "+json"):
+		if query, variables, operationName, ok := graphql.SplitBody(req.Body); ok {
+			m.bodyMode = bodyGraphQL
+			m.bodyTextArea.SetValue(query)
+			m.variablesTextArea.SetValue(variables)
+			m.operationName = operationName
+		} else {
+			m.bodyMode = bodyJSON
+		}
+	}
+}
+
+// renderBodyType renders the body type selector.
+func (m RequestModel) renderBodyType() string {
+	var parts []string
+	for i, name := range bodyModeNames {
+		if i == m.bodyMode {
+			parts = append(parts, "["+name+"]")
+		} else {
+			parts = append(parts, name)
+		}
+	}
+	return "Body type: " + strings.Join(parts, " ")
+}
+
+// renderBody renders the editor of the current body mode.
+func (m RequestModel) renderBody() string {
+	label := "Body:"
+	switch m.bodyMode {
+	case bodyForm:
+		label = "Form fields:"
+	case bodyGraphQL:
+		label = "Query:"
+	case bodyFile:
+		label = "File:"
+	}
+
+	lines := []string{m.renderFieldLabel(fieldBody, label)}
+	switch m.bodyMode {
+	case bodyForm:
+		lines = append(lines, m.formEditor.View())
+	case bodyFile:
+		if m.bodyFilePath != "" {
+			lines = append(lines, fmt.Sprintf("%s (%d bytes)", m.bodyFilePath, len(m.bodyFile)))
+		}
+		if m.focusedField == fieldBody {
+			lines = append(lines, m.filePicker.View())
+		} else if m.bodyFilePath == "" {
+			lines = append(lines, "  (no file chosen)")
+		}
+		if m.bodyFileError != "" {
+			lines = append(lines, "✗ "+m.bodyFileError)
+		}
+	default:
+		lines = append(lines, m.bodyTextArea.View())
+	}
+
+	// Invalid JSON is flagged beside the label.
+	if m.bodyMode == bodyJSON && strings.TrimSpace(m.bodyTextArea.Value()) != "" && validateJSON(m.bodyTextArea.Value()) == nil {
+		lines = append(lines, "✓ valid JSON (formatted when you leave the body)")
+	}
+	return strings.Join(lines, "\n")
+}
+
+// variablesBeside reports whether the window is wide enough for the GraphQL
+// variables editor to sit beside the query editor.
+func (m RequestModel) variablesBeside() bool {
+	return m.width >= m.inputWidth()+len(variablesPaneGap)+variablesPaneWidth+20
+}
+
+// resizeBodyEditors fits the body editors to the window. Beside the query,
+// the variables editor is as tall as it.
+func (m *RequestModel) resizeBodyEditors() {
+	m.bodyTextArea.SetWidth(m.inputWidth())
+	if m.variablesBeside() {
+		m.variablesTextArea.SetWidth(variablesPaneWidth)
+		m.variablesTextArea.SetHeight(m.bodyTextArea.Height())
+	} else {
+		m.variablesTextArea.SetWidth(m.inputWidth())
+		m.variablesTextArea.SetHeight(variablesPaneHeight)
+	}
+}
+
+// renderGraphQLEditors renders the query and variables editors, side by side
+// when they fit.
+func (m RequestModel) renderGraphQLEditors() string {
+	if m.variablesBeside() {
+		return lipgloss.JoinHorizontal(lipgloss.Top, m.renderBody(), variablesPaneGap, m.renderVariables())
+	}
+	return m.renderBody() + "\n" + m.renderVariables()
+}
+
+// renderVariables renders the GraphQL variables editor, with the problems
+// found in the variables of the selected operation: checked against the
+// schema when the endpoint has been introspected, or against the
+// declarations alone otherwise.
+func (m RequestModel) renderVariables() string {
+	label := "Variables (JSON object):"
+	if m.focusedField == fieldVariables {
+		label += focusedIndicator
+	}
+	lines := []string{label, m.variablesTextArea.View()}
+	if _, err := graphql.BuildBody("", m.variablesTextArea.Value(), ""); err != nil {
+		lines = append(lines, "✗ "+err.Error())
+		return strings.Join(lines, "\n")
+	}
+
+	var schema *graphql.Schema
+	if m.graphqlService != nil {
+		schema, _ = m.graphqlService.Schema(m.urlInput.Value())
+	}
+	problems := m.selectedOperation().CheckVariables(schema, m.variablesTextArea.Value())
+	for _, problem := range problems[:min(len(problems), maxGraphQLErrors)] {
+		lines = append(lines, "✗ "+problem)
+	}
+	if n := len(problems); n > maxGraphQLErrors {
+		lines = append(lines, fmt.Sprintf("  +%d more", n-maxGraphQLErrors))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// namedOperations returns the named operations of a GraphQL query, which
+// can be chosen to run.
+func (m RequestModel) namedOperations() []graphql.Operation {
+	if m.bodyMode != bodyGraphQL {
+		return nil
+	}
+	var named []graphql.Operation
+	for _, op := range graphql.Operations(m.bodyTextArea.Value()) {
+		if op.Name != "" {
+			named = append(named, op)
+		}
+	}
+	return named
+}
+
+// selectedOperation returns the GraphQL operation the request runs. Its name
+// is empty unless the query has several named operations, when it is the
+// chosen one, or the first if none was chosen.
+func (m RequestModel) selectedOperation() graphql.Operation {
+	named := m.namedOperations()
+	if len(named) < 2 {
+		ops := graphql.Operations(m.bodyTextArea.Value())
+		if len(ops) == 0 {
+			return graphql.Operation{}
+		}
+		op := ops[0]
+		op.Name = ""
+		return op
+	}
+	for _, op := range named {
+		if op.Name == m.operationName {
+			return op
+		}
+	}
+	return named[0]
+}
+
+// handleOperationField handles keyboard input for the operation selector.
+func (m *RequestModel) handleOperationField(msg tea.KeyMsg) {
+	named := m.namedOperations()
+	current := 0
+	for i, op := range named {
+		if op.Name == m.selectedOperation().Name {
+			current = i
+		}
+	}
+	switch msg.String() {
+	case "left", "h":
+		current--
+	case "right", "l":
+		current++
+	default:
+		return
+	}
+	if current >= 0 && current < len(named) {
+		m.operationName = named[current].Name
+	}
+}
+
+// renderOperation renders the operation selector, shown when the GraphQL
+// query has several operations to choose from.
+func (m RequestModel) renderOperation() string {
+	named := m.namedOperations()
+	if len(named) < 2 {
+		return ""
+	}
+	selected := m.selectedOperation().Name
+	var parts []string
+	for _, op := range named {
+		if op.Name == selected {
+			parts = append(parts, "["+op.Name+"]")
+		} else {
+			parts = append(parts, op.Name)
+		}
+	}
+	focused := ""
+	if m.focusedField == fieldOperation {
+		focused = focusedIndicator
+	}
+	return "Operation: " + strings.Join(parts, " ") + focused
+}
+
+// validateJSON reports why body is not a single JSON value.
+func validateJSON(body string) error {
+	dec := json.NewDecoder(strings.NewReader(body))
+	var value any
+	if err := dec.Decode(&value); err != nil {
+		return err
+	}
+	if dec.More() {
+		return fmt.Errorf("unexpected content after the JSON value")
+	}
+	return nil
+}
+
+// encodeForm encodes enabled form rows in order as a URL-encoded body.
+func encodeForm(rows []components.KeyValueRow) string {
+	var parts []string
+	for _, row := range rows {
+		if row.Disabled || strings.TrimSpace(row.Key) == "" {
+			continue
+		}
+		parts = append(parts, url.QueryEscape(row.Key)+"="+url.QueryEscape(row.Value))
+	}
+	return strings.Join(parts, "&")
+}
+
+// decodeForm parses a URL-encoded body into rows, keeping their order.
+func decodeForm(body string) ([]components.KeyValueRow, error) {
+	var rows []components.KeyValueRow
+	for _, part := range strings.Split(body, "&") {
+		if part == "" {
+			continue
+		}
+		key, value, _ := strings.Cut(part, "=")
+		key, err := url.QueryUnescape(key)
+		if err != nil {
+			return nil, err
+		}
+		if value, err = url.QueryUnescape(value); err != nil {
+			return nil, err
+		}
+		rows = append(rows, components.KeyValueRow{Key: key, Value: value})
+	}
+	return rows, nil
+}
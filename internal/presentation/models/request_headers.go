@@ -0,0 +1,65 @@
+package models
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/williajm/curly/internal/domain"
+)
+
+// headerSuggestionsMsg carries the headers set on saved requests, with their
+// values, for completing header names and values.
+type headerSuggestionsMsg struct {
+	used map[string][]string
+	err  error
+}
+
+// loadHeaderSuggestions returns a command that loads the headers set on
+// saved requests.
+func (m RequestModel) loadHeaderSuggestions() tea.Cmd {
+	if m.requestService == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		used, err := m.requestService.UsedHeaders(context.Background())
+		return headerSuggestionsMsg{used: used, err: err}
+	}
+}
+
+// setHeaderSuggestions offers the common headers and those set on saved
+// requests as completions in the header editor. Values already used for a
+// header come before its common values. Failing to load the saved headers
+// only leaves them out.
+func (m *RequestModel) setHeaderSuggestions(msg headerSuggestionsMsg) {
+	used := msg.used
+	if msg.err != nil {
+		used = nil
+	}
+
+	names := slices.Clone(domain.CommonHeaders)
+	for name := range used {
+		if !slices.ContainsFunc(names, func(n string) bool { return strings.EqualFold(n, name) }) {
+			names = append(names, name)
+		}
+	}
+	slices.SortFunc(names, func(a, b string) int {
+		return strings.Compare(strings.ToLower(a), strings.ToLower(b))
+	})
+
+	m.headersEditor.SetSuggestions(names, func(key string) []string {
+		var values []string
+		for name, usedValues := range used {
+			if strings.EqualFold(name, key) {
+				values = append(values, usedValues...)
+			}
+		}
+		for _, value := range domain.CommonHeaderValues(key) {
+			if !slices.Contains(values, value) {
+				values = append(values, value)
+			}
+		}
+		return values
+	})
+}
@@ -3,14 +3,23 @@ package models
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"maps"
+	"reflect"
 	"strings"
 
+	"github.com/charmbracelet/bubbles/filepicker"
 	"github.com/charmbracelet/bubbles/textarea"
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/williajm/curly/internal/app"
 	"github.com/williajm/curly/internal/domain"
+	"github.com/williajm/curly/internal/infrastructure/graphql"
+	httpinfra "github.com/williajm/curly/internal/infrastructure/http"
+	"github.com/williajm/curly/internal/infrastructure/sse"
+	"github.com/williajm/curly/internal/presentation/components"
+	"github.com/williajm/curly/internal/presentation/styles"
 )
 
 // Field indices for focus management.
@@ -20,31 +29,67 @@ const (
 	fieldName
 	fieldHeaders
 	fieldQueryParams
+	fieldBodyType
 	fieldBody
+	fieldVariables
+	fieldOperation
 	fieldAuthType
 	fieldSend
 	fieldCount // Total number of fields
 )
 
+// authTypes lists the auth types in the order the selector shows them.
+var authTypes = []string{
+	domain.AuthTypeNone,
+	domain.AuthTypeBasic,
+	domain.AuthTypeBearer,
+	domain.AuthTypeAPIKey,
+}
+
 // UI indicator constants.
 const (
 	// focusedIndicator is displayed next to focused input fields.
 	focusedIndicator = " (*)"
 )
 
+// Limits on the GraphQL feedback shown under the body.
+const (
+	maxGraphQLErrors      = 3
+	maxGraphQLSuggestions = 5
+)
+
 // RequestModel represents the request builder form.
 type RequestModel struct {
 	// Services.
 	requestService *app.RequestService
 	authService    *app.AuthService
+	graphqlService *app.GraphQLService // nil disables GraphQL support
 
-	// Current request being built.
+	// Current request being built, and a copy of it as last loaded, to tell
+	// whether the form was changed since.
 	request *domain.Request
+	loaded  *domain.Request
 
 	// Form inputs.
-	urlInput     textinput.Model
-	nameInput    textinput.Model
-	bodyTextArea textarea.Model
+	urlInput      textinput.Model
+	knownURLs     []string // URLs of history and saved requests; see request_url.go
+	urlMatches    []string // knownURLs matching the URL typed
+	urlMatch      int      // index of the match chosen, or -1
+	nameInput     textinput.Model
+	headersEditor components.KeyValueEditor
+	queryEditor   components.KeyValueEditor
+	bodyTextArea  textarea.Model
+
+	// Body editors. The text area holds raw, JSON and GraphQL query bodies;
+	// see request_body.go for the other modes.
+	bodyMode          int
+	variablesTextArea textarea.Model
+	operationName     string // GraphQL operation to run, when the query has several
+	formEditor        components.KeyValueEditor
+	filePicker        filepicker.Model
+	bodyFilePath      string
+	bodyFile          string
+	bodyFileError     string
 
 	// State.
 	methodIndex  int // Index into supported methods
@@ -52,15 +97,28 @@ type RequestModel struct {
 	loading      bool
 	errorMsg     string
 
+	// cancelSend cancels the request being sent.
+	cancelSend context.CancelFunc
+
+	// The progress of the request being sent, reported on progress until
+	// progressDone is closed; see request_progress.go.
+	progress     <-chan httpinfra.Progress
+	progressDone <-chan struct{}
+	transfer     transferState
+
+	// GraphQL introspection state.
+	introspecting bool
+	graphqlError  string
+
+	// environment is the active environment, whose variables the URL preview
+	// resolves (nil for none).
+	environment *domain.Environment
+
 	// UI dimensions.
 	width  int
 	height int
 
-	// Simple key-value editors for headers and query params.
-	// For MVP, we'll use simple string editing.
-	headersText     string
-	queryParamsText string
-	authTypeIndex   int // Index into auth types
+	authTypeIndex int // Index into auth types
 }
 
 // Custom messages for async operations.
@@ -69,8 +127,14 @@ type requestSentMsg struct {
 	err      error
 }
 
+type graphqlSchemaMsg struct {
+	schema *graphql.Schema
+	err    error
+}
+
 // NewRequestModel creates a new request builder model.
-func NewRequestModel(requestService *app.RequestService, authService *app.AuthService) RequestModel {
+// graphqlService may be nil, which disables GraphQL support in the body editor.
+func NewRequestModel(requestService *app.RequestService, authService *app.AuthService, graphqlService *app.GraphQLService) RequestModel {
 	// Initialize text inputs.
 	urlInput := textinput.New()
 	urlInput.Placeholder = "https://api.example.com/endpoint"
@@ -81,6 +145,18 @@ func NewRequestModel(requestService *app.RequestService, authService *app.AuthSe
 	nameInput.Placeholder = "My Request"
 	nameInput.Width = 60
 
+	headersEditor := components.NewKeyValueEditor(components.KeyValueConfig{
+		Noun: "header",
+		Validate: func(name, _ string) error {
+			return domain.ValidateHeaderName(name)
+		},
+		FoldKeys: true,
+	})
+	queryEditor := components.NewKeyValueEditor(components.KeyValueConfig{
+		Noun:    "query parameter",
+		Toggles: true,
+	})
+
 	// Initialize text area for body.
 	bodyTextArea := textarea.New()
 	bodyTextArea.Placeholder = "Request body (JSON, etc.)"
@@ -89,24 +165,46 @@ func NewRequestModel(requestService *app.RequestService, authService *app.AuthSe
 	// Disable ctrl+enter in textarea so we can handle it globally.
 	bodyTextArea.KeyMap.InsertNewline.SetEnabled(false)
 
-	return RequestModel{
-		requestService:  requestService,
-		authService:     authService,
-		request:         domain.NewRequest(),
-		urlInput:        urlInput,
-		nameInput:       nameInput,
-		bodyTextArea:    bodyTextArea,
-		methodIndex:     0, // GET by default
-		focusedField:    fieldURL,
-		headersText:     "",
-		queryParamsText: "",
-		authTypeIndex:   0, // NoAuth by default
+	variablesTextArea := textarea.New()
+	variablesTextArea.Placeholder = `{"id": 1}`
+	variablesTextArea.SetWidth(60)
+	variablesTextArea.SetHeight(variablesPaneHeight)
+	variablesTextArea.KeyMap.InsertNewline.SetEnabled(false)
+
+	formEditor := components.NewKeyValueEditor(components.KeyValueConfig{
+		Noun:            "form field",
+		Toggles:         true,
+		AllowDuplicates: true,
+	})
+
+	m := RequestModel{
+		requestService: requestService,
+		authService:    authService,
+		graphqlService: graphqlService,
+		request:        domain.NewRequest(),
+		urlInput:       urlInput,
+		nameInput:      nameInput,
+		headersEditor:  headersEditor,
+		queryEditor:    queryEditor,
+		bodyTextArea:   bodyTextArea,
+
+		variablesTextArea: variablesTextArea,
+		formEditor:        formEditor,
+		filePicker:        newBodyFilePicker(),
+
+		urlMatch:      -1,
+		methodIndex:   0, // GET by default
+		focusedField:  fieldURL,
+		authTypeIndex: 0, // NoAuth by default
 	}
+	m.loaded = m.buildRequest().Clone()
+	m.setHeaderSuggestions(headerSuggestionsMsg{})
+	return m
 }
 
 // Init initializes the model.
 func (m RequestModel) Init() tea.Cmd {
-	return textinput.Blink
+	return tea.Batch(textinput.Blink, m.loadHeaderSuggestions(), m.loadURLSuggestions())
 }
 
 // Update handles messages and updates the model.
@@ -115,6 +213,14 @@ func (m RequestModel) Update(msg tea.Msg) (RequestModel, tea.Cmd) {
 
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
+		// A cell being edited takes every key, so Tab and Esc finish the edit.
+		if m.Editing() {
+			return m, m.handleFieldKey(msg)
+		}
+		if m.focusedField == fieldURL && m.handleURLSuggestionKey(msg) {
+			return m, nil
+		}
+
 		// Try to handle global keys first.
 		if handled, cmd := m.handleGlobalKey(msg); handled {
 			return m, cmd
@@ -125,12 +231,27 @@ func (m RequestModel) Update(msg tea.Msg) (RequestModel, tea.Cmd) {
 		cmds = append(cmds, cmd)
 
 	case requestSentMsg:
-		m.loading = false
+		m.requestSent(msg)
+		// The URL sent is now in history.
+		return m, m.loadURLSuggestions()
+
+	case headerSuggestionsMsg:
+		m.setHeaderSuggestions(msg)
+		return m, nil
+
+	case urlSuggestionsMsg:
+		m.setURLSuggestions(msg)
+		return m, nil
+
+	case transferProgressMsg:
+		return m, m.updateProgress(msg)
+
+	case graphqlSchemaMsg:
+		m.introspecting = false
 		if msg.err != nil {
-			m.errorMsg = msg.err.Error()
+			m.graphqlError = msg.err.Error()
 		} else {
-			m.errorMsg = ""
-			// Response will be handled by the parent model.
+			m.graphqlError = ""
 		}
 		return m, nil
 
@@ -138,14 +259,41 @@ func (m RequestModel) Update(msg tea.Msg) (RequestModel, tea.Cmd) {
 		m.width = msg.Width
 		m.height = msg.Height
 		// Adjust input widths.
-		m.urlInput.Width = min(60, msg.Width-20)
-		m.nameInput.Width = min(60, msg.Width-20)
-		m.bodyTextArea.SetWidth(min(60, msg.Width-20))
+		m.urlInput.Width = m.inputWidth()
+		m.nameInput.Width = m.inputWidth()
+		m.resizeBodyEditors()
+
+	default:
+		// Directory listings arrive asynchronously.
+		if m.bodyMode == bodyFile {
+			var cmd tea.Cmd
+			m.filePicker, cmd = m.filePicker.Update(msg)
+			cmds = append(cmds, cmd)
+		}
 	}
 
 	return m, tea.Batch(cmds...)
 }
 
+// requestSent ends the request in progress and shows its error, if any.
+// The response is handled by the parent model.
+func (m *RequestModel) requestSent(msg requestSentMsg) {
+	m.loading = false
+	if m.cancelSend != nil {
+		// Releases the context of a stream that has ended.
+		m.cancelSend()
+	}
+	m.cancelSend = nil
+	switch {
+	case errors.Is(msg.err, context.Canceled):
+		m.errorMsg = "Request canceled"
+	case msg.err != nil:
+		m.errorMsg = msg.err.Error()
+	default:
+		m.errorMsg = ""
+	}
+}
+
 // handleGlobalKey handles global keyboard shortcuts.
 // Returns true if the key was handled (and further processing should stop).
 func (m *RequestModel) handleGlobalKey(msg tea.KeyMsg) (bool, tea.Cmd) {
@@ -160,16 +308,21 @@ func (m *RequestModel) handleGlobalKey(msg tea.KeyMsg) (bool, tea.Cmd) {
 		}
 		return true, nil
 
+	case KeyCtrlT:
+		// Introspect the GraphQL schema at the request URL.
+		if m.graphqlService != nil && !m.introspecting {
+			return true, m.introspectSchema()
+		}
+		return true, nil
+
 	case "tab":
 		// Move focus to next field.
-		m.focusedField = (m.focusedField + 1) % fieldCount
-		m.updateFocus()
+		m.moveFocus(1)
 		return true, nil
 
 	case "shift+tab":
 		// Move focus to previous field.
-		m.focusedField = (m.focusedField - 1 + fieldCount) % fieldCount
-		m.updateFocus()
+		m.moveFocus(-1)
 		return true, nil
 	}
 
@@ -185,8 +338,25 @@ func (m *RequestModel) handleFieldKey(msg tea.KeyMsg) tea.Cmd {
 		return m.handleURLField(msg)
 	case fieldName:
 		return m.handleNameField(msg)
+	case fieldHeaders:
+		var cmd tea.Cmd
+		m.headersEditor, cmd = m.headersEditor.Update(msg)
+		return cmd
+	case fieldQueryParams:
+		var cmd tea.Cmd
+		m.queryEditor, cmd = m.queryEditor.Update(msg)
+		return cmd
+	case fieldBodyType:
+		return m.handleBodyTypeField(msg)
 	case fieldBody:
 		return m.handleBodyField(msg)
+	case fieldVariables:
+		var cmd tea.Cmd
+		m.variablesTextArea, cmd = m.variablesTextArea.Update(msg)
+		return cmd
+	case fieldOperation:
+		m.handleOperationField(msg)
+		return nil
 	case fieldAuthType:
 		return m.handleAuthTypeField(msg)
 	case fieldSend:
@@ -212,8 +382,12 @@ func (m *RequestModel) handleMethodField(msg tea.KeyMsg) tea.Cmd {
 
 // handleURLField handles keyboard input for the URL field.
 func (m *RequestModel) handleURLField(msg tea.KeyMsg) tea.Cmd {
+	typed := m.urlInput.Value()
 	var cmd tea.Cmd
 	m.urlInput, cmd = m.urlInput.Update(msg)
+	if m.urlInput.Value() != typed {
+		m.matchURLs()
+	}
 	return cmd
 }
 
@@ -231,6 +405,27 @@ func (m *RequestModel) handleBodyField(msg tea.KeyMsg) tea.Cmd {
 		return nil
 	}
 
+	switch m.bodyMode {
+	case bodyForm:
+		var cmd tea.Cmd
+		m.formEditor, cmd = m.formEditor.Update(msg)
+		return cmd
+	case bodyFile:
+		return m.handleFilePicker(msg)
+	}
+
+	switch msg.String() {
+	case KeyCtrlSpace:
+		m.completeBody()
+		return nil
+	case KeyAltN:
+		// Toggle line numbers. The width is set again so that the editor
+		// stays as wide with or without them.
+		m.bodyTextArea.ShowLineNumbers = !m.bodyTextArea.ShowLineNumbers
+		m.bodyTextArea.SetWidth(m.inputWidth())
+		return nil
+	}
+
 	var cmd tea.Cmd
 	m.bodyTextArea, cmd = m.bodyTextArea.Update(msg)
 	return cmd
@@ -244,7 +439,7 @@ func (m *RequestModel) handleAuthTypeField(msg tea.KeyMsg) tea.Cmd {
 			m.authTypeIndex--
 		}
 	case "right", "l":
-		if m.authTypeIndex < 3 { // 4 auth types: NoAuth, Basic, Bearer, APIKey
+		if m.authTypeIndex < len(authTypes)-1 {
 			m.authTypeIndex++
 		}
 	}
@@ -279,15 +474,37 @@ func (m RequestModel) RenderView() string {
 	sections = append(sections, "")
 	sections = append(sections, m.renderName())
 	sections = append(sections, "")
-	sections = append(sections, m.renderBody())
+	sections = append(sections, m.renderHeaders())
+	sections = append(sections, "")
+	sections = append(sections, m.renderQueryParams())
+	sections = append(sections, "")
+	sections = append(sections, m.renderBodyType())
+	if m.bodyMode == bodyGraphQL {
+		sections = append(sections, m.renderGraphQLEditors())
+	} else {
+		sections = append(sections, m.renderBody())
+	}
+	if graphQL := m.renderGraphQL(); graphQL != "" {
+		sections = append(sections, graphQL)
+	}
+	if operation := m.renderOperation(); operation != "" {
+		sections = append(sections, operation)
+	}
 	sections = append(sections, "")
 	sections = append(sections, m.renderAuth())
+	if m.request.ResponseSchema != "" {
+		// Schemas are attached with `curly schema`; responses are checked against them.
+		sections = append(sections, "Response schema: attached")
+	}
+	if scripts := m.renderScripts(); scripts != "" {
+		sections = append(sections, scripts)
+	}
 	sections = append(sections, "")
 	sections = append(sections, m.renderSendButton())
 
 	if m.loading {
 		sections = append(sections, "")
-		sections = append(sections, "⠋ Sending request...")
+		sections = append(sections, m.renderSending())
 	}
 
 	if m.errorMsg != "" {
@@ -320,12 +537,11 @@ func (m RequestModel) renderMethod() string {
 }
 
 func (m RequestModel) renderURL() string {
-	label := "URL:"
-	focused := ""
-	if m.focusedField == fieldURL {
-		focused = focusedIndicator
+	view := m.renderFieldLabel(fieldURL, "URL:") + "\n" + m.urlInput.View()
+	if suggestions := m.renderURLSuggestions(); suggestions != "" {
+		view += "\n" + suggestions
 	}
-	return label + focused + "\n" + m.urlInput.View()
+	return view
 }
 
 func (m RequestModel) renderName() string {
@@ -337,13 +553,79 @@ func (m RequestModel) renderName() string {
 	return label + focused + "\n" + m.nameInput.View()
 }
 
-func (m RequestModel) renderBody() string {
-	label := "Body:"
-	focused := ""
-	if m.focusedField == fieldBody {
-		focused = focusedIndicator
+func (m RequestModel) renderHeaders() string {
+	return m.renderFieldLabel(fieldHeaders, "Headers:") + "\n" + m.headersEditor.View()
+}
+
+// renderQueryParams shows the query parameter editor and, once a URL has been
+// entered, the URL the request will be sent to.
+func (m RequestModel) renderQueryParams() string {
+	lines := []string{m.renderFieldLabel(fieldQueryParams, "Query parameters:"), m.queryEditor.View()}
+
+	if m.urlInput.Value() != "" {
+		preview := &domain.Request{URL: m.urlInput.Value(), QueryParams: m.queryEditor.Map()}
+		preview, undefined := preview.ResolveVariables(m.previewVariables())
+		if full, err := preview.FullURL(); err == nil {
+			line := "→ " + full
+			if len(undefined) > 0 {
+				line += "  (undefined: " + strings.Join(undefined, ", ") + ")"
+			}
+			lines = append(lines, line)
+		}
 	}
-	return label + focused + "\n" + m.bodyTextArea.View()
+	return strings.Join(lines, "\n")
+}
+
+// renderGraphQL shows, for a GraphQL body whose endpoint has been introspected,
+// whether the query is valid and what can be completed at the cursor.
+func (m RequestModel) renderGraphQL() string {
+	if m.graphqlService == nil {
+		return ""
+	}
+	if m.introspecting {
+		return "⠋ Introspecting GraphQL schema..."
+	}
+	if m.graphqlError != "" {
+		return "GraphQL: " + m.graphqlError
+	}
+	if m.bodyMode == bodyForm || m.bodyMode == bodyFile {
+		return ""
+	}
+
+	query, raw, ok := graphql.QueryFromBody(m.bodyTextArea.Value())
+	if !ok {
+		return ""
+	}
+	schema, ok := m.graphqlService.Schema(m.urlInput.Value())
+	if !ok {
+		return "GraphQL: press Ctrl+T to introspect the schema"
+	}
+
+	var lines []string
+	if errs := schema.Validate(query); len(errs) == 0 {
+		lines = append(lines, "GraphQL: ✓ valid")
+	} else {
+		lines = append(lines, fmt.Sprintf("GraphQL: %d errors", len(errs)))
+		for _, e := range errs[:min(len(errs), maxGraphQLErrors)] {
+			lines = append(lines, "  "+e.String())
+		}
+	}
+
+	// Offsets only line up with the editor when the body is the query itself.
+	if raw && m.focusedField == fieldBody {
+		completion := schema.Complete(query, m.bodyCursorOffset())
+		if n := len(completion.Suggestions); n > 0 {
+			var parts []string
+			for _, sg := range completion.Suggestions[:min(n, maxGraphQLSuggestions)] {
+				parts = append(parts, sg.Label+" "+sg.Detail)
+			}
+			if n > maxGraphQLSuggestions {
+				parts = append(parts, fmt.Sprintf("+%d more", n-maxGraphQLSuggestions))
+			}
+			lines = append(lines, "Complete (Ctrl+Space): "+strings.Join(parts, " • "))
+		}
+	}
+	return strings.Join(lines, "\n")
 }
 
 func (m RequestModel) renderAuth() string {
@@ -357,7 +639,31 @@ func (m RequestModel) renderAuth() string {
 			parts = append(parts, authType)
 		}
 	}
-	return label + strings.Join(parts, " ")
+	view := label + strings.Join(parts, " ")
+	switch err := m.authErr(); {
+	case errors.Is(err, errNoCredentials):
+		// Not an error: the request can still be sent.
+		view += "  " + styles.WarningStyle.Render("⚠ "+err.Error())
+	case err != nil:
+		view += "  " + styles.ErrorStyle.Render("✗ "+err.Error())
+	}
+	return view
+}
+
+// renderScripts notes the scripts the request runs, which are attached with
+// `curly script`, or returns "" if it has none.
+func (m RequestModel) renderScripts() string {
+	var scripts []string
+	if m.request.PreRequestScript != "" {
+		scripts = append(scripts, "pre-request")
+	}
+	if m.request.PostResponseScript != "" {
+		scripts = append(scripts, "post-response")
+	}
+	if len(scripts) == 0 {
+		return ""
+	}
+	return "Scripts: " + strings.Join(scripts, ", ")
 }
 
 func (m RequestModel) renderSendButton() string {
@@ -372,15 +678,42 @@ func (m RequestModel) renderSendButton() string {
 }
 
 func (m RequestModel) renderHelp() string {
+	if m.graphqlService != nil {
+		return "Tab: next • Shift+Tab: prev • Ctrl+Enter: send • Ctrl+T: GraphQL schema • ?: help • q: quit"
+	}
 	return "Tab: next • Shift+Tab: prev • Ctrl+Enter: send • ?: help • q: quit"
 }
 
+// moveFocus moves focus by delta fields, skipping the GraphQL variables
+// unless the body is GraphQL, and the operation selector unless its query
+// has several operations. Leaving a JSON body formats it.
+func (m *RequestModel) moveFocus(delta int) {
+	if m.focusedField == fieldBody && m.bodyMode == bodyJSON {
+		m.formatJSONBody()
+	}
+	for {
+		m.focusedField = (m.focusedField + delta + fieldCount) % fieldCount
+		switch {
+		case m.focusedField == fieldVariables && m.bodyMode != bodyGraphQL:
+			continue
+		case m.focusedField == fieldOperation && len(m.namedOperations()) < 2:
+			continue
+		}
+		break
+	}
+	m.updateFocus()
+}
+
 // updateFocus updates which input field has focus.
 func (m *RequestModel) updateFocus() {
 	// Blur all inputs.
 	m.urlInput.Blur()
 	m.nameInput.Blur()
+	m.headersEditor.Blur()
+	m.queryEditor.Blur()
 	m.bodyTextArea.Blur()
+	m.variablesTextArea.Blur()
+	m.formEditor.Blur()
 
 	// Focus the active field.
 	switch m.focusedField {
@@ -388,13 +721,43 @@ func (m *RequestModel) updateFocus() {
 		m.urlInput.Focus()
 	case fieldName:
 		m.nameInput.Focus()
+	case fieldHeaders:
+		m.headersEditor.Focus()
+	case fieldQueryParams:
+		m.queryEditor.Focus()
 	case fieldBody:
-		m.bodyTextArea.Focus()
+		switch m.bodyMode {
+		case bodyForm:
+			m.formEditor.Focus()
+		case bodyFile:
+			// The file picker takes input whenever the body is focused.
+		default:
+			m.bodyTextArea.Focus()
+		}
+	case fieldVariables:
+		m.variablesTextArea.Focus()
 	}
 }
 
 // sendRequest creates a command to send the HTTP request.
 func (m *RequestModel) sendRequest() tea.Cmd {
+	// Invalid rows are flagged in the editors rather than dropped.
+	for _, editor := range []components.KeyValueEditor{m.headersEditor, m.queryEditor} {
+		if err := editor.Err(); err != nil {
+			return func() tea.Msg {
+				return requestSentMsg{err: err}
+			}
+		}
+	}
+	if err := m.bodyErr(); err != nil {
+		return func() tea.Msg {
+			return requestSentMsg{err: err}
+		}
+	}
+	if m.bodyMode == bodyJSON {
+		m.formatJSONBody()
+	}
+
 	// Build request from form inputs.
 	req := m.buildRequest()
 
@@ -406,12 +769,81 @@ func (m *RequestModel) sendRequest() tea.Cmd {
 	}
 
 	m.loading = true
+	ctx, cancel := context.WithCancel(context.Background())
+	m.cancelSend = cancel
 
-	return func() tea.Msg {
-		ctx := context.Background()
+	if sse.Accepted(req.Headers) {
+		// The stream stays open after the command returns, until it ends or
+		// the send is canceled.
+		return func() tea.Msg {
+			stream, resp, err := m.requestService.OpenStream(ctx, req)
+			if stream == nil {
+				cancel()
+				return requestSentMsg{response: resp, err: err}
+			}
+			return streamOpenedMsg{stream: stream}
+		}
+	}
+
+	ctx, waitProgress := m.trackProgress(ctx)
+	return tea.Batch(func() tea.Msg {
+		defer cancel()
 		resp, err := m.requestService.ExecuteAndSave(ctx, req)
 		return requestSentMsg{response: resp, err: err}
+	}, waitProgress)
+}
+
+// introspectSchema creates a command to fetch the GraphQL schema of the
+// request URL, with the request's headers and auth. An existing schema is
+// always refreshed.
+func (m *RequestModel) introspectSchema() tea.Cmd {
+	req := m.buildRequest().Clone()
+	if req.URL == "" {
+		m.graphqlError = "enter the endpoint URL to introspect"
+		return nil
 	}
+
+	m.introspecting = true
+	m.graphqlError = ""
+
+	return func() tea.Msg {
+		schema, err := m.graphqlService.Introspect(context.Background(), req, true)
+		return graphqlSchemaMsg{schema: schema, err: err}
+	}
+}
+
+// completeBody inserts the rest of the first completion for the word at the
+// cursor. It does nothing unless the body is a raw GraphQL query whose
+// endpoint has been introspected.
+func (m *RequestModel) completeBody() {
+	if m.graphqlService == nil {
+		return
+	}
+	query, raw, ok := graphql.QueryFromBody(m.bodyTextArea.Value())
+	if !ok || !raw {
+		return
+	}
+	completion, err := m.graphqlService.Complete(m.urlInput.Value(), query, m.bodyCursorOffset())
+	if err != nil || len(completion.Suggestions) == 0 {
+		return
+	}
+	label := completion.Suggestions[0].Label
+	m.bodyTextArea.InsertString(label[len(completion.Prefix):])
+}
+
+// bodyCursorOffset returns the byte offset of the cursor in the body.
+func (m RequestModel) bodyCursorOffset() int {
+	lines := strings.Split(m.bodyTextArea.Value(), "\n")
+	row := min(m.bodyTextArea.Line(), len(lines)-1)
+
+	offset := 0
+	for _, line := range lines[:row] {
+		offset += len(line) + 1
+	}
+	info := m.bodyTextArea.LineInfo()
+	runes := []rune(lines[row])
+	col := min(info.StartColumn+info.ColumnOffset, len(runes))
+	return offset + len(string(runes[:col]))
 }
 
 // buildRequest constructs a domain.Request from the form inputs.
@@ -430,14 +862,21 @@ func (m *RequestModel) buildRequest() *domain.Request {
 		req.Name = fmt.Sprintf("%s %s", req.Method, req.URL)
 	}
 
-	// Set body.
-	req.Body = m.bodyTextArea.Value()
+	// Set body from the current body mode.
+	req.Body = m.bodyValue()
+
+	// Set headers. Blank rows are skipped.
+	req.Headers = m.headersEditor.Map()
 
-	// Parse headers from text (simple format: "Key: Value" per line).
-	// For MVP, we'll skip complex parsing.
+	// Set query params. Blank rows are skipped, and disabled rows are kept
+	// apart so that they are saved but not sent.
+	req.QueryParams = m.queryEditor.Map()
+	req.DisabledQueryParams = m.queryEditor.DisabledMap()
 
-	// Parse query params from text (simple format: "key=value" per line).
-	// For MVP, we'll skip complex parsing.
+	// Keep a loaded auth config while its type is still selected.
+	if req.AuthConfig != nil && req.AuthConfig.Type() == authTypes[m.authTypeIndex] {
+		return req
+	}
 
 	// Set auth based on authTypeIndex.
 	switch m.authTypeIndex {
@@ -457,6 +896,142 @@ func (m *RequestModel) buildRequest() *domain.Request {
 	return req
 }
 
+// LoadRequest replaces the form contents with req, for example one imported
+// from a curl command.
+func (m *RequestModel) LoadRequest(req *domain.Request) {
+	m.request = req
+	m.errorMsg = ""
+	m.graphqlError = ""
+
+	m.methodIndex = 0
+	for i, method := range domain.SupportedMethods {
+		if method == req.Method {
+			m.methodIndex = i
+		}
+	}
+
+	m.authTypeIndex = 0
+	if req.AuthConfig != nil {
+		for i, authType := range authTypes {
+			if authType == req.AuthConfig.Type() {
+				m.authTypeIndex = i
+			}
+		}
+	}
+
+	m.urlInput.SetValue(req.URL)
+	m.urlMatches, m.urlMatch = nil, -1
+	m.nameInput.SetValue(req.Name)
+	m.headersEditor.SetMap(req.Headers)
+	m.queryEditor.SetMaps(req.QueryParams, req.DisabledQueryParams)
+	m.loadBody(req)
+	m.loaded = m.buildRequest().Clone()
+}
+
+// RestoreDraft loads req, a draft an earlier session left, into the form.
+// The form is modified compared with saved, the saved request the draft
+// changes, or with an empty form if saved is nil.
+func (m *RequestModel) RestoreDraft(req, saved *domain.Request) {
+	if saved == nil {
+		saved = domain.NewRequest()
+	}
+	m.LoadRequest(saved.Clone())
+	base := m.loaded
+	m.LoadRequest(req)
+	m.loaded = base
+}
+
+// SetAuth replaces the request's auth config with auth and selects its type,
+// for example a token from signing in with OAuth.
+func (m *RequestModel) SetAuth(auth domain.AuthConfig) {
+	m.request.AuthConfig = auth
+	for i, authType := range authTypes {
+		if authType == auth.Type() {
+			m.authTypeIndex = i
+		}
+	}
+}
+
+// Saved takes req, just saved from the form, as the request the form holds,
+// so the form is no longer modified.
+func (m *RequestModel) Saved(req *domain.Request) {
+	m.request = req
+	m.nameInput.SetValue(req.Name)
+	m.loaded = m.buildRequest().Clone()
+}
+
+// Renamed takes the new name of a saved request renamed elsewhere, if it is
+// the request in the form. A name already edited in the form is kept, as are
+// other unsaved changes.
+func (m *RequestModel) Renamed(req *domain.Request) {
+	if m.request == nil || m.request.ID != req.ID {
+		return
+	}
+	m.request.Name = req.Name
+	if m.loaded == nil || m.nameInput.Value() == m.loaded.Name {
+		m.nameInput.SetValue(req.Name)
+	}
+	if m.loaded != nil {
+		m.loaded.Name = req.Name
+	}
+}
+
+// Modified reports whether the form was changed since the request was
+// loaded, or since the form was created for a new request.
+func (m RequestModel) Modified() bool {
+	if m.loaded == nil {
+		return false
+	}
+	req := m.buildRequest()
+	return req.Name != m.loaded.Name ||
+		req.Method != m.loaded.Method ||
+		req.URL != m.loaded.URL ||
+		req.Body != m.loaded.Body ||
+		!maps.Equal(req.Headers, m.loaded.Headers) ||
+		!maps.Equal(req.QueryParams, m.loaded.QueryParams) ||
+		!maps.Equal(req.DisabledQueryParams, m.loaded.DisabledQueryParams) ||
+		!reflect.DeepEqual(req.AuthConfig, m.loaded.AuthConfig)
+}
+
+// inputWidth returns the width of the text inputs and editors: at most 60
+// columns, leaving room for the labels.
+func (m RequestModel) inputWidth() int {
+	if m.width == 0 {
+		return 60
+	}
+	return min(60, m.width-20)
+}
+
+// SetEnvironment sets the active environment, whose variables the URL
+// preview resolves (nil for none).
+func (m *RequestModel) SetEnvironment(env *domain.Environment) {
+	m.environment = env
+}
+
+// previewVariables returns the variables the URL preview resolves, with the
+// values of secrets masked.
+func (m RequestModel) previewVariables() map[string]string {
+	if m.environment == nil {
+		return nil
+	}
+	return m.environment.MaskedVariables()
+}
+
+// Editing reports whether a header or query parameter cell is being edited,
+// in which case every key, including q, Tab and the digit keys, belongs to the
+// request builder.
+func (m RequestModel) Editing() bool {
+	return m.headersEditor.Editing() || m.queryEditor.Editing() || m.formEditor.Editing()
+}
+
+// Draft returns a copy of the request being built to keep as a draft, with
+// the name left empty unless one was typed.
+func (m *RequestModel) Draft() *domain.Request {
+	req := m.buildRequest().Clone()
+	req.Name = m.nameInput.Value()
+	return req
+}
+
 // GetRequest returns the current request being built.
 func (m *RequestModel) GetRequest() *domain.Request {
 	return m.buildRequest()
@@ -471,3 +1046,13 @@ func (m *RequestModel) SetError(err string) {
 func (m *RequestModel) IsLoading() bool {
 	return m.loading
 }
+
+// CancelSend cancels the request being sent, which then completes with a
+// context.Canceled error. It reports whether a request was being sent.
+func (m *RequestModel) CancelSend() bool {
+	if !m.loading || m.cancelSend == nil {
+		return false
+	}
+	m.cancelSend()
+	return true
+}
@@ -0,0 +1,127 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	httpinfra "github.com/williajm/curly/internal/infrastructure/http"
+)
+
+// largeTransfer is the body size from which a progress bar is shown in place
+// of the sending spinner.
+const largeTransfer = 64 * 1024
+
+// progressBarWidth is the width of the progress bar, in cells.
+const progressBarWidth = 30
+
+// transferProgressMsg reports the progress of the request being sent. ch is
+// the channel it came from, to tell reports of an earlier send apart.
+type transferProgressMsg struct {
+	progress httpinfra.Progress
+	at       time.Time
+	ch       <-chan httpinfra.Progress
+}
+
+// transferState is what the progress bar shows: the latest report, and when
+// the body it is about started transferring, for the rate.
+type transferState struct {
+	progress   httpinfra.Progress
+	reported   bool
+	phaseStart time.Time
+	at         time.Time
+}
+
+// trackProgress returns a context reporting the transfer of the request sent
+// with it, and a command waiting for the first report. Reports are dropped
+// rather than queued while the view catches up, so only the latest is shown.
+func (m *RequestModel) trackProgress(ctx context.Context) (context.Context, tea.Cmd) {
+	ch := make(chan httpinfra.Progress, 1)
+	m.progress = ch
+	m.progressDone = ctx.Done()
+	m.transfer = transferState{phaseStart: time.Now()}
+
+	report := func(p httpinfra.Progress) {
+		select {
+		case <-ch:
+		default:
+		}
+		select {
+		case ch <- p:
+		default:
+		}
+	}
+	return httpinfra.WithProgress(ctx, report), waitForProgress(ch, ctx.Done())
+}
+
+// waitForProgress returns a command that waits for the next progress report
+// on ch, or for the send to end, when it returns no message.
+func waitForProgress(ch <-chan httpinfra.Progress, done <-chan struct{}) tea.Cmd {
+	return func() tea.Msg {
+		select {
+		case p := <-ch:
+			return transferProgressMsg{progress: p, at: time.Now(), ch: ch}
+		case <-done:
+			return nil
+		}
+	}
+}
+
+// updateProgress records a progress report and waits for the next.
+func (m *RequestModel) updateProgress(msg transferProgressMsg) tea.Cmd {
+	if !m.loading || msg.ch != m.progress {
+		return nil
+	}
+	if m.transfer.reported && msg.progress.Upload != m.transfer.progress.Upload {
+		// The response body starts once the request body is sent.
+		m.transfer.phaseStart = m.transfer.at
+	}
+	m.transfer.progress = msg.progress
+	m.transfer.reported = true
+	m.transfer.at = msg.at
+	return waitForProgress(m.progress, m.progressDone)
+}
+
+// renderSending renders the sending indicator: a progress bar with the
+// transfer rate and time left for large bodies, a spinner otherwise.
+func (m RequestModel) renderSending() string {
+	t := m.transfer
+	p := t.progress
+	if !t.reported || max(p.Total, p.Done) < largeTransfer {
+		return "⠋ Sending request... (Esc to cancel)"
+	}
+
+	direction := "↓ Receiving"
+	if p.Upload {
+		direction = "↑ Sending"
+	}
+	var rate float64
+	if elapsed := t.at.Sub(t.phaseStart).Seconds(); elapsed > 0 {
+		rate = float64(p.Done) / elapsed
+	}
+
+	var parts []string
+	if p.Total > 0 {
+		fraction := min(float64(p.Done)/float64(p.Total), 1)
+		parts = append(parts, fmt.Sprintf("%s %s %3.0f%%", direction, progressBar(fraction, progressBarWidth), fraction*100))
+		parts = append(parts, formatSize(p.Done)+" of "+formatSize(p.Total))
+	} else {
+		parts = append(parts, direction+" "+formatSize(p.Done))
+	}
+	if rate > 0 {
+		parts = append(parts, formatSize(int64(rate))+"/s")
+		if p.Total > p.Done {
+			eta := time.Duration(float64(p.Total-p.Done) / rate * float64(time.Second))
+			parts = append(parts, "ETA "+eta.Round(time.Second).String())
+		}
+	}
+	return strings.Join(parts, " • ") + " (Esc to cancel)"
+}
+
+// progressBar renders fraction, between 0 and 1, as a bar width cells wide.
+func progressBar(fraction float64, width int) string {
+	filled := int(fraction * float64(width))
+	return "[" + strings.Repeat("█", filled) + strings.Repeat("░", width-filled) + "]"
+}
@@ -0,0 +1,131 @@
+package models
+
+import (
+	"context"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// maxURLSuggestions is how many URL completions are listed under the URL.
+const maxURLSuggestions = 5
+
+// urlSuggestionsMsg carries the URLs of history and saved requests, for
+// completing the URL as it is typed.
+type urlSuggestionsMsg struct {
+	urls []string
+	err  error
+}
+
+// loadURLSuggestions returns a command that loads the URLs of history and
+// saved requests.
+func (m RequestModel) loadURLSuggestions() tea.Cmd {
+	if m.requestService == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		urls, err := m.requestService.KnownURLs(context.Background())
+		return urlSuggestionsMsg{urls: urls, err: err}
+	}
+}
+
+// setURLSuggestions takes the loaded URLs. Failing to load them only leaves
+// the URL without completions.
+func (m *RequestModel) setURLSuggestions(msg urlSuggestionsMsg) {
+	if msg.err == nil {
+		m.knownURLs = msg.urls
+	}
+	m.matchURLs()
+}
+
+// matchURLs lists the known URLs containing the URL typed, ignoring case,
+// those starting with it first, and clears the completion chosen.
+func (m *RequestModel) matchURLs() {
+	m.urlMatches = nil
+	m.urlMatch = -1
+	typed := strings.ToLower(strings.TrimSpace(m.urlInput.Value()))
+	if typed == "" {
+		return
+	}
+
+	var later []string
+	for _, known := range m.knownURLs {
+		lower := strings.ToLower(known)
+		switch {
+		case lower == typed:
+		case strings.HasPrefix(lower, typed), strings.HasPrefix(trimScheme(lower), typed):
+			m.urlMatches = append(m.urlMatches, known)
+		case strings.Contains(lower, typed):
+			later = append(later, known)
+		}
+	}
+	m.urlMatches = append(m.urlMatches, later...)
+}
+
+// trimScheme returns url without its scheme, if any.
+func trimScheme(url string) string {
+	if _, rest, ok := strings.Cut(url, "://"); ok {
+		return rest
+	}
+	return url
+}
+
+// handleURLSuggestionKey chooses and takes URL completions: ↑/↓ choose one,
+// and Enter or Tab takes it. It reports whether it handled the key; Tab with
+// no completion chosen moves on as usual.
+func (m *RequestModel) handleURLSuggestionKey(msg tea.KeyMsg) bool {
+	if len(m.urlMatches) == 0 {
+		return false
+	}
+	shown := min(len(m.urlMatches), maxURLSuggestions)
+
+	switch msg.String() {
+	case "down":
+		m.urlMatch = (m.urlMatch + 1) % shown
+		return true
+	case "up":
+		if m.urlMatch <= 0 {
+			m.urlMatch = shown - 1
+		} else {
+			m.urlMatch--
+		}
+		return true
+	case "enter", "tab":
+		if m.urlMatch < 0 {
+			return false
+		}
+		m.urlInput.SetValue(m.urlMatches[m.urlMatch])
+		m.urlInput.CursorEnd()
+		m.matchURLs()
+		return true
+	case "esc":
+		if m.urlMatch < 0 {
+			return false
+		}
+		m.urlMatch = -1
+		return true
+	}
+	return false
+}
+
+// renderURLSuggestions lists the URL completions under the URL while it has
+// focus, marking the one chosen, or returns "" if there are none.
+func (m RequestModel) renderURLSuggestions() string {
+	if m.focusedField != fieldURL || len(m.urlMatches) == 0 {
+		return ""
+	}
+
+	var lines []string
+	for i, match := range m.urlMatches[:min(len(m.urlMatches), maxURLSuggestions)] {
+		cursor := "  "
+		if i == m.urlMatch {
+			cursor = "> "
+		}
+		lines = append(lines, cursor+match)
+	}
+	hint := "  ↑↓: choose a URL"
+	if m.urlMatch >= 0 {
+		hint = "  Enter/Tab: use this URL • Esc: keep what you typed"
+	}
+	return strings.Join(append(lines, hint), "\n")
+}
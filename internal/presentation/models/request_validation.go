@@ -0,0 +1,77 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/williajm/curly/internal/domain"
+	"github.com/williajm/curly/internal/presentation/styles"
+)
+
+// errNoCredentials is the auth error when an auth type is chosen without
+// credentials to go with it, which sends the request without auth.
+var errNoCredentials = errors.New("no credentials; the request is sent without auth")
+
+// fieldError returns what is wrong with a field of the form as it stands,
+// or nil. The form shows it beside the field as the user types, rather than
+// only when the request is sent.
+func (m RequestModel) fieldError(field int) error {
+	switch field {
+	case fieldURL:
+		return m.urlErr()
+	case fieldHeaders:
+		return m.headersEditor.Err()
+	case fieldQueryParams:
+		return m.queryEditor.Err()
+	case fieldBody:
+		switch m.bodyMode {
+		case bodyJSON:
+			if body := m.bodyTextArea.Value(); strings.TrimSpace(body) != "" {
+				if err := validateJSON(body); err != nil {
+					return fmt.Errorf("invalid JSON: %w", err)
+				}
+			}
+		case bodyForm:
+			return m.formEditor.Err()
+		}
+	case fieldAuthType:
+		return m.authErr()
+	}
+	return nil
+}
+
+// urlErr checks the URL with the active environment's variables filled in.
+// An empty URL is not flagged until the request is sent.
+func (m RequestModel) urlErr() error {
+	value := strings.TrimSpace(m.urlInput.Value())
+	if value == "" {
+		return nil
+	}
+	resolved, _ := (&domain.Request{URL: value}).ResolveVariables(m.previewVariables())
+	return resolved.ValidateURL()
+}
+
+// authErr checks the credentials of the chosen auth type.
+func (m RequestModel) authErr() error {
+	authType := authTypes[m.authTypeIndex]
+	if authType == domain.AuthTypeNone {
+		return nil
+	}
+	if m.request.AuthConfig == nil || m.request.AuthConfig.Type() != authType {
+		return errNoCredentials
+	}
+	return m.request.AuthConfig.Validate()
+}
+
+// renderFieldLabel renders the label of a field, marked when focused, and in
+// red with the field's error beside it when it is invalid.
+func (m RequestModel) renderFieldLabel(field int, label string) string {
+	if m.focusedField == field {
+		label += focusedIndicator
+	}
+	if err := m.fieldError(field); err != nil {
+		return styles.ErrorStyle.Render(label + " ✗ " + err.Error())
+	}
+	return label
+}
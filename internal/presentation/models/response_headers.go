@@ -0,0 +1,226 @@
+package models
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/williajm/curly/internal/presentation/styles"
+)
+
+// updateHeaders handles the keys of the headers pane: ↑/↓ choose a header,
+// Enter or y copies its value and Y copies every header. It reports whether
+// the key was handled.
+func (m *ResponseModel) updateHeaders(msg tea.KeyMsg) (bool, tea.Cmd) {
+	names := m.headerNames()
+	switch msg.String() {
+	case "up", "k":
+		m.headerCursor = max(m.headerCursor-1, 0)
+		return true, nil
+	case "down", "j":
+		m.headerCursor = min(m.headerCursor+1, max(len(names)-1, 0))
+		return true, nil
+	case "enter", "y":
+		if len(names) == 0 {
+			return true, nil
+		}
+		name := names[m.headerCursor]
+		return true, copyToClipboard(name+" header", m.response.Headers[name])
+	}
+	return false, nil
+}
+
+// headerNames returns the response header names, sorted.
+func (m ResponseModel) headerNames() []string {
+	if m.response == nil {
+		return nil
+	}
+	names := make([]string, 0, len(m.response.Headers))
+	for name := range m.response.Headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// headerLines returns the response headers as "Name: value" lines, sorted
+// by name.
+func (m ResponseModel) headerLines() string {
+	names := m.headerNames()
+	lines := make([]string, len(names))
+	for i, name := range names {
+		lines[i] = name + ": " + m.response.Headers[name]
+	}
+	return strings.Join(lines, "\n")
+}
+
+// renderPaneTabs renders the Body and Headers sub-tabs, and Security for
+// HTTPS responses, marking the one shown.
+func (m ResponseModel) renderPaneTabs() string {
+	body, headers, security := "Body", fmt.Sprintf("Headers (%d)", len(m.response.Headers)), "Security"
+	switch {
+	case m.showingHeaders:
+		headers = "[" + headers + "]"
+	case m.showingSecurity:
+		security = "[" + security + "]"
+	default:
+		body = "[" + body + "]"
+	}
+	if !m.hasSecurity() {
+		return body + "  " + headers
+	}
+	return body + "  " + headers + "  " + security
+}
+
+// renderSummary renders the status, timing, size and assertion outcome on
+// one line.
+func (m ResponseModel) renderSummary() string {
+	parts := []string{styles.RenderStatusCode(m.response.StatusCode, m.statusText())}
+	if m.showTime {
+		parts = append(parts, fmt.Sprintf("%dms", m.response.DurationMillis()))
+	}
+	parts = append(parts, formatSize(m.response.ContentLength))
+	if n := len(m.response.AssertionFailures); n > 0 {
+		parts = append(parts, fmt.Sprintf("✗ %d assertions failed", n))
+	}
+	return strings.Join(parts, " • ")
+}
+
+// renderHeaders renders the headers pane: the response metadata, the timing
+// waterfall, its failed assertions and its headers, with the selected header
+// marked.
+func (m ResponseModel) renderHeaders() string {
+	contentType := m.response.ContentType()
+	if contentType == "" {
+		contentType = "(none)"
+	}
+
+	lines := []string{
+		fmt.Sprintf("%-14s %s", "Status:", m.statusText()),
+		fmt.Sprintf("%-14s %dms", "Time:", m.response.DurationMillis()),
+		fmt.Sprintf("%-14s %s (%d bytes)", "Size:", formatSize(m.response.ContentLength), m.response.ContentLength),
+		fmt.Sprintf("%-14s %s", "Content type:", contentType),
+	}
+	if !m.response.Timestamp.IsZero() {
+		lines = append(lines, fmt.Sprintf("%-14s %s", "Received:", m.response.Timestamp.Local().Format("2006-01-02 15:04:05")))
+	}
+	if timing := m.renderTiming(); len(timing) > 0 {
+		lines = append(lines, timing...)
+	}
+	if failures := m.response.AssertionFailures; len(failures) > 0 {
+		lines = append(lines, fmt.Sprintf("%-14s %d failed", "Assertions:", len(failures)))
+		for _, failure := range failures {
+			lines = append(lines, "  ✗ "+failure)
+		}
+	}
+	if len(m.response.SetCookies) > 0 {
+		lines = append(lines, "Cookies set:")
+		lines = append(lines, renderSetCookies(m.response.SetCookies)...)
+	}
+	lines = append(lines, "")
+
+	names := m.headerNames()
+	if len(names) == 0 {
+		return strings.Join(append(lines, "No headers"), "\n")
+	}
+
+	// Show a window of headers around the cursor.
+	height := max(m.viewport.Height-len(lines), 3)
+	start := min(max(m.headerCursor-height/2, 0), max(len(names)-height, 0))
+	end := min(start+height, len(names))
+	for i := start; i < end; i++ {
+		cursor := "  "
+		if i == m.headerCursor {
+			cursor = "> "
+		}
+		lines = append(lines, cursor+names[i]+": "+m.response.Headers[names[i]])
+	}
+	return strings.Join(lines, "\n")
+}
+
+// renderSetCookies renders Set-Cookie header values one cookie per line,
+// with their attributes split out.
+func renderSetCookies(values []string) []string {
+	lines := make([]string, 0, len(values))
+	for _, value := range values {
+		cookie, err := http.ParseSetCookie(value)
+		if err != nil {
+			lines = append(lines, fmt.Sprintf("  ✗ %s (%v)", value, err))
+			continue
+		}
+
+		fields := []string{cookie.Name + "=" + cookie.Value}
+		if cookie.Domain != "" {
+			fields = append(fields, "domain="+cookie.Domain)
+		}
+		if cookie.Path != "" {
+			fields = append(fields, "path="+cookie.Path)
+		}
+		if !cookie.Expires.IsZero() {
+			fields = append(fields, "expires="+cookie.Expires.Local().Format("2006-01-02 15:04:05"))
+		}
+		if cookie.MaxAge != 0 {
+			fields = append(fields, fmt.Sprintf("max-age=%d", max(cookie.MaxAge, 0)))
+		}
+		if cookie.Secure {
+			fields = append(fields, "secure")
+		}
+		if cookie.HttpOnly {
+			fields = append(fields, "httponly")
+		}
+		switch cookie.SameSite {
+		case http.SameSiteLaxMode:
+			fields = append(fields, "samesite=Lax")
+		case http.SameSiteStrictMode:
+			fields = append(fields, "samesite=Strict")
+		case http.SameSiteNoneMode:
+			fields = append(fields, "samesite=None")
+		}
+		lines = append(lines, "  "+strings.Join(fields, "  "))
+	}
+	return lines
+}
+
+// StatusSummary summarizes the response on one line for the status bar,
+// such as "200 OK · 143 ms · 2.4 KB · application/json", without the time
+// unless showTime is set. It returns "" if there is no response.
+func (m ResponseModel) StatusSummary() string {
+	if m.response == nil {
+		return ""
+	}
+	parts := []string{m.statusText()}
+	if m.showTime {
+		parts = append(parts, fmt.Sprintf("%d ms", m.response.DurationMillis()))
+	}
+	parts = append(parts, formatSize(m.response.ContentLength))
+	if contentType := m.response.ContentType(); contentType != "" {
+		parts = append(parts, contentType)
+	}
+	return strings.Join(parts, " · ")
+}
+
+// statusText returns the status line, such as "200 OK".
+func (m ResponseModel) statusText() string {
+	if m.response.Status != "" {
+		return m.response.Status
+	}
+	return fmt.Sprintf("%d", m.response.StatusCode)
+}
+
+// formatSize formats a byte count for reading, such as "4.5 KB".
+func formatSize(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	value, suffix := float64(n)/unit, "KB"
+	for _, next := range []string{"MB", "GB"} {
+		if value < unit {
+			break
+		}
+		value, suffix = value/unit, next
+	}
+	return fmt.Sprintf("%.1f %s", value, suffix)
+}
@@ -0,0 +1,68 @@
+package models
+
+import (
+	"fmt"
+	"mime"
+	"os"
+	"strings"
+
+	"github.com/williajm/curly/internal/infrastructure/imagepreview"
+)
+
+// SetImagePreview sets how image responses are previewed; ProtocolAuto
+// detects what the terminal supports and ProtocolOff shows them as a hex
+// dump.
+func (m *ResponseModel) SetImagePreview(p imagepreview.Protocol) {
+	if p == imagepreview.ProtocolAuto {
+		p = imagepreview.Detect(os.Getenv)
+	}
+	m.imageProtocol = p
+	m.updateViewportContent()
+}
+
+// loadImage decodes the body of an image response for previewing, leaving
+// image nil for other responses and images that cannot be decoded.
+func (m *ResponseModel) loadImage() {
+	m.image = nil
+	if m.response == nil {
+		return
+	}
+	mediaType, _, _ := mime.ParseMediaType(m.response.ContentType())
+	if !strings.HasPrefix(mediaType, "image/") || mediaType == "image/svg+xml" {
+		return
+	}
+	if img, err := imagepreview.Decode(m.response.Body); err == nil {
+		m.image = img
+	}
+}
+
+// previewing reports whether the body is shown as an image preview rather
+// than in the viewport.
+func (m ResponseModel) previewing() bool {
+	return m.image != nil && m.imageProtocol != imagepreview.ProtocolOff &&
+		!m.raw && !m.showingHeaders && !m.showingSecurity && m.filter == ""
+}
+
+// renderImage renders the image preview in place of the viewport, padded to
+// its height, followed by the image's format, dimensions and size.
+func (m ResponseModel) renderImage() string {
+	lines := append([]string(nil), m.imageLines...)
+	for len(lines) < m.viewport.Height {
+		lines = append(lines, "")
+	}
+	lines = append(lines, fmt.Sprintf("%s image • %d×%d px • %s",
+		strings.ToUpper(m.image.Format), m.image.Width(), m.image.Height(), formatSize(int64(len(m.response.Body)))))
+	return strings.Join(lines, "\n")
+}
+
+// ImageShown reports whether an image drawn with a graphics protocol is in
+// the response pane, for clearing kitty images once it is not.
+func (m ResponseModel) ImageShown() bool {
+	return m.response != nil && m.previewing()
+}
+
+// KittyImages reports whether images are previewed with the kitty protocol,
+// whose images stay on screen until deleted.
+func (m ResponseModel) KittyImages() bool {
+	return m.imageProtocol == imagepreview.ProtocolKitty
+}
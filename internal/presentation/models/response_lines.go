@@ -0,0 +1,188 @@
+package models
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/williajm/curly/internal/presentation/styles"
+)
+
+// The body is paged rather than handed to the viewport whole: m.lines holds
+// the lines of the body shown, split once when it changes, and the viewport
+// only ever holds the rows in view, rendered (highlighted, wrapped and
+// numbered) when the view scrolls or changes. Multi-megabyte bodies so cost
+// a screenful of work per key rather than the whole body.
+
+// setContent sets the body shown to content and lays it out.
+func (m *ResponseModel) setContent(content string) {
+	m.content = content
+	m.lines = strings.Split(content, "\n")
+	m.layoutLines()
+}
+
+// layoutLines works out where each line of the body starts when displayed,
+// soft wrapped to the viewport width and numbered when those toggles are on,
+// then renders the rows in view.
+func (m *ResponseModel) layoutLines() {
+	m.lineStarts = nil
+	m.gutterWidth = 0
+	if m.lineNumbers {
+		m.gutterWidth = len(fmt.Sprint(len(m.lines))) + len(" │ ")
+	}
+
+	m.rows = len(m.lines)
+	if m.wrap && m.textWidth() > 0 {
+		// Wrapping needs the width of every line, but only when the body
+		// or the width changes.
+		m.lineStarts = make([]int, len(m.lines))
+		m.rows = 0
+		for i, line := range m.lines {
+			m.lineStarts[i] = m.rows
+			if len(line) <= m.textWidth() || ansi.StringWidth(line) <= m.textWidth() {
+				m.rows++
+				continue
+			}
+			m.rows += strings.Count(ansi.Wrap(line, m.textWidth(), ""), "\n") + 1
+		}
+	}
+	m.scrollTo(m.offset)
+}
+
+// textWidth returns the width left for the body beside the line numbers.
+func (m ResponseModel) textWidth() int {
+	return m.viewport.Width - m.gutterWidth
+}
+
+// scrollTo scrolls so that display row is at the top of the view, as far as
+// the body allows, and renders the rows in view.
+func (m *ResponseModel) scrollTo(row int) {
+	m.offset = max(0, min(row, m.rows-m.viewport.Height))
+	m.renderWindow()
+}
+
+// renderWindow sets the viewport to the rows in view, with the search matches
+// highlighted.
+func (m *ResponseModel) renderWindow() {
+	line, skip := m.lineAt(m.offset)
+	digits := len(fmt.Sprint(len(m.lines)))
+
+	window := make([]string, 0, m.viewport.Height)
+	for ; line < len(m.lines) && len(window) < m.viewport.Height; line++ {
+		text := m.highlight(line)
+		segments := []string{text}
+		if m.wrap && m.textWidth() > 0 {
+			segments = strings.Split(ansi.Wrap(text, m.textWidth(), ""), "\n")
+		}
+		for j, segment := range segments {
+			if j < skip {
+				continue
+			}
+			if m.lineNumbers {
+				// Continuation lines of a wrapped line are not numbered.
+				number := ""
+				if j == 0 {
+					number = fmt.Sprint(line + 1)
+				}
+				segment = fmt.Sprintf("%*s │ %s", digits, number, segment)
+			}
+			window = append(window, segment)
+			if len(window) == m.viewport.Height {
+				break
+			}
+		}
+		skip = 0
+	}
+
+	m.viewport.SetContent(strings.Join(window, "\n"))
+	m.viewport.SetYOffset(0)
+}
+
+// lineAt returns the line of the body displayed at row, and how many of its
+// wrapped rows come before row.
+func (m ResponseModel) lineAt(row int) (int, int) {
+	if m.lineStarts == nil {
+		return row, 0
+	}
+	// The last line starting at or before row.
+	line := sort.SearchInts(m.lineStarts, row+1) - 1
+	if line < 0 {
+		return 0, 0
+	}
+	return line, row - m.lineStarts[line]
+}
+
+// displayLine returns the display row where line of the body shown starts.
+func (m ResponseModel) displayLine(line int) int {
+	if line < len(m.lineStarts) {
+		return m.lineStarts[line]
+	}
+	return line
+}
+
+// highlight returns line of the body shown with its search matches
+// highlighted, or its JSON syntax colored if it has none.
+func (m ResponseModel) highlight(line int) string {
+	text := m.lines[line]
+	i := sort.Search(len(m.matches), func(i int) bool { return m.matches[i].line >= line })
+	if i == len(m.matches) || m.matches[i].line != line {
+		if m.syntax && m.jsonShown {
+			return highlightJSON(text)
+		}
+		return text
+	}
+
+	var b strings.Builder
+	last := 0
+	for ; i < len(m.matches) && m.matches[i].line == line; i++ {
+		match := m.matches[i]
+		style := styles.SearchMatchStyle
+		if i == m.match {
+			style = styles.SearchCurrentMatchStyle
+		}
+		b.WriteString(text[last:match.start])
+		b.WriteString(style.Render(text[match.start:match.end]))
+		last = match.end
+	}
+	b.WriteString(text[last:])
+	return b.String()
+}
+
+// scrollKey scrolls the body for the viewport's scrolling keys: ↑/↓, PgUp/PgDn,
+// Space, b, u and d. It reports whether key was one of them.
+func (m *ResponseModel) scrollKey(msg tea.KeyMsg) bool {
+	keys := m.viewport.KeyMap
+	switch {
+	case key.Matches(msg, keys.Up):
+		m.scrollTo(m.offset - 1)
+	case key.Matches(msg, keys.Down):
+		m.scrollTo(m.offset + 1)
+	case key.Matches(msg, keys.PageUp):
+		m.scrollTo(m.offset - m.viewport.Height)
+	case key.Matches(msg, keys.PageDown):
+		m.scrollTo(m.offset + m.viewport.Height)
+	case key.Matches(msg, keys.HalfPageUp):
+		m.scrollTo(m.offset - m.viewport.Height/2)
+	case key.Matches(msg, keys.HalfPageDown):
+		m.scrollTo(m.offset + m.viewport.Height/2)
+	default:
+		return false
+	}
+	return true
+}
+
+// scrollMouse scrolls the body with the mouse wheel.
+func (m *ResponseModel) scrollMouse(msg tea.MouseMsg) {
+	if msg.Action != tea.MouseActionPress {
+		return
+	}
+	switch msg.Button {
+	case tea.MouseButtonWheelUp:
+		m.scrollTo(m.offset - m.viewport.MouseWheelDelta)
+	case tea.MouseButtonWheelDown:
+		m.scrollTo(m.offset + m.viewport.MouseWheelDelta)
+	}
+}
@@ -2,11 +2,15 @@ package models
 
 import (
 	"fmt"
+	"os"
 	"strings"
 
+	"github.com/charmbracelet/bubbles/textinput"
 	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/williajm/curly/internal/app"
 	"github.com/williajm/curly/internal/domain"
+	"github.com/williajm/curly/internal/infrastructure/imagepreview"
 )
 
 // ResponseModel represents the response viewer.
@@ -18,21 +22,112 @@ type ResponseModel struct {
 	viewport viewport.Model
 
 	// State.
-	showingHeaders bool // Toggle between headers and body view
+	showingHeaders  bool // Toggle between headers and body view
+	showingSecurity bool // Show the TLS details of an HTTPS response; see response_security.go
+	headerCursor    int  // Selected header in the headers pane
+	raw             bool // Show the body exactly as received rather than formatted
+	formatted       bool // The body shown was reformatted, so differs from raw
+	binary          bool // The body is binary, so shown as a hex dump
+
+	// Display preferences: showTime shows how long the response took in the
+	// summary, and syntax colors JSON bodies shown pretty, which jsonShown
+	// reports; see response_syntax.go.
+	showTime  bool
+	syntax    bool
+	jsonShown bool
+
+	// Image responses are previewed with imageProtocol in place of the body;
+	// see response_image.go. imageLines is the preview at the viewport size.
+	imageProtocol imagepreview.Protocol
+	image         *imagepreview.Image
+	imageLines    []string
+
+	// Display toggles for the body: soft wrapping long lines and numbering
+	// lines. The body shown is paged through the viewport a window at a time:
+	// lines are its lines, lineStarts and gutterWidth describe them as
+	// displayed, rows is how many rows they take and offset is the first row
+	// in view; see response_lines.go.
+	wrap        bool
+	lineNumbers bool
+	lines       []string
+	lineStarts  []int
+	gutterWidth int
+	rows        int
+	offset      int
+
+	// The formatted body of formattedFor, kept so that it is only formatted
+	// once per response.
+	formattedFor  *domain.Response
+	formattedBody string
+	formattedOK   bool
+
+	// A server-sent event stream, shown in place of the body while it is
+	// received and after it ends; see response_stream.go. shownEvents is how
+	// many of events are in view, which stops growing while paused.
+	eventStream bool
+	events      []app.StreamEvent
+	shownEvents int
+	streaming   bool
+	paused      bool
+	streamEnd   string
+
+	// Filter box: a JSONPath or jq path that narrows the body to the values
+	// it selects.
+	filterInput textinput.Model
+	filtering   bool   // The filter box has focus
+	filter      string // The applied filter, empty for the whole body
+
+	// Search box: text found in the body shown and highlighted in place; see
+	// response_search.go.
+	searchInput textinput.Model
+	searching   bool   // The search box has focus
+	search      string // The text searched for, empty for no search
+	matches     []searchMatch
+	match       int    // Index of the current match
+	content     string // The body shown, before highlighting
 
 	// UI dimensions.
 	width  int
 	height int
 }
 
+// responseChromeLines is the number of lines around the body viewport: the
+// tabs and status bar, the response title, summary and key hints, and the
+// filter, search and scroll position lines.
+const responseChromeLines = 16
+
+// responseFilterMsg reports a newly applied or cleared body filter, so it can
+// be remembered for the request.
+type responseFilterMsg struct {
+	filter string
+}
+
+// responseFilterSavedMsg reports whether the filter was remembered.
+type responseFilterSavedMsg struct {
+	err error
+}
+
 // NewResponseModel creates a new response viewer model.
 func NewResponseModel() ResponseModel {
 	vp := viewport.New(80, 20)
 	vp.SetContent("No response yet. Send a request to see the response here.")
 
+	filterInput := textinput.New()
+	filterInput.Placeholder = "$.items[*].id or .items[].id"
+	filterInput.CharLimit = 500
+
+	searchInput := textinput.New()
+	searchInput.Placeholder = "text to find"
+	searchInput.CharLimit = 200
+
 	return ResponseModel{
 		viewport:       vp,
 		showingHeaders: false,
+		filterInput:    filterInput,
+		searchInput:    searchInput,
+		imageProtocol:  imagepreview.Detect(os.Getenv),
+		showTime:       true,
+		syntax:         true,
 	}
 }
 
@@ -43,45 +138,271 @@ func (m ResponseModel) Init() tea.Cmd {
 
 // Update handles messages and updates the model.
 func (m ResponseModel) Update(msg tea.Msg) (ResponseModel, tea.Cmd) {
-	var cmd tea.Cmd
-
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
-		switch msg.String() {
-		case KeyCtrlC:
-			return m, tea.Quit
+		return m.updateKeys(msg)
 
-		case "h":
-			// Toggle headers/body view.
-			m.showingHeaders = !m.showingHeaders
-			m.updateViewportContent()
-			return m, nil
-
-		default:
-			// Pass other keys to viewport for scrolling.
-			m.viewport, cmd = m.viewport.Update(msg)
+	case tea.MouseMsg:
+		// Scroll the body with the mouse wheel.
+		if !m.showingHeaders && !m.showingSecurity {
+			m.scrollMouse(msg)
 		}
 
 	case tea.WindowSizeMsg:
 		m.width = msg.Width
 		m.height = msg.Height
-		m.viewport.Width = msg.Width - 4
-		m.viewport.Height = msg.Height - 10 // Leave room for header and status bar
+		m.viewport.Width = max(msg.Width-4, 10)
+		m.viewport.Height = max(msg.Height-responseChromeLines, 3)
 		m.updateViewportContent()
 
 	case requestSentMsg:
 		// Update response when request completes.
 		if msg.err == nil && msg.response != nil {
 			m.response = msg.response
+			m.showingSecurity = m.showingSecurity && m.hasSecurity()
+			m.headerCursor = 0
+			m.offset = 0
+			m.loadImage()
 			m.updateViewportContent()
 		}
 	}
 
+	return m, nil
+}
+
+// updateKeys handles a key, passing it to the filter or search box while
+// one has focus and to the headers or event stream while shown.
+func (m ResponseModel) updateKeys(msg tea.KeyMsg) (ResponseModel, tea.Cmd) {
+	if m.filtering {
+		return m.updateFilter(msg)
+	}
+	if m.searching {
+		return m.updateSearch(msg)
+	}
+	if m.showingHeaders {
+		if handled, cmd := m.updateHeaders(msg); handled {
+			return m, cmd
+		}
+	} else if m.eventsShown() {
+		if handled, cmd := m.updateStreamKeys(msg); handled {
+			return m, cmd
+		}
+	}
+	cmd := m.handleKey(msg)
+	return m, cmd
+}
+
+// handleKey handles the keys available in every view.
+func (m *ResponseModel) handleKey(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case KeyCtrlC:
+		return tea.Quit
+
+	case "y", "Y", "v", "c":
+		return m.copy(msg.String())
+
+	case "o":
+		// Save the body to a file, as received.
+		return m.saveBody()
+
+	case "r":
+		// Show the request behind the response in wire format.
+		if m.response == nil {
+			return nil
+		}
+		return func() tea.Msg { return showSentRequestMsg{} }
+
+	case "/", "f":
+		// Edit the body filter.
+		m.filtering = true
+		m.filterInput.SetValue(m.filter)
+		m.filterInput.CursorEnd()
+		return m.filterInput.Focus()
+
+	case "s":
+		// Search the body.
+		return m.startSearch()
+
+	case "n":
+		m.nextMatch(1)
+
+	case "N":
+		m.nextMatch(-1)
+
+	case "esc":
+		return m.clearSearchOrFilter()
+
+	case "g", "home":
+		m.scrollTo(0)
+
+	case "G", "end":
+		m.scrollTo(m.rows)
+
+	default:
+		if m.toggleView(msg.String()) {
+			return nil
+		}
+		// Scroll with ↑/↓, PgUp/PgDn, Space, b, u and d, and pass other
+		// keys, such as ←/→ for long lines, to the viewport.
+		if !m.scrollKey(msg) {
+			var cmd tea.Cmd
+			m.viewport, cmd = m.viewport.Update(msg)
+			return cmd
+		}
+	}
+	return nil
+}
+
+// toggleView handles the keys that switch what the body view shows,
+// reporting whether the key was one of them.
+func (m *ResponseModel) toggleView(key string) bool {
+	switch key {
+	case "p":
+		// Toggle pretty/raw body view.
+		m.raw = !m.raw
+		m.updateViewportContent()
+
+	case "h":
+		// Toggle headers/body view.
+		m.showingHeaders = !m.showingHeaders
+		m.showingSecurity = false
+		m.updateViewportContent()
+
+	case "t":
+		// Toggle the security pane of an HTTPS response.
+		if m.hasSecurity() {
+			m.showingSecurity = !m.showingSecurity
+			m.showingHeaders = false
+			m.updateViewportContent()
+		}
+
+	case "w":
+		// Toggle soft wrapping of long lines.
+		m.wrap = !m.wrap
+		m.viewport.SetXOffset(0)
+		m.layoutLines()
+
+	case "#":
+		// Toggle line numbers.
+		m.lineNumbers = !m.lineNumbers
+		m.layoutLines()
+
+	default:
+		return false
+	}
+	return true
+}
+
+// clearSearchOrFilter clears the search, then the body filter.
+func (m *ResponseModel) clearSearchOrFilter() tea.Cmd {
+	if m.search != "" {
+		m.setSearch("")
+		return nil
+	}
+	if m.filter != "" {
+		m.filter = ""
+		m.updateViewportContent()
+		return filterChanged("")
+	}
+	return nil
+}
+
+// updateFilter handles keys while the filter box has focus: enter applies
+// the filter and esc leaves it unchanged.
+func (m ResponseModel) updateFilter(msg tea.KeyMsg) (ResponseModel, tea.Cmd) {
+	switch msg.String() {
+	case KeyCtrlC:
+		return m, tea.Quit
+	case "enter":
+		previous := m.filter
+		m.filter = strings.TrimSpace(m.filterInput.Value())
+		m.filtering = false
+		m.filterInput.Blur()
+		m.showingHeaders = false
+		m.showingSecurity = false
+		m.updateViewportContent()
+		if m.filter == previous {
+			return m, nil
+		}
+		return m, filterChanged(m.filter)
+	case "esc":
+		m.filtering = false
+		m.filterInput.Blur()
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.filterInput, cmd = m.filterInput.Update(msg)
 	return m, cmd
 }
 
+// copyRequestMsg asks for the request behind the response to be copied as a
+// curl command; the response view does not have the request.
+type copyRequestMsg struct{}
+
+// copy returns a command copying part of the response for a copy key: y the
+// body as received, whichever view is shown; Y the headers; v the values the
+// filter selects; c the request as a curl command.
+func (m ResponseModel) copy(key string) tea.Cmd {
+	if m.response == nil {
+		return nil
+	}
+	switch key {
+	case "y":
+		return copyToClipboard("response body", m.response.Body)
+	case "Y":
+		return copyToClipboard("response headers", m.headerLines())
+	case "v":
+		if m.filter == "" {
+			return func() tea.Msg {
+				return clipboardMsg{what: "selected value", err: fmt.Errorf("filter the body with / to select a value first")}
+			}
+		}
+		text, err := m.response.ExtractText(m.filter)
+		if err != nil {
+			return func() tea.Msg {
+				return clipboardMsg{what: "selected value", err: err}
+			}
+		}
+		return copyToClipboard("selected value", text)
+	case "c":
+		return func() tea.Msg {
+			return copyRequestMsg{}
+		}
+	}
+	return nil
+}
+
+// filterChanged returns a command reporting the applied filter.
+func filterChanged(filter string) tea.Cmd {
+	return func() tea.Msg {
+		return responseFilterMsg{filter: filter}
+	}
+}
+
+// SetFilter applies filter to the body, as when loading a request that
+// remembers one.
+func (m *ResponseModel) SetFilter(filter string) {
+	m.filter = filter
+	m.filtering = false
+	m.filterInput.Blur()
+	m.updateViewportContent()
+}
+
+// Filtering reports whether the filter box has focus, so that keys such as
+// "q" are typed into it rather than handled globally.
+func (m ResponseModel) Filtering() bool {
+	return m.filtering
+}
+
 // View renders the response viewer.
 func (m ResponseModel) View() string {
+	return m.PaneView() + "\n\n" + m.renderKeyHints()
+}
+
+// PaneView renders the response without the key hints, for showing it beside
+// the request builder.
+func (m ResponseModel) PaneView() string {
 	var sections []string
 
 	sections = append(sections, "══ Response ══")
@@ -89,74 +410,184 @@ func (m ResponseModel) View() string {
 
 	if m.response == nil {
 		sections = append(sections, "No response yet. Send a request to see the response here.")
-		sections = append(sections, "")
-		sections = append(sections, "h: toggle headers/body • ↑↓: scroll • q: quit")
 		return strings.Join(sections, "\n")
 	}
 
-	// Status line.
-	statusLine := fmt.Sprintf("Status: %d %s", m.response.StatusCode, m.response.Status)
-	sections = append(sections, statusLine)
-
-	// Timing.
-	timingLine := fmt.Sprintf("Time: %dms", m.response.DurationMillis())
-	sections = append(sections, timingLine)
-
-	// Content length.
-	sizeLine := fmt.Sprintf("Size: %d bytes", m.response.ContentLength)
-	sections = append(sections, sizeLine)
+	// Sub-tabs, with the status, timing and size always in view. The headers
+	// pane has the details.
+	sections = append(sections, m.renderPaneTabs())
+	sections = append(sections, m.renderSummary())
 	sections = append(sections, "")
 
-	// View mode indicator.
-	if m.showingHeaders {
-		sections = append(sections, "═══ Headers ═══")
+	switch {
+	case m.showingHeaders:
 		sections = append(sections, m.renderHeaders())
-	} else {
-		sections = append(sections, "═══ Body ═══")
+	case m.showingSecurity:
+		sections = append(sections, m.renderSecurity())
+	default:
+		sections = append(sections, "═══ Body ("+m.bodyViewName()+") ═══")
+		switch {
+		case m.filtering:
+			sections = append(sections, "Filter: "+m.filterInput.View())
+		case m.filter != "":
+			sections = append(sections, "Filter: "+m.filter)
+		}
+		if m.searching || m.search != "" {
+			sections = append(sections, m.renderSearch())
+		}
+		if m.previewing() {
+			sections = append(sections, m.renderImage())
+			return strings.Join(sections, "\n")
+		}
 		sections = append(sections, m.viewport.View())
+		sections = append(sections, m.renderScrollPosition())
 	}
 
-	sections = append(sections, "")
-	sections = append(sections, "h: toggle headers/body • ↑↓: scroll • q: quit")
-
 	return strings.Join(sections, "\n")
 }
 
-func (m ResponseModel) renderHeaders() string {
-	if m.response == nil || len(m.response.Headers) == 0 {
-		return "No headers"
+// renderScrollPosition renders which lines of the body are in view, such as
+// "lines 21-40 of 350 (11%)".
+func (m ResponseModel) renderScrollPosition() string {
+	total := m.rows
+	if total <= m.viewport.Height {
+		return fmt.Sprintf("%d lines (all)", total)
 	}
+	first := m.offset + 1
+	last := min(m.offset+m.viewport.Height, total)
+	percent := float64(m.offset) / float64(total-m.viewport.Height) * 100
+	return fmt.Sprintf("lines %d-%d of %d (%.0f%%)", first, last, total, percent)
+}
+
+// renderKeyHints renders the keys available in the current mode.
+func (m ResponseModel) renderKeyHints() string {
+	switch {
+	case m.response == nil:
+		return "h: toggle headers/body • p: pretty/raw • /: filter body • ↑↓: scroll • q: quit"
+	case m.filtering:
+		return "enter: apply filter • esc: cancel"
+	case m.searching:
+		return "enter: keep search • esc: clear search"
+	case m.showingHeaders:
+		return "h: body • ↑↓: choose header • enter/y: copy value • Y: copy all • q: quit"
+	case m.showingSecurity:
+		return "t: body • h: headers • q: quit"
+	default:
+		return m.bodyKeyHints()
+	}
+}
 
-	var lines []string
-	for key, value := range m.response.Headers {
-		lines = append(lines, fmt.Sprintf("%s: %s", key, value))
+// bodyKeyHints renders the keys available while the body is shown.
+func (m ResponseModel) bodyKeyHints() string {
+	switch {
+	case m.eventsShown() && m.streaming:
+		return "p: pause/resume • o: save transcript • esc: stop stream • h: headers • ↑↓/PgUp/PgDn: scroll • g/G: top/bottom • q: quit"
+	case m.eventsShown():
+		return "h: toggle headers/body • p: raw stream • o: save transcript • s: search • ↑↓/PgUp/PgDn: scroll • g/G: top/bottom • q: quit"
+	case m.previewing():
+		return "h: toggle headers/body • p: hex dump • o: save body to file • r: raw request • q: quit"
+	case m.binary && m.search == "" && m.image != nil && m.imageProtocol != imagepreview.ProtocolOff:
+		return "h: toggle headers/body • p: image preview • o: save body to file • s: search • ↑↓/PgUp/PgDn: page • g/G: top/bottom • q: quit"
+	case m.binary && m.search == "":
+		return "h: toggle headers/body • o: save body to file • s: search • r: raw request • ↑↓/PgUp/PgDn: page • g/G: top/bottom • q: quit"
+	case m.search != "":
+		return "n/N: next/previous match • s: edit search • esc: clear search • ↑↓/PgUp/PgDn: scroll • q: quit"
+	case m.filter != "":
+		return "h: toggle headers/body • /: edit filter • esc: clear filter • v: copy value • ↑↓/PgUp/PgDn: scroll • g/G: top/bottom • q: quit"
+	default:
+		return "h: toggle headers/body • p: pretty/raw • /: filter body • s: search • y/Y/c: copy body/headers/curl • r: raw request • w/#: wrap/line numbers • ↑↓/PgUp/PgDn: scroll • g/G: top/bottom • q: quit"
 	}
-	return strings.Join(lines, "\n")
 }
 
 // updateViewportContent updates the viewport with current response data.
 func (m *ResponseModel) updateViewportContent() {
 	if m.response == nil {
-		m.viewport.SetContent("No response yet. Send a request to see the response here.")
+		m.setContent("No response yet. Send a request to see the response here.")
 		return
 	}
 
 	// Content will be formatted in response_view.go.
 	// For now, use simple formatting.
 	content := ""
-	if m.showingHeaders {
+	m.formatted = false
+	m.binary = m.response.IsBinary()
+	m.imageLines = nil
+	switch {
+	case m.showingHeaders:
 		content = "Headers view"
-	} else {
+	case m.showingSecurity:
+		content = "Security view"
+	case m.eventsShown():
+		content = m.eventsContent()
+	case m.previewing():
+		// Shown in place of the viewport, leaving a line for the image info.
+		m.imageLines = m.image.Render(m.imageProtocol, m.viewport.Width, m.viewport.Height)
+	case m.binary:
+		// Binary bytes would garble the terminal, raw or not.
+		content = strings.Join(domain.HexDump(m.response.Body), "\n")
+	case m.filter != "":
+		text, err := m.response.ExtractText(m.filter)
+		switch {
+		case err != nil:
+			content = "Filter error: " + err.Error()
+		case text == "":
+			content = "No matches"
+		default:
+			content = text
+		}
+	case m.raw:
 		content = m.response.Body
+	default:
+		if m.formattedFor != m.response {
+			m.formattedBody, m.formattedOK = m.response.FormattedBody()
+			m.formattedFor = m.response
+		}
+		content, m.formatted = m.formattedBody, m.formattedOK
 	}
+	m.jsonShown = m.formatted && (strings.HasPrefix(content, "{") || strings.HasPrefix(content, "["))
+
+	// Matches are found again in the new content.
+	m.lines = strings.Split(content, "\n")
+	m.matches = findMatches(m.lines, m.search)
+	m.match = min(m.match, max(len(m.matches)-1, 0))
+	m.content = content
+	m.layoutLines()
+}
 
-	m.viewport.SetContent(content)
+// bodyViewName names how the body is shown, for the body heading.
+func (m ResponseModel) bodyViewName() string {
+	switch {
+	case m.eventsShown():
+		return m.streamState()
+	case m.previewing():
+		return "image preview"
+	case m.binary:
+		return "binary, hex"
+	case m.filter != "":
+		return "filtered"
+	case m.raw:
+		return "raw"
+	case m.formatted:
+		return "pretty"
+	}
+	return "as received"
 }
 
-// SetResponse sets the response to display.
+// SetResponse sets the response to display. The body filter is kept, so
+// re-sending a request shows the same values.
 func (m *ResponseModel) SetResponse(response *domain.Response) {
 	m.response = response
+	m.eventStream = false
+	m.events = nil
+	m.shownEvents = 0
+	m.streaming = false
+	m.paused = false
+	m.streamEnd = ""
 	m.showingHeaders = false
+	m.showingSecurity = false
+	m.headerCursor = 0
+	m.offset = 0
+	m.loadImage()
 	m.updateViewportContent()
 }
 
@@ -0,0 +1,71 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// bodySavedMsg reports the outcome of saving a response body to a file.
+type bodySavedMsg struct {
+	path string
+	err  error
+}
+
+// maxSaveAttempts bounds the numbered names tried when the suggested file
+// name is taken.
+const maxSaveAttempts = 100
+
+// saveBody returns a command that writes the body, as received, to a new
+// file in the working directory named after the response. An existing file
+// is never overwritten: "logo.png" becomes "logo-1.png" and so on.
+func (m ResponseModel) saveBody() tea.Cmd {
+	if m.response == nil {
+		return nil
+	}
+	name := m.response.SuggestedFilename()
+	body := m.response.Body
+	return func() tea.Msg {
+		path, err := writeNewFile(name, body)
+		return bodySavedMsg{path: path, err: err}
+	}
+}
+
+// writeNewFile writes data to name, or to the first numbered variant of it
+// that does not exist yet, and returns the path written.
+func writeNewFile(name, data string) (string, error) {
+	ext := filepath.Ext(name)
+	base := strings.TrimSuffix(name, ext)
+
+	for i := range maxSaveAttempts {
+		path := name
+		if i > 0 {
+			path = fmt.Sprintf("%s-%d%s", base, i, ext)
+		}
+
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+		if errors.Is(err, fs.ErrExist) {
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+		if _, err := f.WriteString(data); err != nil {
+			_ = f.Close()
+			return "", err
+		}
+		if err := f.Close(); err != nil {
+			return "", err
+		}
+		if abs, err := filepath.Abs(path); err == nil {
+			path = abs
+		}
+		return path, nil
+	}
+	return "", fmt.Errorf("%s and %d numbered variants already exist", name, maxSaveAttempts-1)
+}
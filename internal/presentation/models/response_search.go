@@ -0,0 +1,160 @@
+package models
+
+import (
+	"fmt"
+	"regexp"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// maxSearchMatches caps how many matches are highlighted, so that searching
+// a large body for a single character stays responsive.
+const maxSearchMatches = 10000
+
+// searchMatch is one match of the search text in the body shown, by line and
+// byte offsets within the line.
+type searchMatch struct {
+	line       int
+	start, end int
+}
+
+// startSearch focuses the search box, showing the body if the headers or the
+// security pane were.
+func (m *ResponseModel) startSearch() tea.Cmd {
+	if m.showingHeaders || m.showingSecurity {
+		m.showingHeaders = false
+		m.showingSecurity = false
+		m.updateViewportContent()
+	}
+	m.searching = true
+	m.searchInput.SetValue(m.search)
+	m.searchInput.CursorEnd()
+	return m.searchInput.Focus()
+}
+
+// updateSearch handles keys while the search box has focus. The body is
+// searched as the text changes; enter keeps the search and esc clears it.
+func (m ResponseModel) updateSearch(msg tea.KeyMsg) (ResponseModel, tea.Cmd) {
+	switch msg.String() {
+	case KeyCtrlC:
+		return m, tea.Quit
+	case "enter":
+		m.searching = false
+		m.searchInput.Blur()
+		return m, nil
+	case "esc":
+		m.searching = false
+		m.searchInput.Blur()
+		m.setSearch("")
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.searchInput, cmd = m.searchInput.Update(msg)
+	if text := m.searchInput.Value(); text != m.search {
+		m.setSearch(text)
+	}
+	return m, cmd
+}
+
+// Searching reports whether the search box has focus, so that keys such as
+// "q" are typed into it rather than handled globally.
+func (m ResponseModel) Searching() bool {
+	return m.searching
+}
+
+// setSearch searches the body shown for text, ignoring case, and moves to the
+// first match at or below the top of the viewport.
+func (m *ResponseModel) setSearch(text string) {
+	m.search = text
+	m.matches = findMatches(m.lines, text)
+	m.match = 0
+	for i, match := range m.matches {
+		if m.displayLine(match.line) >= m.offset {
+			m.match = i
+			break
+		}
+	}
+	m.renderWindow()
+	m.showMatch()
+}
+
+// nextMatch moves delta matches forward, or backward if negative, wrapping
+// around the ends of the body.
+func (m *ResponseModel) nextMatch(delta int) {
+	if len(m.matches) == 0 {
+		return
+	}
+	m.match = (m.match + delta + len(m.matches)) % len(m.matches)
+	m.renderWindow()
+	m.showMatch()
+}
+
+// showMatch scrolls the current match into view.
+func (m *ResponseModel) showMatch() {
+	if len(m.matches) == 0 {
+		return
+	}
+	match := m.matches[m.match]
+	y := m.displayLine(match.line)
+	if y < m.offset || y >= m.offset+m.viewport.Height {
+		m.scrollTo(y - m.viewport.Height/2)
+	}
+
+	// Long lines, such as minified JSON, scroll sideways to the match
+	// unless they are wrapped.
+	if m.wrap {
+		m.viewport.SetXOffset(0)
+		return
+	}
+	line := m.lines[match.line]
+	column := m.gutterWidth + lipgloss.Width(line[:match.start])
+	if column+lipgloss.Width(line[match.start:match.end]) > m.viewport.Width {
+		m.viewport.SetXOffset(column - m.viewport.Width/3)
+	} else {
+		m.viewport.SetXOffset(0)
+	}
+}
+
+// renderSearch renders the search box or the current search and its position.
+func (m ResponseModel) renderSearch() string {
+	if m.searching {
+		return "Search: " + m.searchInput.View() + " " + m.matchCount()
+	}
+	return "Search: " + m.search + " " + m.matchCount()
+}
+
+// matchCount describes the current match and how many there are.
+func (m ResponseModel) matchCount() string {
+	switch n := len(m.matches); {
+	case m.search == "":
+		return ""
+	case n == 0:
+		return "(no matches)"
+	case n >= maxSearchMatches:
+		return fmt.Sprintf("(%d/%d+)", m.match+1, n)
+	default:
+		return fmt.Sprintf("(%d/%d)", m.match+1, n)
+	}
+}
+
+// findMatches returns the matches of text in lines, ignoring case, in order,
+// up to maxSearchMatches.
+func findMatches(lines []string, text string) []searchMatch {
+	if text == "" {
+		return nil
+	}
+	re := regexp.MustCompile("(?i)" + regexp.QuoteMeta(text))
+
+	var matches []searchMatch
+	for i, line := range lines {
+		for _, loc := range re.FindAllStringIndex(line, maxSearchMatches-len(matches)) {
+			matches = append(matches, searchMatch{line: i, start: loc[0], end: loc[1]})
+		}
+		if len(matches) >= maxSearchMatches {
+			break
+		}
+	}
+	return matches
+}
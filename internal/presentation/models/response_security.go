@@ -0,0 +1,90 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/williajm/curly/internal/domain"
+	"github.com/williajm/curly/internal/presentation/styles"
+)
+
+// certificateExpiryWarning is how close to expiry a certificate is
+// highlighted as expiring soon.
+const certificateExpiryWarning = 30 * 24 * time.Hour
+
+// hasSecurity reports whether the response has TLS details for the
+// Security pane: it was received over HTTPS in this session.
+func (m ResponseModel) hasSecurity() bool {
+	return m.response != nil && m.response.TLS != nil
+}
+
+// renderSecurity renders the Security pane: the negotiated protocol and
+// cipher, then each certificate of the server's chain, its own first, with
+// the time left until it expires.
+func (m ResponseModel) renderSecurity() string {
+	info := m.response.TLS
+	protocol := info.Version
+	if info.NegotiatedProtocol != "" {
+		protocol += " (ALPN " + info.NegotiatedProtocol + ")"
+	}
+	lines := []string{
+		fmt.Sprintf("%-14s %s", "Protocol:", protocol),
+		fmt.Sprintf("%-14s %s", "Cipher suite:", info.CipherSuite),
+	}
+	if info.ServerName != "" {
+		lines = append(lines, fmt.Sprintf("%-14s %s", "Server name:", info.ServerName))
+	}
+	lines = append(lines, "")
+
+	if len(info.Certificates) == 0 {
+		return strings.Join(append(lines, "No certificates"), "\n")
+	}
+	lines = append(lines, fmt.Sprintf("Certificate chain (%d):", len(info.Certificates)))
+	now := time.Now()
+	for i, cert := range info.Certificates {
+		lines = append(lines, fmt.Sprintf("%d. %s", i+1, cert.Subject))
+		lines = append(lines, fmt.Sprintf("   %-12s %s", "Issuer:", cert.Issuer))
+		if len(cert.DNSNames) > 0 {
+			lines = append(lines, fmt.Sprintf("   %-12s %s", "Names:", strings.Join(cert.DNSNames, ", ")))
+		}
+		lines = append(lines, fmt.Sprintf("   %-12s %s to %s",
+			"Valid:", cert.NotBefore.Local().Format("2006-01-02"), cert.NotAfter.Local().Format("2006-01-02")))
+		lines = append(lines, fmt.Sprintf("   %-12s %s", "Expiry:", renderCertificateExpiry(cert, now)))
+		lines = append(lines, fmt.Sprintf("   %-12s %s, %s signature", "Key:", cert.PublicKeyAlgorithm, cert.SignatureAlgorithm))
+		lines = append(lines, fmt.Sprintf("   %-12s %s", "Serial:", cert.SerialNumber))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// renderCertificateExpiry renders the time left until cert expires,
+// highlighted when it is close or past, or when it is not valid yet.
+func renderCertificateExpiry(cert domain.Certificate, now time.Time) string {
+	left := cert.ExpiresIn(now)
+	switch {
+	case left < 0:
+		return styles.ErrorStyle.Render("✗ expired " + formatDays(-left) + " ago")
+	case now.Before(cert.NotBefore):
+		return styles.ErrorStyle.Render("✗ not valid until " + cert.NotBefore.Local().Format("2006-01-02 15:04"))
+	case left < certificateExpiryWarning:
+		return styles.WarningStyle.Render("⚠ expires in " + formatDays(left))
+	default:
+		return styles.SuccessStyle.Render("✓ expires in " + formatDays(left))
+	}
+}
+
+// formatDays formats a duration in whole days, or hours under a day.
+func formatDays(d time.Duration) string {
+	if d < 24*time.Hour {
+		hours := int(d.Hours())
+		if hours == 1 {
+			return "1 hour"
+		}
+		return fmt.Sprintf("%d hours", hours)
+	}
+	days := int(d.Hours() / 24)
+	if days == 1 {
+		return "1 day"
+	}
+	return fmt.Sprintf("%d days", days)
+}
@@ -0,0 +1,185 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/williajm/curly/internal/app"
+	"github.com/williajm/curly/internal/domain"
+)
+
+// Requests that accept text/event-stream are sent with
+// RequestService.OpenStream, and their events are shown in the response pane
+// as they arrive, each stamped with the time it came, rather than once the
+// server closes the connection. The events stay in view after the stream
+// ends, until the next response.
+
+// streamOpenedMsg reports that the response to a request is an event stream.
+type streamOpenedMsg struct {
+	stream *app.EventStream
+}
+
+// streamEventMsg delivers an event of stream.
+type streamEventMsg struct {
+	stream *app.EventStream
+	event  app.StreamEvent
+}
+
+// streamClosedMsg reports that the stream ended, with the response as
+// received. err is nil if the server closed it, and context.Canceled if it
+// was stopped.
+type streamClosedMsg struct {
+	response *domain.Response
+	err      error
+}
+
+// transcriptSavedMsg reports the outcome of saving a stream's events to a
+// file.
+type transcriptSavedMsg struct {
+	path string
+	err  error
+}
+
+// nextStreamEvent returns a command that waits for the next event of stream,
+// closing the stream once there are no more.
+func nextStreamEvent(stream *app.EventStream) tea.Cmd {
+	return func() tea.Msg {
+		event, err := stream.Next()
+		if err == nil {
+			return streamEventMsg{stream: stream, event: event}
+		}
+		if errors.Is(err, io.EOF) {
+			err = nil
+		}
+		// The stream is recorded even if it was stopped, which is how most
+		// streams end.
+		return streamClosedMsg{response: stream.Close(context.Background()), err: err}
+	}
+}
+
+// StartStream shows the event stream whose status and headers are response,
+// with no events yet.
+func (m *ResponseModel) StartStream(response *domain.Response) {
+	m.SetResponse(response)
+	m.eventStream = true
+	m.streaming = true
+	m.updateViewportContent()
+}
+
+// AddEvent adds an event of the stream shown. The view follows new events
+// while it is scrolled to the bottom, and holds them back while paused.
+func (m *ResponseModel) AddEvent(event app.StreamEvent) {
+	m.events = append(m.events, event)
+	if m.paused {
+		return
+	}
+	following := m.offset >= m.rows-m.viewport.Height
+	m.shownEvents = len(m.events)
+	m.updateViewportContent()
+	if following {
+		m.scrollTo(m.rows)
+	}
+}
+
+// FinishStream shows that the stream ended, with response as received and
+// end describing how.
+func (m *ResponseModel) FinishStream(response *domain.Response, end string) {
+	m.response = response
+	m.streaming = false
+	m.paused = false
+	m.shownEvents = len(m.events)
+	m.streamEnd = end
+	m.updateViewportContent()
+}
+
+// eventsShown reports whether the events of a stream are shown in place of
+// the body.
+func (m ResponseModel) eventsShown() bool {
+	return m.eventStream && !m.raw && m.filter == ""
+}
+
+// updateStreamKeys handles the keys of the event view: p pauses and resumes
+// a stream being received, and o saves the events to a file. It reports
+// whether key was one of them.
+func (m *ResponseModel) updateStreamKeys(msg tea.KeyMsg) (bool, tea.Cmd) {
+	switch msg.String() {
+	case "p":
+		if !m.streaming {
+			return false, nil
+		}
+		m.paused = !m.paused
+		if !m.paused {
+			// Catch up with the events held back.
+			m.shownEvents = len(m.events)
+			m.updateViewportContent()
+			m.scrollTo(m.rows)
+		}
+		return true, nil
+	case "o":
+		return true, m.saveTranscript()
+	}
+	return false, nil
+}
+
+// eventsContent returns the events in view as text: each event's arrival
+// time, type and ID, then its data indented.
+func (m ResponseModel) eventsContent() string {
+	if m.shownEvents == 0 {
+		if m.streaming {
+			return "Waiting for events..."
+		}
+		return "No events"
+	}
+	return transcript(m.events[:m.shownEvents])
+}
+
+// transcript returns events as text, as the event view shows them.
+func transcript(events []app.StreamEvent) string {
+	var b strings.Builder
+	for i, event := range events {
+		if i > 0 {
+			b.WriteString("\n\n")
+		}
+		fmt.Fprintf(&b, "%s  %s", event.Received.Format("15:04:05.000"), event.Type)
+		if event.ID != "" {
+			fmt.Fprintf(&b, "  id=%s", event.ID)
+		}
+		for _, line := range strings.Split(event.Data, "\n") {
+			b.WriteString("\n  " + line)
+		}
+	}
+	return b.String()
+}
+
+// streamState describes the stream for the body heading, such as
+// "12 events, paused, 3 new".
+func (m ResponseModel) streamState() string {
+	state := fmt.Sprintf("%d events", len(m.events))
+	switch {
+	case m.paused:
+		state += fmt.Sprintf(", paused, %d new", len(m.events)-m.shownEvents)
+	case m.streaming:
+		state += ", streaming"
+	case m.streamEnd != "":
+		state += ", " + m.streamEnd
+	}
+	return state
+}
+
+// saveTranscript returns a command that writes every event received so far
+// to a new file in the working directory.
+func (m ResponseModel) saveTranscript() tea.Cmd {
+	if m.response == nil {
+		return nil
+	}
+	name := "events-" + m.response.Timestamp.Format("20060102-150405") + ".txt"
+	text := transcript(m.events) + "\n"
+	return func() tea.Msg {
+		path, err := writeNewFile(name, text)
+		return transcriptSavedMsg{path: path, err: err}
+	}
+}
@@ -0,0 +1,69 @@
+package models
+
+import (
+	"strings"
+
+	"github.com/williajm/curly/internal/presentation/styles"
+)
+
+// jsonLiterals are the JSON values written as words.
+var jsonLiterals = []string{"true", "false", "null"}
+
+// highlightJSON colors the keys, strings, numbers and literals of line, a
+// line of pretty-printed JSON. JSON strings cannot span lines, so each line
+// can be colored on its own.
+func highlightJSON(line string) string {
+	var b strings.Builder
+	for i := 0; i < len(line); {
+		c := line[i]
+		switch {
+		case c == '"':
+			end := jsonStringEnd(line, i)
+			style := styles.SyntaxStringStyle
+			if strings.HasPrefix(strings.TrimLeft(line[end:], " "), ":") {
+				style = styles.SyntaxKeyStyle
+			}
+			b.WriteString(style.Render(line[i:end]))
+			i = end
+
+		case c == '-' || (c >= '0' && c <= '9'):
+			end := i + 1
+			for end < len(line) && strings.IndexByte("0123456789.eE+-", line[end]) >= 0 {
+				end++
+			}
+			b.WriteString(styles.SyntaxNumberStyle.Render(line[i:end]))
+			i = end
+
+		default:
+			literal := ""
+			for _, word := range jsonLiterals {
+				if strings.HasPrefix(line[i:], word) {
+					literal = word
+					break
+				}
+			}
+			if literal == "" {
+				b.WriteByte(c)
+				i++
+				continue
+			}
+			b.WriteString(styles.SyntaxLiteralStyle.Render(literal))
+			i += len(literal)
+		}
+	}
+	return b.String()
+}
+
+// jsonStringEnd returns the index just past the string that starts with the
+// quote at start, or the end of line if the string is not closed.
+func jsonStringEnd(line string, start int) int {
+	for i := start + 1; i < len(line); i++ {
+		switch line[i] {
+		case '\\':
+			i++
+		case '"':
+			return i + 1
+		}
+	}
+	return len(line)
+}
@@ -0,0 +1,49 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// maxWaterfallWidth is the widest the timing waterfall's bars are drawn.
+const maxWaterfallWidth = 40
+
+// renderTiming renders the phases of the request as a waterfall: a bar per
+// phase, placed on a shared time axis, so that the longest stands out. It
+// returns nothing if the phases were not measured.
+func (m ResponseModel) renderTiming() []string {
+	timing := m.response.Timing
+	if timing == nil {
+		return nil
+	}
+	phases := timing.Phases()
+	total := timing.Total()
+	if len(phases) == 0 || total <= 0 {
+		return nil
+	}
+
+	width := min(maxWaterfallWidth, max(m.viewport.Width-30, 10))
+	title := fmt.Sprintf("Timing (%s):", formatPhase(total))
+	if timing.ReusedConnection {
+		title = fmt.Sprintf("Timing (%s, reused connection):", formatPhase(total))
+	}
+	lines := []string{title}
+	for _, phase := range phases {
+		start := int(float64(phase.Start) / float64(total) * float64(width))
+		length := max(int(float64(phase.Duration)/float64(total)*float64(width)+0.5), 1)
+		start = min(start, width-length)
+		bar := strings.Repeat(" ", start) + strings.Repeat("█", length) + strings.Repeat(" ", width-start-length)
+		lines = append(lines, fmt.Sprintf("  %-15s %s %s", phase.Name, bar, formatPhase(phase.Duration)))
+	}
+	return lines
+}
+
+// formatPhase formats the duration of a phase in milliseconds, with
+// fractions for phases under one.
+func formatPhase(d time.Duration) string {
+	if d < time.Millisecond {
+		return fmt.Sprintf("%.2fms", float64(d)/float64(time.Millisecond))
+	}
+	return fmt.Sprintf("%dms", d.Milliseconds())
+}
@@ -0,0 +1,435 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/williajm/curly/internal/app"
+	"github.com/williajm/curly/internal/domain"
+	"github.com/williajm/curly/internal/presentation/styles"
+)
+
+// RunnerModel represents the collection run panel.
+type RunnerModel struct {
+	// Services.
+	requestService *app.RequestService
+	runnerService  *app.RunnerService
+
+	// Folders that can be run.
+	folders       []string
+	selectedIndex int
+	loading       bool
+	running       bool
+	errorMsg      string
+
+	// The run in progress, or the last one: its requests as they execute,
+	// and the report once it has finished. cancel aborts a run in progress.
+	folder       string
+	steps        []runStep
+	progress     <-chan app.RunProgress
+	progressDone <-chan struct{}
+	cancel       context.CancelFunc
+	aborted      bool
+	report       *app.RunReport
+
+	// showTime shows how long each request took beside its status.
+	showTime bool
+}
+
+// runStepState is how far a request of a run has got.
+type runStepState int
+
+const (
+	stepPending runStepState = iota
+	stepRunning
+	stepPassed
+	stepFailed
+	stepSkipped
+)
+
+// runStep is a request of a run and its result, once it has finished.
+type runStep struct {
+	request *domain.Request
+	state   runStepState
+	result  *app.ExecutionResult
+}
+
+// Custom messages.
+type foldersLoadedMsg struct {
+	folders []string
+	err     error
+}
+
+type runFinishedMsg struct {
+	report  *app.RunReport
+	aborted bool
+	err     error
+}
+
+// runProgressMsg reports the progress of the run in progress. ch is the
+// channel it came from, to tell reports of an earlier run apart.
+type runProgressMsg struct {
+	progress app.RunProgress
+	ch       <-chan app.RunProgress
+}
+
+// NewRunnerModel creates a new collection run panel model.
+func NewRunnerModel(requestService *app.RequestService, runnerService *app.RunnerService) RunnerModel {
+	return RunnerModel{
+		requestService: requestService,
+		runnerService:  runnerService,
+		showTime:       true,
+	}
+}
+
+// Open starts loading the folder list. The last run's results stay visible.
+func (m *RunnerModel) Open() tea.Cmd {
+	m.errorMsg = ""
+	m.loading = true
+	return func() tea.Msg {
+		folders, err := m.requestService.ListFolders(context.Background())
+		return foldersLoadedMsg{folders: folders, err: err}
+	}
+}
+
+// Running reports whether a run is in progress.
+func (m RunnerModel) Running() bool {
+	return m.running
+}
+
+// Stop aborts the run in progress, if any. The requests that finished are
+// still reported, and recorded to history.
+func (m *RunnerModel) Stop() {
+	if m.cancel != nil {
+		m.cancel()
+		m.aborted = true
+	}
+}
+
+// Update handles messages and updates the model.
+func (m RunnerModel) Update(msg tea.Msg) (RunnerModel, tea.Cmd) {
+	switch msg := msg.(type) {
+	case foldersLoadedMsg:
+		m.loading = false
+		if msg.err != nil {
+			m.errorMsg = msg.err.Error()
+			return m, nil
+		}
+		m.folders = msg.folders
+		if m.selectedIndex >= len(m.folders) {
+			m.selectedIndex = 0
+		}
+
+	case runProgressMsg:
+		if !m.running || msg.ch != m.progress {
+			return m, nil
+		}
+		m.updateStep(msg.progress)
+		return m, waitForRunProgress(m.progress, m.progressDone)
+
+	case runFinishedMsg:
+		m.running = false
+		m.cancel = nil
+		if msg.err != nil {
+			m.steps = nil
+			m.errorMsg = msg.err.Error()
+			return m, nil
+		}
+		m.errorMsg = ""
+		m.report = msg.report
+		m.steps = reportSteps(msg.report)
+
+	case tea.KeyMsg:
+		if m.loading || m.running {
+			return m, nil
+		}
+		cmd := m.handleKey(msg)
+		return m, cmd
+	}
+
+	return m, nil
+}
+
+// handleKey moves through the folders and runs the one selected.
+func (m *RunnerModel) handleKey(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "up", "k":
+		if m.selectedIndex > 0 {
+			m.selectedIndex--
+		}
+	case "down", "j":
+		if m.selectedIndex < len(m.folders)-1 {
+			m.selectedIndex++
+		}
+	case "enter":
+		if len(m.folders) > 0 {
+			return m.run(m.folders[m.selectedIndex])
+		}
+	}
+	return nil
+}
+
+// run returns a command that runs folder and reports the outcome, and one
+// waiting for the progress of the run.
+func (m *RunnerModel) run(folder string) tea.Cmd {
+	ctx, cancel := context.WithCancel(context.Background())
+	ch := make(chan app.RunProgress)
+	m.running = true
+	m.errorMsg = ""
+	m.folder = folder
+	m.steps = nil
+	m.progress = ch
+	m.progressDone = ctx.Done()
+	m.cancel = cancel
+	m.aborted = false
+	m.report = nil
+
+	// The run waits for each report to be taken, so none is lost, unless it
+	// was aborted, when the final report tells how every request ended.
+	report := func(p app.RunProgress) {
+		select {
+		case ch <- p:
+		case <-ctx.Done():
+		}
+	}
+	run := func() tea.Msg {
+		defer cancel()
+		report, err := m.runnerService.Run(app.WithRunProgress(ctx, report), folder)
+		return runFinishedMsg{report: report, aborted: ctx.Err() != nil, err: err}
+	}
+	return tea.Batch(run, waitForRunProgress(ch, ctx.Done()))
+}
+
+// waitForRunProgress returns a command that waits for the next progress
+// report on ch, or for the run to end, when it returns no message.
+func waitForRunProgress(ch <-chan app.RunProgress, done <-chan struct{}) tea.Cmd {
+	return func() tea.Msg {
+		select {
+		case p := <-ch:
+			return runProgressMsg{progress: p, ch: ch}
+		case <-done:
+			return nil
+		}
+	}
+}
+
+// updateStep records a progress report of the run in progress.
+func (m *RunnerModel) updateStep(p app.RunProgress) {
+	if len(m.steps) != len(p.Requests) {
+		m.steps = make([]runStep, len(p.Requests))
+		for i, req := range p.Requests {
+			m.steps[i] = runStep{request: req}
+		}
+	}
+	if p.Index < 0 || p.Index >= len(m.steps) {
+		return
+	}
+	if p.Result == nil {
+		m.steps[p.Index].state = stepRunning
+		return
+	}
+	m.steps[p.Index].result = p.Result
+	m.steps[p.Index].state = resultState(*p.Result)
+}
+
+// reportSteps returns the steps of a finished run.
+func reportSteps(report *app.RunReport) []runStep {
+	steps := make([]runStep, len(report.Results))
+	for i := range report.Results {
+		result := &report.Results[i]
+		steps[i] = runStep{request: result.Request, result: result, state: resultState(*result)}
+	}
+	return steps
+}
+
+// resultState returns the state of a request that has finished.
+func resultState(result app.ExecutionResult) runStepState {
+	switch {
+	case result.Passed():
+		return stepPassed
+	case result.Response == nil && errors.Is(result.Err, context.Canceled):
+		return stepSkipped
+	default:
+		return stepFailed
+	}
+}
+
+// View renders the folder list and the last run's results.
+func (m RunnerModel) View() string {
+	var sections []string
+
+	sections = append(sections, "══ Run Collection ══")
+	sections = append(sections, "")
+
+	if m.loading {
+		sections = append(sections, "Loading folders...")
+		return strings.Join(sections, "\n")
+	}
+
+	if len(m.folders) == 0 {
+		sections = append(sections, "No saved requests to run.")
+	}
+	for i, folder := range m.folders {
+		cursor := "  "
+		if i == m.selectedIndex {
+			cursor = "> "
+		}
+		sections = append(sections, cursor+folderLabel(folder))
+	}
+
+	if m.errorMsg != "" {
+		sections = append(sections, "")
+		sections = append(sections, "Error: "+m.errorMsg)
+	}
+
+	if m.running || m.report != nil {
+		sections = append(sections, "")
+		sections = append(sections, m.renderRun())
+	}
+
+	sections = append(sections, "")
+	if m.running {
+		sections = append(sections, "Esc: abort run • Ctrl+X: close, keep running")
+	} else {
+		sections = append(sections, "↑↓: choose folder • Enter: run • Esc: close")
+	}
+
+	return strings.Join(sections, "\n")
+}
+
+// renderRun renders one line per request of the run, with the assertions
+// failed under it, followed by the run summary.
+func (m RunnerModel) renderRun() string {
+	var lines []string
+
+	if m.running {
+		lines = append(lines, "Running "+folderLabel(m.folder)+":")
+		if m.steps == nil {
+			lines = append(lines, "  Loading requests...")
+		}
+	} else {
+		lines = append(lines, "Results for "+folderLabel(m.report.Folder)+":")
+	}
+	for _, step := range m.steps {
+		lines = append(lines, fmt.Sprintf("  %s %s %s  %s", stepIcon(step.state),
+			styles.MethodStyle(step.request.Method).Render(step.request.Method), step.request.Name, stepDetail(step, m.showTime)))
+		if step.result != nil && step.result.Response != nil {
+			for _, failure := range step.result.Response.AssertionFailures {
+				lines = append(lines, "      "+styles.ErrorStyle.Render("✗ "+failure))
+			}
+		}
+	}
+	lines = append(lines, m.renderSummary())
+	if m.report != nil {
+		for _, regression := range m.report.Regressions {
+			lines = append(lines, fmt.Sprintf("⚠ %s: latency regressed, %s", requestName(m.report, regression.RequestID), regression))
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// renderSummary renders the counts of the run: so far while it is in
+// progress, and with its duration once it has finished.
+func (m RunnerModel) renderSummary() string {
+	summary := m.Counts()
+	switch {
+	case m.running && m.aborted:
+		return summary + " • aborting..."
+	case m.running:
+		done := 0
+		for _, step := range m.steps {
+			if step.state != stepPending && step.state != stepRunning {
+				done++
+			}
+		}
+		return fmt.Sprintf("%s • %d of %d done", summary, done, len(m.steps))
+	case m.aborted:
+		return summary + " • aborted after " + m.report.Duration.Round(time.Millisecond).String()
+	default:
+		return summary + " in " + m.report.Duration.Round(time.Millisecond).String()
+	}
+}
+
+// Counts describes how many requests of the run passed, failed and were
+// aborted.
+func (m RunnerModel) Counts() string {
+	counts := map[runStepState]int{}
+	for _, step := range m.steps {
+		counts[step.state]++
+	}
+
+	summary := fmt.Sprintf("%d passed, %d failed", counts[stepPassed], counts[stepFailed])
+	if n := counts[stepSkipped]; n > 0 {
+		summary += fmt.Sprintf(", %d aborted", n)
+	}
+	return summary
+}
+
+// stepIcon returns the icon of a request's state in a run.
+func stepIcon(state runStepState) string {
+	switch state {
+	case stepRunning:
+		return "⠋"
+	case stepPassed:
+		return styles.SuccessStyle.Render("✓")
+	case stepFailed:
+		return styles.ErrorStyle.Render("✗")
+	case stepSkipped:
+		return "–"
+	default:
+		return "·"
+	}
+}
+
+// stepDetail describes how a request of a run is getting on, or how it ended,
+// with the time it took if showTime is set.
+func stepDetail(step runStep, showTime bool) string {
+	switch step.state {
+	case stepPending:
+		return "pending"
+	case stepRunning:
+		return "running..."
+	case stepSkipped:
+		return "aborted"
+	}
+
+	result := step.result
+	switch {
+	case result.Err != nil:
+		return result.Err.Error()
+	case result.Response != nil:
+		detail := styles.RenderStatusCode(result.Response.StatusCode, strconv.Itoa(result.Response.StatusCode))
+		if showTime {
+			detail += fmt.Sprintf(" (%dms)", result.Response.DurationMillis())
+		}
+		if n := len(result.Response.AssertionFailures); n > 0 {
+			detail += fmt.Sprintf(", %d assertion failures", n)
+		}
+		return detail
+	}
+	return ""
+}
+
+// requestName returns the name of the request with id in a run.
+func requestName(report *app.RunReport, id string) string {
+	for _, result := range report.Results {
+		if result.Request.ID == id {
+			return result.Request.Name
+		}
+	}
+	return id
+}
+
+// folderLabel returns the display name of folder.
+func folderLabel(folder string) string {
+	if folder == "" {
+		return "(top level)"
+	}
+	return folder
+}
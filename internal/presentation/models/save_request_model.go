@@ -0,0 +1,258 @@
+package models
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/williajm/curly/internal/app"
+	"github.com/williajm/curly/internal/domain"
+)
+
+// Save dialog fields.
+const (
+	saveFieldName = iota
+	saveFieldFolder
+)
+
+// SaveRequestModel represents the save dialog, which names the request in the
+// builder and chooses the folder it is saved in. Typing a folder that does
+// not exist yet creates it.
+type SaveRequestModel struct {
+	// Services.
+	requestService *app.RequestService
+
+	// request is the request being saved.
+	request *domain.Request
+
+	nameInput   textinput.Model
+	folderInput textinput.Model
+	focus       int
+
+	// folders are the existing folders, and folderQuery the text last typed
+	// into the folder input, which filters them. folderIndex is the folder
+	// picked among the matches, or -1 for none.
+	folders     []string
+	folderQuery string
+	folderIndex int
+
+	loading  bool
+	errorMsg string
+}
+
+// saveFoldersLoadedMsg carries the existing folders to choose from.
+type saveFoldersLoadedMsg struct {
+	folders []string
+	err     error
+}
+
+// requestSavedMsg reports the outcome of saving a request from the dialog.
+type requestSavedMsg struct {
+	request *domain.Request
+	err     error
+}
+
+// NewSaveRequestModel creates a new save dialog model.
+func NewSaveRequestModel(requestService *app.RequestService) SaveRequestModel {
+	nameInput := textinput.New()
+	nameInput.Placeholder = "Get users"
+	nameInput.CharLimit = 100
+	nameInput.Width = 50
+
+	folderInput := textinput.New()
+	folderInput.Placeholder = "(top level)"
+	folderInput.CharLimit = 200
+	folderInput.Width = 50
+
+	return SaveRequestModel{
+		requestService: requestService,
+		nameInput:      nameInput,
+		folderInput:    folderInput,
+		folderIndex:    -1,
+	}
+}
+
+// Open prepares the dialog to save req, prefilled with its name and folder,
+// and starts loading the existing folders.
+func (m *SaveRequestModel) Open(req *domain.Request) tea.Cmd {
+	m.request = req.Clone()
+	m.errorMsg = ""
+	m.loading = false
+	m.folders = nil
+	m.folderQuery = ""
+	m.folderIndex = -1
+
+	m.nameInput.SetValue(req.Name)
+	m.nameInput.CursorEnd()
+	m.folderInput.SetValue(req.Folder)
+	m.folderInput.CursorEnd()
+	m.focus = saveFieldName
+	m.folderInput.Blur()
+
+	return tea.Batch(m.nameInput.Focus(), func() tea.Msg {
+		folders, err := m.requestService.ListFolders(context.Background())
+		return saveFoldersLoadedMsg{folders: folders, err: err}
+	})
+}
+
+// Update handles messages and updates the model.
+func (m SaveRequestModel) Update(msg tea.Msg) (SaveRequestModel, tea.Cmd) {
+	switch msg := msg.(type) {
+	case saveFoldersLoadedMsg:
+		if msg.err != nil {
+			m.errorMsg = msg.err.Error()
+			return m, nil
+		}
+		// The top level is offered as the empty folder input.
+		m.folders = slices.DeleteFunc(msg.folders, func(f string) bool { return f == "" })
+		return m, nil
+
+	case requestSavedMsg:
+		m.loading = false
+		if msg.err != nil {
+			m.errorMsg = msg.err.Error()
+		}
+		return m, nil
+
+	case tea.KeyMsg:
+		if m.loading {
+			return m, nil
+		}
+		return m.handleKey(msg)
+	}
+
+	return m, nil
+}
+
+// handleKey handles keyboard input: Tab switches fields, ↑/↓ pick an
+// existing folder and Enter saves.
+func (m SaveRequestModel) handleKey(msg tea.KeyMsg) (SaveRequestModel, tea.Cmd) {
+	switch msg.String() {
+	case "tab", "shift+tab":
+		return m, m.setFocus(1 - m.focus)
+
+	case "up", "down":
+		matches := m.matchingFolders()
+		if len(matches) == 0 {
+			return m, nil
+		}
+		if msg.String() == "up" {
+			m.folderIndex = max(m.folderIndex-1, 0)
+		} else {
+			m.folderIndex = min(m.folderIndex+1, len(matches)-1)
+		}
+		m.folderInput.SetValue(matches[m.folderIndex])
+		m.folderInput.CursorEnd()
+		return m, m.setFocus(saveFieldFolder)
+
+	case "enter":
+		return m, m.save()
+	}
+
+	var cmd tea.Cmd
+	if m.focus == saveFieldName {
+		m.nameInput, cmd = m.nameInput.Update(msg)
+	} else {
+		before := m.folderInput.Value()
+		m.folderInput, cmd = m.folderInput.Update(msg)
+		if m.folderInput.Value() != before {
+			m.folderQuery = m.folderInput.Value()
+			m.folderIndex = -1
+		}
+	}
+	return m, cmd
+}
+
+// setFocus moves the focus to field.
+func (m *SaveRequestModel) setFocus(field int) tea.Cmd {
+	m.focus = field
+	if field == saveFieldName {
+		m.folderInput.Blur()
+		return m.nameInput.Focus()
+	}
+	m.nameInput.Blur()
+	return m.folderInput.Focus()
+}
+
+// matchingFolders returns the existing folders containing the text typed in
+// the folder input.
+func (m SaveRequestModel) matchingFolders() []string {
+	query := strings.ToLower(strings.TrimSpace(m.folderQuery))
+	var matches []string
+	for _, folder := range m.folders {
+		if strings.Contains(strings.ToLower(folder), query) {
+			matches = append(matches, folder)
+		}
+	}
+	return matches
+}
+
+// folder returns the folder typed or picked, with surrounding spaces and
+// separators trimmed.
+func (m SaveRequestModel) folder() string {
+	folder := strings.TrimSpace(m.folderInput.Value())
+	return strings.Trim(folder, app.FolderSeparator)
+}
+
+// save returns a command that saves the request under the name and in the
+// folder given, or nil if the name is missing.
+func (m *SaveRequestModel) save() tea.Cmd {
+	name := strings.TrimSpace(m.nameInput.Value())
+	if name == "" {
+		m.errorMsg = "Name is required"
+		return nil
+	}
+
+	req := m.request.Clone()
+	req.Name = name
+	folder := m.folder()
+	m.loading = true
+	m.errorMsg = ""
+	return func() tea.Msg {
+		err := m.requestService.SaveRequestToFolder(context.Background(), req, folder)
+		return requestSavedMsg{request: req, err: err}
+	}
+}
+
+// View renders the save dialog.
+func (m SaveRequestModel) View() string {
+	var sections []string
+
+	sections = append(sections, "══ Save Request ══")
+	sections = append(sections, "")
+	sections = append(sections, "Name:   "+m.nameInput.View())
+	sections = append(sections, "Folder: "+m.folderInput.View())
+
+	folder := m.folder()
+	if folder != "" && !slices.Contains(m.folders, folder) {
+		sections = append(sections, "        (new folder)")
+	}
+
+	if matches := m.matchingFolders(); len(matches) > 0 {
+		sections = append(sections, "")
+		sections = append(sections, "Folders:")
+		for i, f := range matches {
+			cursor := "  "
+			if i == m.folderIndex {
+				cursor = "> "
+			}
+			sections = append(sections, cursor+f)
+		}
+	}
+
+	if m.loading {
+		sections = append(sections, "")
+		sections = append(sections, "Saving...")
+	}
+	if m.errorMsg != "" {
+		sections = append(sections, "")
+		sections = append(sections, "Error: "+m.errorMsg)
+	}
+
+	sections = append(sections, "")
+	sections = append(sections, "Tab: switch field • ↑↓: pick folder • Enter: save • Esc: cancel")
+
+	return strings.Join(sections, "\n")
+}
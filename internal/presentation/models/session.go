@@ -0,0 +1,77 @@
+package models
+
+import (
+	"context"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/williajm/curly/internal/app"
+	"github.com/williajm/curly/internal/domain"
+)
+
+// sessionRestoredMsg reports where the last session left the TUI, if
+// anywhere.
+type sessionRestoredMsg struct {
+	restored *app.RestoredSession
+	err      error
+}
+
+// loadSession returns a command that loads where the last session left the
+// TUI.
+func (m MainModel) loadSession() tea.Cmd {
+	if m.sessionService == nil {
+		return nil
+	}
+	service := m.sessionService
+	return func() tea.Msg {
+		restored, err := service.Restore(context.Background())
+		return sessionRestoredMsg{restored: restored, err: err}
+	}
+}
+
+// saveSession returns a command that remembers where the TUI is left: the
+// request open in the request builder, the tab shown and the scroll
+// positions of the response body and history list.
+func (m MainModel) saveSession() tea.Cmd {
+	if m.sessionService == nil {
+		return nil
+	}
+	session := &domain.Session{
+		Tab:            tabNames[m.activeTab],
+		ResponseOffset: m.responseModel.offset,
+		HistoryIndex:   m.historyModel.selectedIndex,
+	}
+	if m.requestModel.request != nil {
+		session.RequestID = m.requestModel.request.ID
+	}
+	service := m.sessionService
+	return func() tea.Msg {
+		// The program is exiting, so a failure is only logged, by the
+		// service.
+		_ = service.Save(context.Background(), session)
+		return nil
+	}
+}
+
+// restoreSession reopens the request, tab and scroll positions the last
+// session left the TUI at. A request already changed, by restoring a draft,
+// is left as it is, as is a tab configured explicitly.
+func (m *MainModel) restoreSession(restored *app.RestoredSession) tea.Cmd {
+	session := restored.Session
+	if tab, err := TabNamed(session.Tab); err == nil && !m.keepDefaultTab {
+		m.activeTab = tab
+	}
+
+	if req := restored.Request; req != nil && !m.requestModel.Modified() {
+		m.requestModel.LoadRequest(req)
+		m.responseModel.SetFilter(req.ResponseFilter)
+		if restored.Response != nil {
+			m.responseModel.SetResponse(restored.Response)
+			m.responseModel.scrollTo(session.ResponseOffset)
+		}
+		m.statusMsg = "Reopened " + requestLabel(req)
+	}
+
+	// Enough of the history is loaded to reach the cursor.
+	m.historyModel.selectedIndex = max(0, session.HistoryIndex)
+	return m.historyModel.loadHistory()
+}
@@ -0,0 +1,105 @@
+package models
+
+import (
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/williajm/curly/internal/domain"
+)
+
+// unsavedAction is an action that would discard the request builder's
+// unsaved changes, run once they are saved or the user chooses to discard
+// them.
+type unsavedAction struct {
+	// what describes the action, such as "quit".
+	what string
+	run  func(m *MainModel) tea.Cmd
+}
+
+// confirmUnsaved runs the action described by what at once if the request
+// builder has no unsaved changes, and otherwise asks whether to save or
+// discard them first.
+func (m *MainModel) confirmUnsaved(what string, run func(m *MainModel) tea.Cmd) tea.Cmd {
+	if !m.requestModel.Modified() {
+		return run(m)
+	}
+	m.unsaved = &unsavedAction{what: what, run: run}
+	m.overlay = overlayUnsaved
+	return nil
+}
+
+// openRequest loads req into the request builder and shows status, once the
+// builder's unsaved changes are saved or discarded.
+func (m *MainModel) openRequest(req *domain.Request, status string) tea.Cmd {
+	return m.confirmUnsaved("open "+requestLabel(req), func(m *MainModel) tea.Cmd {
+		m.requestModel.LoadRequest(req)
+		m.responseModel.SetFilter(req.ResponseFilter)
+		m.activeTab = TabRequest
+		m.statusMsg = status
+		return nil
+	})
+}
+
+// quit quits the program, once the request builder's unsaved changes are
+// saved or discarded.
+func (m *MainModel) quit() tea.Cmd {
+	return m.confirmUnsaved("quit", func(m *MainModel) tea.Cmd {
+		// An open WebSocket session is recorded before the program exits.
+		m.webSocketModel.CloseSession()
+		m.quitting = true
+		return m.exit()
+	})
+}
+
+// handleUnsavedKey handles keyboard input while the unsaved changes prompt
+// is open: s saves the request first, d discards the changes, Esc cancels
+// the action and Ctrl+C quits without saving.
+func (m *MainModel) handleUnsavedKey(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "s", KeyCtrlS:
+		// The action runs once the request is saved.
+		return m.openOverlay(overlaySave)
+
+	case "d":
+		action := m.unsaved
+		m.overlay = overlayNone
+		m.unsaved = nil
+		return action.run(m)
+
+	case "esc":
+		m.overlay = overlayNone
+		m.unsaved = nil
+
+	case KeyCtrlC:
+		m.quitting = true
+		return m.exit()
+	}
+	return nil
+}
+
+// runUnsaved runs the action waiting for the request builder's changes to
+// be saved, if any.
+func (m *MainModel) runUnsaved() tea.Cmd {
+	action := m.unsaved
+	m.unsaved = nil
+	if action == nil {
+		return nil
+	}
+	return action.run(m)
+}
+
+// renderUnsaved renders the unsaved changes prompt.
+func (m MainModel) renderUnsaved() string {
+	var sections []string
+
+	sections = append(sections, "══ Unsaved Changes ══")
+	sections = append(sections, "")
+	sections = append(sections, "The request has unsaved changes.")
+	if m.unsaved != nil {
+		sections = append(sections, "Save them before you "+m.unsaved.what+"?")
+	}
+	sections = append(sections, "")
+	sections = append(sections, "s: save • d: discard • Esc: cancel")
+
+	return strings.Join(sections, "\n")
+}
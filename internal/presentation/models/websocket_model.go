@@ -0,0 +1,446 @@
+package models
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/williajm/curly/internal/app"
+	"github.com/williajm/curly/internal/domain"
+	"github.com/williajm/curly/internal/infrastructure/websocket"
+)
+
+// WebSocket tab focus.
+const (
+	wsFocusNone = iota
+	wsFocusURL
+	wsFocusMessage
+)
+
+// webSocketChromeLines is the number of lines around the message log: the
+// tabs and status bar, the title, URL, connection state, message line and
+// key hints.
+const webSocketChromeLines = 14
+
+// WebSocketModel represents the WebSocket tab: a URL to connect to, a line
+// to send text or JSON messages from, and the log of messages both ways. The
+// handshake sends the request builder's headers and authentication.
+type WebSocketModel struct {
+	// Services.
+	requestService *app.RequestService
+
+	// request is the request builder's request, set before each key.
+	request *domain.Request
+
+	// Inputs, and which of them has focus. While one has, keys are typed
+	// into it rather than switching tabs.
+	urlInput     textinput.Model
+	messageInput textinput.Model
+	focus        int
+
+	// jsonOnly checks that messages are JSON before they are sent.
+	jsonOnly bool
+
+	// session is the open connection, nil when disconnected. end describes
+	// how the last session ended.
+	session    *app.WebSocketSession
+	connecting bool
+	end        string
+	messages   []app.WebSocketMessage
+	errorMsg   string
+
+	// Message log.
+	viewport viewport.Model
+	width    int
+}
+
+// Custom messages.
+type wsConnectedMsg struct {
+	session *app.WebSocketSession
+	err     error
+}
+
+type wsReceivedMsg struct {
+	session *app.WebSocketSession
+	message app.WebSocketMessage
+}
+
+type wsSentMsg struct {
+	session *app.WebSocketSession
+	message app.WebSocketMessage
+	err     error
+}
+
+// wsClosedMsg reports that session ended, with the response recorded to
+// history. err is nil if either side closed it normally.
+type wsClosedMsg struct {
+	session  *app.WebSocketSession
+	response *domain.Response
+	err      error
+}
+
+// NewWebSocketModel creates a new WebSocket tab model.
+func NewWebSocketModel(requestService *app.RequestService) WebSocketModel {
+	urlInput := textinput.New()
+	urlInput.Placeholder = "wss://echo.example.com/socket"
+	urlInput.Width = 60
+	urlInput.Focus()
+
+	messageInput := textinput.New()
+	messageInput.Placeholder = "message to send"
+	messageInput.Width = 60
+	messageInput.CharLimit = 0
+
+	return WebSocketModel{
+		requestService: requestService,
+		urlInput:       urlInput,
+		messageInput:   messageInput,
+		focus:          wsFocusURL,
+		viewport:       viewport.New(80, 10),
+	}
+}
+
+// SetRequest sets the request whose headers and authentication the
+// handshake sends. Its URL fills the URL input while that is empty.
+func (m *WebSocketModel) SetRequest(req *domain.Request) {
+	m.request = req
+	if m.urlInput.Value() == "" && req != nil && req.URL != "" {
+		m.urlInput.SetValue(websocket.WSURL(req.URL))
+	}
+}
+
+// Typing reports whether keys are typed into an input.
+func (m WebSocketModel) Typing() bool {
+	return m.focus != wsFocusNone
+}
+
+// Connected reports whether a session is open.
+func (m WebSocketModel) Connected() bool {
+	return m.session != nil
+}
+
+// Update handles messages and updates the model.
+func (m WebSocketModel) Update(msg tea.Msg) (WebSocketModel, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.viewport.Width = max(msg.Width-4, 10)
+		m.viewport.Height = max(msg.Height-webSocketChromeLines, 3)
+		m.updateLog()
+		return m, nil
+
+	case wsConnectedMsg:
+		m.connecting = false
+		if msg.err != nil {
+			m.errorMsg = msg.err.Error()
+			return m, nil
+		}
+		m.session = msg.session
+		m.messages = nil
+		m.end = ""
+		m.updateLog()
+		return m, tea.Batch(m.setFocus(wsFocusMessage), receiveMessage(msg.session))
+
+	case wsReceivedMsg:
+		if msg.session != m.session {
+			return m, nil
+		}
+		m.addMessage(msg.message)
+		return m, receiveMessage(msg.session)
+
+	case wsSentMsg:
+		if msg.session != m.session {
+			return m, nil
+		}
+		if msg.err != nil {
+			m.errorMsg = "Cannot send: " + msg.err.Error()
+			return m, nil
+		}
+		m.addMessage(msg.message)
+		return m, nil
+
+	case wsClosedMsg:
+		if msg.session != m.session {
+			return m, nil
+		}
+		m.session = nil
+		m.end = "closed"
+		if msg.err != nil {
+			m.end = "failed: " + msg.err.Error()
+		}
+		if m.focus == wsFocusMessage {
+			return m, m.setFocus(wsFocusURL)
+		}
+		return m, nil
+
+	case tea.KeyMsg:
+		if m.Typing() {
+			return m.updateInput(msg)
+		}
+		return m.updateLogKeys(msg)
+	}
+
+	return m, nil
+}
+
+// updateInput handles a key while an input has focus: Enter connects or
+// disconnects from the URL input and sends from the message input, Tab
+// moves between them and Esc leaves them.
+func (m WebSocketModel) updateInput(msg tea.KeyMsg) (WebSocketModel, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		return m, m.setFocus(wsFocusNone)
+	case "tab", "shift+tab":
+		if m.focus == wsFocusURL {
+			return m, m.setFocus(wsFocusMessage)
+		}
+		return m, m.setFocus(wsFocusURL)
+	case KeyAltJ:
+		m.jsonOnly = !m.jsonOnly
+		return m, nil
+	case "enter":
+		if m.focus == wsFocusURL {
+			if m.session != nil {
+				return m, m.disconnect()
+			}
+			return m, m.connect()
+		}
+		return m, m.send()
+	}
+
+	var cmd tea.Cmd
+	if m.focus == wsFocusURL {
+		m.urlInput, cmd = m.urlInput.Update(msg)
+	} else {
+		m.messageInput, cmd = m.messageInput.Update(msg)
+	}
+	return m, cmd
+}
+
+// updateLogKeys handles a key while no input has focus: Enter or i types a
+// message (or the URL while disconnected), u edits the URL, d disconnects,
+// x clears the log and the arrow keys scroll it.
+func (m WebSocketModel) updateLogKeys(msg tea.KeyMsg) (WebSocketModel, tea.Cmd) {
+	switch msg.String() {
+	case "enter", "i":
+		if m.session != nil {
+			return m, m.setFocus(wsFocusMessage)
+		}
+		return m, m.setFocus(wsFocusURL)
+	case "u":
+		return m, m.setFocus(wsFocusURL)
+	case "d":
+		return m, m.disconnect()
+	case "x":
+		m.messages = nil
+		m.updateLog()
+		return m, nil
+	case KeyAltJ:
+		m.jsonOnly = !m.jsonOnly
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.viewport, cmd = m.viewport.Update(msg)
+	return m, cmd
+}
+
+// setFocus moves the focus to the input focus, or to the log for
+// wsFocusNone.
+func (m *WebSocketModel) setFocus(focus int) tea.Cmd {
+	m.focus = focus
+	m.urlInput.Blur()
+	m.messageInput.Blur()
+	switch focus {
+	case wsFocusURL:
+		return m.urlInput.Focus()
+	case wsFocusMessage:
+		return m.messageInput.Focus()
+	}
+	return nil
+}
+
+// connect returns a command that opens a session to the URL entered.
+func (m *WebSocketModel) connect() tea.Cmd {
+	if m.connecting {
+		return nil
+	}
+	if m.requestService == nil {
+		m.errorMsg = "WebSocket sessions are not available"
+		return nil
+	}
+
+	rawURL := strings.TrimSpace(m.urlInput.Value())
+	if rawURL == "" {
+		m.errorMsg = "Enter a ws:// or wss:// URL"
+		return nil
+	}
+	req := domain.NewRequestWithMethodAndURL(domain.MethodGet, rawURL)
+	if m.request != nil {
+		req = m.request.Clone()
+		req.URL = rawURL
+	}
+
+	m.connecting = true
+	m.errorMsg = ""
+	requestService := m.requestService
+	return func() tea.Msg {
+		session, err := requestService.OpenWebSocket(context.Background(), req)
+		return wsConnectedMsg{session: session, err: err}
+	}
+}
+
+// disconnect returns a command that closes the session, which records it to
+// history. Its receiver then reports the session closed.
+func (m WebSocketModel) disconnect() tea.Cmd {
+	session := m.session
+	if session == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		session.Close(context.Background())
+		return nil
+	}
+}
+
+// CloseSession closes the session, if one is open, and records it to
+// history before returning.
+func (m *WebSocketModel) CloseSession() {
+	if m.session != nil {
+		m.session.Close(context.Background())
+		m.session = nil
+	}
+}
+
+// send returns a command that sends the message typed, after checking it is
+// JSON if only JSON is sent.
+func (m *WebSocketModel) send() tea.Cmd {
+	if m.session == nil {
+		m.errorMsg = "Not connected"
+		return nil
+	}
+	text := m.messageInput.Value()
+	if text == "" {
+		return nil
+	}
+	if m.jsonOnly {
+		var v any
+		if err := json.Unmarshal([]byte(text), &v); err != nil {
+			m.errorMsg = "Not JSON: " + err.Error()
+			return nil
+		}
+	}
+
+	m.errorMsg = ""
+	m.messageInput.SetValue("")
+	session := m.session
+	return func() tea.Msg {
+		message, err := session.Send(text)
+		return wsSentMsg{session: session, message: message, err: err}
+	}
+}
+
+// receiveMessage returns a command that waits for the next message of
+// session, closing the session once there are no more.
+func receiveMessage(session *app.WebSocketSession) tea.Cmd {
+	return func() tea.Msg {
+		message, err := session.Receive()
+		if err == nil {
+			return wsReceivedMsg{session: session, message: message}
+		}
+		if errors.Is(err, io.EOF) {
+			err = nil
+		}
+		return wsClosedMsg{session: session, response: session.Close(context.Background()), err: err}
+	}
+}
+
+// addMessage adds message to the log, following new messages while the log
+// is scrolled to the bottom.
+func (m *WebSocketModel) addMessage(message app.WebSocketMessage) {
+	following := m.viewport.AtBottom()
+	m.messages = append(m.messages, message)
+	m.updateLog()
+	if following {
+		m.viewport.GotoBottom()
+	}
+}
+
+// updateLog renders the message log into the viewport, wrapping long
+// messages.
+func (m *WebSocketModel) updateLog() {
+	if len(m.messages) == 0 {
+		m.viewport.SetContent("No messages")
+		return
+	}
+	wrap := lipgloss.NewStyle().Width(m.viewport.Width)
+	lines := make([]string, len(m.messages))
+	for i, message := range m.messages {
+		lines[i] = wrap.Render(app.FormatWebSocketMessage(message))
+	}
+	m.viewport.SetContent(strings.Join(lines, "\n"))
+}
+
+// state describes the connection, such as "connected, 12 messages".
+func (m WebSocketModel) state() string {
+	var state string
+	switch {
+	case m.connecting:
+		state = "connecting..."
+	case m.session != nil:
+		state = "connected"
+	case m.end != "":
+		state = m.end
+	default:
+		state = "disconnected"
+	}
+	if n := len(m.messages); n > 0 {
+		state += fmt.Sprintf(", %d messages", n)
+	}
+	if m.jsonOnly {
+		state += ", JSON only"
+	}
+	return state
+}
+
+// View renders the WebSocket tab.
+func (m WebSocketModel) View() string {
+	var sections []string
+
+	sections = append(sections, "══ WebSocket ══")
+	sections = append(sections, "")
+	sections = append(sections, "URL:     "+m.urlInput.View())
+	sections = append(sections, "State:   "+m.state())
+	sections = append(sections, "")
+	sections = append(sections, m.viewport.View())
+	sections = append(sections, "")
+	sections = append(sections, "Message: "+m.messageInput.View())
+
+	if m.errorMsg != "" {
+		sections = append(sections, "Error: "+m.errorMsg)
+	} else {
+		sections = append(sections, "")
+	}
+
+	connect := "Enter: connect"
+	if m.session != nil {
+		connect = "Enter: disconnect"
+	}
+	switch m.focus {
+	case wsFocusURL:
+		sections = append(sections, connect+" • Tab: message • Alt+J: JSON only • Esc: leave input")
+	case wsFocusMessage:
+		sections = append(sections, "Enter: send • Tab: URL • Alt+J: JSON only • Esc: leave input")
+	default:
+		sections = append(sections, "Enter/i: type • u: URL • d: disconnect • x: clear log • ↑↓: scroll • Tab/1-4: switch tabs")
+	}
+
+	return strings.Join(sections, "\n")
+}
@@ -0,0 +1,264 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/williajm/curly/internal/app"
+	"github.com/williajm/curly/internal/domain"
+)
+
+// welcomeChoice is a way to get started offered by the welcome screen.
+type welcomeChoice int
+
+const (
+	welcomeSamples welcomeChoice = iota
+	welcomePostman
+	welcomeScratch
+)
+
+// welcomeChoices labels the choices, in the order they are listed.
+var welcomeChoices = []string{
+	welcomeSamples: "Create sample requests (httpbin.org)",
+	welcomePostman: "Import a Postman collection",
+	welcomeScratch: "Start from scratch",
+}
+
+// WelcomeModel represents the welcome screen shown on first run, when there
+// are no saved requests and no history yet.
+type WelcomeModel struct {
+	// Services.
+	requestService *app.RequestService
+	historyService *app.HistoryService
+	importService  *app.ImportService
+
+	selectedIndex int
+	errorMsg      string
+
+	// Postman collection path, typed once the import is chosen.
+	choosingFile bool
+	input        textinput.Model
+
+	// working is set while sample requests are created or a collection is
+	// imported.
+	working bool
+}
+
+// Custom messages.
+type firstRunCheckedMsg struct {
+	firstRun bool
+}
+
+// welcomeFinishedMsg reports that the user got started: request is the
+// request to open (nil for none) and status what was done.
+type welcomeFinishedMsg struct {
+	request *domain.Request
+	status  string
+	err     error
+}
+
+// NewWelcomeModel creates a new welcome screen model.
+func NewWelcomeModel(requestService *app.RequestService, historyService *app.HistoryService, importService *app.ImportService) WelcomeModel {
+	input := textinput.New()
+	input.Placeholder = "~/Downloads/My API.postman_collection.json"
+	input.Prompt = "File: "
+	input.Width = 60
+
+	return WelcomeModel{
+		requestService: requestService,
+		historyService: historyService,
+		importService:  importService,
+		input:          input,
+	}
+}
+
+// CheckFirstRun returns a command that reports whether this is a first run:
+// there are no saved requests and no history. It is not one if either
+// cannot be read.
+func (m WelcomeModel) CheckFirstRun() tea.Cmd {
+	return func() tea.Msg {
+		ctx := context.Background()
+		requests, err := m.requestService.ListRequests(ctx)
+		if err != nil || len(requests) > 0 {
+			return firstRunCheckedMsg{}
+		}
+		history, err := m.historyService.GetHistory(ctx, 1)
+		return firstRunCheckedMsg{firstRun: err == nil && len(history) == 0}
+	}
+}
+
+// ChoosingFile reports whether a Postman collection path is being typed.
+func (m WelcomeModel) ChoosingFile() bool {
+	return m.choosingFile
+}
+
+// Update handles messages and updates the model.
+func (m WelcomeModel) Update(msg tea.Msg) (WelcomeModel, tea.Cmd) {
+	switch msg := msg.(type) {
+	case welcomeFinishedMsg:
+		m.working = false
+		if msg.err != nil {
+			m.errorMsg = msg.err.Error()
+		}
+		return m, nil
+
+	case tea.KeyMsg:
+		if m.working {
+			return m, nil
+		}
+		if m.choosingFile {
+			return m.updateFile(msg)
+		}
+		switch msg.String() {
+		case "up", "k":
+			if m.selectedIndex > 0 {
+				m.selectedIndex--
+			}
+		case "down", "j":
+			if m.selectedIndex < len(welcomeChoices)-1 {
+				m.selectedIndex++
+			}
+		case "enter":
+			return m.choose(welcomeChoice(m.selectedIndex))
+		}
+	}
+
+	return m, nil
+}
+
+// choose acts on a choice.
+func (m WelcomeModel) choose(choice welcomeChoice) (WelcomeModel, tea.Cmd) {
+	m.errorMsg = ""
+	switch choice {
+	case welcomeSamples:
+		m.working = true
+		return m, m.createSamples()
+	case welcomePostman:
+		m.choosingFile = true
+		m.input.Reset()
+		return m, m.input.Focus()
+	default:
+		return m, func() tea.Msg {
+			return welcomeFinishedMsg{status: "Enter a URL and press Ctrl+S to save your first request"}
+		}
+	}
+}
+
+// updateFile handles keys while the collection path is typed: Enter imports
+// it and Esc goes back to the choices.
+func (m WelcomeModel) updateFile(msg tea.KeyMsg) (WelcomeModel, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.choosingFile = false
+		m.errorMsg = ""
+		m.input.Blur()
+		return m, nil
+	case "enter":
+		path := strings.TrimSpace(m.input.Value())
+		if path == "" {
+			m.errorMsg = "enter the path of a collection exported from Postman"
+			return m, nil
+		}
+		m.errorMsg = ""
+		m.working = true
+		return m, m.importPostman(path)
+	}
+
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(msg)
+	return m, cmd
+}
+
+// createSamples returns a command that creates the sample requests and opens
+// the first.
+func (m WelcomeModel) createSamples() tea.Cmd {
+	return func() tea.Msg {
+		requests, err := m.importService.CreateSamples(context.Background())
+		if err != nil {
+			return welcomeFinishedMsg{err: err}
+		}
+		status := fmt.Sprintf("Created %d sample requests in %q • Ctrl+B to browse them", len(requests), app.SamplesFolder)
+		return welcomeFinishedMsg{request: requests[0], status: status}
+	}
+}
+
+// importPostman returns a command that imports the collection at path and
+// opens its first request.
+func (m WelcomeModel) importPostman(path string) tea.Cmd {
+	return func() tea.Msg {
+		data, err := os.ReadFile(expandHome(path))
+		if err != nil {
+			return welcomeFinishedMsg{err: err}
+		}
+		result, err := m.importService.ImportPostman(context.Background(), data, "")
+		if err != nil {
+			return welcomeFinishedMsg{err: err}
+		}
+		status := fmt.Sprintf("Imported %d requests into %q • Ctrl+B to browse them", len(result.Requests), result.Requests[0].Folder)
+		if len(result.Warnings) > 0 {
+			status += " (" + strings.Join(result.Warnings, "; ") + ")"
+		}
+		return welcomeFinishedMsg{request: result.Requests[0], status: status}
+	}
+}
+
+// expandHome replaces a leading ~ in path with the home directory.
+func expandHome(path string) string {
+	rest, ok := strings.CutPrefix(path, "~")
+	if !ok || (rest != "" && rest[0] != '/' && rest[0] != filepath.Separator) {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, rest)
+}
+
+// View renders the welcome screen.
+func (m WelcomeModel) View() string {
+	var sections []string
+
+	sections = append(sections, "══ Welcome to curly ══")
+	sections = append(sections, "")
+	sections = append(sections, "There are no saved requests yet. How would you like to start?")
+	sections = append(sections, "")
+
+	for i, label := range welcomeChoices {
+		cursor := "  "
+		if i == m.selectedIndex {
+			cursor = "> "
+		}
+		sections = append(sections, cursor+label)
+	}
+
+	if m.choosingFile {
+		sections = append(sections, "")
+		sections = append(sections, "Path of a collection exported from Postman (v2.0 or v2.1):")
+		sections = append(sections, m.input.View())
+	}
+
+	if m.working {
+		sections = append(sections, "")
+		sections = append(sections, "Working...")
+	}
+
+	if m.errorMsg != "" {
+		sections = append(sections, "")
+		sections = append(sections, "Error: "+m.errorMsg)
+	}
+
+	sections = append(sections, "")
+	if m.choosingFile {
+		sections = append(sections, "Enter: import • Esc: back")
+	} else {
+		sections = append(sections, "↑↓: choose • Enter: select • Esc: close")
+	}
+
+	return strings.Join(sections, "\n")
+}
@@ -0,0 +1,127 @@
+package models
+
+import (
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/williajm/curly/internal/app"
+)
+
+// WorkspaceModel represents the workspace switcher.
+type WorkspaceModel struct {
+	// Services.
+	workspaceService *app.WorkspaceService
+
+	// Workspaces.
+	workspaces    []string
+	selectedIndex int
+	loading       bool
+	errorMsg      string
+
+	// chosen is set once the user picks a workspace.
+	chosen string
+}
+
+// Custom messages.
+type workspacesLoadedMsg struct {
+	workspaces []string
+	err        error
+}
+
+// NewWorkspaceModel creates a new workspace switcher model.
+func NewWorkspaceModel(workspaceService *app.WorkspaceService) WorkspaceModel {
+	return WorkspaceModel{
+		workspaceService: workspaceService,
+	}
+}
+
+// Open resets the switcher and starts loading the workspace list.
+func (m *WorkspaceModel) Open() tea.Cmd {
+	m.chosen = ""
+	m.errorMsg = ""
+	m.loading = true
+	return func() tea.Msg {
+		workspaces, err := m.workspaceService.List()
+		return workspacesLoadedMsg{workspaces: workspaces, err: err}
+	}
+}
+
+// Update handles messages and updates the model.
+func (m WorkspaceModel) Update(msg tea.Msg) (WorkspaceModel, tea.Cmd) {
+	switch msg := msg.(type) {
+	case workspacesLoadedMsg:
+		m.loading = false
+		if msg.err != nil {
+			m.errorMsg = msg.err.Error()
+			return m, nil
+		}
+		m.workspaces = msg.workspaces
+		m.selectedIndex = 0
+		for i, name := range m.workspaces {
+			if name == m.workspaceService.Current() {
+				m.selectedIndex = i
+			}
+		}
+
+	case tea.KeyMsg:
+		if m.loading {
+			return m, nil
+		}
+		switch msg.String() {
+		case "up", "k":
+			if m.selectedIndex > 0 {
+				m.selectedIndex--
+			}
+		case "down", "j":
+			if m.selectedIndex < len(m.workspaces)-1 {
+				m.selectedIndex++
+			}
+		case "enter":
+			if len(m.workspaces) > 0 {
+				m.chosen = m.workspaces[m.selectedIndex]
+			}
+		}
+	}
+
+	return m, nil
+}
+
+// View renders the workspace switcher.
+func (m WorkspaceModel) View() string {
+	var sections []string
+
+	sections = append(sections, "══ Workspaces ══")
+	sections = append(sections, "")
+
+	if m.loading {
+		sections = append(sections, "Loading workspaces...")
+		return strings.Join(sections, "\n")
+	}
+
+	if m.errorMsg != "" {
+		sections = append(sections, "Error: "+m.errorMsg)
+		sections = append(sections, "")
+	}
+
+	for i, name := range m.workspaces {
+		cursor := "  "
+		if i == m.selectedIndex {
+			cursor = "> "
+		}
+		if name == m.workspaceService.Current() {
+			name += " (current)"
+		}
+		sections = append(sections, cursor+name)
+	}
+
+	sections = append(sections, "")
+	sections = append(sections, "Start curly with --workspace <name> to create a new workspace.")
+	sections = append(sections, "↑↓: navigate • Enter: switch • Esc: close")
+
+	return strings.Join(sections, "\n")
+}
+
+// Chosen returns the workspace the user picked, or "" if none yet.
+func (m WorkspaceModel) Chosen() string {
+	return m.chosen
+}
@@ -2,170 +2,304 @@
 package styles
 
 import (
+	"fmt"
+	"strings"
+
 	"github.com/charmbracelet/lipgloss"
 )
 
-// Color palette.
+// Colors of the active theme, set by Apply.
 var (
 	// Primary colors.
-	ColorPrimary   = lipgloss.Color("#7C3AED") // Purple
-	ColorSecondary = lipgloss.Color("#06B6D4") // Cyan
+	ColorPrimary   lipgloss.Color
+	ColorSecondary lipgloss.Color
 
 	// Status colors.
-	ColorSuccess = lipgloss.Color("#10B981") // Green
-	ColorError   = lipgloss.Color("#EF4444") // Red
-	ColorWarning = lipgloss.Color("#F59E0B") // Orange
-	ColorInfo    = lipgloss.Color("#3B82F6") // Blue
+	ColorSuccess lipgloss.Color
+	ColorError   lipgloss.Color
+	ColorWarning lipgloss.Color
+	ColorInfo    lipgloss.Color
 
 	// Status code colors.
-	Color2xx = lipgloss.Color("#10B981") // Green for 2xx
-	Color3xx = lipgloss.Color("#F59E0B") // Yellow for 3xx
-	Color4xx = lipgloss.Color("#FB923C") // Orange for 4xx
-	Color5xx = lipgloss.Color("#EF4444") // Red for 5xx
+	Color2xx lipgloss.Color
+	Color3xx lipgloss.Color
+	Color4xx lipgloss.Color
+	Color5xx lipgloss.Color
 
 	// Text colors.
-	ColorText       = lipgloss.Color("#F5F5F5") // Light gray
-	ColorTextDimmed = lipgloss.Color("#9CA3AF") // Dimmed gray
-	ColorTextBright = lipgloss.Color("#FFFFFF") // White
+	ColorText       lipgloss.Color
+	ColorTextDimmed lipgloss.Color
+	ColorTextBright lipgloss.Color
 
 	// Background colors.
-	ColorBackground       = lipgloss.Color("#1F2937") // Dark gray
-	ColorBackgroundLight  = lipgloss.Color("#374151") // Light gray
-	ColorBackgroundActive = lipgloss.Color("#4B5563") // Active state
+	ColorBackground       lipgloss.Color
+	ColorBackgroundLight  lipgloss.Color
+	ColorBackgroundActive lipgloss.Color
 
 	// Border colors.
-	ColorBorder       = lipgloss.Color("#4B5563")
-	ColorBorderActive = lipgloss.Color("#7C3AED")
+	ColorBorder       lipgloss.Color
+	ColorBorderActive lipgloss.Color
+
+	// methodColors colors HTTP methods, keyed by upper-case method.
+	methodColors map[string]lipgloss.Color
 )
 
 // Base text styles.
 var (
 	// TextStyle is the default text style.
-	TextStyle = lipgloss.NewStyle().
-			Foreground(ColorText)
+	TextStyle lipgloss.Style
 
 	// TitleStyle is for main titles.
-	TitleStyle = lipgloss.NewStyle().
-			Foreground(ColorPrimary).
-			Bold(true).
-			Padding(0, 1)
+	TitleStyle lipgloss.Style
 
 	// SubtitleStyle is for subtitles and section headers.
-	SubtitleStyle = lipgloss.NewStyle().
-			Foreground(ColorSecondary).
-			Bold(true)
+	SubtitleStyle lipgloss.Style
 
 	// DimmedStyle is for less important text.
-	DimmedStyle = lipgloss.NewStyle().
-			Foreground(ColorTextDimmed)
+	DimmedStyle lipgloss.Style
 
 	// BoldStyle is for emphasized text.
-	BoldStyle = lipgloss.NewStyle().
-			Foreground(ColorTextBright).
-			Bold(true)
+	BoldStyle lipgloss.Style
 
 	// SuccessStyle is for success messages.
-	SuccessStyle = lipgloss.NewStyle().
-			Foreground(ColorSuccess).
-			Bold(true)
+	SuccessStyle lipgloss.Style
 
 	// ErrorStyle is for error messages.
-	ErrorStyle = lipgloss.NewStyle().
-			Foreground(ColorError).
-			Bold(true)
+	ErrorStyle lipgloss.Style
 
 	// WarningStyle is for warning messages.
-	WarningStyle = lipgloss.NewStyle().
-			Foreground(ColorWarning).
-			Bold(true)
+	WarningStyle lipgloss.Style
 )
 
 // Component styles.
 var (
 	// TabStyle is for inactive tabs.
-	TabStyle = lipgloss.NewStyle().
-			Padding(0, 2).
-			Foreground(ColorTextDimmed).
-			Background(ColorBackground)
+	TabStyle lipgloss.Style
 
 	// ActiveTabStyle is for the active tab.
-	ActiveTabStyle = lipgloss.NewStyle().
-			Padding(0, 2).
-			Foreground(ColorTextBright).
-			Background(ColorBackgroundActive).
-			Bold(true)
+	ActiveTabStyle lipgloss.Style
 
 	// TabIndicatorStyle is for the line under the active tab.
-	TabIndicatorStyle = lipgloss.NewStyle().
-				Foreground(ColorPrimary).
-				Bold(true)
+	TabIndicatorStyle lipgloss.Style
 
 	// InputStyle is for text input fields.
-	InputStyle = lipgloss.NewStyle().
-			Padding(0, 1).
-			Border(lipgloss.RoundedBorder()).
-			BorderForeground(ColorBorder)
+	InputStyle lipgloss.Style
 
 	// InputFocusedStyle is for focused input fields.
-	InputFocusedStyle = lipgloss.NewStyle().
-				Padding(0, 1).
-				Border(lipgloss.RoundedBorder()).
-				BorderForeground(ColorBorderActive)
+	InputFocusedStyle lipgloss.Style
 
 	// ButtonStyle is for buttons.
-	ButtonStyle = lipgloss.NewStyle().
-			Padding(0, 2).
-			Background(ColorPrimary).
-			Foreground(ColorTextBright).
-			Bold(true)
+	ButtonStyle lipgloss.Style
 
 	// ButtonDisabledStyle is for disabled buttons.
-	ButtonDisabledStyle = lipgloss.NewStyle().
-				Padding(0, 2).
-				Background(ColorBackgroundLight).
-				Foreground(ColorTextDimmed)
+	ButtonDisabledStyle lipgloss.Style
 
 	// StatusBarStyle is for the bottom status bar.
-	StatusBarStyle = lipgloss.NewStyle().
-			Foreground(ColorTextBright).
-			Background(ColorBackgroundLight).
-			Padding(0, 1)
+	StatusBarStyle lipgloss.Style
 
 	// BoxStyle is for general containers.
-	BoxStyle = lipgloss.NewStyle().
-			Border(lipgloss.RoundedBorder()).
-			BorderForeground(ColorBorder).
-			Padding(1, 2)
+	BoxStyle lipgloss.Style
 
 	// SelectedItemStyle is for selected items in lists.
-	SelectedItemStyle = lipgloss.NewStyle().
-				Foreground(ColorPrimary).
-				Bold(true)
+	SelectedItemStyle lipgloss.Style
 )
 
 // Status code styles.
 var (
 	// Status2xxStyle is for 2xx success responses.
-	Status2xxStyle = lipgloss.NewStyle().
-			Foreground(Color2xx).
-			Bold(true)
+	Status2xxStyle lipgloss.Style
 
 	// Status3xxStyle is for 3xx redirect responses.
-	Status3xxStyle = lipgloss.NewStyle().
-			Foreground(Color3xx).
-			Bold(true)
+	Status3xxStyle lipgloss.Style
 
 	// Status4xxStyle is for 4xx client error responses.
-	Status4xxStyle = lipgloss.NewStyle().
-			Foreground(Color4xx).
-			Bold(true)
+	Status4xxStyle lipgloss.Style
 
 	// Status5xxStyle is for 5xx server error responses.
-	Status5xxStyle = lipgloss.NewStyle().
-			Foreground(Color5xx).
-			Bold(true)
+	Status5xxStyle lipgloss.Style
+)
+
+// Specialized styles for specific UI elements.
+var (
+	// LabelStyle is for form field labels.
+	LabelStyle lipgloss.Style
+
+	// ValueStyle is for form field values.
+	ValueStyle lipgloss.Style
+
+	// KeyStyle is for key-value pair keys (headers, query params).
+	KeyStyle lipgloss.Style
+
+	// HelpStyle is for help text.
+	HelpStyle lipgloss.Style
+
+	// ShortcutStyle is for keyboard shortcuts.
+	ShortcutStyle lipgloss.Style
+
+	// SearchMatchStyle is for search matches in the response body.
+	SearchMatchStyle lipgloss.Style
+
+	// SearchCurrentMatchStyle is for the search match being navigated to.
+	SearchCurrentMatchStyle lipgloss.Style
 )
 
+// Syntax highlighting styles for JSON bodies.
+var (
+	// SyntaxKeyStyle is for object keys.
+	SyntaxKeyStyle lipgloss.Style
+
+	// SyntaxStringStyle is for string values.
+	SyntaxStringStyle lipgloss.Style
+
+	// SyntaxNumberStyle is for numbers.
+	SyntaxNumberStyle lipgloss.Style
+
+	// SyntaxLiteralStyle is for true, false and null.
+	SyntaxLiteralStyle lipgloss.Style
+)
+
+func init() {
+	Apply(Dark)
+}
+
+// Apply makes p the active theme, recomputing every color and style.
+func Apply(p Palette) {
+	ColorPrimary, ColorSecondary = p.Primary, p.Secondary
+	ColorSuccess, ColorError, ColorWarning, ColorInfo = p.Success, p.Error, p.Warning, p.Info
+	Color2xx, Color3xx, Color4xx, Color5xx = p.Status2xx, p.Status3xx, p.Status4xx, p.Status5xx
+	ColorText, ColorTextDimmed, ColorTextBright = p.Text, p.TextDimmed, p.TextBright
+	ColorBackground, ColorBackgroundLight, ColorBackgroundActive = p.Background, p.BackgroundLight, p.BackgroundActive
+	ColorBorder, ColorBorderActive = p.Border, p.BorderActive
+	methodColors = p.Methods
+
+	TextStyle = lipgloss.NewStyle().
+		Foreground(ColorText)
+	TitleStyle = lipgloss.NewStyle().
+		Foreground(ColorPrimary).
+		Bold(true).
+		Padding(0, 1)
+	SubtitleStyle = lipgloss.NewStyle().
+		Foreground(ColorSecondary).
+		Bold(true)
+	DimmedStyle = lipgloss.NewStyle().
+		Foreground(ColorTextDimmed)
+	BoldStyle = lipgloss.NewStyle().
+		Foreground(ColorTextBright).
+		Bold(true)
+	SuccessStyle = lipgloss.NewStyle().
+		Foreground(ColorSuccess).
+		Bold(true)
+	ErrorStyle = lipgloss.NewStyle().
+		Foreground(ColorError).
+		Bold(true)
+	WarningStyle = lipgloss.NewStyle().
+		Foreground(ColorWarning).
+		Bold(true)
+
+	TabStyle = lipgloss.NewStyle().
+		Padding(0, 2).
+		Foreground(ColorTextDimmed).
+		Background(ColorBackground)
+	ActiveTabStyle = lipgloss.NewStyle().
+		Padding(0, 2).
+		Foreground(ColorTextBright).
+		Background(ColorBackgroundActive).
+		Bold(true)
+	TabIndicatorStyle = lipgloss.NewStyle().
+		Foreground(ColorPrimary).
+		Bold(true)
+	InputStyle = lipgloss.NewStyle().
+		Padding(0, 1).
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(ColorBorder)
+	InputFocusedStyle = lipgloss.NewStyle().
+		Padding(0, 1).
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(ColorBorderActive)
+	ButtonStyle = lipgloss.NewStyle().
+		Padding(0, 2).
+		Background(ColorPrimary).
+		Foreground(ColorTextBright).
+		Bold(true)
+	ButtonDisabledStyle = lipgloss.NewStyle().
+		Padding(0, 2).
+		Background(ColorBackgroundLight).
+		Foreground(ColorTextDimmed)
+	StatusBarStyle = lipgloss.NewStyle().
+		Foreground(ColorTextBright).
+		Background(ColorBackgroundLight).
+		Padding(0, 1)
+	BoxStyle = lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(ColorBorder).
+		Padding(1, 2)
+	SelectedItemStyle = lipgloss.NewStyle().
+		Foreground(ColorPrimary).
+		Bold(true)
+
+	Status2xxStyle = lipgloss.NewStyle().
+		Foreground(Color2xx).
+		Bold(true)
+	Status3xxStyle = lipgloss.NewStyle().
+		Foreground(Color3xx).
+		Bold(true)
+	Status4xxStyle = lipgloss.NewStyle().
+		Foreground(Color4xx).
+		Bold(true)
+	Status5xxStyle = lipgloss.NewStyle().
+		Foreground(Color5xx).
+		Bold(true)
+
+	LabelStyle = lipgloss.NewStyle().
+		Foreground(ColorSecondary).
+		Bold(true)
+	ValueStyle = lipgloss.NewStyle().
+		Foreground(ColorText)
+	KeyStyle = lipgloss.NewStyle().
+		Foreground(ColorInfo).
+		Bold(true)
+	HelpStyle = lipgloss.NewStyle().
+		Foreground(ColorTextDimmed).
+		Italic(true)
+	ShortcutStyle = lipgloss.NewStyle().
+		Foreground(ColorPrimary).
+		Bold(true)
+	SearchMatchStyle = lipgloss.NewStyle().
+		Foreground(ColorBackground).
+		Background(ColorWarning)
+	SearchCurrentMatchStyle = lipgloss.NewStyle().
+		Foreground(ColorTextBright).
+		Background(ColorPrimary).
+		Bold(true)
+
+	SyntaxKeyStyle = lipgloss.NewStyle().
+		Foreground(ColorInfo)
+	SyntaxStringStyle = lipgloss.NewStyle().
+		Foreground(ColorSuccess)
+	SyntaxNumberStyle = lipgloss.NewStyle().
+		Foreground(ColorWarning)
+	SyntaxLiteralStyle = lipgloss.NewStyle().
+		Foreground(ColorSecondary)
+}
+
+// MethodStyle returns the style for an HTTP method, or TextStyle for a
+// method the theme has no color for.
+func MethodStyle(method string) lipgloss.Style {
+	color, ok := methodColors[strings.ToUpper(method)]
+	if !ok {
+		return TextStyle
+	}
+	return lipgloss.NewStyle().
+		Foreground(color).
+		Bold(true)
+}
+
+// RenderMethod renders an HTTP method in its theme color, padded to width
+// first so that color codes don't skew columns.
+func RenderMethod(method string, width int) string {
+	return MethodStyle(method).Render(fmt.Sprintf("%-*s", width, method))
+}
+
 // GetStatusStyle returns the appropriate style for a status code.
 func GetStatusStyle(statusCode int) lipgloss.Style {
 	switch {
@@ -182,33 +316,6 @@ func GetStatusStyle(statusCode int) lipgloss.Style {
 	}
 }
 
-// Specialized styles for specific UI elements.
-var (
-	// LabelStyle is for form field labels.
-	LabelStyle = lipgloss.NewStyle().
-			Foreground(ColorSecondary).
-			Bold(true)
-
-	// ValueStyle is for form field values.
-	ValueStyle = lipgloss.NewStyle().
-			Foreground(ColorText)
-
-	// KeyStyle is for key-value pair keys (headers, query params).
-	KeyStyle = lipgloss.NewStyle().
-			Foreground(ColorInfo).
-			Bold(true)
-
-	// HelpStyle is for help text.
-	HelpStyle = lipgloss.NewStyle().
-			Foreground(ColorTextDimmed).
-			Italic(true)
-
-	// ShortcutStyle is for keyboard shortcuts.
-	ShortcutStyle = lipgloss.NewStyle().
-			Foreground(ColorPrimary).
-			Bold(true)
-)
-
 // Helper functions for common styling operations.
 
 // RenderKeyValue renders a key-value pair with consistent styling.
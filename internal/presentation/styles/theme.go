@@ -0,0 +1,188 @@
+package styles
+
+import (
+	"maps"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Palette holds the colors of a theme.
+type Palette struct {
+	Primary   lipgloss.Color
+	Secondary lipgloss.Color
+
+	Success lipgloss.Color
+	Error   lipgloss.Color
+	Warning lipgloss.Color
+	Info    lipgloss.Color
+
+	Status2xx lipgloss.Color
+	Status3xx lipgloss.Color
+	Status4xx lipgloss.Color
+	Status5xx lipgloss.Color
+
+	Text       lipgloss.Color
+	TextDimmed lipgloss.Color
+	TextBright lipgloss.Color
+
+	Background       lipgloss.Color
+	BackgroundLight  lipgloss.Color
+	BackgroundActive lipgloss.Color
+
+	Border       lipgloss.Color
+	BorderActive lipgloss.Color
+
+	// Methods colors HTTP methods, keyed by upper-case method.
+	Methods map[string]lipgloss.Color
+}
+
+// Dark is the default theme, for terminals with a dark background.
+var Dark = Palette{
+	Primary:   lipgloss.Color("#7C3AED"), // Purple
+	Secondary: lipgloss.Color("#06B6D4"), // Cyan
+
+	Success: lipgloss.Color("#10B981"), // Green
+	Error:   lipgloss.Color("#EF4444"), // Red
+	Warning: lipgloss.Color("#F59E0B"), // Orange
+	Info:    lipgloss.Color("#3B82F6"), // Blue
+
+	Status2xx: lipgloss.Color("#10B981"), // Green
+	Status3xx: lipgloss.Color("#22D3EE"), // Cyan
+	Status4xx: lipgloss.Color("#FACC15"), // Yellow
+	Status5xx: lipgloss.Color("#EF4444"), // Red
+
+	Text:       lipgloss.Color("#F5F5F5"), // Light gray
+	TextDimmed: lipgloss.Color("#9CA3AF"), // Dimmed gray
+	TextBright: lipgloss.Color("#FFFFFF"), // White
+
+	Background:       lipgloss.Color("#1F2937"), // Dark gray
+	BackgroundLight:  lipgloss.Color("#374151"), // Light gray
+	BackgroundActive: lipgloss.Color("#4B5563"), // Active state
+
+	Border:       lipgloss.Color("#4B5563"),
+	BorderActive: lipgloss.Color("#7C3AED"),
+
+	Methods: map[string]lipgloss.Color{
+		"GET":     lipgloss.Color("#10B981"),
+		"POST":    lipgloss.Color("#F59E0B"),
+		"PUT":     lipgloss.Color("#3B82F6"),
+		"PATCH":   lipgloss.Color("#A78BFA"),
+		"DELETE":  lipgloss.Color("#EF4444"),
+		"HEAD":    lipgloss.Color("#06B6D4"),
+		"OPTIONS": lipgloss.Color("#9CA3AF"),
+	},
+}
+
+// Light is the theme for terminals with a light background.
+var Light = Palette{
+	Primary:   lipgloss.Color("#6D28D9"), // Purple
+	Secondary: lipgloss.Color("#0E7490"), // Cyan
+
+	Success: lipgloss.Color("#047857"), // Green
+	Error:   lipgloss.Color("#B91C1C"), // Red
+	Warning: lipgloss.Color("#B45309"), // Orange
+	Info:    lipgloss.Color("#1D4ED8"), // Blue
+
+	Status2xx: lipgloss.Color("#047857"), // Green
+	Status3xx: lipgloss.Color("#0E7490"), // Cyan
+	Status4xx: lipgloss.Color("#A16207"), // Yellow
+	Status5xx: lipgloss.Color("#B91C1C"), // Red
+
+	Text:       lipgloss.Color("#1F2937"), // Dark gray
+	TextDimmed: lipgloss.Color("#6B7280"), // Dimmed gray
+	TextBright: lipgloss.Color("#111827"), // Near black
+
+	Background:       lipgloss.Color("#F3F4F6"), // Light gray
+	BackgroundLight:  lipgloss.Color("#E5E7EB"), // Lighter gray
+	BackgroundActive: lipgloss.Color("#D1D5DB"), // Active state
+
+	Border:       lipgloss.Color("#D1D5DB"),
+	BorderActive: lipgloss.Color("#6D28D9"),
+
+	Methods: map[string]lipgloss.Color{
+		"GET":     lipgloss.Color("#047857"),
+		"POST":    lipgloss.Color("#B45309"),
+		"PUT":     lipgloss.Color("#1D4ED8"),
+		"PATCH":   lipgloss.Color("#6D28D9"),
+		"DELETE":  lipgloss.Color("#B91C1C"),
+		"HEAD":    lipgloss.Color("#0E7490"),
+		"OPTIONS": lipgloss.Color("#6B7280"),
+	},
+}
+
+// builtinThemes are the themes available without configuration.
+var builtinThemes = map[string]Palette{
+	"dark":  Dark,
+	"light": Light,
+}
+
+// Builtin returns the built-in theme with the given name.
+func Builtin(name string) (Palette, bool) {
+	p, ok := builtinThemes[strings.ToLower(name)]
+	return p, ok
+}
+
+// BuiltinNames returns the names of the built-in themes, sorted.
+func BuiltinNames() []string {
+	names := make([]string, 0, len(builtinThemes))
+	for name := range builtinThemes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Override returns p with every color set in o replacing its own. Method
+// colors are merged, so o may color only some methods.
+func (p Palette) Override(o Palette) Palette {
+	for _, pair := range []struct{ dst, src *lipgloss.Color }{
+		{&p.Primary, &o.Primary},
+		{&p.Secondary, &o.Secondary},
+		{&p.Success, &o.Success},
+		{&p.Error, &o.Error},
+		{&p.Warning, &o.Warning},
+		{&p.Info, &o.Info},
+		{&p.Status2xx, &o.Status2xx},
+		{&p.Status3xx, &o.Status3xx},
+		{&p.Status4xx, &o.Status4xx},
+		{&p.Status5xx, &o.Status5xx},
+		{&p.Text, &o.Text},
+		{&p.TextDimmed, &o.TextDimmed},
+		{&p.TextBright, &o.TextBright},
+		{&p.Background, &o.Background},
+		{&p.BackgroundLight, &o.BackgroundLight},
+		{&p.BackgroundActive, &o.BackgroundActive},
+		{&p.Border, &o.Border},
+		{&p.BorderActive, &o.BorderActive},
+	} {
+		if *pair.src != "" {
+			*pair.dst = *pair.src
+		}
+	}
+
+	methods := maps.Clone(p.Methods)
+	if methods == nil {
+		methods = make(map[string]lipgloss.Color)
+	}
+	for method, color := range o.Methods {
+		methods[strings.ToUpper(method)] = color
+	}
+	p.Methods = methods
+	return p
+}
+
+var hexColor = regexp.MustCompile(`^#([0-9A-Fa-f]{3}|[0-9A-Fa-f]{6})$`)
+
+// ValidColor reports whether c is a color lipgloss understands: a hex color
+// such as "#7C3AED" or an ANSI color number from 0 to 255.
+func ValidColor(c string) bool {
+	if hexColor.MatchString(c) {
+		return true
+	}
+	n, err := strconv.Atoi(c)
+	return err == nil && n >= 0 && n <= 255
+}
@@ -19,11 +19,22 @@ func RenderHelp(_, _ int) string {
 	sections = append(sections, "")
 	sections = append(sections, "  q, Ctrl+C     Quit application")
 	sections = append(sections, "  ?             Toggle help screen")
-	sections = append(sections, "  Tab           Switch to next tab")
-	sections = append(sections, "  Shift+Tab     Switch to previous tab")
+	sections = append(sections, "  Tab           Switch to next tab (next field on the Request tab)")
+	sections = append(sections, "  Shift+Tab     Switch to previous tab (previous field on the Request tab)")
 	sections = append(sections, "  1             Jump to Request tab")
 	sections = append(sections, "  2             Jump to Response tab")
 	sections = append(sections, "  3             Jump to History tab")
+	sections = append(sections, "  4             Jump to WebSocket tab")
+	sections = append(sections, "  Ctrl+O        Switch workspace")
+	sections = append(sections, "  Ctrl+G        Import a curl command or share link")
+	sections = append(sections, "  Ctrl+Y        Copy the request as code or a share link")
+	sections = append(sections, "  Ctrl+X        Run a collection (Esc: abort the run)")
+	sections = append(sections, "  Ctrl+B        Browse collections (n: rename, x: cut, p: paste, r: run folder)")
+	sections = append(sections, "  Ctrl+P        Find and open a saved request")
+	sections = append(sections, "  Ctrl+E        Choose the environment (n: new, e: edit variables)")
+	sections = append(sections, "  Ctrl+Q        Preview the request with its variables filled in (r: raw)")
+	sections = append(sections, "  Ctrl+\\        Show or collapse the response beside the request")
+	sections = append(sections, "  Alt+- Alt+=   Narrow or widen the request pane")
 	sections = append(sections, "")
 
 	// Request tab shortcuts.
@@ -33,30 +44,87 @@ func RenderHelp(_, _ int) string {
 	sections = append(sections, "  Shift+Tab     Move to previous field")
 	sections = append(sections, "  Ctrl+Enter    Send request")
 	sections = append(sections, "  Ctrl+R        Send request (alternative)")
-	sections = append(sections, "  Ctrl+S        Save request (coming soon)")
+	sections = append(sections, "  Esc           Cancel the request being sent")
+	sections = append(sections, "  Ctrl+S        Save request, choosing its name and folder")
 	sections = append(sections, "  ←/→ or h/l    Change method selection")
 	sections = append(sections, "  ←/→ or h/l    Change auth type")
+	sections = append(sections, "  ←/→ or h/l    Change body type (Raw, JSON, Form, GraphQL, File)")
+	sections = append(sections, "  ←/→ or h/l    Choose the GraphQL operation to run, when there are several")
+	sections = append(sections, "  Alt+C         Copy the request as curl, secrets masked")
+	sections = append(sections, "  Alt+Shift+C   Copy the request as curl, secrets included")
+	sections = append(sections, "  Alt+N         Show or hide line numbers in the body editor")
+	sections = append(sections, "  Alt+O         Sign in with OAuth (device flow) as bearer auth")
+	sections = append(sections, "")
+	sections = append(sections, "  In the headers and query parameter editors:")
+	sections = append(sections, "  a             Add a row")
+	sections = append(sections, "  Enter         Edit the selected name or value (Enter keeps, Esc discards)")
+	sections = append(sections, "  d             Delete the selected row")
+	sections = append(sections, "  ←/→, ↑/↓      Choose name or value, and row")
+	sections = append(sections, "  Shift+↑/↓     Move the selected row")
+	sections = append(sections, "  Space         Turn the selected query parameter or form field on or off")
 	sections = append(sections, "")
 
 	// Response tab shortcuts.
 	sections = append(sections, "RESPONSE TAB:")
 	sections = append(sections, "")
-	sections = append(sections, "  h             Toggle between headers and body view")
+	sections = append(sections, "  h             Switch between the body and the headers pane")
+	sections = append(sections, "  ↑/↓, Enter    In the headers pane, choose a header and copy its value")
+	sections = append(sections, "  t             Show the TLS details of an HTTPS response (Security pane)")
+	sections = append(sections, "  p             Toggle between the formatted and raw body")
+	sections = append(sections, "  o             Save the body to a file (binary bodies show as hex)")
+	sections = append(sections, "  y             Copy the raw body to the clipboard")
+	sections = append(sections, "  Y             Copy the response headers")
+	sections = append(sections, "  v             Copy the values selected by the filter")
+	sections = append(sections, "  c             Copy the request as a curl command")
+	sections = append(sections, "  r             Show the request as sent, in HTTP wire format")
+	sections = append(sections, "  / or f        Filter the body with a JSONPath or jq path (Esc clears)")
+	sections = append(sections, "  s             Search the body as you type (Esc clears)")
+	sections = append(sections, "  n/N           Next/previous search match")
+	sections = append(sections, "  w             Wrap long lines")
+	sections = append(sections, "  #             Show or hide line numbers")
 	sections = append(sections, "  ↑/↓           Scroll response content")
-	sections = append(sections, "  PgUp/PgDn     Page up/down")
+	sections = append(sections, "  PgUp/PgDn     Page up/down (also Space/b, and d/u for half a page)")
+	sections = append(sections, "  g/G           Go to the top/bottom of the body")
+	sections = append(sections, "")
+	sections = append(sections, "  For event streams (requests that accept text/event-stream):")
+	sections = append(sections, "  p             Pause or resume the live view")
+	sections = append(sections, "  o             Save the events to a transcript file")
+	sections = append(sections, "  Esc           Stop the stream")
 	sections = append(sections, "")
 
 	// History tab shortcuts.
 	sections = append(sections, "HISTORY TAB:")
 	sections = append(sections, "")
 	sections = append(sections, "  ↑/↓ or k/j    Navigate history entries")
-	sections = append(sections, "  Enter         Load selected entry (coming soon)")
-	sections = append(sections, "  d, Delete     Delete selected entry")
+	sections = append(sections, "  Enter         Load selected entry into the request builder")
+	sections = append(sections, "  v             Show entry details (esc to go back)")
+	sections = append(sections, "  d, Delete     Delete selected entry, or the entries selected with Space")
+	sections = append(sections, "  Space         Select entry for bulk actions")
+	sections = append(sections, "  *             Select all filtered entries, or none")
+	sections = append(sections, "  x             Export selected entries to an archive")
 	sections = append(sections, "  r             Refresh history")
+	sections = append(sections, "  m             Mark entry for comparison")
+	sections = append(sections, "  c             Compare the two selected entries, or the entry with the marked one")
+	sections = append(sections, "  s             Toggle statistics panel")
+	sections = append(sections, "  / or f        Filter (status:4xx method:POST url:users)")
+	sections = append(sections, "  e             Show only failed executions")
+	sections = append(sections, "  Esc           Clear the selection, then the filter")
 	sections = append(sections, "  g, Home       Jump to first entry")
 	sections = append(sections, "  G, End        Jump to last entry")
 	sections = append(sections, "")
 
+	// WebSocket tab shortcuts.
+	sections = append(sections, "WEBSOCKET TAB:")
+	sections = append(sections, "")
+	sections = append(sections, "  Enter         Connect or disconnect (URL), or send the message")
+	sections = append(sections, "  Tab           Move between the URL and message lines")
+	sections = append(sections, "  Alt+J         Only send messages that are JSON")
+	sections = append(sections, "  Esc           Leave the lines so global keys work")
+	sections = append(sections, "  ↑/↓           Scroll the message log")
+	sections = append(sections, "  d             Disconnect")
+	sections = append(sections, "  x             Clear the log")
+	sections = append(sections, "")
+
 	sections = append(sections, "════════════════════════════════════════════════════════════")
 	sections = append(sections, "")
 	sections = append(sections, "                   Press ESC or ? to close")
@@ -1,9 +1,6 @@
 -- Migration 001: Initial Schema
 -- Creates the core tables for requests and history
 
--- Enable foreign key support
-PRAGMA foreign_keys = ON;
-
 -- Requests table: stores saved HTTP requests
 CREATE TABLE IF NOT EXISTS requests (
     id TEXT PRIMARY KEY,
@@ -30,7 +27,7 @@ CREATE TABLE IF NOT EXISTS history (
     response_headers TEXT,     -- JSON serialized response headers
     response_body TEXT,        -- Response body content
     error TEXT,                -- Error message if request failed (NULL on success)
-    FOREIGN KEY (request_id) REFERENCES requests(id) ON DELETE SET NULL
+    FOREIGN KEY (request_id) REFERENCES requests(id) ON DELETE CASCADE
 );
 
 -- Indexes for performance
@@ -38,3 +35,4 @@ CREATE INDEX IF NOT EXISTS idx_history_executed_at ON history(executed_at DESC);
 CREATE INDEX IF NOT EXISTS idx_history_request_id ON history(request_id);
 CREATE INDEX IF NOT EXISTS idx_requests_name ON requests(name);
 CREATE INDEX IF NOT EXISTS idx_requests_created_at ON requests(created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_requests_updated_at ON requests(updated_at DESC);
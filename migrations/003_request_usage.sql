@@ -0,0 +1,7 @@
+-- Migration 003: Request usage metadata
+-- Tracks when each saved request was last executed and how often.
+
+ALTER TABLE requests ADD COLUMN last_executed_at TIMESTAMP;                 -- NULL if never executed
+ALTER TABLE requests ADD COLUMN execution_count INTEGER NOT NULL DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS idx_requests_last_executed_at ON requests(last_executed_at DESC);
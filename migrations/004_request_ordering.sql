@@ -0,0 +1,15 @@
+-- Migration 004: Request ordering
+-- Groups saved requests into folders and gives each an explicit position
+-- within its folder, so curated collections keep the order their runs need.
+
+ALTER TABLE requests ADD COLUMN folder TEXT NOT NULL DEFAULT '';        -- '' is the top level
+ALTER TABLE requests ADD COLUMN position INTEGER NOT NULL DEFAULT 0;     -- 0-based order within folder
+
+-- Existing requests keep their creation order.
+UPDATE requests SET position = (
+    SELECT COUNT(*) FROM requests AS earlier
+    WHERE earlier.created_at < requests.created_at
+       OR (earlier.created_at = requests.created_at AND earlier.id < requests.id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_requests_folder_position ON requests(folder, position);
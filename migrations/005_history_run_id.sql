@@ -0,0 +1,7 @@
+-- Migration 005: Collection run IDs
+-- Executions made by the collection runner share a run ID so a run's results
+-- can be read back together.
+
+ALTER TABLE history ADD COLUMN run_id TEXT;  -- NULL for executions outside a run
+
+CREATE INDEX IF NOT EXISTS idx_history_run_id ON history(run_id);
@@ -0,0 +1,6 @@
+-- Migration 006: Request snapshots and replays
+-- Each execution records the request as it was sent, so it can be replayed
+-- even after the saved request is edited. Replays link to the entry they re-sent.
+
+ALTER TABLE history ADD COLUMN request_snapshot TEXT;  -- JSON serialized request; NULL for older entries
+ALTER TABLE history ADD COLUMN replay_of TEXT;         -- History entry this execution replayed
@@ -0,0 +1,6 @@
+-- Migration 007: Response schemas
+-- A request can carry a JSON Schema its response body is validated against.
+-- Each execution records the violations found as assertion failures.
+
+ALTER TABLE requests ADD COLUMN response_schema TEXT NOT NULL DEFAULT '';  -- '' means no schema
+ALTER TABLE history ADD COLUMN assertion_failures TEXT;                    -- JSON array of messages; NULL when none
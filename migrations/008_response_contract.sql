@@ -0,0 +1,5 @@
+-- Migration 008: Response contracts
+-- A request imported from an OpenAPI document can carry its operation's
+-- response contract; each execution is checked against it.
+
+ALTER TABLE requests ADD COLUMN response_contract TEXT NOT NULL DEFAULT '';  -- '' means no contract
@@ -0,0 +1,5 @@
+-- Migration 009: Request tags
+-- Saved requests can be labelled with tags, which bulk actions add and remove
+-- across many requests at once.
+
+ALTER TABLE requests ADD COLUMN tags TEXT NOT NULL DEFAULT '[]';  -- JSON serialized sorted []string
@@ -0,0 +1,13 @@
+-- Migration 011: Environments
+-- Named sets of variables that fill {{name}} references in requests. At most
+-- one environment is active at a time.
+
+CREATE TABLE IF NOT EXISTS environments (
+    id TEXT PRIMARY KEY,
+    name TEXT NOT NULL UNIQUE,
+    variables TEXT NOT NULL DEFAULT '{}',  -- JSON serialized map[string]string
+    secrets TEXT NOT NULL DEFAULT '[]',    -- JSON serialized sorted []string of secret variable names
+    active INTEGER NOT NULL DEFAULT 0,     -- 1 for the active environment
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,9 @@
+-- Migration 013: Drafts
+-- The request builder's unsaved changes, saved periodically by each TUI
+-- session so they can be restored after a crash or an accidental quit.
+
+CREATE TABLE IF NOT EXISTS drafts (
+    id TEXT PRIMARY KEY,                   -- One per TUI session
+    request TEXT NOT NULL,                 -- JSON request snapshot, as in history
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,12 @@
+-- Migration 014: Session
+-- Where the TUI was left when it last exited, so that it starts there again.
+-- The table holds a single row.
+
+CREATE TABLE IF NOT EXISTS session (
+    id INTEGER PRIMARY KEY CHECK (id = 1),
+    request_id TEXT NOT NULL DEFAULT '',        -- Saved request open in the request builder, '' for none
+    tab TEXT NOT NULL DEFAULT '',               -- Name of the tab shown
+    response_offset INTEGER NOT NULL DEFAULT 0, -- First row of the response body in view
+    history_index INTEGER NOT NULL DEFAULT 0,   -- Position of the history cursor
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,30 @@
+// Package migrations embeds the SQL schema migrations for curly's databases.
+//
+// Files are named NNN_description.sql and applied in version order by the
+// repository packages, so installed binaries never depend on this directory
+// existing on disk. SQLite migrations live at the top level; PostgreSQL
+// migrations live in the postgres directory.
+package migrations
+
+import (
+	"embed"
+	"io/fs"
+)
+
+// FS contains the embedded SQLite migration files.
+//
+//go:embed *.sql
+var FS embed.FS
+
+//go:embed postgres/*.sql
+var postgresFS embed.FS
+
+// PostgresFS returns the embedded PostgreSQL migration files.
+func PostgresFS() fs.FS {
+	sub, err := fs.Sub(postgresFS, "postgres")
+	if err != nil {
+		// The directory is embedded at compile time, so this cannot happen.
+		panic(err)
+	}
+	return sub
+}
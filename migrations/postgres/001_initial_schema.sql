@@ -0,0 +1,37 @@
+-- Migration 001: Initial Schema (PostgreSQL)
+-- Creates the core tables for requests and history
+
+-- Requests table: stores saved HTTP requests
+CREATE TABLE IF NOT EXISTS requests (
+    id TEXT PRIMARY KEY,
+    name TEXT NOT NULL,
+    method TEXT NOT NULL,
+    url TEXT NOT NULL,
+    headers TEXT,          -- JSON serialized map[string]string
+    query_params TEXT,     -- JSON serialized map[string]string
+    body TEXT,
+    auth_type TEXT,        -- Type of authentication: "none", "basic", "bearer", "apikey"
+    auth_config TEXT,      -- JSON serialized auth configuration
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- History table: tracks request execution history
+CREATE TABLE IF NOT EXISTS history (
+    id TEXT PRIMARY KEY,
+    request_id TEXT REFERENCES requests(id) ON DELETE CASCADE,
+    executed_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    status_code INTEGER,
+    status TEXT,
+    response_time_ms BIGINT,
+    response_headers TEXT,
+    response_body TEXT,
+    error TEXT
+);
+
+-- Indexes for performance
+CREATE INDEX IF NOT EXISTS idx_history_executed_at ON history(executed_at DESC);
+CREATE INDEX IF NOT EXISTS idx_history_request_id ON history(request_id);
+CREATE INDEX IF NOT EXISTS idx_requests_name ON requests(name);
+CREATE INDEX IF NOT EXISTS idx_requests_created_at ON requests(created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_requests_updated_at ON requests(updated_at DESC);
@@ -0,0 +1,5 @@
+-- Migration 002: Offloaded response bodies (PostgreSQL)
+-- Large response bodies are stored as compressed files on disk; history keeps
+-- only their content hash and a preview in response_body.
+
+ALTER TABLE history ADD COLUMN IF NOT EXISTS response_body_ref TEXT;
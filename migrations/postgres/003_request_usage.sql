@@ -0,0 +1,7 @@
+-- Migration 003: Request usage metadata (PostgreSQL)
+-- Tracks when each saved request was last executed and how often.
+
+ALTER TABLE requests ADD COLUMN IF NOT EXISTS last_executed_at TIMESTAMPTZ;
+ALTER TABLE requests ADD COLUMN IF NOT EXISTS execution_count BIGINT NOT NULL DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS idx_requests_last_executed_at ON requests(last_executed_at DESC NULLS LAST);
@@ -0,0 +1,13 @@
+-- Migration 004: Request ordering (PostgreSQL)
+-- Groups saved requests into folders and gives each an explicit position
+-- within its folder, so curated collections keep the order their runs need.
+
+ALTER TABLE requests ADD COLUMN IF NOT EXISTS folder TEXT NOT NULL DEFAULT '';
+ALTER TABLE requests ADD COLUMN IF NOT EXISTS position INTEGER NOT NULL DEFAULT 0;
+
+-- Existing requests keep their creation order.
+UPDATE requests AS r SET position = ordered.rn - 1
+FROM (SELECT id, ROW_NUMBER() OVER (ORDER BY created_at, id) AS rn FROM requests) AS ordered
+WHERE r.id = ordered.id;
+
+CREATE INDEX IF NOT EXISTS idx_requests_folder_position ON requests(folder, position);
@@ -0,0 +1,7 @@
+-- Migration 005: Collection run IDs (PostgreSQL)
+-- Executions made by the collection runner share a run ID so a run's results
+-- can be read back together.
+
+ALTER TABLE history ADD COLUMN IF NOT EXISTS run_id TEXT;
+
+CREATE INDEX IF NOT EXISTS idx_history_run_id ON history(run_id);
@@ -0,0 +1,6 @@
+-- Migration 006: Request snapshots and replays (PostgreSQL)
+-- Each execution records the request as it was sent, so it can be replayed
+-- even after the saved request is edited. Replays link to the entry they re-sent.
+
+ALTER TABLE history ADD COLUMN IF NOT EXISTS request_snapshot TEXT;
+ALTER TABLE history ADD COLUMN IF NOT EXISTS replay_of TEXT;
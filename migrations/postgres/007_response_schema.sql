@@ -0,0 +1,6 @@
+-- Migration 007: Response schemas (PostgreSQL)
+-- A request can carry a JSON Schema its response body is validated against.
+-- Each execution records the violations found as assertion failures.
+
+ALTER TABLE requests ADD COLUMN IF NOT EXISTS response_schema TEXT NOT NULL DEFAULT '';
+ALTER TABLE history ADD COLUMN IF NOT EXISTS assertion_failures TEXT;
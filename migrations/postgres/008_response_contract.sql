@@ -0,0 +1,5 @@
+-- Migration 008: Response contracts (PostgreSQL)
+-- A request imported from an OpenAPI document can carry its operation's
+-- response contract; each execution is checked against it.
+
+ALTER TABLE requests ADD COLUMN IF NOT EXISTS response_contract TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,5 @@
+-- Migration 010: Response filters (PostgreSQL)
+-- A request remembers the JSONPath or jq filter last applied to its response,
+-- so the response view shows the same values the next time it is sent.
+
+ALTER TABLE requests ADD COLUMN IF NOT EXISTS response_filter TEXT NOT NULL DEFAULT '';
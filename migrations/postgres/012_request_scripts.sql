@@ -0,0 +1,6 @@
+-- Migration 012: Request scripts (PostgreSQL)
+-- A request can carry JavaScript run before it is sent and once its response
+-- is received, to change it, set variables and assert on the response.
+
+ALTER TABLE requests ADD COLUMN IF NOT EXISTS pre_request_script TEXT NOT NULL DEFAULT '';
+ALTER TABLE requests ADD COLUMN IF NOT EXISTS post_response_script TEXT NOT NULL DEFAULT '';
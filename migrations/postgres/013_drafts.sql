@@ -0,0 +1,9 @@
+-- Migration 013: Drafts (PostgreSQL)
+-- The request builder's unsaved changes, saved periodically by each TUI
+-- session so they can be restored after a crash or an accidental quit.
+
+CREATE TABLE IF NOT EXISTS drafts (
+    id TEXT PRIMARY KEY,
+    request TEXT NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
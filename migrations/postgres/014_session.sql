@@ -0,0 +1,12 @@
+-- Migration 014: Session (PostgreSQL)
+-- Where the TUI was left when it last exited, so that it starts there again.
+-- The table holds a single row.
+
+CREATE TABLE IF NOT EXISTS session (
+    id INTEGER PRIMARY KEY CHECK (id = 1),
+    request_id TEXT NOT NULL DEFAULT '',
+    tab TEXT NOT NULL DEFAULT '',
+    response_offset INTEGER NOT NULL DEFAULT 0,
+    history_index INTEGER NOT NULL DEFAULT 0,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
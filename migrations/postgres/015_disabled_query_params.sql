@@ -0,0 +1,5 @@
+-- Migration 015: Disabled query parameters (PostgreSQL)
+-- Query parameters turned off in the request builder are kept with the
+-- request, as a JSON object like query_params, but not sent.
+
+ALTER TABLE requests ADD COLUMN IF NOT EXISTS disabled_query_params TEXT NOT NULL DEFAULT '{}';